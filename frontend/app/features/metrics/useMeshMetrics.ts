@@ -1,22 +1,9 @@
 import { useEffect, useState } from 'react';
-import { OFFSET_MESH_METRICS, IDX_METRICS_EPOCH } from '../../../src/wasm/layout';
+import { OFFSET_MESH_METRICS, SIZE_MESH_METRICS, IDX_METRICS_EPOCH } from '../../../src/wasm/layout';
 import { INOSBridge } from '../../../src/wasm/bridge-state';
+import { decodeMeshMetrics, type MeshMetricsRecord } from '../../../src/wasm/mesh-metrics';
 
-export interface MeshMetrics {
-  totalPeers: number;
-  connectedPeers: number;
-  dhtEntries: number;
-  gossipRate: number;
-  avgReputation: number;
-  bytesSent: bigint;
-  bytesReceived: bigint;
-  p50Latency: number;
-  p95Latency: number;
-  successRate: number;
-  fetchSuccessRate: number;
-  localChunks: number;
-  totalChunks: number;
-  sectorId: number;
+export interface MeshMetrics extends MeshMetricsRecord {
   meshActive: boolean;
 }
 
@@ -43,26 +30,13 @@ export function useMeshMetrics() {
         lastEpoch = currentEpoch;
 
         // Get cached DataView for mesh metrics region
-        const view = INOSBridge.getRegionDataView(OFFSET_MESH_METRICS, 256);
+        const view = INOSBridge.getRegionDataView(OFFSET_MESH_METRICS, SIZE_MESH_METRICS);
         if (!view) return;
 
-        setMetrics({
-          totalPeers: view.getUint32(0, true),
-          connectedPeers: view.getUint32(4, true),
-          dhtEntries: view.getUint32(8, true),
-          gossipRate: view.getFloat32(12, true),
-          avgReputation: view.getFloat32(16, true),
-          bytesSent: view.getBigUint64(24, true),
-          bytesReceived: view.getBigUint64(32, true),
-          p50Latency: view.getFloat32(40, true),
-          p95Latency: view.getFloat32(44, true),
-          successRate: view.getFloat32(48, true),
-          fetchSuccessRate: view.getFloat32(52, true),
-          localChunks: view.getUint32(56, true),
-          totalChunks: view.getUint32(60, true),
-          sectorId: view.getUint32(20, true),
-          meshActive: true,
-        });
+        const record = decodeMeshMetrics(view);
+        if (!record) return;
+
+        setMetrics({ ...record, meshActive: true });
       } catch {
         // SAB not ready or out of bounds
       }
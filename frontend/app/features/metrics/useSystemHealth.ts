@@ -0,0 +1,38 @@
+import { useEffect, useState } from 'react';
+import { IDX_HEALTH_EPOCH } from '../../../src/wasm/layout';
+import { INOSBridge } from '../../../src/wasm/bridge-state';
+import { readHealthBlock, type SystemHealth } from '../../../src/wasm/health-block';
+
+/**
+ * useSystemHealth - Per-Subsystem Health Hook
+ *
+ * Reads the mesh, gossip, DHT, transport, economy and supervisor sections of
+ * the SAB health block whenever the kernel bumps IDX_HEALTH_EPOCH.
+ */
+export function useSystemHealth() {
+  const [health, setHealth] = useState<SystemHealth | null>(null);
+
+  useEffect(() => {
+    let lastEpoch = -1;
+
+    const interval = setInterval(() => {
+      try {
+        if (!INOSBridge.isReady()) return;
+
+        const currentEpoch = INOSBridge.atomicLoad(IDX_HEALTH_EPOCH);
+        if (currentEpoch === lastEpoch) return;
+
+        const next = readHealthBlock();
+        if (!next) return; // Retry on the next tick
+        lastEpoch = currentEpoch;
+        setHealth(next);
+      } catch {
+        // SAB not ready or out of bounds
+      }
+    }, 500);
+
+    return () => clearInterval(interval);
+  }, []);
+
+  return health;
+}
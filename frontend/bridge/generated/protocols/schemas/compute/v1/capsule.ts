@@ -6,7 +6,7 @@ export class Compute_JobRequest extends $.Struct {
   static readonly _capnp = {
     displayName: "JobRequest",
     id: "c3a67580d014f041",
-    size: new $.ObjectSize(24, 6),
+    size: new $.ObjectSize(40, 6),
   };
   /**
 * Unique job identifier
@@ -134,6 +134,26 @@ export class Compute_JobRequest extends $.Struct {
   set metadata(value: Base_Metadata) {
     $.utils.copyFrom(value, $.utils.getPointer(5, this));
   }
+  /**
+* Work budget for the job; 0 = unit default
+*
+*/
+  get fuelLimit(): bigint {
+    return $.utils.getUint64(24, this);
+  }
+  set fuelLimit(value: bigint) {
+    $.utils.setUint64(24, value, this);
+  }
+  /**
+* Memory the job may grow (bytes); 0 = unit default
+*
+*/
+  get memoryLimit(): bigint {
+    return $.utils.getUint64(32, this);
+  }
+  set memoryLimit(value: bigint) {
+    $.utils.setUint64(32, value, this);
+  }
   toString(): string { return "Compute_JobRequest_" + super.toString(); }
 }
 export const Compute_JobParams_Which = {
@@ -72,6 +72,21 @@ export const OFFSET_BLOOM_FILTER = 0x001940 as const;
 /** 256 bytes */
 export const SIZE_BLOOM_FILTER = 256 as const;
 
+/** Magic, version, SAB size, region table */
+export const OFFSET_LAYOUT_HEADER = 0x001C00 as const;
+
+/** 1KB */
+export const SIZE_LAYOUT_HEADER = 1024 as const;
+
+/** "INOS" little-endian */
+export const LAYOUT_MAGIC = 0x534F4E49 as const;
+
+/** Major 2, minor 3 (major must match) */
+export const LAYOUT_VERSION = 0x020003 as const;
+
+/** 20-byte name + offset u32 + size u32 */
+export const LAYOUT_REGION_ENTRY_SIZE = 28 as const;
+
 /** Supervisor state headers */
 export const OFFSET_SUPERVISOR_HEADERS = 0x002000 as const;
 
@@ -99,6 +114,57 @@ export const OFFSET_MESH_METRICS = 0x004000 as const;
 /** 256 bytes */
 export const SIZE_MESH_METRICS = 256 as const;
 
+/** u32 peers known */
+export const MESH_METRICS_TOTAL_PEERS = 0x00 as const;
+
+/** u32 peers connected */
+export const MESH_METRICS_CONNECTED_PEERS = 0x04 as const;
+
+/** u32 DHT entries */
+export const MESH_METRICS_DHT_ENTRIES = 0x08 as const;
+
+/** f32 gossip messages per second */
+export const MESH_METRICS_GOSSIP_RATE = 0x0C as const;
+
+/** f32 mean peer reputation */
+export const MESH_METRICS_AVG_REPUTATION = 0x10 as const;
+
+/** u32 CRC32 of the region name */
+export const MESH_METRICS_REGION_ID = 0x14 as const;
+
+/** u64 */
+export const MESH_METRICS_BYTES_SENT = 0x18 as const;
+
+/** u64 */
+export const MESH_METRICS_BYTES_RECEIVED = 0x20 as const;
+
+/** f32 ms */
+export const MESH_METRICS_P50_LATENCY = 0x28 as const;
+
+/** f32 ms */
+export const MESH_METRICS_P95_LATENCY = 0x2C as const;
+
+/** f32 ratio */
+export const MESH_METRICS_CONNECTION_SUCCESS = 0x30 as const;
+
+/** f32 chunk fetch ratio */
+export const MESH_METRICS_FETCH_SUCCESS = 0x34 as const;
+
+/** u32 */
+export const MESH_METRICS_LOCAL_CHUNKS = 0x38 as const;
+
+/** u32 chunks available in the mesh */
+export const MESH_METRICS_TOTAL_CHUNKS = 0x3C as const;
+
+/** u32 */
+export const MESH_METRICS_SECTOR_ID = 0x40 as const;
+
+/** u32 including this node */
+export const MESH_METRICS_SECTOR_MEMBERS = 0x44 as const;
+
+/** 72 bytes in use */
+export const MESH_METRICS_RECORD_SIZE = 0x48 as const;
+
 /** Aggregated mesh metrics */
 export const OFFSET_GLOBAL_ANALYTICS = 0x004100 as const;
 
@@ -138,7 +204,7 @@ export const MAX_PATTERNS_INLINE = 1024 as const;
 /** Total with arena overflow */
 export const MAX_PATTERNS_TOTAL = 0x004000 as const;
 
-/** Job execution history (circular buffer) */
+/** In-flight job journal (supervisor failover) */
 export const OFFSET_JOB_HISTORY = 0x020000 as const;
 
 /** 128KB */
@@ -162,6 +228,33 @@ export const OFFSET_INBOX_BASE = 0x050000 as const;
 /** 512KB */
 export const SIZE_INBOX_TOTAL = 0x080000 as const;
 
+/** Cancels, signals, lifecycle */
+export const OFFSET_INBOX_CONTROL = 0x050000 as const;
+
+/** 64KB */
+export const SIZE_INBOX_CONTROL = 0x010000 as const;
+
+/** Ordinary jobs */
+export const OFFSET_INBOX_COMPUTE = 0x060000 as const;
+
+/** 256KB */
+export const SIZE_INBOX_COMPUTE = 0x040000 as const;
+
+/** Particle and stream updates */
+export const OFFSET_INBOX_BULK = 0x0A0000 as const;
+
+/** 192KB */
+export const SIZE_INBOX_BULK = 0x030000 as const;
+
+/** Messages per lane per drain round */
+export const INBOX_WEIGHT_CONTROL = 8 as const;
+
+/** inboxWeightCompute */
+export const INBOX_WEIGHT_COMPUTE = 4 as const;
+
+/** inboxWeightBulk */
+export const INBOX_WEIGHT_BULK = 1 as const;
+
 /** Outbox for Kernel -> Host (Results) */
 export const OFFSET_OUTBOX_HOST_BASE = 0x0D0000 as const;
 
@@ -195,6 +288,243 @@ export const OFFSET_BRIDGE_METRICS = 0x150800 as const;
 /** 256 bytes */
 export const SIZE_BRIDGE_METRICS = 256 as const;
 
+/** Sequence, frame start, idle start, deadline */
+export const OFFSET_FRAME_SCHEDULE = 0x150900 as const;
+
+/** 64 bytes */
+export const SIZE_FRAME_SCHEDULE = 0x000040 as const;
+
+/** Sequence, version, per-subsystem sections */
+export const OFFSET_HEALTH_BLOCK = 0x150A00 as const;
+
+/** 1KB */
+export const SIZE_HEALTH_BLOCK = 0x000400 as const;
+
+/** Encoding this layout describes */
+export const HEALTH_BLOCK_VERSION = 1 as const;
+
+/** u32 odd while a write is in progress */
+export const HEALTH_HEADER_SEQUENCE = 0x00 as const;
+
+/** u32 healthBlockVersion of the writer */
+export const HEALTH_HEADER_VERSION = 0x04 as const;
+
+/** u32 sections that follow */
+export const HEALTH_HEADER_SECTION_COUNT = 0x08 as const;
+
+/** u32 healthSectionSize of the writer */
+export const HEALTH_HEADER_SECTION_SIZE = 0x0C as const;
+
+/** f64 Unix milliseconds */
+export const HEALTH_HEADER_WRITTEN_AT = 0x10 as const;
+
+/** 32 bytes, sections start here */
+export const HEALTH_HEADER_SIZE = 0x20 as const;
+
+/** 96 bytes per section */
+export const HEALTH_SECTION_SIZE = 0x60 as const;
+
+/** Sections this layout defines */
+export const HEALTH_SECTION_COUNT = 6 as const;
+
+/** Section index */
+export const HEALTH_SECTION_MESH = 0 as const;
+
+/** Section index */
+export const HEALTH_SECTION_GOSSIP = 1 as const;
+
+/** Section index */
+export const HEALTH_SECTION_DHT = 2 as const;
+
+/** Section index */
+export const HEALTH_SECTION_TRANSPORT = 3 as const;
+
+/** Section index */
+export const HEALTH_SECTION_ECONOMY = 4 as const;
+
+/** Section index */
+export const HEALTH_SECTION_SUPERVISOR = 5 as const;
+
+/** u32 unknown, ok, degraded, down */
+export const HEALTH_SECTION_STATUS = 0x00 as const;
+
+/** f32 0-1 */
+export const HEALTH_SECTION_SCORE = 0x04 as const;
+
+/** u32 */
+export const HEALTH_MESH_TOTAL_PEERS = 0x08 as const;
+
+/** u32 */
+export const HEALTH_MESH_CONNECTED_PEERS = 0x0C as const;
+
+/** u32 */
+export const HEALTH_MESH_LOCAL_CHUNKS = 0x10 as const;
+
+/** u32 */
+export const HEALTH_MESH_CHUNKS_AVAILABLE = 0x14 as const;
+
+/** f32 */
+export const HEALTH_MESH_AVG_REPUTATION = 0x18 as const;
+
+/** u32 */
+export const HEALTH_MESH_SECTOR_ID = 0x1C as const;
+
+/** u32 including this node */
+export const HEALTH_MESH_SECTOR_MEMBERS = 0x20 as const;
+
+/** u32 CRC32 of the region name */
+export const HEALTH_MESH_REGION_ID = 0x24 as const;
+
+/** f32 chunk fetch ratio */
+export const HEALTH_MESH_FETCH_SUCCESS = 0x28 as const;
+
+/** u32 1 while shedding load */
+export const HEALTH_MESH_BUSY = 0x2C as const;
+
+/** u64 bytes held for peers */
+export const HEALTH_MESH_INBOUND_HELD_BYTES = 0x30 as const;
+
+/** u64 */
+export const HEALTH_GOSSIP_SENT = 0x08 as const;
+
+/** u64 */
+export const HEALTH_GOSSIP_RECEIVED = 0x10 as const;
+
+/** u64 */
+export const HEALTH_GOSSIP_DROPPED = 0x18 as const;
+
+/** u64 */
+export const HEALTH_GOSSIP_RATE_LIMITED = 0x20 as const;
+
+/** u64 */
+export const HEALTH_GOSSIP_FAILED_SIGNATURES = 0x28 as const;
+
+/** u32 */
+export const HEALTH_GOSSIP_QUEUE_LENGTH = 0x30 as const;
+
+/** u32 */
+export const HEALTH_GOSSIP_FANOUT = 0x34 as const;
+
+/** f32 messages per second */
+export const HEALTH_GOSSIP_MESSAGE_RATE = 0x38 as const;
+
+/** f32 */
+export const HEALTH_GOSSIP_DELIVERY_RATIO = 0x3C as const;
+
+/** f32 */
+export const HEALTH_GOSSIP_DUPLICATE_RATIO = 0x40 as const;
+
+/** f32 ms */
+export const HEALTH_GOSSIP_PROPAGATION_P95 = 0x44 as const;
+
+/** u32 estimated nodes */
+export const HEALTH_GOSSIP_NETWORK_SIZE = 0x48 as const;
+
+/** u32 */
+export const HEALTH_DHT_ENTRIES = 0x08 as const;
+
+/** u32 */
+export const HEALTH_DHT_PEERS = 0x0C as const;
+
+/** u32 estimated nodes */
+export const HEALTH_DHT_NETWORK_SIZE = 0x10 as const;
+
+/** f32 ratio */
+export const HEALTH_DHT_LOOKUP_SUCCESS = 0x14 as const;
+
+/** u32 ms */
+export const HEALTH_DHT_LOOKUP_P95 = 0x18 as const;
+
+/** u64 */
+export const HEALTH_DHT_QUERIES = 0x20 as const;
+
+/** u64 */
+export const HEALTH_DHT_FAILED_QUERIES = 0x28 as const;
+
+/** u64 */
+export const HEALTH_DHT_REJECTED_NODE_IDS = 0x30 as const;
+
+/** u64 */
+export const HEALTH_DHT_INVALID_NODE_REPLIES = 0x38 as const;
+
+/** u32 */
+export const HEALTH_TRANSPORT_ACTIVE_CONNECTIONS = 0x08 as const;
+
+/** u32 */
+export const HEALTH_TRANSPORT_WEBSOCKET_FALLBACKS = 0x0C as const;
+
+/** u64 */
+export const HEALTH_TRANSPORT_BYTES_SENT = 0x10 as const;
+
+/** u64 */
+export const HEALTH_TRANSPORT_BYTES_RECEIVED = 0x18 as const;
+
+/** u64 */
+export const HEALTH_TRANSPORT_MESSAGES_SENT = 0x20 as const;
+
+/** u64 */
+export const HEALTH_TRANSPORT_MESSAGES_RECEIVED = 0x28 as const;
+
+/** u64 */
+export const HEALTH_TRANSPORT_FAILED_MESSAGES = 0x30 as const;
+
+/** f32 ms */
+export const HEALTH_TRANSPORT_LATENCY_P50 = 0x38 as const;
+
+/** f32 ms */
+export const HEALTH_TRANSPORT_LATENCY_P95 = 0x3C as const;
+
+/** f32 ms */
+export const HEALTH_TRANSPORT_LATENCY_P99 = 0x40 as const;
+
+/** f32 */
+export const HEALTH_TRANSPORT_ERROR_RATE = 0x44 as const;
+
+/** u64 */
+export const HEALTH_TRANSPORT_TOTAL_CONNECTIONS = 0x48 as const;
+
+/** i64 own balance */
+export const HEALTH_ECONOMY_BALANCE = 0x08 as const;
+
+/** u64 */
+export const HEALTH_ECONOMY_ESCROWED = 0x10 as const;
+
+/** u64 */
+export const HEALTH_ECONOMY_SETTLED = 0x18 as const;
+
+/** u64 */
+export const HEALTH_ECONOMY_REFUNDED = 0x20 as const;
+
+/** u64 */
+export const HEALTH_ECONOMY_SLASHED = 0x28 as const;
+
+/** u32 */
+export const HEALTH_ECONOMY_ACTIVE_ESCROWS = 0x30 as const;
+
+/** u32 */
+export const HEALTH_ECONOMY_ACCOUNTS = 0x34 as const;
+
+/** u64 */
+export const HEALTH_ECONOMY_SETTLEMENTS = 0x38 as const;
+
+/** u64 */
+export const HEALTH_ECONOMY_JOBS_EXECUTED = 0x40 as const;
+
+/** u64 */
+export const HEALTH_ECONOMY_JOBS_FAILED = 0x48 as const;
+
+/** u32 */
+export const HEALTH_SUPERVISOR_ACTIVE_THREADS = 0x08 as const;
+
+/** u32 */
+export const HEALTH_SUPERVISOR_FAILED_THREADS = 0x0C as const;
+
+/** u32 */
+export const HEALTH_SUPERVISOR_RESTARTED_THREADS = 0x10 as const;
+
+/** u64 */
+export const HEALTH_SUPERVISOR_MESSAGES = 0x18 as const;
+
 /** Async allocation requests */
 export const OFFSET_ARENA_REQUEST_QUEUE = 0x151000 as const;
 
@@ -231,6 +561,15 @@ export const OFFSET_PINGPONG_CONTROL = 0x161000 as const;
 /** 64 bytes */
 export const SIZE_PINGPONG_CONTROL = 64 as const;
 
+/** Magic, sequence, length, report JSON */
+export const OFFSET_CRASH_DUMP = 0x161800 as const;
+
+/** 2KB */
+export const SIZE_CRASH_DUMP = 0x000800 as const;
+
+/** "CRSH" little-endian */
+export const CRASH_DUMP_MAGIC = 0x48535243 as const;
+
 /** offsetBirdBufferA */
 export const OFFSET_BIRD_BUFFER_A = 0x162000 as const;
 
@@ -255,6 +594,15 @@ export const SIZE_MATRIX_BUFFER = 0x4E2000 as const;
 /** matrixStride */
 export const MATRIX_STRIDE = 64 as const;
 
+/** Page after MatrixBufferB */
+export const OFFSET_DYNAMIC_REGISTRY = 0x1004000 as const;
+
+/** 4KB */
+export const SIZE_DYNAMIC_REGISTRY = 0x001000 as const;
+
+/** 20-byte name + offset + size + flags */
+export const DYNAMIC_REGION_ENTRY_SIZE = 32 as const;
+
 /** Kernel boot complete */
 export const IDX_KERNEL_READY = 0 as const;
 
@@ -417,6 +765,11 @@ export const CONSTS = {
   MAX_MODULES_TOTAL,
   OFFSET_BLOOM_FILTER,
   SIZE_BLOOM_FILTER,
+  OFFSET_LAYOUT_HEADER,
+  SIZE_LAYOUT_HEADER,
+  LAYOUT_MAGIC,
+  LAYOUT_VERSION,
+  LAYOUT_REGION_ENTRY_SIZE,
   OFFSET_SUPERVISOR_HEADERS,
   SIZE_SUPERVISOR_HEADERS,
   SUPERVISOR_HEADER_SIZE,
@@ -426,6 +779,23 @@ export const CONSTS = {
   SIZE_SYSCALL_TABLE,
   OFFSET_MESH_METRICS,
   SIZE_MESH_METRICS,
+  MESH_METRICS_TOTAL_PEERS,
+  MESH_METRICS_CONNECTED_PEERS,
+  MESH_METRICS_DHT_ENTRIES,
+  MESH_METRICS_GOSSIP_RATE,
+  MESH_METRICS_AVG_REPUTATION,
+  MESH_METRICS_REGION_ID,
+  MESH_METRICS_BYTES_SENT,
+  MESH_METRICS_BYTES_RECEIVED,
+  MESH_METRICS_P50_LATENCY,
+  MESH_METRICS_P95_LATENCY,
+  MESH_METRICS_CONNECTION_SUCCESS,
+  MESH_METRICS_FETCH_SUCCESS,
+  MESH_METRICS_LOCAL_CHUNKS,
+  MESH_METRICS_TOTAL_CHUNKS,
+  MESH_METRICS_SECTOR_ID,
+  MESH_METRICS_SECTOR_MEMBERS,
+  MESH_METRICS_RECORD_SIZE,
   OFFSET_GLOBAL_ANALYTICS,
   SIZE_GLOBAL_ANALYTICS,
   OFFSET_ECONOMICS,
@@ -447,6 +817,15 @@ export const CONSTS = {
   SIZE_INBOX_OUTBOX,
   OFFSET_INBOX_BASE,
   SIZE_INBOX_TOTAL,
+  OFFSET_INBOX_CONTROL,
+  SIZE_INBOX_CONTROL,
+  OFFSET_INBOX_COMPUTE,
+  SIZE_INBOX_COMPUTE,
+  OFFSET_INBOX_BULK,
+  SIZE_INBOX_BULK,
+  INBOX_WEIGHT_CONTROL,
+  INBOX_WEIGHT_COMPUTE,
+  INBOX_WEIGHT_BULK,
   OFFSET_OUTBOX_HOST_BASE,
   SIZE_OUTBOX_HOST_TOTAL,
   OFFSET_OUTBOX_KERNEL_BASE,
@@ -458,6 +837,85 @@ export const CONSTS = {
   SIZE_DIAGNOSTICS,
   OFFSET_BRIDGE_METRICS,
   SIZE_BRIDGE_METRICS,
+  OFFSET_FRAME_SCHEDULE,
+  SIZE_FRAME_SCHEDULE,
+  OFFSET_HEALTH_BLOCK,
+  SIZE_HEALTH_BLOCK,
+  HEALTH_BLOCK_VERSION,
+  HEALTH_HEADER_SEQUENCE,
+  HEALTH_HEADER_VERSION,
+  HEALTH_HEADER_SECTION_COUNT,
+  HEALTH_HEADER_SECTION_SIZE,
+  HEALTH_HEADER_WRITTEN_AT,
+  HEALTH_HEADER_SIZE,
+  HEALTH_SECTION_SIZE,
+  HEALTH_SECTION_COUNT,
+  HEALTH_SECTION_MESH,
+  HEALTH_SECTION_GOSSIP,
+  HEALTH_SECTION_DHT,
+  HEALTH_SECTION_TRANSPORT,
+  HEALTH_SECTION_ECONOMY,
+  HEALTH_SECTION_SUPERVISOR,
+  HEALTH_SECTION_STATUS,
+  HEALTH_SECTION_SCORE,
+  HEALTH_MESH_TOTAL_PEERS,
+  HEALTH_MESH_CONNECTED_PEERS,
+  HEALTH_MESH_LOCAL_CHUNKS,
+  HEALTH_MESH_CHUNKS_AVAILABLE,
+  HEALTH_MESH_AVG_REPUTATION,
+  HEALTH_MESH_SECTOR_ID,
+  HEALTH_MESH_SECTOR_MEMBERS,
+  HEALTH_MESH_REGION_ID,
+  HEALTH_MESH_FETCH_SUCCESS,
+  HEALTH_MESH_BUSY,
+  HEALTH_MESH_INBOUND_HELD_BYTES,
+  HEALTH_GOSSIP_SENT,
+  HEALTH_GOSSIP_RECEIVED,
+  HEALTH_GOSSIP_DROPPED,
+  HEALTH_GOSSIP_RATE_LIMITED,
+  HEALTH_GOSSIP_FAILED_SIGNATURES,
+  HEALTH_GOSSIP_QUEUE_LENGTH,
+  HEALTH_GOSSIP_FANOUT,
+  HEALTH_GOSSIP_MESSAGE_RATE,
+  HEALTH_GOSSIP_DELIVERY_RATIO,
+  HEALTH_GOSSIP_DUPLICATE_RATIO,
+  HEALTH_GOSSIP_PROPAGATION_P95,
+  HEALTH_GOSSIP_NETWORK_SIZE,
+  HEALTH_DHT_ENTRIES,
+  HEALTH_DHT_PEERS,
+  HEALTH_DHT_NETWORK_SIZE,
+  HEALTH_DHT_LOOKUP_SUCCESS,
+  HEALTH_DHT_LOOKUP_P95,
+  HEALTH_DHT_QUERIES,
+  HEALTH_DHT_FAILED_QUERIES,
+  HEALTH_DHT_REJECTED_NODE_IDS,
+  HEALTH_DHT_INVALID_NODE_REPLIES,
+  HEALTH_TRANSPORT_ACTIVE_CONNECTIONS,
+  HEALTH_TRANSPORT_WEBSOCKET_FALLBACKS,
+  HEALTH_TRANSPORT_BYTES_SENT,
+  HEALTH_TRANSPORT_BYTES_RECEIVED,
+  HEALTH_TRANSPORT_MESSAGES_SENT,
+  HEALTH_TRANSPORT_MESSAGES_RECEIVED,
+  HEALTH_TRANSPORT_FAILED_MESSAGES,
+  HEALTH_TRANSPORT_LATENCY_P50,
+  HEALTH_TRANSPORT_LATENCY_P95,
+  HEALTH_TRANSPORT_LATENCY_P99,
+  HEALTH_TRANSPORT_ERROR_RATE,
+  HEALTH_TRANSPORT_TOTAL_CONNECTIONS,
+  HEALTH_ECONOMY_BALANCE,
+  HEALTH_ECONOMY_ESCROWED,
+  HEALTH_ECONOMY_SETTLED,
+  HEALTH_ECONOMY_REFUNDED,
+  HEALTH_ECONOMY_SLASHED,
+  HEALTH_ECONOMY_ACTIVE_ESCROWS,
+  HEALTH_ECONOMY_ACCOUNTS,
+  HEALTH_ECONOMY_SETTLEMENTS,
+  HEALTH_ECONOMY_JOBS_EXECUTED,
+  HEALTH_ECONOMY_JOBS_FAILED,
+  HEALTH_SUPERVISOR_ACTIVE_THREADS,
+  HEALTH_SUPERVISOR_FAILED_THREADS,
+  HEALTH_SUPERVISOR_RESTARTED_THREADS,
+  HEALTH_SUPERVISOR_MESSAGES,
   OFFSET_ARENA_REQUEST_QUEUE,
   OFFSET_ARENA_RESPONSE_QUEUE,
   ARENA_QUEUE_ENTRY_SIZE,
@@ -470,6 +928,9 @@ export const CONSTS = {
   SIZE_BIRD_STATE,
   OFFSET_PINGPONG_CONTROL,
   SIZE_PINGPONG_CONTROL,
+  OFFSET_CRASH_DUMP,
+  SIZE_CRASH_DUMP,
+  CRASH_DUMP_MAGIC,
   OFFSET_BIRD_BUFFER_A,
   OFFSET_BIRD_BUFFER_B,
   SIZE_BIRD_BUFFER,
@@ -478,6 +939,9 @@ export const CONSTS = {
   OFFSET_MATRIX_BUFFER_B,
   SIZE_MATRIX_BUFFER,
   MATRIX_STRIDE,
+  OFFSET_DYNAMIC_REGISTRY,
+  SIZE_DYNAMIC_REGISTRY,
+  DYNAMIC_REGION_ENTRY_SIZE,
   IDX_KERNEL_READY,
   IDX_INBOX_DIRTY,
   IDX_OUTBOX_HOST_DIRTY,
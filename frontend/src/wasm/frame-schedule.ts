@@ -0,0 +1,108 @@
+import { getDataView, getOffset } from './bridge-state';
+import { OFFSET_FRAME_SCHEDULE } from './layout';
+
+/**
+ * Frame Schedule - reports requestAnimationFrame timing into the SAB so the
+ * kernel can run deferrable mesh work (anti-entropy, cache cleanup, metric
+ * packing) in the idle time between frames.
+ *
+ * Layout at OFFSET_FRAME_SCHEDULE (little-endian, times are f64 Unix ms):
+ *   0x00 sequence    u32  frames reported
+ *   0x08 frame start f64
+ *   0x10 idle start  f64  0 until the frame's work is done
+ *   0x18 deadline    f64  when the next frame is due
+ *
+ * Times are written before the sequence. While the page is hidden rAF stops,
+ * the report goes stale and the kernel stops waiting for windows.
+ */
+
+const SEQUENCE = 0;
+const FRAME_START = 8;
+const IDLE_START = 16;
+const DEADLINE = 24;
+
+// Frame interval estimate, smoothed; starts at 60 fps
+const DEFAULT_INTERVAL_MS = 1000 / 60;
+const MAX_INTERVAL_MS = 100;
+const SMOOTHING = 0.1;
+
+let rafId: number | null = null;
+let idleId: number | null = null;
+let sequence = 0;
+let lastFrame = 0;
+let interval = DEFAULT_INTERVAL_MS;
+
+const idleChannel = typeof MessageChannel !== 'undefined' ? new MessageChannel() : null;
+
+function wallClock(highRes: number): number {
+  return performance.timeOrigin + highRes;
+}
+
+function write(field: number, value: number) {
+  const view = getDataView();
+  if (!view) return;
+  view.setFloat64(getOffset() + OFFSET_FRAME_SCHEDULE + field, value, true);
+}
+
+function markIdle(deadline: number) {
+  const now = performance.now();
+  if (deadline <= now) return;
+  write(DEADLINE, wallClock(deadline));
+  write(IDLE_START, wallClock(now));
+}
+
+function onFrame(timestamp: number) {
+  const view = getDataView();
+  if (view) {
+    if (lastFrame > 0) {
+      const delta = timestamp - lastFrame;
+      if (delta > 0 && delta < MAX_INTERVAL_MS) {
+        interval += SMOOTHING * (delta - interval);
+      }
+    }
+    lastFrame = timestamp;
+    const nextFrame = timestamp + interval;
+
+    // Window closed until this frame's work is done
+    write(FRAME_START, wallClock(timestamp));
+    write(IDLE_START, 0);
+    write(DEADLINE, wallClock(nextFrame));
+    sequence = (sequence + 1) >>> 0;
+    view.setUint32(getOffset() + OFFSET_FRAME_SCHEDULE + SEQUENCE, sequence, true);
+
+    if (typeof requestIdleCallback === 'function') {
+      if (idleId === null) {
+        idleId = requestIdleCallback(
+          deadline => {
+            idleId = null;
+            markIdle(performance.now() + deadline.timeRemaining());
+          },
+          { timeout: interval }
+        );
+      }
+    } else if (idleChannel) {
+      // No idle callbacks (Safari): a task posted now runs after the frame renders
+      idleChannel.port1.onmessage = () => markIdle(nextFrame);
+      idleChannel.port2.postMessage(null);
+    }
+  }
+  rafId = requestAnimationFrame(onFrame);
+}
+
+export function startFrameSchedule() {
+  if (rafId !== null || typeof requestAnimationFrame !== 'function') return;
+  lastFrame = 0;
+  interval = DEFAULT_INTERVAL_MS;
+  rafId = requestAnimationFrame(onFrame);
+}
+
+export function stopFrameSchedule() {
+  if (rafId !== null) {
+    cancelAnimationFrame(rafId);
+    rafId = null;
+  }
+  if (idleId !== null && typeof cancelIdleCallback === 'function') {
+    cancelIdleCallback(idleId);
+    idleId = null;
+  }
+}
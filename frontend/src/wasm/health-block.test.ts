@@ -0,0 +1,110 @@
+import { describe, it, expect } from 'vitest';
+import golden from '../../../kernel/threads/sab/testdata/health_block.hex?raw';
+import { decodeHealthBlock } from './health-block';
+
+// The kernel's encoder is checked against the same block in
+// kernel/threads/sab/health_block_test.go.
+function fromHex(hex: string): DataView {
+  const clean = hex.trim();
+  const bytes = new Uint8Array(clean.length / 2);
+  for (let i = 0; i < bytes.length; i++) {
+    bytes[i] = parseInt(clean.slice(i * 2, i * 2 + 2), 16);
+  }
+  return new DataView(bytes.buffer);
+}
+
+describe('decodeHealthBlock', () => {
+  it('reads the block the kernel writes', () => {
+    expect(decodeHealthBlock(fromHex(golden), 42)).toEqual({
+      sequence: 42,
+      version: 1,
+      writtenAt: 1_700_000_000_123,
+      mesh: {
+        status: 'ok',
+        score: 0.75,
+        totalPeers: 40,
+        connectedPeers: 12,
+        localChunks: 300,
+        chunksAvailable: 4500,
+        avgReputation: 0.625,
+        sectorId: 7,
+        sectorMembers: 5,
+        regionId: 0xdeadbeef,
+        fetchSuccessRate: 0.875,
+        busy: true,
+        inboundHeldBytes: 1n << 33n,
+      },
+      gossip: {
+        status: 'degraded',
+        score: 0.5,
+        messagesSent: 1001n,
+        messagesReceived: 2002n,
+        messagesDropped: 3n,
+        rateLimited: 4n,
+        failedSignatures: 5n,
+        queueLength: 6,
+        fanout: 8,
+        messageRate: 12.5,
+        deliveryRatio: 0.96875,
+        duplicateRatio: 0.125,
+        propagationP95Ms: 48.5,
+        networkSizeEstimate: 1024,
+      },
+      dht: {
+        status: 'ok',
+        score: 1,
+        entries: 256,
+        peers: 20,
+        networkSizeEstimate: 1000,
+        lookupSuccessRate: 0.9375,
+        lookupP95Ms: 180,
+        queries: 5000n,
+        failedQueries: 17n,
+        rejectedNodeIds: 2n,
+        invalidNodeReplies: 9n,
+      },
+      transport: {
+        status: 'down',
+        score: 0.25,
+        activeConnections: 11,
+        websocketFallbacks: 2,
+        bytesSent: 0x0102030405060708n,
+        bytesReceived: 1n << 40n,
+        messagesSent: 7000n,
+        messagesReceived: 6999n,
+        failedMessages: 13n,
+        latencyP50Ms: 3.25,
+        latencyP95Ms: 20.5,
+        latencyP99Ms: 120.5,
+        errorRate: 0.0625,
+        totalConnections: 77n,
+      },
+      economy: {
+        status: 'ok',
+        score: 1,
+        balance: -1500n,
+        escrowed: 200n,
+        settled: 3000n,
+        refunded: 40n,
+        slashed: 5n,
+        activeEscrows: 3,
+        accounts: 9,
+        settlements: 60n,
+        jobsExecuted: 70n,
+        jobsFailed: 8n,
+      },
+      supervisor: {
+        status: 'degraded',
+        score: 0.5,
+        activeThreads: 6,
+        failedThreads: 1,
+        restartedThreads: 2,
+        messages: 123456n,
+      },
+    });
+  });
+
+  it('rejects a truncated block', () => {
+    expect(decodeHealthBlock(new DataView(new ArrayBuffer(32)), 2)).toBeNull();
+  });
+});
@@ -0,0 +1,307 @@
+import { getRegionDataView } from './bridge-state';
+import {
+  HEALTH_DHT_ENTRIES,
+  HEALTH_DHT_FAILED_QUERIES,
+  HEALTH_DHT_INVALID_NODE_REPLIES,
+  HEALTH_DHT_LOOKUP_P95,
+  HEALTH_DHT_LOOKUP_SUCCESS,
+  HEALTH_DHT_NETWORK_SIZE,
+  HEALTH_DHT_PEERS,
+  HEALTH_DHT_QUERIES,
+  HEALTH_DHT_REJECTED_NODE_IDS,
+  HEALTH_ECONOMY_ACCOUNTS,
+  HEALTH_ECONOMY_ACTIVE_ESCROWS,
+  HEALTH_ECONOMY_BALANCE,
+  HEALTH_ECONOMY_ESCROWED,
+  HEALTH_ECONOMY_JOBS_EXECUTED,
+  HEALTH_ECONOMY_JOBS_FAILED,
+  HEALTH_ECONOMY_REFUNDED,
+  HEALTH_ECONOMY_SETTLED,
+  HEALTH_ECONOMY_SETTLEMENTS,
+  HEALTH_ECONOMY_SLASHED,
+  HEALTH_GOSSIP_DELIVERY_RATIO,
+  HEALTH_GOSSIP_DROPPED,
+  HEALTH_GOSSIP_DUPLICATE_RATIO,
+  HEALTH_GOSSIP_FAILED_SIGNATURES,
+  HEALTH_GOSSIP_FANOUT,
+  HEALTH_GOSSIP_MESSAGE_RATE,
+  HEALTH_GOSSIP_NETWORK_SIZE,
+  HEALTH_GOSSIP_PROPAGATION_P95,
+  HEALTH_GOSSIP_QUEUE_LENGTH,
+  HEALTH_GOSSIP_RATE_LIMITED,
+  HEALTH_GOSSIP_RECEIVED,
+  HEALTH_GOSSIP_SENT,
+  HEALTH_HEADER_SECTION_COUNT,
+  HEALTH_HEADER_SECTION_SIZE,
+  HEALTH_HEADER_SEQUENCE,
+  HEALTH_HEADER_SIZE,
+  HEALTH_HEADER_VERSION,
+  HEALTH_HEADER_WRITTEN_AT,
+  HEALTH_MESH_AVG_REPUTATION,
+  HEALTH_MESH_BUSY,
+  HEALTH_MESH_CHUNKS_AVAILABLE,
+  HEALTH_MESH_CONNECTED_PEERS,
+  HEALTH_MESH_FETCH_SUCCESS,
+  HEALTH_MESH_INBOUND_HELD_BYTES,
+  HEALTH_MESH_LOCAL_CHUNKS,
+  HEALTH_MESH_REGION_ID,
+  HEALTH_MESH_SECTOR_ID,
+  HEALTH_MESH_SECTOR_MEMBERS,
+  HEALTH_MESH_TOTAL_PEERS,
+  HEALTH_SECTION_COUNT,
+  HEALTH_SECTION_DHT,
+  HEALTH_SECTION_ECONOMY,
+  HEALTH_SECTION_GOSSIP,
+  HEALTH_SECTION_MESH,
+  HEALTH_SECTION_SCORE,
+  HEALTH_SECTION_SIZE,
+  HEALTH_SECTION_STATUS,
+  HEALTH_SECTION_SUPERVISOR,
+  HEALTH_SECTION_TRANSPORT,
+  HEALTH_SUPERVISOR_ACTIVE_THREADS,
+  HEALTH_SUPERVISOR_FAILED_THREADS,
+  HEALTH_SUPERVISOR_MESSAGES,
+  HEALTH_SUPERVISOR_RESTARTED_THREADS,
+  HEALTH_TRANSPORT_ACTIVE_CONNECTIONS,
+  HEALTH_TRANSPORT_BYTES_RECEIVED,
+  HEALTH_TRANSPORT_BYTES_SENT,
+  HEALTH_TRANSPORT_ERROR_RATE,
+  HEALTH_TRANSPORT_FAILED_MESSAGES,
+  HEALTH_TRANSPORT_LATENCY_P50,
+  HEALTH_TRANSPORT_LATENCY_P95,
+  HEALTH_TRANSPORT_LATENCY_P99,
+  HEALTH_TRANSPORT_MESSAGES_RECEIVED,
+  HEALTH_TRANSPORT_MESSAGES_SENT,
+  HEALTH_TRANSPORT_TOTAL_CONNECTIONS,
+  HEALTH_TRANSPORT_WEBSOCKET_FALLBACKS,
+  OFFSET_HEALTH_BLOCK,
+  SIZE_HEALTH_BLOCK,
+} from './layout';
+
+/**
+ * Health Block - per-subsystem health the kernel packs into the SAB, so the
+ * UI can render a health dashboard without calling into the kernel.
+ *
+ * Field offsets come from the HEALTH_* layout constants; values are
+ * little-endian with IEEE 754 floats. HEALTH_SECTION_COUNT sections follow
+ * the header, each starting with status u32 and score f32. The block is a
+ * seqlock: a read is kept only if the sequence was even and unchanged
+ * across it.
+ * Mirrors kernel/threads/sab/health_block.go.
+ */
+
+export const HEALTH_STATUS = ['unknown', 'ok', 'degraded', 'down'] as const;
+export type HealthStatus = (typeof HEALTH_STATUS)[number];
+
+export interface HealthSection {
+  status: HealthStatus;
+  score: number;
+}
+
+export interface SystemHealth {
+  sequence: number;
+  version: number;
+  writtenAt: number;
+  mesh: HealthSection & {
+    totalPeers: number;
+    connectedPeers: number;
+    localChunks: number;
+    chunksAvailable: number;
+    avgReputation: number;
+    sectorId: number;
+    sectorMembers: number;
+    regionId: number;
+    fetchSuccessRate: number;
+    busy: boolean;
+    inboundHeldBytes: bigint;
+  };
+  gossip: HealthSection & {
+    messagesSent: bigint;
+    messagesReceived: bigint;
+    messagesDropped: bigint;
+    rateLimited: bigint;
+    failedSignatures: bigint;
+    queueLength: number;
+    fanout: number;
+    messageRate: number;
+    deliveryRatio: number;
+    duplicateRatio: number;
+    propagationP95Ms: number;
+    networkSizeEstimate: number;
+  };
+  dht: HealthSection & {
+    entries: number;
+    peers: number;
+    networkSizeEstimate: number;
+    lookupSuccessRate: number;
+    lookupP95Ms: number;
+    queries: bigint;
+    failedQueries: bigint;
+    rejectedNodeIds: bigint;
+    invalidNodeReplies: bigint;
+  };
+  transport: HealthSection & {
+    activeConnections: number;
+    websocketFallbacks: number;
+    bytesSent: bigint;
+    bytesReceived: bigint;
+    messagesSent: bigint;
+    messagesReceived: bigint;
+    failedMessages: bigint;
+    latencyP50Ms: number;
+    latencyP95Ms: number;
+    latencyP99Ms: number;
+    errorRate: number;
+    totalConnections: bigint;
+  };
+  economy: HealthSection & {
+    balance: bigint;
+    escrowed: bigint;
+    settled: bigint;
+    refunded: bigint;
+    slashed: bigint;
+    activeEscrows: number;
+    accounts: number;
+    settlements: bigint;
+    jobsExecuted: bigint;
+    jobsFailed: bigint;
+  };
+  supervisor: HealthSection & {
+    activeThreads: number;
+    failedThreads: number;
+    restartedThreads: number;
+    messages: bigint;
+  };
+}
+
+const READ_ATTEMPTS = 8;
+
+function section(view: DataView, base: number): HealthSection {
+  return {
+    status: HEALTH_STATUS[view.getUint32(base + HEALTH_SECTION_STATUS, true)] ?? 'unknown',
+    score: view.getFloat32(base + HEALTH_SECTION_SCORE, true),
+  };
+}
+
+/**
+ * Decode a complete health block read with the given sequence, or null if it
+ * was written with another section size or fewer sections than this build
+ * knows.
+ */
+export function decodeHealthBlock(view: DataView, sequence: number): SystemHealth | null {
+  const size = HEALTH_HEADER_SIZE + HEALTH_SECTION_COUNT * HEALTH_SECTION_SIZE;
+  if (view.byteLength < size) return null;
+  if (view.getUint32(HEALTH_HEADER_SECTION_SIZE, true) !== HEALTH_SECTION_SIZE) return null;
+  if (view.getUint32(HEALTH_HEADER_SECTION_COUNT, true) < HEALTH_SECTION_COUNT) return null;
+
+  const u32 = (base: number, off: number) => view.getUint32(base + off, true);
+  const u64 = (base: number, off: number) => view.getBigUint64(base + off, true);
+  const f32 = (base: number, off: number) => view.getFloat32(base + off, true);
+  const at = (index: number) => HEALTH_HEADER_SIZE + index * HEALTH_SECTION_SIZE;
+  const mesh = at(HEALTH_SECTION_MESH);
+  const gossip = at(HEALTH_SECTION_GOSSIP);
+  const dht = at(HEALTH_SECTION_DHT);
+  const transport = at(HEALTH_SECTION_TRANSPORT);
+  const economy = at(HEALTH_SECTION_ECONOMY);
+  const supervisor = at(HEALTH_SECTION_SUPERVISOR);
+
+  return {
+    sequence,
+    version: view.getUint32(HEALTH_HEADER_VERSION, true),
+    writtenAt: view.getFloat64(HEALTH_HEADER_WRITTEN_AT, true),
+    mesh: {
+      ...section(view, mesh),
+      totalPeers: u32(mesh, HEALTH_MESH_TOTAL_PEERS),
+      connectedPeers: u32(mesh, HEALTH_MESH_CONNECTED_PEERS),
+      localChunks: u32(mesh, HEALTH_MESH_LOCAL_CHUNKS),
+      chunksAvailable: u32(mesh, HEALTH_MESH_CHUNKS_AVAILABLE),
+      avgReputation: f32(mesh, HEALTH_MESH_AVG_REPUTATION),
+      sectorId: u32(mesh, HEALTH_MESH_SECTOR_ID),
+      sectorMembers: u32(mesh, HEALTH_MESH_SECTOR_MEMBERS),
+      regionId: u32(mesh, HEALTH_MESH_REGION_ID),
+      fetchSuccessRate: f32(mesh, HEALTH_MESH_FETCH_SUCCESS),
+      busy: u32(mesh, HEALTH_MESH_BUSY) !== 0,
+      inboundHeldBytes: u64(mesh, HEALTH_MESH_INBOUND_HELD_BYTES),
+    },
+    gossip: {
+      ...section(view, gossip),
+      messagesSent: u64(gossip, HEALTH_GOSSIP_SENT),
+      messagesReceived: u64(gossip, HEALTH_GOSSIP_RECEIVED),
+      messagesDropped: u64(gossip, HEALTH_GOSSIP_DROPPED),
+      rateLimited: u64(gossip, HEALTH_GOSSIP_RATE_LIMITED),
+      failedSignatures: u64(gossip, HEALTH_GOSSIP_FAILED_SIGNATURES),
+      queueLength: u32(gossip, HEALTH_GOSSIP_QUEUE_LENGTH),
+      fanout: u32(gossip, HEALTH_GOSSIP_FANOUT),
+      messageRate: f32(gossip, HEALTH_GOSSIP_MESSAGE_RATE),
+      deliveryRatio: f32(gossip, HEALTH_GOSSIP_DELIVERY_RATIO),
+      duplicateRatio: f32(gossip, HEALTH_GOSSIP_DUPLICATE_RATIO),
+      propagationP95Ms: f32(gossip, HEALTH_GOSSIP_PROPAGATION_P95),
+      networkSizeEstimate: u32(gossip, HEALTH_GOSSIP_NETWORK_SIZE),
+    },
+    dht: {
+      ...section(view, dht),
+      entries: u32(dht, HEALTH_DHT_ENTRIES),
+      peers: u32(dht, HEALTH_DHT_PEERS),
+      networkSizeEstimate: u32(dht, HEALTH_DHT_NETWORK_SIZE),
+      lookupSuccessRate: f32(dht, HEALTH_DHT_LOOKUP_SUCCESS),
+      lookupP95Ms: u32(dht, HEALTH_DHT_LOOKUP_P95),
+      queries: u64(dht, HEALTH_DHT_QUERIES),
+      failedQueries: u64(dht, HEALTH_DHT_FAILED_QUERIES),
+      rejectedNodeIds: u64(dht, HEALTH_DHT_REJECTED_NODE_IDS),
+      invalidNodeReplies: u64(dht, HEALTH_DHT_INVALID_NODE_REPLIES),
+    },
+    transport: {
+      ...section(view, transport),
+      activeConnections: u32(transport, HEALTH_TRANSPORT_ACTIVE_CONNECTIONS),
+      websocketFallbacks: u32(transport, HEALTH_TRANSPORT_WEBSOCKET_FALLBACKS),
+      bytesSent: u64(transport, HEALTH_TRANSPORT_BYTES_SENT),
+      bytesReceived: u64(transport, HEALTH_TRANSPORT_BYTES_RECEIVED),
+      messagesSent: u64(transport, HEALTH_TRANSPORT_MESSAGES_SENT),
+      messagesReceived: u64(transport, HEALTH_TRANSPORT_MESSAGES_RECEIVED),
+      failedMessages: u64(transport, HEALTH_TRANSPORT_FAILED_MESSAGES),
+      latencyP50Ms: f32(transport, HEALTH_TRANSPORT_LATENCY_P50),
+      latencyP95Ms: f32(transport, HEALTH_TRANSPORT_LATENCY_P95),
+      latencyP99Ms: f32(transport, HEALTH_TRANSPORT_LATENCY_P99),
+      errorRate: f32(transport, HEALTH_TRANSPORT_ERROR_RATE),
+      totalConnections: u64(transport, HEALTH_TRANSPORT_TOTAL_CONNECTIONS),
+    },
+    economy: {
+      ...section(view, economy),
+      balance: view.getBigInt64(economy + HEALTH_ECONOMY_BALANCE, true),
+      escrowed: u64(economy, HEALTH_ECONOMY_ESCROWED),
+      settled: u64(economy, HEALTH_ECONOMY_SETTLED),
+      refunded: u64(economy, HEALTH_ECONOMY_REFUNDED),
+      slashed: u64(economy, HEALTH_ECONOMY_SLASHED),
+      activeEscrows: u32(economy, HEALTH_ECONOMY_ACTIVE_ESCROWS),
+      accounts: u32(economy, HEALTH_ECONOMY_ACCOUNTS),
+      settlements: u64(economy, HEALTH_ECONOMY_SETTLEMENTS),
+      jobsExecuted: u64(economy, HEALTH_ECONOMY_JOBS_EXECUTED),
+      jobsFailed: u64(economy, HEALTH_ECONOMY_JOBS_FAILED),
+    },
+    supervisor: {
+      ...section(view, supervisor),
+      activeThreads: u32(supervisor, HEALTH_SUPERVISOR_ACTIVE_THREADS),
+      failedThreads: u32(supervisor, HEALTH_SUPERVISOR_FAILED_THREADS),
+      restartedThreads: u32(supervisor, HEALTH_SUPERVISOR_RESTARTED_THREADS),
+      messages: u64(supervisor, HEALTH_SUPERVISOR_MESSAGES),
+    },
+  };
+}
+
+/**
+ * Read a consistent health block, or null if the kernel has not written one
+ * yet or kept rewriting it during every attempt.
+ */
+export function readHealthBlock(): SystemHealth | null {
+  const view = getRegionDataView(OFFSET_HEALTH_BLOCK, SIZE_HEALTH_BLOCK);
+  if (!view) return null;
+
+  for (let attempt = 0; attempt < READ_ATTEMPTS; attempt++) {
+    const before = view.getUint32(HEALTH_HEADER_SEQUENCE, true);
+    if (before === 0) return null;
+    if (before % 2 === 1) continue;
+    const health = decodeHealthBlock(view, before);
+    if (view.getUint32(HEALTH_HEADER_SEQUENCE, true) === before) return health;
+  }
+  return null;
+}
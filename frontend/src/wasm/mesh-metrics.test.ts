@@ -0,0 +1,40 @@
+import { describe, it, expect } from 'vitest';
+import golden from '../../../kernel/threads/sab/testdata/mesh_metrics.hex?raw';
+import { decodeMeshMetrics } from './mesh-metrics';
+
+// The kernel's encoder is checked against the same record in
+// kernel/threads/sab/mesh_metrics_test.go.
+function fromHex(hex: string): DataView {
+  const clean = hex.trim();
+  const bytes = new Uint8Array(clean.length / 2);
+  for (let i = 0; i < bytes.length; i++) {
+    bytes[i] = parseInt(clean.slice(i * 2, i * 2 + 2), 16);
+  }
+  return new DataView(bytes.buffer);
+}
+
+describe('decodeMeshMetrics', () => {
+  it('reads the record the kernel writes', () => {
+    expect(decodeMeshMetrics(fromHex(golden))).toEqual({
+      totalPeers: 42,
+      connectedPeers: 17,
+      dhtEntries: 256,
+      gossipRate: 12.5,
+      avgReputation: 0.75,
+      bytesSent: 0x0102030405060708n,
+      bytesReceived: 1n << 40n,
+      p50Latency: 3.25,
+      p95Latency: 48.5,
+      successRate: 0.5,
+      fetchSuccessRate: 0.875,
+      localChunks: 9,
+      totalChunks: 1234,
+      sectorId: 7,
+      sectorMembers: 5,
+    });
+  });
+
+  it('rejects a truncated record', () => {
+    expect(decodeMeshMetrics(new DataView(new ArrayBuffer(8)))).toBeNull();
+  });
+});
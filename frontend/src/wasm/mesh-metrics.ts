@@ -0,0 +1,64 @@
+import {
+  MESH_METRICS_AVG_REPUTATION,
+  MESH_METRICS_BYTES_RECEIVED,
+  MESH_METRICS_BYTES_SENT,
+  MESH_METRICS_CONNECTED_PEERS,
+  MESH_METRICS_CONNECTION_SUCCESS,
+  MESH_METRICS_DHT_ENTRIES,
+  MESH_METRICS_FETCH_SUCCESS,
+  MESH_METRICS_GOSSIP_RATE,
+  MESH_METRICS_LOCAL_CHUNKS,
+  MESH_METRICS_P50_LATENCY,
+  MESH_METRICS_P95_LATENCY,
+  MESH_METRICS_RECORD_SIZE,
+  MESH_METRICS_SECTOR_ID,
+  MESH_METRICS_SECTOR_MEMBERS,
+  MESH_METRICS_TOTAL_CHUNKS,
+  MESH_METRICS_TOTAL_PEERS,
+} from './layout';
+
+/**
+ * Mesh Metrics Record - the telemetry the kernel packs at OFFSET_MESH_METRICS.
+ *
+ * Field offsets come from the MESH_METRICS_* layout constants; values are
+ * little-endian with IEEE 754 floats. Mirrors kernel/threads/sab/mesh_metrics.go.
+ */
+export interface MeshMetricsRecord {
+  totalPeers: number;
+  connectedPeers: number;
+  dhtEntries: number;
+  gossipRate: number;
+  avgReputation: number;
+  bytesSent: bigint;
+  bytesReceived: bigint;
+  p50Latency: number;
+  p95Latency: number;
+  successRate: number;
+  fetchSuccessRate: number;
+  localChunks: number;
+  totalChunks: number;
+  sectorId: number;
+  sectorMembers: number;
+}
+
+export function decodeMeshMetrics(view: DataView): MeshMetricsRecord | null {
+  if (view.byteLength < MESH_METRICS_RECORD_SIZE) return null;
+
+  return {
+    totalPeers: view.getUint32(MESH_METRICS_TOTAL_PEERS, true),
+    connectedPeers: view.getUint32(MESH_METRICS_CONNECTED_PEERS, true),
+    dhtEntries: view.getUint32(MESH_METRICS_DHT_ENTRIES, true),
+    gossipRate: view.getFloat32(MESH_METRICS_GOSSIP_RATE, true),
+    avgReputation: view.getFloat32(MESH_METRICS_AVG_REPUTATION, true),
+    bytesSent: view.getBigUint64(MESH_METRICS_BYTES_SENT, true),
+    bytesReceived: view.getBigUint64(MESH_METRICS_BYTES_RECEIVED, true),
+    p50Latency: view.getFloat32(MESH_METRICS_P50_LATENCY, true),
+    p95Latency: view.getFloat32(MESH_METRICS_P95_LATENCY, true),
+    successRate: view.getFloat32(MESH_METRICS_CONNECTION_SUCCESS, true),
+    fetchSuccessRate: view.getFloat32(MESH_METRICS_FETCH_SUCCESS, true),
+    localChunks: view.getUint32(MESH_METRICS_LOCAL_CHUNKS, true),
+    totalChunks: view.getUint32(MESH_METRICS_TOTAL_CHUNKS, true),
+    sectorId: view.getUint32(MESH_METRICS_SECTOR_ID, true),
+    sectorMembers: view.getUint32(MESH_METRICS_SECTOR_MEMBERS, true),
+  };
+}
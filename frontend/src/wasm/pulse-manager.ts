@@ -1,5 +1,6 @@
 import PulseWorkerUrl from './pulse.worker.ts?worker&url';
 import { IDX_SYSTEM_PULSE } from './layout';
+import { startFrameSchedule, stopFrameSchedule } from './frame-schedule';
 
 let pulseWorker: Worker | null = null;
 let mainThreadPulseId: number | null = null;
@@ -41,6 +42,9 @@ const pulseManager = {
   start(sab: SharedArrayBuffer) {
     if (pulseWorker) return;
 
+    // Report frame timing so the kernel defers background work into idle time
+    startFrameSchedule();
+
     console.log('[PulseManager] Starting high-precision pulse worker...');
 
     try {
@@ -153,6 +157,7 @@ const pulseManager = {
    * Cleanup resources
    */
   shutdown() {
+    stopFrameSchedule();
     if (pulseWorker) {
       pulseWorker.terminate();
       pulseWorker = null;
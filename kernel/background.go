@@ -0,0 +1,81 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"syscall/js"
+
+	"github.com/nmxmxh/inos_v1/kernel/utils"
+)
+
+// watchVisibility moves the kernel into background mode while the page is
+// hidden. Browsers throttle timers in hidden tabs, so loops that keep firing
+// only pile up late work. Kernels running in a worker have no document; the
+// host reports visibility with kernel.setBackground instead.
+func (k *Kernel) watchVisibility() {
+	doc := js.Global().Get("document")
+	if doc.IsUndefined() || doc.IsNull() {
+		return
+	}
+
+	report := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		k.SetBackground(doc.Get("visibilityState").String() == "hidden")
+		return nil
+	})
+	report.Invoke()
+	doc.Call("addEventListener", "visibilitychange", report)
+}
+
+// SetBackground switches a running kernel into or out of background mode.
+// While in background, the mesh skips its periodic work, gossip rounds pause,
+// keepalives stretch and epoch changes are coalesced per index; returning to
+// the foreground delivers the latest epochs and resumes everything. It
+// reports whether the state changed.
+func (k *Kernel) SetBackground(hidden bool) bool {
+	from, to := StateRunning, StateBackground
+	if !hidden {
+		from, to = StateBackground, StateRunning
+	}
+	if !k.transitionState(from, to) {
+		return false
+	}
+
+	restored := 0
+	if k.supervisor != nil {
+		if bridge := k.supervisor.GetBridge(); bridge != nil {
+			restored = bridge.SetBackground(hidden)
+		}
+	}
+	if k.meshCoordinator != nil {
+		k.meshCoordinator.SetBackgroundMode(hidden)
+	}
+
+	if hidden {
+		k.logger.Info("Page hidden; kernel entering background mode")
+		k.notifyHost("kernel:background", nil)
+	} else {
+		k.logger.Info("Page visible; kernel resuming full activity",
+			utils.Int("buffered_epochs", restored))
+		k.notifyHost("kernel:foreground", map[string]interface{}{
+			"bufferedEpochs": restored,
+		})
+	}
+	return true
+}
+
+// jsSetBackground lets hosts without a document report visibility:
+// kernel.setBackground(hidden).
+func jsSetBackground(this js.Value, args []js.Value) interface{} {
+	if len(args) < 1 || args[0].Type() != js.TypeBoolean {
+		return js.ValueOf(map[string]interface{}{"error": "missing hidden flag"})
+	}
+	if kernelInstance == nil {
+		return js.ValueOf(map[string]interface{}{"error": "kernel not initialized"})
+	}
+	return js.ValueOf(map[string]interface{}{
+		"success": true,
+		"changed": kernelInstance.SetBackground(args[0].Bool()),
+		"state":   kernelInstance.StateName(),
+	})
+}
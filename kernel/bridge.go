@@ -9,8 +9,10 @@ import (
 	"time"
 	"unsafe"
 
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh"
 	"github.com/nmxmxh/inos_v1/kernel/threads/foundation"
 	sab_layout "github.com/nmxmxh/inos_v1/kernel/threads/sab"
+	"github.com/nmxmxh/inos_v1/kernel/threads/supervisor"
 	"github.com/nmxmxh/inos_v1/kernel/utils"
 )
 
@@ -93,13 +95,59 @@ func jsGetKernelStats(this js.Value, args []js.Value) interface{} {
 		"mesh":      meshStats,
 	}
 
+	if memory := kernelInstance.roleConfig.Memory; memory.Tier != "" {
+		stats["memoryProfile"] = map[string]interface{}{
+			"tier":                string(memory.Tier),
+			"chunkCacheEntries":   memory.ChunkCacheEntries,
+			"bloomFilterElements": int(memory.BloomFilterElements),
+			"seenCacheEntries":    memory.SeenCacheEntries,
+			"gossipQueueSize":     memory.GossipQueueSize,
+			"transportQueueSize":  memory.TransportQueueSize,
+			"maxWorkers":          memory.MaxWorkers,
+			"workers":             kernelInstance.config.MaxWorkers,
+		}
+	}
+
 	if kernelInstance.supervisor != nil {
 		supStats := kernelInstance.supervisor.GetStats()
+		threads := map[string]interface{}{}
+		for name, ts := range supStats.Threads {
+			threads[name] = map[string]interface{}{
+				"restarts":  ts.Restarts,
+				"recent":    ts.Recent,
+				"failed":    ts.Failed,
+				"lastError": ts.LastError,
+			}
+		}
 		stats["supervisor"] = map[string]interface{}{
-			"activeThreads": supStats.ActiveThreads,
-			"totalMessages": supStats.TotalMessages,
-			"failedThreads": supStats.FailedThreads,
+			"activeThreads":    supStats.ActiveThreads,
+			"totalMessages":    supStats.TotalMessages,
+			"failedThreads":    supStats.FailedThreads,
+			"restartedThreads": supStats.RestartedThreads,
+			"threads":          threads,
 		}
+		if bridge := kernelInstance.supervisor.GetBridge(); bridge != nil {
+			rings := map[string]interface{}{}
+			for _, rs := range bridge.RingStats() {
+				rings[rs.Ring] = ringStatsToMap(rs)
+			}
+			stats["rings"] = rings
+		}
+		kernelInstance.standbyMu.Lock()
+		standbyReady := kernelInstance.standby != nil
+		kernelInstance.standbyMu.Unlock()
+		failover := map[string]interface{}{"standbyReady": standbyReady}
+		if kernelInstance.journal != nil {
+			failover["journaledJobs"] = kernelInstance.journal.InFlight()
+		}
+		stats["failover"] = failover
+		frameBudget := frameBudgetStatsMap(kernelInstance.frameBudget.Stats())
+		if kernelInstance.meshCoordinator != nil {
+			frameBudget["meshDeferredTicks"] = float64(kernelInstance.meshCoordinator.DeferredTicks())
+			stats["idleSchedule"] = idleScheduleStatsMap(kernelInstance.meshCoordinator.GetIdleScheduleStats())
+		}
+		stats["frameBudget"] = frameBudget
+		stats["load"] = loadStatsMap(kernelInstance.loadMonitor.Stats())
 	} else {
 		stats["supervisor"] = "not_started"
 	}
@@ -107,6 +155,37 @@ func jsGetKernelStats(this js.Value, args []js.Value) interface{} {
 	return js.ValueOf(stats)
 }
 
+func ringStatsToMap(rs supervisor.RingStats) map[string]interface{} {
+	return map[string]interface{}{
+		"dropped":       float64(rs.Dropped),
+		"waits":         float64(rs.Waits),
+		"pendingBytes":  rs.PendingBytes,
+		"capacityBytes": rs.CapacityBytes,
+		"stalled":       rs.Stalled,
+		"stalledEpochs": rs.StalledEpochs,
+	}
+}
+
+func idleScheduleStatsMap(stats mesh.IdleScheduleStats) map[string]interface{} {
+	tasks := map[string]interface{}{}
+	for name, ts := range stats.Tasks {
+		tasks[name] = map[string]interface{}{
+			"runs":           float64(ts.Runs),
+			"idleRuns":       float64(ts.IdleRuns),
+			"forcedRuns":     float64(ts.ForcedRuns),
+			"deadlineMisses": float64(ts.DeadlineMisses),
+			"lastMs":         float64(ts.LastDuration.Microseconds()) / 1000,
+		}
+	}
+	return map[string]interface{}{
+		"hostFrames":     stats.HostFrames,
+		"frameSequence":  float64(stats.FrameSequence),
+		"pending":        stats.Pending,
+		"deadlineMisses": float64(stats.DeadlineMisses),
+		"tasks":          tasks,
+	}
+}
+
 func jsGetSharedArrayBuffer(this js.Value, args []js.Value) interface{} {
 	if kernelInstance == nil || kernelInstance.supervisor == nil {
 		return js.Null()
@@ -220,6 +299,12 @@ func jsDelegateJob(this js.Value, args []js.Value) interface{} {
 		js.CopyBytesToGo(job.Data, dataVal)
 	}
 
+	// Retries sharing a key run at most once on the executing peer
+	var idempotencyKey string
+	if key := jobVal.Get("idempotencyKey"); key.Type() == js.TypeString {
+		idempotencyKey = key.String()
+	}
+
 	// EXPLICIT DELEGATION
 	// Specifically forces Mesh Coordinator handling
 	utils.Info("JS delegated job", utils.String("job_id", job.ID), utils.String("op", job.Operation))
@@ -231,6 +316,9 @@ func jsDelegateJob(this js.Value, args []js.Value) interface{} {
 		if kernelInstance.meshCoordinator != nil {
 			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 			defer cancel()
+			if idempotencyKey != "" {
+				ctx = mesh.WithIdempotencyKey(ctx, idempotencyKey)
+			}
 			result, err := kernelInstance.meshCoordinator.DelegateJob(ctx, job)
 			if err != nil {
 				utils.Warn("Mesh delegation error", utils.String("job_id", job.ID), utils.Err(err))
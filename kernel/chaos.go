@@ -0,0 +1,66 @@
+//go:build js && wasm && chaos
+// +build js,wasm,chaos
+
+package main
+
+import (
+	"encoding/json"
+	"syscall/js"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh"
+)
+
+// registerChaosAPI exposes fault injection on the mesh object. It is only
+// compiled into builds tagged chaos, so production kernels cannot be told
+// to drop their own traffic.
+func registerChaosAPI(meshObj js.Value) {
+	meshObj.Set("setChaos", js.FuncOf(jsMeshSetChaos))
+	meshObj.Set("getChaos", js.FuncOf(jsMeshGetChaos))
+	meshObj.Set("tripBreaker", js.FuncOf(jsMeshTripBreaker))
+}
+
+// jsMeshSetChaos replaces the fault rules from a JSON ChaosConfig; an empty
+// rule list turns injection off.
+func jsMeshSetChaos(this js.Value, args []js.Value) interface{} {
+	if len(args) < 1 || args[0].Type() != js.TypeString {
+		return js.ValueOf(map[string]interface{}{"error": "missing chaos config JSON"})
+	}
+	if kernelInstance == nil || kernelInstance.meshCoordinator == nil {
+		return js.ValueOf(map[string]interface{}{"error": "mesh not initialized"})
+	}
+	var config mesh.ChaosConfig
+	if err := json.Unmarshal([]byte(args[0].String()), &config); err != nil {
+		return js.ValueOf(map[string]interface{}{"error": "invalid chaos config: " + err.Error()})
+	}
+	if err := kernelInstance.meshCoordinator.ConfigureChaos(config); err != nil {
+		return js.ValueOf(map[string]interface{}{"error": err.Error()})
+	}
+	return jsMeshGetChaos(this, nil)
+}
+
+func jsMeshGetChaos(this js.Value, args []js.Value) interface{} {
+	if kernelInstance == nil || kernelInstance.meshCoordinator == nil {
+		return js.ValueOf(map[string]interface{}{"error": "mesh not initialized"})
+	}
+	data, err := json.Marshal(kernelInstance.meshCoordinator.ChaosStatus())
+	if err != nil {
+		return js.ValueOf(map[string]interface{}{"error": err.Error()})
+	}
+	var status map[string]interface{}
+	if err := json.Unmarshal(data, &status); err != nil {
+		return js.ValueOf(map[string]interface{}{"error": err.Error()})
+	}
+	status["success"] = true
+	return js.ValueOf(status)
+}
+
+func jsMeshTripBreaker(this js.Value, args []js.Value) interface{} {
+	if len(args) < 1 || args[0].Type() != js.TypeString {
+		return js.ValueOf(map[string]interface{}{"error": "missing peer ID"})
+	}
+	if kernelInstance == nil || kernelInstance.meshCoordinator == nil {
+		return js.ValueOf(map[string]interface{}{"error": "mesh not initialized"})
+	}
+	kernelInstance.meshCoordinator.TripCircuitBreaker(args[0].String())
+	return js.ValueOf(map[string]interface{}{"success": true})
+}
@@ -0,0 +1,9 @@
+//go:build js && wasm && !chaos
+// +build js,wasm,!chaos
+
+package main
+
+import "syscall/js"
+
+// registerChaosAPI is a no-op outside chaos builds; see chaos.go.
+func registerChaosAPI(meshObj js.Value) {}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/inos"
+)
+
+// controlFlags registers the flags every client command shares and returns
+// a constructor for the client they describe. The token comes from -token,
+// then INOS_CONTROL_TOKEN, then the daemon's data directory.
+func controlFlags(flags *flag.FlagSet) func() (*inos.ControlClient, error) {
+	addr := flags.String("addr", envOr("INOS_CONTROL_ADDR", defaultControlAddr), "daemon control API address")
+	token := flags.String("token", "", "control token (default $INOS_CONTROL_TOKEN or the daemon's token file)")
+	dataDir := flags.String("data", defaultDataDir(), "daemon data directory holding the control token")
+	return func() (*inos.ControlClient, error) {
+		value := *token
+		if value == "" {
+			value = os.Getenv("INOS_CONTROL_TOKEN")
+		}
+		if value == "" {
+			data, err := os.ReadFile(filepath.Join(*dataDir, controlTokenFile))
+			if err != nil {
+				return nil, fmt.Errorf("no control token: %w", err)
+			}
+			value = strings.TrimSpace(string(data))
+		}
+		return inos.NewControlClient(*addr, value), nil
+	}
+}
+
+// clientContext is cancelled on interrupt.
+func clientContext() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt)
+}
+
+func runStatus(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("status", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	client := controlFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	return withClient("status", client, stderr, func(ctx context.Context, c *inos.ControlClient) error {
+		status, err := c.Status(ctx)
+		if err != nil {
+			return err
+		}
+		return writeJSON(stdout, status)
+	})
+}
+
+func runPut(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("put", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	client := controlFlags(flags)
+	contentType := flags.String("type", "", "content type (default from the file extension)")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		fmt.Fprint(stderr, "usage: inos-node put [-type content-type] <file>\n")
+		return 2
+	}
+	path := flags.Arg(0)
+	if *contentType == "" {
+		*contentType = mime.TypeByExtension(filepath.Ext(path))
+	}
+
+	return withClient("put", client, stderr, func(ctx context.Context, c *inos.ControlClient) error {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		object, err := c.PutObject(ctx, file, *contentType)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(stdout, object.Hash)
+		return nil
+	})
+}
+
+func runGet(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("get", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	client := controlFlags(flags)
+	out := flags.String("o", "", "write to this file instead of stdout")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		fmt.Fprint(stderr, "usage: inos-node get [-o file] <hash>\n")
+		return 2
+	}
+
+	return withClient("get", client, stderr, func(ctx context.Context, c *inos.ControlClient) error {
+		if *out == "" {
+			_, err := c.GetObject(ctx, flags.Arg(0), stdout)
+			return err
+		}
+		file, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		if _, err := c.GetObject(ctx, flags.Arg(0), file); err != nil {
+			file.Close()
+			os.Remove(*out)
+			return err
+		}
+		return file.Close()
+	})
+}
+
+func runDelegate(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("delegate", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	client := controlFlags(flags)
+	op := flags.String("op", "", "operation to run")
+	in := flags.String("in", "", "input file (default no input)")
+	out := flags.String("out", "", "write the result to this file instead of stdout")
+	params := flags.String("params", "", "job parameters as a JSON object")
+	priority := flags.Int("priority", 0, "job priority")
+	timeout := flags.Duration("timeout", 0, "give up after this long (default no limit)")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *op == "" || flags.NArg() != 0 {
+		fmt.Fprint(stderr, "usage: inos-node delegate -op operation [-in file] [-out file] [-params json] [-priority n] [-timeout d]\n")
+		return 2
+	}
+
+	req := inos.ControlDelegateRequest{Operation: *op, Priority: *priority, TimeoutMs: timeout.Milliseconds()}
+	if *params != "" {
+		if err := json.Unmarshal([]byte(*params), &req.Parameters); err != nil {
+			fmt.Fprintf(stderr, "inos-node delegate: invalid -params: %v\n", err)
+			return 2
+		}
+	}
+	if *in != "" {
+		data, err := os.ReadFile(*in)
+		if err != nil {
+			fmt.Fprintf(stderr, "inos-node delegate: %v\n", err)
+			return 1
+		}
+		req.Data = data
+	}
+
+	return withClient("delegate", client, stderr, func(ctx context.Context, c *inos.ControlClient) error {
+		result, err := c.Delegate(ctx, req)
+		if err != nil {
+			return err
+		}
+		if !result.Success {
+			return fmt.Errorf("job %s failed: %s", result.JobID, result.Error)
+		}
+		fmt.Fprintf(stderr, "job %s done in %s\n", result.JobID, time.Duration(result.LatencyMs*float64(time.Millisecond)))
+		if *out != "" {
+			return os.WriteFile(*out, result.Data, 0o644)
+		}
+		_, err = stdout.Write(result.Data)
+		return err
+	})
+}
+
+func runPeers(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("peers", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	client := controlFlags(flags)
+	asJSON := flags.Bool("json", false, "print peers as JSON")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	return withClient("peers", client, stderr, func(ctx context.Context, c *inos.ControlClient) error {
+		peers, err := c.Peers(ctx)
+		if err != nil {
+			return err
+		}
+		if *asJSON {
+			return writeJSON(stdout, peers)
+		}
+		tw := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "PEER\tREGION\tREPUTATION\tCONNECTED\tLATENCY")
+		for _, peer := range peers {
+			fmt.Fprintf(tw, "%s\t%s\t%.2f\t%t\t%.1fms\n", peer.ID, peer.Region, peer.Reputation, peer.Connected, peer.LatencyMs)
+		}
+		return tw.Flush()
+	})
+}
+
+func runLedger(args []string, stdout, stderr io.Writer) int {
+	const ledgerUsage = "usage: inos-node ledger balance [account] | inos-node ledger stats\n"
+	if len(args) == 0 {
+		fmt.Fprint(stderr, ledgerUsage)
+		return 2
+	}
+	flags := flag.NewFlagSet("ledger "+args[0], flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	client := controlFlags(flags)
+	if err := flags.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	switch {
+	case args[0] == "balance" && flags.NArg() <= 1:
+		return withClient("ledger", client, stderr, func(ctx context.Context, c *inos.ControlClient) error {
+			balance, err := c.Balance(ctx, flags.Arg(0))
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(stdout, "%s\t%d\n", balance.Account, balance.Balance)
+			return nil
+		})
+	case args[0] == "stats" && flags.NArg() == 0:
+		return withClient("ledger", client, stderr, func(ctx context.Context, c *inos.ControlClient) error {
+			stats, err := c.Ledger(ctx)
+			if err != nil {
+				return err
+			}
+			return writeJSON(stdout, stats)
+		})
+	default:
+		fmt.Fprint(stderr, ledgerUsage)
+		return 2
+	}
+}
+
+// withClient builds the client and runs fn, reporting any error under the
+// command's name.
+func withClient(name string, client func() (*inos.ControlClient, error), stderr io.Writer, fn func(context.Context, *inos.ControlClient) error) int {
+	c, err := client()
+	if err == nil {
+		ctx, cancel := clientContext()
+		defer cancel()
+		err = fn(ctx, c)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "inos-node %s: %v\n", name, err)
+		return 1
+	}
+	return 0
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh"
+	"github.com/nmxmxh/inos_v1/kernel/inos"
+)
+
+const (
+	defaultControlAddr = "127.0.0.1:7468"
+	controlTokenFile   = "control.token"
+	nodeKeyFile        = "node.key"
+)
+
+// runDaemon starts a node and serves its control API until interrupted.
+// The node key and the control token live in the data directory, so both
+// the node ID and the token survive restarts.
+func runDaemon(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("daemon", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	listen := flags.String("listen", defaultControlAddr, "control API address")
+	dataDir := flags.String("data", defaultDataDir(), "directory for the node key and control token")
+	region := flags.String("region", "global", "region code announced to the mesh")
+	did := flags.String("did", "", "DID the node earns into")
+	bootstrap := flags.String("bootstrap", "", "comma-separated bootstrap peers")
+	bridge := flags.String("bridge", "", "address to accept WebSocket bridge links from browser kernels on")
+	relay := flags.Bool("relay", false, "relay circuits for peers that cannot connect directly")
+	metricsAddr := flags.String("metrics", "", "address to serve Prometheus metrics on at /metrics")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if err := os.MkdirAll(*dataDir, 0o700); err != nil {
+		fmt.Fprintf(stderr, "inos-node daemon: %v\n", err)
+		return 1
+	}
+	token, err := loadOrCreateControlToken(filepath.Join(*dataDir, controlTokenFile))
+	if err != nil {
+		fmt.Fprintf(stderr, "inos-node daemon: %v\n", err)
+		return 1
+	}
+
+	config := inos.DefaultConfig()
+	config.Region = *region
+	config.DID = *did
+	config.IdentityStore = &mesh.FileIdentityKeyStore{Path: filepath.Join(*dataDir, nodeKeyFile)}
+	config.BootstrapPeers = splitList(*bootstrap)
+	config.Transport.BridgeListen = *bridge
+	config.Transport.Relay.Enabled = *relay
+	config.MetricsListen = *metricsAddr
+	config.Logger = slog.New(slog.NewTextHandler(stderr, nil))
+
+	node, err := inos.New(config)
+	if err != nil {
+		fmt.Fprintf(stderr, "inos-node daemon: %v\n", err)
+		return 1
+	}
+	defer node.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := node.Start(ctx); err != nil {
+		fmt.Fprintf(stderr, "inos-node daemon: %v\n", err)
+		return 1
+	}
+	server, err := inos.NewControlServer(node, token)
+	if err != nil {
+		fmt.Fprintf(stderr, "inos-node daemon: %v\n", err)
+		return 1
+	}
+	ln, err := net.Listen("tcp", *listen)
+	if err != nil {
+		fmt.Fprintf(stderr, "inos-node daemon: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(stdout, "node %s serving control API on %s (token in %s)\n",
+		node.NodeID(), ln.Addr(), filepath.Join(*dataDir, controlTokenFile))
+	if addr := node.MetricsAddr(); addr != "" {
+		fmt.Fprintf(stdout, "serving metrics on http://%s/metrics\n", addr)
+	}
+
+	if err := server.Serve(ctx, ln); err != nil {
+		fmt.Fprintf(stderr, "inos-node daemon: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// loadOrCreateControlToken reads the token at path, writing a new one
+// readable only by the owner when none exists.
+func loadOrCreateControlToken(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		if token := strings.TrimSpace(string(data)); token != "" {
+			return token, nil
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("failed to read control token: %w", err)
+	}
+
+	token, err := inos.NewControlToken()
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, []byte(token+"\n"), 0o600); err != nil {
+		return "", fmt.Errorf("failed to write control token: %w", err)
+	}
+	return token, nil
+}
+
+func defaultDataDir() string {
+	if dir, err := os.UserConfigDir(); err == nil {
+		return filepath.Join(dir, "inos-node")
+	}
+	return ".inos-node"
+}
+
+func splitList(value string) []string {
+	var out []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
@@ -0,0 +1,122 @@
+// Command inos-node runs tasks for native INOS nodes.
+//
+//	inos-node daemon [-listen addr] [-data dir] [-region code] [-bootstrap peers] [-bridge addr] [-relay] [-metrics addr]
+//	inos-node status
+//	inos-node put [-type content-type] file
+//	inos-node get [-o file] hash
+//	inos-node delegate -op operation [-in file] [-out file]
+//	inos-node peers [-json]
+//	inos-node ledger balance [account] | ledger stats
+//	inos-node verify [-json] export.json
+//
+// daemon runs a headless node and serves its control API (see
+// inos.ControlServer) on a local address. Requests must carry the token the
+// daemon writes to control.token in its data directory. With -bridge it
+// also accepts WebSocket bridge links, which browser kernels dial as
+// "inos+ws://addr/". With -relay it forwards circuits for peers that
+// cannot connect directly, earning relay credit. With -metrics it serves
+// Prometheus metrics at http://addr/metrics.
+//
+// status, put, get, delegate, peers and ledger talk to a running daemon.
+// They take -addr and find the token through -token, $INOS_CONTROL_TOKEN or
+// the daemon's data directory (-data), in that order.
+//
+// verify checks an audit export (MeshCoordinator.ExportAudit, or
+// mesh.exportAudit() in the browser) offline and exits non-zero when any
+// check fails.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/audit"
+)
+
+const usage = `usage: inos-node <command> [arguments]
+
+commands:
+  daemon [flags]                 run a node and serve its control API
+  status                         show the daemon's node status
+  put [-type t] <file>           store a file on the mesh and print its hash
+  get [-o file] <hash>           fetch an object
+  delegate -op <op> [-in file]   run a job on the mesh and print its result
+  peers [-json]                  list known peers
+  ledger balance [account]       show an account's balance
+  ledger stats                   show ledger totals
+  verify [-json] <export.json>   verify an exported audit log and ledger
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+	switch args[0] {
+	case "daemon":
+		return runDaemon(args[1:], stdout, stderr)
+	case "status":
+		return runStatus(args[1:], stdout, stderr)
+	case "put":
+		return runPut(args[1:], stdout, stderr)
+	case "get":
+		return runGet(args[1:], stdout, stderr)
+	case "delegate":
+		return runDelegate(args[1:], stdout, stderr)
+	case "peers":
+		return runPeers(args[1:], stdout, stderr)
+	case "ledger":
+		return runLedger(args[1:], stdout, stderr)
+	case "verify":
+		return runVerify(args[1:], stdout, stderr)
+	case "help", "-h", "--help":
+		fmt.Fprint(stdout, usage)
+		return 0
+	default:
+		fmt.Fprintf(stderr, "inos-node: unknown command %q\n\n%s", args[0], usage)
+		return 2
+	}
+}
+
+func runVerify(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("verify", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	asJSON := flags.Bool("json", false, "print the report as JSON")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		fmt.Fprint(stderr, "usage: inos-node verify [-json] <export.json>\n")
+		return 2
+	}
+
+	export, err := audit.LoadFile(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintf(stderr, "inos-node verify: %v\n", err)
+		return 2
+	}
+	report := audit.Verify(export)
+
+	if *asJSON {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(report)
+	} else {
+		err = report.WriteText(stdout)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "inos-node verify: %v\n", err)
+		return 2
+	}
+	if !report.Valid {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,118 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"syscall/js"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/threads/foundation"
+	"github.com/nmxmxh/inos_v1/kernel/threads/supervisor"
+	"github.com/nmxmxh/inos_v1/kernel/utils"
+	"github.com/nmxmxh/inos_v1/kernel/utils/tracing"
+)
+
+// submitJobCommand is the payload of a "submit_job" host command.
+type submitJobCommand struct {
+	ID       string                 `json:"id"`
+	Type     string                 `json:"type"`
+	Op       string                 `json:"op"`
+	Data     []byte                 `json:"data,omitempty"` // Base64 in JSON
+	Params   map[string]interface{} `json:"params,omitempty"`
+	Priority int                    `json:"priority,omitempty"`
+}
+
+// startCommandBus wires the host command bus. Every command gets a result
+// envelope in the host outbox, tagged with its correlation ID.
+func (k *Kernel) startCommandBus() {
+	bridge := k.supervisor.GetBridge()
+	if bridge == nil {
+		return
+	}
+
+	bus := supervisor.NewCommandBus(bridge.WriteOutbox)
+	bus.Register("submit_job", k.handleSubmitJobCommand)
+	bus.Register("get_stats", func(ctx context.Context, _ json.RawMessage) (interface{}, error) {
+		return map[string]interface{}{
+			"state":  k.StateName(),
+			"rings":  bridge.RingStats(),
+			"uptime": time.Since(k.startTime).String(),
+		}, nil
+	})
+
+	k.commandsMu.Lock()
+	k.commands = bus
+	k.commandsMu.Unlock()
+}
+
+func (k *Kernel) handleSubmitJobCommand(ctx context.Context, payload json.RawMessage) (interface{}, error) {
+	var req submitJobCommand
+	if err := json.Unmarshal(payload, &req); err != nil {
+		return nil, &supervisor.CommandFailure{Code: supervisor.CodeInvalidCommand, Err: fmt.Errorf("invalid submit_job payload: %w", err)}
+	}
+	if req.Type == "" || req.Op == "" {
+		return nil, &supervisor.CommandFailure{Code: supervisor.CodeInvalidCommand, Err: errors.New("submit_job requires type and op")}
+	}
+	if req.ID == "" {
+		req.ID = utils.GenerateID()
+	}
+
+	resChan, err := k.supervisor.Submit(&foundation.Job{
+		ID:          req.ID,
+		Type:        req.Type,
+		Operation:   req.Op,
+		Data:        req.Data,
+		Parameters:  req.Params,
+		Priority:    req.Priority,
+		TraceParent: tracing.TraceparentFromContext(ctx),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	select {
+	case res := <-resChan:
+		if res == nil {
+			return nil, errors.New("job finished without a result")
+		}
+		if !res.Success {
+			return nil, errors.New(res.Error)
+		}
+		return map[string]interface{}{"job_id": req.ID, "data": res.Data}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// jsSubmitCommand accepts a host command as a JSON string or object:
+// {correlation_id, idempotency_key?, type, payload?}. The result envelope is
+// written to the host outbox; the return value only acknowledges receipt.
+func jsSubmitCommand(this js.Value, args []js.Value) interface{} {
+	if len(args) < 1 {
+		return js.ValueOf(map[string]interface{}{"error": "missing command argument"})
+	}
+	if kernelInstance == nil {
+		return js.ValueOf(map[string]interface{}{"error": "kernel not initialized"})
+	}
+	kernelInstance.commandsMu.RLock()
+	bus := kernelInstance.commands
+	kernelInstance.commandsMu.RUnlock()
+	if bus == nil {
+		return js.ValueOf(map[string]interface{}{"error": "command bus not ready"})
+	}
+
+	frame := args[0]
+	if frame.Type() != js.TypeString {
+		frame = js.Global().Get("JSON").Call("stringify", frame)
+	}
+	data := []byte(frame.String())
+
+	go bus.Consume(kernelInstance.ctx, data)
+
+	return js.ValueOf(map[string]interface{}{"success": true, "status": "accepted"})
+}
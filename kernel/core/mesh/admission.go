@@ -0,0 +1,457 @@
+package mesh
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Region admission modes.
+const (
+	RegionPolicyOpen          = "open"           // default rules apply
+	RegionPolicyDeny          = "deny"           // refuse every peer from the region
+	RegionPolicyAllowlistOnly = "allowlist_only" // admit only allowlisted peers
+)
+
+var (
+	ErrPeerBlocked    = errors.New("peer is blocklisted")
+	ErrPeerNotAllowed = errors.New("peer is not on the allowlist")
+	ErrRegionDenied   = errors.New("peer region is denied by policy")
+)
+
+// AdmissionRule matches peer IDs or DIDs. Patterns use path.Match syntax, so
+// "did:inos:acme:*" covers every DID under that prefix.
+type AdmissionRule struct {
+	Pattern   string    `json:"pattern"`
+	Reason    string    `json:"reason,omitempty"`
+	AddedAt   time.Time `json:"added_at"`
+	ExpiresAt time.Time `json:"expires_at,omitempty"` // zero = permanent
+	Automatic bool      `json:"automatic,omitempty"`  // set by auto-ban
+}
+
+func (r AdmissionRule) expired(now time.Time) bool {
+	return !r.ExpiresAt.IsZero() && now.After(r.ExpiresAt)
+}
+
+func (r AdmissionRule) matches(ids ...string) bool {
+	for _, id := range ids {
+		if id == "" {
+			continue
+		}
+		if r.Pattern == id {
+			return true
+		}
+		if ok, err := path.Match(r.Pattern, id); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+// AdmissionPolicy is the persisted state of an AdmissionController.
+type AdmissionPolicy struct {
+	Blocklist []AdmissionRule   `json:"blocklist"`
+	Allowlist []AdmissionRule   `json:"allowlist"`
+	Regions   map[string]string `json:"regions"`
+	AutoBan   AdmissionAutoBan  `json:"auto_ban"`
+}
+
+// AdmissionAutoBan bans peers whose circuit breaker trips repeatedly.
+type AdmissionAutoBan struct {
+	Enabled     bool          `json:"enabled"`
+	TripsToBan  int           `json:"trips_to_ban"`
+	Window      time.Duration `json:"window"`
+	BanDuration time.Duration `json:"ban_duration"`
+}
+
+// AdmissionStore persists admission policy across restarts.
+type AdmissionStore interface {
+	SaveAdmissionPolicy(policy AdmissionPolicy) error
+	LoadAdmissionPolicy() (AdmissionPolicy, error)
+}
+
+// FileAdmissionStore keeps the policy in a JSON file (native nodes).
+type FileAdmissionStore struct {
+	Path string
+}
+
+// SaveAdmissionPolicy writes the policy atomically.
+func (s FileAdmissionStore) SaveAdmissionPolicy(policy AdmissionPolicy) error {
+	data, err := json.MarshalIndent(policy, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.Path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.Path)
+}
+
+// LoadAdmissionPolicy reads the policy; a missing file yields an empty policy.
+func (s FileAdmissionStore) LoadAdmissionPolicy() (AdmissionPolicy, error) {
+	var policy AdmissionPolicy
+	data, err := os.ReadFile(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return policy, nil
+	}
+	if err != nil {
+		return policy, err
+	}
+	return policy, json.Unmarshal(data, &policy)
+}
+
+// AdmissionController decides which peers may join. Blocklist entries win
+// over allowlist entries; a non-empty allowlist admits only matching peers.
+type AdmissionController struct {
+	mu        sync.RWMutex
+	policy    AdmissionPolicy
+	store     AdmissionStore
+	trips     map[string][]time.Time
+	listeners []func(AdmissionPolicy)
+	now       func() time.Time
+}
+
+// DefaultAdmissionAutoBan bans a peer for a day after ten breaker trips
+// within an hour. Shorter failure streaks are handled by quarantine.
+func DefaultAdmissionAutoBan() AdmissionAutoBan {
+	return AdmissionAutoBan{Enabled: true, TripsToBan: 10, Window: time.Hour, BanDuration: 24 * time.Hour}
+}
+
+// NewAdmissionController creates a controller, restoring state from store.
+func NewAdmissionController(store AdmissionStore) (*AdmissionController, error) {
+	ac := &AdmissionController{
+		policy: AdmissionPolicy{Regions: make(map[string]string), AutoBan: DefaultAdmissionAutoBan()},
+		store:  store,
+		trips:  make(map[string][]time.Time),
+		now:    time.Now,
+	}
+	if store == nil {
+		return ac, nil
+	}
+	loaded, err := store.LoadAdmissionPolicy()
+	if err != nil {
+		return ac, fmt.Errorf("failed to load admission policy: %w", err)
+	}
+	ac.replacePolicy(loaded)
+	return ac, nil
+}
+
+// SetStore attaches a persistence backend and loads its policy.
+func (ac *AdmissionController) SetStore(store AdmissionStore) error {
+	ac.mu.Lock()
+	ac.store = store
+	ac.mu.Unlock()
+	if store == nil {
+		return nil
+	}
+	loaded, err := store.LoadAdmissionPolicy()
+	if err != nil {
+		return fmt.Errorf("failed to load admission policy: %w", err)
+	}
+	ac.replacePolicy(loaded)
+	return nil
+}
+
+// Subscribe registers a callback fired after every policy mutation.
+func (ac *AdmissionController) Subscribe(fn func(AdmissionPolicy)) {
+	ac.mu.Lock()
+	ac.listeners = append(ac.listeners, fn)
+	ac.mu.Unlock()
+}
+
+// ImportPolicy replaces the whole policy, e.g. from host storage.
+func (ac *AdmissionController) ImportPolicy(policy AdmissionPolicy) error {
+	ac.replacePolicy(policy)
+	return ac.persist()
+}
+
+func (ac *AdmissionController) replacePolicy(policy AdmissionPolicy) {
+	if policy.Regions == nil {
+		policy.Regions = make(map[string]string)
+	}
+	if policy.AutoBan.TripsToBan == 0 && policy.AutoBan.Window == 0 && policy.AutoBan.BanDuration == 0 {
+		policy.AutoBan = DefaultAdmissionAutoBan()
+	}
+	ac.mu.Lock()
+	ac.policy = policy
+	ac.mu.Unlock()
+}
+
+// Block adds a blocklist rule. ttl <= 0 blocks permanently.
+func (ac *AdmissionController) Block(pattern, reason string, ttl time.Duration) error {
+	return ac.addRule(&ac.policy.Blocklist, pattern, reason, ttl, false)
+}
+
+// Unblock removes a blocklist rule.
+func (ac *AdmissionController) Unblock(pattern string) error {
+	return ac.removeRule(&ac.policy.Blocklist, pattern)
+}
+
+// Allow adds an allowlist rule.
+func (ac *AdmissionController) Allow(pattern string) error {
+	return ac.addRule(&ac.policy.Allowlist, pattern, "", 0, false)
+}
+
+// Disallow removes an allowlist rule.
+func (ac *AdmissionController) Disallow(pattern string) error {
+	return ac.removeRule(&ac.policy.Allowlist, pattern)
+}
+
+// SetRegionPolicy sets the admission mode for a region.
+func (ac *AdmissionController) SetRegionPolicy(region, mode string) error {
+	region = strings.TrimSpace(region)
+	if region == "" {
+		return errors.New("region is required")
+	}
+	switch mode {
+	case RegionPolicyOpen, RegionPolicyDeny, RegionPolicyAllowlistOnly:
+	default:
+		return fmt.Errorf("unknown region policy %q", mode)
+	}
+
+	ac.mu.Lock()
+	if mode == RegionPolicyOpen {
+		delete(ac.policy.Regions, region)
+	} else {
+		ac.policy.Regions[region] = mode
+	}
+	ac.mu.Unlock()
+	return ac.persist()
+}
+
+// SetAutoBan configures automatic bans for repeat offenders.
+func (ac *AdmissionController) SetAutoBan(cfg AdmissionAutoBan) error {
+	ac.mu.Lock()
+	ac.policy.AutoBan = cfg
+	ac.mu.Unlock()
+	return ac.persist()
+}
+
+// Policy returns a copy of the current policy with expired rules dropped.
+func (ac *AdmissionController) Policy() AdmissionPolicy {
+	now := ac.now()
+	ac.mu.RLock()
+	defer ac.mu.RUnlock()
+
+	out := AdmissionPolicy{
+		Blocklist: activeRules(ac.policy.Blocklist, now),
+		Allowlist: activeRules(ac.policy.Allowlist, now),
+		Regions:   make(map[string]string, len(ac.policy.Regions)),
+		AutoBan:   ac.policy.AutoBan,
+	}
+	for region, mode := range ac.policy.Regions {
+		out.Regions[region] = mode
+	}
+	return out
+}
+
+// Admit checks a peer against the policy. did and region may be empty when
+// not yet known; callers re-check once attestation reveals them.
+func (ac *AdmissionController) Admit(peerID, did, region string) error {
+	now := ac.now()
+	ac.mu.RLock()
+	defer ac.mu.RUnlock()
+
+	for _, rule := range ac.policy.Blocklist {
+		if !rule.expired(now) && rule.matches(peerID, did) {
+			if rule.Reason != "" {
+				return fmt.Errorf("%w: %s", ErrPeerBlocked, rule.Reason)
+			}
+			return ErrPeerBlocked
+		}
+	}
+
+	allowed := false
+	allowlist := activeRules(ac.policy.Allowlist, now)
+	for _, rule := range allowlist {
+		if rule.matches(peerID, did) {
+			allowed = true
+			break
+		}
+	}
+
+	switch ac.policy.Regions[region] {
+	case RegionPolicyDeny:
+		if !allowed {
+			return ErrRegionDenied
+		}
+	case RegionPolicyAllowlistOnly:
+		if !allowed {
+			return ErrPeerNotAllowed
+		}
+	}
+
+	if len(allowlist) > 0 && !allowed {
+		return ErrPeerNotAllowed
+	}
+	return nil
+}
+
+// RecordBreakerTrip notes that a peer's circuit breaker opened and bans it
+// once the auto-ban threshold is reached. It reports whether a ban was added.
+func (ac *AdmissionController) RecordBreakerTrip(peerID string) bool {
+	now := ac.now()
+
+	ac.mu.Lock()
+	cfg := ac.policy.AutoBan
+	if !cfg.Enabled || cfg.TripsToBan <= 0 {
+		ac.mu.Unlock()
+		return false
+	}
+	trips := ac.trips[peerID][:0]
+	for _, at := range ac.trips[peerID] {
+		if now.Sub(at) <= cfg.Window {
+			trips = append(trips, at)
+		}
+	}
+	trips = append(trips, now)
+	if len(trips) < cfg.TripsToBan {
+		ac.trips[peerID] = trips
+		ac.mu.Unlock()
+		return false
+	}
+	delete(ac.trips, peerID)
+	ac.mu.Unlock()
+
+	reason := fmt.Sprintf("auto-ban: circuit breaker tripped %d times within %s", len(trips), cfg.Window)
+	return ac.addRule(&ac.policy.Blocklist, peerID, reason, cfg.BanDuration, true) == nil
+}
+
+func (ac *AdmissionController) addRule(list *[]AdmissionRule, pattern, reason string, ttl time.Duration, automatic bool) error {
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" {
+		return errors.New("pattern is required")
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+
+	now := ac.now()
+	rule := AdmissionRule{Pattern: pattern, Reason: reason, AddedAt: now, Automatic: automatic}
+	if ttl > 0 {
+		rule.ExpiresAt = now.Add(ttl)
+	}
+
+	ac.mu.Lock()
+	replaced := false
+	for i := range *list {
+		if (*list)[i].Pattern == pattern {
+			(*list)[i] = rule
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		*list = append(*list, rule)
+	}
+	ac.mu.Unlock()
+	return ac.persist()
+}
+
+func (ac *AdmissionController) removeRule(list *[]AdmissionRule, pattern string) error {
+	ac.mu.Lock()
+	kept := (*list)[:0]
+	for _, rule := range *list {
+		if rule.Pattern != pattern {
+			kept = append(kept, rule)
+		}
+	}
+	*list = kept
+	ac.mu.Unlock()
+	return ac.persist()
+}
+
+func (ac *AdmissionController) persist() error {
+	policy := ac.Policy()
+
+	ac.mu.RLock()
+	store := ac.store
+	listeners := append([]func(AdmissionPolicy){}, ac.listeners...)
+	ac.mu.RUnlock()
+
+	for _, fn := range listeners {
+		fn(policy)
+	}
+	if store == nil {
+		return nil
+	}
+	return store.SaveAdmissionPolicy(policy)
+}
+
+func activeRules(rules []AdmissionRule, now time.Time) []AdmissionRule {
+	out := make([]AdmissionRule, 0, len(rules))
+	for _, rule := range rules {
+		if !rule.expired(now) {
+			out = append(out, rule)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Pattern < out[j].Pattern })
+	return out
+}
+
+// ========== Coordinator integration ==========
+
+// Admission returns the peer admission controller.
+func (m *MeshCoordinator) Admission() *AdmissionController {
+	return m.admission
+}
+
+// SetAdmissionStore attaches persistent storage for the admission policy.
+func (m *MeshCoordinator) SetAdmissionStore(store AdmissionStore) error {
+	return m.admission.SetStore(store)
+}
+
+// admitPeer checks a connected peer and disconnects it when refused.
+func (m *MeshCoordinator) admitPeer(peerID string) bool {
+	var region string
+	if cached := m.getCachedPeer(peerID); cached != nil {
+		region = cached.Region
+	}
+	m.attestationMu.RLock()
+	record := m.attestedPeers[peerID]
+	m.attestationMu.RUnlock()
+	did := record.DID
+
+	err := m.admission.Admit(peerID, did, region)
+	if err == nil {
+		err = m.checkSybilAdmission(record)
+	}
+	if err == nil {
+		return true
+	}
+
+	m.logger.Warn("peer refused by admission policy", "peer", getShortID(peerID), "did", did, "region", region, "error", err)
+	_ = m.transport.Disconnect(peerID)
+	m.clearPeerAttestation(peerID)
+	m.dht.RemovePeer(peerID)
+	m.gossip.RemovePeer(peerID)
+	m.emitPeerUpdateEvent(&PeerCapability{
+		PeerID:          peerID,
+		ConnectionState: ConnectionStateFailed,
+		LastSeen:        time.Now().UnixNano(),
+	})
+	return false
+}
+
+// enforceAdmission disconnects currently connected peers that the policy
+// now refuses; called after every policy change.
+func (m *MeshCoordinator) enforceAdmission() {
+	for _, peerID := range m.transport.GetConnectedPeers() {
+		m.admitPeer(peerID)
+	}
+}
+
+// recordBreakerTrip feeds circuit breaker openings into auto-ban.
+func (m *MeshCoordinator) recordBreakerTrip(peerID string) {
+	if m.admission.RecordBreakerTrip(peerID) {
+		m.logger.Warn("peer auto-banned after repeated circuit breaker trips", "peer", getShortID(peerID))
+		go m.admitPeer(peerID)
+	}
+}
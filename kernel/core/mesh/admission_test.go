@@ -0,0 +1,100 @@
+package mesh
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAdmissionController_BlocklistWinsOverAllowlist(t *testing.T) {
+	ac, _ := NewAdmissionController(nil)
+
+	if err := ac.Allow("did:inos:acme:*"); err != nil {
+		t.Fatalf("allow: %v", err)
+	}
+	if err := ac.Admit("peer-1", "did:inos:acme:alice", ""); err != nil {
+		t.Fatalf("expected wildcard DID to be admitted: %v", err)
+	}
+	if err := ac.Admit("peer-2", "did:inos:other:bob", ""); !errors.Is(err, ErrPeerNotAllowed) {
+		t.Fatalf("expected non-allowlisted peer to be refused, got %v", err)
+	}
+
+	_ = ac.Block("did:inos:acme:mallory", "spam", 0)
+	if err := ac.Admit("peer-3", "did:inos:acme:mallory", ""); !errors.Is(err, ErrPeerBlocked) {
+		t.Fatalf("expected blocklist to win, got %v", err)
+	}
+}
+
+func TestAdmissionController_RegionPolicies(t *testing.T) {
+	ac, _ := NewAdmissionController(nil)
+	_ = ac.SetRegionPolicy("eu-west", RegionPolicyDeny)
+	_ = ac.SetRegionPolicy("ap-south", RegionPolicyAllowlistOnly)
+	_ = ac.Allow("trusted")
+
+	if err := ac.Admit("peer-1", "", "eu-west"); !errors.Is(err, ErrRegionDenied) {
+		t.Fatalf("expected denied region, got %v", err)
+	}
+	if err := ac.Admit("trusted", "", "ap-south"); err != nil {
+		t.Fatalf("allowlisted peer should pass allowlist_only region: %v", err)
+	}
+	if err := ac.SetRegionPolicy("eu-west", "maybe"); err == nil {
+		t.Fatal("expected unknown mode to be rejected")
+	}
+}
+
+func TestAdmissionController_ExpiryAndPersistence(t *testing.T) {
+	store := FileAdmissionStore{Path: filepath.Join(t.TempDir(), "admission.json")}
+	ac, err := NewAdmissionController(store)
+	if err != nil {
+		t.Fatalf("new controller: %v", err)
+	}
+	now := time.Now()
+	ac.now = func() time.Time { return now }
+
+	_ = ac.Block("peer-temp", "cooldown", time.Minute)
+	_ = ac.Block("peer-perm", "", 0)
+	if err := ac.Admit("peer-temp", "", ""); err == nil {
+		t.Fatal("expected temporary block to apply")
+	}
+
+	restored, err := NewAdmissionController(store)
+	if err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if got := len(restored.Policy().Blocklist); got != 2 {
+		t.Fatalf("expected 2 persisted rules, got %d", got)
+	}
+
+	restored.now = func() time.Time { return now.Add(2 * time.Minute) }
+	if err := restored.Admit("peer-temp", "", ""); err != nil {
+		t.Fatalf("expired block should no longer apply: %v", err)
+	}
+	if err := restored.Admit("peer-perm", "", ""); err == nil {
+		t.Fatal("permanent block should survive restart")
+	}
+}
+
+func TestMeshCoordinator_CircuitBreakerTripsAutoBan(t *testing.T) {
+	tr := &MockTransport{nodeID: "self"}
+	coord := NewMeshCoordinator("self", "us-east", tr, nil)
+	coord.config.CircuitBreaker.FailureThreshold = 1
+	_ = coord.admission.SetAutoBan(AdmissionAutoBan{Enabled: true, TripsToBan: 2, Window: time.Minute, BanDuration: time.Hour})
+
+	// Closed -> open, then half-open -> re-open: two trips.
+	coord.updateCircuitBreaker("abuser", false)
+	if err := coord.admission.Admit("abuser", "", ""); err != nil {
+		t.Fatalf("one trip should not ban: %v", err)
+	}
+	coord.circuitBreakers["peer:abuser"].state = BreakerHalfOpen
+	coord.updateCircuitBreaker("abuser", false)
+
+	err := coord.admission.Admit("abuser", "", "")
+	if !errors.Is(err, ErrPeerBlocked) {
+		t.Fatalf("expected auto-ban after repeated trips, got %v", err)
+	}
+	rules := coord.admission.Policy().Blocklist
+	if len(rules) != 1 || !rules[0].Automatic || rules[0].ExpiresAt.IsZero() {
+		t.Fatalf("expected one expiring automatic rule, got %+v", rules)
+	}
+}
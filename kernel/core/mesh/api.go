@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/transport"
 	p2p "github.com/nmxmxh/inos_v1/kernel/gen/p2p/v1"
 )
 
@@ -29,10 +30,16 @@ func (m *MeshCoordinator) RegisterChunk(ctx context.Context, chunkHash string) e
 	m.localChunksMu.Lock()
 	m.localChunks[chunkHash] = struct{}{}
 	m.localChunksMu.Unlock()
-	if err := m.dht.Store(chunkHash, m.nodeID, 3600); err != nil {
+	// Chunks written by the host bypass our store paths; size them here
+	if m.storage != nil && !m.storageQuota.Tracked(chunkHash) {
+		if data, err := m.storage.FetchChunk(ctx, chunkHash); err == nil {
+			m.trackStoredChunk(ctx, chunkHash, len(data))
+		}
+	}
+	if err := m.storeChunkRecord(chunkHash, m.nodeID); err != nil {
 		return err
 	}
-	if err := m.gossip.AnnounceChunk(chunkHash); err != nil {
+	if err := m.announceChunkOrQueue(chunkHash); err != nil {
 		return err
 	}
 	m.emitChunkDiscoveredEvent(chunkHash, m.nodeID, p2p.ChunkPriority_medium)
@@ -47,6 +54,9 @@ func (m *MeshCoordinator) UnregisterChunk(ctx context.Context, chunkHash string)
 	m.localChunksMu.Lock()
 	delete(m.localChunks, chunkHash)
 	m.localChunksMu.Unlock()
+	m.storageQuota.Forget(chunkHash)
+	// A withdrawn chunk must not be announced when we come back online.
+	m.offlineQueue.Cancel("chunk:" + chunkHash)
 	return m.dht.RemoveChunkPeer(chunkHash, m.nodeID)
 }
 
@@ -69,13 +79,13 @@ func (m *MeshCoordinator) ScheduleChunkPrefetch(ctx context.Context, chunkHashes
 		go func() {
 			pctx, cancel := context.WithTimeout(ctx, timeout)
 			defer cancel()
-			data, err := m.FetchChunk(pctx, chunkHash)
+			data, err := m.fetchStoredChunk(pctx, chunkHash)
 			if err != nil {
 				m.logger.Debug("prefetch failed", "chunk", getShortID(chunkHash), "error", err)
 				return
 			}
-			if m.storage != nil {
-				_ = m.storage.StoreChunk(pctx, chunkHash, data)
+			if m.storage != nil && m.storage.StoreChunk(pctx, chunkHash, data) == nil {
+				m.trackStoredChunk(pctx, chunkHash, len(data))
 			}
 		}()
 	}
@@ -127,15 +137,19 @@ func (m *MeshCoordinator) ConnectToPeer(ctx context.Context, peerID string, addr
 			}
 		}
 	}
-	if len(bootstrapAddresses) > 0 {
-		if bootstrapper, ok := m.transport.(interface {
-			AddSignalingServer(server string) error
-		}); ok {
-			for _, server := range bootstrapAddresses {
-				if err := bootstrapper.AddSignalingServer(server); err != nil {
+	for _, server := range bootstrapAddresses {
+		if transport.IsBridgeAddress(server) {
+			if bridger, ok := m.transport.(interface {
+				SetPeerAddress(peerID, addr string) error
+			}); ok {
+				if err := bridger.SetPeerAddress(peerID, server); err != nil {
 					return err
 				}
 			}
+			continue
+		}
+		if err := m.addSignalingServer(server); err != nil {
+			return err
 		}
 	}
 
@@ -152,7 +166,13 @@ func (m *MeshCoordinator) ConnectToPeer(ctx context.Context, peerID string, addr
 		connCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 		defer cancel()
 
-		if err := m.transport.Connect(connCtx, peerID); err != nil {
+		err := m.transport.Connect(connCtx, peerID)
+		if err != nil && m.ConnectViaRelay(connCtx, peerID) == nil {
+			// Unreachable directly, e.g. behind a symmetric NAT
+			m.logger.Info("connected through relay", "peer", peerID)
+			err = nil
+		}
+		if err != nil {
 			m.logger.Error("async connection failed", "peer", peerID, "error", err)
 			m.emitPeerUpdateEvent(&PeerCapability{
 				PeerID:          peerID,
@@ -175,3 +195,31 @@ func (m *MeshCoordinator) DisconnectFromPeer(peerID string) error {
 	}
 	return nil
 }
+
+// GetConnectivityReport returns NAT traversal diagnostics from the transport.
+// When refresh is set, or no report exists yet, a new ICE gathering probe runs.
+func (m *MeshCoordinator) GetConnectivityReport(ctx context.Context, refresh bool) (*transport.ConnectivityReport, error) {
+	prober, ok := m.transport.(connectivityProber)
+	if !ok {
+		return nil, errors.New("transport does not support connectivity probing")
+	}
+	if !refresh {
+		if report := prober.GetConnectivityReport(); report != nil {
+			return report, nil
+		}
+	}
+	return prober.ProbeConnectivity(ctx)
+}
+
+// LastConnectivityReport returns the cached diagnostics without probing.
+func (m *MeshCoordinator) LastConnectivityReport() *transport.ConnectivityReport {
+	if prober, ok := m.transport.(connectivityProber); ok {
+		return prober.GetConnectivityReport()
+	}
+	return nil
+}
+
+type connectivityProber interface {
+	ProbeConnectivity(ctx context.Context) (*transport.ConnectivityReport, error)
+	GetConnectivityReport() *transport.ConnectivityReport
+}
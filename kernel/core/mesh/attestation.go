@@ -30,6 +30,9 @@ type AttestationChallenge struct {
 	SabOffset   uint32 `json:"sab_offset"`
 	SabLength   uint32 `json:"sab_length"`
 	KnownRegions []AttestationRegion `json:"known_regions"`
+	// PoWDifficulty asks the responder for an admission proof-of-work of
+	// this many bits (see sybil_admission.go).
+	PoWDifficulty int `json:"pow_difficulty,omitempty"`
 }
 
 type AttestationResponse struct {
@@ -42,6 +45,15 @@ type AttestationResponse struct {
 	Signature   string `json:"signature"`
 	SabHash     string `json:"sab_hash"`
 	RegionHashes map[string]string `json:"region_hashes"`
+	// DID is self-asserted and outside the signed payload; it is only used
+	// for admission matching, never as proof of identity.
+	DID string `json:"did,omitempty"`
+	// DIDBinding, when present, is the node's signed claim to DID and lets
+	// the requester mark the DID as verified.
+	DIDBinding *DIDBinding `json:"did_binding,omitempty"`
+	// AdmissionPoW is a nonce whose work is bound to PublicKey, so it needs
+	// no signature of its own.
+	AdmissionPoW uint64 `json:"admission_pow,omitempty"`
 }
 
 type AttestationRegion struct {
@@ -51,12 +63,15 @@ type AttestationRegion struct {
 }
 
 type AttestationRecord struct {
-	PublicKey ed25519.PublicKey
-	Attested  time.Time
+	PublicKey   ed25519.PublicKey
+	Attested    time.Time
+	DID         string
+	DIDVerified bool
+	PoWBits     int // Admission work shown for PublicKey
 }
 
 func (m *MeshCoordinator) registerAttestationHandler() {
-	m.transport.RegisterRPCHandler(attestationMethod, func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error) {
+	m.registerRPC(attestationMethod, func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error) {
 		var challenge AttestationChallenge
 		if err := json.Unmarshal(args, &challenge); err != nil {
 			return nil, fmt.Errorf("attestation challenge decode failed: %w", err)
@@ -91,10 +106,24 @@ func (m *MeshCoordinator) registerAttestationHandler() {
 		}
 
 		payload := attestationPayload(challenge, sabHash, regionHashes)
+		m.identityMu.RLock()
+		did := m.did
+		m.identityMu.RUnlock()
 		signature, publicKey, err := m.gossip.SignAttestation(payload)
 		if err != nil {
 			return nil, fmt.Errorf("attestation signing failed: %w", err)
 		}
+		var binding *DIDBinding
+		if identity := m.NodeIdentity(); identity != nil {
+			if b := identity.DIDBinding(); b != nil && b.DID == did {
+				binding = b
+			}
+		}
+
+		var pow uint64
+		if challenge.PoWDifficulty > 0 {
+			pow = m.admissionProof(publicKey, challenge.PoWDifficulty)
+		}
 
 		return AttestationResponse{
 			Version:     challenge.Version,
@@ -106,6 +135,9 @@ func (m *MeshCoordinator) registerAttestationHandler() {
 			Signature:   base64.StdEncoding.EncodeToString(signature),
 			SabHash:     base64.StdEncoding.EncodeToString(sabHash),
 			RegionHashes: encodeRegionHashes(regionHashes),
+			DID:          did,
+			DIDBinding:   binding,
+			AdmissionPoW: pow,
 		}, nil
 	})
 }
@@ -137,19 +169,37 @@ func (m *MeshCoordinator) startPeerAttestation(peerID string) {
 		m.attestationMu.Lock()
 		m.attestedPeers[peerID] = record
 		m.attestationMu.Unlock()
+		m.gossip.SetPeerIdentityKey(peerID, record.PublicKey)
 
 		m.acceptConnectedPeer(peerID)
 	}()
 }
 
 func (m *MeshCoordinator) acceptConnectedPeer(peerID string) {
-	_ = m.dht.AddPeer(PeerInfo{ID: peerID})
-	m.gossip.AddPeer(peerID)
+	if !m.admitPeer(peerID) {
+		return
+	}
+	info := PeerInfo{ID: peerID}
+	m.attestationMu.RLock()
+	if record, ok := m.attestedPeers[peerID]; ok {
+		info.PublicKey = record.PublicKey
+	}
+	m.attestationMu.RUnlock()
+	_ = m.dht.AddPeer(info)
+	if !m.isPeerQuarantined(peerID) {
+		if sector, ok := m.peerSector(peerID); ok {
+			m.gossip.SetPeerSector(peerID, sector)
+		}
+		m.gossip.AddPeer(peerID)
+	}
 	m.emitPeerUpdateEvent(&PeerCapability{
 		PeerID:          peerID,
 		ConnectionState: ConnectionStateConnected,
 		LastSeen:        time.Now().UnixNano(),
 	})
+	if m.offlineQueue.Len() > 0 {
+		go m.replayOfflineQueue()
+	}
 }
 
 func (m *MeshCoordinator) clearPeerAttestation(peerID string) {
@@ -234,6 +284,7 @@ func (m *MeshCoordinator) requestAttestation(peerID string) (AttestationRecord,
 		SabOffset:   sabOffset,
 		SabLength:   sabLength,
 		KnownRegions: knownRegions,
+		PoWDifficulty: m.requestedPoWBits(),
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), m.config.AttestationTimeout)
@@ -255,6 +306,9 @@ func (m *MeshCoordinator) requestAttestation(peerID string) (AttestationRecord,
 	if len(pubKeyBytes) != ed25519.PublicKeySize {
 		return AttestationRecord{}, errors.New("attestation public key has invalid size")
 	}
+	if m.IsKeyRevoked(ed25519.PublicKey(pubKeyBytes)) {
+		return AttestationRecord{}, errors.New("attestation public key has been revoked")
+	}
 
 	sigBytes, err := base64.StdEncoding.DecodeString(response.Signature)
 	if err != nil {
@@ -276,10 +330,22 @@ func (m *MeshCoordinator) requestAttestation(peerID string) (AttestationRecord,
 		return AttestationRecord{}, errors.New("attestation signature verification failed")
 	}
 
-	return AttestationRecord{
+	record := AttestationRecord{
 		PublicKey: ed25519.PublicKey(pubKeyBytes),
 		Attested:  time.Now(),
-	}, nil
+		DID:       response.DID,
+		PoWBits:   admissionPoWBits(ed25519.PublicKey(pubKeyBytes), response.AdmissionPoW),
+	}
+	if response.DIDBinding != nil {
+		if response.DIDBinding.DID != response.DID {
+			return AttestationRecord{}, errors.New("DID binding does not match asserted DID")
+		}
+		if err := response.DIDBinding.Verify(response.PeerID, record.PublicKey); err != nil {
+			return AttestationRecord{}, err
+		}
+		record.DIDVerified = true
+	}
+	return record, nil
 }
 
 func validateAttestationResponse(challenge AttestationChallenge, response AttestationResponse) error {
@@ -0,0 +1,250 @@
+// Package audit verifies a node's exported audit log and ledger offline.
+//
+// Nothing here needs the mesh or the node's keys beyond what the export
+// carries: the delegation log is checked for hash-chain integrity and
+// signatures, settled escrows for the node's receipt signature, and the
+// ledger totals are reconciled against the escrows they summarize.
+package audit
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh"
+)
+
+// Finding severities. Any error makes the report invalid; warnings flag
+// evidence that is missing rather than wrong.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Checks a finding can come from.
+const (
+	CheckChain     = "chain"
+	CheckSignature = "signature"
+	CheckReceipt   = "receipt"
+	CheckBalance   = "balance"
+)
+
+// Finding is one problem the verifier found.
+type Finding struct {
+	Severity string `json:"severity"`
+	Check    string `json:"check"`
+	Subject  string `json:"subject,omitempty"` // Request or escrow ID, or account
+	Detail   string `json:"detail"`
+}
+
+// Report is the outcome of verifying an export.
+type Report struct {
+	NodeID string `json:"node_id"`
+	Valid  bool   `json:"valid"`
+
+	AuditRecords   int  `json:"audit_records"`
+	ChainedRecords int  `json:"chained_records"` // Records whose hash and link verify
+	SignedRecords  int  `json:"signed_records"`  // Records whose signatures verify
+	Truncated      bool `json:"truncated"`       // The log starts after dropped records
+
+	Escrows         int    `json:"escrows"`
+	Settlements     int    `json:"settlements"`      // Released, refunded or expired escrows
+	SignedReceipts  int    `json:"signed_receipts"`  // Settlements whose signature verifies
+	Balances        int64  `json:"balances"`         // Sum of account balances
+	OutstandingHeld uint64 `json:"outstanding_held"` // Credits still locked in escrow
+
+	Findings []Finding `json:"findings"`
+}
+
+func (r *Report) add(severity, check, subject, format string, args ...interface{}) {
+	r.Findings = append(r.Findings, Finding{
+		Severity: severity,
+		Check:    check,
+		Subject:  subject,
+		Detail:   fmt.Sprintf(format, args...),
+	})
+}
+
+// Errors returns the findings that invalidate the export.
+func (r *Report) Errors() []Finding {
+	var out []Finding
+	for _, f := range r.Findings {
+		if f.Severity == SeverityError {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// Load decodes an export written from MeshCoordinator.ExportAudit.
+func Load(r io.Reader) (*mesh.AuditExport, error) {
+	var export mesh.AuditExport
+	if err := json.NewDecoder(r).Decode(&export); err != nil {
+		return nil, fmt.Errorf("invalid audit export: %w", err)
+	}
+	if export.Version != mesh.AuditExportVersion {
+		return nil, fmt.Errorf("unsupported audit export version %d", export.Version)
+	}
+	return &export, nil
+}
+
+// LoadFile reads an export from path.
+func LoadFile(path string) (*mesh.AuditExport, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Load(f)
+}
+
+// Verify runs every check against an export.
+func Verify(export *mesh.AuditExport) *Report {
+	report := &Report{NodeID: export.NodeID, Findings: []Finding{}}
+	verifyChain(export, report)
+	verifyReceipts(export, report)
+	reconcileLedger(export, report)
+	report.Valid = len(report.Errors()) == 0
+	return report
+}
+
+func verifyChain(export *mesh.AuditExport, report *Report) {
+	report.AuditRecords = len(export.Audit)
+	for i := range export.Audit {
+		rec := &export.Audit[i]
+
+		switch {
+		case len(rec.Hash) == 0:
+			report.add(SeverityWarning, CheckChain, rec.RequestID, "record %d is not chained", i)
+		case !bytes.Equal(rec.ComputeHash(), rec.Hash):
+			report.add(SeverityError, CheckChain, rec.RequestID, "record %d does not match its hash", i)
+		case i == 0:
+			report.Truncated = len(rec.PrevHash) > 0
+			report.ChainedRecords++
+		case !bytes.Equal(rec.PrevHash, export.Audit[i-1].Hash):
+			report.add(SeverityError, CheckChain, rec.RequestID, "record %d does not link to record %d; records were removed or reordered", i, i-1)
+		default:
+			report.ChainedRecords++
+		}
+
+		if len(rec.RequestSignature) == 0 {
+			report.add(SeverityWarning, CheckSignature, rec.RequestID, "record %d is unsigned", i)
+			continue
+		}
+		if err := rec.Verify(); err != nil {
+			report.add(SeverityError, CheckSignature, rec.RequestID, "record %d: %v", i, err)
+			continue
+		}
+		report.SignedRecords++
+	}
+}
+
+func verifyReceipts(export *mesh.AuditExport, report *Report) {
+	keys := append([]ed25519.PublicKey{export.PublicKey}, export.PriorKeys...)
+	report.Escrows = len(export.Ledger.Escrows)
+
+	for i := range export.Ledger.Escrows {
+		escrow := &export.Ledger.Escrows[i]
+		switch escrow.Status {
+		case mesh.EscrowReleased, mesh.EscrowRefunded, mesh.EscrowExpired:
+		default:
+			continue
+		}
+		report.Settlements++
+
+		if escrow.Status == mesh.EscrowReleased && escrow.ProviderID == "" {
+			report.add(SeverityError, CheckReceipt, escrow.ID, "released escrow names no provider")
+		}
+		if len(escrow.Signature) == 0 {
+			report.add(SeverityWarning, CheckReceipt, escrow.ID, "settlement is unsigned")
+			continue
+		}
+		if !signedByAny(keys, mesh.SettlementDigest(escrow), escrow.Signature) {
+			report.add(SeverityError, CheckReceipt, escrow.ID, "settlement signature does not verify against the node's keys")
+			continue
+		}
+		report.SignedReceipts++
+	}
+}
+
+// reconcileLedger checks the lifetime totals against the escrows they
+// count. Escrows are never deleted, so every credit locked, released or
+// refunded is accounted for by one of them.
+func reconcileLedger(export *mesh.AuditExport, report *Report) {
+	ledger := export.Ledger
+	var escrowed, settled, refunded, settlements uint64
+	for _, escrow := range ledger.Escrows {
+		escrowed += escrow.Amount
+		switch escrow.Status {
+		case mesh.EscrowReleased:
+			settled += escrow.Amount
+			settlements++
+		case mesh.EscrowRefunded, mesh.EscrowExpired:
+			refunded += escrow.Amount
+		default:
+			report.OutstandingHeld += escrow.Amount
+		}
+	}
+
+	check := func(name string, want, got uint64) {
+		if want != got {
+			report.add(SeverityError, CheckBalance, "", "ledger reports %d %s, escrows add up to %d", want, name, got)
+		}
+	}
+	check("escrowed", ledger.Totals.Escrowed, escrowed)
+	check("settled", ledger.Totals.Settled, settled)
+	check("refunded", ledger.Totals.Refunded, refunded)
+	check("settlements", ledger.Totals.Settlements, settlements)
+
+	accounts := make([]string, 0, len(ledger.Balances))
+	for account := range ledger.Balances {
+		accounts = append(accounts, account)
+	}
+	sort.Strings(accounts)
+	for _, account := range accounts {
+		balance := ledger.Balances[account]
+		report.Balances += balance
+		if balance < 0 {
+			report.add(SeverityError, CheckBalance, account, "negative balance %d", balance)
+		}
+	}
+}
+
+func signedByAny(keys []ed25519.PublicKey, digest, signature []byte) bool {
+	for _, key := range keys {
+		if len(key) == ed25519.PublicKeySize && ed25519.Verify(key, digest, signature) {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteText writes a human-readable report.
+func (r *Report) WriteText(w io.Writer) error {
+	status := "VALID"
+	if !r.Valid {
+		status = "INVALID"
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "audit export for %s: %s\n", r.NodeID, status)
+	fmt.Fprintf(&buf, "  audit log:   %d records, %d chained, %d signed", r.AuditRecords, r.ChainedRecords, r.SignedRecords)
+	if r.Truncated {
+		buf.WriteString(" (starts after dropped records)")
+	}
+	buf.WriteByte('\n')
+	fmt.Fprintf(&buf, "  settlements: %d of %d escrows, %d signed receipts\n", r.Settlements, r.Escrows, r.SignedReceipts)
+	fmt.Fprintf(&buf, "  ledger:      %d credits in balances, %d held in escrow\n", r.Balances, r.OutstandingHeld)
+	for _, f := range r.Findings {
+		subject := ""
+		if f.Subject != "" {
+			subject = " [" + f.Subject + "]"
+		}
+		fmt.Fprintf(&buf, "  %-7s %s%s: %s\n", f.Severity, f.Check, subject, f.Detail)
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
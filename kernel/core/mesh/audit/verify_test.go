@@ -0,0 +1,151 @@
+package audit
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh"
+)
+
+// buildExport returns an export round-tripped through JSON, as the
+// verifier would read it from disk.
+func buildExport(t *testing.T) *mesh.AuditExport {
+	t.Helper()
+	identity, err := mesh.NewEphemeralNodeIdentity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, priv := identity.PublicKey(), identity.PrivateKey()
+
+	var records []mesh.DelegationAuditRecord
+	for i := 0; i < 3; i++ {
+		payload := []byte(fmt.Sprintf("request-%d", i))
+		rec := mesh.DelegationAuditRecord{
+			RequestID:        fmt.Sprintf("req-%d", i),
+			Role:             mesh.DelegationRoleRequester,
+			Requester:        identity.NodeID(),
+			RequesterKey:     pub,
+			RequestPayload:   payload,
+			RequestSignature: ed25519.Sign(priv, payload),
+			RecordedAt:       time.Now(),
+		}
+		if i > 0 {
+			rec.PrevHash = records[i-1].Hash
+		}
+		rec.Hash = rec.ComputeHash()
+		records = append(records, rec)
+	}
+
+	ledger := mesh.NewEconomicLedger()
+	ledger.SetSigner(identity.Sign)
+	ledger.RegisterAccount("requester", 1000)
+	for i, release := range []bool{true, false, true} {
+		escrow, err := ledger.CreateEscrow(fmt.Sprintf("escrow-%d", i), "requester", 100, time.Minute, "job")
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = ledger.AssignProvider(escrow.ID, "provider")
+		if release {
+			_ = ledger.ReleaseToProvider(escrow.ID, true)
+		} else {
+			_ = ledger.RefundToRequester(escrow.ID)
+		}
+	}
+	if _, err := ledger.CreateEscrow("escrow-open", "requester", 50, time.Minute, "job"); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := json.Marshal(mesh.AuditExport{
+		Version:    mesh.AuditExportVersion,
+		NodeID:     identity.NodeID(),
+		PublicKey:  pub,
+		Audit:      records,
+		Ledger:     ledger.Snapshot(),
+		ExportedAt: time.Now(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	export, err := Load(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return export
+}
+
+func TestVerify_AcceptsIntactExport(t *testing.T) {
+	report := Verify(buildExport(t))
+	if !report.Valid {
+		t.Fatalf("expected valid export, got %+v", report.Findings)
+	}
+	if report.ChainedRecords != 3 || report.SignedRecords != 3 || report.Truncated {
+		t.Fatalf("unexpected audit summary %+v", report)
+	}
+	if report.Settlements != 3 || report.SignedReceipts != 3 || report.OutstandingHeld != 50 {
+		t.Fatalf("unexpected ledger summary %+v", report)
+	}
+	if report.Balances+int64(report.OutstandingHeld) != 1000 {
+		t.Fatalf("credits not conserved: %d in balances, %d held", report.Balances, report.OutstandingHeld)
+	}
+
+	var out bytes.Buffer
+	if err := report.WriteText(&out); err != nil || !bytes.Contains(out.Bytes(), []byte("VALID")) {
+		t.Fatalf("unexpected text report %q (%v)", out.String(), err)
+	}
+}
+
+func TestVerify_DetectsRemovedAndEditedRecords(t *testing.T) {
+	export := buildExport(t)
+	export.Audit = append(export.Audit[:1], export.Audit[2:]...)
+	report := Verify(export)
+	if report.Valid || report.Errors()[0].Check != CheckChain {
+		t.Fatalf("expected a chain break, got %+v", report.Findings)
+	}
+
+	export = buildExport(t)
+	export.Audit[1].Operation = "rewritten"
+	if report := Verify(export); report.Valid {
+		t.Fatal("expected an edited record to break its hash")
+	}
+
+	// Dropping the oldest records, as the bounded log does, is not tampering.
+	export = buildExport(t)
+	export.Audit = export.Audit[1:]
+	if report := Verify(export); !report.Valid || !report.Truncated {
+		t.Fatalf("expected a valid truncated log, got %+v", report)
+	}
+}
+
+func TestVerify_DetectsForgedReceiptsAndBalances(t *testing.T) {
+	export := buildExport(t)
+	for i := range export.Ledger.Escrows {
+		if export.Ledger.Escrows[i].Status == mesh.EscrowReleased {
+			export.Ledger.Escrows[i].Amount = 1000
+			break
+		}
+	}
+	report := Verify(export)
+	checks := map[string]bool{}
+	for _, f := range report.Errors() {
+		checks[f.Check] = true
+	}
+	if report.Valid || !checks[CheckReceipt] || !checks[CheckBalance] {
+		t.Fatalf("expected receipt and balance errors, got %+v", report.Findings)
+	}
+
+	_, otherKey, _ := ed25519.GenerateKey(rand.Reader)
+	export = buildExport(t)
+	export.PublicKey = otherKey.Public().(ed25519.PublicKey)
+	if report := Verify(export); report.Valid {
+		t.Fatal("expected receipts signed by another key to fail")
+	}
+	export.PriorKeys = []ed25519.PublicKey{export.Audit[0].RequesterKey}
+	if report := Verify(export); !report.Valid {
+		t.Fatalf("expected receipts signed by a prior key to verify, got %+v", report.Findings)
+	}
+}
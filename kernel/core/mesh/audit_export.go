@@ -0,0 +1,57 @@
+package mesh
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"sort"
+	"time"
+)
+
+// AuditExportVersion is the export format written by ExportAudit.
+const AuditExportVersion = 1
+
+// AuditExport bundles the evidence a node keeps about its own economic
+// activity: the chained delegation audit log and a ledger snapshot with
+// signed settlements. The core/mesh/audit package verifies it offline.
+type AuditExport struct {
+	Version    int                     `json:"version"`
+	NodeID     string                  `json:"node_id"`
+	PublicKey  ed25519.PublicKey       `json:"public_key"`
+	PriorKeys  []ed25519.PublicKey     `json:"prior_keys,omitempty"` // Keys this node rotated away from, oldest first
+	Audit      []DelegationAuditRecord `json:"audit"`
+	Ledger     LedgerSnapshot          `json:"ledger"`
+	ExportedAt time.Time               `json:"exported_at"`
+}
+
+// ExportAudit snapshots the audit log and ledger for offline verification.
+func (m *MeshCoordinator) ExportAudit() AuditExport {
+	export := AuditExport{
+		Version:    AuditExportVersion,
+		NodeID:     m.nodeID,
+		Audit:      m.GetDelegationAuditLog(),
+		ExportedAt: time.Now().UTC(),
+	}
+	if m.gossip != nil {
+		export.PublicKey = m.gossip.PublicKey()
+	}
+	if m.ledger != nil {
+		export.Ledger = m.ledger.Snapshot()
+		sort.Slice(export.Ledger.Escrows, func(i, j int) bool {
+			return export.Ledger.Escrows[i].CreatedAt.Before(export.Ledger.Escrows[j].CreatedAt)
+		})
+	}
+
+	var own []KeyRevocation
+	for _, revocation := range m.GetKeyRevocations() {
+		if revocation.PeerID == m.nodeID {
+			own = append(own, revocation)
+		}
+	}
+	sort.Slice(own, func(i, j int) bool { return own[i].RevokedAt.Before(own[j].RevokedAt) })
+	for _, revocation := range own {
+		if key, err := base64.StdEncoding.DecodeString(revocation.PublicKey); err == nil && len(key) == ed25519.PublicKeySize {
+			export.PriorKeys = append(export.PriorKeys, ed25519.PublicKey(key))
+		}
+	}
+	return export
+}
@@ -0,0 +1,39 @@
+package mesh
+
+// backgroundTransport is implemented by transports that slow their
+// keepalives while the host page is hidden.
+type backgroundTransport interface {
+	SetBackground(background bool)
+}
+
+// SetBackgroundMode is called when the host page is hidden or shown again.
+// While hidden, periodic background work (metrics gossip, storage
+// challenges, probes, republishing, repair) skips its ticks, gossip rounds
+// and anti-entropy pause, and keepalives stretch. Incoming messages and RPCs
+// are still served.
+func (m *MeshCoordinator) SetBackgroundMode(background bool) {
+	if m.background.Swap(background) == background {
+		return
+	}
+	if m.gossip != nil {
+		m.gossip.SetSuspended(background)
+	}
+	if bt, ok := m.transport.(backgroundTransport); ok {
+		bt.SetBackground(background)
+	}
+	m.logger.Info("background mode changed", "background", background)
+	m.publishEvent(MeshEventBackgroundChanged, "", map[string]interface{}{
+		"background": background,
+	})
+}
+
+// InBackground reports whether the mesh is in background mode.
+func (m *MeshCoordinator) InBackground() bool {
+	return m.background.Load()
+}
+
+// BackgroundTicks returns how many background ticks were skipped while the
+// host page was hidden.
+func (m *MeshCoordinator) BackgroundTicks() uint64 {
+	return m.backgroundTicks.Load()
+}
@@ -0,0 +1,28 @@
+package mesh
+
+import "testing"
+
+func TestBackgroundMode_SkipsBackgroundTicks(t *testing.T) {
+	coord, _ := newDelegationTestCoordinator(t)
+
+	if coord.deferLowPriority() {
+		t.Fatal("foreground node should not defer")
+	}
+
+	coord.SetBackgroundMode(true)
+	if !coord.InBackground() {
+		t.Fatal("expected background mode")
+	}
+	if !coord.deferLowPriority() || !coord.deferLowPriority() {
+		t.Fatal("hidden page should skip background ticks")
+	}
+	if coord.BackgroundTicks() != 2 || coord.DeferredTicks() != 0 {
+		t.Fatalf("expected skips counted as background ticks, got background=%d deferred=%d",
+			coord.BackgroundTicks(), coord.DeferredTicks())
+	}
+
+	coord.SetBackgroundMode(false)
+	if coord.deferLowPriority() {
+		t.Fatal("foreground should resume background work")
+	}
+}
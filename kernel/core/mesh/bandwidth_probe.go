@@ -0,0 +1,261 @@
+package mesh
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/routing"
+)
+
+const bandwidthProbeMethod = "mesh.BandwidthProbe"
+
+// bandwidthProbeMaxBytes bounds the payload a peer may ask us to echo.
+const bandwidthProbeMaxBytes = 1024 * 1024
+
+// bandwidthMeasurementTTL is how long a probe result stays usable for scoring.
+const bandwidthMeasurementTTL = 15 * time.Minute
+
+// bandwidthDivergenceMinSamples is how many probes must agree before a
+// peer's bandwidth claim is called divergent and penalized.
+const bandwidthDivergenceMinSamples = 3
+
+// errBandwidthProbeTooSmall means the transfer finished within the round
+// trip, so the probe measured latency rather than throughput.
+var errBandwidthProbeTooSmall = errors.New("bandwidth probe too small to measure throughput")
+
+// BandwidthMeasurement tracks actively measured throughput for a peer.
+type BandwidthMeasurement struct {
+	PeerID       string    `json:"peer_id"`
+	MeasuredKbps float32   `json:"measured_kbps"` // EMA of probe results
+	ReportedKbps float32   `json:"reported_kbps"` // Last self-reported value seen
+	Samples      uint32    `json:"samples"`
+	Divergent    bool      `json:"divergent"`
+	LastProbe    time.Time `json:"last_probe"`
+}
+
+type bandwidthProbeRequest struct {
+	Data []byte `json:"data"`
+}
+
+type bandwidthProbeResponse struct {
+	Data []byte `json:"data"`
+	Size int    `json:"size"`
+}
+
+func (m *MeshCoordinator) registerBandwidthProbeHandler() {
+	m.registerRPC(bandwidthProbeMethod, func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error) {
+		var req bandwidthProbeRequest
+		if err := json.Unmarshal(args, &req); err != nil {
+			return nil, fmt.Errorf("failed to decode bandwidth probe: %w", err)
+		}
+		if len(req.Data) > bandwidthProbeMaxBytes {
+			return nil, fmt.Errorf("bandwidth probe too large: %d bytes", len(req.Data))
+		}
+
+		// Echo the payload so the prober measures both directions.
+		return bandwidthProbeResponse{Data: req.Data, Size: len(req.Data)}, nil
+	})
+}
+
+func (m *MeshCoordinator) bandwidthProbeLoop() {
+	if m.config.BandwidthProbe.Interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(m.config.BandwidthProbe.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if !m.deferLowPriority() {
+				m.probeConnectedPeers()
+			}
+		case <-m.shutdown:
+			return
+		}
+	}
+}
+
+// probeConnectedPeers measures throughput to the least recently probed peers.
+func (m *MeshCoordinator) probeConnectedPeers() {
+	peers := m.transport.GetConnectedPeers()
+	if len(peers) == 0 {
+		return
+	}
+
+	m.bandwidthProbesMu.RLock()
+	candidates := make([]string, 0, len(peers))
+	for _, peerID := range peers {
+		if entry, ok := m.bandwidthProbes[peerID]; ok && time.Since(entry.LastProbe) < m.config.BandwidthProbe.Interval {
+			continue
+		}
+		candidates = append(candidates, peerID)
+	}
+	m.bandwidthProbesMu.RUnlock()
+
+	limit := m.config.BandwidthProbe.MaxPeers
+	if limit <= 0 || limit > len(candidates) {
+		limit = len(candidates)
+	}
+
+	for _, peerID := range candidates[:limit] {
+		ctx, cancel := context.WithTimeout(context.Background(), m.config.LookupTimeout)
+		if _, err := m.ProbePeerBandwidth(ctx, peerID); err != nil {
+			m.logger.Debug("bandwidth probe failed", "peer", getShortID(peerID), "error", err)
+		}
+		cancel()
+	}
+}
+
+// ProbePeerBandwidth runs a single bulk transfer against a peer and records the
+// measured throughput in kbps. An empty probe first measures the round trip,
+// which is taken off the transfer time, and throughput counts the encoded
+// bytes that crossed the wire in both directions.
+func (m *MeshCoordinator) ProbePeerBandwidth(ctx context.Context, peerID string) (float32, error) {
+	size := m.config.BandwidthProbe.PayloadBytes
+	if size <= 0 {
+		return 0, errors.New("bandwidth probing disabled")
+	}
+	if size > bandwidthProbeMaxBytes {
+		size = bandwidthProbeMaxBytes
+	}
+
+	payload := make([]byte, size)
+	if _, err := rand.Read(payload); err != nil {
+		return 0, fmt.Errorf("failed to generate probe payload: %w", err)
+	}
+
+	rtt, _, err := m.sendBandwidthProbe(ctx, peerID, nil)
+	if err != nil {
+		return 0, err
+	}
+	elapsed, wireBytes, err := m.sendBandwidthProbe(ctx, peerID, payload)
+	if err != nil {
+		return 0, err
+	}
+
+	transfer := (elapsed - rtt).Seconds()
+	if transfer <= 0 {
+		return 0, errBandwidthProbeTooSmall
+	}
+	kbps := float32(float64(wireBytes*8) / 1000.0 / transfer)
+
+	m.recordBandwidthSample(peerID, kbps)
+	return kbps, nil
+}
+
+// sendBandwidthProbe has peerID echo payload and returns how long the round
+// trip took and how many encoded bytes it moved.
+func (m *MeshCoordinator) sendBandwidthProbe(ctx context.Context, peerID string, payload []byte) (time.Duration, int, error) {
+	request, err := json.Marshal(bandwidthProbeRequest{Data: payload})
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to encode probe payload: %w", err)
+	}
+
+	start := time.Now()
+	var raw json.RawMessage
+	if err := m.transport.SendRPC(ctx, peerID, bandwidthProbeMethod, json.RawMessage(request), &raw); err != nil {
+		m.recordRPCFailure(peerID, bandwidthProbeMethod, err)
+		return 0, 0, err
+	}
+	elapsed := time.Since(start)
+
+	var resp bandwidthProbeResponse
+	if err := json.Unmarshal(raw, &resp); err != nil || resp.Size != len(payload) || len(resp.Data) != len(payload) {
+		m.reputation.ReportPenalty(peerID, routing.PenaltyInvalidData)
+		return 0, 0, fmt.Errorf("bandwidth probe echo mismatch: got=%d want=%d", len(resp.Data), len(payload))
+	}
+	return elapsed, len(request) + len(raw), nil
+}
+
+func (m *MeshCoordinator) recordBandwidthSample(peerID string, kbps float32) {
+	var reported float32
+	if cached := m.getCachedPeer(peerID); cached != nil {
+		reported = cached.BandwidthKbps
+	}
+
+	m.bandwidthProbesMu.Lock()
+	entry, ok := m.bandwidthProbes[peerID]
+	if !ok {
+		entry = &BandwidthMeasurement{PeerID: peerID}
+		m.bandwidthProbes[peerID] = entry
+	}
+	if entry.Samples == 0 {
+		entry.MeasuredKbps = kbps
+	} else {
+		entry.MeasuredKbps = 0.3*kbps + 0.7*entry.MeasuredKbps
+	}
+	entry.Samples++
+	entry.LastProbe = time.Now()
+	if reported > 0 {
+		entry.ReportedKbps = reported
+	}
+	wasDivergent := entry.Divergent
+	entry.Divergent = m.isBandwidthClaimDivergent(entry)
+	divergent := entry.Divergent
+	measured := entry.MeasuredKbps
+	m.bandwidthProbesMu.Unlock()
+
+	if divergent && !wasDivergent {
+		m.logger.Warn("peer bandwidth claim diverges from measurement",
+			"peer", getShortID(peerID),
+			"reported_kbps", reported,
+			"measured_kbps", measured)
+		m.reputation.ReportPenalty(peerID, routing.PenaltyInvalidData)
+	}
+}
+
+// isBandwidthClaimDivergent requires bandwidthDivergenceMinSamples samples so
+// a few slow probes on a congested link do not cost the peer reputation.
+func (m *MeshCoordinator) isBandwidthClaimDivergent(entry *BandwidthMeasurement) bool {
+	ratio := m.config.BandwidthProbe.DivergenceRatio
+	if ratio <= 1 || entry.Samples < bandwidthDivergenceMinSamples || entry.ReportedKbps <= 0 || entry.MeasuredKbps <= 0 {
+		return false
+	}
+	return float64(entry.ReportedKbps) > float64(entry.MeasuredKbps)*ratio
+}
+
+// effectiveBandwidthKbps blends the self-reported bandwidth with the measured
+// value. Trusted peers keep more of their claim; divergent peers lose it entirely.
+func (m *MeshCoordinator) effectiveBandwidthKbps(peer *PeerCapability) float32 {
+	m.bandwidthProbesMu.RLock()
+	entry, ok := m.bandwidthProbes[peer.PeerID]
+	var measurement BandwidthMeasurement
+	if ok {
+		measurement = *entry
+	}
+	m.bandwidthProbesMu.RUnlock()
+
+	if !ok || measurement.Samples == 0 || time.Since(measurement.LastProbe) > bandwidthMeasurementTTL {
+		return peer.BandwidthKbps
+	}
+	if peer.BandwidthKbps <= 0 || measurement.Divergent {
+		return measurement.MeasuredKbps
+	}
+
+	score, confidence := m.reputation.GetTrustScore(peer.PeerID)
+	trust := float32(score * confidence)
+	if trust < 0 {
+		trust = 0
+	} else if trust > 1 {
+		trust = 1
+	}
+	return trust*peer.BandwidthKbps + (1-trust)*measurement.MeasuredKbps
+}
+
+// GetBandwidthMeasurement returns the latest probe result for a peer.
+func (m *MeshCoordinator) GetBandwidthMeasurement(peerID string) (BandwidthMeasurement, bool) {
+	m.bandwidthProbesMu.RLock()
+	defer m.bandwidthProbesMu.RUnlock()
+
+	entry, ok := m.bandwidthProbes[peerID]
+	if !ok {
+		return BandwidthMeasurement{}, false
+	}
+	return *entry, true
+}
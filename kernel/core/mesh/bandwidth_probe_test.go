@@ -0,0 +1,115 @@
+package mesh
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// echoWithLatency answers bandwidth probes after a fixed round trip, plus
+// extra for any payload, as a link with that latency and throughput would.
+func echoWithLatency(latency, transfer time.Duration) func(args interface{}) (interface{}, error) {
+	return func(args interface{}) (interface{}, error) {
+		data, _ := json.Marshal(args)
+		var req bandwidthProbeRequest
+		if err := json.Unmarshal(data, &req); err != nil {
+			return nil, err
+		}
+		time.Sleep(latency)
+		if len(req.Data) > 0 {
+			time.Sleep(transfer)
+		}
+		return bandwidthProbeResponse{Data: req.Data, Size: len(req.Data)}, nil
+	}
+}
+
+func TestMeshCoordinator_ProbePeerBandwidthRecordsMeasurement(t *testing.T) {
+	tr := &MockTransport{nodeID: "node-a", rpcHandlers: map[string]func(args interface{}) (interface{}, error){
+		bandwidthProbeMethod: echoWithLatency(0, 5*time.Millisecond),
+	}}
+	coord := NewMeshCoordinator("node-a", "us-east", tr, nil)
+	coord.config.BandwidthProbe.PayloadBytes = 4096
+
+	kbps, err := coord.ProbePeerBandwidth(context.Background(), "peer-1")
+	if err != nil {
+		t.Fatalf("ProbePeerBandwidth failed: %v", err)
+	}
+	if kbps <= 0 {
+		t.Fatalf("expected positive throughput, got %f", kbps)
+	}
+
+	m, ok := coord.GetBandwidthMeasurement("peer-1")
+	if !ok {
+		t.Fatal("expected measurement to be recorded")
+	}
+	if m.Samples != 1 {
+		t.Fatalf("expected 1 sample, got %d", m.Samples)
+	}
+}
+
+func TestMeshCoordinator_BandwidthClaimDivergenceFlagsPeer(t *testing.T) {
+	tr := &MockTransport{nodeID: "node-a"}
+	coord := NewMeshCoordinator("node-a", "us-east", tr, nil)
+
+	peer := &PeerCapability{PeerID: "liar", BandwidthKbps: 100000}
+	coord.cachePeer(peer.PeerID, peer)
+
+	before, _ := coord.reputation.GetTrustScore("liar")
+	for i := 1; i < bandwidthDivergenceMinSamples; i++ {
+		coord.recordBandwidthSample("liar", 1000)
+		if m, _ := coord.GetBandwidthMeasurement("liar"); m.Divergent {
+			t.Fatalf("%d samples must not flag divergence", i)
+		}
+	}
+	coord.recordBandwidthSample("liar", 1000)
+
+	m, _ := coord.GetBandwidthMeasurement("liar")
+	if !m.Divergent {
+		t.Fatal("expected claim 100x above measurement to be flagged")
+	}
+	after, _ := coord.reputation.GetTrustScore("liar")
+	if after >= before {
+		t.Fatalf("expected reputation penalty, before=%f after=%f", before, after)
+	}
+	if got := coord.effectiveBandwidthKbps(peer); got != m.MeasuredKbps {
+		t.Fatalf("divergent peer should be scored on measured bandwidth, got %f want %f", got, m.MeasuredKbps)
+	}
+}
+
+func TestMeshCoordinator_ProbePeerBandwidthExcludesRoundTrip(t *testing.T) {
+	tr := &MockTransport{nodeID: "node-a", rpcHandlers: map[string]func(args interface{}) (interface{}, error){
+		bandwidthProbeMethod: echoWithLatency(50*time.Millisecond, 10*time.Millisecond),
+	}}
+	coord := NewMeshCoordinator("node-a", "us-east", tr, nil)
+	coord.config.BandwidthProbe.PayloadBytes = 4096
+
+	kbps, err := coord.ProbePeerBandwidth(context.Background(), "peer-1")
+	if err != nil {
+		t.Fatalf("ProbePeerBandwidth failed: %v", err)
+	}
+	// The payload crosses the wire base64-encoded in both directions. Had the
+	// 50ms round trip been counted, throughput would fall well below this.
+	wireKbits := float32(2*4096*4/3*8) / 1000
+	if floor := wireKbits / 0.030; kbps < floor {
+		t.Fatalf("expected the round trip to be excluded, got %f kbps want at least %f", kbps, floor)
+	}
+}
+
+func TestMeshCoordinator_EffectiveBandwidthBlendsWithMeasurement(t *testing.T) {
+	tr := &MockTransport{nodeID: "node-a"}
+	coord := NewMeshCoordinator("node-a", "us-east", tr, nil)
+
+	peer := &PeerCapability{PeerID: "honest", BandwidthKbps: 2000}
+	if got := coord.effectiveBandwidthKbps(peer); got != 2000 {
+		t.Fatalf("unprobed peer should use reported bandwidth, got %f", got)
+	}
+
+	coord.cachePeer(peer.PeerID, peer)
+	coord.recordBandwidthSample("honest", 1000)
+
+	got := coord.effectiveBandwidthKbps(peer)
+	if got < 1000 || got > 2000 {
+		t.Fatalf("expected blended bandwidth between measured and reported, got %f", got)
+	}
+}
@@ -0,0 +1,313 @@
+package mesh
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/transport"
+)
+
+// rendezvousTXTPrefix marks DNS TXT records that carry bootstrap entries.
+const rendezvousTXTPrefix = "inos-peer="
+
+// BootstrapStatus reports the state of mesh bootstrapping.
+type BootstrapStatus struct {
+	Peers         []string      `json:"peers"`
+	Rendezvous    []string      `json:"rendezvous"`
+	Attempts      uint64        `json:"attempts"`
+	LastAttempt   time.Time     `json:"last_attempt"`
+	LastError     string        `json:"last_error,omitempty"`
+	NextBackoff   time.Duration `json:"next_backoff"`
+	Isolated      bool          `json:"isolated"`
+	ConnectedPeer int           `json:"connected_peers"`
+}
+
+// bootstrapEntry is a parsed bootstrap target. Entries take the form
+// "peerID", "peerID@wss://rendezvous", "peerID@inos+wss://bridge" or a
+// bare signaling or bridge URL.
+type bootstrapEntry struct {
+	PeerID  string
+	Address string
+}
+
+type bootstrapState struct {
+	peers       []string
+	rendezvous  []string
+	attempts    uint64
+	lastAttempt time.Time
+	lastError   string
+	backoff     time.Duration
+	resolveTXT  func(ctx context.Context, name string) ([]string, error)
+}
+
+func parseBootstrapEntry(raw string) (bootstrapEntry, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return bootstrapEntry{}, false
+	}
+	if isSignalingAddress(raw) || transport.IsBridgeAddress(raw) {
+		return bootstrapEntry{Address: raw}, true
+	}
+	if peerID, address, ok := strings.Cut(raw, "@"); ok {
+		peerID = strings.TrimSpace(peerID)
+		address = strings.TrimSpace(address)
+		if peerID == "" || !(isSignalingAddress(address) || transport.IsBridgeAddress(address)) {
+			return bootstrapEntry{}, false
+		}
+		return bootstrapEntry{PeerID: peerID, Address: address}, true
+	}
+	return bootstrapEntry{PeerID: raw}, true
+}
+
+func isSignalingAddress(s string) bool {
+	return strings.HasPrefix(s, "ws://") || strings.HasPrefix(s, "wss://") || strings.HasPrefix(s, "gossip://")
+}
+
+// AddBootstrapPeers registers static bootstrap entries used on startup and
+// whenever the node becomes isolated.
+func (m *MeshCoordinator) AddBootstrapPeers(entries ...string) {
+	m.bootstrapMu.Lock()
+	defer m.bootstrapMu.Unlock()
+	for _, raw := range entries {
+		entry := strings.TrimSpace(raw)
+		if _, ok := parseBootstrapEntry(entry); ok && !containsString(m.bootstrap.peers, entry) {
+			m.bootstrap.peers = append(m.bootstrap.peers, entry)
+		}
+	}
+}
+
+// AddRendezvousDomains registers DNS names whose TXT records list bootstrap
+// entries as "inos-peer=<entry>".
+func (m *MeshCoordinator) AddRendezvousDomains(domains ...string) {
+	m.bootstrapMu.Lock()
+	defer m.bootstrapMu.Unlock()
+	for _, domain := range domains {
+		domain = strings.TrimSpace(domain)
+		if domain != "" && !containsString(m.bootstrap.rendezvous, domain) {
+			m.bootstrap.rendezvous = append(m.bootstrap.rendezvous, domain)
+		}
+	}
+}
+
+// Bootstrap connects to the given peers plus any configured static peers and
+// rendezvous records. It returns the number of connection attempts started.
+func (m *MeshCoordinator) Bootstrap(ctx context.Context, peers []string) (int, error) {
+	m.AddBootstrapPeers(peers...)
+
+	m.bootstrapMu.Lock()
+	static := append([]string(nil), m.bootstrap.peers...)
+	domains := append([]string(nil), m.bootstrap.rendezvous...)
+	resolve := m.bootstrap.resolveTXT
+	m.bootstrap.attempts++
+	m.bootstrap.lastAttempt = time.Now()
+	m.bootstrapMu.Unlock()
+
+	entries := make([]bootstrapEntry, 0, len(static))
+	for _, raw := range static {
+		if entry, ok := parseBootstrapEntry(raw); ok {
+			entries = append(entries, entry)
+		}
+	}
+	var resolveErr error
+	for _, domain := range domains {
+		found, err := m.resolveRendezvous(ctx, resolve, domain)
+		if err != nil {
+			m.logger.Debug("rendezvous lookup failed", "domain", domain, "error", err)
+			resolveErr = err
+			continue
+		}
+		entries = append(entries, found...)
+	}
+
+	attempted := 0
+	var lastErr error
+	for _, entry := range entries {
+		if entry.PeerID == m.nodeID {
+			continue
+		}
+		if entry.PeerID == "" && transport.IsBridgeAddress(entry.Address) {
+			if err := m.dialBridge(entry.Address); err != nil {
+				lastErr = err
+				continue
+			}
+			attempted++
+			continue
+		}
+		if entry.PeerID == "" {
+			if err := m.addSignalingServer(entry.Address); err != nil {
+				lastErr = err
+			}
+			continue
+		}
+		if m.transport.IsConnected(entry.PeerID) {
+			continue
+		}
+		var err error
+		if entry.Address != "" {
+			err = m.ConnectToPeer(ctx, entry.PeerID, entry.Address)
+		} else {
+			err = m.ConnectToPeer(ctx, entry.PeerID)
+		}
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		attempted++
+	}
+
+	if lastErr == nil && attempted == 0 && len(entries) == 0 {
+		lastErr = resolveErr
+		if lastErr == nil {
+			lastErr = errors.New("no bootstrap peers configured")
+		}
+	}
+
+	m.bootstrapMu.Lock()
+	m.bootstrap.lastError = ""
+	if lastErr != nil {
+		m.bootstrap.lastError = lastErr.Error()
+	}
+	m.bootstrapMu.Unlock()
+
+	if attempted == 0 && lastErr != nil {
+		return 0, lastErr
+	}
+	return attempted, nil
+}
+
+// GetBootstrapStatus returns bootstrap configuration and progress.
+func (m *MeshCoordinator) GetBootstrapStatus() BootstrapStatus {
+	connected := len(m.transport.GetConnectedPeers())
+
+	m.bootstrapMu.Lock()
+	defer m.bootstrapMu.Unlock()
+	return BootstrapStatus{
+		Peers:         append([]string(nil), m.bootstrap.peers...),
+		Rendezvous:    append([]string(nil), m.bootstrap.rendezvous...),
+		Attempts:      m.bootstrap.attempts,
+		LastAttempt:   m.bootstrap.lastAttempt,
+		LastError:     m.bootstrap.lastError,
+		NextBackoff:   m.bootstrap.backoff,
+		Isolated:      connected == 0,
+		ConnectedPeer: connected,
+	}
+}
+
+func (m *MeshCoordinator) resolveRendezvous(ctx context.Context, resolve func(context.Context, string) ([]string, error), domain string) ([]bootstrapEntry, error) {
+	if resolve == nil {
+		resolve = net.DefaultResolver.LookupTXT
+	}
+	lookupCtx, cancel := context.WithTimeout(ctx, m.config.Bootstrap.DNSTimeout)
+	defer cancel()
+
+	records, err := resolve(lookupCtx, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []bootstrapEntry
+	for _, record := range records {
+		if !strings.HasPrefix(record, rendezvousTXTPrefix) {
+			continue
+		}
+		if entry, ok := parseBootstrapEntry(strings.TrimPrefix(record, rendezvousTXTPrefix)); ok {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+func (m *MeshCoordinator) addSignalingServer(address string) error {
+	if bootstrapper, ok := m.transport.(interface {
+		AddSignalingServer(server string) error
+	}); ok {
+		return bootstrapper.AddSignalingServer(address)
+	}
+	return nil
+}
+
+// dialBridge connects to a bridge listener whose node ID is not known yet.
+// Like ConnectToPeer it dials in the background.
+func (m *MeshCoordinator) dialBridge(address string) error {
+	bridger, ok := m.transport.(interface {
+		DialBridge(ctx context.Context, addr string) (string, error)
+	})
+	if !ok {
+		return errors.New("transport cannot dial bridge addresses")
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		peerID, err := bridger.DialBridge(ctx, address)
+		if err != nil {
+			m.logger.Debug("bridge dial failed", "address", address, "error", err)
+			return
+		}
+		m.logger.Info("connected to bridge peer", "peer", peerID, "address", address)
+	}()
+	return nil
+}
+
+// bootstrapLoop re-bootstraps with exponential backoff while the node is isolated.
+func (m *MeshCoordinator) bootstrapLoop() {
+	cfg := m.config.Bootstrap
+	if cfg.CheckInterval <= 0 {
+		return
+	}
+
+	m.bootstrapMu.Lock()
+	m.bootstrap.backoff = cfg.InitialBackoff
+	m.bootstrapMu.Unlock()
+
+	next := time.Now()
+	ticker := time.NewTicker(cfg.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		if len(m.transport.GetConnectedPeers()) > 0 {
+			m.bootstrapMu.Lock()
+			m.bootstrap.backoff = cfg.InitialBackoff
+			m.bootstrapMu.Unlock()
+			next = time.Now().Add(cfg.InitialBackoff)
+		} else if m.hasBootstrapTargets() && !time.Now().Before(next) {
+			ctx, cancel := context.WithTimeout(context.Background(), m.config.LookupTimeout)
+			if _, err := m.Bootstrap(ctx, nil); err != nil {
+				m.logger.Debug("re-bootstrap failed", "error", err)
+			}
+			cancel()
+
+			m.bootstrapMu.Lock()
+			wait := m.bootstrap.backoff
+			m.bootstrap.backoff *= 2
+			if m.bootstrap.backoff > cfg.MaxBackoff {
+				m.bootstrap.backoff = cfg.MaxBackoff
+			}
+			m.bootstrapMu.Unlock()
+			next = time.Now().Add(wait)
+		}
+
+		select {
+		case <-m.shutdown:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *MeshCoordinator) hasBootstrapTargets() bool {
+	m.bootstrapMu.Lock()
+	defer m.bootstrapMu.Unlock()
+	return len(m.bootstrap.peers) > 0 || len(m.bootstrap.rendezvous) > 0
+}
+
+func containsString(values []string, target string) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+package mesh
+
+import (
+	"context"
+	"testing"
+)
+
+func TestParseBootstrapEntry(t *testing.T) {
+	cases := map[string]bootstrapEntry{
+		"node-a":                        {PeerID: "node-a"},
+		"node-a@wss://rv.inos.dev":      {PeerID: "node-a", Address: "wss://rv.inos.dev"},
+		"wss://rv.inos.dev":             {Address: "wss://rv.inos.dev"},
+		"node-a@inos+wss://n.inos.dev/": {PeerID: "node-a", Address: "inos+wss://n.inos.dev/"},
+		"inos+ws://10.0.0.2:7469/":      {Address: "inos+ws://10.0.0.2:7469/"},
+	}
+	for raw, want := range cases {
+		got, ok := parseBootstrapEntry(raw)
+		if !ok || got != want {
+			t.Fatalf("parseBootstrapEntry(%q) = %+v, %v", raw, got, ok)
+		}
+	}
+	for _, raw := range []string{"", "  ", "@wss://x", "node@http://x", "node@inos+http://x"} {
+		if _, ok := parseBootstrapEntry(raw); ok {
+			t.Fatalf("expected %q to be rejected", raw)
+		}
+	}
+}
+
+// isolatedTransport reports no live connections so bootstrap dials every target.
+type isolatedTransport struct {
+	*MockTransport
+}
+
+func (isolatedTransport) IsConnected(peerID string) bool { return false }
+
+func TestMeshCoordinator_BootstrapUsesStaticAndRendezvous(t *testing.T) {
+	tr := isolatedTransport{&MockTransport{nodeID: "self"}}
+	coord := NewMeshCoordinator("self", "us-east", tr, nil)
+
+	if _, err := coord.Bootstrap(context.Background(), nil); err == nil {
+		t.Fatal("expected error with no bootstrap peers configured")
+	}
+
+	coord.bootstrap.resolveTXT = func(ctx context.Context, name string) ([]string, error) {
+		if name != "_inos.example.org" {
+			t.Fatalf("unexpected rendezvous lookup %q", name)
+		}
+		return []string{"v=spf1 -all", "inos-peer=node-dns", "inos-peer=self"}, nil
+	}
+	coord.AddRendezvousDomains("_inos.example.org")
+
+	attempted, err := coord.Bootstrap(context.Background(), []string{"node-static", "node-static"})
+	if err != nil {
+		t.Fatalf("Bootstrap failed: %v", err)
+	}
+	if attempted != 2 {
+		t.Fatalf("expected 2 connection attempts (static + DNS, excluding self), got %d", attempted)
+	}
+
+	status := coord.GetBootstrapStatus()
+	if len(status.Peers) != 1 || status.Attempts != 2 || !status.Isolated {
+		t.Fatalf("unexpected bootstrap status %+v", status)
+	}
+}
@@ -0,0 +1,300 @@
+package mesh
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+)
+
+// FaultRule injects faults at one point. Rates are probabilities per
+// operation; a rule with an empty Target matches every target at its point.
+type FaultRule struct {
+	Point       common.FaultPoint `json:"point"`
+	Target      string            `json:"target,omitempty"`
+	DropRate    float64           `json:"drop_rate,omitempty"`
+	CorruptRate float64           `json:"corrupt_rate,omitempty"`
+	DelayRate   float64           `json:"delay_rate,omitempty"`
+	DelayMs     int64             `json:"delay_ms,omitempty"`
+}
+
+// ChaosConfig configures a ChaosInjector. No rules disables injection.
+type ChaosConfig struct {
+	Seed  int64       `json:"seed,omitempty"`
+	Rules []FaultRule `json:"rules"`
+}
+
+// ChaosStats counts the faults a ChaosInjector has injected at each point.
+type ChaosStats struct {
+	Drops    map[common.FaultPoint]uint64 `json:"drops"`
+	Delays   map[common.FaultPoint]uint64 `json:"delays"`
+	Corrupts map[common.FaultPoint]uint64 `json:"corrupts"`
+}
+
+// ChaosStatus reports whether chaos is on and what it has done.
+type ChaosStatus struct {
+	Enabled bool        `json:"enabled"`
+	Config  ChaosConfig `json:"config"`
+	Stats   ChaosStats  `json:"stats"`
+}
+
+var validFaultPoints = map[common.FaultPoint]bool{
+	common.FaultPointSend:      true,
+	common.FaultPointRPCHandle: true,
+	common.FaultPointStorage:   true,
+	common.FaultPointSAB:       true,
+}
+
+// Validate checks the rules' points and rates.
+func (c ChaosConfig) Validate() error {
+	for i, rule := range c.Rules {
+		if !validFaultPoints[rule.Point] {
+			return fmt.Errorf("rule %d: unknown fault point %q", i, rule.Point)
+		}
+		for name, rate := range map[string]float64{"drop_rate": rule.DropRate, "corrupt_rate": rule.CorruptRate, "delay_rate": rule.DelayRate} {
+			if rate < 0 || rate > 1 {
+				return fmt.Errorf("rule %d: %s must be between 0 and 1", i, name)
+			}
+		}
+		if rule.DelayMs < 0 {
+			return fmt.Errorf("rule %d: delay_ms must not be negative", i)
+		}
+	}
+	return nil
+}
+
+// ChaosInjector is a FaultInjector driven by probabilistic rules. Its
+// random source is seeded so a run can be repeated.
+type ChaosInjector struct {
+	mu     sync.Mutex
+	config ChaosConfig
+	rng    *rand.Rand
+	stats  ChaosStats
+}
+
+// NewChaosInjector creates an injector for a validated config.
+func NewChaosInjector(config ChaosConfig) (*ChaosInjector, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	seed := config.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &ChaosInjector{
+		config: config,
+		rng:    rand.New(rand.NewSource(seed)),
+		stats: ChaosStats{
+			Drops:    make(map[common.FaultPoint]uint64),
+			Delays:   make(map[common.FaultPoint]uint64),
+			Corrupts: make(map[common.FaultPoint]uint64),
+		},
+	}, nil
+}
+
+// Inject combines every rule matching point and target.
+func (c *ChaosInjector) Inject(point common.FaultPoint, target string) common.Fault {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var fault common.Fault
+	for _, rule := range c.config.Rules {
+		if rule.Point != point || (rule.Target != "" && rule.Target != target) {
+			continue
+		}
+		if rule.DropRate > 0 && c.rng.Float64() < rule.DropRate {
+			fault.Drop = true
+		}
+		if rule.CorruptRate > 0 && c.rng.Float64() < rule.CorruptRate {
+			fault.Corrupt = true
+		}
+		if rule.DelayMs > 0 && rule.DelayRate > 0 && c.rng.Float64() < rule.DelayRate {
+			fault.Delay += time.Duration(rule.DelayMs) * time.Millisecond
+		}
+	}
+	if fault.Drop {
+		c.stats.Drops[point]++
+	}
+	if fault.Corrupt {
+		c.stats.Corrupts[point]++
+	}
+	if fault.Delay > 0 {
+		c.stats.Delays[point]++
+	}
+	return fault
+}
+
+// Stats returns a copy of the injected fault counts.
+func (c *ChaosInjector) Stats() ChaosStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return ChaosStats{
+		Drops:    copyFaultCounts(c.stats.Drops),
+		Delays:   copyFaultCounts(c.stats.Delays),
+		Corrupts: copyFaultCounts(c.stats.Corrupts),
+	}
+}
+
+func copyFaultCounts(in map[common.FaultPoint]uint64) map[common.FaultPoint]uint64 {
+	out := make(map[common.FaultPoint]uint64, len(in))
+	for point, n := range in {
+		out[point] = n
+	}
+	return out
+}
+
+// SetFaultInjector installs injector on the transport, storage and SAB
+// bridge; nil turns injection off. It is safe to call while running.
+func (m *MeshCoordinator) SetFaultInjector(injector common.FaultInjector) {
+	m.faults.Set(injector)
+	if tr, ok := m.transport.(interface {
+		SetFaultInjector(common.FaultInjector)
+	}); ok {
+		tr.SetFaultInjector(injector)
+	}
+}
+
+// ConfigureChaos replaces the chaos rules. A config without rules turns
+// fault injection off.
+func (m *MeshCoordinator) ConfigureChaos(config ChaosConfig) error {
+	if len(config.Rules) == 0 {
+		m.chaosMu.Lock()
+		m.chaos = nil
+		m.chaosMu.Unlock()
+		m.SetFaultInjector(nil)
+		m.logger.Info("chaos disabled")
+		return nil
+	}
+
+	injector, err := NewChaosInjector(config)
+	if err != nil {
+		return err
+	}
+	m.chaosMu.Lock()
+	m.chaos = injector
+	m.chaosMu.Unlock()
+	m.SetFaultInjector(injector)
+	m.logger.Warn("chaos enabled", "rules", len(config.Rules), "seed", config.Seed)
+	return nil
+}
+
+// ChaosStatus reports the active chaos rules and their fault counts.
+func (m *MeshCoordinator) ChaosStatus() ChaosStatus {
+	m.chaosMu.Lock()
+	injector := m.chaos
+	m.chaosMu.Unlock()
+	if injector == nil {
+		return ChaosStatus{}
+	}
+	return ChaosStatus{Enabled: true, Config: injector.config, Stats: injector.Stats()}
+}
+
+// TripCircuitBreaker forces the peer's breaker open as if it had reached
+// its failure threshold. It closes again through the usual half-open path.
+func (m *MeshCoordinator) TripCircuitBreaker(peerID string) {
+	resource := "peer:" + peerID
+
+	m.cbMu.Lock()
+	cb, exists := m.circuitBreakers[resource]
+	if !exists {
+		cb = &CircuitBreaker{
+			peerID:           peerID,
+			resetTimeout:     m.config.CircuitBreaker.ResetTimeout,
+			failureThreshold: m.config.CircuitBreaker.FailureThreshold,
+		}
+		m.circuitBreakers[resource] = cb
+	}
+	m.cbMu.Unlock()
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	wasOpen := cb.state == BreakerOpen
+	cb.state = BreakerOpen
+	cb.failures = cb.failureThreshold
+	cb.successes = 0
+	cb.lastFailure = time.Now()
+	m.logger.Warn("circuit breaker tripped by fault injection", "peer", getShortID(peerID))
+	if !wasOpen {
+		m.recordBreakerTrip(peerID)
+		m.noteBreakerOpened(peerID)
+	}
+}
+
+// faultStorage applies storage faults around the coordinator's storage.
+// Without an injector it passes every call straight through.
+type faultStorage struct {
+	StorageProvider
+	faults *common.FaultHook
+}
+
+func (s *faultStorage) StoreChunk(ctx context.Context, hash string, data []byte) error {
+	fault := s.faults.Inject(common.FaultPointStorage, hash)
+	if err := fault.Apply(ctx); err != nil {
+		return err
+	}
+	if fault.Corrupt {
+		data = common.CorruptBytes(data)
+	}
+	return s.StorageProvider.StoreChunk(ctx, hash, data)
+}
+
+func (s *faultStorage) FetchChunk(ctx context.Context, hash string) ([]byte, error) {
+	fault := s.faults.Inject(common.FaultPointStorage, hash)
+	if err := fault.Apply(ctx); err != nil {
+		return nil, err
+	}
+	data, err := s.StorageProvider.FetchChunk(ctx, hash)
+	if err == nil && fault.Corrupt {
+		data = common.CorruptBytes(data)
+	}
+	return data, err
+}
+
+func (s *faultStorage) HasChunk(ctx context.Context, hash string) (bool, error) {
+	if err := s.faults.Inject(common.FaultPointStorage, hash).Apply(ctx); err != nil {
+		return false, err
+	}
+	return s.StorageProvider.HasChunk(ctx, hash)
+}
+
+// faultSAB applies SAB faults to raw reads and writes. Flags-region
+// atomics pass through: they coordinate with JS and are not payloads.
+type faultSAB struct {
+	SABWriter
+	faults *common.FaultHook
+}
+
+func (b *faultSAB) WriteRaw(offset uint32, data []byte) error {
+	fault := b.faults.Inject(common.FaultPointSAB, "write")
+	if err := fault.Apply(context.Background()); err != nil {
+		return err
+	}
+	if fault.Corrupt {
+		data = common.CorruptBytes(data)
+	}
+	return b.SABWriter.WriteRaw(offset, data)
+}
+
+func (b *faultSAB) ReadRaw(offset uint32, size uint32) ([]byte, error) {
+	fault := b.faults.Inject(common.FaultPointSAB, "read")
+	if err := fault.Apply(context.Background()); err != nil {
+		return nil, err
+	}
+	data, err := b.SABWriter.ReadRaw(offset, size)
+	if err == nil && fault.Corrupt {
+		data = common.CorruptBytes(data)
+	}
+	return data, err
+}
+
+// unwrapSAB returns the bridge behind any fault wrapper, for checks on
+// optional bridge interfaces.
+func unwrapSAB(bridge SABWriter) SABWriter {
+	if wrapped, ok := bridge.(*faultSAB); ok {
+		return wrapped.SABWriter
+	}
+	return bridge
+}
@@ -0,0 +1,97 @@
+package mesh
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+)
+
+func TestChaosInjector_SeededRulesRepeat(t *testing.T) {
+	config := ChaosConfig{Seed: 42, Rules: []FaultRule{
+		{Point: common.FaultPointSend, Target: "peer-a", DropRate: 0.5},
+		{Point: common.FaultPointStorage, CorruptRate: 1},
+	}}
+	first, err := NewChaosInjector(config)
+	if err != nil {
+		t.Fatalf("NewChaosInjector failed: %v", err)
+	}
+	second, _ := NewChaosInjector(config)
+
+	drops := 0
+	for i := 0; i < 64; i++ {
+		a, b := first.Inject(common.FaultPointSend, "peer-a"), second.Inject(common.FaultPointSend, "peer-a")
+		if a != b {
+			t.Fatalf("call %d differs between injectors with the same seed", i)
+		}
+		if a.Drop {
+			drops++
+		}
+	}
+	if drops == 0 || drops == 64 {
+		t.Fatalf("expected some drops at 50%%, got %d", drops)
+	}
+	if f := first.Inject(common.FaultPointSend, "peer-b"); f != (common.Fault{}) {
+		t.Fatalf("rule for peer-a should not touch peer-b, got %+v", f)
+	}
+	if !first.Inject(common.FaultPointStorage, "any-chunk").Corrupt {
+		t.Fatal("an untargeted rule should match every target")
+	}
+	if stats := first.Stats(); stats.Drops[common.FaultPointSend] != uint64(drops) || stats.Corrupts[common.FaultPointStorage] != 1 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+
+	if _, err := NewChaosInjector(ChaosConfig{Rules: []FaultRule{{Point: "disk"}}}); err == nil {
+		t.Fatal("expected unknown fault point to be rejected")
+	}
+	if _, err := NewChaosInjector(ChaosConfig{Rules: []FaultRule{{Point: common.FaultPointSAB, DropRate: 1.5}}}); err == nil {
+		t.Fatal("expected out-of-range rate to be rejected")
+	}
+}
+
+func TestChaos_InjectsAcrossSubsystemsAndTurnsOff(t *testing.T) {
+	cluster := startSimCluster(t, SimClusterConfig{Nodes: 2})
+	a, _ := cluster.Node("node-01")
+	b, _ := cluster.Node("node-02")
+	b.Transport.RegisterRPCHandler("chaos_echo", func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error) {
+		return "ok", nil
+	})
+	ctx := context.Background()
+
+	err := a.Coordinator.ConfigureChaos(ChaosConfig{Seed: 1, Rules: []FaultRule{
+		{Point: common.FaultPointSend, Target: b.ID, DropRate: 1},
+		{Point: common.FaultPointStorage, DropRate: 1},
+	}})
+	if err != nil {
+		t.Fatalf("ConfigureChaos failed: %v", err)
+	}
+	if err := a.Transport.SendRPC(ctx, b.ID, "chaos_echo", nil, nil); !errors.Is(err, common.ErrFaultInjected) {
+		t.Fatalf("expected injected send failure, got %v", err)
+	}
+	if err := a.Coordinator.storage.StoreChunk(ctx, "chunk", []byte("data")); !errors.Is(err, common.ErrFaultInjected) {
+		t.Fatalf("expected injected storage failure, got %v", err)
+	}
+	if status := a.Coordinator.ChaosStatus(); !status.Enabled || status.Stats.Drops[common.FaultPointSend] == 0 {
+		t.Fatalf("unexpected chaos status %+v", status)
+	}
+
+	a.Coordinator.TripCircuitBreaker(b.ID)
+	if !a.Coordinator.isCircuitBreakerOpenForPeer(b.ID) {
+		t.Fatal("expected forced breaker trip to open the breaker")
+	}
+
+	if err := a.Coordinator.ConfigureChaos(ChaosConfig{}); err != nil {
+		t.Fatalf("disabling chaos failed: %v", err)
+	}
+	if err := a.Transport.SendRPC(ctx, b.ID, "chaos_echo", nil, nil); err != nil {
+		t.Fatalf("send after disabling chaos failed: %v", err)
+	}
+	if err := a.Coordinator.storage.StoreChunk(ctx, "chunk", []byte("data")); err != nil {
+		t.Fatalf("store after disabling chaos failed: %v", err)
+	}
+	if a.Coordinator.ChaosStatus().Enabled {
+		t.Fatal("expected chaos to report disabled")
+	}
+}
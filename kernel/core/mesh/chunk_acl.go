@@ -0,0 +1,595 @@
+package mesh
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+)
+
+const (
+	chunkACLVersion        = "inos-chunk-acl-v1"
+	chunkGrantVersion      = "inos-chunk-grant-v1"
+	chunkFetchVersion      = "inos-chunk-fetch-v1"
+	chunkAccessDeniedTopic = "chunk_access_denied"
+
+	// chunkFetchNonceTTL is how long a fetch nonce is remembered when
+	// ChunkACL.RequestMaxAge leaves request age unchecked.
+	chunkFetchNonceTTL = 10 * time.Minute
+)
+
+// Reason codes for a refused chunk.fetch.
+const (
+	ChunkDenyUnsigned     = "unsigned"      // No request signature
+	ChunkDenyBadSignature = "bad_signature" // Signature or signer key did not check out
+	ChunkDenyStale        = "stale_request" // Signed outside ChunkACL.RequestMaxAge
+	ChunkDenyReplayed     = "replayed"      // Nonce already used by the requester
+	ChunkDenyBadGrant     = "invalid_grant" // A grant for the chunk's group was forged or expired
+	ChunkDenyNotAllowed   = "not_allowed"   // Signed, but the ACL does not name the requester
+)
+
+// ErrChunkAccessDenied is returned to a peer the chunk's ACL refuses.
+var ErrChunkAccessDenied = errors.New("chunk access denied")
+
+// ObjectACL restricts who may fetch an object's chunks and manifest. The
+// owner, nodes bound to the owner's DID, and the listed nodes and DIDs may;
+// anyone else needs a ChunkGrant for Group.
+type ObjectACL struct {
+	Owner        string   `json:"owner,omitempty"` // Set by PutObject
+	Group        string   `json:"group,omitempty"` // Grants name this; PutObject picks one if empty
+	AllowedDIDs  []string `json:"allowed_dids,omitempty"`
+	AllowedNodes []string `json:"allowed_nodes,omitempty"`
+}
+
+// ChunkACL is an owner-signed access list for one chunk. It travels with the
+// chunk to every holder, which checks fetches against it. The owner is the
+// node ID, or the DID OwnerBinding proves, not OwnerKey, so a key rotation
+// does not orphan the chunk: holders keep the ACL they stored, and the owner
+// re-signs its ACLs under the new key for the replicas it sends afterwards.
+// An ACL arriving with a replica must be signed with the owner's current key.
+type ChunkACL struct {
+	Version      string            `json:"version"`
+	ChunkHash    string            `json:"chunk_hash"`
+	Owner        string            `json:"owner"`
+	OwnerDID     string            `json:"owner_did,omitempty"`
+	OwnerKey     ed25519.PublicKey `json:"owner_key"`
+	OwnerBinding *DIDBinding       `json:"owner_binding,omitempty"`
+	Group        string            `json:"group"`
+	AllowedDIDs  []string          `json:"allowed_dids,omitempty"`
+	AllowedNodes []string          `json:"allowed_nodes,omitempty"`
+	IssuedAt     int64             `json:"issued_at"` // Unix milliseconds
+	Signature    []byte            `json:"signature"`
+}
+
+// ChunkGrant is a capability token from a chunk owner letting Subject (a node
+// ID or DID) fetch every chunk whose ACL names Group, until it expires. It
+// applies to ACLs of the same owner node, or of a node OwnerBinding shows is
+// bound to the same DID. Only grants signed with the owner's current key are
+// honoured, so a key rotation lapses the grants issued before it.
+type ChunkGrant struct {
+	Version      string            `json:"version"`
+	Owner        string            `json:"owner"`
+	OwnerKey     ed25519.PublicKey `json:"owner_key"`
+	OwnerBinding *DIDBinding       `json:"owner_binding,omitempty"`
+	Group        string            `json:"group"`
+	Subject      string            `json:"subject"`
+	IssuedAt     int64             `json:"issued_at"`  // Unix milliseconds
+	ExpiresAt    int64             `json:"expires_at"` // Unix milliseconds
+	Signature    []byte            `json:"signature"`
+}
+
+// ChunkAccessDenial is one refused fetch. Holders log it and report it to
+// the chunk's owner, which keeps it with the reporting Holder.
+type ChunkAccessDenial struct {
+	ChunkHash string    `json:"chunk_hash"`
+	Owner     string    `json:"owner"`
+	Holder    string    `json:"holder"`
+	Requester string    `json:"requester"`
+	DID       string    `json:"did,omitempty"`
+	Reason    string    `json:"reason"`
+	At        time.Time `json:"at"`
+}
+
+// chunkACLState holds the ACLs of chunks stored here, grants this node can
+// present, the fetch nonces already seen, and the denial log.
+type chunkACLState struct {
+	mu          sync.RWMutex
+	acls        map[string]*ChunkACL   // By chunk hash
+	grants      map[string]*ChunkGrant // Held, by owner and group
+	nonces      map[string]time.Time   // Until when a fetch nonce is remembered, by requester and nonce
+	noncesSwept time.Time              // Last time expired nonces were dropped
+	denials     []ChunkAccessDenial    // Oldest first
+	notified    map[string]time.Time   // Last report to an owner, by owner, requester and reason
+}
+
+func newChunkACLState() chunkACLState {
+	return chunkACLState{
+		acls:     make(map[string]*ChunkACL),
+		grants:   make(map[string]*ChunkGrant),
+		nonces:   make(map[string]time.Time),
+		notified: make(map[string]time.Time),
+	}
+}
+
+func appendACLString(buf []byte, s string) []byte {
+	buf = append(buf, s...)
+	return append(buf, 0)
+}
+
+func chunkACLPayload(a *ChunkACL) []byte {
+	buf := make([]byte, 0, 256)
+	for _, s := range []string{a.Version, a.ChunkHash, a.Owner, a.OwnerDID, a.Group} {
+		buf = appendACLString(buf, s)
+	}
+	buf = append(buf, a.OwnerKey...)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(a.AllowedDIDs)))
+	for _, did := range a.AllowedDIDs {
+		buf = appendACLString(buf, did)
+	}
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(a.AllowedNodes)))
+	for _, node := range a.AllowedNodes {
+		buf = appendACLString(buf, node)
+	}
+	buf = binary.BigEndian.AppendUint64(buf, uint64(a.IssuedAt))
+	sum := sha256.Sum256(buf)
+	return sum[:]
+}
+
+func chunkGrantPayload(g *ChunkGrant) []byte {
+	buf := make([]byte, 0, 160)
+	for _, s := range []string{g.Version, g.Owner, g.Group, g.Subject} {
+		buf = appendACLString(buf, s)
+	}
+	buf = append(buf, g.OwnerKey...)
+	buf = binary.BigEndian.AppendUint64(buf, uint64(g.IssuedAt))
+	return binary.BigEndian.AppendUint64(buf, uint64(g.ExpiresAt))
+}
+
+func chunkFetchPayload(req *ChunkFetchRequest) []byte {
+	buf := make([]byte, 0, 192)
+	for _, s := range []string{chunkFetchVersion, req.ChunkHash, req.Requester, req.Nonce} {
+		buf = appendACLString(buf, s)
+	}
+	buf = binary.BigEndian.AppendUint64(buf, uint64(req.IssuedAt))
+	return append(buf, req.PublicKey...)
+}
+
+// chunkOwnerDID returns the DID binding proves owner's key is bound to, or
+// "" if there is none.
+func chunkOwnerDID(owner string, key ed25519.PublicKey, binding *DIDBinding) string {
+	if binding == nil || binding.Verify(owner, key) != nil {
+		return ""
+	}
+	return binding.DID
+}
+
+// sameChunkOwner reports whether owner, signing with key, is acl's owner:
+// the same node, or a node bound to the same DID.
+func sameChunkOwner(owner string, key ed25519.PublicKey, binding *DIDBinding, acl *ChunkACL) bool {
+	if owner == acl.Owner {
+		return true
+	}
+	did := chunkOwnerDID(owner, key, binding)
+	return did != "" && did == chunkOwnerDID(acl.Owner, acl.OwnerKey, acl.OwnerBinding)
+}
+
+// verifyChunkACL checks the ACL is signed with the owner's current key.
+func (m *MeshCoordinator) verifyChunkACL(a *ChunkACL, chunkHash string) error {
+	if a.Version != chunkACLVersion || a.ChunkHash != chunkHash {
+		return errors.New("ACL is not for this chunk")
+	}
+	if err := m.checkIdentityKey(a.Owner, a.OwnerKey); err != nil {
+		return fmt.Errorf("ACL owner: %w", err)
+	}
+	if !ed25519.Verify(a.OwnerKey, chunkACLPayload(a), a.Signature) {
+		return errors.New("invalid ACL signature")
+	}
+	return nil
+}
+
+// ownerBinding returns this node's DID binding, if it has one.
+func (m *MeshCoordinator) ownerBinding() *DIDBinding {
+	if id := m.NodeIdentity(); id != nil {
+		return id.DIDBinding()
+	}
+	return nil
+}
+
+// signChunkACL issues this node's ACL for chunkHash under acl.
+func (m *MeshCoordinator) signChunkACL(chunkHash string, acl *ObjectACL) (*ChunkACL, error) {
+	m.identityMu.RLock()
+	did := m.did
+	m.identityMu.RUnlock()
+
+	signed := &ChunkACL{
+		Version:      chunkACLVersion,
+		ChunkHash:    chunkHash,
+		Owner:        m.nodeID,
+		OwnerDID:     did,
+		OwnerKey:     m.gossip.PublicKey(),
+		OwnerBinding: m.ownerBinding(),
+		Group:        acl.Group,
+		AllowedDIDs:  acl.AllowedDIDs,
+		AllowedNodes: acl.AllowedNodes,
+		IssuedAt:     time.Now().UnixMilli(),
+	}
+	sig, pub, err := m.gossip.SignAttestation(chunkACLPayload(signed))
+	if err != nil {
+		return nil, err
+	}
+	if !pub.Equal(signed.OwnerKey) {
+		return nil, errors.New("identity key rotated while signing ACL")
+	}
+	signed.Signature = sig
+	return signed, nil
+}
+
+// newObjectACL fills in the owner and a random group.
+func (m *MeshCoordinator) newObjectACL(acl *ObjectACL) (*ObjectACL, error) {
+	copied := *acl
+	copied.Owner = m.nodeID
+	if copied.Group == "" {
+		var id [16]byte
+		if _, err := rand.Read(id[:]); err != nil {
+			return nil, err
+		}
+		copied.Group = hex.EncodeToString(id[:])
+	}
+	return &copied, nil
+}
+
+// restrictChunk signs an ACL for a chunk this node is about to distribute;
+// every replica it stores carries it.
+func (m *MeshCoordinator) restrictChunk(chunkHash string, acl *ObjectACL) error {
+	signed, err := m.signChunkACL(chunkHash, acl)
+	if err != nil {
+		return fmt.Errorf("failed to sign chunk ACL: %w", err)
+	}
+	m.chunkACL.mu.Lock()
+	m.chunkACL.acls[chunkHash] = signed
+	m.chunkACL.mu.Unlock()
+	return nil
+}
+
+// resignChunkACLs re-signs the ACLs this node owns under its current key, so
+// replicas it sends after a key rotation carry an ACL holders accept.
+func (m *MeshCoordinator) resignChunkACLs() {
+	m.chunkACL.mu.RLock()
+	var owned []*ChunkACL
+	for _, acl := range m.chunkACL.acls {
+		if acl.Owner == m.nodeID {
+			owned = append(owned, acl)
+		}
+	}
+	m.chunkACL.mu.RUnlock()
+
+	for _, acl := range owned {
+		objectACL := &ObjectACL{Group: acl.Group, AllowedDIDs: acl.AllowedDIDs, AllowedNodes: acl.AllowedNodes}
+		if err := m.restrictChunk(acl.ChunkHash, objectACL); err != nil {
+			m.logger.Warn("failed to re-sign chunk ACL", "chunk", getShortID(acl.ChunkHash), "error", err)
+		}
+	}
+}
+
+// chunkACLFor returns the ACL a chunk is stored under, or nil if it is open.
+func (m *MeshCoordinator) chunkACLFor(chunkHash string) *ChunkACL {
+	m.chunkACL.mu.RLock()
+	defer m.chunkACL.mu.RUnlock()
+	return m.chunkACL.acls[chunkHash]
+}
+
+// acceptChunkACL keeps the ACL a replica arrived with. Once a chunk has an
+// ACL only its owner can replace it, with a newer one, whichever key it now
+// signs with.
+func (m *MeshCoordinator) acceptChunkACL(chunkHash string, acl *ChunkACL) error {
+	if err := m.verifyChunkACL(acl, chunkHash); err != nil {
+		return err
+	}
+	m.chunkACL.mu.Lock()
+	defer m.chunkACL.mu.Unlock()
+	if held, ok := m.chunkACL.acls[chunkHash]; ok {
+		if !sameChunkOwner(acl.Owner, acl.OwnerKey, acl.OwnerBinding, held) {
+			return errors.New("chunk already has an ACL from another owner")
+		}
+		if acl.IssuedAt <= held.IssuedAt {
+			return nil
+		}
+	}
+	m.chunkACL.acls[chunkHash] = acl
+	return nil
+}
+
+// forgetChunkACL drops the ACL of a chunk no longer stored here.
+func (m *MeshCoordinator) forgetChunkACL(chunkHash string) {
+	m.chunkACL.mu.Lock()
+	delete(m.chunkACL.acls, chunkHash)
+	m.chunkACL.mu.Unlock()
+}
+
+// GrantChunkAccess issues a grant letting subject, a node ID or DID, fetch
+// this node's chunks restricted under group for ttl.
+func (m *MeshCoordinator) GrantChunkAccess(group, subject string, ttl time.Duration) (*ChunkGrant, error) {
+	if group == "" || subject == "" || ttl <= 0 {
+		return nil, errors.New("group, subject and ttl are required")
+	}
+	now := time.Now()
+	grant := &ChunkGrant{
+		Version:      chunkGrantVersion,
+		Owner:        m.nodeID,
+		OwnerKey:     m.gossip.PublicKey(),
+		OwnerBinding: m.ownerBinding(),
+		Group:        group,
+		Subject:      subject,
+		IssuedAt:     now.UnixMilli(),
+		ExpiresAt:    now.Add(ttl).UnixMilli(),
+	}
+	sig, pub, err := m.gossip.SignAttestation(chunkGrantPayload(grant))
+	if err != nil {
+		return nil, err
+	}
+	if !pub.Equal(grant.OwnerKey) {
+		return nil, errors.New("identity key rotated while signing grant")
+	}
+	grant.Signature = sig
+	return grant, nil
+}
+
+// ImportChunkGrant keeps a grant issued to this node, to present with every
+// chunk fetch until it expires.
+func (m *MeshCoordinator) ImportChunkGrant(grant *ChunkGrant) error {
+	if grant == nil || grant.Version != chunkGrantVersion {
+		return errors.New("unsupported chunk grant")
+	}
+	if time.Now().UnixMilli() >= grant.ExpiresAt {
+		return errors.New("chunk grant expired")
+	}
+	if !ed25519.Verify(grant.OwnerKey, chunkGrantPayload(grant), grant.Signature) {
+		return errors.New("invalid chunk grant signature")
+	}
+	m.chunkACL.mu.Lock()
+	m.chunkACL.grants[grant.Owner+"|"+grant.Group] = grant
+	m.chunkACL.mu.Unlock()
+	return nil
+}
+
+// heldChunkGrants returns unexpired grants, soonest expiring last, at most
+// ChunkACL.MaxGrants of them. Expired ones are dropped.
+func (m *MeshCoordinator) heldChunkGrants() []*ChunkGrant {
+	now := time.Now().UnixMilli()
+	m.chunkACL.mu.Lock()
+	grants := make([]*ChunkGrant, 0, len(m.chunkACL.grants))
+	for key, grant := range m.chunkACL.grants {
+		if now >= grant.ExpiresAt {
+			delete(m.chunkACL.grants, key)
+			continue
+		}
+		grants = append(grants, grant)
+	}
+	m.chunkACL.mu.Unlock()
+
+	sort.Slice(grants, func(i, j int) bool { return grants[i].ExpiresAt > grants[j].ExpiresAt })
+	if limit := m.config.ChunkACL.MaxGrants; limit > 0 && len(grants) > limit {
+		grants = grants[:limit]
+	}
+	return grants
+}
+
+// signChunkFetch turns a bare fetch into one the holder can check against
+// the chunk's ACL. Each carries a fresh nonce, so it is served once. An
+// unsigned request still reaches open chunks.
+func (m *MeshCoordinator) signChunkFetch(chunkHash string) ChunkFetchRequest {
+	var nonce [16]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		m.logger.Debug("sending unsigned chunk fetch", "chunk", getShortID(chunkHash), "error", err)
+		return ChunkFetchRequest{ChunkHash: chunkHash}
+	}
+	req := ChunkFetchRequest{
+		ChunkHash:  chunkHash,
+		Requester:  m.nodeID,
+		Nonce:      hex.EncodeToString(nonce[:]),
+		IssuedAt:   m.MeshTime().UnixMilli(),
+		PublicKey:  m.gossip.PublicKey(),
+		DIDBinding: m.ownerBinding(),
+		Grants:     m.heldChunkGrants(),
+	}
+	sig, pub, err := m.gossip.SignAttestation(chunkFetchPayload(&req))
+	if err != nil || !pub.Equal(req.PublicKey) {
+		m.logger.Debug("sending unsigned chunk fetch", "chunk", getShortID(chunkHash), "error", err)
+		return ChunkFetchRequest{ChunkHash: chunkHash}
+	}
+	req.Signature = sig
+	return req
+}
+
+// authorizeChunkFetch checks a chunk.fetch from peerID against the chunk's
+// ACL. It returns "" when the fetch may be served, otherwise the reason code
+// and the DID the requester proved, if any.
+func (m *MeshCoordinator) authorizeChunkFetch(peerID string, req *ChunkFetchRequest) (reason, did string) {
+	acl := m.chunkACLFor(req.ChunkHash)
+	if acl == nil {
+		return "", ""
+	}
+	if len(req.Signature) == 0 {
+		return ChunkDenyUnsigned, ""
+	}
+	if req.Requester != peerID || m.checkIdentityKey(peerID, req.PublicKey) != nil ||
+		!ed25519.Verify(req.PublicKey, chunkFetchPayload(req), req.Signature) {
+		return ChunkDenyBadSignature, ""
+	}
+	maxAge := m.config.ChunkACL.RequestMaxAge
+	if age := m.MeshTime().Sub(time.UnixMilli(req.IssuedAt)); maxAge > 0 && (age > maxAge || age < -maxAge) {
+		return ChunkDenyStale, ""
+	}
+	if !m.claimFetchNonce(req) {
+		return ChunkDenyReplayed, ""
+	}
+
+	if req.DIDBinding != nil && req.DIDBinding.Verify(peerID, req.PublicKey) == nil {
+		did = req.DIDBinding.DID
+	}
+	if peerID == acl.Owner || slices.Contains(acl.AllowedNodes, peerID) {
+		return "", did
+	}
+	if did != "" && (did == acl.OwnerDID || slices.Contains(acl.AllowedDIDs, did)) {
+		return "", did
+	}
+
+	reason = ChunkDenyNotAllowed
+	now := time.Now().UnixMilli()
+	for _, grant := range req.Grants {
+		if grant == nil || grant.Group != acl.Group || !sameChunkOwner(grant.Owner, grant.OwnerKey, grant.OwnerBinding, acl) {
+			continue
+		}
+		if grant.Subject != peerID && (did == "" || grant.Subject != did) {
+			continue
+		}
+		if grant.Version != chunkGrantVersion || now >= grant.ExpiresAt ||
+			m.checkIdentityKey(grant.Owner, grant.OwnerKey) != nil ||
+			!ed25519.Verify(grant.OwnerKey, chunkGrantPayload(grant), grant.Signature) {
+			reason = ChunkDenyBadGrant
+			continue
+		}
+		return "", did
+	}
+	return reason, did
+}
+
+// claimFetchNonce records the nonce of a signed fetch, reporting false if
+// the requester already used it. A nonce is remembered until its request
+// would be refused as stale anyway.
+func (m *MeshCoordinator) claimFetchNonce(req *ChunkFetchRequest) bool {
+	if req.Nonce == "" {
+		return false
+	}
+	ttl := m.config.ChunkACL.RequestMaxAge
+	if ttl <= 0 {
+		ttl = chunkFetchNonceTTL
+	}
+	now := m.MeshTime()
+	key := req.Requester + "|" + req.Nonce
+
+	m.chunkACL.mu.Lock()
+	defer m.chunkACL.mu.Unlock()
+	if now.Sub(m.chunkACL.noncesSwept) >= ttl {
+		for k, until := range m.chunkACL.nonces {
+			if now.After(until) {
+				delete(m.chunkACL.nonces, k)
+			}
+		}
+		m.chunkACL.noncesSwept = now
+	}
+	if _, seen := m.chunkACL.nonces[key]; seen {
+		return false
+	}
+	m.chunkACL.nonces[key] = time.UnixMilli(req.IssuedAt).Add(ttl)
+	return true
+}
+
+// denyChunkFetch logs a refused fetch and reports it to the chunk's owner,
+// at most once per ChunkACL.NotifyInterval for a requester and reason.
+func (m *MeshCoordinator) denyChunkFetch(ctx context.Context, peerID, chunkHash, reason, did string) error {
+	denial := ChunkAccessDenial{
+		ChunkHash: chunkHash,
+		Holder:    m.nodeID,
+		Requester: peerID,
+		DID:       did,
+		Reason:    reason,
+		At:        time.Now(),
+	}
+	if acl := m.chunkACLFor(chunkHash); acl != nil {
+		denial.Owner = acl.Owner
+	}
+	m.rpcLogger(ctx).Info("chunk access denied",
+		"chunk", getShortID(chunkHash),
+		"owner", getShortID(denial.Owner),
+		"peer", getShortID(peerID),
+		"did", did,
+		"reason", reason,
+	)
+	m.recordChunkDenial(denial)
+
+	if denial.Owner != "" && denial.Owner != m.nodeID && m.shouldNotifyDenial(denial) {
+		if err := m.gossip.BroadcastTo(chunkAccessDeniedTopic, denial.Owner, denial); err != nil {
+			m.logger.Debug("failed to report chunk denial to owner", "owner", getShortID(denial.Owner), "error", err)
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrChunkAccessDenied, reason)
+}
+
+func (m *MeshCoordinator) shouldNotifyDenial(d ChunkAccessDenial) bool {
+	key := d.Owner + "|" + d.Requester + "|" + d.Reason
+	m.chunkACL.mu.Lock()
+	defer m.chunkACL.mu.Unlock()
+	if last, ok := m.chunkACL.notified[key]; ok && d.At.Sub(last) < m.config.ChunkACL.NotifyInterval {
+		return false
+	}
+	for k, last := range m.chunkACL.notified {
+		if d.At.Sub(last) >= m.config.ChunkACL.NotifyInterval {
+			delete(m.chunkACL.notified, k)
+		}
+	}
+	m.chunkACL.notified[key] = d.At
+	return true
+}
+
+// recordChunkDenial appends to the denial log, dropping the oldest once it
+// holds ChunkACL.DenialLog entries.
+func (m *MeshCoordinator) recordChunkDenial(d ChunkAccessDenial) {
+	m.chunkACL.mu.Lock()
+	m.chunkACL.denials = append(m.chunkACL.denials, d)
+	if limit := m.config.ChunkACL.DenialLog; limit > 0 && len(m.chunkACL.denials) > limit {
+		m.chunkACL.denials = append(m.chunkACL.denials[:0], m.chunkACL.denials[len(m.chunkACL.denials)-limit:]...)
+	}
+	m.chunkACL.mu.Unlock()
+
+	m.publishEvent(MeshEventChunkAccessDenied, d.Requester, map[string]interface{}{
+		"chunk_hash": d.ChunkHash,
+		"owner":      d.Owner,
+		"holder":     d.Holder,
+		"did":        d.DID,
+		"reason":     d.Reason,
+	})
+}
+
+// GetChunkAccessDenials returns refused fetches, newest first: those of
+// chunks held here and those holders reported for chunks this node owns.
+func (m *MeshCoordinator) GetChunkAccessDenials() []ChunkAccessDenial {
+	m.chunkACL.mu.RLock()
+	denials := make([]ChunkAccessDenial, len(m.chunkACL.denials))
+	copy(denials, m.chunkACL.denials)
+	m.chunkACL.mu.RUnlock()
+	slices.Reverse(denials)
+	return denials
+}
+
+// handleChunkAccessDenied takes in a denial a holder reports for a chunk
+// this node owns.
+func (m *MeshCoordinator) handleChunkAccessDenied(msg *common.GossipMessage) error {
+	data, err := json.Marshal(msg.Payload)
+	if err != nil {
+		return err
+	}
+	var denial ChunkAccessDenial
+	if err := json.Unmarshal(data, &denial); err != nil {
+		return err
+	}
+	if denial.Owner != m.nodeID {
+		return nil
+	}
+	// Only the reporting holder is vouched for by the transport
+	denial.Holder = msg.Sender
+	denial.At = time.Now()
+	m.recordChunkDenial(denial)
+	return nil
+}
@@ -0,0 +1,342 @@
+package mesh
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+)
+
+// aclFixture is an owner that stored a restricted object and a holder that
+// took a replica of its first chunk.
+type aclFixture struct {
+	owner, holder *MeshCoordinator
+	manifest      *ObjectManifest
+	chunkHash     string
+}
+
+func newACLFixture(t *testing.T, acl *ObjectACL) *aclFixture {
+	t.Helper()
+	owner := NewMeshCoordinator("owner", "us-east", &MockTransport{nodeID: "owner"}, nil)
+	owner.SetStorage(&MockStorage{chunks: map[string][]byte{}})
+	holder := NewMeshCoordinator("holder", "us-east", &MockTransport{nodeID: "holder"}, nil)
+	holder.SetStorage(&MockStorage{chunks: map[string][]byte{}})
+	holder.gossip.SetPeerIdentityKey("owner", owner.gossip.PublicKey())
+
+	_, manifest, err := owner.PutObject(context.Background(), bytes.NewReader([]byte("restricted object")), ObjectOptions{ACL: acl})
+	if err != nil {
+		t.Fatalf("PutObject failed: %v", err)
+	}
+	f := &aclFixture{owner: owner, holder: holder, manifest: manifest, chunkHash: manifest.Chunks[0].Hash}
+	if _, err := f.store(owner.chunkACLFor(f.chunkHash)); err != nil {
+		t.Fatalf("holder refused the replica: %v", err)
+	}
+	return f
+}
+
+func (f *aclFixture) store(acl *ChunkACL) (interface{}, error) {
+	params, _ := json.Marshal(ChunkStoreRequest{ChunkHash: f.chunkHash, Data: []byte("restricted object"), ACL: acl})
+	handler := f.holder.transport.(*MockTransport).registeredRPCHandlers[chunkStoreMethod]
+	return handler(context.Background(), "owner", params)
+}
+
+// fetch sends req to the holder as coming from peerID.
+func (f *aclFixture) fetch(peerID string, req ChunkFetchRequest) error {
+	params, _ := json.Marshal(req)
+	handler := f.holder.transport.(*MockTransport).registeredRPCHandlers[chunkFetchMethod]
+	_, err := handler(context.Background(), peerID, params)
+	return err
+}
+
+// requester creates a node the holder knows the key of.
+func (f *aclFixture) requester(nodeID string) *MeshCoordinator {
+	coord := NewMeshCoordinator(nodeID, "us-east", &MockTransport{nodeID: nodeID}, nil)
+	f.holder.gossip.SetPeerIdentityKey(nodeID, coord.gossip.PublicKey())
+	return coord
+}
+
+func expectDenied(t *testing.T, err error, reason string) {
+	t.Helper()
+	if !errors.Is(err, ErrChunkAccessDenied) || !strings.Contains(err.Error(), reason) {
+		t.Fatalf("expected denial %q, got %v", reason, err)
+	}
+}
+
+func TestChunkACL_HolderServesOnlyAllowedRequesters(t *testing.T) {
+	f := newACLFixture(t, &ObjectACL{AllowedNodes: []string{"friend"}})
+	if f.manifest.ACL == nil || f.manifest.ACL.Owner != "owner" || f.manifest.ACL.Group == "" {
+		t.Fatalf("expected the manifest to record the ACL, got %+v", f.manifest.ACL)
+	}
+
+	friend := f.requester("friend")
+	stranger := f.requester("stranger")
+	if err := f.fetch("friend", friend.signChunkFetch(f.chunkHash)); err != nil {
+		t.Fatalf("allowed node refused: %v", err)
+	}
+	if err := f.fetch("owner", f.owner.signChunkFetch(f.chunkHash)); err != nil {
+		t.Fatalf("owner refused: %v", err)
+	}
+
+	expectDenied(t, f.fetch("stranger", stranger.signChunkFetch(f.chunkHash)), ChunkDenyNotAllowed)
+	expectDenied(t, f.fetch("stranger", ChunkFetchRequest{ChunkHash: f.chunkHash}), ChunkDenyUnsigned)
+	// The friend's signed request replayed by someone else
+	expectDenied(t, f.fetch("stranger", friend.signChunkFetch(f.chunkHash)), ChunkDenyBadSignature)
+
+	stranger.timeSync.offset.Store(int64(-time.Hour))
+	expectDenied(t, f.fetch("stranger", stranger.signChunkFetch(f.chunkHash)), ChunkDenyStale)
+
+	denials := f.holder.GetChunkAccessDenials()
+	if len(denials) != 4 || denials[0].Reason != ChunkDenyStale || denials[3].Reason != ChunkDenyNotAllowed || denials[0].Owner != "owner" {
+		t.Fatalf("expected four denials, newest first, got %+v", denials)
+	}
+}
+
+func TestChunkACL_GrantsAndDIDs(t *testing.T) {
+	id, err := NewEphemeralNodeIdentity()
+	if err != nil {
+		t.Fatalf("identity: %v", err)
+	}
+	alice := NewMeshCoordinator(id.NodeID(), "us-east", &MockTransport{nodeID: id.NodeID()}, nil)
+	if err := alice.SetNodeIdentity(id); err != nil {
+		t.Fatalf("SetNodeIdentity: %v", err)
+	}
+	alice.SetIdentity("did:inos:alice", "", "")
+
+	f := newACLFixture(t, &ObjectACL{AllowedDIDs: []string{"did:inos:alice"}})
+	if err := f.fetch(alice.nodeID, alice.signChunkFetch(f.chunkHash)); err != nil {
+		t.Fatalf("allowed DID refused: %v", err)
+	}
+
+	guest := f.requester("guest")
+	expired, _ := f.owner.GrantChunkAccess(f.manifest.ACL.Group, "guest", time.Millisecond)
+	time.Sleep(2 * time.Millisecond)
+	req := guest.signChunkFetch(f.chunkHash)
+	req.Grants = []*ChunkGrant{expired}
+	expectDenied(t, f.fetch("guest", req), ChunkDenyBadGrant)
+
+	grant, err := f.owner.GrantChunkAccess(f.manifest.ACL.Group, "guest", time.Hour)
+	if err != nil {
+		t.Fatalf("grant failed: %v", err)
+	}
+	if err := guest.ImportChunkGrant(grant); err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+	if err := f.fetch("guest", guest.signChunkFetch(f.chunkHash)); err != nil {
+		t.Fatalf("granted node refused: %v", err)
+	}
+
+	// A grant is only good for its subject
+	other := f.requester("other")
+	req = other.signChunkFetch(f.chunkHash)
+	req.Grants = []*ChunkGrant{grant}
+	expectDenied(t, f.fetch("other", req), ChunkDenyNotAllowed)
+}
+
+func TestChunkACL_SignedFetchIsServedOnce(t *testing.T) {
+	f := newACLFixture(t, &ObjectACL{AllowedNodes: []string{"friend"}})
+	friend := f.requester("friend")
+
+	req := friend.signChunkFetch(f.chunkHash)
+	if err := f.fetch("friend", req); err != nil {
+		t.Fatalf("allowed node refused: %v", err)
+	}
+	expectDenied(t, f.fetch("friend", req), ChunkDenyReplayed)
+
+	// The nonce is signed, so a replay cannot swap in a fresh one
+	req.Nonce = "fresh"
+	expectDenied(t, f.fetch("friend", req), ChunkDenyBadSignature)
+	if err := f.fetch("friend", friend.signChunkFetch(f.chunkHash)); err != nil {
+		t.Fatalf("new request refused: %v", err)
+	}
+}
+
+func TestChunkACL_SurvivesOwnerKeyRotation(t *testing.T) {
+	f := newACLFixture(t, &ObjectACL{})
+	guest := f.requester("guest")
+	oldACL := f.owner.chunkACLFor(f.chunkHash)
+	before, err := f.owner.GrantChunkAccess(f.manifest.ACL.Group, "guest", time.Hour)
+	if err != nil {
+		t.Fatalf("grant failed: %v", err)
+	}
+	// What a thief of the old key would sign after the rotation, dated
+	// before it
+	backdated := *before
+	backdated.IssuedAt = time.Now().Add(-time.Hour).UnixMilli()
+	backdated.ExpiresAt = time.Now().Add(24 * time.Hour).UnixMilli()
+	backdated.Signature, _, _ = f.owner.gossip.SignAttestation(chunkGrantPayload(&backdated))
+
+	rotation, err := f.owner.RotateIdentityKey()
+	if err != nil {
+		t.Fatalf("RotateIdentityKey failed: %v", err)
+	}
+	if err := f.holder.applyKeyRotation("owner", *rotation); err != nil {
+		t.Fatalf("applyKeyRotation failed: %v", err)
+	}
+
+	// The ACL the holder stored still governs the chunk, but only grants
+	// signed with the owner's current key open it
+	after, err := f.owner.GrantChunkAccess(f.manifest.ACL.Group, "guest", time.Hour)
+	if err != nil {
+		t.Fatalf("grant after rotation failed: %v", err)
+	}
+	req := guest.signChunkFetch(f.chunkHash)
+	req.Grants = []*ChunkGrant{after}
+	if err := f.fetch("guest", req); err != nil {
+		t.Fatalf("granted node refused after rotation: %v", err)
+	}
+	for _, grant := range []*ChunkGrant{before, &backdated} {
+		req := guest.signChunkFetch(f.chunkHash)
+		req.Grants = []*ChunkGrant{grant}
+		expectDenied(t, f.fetch("guest", req), ChunkDenyBadGrant)
+	}
+
+	// Replicas must carry an ACL under the current key, which the owner
+	// re-signed when it rotated
+	if _, err := f.store(oldACL); err == nil {
+		t.Fatal("expected an ACL signed with the retired key to be refused")
+	}
+	resigned := f.owner.chunkACLFor(f.chunkHash)
+	if !resigned.OwnerKey.Equal(f.owner.gossip.PublicKey()) || resigned.Group != oldACL.Group {
+		t.Fatal("expected the owner to re-sign its ACL under the new key")
+	}
+	if _, err := f.store(resigned); err != nil {
+		t.Fatalf("holder refused the re-signed ACL: %v", err)
+	}
+}
+
+func TestChunkACL_OwnerResolvedThroughDID(t *testing.T) {
+	newBound := func(did string) *MeshCoordinator {
+		id, err := NewEphemeralNodeIdentity()
+		if err != nil {
+			t.Fatalf("identity: %v", err)
+		}
+		coord := NewMeshCoordinator(id.NodeID(), "us-east", &MockTransport{nodeID: id.NodeID()}, nil)
+		if err := coord.SetNodeIdentity(id); err != nil {
+			t.Fatalf("SetNodeIdentity: %v", err)
+		}
+		coord.SetIdentity(did, "", "")
+		return coord
+	}
+	laptop := newBound("did:inos:alice")
+	phone := newBound("did:inos:alice")
+	stranger := newBound("did:inos:mallory")
+
+	holder := NewMeshCoordinator("holder", "us-east", &MockTransport{nodeID: "holder"}, nil)
+	acl, err := laptop.signChunkACL("chunk", &ObjectACL{Group: "photos"})
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	if err := holder.acceptChunkACL("chunk", acl); err != nil {
+		t.Fatalf("holder refused the ACL: %v", err)
+	}
+
+	guest := NewMeshCoordinator("guest", "us-east", &MockTransport{nodeID: "guest"}, nil)
+	holder.gossip.SetPeerIdentityKey("guest", guest.gossip.PublicKey())
+	for _, tc := range []struct {
+		issuer *MeshCoordinator
+		want   string
+	}{{phone, ""}, {stranger, ChunkDenyNotAllowed}} {
+		grant, err := tc.issuer.GrantChunkAccess("photos", "guest", time.Hour)
+		if err != nil {
+			t.Fatalf("grant failed: %v", err)
+		}
+		req := guest.signChunkFetch("chunk")
+		req.Grants = []*ChunkGrant{grant}
+		if reason, _ := holder.authorizeChunkFetch("guest", &req); reason != tc.want {
+			t.Fatalf("expected grant from %s to give %q, got %q", tc.issuer.nodeID, tc.want, reason)
+		}
+	}
+
+	// Another node of the same DID may reissue the ACL; a stranger may not
+	time.Sleep(2 * time.Millisecond)
+	reissued, _ := phone.signChunkACL("chunk", &ObjectACL{Group: "photos"})
+	if err := holder.acceptChunkACL("chunk", reissued); err != nil || holder.chunkACLFor("chunk").Owner != phone.nodeID {
+		t.Fatalf("expected the DID's other node to replace the ACL, got %v", err)
+	}
+	hijack, _ := stranger.signChunkACL("chunk", &ObjectACL{Group: "photos"})
+	if err := holder.acceptChunkACL("chunk", hijack); err == nil {
+		t.Fatal("expected an ACL from another DID to be rejected")
+	}
+}
+
+func TestChunkACL_ReplicaACLMustComeFromOwner(t *testing.T) {
+	f := newACLFixture(t, &ObjectACL{})
+
+	// An ACL signed for another chunk
+	other := f.owner.chunkACLFor(f.manifest.Chunks[0].Hash)
+	wrong := *other
+	wrong.ChunkHash = "another-chunk"
+	if _, err := f.store(&wrong); err == nil {
+		t.Fatal("expected an ACL for another chunk to be rejected")
+	}
+
+	// Another node cannot swap in its own ACL
+	intruder := NewMeshCoordinator("intruder", "us-east", &MockTransport{nodeID: "intruder"}, nil)
+	f.holder.gossip.SetPeerIdentityKey("intruder", intruder.gossip.PublicKey())
+	open, err := intruder.signChunkACL(f.chunkHash, &ObjectACL{Group: "g", AllowedNodes: []string{"intruder"}})
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	if _, err := f.store(open); err == nil {
+		t.Fatal("expected an ACL from another owner to be rejected")
+	}
+	expectDenied(t, f.fetch("intruder", intruder.signChunkFetch(f.chunkHash)), ChunkDenyNotAllowed)
+
+	if f.holder.forgetChunkACL(f.chunkHash); f.holder.chunkACLFor(f.chunkHash) != nil {
+		t.Fatal("expected the ACL dropped with the chunk")
+	}
+}
+
+func TestChunkACL_OwnerKeepsReportedDenials(t *testing.T) {
+	f := newACLFixture(t, &ObjectACL{})
+	msg := &common.GossipMessage{
+		Type:   chunkAccessDeniedTopic,
+		Sender: "holder",
+		Payload: map[string]interface{}{
+			"chunk_hash": f.chunkHash,
+			"owner":      "owner",
+			"holder":     "someone-else",
+			"requester":  "stranger",
+			"reason":     ChunkDenyNotAllowed,
+		},
+	}
+	if err := f.owner.handleChunkAccessDenied(msg); err != nil {
+		t.Fatalf("report rejected: %v", err)
+	}
+	denials := f.owner.GetChunkAccessDenials()
+	if len(denials) != 1 || denials[0].Holder != "holder" || denials[0].Requester != "stranger" {
+		t.Fatalf("expected the report kept under the sending holder, got %+v", denials)
+	}
+
+	// Reports about other owners' chunks are ignored
+	msg.Payload.(map[string]interface{})["owner"] = "someone"
+	_ = f.owner.handleChunkAccessDenied(msg)
+	if len(f.owner.GetChunkAccessDenials()) != 1 {
+		t.Fatal("expected a report for another owner to be ignored")
+	}
+
+	first := ChunkAccessDenial{Owner: "owner", Requester: "stranger", Reason: ChunkDenyNotAllowed, At: time.Now()}
+	if !f.holder.shouldNotifyDenial(first) || f.holder.shouldNotifyDenial(first) {
+		t.Fatal("expected repeated denials reported once per NotifyInterval")
+	}
+}
+
+func TestChunkACL_UnreachableHolderGetsNoBareChunk(t *testing.T) {
+	tr := &MockTransport{nodeID: "owner", rpcFailures: map[string]error{chunkStoreMethod + "@holder": errors.New("rpc down")}}
+	owner := NewMeshCoordinator("owner", "us-east", tr, nil)
+	if err := owner.restrictChunk("chunk", &ObjectACL{Group: "g"}); err != nil {
+		t.Fatalf("restrictChunk failed: %v", err)
+	}
+
+	if err := owner.sendChunkToPeer(context.Background(), "holder", "chunk", []byte("secret")); err == nil {
+		t.Fatal("expected the failed chunk.store to be reported")
+	}
+	if len(tr.sentMsgs) != 0 {
+		t.Fatalf("chunk must not be sent without its ACL, sent %v", tr.sentMsgs)
+	}
+}
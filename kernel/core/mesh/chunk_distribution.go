@@ -0,0 +1,334 @@
+package mesh
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+	"github.com/nmxmxh/inos_v1/kernel/gen/p2p/v1"
+	"github.com/nmxmxh/inos_v1/kernel/threads/sab"
+)
+
+// errNoPeerReplica is returned when a distribution finished without any
+// peer confirming a replica.
+var errNoPeerReplica = errors.New("no peer confirmed a replica")
+
+// ReplicaState is where one replica send stands.
+type ReplicaState string
+
+const (
+	ReplicaPending   ReplicaState = "pending"
+	ReplicaConfirmed ReplicaState = "confirmed"
+	ReplicaFailed    ReplicaState = "failed"
+)
+
+// ReplicaProgress is one peer's share of a distribution.
+type ReplicaProgress struct {
+	PeerID    string       `json:"peer_id"`
+	State     ReplicaState `json:"state"`
+	BytesSent int          `json:"bytes_sent"`
+	Error     string       `json:"error,omitempty"`
+}
+
+// DistributionProgress is a snapshot of a chunk distribution.
+type DistributionProgress struct {
+	ChunkHash   string            `json:"chunk_hash"`
+	Size        int               `json:"size"`
+	Requested   int               `json:"requested_replicas"`
+	Confirmed   int               `json:"confirmed"` // Peer replicas acknowledged
+	Failed      int               `json:"failed"`
+	BytesSent   int64             `json:"bytes_sent"`
+	LocalStored bool              `json:"local_stored"`
+	Peers       []ReplicaProgress `json:"peers"`
+	Done        bool              `json:"done"`
+	Error       string            `json:"error,omitempty"`
+}
+
+// ChunkDistribution tracks a chunk being replicated in the background.
+// Each replica outcome is published as MeshEventChunkReplicated and the
+// result as MeshEventChunkDistributed or MeshEventChunkDistributionFailed.
+type ChunkDistribution struct {
+	mu        sync.Mutex
+	progress  DistributionProgress
+	replicas  int
+	err       error
+	first     chan struct{} // Closed on the first peer confirmation or when done
+	firstOnce sync.Once
+	done      chan struct{}
+}
+
+// Progress returns the distribution's current state.
+func (d *ChunkDistribution) Progress() DistributionProgress {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	p := d.progress
+	p.Peers = append([]ReplicaProgress(nil), d.progress.Peers...)
+	return p
+}
+
+// Done is closed once every replica send has finished.
+func (d *ChunkDistribution) Done() <-chan struct{} {
+	return d.done
+}
+
+// Wait blocks until the distribution finishes and returns the replicas
+// delivered, the local copy included.
+func (d *ChunkDistribution) Wait(ctx context.Context) (int, error) {
+	select {
+	case <-d.done:
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		return d.replicas, d.err
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+}
+
+// WaitFirstReplica blocks until a peer confirms a replica. If the
+// distribution ends first, it returns why no peer did.
+func (d *ChunkDistribution) WaitFirstReplica(ctx context.Context) error {
+	select {
+	case <-d.first:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	switch {
+	case d.progress.Confirmed > 0:
+		return nil
+	case d.err != nil:
+		return d.err
+	default:
+		return errNoPeerReplica
+	}
+}
+
+func (d *ChunkDistribution) closeFirst() {
+	d.firstOnce.Do(func() { close(d.first) })
+}
+
+// DistributeChunkAsync starts distributing a chunk and returns at once. ctx
+// bounds the whole distribution.
+func (m *MeshCoordinator) DistributeChunkAsync(ctx context.Context, chunkHash string, data []byte) *ChunkDistribution {
+	// 1. Calculate optimal replicas based on size and demand
+	demandScore := m.demandTracker.GetDemandScore(chunkHash)
+	replicas := m.allocator.CalculateReplicas(common.Resource{
+		Size:        uint64(len(data)),
+		Type:        "chunk",
+		DemandScore: demandScore,
+	})
+
+	m.logger.Debug("distributing chunk",
+		"chunk", getShortID(chunkHash),
+		"size", len(data),
+		"replicas", replicas)
+
+	// 2. Find candidate peers via DHT
+	closestPeers := m.dht.FindNode(chunkHash)
+
+	// 3. Score and select best peers
+	scored := m.scorePeers(closestPeers)
+	selected := scored[:minInt(replicas, len(scored))]
+
+	d := &ChunkDistribution{
+		progress: DistributionProgress{
+			ChunkHash: chunkHash,
+			Size:      len(data),
+			Requested: replicas,
+			Peers:     make([]ReplicaProgress, len(selected)),
+		},
+		first: make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	for i, peer := range selected {
+		d.progress.Peers[i] = ReplicaProgress{PeerID: peer.ID, State: ReplicaPending}
+	}
+
+	go m.runDistribution(withReplicaTarget(ctx, replicas), d, selected, data)
+	return d
+}
+
+// DistributeChunkFirstReplica returns as soon as one peer confirms a
+// replica; the remaining sends, and the local copy, finish in the
+// background even if ctx ends.
+func (m *MeshCoordinator) DistributeChunkFirstReplica(ctx context.Context, chunkHash string, data []byte) (*ChunkDistribution, error) {
+	d := m.DistributeChunkAsync(context.WithoutCancel(ctx), chunkHash, data)
+	return d, d.WaitFirstReplica(ctx)
+}
+
+func (m *MeshCoordinator) runDistribution(ctx context.Context, d *ChunkDistribution, selected []PeerInfo, data []byte) {
+	start := time.Now()
+	chunkHash := d.progress.ChunkHash
+
+	// 4. Send chunk to selected peers in parallel
+	var wg sync.WaitGroup
+	sendErrors := make(chan error, len(selected))
+	successfulPeers := make(chan string, len(selected))
+
+	for i, peer := range selected {
+		wg.Add(1)
+		go func(i int, p PeerInfo) {
+			defer wg.Done()
+
+			err := m.sendChunkToPeer(ctx, p.ID, chunkHash, data)
+			m.recordReplica(d, i, len(data), err)
+			if err != nil {
+				sendErrors <- fmt.Errorf("peer %s: %w", getShortID(p.ID), err)
+				return
+			}
+			successfulPeers <- p.ID
+		}(i, peer)
+	}
+
+	wg.Wait()
+	close(sendErrors)
+	close(successfulPeers)
+
+	// 5. Store in local DHT
+	if err := m.storeChunkRecord(chunkHash, m.nodeID); err != nil {
+		m.logger.Warn("failed to store in DHT", "error", err)
+	}
+
+	// 6. Announce via gossip (deferred until reconnect when offline)
+	m.announceChunkOrQueue(chunkHash)
+
+	// 7. Store locally
+	localStored := false
+	var localStoreErr error
+	if m.storage != nil {
+		if err := m.storage.StoreChunk(ctx, chunkHash, data); err != nil {
+			m.logger.Warn("failed to store chunk locally", "error", err)
+			localStoreErr = err
+		} else {
+			localStored = true
+			m.trackStoredChunk(ctx, chunkHash, len(data))
+		}
+	}
+
+	if localStored {
+		m.localChunksMu.Lock()
+		m.localChunks[chunkHash] = struct{}{}
+		m.localChunksMu.Unlock()
+	}
+
+	// 8. Update chunk cache with peers that actually received it
+	deliveredPeerIDs := make([]string, 0, len(selected))
+	for peerID := range successfulPeers {
+		deliveredPeerIDs = append(deliveredPeerIDs, peerID)
+	}
+
+	var firstSendErr error
+	failedSends := 0
+	for err := range sendErrors {
+		failedSends++
+		if firstSendErr == nil {
+			firstSendErr = err
+		}
+		m.logger.Warn("failed to replicate chunk to peer", "chunk", getShortID(chunkHash), "error", err)
+	}
+
+	if len(deliveredPeerIDs) > 0 {
+		m.chunkCache.Put(chunkHash, deliveredPeerIDs, 1.0)
+	}
+
+	deliveredReplicas := len(deliveredPeerIDs)
+	if localStored {
+		deliveredReplicas++
+	}
+
+	var err error
+	if deliveredReplicas == 0 {
+		switch {
+		case firstSendErr != nil:
+			err = fmt.Errorf("chunk distribution failed: %w", firstSendErr)
+		case localStoreErr != nil:
+			err = fmt.Errorf("chunk distribution failed: %w", localStoreErr)
+		default:
+			err = errors.New("chunk distribution failed: no replicas delivered")
+		}
+		m.finishDistribution(d, 0, localStored, err)
+		return
+	}
+
+	m.trackOriginatedChunk(chunkHash, len(data))
+	m.emitChunkDiscoveredEvent(chunkHash, m.nodeID, p2p.ChunkPriority_high)
+
+	m.logger.Info("chunk distributed",
+		"chunk", getShortID(chunkHash),
+		"requested_replicas", d.progress.Requested,
+		"delivered_replicas", deliveredReplicas,
+		"failed_sends", failedSends,
+		"duration", time.Since(start))
+
+	// 9. Signal chunk distribution complete
+	if m.bridge != nil {
+		m.bridge.SignalEpoch(sab.IDX_DELEGATED_CHUNK_EPOCH)
+	}
+
+	m.finishDistribution(d, deliveredReplicas, localStored, nil)
+}
+
+// recordReplica notes one peer's outcome and publishes it.
+func (m *MeshCoordinator) recordReplica(d *ChunkDistribution, i, size int, err error) {
+	d.mu.Lock()
+	peer := &d.progress.Peers[i]
+	if err != nil {
+		peer.State = ReplicaFailed
+		peer.Error = err.Error()
+		d.progress.Failed++
+	} else {
+		peer.State = ReplicaConfirmed
+		peer.BytesSent = size
+		d.progress.Confirmed++
+		d.progress.BytesSent += int64(size)
+	}
+	replica := *peer
+	confirmed, requested := d.progress.Confirmed, d.progress.Requested
+	d.mu.Unlock()
+
+	if err == nil {
+		d.closeFirst()
+	}
+	m.publishEvent(MeshEventChunkReplicated, replica.PeerID, map[string]interface{}{
+		"chunk_hash": d.progress.ChunkHash,
+		"state":      replica.State,
+		"bytes_sent": replica.BytesSent,
+		"error":      replica.Error,
+		"confirmed":  confirmed,
+		"requested":  requested,
+	})
+}
+
+func (m *MeshCoordinator) finishDistribution(d *ChunkDistribution, replicas int, localStored bool, err error) {
+	d.mu.Lock()
+	d.replicas, d.err = replicas, err
+	d.progress.LocalStored = localStored
+	d.progress.Done = true
+	if err != nil {
+		d.progress.Error = err.Error()
+	}
+	progress := d.progress
+	d.mu.Unlock()
+
+	close(d.done)
+	d.closeFirst()
+
+	data := map[string]interface{}{
+		"chunk_hash":   progress.ChunkHash,
+		"replicas":     replicas,
+		"requested":    progress.Requested,
+		"confirmed":    progress.Confirmed,
+		"failed":       progress.Failed,
+		"local_stored": localStored,
+	}
+	if err != nil {
+		data["error"] = err.Error()
+		m.publishEvent(MeshEventChunkDistributionFailed, "", data)
+		return
+	}
+	m.publishEvent(MeshEventChunkDistributed, "", data)
+}
@@ -0,0 +1,122 @@
+package mesh
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+)
+
+// gatedStoreTransport answers chunk.store per peer: some at once, some
+// after a gate opens, some with an error.
+type gatedStoreTransport struct {
+	*MockTransport
+	gate  chan struct{}
+	gated map[string]bool
+	fail  map[string]bool
+}
+
+func (g *gatedStoreTransport) SendRPC(ctx context.Context, peerID, method string, args, reply interface{}) error {
+	if method != chunkStoreMethod {
+		return g.MockTransport.SendRPC(ctx, peerID, method, args, reply)
+	}
+	if g.fail[peerID] {
+		return errors.New("peer unavailable")
+	}
+	if g.gated[peerID] {
+		<-g.gate
+	}
+	req := args.(ChunkStoreRequest)
+	data, _ := json.Marshal(ChunkStoreResponse{Stored: true, Size: req.RawSize})
+	return json.Unmarshal(data, reply)
+}
+
+func newDistributionTestCoordinator(t *testing.T, tr *gatedStoreTransport) *MeshCoordinator {
+	t.Helper()
+	tr.MockTransport = &MockTransport{nodeID: "node-a", sendFailures: map[string]error{}}
+	coord := NewMeshCoordinator("node-a", "us-east", tr, nil)
+	coord.SetSABBridge(newRegionSABBridge(4096 + meshEventRingSize))
+	for _, id := range []string{"peer-1", "peer-2", "peer-3"} {
+		coord.dht.AddPeer(common.PeerInfo{ID: id, Capabilities: &common.PeerCapability{PeerID: id, Reputation: 0.9}})
+		tr.sendFailures[id] = errors.New("legacy send disabled")
+	}
+	return coord
+}
+
+func TestDistributeChunkFirstReplica_ReturnsEarlyAndFinishesInBackground(t *testing.T) {
+	tr := &gatedStoreTransport{gate: make(chan struct{}), gated: map[string]bool{"peer-2": true}, fail: map[string]bool{"peer-3": true}}
+	coord := newDistributionTestCoordinator(t, tr)
+	coord.SetStorage(&MockStorage{chunks: make(map[string][]byte)})
+	sub, err := coord.SubscribeMeshEvents(MeshEventFilter{Topics: []string{"chunk.*"}})
+	if err != nil {
+		t.Fatalf("subscribe failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	d, err := coord.DistributeChunkFirstReplica(ctx, "hash-1", []byte("payload"))
+	if err != nil {
+		t.Fatalf("expected the first replica confirmed: %v", err)
+	}
+	cancel() // The rest carries on without the caller
+
+	deadline := time.Now().Add(time.Second)
+	for d.Progress().Failed == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	p := d.Progress()
+	if p.Done || p.Confirmed != 1 || p.Failed != 1 || p.BytesSent != 7 {
+		t.Fatalf("expected peer-2 still pending, got %+v", p)
+	}
+	states := map[string]ReplicaState{}
+	for _, peer := range p.Peers {
+		states[peer.PeerID] = peer.State
+	}
+	if states["peer-1"] != ReplicaConfirmed || states["peer-2"] != ReplicaPending || states["peer-3"] != ReplicaFailed {
+		t.Fatalf("unexpected per-peer states %v", states)
+	}
+
+	close(tr.gate)
+	replicas, err := d.Wait(context.Background())
+	if err != nil || replicas != 3 {
+		t.Fatalf("expected two peer replicas and the local copy, got %d: %v", replicas, err)
+	}
+	if p := d.Progress(); !p.Done || !p.LocalStored || p.Confirmed != 2 {
+		t.Fatalf("unexpected final progress %+v", p)
+	}
+
+	events, _, _ := coord.ReadMeshEvents(sub.ID, 0)
+	var replicated int
+	var last string
+	for _, ev := range events {
+		if ev.Topic == MeshEventChunkReplicated {
+			replicated++
+		}
+		last = ev.Topic
+	}
+	if replicated != 3 || last != MeshEventChunkDistributed {
+		t.Fatalf("expected three replica events then completion, got %+v", events)
+	}
+}
+
+func TestDistributeChunkAsync_FailureIsReportedEverywhere(t *testing.T) {
+	tr := &gatedStoreTransport{fail: map[string]bool{"peer-1": true, "peer-2": true, "peer-3": true}}
+	coord := newDistributionTestCoordinator(t, tr)
+	sub, _ := coord.SubscribeMeshEvents(MeshEventFilter{Topics: []string{MeshEventChunkDistributionFailed}})
+
+	d := coord.DistributeChunkAsync(context.Background(), "hash-2", []byte("payload"))
+	if err := d.WaitFirstReplica(context.Background()); err == nil {
+		t.Fatal("expected no replica to be confirmed")
+	}
+	if _, err := d.Wait(context.Background()); err == nil {
+		t.Fatal("expected the distribution to fail")
+	}
+	if p := d.Progress(); p.Failed != 3 || p.Error == "" {
+		t.Fatalf("unexpected progress %+v", p)
+	}
+	if events, _, _ := coord.ReadMeshEvents(sub.ID, 0); len(events) != 1 {
+		t.Fatalf("expected one failure event, got %+v", events)
+	}
+}
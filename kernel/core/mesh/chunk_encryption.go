@@ -0,0 +1,240 @@
+package mesh
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/routing"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/hkdf"
+)
+
+const (
+	// ChunkEncryptionAlgorithm marks manifests whose chunks the mesh sealed
+	// with a namespace key.
+	ChunkEncryptionAlgorithm = "xchacha20-poly1305"
+
+	namespaceKeyVersion   = "inos-chunk-key-v1"
+	namespaceKeyIDVersion = "inos-chunk-key-id-v1"
+	namespaceKeyIDSize    = 16
+)
+
+// sealedChunkMagic starts every sealed chunk. A sealed chunk is
+//
+//	magic | key ID (16) | nonce (24) | ciphertext and tag
+//
+// so any holder of the key can open it without the manifest.
+var sealedChunkMagic = []byte("INOSENC1")
+
+// sealedChunkOverhead is the bytes sealing adds to a chunk.
+const sealedChunkOverhead = 8 + namespaceKeyIDSize + chacha20poly1305.NonceSizeX + chacha20poly1305.Overhead
+
+var (
+	// ErrNamespaceKeyUnavailable is returned when a chunk is sealed with a key
+	// this node does not hold.
+	ErrNamespaceKeyUnavailable = errors.New("namespace key not held")
+
+	// ErrChunkDecrypt is returned when a sealed chunk fails authentication.
+	ErrChunkDecrypt = errors.New("sealed chunk failed authentication")
+)
+
+// NamespaceKey is the symmetric key chunks stored under one namespace are
+// sealed with. The owner derives it from their identity key; anyone else
+// holding it was given it through ShareNamespaceKey or ImportNamespaceKey.
+type NamespaceKey struct {
+	Namespace string `json:"namespace"`
+	Owner     string `json:"owner"` // DID the key was derived for
+	KeyID     string `json:"key_id"`
+	Key       []byte `json:"key"`
+}
+
+func namespaceKeyID(key []byte) string {
+	sum := sha256.Sum256(append([]byte(namespaceKeyIDVersion), key...))
+	return hex.EncodeToString(sum[:namespaceKeyIDSize])
+}
+
+// namespaceKeySeed is the secret a namespace key is derived from: the
+// identity key's signature over the namespace. Ed25519 signatures are
+// deterministic, so the same key always yields the same seed, and no one
+// without the key can produce it.
+func namespaceKeySeed(did, namespace string) []byte {
+	buf := make([]byte, 0, len(namespaceKeyVersion)+len(did)+len(namespace)+2)
+	buf = append(buf, namespaceKeyVersion...)
+	buf = append(buf, 0)
+	buf = append(buf, did...)
+	buf = append(buf, 0)
+	return append(buf, namespace...)
+}
+
+// NamespaceKey returns this node's key for namespace, deriving it from the
+// identity key bound to the node's DID on first use. Derived keys are kept,
+// so objects sealed before a key rotation stay readable while the node runs;
+// export the key to keep it across a rotation and restart.
+func (m *MeshCoordinator) NamespaceKey(namespace string) (*NamespaceKey, error) {
+	if namespace == "" {
+		return nil, errors.New("namespace is required")
+	}
+	m.identityMu.RLock()
+	did := m.did
+	m.identityMu.RUnlock()
+
+	m.chunkKeysMu.RLock()
+	for _, key := range m.chunkKeys {
+		if key.Owner == did && key.Namespace == namespace {
+			m.chunkKeysMu.RUnlock()
+			return key, nil
+		}
+	}
+	m.chunkKeysMu.RUnlock()
+
+	seed := namespaceKeySeed(did, namespace)
+	var sig []byte
+	var err error
+	if id := m.NodeIdentity(); id != nil {
+		sig, err = id.Sign(seed)
+	} else {
+		sig, _, err = m.gossip.SignAttestation(seed)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive namespace key: %w", err)
+	}
+	key := make([]byte, chacha20poly1305.KeySize)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, sig, []byte(namespaceKeyVersion), seed), key); err != nil {
+		return nil, err
+	}
+
+	nk := &NamespaceKey{Namespace: namespace, Owner: did, KeyID: namespaceKeyID(key), Key: key}
+	m.chunkKeysMu.Lock()
+	if held, ok := m.chunkKeys[nk.KeyID]; ok {
+		nk = held
+	} else {
+		m.chunkKeys[nk.KeyID] = nk
+	}
+	m.chunkKeysMu.Unlock()
+	return nk, nil
+}
+
+// ImportNamespaceKey authorizes this node to open chunks sealed with key.
+func (m *MeshCoordinator) ImportNamespaceKey(key *NamespaceKey) error {
+	if key == nil || len(key.Key) != chacha20poly1305.KeySize {
+		return errors.New("invalid namespace key")
+	}
+	if key.KeyID != namespaceKeyID(key.Key) {
+		return errors.New("namespace key does not match its key ID")
+	}
+	copied := *key
+	copied.Key = append([]byte(nil), key.Key...)
+
+	m.chunkKeysMu.Lock()
+	m.chunkKeys[copied.KeyID] = &copied
+	m.chunkKeysMu.Unlock()
+	return nil
+}
+
+// RemoveNamespaceKey forgets a key. Chunks already opened are unaffected,
+// and peers it was shared with keep their copy.
+func (m *MeshCoordinator) RemoveNamespaceKey(keyID string) bool {
+	m.chunkKeysMu.Lock()
+	defer m.chunkKeysMu.Unlock()
+	_, ok := m.chunkKeys[keyID]
+	delete(m.chunkKeys, keyID)
+	return ok
+}
+
+// ShareNamespaceKey seals this node's key for namespace to peerID, for the
+// peer to pass to AcceptNamespaceKey. Only the peer can open the envelope.
+func (m *MeshCoordinator) ShareNamespaceKey(namespace, peerID string) (*routing.E2EEnvelope, error) {
+	key, err := m.NamespaceKey(namespace)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(key)
+	if err != nil {
+		return nil, err
+	}
+	return m.gossip.SealBytes(peerID, data)
+}
+
+// AcceptNamespaceKey opens a key shared by a peer and imports it.
+func (m *MeshCoordinator) AcceptNamespaceKey(env *routing.E2EEnvelope) (*NamespaceKey, error) {
+	data, err := m.gossip.OpenBytes(env)
+	if err != nil {
+		return nil, err
+	}
+	var key NamespaceKey
+	if err := json.Unmarshal(data, &key); err != nil {
+		return nil, fmt.Errorf("invalid shared namespace key: %w", err)
+	}
+	if err := m.ImportNamespaceKey(&key); err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// sealChunk encrypts one chunk. The nonce is a MAC of the plaintext, so a
+// chunk sealed twice under one key is stored once; it reveals only that
+// two chunks of the namespace are equal.
+func sealChunk(key *NamespaceKey, plaintext []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.NewX(key.Key)
+	if err != nil {
+		return nil, err
+	}
+	keyID, err := hex.DecodeString(key.KeyID)
+	if err != nil || len(keyID) != namespaceKeyIDSize {
+		return nil, errors.New("invalid namespace key ID")
+	}
+
+	mac := hmac.New(sha256.New, key.Key)
+	mac.Write(plaintext)
+	nonce := mac.Sum(nil)[:chacha20poly1305.NonceSizeX]
+
+	out := make([]byte, 0, len(plaintext)+sealedChunkOverhead)
+	out = append(out, sealedChunkMagic...)
+	out = append(out, keyID...)
+	out = append(out, nonce...)
+	header := out[:len(out):len(out)]
+	return aead.Seal(out, nonce, plaintext, header), nil
+}
+
+// isSealedChunk reports whether data is a sealed chunk.
+func isSealedChunk(data []byte) bool {
+	return len(data) >= sealedChunkOverhead && bytes.HasPrefix(data, sealedChunkMagic)
+}
+
+// sealedChunkKeyID returns the ID of the key a sealed chunk needs.
+func sealedChunkKeyID(data []byte) string {
+	start := len(sealedChunkMagic)
+	return hex.EncodeToString(data[start : start+namespaceKeyIDSize])
+}
+
+// openChunk decrypts data if it is sealed and returns it unchanged if not.
+func (m *MeshCoordinator) openChunk(data []byte) ([]byte, error) {
+	if !isSealedChunk(data) {
+		return data, nil
+	}
+	keyID := sealedChunkKeyID(data)
+	m.chunkKeysMu.RLock()
+	key, ok := m.chunkKeys[keyID]
+	m.chunkKeysMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNamespaceKeyUnavailable, keyID)
+	}
+
+	aead, err := chacha20poly1305.NewX(key.Key)
+	if err != nil {
+		return nil, err
+	}
+	headerLen := len(sealedChunkMagic) + namespaceKeyIDSize + chacha20poly1305.NonceSizeX
+	nonce := data[headerLen-chacha20poly1305.NonceSizeX : headerLen]
+	plaintext, err := aead.Open(nil, nonce, data[headerLen:], data[:headerLen])
+	if err != nil {
+		return nil, ErrChunkDecrypt
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,109 @@
+package mesh
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestChunkEncryption_SealedObjectRoundTrip(t *testing.T) {
+	coord := NewMeshCoordinator("owner", "us-east", &MockTransport{nodeID: "owner"}, nil)
+	storage := &MockStorage{chunks: map[string][]byte{}}
+	coord.SetStorage(storage)
+
+	object := append(bytes.Repeat([]byte("secret"), 32), bytes.Repeat([]byte("secret"), 32)...)
+	hash, manifest, err := coord.PutObject(context.Background(), bytes.NewReader(object), ObjectOptions{
+		ChunkSize:        192,
+		EncryptNamespace: "photos",
+	})
+	if err != nil {
+		t.Fatalf("PutObject failed: %v", err)
+	}
+	key, _ := coord.NamespaceKey("photos")
+	if !manifest.Encryption.sealed() || manifest.Encryption.KeyID != key.KeyID || manifest.Encryption.Namespace != "photos" {
+		t.Fatalf("expected the manifest to name the namespace key, got %+v", manifest.Encryption)
+	}
+	if manifest.Size != uint64(len(object)) || manifest.Chunks[0].Hash != manifest.Chunks[1].Hash {
+		t.Fatalf("expected equal chunks to seal identically, got %+v", manifest)
+	}
+	for chunkHash, data := range storage.chunks {
+		if chunkHash != hash && bytes.Contains(data, []byte("secret")) {
+			t.Fatalf("chunk %s stored in plaintext", chunkHash)
+		}
+	}
+
+	var out bytes.Buffer
+	if n, err := coord.GetObject(context.Background(), hash, &out); err != nil || n != int64(len(object)) || !bytes.Equal(out.Bytes(), object) {
+		t.Fatalf("round trip failed: n=%d err=%v", n, err)
+	}
+	if data, err := coord.FetchChunk(context.Background(), manifest.Chunks[0].Hash); err != nil || !bytes.Equal(data, object[:192]) {
+		t.Fatalf("expected FetchChunk to open the chunk, err=%v", err)
+	}
+}
+
+func TestChunkEncryption_OnlyAuthorizedIdentitiesDecrypt(t *testing.T) {
+	owner := NewMeshCoordinator("owner", "us-east", &MockTransport{nodeID: "owner"}, nil)
+	reader := NewMeshCoordinator("reader", "us-east", &MockTransport{nodeID: "reader"}, nil)
+	storage := &MockStorage{chunks: map[string][]byte{}}
+	owner.SetStorage(storage)
+	reader.SetStorage(storage)
+	owner.gossip.SetPeerIdentityKey("reader", reader.gossip.PublicKey())
+	reader.gossip.SetPeerIdentityKey("owner", owner.gossip.PublicKey())
+
+	object := []byte("for authorized eyes only")
+	hash, manifest, err := owner.PutObject(context.Background(), bytes.NewReader(object), ObjectOptions{EncryptNamespace: "docs"})
+	if err != nil {
+		t.Fatalf("PutObject failed: %v", err)
+	}
+	if _, err := reader.GetObject(context.Background(), hash, &bytes.Buffer{}); !errors.Is(err, ErrNamespaceKeyUnavailable) {
+		t.Fatalf("expected ErrNamespaceKeyUnavailable without the key, got %v", err)
+	}
+	if _, err := reader.FetchChunk(context.Background(), manifest.Chunks[0].Hash); !errors.Is(err, ErrNamespaceKeyUnavailable) {
+		t.Fatalf("expected FetchChunk to refuse without the key, got %v", err)
+	}
+
+	env, err := owner.ShareNamespaceKey("docs", "reader")
+	if err != nil {
+		t.Fatalf("share failed: %v", err)
+	}
+	if _, err := reader.AcceptNamespaceKey(env); err != nil {
+		t.Fatalf("accept failed: %v", err)
+	}
+	var out bytes.Buffer
+	if _, err := reader.GetObject(context.Background(), hash, &out); err != nil || !bytes.Equal(out.Bytes(), object) {
+		t.Fatalf("expected the shared key to open the object, err=%v", err)
+	}
+}
+
+func TestChunkEncryption_KeysAndTampering(t *testing.T) {
+	coord := NewMeshCoordinator("owner", "us-east", &MockTransport{nodeID: "owner"}, nil)
+
+	photos, err := coord.NamespaceKey("photos")
+	if err != nil {
+		t.Fatalf("derive failed: %v", err)
+	}
+	again, _ := coord.NamespaceKey("photos")
+	docs, _ := coord.NamespaceKey("docs")
+	if again.KeyID != photos.KeyID || docs.KeyID == photos.KeyID {
+		t.Fatal("expected one stable key per namespace")
+	}
+
+	forged := *photos
+	forged.Key = bytes.Repeat([]byte{1}, len(photos.Key))
+	if err := coord.ImportNamespaceKey(&forged); err == nil {
+		t.Fatal("expected a key that does not match its ID to be rejected")
+	}
+
+	sealed, err := sealChunk(photos, []byte("payload"))
+	if err != nil {
+		t.Fatalf("seal failed: %v", err)
+	}
+	sealed[len(sealed)-1] ^= 0xff
+	if _, err := coord.openChunk(sealed); !errors.Is(err, ErrChunkDecrypt) {
+		t.Fatalf("expected ErrChunkDecrypt for a tampered chunk, got %v", err)
+	}
+	if plain, err := coord.openChunk([]byte("plain")); err != nil || string(plain) != "plain" {
+		t.Fatal("expected unsealed chunks to pass through")
+	}
+}
@@ -0,0 +1,141 @@
+package mesh
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// chunkRecord is a provider record this node announced for itself.
+type chunkRecord struct {
+	ttl       time.Duration
+	announced time.Time
+}
+
+type chunkTTLCounters struct {
+	republished atomic.Uint64
+	renewed     atomic.Uint64
+	expired     atomic.Uint64
+}
+
+// ChunkTTLStats summarizes the provider records this node announced.
+type ChunkTTLStats struct {
+	Tracked     int    `json:"tracked"`
+	Hot         int    `json:"hot"`         // Demand at or above the hot threshold
+	Lapsed      int    `json:"lapsed"`      // TTL passed without a republish
+	Republished uint64 `json:"republished"` // Hot records republished early
+	Renewed     uint64 `json:"renewed"`     // Records renewed ahead of expiry by the republish pass
+	Expired     uint64 `json:"expired"`     // Provider records dropped from the local DHT store
+}
+
+// chunkRecordTTL scales a chunk's provider-record TTL with its demand: a chunk
+// nobody asks for gets the minimum, a chunk at full demand the maximum.
+func (m *MeshCoordinator) chunkRecordTTL(chunkHash string) time.Duration {
+	cfg := m.config.ChunkTTL
+	score := m.demandTracker.GetDemandScore(chunkHash)
+	score = min(max(score, 0), 1)
+	return cfg.Min + time.Duration(score*float64(cfg.Max-cfg.Min))
+}
+
+func (m *MeshCoordinator) isHotChunk(chunkHash string) bool {
+	return m.demandTracker.GetDemandScore(chunkHash) >= m.config.ChunkTTL.HotThreshold
+}
+
+// storeChunkRecord announces providerID for chunkHash in the DHT with a
+// demand-scaled TTL. Records for this node are tracked for republishing.
+func (m *MeshCoordinator) storeChunkRecord(chunkHash, providerID string) error {
+	ttl := m.chunkRecordTTL(chunkHash)
+	if err := m.dht.Store(chunkHash, providerID, int64(ttl/time.Second)); err != nil {
+		return err
+	}
+	if providerID == m.nodeID {
+		m.chunkRecordsMu.Lock()
+		m.chunkRecords[chunkHash] = &chunkRecord{ttl: ttl, announced: time.Now()}
+		m.chunkRecordsMu.Unlock()
+	}
+	return nil
+}
+
+func (m *MeshCoordinator) chunkTTLLoop() {
+	if m.config.ChunkTTL.CheckInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(m.config.ChunkTTL.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			m.chunkTTL.expired.Add(uint64(m.dht.ExpireProviders(time.Now())))
+			m.dht.ExpireRecords(time.Now())
+			if !m.deferLowPriority() {
+				m.refreshChunkRecords(time.Now())
+				m.refreshNamedRecords(context.Background(), time.Now())
+			}
+		case <-m.shutdown:
+			return
+		}
+	}
+}
+
+// refreshChunkRecords republishes hot chunks once RepublishFraction of their
+// TTL has passed, so popular content picks up its longer TTL promptly. Cold
+// records keep their short TTL and are only renewed just ahead of expiry by
+// providerRepublishLoop; records for chunks no longer held are forgotten.
+func (m *MeshCoordinator) refreshChunkRecords(now time.Time) int {
+	cfg := m.config.ChunkTTL
+
+	m.localChunksMu.RLock()
+	m.chunkRecordsMu.Lock()
+	var due []string
+	for chunkHash, record := range m.chunkRecords {
+		if _, held := m.localChunks[chunkHash]; !held {
+			delete(m.chunkRecords, chunkHash)
+			continue
+		}
+		threshold := time.Duration(float64(record.ttl) * cfg.RepublishFraction)
+		if now.Sub(record.announced) >= threshold && m.isHotChunk(chunkHash) {
+			due = append(due, chunkHash)
+		}
+	}
+	m.chunkRecordsMu.Unlock()
+	m.localChunksMu.RUnlock()
+
+	republished := 0
+	for _, chunkHash := range due {
+		if err := m.storeChunkRecord(chunkHash, m.nodeID); err != nil {
+			m.logger.Debug("chunk record republish failed", "chunk", getShortID(chunkHash), "error", err)
+			continue
+		}
+		republished++
+	}
+	m.chunkTTL.republished.Add(uint64(republished))
+	return republished
+}
+
+// GetChunkTTLStats returns counts for this node's provider records.
+func (m *MeshCoordinator) GetChunkTTLStats() ChunkTTLStats {
+	now := time.Now()
+
+	m.chunkRecordsMu.Lock()
+	hashes := make([]string, 0, len(m.chunkRecords))
+	stats := ChunkTTLStats{Tracked: len(m.chunkRecords)}
+	for chunkHash, record := range m.chunkRecords {
+		hashes = append(hashes, chunkHash)
+		if now.Sub(record.announced) > record.ttl {
+			stats.Lapsed++
+		}
+	}
+	m.chunkRecordsMu.Unlock()
+
+	for _, chunkHash := range hashes {
+		if m.isHotChunk(chunkHash) {
+			stats.Hot++
+		}
+	}
+	stats.Republished = m.chunkTTL.republished.Load()
+	stats.Renewed = m.chunkTTL.renewed.Load()
+	stats.Expired = m.chunkTTL.expired.Load()
+	return stats
+}
@@ -0,0 +1,129 @@
+package mesh
+
+import (
+	"testing"
+	"time"
+)
+
+func newChunkTTLTestCoordinator(t *testing.T) *MeshCoordinator {
+	t.Helper()
+	tr := &MockTransport{
+		nodeID:      "node-a",
+		rpcHandlers: make(map[string]func(args interface{}) (interface{}, error)),
+	}
+	return NewMeshCoordinator("node-a", "us-east", tr, nil)
+}
+
+func heatChunk(m *MeshCoordinator, chunkHash string, accesses int) {
+	for i := 0; i < accesses; i++ {
+		m.demandTracker.RecordAccess(chunkHash)
+	}
+}
+
+func TestChunkTTL_ScalesWithDemand(t *testing.T) {
+	coord := newChunkTTLTestCoordinator(t)
+	cfg := coord.config.ChunkTTL
+	heatChunk(coord, "hot", 100)
+
+	if err := coord.storeChunkRecord("cold", coord.nodeID); err != nil {
+		t.Fatalf("store cold record: %v", err)
+	}
+	if err := coord.storeChunkRecord("hot", coord.nodeID); err != nil {
+		t.Fatalf("store hot record: %v", err)
+	}
+
+	for chunk, want := range map[string]time.Duration{"cold": cfg.Min, "hot": cfg.Max} {
+		expires, ok := coord.dht.ProviderExpiry(chunk, coord.nodeID)
+		if !ok {
+			t.Fatalf("no DHT record for %s", chunk)
+		}
+		if diff := time.Until(expires) - want; diff < -time.Second || diff > time.Second {
+			t.Fatalf("%s record TTL off by %v", chunk, diff)
+		}
+	}
+
+	if ttl := coord.chunkRecordTTL("warm"); ttl != cfg.Min {
+		t.Fatalf("untracked chunk should get the minimum TTL, got %v", ttl)
+	}
+	heatChunk(coord, "warm", 10)
+	if ttl := coord.chunkRecordTTL("warm"); ttl <= cfg.Min || ttl >= cfg.Max {
+		t.Fatalf("medium demand should land between the bounds, got %v", ttl)
+	}
+}
+
+func TestChunkTTL_RepublishesOnlyHotHeldChunks(t *testing.T) {
+	coord := newChunkTTLTestCoordinator(t)
+	heatChunk(coord, "hot", 100)
+
+	coord.localChunksMu.Lock()
+	coord.localChunks["hot"] = struct{}{}
+	coord.localChunks["cold"] = struct{}{}
+	coord.localChunksMu.Unlock()
+
+	past := time.Now().Add(-4 * time.Hour)
+	coord.chunkRecordsMu.Lock()
+	coord.chunkRecords["hot"] = &chunkRecord{ttl: 6 * time.Hour, announced: past}
+	coord.chunkRecords["cold"] = &chunkRecord{ttl: 10 * time.Minute, announced: past}
+	coord.chunkRecords["gone"] = &chunkRecord{ttl: 6 * time.Hour, announced: past}
+	coord.chunkRecordsMu.Unlock()
+
+	if n := coord.refreshChunkRecords(time.Now()); n != 1 {
+		t.Fatalf("expected only the hot chunk republished, got %d", n)
+	}
+	if _, ok := coord.dht.ProviderExpiry("cold", coord.nodeID); ok {
+		t.Fatal("cold chunk should be left to lapse")
+	}
+
+	stats := coord.GetChunkTTLStats()
+	if stats.Tracked != 2 || stats.Hot != 1 || stats.Lapsed != 1 || stats.Republished != 1 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+
+	// A freshly republished record is not due again
+	if n := coord.refreshChunkRecords(time.Now()); n != 0 {
+		t.Fatalf("expected no republish right after refresh, got %d", n)
+	}
+}
+
+func TestChunkTTL_RenewsEveryHeldChunkBeforeExpiry(t *testing.T) {
+	coord := newChunkTTLTestCoordinator(t)
+	coord.config.ChunkTTL.RepublishBatch = 1
+	coord.config.ChunkTTL.RepublishPause = time.Millisecond
+
+	coord.localChunksMu.Lock()
+	for _, chunk := range []string{"expiring", "fresh", "unrecorded"} {
+		coord.localChunks[chunk] = struct{}{}
+	}
+	coord.localChunksMu.Unlock()
+
+	now := time.Now()
+	coord.chunkRecordsMu.Lock()
+	coord.chunkRecords["expiring"] = &chunkRecord{ttl: 10 * time.Minute, announced: now.Add(-9 * time.Minute)}
+	coord.chunkRecords["fresh"] = &chunkRecord{ttl: 6 * time.Hour, announced: now}
+	coord.chunkRecords["dropped"] = &chunkRecord{ttl: 10 * time.Minute, announced: now.Add(-9 * time.Minute)}
+	coord.chunkRecordsMu.Unlock()
+
+	due := coord.dueProviderRecords(now)
+	if len(due) != 2 || due[0] != "unrecorded" || due[1] != "expiring" {
+		t.Fatalf("expected the unrecorded then the expiring chunk, got %v", due)
+	}
+	if n := coord.republishProviderRecords(now); n != 2 {
+		t.Fatalf("expected two renewals, got %d", n)
+	}
+	if _, ok := coord.dht.ProviderExpiry("unrecorded", coord.nodeID); !ok {
+		t.Fatal("expected the unrecorded chunk to be announced")
+	}
+	if stats := coord.GetChunkTTLStats(); stats.Renewed != 2 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+	if due := coord.dueProviderRecords(time.Now()); len(due) != 0 {
+		t.Fatalf("expected nothing due after renewal, got %v", due)
+	}
+
+	coord.config.ChunkTTL.RepublishJitter = 0.2
+	for i := 0; i < 20; i++ {
+		if d := coord.nextRepublishDelay(); d < 96*time.Second || d > 144*time.Second {
+			t.Fatalf("delay %v outside the jitter window", d)
+		}
+	}
+}
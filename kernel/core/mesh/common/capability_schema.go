@@ -0,0 +1,156 @@
+package common
+
+import (
+	"slices"
+
+	p2p "github.com/nmxmxh/inos_v1/kernel/gen/p2p/v1"
+	capnp "zombiezen.com/go/capnproto2"
+)
+
+// CapabilitySchemaVersion is the PeerCapability layout this build writes.
+// Version 1 is the flat layout of capability strings and RuntimeCaps; 2
+// adds the typed Hardware descriptor and carries the GPU adapter in the
+// Cap'n Proto form. Both encodings skip fields they do not know, so peers
+// on different versions read what they share and ignore the rest.
+const CapabilitySchemaVersion uint16 = 2
+
+// SIMDFlagWASM128 is the SIMD flag of a runtime with WebAssembly 128-bit SIMD.
+const SIMDFlagWASM128 = "simd128"
+
+// HardwareDescriptor describes the machine behind a peer. Zero fields are
+// unknown, not absent.
+type HardwareDescriptor struct {
+	CPUCores         uint16   `json:"cpu_cores,omitempty"`
+	CPUArch          string   `json:"cpu_arch,omitempty"` // GOARCH-style: "amd64", "arm64", "wasm"
+	SIMD             []string `json:"simd,omitempty"`     // e.g. "simd128", "avx2", "neon"
+	MemoryBytes      uint64   `json:"memory_bytes,omitempty"`
+	StorageFreeBytes uint64   `json:"storage_free_bytes,omitempty"` // Storage offered to peers and still free
+}
+
+// HasSIMD reports whether the descriptor lists the SIMD flag.
+func (h *HardwareDescriptor) HasSIMD(flag string) bool {
+	return h != nil && slices.Contains(h.SIMD, flag)
+}
+
+// NegotiateCapabilitySchema returns the schema this build and a peer at
+// remote share. Writers that predate versioning send 0, which is 1.
+func NegotiateCapabilitySchema(remote uint16) uint16 {
+	return min(max(remote, 1), CapabilitySchemaVersion)
+}
+
+// NormalizeSchema fills in what an older schema could not say from what it
+// did, so readers need not care which version wrote the capability. A
+// schema 1 peer that reported SIMD in RuntimeCaps gets a descriptor with
+// the WebAssembly SIMD flag.
+func (p *PeerCapability) NormalizeSchema() {
+	if NegotiateCapabilitySchema(p.SchemaVersion) >= 2 || p.Hardware != nil {
+		return
+	}
+	if p.RuntimeCaps != nil && p.RuntimeCaps.HasSimd {
+		p.Hardware = &HardwareDescriptor{SIMD: []string{SIMDFlagWASM128}}
+	}
+}
+
+// toCapnp fills hw from h, which may be nil, and the GPU adapter.
+func (h *HardwareDescriptor) toCapnp(hw p2p.HardwareDescriptor, gpu *GPUCapability) error {
+	if h != nil {
+		hw.SetCpuCores(h.CPUCores)
+		if err := hw.SetCpuArch(h.CPUArch); err != nil {
+			return err
+		}
+		if err := setTextList(h.SIMD, hw.NewSimd); err != nil {
+			return err
+		}
+		hw.SetMemoryBytes(h.MemoryBytes)
+		hw.SetStorageFreeBytes(h.StorageFreeBytes)
+	}
+	if gpu == nil {
+		return nil
+	}
+
+	g, err := hw.NewGpu()
+	if err != nil {
+		return err
+	}
+	if err := g.SetVendor(gpu.Vendor); err != nil {
+		return err
+	}
+	if err := g.SetArchitecture(gpu.Architecture); err != nil {
+		return err
+	}
+	if err := g.SetDevice(gpu.Device); err != nil {
+		return err
+	}
+	g.SetMaxBufferSize(gpu.MaxBufferSize)
+	g.SetMaxStorageBufferBindingSize(gpu.MaxStorageBufferBindingSize)
+	g.SetMaxComputeWorkgroupStorageSize(gpu.MaxComputeWorkgroupStorageSize)
+	g.SetMaxComputeInvocationsPerWorkgroup(gpu.MaxComputeInvocationsPerWorkgroup)
+	g.SetMaxComputeWorkgroupsPerDimension(gpu.MaxComputeWorkgroupsPerDimension)
+	return setTextList(gpu.Features, g.NewFeatures)
+}
+
+// hardwareFromCapnp reads the descriptor and GPU adapter out of hw; either
+// is nil when hw does not carry it.
+func hardwareFromCapnp(hw p2p.HardwareDescriptor) (*HardwareDescriptor, *GPUCapability) {
+	h := &HardwareDescriptor{
+		CPUCores:         hw.CpuCores(),
+		MemoryBytes:      hw.MemoryBytes(),
+		StorageFreeBytes: hw.StorageFreeBytes(),
+	}
+	h.CPUArch, _ = hw.CpuArch()
+	if simd, err := hw.Simd(); err == nil {
+		h.SIMD = textList(simd)
+	}
+	if h.CPUCores == 0 && h.CPUArch == "" && len(h.SIMD) == 0 && h.MemoryBytes == 0 && h.StorageFreeBytes == 0 {
+		h = nil
+	}
+	if !hw.HasGpu() {
+		return h, nil
+	}
+
+	g, err := hw.Gpu()
+	if err != nil {
+		return h, nil
+	}
+	gpu := &GPUCapability{
+		MaxBufferSize:                     g.MaxBufferSize(),
+		MaxStorageBufferBindingSize:       g.MaxStorageBufferBindingSize(),
+		MaxComputeWorkgroupStorageSize:    g.MaxComputeWorkgroupStorageSize(),
+		MaxComputeInvocationsPerWorkgroup: g.MaxComputeInvocationsPerWorkgroup(),
+		MaxComputeWorkgroupsPerDimension:  g.MaxComputeWorkgroupsPerDimension(),
+	}
+	gpu.Vendor, _ = g.Vendor()
+	gpu.Architecture, _ = g.Architecture()
+	gpu.Device, _ = g.Device()
+	if features, err := g.Features(); err == nil {
+		gpu.Features = textList(features)
+	}
+	return h, gpu
+}
+
+func setTextList(values []string, alloc func(int32) (capnp.TextList, error)) error {
+	if len(values) == 0 {
+		return nil
+	}
+	list, err := alloc(int32(len(values)))
+	if err != nil {
+		return err
+	}
+	for i, v := range values {
+		if err := list.Set(i, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func textList(list capnp.TextList) []string {
+	if list.Len() == 0 {
+		return nil
+	}
+	values := make([]string, list.Len())
+	for i := range values {
+		values[i], _ = list.At(i)
+	}
+	return values
+}
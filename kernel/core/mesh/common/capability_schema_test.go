@@ -0,0 +1,103 @@
+package common
+
+import (
+	"reflect"
+	"testing"
+
+	p2p "github.com/nmxmxh/inos_v1/kernel/gen/p2p/v1"
+	capnp "zombiezen.com/go/capnproto2"
+)
+
+func TestCapabilitySchema_HardwareRoundTrip(t *testing.T) {
+	want := &PeerCapability{
+		PeerID:        "peer1",
+		SchemaVersion: CapabilitySchemaVersion,
+		Hardware: &HardwareDescriptor{
+			CPUCores:         8,
+			CPUArch:          "arm64",
+			SIMD:             []string{"neon", SIMDFlagWASM128},
+			MemoryBytes:      16 << 30,
+			StorageFreeBytes: 200 << 30,
+		},
+		GPU: &GPUCapability{
+			Vendor:        "apple",
+			Architecture:  "metal-3",
+			Device:        "m2",
+			MaxBufferSize: 1 << 30,
+			Features:      []string{"shader-f16"},
+		},
+	}
+
+	_, seg, err := capnp.NewMessage(capnp.SingleSegment(nil))
+	if err != nil {
+		t.Fatalf("Failed to create capnp message: %v", err)
+	}
+	c, err := want.ToCapnp(seg)
+	if err != nil {
+		t.Fatalf("ToCapnp failed: %v", err)
+	}
+	got := &PeerCapability{}
+	if err := got.FromCapnp(c); err != nil {
+		t.Fatalf("FromCapnp failed: %v", err)
+	}
+
+	if got.SchemaVersion != CapabilitySchemaVersion {
+		t.Errorf("schema version = %d, want %d", got.SchemaVersion, CapabilitySchemaVersion)
+	}
+	if !reflect.DeepEqual(got.Hardware, want.Hardware) {
+		t.Errorf("hardware = %+v, want %+v", got.Hardware, want.Hardware)
+	}
+	if !reflect.DeepEqual(got.GPU, want.GPU) {
+		t.Errorf("gpu = %+v, want %+v", got.GPU, want.GPU)
+	}
+}
+
+func TestCapabilitySchema_ReadsSchemaOneLayout(t *testing.T) {
+	// A schema 1 writer's struct is smaller; the new fields read as zero
+	_, seg, err := capnp.NewMessage(capnp.SingleSegment(nil))
+	if err != nil {
+		t.Fatalf("Failed to create capnp message: %v", err)
+	}
+	st, err := capnp.NewStruct(seg, capnp.ObjectSize{DataSize: 24, PointerCount: 6})
+	if err != nil {
+		t.Fatalf("NewStruct failed: %v", err)
+	}
+	old := p2p.PeerCapability{Struct: st}
+	if err := old.SetPeerId("legacy"); err != nil {
+		t.Fatalf("SetPeerId failed: %v", err)
+	}
+	rc, err := old.NewRuntimeCaps()
+	if err != nil {
+		t.Fatalf("NewRuntimeCaps failed: %v", err)
+	}
+	rc.SetHasSimd(true)
+
+	got := &PeerCapability{}
+	if err := got.FromCapnp(old); err != nil {
+		t.Fatalf("FromCapnp failed: %v", err)
+	}
+	if got.PeerID != "legacy" || got.SchemaVersion != 0 || got.Hardware != nil || got.GPU != nil {
+		t.Fatalf("unexpected decode of schema 1 capability: %+v", got)
+	}
+
+	// Normalizing derives a descriptor from what schema 1 could say
+	got.NormalizeSchema()
+	if !got.Hardware.HasSIMD(SIMDFlagWASM128) {
+		t.Errorf("expected simd128 from RuntimeCaps, got %+v", got.Hardware)
+	}
+}
+
+func TestNegotiateCapabilitySchema(t *testing.T) {
+	for remote, want := range map[uint16]uint16{0: 1, 1: 1, 2: 2, 9: CapabilitySchemaVersion} {
+		if got := NegotiateCapabilitySchema(remote); got != want {
+			t.Errorf("NegotiateCapabilitySchema(%d) = %d, want %d", remote, got, want)
+		}
+	}
+
+	// A schema 2 capability is taken as written, even without hardware
+	p := &PeerCapability{SchemaVersion: 2, RuntimeCaps: &RuntimeCapabilities{HasSimd: true}}
+	p.NormalizeSchema()
+	if p.Hardware != nil {
+		t.Errorf("schema 2 capability gained a descriptor: %+v", p.Hardware)
+	}
+}
@@ -0,0 +1,94 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// ErrFaultInjected is returned by an operation a FaultInjector dropped.
+var ErrFaultInjected = errors.New("fault injected")
+
+// FaultPoint names a place in the mesh where faults can be injected.
+type FaultPoint string
+
+const (
+	FaultPointSend      FaultPoint = "transport.send" // target: peer ID
+	FaultPointRPCHandle FaultPoint = "rpc.handle"     // target: RPC method
+	FaultPointStorage   FaultPoint = "storage"        // target: chunk hash
+	FaultPointSAB       FaultPoint = "sab"            // target: "read" or "write"
+)
+
+// Fault is what a FaultInjector decided for one operation. The zero value
+// lets the operation through untouched.
+type Fault struct {
+	Drop    bool          // Fail the operation with ErrFaultInjected
+	Delay   time.Duration // Wait before carrying on
+	Corrupt bool          // Flip bits in the operation's payload
+}
+
+// FaultInjector decides the fault for an operation. Transports that accept
+// one implement SetFaultInjector(FaultInjector); a nil injector disables
+// injection.
+type FaultInjector interface {
+	Inject(point FaultPoint, target string) Fault
+}
+
+// Apply waits out the fault's delay and reports a drop as ErrFaultInjected.
+func (f Fault) Apply(ctx context.Context) error {
+	if f.Delay > 0 {
+		timer := time.NewTimer(f.Delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+	if f.Drop {
+		return ErrFaultInjected
+	}
+	return nil
+}
+
+// CorruptBytes returns a copy of data with a spread of bits flipped, so the
+// damage reaches both framing and contents.
+func CorruptBytes(data []byte) []byte {
+	out := append([]byte(nil), data...)
+	for i := 0; i < len(out); i += 7 {
+		out[i] ^= 0x5a
+	}
+	return out
+}
+
+// FaultHook holds an optional FaultInjector. It sits on hot paths, so the
+// injector is swapped atomically and an empty hook costs one load.
+type FaultHook struct {
+	v atomic.Value // faultInjectorRef
+}
+
+// faultInjectorRef lets atomic.Value hold a nil injector.
+type faultInjectorRef struct {
+	injector FaultInjector
+}
+
+// Set installs injector; nil disables injection.
+func (h *FaultHook) Set(injector FaultInjector) {
+	h.v.Store(faultInjectorRef{injector: injector})
+}
+
+// Injector returns the installed injector, or nil.
+func (h *FaultHook) Injector() FaultInjector {
+	ref, _ := h.v.Load().(faultInjectorRef)
+	return ref.injector
+}
+
+// Inject asks the installed injector for a fault; with none it is a no-op.
+func (h *FaultHook) Inject(point FaultPoint, target string) Fault {
+	injector := h.Injector()
+	if injector == nil {
+		return Fault{}
+	}
+	return injector.Inject(point, target)
+}
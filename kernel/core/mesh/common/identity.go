@@ -0,0 +1,17 @@
+package common
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+const nodeIDPrefix = "node:"
+
+// NodeIDFromPublicKey derives a stable node ID from an identity key. A node
+// keeps the ID when it later rotates the key, so a key that no longer derives
+// the ID speaks for it only through attestation or a verified rotation.
+func NodeIDFromPublicKey(pub ed25519.PublicKey) string {
+	sum := sha256.Sum256(pub)
+	return nodeIDPrefix + hex.EncodeToString(sum[:16])
+}
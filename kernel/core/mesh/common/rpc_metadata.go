@@ -0,0 +1,152 @@
+package common
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/utils/tracing"
+)
+
+// DefaultNamespace is used when a caller does not name a tenant namespace.
+const DefaultNamespace = "default"
+
+// RPCMetadata is caller context carried alongside RPC params. Handlers read
+// it through the context accessors below rather than from their args.
+type RPCMetadata struct {
+	Namespace string            `json:"namespace,omitempty"`
+	Priority  int               `json:"priority,omitempty"`
+	Deadline  int64             `json:"deadline,omitempty"` // Unix milliseconds
+	TraceID   string            `json:"trace_id,omitempty"`
+	Baggage   map[string]string `json:"baggage,omitempty"`
+	// TraceParent is the caller's span as a W3C traceparent, so the handler's
+	// spans join the caller's trace.
+	TraceParent string `json:"traceparent,omitempty"`
+	// Capability is an encoded token authorizing privileged methods; only
+	// the peer that issued it can check it.
+	Capability string `json:"capability,omitempty"`
+}
+
+type rpcMetadataKey struct{}
+
+// WithRPCMetadata attaches metadata to ctx, replacing any already present.
+func WithRPCMetadata(ctx context.Context, md RPCMetadata) context.Context {
+	return context.WithValue(ctx, rpcMetadataKey{}, md)
+}
+
+// RPCMetadataFromContext returns the metadata attached to ctx.
+func RPCMetadataFromContext(ctx context.Context) (RPCMetadata, bool) {
+	md, ok := ctx.Value(rpcMetadataKey{}).(RPCMetadata)
+	return md, ok
+}
+
+// WithNamespace sets the tenant namespace for outgoing RPCs.
+func WithNamespace(ctx context.Context, namespace string) context.Context {
+	md, _ := RPCMetadataFromContext(ctx)
+	md.Namespace = namespace
+	return WithRPCMetadata(ctx, md)
+}
+
+// WithPriority sets the scheduling priority for outgoing RPCs.
+func WithPriority(ctx context.Context, priority int) context.Context {
+	md, _ := RPCMetadataFromContext(ctx)
+	md.Priority = priority
+	return WithRPCMetadata(ctx, md)
+}
+
+// WithTraceID sets the trace ID for outgoing RPCs.
+func WithTraceID(ctx context.Context, traceID string) context.Context {
+	md, _ := RPCMetadataFromContext(ctx)
+	md.TraceID = traceID
+	return WithRPCMetadata(ctx, md)
+}
+
+// WithCapability attaches an encoded capability token for outgoing RPCs.
+func WithCapability(ctx context.Context, token string) context.Context {
+	md, _ := RPCMetadataFromContext(ctx)
+	md.Capability = token
+	return WithRPCMetadata(ctx, md)
+}
+
+// CapabilityFromContext returns the caller's capability token, or "".
+func CapabilityFromContext(ctx context.Context) string {
+	md, _ := RPCMetadataFromContext(ctx)
+	return md.Capability
+}
+
+// NamespaceFromContext returns the caller's namespace, or DefaultNamespace.
+func NamespaceFromContext(ctx context.Context) string {
+	if md, ok := RPCMetadataFromContext(ctx); ok && md.Namespace != "" {
+		return md.Namespace
+	}
+	return DefaultNamespace
+}
+
+// PriorityFromContext returns the caller's priority, if one was set.
+func PriorityFromContext(ctx context.Context) (int, bool) {
+	md, ok := RPCMetadataFromContext(ctx)
+	if !ok || md.Priority == 0 {
+		return 0, false
+	}
+	return md.Priority, true
+}
+
+// TraceIDFromContext returns the trace ID, or "" if none is set.
+func TraceIDFromContext(ctx context.Context) string {
+	md, _ := RPCMetadataFromContext(ctx)
+	return md.TraceID
+}
+
+// NewTraceID returns a random 16-byte hex trace ID.
+func NewTraceID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// OutgoingRPCMetadata builds the metadata to send with a request: the
+// caller's metadata, the context deadline, the caller's span, and a trace ID
+// (the span's, or minted if absent so every call can be correlated across
+// hops).
+func OutgoingRPCMetadata(ctx context.Context) RPCMetadata {
+	md, _ := RPCMetadataFromContext(ctx)
+	if deadline, ok := ctx.Deadline(); ok && (md.Deadline == 0 || deadline.UnixMilli() < md.Deadline) {
+		md.Deadline = deadline.UnixMilli()
+	}
+	if span := tracing.SpanFromContext(ctx); span != nil {
+		md.TraceParent = span.Traceparent()
+		if md.TraceID == "" {
+			md.TraceID = span.Context().TraceIDString()
+		}
+	}
+	if md.TraceID == "" {
+		md.TraceID = NewTraceID()
+	}
+	return md
+}
+
+// IncomingRPCContext derives a handler context from received metadata. The
+// handler deadline is the earlier of the caller's deadline and timeout.
+func IncomingRPCContext(parent context.Context, md *RPCMetadata, timeout time.Duration) (context.Context, context.CancelFunc) {
+	var received RPCMetadata
+	if md != nil {
+		received = *md
+	}
+	ctx := WithRPCMetadata(parent, received)
+	ctx = tracing.ContextWithTraceparent(ctx, received.TraceParent)
+
+	var deadline time.Time
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+	if received.Deadline > 0 {
+		if callerDeadline := time.UnixMilli(received.Deadline); deadline.IsZero() || callerDeadline.Before(deadline) {
+			deadline = callerDeadline
+		}
+	}
+	if deadline.IsZero() {
+		return context.WithCancel(ctx)
+	}
+	return context.WithDeadline(ctx, deadline)
+}
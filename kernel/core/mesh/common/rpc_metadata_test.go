@@ -0,0 +1,58 @@
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestRPCMetadata_RoundTripsIntoHandlerContext(t *testing.T) {
+	ctx := WithNamespace(context.Background(), "tenant-a")
+	ctx = WithPriority(ctx, 220)
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	md := OutgoingRPCMetadata(ctx)
+	if md.TraceID == "" || md.Deadline == 0 {
+		t.Fatalf("expected trace ID and deadline to be filled, got %+v", md)
+	}
+
+	// Simulate the wire.
+	data, _ := json.Marshal(md)
+	var received RPCMetadata
+	if err := json.Unmarshal(data, &received); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	handlerCtx, done := IncomingRPCContext(context.Background(), &received, 30*time.Second)
+	defer done()
+
+	if NamespaceFromContext(handlerCtx) != "tenant-a" {
+		t.Fatalf("namespace not propagated")
+	}
+	if p, ok := PriorityFromContext(handlerCtx); !ok || p != 220 {
+		t.Fatalf("priority not propagated: %d %v", p, ok)
+	}
+	if TraceIDFromContext(handlerCtx) != md.TraceID {
+		t.Fatalf("trace ID not propagated")
+	}
+	deadline, ok := handlerCtx.Deadline()
+	if !ok || time.Until(deadline) > 2*time.Second {
+		t.Fatalf("caller deadline should bound the handler, got %v", time.Until(deadline))
+	}
+}
+
+func TestRPCMetadata_Defaults(t *testing.T) {
+	ctx, done := IncomingRPCContext(context.Background(), nil, time.Second)
+	defer done()
+	if NamespaceFromContext(ctx) != DefaultNamespace {
+		t.Fatalf("expected default namespace")
+	}
+	if _, ok := PriorityFromContext(ctx); ok {
+		t.Fatalf("expected no priority")
+	}
+	if _, ok := ctx.Deadline(); !ok {
+		t.Fatalf("request timeout should still bound the handler")
+	}
+}
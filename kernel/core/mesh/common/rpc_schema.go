@@ -0,0 +1,341 @@
+package common
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RPCProtocolName identifies the mesh RPC protocol in generated descriptions.
+const RPCProtocolName = "inos-mesh-rpc"
+
+// RPCProtocolVersion is bumped whenever a method's wire shape changes incompatibly.
+const RPCProtocolVersion = "1.0.0"
+
+// RPCSchema is a JSON-Schema style description of an RPC payload.
+type RPCSchema struct {
+	Type                 string                `json:"type"` // object | array | string | integer | number | boolean | any | null
+	Format               string                `json:"format,omitempty"`
+	Properties           map[string]*RPCSchema `json:"properties,omitempty"`
+	Required             []string              `json:"required,omitempty"`
+	Items                *RPCSchema            `json:"items,omitempty"`
+	AdditionalProperties *RPCSchema            `json:"additional_properties,omitempty"`
+}
+
+// RPCMethodSpec documents a single mesh RPC method.
+type RPCMethodSpec struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Streaming   bool   `json:"streaming,omitempty"` // Response is streamed via StreamRPC
+
+	// Request and Response are zero values of the wire types; schemas are derived from them.
+	Request  interface{} `json:"-"`
+	Response interface{} `json:"-"`
+
+	RequestSchema  *RPCSchema `json:"request"`
+	ResponseSchema *RPCSchema `json:"response"`
+}
+
+// RPCProtocolDescription is the machine-readable description of all mesh RPCs.
+type RPCProtocolDescription struct {
+	Protocol string          `json:"protocol"`
+	Version  string          `json:"version"`
+	Envelope string          `json:"envelope"`
+	Methods  []RPCMethodSpec `json:"methods"`
+}
+
+// RPCRegistry holds typed schemas for RPC methods.
+type RPCRegistry struct {
+	mu      sync.RWMutex
+	methods map[string]RPCMethodSpec
+}
+
+// DefaultRPCRegistry collects the methods registered by mesh packages at init.
+var DefaultRPCRegistry = NewRPCRegistry()
+
+// NewRPCRegistry creates an empty registry.
+func NewRPCRegistry() *RPCRegistry {
+	return &RPCRegistry{methods: make(map[string]RPCMethodSpec)}
+}
+
+// Register adds a method, deriving its schemas from the request/response samples.
+func (r *RPCRegistry) Register(spec RPCMethodSpec) error {
+	if spec.Name == "" {
+		return errors.New("rpc method name is required")
+	}
+	if spec.RequestSchema == nil {
+		spec.RequestSchema = SchemaFor(spec.Request)
+	}
+	if spec.ResponseSchema == nil {
+		spec.ResponseSchema = SchemaFor(spec.Response)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.methods[spec.Name]; exists {
+		return fmt.Errorf("rpc method %s already registered", spec.Name)
+	}
+	r.methods[spec.Name] = spec
+	return nil
+}
+
+// MustRegisterRPCMethod registers into DefaultRPCRegistry and panics on
+// duplicates; intended for package init.
+func MustRegisterRPCMethod(spec RPCMethodSpec) {
+	if err := DefaultRPCRegistry.Register(spec); err != nil {
+		panic(err)
+	}
+}
+
+// Lookup returns the spec for a method.
+func (r *RPCRegistry) Lookup(name string) (RPCMethodSpec, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	spec, ok := r.methods[name]
+	return spec, ok
+}
+
+// Methods returns all specs sorted by name.
+func (r *RPCRegistry) Methods() []RPCMethodSpec {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]RPCMethodSpec, 0, len(r.methods))
+	for _, spec := range r.methods {
+		out = append(out, spec)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// Describe generates the protocol description.
+func (r *RPCRegistry) Describe() RPCProtocolDescription {
+	return RPCProtocolDescription{
+		Protocol: RPCProtocolName,
+		Version:  RPCProtocolVersion,
+		Envelope: "JSON RPCRequest{id, method, params, timeout, metadata{namespace, priority, deadline, trace_id, baggage, capability}} / RPCResponse{id, result, error{code, message}} over a peer data channel",
+		Methods:  r.Methods(),
+	}
+}
+
+// ValidateRequest checks raw request args against the method's schema.
+func (r *RPCRegistry) ValidateRequest(method string, raw json.RawMessage) error {
+	spec, ok := r.Lookup(method)
+	if !ok {
+		return fmt.Errorf("unknown rpc method %s", method)
+	}
+	return ValidateAgainstSchema(spec.RequestSchema, raw)
+}
+
+// ValidateResponse checks a raw response against the method's schema.
+func (r *RPCRegistry) ValidateResponse(method string, raw json.RawMessage) error {
+	spec, ok := r.Lookup(method)
+	if !ok {
+		return fmt.Errorf("unknown rpc method %s", method)
+	}
+	return ValidateAgainstSchema(spec.ResponseSchema, raw)
+}
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+	rawJSONType  = reflect.TypeOf(json.RawMessage(nil))
+)
+
+// SchemaFor derives a schema from a Go value using encoding/json rules.
+func SchemaFor(v interface{}) *RPCSchema {
+	if v == nil {
+		return &RPCSchema{Type: "null"}
+	}
+	return schemaForType(reflect.TypeOf(v), map[reflect.Type]bool{})
+}
+
+func schemaForType(t reflect.Type, visiting map[reflect.Type]bool) *RPCSchema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t {
+	case timeType:
+		return &RPCSchema{Type: "string", Format: "date-time"}
+	case durationType:
+		return &RPCSchema{Type: "integer", Format: "duration-ns"}
+	case rawJSONType:
+		return &RPCSchema{Type: "any"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &RPCSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &RPCSchema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &RPCSchema{Type: "number"}
+	case reflect.String:
+		return &RPCSchema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 && t.Kind() == reflect.Slice {
+			return &RPCSchema{Type: "string", Format: "base64"}
+		}
+		return &RPCSchema{Type: "array", Items: schemaForType(t.Elem(), visiting)}
+	case reflect.Map:
+		return &RPCSchema{Type: "object", AdditionalProperties: schemaForType(t.Elem(), visiting)}
+	case reflect.Struct:
+		if visiting[t] {
+			return &RPCSchema{Type: "object"}
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+
+		schema := &RPCSchema{Type: "object", Properties: make(map[string]*RPCSchema)}
+		addStructFields(schema, t, visiting)
+		sort.Strings(schema.Required)
+		return schema
+	default:
+		return &RPCSchema{Type: "any"}
+	}
+}
+
+func addStructFields(schema *RPCSchema, t reflect.Type, visiting map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		switch field.Type.Kind() {
+		case reflect.Chan, reflect.Func, reflect.UnsafePointer:
+			continue
+		}
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				addStructFields(schema, ft, visiting)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		schema.Properties[name] = schemaForType(field.Type, visiting)
+		if !strings.Contains(opts, "omitempty") && field.Type.Kind() != reflect.Ptr {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
+
+// ValidateAgainstSchema decodes raw JSON and checks it against a schema.
+// Unknown object properties are accepted so peers can extend payloads.
+func ValidateAgainstSchema(schema *RPCSchema, raw json.RawMessage) error {
+	var value interface{}
+	if len(raw) > 0 {
+		dec := json.NewDecoder(strings.NewReader(string(raw)))
+		dec.UseNumber()
+		if err := dec.Decode(&value); err != nil {
+			return fmt.Errorf("invalid JSON: %w", err)
+		}
+	}
+	return validateValue(schema, value, "$")
+}
+
+func validateValue(schema *RPCSchema, value interface{}, path string) error {
+	if schema == nil || schema.Type == "any" {
+		return nil
+	}
+	if value == nil {
+		// encoding/json emits null for nil slices, maps and pointers.
+		switch schema.Type {
+		case "null", "object", "array", "string":
+			return nil
+		}
+		return fmt.Errorf("%s: expected %s, got null", path, schema.Type)
+	}
+
+	switch schema.Type {
+	case "null":
+		return fmt.Errorf("%s: expected null", path)
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%s: expected boolean", path)
+		}
+	case "integer":
+		n, ok := value.(json.Number)
+		if !ok {
+			return fmt.Errorf("%s: expected integer", path)
+		}
+		if _, err := n.Int64(); err != nil {
+			if _, uerr := strconv.ParseUint(n.String(), 10, 64); uerr != nil {
+				return fmt.Errorf("%s: expected integer, got %s", path, n)
+			}
+		}
+	case "number":
+		if _, ok := value.(json.Number); !ok {
+			return fmt.Errorf("%s: expected number", path)
+		}
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("%s: expected string", path)
+		}
+		switch schema.Format {
+		case "base64":
+			if _, err := base64.StdEncoding.DecodeString(s); err != nil {
+				return fmt.Errorf("%s: expected base64 string", path)
+			}
+		case "date-time":
+			if _, err := time.Parse(time.RFC3339Nano, s); err != nil {
+				return fmt.Errorf("%s: expected RFC 3339 timestamp", path)
+			}
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s: expected array", path)
+		}
+		for i, item := range items {
+			if err := validateValue(schema.Items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case "object":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: expected object", path)
+		}
+		for _, name := range schema.Required {
+			if _, present := obj[name]; !present {
+				return fmt.Errorf("%s: missing required field %q", path, name)
+			}
+		}
+		for name, v := range obj {
+			propSchema, known := schema.Properties[name]
+			if !known {
+				propSchema = schema.AdditionalProperties
+			}
+			if propSchema == nil {
+				continue
+			}
+			if err := validateValue(propSchema, v, path+"."+name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
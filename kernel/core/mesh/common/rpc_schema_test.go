@@ -0,0 +1,117 @@
+package common
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+type schemaFixtureInner struct {
+	Count int `json:"count"`
+}
+
+type schemaFixture struct {
+	schemaFixtureInner
+	ID       string            `json:"id"`
+	Blob     []byte            `json:"blob"`
+	When     time.Time         `json:"when"`
+	Timeout  time.Duration     `json:"timeout"`
+	Tags     []string          `json:"tags,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Next     *schemaFixture    `json:"next"`
+	Skipped  string            `json:"-"`
+	internal int
+}
+
+func TestSchemaFor_Struct(t *testing.T) {
+	schema := SchemaFor(schemaFixture{})
+	if schema.Type != "object" {
+		t.Fatalf("expected object, got %s", schema.Type)
+	}
+
+	want := map[string]string{
+		"count":   "integer",
+		"id":      "string",
+		"blob":    "string",
+		"when":    "string",
+		"timeout": "integer",
+		"tags":    "array",
+		"labels":  "object",
+		"next":    "object",
+	}
+	if len(schema.Properties) != len(want) {
+		t.Fatalf("unexpected properties %v", schema.Properties)
+	}
+	for name, typ := range want {
+		if got := schema.Properties[name]; got == nil || got.Type != typ {
+			t.Fatalf("property %s: want %s, got %+v", name, typ, got)
+		}
+	}
+	if schema.Properties["blob"].Format != "base64" || schema.Properties["when"].Format != "date-time" {
+		t.Fatal("expected base64 and date-time formats")
+	}
+
+	required := map[string]bool{}
+	for _, name := range schema.Required {
+		required[name] = true
+	}
+	for _, name := range []string{"count", "id", "blob", "when", "timeout"} {
+		if !required[name] {
+			t.Fatalf("expected %s to be required, got %v", name, schema.Required)
+		}
+	}
+	for _, name := range []string{"tags", "labels", "next"} {
+		if required[name] {
+			t.Fatalf("expected %s to be optional", name)
+		}
+	}
+}
+
+func TestValidateAgainstSchema(t *testing.T) {
+	schema := SchemaFor(schemaFixture{})
+	valid, err := json.Marshal(schemaFixture{ID: "a", Blob: []byte{1, 2}, When: time.Now(), Tags: []string{"x"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ValidateAgainstSchema(schema, valid); err != nil {
+		t.Fatalf("expected marshalled value to validate: %v", err)
+	}
+
+	invalid := []string{
+		`[]`,
+		`{"id":"a","blob":"","when":"2026-01-01T00:00:00Z","timeout":0}`,
+		`{"count":1.5,"id":"a","blob":"","when":"2026-01-01T00:00:00Z","timeout":0}`,
+		`{"count":1,"id":7,"blob":"","when":"2026-01-01T00:00:00Z","timeout":0}`,
+		`{"count":1,"id":"a","blob":"!!","when":"2026-01-01T00:00:00Z","timeout":0}`,
+		`{"count":1,"id":"a","blob":"","when":"yesterday","timeout":0}`,
+		`{"count":1,"id":"a","blob":"","when":"2026-01-01T00:00:00Z","timeout":0,"tags":[1]}`,
+		`{"count":1,"id":"a"`,
+	}
+	for _, raw := range invalid {
+		if err := ValidateAgainstSchema(schema, json.RawMessage(raw)); err == nil {
+			t.Fatalf("expected %s to be rejected", raw)
+		}
+	}
+
+	extended := `{"count":1,"id":"a","blob":"","when":"2026-01-01T00:00:00Z","timeout":0,"future_field":true}`
+	if err := ValidateAgainstSchema(schema, json.RawMessage(extended)); err != nil {
+		t.Fatalf("unknown fields should be accepted: %v", err)
+	}
+}
+
+func TestRPCRegistry_RejectsDuplicates(t *testing.T) {
+	reg := NewRPCRegistry()
+	spec := RPCMethodSpec{Name: "test.echo", Request: "", Response: ""}
+	if err := reg.Register(spec); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if err := reg.Register(spec); err == nil {
+		t.Fatal("expected duplicate registration to fail")
+	}
+	if err := reg.ValidateRequest("test.missing", nil); err == nil {
+		t.Fatal("expected unknown method to fail validation")
+	}
+	if desc := reg.Describe(); desc.Protocol != RPCProtocolName || len(desc.Methods) != 1 {
+		t.Fatalf("unexpected description %+v", desc)
+	}
+}
@@ -28,6 +28,26 @@ type PeerCapability struct {
 	Coordinates     *GeoCoordinates            `json:"coordinates,omitempty"`
 	Role            system.Runtime_RuntimeRole `json:"role"`
 	RuntimeCaps     *RuntimeCapabilities       `json:"runtime_caps,omitempty"`
+	// GPU describes the WebGPU adapter. The Cap'n Proto form carries it in
+	// Hardware from schema 2 on.
+	GPU *GPUCapability `json:"gpu,omitempty"`
+	// SchemaVersion is the layout the writer used; see CapabilitySchemaVersion.
+	SchemaVersion uint16              `json:"schema_version,omitempty"`
+	Hardware      *HardwareDescriptor `json:"hardware,omitempty"`
+}
+
+// GPUCapability mirrors the WebGPU adapter info and the limits compute
+// jobs depend on.
+type GPUCapability struct {
+	Vendor                            string   `json:"vendor,omitempty"`
+	Architecture                      string   `json:"architecture,omitempty"`
+	Device                            string   `json:"device,omitempty"` // Adapter model, when the host reveals it
+	MaxBufferSize                     uint64   `json:"max_buffer_size"`
+	MaxStorageBufferBindingSize       uint64   `json:"max_storage_buffer_binding_size"`
+	MaxComputeWorkgroupStorageSize    uint32   `json:"max_compute_workgroup_storage_size"`
+	MaxComputeInvocationsPerWorkgroup uint32   `json:"max_compute_invocations_per_workgroup"`
+	MaxComputeWorkgroupsPerDimension  uint32   `json:"max_compute_workgroups_per_dimension"`
+	Features                          []string `json:"features,omitempty"` // e.g. "shader-f16", "timestamp-query"
 }
 
 type RuntimeCapabilities struct {
@@ -102,6 +122,17 @@ func (p *PeerCapability) ToCapnp(seg *capnp.Segment) (p2p.PeerCapability, error)
 		rc.SetBatteryLevel(p.RuntimeCaps.BatteryLevel)
 	}
 
+	cap.SetSchemaVersion(p.SchemaVersion)
+	if p.Hardware != nil || p.GPU != nil {
+		hw, err := cap.NewHardware()
+		if err != nil {
+			return p2p.PeerCapability{}, err
+		}
+		if err := p.Hardware.toCapnp(hw, p.GPU); err != nil {
+			return p2p.PeerCapability{}, err
+		}
+	}
+
 	return cap, nil
 }
 
@@ -153,6 +184,17 @@ func (p *PeerCapability) FromCapnp(cap p2p.PeerCapability) error {
 		}
 	}
 
+	// Writers before schema 2 leave these zero; readers of a newer schema
+	// keep what they understand
+	p.SchemaVersion = cap.SchemaVersion()
+	if cap.HasHardware() {
+		hw, err := cap.Hardware()
+		if err != nil {
+			return err
+		}
+		p.Hardware, p.GPU = hardwareFromCapnp(hw)
+	}
+
 	return nil
 }
 
@@ -282,6 +324,7 @@ type StorageProvider interface {
 	StoreChunk(ctx context.Context, hash string, data []byte) error
 	FetchChunk(ctx context.Context, hash string) ([]byte, error)
 	HasChunk(ctx context.Context, hash string) (bool, error)
+	DeleteChunk(ctx context.Context, hash string) error
 }
 
 // Transport defines the interface for peer-to-peer communication
@@ -310,6 +353,27 @@ type Transport interface {
 	GetStats() map[string]interface{}
 }
 
+// RPCCall is one call in a batch sent to a single peer.
+type RPCCall struct {
+	Method     string
+	Args       interface{}
+	Reply      interface{} // Decoded into when the call succeeds; may be nil
+	Capability string      // Encoded capability sent with this call only; may be empty
+	Err        error       // Set once the batch returns
+}
+
+// BatchTransport is implemented by transports that can carry several RPCs
+// to one peer in a single frame.
+type BatchTransport interface {
+	SendRPCBatch(ctx context.Context, peerID string, calls []RPCCall) error
+}
+
+// LatencyReporter is implemented by transports that measure round trips to
+// each peer. Measured latency replaces the figure a peer advertises.
+type LatencyReporter interface {
+	PeerRPCLatency(peerID string) (time.Duration, bool)
+}
+
 // ConnectionMetrics tracks transport-level statistics
 type ConnectionMetrics struct {
 	ActiveConnections  uint32  `json:"active_connections"`
@@ -320,6 +384,7 @@ type ConnectionMetrics struct {
 	MessagesReceived   uint64  `json:"messages_received"`
 	LatencyP50         float32 `json:"latency_p50_ms"`
 	LatencyP95         float32 `json:"latency_p95_ms"`
+	LatencyP99         float32 `json:"latency_p99_ms"`
 	ErrorRate          float32 `json:"error_rate"`
 	SuccessRate        float32 `json:"success_rate"`
 	FailedMessages     uint64  `json:"failed_messages"`
@@ -542,6 +607,7 @@ type PeerInfo struct {
 	LastContact  time.Time       `json:"last_contact"`
 	BucketIndex  int             `json:"bucket_index"`
 	Capabilities *PeerCapability `json:"capabilities,omitempty"`
+	PublicKey    []byte          `json:"public_key,omitempty"` // Identity key the ID is checked against
 }
 
 // ToCapnp converts PeerInfo to p2p.PeerInfo (internal schema if available, otherwise just use as is).
@@ -600,6 +666,7 @@ type MeshMetrics struct {
 	BytesSent        uint64  `json:"bytes_sent"`
 	BytesReceived    uint64  `json:"bytes_received"`
 	RegionID         uint32  `json:"region_id"`
+	SectorID         uint32  `json:"sector_id"` // Sector the node measured itself into, 0..255
 	// Latency
 	P50LatencyMs float32 `json:"p50_latency_ms"`
 	P95LatencyMs float32 `json:"p95_latency_ms"`
@@ -617,6 +684,11 @@ type MeshMetrics struct {
 	TotalComputeGFLOPS float32 `json:"total_compute_gflops"`
 	GlobalOpsPerSec    float32 `json:"global_ops_per_sec"`
 	ActiveNodeCount    uint32  `json:"active_node_count"`
+
+	// Demo mode: Synthetic marks metrics produced by an in-process synthetic
+	// peer; SyntheticNodeCount is how many of ActiveNodeCount are synthetic.
+	Synthetic          bool   `json:"synthetic,omitempty"`
+	SyntheticNodeCount uint32 `json:"synthetic_node_count,omitempty"`
 }
 
 // GossipMessage represents a message propagated through the gossip protocol
@@ -631,6 +703,12 @@ type GossipMessage struct {
 	Payload   interface{} `json:"payload"`
 	PublicKey []byte      `json:"public_key,omitempty"`
 	Signature []byte      `json:"signature,omitempty"`
+	// TraceParent links handlers on receiving nodes to the publisher's span.
+	// It is not signed: a relay can only misattribute a span, not content.
+	TraceParent string `json:"trace_parent,omitempty"`
+	// Sector, when set, keeps the message in that sector's gossip overlay.
+	// It is signed, so a relay cannot widen a sector-local message.
+	Sector *uint32 `json:"sector,omitempty"`
 }
 
 // ToCapnp converts GossipMessage to its Cap'n Proto Envelope representation.
@@ -0,0 +1,211 @@
+package mesh
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ConnectionEviction records one peer dropped to stay under MaxConnections.
+type ConnectionEviction struct {
+	PeerID     string    `json:"peer_id"`
+	Value      float64   `json:"value"` // Reputation x (1 + utility) at eviction
+	Reputation float64   `json:"reputation"`
+	Utility    float64   `json:"utility"`   // Decayed count of recent useful interactions
+	Connected  int       `json:"connected"` // Connections open when the limit was enforced
+	At         time.Time `json:"at"`
+}
+
+// ConnectionStats summarizes connection management.
+type ConnectionStats struct {
+	MaxConnections int                 `json:"max_connections"`
+	Connected      int                 `json:"connected"`
+	Protected      int                 `json:"protected"`
+	Evictions      uint64              `json:"evictions"`
+	LastEviction   *ConnectionEviction `json:"last_eviction,omitempty"`
+}
+
+type connectionState struct {
+	mu          sync.Mutex
+	connectedAt map[string]time.Time
+	utility     map[string]peerUtility
+	evictions   uint64
+	last        *ConnectionEviction
+
+	enforceMu sync.Mutex // One enforcement pass at a time
+}
+
+// peerUtility is a count of successful interactions that halves every
+// UtilityHalfLife.
+type peerUtility struct {
+	score   float64
+	updated time.Time
+}
+
+func (u peerUtility) at(now time.Time, halfLife time.Duration) float64 {
+	if halfLife <= 0 || u.updated.IsZero() {
+		return u.score
+	}
+	return u.score * math.Pow(0.5, float64(now.Sub(u.updated))/float64(halfLife))
+}
+
+func (m *MeshCoordinator) notePeerConnected(peerID string) {
+	m.connections.mu.Lock()
+	if m.connections.connectedAt == nil {
+		m.connections.connectedAt = make(map[string]time.Time)
+	}
+	m.connections.connectedAt[peerID] = time.Now()
+	m.connections.mu.Unlock()
+}
+
+func (m *MeshCoordinator) notePeerDisconnected(peerID string) {
+	m.connections.mu.Lock()
+	delete(m.connections.connectedAt, peerID)
+	m.connections.mu.Unlock()
+}
+
+// recordPeerUtility credits a peer with a successful interaction.
+func (m *MeshCoordinator) recordPeerUtility(peerID string) {
+	now := time.Now()
+	halfLife := m.config.Connections.UtilityHalfLife
+
+	m.connections.mu.Lock()
+	defer m.connections.mu.Unlock()
+	if m.connections.utility == nil {
+		m.connections.utility = make(map[string]peerUtility)
+	}
+	u := m.connections.utility[peerID]
+	m.connections.utility[peerID] = peerUtility{score: u.at(now, halfLife) + 1, updated: now}
+}
+
+// connectionValue scores a connected peer for eviction; lower goes first.
+func (m *MeshCoordinator) connectionValue(peerID string, now time.Time) (value, reputation, utility float64) {
+	reputation, _ = m.reputation.GetTrustScore(peerID)
+	m.connections.mu.Lock()
+	utility = m.connections.utility[peerID].at(now, m.config.Connections.UtilityHalfLife)
+	m.connections.mu.Unlock()
+	return reputation * (1 + utility), reputation, utility
+}
+
+// protectedPeers returns peers that must not be evicted: those running or
+// handing us delegated work, providers of pinned chunks, and peers still in
+// their grace period after connecting.
+func (m *MeshCoordinator) protectedPeers(now time.Time) map[string]bool {
+	protected := make(map[string]bool)
+
+	m.activeJobsMu.RLock()
+	for peerID, active := range m.activeJobs {
+		if active > 0 {
+			protected[peerID] = true
+		}
+	}
+	m.activeJobsMu.RUnlock()
+
+	m.pulledWorkMu.Lock()
+	for _, requester := range m.pulledWork {
+		protected[requester] = true
+	}
+	m.pulledWorkMu.Unlock()
+
+	for _, target := range m.pinTargets() {
+		for _, peerID := range m.otherProviders(m.dht.LocalProviders(target.hash)) {
+			protected[peerID] = true
+		}
+	}
+
+	if grace := m.config.Connections.NewPeerGrace; grace > 0 {
+		m.connections.mu.Lock()
+		for peerID, at := range m.connections.connectedAt {
+			if now.Sub(at) < grace {
+				protected[peerID] = true
+			}
+		}
+		m.connections.mu.Unlock()
+	}
+	return protected
+}
+
+// enforceConnectionLimit disconnects the lowest-value unprotected peers
+// until the node is back under MaxConnections. If every peer over the limit
+// is protected the node stays over it until one frees up.
+func (m *MeshCoordinator) enforceConnectionLimit() {
+	limit := m.config.Connections.MaxConnections
+	if limit <= 0 {
+		return
+	}
+	m.connections.enforceMu.Lock()
+	defer m.connections.enforceMu.Unlock()
+
+	peers := m.transport.GetConnectedPeers()
+	excess := len(peers) - limit
+	if excess <= 0 {
+		return
+	}
+
+	now := time.Now()
+	protected := m.protectedPeers(now)
+	candidates := make([]ConnectionEviction, 0, len(peers))
+	for _, peerID := range peers {
+		if protected[peerID] {
+			continue
+		}
+		value, reputation, utility := m.connectionValue(peerID, now)
+		candidates = append(candidates, ConnectionEviction{
+			PeerID:     peerID,
+			Value:      value,
+			Reputation: reputation,
+			Utility:    utility,
+			Connected:  len(peers),
+			At:         now,
+		})
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Value != candidates[j].Value {
+			return candidates[i].Value < candidates[j].Value
+		}
+		return candidates[i].PeerID < candidates[j].PeerID
+	})
+	if len(candidates) < excess {
+		m.logger.Debug("connection limit exceeded by protected peers",
+			"connected", len(peers), "limit", limit, "protected", len(protected))
+		excess = len(candidates)
+	}
+
+	for _, eviction := range candidates[:excess] {
+		if err := m.transport.Disconnect(eviction.PeerID); err != nil {
+			m.logger.Debug("failed to evict peer", "peer", getShortID(eviction.PeerID), "error", err)
+			continue
+		}
+		eviction := eviction
+		m.connections.mu.Lock()
+		m.connections.evictions++
+		m.connections.last = &eviction
+		m.connections.mu.Unlock()
+
+		m.logger.Info("evicted peer at connection limit",
+			"peer", getShortID(eviction.PeerID),
+			"value", eviction.Value,
+			"connected", eviction.Connected,
+			"limit", limit)
+		m.publishEvent(MeshEventPeerEvicted, eviction.PeerID, eviction)
+	}
+}
+
+// GetConnectionStats reports the connection limit and evictions so far.
+func (m *MeshCoordinator) GetConnectionStats() ConnectionStats {
+	now := time.Now()
+	stats := ConnectionStats{
+		MaxConnections: m.config.Connections.MaxConnections,
+		Connected:      len(m.transport.GetConnectedPeers()),
+		Protected:      len(m.protectedPeers(now)),
+	}
+	m.connections.mu.Lock()
+	stats.Evictions = m.connections.evictions
+	if m.connections.last != nil {
+		last := *m.connections.last
+		stats.LastEviction = &last
+	}
+	m.connections.mu.Unlock()
+	return stats
+}
@@ -0,0 +1,69 @@
+package mesh
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestConnectionLimit_EvictsLowestValueUnprotectedPeer(t *testing.T) {
+	cluster := NewSimCluster(SimClusterConfig{
+		Nodes: 6,
+		Configure: func(node *SimClusterNode) {
+			if node.ID != "node-01" {
+				return
+			}
+			cfg := &node.Coordinator.config.Connections
+			cfg.MaxConnections = 3
+			cfg.NewPeerGrace = 0
+		},
+	})
+	origin, _ := cluster.Node("node-01")
+	m := origin.Coordinator
+
+	// node-02 runs a job for us and node-03 holds a replica of a pinned
+	// chunk; node-04 has been useful lately. node-05 and node-06 have
+	// nothing going for them, so they are the ones to go.
+	m.incrementActiveJobs("node-02")
+	if err := m.PinChunk("pinned-chunk", 2); err != nil {
+		t.Fatalf("PinChunk failed: %v", err)
+	}
+	_ = m.dht.Store("pinned-chunk", "node-03", 3600)
+	for i := 0; i < 3; i++ {
+		m.recordPeerUtility("node-04")
+	}
+
+	ctx := context.Background()
+	if err := cluster.Start(ctx); err != nil {
+		t.Fatalf("cluster start failed: %v", err)
+	}
+	t.Cleanup(cluster.Close)
+
+	waitForSimCluster(t, cluster, "evictions", func() bool {
+		return len(origin.Transport.GetConnectedPeers()) == 3
+	})
+	for _, kept := range []string{"node-02", "node-03", "node-04"} {
+		if !origin.Transport.IsConnected(kept) {
+			t.Fatalf("expected %s to be kept", kept)
+		}
+	}
+
+	stats := m.GetConnectionStats()
+	if stats.Evictions != 2 || stats.LastEviction == nil {
+		t.Fatalf("expected two evictions, got %+v", stats)
+	}
+	if evicted := stats.LastEviction.PeerID; evicted != "node-05" && evicted != "node-06" {
+		t.Fatalf("evicted a valuable peer %s", evicted)
+	}
+	if stats.Protected < 2 {
+		t.Fatalf("expected the job and pinned-chunk peers to be protected, got %d", stats.Protected)
+	}
+}
+
+func TestPeerUtility_DecaysByHalfLife(t *testing.T) {
+	now := time.Now()
+	u := peerUtility{score: 4, updated: now.Add(-2 * time.Minute)}
+	if got := u.at(now, time.Minute); got < 0.99 || got > 1.01 {
+		t.Fatalf("expected two half-lives to leave 1, got %v", got)
+	}
+}
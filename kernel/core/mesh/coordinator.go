@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"context"
 	"crypto/sha256"
-	"encoding/binary"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
@@ -12,10 +11,12 @@ import (
 	"hash/crc32"
 	"io"
 	"log/slog"
+	"math"
+	"slices"
 	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
-	"unsafe"
 
 	"github.com/andybalholm/brotli"
 	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
@@ -28,6 +29,7 @@ import (
 	"github.com/nmxmxh/inos_v1/kernel/runtime"
 	"github.com/nmxmxh/inos_v1/kernel/threads/foundation"
 	"github.com/nmxmxh/inos_v1/kernel/threads/sab"
+	"github.com/nmxmxh/inos_v1/kernel/utils/tracing"
 	capnp "zombiezen.com/go/capnproto2"
 )
 
@@ -76,6 +78,14 @@ type MeshCoordinator struct {
 	circuitBreakers map[string]*CircuitBreaker
 	cbMu            sync.RWMutex
 
+	// Scored connection management under MaxConnections
+	connections connectionState
+
+	// Fault injection for chaos testing; inert until an injector is set
+	faults  common.FaultHook
+	chaos   *ChaosInjector
+	chaosMu sync.Mutex
+
 	// Caches
 	peerCache     map[string]PeerCacheEntry
 	peerCacheMu   sync.RWMutex
@@ -90,17 +100,31 @@ type MeshCoordinator struct {
 	// Monitoring
 	metrics       common.MeshMetrics
 	metricsMu     sync.RWMutex
+	healthBlock   healthBlockState
 	peerMetrics   map[string]common.MeshMetrics
 	peerMetricsMu sync.RWMutex
 	healthTicker  *time.Ticker
 	shutdown      chan struct{}
 	identityMu    sync.RWMutex
+	nodeIdentity  *NodeIdentity
+
+	// Newest signed metrics report accepted per node, guarded by peerMetricsMu
+	peerMetricsVersions map[string]peerMetricsVersion
+	metricsSequence     atomic.Uint64 // Last sequence this node signed
+
+	// Reporters whose gossiped metrics were clamped or rejected as outliers
+	metricsOutliers   map[string]*MetricsOutlier
+	metricsOutliersMu sync.Mutex
 
 	// Event streaming
 	eventQueue      *MeshEventQueue
 	subscriptions   map[string]*meshSubscription
 	subscriptionsMu sync.RWMutex
 
+	// Filtered, per-cursor event ring for host subscriptions; opened on first subscribe
+	eventRing   *meshEventRing
+	eventRingMu sync.Mutex
+
 	// Configuration
 	config CoordinatorConfig
 	logger *slog.Logger
@@ -108,6 +132,41 @@ type MeshCoordinator struct {
 	// External Dispatcher for remote delegation
 	dispatcher foundation.Dispatcher
 
+	// Per-frame budget shared with the SAB bridge; nil when not running in a frame loop
+	frameBudget   atomic.Pointer[foundation.FrameBudget]
+	deferredTicks atomic.Uint64
+
+	// Load reached Load.BusyThreshold and has not fallen to IdleThreshold
+	busy atomic.Bool
+
+	// Deferrable work waiting for a host idle window; see RunWhenIdle
+	idle idleScheduleState
+
+	// ACLs of chunks stored here, grants held and refused fetches
+	chunkACL chunkACLState
+
+	// Replicas held for peers, charged against inbound quotas
+	inbound inboundStorageState
+
+	// Host page hidden: background loops skip their ticks
+	background      atomic.Bool
+	backgroundTicks atomic.Uint64
+
+	// Host reported no network; see HandleNetworkChange
+	offline atomic.Bool
+
+	// Sector this node is in; see reassignSector
+	sector atomic.Uint32
+
+	// Counters for jobs executed on behalf of peers
+	execution executionCounters
+
+	// Responses to executed requests, replayed to retries with the same key
+	idempotency idempotencyCache
+
+	// Probed round trips and the probe schedule
+	latencyProbes latencyProbeState
+
 	// Decision engine for offloading
 	decider *DelegationEngine
 
@@ -118,11 +177,172 @@ type MeshCoordinator struct {
 	activeJobs   map[string]int32
 	activeJobsMu sync.RWMutex
 
+	// Work-stealing queue: jobs we announced and jobs we pulled
+	pendingWork   map[string]*workItem
+	pendingWorkMu sync.Mutex
+	localWorkers  atomic.Int32
+	pulledWork    map[string]string // job ID -> requester, for jobs running here
+	pulledWorkMu  sync.Mutex
+
+	// Set once Depart starts; no new work is accepted afterwards
+	departing atomic.Bool
+
+	// Stores that outlive the session
+	ledgerStore  LedgerStore
+	stateStoreMu sync.Mutex
+
+	// Outbound operations deferred while no peers are reachable
+	offlineQueue     *OfflineQueue
+	offlineReplaying atomic.Bool
+
 	// Attestation state
 	attestedPeers    map[string]AttestationRecord
 	attestationMu    sync.RWMutex
 	attestingPeers   map[string]struct{}
 	attestingPeersMu sync.Mutex
+
+	// Identity keys retired through rotation, keyed by base64 public key
+	keyRevocations map[string]KeyRevocation
+	keyRotationMu  sync.RWMutex
+
+	// Signed evidence of delegated jobs, oldest first
+	delegationAudit   []DelegationAuditRecord
+	delegationAuditMu sync.Mutex // Also guards delegationReceipts
+
+	// Receipts issued as executor and received as requester
+	delegationReceipts []DelegationReceipt
+
+	// Disputes this node filed, oldest first
+	disputes   []Dispute
+	disputesMu sync.Mutex
+
+	// Static peers and DNS rendezvous used to (re)join the mesh
+	bootstrap   bootstrapState
+	bootstrapMu sync.Mutex
+
+	// Measured peer throughput from active probing
+	bandwidthProbes   map[string]*BandwidthMeasurement
+	bandwidthProbesMu sync.RWMutex
+
+	// Provider records we announced, republished early while their chunk is hot
+	chunkRecords   map[string]*chunkRecord
+	chunkRecordsMu sync.Mutex
+	chunkTTL       chunkTTLCounters
+
+	// WASM feature reports from peers and the local runtime that answers probes
+	featureProbes   map[string]*PeerFeatureReport
+	featureProber   FeatureProber
+	featureProbesMu sync.RWMutex
+
+	// Local chunk bytes per namespace and quota-driven eviction
+	storageQuota      *StorageQuota
+	storageQuotaStats storageQuotaCounters
+	quotaEnforcing    atomic.Bool
+
+	// Chunks and namespaces pinned against eviction and kept at a replica target
+	pinnedChunks     map[string]*PinnedChunk
+	pinnedNamespaces map[string]*PinnedNamespace
+	pinRepairs       map[string]*pinRepairState
+	pinStore         PinStore
+	pinsMu           sync.Mutex
+	pinRepairKick    chan struct{}
+	pins             pinCounters
+
+	// Chunks this node distributed, watched for lost replicas
+	replicaRepair replicaRepairState
+
+	// Battery and thermal state, and the throttling it causes
+	power powerState
+
+	// What the role profile offers peers; nil is balanced
+	contribution atomic.Pointer[runtime.Contribution]
+
+	// Object manifests announced by peers (and by this node), by manifest hash
+	knownObjects   map[string]*ObjectAnnouncement
+	knownObjectsMu sync.Mutex
+
+	// Namespace keys this node can encrypt or decrypt chunks with, by key ID
+	chunkKeys   map[string]*NamespaceKey
+	chunkKeysMu sync.RWMutex
+
+	// Named services this node advertises in the DHT
+	services   map[string]*localService
+	servicesMu sync.Mutex
+
+	// Signed mutable records this node publishes and keeps republished
+	publishedRecords   map[string]*publishedRecord
+	publishedRecordsMu sync.Mutex
+
+	// Application pub/sub: per-topic limits and retained history, and the
+	// local subscriptions waiting to be read
+	topics      map[string]*topicState
+	topicsMu    sync.Mutex
+	topicSubs   map[string]*topicSubscriber
+	topicSubsMu sync.Mutex
+
+	// Manifests warmed for applications and the chunks held on their behalf
+	warmJobs  map[string]*warmJob
+	warmHolds map[string]time.Time // Chunk -> hold expiry
+	warmMu    sync.Mutex
+
+	// This node's admission proof-of-work, solved once per identity key
+	admissionPoW admissionPoWState
+
+	// Mesh clock: reference nodes heard from and the offset derived from them
+	timeSync timeSyncState
+
+	// WASM modules by content hash: announcements, compiled cache and run policy
+	modules moduleRegistry
+
+	// WebGPU adapter and hardware this node advertises, and the GPU time
+	// peers reported
+	localGPU      *GPUCapability
+	localHardware *HardwareDescriptor
+	gpuTimings    map[string]*GPUTimingStats
+	gpuMu         sync.Mutex
+
+	// Proof-of-replication challenge outcomes
+	storageProofs storageProofCounters
+
+	// Operator blocklist/allowlist and auto-ban of repeat offenders
+	admission *AdmissionController
+
+	// Escalating quarantine for peers failing across breaker cycles
+	quarantined  map[string]*quarantineEntry
+	quarantineMu sync.Mutex
+
+	// Work served per caller namespace (from RPC metadata)
+	namespaceUsage   map[string]*NamespaceUsage
+	namespaceUsageMu sync.Mutex
+
+	// RPC capabilities held for remote peers, peers that refused to issue
+	// one, and revocations of ones we issued
+	heldCapabilities      map[string]*RPCCapability
+	capabilityRefusals    map[string]time.Time
+	capabilityRevocations map[string]time.Time
+	capabilityMu          sync.RWMutex
+
+	// In-process synthetic peers for demo/offline mode (nil otherwise)
+	sim *SimTransport
+
+	// Cache and queue bounds from the runtime memory profile
+	memoryProfile   runtime.MemoryProfile
+	memoryProfileMu sync.RWMutex
+
+	// Topology export rate limiting and per-session pseudonym salt
+	lastTopologyExport time.Time
+	topologySalt       []byte
+	topologyMu         sync.Mutex
+
+	// Replicas stored for an uncommitted publish, keyed by transaction ID
+	stagedReplicas map[string]*stagedTransaction
+	stagedMu       sync.Mutex
+
+	// DHT mode selection from the runtime role and memory profile
+	dhtRole        system.Runtime_RuntimeRole
+	dhtPromotable  bool
+	dhtClientSince time.Time
+	dhtMu          sync.Mutex
 }
 
 // CoordinatorConfig holds mesh coordinator settings
@@ -148,6 +368,298 @@ type CoordinatorConfig struct {
 	MetricsUpdatePeriod time.Duration `json:"metrics_update_period"`
 	AttestationEnabled  bool          `json:"attestation_enabled"`
 	AttestationTimeout  time.Duration `json:"attestation_timeout"`
+
+	BandwidthProbe struct {
+		Interval        time.Duration `json:"interval"`
+		PayloadBytes    int           `json:"payload_bytes"`
+		MaxPeers        int           `json:"max_peers"`
+		DivergenceRatio float64       `json:"divergence_ratio"`
+	} `json:"bandwidth_probe"`
+
+	WorkQueue struct {
+		Enabled          bool          `json:"enabled"`
+		MaxJobsPerPeer   int           `json:"max_jobs_per_peer"`
+		MaxLocalWorkers  int           `json:"max_local_workers"`
+		ClaimTimeout     time.Duration `json:"claim_timeout"`
+		AnnounceInterval time.Duration `json:"announce_interval"`
+		ResultTimeout    time.Duration `json:"result_timeout"`
+		FairShareCap     float64       `json:"fair_share_cap"`
+		FairShareGrace   time.Duration `json:"fair_share_grace"`
+	} `json:"work_queue"`
+
+	OfflineQueue struct {
+		MaxSize int `json:"max_size"`
+	} `json:"offline_queue"`
+
+	Bootstrap struct {
+		CheckInterval  time.Duration `json:"check_interval"`
+		InitialBackoff time.Duration `json:"initial_backoff"`
+		MaxBackoff     time.Duration `json:"max_backoff"`
+		DNSTimeout     time.Duration `json:"dns_timeout"`
+	} `json:"bootstrap"`
+
+	StorageProof struct {
+		Interval      time.Duration `json:"interval"`
+		MaxPeers      int           `json:"max_peers"`
+		MaxRangeBytes int           `json:"max_range_bytes"`
+		Timeout       time.Duration `json:"timeout"`
+	} `json:"storage_proof"`
+
+	LocalDiscovery struct {
+		Enabled bool `json:"enabled"`
+	} `json:"local_discovery"`
+
+	Quarantine struct {
+		Enabled                bool          `json:"enabled"`
+		CyclesBeforeQuarantine int           `json:"cycles_before_quarantine"`
+		BaseDuration           time.Duration `json:"base_duration"`
+		MaxDuration            time.Duration `json:"max_duration"`
+		Multiplier             float64       `json:"multiplier"`
+		DecayAfter             time.Duration `json:"decay_after"`
+		CheckInterval          time.Duration `json:"check_interval"`
+	} `json:"quarantine"`
+
+	Authorization struct {
+		Enabled       bool                       `json:"enabled"`
+		DefaultMode   RPCPolicyMode              `json:"default_mode"`
+		Policies      map[string]RPCMethodPolicy `json:"policies"`
+		CapabilityTTL time.Duration              `json:"capability_ttl"`
+	} `json:"authorization"`
+
+	Demo struct {
+		Enabled         bool          `json:"enabled"`
+		Peers           int           `json:"peers"`
+		MetricsInterval time.Duration `json:"metrics_interval"`
+	} `json:"demo"`
+
+	TopologyExport struct {
+		MinInterval     time.Duration `json:"min_interval"`
+		AllowIdentities bool          `json:"allow_identities"` // Permit exports with raw peer IDs
+	} `json:"topology_export"`
+
+	Publish struct {
+		MinReplicas int           `json:"min_replicas"` // Peer acks required per chunk
+		StagedTTL   time.Duration `json:"staged_ttl"`   // How long a staged replica can be aborted
+	} `json:"publish"`
+
+	DHT struct {
+		Mode            string        `json:"mode"`              // "auto", "server" or "client"
+		PromoteAfter    time.Duration `json:"promote_after"`     // Client time before an auto promotion
+		PromoteMinPeers int           `json:"promote_min_peers"` // Connected peers required to promote
+
+		DisjointPaths      int  `json:"disjoint_paths"`       // Independent paths per lookup
+		MaxPerSubnet       int  `json:"max_per_subnet"`       // Routing bucket peers from one network; 0 is unlimited
+		RequireVerifiedIDs bool `json:"require_verified_ids"` // Refuse routing peers without an identity key
+		IDPuzzleBits       int  `json:"id_puzzle_bits"`       // Leading zero bits of sha256(sha256(key)) a node ID needs
+	} `json:"dht"`
+
+	ChunkTTL struct {
+		Min               time.Duration `json:"min"`                // Record TTL for chunks with no demand
+		Max               time.Duration `json:"max"`                // Record TTL at full demand
+		HotThreshold      float64       `json:"hot_threshold"`      // Demand score that earns early republishing
+		RepublishFraction float64       `json:"republish_fraction"` // Share of a hot record's TTL before it is republished
+		CheckInterval     time.Duration `json:"check_interval"`
+		RepublishInterval time.Duration `json:"republish_interval"` // Cadence of the pass renewing every held chunk's record; 0 disables it
+		RepublishJitter   float64       `json:"republish_jitter"`   // Fraction of the interval each pass shifts by at random, up to 0.5
+		RepublishBatch    int           `json:"republish_batch"`    // Records renewed before pausing; 0 renews all at once
+		RepublishPause    time.Duration `json:"republish_pause"`    // Pause between batches
+	} `json:"chunk_ttl"`
+
+	StorageQuota struct {
+		MaxBytes       uint64            `json:"max_bytes"`       // Local chunk storage limit; 0 disables eviction
+		NamespaceBytes map[string]uint64 `json:"namespace_bytes"` // Per-namespace limits within MaxBytes
+		LowWatermark   float64           `json:"low_watermark"`   // Fraction of a limit eviction frees down to
+		ReplicaTarget  int               `json:"replica_target"`  // Replicas at which a chunk counts as fully covered
+		DemandWeight   float64           `json:"demand_weight"`   // How strongly demand protects a chunk from eviction
+		CheckInterval  time.Duration     `json:"check_interval"`
+	} `json:"storage_quota"`
+
+	Objects struct {
+		ChunkSize int `json:"chunk_size"` // Bytes per object chunk
+		MaxChunks int `json:"max_chunks"` // Largest object PutObject accepts, in chunks
+		MaxKnown  int `json:"max_known"`  // Announced manifests remembered
+	} `json:"objects"`
+
+	ChunkACL struct {
+		RequestMaxAge  time.Duration `json:"request_max_age"` // How far a signed chunk.fetch may be from mesh time
+		MaxGrants      int           `json:"max_grants"`      // Grants sent with one fetch
+		DenialLog      int           `json:"denial_log"`      // Refused fetches remembered
+		NotifyInterval time.Duration `json:"notify_interval"` // Least time between reports of one requester's denials to an owner
+	} `json:"chunk_acl"`
+
+	InboundStorage struct {
+		Enabled          bool          `json:"enabled"`
+		PeerBytes        uint64        `json:"peer_bytes"`      // Replica bytes held for one announcer
+		GlobalBytes      uint64        `json:"global_bytes"`    // Replica bytes held for all peers
+		PeerRateBytes    uint64        `json:"peer_rate_bytes"` // Bytes one peer may push per RateWindow
+		RateWindow       time.Duration `json:"rate_window"`
+		UnjustifiedBytes uint64        `json:"unjustified_bytes"`  // Bytes one peer may push with no announcement or payment
+		MaxReplicaTarget int           `json:"max_replica_target"` // Highest replica target an announcement may claim
+		PaidMinBalance   int64         `json:"paid_min_balance"`   // Credits that justify a replica without announcement; 0 disables
+		AbuseThreshold   int           `json:"abuse_threshold"`    // Rejections within AbuseWindow before a peer is penalized
+		AbuseWindow      time.Duration `json:"abuse_window"`
+	} `json:"inbound_storage"`
+
+	Warm struct {
+		BandwidthBytesPerSec int64         `json:"bandwidth_bytes_per_sec"` // Budget a high-priority warm may use; 0 is unpaced
+		HoldTTL              time.Duration `json:"hold_ttl"`                // How long warmed chunks are kept from eviction
+		Timeout              time.Duration `json:"timeout"`                 // Bound on one warm job
+	} `json:"warm"`
+
+	SybilAdmission struct {
+		Mode          string `json:"mode"`           // "off", "pow", "stake" or "either"
+		PoWDifficulty int    `json:"pow_difficulty"` // Leading zero bits required of a peer's proof
+		MinStake      int64  `json:"min_stake"`      // Ledger balance a peer's verified DID must hold
+	} `json:"sybil_admission"`
+
+	Modules struct {
+		Policy    string `json:"policy"`     // "open" or "allowlist"
+		MaxSize   int    `json:"max_size"`   // Largest module accepted, in bytes
+		MaxCached int    `json:"max_cached"` // Compiled modules kept before the least recently used is dropped
+		MaxKnown  int    `json:"max_known"`  // Announced modules remembered
+	} `json:"modules"`
+
+	Sandbox struct {
+		Fuel        uint64        `json:"fuel"`         // Instruction budget per delegated job; 0 is unmetered
+		MemoryBytes uint64        `json:"memory_bytes"` // Linear memory cap per delegated job
+		WallClock   time.Duration `json:"wall_clock"`   // Deadline per delegated job
+	} `json:"sandbox"`
+
+	ShardAssembly struct {
+		Parallelism      int `json:"parallelism"`        // Shards delegated at once
+		MaxPendingShards int `json:"max_pending_shards"` // Finished shards held while an earlier one is outstanding
+	} `json:"shard_assembly"`
+
+	Services struct {
+		DefaultTTL     time.Duration `json:"default_ttl"`     // Record TTL when a registration does not set one
+		CheckInterval  time.Duration `json:"check_interval"`  // Health check and refresh cadence
+		HealthTimeout  time.Duration `json:"health_timeout"`  // Bound on one registration's health check
+		ResolveTimeout time.Duration `json:"resolve_timeout"` // Bound on confirming providers during a resolve
+		MaxResults     int           `json:"max_results"`
+	} `json:"services"`
+
+	Records struct {
+		DefaultTTL     time.Duration `json:"default_ttl"`     // Mutable record TTL when a publish does not set one
+		ResolveTimeout time.Duration `json:"resolve_timeout"` // Bound on querying holders for the newest copy
+	} `json:"records"`
+
+	PubSub struct {
+		MaxPayload     int           `json:"max_payload"`     // Per-message limit for topics that do not set one
+		RatePerSecond  int           `json:"rate_per_second"` // Messages per publisher per topic, unless the topic sets its own
+		Burst          int           `json:"burst"`
+		InboxSize      int           `json:"inbox_size"`      // Unread messages kept per subscription before the oldest drop
+		HistoryPeers   int           `json:"history_peers"`   // Peers asked for retained messages when subscribing
+		HistoryTimeout time.Duration `json:"history_timeout"` // Bound on that history fetch
+	} `json:"pubsub"`
+
+	TimeSync struct {
+		Reference         bool          `json:"reference"`          // Serve as a time reference and broadcast beacons
+		BeaconInterval    time.Duration `json:"beacon_interval"`    // References are forgotten after three missed beacons
+		SyncInterval      time.Duration `json:"sync_interval"`      // How often non-references measure their offset
+		MaxReferences     int           `json:"max_references"`     // References measured per sync
+		MaxDelay          time.Duration `json:"max_delay"`          // Round trips slower than this are discarded
+		TrustedReferences []string      `json:"trusted_references"` // If set, only these peers are followed
+	} `json:"time_sync"`
+
+	Pinning struct {
+		DefaultReplicas   int           `json:"default_replicas"`     // Target when a pin does not name one
+		MaxReplicas       int           `json:"max_replicas"`         // Upper bound on any pin's target
+		MaxRepairsPerPass int           `json:"max_repairs_per_pass"` // Replicas placed per repair pass; 0 is unlimited
+		RepairInterval    time.Duration `json:"repair_interval"`
+	} `json:"pinning"`
+
+	Repair struct {
+		Interval          time.Duration `json:"interval"`             // Between re-replication passes; 0 disables them
+		SampleSize        int           `json:"sample_size"`          // Chunks checked per pass, round-robin
+		MaxTracked        int           `json:"max_tracked"`          // Originated chunks watched; the oldest are dropped first
+		MaxBytesPerSecond int64         `json:"max_bytes_per_second"` // Repair send rate; 0 is unthrottled
+	} `json:"repair"`
+
+	Power struct {
+		BatteryThreshold float64       `json:"battery_threshold"` // Discharging at or below this level (0-1) counts as on battery
+		DeclineJobs      bool          `json:"decline_jobs"`      // Refuse incoming jobs while constrained
+		FanoutFactor     float64       `json:"fanout_factor"`     // Gossip fanout multiplier while constrained
+		SampleInterval   time.Duration `json:"sample_interval"`   // Frame latency sampling for the thermal heuristic
+		ThermalRatio     float64       `json:"thermal_ratio"`     // Smoothed latency over the best seen that reads as throttling
+		ThermalSamples   int           `json:"thermal_samples"`   // Consecutive samples before the thermal state flips
+	} `json:"power"`
+
+	Load struct {
+		SampleInterval time.Duration `json:"sample_interval"` // Load checks for the busy flag; 0 never flags the node
+		BusyThreshold  float64       `json:"busy_threshold"`  // Load (0-1) at or above which the node advertises itself busy
+		IdleThreshold  float64       `json:"idle_threshold"`  // Load at or below which it stops
+	} `json:"load"`
+
+	IdleSchedule struct {
+		PollInterval time.Duration `json:"poll_interval"` // Checks for an idle window while work waits; 0 runs deferred work at once
+		MinIdle      time.Duration `json:"min_idle"`      // Idle time left in a window below which no work starts
+		MaxDelay     time.Duration `json:"max_delay"`     // Longest work waits for a window before it runs anyway
+		StaleAfter   time.Duration `json:"stale_after"`   // Frame report age past which the host counts as not rendering
+	} `json:"idle_schedule"`
+
+	MetricsGossip struct {
+		MaxAge time.Duration `json:"max_age"` // Oldest gossiped metrics accepted, and how far ahead of mesh time they may be
+		TTL    time.Duration `json:"ttl"`     // A peer's metrics are dropped if it sends no newer report in this time
+	} `json:"metrics_gossip"`
+
+	MetricsAggregation struct {
+		Bounds       map[string]MetricsBounds `json:"bounds"`        // Plausible per-node values by capability class
+		TrimFraction float64                  `json:"trim_fraction"` // Share of reporters dropped from each end for rates
+		OutlierMADs  float64                  `json:"outlier_mads"`  // Deviations above the median before a value is an outlier
+		MinWeight    float64                  `json:"min_weight"`    // Reputation weight floor for new reporters
+	} `json:"metrics_aggregation"`
+
+	Departure struct {
+		Budget    time.Duration `json:"budget"`     // Wall time for the whole leave sequence
+		MaxChunks int           `json:"max_chunks"` // Locally-unique chunks pushed to replicas before leaving
+	} `json:"departure"`
+
+	Connections struct {
+		MaxConnections  int           `json:"max_connections"`   // Peers kept before the lowest-value one is evicted; 0 is unlimited
+		NewPeerGrace    time.Duration `json:"new_peer_grace"`    // How long a new peer is safe from eviction
+		UtilityHalfLife time.Duration `json:"utility_half_life"` // Decay of a peer's credit for useful interactions
+	} `json:"connections"`
+
+	Delegation struct {
+		RequireSignatures bool          `json:"require_signatures"` // Reject unsigned requests and responses
+		MaxRequestSkew    time.Duration `json:"max_request_skew"`
+		AuditLogSize      int           `json:"audit_log_size"`
+		ReceiptLogSize    int           `json:"receipt_log_size"` // Receipts kept; the oldest go first
+
+		IdempotencyTTL       time.Duration `json:"idempotency_ttl"`        // How long an executed request's response is replayed to retries
+		IdempotencyCacheSize int           `json:"idempotency_cache_size"` // Responses kept for replay; the oldest go first
+	} `json:"delegation"`
+
+	Disputes struct {
+		CommitteeSize     int           `json:"committee_size"`     // Reviewers asked to re-execute a disputed job
+		Quorum            int           `json:"quorum"`             // Agreeing verdicts that decide a dispute
+		MinReputation     float64       `json:"min_reputation"`     // Trust score a reviewer needs
+		SlashAmount       int64         `json:"slash_amount"`       // Credits taken from an executor whose receipt is disproved
+		CompensationShare float64       `json:"compensation_share"` // Share of the slashed credits paid to the requester; the rest is burned
+		MaxInputBytes     int           `json:"max_input_bytes"`    // Largest disputed input sent to reviewers
+		Timeout           time.Duration `json:"timeout"`            // Bound on one reviewer's re-execution
+		LogSize           int           `json:"log_size"`           // Disputes kept; the oldest go first
+	} `json:"disputes"`
+
+	LatencyProbe struct {
+		Interval     time.Duration `json:"interval"`      // Time between probe rounds; 0 disables probing
+		SampleSize   int           `json:"sample_size"`   // Peers probed per round, least recently probed first
+		PeerInterval time.Duration `json:"peer_interval"` // Minimum time between probes of one peer; peers probing faster are refused
+		Alpha        float64       `json:"alpha"`         // EWMA weight of a new round trip
+		TTL          time.Duration `json:"ttl"`           // How long an estimate is used without a new probe
+		Timeout      time.Duration `json:"timeout"`
+	} `json:"latency_probe"`
+
+	Sectors struct {
+		JoinLatency      time.Duration `json:"join_latency"`      // Median round trip to a sector's members under which it is joined
+		LeaveFactor      float64       `json:"leave_factor"`      // Multiple of JoinLatency past which the current sector is left
+		MinSamples       int           `json:"min_samples"`       // Measured members a sector needs before it is joined
+		ReassignInterval time.Duration `json:"reassign_interval"` // 0 keeps every node in its home sector
+		Affinity         float32       `json:"affinity"`          // Score multiplier for same-sector peers in placement and delegation
+		// Gossip shards by sector: members gossip among themselves and
+		// through a few bridge peers, and chunk announcements stay local
+		Gossip routing.ShardConfig `json:"gossip"`
+	} `json:"sectors"`
 }
 
 // PeerCacheEntry caches peer information
@@ -205,6 +717,215 @@ func DefaultCoordinatorConfig() CoordinatorConfig {
 	config.CircuitBreaker.ResetTimeout = 30 * time.Second
 	config.CircuitBreaker.HalfOpenMax = 3
 
+	config.BandwidthProbe.Interval = 2 * time.Minute
+	config.BandwidthProbe.PayloadBytes = 64 * 1024
+	config.BandwidthProbe.MaxPeers = 8
+	config.BandwidthProbe.DivergenceRatio = 4.0
+
+	// Pull-based delegation is opt-in: with gossip peers every delegation
+	// would otherwise wait out ClaimTimeout before falling back to direct RPC
+	config.WorkQueue.Enabled = false
+	config.WorkQueue.MaxJobsPerPeer = 2
+	config.WorkQueue.MaxLocalWorkers = 2
+	config.WorkQueue.ClaimTimeout = 3 * time.Second
+	config.WorkQueue.AnnounceInterval = 1 * time.Second
+	config.WorkQueue.ResultTimeout = 60 * time.Second
+	config.WorkQueue.FairShareCap = 2.0
+	config.WorkQueue.FairShareGrace = 250 * time.Millisecond
+
+	config.OfflineQueue.MaxSize = defaultOfflineQueueSize
+
+	config.Bootstrap.CheckInterval = 5 * time.Second
+	config.Bootstrap.InitialBackoff = 5 * time.Second
+	config.Bootstrap.MaxBackoff = 5 * time.Minute
+	config.Bootstrap.DNSTimeout = 5 * time.Second
+
+	config.StorageProof.Interval = 5 * time.Minute
+	config.StorageProof.MaxPeers = 4
+	config.StorageProof.MaxRangeBytes = 4096
+	config.StorageProof.Timeout = 5 * time.Second
+
+	config.LocalDiscovery.Enabled = true
+
+	config.Quarantine.Enabled = true
+	config.Quarantine.CyclesBeforeQuarantine = 3
+	config.Quarantine.BaseDuration = 2 * time.Minute
+	config.Quarantine.MaxDuration = 2 * time.Hour
+	config.Quarantine.Multiplier = 2.0
+	config.Quarantine.DecayAfter = 6 * time.Hour
+	config.Quarantine.CheckInterval = 15 * time.Second
+
+	// Compute delegation is deny-by-default: callers need a capability, and
+	// one is only issued to peers that have earned reputation above the 0.5
+	// every unknown peer starts with.
+	config.Authorization.Enabled = true
+	config.Authorization.DefaultMode = RPCPolicyOpen
+	config.Authorization.Policies = map[string]RPCMethodPolicy{
+		delegateComputeMethod: {Mode: RPCPolicyCapability, MinReputation: 0.6},
+		executeJobMethod:      {Mode: RPCPolicyCapability, MinReputation: 0.6},
+	}
+	config.Authorization.CapabilityTTL = 10 * time.Minute
+
+	config.Demo.Peers = 6
+	config.Demo.MetricsInterval = 2 * time.Second
+
+	config.TopologyExport.MinInterval = 30 * time.Second
+
+	config.Publish.MinReplicas = 1
+	config.Publish.StagedTTL = 10 * time.Minute
+
+	config.DHT.Mode = "auto"
+	config.DHT.PromoteAfter = 10 * time.Minute
+	config.DHT.PromoteMinPeers = 3
+	config.DHT.DisjointPaths = 3
+	config.DHT.MaxPerSubnet = 2
+
+	config.ChunkTTL.Min = 10 * time.Minute
+	config.ChunkTTL.Max = 6 * time.Hour
+	config.ChunkTTL.HotThreshold = 0.7
+	config.ChunkTTL.RepublishFraction = 0.5
+	config.ChunkTTL.CheckInterval = time.Minute
+	config.ChunkTTL.RepublishInterval = 2 * time.Minute
+	config.ChunkTTL.RepublishJitter = 0.2
+	config.ChunkTTL.RepublishBatch = 64
+	config.ChunkTTL.RepublishPause = 500 * time.Millisecond
+
+	config.StorageQuota.MaxBytes = 1 << 30
+	config.StorageQuota.LowWatermark = 0.9
+	config.StorageQuota.ReplicaTarget = 3
+	config.StorageQuota.DemandWeight = 4
+	config.StorageQuota.CheckInterval = time.Minute
+
+	config.Objects.ChunkSize = 1 << 20
+	config.Objects.MaxChunks = 4096
+	config.Objects.MaxKnown = 1024
+
+	config.ChunkACL.RequestMaxAge = 2 * time.Minute
+	config.ChunkACL.MaxGrants = 8
+	config.ChunkACL.DenialLog = 256
+	config.ChunkACL.NotifyInterval = time.Minute
+
+	config.InboundStorage.Enabled = true
+	config.InboundStorage.PeerBytes = 256 * 1024 * 1024
+	config.InboundStorage.GlobalBytes = 768 * 1024 * 1024
+	config.InboundStorage.PeerRateBytes = 64 * 1024 * 1024
+	config.InboundStorage.RateWindow = time.Minute
+	config.InboundStorage.UnjustifiedBytes = 4 * 1024 * 1024
+	config.InboundStorage.MaxReplicaTarget = 16
+	config.InboundStorage.AbuseThreshold = 5
+	config.InboundStorage.AbuseWindow = 10 * time.Minute
+
+	config.Warm.BandwidthBytesPerSec = 4 << 20
+	config.Warm.HoldTTL = 10 * time.Minute
+	config.Warm.Timeout = 5 * time.Minute
+
+	config.SybilAdmission.Mode = SybilAdmissionOff
+	config.SybilAdmission.PoWDifficulty = 20
+	config.SybilAdmission.MinStake = 100
+
+	config.Modules.Policy = ModulePolicyOpen
+	config.Modules.MaxSize = 16 << 20
+	config.Modules.MaxCached = 64
+	config.Modules.MaxKnown = 1024
+
+	config.Sandbox.Fuel = 10_000_000_000
+	config.Sandbox.MemoryBytes = 256 << 20
+	config.Sandbox.WallClock = 30 * time.Second
+
+	config.ShardAssembly.Parallelism = 8
+	config.ShardAssembly.MaxPendingShards = 16
+
+	config.Services.DefaultTTL = 10 * time.Minute
+	config.Services.CheckInterval = 30 * time.Second
+	config.Services.HealthTimeout = 5 * time.Second
+	config.Services.ResolveTimeout = 3 * time.Second
+	config.Services.MaxResults = 20
+
+	config.Records.DefaultTTL = 12 * time.Hour
+	config.Records.ResolveTimeout = 5 * time.Second
+
+	config.PubSub.MaxPayload = 64 * 1024
+	config.PubSub.RatePerSecond = 10
+	config.PubSub.Burst = 20
+	config.PubSub.InboxSize = 256
+	config.PubSub.HistoryPeers = 3
+	config.PubSub.HistoryTimeout = 3 * time.Second
+
+	config.TimeSync.BeaconInterval = 30 * time.Second
+	config.TimeSync.SyncInterval = time.Minute
+	config.TimeSync.MaxReferences = 5
+	config.TimeSync.MaxDelay = 250 * time.Millisecond
+
+	config.Pinning.DefaultReplicas = 3
+	config.Pinning.MaxReplicas = 16
+	config.Pinning.MaxRepairsPerPass = 16
+	config.Pinning.RepairInterval = 5 * time.Minute
+	config.Repair.Interval = 10 * time.Minute
+	config.Repair.SampleSize = 32
+	config.Repair.MaxTracked = 4096
+	config.Repair.MaxBytesPerSecond = 1 << 20
+	config.Power.BatteryThreshold = 1
+	config.Power.DeclineJobs = true
+	config.Power.FanoutFactor = 0.5
+	config.Power.SampleInterval = 5 * time.Second
+	config.Power.ThermalRatio = 2
+	config.Power.ThermalSamples = 6
+	config.Load.SampleInterval = 2 * time.Second
+	config.Load.BusyThreshold = 0.85
+	config.Load.IdleThreshold = 0.6
+	config.IdleSchedule.PollInterval = 4 * time.Millisecond
+	config.IdleSchedule.MinIdle = 2 * time.Millisecond
+	config.IdleSchedule.MaxDelay = time.Second
+	config.IdleSchedule.StaleAfter = 250 * time.Millisecond
+
+	config.MetricsAggregation.Bounds = map[string]MetricsBounds{
+		MetricsClassLight:    {MaxComputeGFLOPS: 200, MaxOpsPerSec: 5e6, MaxStorageBytes: 8 << 30},
+		MetricsClassStandard: {MaxComputeGFLOPS: 2000, MaxOpsPerSec: 5e7, MaxStorageBytes: 256 << 30},
+		MetricsClassGPU:      {MaxComputeGFLOPS: 100000, MaxOpsPerSec: 5e8, MaxStorageBytes: 256 << 30},
+	}
+	config.MetricsAggregation.TrimFraction = 0.1
+	config.MetricsAggregation.OutlierMADs = 10
+	config.MetricsAggregation.MinWeight = 0.1
+
+	config.Departure.Budget = 3 * time.Second
+	config.Departure.MaxChunks = 32
+
+	config.Connections.MaxConnections = 100
+	config.Connections.NewPeerGrace = 30 * time.Second
+	config.Connections.UtilityHalfLife = 10 * time.Minute
+
+	config.Delegation.RequireSignatures = true
+	config.Delegation.MaxRequestSkew = 10 * time.Minute
+	config.MetricsGossip.MaxAge = 2 * time.Minute
+	config.MetricsGossip.TTL = 5 * time.Minute
+	config.Delegation.AuditLogSize = 1024
+	config.Delegation.ReceiptLogSize = 1024
+	config.Delegation.IdempotencyTTL = 10 * time.Minute
+	config.Delegation.IdempotencyCacheSize = 1024
+
+	config.Disputes.CommitteeSize = 3
+	config.Disputes.Quorum = 2
+	config.Disputes.MinReputation = 0.5
+	config.Disputes.SlashAmount = 500
+	config.Disputes.CompensationShare = 0.5
+	config.Disputes.MaxInputBytes = 4 << 20
+	config.Disputes.Timeout = 30 * time.Second
+	config.Disputes.LogSize = 256
+
+	config.LatencyProbe.Interval = 15 * time.Second
+	config.LatencyProbe.SampleSize = 4
+	config.LatencyProbe.PeerInterval = time.Minute
+	config.LatencyProbe.Alpha = 0.3
+	config.LatencyProbe.TTL = 10 * time.Minute
+	config.LatencyProbe.Timeout = 2 * time.Second
+
+	config.Sectors.JoinLatency = 40 * time.Millisecond
+	config.Sectors.LeaveFactor = 2
+	config.Sectors.MinSamples = 1
+	config.Sectors.ReassignInterval = 30 * time.Second
+	config.Sectors.Affinity = 1.25
+	config.Sectors.Gossip = routing.DefaultShardConfig()
+
 	return config
 }
 
@@ -228,17 +949,60 @@ func NewMeshCoordinator(nodeID, region string, tr Transport, logger *slog.Logger
 		peerCache:       make(map[string]PeerCacheEntry),
 		peerCacheTTL:    config.CacheTTL,
 		peerMetrics:     make(map[string]common.MeshMetrics),
+		metricsOutliers: make(map[string]*MetricsOutlier),
 		shutdown:        make(chan struct{}),
 		subscriptions:   make(map[string]*meshSubscription),
 		config:          config,
 		logger:          logger.With("component", "mesh_coordinator", "node_id", getShortID(nodeID)),
 		activeJobs:      make(map[string]int32),
+		pendingWork:     make(map[string]*workItem),
+		pulledWork:      make(map[string]string),
 		attestedPeers:   make(map[string]AttestationRecord),
 		attestingPeers:  make(map[string]struct{}),
+		bandwidthProbes: make(map[string]*BandwidthMeasurement),
+		featureProbes:   make(map[string]*PeerFeatureReport),
+		chunkRecords:    make(map[string]*chunkRecord),
+		keyRevocations:  make(map[string]KeyRevocation),
+		quarantined:     make(map[string]*quarantineEntry),
+		namespaceUsage:  make(map[string]*NamespaceUsage),
+		stagedReplicas:  make(map[string]*stagedTransaction),
+
+		pinnedChunks:     make(map[string]*PinnedChunk),
+		pinnedNamespaces: make(map[string]*PinnedNamespace),
+		pinRepairs:       make(map[string]*pinRepairState),
+		pinRepairKick:    make(chan struct{}, 1),
+		knownObjects:     make(map[string]*ObjectAnnouncement),
+		chunkKeys:        make(map[string]*NamespaceKey),
+		services:         make(map[string]*localService),
+		publishedRecords: make(map[string]*publishedRecord),
+		topics:           make(map[string]*topicState),
+		topicSubs:        make(map[string]*topicSubscriber),
+		warmJobs:         make(map[string]*warmJob),
+		warmHolds:        make(map[string]time.Time),
+		timeSync:         timeSyncState{references: make(map[string]*timeReference)},
+		modules:          newModuleRegistry(),
+		gpuTimings:       make(map[string]*GPUTimingStats),
+		idle:             idleScheduleState{wake: make(chan struct{}, 1)},
+		chunkACL:         newChunkACLState(),
+		inbound:          newInboundStorageState(),
+
+		heldCapabilities:      make(map[string]*RPCCapability),
+		capabilityRefusals:    make(map[string]time.Time),
+		peerMetricsVersions:   make(map[string]peerMetricsVersion),
+		capabilityRevocations: make(map[string]time.Time),
 	}
 
 	// Initialize subsystems
+	coord.sector.Store(homeSector(nodeID))
+	coord.offlineQueue, _ = NewOfflineQueue(config.OfflineQueue.MaxSize, nil)
+	coord.storageQuota = NewStorageQuota(config.StorageQuota.MaxBytes)
+	for namespace, limit := range config.StorageQuota.NamespaceBytes {
+		coord.storageQuota.SetNamespaceLimit(namespace, limit)
+	}
+	coord.admission, _ = NewAdmissionController(nil)
+	coord.admission.Subscribe(func(AdmissionPolicy) { go coord.enforceAdmission() })
 	coord.dht = routing.NewDHT(nodeID, tr, logger)
+	coord.configureDHTSecurity()
 	coord.reputation = routing.NewReputationManager(3*24*time.Hour, nil, logger)
 
 	var err error
@@ -249,10 +1013,15 @@ func NewMeshCoordinator(nodeID, region string, tr Transport, logger *slog.Logger
 
 	// Initialize Gossip-based signaling channel for decentralized bootstrapping
 	coord.gossipSignaling = transport.NewGossipSignalingChannel(nodeID, func(topic string, payload interface{}) error {
-		if coord.gossip != nil {
-			return coord.gossip.Broadcast(topic, payload)
+		if coord.gossip == nil {
+			return errors.New("gossip manager not initialized")
 		}
-		return errors.New("gossip manager not initialized")
+		// Offers and answers are addressed to one peer; seal them so relaying
+		// nodes cannot read the SDP.
+		if targetID := signalingTarget(payload); targetID != "" {
+			return coord.gossip.BroadcastTo(topic, targetID, payload)
+		}
+		return coord.gossip.Broadcast(topic, payload)
 	})
 
 	// Inject into transport if it supports it
@@ -261,6 +1030,7 @@ func NewMeshCoordinator(nodeID, region string, tr Transport, logger *slog.Logger
 	}); ok {
 		injector.InjectSignalingChannel("gossip://mesh", coord.gossipSignaling)
 	}
+	coord.injectRelaySealer(tr)
 
 	// Initialize adaptive allocator
 	coord.allocator = internal.NewAdaptiveAllocator(5, 700, 0.375, 0.50)
@@ -286,6 +1056,7 @@ func NewMeshCoordinator(nodeID, region string, tr Transport, logger *slog.Logger
 
 	// Initialize Economic Ledger
 	coord.ledger = NewEconomicLedger()
+	coord.ledger.SetChangeHandler(coord.publishLedgerChange)
 	// Bootstrap local account with Early Adopter Bonus (10,000 microcredits)
 	coord.ledger.RegisterAccount(nodeID, 0)
 	coord.ledger.GrantEarlyAdopterBonus(nodeID, 10000)
@@ -293,6 +1064,14 @@ func NewMeshCoordinator(nodeID, region string, tr Transport, logger *slog.Logger
 	return coord
 }
 
+// SetRegion changes the node's region before the mesh starts.
+func (m *MeshCoordinator) SetRegion(region string) {
+	if region == "" {
+		return
+	}
+	m.region = region
+}
+
 // ReplaceTransport swaps the transport before the mesh starts.
 func (m *MeshCoordinator) ReplaceTransport(tr Transport) {
 	if tr == nil {
@@ -305,16 +1084,24 @@ func (m *MeshCoordinator) ReplaceTransport(tr Transport) {
 	}); ok && m.gossipSignaling != nil {
 		injector.InjectSignalingChannel("gossip://mesh", m.gossipSignaling)
 	}
+	if injector := m.faults.Injector(); injector != nil {
+		m.SetFaultInjector(injector)
+	}
 
 	m.dht = routing.NewDHT(m.nodeID, tr, m.logger)
+	m.configureDHTSecurity()
 	m.reputation = routing.NewReputationManager(3*24*time.Hour, nil, m.logger)
 
 	gossip, err := routing.NewGossipManager(m.nodeID, tr, m.logger)
 	if err == nil {
 		m.gossip = gossip
+		if identity := m.NodeIdentity(); identity != nil {
+			_ = gossip.SetSigningKey(identity.PrivateKey())
+		}
 		m.registerGossipHandlers()
 		m.registerRPCHandlers()
 	}
+	m.injectRelaySealer(tr)
 }
 
 // SetIdentity updates mesh identity metadata (DID/device/display name).
@@ -327,6 +1114,11 @@ func (m *MeshCoordinator) SetIdentity(did, deviceID, displayName string) {
 		if m.ledger != nil {
 			m.ledger.EnsureAccount(did, 0)
 		}
+		if m.nodeIdentity != nil {
+			if _, err := m.nodeIdentity.BindDID(did); err != nil {
+				m.logger.Warn("failed to bind DID to node identity", "did", did, "error", err)
+			}
+		}
 	}
 	if deviceID != "" {
 		m.device = deviceID
@@ -338,6 +1130,9 @@ func (m *MeshCoordinator) SetIdentity(did, deviceID, displayName string) {
 
 // SetStorage sets the local storage provider
 func (m *MeshCoordinator) SetStorage(storage StorageProvider) {
+	if storage != nil {
+		storage = &faultStorage{StorageProvider: storage, faults: &m.faults}
+	}
 	m.storage = storage
 }
 
@@ -345,15 +1140,27 @@ func (m *MeshCoordinator) SetStorage(storage StorageProvider) {
 func (m *MeshCoordinator) SetSABBridge(bridge SABWriter) {
 	m.bridge = bridge
 	if bridge != nil {
-		m.eventQueue = NewMeshEventQueue(bridge)
+		m.bridge = &faultSAB{SABWriter: bridge, faults: &m.faults}
+		m.eventQueue = NewMeshEventQueue(m.bridge)
+	}
+
+	m.eventRingMu.Lock()
+	if m.eventRing != nil && bridge != nil {
+		m.eventRing.setBridge(m.bridge)
 	}
+	m.eventRingMu.Unlock()
 }
 
-// SetMonitor sets the system load provider for the delegation engine
+// SetMonitor sets the system load provider for the delegation engine and
+// the gossip round scheduler
 func (m *MeshCoordinator) SetMonitor(monitor SystemLoadProvider) {
 	m.decider.mu.Lock()
-	defer m.decider.mu.Unlock()
 	m.decider.loadProvider = monitor
+	m.decider.mu.Unlock()
+
+	if m.gossip != nil && monitor != nil {
+		m.gossip.SetLoadProvider(monitor.GetSystemLoad)
+	}
 }
 
 // SetEconomicVault sets the grounded economic authority
@@ -370,15 +1177,24 @@ func (m *MeshCoordinator) ApplyRoleConfig(config runtime.RoleConfig) {
 		tr.ApplyRoleConfig(config)
 	}
 
-	if m.gossip != nil {
-		m.gossip.SetFanout(config.GossipFanout)
+	m.setGossipFanout(config.GossipFanout)
+
+	if config.Memory.Tier != "" {
+		m.applyMemoryProfile(config.Memory)
 	}
+
+	m.applyContribution(config.Contribution)
+	m.applyDHTMode(config)
 }
 
 // Start begins mesh coordination
 func (m *MeshCoordinator) Start(ctx context.Context) error {
 	m.logger.Info("starting mesh coordinator")
 
+	if m.config.Demo.Enabled && m.sim == nil {
+		m.startDemoMode()
+	}
+
 	if err := m.transport.Start(ctx); err != nil {
 		return fmt.Errorf("failed to start transport: %w", err)
 	}
@@ -390,12 +1206,31 @@ func (m *MeshCoordinator) Start(ctx context.Context) error {
 	}); ok {
 		hook.SetPeerEventHandler(m.handleTransportPeerEvent)
 	}
+	if hook, ok := m.transport.(interface {
+		SetSecurityEventHandler(func(transport.TranscriptMismatch))
+	}); ok {
+		hook.SetSecurityEventHandler(m.handleTranscriptMismatch)
+	}
+	if hook, ok := m.transport.(interface {
+		SetMessageHandler(func(peerID string, payload json.RawMessage))
+	}); ok {
+		hook.SetMessageHandler(m.handleTransportMessage)
+	}
+	if hook, ok := m.transport.(interface {
+		SetRelayUsageHandler(func(transport.RelayUsage))
+	}); ok {
+		hook.SetRelayUsageHandler(m.creditRelay)
+	}
 
 	// Start subsystems
 	if err := m.dht.Start(); err != nil {
 		return fmt.Errorf("failed to start DHT: %w", err)
 	}
 
+	m.gossip.SetSharding(m.config.Sectors.Gossip)
+	m.gossip.SetSector(m.sector.Load())
+	m.gossip.SetIdleScheduler(m)
+	m.gossip.SetNetworkSizeEstimator(m.dht.EstimateNetworkSize)
 	if err := m.gossip.Start(); err != nil {
 		return fmt.Errorf("failed to start Gossip: %w", err)
 	}
@@ -406,6 +1241,29 @@ func (m *MeshCoordinator) Start(ctx context.Context) error {
 	go m.metricsLoop()
 	go m.healthLoop()
 	go m.cacheCleanupLoop()
+	go m.bandwidthProbeLoop()
+	go m.bootstrapLoop()
+	go m.storageProofLoop()
+	go m.quarantineLoop()
+	go m.chunkTTLLoop()
+	go m.providerRepublishLoop()
+	go m.timeSyncLoop()
+	go m.storageQuotaLoop()
+	go m.pinRepairLoop()
+	go m.replicaRepairLoop()
+	go m.powerLoop()
+	go m.loadLoop()
+	go m.idleScheduleLoop()
+	go m.serviceRefreshLoop()
+	go m.latencyProbeLoop()
+	go m.sectorLoop()
+	if m.sim != nil {
+		go m.demoLoop()
+	}
+
+	if m.config.LocalDiscovery.Enabled {
+		m.startLocalDiscovery(ctx)
+	}
 
 	// Start epoch-aware optimization (NEW)
 	m.epochTicker.Start(ctx)
@@ -444,6 +1302,16 @@ func (m *MeshCoordinator) GrantEconomicBonus(did string, bonus int64) {
 	}
 }
 
+// Ledger returns the node's economic ledger.
+func (m *MeshCoordinator) Ledger() *EconomicLedger {
+	return m.ledger
+}
+
+// Storage returns the local storage provider, or nil before SetStorage.
+func (m *MeshCoordinator) Storage() StorageProvider {
+	return m.storage
+}
+
 // Stop gracefully shuts down
 func (m *MeshCoordinator) Stop() error {
 	m.logger.Info("stopping mesh coordinator")
@@ -468,6 +1336,11 @@ func (m *MeshCoordinator) handleTransportPeerEvent(peerID string, connected bool
 	}
 
 	if connected {
+		if !m.admitPeer(peerID) {
+			return
+		}
+		m.notePeerConnected(peerID)
+		go m.enforceConnectionLimit()
 		if m.config.AttestationEnabled {
 			m.startPeerAttestation(peerID)
 			return
@@ -477,6 +1350,7 @@ func (m *MeshCoordinator) handleTransportPeerEvent(peerID string, connected bool
 	}
 
 	m.clearPeerAttestation(peerID)
+	m.notePeerDisconnected(peerID)
 	m.dht.RemovePeer(peerID)
 	m.gossip.RemovePeer(peerID)
 	m.emitPeerUpdateEvent(&PeerCapability{
@@ -486,6 +1360,26 @@ func (m *MeshCoordinator) handleTransportPeerEvent(peerID string, connected bool
 	})
 }
 
+// handleTransportMessage takes messages pushed by peers outside of RPCs.
+// Eagerly pushed gossip goes to the gossip manager; anything else is ignored.
+func (m *MeshCoordinator) handleTransportMessage(peerID string, payload json.RawMessage) {
+	var msg common.GossipMessage
+	if err := json.Unmarshal(payload, &msg); err != nil || msg.ID == "" || msg.Type == "" || msg.Sender == "" {
+		return
+	}
+	if err := m.gossip.ReceiveMessage(peerID, &msg); err != nil {
+		m.logger.Debug("dropped pushed gossip", "peer", getShortID(peerID), "type", msg.Type, "error", err)
+	}
+}
+
+// handleTranscriptMismatch surfaces a connection whose frames were altered
+// in transit. The transport has already torn the connection down; the peer
+// itself is not penalized since a relay on the path is the likelier culprit.
+func (m *MeshCoordinator) handleTranscriptMismatch(event transport.TranscriptMismatch) {
+	m.logger.Warn("connection tampering detected", "peer", getShortID(event.PeerID), "frames", event.Frames)
+	m.publishEvent(MeshEventTranscriptMismatch, event.PeerID, event)
+}
+
 // GetNodeCount returns the number of active nodes in the mesh (including self)
 func (m *MeshCoordinator) GetNodeCount() int {
 	stats := m.transport.GetStats()
@@ -520,31 +1414,55 @@ func (m *MeshCoordinator) GetTelemetry() map[string]interface{} {
 	if peerCount > 0 {
 		avgLatency = totalLatency / float32(peerCount)
 	}
-
-	return map[string]interface{}{
-		"node_count":        m.GetNodeCount(),
-		"sector_id":         m.GetSectorID(),
-		"active_peers":      peerCount,
-		"avg_latency_ms":    avgLatency,
-		"bytes_sent":        stats["bytes_sent"],
-		"bytes_received":    stats["bytes_received"],
-		"messages_sent":     stats["messages_sent"],
-		"messages_received": stats["messages_received"],
-		"region":            m.region,
-		"node_id":           m.nodeID,
-		"did":               did,
-		"device_id":         device,
-		"display_name":      name,
+	quota := m.GetStorageQuotaUsage()
+	connections := m.GetConnectionStats()
+	probes := m.GetLatencyProbeStats()
+	sectors := m.GetSectors()
+	sectorMembers := 0
+	sectorRegion := ""
+	for _, sector := range sectors {
+		if sector.Local {
+			sectorMembers, sectorRegion = sector.Members, sector.Region
+		}
 	}
-}
+	shardMembers, bridgePeers := m.gossip.ShardPeers()
 
-// GetSectorID returns the sector identifier for this node
-func (m *MeshCoordinator) GetSectorID() int {
-	hash := 0
-	for _, c := range m.nodeID {
-		hash = (hash * 31) + int(c)
+	return map[string]interface{}{
+		"node_count":         m.GetNodeCount(),
+		"sector_id":          m.GetSectorID(),
+		"sector_members":     sectorMembers,
+		"sector_region":      sectorRegion,
+		"sectors":            sectorTelemetry(sectors),
+		"gossip_shard_peers": len(shardMembers),
+		"gossip_bridges":     len(bridgePeers),
+		"local_peers":        len(m.GetLocalSector()),
+		"served_namespaces":  len(m.GetNamespaceUsage()),
+		"demo_mode":          m.IsDemoMode(),
+		"synthetic_peers":    m.syntheticPeerCount(),
+		"memory_profile":     string(m.MemoryProfile().Tier),
+		"dht_mode":           string(m.dht.Mode()),
+		"storage_used":       quota.UsedBytes,
+		"storage_quota":      quota.MaxBytes,
+		"storage_chunks":     quota.Chunks,
+		"storage_evicted":    quota.Evicted,
+		"pinned_chunks":      len(m.pinTargets()),
+		"active_peers":       peerCount,
+		"max_connections":    connections.MaxConnections,
+		"peer_evictions":     connections.Evictions,
+		"request_replays":    m.idempotency.replayCount(),
+		"latency_probes":     probes.Sent,
+		"latency_measured":   probes.Measured,
+		"avg_latency_ms":     avgLatency,
+		"bytes_sent":         stats["bytes_sent"],
+		"bytes_received":     stats["bytes_received"],
+		"messages_sent":      stats["messages_sent"],
+		"messages_received":  stats["messages_received"],
+		"region":             m.region,
+		"node_id":            m.nodeID,
+		"did":                did,
+		"device_id":          device,
+		"display_name":       name,
 	}
-	return (hash & 0x7FFFFFFF) % 256
 }
 
 // SendMessage sends a generic message to a target peer via the transport
@@ -556,132 +1474,24 @@ func (m *MeshCoordinator) SendMessage(ctx context.Context, targetPeerID string,
 // ========== SHARED COMPUTE ORCHESTRATION ==========
 
 // DistributeChunk distributes a chunk across the mesh for shared storage
+// and returns once every replica send has finished.
 func (m *MeshCoordinator) DistributeChunk(ctx context.Context, chunkHash string, data []byte) (int, error) {
-	start := time.Now()
-
-	// 1. Calculate optimal replicas based on size and demand
-	demandScore := m.demandTracker.GetDemandScore(chunkHash)
-	replicas := 3 // m.allocator.CalculateReplicas(...) - Defaulting to 3 if allocator logic is complex or internal
-	// Re-reading code: m.allocator.CalculateReplicas IS callled in original. I should keep it.
-	replicas = m.allocator.CalculateReplicas(common.Resource{
-		Size:        uint64(len(data)),
-		Type:        "chunk",
-		DemandScore: demandScore,
-	})
-
-	m.logger.Debug("distributing chunk",
-		"chunk", getShortID(chunkHash),
-		"size", len(data),
-		"replicas", replicas)
-
-	// 2. Find candidate peers via DHT
-	closestPeers := m.dht.FindNode(chunkHash)
-
-	// 3. Score and select best peers
-	scored := m.scorePeers(closestPeers)
-	selected := scored[:minInt(replicas, len(scored))]
-
-	// 4. Send chunk to selected peers in parallel
-	var wg sync.WaitGroup
-	sendErrors := make(chan error, len(selected))
-	successfulPeers := make(chan string, len(selected))
-
-	for _, peer := range selected {
-		wg.Add(1)
-		go func(p PeerInfo) {
-			defer wg.Done()
-
-			if err := m.sendChunkToPeer(ctx, p.ID, chunkHash, data); err != nil {
-				sendErrors <- fmt.Errorf("peer %s: %w", getShortID(p.ID), err)
-				return
-			}
-			successfulPeers <- p.ID
-		}(peer)
-	}
-
-	wg.Wait()
-	close(sendErrors)
-	close(successfulPeers)
-
-	// 5. Store in local DHT
-	if err := m.dht.Store(chunkHash, m.nodeID, 3600); err != nil {
-		m.logger.Warn("failed to store in DHT", "error", err)
-	}
-
-	// 6. Announce via gossip
-	m.gossip.AnnounceChunk(chunkHash)
-
-	// 7. Store locally
-	localStored := false
-	var localStoreErr error
-	if m.storage != nil {
-		if err := m.storage.StoreChunk(ctx, chunkHash, data); err != nil {
-			m.logger.Warn("failed to store chunk locally", "error", err)
-			localStoreErr = err
-		} else {
-			localStored = true
-		}
-	}
-
-	if localStored {
-		m.localChunksMu.Lock()
-		m.localChunks[chunkHash] = struct{}{}
-		m.localChunksMu.Unlock()
-	}
-
-	// 8. Update chunk cache with peers that actually received it
-	deliveredPeerIDs := make([]string, 0, len(selected))
-	for peerID := range successfulPeers {
-		deliveredPeerIDs = append(deliveredPeerIDs, peerID)
-	}
-
-	var firstSendErr error
-	failedSends := 0
-	for err := range sendErrors {
-		failedSends++
-		if firstSendErr == nil {
-			firstSendErr = err
-		}
-		m.logger.Warn("failed to replicate chunk to peer", "chunk", getShortID(chunkHash), "error", err)
-	}
-
-	if len(deliveredPeerIDs) > 0 {
-		m.chunkCache.Put(chunkHash, deliveredPeerIDs, 1.0)
-	}
-
-	deliveredReplicas := len(deliveredPeerIDs)
-	if localStored {
-		deliveredReplicas++
-	}
-
-	if deliveredReplicas == 0 {
-		if firstSendErr != nil {
-			return 0, fmt.Errorf("chunk distribution failed: %w", firstSendErr)
-		}
-		if localStoreErr != nil {
-			return 0, fmt.Errorf("chunk distribution failed: %w", localStoreErr)
-		}
-		return 0, errors.New("chunk distribution failed: no replicas delivered")
-	}
-
-	m.emitChunkDiscoveredEvent(chunkHash, m.nodeID, p2p.ChunkPriority_high)
-
-	m.logger.Info("chunk distributed",
-		"chunk", getShortID(chunkHash),
-		"requested_replicas", replicas,
-		"delivered_replicas", deliveredReplicas,
-		"failed_sends", failedSends,
-		"duration", time.Since(start))
+	return m.DistributeChunkAsync(ctx, chunkHash, data).Wait(ctx)
+}
 
-	// 9. Signal chunk distribution complete
-	if m.bridge != nil {
-		m.bridge.SignalEpoch(sab.IDX_DELEGATED_CHUNK_EPOCH)
-	}
+func (m *MeshCoordinator) selectBestPeerForJob() (string, float32) {
+	return m.selectBestPeerWhere(nil)
+}
 
-	return deliveredReplicas, nil
+// selectBestPeerWhere is selectBestPeerForJob restricted to peers accepted
+// by accept; a nil accept considers every peer.
+func (m *MeshCoordinator) selectBestPeerWhere(accept func(peerID string) bool) (string, float32) {
+	return m.selectBestPeerScored(accept, nil)
 }
 
-func (m *MeshCoordinator) selectBestPeerForJob() (string, float32) {
+// selectBestPeerScored is selectBestPeerWhere with each score passed through
+// adjust, e.g. to rank peers for a job class; a nil adjust keeps the scores.
+func (m *MeshCoordinator) selectBestPeerScored(accept func(peerID string) bool, adjust func(peerID string, score float32) float32) (string, float32) {
 	m.peerMetricsMu.RLock()
 	defer m.peerMetricsMu.RUnlock()
 
@@ -691,8 +1501,14 @@ func (m *MeshCoordinator) selectBestPeerForJob() (string, float32) {
 
 	var bestPeer string
 	var bestScore float32 = -1.0
+	sector := m.sector.Load()
 
 	for peerID, metrics := range m.peerMetrics {
+		if m.isPeerQuarantined(peerID) || m.peerWithholds(peerID, noComputeCapability) ||
+			(accept != nil && !accept(peerID)) {
+			continue
+		}
+
 		// Score = (Reputation * LocationBoost) / (Latency + 0.1)
 		// We "gamify" for best performance - the fastest, most reliable nodes win.
 		// Busy nodes are NOT penalized as long as they stay performant.
@@ -703,6 +1519,13 @@ func (m *MeshCoordinator) selectBestPeerForJob() (string, float32) {
 		if metrics.RegionID != 0 && metrics.RegionID == m.metrics.RegionID {
 			score *= 1.5 // 50% boost for same region
 		}
+		// Sectors are measured, not declared: prefer the near ones
+		if affinity := m.config.Sectors.Affinity; affinity > 0 && metrics.SectorID == sector {
+			score *= affinity
+		}
+		if adjust != nil {
+			score = adjust(peerID, score)
+		}
 
 		if score > bestScore {
 			bestScore = score
@@ -727,8 +1550,20 @@ func (m *MeshCoordinator) decrementActiveJobs(peerID string) {
 	}
 }
 
-// FetchChunk retrieves a chunk from the mesh for shared compute
+// FetchChunk retrieves a chunk from the mesh for shared compute. A chunk
+// sealed with a namespace key is returned decrypted, or fails with
+// ErrNamespaceKeyUnavailable if this node does not hold the key.
 func (m *MeshCoordinator) FetchChunk(ctx context.Context, chunkHash string) ([]byte, error) {
+	data, err := m.fetchStoredChunk(ctx, chunkHash)
+	if err != nil {
+		return nil, err
+	}
+	return m.openChunk(data)
+}
+
+// fetchStoredChunk retrieves a chunk as stored, sealed or not: the bytes its
+// hash covers, for re-storing and replicating.
+func (m *MeshCoordinator) fetchStoredChunk(ctx context.Context, chunkHash string) ([]byte, error) {
 	start := time.Now()
 
 	if m.storage != nil {
@@ -736,6 +1571,7 @@ func (m *MeshCoordinator) FetchChunk(ctx context.Context, chunkHash string) ([]b
 			data, err := m.storage.FetchChunk(ctx, chunkHash)
 			if err == nil {
 				m.logger.Debug("chunk fetched from local storage", "chunk", getShortID(chunkHash))
+				m.touchStoredChunk(chunkHash)
 				return data, nil
 			}
 			m.logger.Warn("failed to fetch locally even though HasChunk returned true", "error", err)
@@ -822,9 +1658,7 @@ func (m *MeshCoordinator) FetchChunkDirect(ctx context.Context, chunkHash string
 	}
 
 	// 3. Use StreamRPC for direct piping from network to writer
-	return m.transport.StreamRPC(ctx, peer.PeerID, "chunk.fetch", map[string]string{
-		"chunk_hash": chunkHash,
-	}, writer)
+	return m.transport.StreamRPC(ctx, peer.PeerID, chunkFetchMethod, m.signChunkFetch(chunkHash), writer)
 }
 
 // FindBestPeerForChunk finds the optimal peer for fetching a chunk
@@ -890,10 +1724,16 @@ func (m *MeshCoordinator) selectBestPeer(peers []*PeerCapability) (*PeerCapabili
 		score float32
 	}
 
-	scoredPeers := make([]scoredPeer, len(peers))
-	for i, peer := range peers {
+	scoredPeers := make([]scoredPeer, 0, len(peers))
+	for _, peer := range peers {
+		if m.isPeerQuarantined(peer.PeerID) {
+			continue
+		}
 		score := m.calculatePeerScore(peer)
-		scoredPeers[i] = scoredPeer{peer: peer, score: score}
+		scoredPeers = append(scoredPeers, scoredPeer{peer: peer, score: score})
+	}
+	if len(scoredPeers) == 0 {
+		return nil, errors.New("all candidate peers are quarantined")
 	}
 
 	// Sort by score descending
@@ -909,6 +1749,18 @@ func (m *MeshCoordinator) selectBestPeer(peers []*PeerCapability) (*PeerCapabili
 	return scoredPeers[0].peer, nil
 }
 
+// peerLatency returns the measured round trip to peerID: the probed
+// estimate while it is fresh, else what the transport timed on RPCs.
+func (m *MeshCoordinator) peerLatency(peerID string) (time.Duration, bool) {
+	if rtt, ok := m.probedLatency(peerID); ok {
+		return rtt, true
+	}
+	if lr, ok := m.transport.(common.LatencyReporter); ok {
+		return lr.PeerRPCLatency(peerID)
+	}
+	return 0, false
+}
+
 func (m *MeshCoordinator) calculatePeerScore(peer *PeerCapability) float32 {
 	var score float32
 	weights := m.config.PeerSelectionWeights
@@ -917,22 +1769,39 @@ func (m *MeshCoordinator) calculatePeerScore(peer *PeerCapability) float32 {
 	reputation, _ := m.reputation.GetTrustScore(peer.PeerID)
 	score += float32(reputation) * weights.Reputation
 
-	// 2. Latency (inverse)
-	latencyScore := m.calculateLatencyScore(peer.LatencyMs)
+	// 2. Latency (inverse), measured where the transport has round trips
+	latencyMs := peer.LatencyMs
+	if measured, ok := m.peerLatency(peer.PeerID); ok {
+		latencyMs = float32(measured) / float32(time.Millisecond)
+	}
+	latencyScore := m.calculateLatencyScore(latencyMs)
 	score += latencyScore * weights.Latency
 
 	// 3. Bandwidth
-	bandwidthScore := m.calculateBandwidthScore(peer.BandwidthKbps)
+	bandwidthScore := m.calculateBandwidthScore(m.effectiveBandwidthKbps(peer))
 	score += bandwidthScore * weights.Bandwidth
 
-	// 4. Region proximity
+	// 4. Region proximity (peers on our LAN or in our sector are as close
+	// as it gets)
 	regionScore := m.calculateRegionScore(peer.Region)
+	if m.isLocalPeer(peer.PeerID) || m.inSector(peer.PeerID) {
+		regionScore = 1.0
+	}
 	score += regionScore * weights.Region
 
 	// 5. Freshness
 	freshnessScore := m.calculateFreshnessScore(peer.LastSeen)
 	score += freshnessScore * weights.Freshness
 
+	// 6. Peers saving battery or heat asked to be spared
+	if slices.Contains(peer.Capabilities, powerSavingCapability) {
+		score *= 0.5
+	}
+	// 7. Busy peers take work after idle ones
+	if slices.Contains(peer.Capabilities, busyCapability) {
+		score *= 0.7
+	}
+
 	return score
 }
 
@@ -961,16 +1830,7 @@ func (m *MeshCoordinator) calculateRegionScore(peerRegion string) float32 {
 	if peerRegion == "" {
 		return 0.5
 	}
-	if peerRegion == m.region {
-		return 1.0
-	}
-	if len(peerRegion) >= 2 && len(m.region) >= 2 && peerRegion[:2] == m.region[:2] {
-		return 0.7
-	}
-	if len(peerRegion) >= 1 && len(m.region) >= 1 && peerRegion[0] == m.region[0] {
-		return 0.4
-	}
-	return 0.1
+	return regionProximity(ParseRegion(m.region), ParseRegion(peerRegion))
 }
 
 func (m *MeshCoordinator) calculateFreshnessScore(lastSeen int64) float32 {
@@ -997,8 +1857,9 @@ func (m *MeshCoordinator) scorePeers(peers []PeerInfo) []PeerInfo {
 
 	scoredList := make([]scored, 0, len(peers))
 	for _, peer := range peers {
-		if peer.Capabilities != nil {
-			score := m.calculatePeerScore(peer.Capabilities)
+		if peer.Capabilities != nil && !m.isPeerQuarantined(peer.ID) &&
+			!slices.Contains(peer.Capabilities.Capabilities, noStorageCapability) {
+			score := m.sectorAffinity(peer.ID, m.calculatePeerScore(peer.Capabilities))
 			scoredList = append(scoredList, scored{peer: peer, score: score})
 		}
 	}
@@ -1018,37 +1879,98 @@ func (m *MeshCoordinator) scorePeers(peers []PeerInfo) []PeerInfo {
 // ========== HELPER METHODS ==========
 
 func (m *MeshCoordinator) sendChunkToPeer(ctx context.Context, peerID, chunkHash string, data []byte) error {
+	if framed, err := m.sendChunkFrameToPeer(ctx, peerID, chunkHash, data); framed {
+		if err == nil {
+			return nil
+		}
+		m.logger.Debug("chunk frame send failed, falling back to inline chunk.store",
+			"peer", getShortID(peerID),
+			"error", err,
+		)
+	}
+
+	return m.storeChunkOnPeer(ctx, peerID, chunkHash, "", data)
+}
+
+// chunkFrameSender is a transport that can send chunk bytes as a raw
+// binary frame outside the JSON envelope.
+type chunkFrameSender interface {
+	SendChunkFrame(ctx context.Context, peerID, chunkHash string, data []byte) error
+}
+
+// chunkFrameReceiver hands over chunk bodies received as binary frames.
+type chunkFrameReceiver interface {
+	TakeChunkFrame(peerID, chunkHash string) ([]byte, bool)
+}
+
+// sendChunkFrameToPeer stores a SAB-backed chunk on a peer by sending the
+// bytes straight from the shared buffer as a binary frame, followed by a
+// chunk.store that claims it. It reports false, without sending, when the
+// chunk is not in the SAB or the transport cannot send frames; the copying
+// path is only worth avoiding for data already in shared memory.
+func (m *MeshCoordinator) sendChunkFrameToPeer(ctx context.Context, peerID, chunkHash string, data []byte) (bool, error) {
+	if m.bridge == nil {
+		return false, nil
+	}
+	sender, ok := m.transport.(chunkFrameSender)
+	if !ok {
+		return false, nil
+	}
+	if _, ok := m.bridge.GetAddress(data); !ok {
+		return false, nil
+	}
+
+	if err := sender.SendChunkFrame(ctx, peerID, chunkHash, data); err != nil {
+		return true, err
+	}
+	req := ChunkStoreRequest{
+		ChunkHash:    chunkHash,
+		RawSize:      len(data),
+		WireSize:     len(data),
+		Framed:       true,
+		ACL:          m.chunkACLFor(chunkHash),
+		Announcement: m.replicaAnnouncementFor(ctx, chunkHash, len(data)),
+	}
+	var resp ChunkStoreResponse
+	if err := m.transport.SendRPC(ctx, peerID, chunkStoreMethod, req, &resp); err != nil {
+		return true, err
+	}
+	if resp.Rejected != nil {
+		return true, resp.Rejected
+	}
+	if !resp.Stored || (resp.Size > 0 && resp.Size != len(data)) {
+		return true, errors.New("peer did not store chunk frame")
+	}
+	return true, nil
+}
+
+// storeChunkOnPeer stores a replica via chunk.store and verifies the ack.
+// A non-empty txID stages the replica so chunk.abort can remove it.
+func (m *MeshCoordinator) storeChunkOnPeer(ctx context.Context, peerID, chunkHash, txID string, data []byte) error {
 	payload, err := m.encodePayloadForWire(data, meshCompressionMinBytes, meshBrotliCompressionLevel)
 	if err != nil {
 		return fmt.Errorf("failed to encode chunk payload: %w", err)
 	}
 
-	req := map[string]interface{}{
-		"chunk_hash":  chunkHash,
-		"data":        payload.Data,
-		"raw_size":    payload.RawSize,
-		"wire_size":   payload.WireSize,
-		"compression": payload.Compression,
+	req := ChunkStoreRequest{
+		ChunkHash:    chunkHash,
+		Data:         payload.Data,
+		RawSize:      payload.RawSize,
+		WireSize:     payload.WireSize,
+		Compression:  payload.Compression,
+		TxID:         txID,
+		ACL:          m.chunkACLFor(chunkHash),
+		Announcement: m.replicaAnnouncementFor(ctx, chunkHash, len(data)),
 	}
 
-	var resp struct {
-		Stored bool `json:"stored"`
-		Size   int  `json:"size"`
+	var resp ChunkStoreResponse
+	if err := m.transport.SendRPC(ctx, peerID, chunkStoreMethod, req, &resp); err != nil {
+		return fmt.Errorf("chunk.store RPC failed: %w", err)
 	}
 
-	if err := m.transport.SendRPC(ctx, peerID, "chunk.store", req, &resp); err != nil {
-		// Backward-compatible fallback for older peers.
-		m.logger.Debug("chunk.store RPC failed, falling back to legacy chunk_store payload",
-			"peer", getShortID(peerID),
-			"error", err,
-		)
-		return m.transport.SendMessage(ctx, peerID, map[string]interface{}{
-			"type":       "chunk_store",
-			"chunk_hash": chunkHash,
-			"data":       data,
-		})
+	if resp.Rejected != nil {
+		return resp.Rejected
 	}
-
 	if !resp.Stored {
 		return errors.New("peer rejected chunk.store")
 	}
@@ -1065,20 +1987,10 @@ func (m *MeshCoordinator) fetchFromPeer(ctx context.Context, chunkHash string, p
 		return nil, fmt.Errorf("circuit breaker open for peer %s", getShortID(peer.PeerID))
 	}
 
-	var result struct {
-		Data        []byte `json:"data"`
-		Size        int    `json:"size"`
-		RawSize     int    `json:"raw_size"`
-		WireSize    int    `json:"wire_size"`
-		Compression string `json:"compression"`
-	}
-
-	err := m.transport.SendRPC(ctx, peer.PeerID, "chunk.fetch", map[string]string{
-		"chunk_hash": chunkHash,
-	}, &result)
-
+	var result ChunkFetchResponse
+	err := m.transport.SendRPC(ctx, peer.PeerID, chunkFetchMethod, m.signChunkFetch(chunkHash), &result)
 	if err != nil {
-		m.recordRPCFailure(peer.PeerID, "chunk.fetch", err)
+		m.recordRPCFailure(peer.PeerID, chunkFetchMethod, err)
 		return nil, err
 	}
 
@@ -1170,11 +2082,14 @@ func (m *MeshCoordinator) isCircuitBreakerOpen(resource string) bool {
 }
 
 func (m *MeshCoordinator) isCircuitBreakerOpenForPeer(peerID string) bool {
-	return m.isCircuitBreakerOpen("peer:" + peerID)
+	return m.isPeerQuarantined(peerID) || m.isCircuitBreakerOpen("peer:"+peerID)
 }
 
 func (m *MeshCoordinator) updateCircuitBreaker(peerID string, success bool) {
 	resource := "peer:" + peerID
+	if success {
+		m.recordPeerUtility(peerID)
+	}
 
 	m.cbMu.Lock()
 	cb, exists := m.circuitBreakers[resource]
@@ -1200,6 +2115,8 @@ func (m *MeshCoordinator) updateCircuitBreaker(peerID string, success bool) {
 				cb.state = BreakerOpen
 				cb.lastFailure = time.Now()
 				m.logger.Warn("circuit breaker opened", "peer", getShortID(peerID))
+				m.recordBreakerTrip(peerID)
+				m.noteBreakerOpened(peerID)
 			}
 		} else {
 			cb.successes++
@@ -1222,11 +2139,14 @@ func (m *MeshCoordinator) updateCircuitBreaker(peerID string, success bool) {
 			if cb.successes >= m.config.CircuitBreaker.HalfOpenMax {
 				cb.state = BreakerClosed
 				m.logger.Info("circuit breaker closed", "peer", getShortID(peerID))
+				m.noteBreakerClosed(peerID)
 			}
 		} else {
 			cb.state = BreakerOpen
 			cb.lastFailure = time.Now()
 			m.logger.Warn("circuit breaker re-opened", "peer", getShortID(peerID))
+			m.recordBreakerTrip(peerID)
+			m.noteBreakerOpened(peerID)
 		}
 	}
 }
@@ -1263,6 +2183,9 @@ func (m *MeshCoordinator) getCachedPeer(peerID string) *PeerCapability {
 }
 
 func (m *MeshCoordinator) cachePeer(peerID string, capability *PeerCapability) {
+	if capability != nil {
+		capability.NormalizeSchema()
+	}
 	m.peerCacheMu.Lock()
 	m.peerCache[peerID] = PeerCacheEntry{
 		Capability:  capability,
@@ -1302,7 +2225,10 @@ func (m *MeshCoordinator) metricsLoop() {
 		select {
 		case <-ticker.C:
 			m.updateMetrics()
-			m.gossipMetrics()
+			m.expirePeerMetrics(time.Now())
+			if !m.deferLowPriority() {
+				m.gossipMetrics()
+			}
 		case <-m.shutdown:
 			return
 		}
@@ -1310,14 +2236,21 @@ func (m *MeshCoordinator) metricsLoop() {
 }
 
 func (m *MeshCoordinator) updateMetrics() {
+	sector := m.sector.Load()
+	sectorMembers := uint32(len(m.sectorMembers()[sector])) + 1
+
 	m.metricsMu.Lock()
-	defer m.metricsMu.Unlock()
 
+	// Synthetic peers never count toward metrics gossiped to the mesh.
 	m.metrics.TotalPeers = m.dht.TotalPeers()
+	if synthetic := uint32(m.syntheticPeerCount()); synthetic <= m.metrics.TotalPeers {
+		m.metrics.TotalPeers -= synthetic
+	}
 	m.metrics.DHTEntries = m.dht.GetEntryCount()
 	m.metrics.AvgReputation = float32(m.reputation.GetAverageScore())
 	m.metrics.GossipRatePerSec = m.gossip.GetMessageRate()
 	m.metrics.RegionID = crc32.ChecksumIEEE([]byte(m.region))
+	m.metrics.SectorID = sector
 
 	connMetrics := m.transport.GetConnectionMetrics()
 	m.metrics.ConnectedPeers = connMetrics.ActiveConnections
@@ -1332,31 +2265,46 @@ func (m *MeshCoordinator) updateMetrics() {
 
 	m.metrics.TotalChunksAvailable = m.dht.GetTotalChunksCount()
 
-	// Zero-Copy Bridge: Write to SAB
+	snapshot := m.metrics
+	m.metricsMu.Unlock()
+
+	// Packing for the host waits for an idle window so it never lands mid-frame
 	if m.bridge != nil {
-		buf := make([]byte, 256) // Matches SIZE_MESH_METRICS
+		m.RunWhenIdle(idleTaskMetricsPack, func() {
+			m.packMetrics(snapshot, sectorMembers)
+		})
+	}
+}
 
-		// Pack metrics into binary format (compatible with JS views)
-		binary.LittleEndian.PutUint32(buf[0:], m.metrics.TotalPeers)
-		binary.LittleEndian.PutUint32(buf[4:], m.metrics.ConnectedPeers)
-		binary.LittleEndian.PutUint32(buf[8:], m.metrics.DHTEntries)
-		binary.LittleEndian.PutUint32(buf[12:], *(*uint32)(unsafe.Pointer(&m.metrics.GossipRatePerSec)))
-		binary.LittleEndian.PutUint32(buf[16:], *(*uint32)(unsafe.Pointer(&m.metrics.AvgReputation)))
-		binary.LittleEndian.PutUint32(buf[20:], m.metrics.RegionID)
-		binary.LittleEndian.PutUint64(buf[24:], m.metrics.BytesSent)
-		binary.LittleEndian.PutUint64(buf[32:], m.metrics.BytesReceived)
-		binary.LittleEndian.PutUint32(buf[40:], *(*uint32)(unsafe.Pointer(&m.metrics.P50LatencyMs)))
-		binary.LittleEndian.PutUint32(buf[44:], *(*uint32)(unsafe.Pointer(&m.metrics.P95LatencyMs)))
-		binary.LittleEndian.PutUint32(buf[48:], *(*uint32)(unsafe.Pointer(&m.metrics.ConnectionSuccessRate)))
-		binary.LittleEndian.PutUint32(buf[52:], *(*uint32)(unsafe.Pointer(&m.metrics.ChunkFetchSuccessRate)))
-		binary.LittleEndian.PutUint32(buf[56:], m.metrics.LocalChunks)
-		binary.LittleEndian.PutUint32(buf[60:], m.metrics.TotalChunksAvailable)
+// packMetrics writes metrics to the SAB for the host.
+func (m *MeshCoordinator) packMetrics(metrics common.MeshMetrics, sectorMembers uint32) {
+	if m.bridge == nil {
+		return
+	}
+	buf := sab.EncodeMeshMetrics(sab.MeshMetricsRecord{
+		TotalPeers:            metrics.TotalPeers,
+		ConnectedPeers:        metrics.ConnectedPeers,
+		DHTEntries:            metrics.DHTEntries,
+		GossipRatePerSec:      metrics.GossipRatePerSec,
+		AvgReputation:         metrics.AvgReputation,
+		RegionID:              metrics.RegionID,
+		BytesSent:             metrics.BytesSent,
+		BytesReceived:         metrics.BytesReceived,
+		P50LatencyMs:          metrics.P50LatencyMs,
+		P95LatencyMs:          metrics.P95LatencyMs,
+		ConnectionSuccessRate: metrics.ConnectionSuccessRate,
+		ChunkFetchSuccessRate: metrics.ChunkFetchSuccessRate,
+		LocalChunks:           metrics.LocalChunks,
+		TotalChunksAvailable:  metrics.TotalChunksAvailable,
+		SectorID:              metrics.SectorID,
+		SectorMembers:         sectorMembers,
+	})
 
-		if err := m.bridge.WriteRaw(sab.OFFSET_MESH_METRICS, buf); err == nil {
-			m.bridge.SignalEpoch(sab.IDX_METRICS_EPOCH)
-			m.bridge.SignalEpoch(sab.IDX_SYSTEM_EPOCH) // Heartbeat for analytics and other watchers
-		}
+	if err := m.bridge.WriteRaw(sab.OFFSET_MESH_METRICS, buf); err == nil {
+		m.bridge.SignalEpoch(sab.IDX_METRICS_EPOCH)
+		m.bridge.SignalEpoch(sab.IDX_SYSTEM_EPOCH) // Heartbeat for analytics and other watchers
 	}
+	m.packHealthBlock()
 }
 
 func (m *MeshCoordinator) healthLoop() {
@@ -1377,6 +2325,7 @@ func (m *MeshCoordinator) performHealthChecks() {
 	if !m.gossip.IsHealthy() {
 		m.logger.Warn("gossip health check failed")
 	}
+	m.maybePromoteDHT()
 }
 
 func (m *MeshCoordinator) cacheCleanupLoop() {
@@ -1386,7 +2335,7 @@ func (m *MeshCoordinator) cacheCleanupLoop() {
 	for {
 		select {
 		case <-ticker.C:
-			m.cleanupExpiredCache()
+			m.RunWhenIdle(idleTaskCacheCleanup, m.cleanupExpiredCache)
 		case <-m.shutdown:
 			return
 		}
@@ -1415,7 +2364,7 @@ func (m *MeshCoordinator) registerGossipHandlers() {
 		}
 
 		if chunkHash, ok := payload["chunk_hash"].(string); ok {
-			m.dht.Store(chunkHash, msg.Sender, 1800)
+			m.storeChunkRecord(chunkHash, msg.Sender)
 		}
 		return nil
 	})
@@ -1437,30 +2386,11 @@ func (m *MeshCoordinator) registerGossipHandlers() {
 		}
 
 		m.cachePeer(capability.PeerID, &capability)
+		m.dht.SetPeerMode(capability.PeerID, routing.DHTModeFromCapabilities(capability.Capabilities))
 		return nil
 	})
 
-	m.gossip.RegisterHandler("mesh_metrics", func(msg *common.GossipMessage) error {
-		payload, ok := msg.Payload.(map[string]interface{})
-		if !ok {
-			return errors.New("invalid payload type for mesh_metrics")
-		}
-
-		data, err := json.Marshal(payload)
-		if err != nil {
-			return err
-		}
-
-		var peerMetrics common.MeshMetrics
-		if err := json.Unmarshal(data, &peerMetrics); err != nil {
-			return err
-		}
-
-		m.peerMetricsMu.Lock()
-		m.peerMetrics[msg.Sender] = peerMetrics
-		m.peerMetricsMu.Unlock()
-		return nil
-	})
+	m.gossip.RegisterHandler("mesh_metrics", m.handleMeshMetrics)
 
 	m.gossip.RegisterHandler("webrtc.signaling", func(msg *common.GossipMessage) error {
 		if m.gossipSignaling == nil {
@@ -1480,6 +2410,15 @@ func (m *MeshCoordinator) registerGossipHandlers() {
 		m.gossipSignaling.HandleIncoming(msg.Payload)
 		return nil
 	})
+
+	m.registerWorkQueueGossip()
+	m.registerDepartureGossip()
+	m.registerManifestGossip()
+	m.gossip.RegisterHandler(chunkAccessDeniedTopic, m.handleChunkAccessDenied)
+	m.registerKeyRotationGossip()
+	m.registerPubSubGossip()
+	m.registerTimeSyncGossip()
+	m.registerModuleGossip()
 }
 
 // ========== METRICS RECORDING ==========
@@ -1530,7 +2469,8 @@ func (m *MeshCoordinator) ReportComputeActivity(ops float64, gflops float64) {
 	m.metrics.TotalComputeGFLOPS = float32(gflops)
 }
 
-// GetGlobalMetrics aggregates local and peer metrics
+// GetGlobalMetrics aggregates local and peer metrics. Peer-reported totals
+// are bounded and outlier-resistant; see aggregateGlobalMetrics.
 func (m *MeshCoordinator) GetGlobalMetrics() common.MeshMetrics {
 	m.metricsMu.RLock()
 	local := m.metrics
@@ -1540,15 +2480,26 @@ func (m *MeshCoordinator) GetGlobalMetrics() common.MeshMetrics {
 	global.ActiveNodeCount = 1 // Start with self
 
 	m.peerMetricsMu.RLock()
-	defer m.peerMetricsMu.RUnlock()
+	peers := make(map[string]common.MeshMetrics, len(m.peerMetrics))
+	for peerID, pm := range m.peerMetrics {
+		peers[peerID] = pm
+	}
+	m.peerMetricsMu.RUnlock()
 
-	for _, pm := range m.peerMetrics {
-		global.TotalStorageBytes += pm.TotalStorageBytes
-		global.TotalComputeGFLOPS += pm.TotalComputeGFLOPS
-		global.GlobalOpsPerSec += pm.GlobalOpsPerSec
+	samples := make([]metricsSample, 0, len(peers)+1)
+	samples = append(samples, peerMetricsSample(m.nodeID, local, "", 1))
+	for peerID, pm := range peers {
+		samples = append(samples, peerMetricsSample(peerID, pm, m.metricsClass(peerID), m.metricsWeight(peerID)))
 		global.ActiveNodeCount++
+		if pm.Synthetic {
+			global.SyntheticNodeCount++
+		}
 	}
 
+	gflops, ops, storage := m.aggregateGlobalMetrics(samples)
+	global.TotalComputeGFLOPS = float32(gflops)
+	global.GlobalOpsPerSec = float32(ops)
+	global.TotalStorageBytes = storage
 	return global
 }
 
@@ -1557,7 +2508,12 @@ func (m *MeshCoordinator) gossipMetrics() {
 	metrics := m.metrics
 	m.metricsMu.RUnlock()
 
-	m.gossip.Broadcast("mesh_metrics", metrics)
+	signed, err := m.signMeshMetrics(metrics)
+	if err != nil {
+		m.logger.Warn("failed to sign metrics", "error", err)
+		return
+	}
+	m.gossip.Broadcast("mesh_metrics", signed)
 }
 
 // SetDispatcher injects the dispatcher for remote job execution
@@ -1565,11 +2521,46 @@ func (m *MeshCoordinator) SetDispatcher(d foundation.Dispatcher) {
 	m.dispatcher = d
 }
 
-// DelegateJob dispatches a job to the most suitable peer in the mesh
+// DelegateJob dispatches a job to the mesh. Idle peers pull it from the shared
+// work queue first; the best-scoring peer is used only when nobody claims it.
 func (m *MeshCoordinator) DelegateJob(ctx context.Context, job *foundation.Job) (*foundation.Result, error) {
-	// 1. Find suitable peers (those with required capabilities)
-	bestPeer, bestScore := m.selectBestPeerForJob()
+	ctx, span := tracing.Start(traceContext(ctx, job.TraceParent), "mesh.delegate_job", tracing.SpanKindClient)
+	span.SetAttribute("job.id", job.ID)
+	span.SetAttribute("job.operation", job.Operation)
+	job.TraceParent = span.Traceparent()
+
+	result, err := m.delegateJob(ctx, job)
+	if err == nil && result != nil && !result.Success {
+		span.SetStatus(tracing.StatusError, result.Error)
+	}
+	span.SetError(err)
+	span.End()
+	return result, err
+}
+
+func (m *MeshCoordinator) delegateJob(ctx context.Context, job *foundation.Job) (*foundation.Result, error) {
+	requirements := JobWASMRequirements(job).merge(wasmRequirementsFromContext(ctx))
+
+	if m.config.WorkQueue.Enabled && m.gossip != nil && m.gossip.TotalPeers() > 0 {
+		result, err := m.submitWork(ctx, job, requirements)
+		if err == nil {
+			if m.bridge != nil {
+				m.bridge.SignalEpoch(sab.IDX_DELEGATED_JOB_EPOCH)
+				m.bridge.SignalEpoch(sab.IDX_OUTBOX_HOST_DIRTY)
+			}
+			return result, nil
+		}
+		if !errors.Is(err, ErrNoWorkClaimed) {
+			return nil, err
+		}
+		m.logger.Debug("no peer pulled job, falling back to direct delegation", "job_id", job.ID)
+	}
 
+	// 1. Find suitable peers (those with required capabilities)
+	bestPeer, bestScore, err := m.selectPeerForRequirements(ctx, requirements)
+	if err != nil {
+		return nil, err
+	}
 	if bestPeer == "" {
 		return nil, errors.New("no suitable peers found for delegation (peer metrics empty)")
 	}
@@ -1581,13 +2572,21 @@ func (m *MeshCoordinator) DelegateJob(ctx context.Context, job *foundation.Job)
 	defer m.decrementActiveJobs(bestPeer)
 
 	// 3. Dispatch via RPC
+	rpcCtx, cancel := jobRPCContext(ctx, job)
+	defer cancel()
+	rpcCtx = m.capabilityContext(rpcCtx, bestPeer, executeJobMethod)
+	request := toWorkJob(job)
+	request.IdempotencyKey = idempotencyKeyFromContext(ctx)
 	var result foundation.Result
-	err := m.transport.SendRPC(ctx, bestPeer, "mesh.ExecuteJob", job, &result)
+	err = m.transport.SendRPC(rpcCtx, bestPeer, executeJobMethod, request, &result)
 	if err != nil {
+		m.dropHeldCapability(bestPeer, err)
 		m.logger.Error("mesh delegation failed", "job_id", job.ID, "peer", getShortID(bestPeer), "error", err)
 		return nil, fmt.Errorf("mesh delegation failed to peer %s: %w", bestPeer, err)
 	}
 
+	m.recordResultGPUTiming(bestPeer, &result)
+
 	// 4. Signal delegation completion for observers
 	if m.bridge != nil {
 		m.bridge.SignalEpoch(sab.IDX_DELEGATED_JOB_EPOCH)
@@ -1599,9 +2598,23 @@ func (m *MeshCoordinator) DelegateJob(ctx context.Context, job *foundation.Job)
 
 // DelegateCompute offloads a compute operation to the mesh with integrity verification
 func (m *MeshCoordinator) DelegateCompute(ctx context.Context, operation string, inputDigest string, data []byte) ([]byte, error) {
-	// 1. Find suitable peer
-	bestPeer, _ := m.selectBestPeerForJob()
+	ctx, span := tracing.Start(ctx, "mesh.delegate_compute", tracing.SpanKindClient)
+	span.SetAttribute("delegation.operation", operation)
+	span.SetAttribute("delegation.input_bytes", len(data))
 
+	result, err := m.delegateCompute(ctx, operation, inputDigest, data)
+	span.SetError(err)
+	span.End()
+	return result, err
+}
+
+func (m *MeshCoordinator) delegateCompute(ctx context.Context, operation string, inputDigest string, data []byte) ([]byte, error) {
+	// 1. Find suitable peer
+	requirements := wasmRequirementsFromContext(ctx).merge(WASMRequirements{GPU: jobGPURequirements(operation, nil)})
+	bestPeer, _, err := m.selectPeerForRequirements(ctx, requirements)
+	if err != nil {
+		return nil, err
+	}
 	if bestPeer == "" {
 		return nil, errors.New("no suitable peers found for compute delegation")
 	}
@@ -1612,9 +2625,15 @@ func (m *MeshCoordinator) DelegateCompute(ctx context.Context, operation string,
 
 	// 2. Prepare request
 	req := DelegateRequest{
-		ID:        fmt.Sprintf("deleg_%d", time.Now().UnixNano()),
-		Operation: operation,
+		ID:             fmt.Sprintf("deleg_%d", time.Now().UnixNano()),
+		Operation:      operation,
+		Module:         moduleFromContext(ctx),
+		IdempotencyKey: idempotencyKeyFromContext(ctx),
 	}
+	if err := validateIdempotencyKey(req.IdempotencyKey); err != nil {
+		return nil, err
+	}
+	m.stampDelegationEnvelope(ctx, &req)
 
 	// Create Resource payload
 	resBytes, err := m.packResource(req.ID, inputDigest, data)
@@ -1622,23 +2641,45 @@ func (m *MeshCoordinator) DelegateCompute(ctx context.Context, operation string,
 		return nil, fmt.Errorf("failed to pack resource: %w", err)
 	}
 	req.Resource = resBytes
+	if err := m.signDelegationRequest(&req); err != nil {
+		return nil, err
+	}
+	req.TraceParent = tracing.TraceparentFromContext(ctx)
+	tracing.SpanFromContext(ctx).SetAttribute("peer", getShortID(bestPeer))
 	m.emitDelegationRequestEvent(operation, req.ID, []byte(inputDigest), uint32(len(data)))
 
 	// 3. Dispatch via RPC
 	var resp DelegationResponse
-	err = m.transport.SendRPC(ctx, bestPeer, "mesh.DelegateCompute", req, &resp)
+	err = m.transport.SendRPC(m.capabilityContext(ctx, bestPeer, delegateComputeMethod), bestPeer, delegateComputeMethod, req, &resp)
 	if err != nil {
+		m.dropHeldCapability(bestPeer, err)
 		m.updateCircuitBreaker(bestPeer, false)
 		return nil, fmt.Errorf("compute delegation RPC failed: %w", err)
 	}
+	if err := m.verifyDelegationResponse(&req, &resp); err != nil {
+		m.updateCircuitBreaker(bestPeer, false)
+		return nil, fmt.Errorf("compute delegation response rejected: %w", err)
+	}
+	if err := m.checkDelegationReceipt(&req, &resp, data); err != nil {
+		m.updateCircuitBreaker(bestPeer, false)
+		return nil, fmt.Errorf("compute delegation receipt rejected: %w", err)
+	}
+	m.recordDelegation(DelegationRoleRequester, &req, &resp)
+	m.storeDelegationReceipt(resp.Receipt)
 
 	if resp.Status == "input_missing" {
 		m.emitDelegationResponseEvent(p2p.DelegateResponse_Status_inputMissing, req.ID, []byte(inputDigest), 0, resp.LatencyMs, resp.Error)
 		return nil, errors.New("remote peer missing input chunk")
 	}
+	if resp.Status == "in_progress" {
+		return nil, fmt.Errorf("compute delegation to %s: %w", getShortID(bestPeer), ErrRequestInProgress)
+	}
 
 	if resp.Status != "success" {
 		m.emitDelegationResponseEvent(p2p.DelegateResponse_Status_failed, req.ID, []byte(inputDigest), 0, resp.LatencyMs, resp.Error)
+		if exceeded, ok := ParseResourceExceeded(resp.Error); ok {
+			return nil, fmt.Errorf("compute delegation failed: %w", exceeded)
+		}
 		return nil, fmt.Errorf("compute delegation failed: %s", resp.Error)
 	}
 
@@ -1663,12 +2704,20 @@ func (m *MeshCoordinator) DelegateCompute(ctx context.Context, operation string,
 		m.updateCircuitBreaker(bestPeer, false)
 		return nil, errors.New("delegation response missing digest")
 	}
+	done := m.trackDelegation()
 	computedDigest := m.computeResourceDigest(resultData)
+	done()
 	if string(digest) != computedDigest {
 		m.updateCircuitBreaker(bestPeer, false)
 		return nil, fmt.Errorf("delegation digest mismatch: expected=%s computed=%s", string(digest), computedDigest)
 	}
+	// The stored receipt now proves the executor signed for other output
+	if resp.Receipt != nil && resp.Receipt.OutputDigest != computedDigest {
+		m.updateCircuitBreaker(bestPeer, false)
+		return nil, fmt.Errorf("delegation receipt output mismatch: receipt=%s computed=%s", resp.Receipt.OutputDigest, computedDigest)
+	}
 
+	m.recordGPUTiming(bestPeer, time.Duration(resp.GPUTimeMs*float32(time.Millisecond)))
 	m.logger.Info("compute delegation successful", "peer", getShortID(bestPeer), "latency", resp.LatencyMs)
 	m.updateCircuitBreaker(bestPeer, true)
 	m.emitDelegationResponseEvent(p2p.DelegateResponse_Status_success, req.ID, digest, res.RawSize(), resp.LatencyMs, "")
@@ -1678,24 +2727,44 @@ func (m *MeshCoordinator) DelegateCompute(ctx context.Context, operation string,
 
 func (m *MeshCoordinator) registerRPCHandlers() {
 	m.registerAttestationHandler()
-	m.transport.RegisterRPCHandler("chunk.store", func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error) {
+	m.registerBandwidthProbeHandler()
+	m.registerFeatureProbeHandler()
+	m.registerWorkQueueHandlers()
+	m.registerDepartureHandler()
+	m.registerServiceHandler()
+	m.registerStorageProofHandler()
+	m.registerCapabilityHandler()
+	m.registerPublishHandlers()
+	m.registerPubSubHandler()
+	m.registerTimeSyncHandler()
+	m.registerLatencyProbeHandler()
+	m.registerDisputeHandler()
+	m.registerRPC(chunkStoreMethod, func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error) {
 		if m.storage == nil {
 			return nil, errors.New("storage provider not configured")
 		}
-
-		var req struct {
-			ChunkHash   string `json:"chunk_hash"`
-			Data        []byte `json:"data"`
-			RawSize     int    `json:"raw_size"`
-			WireSize    int    `json:"wire_size"`
-			Compression string `json:"compression"`
+		if err := m.admitProfileStorage(); err != nil {
+			return nil, err
 		}
+
+		var req ChunkStoreRequest
 		if err := json.Unmarshal(args, &req); err != nil {
 			return nil, fmt.Errorf("failed to decode chunk.store request: %w", err)
 		}
 		if req.ChunkHash == "" {
 			return nil, errors.New("missing chunk_hash")
 		}
+		if req.Framed {
+			receiver, ok := m.transport.(chunkFrameReceiver)
+			if !ok {
+				return nil, errors.New("chunk frames not supported")
+			}
+			body, ok := receiver.TakeChunkFrame(peerID, req.ChunkHash)
+			if !ok {
+				return nil, errors.New("chunk frame not received")
+			}
+			req.Data, req.Compression = body, ""
+		}
 
 		expectedRawSize := req.RawSize
 		if expectedRawSize == 0 {
@@ -1706,16 +2775,42 @@ func (m *MeshCoordinator) registerRPCHandlers() {
 			return nil, fmt.Errorf("failed to decode chunk.store payload: %w", err)
 		}
 
+		m.localChunksMu.RLock()
+		_, held := m.localChunks[req.ChunkHash]
+		m.localChunksMu.RUnlock()
+		if !held {
+			if rejection := m.admitInboundChunk(peerID, req.ChunkHash, req.Announcement, len(decoded)); rejection != nil {
+				m.rejectInboundChunk(ctx, peerID, req.ChunkHash, rejection)
+				return ChunkStoreResponse{Rejected: rejection}, nil
+			}
+		}
+
+		if req.ACL != nil {
+			if err := m.acceptChunkACL(req.ChunkHash, req.ACL); err != nil {
+				m.releaseInboundChunk(req.ChunkHash)
+				return nil, fmt.Errorf("chunk ACL rejected: %w", err)
+			}
+		}
+		if req.TxID != "" {
+			m.stageReplica(ctx, peerID, req.TxID, req.ChunkHash)
+		}
 		if err := m.storage.StoreChunk(ctx, req.ChunkHash, decoded); err != nil {
+			m.releaseInboundChunk(req.ChunkHash)
 			return nil, fmt.Errorf("failed to store chunk: %w", err)
 		}
+		m.trackStoredChunk(ctx, req.ChunkHash, len(decoded))
+		m.publishEvent(MeshEventChunkStored, peerID, map[string]interface{}{
+			"chunk_hash": req.ChunkHash,
+			"size":       len(decoded),
+		})
 
 		m.localChunksMu.Lock()
 		m.localChunks[req.ChunkHash] = struct{}{}
 		m.localChunksMu.Unlock()
-		_ = m.dht.Store(req.ChunkHash, m.nodeID, 3600)
+		_ = m.storeChunkRecord(req.ChunkHash, m.nodeID)
 
-		m.logger.Debug("stored chunk from peer",
+		m.recordNamespaceUsage(ctx, len(decoded))
+		m.rpcLogger(ctx).Debug("stored chunk from peer",
 			"peer", getShortID(peerID),
 			"chunk", getShortID(req.ChunkHash),
 			"raw_size", len(decoded),
@@ -1723,29 +2818,30 @@ func (m *MeshCoordinator) registerRPCHandlers() {
 			"compression", req.Compression,
 		)
 
-		return map[string]interface{}{
-			"stored":      true,
-			"size":        len(decoded),
-			"raw_size":    len(decoded),
-			"wire_size":   len(req.Data),
-			"compression": req.Compression,
+		return ChunkStoreResponse{
+			Stored:      true,
+			Size:        len(decoded),
+			RawSize:     len(decoded),
+			WireSize:    len(req.Data),
+			Compression: req.Compression,
 		}, nil
 	})
 
-	m.transport.RegisterRPCHandler("chunk.fetch", func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error) {
+	m.registerRPC(chunkFetchMethod, func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error) {
 		if m.storage == nil {
 			return nil, errors.New("storage provider not configured")
 		}
 
-		var req struct {
-			ChunkHash string `json:"chunk_hash"`
-		}
+		var req ChunkFetchRequest
 		if err := json.Unmarshal(args, &req); err != nil {
 			return nil, fmt.Errorf("failed to decode chunk.fetch request: %w", err)
 		}
 		if req.ChunkHash == "" {
 			return nil, errors.New("missing chunk_hash")
 		}
+		if reason, did := m.authorizeChunkFetch(peerID, &req); reason != "" {
+			return nil, m.denyChunkFetch(ctx, peerID, req.ChunkHash, reason, did)
+		}
 
 		has, err := m.storage.HasChunk(ctx, req.ChunkHash)
 		if err != nil {
@@ -1765,7 +2861,11 @@ func (m *MeshCoordinator) registerRPCHandlers() {
 			return nil, fmt.Errorf("failed to encode chunk.fetch payload: %w", err)
 		}
 
-		m.logger.Debug("served chunk to peer",
+		// Served fetches drive the chunk's demand and so its record TTL
+		m.demandTracker.RecordAccess(req.ChunkHash)
+		m.touchStoredChunk(req.ChunkHash)
+		m.recordNamespaceUsage(ctx, len(data))
+		m.rpcLogger(ctx).Debug("served chunk to peer",
 			"peer", getShortID(peerID),
 			"chunk", getShortID(req.ChunkHash),
 			"raw_size", len(data),
@@ -1773,119 +2873,280 @@ func (m *MeshCoordinator) registerRPCHandlers() {
 			"compression", payload.Compression,
 		)
 
-		return map[string]interface{}{
-			"data":        payload.Data,
-			"size":        len(data),
-			"raw_size":    payload.RawSize,
-			"wire_size":   payload.WireSize,
-			"compression": payload.Compression,
+		return ChunkFetchResponse{
+			Data:        payload.Data,
+			Size:        len(data),
+			RawSize:     payload.RawSize,
+			WireSize:    payload.WireSize,
+			Compression: payload.Compression,
 		}, nil
 	})
 
-	m.transport.RegisterRPCHandler("mesh.DelegateCompute", func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error) {
+	m.registerRPC(delegateComputeMethod, func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error) {
 		if m.dispatcher == nil {
 			return nil, errors.New("local dispatcher not initialized")
 		}
+		if m.departing.Load() {
+			return nil, ErrDeparting
+		}
+		if err := m.admitRemoteCompute(); err != nil {
+			return nil, err
+		}
 
 		var req DelegateRequest
 		if err := json.Unmarshal(args, &req); err != nil {
 			return nil, fmt.Errorf("failed to unmarshal delegation request: %w", err)
 		}
 
-		m.logger.Debug("received delegation request", "operation", req.Operation, "from_peer", getShortID(peerID))
-
-		// 1. Unpack Resource
-		res, err := m.unpackResource(req.Resource)
+		if budget, ok := remainingBudget(ctx); ok && budget <= 0 {
+			return nil, errors.New("delegation deadline already passed")
+		}
+		ctx, cancel, err := m.delegationEnvelopeContext(ctx, &req)
 		if err != nil {
-			return nil, fmt.Errorf("failed to unpack resource: %w", err)
+			return nil, err
 		}
+		defer cancel()
+		if err := m.verifyDelegationRequest(&req); err != nil {
+			m.rejectDelegation(peerID, err)
+			return nil, err
+		}
+		m.rpcLogger(ctx).Debug("received delegation request", "operation", req.Operation, "from_peer", getShortID(peerID))
 
-		inputDigest, _ := res.Digest()
-		var data []byte
+		if err := validateIdempotencyKey(req.IdempotencyKey); err != nil {
+			return nil, err
+		}
 
-		// 2. Resolve data from Resource (SABRef > Storage)
-		if res.Which() == system.Resource_Which_sabRef && m.bridge != nil {
-			ref, _ := res.SabRef()
-			m.logger.Debug("resolved input via sabRef", "offset", ref.Offset(), "size", ref.Size())
-			data, err = m.bridge.ReadRaw(ref.Offset(), ref.Size())
-			if err != nil {
-				return nil, fmt.Errorf("failed to read from sabRef: %w", err)
-			}
-			data, err = m.decodePayloadFromWire(data, resourceCompressionToString(res.Compression()), int(res.RawSize()))
-			if err != nil {
-				return nil, fmt.Errorf("failed to decode sabRef resource payload: %w", err)
-			}
-		} else if res.Which() == system.Resource_Which_inline {
-			wirePayload, err := res.Inline()
-			if err != nil {
-				return nil, fmt.Errorf("failed to read inline resource: %w", err)
-			}
-			data, err = m.decodePayloadFromWire(wirePayload, resourceCompressionToString(res.Compression()), int(res.RawSize()))
-			if err != nil {
-				return nil, fmt.Errorf("failed to decode inline resource payload: %w", err)
-			}
-		} else if m.storage != nil {
-			if len(inputDigest) == 0 {
-				return DelegationResponse{Status: "input_missing"}, nil
-			}
-			// Check if we have the input chunk
-			has, err := m.storage.HasChunk(ctx, string(inputDigest))
-			if err != nil || !has {
-				return DelegationResponse{Status: "input_missing"}, nil
+		value, replayed, err := m.runIdempotent(ctx, delegateComputeMethod, peerID, req.IdempotencyKey, func() (interface{}, error) {
+			ctx, span := tracing.Start(traceContext(ctx, req.TraceParent), "mesh.delegate_compute.execute", tracing.SpanKindInternal)
+			span.SetAttribute("delegation.id", req.ID)
+			span.SetAttribute("delegation.operation", req.Operation)
+			resp, err := m.executeDelegation(ctx, &req)
+			if err == nil && resp.Status != "success" {
+				span.SetStatus(tracing.StatusError, resp.Status)
 			}
+			span.SetError(err)
+			span.End()
+			return resp, err
+		})
+		var resp DelegationResponse
+		switch {
+		case errors.Is(err, ErrRequestInProgress):
+			resp = DelegationResponse{Status: "in_progress", Error: err.Error()}
+		case err != nil:
+			return nil, err
+		default:
+			resp = value.(DelegationResponse)
+			resp.Replayed = replayed
+		}
+		// Replays are signed afresh: the response signature covers this
+		// request's signature, not the first one's
+		if err := m.signDelegationResponse(&req, &resp); err != nil {
+			return nil, err
+		}
+		if replayed {
+			m.rpcLogger(ctx).Debug("replayed delegation response", "id", req.ID, "from_peer", getShortID(peerID))
+		} else if resp.Status != "in_progress" {
+			m.recordDelegation(DelegationRoleExecutor, &req, &resp)
+		}
+		return resp, nil
+	})
 
-			// Fetch data
-			data, err = m.storage.FetchChunk(ctx, string(inputDigest))
-			if err != nil {
-				return nil, fmt.Errorf("failed to fetch input chunk: %w", err)
-			}
-		} else {
-			return nil, errors.New("no data source available (storage/bridge missing)")
+	m.registerRPC(executeJobMethod, func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error) {
+		if m.dispatcher == nil {
+			return nil, errors.New("local dispatcher not initialized")
 		}
 
-		// 4. Execute locally
-		job := &foundation.Job{
-			ID:        req.ID,
-			Operation: req.Operation,
-			Data:      data,
-			Priority:  100, // Default priority for delegated tasks
+		var job foundation.Job
+		if err := json.Unmarshal(args, &job); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal job: %w", err)
+		}
+		var keyed struct {
+			IdempotencyKey string `json:"idempotency_key"`
+		}
+		_ = json.Unmarshal(args, &keyed)
+		if err := validateIdempotencyKey(keyed.IdempotencyKey); err != nil {
+			return nil, err
 		}
 
-		result := m.dispatcher.ExecuteJob(job)
-		if !result.Success {
-			return DelegationResponse{Status: "failed", Error: result.Error}, nil
+		if budget, ok := remainingBudget(ctx); ok && budget <= 0 {
+			return nil, errors.New("job deadline already passed")
+		}
+		if m.departing.Load() {
+			return nil, ErrDeparting
+		}
+		if err := m.admitRemoteCompute(); err != nil {
+			return nil, err
 		}
+		if err := m.checkLocalFeatures(JobWASMRequirements(&job)); err != nil {
+			return nil, err
+		}
+		if err := m.jobModule(ctx, &job); err != nil {
+			return nil, err
+		}
+		applyRPCScheduling(ctx, &job, 100)
+		m.recordNamespaceUsage(ctx, len(job.Data))
+		m.rpcLogger(ctx).Debug("executing remote job", "job_id", job.ID, "from_peer", getShortID(peerID), "priority", job.Priority)
+
+		// A retry with the same key gets the first run's result, failed or
+		// not; only errors that kept the job from running are retried
+		value, replayed, err := m.runIdempotent(ctx, executeJobMethod, peerID, keyed.IdempotencyKey, func() (interface{}, error) {
+			_, span := tracing.Start(traceContext(ctx, job.TraceParent), "mesh.execute_job", tracing.SpanKindInternal)
+			span.SetAttribute("job.id", job.ID)
+			job.TraceParent = span.Traceparent()
 
-		// 5. Pack Result with content-address digest
-		outputDigest := m.computeResourceDigest(result.Data)
-		resOutBytes, err := m.packResource(req.ID, outputDigest, result.Data)
+			// Execute locally, inside the sandbox limits
+			result, err := m.runSandboxed(ctx, peerID, &job)
+			var exceeded *ResourceExceededError
+			if errors.As(err, &exceeded) {
+				result = &foundation.Result{JobID: job.ID, Error: exceeded.wire()}
+			} else if err != nil {
+				span.SetError(err)
+				span.End()
+				return nil, err
+			}
+			if !result.Success {
+				span.SetStatus(tracing.StatusError, result.Error)
+			}
+			span.End()
+			return result, nil
+		})
 		if err != nil {
-			return nil, fmt.Errorf("failed to pack result resource: %w", err)
+			return nil, err
 		}
-
-		return DelegationResponse{
-			Status:    "success",
-			Resource:  resOutBytes,
-			LatencyMs: float32(result.Latency),
-		}, nil
+		if replayed {
+			m.rpcLogger(ctx).Debug("replayed remote job result", "job_id", job.ID, "from_peer", getShortID(peerID))
+		}
+		return value, nil
 	})
+}
 
-	m.transport.RegisterRPCHandler("mesh.ExecuteJob", func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error) {
-		if m.dispatcher == nil {
-			return nil, errors.New("local dispatcher not initialized")
-		}
+// executeDelegation resolves a delegated job's input and runs it locally.
+func (m *MeshCoordinator) executeDelegation(ctx context.Context, req *DelegateRequest) (DelegationResponse, error) {
+	// 1. Unpack Resource
+	res, err := m.unpackResource(req.Resource)
+	if err != nil {
+		return DelegationResponse{}, fmt.Errorf("failed to unpack resource: %w", err)
+	}
 
-		var job foundation.Job
-		if err := json.Unmarshal(args, &job); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal job: %w", err)
-		}
+	inputDigest, _ := res.Digest()
+	var data []byte
 
-		m.logger.Debug("executing remote job", "job_id", job.ID, "from_peer", getShortID(peerID))
+	// 2. Resolve data from Resource (SABRef > Storage)
+	if res.Which() == system.Resource_Which_sabRef && m.bridge != nil {
+		ref, _ := res.SabRef()
+		m.logger.Debug("resolved input via sabRef", "offset", ref.Offset(), "size", ref.Size())
+		data, err = m.bridge.ReadRaw(ref.Offset(), ref.Size())
+		if err != nil {
+			return DelegationResponse{}, fmt.Errorf("failed to read from sabRef: %w", err)
+		}
+		data, err = m.decodePayloadFromWire(data, resourceCompressionToString(res.Compression()), int(res.RawSize()))
+		if err != nil {
+			return DelegationResponse{}, fmt.Errorf("failed to decode sabRef resource payload: %w", err)
+		}
+	} else if res.Which() == system.Resource_Which_inline {
+		wirePayload, err := res.Inline()
+		if err != nil {
+			return DelegationResponse{}, fmt.Errorf("failed to read inline resource: %w", err)
+		}
+		data, err = m.decodePayloadFromWire(wirePayload, resourceCompressionToString(res.Compression()), int(res.RawSize()))
+		if err != nil {
+			return DelegationResponse{}, fmt.Errorf("failed to decode inline resource payload: %w", err)
+		}
+	} else if m.storage != nil {
+		if len(inputDigest) == 0 {
+			return DelegationResponse{Status: "input_missing"}, nil
+		}
+		// Check if we have the input chunk
+		has, err := m.storage.HasChunk(ctx, string(inputDigest))
+		if err != nil || !has {
+			return DelegationResponse{Status: "input_missing"}, nil
+		}
 
-		// Execute locally!
-		result := m.dispatcher.ExecuteJob(&job)
-		return result, nil
+		// Fetch data
+		data, err = m.storage.FetchChunk(ctx, string(inputDigest))
+		if err != nil {
+			return DelegationResponse{}, fmt.Errorf("failed to fetch input chunk: %w", err)
+		}
+	} else {
+		return DelegationResponse{}, errors.New("no data source available (storage/bridge missing)")
+	}
+
+	if err := m.checkLocalGPU(jobGPURequirements(req.Operation, nil)); err != nil {
+		return DelegationResponse{Status: "failed", Error: err.Error()}, nil
+	}
+
+	// 4. Execute locally
+	if req.Module != "" {
+		if _, err := m.LoadModule(ctx, req.Module); err != nil {
+			return DelegationResponse{Status: "module_rejected", Error: err.Error()}, nil
+		}
+	}
+	job := req.toJob(data)
+	job.TraceParent = tracing.TraceparentFromContext(ctx)
+	applyRPCScheduling(ctx, job, 100) // Default priority for delegated tasks
+	m.recordNamespaceUsage(ctx, len(data))
+
+	receipt := &DelegationReceipt{
+		JobID:       req.ID,
+		Operation:   req.Operation,
+		Requester:   req.Requester,
+		InputDigest: m.computeResourceDigest(data),
+		StartedAt:   m.MeshTime().UnixNano(),
+	}
+	started := time.Now()
+	result, err := m.runSandboxed(ctx, req.Requester, job)
+	var exceeded *ResourceExceededError
+	if errors.As(err, &exceeded) {
+		result = &foundation.Result{JobID: job.ID, Error: exceeded.wire()}
+	} else if err != nil {
+		return DelegationResponse{}, err
+	}
+	elapsed := time.Since(started)
+	receipt.FinishedAt = m.MeshTime().UnixNano()
+	m.execution.record(len(data), len(result.Data), elapsed, result.Success)
+	m.publishEvent(MeshEventDelegationExecuted, req.Requester, map[string]interface{}{
+		"id":         req.ID,
+		"operation":  req.Operation,
+		"success":    result.Success,
+		"latency_ms": float64(elapsed.Microseconds()) / 1000,
+		"error":      result.Error,
 	})
+	if exceeded != nil {
+		return m.attachReceipt(DelegationResponse{Status: "resource_exceeded", Error: result.Error}, receipt)
+	}
+	if !result.Success {
+		return m.attachReceipt(DelegationResponse{Status: "failed", Error: result.Error}, receipt)
+	}
+
+	// 5. Pack Result with content-address digest
+	outputDigest := m.computeResourceDigest(result.Data)
+	resOutBytes, err := m.packResource(req.ID, outputDigest, result.Data)
+	if err != nil {
+		return DelegationResponse{}, fmt.Errorf("failed to pack result resource: %w", err)
+	}
+
+	resp := DelegationResponse{
+		Status:    "success",
+		Resource:  resOutBytes,
+		LatencyMs: float32(result.Latency),
+	}
+	if result.Metrics != nil {
+		resp.GPUTimeMs = float32(result.Metrics.GPUTime) / float32(time.Millisecond)
+	}
+	receipt.OutputDigest = outputDigest
+	return m.attachReceipt(resp, receipt)
+}
+
+// attachReceipt signs the receipt for a job that ran, keeps it and returns
+// it with the response.
+func (m *MeshCoordinator) attachReceipt(resp DelegationResponse, receipt *DelegationReceipt) (DelegationResponse, error) {
+	receipt.Status = resp.Status
+	if err := m.signDelegationReceipt(receipt); err != nil {
+		return DelegationResponse{}, err
+	}
+	m.storeDelegationReceipt(receipt)
+	resp.Receipt = receipt
+	return resp, nil
 }
 
 // DelegateRequest represents a compute delegation request
@@ -1895,15 +3156,73 @@ type DelegateRequest struct {
 	// Resource carries the serialized system.Resource Cap'n Proto message
 	Resource []byte `json:"resource,omitempty"`
 	Params   string `json:"params,omitempty"`
+	// Module is the content hash of a registry module to run the operation
+	// with; the executor loads it by hash instead of receiving the bytes
+	Module string `json:"module,omitempty"`
+
+	// Requester identity and its signature over the request
+	Requester string `json:"requester,omitempty"`
+	PublicKey []byte `json:"public_key,omitempty"`
+	Timestamp int64  `json:"timestamp,omitempty"`
+	Signature []byte `json:"signature,omitempty"`
+
+	// TraceParent is the requester's span; it is not covered by Signature
+	TraceParent string `json:"trace_parent,omitempty"`
+
+	// IdempotencyKey makes the request at-most-once on the executor: a
+	// retry with the same key is answered from the first execution.
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
+
+	// Version 2 requests carry a signed scheduling envelope; see
+	// DelegateRequestV2. Zero is a version 1 request from an older peer.
+	Version  int            `json:"version,omitempty"`
+	Deadline int64          `json:"deadline,omitempty"` // Requester mesh time, Unix nanoseconds
+	Priority int            `json:"priority,omitempty"`
+	Limits   *SandboxLimits `json:"limits,omitempty"` // Caps the executor applies on top of its own
 }
 
 // DelegationResponse represents the result of a compute delegation
 type DelegationResponse struct {
+	// Status is "success"; "failed", "resource_exceeded" or
+	// "module_rejected" when the job ran or was refused, and retrying with
+	// the same idempotency key returns the same answer; "input_missing"
+	// when the executor could not resolve the input; or "in_progress" when
+	// an earlier request with the same key is still running, so the retry
+	// should be repeated with that key rather than a new one.
 	Status string `json:"status"`
 	// Resource carries the serialized system.Resource Cap'n Proto result
 	Resource  []byte  `json:"resource,omitempty"`
 	Error     string  `json:"error,omitempty"`
 	LatencyMs float32 `json:"latency_ms"`
+	GPUTimeMs float32 `json:"gpu_time_ms,omitempty"` // Set by GPU-class jobs
+	Replayed  bool    `json:"replayed,omitempty"`    // Answered from an earlier request with the same idempotency key
+
+	// Executor identity and its signature over the response, which covers
+	// the request signature
+	Executor         string `json:"executor,omitempty"`
+	ExecutorKey      []byte `json:"executor_key,omitempty"`
+	RequestSignature []byte `json:"request_signature,omitempty"`
+	Signature        []byte `json:"signature,omitempty"`
+
+	// Receipt is the executor's signed account of the run, set when the
+	// job ran; see DelegationReceipt
+	Receipt *DelegationReceipt `json:"receipt,omitempty"`
+}
+
+// toJob turns a received request and its resolved input into the canonical
+// job model, with the module and any signed envelope applied.
+func (r *DelegateRequest) toJob(data []byte) *foundation.Job {
+	job := &foundation.Job{
+		ID:        r.ID,
+		Operation: r.Operation,
+		Data:      data,
+		Source:    r.Requester,
+	}
+	if r.Module != "" {
+		job.Parameters = map[string]interface{}{JobParamModule: r.Module}
+	}
+	applyDelegationEnvelope(r, job)
+	return job
 }
 
 // ToCapnp converts DelegateRequest to p2p.DelegateRequest.
@@ -1922,6 +3241,14 @@ func (r *DelegateRequest) ToCapnp(seg *capnp.Segment) (p2p.DelegateRequest, erro
 	if r.Params != "" {
 		req.SetParams([]byte(r.Params))
 	}
+	if r.Deadline > 0 {
+		req.SetDeadline(uint64(r.Deadline))
+	}
+	req.SetPriority(uint8(min(max(r.Priority, 0), math.MaxUint8)))
+	if r.TraceParent != "" {
+		md, _ := req.NewMetadata()
+		md.SetTraceParent(r.TraceParent)
+	}
 
 	if len(r.Resource) > 0 {
 		// Resource is already a serialized system.Resource
@@ -1949,6 +3276,18 @@ func (r *DelegateRequest) FromCapnp(req p2p.DelegateRequest) error {
 	params, _ := req.Params()
 	r.Params = string(params)
 
+	// The schema's deadline and priority are the v2 envelope, unsigned on
+	// this path like the rest of the message
+	r.Deadline = int64(req.Deadline())
+	r.Priority = int(req.Priority())
+	if r.Deadline != 0 || r.Priority != 0 {
+		r.Version = DelegateRequestV2
+	}
+	if req.HasMetadata() {
+		md, _ := req.Metadata()
+		r.TraceParent, _ = md.TraceParent()
+	}
+
 	if req.HasResource() {
 		res, _ := req.Resource()
 		msg, seg, _ := capnp.NewMessage(capnp.SingleSegment(nil))
@@ -2066,6 +3405,8 @@ func (r *DelegationResponse) FromCapnp(res p2p.DelegateResponse) error {
 
 // packResource creates a serialized system.Resource
 func (m *MeshCoordinator) packResource(id, digest string, data []byte) ([]byte, error) {
+	defer m.trackDelegation()()
+
 	msg, seg, err := capnp.NewMessage(capnp.SingleSegment(nil))
 	if err != nil {
 		return nil, err
@@ -2117,6 +3458,8 @@ func (m *MeshCoordinator) packResource(id, digest string, data []byte) ([]byte,
 
 // unpackResource deserializes a system.Resource
 func (m *MeshCoordinator) unpackResource(data []byte) (system.Resource, error) {
+	defer m.trackDelegation()()
+
 	msg, err := capnp.Unmarshal(data)
 	if err != nil {
 		return system.Resource{}, err
@@ -2126,6 +3469,8 @@ func (m *MeshCoordinator) unpackResource(data []byte) (system.Resource, error) {
 }
 
 func (m *MeshCoordinator) resolveResourceData(res system.Resource) ([]byte, error) {
+	defer m.trackDelegation()()
+
 	compression := resourceCompressionToString(res.Compression())
 	rawSize := int(res.RawSize())
 
@@ -14,6 +14,7 @@ import (
 	"sync"
 	"testing"
 	"time"
+	"unsafe"
 
 	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
 	meshtransport "github.com/nmxmxh/inos_v1/kernel/core/mesh/transport"
@@ -24,14 +25,19 @@ import (
 // MockStorage implements StorageProvider for testing
 type MockStorage struct {
 	chunks map[string][]byte
+	mu     sync.Mutex
 }
 
 func (m *MockStorage) StoreChunk(ctx context.Context, hash string, data []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.chunks[hash] = data
 	return nil
 }
 
 func (m *MockStorage) FetchChunk(ctx context.Context, hash string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	if data, ok := m.chunks[hash]; ok {
 		return data, nil
 	}
@@ -39,10 +45,19 @@ func (m *MockStorage) FetchChunk(ctx context.Context, hash string) ([]byte, erro
 }
 
 func (m *MockStorage) HasChunk(ctx context.Context, hash string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	_, ok := m.chunks[hash]
 	return ok, nil
 }
 
+func (m *MockStorage) DeleteChunk(ctx context.Context, hash string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.chunks, hash)
+	return nil
+}
+
 // MockTransport implements Transport for testing
 type MockTransport struct {
 	nodeID                string
@@ -410,6 +425,32 @@ func TestMeshCoordinator_DetailedTelemetry(t *testing.T) {
 	}
 }
 
+// measuringTransport reports measured round trips like WebRTCTransport.
+type measuringTransport struct {
+	*MockTransport
+	latency map[string]time.Duration
+}
+
+func (m *measuringTransport) PeerRPCLatency(peerID string) (time.Duration, bool) {
+	d, ok := m.latency[peerID]
+	return d, ok
+}
+
+func TestMeshCoordinator_PeerScoreUsesMeasuredLatency(t *testing.T) {
+	tr := &measuringTransport{
+		MockTransport: &MockTransport{nodeID: "self"},
+		latency:       map[string]time.Duration{"slow": 400 * time.Millisecond},
+	}
+	coord := NewMeshCoordinator("self", "us-east", tr, nil)
+
+	// Both advertise 10ms; only one has been measured, and it is slow
+	claimed := &common.PeerCapability{PeerID: "unmeasured", Region: "us-east", LatencyMs: 10}
+	measured := &common.PeerCapability{PeerID: "slow", Region: "us-east", LatencyMs: 10}
+	if coord.calculatePeerScore(measured) >= coord.calculatePeerScore(claimed) {
+		t.Fatal("expected the measured round trip to override the advertised latency")
+	}
+}
+
 func TestMeshCoordinator_ChunkOrchestration(t *testing.T) {
 	nodeID := "test-node-1"
 	tr := &MockTransport{
@@ -625,6 +666,86 @@ func TestMeshCoordinator_ChunkRPCBrotliRoundTrip(t *testing.T) {
 	}
 }
 
+// framingTransport parks chunk frames the way WebRTCTransport does, keyed
+// by the receiving coordinator's view of the sender.
+type framingTransport struct {
+	*MockTransport
+	frames map[string][]byte
+}
+
+func (f *framingTransport) SendChunkFrame(ctx context.Context, peerID, chunkHash string, data []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.frames[peerID+"/"+chunkHash] = data
+	return nil
+}
+
+func (f *framingTransport) TakeChunkFrame(peerID, chunkHash string) ([]byte, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	data, ok := f.frames[peerID+"/"+chunkHash]
+	delete(f.frames, peerID+"/"+chunkHash)
+	return data, ok
+}
+
+// addressedSABBridge reports slices of its own buffer as SAB-backed.
+type addressedSABBridge struct {
+	*regionSABBridge
+}
+
+func (b addressedSABBridge) GetAddress(data []byte) (uint32, bool) {
+	if len(data) == 0 {
+		return 0, false
+	}
+	base := uintptr(unsafe.Pointer(&b.data[0]))
+	ptr := uintptr(unsafe.Pointer(&data[0]))
+	if ptr < base || ptr >= base+uintptr(len(b.data)) {
+		return 0, false
+	}
+	return uint32(ptr - base), true
+}
+
+func TestMeshCoordinator_SABChunksSendAsBinaryFrames(t *testing.T) {
+	tr := &framingTransport{
+		MockTransport: &MockTransport{nodeID: "self", rpcHandlers: make(map[string]func(args interface{}) (interface{}, error))},
+		frames:        make(map[string][]byte),
+	}
+	coord := NewMeshCoordinator("self", "us-east", tr, nil)
+	storage := &MockStorage{chunks: make(map[string][]byte)}
+	coord.SetStorage(storage)
+	bridge := addressedSABBridge{newRegionSABBridge(8192)}
+	coord.SetSABBridge(bridge)
+
+	// A chunk in the SAB travels as a frame that chunk.store claims, uncopied
+	shared := bridge.data[4096 : 4096+1024]
+	copy(shared, strings.Repeat("sab-chunk|", 103))
+	if err := coord.sendChunkToPeer(context.Background(), "peer-1", "sab-hash", shared); err != nil {
+		t.Fatalf("sendChunkToPeer failed: %v", err)
+	}
+	if stored := storage.chunks["sab-hash"]; len(stored) != len(shared) || &stored[0] != &shared[0] {
+		t.Fatal("expected the stored chunk to be the SAB bytes themselves")
+	}
+	if len(tr.frames) != 0 {
+		t.Fatalf("expected the frame claimed, %d left", len(tr.frames))
+	}
+
+	// Heap data keeps the inline path
+	heap := []byte("heap-chunk")
+	if err := coord.sendChunkToPeer(context.Background(), "peer-1", "heap-hash", heap); err != nil {
+		t.Fatalf("sendChunkToPeer failed: %v", err)
+	}
+	if stored := storage.chunks["heap-hash"]; !bytes.Equal(stored, heap) || &stored[0] == &heap[0] {
+		t.Fatal("expected heap chunk stored through the encoded request")
+	}
+
+	// A request claiming a frame that never came is refused
+	var resp ChunkStoreResponse
+	err := tr.SendRPC(context.Background(), "peer-1", chunkStoreMethod, ChunkStoreRequest{ChunkHash: "lost", RawSize: 4, Framed: true}, &resp)
+	if err == nil {
+		t.Fatal("expected a missing frame to fail chunk.store")
+	}
+}
+
 func TestMeshCoordinator_DelegateComputeRejectsDigestMismatch(t *testing.T) {
 	nodeID := "test-node-1"
 	tr := &MockTransport{
@@ -635,15 +756,31 @@ func TestMeshCoordinator_DelegateComputeRejectsDigestMismatch(t *testing.T) {
 
 	tr.mu.Lock()
 	tr.rpcHandlers["mesh.DelegateCompute"] = func(args interface{}) (interface{}, error) {
+		req := args.(DelegateRequest)
 		badResource, err := coord.packResource("resp-1", "definitely-wrong-digest", []byte("tampered-output"))
 		if err != nil {
 			return nil, err
 		}
-		return DelegationResponse{
+		resp := DelegationResponse{
 			Status:    "success",
 			Resource:  badResource,
 			LatencyMs: 1,
-		}, nil
+			Receipt: &DelegationReceipt{
+				JobID:        req.ID,
+				Operation:    req.Operation,
+				InputDigest:  coord.computeResourceDigest([]byte("input-data")),
+				OutputDigest: coord.computeResourceDigest([]byte("tampered-output")),
+				Status:       "success",
+			},
+		}
+		// A correctly signed response must still fail the digest check
+		if err := coord.signDelegationReceipt(resp.Receipt); err != nil {
+			return nil, err
+		}
+		if err := coord.signDelegationResponse(&req, &resp); err != nil {
+			return nil, err
+		}
+		return resp, nil
 	}
 	tr.mu.Unlock()
 
@@ -681,6 +818,7 @@ func TestMeshCoordinator_DelegateComputeVerifiesReturnedDigest(t *testing.T) {
 	coord.peerMetricsMu.Lock()
 	coord.peerMetrics["peer-1"] = common.MeshMetrics{AvgReputation: 1.0, P50LatencyMs: 1.0}
 	coord.peerMetricsMu.Unlock()
+	trustPeers(coord, "peer-1")
 
 	output, err := coord.DelegateCompute(context.Background(), "compress", "input-digest", []byte("source"))
 	if err != nil {
@@ -741,7 +879,7 @@ func TestMeshCoordinator_DelegateComputeCompressedResourceRoundTrip(t *testing.T
 		}
 
 		paramsBytes, _ := json.Marshal(args)
-		result, err := delegateHandler(context.Background(), "peer-1", json.RawMessage(paramsBytes))
+		result, err := delegateHandler(capabilityContextFor(t, coord, "peer-1", delegateComputeMethod), "peer-1", json.RawMessage(paramsBytes))
 		if err != nil {
 			return nil, err
 		}
@@ -765,6 +903,7 @@ func TestMeshCoordinator_DelegateComputeCompressedResourceRoundTrip(t *testing.T
 	coord.peerMetricsMu.Lock()
 	coord.peerMetrics["peer-1"] = common.MeshMetrics{AvgReputation: 1.0, P50LatencyMs: 1.0}
 	coord.peerMetricsMu.Unlock()
+	trustPeers(coord, "peer-1")
 
 	output, err := coord.DelegateCompute(context.Background(), "compress", "input-digest", input)
 	if err != nil {
@@ -12,6 +12,8 @@ import (
 type DelegationDecision struct {
 	ShouldDelegate     bool
 	TargetType         DelegationTargetType
+	RequiresGPU        bool                  // GPU-class job; only peers advertising an adapter qualify
+	Hardware           *HardwareRequirements // Only peers whose hardware descriptor meets it qualify
 	PeerScoreThreshold float32
 	FallbackTimeout    time.Duration
 	EstimatedCost      float64
@@ -37,9 +39,11 @@ type DelegationEngine struct {
 	loadProvider SystemLoadProvider
 	// TODO: Add CostModel and LoadPredictor when available in kernel
 
-	networkLatency float64 // Rolling average of mesh latency
-	localLoad      float64 // Current system load (0-1)
-	mu             sync.RWMutex
+	networkLatency   float64 // Rolling average of mesh latency
+	localLoad        float64 // Current system load (0-1)
+	powerConstrained bool    // On battery or thermally throttled
+	localHardware    *HardwareDescriptor
+	mu               sync.RWMutex
 }
 
 // NewDelegationEngine creates a new delegation engine
@@ -56,14 +60,24 @@ func (de *DelegationEngine) Analyze(ctx context.Context, job *foundation.Job) De
 	defer de.mu.RUnlock()
 
 	efficiency := de.predictEfficiency(job)
+	hardware := jobHardwareRequirements(job.Parameters)
 
-	return DelegationDecision{
+	decision := DelegationDecision{
 		ShouldDelegate:     efficiency > 0.7,
 		TargetType:         de.selectTargetType(job, efficiency),
+		RequiresGPU:        IsGPUOperation(job.Operation),
+		Hardware:           hardware,
 		PeerScoreThreshold: de.calculateMinScore(job),
 		FallbackTimeout:    500 * time.Millisecond,
 		EfficiencyScore:    efficiency,
 	}
+
+	// A job this machine is known not to fit goes to a peer that does
+	if de.localHardware != nil && len(hardware.missing(de.localHardware)) > 0 {
+		decision.ShouldDelegate = true
+		decision.TargetType = TargetDedicatedHW
+	}
+	return decision
 }
 
 // predictEfficiency uses multi-factor analysis to estimate delegation benefit
@@ -80,9 +94,12 @@ func (de *DelegationEngine) predictEfficiency(job *foundation.Job) float64 {
 		computeSpeedup = de.loadProvider.GetSystemLoad()
 	}
 
-	// 3. TODO: Resource Intensity (Battery/Thermal aware)
-	// For now, use a constant placeholder
+	// 3. Resource Intensity: running on battery or a hot device costs
+	// more than shipping the work to a peer
 	energyEfficiency := 0.5
+	if de.powerConstrained {
+		energyEfficiency = 1.0
+	}
 
 	// 4. Opportunity Cost (Local Task Priority)
 	// High priority tasks should stay local unless load is critical
@@ -95,7 +112,10 @@ func (de *DelegationEngine) predictEfficiency(job *foundation.Job) float64 {
 	return (transferEfficiency * 0.4) + (computeSpeedup * 0.3) + (energyEfficiency * 0.2) + (priorityFactor * 0.1)
 }
 
-func (de *DelegationEngine) selectTargetType(_ *foundation.Job, efficiency float64) DelegationTargetType {
+func (de *DelegationEngine) selectTargetType(job *foundation.Job, efficiency float64) DelegationTargetType {
+	if IsGPUOperation(job.Operation) {
+		return TargetDedicatedHW
+	}
 	if efficiency < 0.3 {
 		return TargetLocal
 	}
@@ -112,6 +132,22 @@ func (de *DelegationEngine) calculateMinScore(job *foundation.Job) float32 {
 	return 0.6
 }
 
+// SetPowerConstrained tells the engine whether the device is on battery or
+// thermally throttled, which favours delegating.
+func (de *DelegationEngine) SetPowerConstrained(constrained bool) {
+	de.mu.Lock()
+	de.powerConstrained = constrained
+	de.mu.Unlock()
+}
+
+// SetLocalHardware gives the engine this machine's descriptor, so jobs
+// that need more than it has are always delegated.
+func (de *DelegationEngine) SetLocalHardware(hw *HardwareDescriptor) {
+	de.mu.Lock()
+	de.localHardware = hw
+	de.mu.Unlock()
+}
+
 // UpdateMetrics updates the engine's internal state for decision making
 func (de *DelegationEngine) UpdateMetrics(load float64, latency float64) {
 	de.mu.Lock()
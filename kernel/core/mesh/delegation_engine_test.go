@@ -241,3 +241,14 @@ func TestDelegationEngine_ConcurrentAccess(t *testing.T) {
 	// No panic means success
 	assert.True(t, true)
 }
+
+func TestDelegationEngine_PowerConstrainedFavoursDelegation(t *testing.T) {
+	engine := NewDelegationEngine(&mockSystemLoadProvider{load: 0.5})
+	job := &foundation.Job{ID: "test", Data: make([]byte, 1024), Priority: 50}
+
+	before := engine.predictEfficiency(job)
+	engine.SetPowerConstrained(true)
+	if after := engine.predictEfficiency(job); after <= before {
+		t.Fatalf("expected delegation to look better on battery: %v <= %v", after, before)
+	}
+}
@@ -0,0 +1,128 @@
+package mesh
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+	"github.com/nmxmxh/inos_v1/kernel/threads/foundation"
+)
+
+// DelegateRequest versions. Version 1 requests, and unversioned ones from
+// older peers, leave scheduling to RPC metadata, which the requester does
+// not sign. Version 2 adds a signed envelope: the deadline, priority and
+// resource limits travel in the request and are covered by its signature,
+// so a relay cannot stretch or reprioritise the job.
+const (
+	DelegateRequestV1 = 1
+	DelegateRequestV2 = 2
+)
+
+// ErrUnsupportedRequestVersion is returned for requests newer than this
+// node understands; their signatures cannot be checked.
+var ErrUnsupportedRequestVersion = errors.New("unsupported delegation request version")
+
+type delegationLimitsKey struct{}
+
+// WithDelegationLimits asks the executor of DelegateCompute calls made with
+// ctx to run the job under limits. Executors apply them on top of their own
+// sandbox caps, so limits can tighten the sandbox but never loosen it.
+func WithDelegationLimits(ctx context.Context, limits SandboxLimits) context.Context {
+	return context.WithValue(ctx, delegationLimitsKey{}, limits)
+}
+
+func delegationLimitsFromContext(ctx context.Context) *SandboxLimits {
+	limits, ok := ctx.Value(delegationLimitsKey{}).(SandboxLimits)
+	if !ok {
+		return nil
+	}
+	return &limits
+}
+
+// stampDelegationEnvelope fills a request's v2 envelope from ctx: its
+// deadline in mesh time, its RPC priority and any requested limits.
+func (m *MeshCoordinator) stampDelegationEnvelope(ctx context.Context, req *DelegateRequest) {
+	req.Version = DelegateRequestV2
+	if deadline, ok := ctx.Deadline(); ok {
+		req.Deadline = m.MeshTime().Add(time.Until(deadline)).UnixNano()
+	}
+	if priority, ok := common.PriorityFromContext(ctx); ok {
+		req.Priority = priority
+	}
+	req.Limits = delegationLimitsFromContext(ctx)
+}
+
+// delegationEnvelopeContext checks a received request's version and bounds
+// ctx by its signed deadline. Requests without an envelope keep ctx.
+func (m *MeshCoordinator) delegationEnvelopeContext(ctx context.Context, req *DelegateRequest) (context.Context, context.CancelFunc, error) {
+	switch req.Version {
+	case 0, DelegateRequestV1:
+		return ctx, func() {}, nil
+	case DelegateRequestV2:
+	default:
+		return nil, nil, fmt.Errorf("%w: %d", ErrUnsupportedRequestVersion, req.Version)
+	}
+	if req.Deadline == 0 {
+		return ctx, func() {}, nil
+	}
+	remaining := time.Duration(req.Deadline - m.MeshTime().UnixNano())
+	if remaining <= 0 {
+		return nil, nil, errors.New("delegation deadline already passed")
+	}
+	ctx, cancel := context.WithTimeout(ctx, remaining)
+	return ctx, cancel, nil
+}
+
+// applyDelegationEnvelope gives a job the priority and limits its request
+// signed for. The signed priority wins over RPC metadata; the limits only
+// lower what sandboxLimits would otherwise allow.
+func applyDelegationEnvelope(req *DelegateRequest, job *foundation.Job) {
+	if req.Version < DelegateRequestV2 {
+		return
+	}
+	if req.Priority != 0 {
+		job.Priority = req.Priority
+	}
+	if req.Limits == nil {
+		return
+	}
+	if job.Parameters == nil {
+		job.Parameters = make(map[string]interface{})
+	}
+	if req.Limits.Fuel > 0 {
+		job.Parameters[JobParamFuelLimit] = req.Limits.Fuel
+	}
+	if req.Limits.MemoryBytes > 0 {
+		job.Parameters[JobParamMemoryLimit] = req.Limits.MemoryBytes
+	}
+	if req.Limits.WallClock > 0 {
+		if deadline := time.Now().Add(req.Limits.WallClock); job.Deadline.IsZero() || deadline.Before(job.Deadline) {
+			job.Deadline = deadline
+		}
+	}
+}
+
+// appendDelegationEnvelope adds the v2 envelope to a request's signed
+// payload. Older requests sign without it, exactly as before.
+func appendDelegationEnvelope(buf []byte, req *DelegateRequest) []byte {
+	if req.Version < DelegateRequestV2 {
+		return buf
+	}
+	buf = append(buf, 3)
+	buf = binary.BigEndian.AppendUint16(buf, uint16(req.Version))
+	buf = binary.BigEndian.AppendUint64(buf, uint64(req.Deadline))
+	buf = binary.BigEndian.AppendUint64(buf, uint64(int64(req.Priority)))
+	var limits SandboxLimits
+	if req.Limits != nil {
+		limits = *req.Limits
+		buf = append(buf, 1)
+	} else {
+		buf = append(buf, 0)
+	}
+	buf = binary.BigEndian.AppendUint64(buf, limits.Fuel)
+	buf = binary.BigEndian.AppendUint64(buf, limits.MemoryBytes)
+	return binary.BigEndian.AppendUint64(buf, uint64(limits.WallClock))
+}
@@ -0,0 +1,107 @@
+package mesh
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+	"github.com/nmxmxh/inos_v1/kernel/threads/foundation"
+	capnp "zombiezen.com/go/capnproto2"
+)
+
+func TestDelegationEnvelope_ExecutorAppliesSignedScheduling(t *testing.T) {
+	coord, _ := newDelegationTestCoordinator(t)
+	var seen foundation.Job
+	coord.SetDispatcher(&mockDispatcher{
+		run: func(job *foundation.Job) *foundation.Result {
+			seen = *job
+			return &foundation.Result{JobID: job.ID, Success: true, Data: job.Data}
+		},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	ctx = common.WithPriority(ctx, 7)
+	ctx = WithDelegationLimits(ctx, SandboxLimits{Fuel: 1000, WallClock: 10 * time.Second})
+	if _, err := coord.DelegateCompute(ctx, "compress", "input-digest", []byte("source")); err != nil {
+		t.Fatalf("DelegateCompute failed: %v", err)
+	}
+
+	if seen.Priority != 7 {
+		t.Fatalf("expected the signed priority, got %d", seen.Priority)
+	}
+	if foundation.UintParam(seen.Parameters[JobParamFuelLimit]) != 1000 {
+		t.Fatalf("expected the requested fuel limit, got %v", seen.Parameters[JobParamFuelLimit])
+	}
+	if until := time.Until(seen.Deadline); until <= 0 || until > 10*time.Second {
+		t.Fatalf("expected the wall clock limit to bound the job, got %v", until)
+	}
+}
+
+func TestDelegationEnvelope_SignedAndVersioned(t *testing.T) {
+	coord, _ := newDelegationTestCoordinator(t)
+
+	legacy := DelegateRequest{ID: "legacy", Operation: "compress"}
+	legacyPayload := delegationRequestPayload(&legacy)
+	legacy.Priority = 9 // Not part of a version 1 request
+	if string(delegationRequestPayload(&legacy)) != string(legacyPayload) {
+		t.Fatal("expected version 1 requests to sign without the envelope")
+	}
+
+	req := DelegateRequest{ID: "v2", Operation: "compress"}
+	coord.stampDelegationEnvelope(common.WithPriority(context.Background(), 3), &req)
+	if err := coord.signDelegationRequest(&req); err != nil {
+		t.Fatalf("signDelegationRequest failed: %v", err)
+	}
+	if err := coord.verifyDelegationRequest(&req); err != nil {
+		t.Fatalf("v2 request does not verify: %v", err)
+	}
+	req.Priority = 250
+	if err := coord.verifyDelegationRequest(&req); err == nil {
+		t.Fatal("expected a raised priority to break the signature")
+	}
+
+	req.Priority, req.Version = 3, 3
+	if _, _, err := coord.delegationEnvelopeContext(context.Background(), &req); !errors.Is(err, ErrUnsupportedRequestVersion) {
+		t.Fatalf("expected ErrUnsupportedRequestVersion, got %v", err)
+	}
+	req.Version, req.Deadline = DelegateRequestV2, coord.MeshTime().Add(-time.Second).UnixNano()
+	if _, _, err := coord.delegationEnvelopeContext(context.Background(), &req); err == nil {
+		t.Fatal("expected an expired deadline to be refused")
+	}
+}
+
+func TestDelegationEnvelope_CarriedThroughCapnp(t *testing.T) {
+	req := DelegateRequest{
+		ID:          "v2",
+		Operation:   "compress",
+		Version:     DelegateRequestV2,
+		Deadline:    time.Now().Add(time.Minute).UnixNano(),
+		Priority:    40,
+		Module:      "module-hash",
+		TraceParent: "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01",
+	}
+	_, seg, err := capnp.NewMessage(capnp.SingleSegment(nil))
+	if err != nil {
+		t.Fatalf("NewMessage failed: %v", err)
+	}
+	wire, err := req.ToCapnp(seg)
+	if err != nil {
+		t.Fatalf("ToCapnp failed: %v", err)
+	}
+
+	var got DelegateRequest
+	if err := got.FromCapnp(wire); err != nil {
+		t.Fatalf("FromCapnp failed: %v", err)
+	}
+	if got.Version != DelegateRequestV2 || got.Deadline != req.Deadline || got.Priority != 40 || got.TraceParent != req.TraceParent {
+		t.Fatalf("envelope lost in conversion: %+v", got)
+	}
+
+	job := req.toJob([]byte("input"))
+	if job.Priority != 40 || job.Source != "" || job.Parameters[JobParamModule] != "module-hash" {
+		t.Fatalf("unexpected canonical job %+v", job)
+	}
+}
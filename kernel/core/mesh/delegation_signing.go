@@ -0,0 +1,298 @@
+package mesh
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/routing"
+)
+
+const (
+	delegationRequestVersion  = "inos-delegate-req-v1"
+	delegationResponseVersion = "inos-delegate-resp-v1"
+)
+
+// Delegation audit roles.
+const (
+	DelegationRoleRequester = "requester"
+	DelegationRoleExecutor  = "executor"
+)
+
+// DelegationAuditRecord is the signed evidence of one delegated job. The
+// requester's signature proves who asked for the work and the executor's
+// signature, which covers the request signature, proves who did it and what
+// it returned. Either party can replay Verify against a record in a dispute.
+//
+// Records are hash-chained in log order: PrevHash is the Hash of the record
+// before, so an exported log cannot be reordered or thinned without breaking
+// the chain.
+type DelegationAuditRecord struct {
+	RequestID         string    `json:"request_id"`
+	Role              string    `json:"role"` // Which side of the delegation recorded this
+	Operation         string    `json:"operation"`
+	Requester         string    `json:"requester"`
+	RequesterKey      []byte    `json:"requester_key,omitempty"`
+	RequestPayload    []byte    `json:"request_payload"`
+	RequestSignature  []byte    `json:"request_signature,omitempty"`
+	Executor          string    `json:"executor,omitempty"`
+	ExecutorKey       []byte    `json:"executor_key,omitempty"`
+	Status            string    `json:"status,omitempty"`
+	ResponsePayload   []byte    `json:"response_payload,omitempty"`
+	ResponseSignature []byte    `json:"response_signature,omitempty"`
+	RecordedAt        time.Time `json:"recorded_at"`
+	PrevHash          []byte    `json:"prev_hash,omitempty"`
+	Hash              []byte    `json:"hash,omitempty"`
+}
+
+// Verify checks both signatures in the record. Unsigned halves fail.
+func (r *DelegationAuditRecord) Verify() error {
+	if len(r.RequesterKey) != ed25519.PublicKeySize || !ed25519.Verify(r.RequesterKey, r.RequestPayload, r.RequestSignature) {
+		return errors.New("invalid delegation request signature")
+	}
+	if len(r.ResponsePayload) == 0 {
+		return nil
+	}
+	if len(r.ExecutorKey) != ed25519.PublicKeySize || !ed25519.Verify(r.ExecutorKey, r.ResponsePayload, r.ResponseSignature) {
+		return errors.New("invalid delegation response signature")
+	}
+	return nil
+}
+
+// ComputeHash returns the chain hash of the record: every field but Hash,
+// including PrevHash.
+func (r *DelegationAuditRecord) ComputeHash() []byte {
+	h := sha256.New()
+	for _, part := range [][]byte{
+		r.PrevHash, []byte(r.RequestID), []byte(r.Role), []byte(r.Operation),
+		[]byte(r.Requester), r.RequesterKey, r.RequestPayload, r.RequestSignature,
+		[]byte(r.Executor), r.ExecutorKey, []byte(r.Status), r.ResponsePayload, r.ResponseSignature,
+	} {
+		_ = binary.Write(h, binary.BigEndian, uint32(len(part)))
+		h.Write(part)
+	}
+	_ = binary.Write(h, binary.BigEndian, r.RecordedAt.UnixNano())
+	return h.Sum(nil)
+}
+
+// signDelegationRequest stamps the request with this node's identity.
+func (m *MeshCoordinator) signDelegationRequest(req *DelegateRequest) error {
+	if m.gossip == nil {
+		return errors.New("gossip manager unavailable for delegation signing")
+	}
+	req.Requester = m.nodeID
+	req.PublicKey = m.gossip.PublicKey()
+	req.Timestamp = m.MeshTime().UnixNano()
+
+	sig, pub, err := m.gossip.SignAttestation(delegationRequestPayload(req))
+	if err != nil {
+		return fmt.Errorf("failed to sign delegation request: %w", err)
+	}
+	if !bytes.Equal(pub, req.PublicKey) {
+		return errors.New("identity key rotated while signing delegation request")
+	}
+	req.Signature = sig
+	return nil
+}
+
+// verifyDelegationRequest checks the requester's signature and that the
+// signing key belongs to the node the request names. The requester need not
+// be the RPC sender: a relayed request still bills the node that signed it.
+func (m *MeshCoordinator) verifyDelegationRequest(req *DelegateRequest) error {
+	if len(req.Signature) == 0 {
+		if m.config.Delegation.RequireSignatures {
+			return errors.New("delegation request is not signed")
+		}
+		return nil
+	}
+	if skew := m.MeshTime().Sub(time.Unix(0, req.Timestamp)); skew > m.config.Delegation.MaxRequestSkew || skew < -m.config.Delegation.MaxRequestSkew {
+		return errors.New("delegation request timestamp outside allowed window")
+	}
+	if err := m.checkIdentityKey(req.Requester, req.PublicKey); err != nil {
+		return fmt.Errorf("delegation requester: %w", err)
+	}
+	if !ed25519.Verify(req.PublicKey, delegationRequestPayload(req), req.Signature) {
+		return errors.New("invalid delegation request signature")
+	}
+	return nil
+}
+
+// signDelegationResponse binds the result to the request it answers.
+func (m *MeshCoordinator) signDelegationResponse(req *DelegateRequest, resp *DelegationResponse) error {
+	if m.gossip == nil {
+		return errors.New("gossip manager unavailable for delegation signing")
+	}
+	resp.Executor = m.nodeID
+	resp.ExecutorKey = m.gossip.PublicKey()
+	resp.RequestSignature = req.Signature
+
+	sig, pub, err := m.gossip.SignAttestation(delegationResponsePayload(resp))
+	if err != nil {
+		return fmt.Errorf("failed to sign delegation response: %w", err)
+	}
+	if !bytes.Equal(pub, resp.ExecutorKey) {
+		return errors.New("identity key rotated while signing delegation response")
+	}
+	resp.Signature = sig
+	return nil
+}
+
+// verifyDelegationResponse checks that the executor signed this exact
+// request's result.
+func (m *MeshCoordinator) verifyDelegationResponse(req *DelegateRequest, resp *DelegationResponse) error {
+	if len(resp.Signature) == 0 {
+		if m.config.Delegation.RequireSignatures {
+			return errors.New("delegation response is not signed")
+		}
+		return nil
+	}
+	if string(resp.RequestSignature) != string(req.Signature) {
+		return errors.New("delegation response answers a different request")
+	}
+	if err := m.checkIdentityKey(resp.Executor, resp.ExecutorKey); err != nil {
+		return fmt.Errorf("delegation executor: %w", err)
+	}
+	if !ed25519.Verify(resp.ExecutorKey, delegationResponsePayload(resp), resp.Signature) {
+		return errors.New("invalid delegation response signature")
+	}
+	return nil
+}
+
+// checkIdentityKey reports whether key may sign for nodeID: it must not be
+// revoked and must be the key the gossip directory binds to the node.
+func (m *MeshCoordinator) checkIdentityKey(nodeID string, key ed25519.PublicKey) error {
+	if nodeID == "" {
+		return errors.New("missing node id")
+	}
+	if len(key) != ed25519.PublicKeySize {
+		return errors.New("invalid public key")
+	}
+	if m.IsKeyRevoked(key) {
+		return errors.New("public key is revoked")
+	}
+	if nodeID == m.nodeID {
+		if !key.Equal(m.gossip.PublicKey()) {
+			return errors.New("public key does not match local identity")
+		}
+		return nil
+	}
+	return m.gossip.CheckPeerKey(nodeID, key)
+}
+
+// rejectDelegation penalizes a peer that sent a forged or stale request.
+func (m *MeshCoordinator) rejectDelegation(peerID string, err error) {
+	m.logger.Warn("rejected delegation request", "peer", getShortID(peerID), "error", err)
+	if m.reputation != nil {
+		m.reputation.ReportPenalty(peerID, routing.PenaltyInvalidData)
+	}
+}
+
+// recordDelegation appends the evidence of a delegation to the audit log,
+// chained to the previous record, dropping the oldest once the log is full.
+func (m *MeshCoordinator) recordDelegation(role string, req *DelegateRequest, resp *DelegationResponse) {
+	record := DelegationAuditRecord{
+		RequestID:        req.ID,
+		Role:             role,
+		Operation:        req.Operation,
+		Requester:        req.Requester,
+		RequesterKey:     req.PublicKey,
+		RequestPayload:   delegationRequestPayload(req),
+		RequestSignature: req.Signature,
+		RecordedAt:       time.Now(),
+	}
+	if resp != nil {
+		record.Executor = resp.Executor
+		record.ExecutorKey = resp.ExecutorKey
+		record.Status = resp.Status
+		record.ResponsePayload = delegationResponsePayload(resp)
+		record.ResponseSignature = resp.Signature
+	}
+
+	m.delegationAuditMu.Lock()
+	defer m.delegationAuditMu.Unlock()
+	if n := len(m.delegationAudit); n > 0 {
+		record.PrevHash = m.delegationAudit[n-1].Hash
+	}
+	record.Hash = record.ComputeHash()
+	if limit := m.config.Delegation.AuditLogSize; limit > 0 && len(m.delegationAudit) >= limit {
+		m.delegationAudit = append(m.delegationAudit[:0], m.delegationAudit[len(m.delegationAudit)-limit+1:]...)
+	}
+	m.delegationAudit = append(m.delegationAudit, record)
+}
+
+// GetDelegationAudit returns the audit records for one delegation request.
+func (m *MeshCoordinator) GetDelegationAudit(requestID string) []DelegationAuditRecord {
+	m.delegationAuditMu.Lock()
+	defer m.delegationAuditMu.Unlock()
+	var out []DelegationAuditRecord
+	for _, r := range m.delegationAudit {
+		if r.RequestID == requestID {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// GetDelegationAuditLog returns the audit log, oldest first.
+func (m *MeshCoordinator) GetDelegationAuditLog() []DelegationAuditRecord {
+	m.delegationAuditMu.Lock()
+	defer m.delegationAuditMu.Unlock()
+	return append([]DelegationAuditRecord(nil), m.delegationAudit...)
+}
+
+func delegationRequestPayload(req *DelegateRequest) []byte {
+	resource := sha256.Sum256(req.Resource)
+	buf := make([]byte, 0, 256)
+	buf = append(buf, delegationRequestVersion...)
+	buf = append(buf, 0)
+	buf = append(buf, req.ID...)
+	buf = append(buf, 0)
+	buf = append(buf, req.Operation...)
+	buf = append(buf, 0)
+	buf = append(buf, resource[:]...)
+	buf = append(buf, req.Params...)
+	buf = append(buf, 0)
+	buf = append(buf, req.Requester...)
+	buf = append(buf, 0)
+	buf = append(buf, req.PublicKey...)
+	buf = binary.BigEndian.AppendUint64(buf, uint64(req.Timestamp))
+	// Only appended when set, so requests without a module sign as before
+	if req.Module != "" {
+		buf = append(buf, 0)
+		buf = append(buf, req.Module...)
+	}
+	// Likewise the idempotency key, behind a separator no module hash
+	// contains so the two cannot be swapped
+	if req.IdempotencyKey != "" {
+		buf = append(buf, 1)
+		buf = append(buf, req.IdempotencyKey...)
+	}
+	return appendDelegationEnvelope(buf, req)
+}
+
+func delegationResponsePayload(resp *DelegationResponse) []byte {
+	resource := sha256.Sum256(resp.Resource)
+	buf := make([]byte, 0, 256)
+	buf = append(buf, delegationResponseVersion...)
+	buf = append(buf, 0)
+	buf = append(buf, resp.Status...)
+	buf = append(buf, 0)
+	buf = append(buf, resource[:]...)
+	buf = append(buf, resp.Error...)
+	buf = append(buf, 0)
+	buf = append(buf, resp.Executor...)
+	buf = append(buf, 0)
+	buf = append(buf, resp.ExecutorKey...)
+	buf = append(buf, resp.RequestSignature...)
+	// The receipt signs itself; binding its signature here keeps it from
+	// being swapped for another of the executor's receipts
+	if resp.Receipt != nil {
+		buf = append(buf, 2)
+		buf = append(buf, resp.Receipt.Signature...)
+	}
+	return buf
+}
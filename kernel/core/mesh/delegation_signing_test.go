@@ -0,0 +1,185 @@
+package mesh
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+	"github.com/nmxmxh/inos_v1/kernel/threads/foundation"
+)
+
+func newDelegationTestCoordinator(t *testing.T) (*MeshCoordinator, *MockTransport) {
+	t.Helper()
+	tr := &MockTransport{
+		nodeID:      "node-a",
+		rpcHandlers: make(map[string]func(args interface{}) (interface{}, error)),
+	}
+	coord := NewMeshCoordinator("node-a", "us-east", tr, nil)
+	coord.SetDispatcher(&mockDispatcher{
+		run: func(job *foundation.Job) *foundation.Result {
+			return &foundation.Result{JobID: job.ID, Success: true, Data: append([]byte("done:"), job.Data...)}
+		},
+	})
+	coord.peerMetricsMu.Lock()
+	coord.peerMetrics["peer-1"] = common.MeshMetrics{AvgReputation: 1.0, P50LatencyMs: 1.0}
+	coord.peerMetricsMu.Unlock()
+	trustPeers(coord, "peer-1")
+	return coord, tr
+}
+
+func TestDelegationSigning_RoundTripIsAudited(t *testing.T) {
+	coord, _ := newDelegationTestCoordinator(t)
+
+	if _, err := coord.DelegateCompute(context.Background(), "compress", "input-digest", []byte("source")); err != nil {
+		t.Fatalf("DelegateCompute failed: %v", err)
+	}
+
+	log := coord.GetDelegationAuditLog()
+	if len(log) != 2 {
+		t.Fatalf("expected executor and requester records, got %d", len(log))
+	}
+	if log[0].Role != DelegationRoleExecutor || log[1].Role != DelegationRoleRequester {
+		t.Fatalf("unexpected audit roles: %s, %s", log[0].Role, log[1].Role)
+	}
+	for _, record := range log {
+		if err := record.Verify(); err != nil {
+			t.Fatalf("%s record does not verify: %v", record.Role, err)
+		}
+		if record.Requester != "node-a" || record.Executor != "node-a" || record.Status != "success" {
+			t.Fatalf("unexpected %s record: %+v", record.Role, record)
+		}
+	}
+	if got := coord.GetDelegationAudit(log[0].RequestID); len(got) != 2 {
+		t.Fatalf("expected both records for request %s, got %d", log[0].RequestID, len(got))
+	}
+
+	tampered := log[1]
+	tampered.ResponsePayload = append([]byte(nil), tampered.ResponsePayload...)
+	tampered.ResponsePayload[0] ^= 0xFF
+	if err := tampered.Verify(); err == nil {
+		t.Fatal("expected tampered audit record to fail verification")
+	}
+}
+
+func TestDelegationSigning_ExecutorRejectsTamperedRequest(t *testing.T) {
+	coord, tr := newDelegationTestCoordinator(t)
+
+	req := DelegateRequest{ID: "deleg-1", Operation: "compress"}
+	if err := coord.signDelegationRequest(&req); err != nil {
+		t.Fatalf("signDelegationRequest failed: %v", err)
+	}
+	req.Operation = "mine"
+
+	args, _ := json.Marshal(req)
+	handler := tr.registeredRPCHandlers[delegateComputeMethod]
+	_, err := handler(capabilityContextFor(t, coord, "peer-1", delegateComputeMethod), "peer-1", args)
+	if err == nil || !strings.Contains(err.Error(), "signature") {
+		t.Fatalf("expected signature error, got %v", err)
+	}
+	if len(coord.GetDelegationAuditLog()) != 0 {
+		t.Fatal("rejected request must not be audited")
+	}
+}
+
+func TestDelegationSigning_RejectsKeyNotBoundToRequester(t *testing.T) {
+	coord, tr := newDelegationTestCoordinator(t)
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+	req := DelegateRequest{
+		ID:        "deleg-2",
+		Operation: "compress",
+		Requester: "node-victim",
+		PublicKey: pub,
+		Timestamp: time.Now().UnixNano(),
+	}
+	req.Signature = ed25519.Sign(priv, delegationRequestPayload(&req))
+
+	args, _ := json.Marshal(req)
+	handler := tr.registeredRPCHandlers[delegateComputeMethod]
+	_, err = handler(capabilityContextFor(t, coord, "peer-1", delegateComputeMethod), "peer-1", args)
+	if err == nil || !strings.Contains(err.Error(), "not bound") {
+		t.Fatalf("expected unbound key error, got %v", err)
+	}
+
+	// The same key signing for the node ID it derives is accepted
+	req.Requester = NodeIDFromPublicKey(pub)
+	req.Signature = ed25519.Sign(priv, delegationRequestPayload(&req))
+	if err := coord.verifyDelegationRequest(&req); err != nil {
+		t.Fatalf("expected self-certifying requester to verify: %v", err)
+	}
+}
+
+func TestDelegationSigning_RequesterRejectsResponseToOtherRequest(t *testing.T) {
+	coord, _ := newDelegationTestCoordinator(t)
+
+	first := DelegateRequest{ID: "deleg-1", Operation: "compress"}
+	second := DelegateRequest{ID: "deleg-2", Operation: "compress"}
+	for _, req := range []*DelegateRequest{&first, &second} {
+		if err := coord.signDelegationRequest(req); err != nil {
+			t.Fatalf("signDelegationRequest failed: %v", err)
+		}
+	}
+
+	resp := DelegationResponse{Status: "success"}
+	if err := coord.signDelegationResponse(&first, &resp); err != nil {
+		t.Fatalf("signDelegationResponse failed: %v", err)
+	}
+	if err := coord.verifyDelegationResponse(&first, &resp); err != nil {
+		t.Fatalf("expected response to verify: %v", err)
+	}
+	if err := coord.verifyDelegationResponse(&second, &resp); err == nil {
+		t.Fatal("expected replayed response to be rejected")
+	}
+}
+
+func TestDelegationSigning_AuditLogIsBounded(t *testing.T) {
+	coord, _ := newDelegationTestCoordinator(t)
+	coord.config.Delegation.AuditLogSize = 3
+
+	for _, id := range []string{"a", "b", "c", "d", "e"} {
+		coord.recordDelegation(DelegationRoleRequester, &DelegateRequest{ID: id}, nil)
+	}
+
+	log := coord.GetDelegationAuditLog()
+	if len(log) != 3 || log[0].RequestID != "c" || log[2].RequestID != "e" {
+		t.Fatalf("expected the newest three records, got %+v", log)
+	}
+}
+
+func TestDelegationSigning_AuditLogIsChainedAndExported(t *testing.T) {
+	coord, _ := newDelegationTestCoordinator(t)
+	if _, err := coord.DelegateCompute(context.Background(), "compress", "input-digest", []byte("source")); err != nil {
+		t.Fatalf("DelegateCompute failed: %v", err)
+	}
+
+	export := coord.ExportAudit()
+	if export.Version != AuditExportVersion || export.NodeID != "node-a" || !export.PublicKey.Equal(coord.gossip.PublicKey()) {
+		t.Fatalf("unexpected export header %+v", export)
+	}
+	if len(export.Audit) != 2 || len(export.Audit[0].PrevHash) != 0 {
+		t.Fatalf("unexpected audit log %+v", export.Audit)
+	}
+	for i, record := range export.Audit {
+		if string(record.Hash) != string(record.ComputeHash()) {
+			t.Fatalf("record %d hash does not match its contents", i)
+		}
+	}
+	if string(export.Audit[1].PrevHash) != string(export.Audit[0].Hash) {
+		t.Fatal("expected the second record to link to the first")
+	}
+
+	if _, err := coord.RotateIdentityKey(); err != nil {
+		t.Fatalf("RotateIdentityKey failed: %v", err)
+	}
+	if export := coord.ExportAudit(); len(export.PriorKeys) != 1 {
+		t.Fatalf("expected the rotated key in the export, got %d", len(export.PriorKeys))
+	}
+}
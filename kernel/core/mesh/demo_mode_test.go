@@ -0,0 +1,93 @@
+package mesh
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/nmxmxh/inos_v1/kernel/threads/foundation"
+)
+
+func TestDemoMode_SyntheticPeersServeWorkAndStayFlagged(t *testing.T) {
+	tr := &MockTransport{nodeID: "self"}
+	coord := NewMeshCoordinator("self", "us-east", tr, nil)
+	coord.config.WorkQueue.Enabled = false
+	coord.SetStorage(&MockStorage{chunks: make(map[string][]byte)})
+	coord.SetDispatcher(&mockDispatcher{run: func(job *foundation.Job) *foundation.Result {
+		return &foundation.Result{JobID: job.ID, Success: true, Data: append([]byte("done:"), job.Data...)}
+	}})
+	coord.EnableDemoMode(3)
+
+	if err := coord.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer coord.Stop()
+
+	telemetry := coord.GetTelemetry()
+	if telemetry["demo_mode"] != true || telemetry["synthetic_peers"] != 3 {
+		t.Fatalf("expected demo mode with 3 synthetic peers, got %v / %v", telemetry["demo_mode"], telemetry["synthetic_peers"])
+	}
+
+	// Delegation lands on a synthetic peer and returns a real result.
+	result, err := coord.DelegateJob(context.Background(), &foundation.Job{ID: "job-1", Operation: "noop", Data: []byte("x")})
+	if err != nil {
+		t.Fatalf("DelegateJob failed: %v", err)
+	}
+	if !result.Success || string(result.Data) != "done:x" {
+		t.Fatalf("unexpected delegated result %+v", result)
+	}
+
+	// Chunks replicate to synthetic peers and can be fetched back.
+	data := []byte("demo chunk payload")
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	stored, err := coord.DistributeChunk(context.Background(), hash, data)
+	if err != nil || stored == 0 {
+		t.Fatalf("DistributeChunk stored %d replicas: %v", stored, err)
+	}
+	held := 0
+	for _, peer := range coord.sim.Peers() {
+		if _, ok := peer.chunk(hash); ok {
+			held++
+		}
+	}
+	if held == 0 {
+		t.Fatal("expected a synthetic peer to hold the chunk")
+	}
+
+	// Synthetic peers are visible locally but never in gossiped metrics.
+	coord.updateMetrics()
+	coord.metricsMu.RLock()
+	totalPeers := coord.metrics.TotalPeers
+	coord.metricsMu.RUnlock()
+	if totalPeers != 0 {
+		t.Fatalf("expected synthetic peers excluded from reported peers, got %d", totalPeers)
+	}
+	global := coord.GetGlobalMetrics()
+	if global.SyntheticNodeCount != 3 {
+		t.Fatalf("expected synthetic nodes flagged in global metrics, got %d", global.SyntheticNodeCount)
+	}
+}
+
+func TestSimTransport_PassesRealPeersThrough(t *testing.T) {
+	inner := &MockTransport{nodeID: "self", rpcHandlers: map[string]func(args interface{}) (interface{}, error){
+		"echo": func(args interface{}) (interface{}, error) { return args, nil },
+	}}
+	sim := NewSimTransport(inner)
+	sim.AddPeer(NewSimPeer("a", "us-east", 0, 10000))
+
+	var reply string
+	if err := sim.SendRPC(context.Background(), "real-peer", "echo", "hello", &reply); err != nil || reply != "hello" {
+		t.Fatalf("expected real peer RPC to reach the inner transport, got %q, %v", reply, err)
+	}
+	if !sim.IsConnected("sim:a") {
+		t.Fatal("expected synthetic peer to be connected")
+	}
+	if err := sim.SendRPC(context.Background(), "sim:a", attestationMethod, nil, nil); err == nil {
+		t.Fatal("expected synthetic peers to refuse attestation")
+	}
+	if got := sim.GetConnectionMetrics().ActiveConnections; got != 0 {
+		t.Fatalf("expected synthetic peers kept out of connection metrics, got %d", got)
+	}
+}
@@ -0,0 +1,227 @@
+package mesh
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+)
+
+const (
+	peerLeaveTopic    = "peer_leave"
+	workReleaseMethod = "mesh.ReleaseJob"
+)
+
+// ErrDeparting is returned for work offered to a node that is leaving the mesh.
+var ErrDeparting = errors.New("node is leaving the mesh")
+
+// PeerLeave is gossiped by a node shortly before it disconnects, so peers
+// drop its routes and provider records instead of waiting for timeouts.
+type PeerLeave struct {
+	PeerID string `json:"peer_id"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// DepartureReport describes what a node handed off before leaving.
+type DepartureReport struct {
+	Announced    bool          `json:"announced"`
+	ReleasedJobs int           `json:"released_jobs"` // Pulled jobs returned to their requesters
+	UniqueChunks int           `json:"unique_chunks"` // Local chunks with no other known provider
+	PushedChunks int           `json:"pushed_chunks"` // Unique chunks placed on a replica before leaving
+	Persisted    bool          `json:"persisted"`
+	Elapsed      time.Duration `json:"elapsed"`
+	Errors       []string      `json:"errors,omitempty"`
+}
+
+// Depart hands off this node's state before shutdown: it persists the ledger
+// and reputation, announces the leave, returns pulled jobs to their requesters
+// and pushes chunks no other peer holds. Network steps share the configured
+// budget and are best-effort. Jobs delegated to us directly are refused from
+// here on and surface as errors to their callers. Depart does not stop the
+// coordinator; call Stop afterwards.
+func (m *MeshCoordinator) Depart(ctx context.Context, reason string) DepartureReport {
+	start := time.Now()
+	var report DepartureReport
+	if !m.departing.CompareAndSwap(false, true) {
+		report.Errors = append(report.Errors, "departure already in progress")
+		return report
+	}
+
+	// Persist first: it is synchronous and must land even if the page is
+	// torn down before the network steps finish
+	if err := m.persistState(); err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("persist: %v", err))
+	} else {
+		report.Persisted = true
+	}
+
+	budget := m.config.Departure.Budget
+	if budget <= 0 {
+		budget = 3 * time.Second
+	}
+	handoffCtx, cancel := context.WithTimeout(ctx, budget)
+	defer cancel()
+
+	if m.gossip != nil {
+		if err := m.gossip.Broadcast(peerLeaveTopic, PeerLeave{PeerID: m.nodeID, Reason: reason}); err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("announce: %v", err))
+		} else {
+			report.Announced = true
+		}
+	}
+
+	released, err := m.releasePulledWork(handoffCtx)
+	report.ReleasedJobs = released
+	if err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("release jobs: %v", err))
+	}
+
+	report.UniqueChunks, report.PushedChunks = m.pushUniqueChunks(handoffCtx)
+
+	report.Elapsed = time.Since(start)
+	m.logger.Info("departed mesh",
+		"reason", reason,
+		"released_jobs", report.ReleasedJobs,
+		"unique_chunks", report.UniqueChunks,
+		"pushed_chunks", report.PushedChunks,
+		"persisted", report.Persisted,
+		"elapsed", report.Elapsed,
+	)
+	return report
+}
+
+// IsDeparting reports whether Depart has been called.
+func (m *MeshCoordinator) IsDeparting() bool {
+	return m.departing.Load()
+}
+
+// releasePulledWork hands every job this node pulled back to its requester.
+func (m *MeshCoordinator) releasePulledWork(ctx context.Context) (int, error) {
+	m.pulledWorkMu.Lock()
+	pulled := make(map[string]string, len(m.pulledWork))
+	for jobID, requester := range m.pulledWork {
+		pulled[jobID] = requester
+	}
+	m.pulledWorkMu.Unlock()
+
+	released := 0
+	var errs []error
+	for jobID, requester := range pulled {
+		if ctx.Err() != nil {
+			errs = append(errs, ctx.Err())
+			break
+		}
+		var ack workCompletionAck
+		if err := m.transport.SendRPC(ctx, requester, workReleaseMethod, workReleaseRequest{JobID: jobID}, &ack); err != nil {
+			errs = append(errs, fmt.Errorf("job %s: %w", jobID, err))
+			continue
+		}
+		released++
+	}
+	return released, errors.Join(errs...)
+}
+
+// pushUniqueChunks copies chunks that no other known provider holds to the
+// best replica candidate, up to Departure.MaxChunks.
+func (m *MeshCoordinator) pushUniqueChunks(ctx context.Context) (unique, pushed int) {
+	if m.storage == nil {
+		return 0, 0
+	}
+
+	m.localChunksMu.RLock()
+	var candidates []string
+	for chunkHash := range m.localChunks {
+		if !m.hasOtherProvider(chunkHash) {
+			candidates = append(candidates, chunkHash)
+		}
+	}
+	m.localChunksMu.RUnlock()
+
+	unique = len(candidates)
+	limit := m.config.Departure.MaxChunks
+	for i, chunkHash := range candidates {
+		if ctx.Err() != nil || (limit > 0 && i >= limit) {
+			break
+		}
+		data, err := m.storage.FetchChunk(ctx, chunkHash)
+		if err != nil {
+			continue
+		}
+		if _, err := m.placeReplica(ctx, chunkHash, data, ""); err != nil {
+			m.logger.Debug("could not hand off chunk", "chunk", getShortID(chunkHash), "error", err)
+			continue
+		}
+		pushed++
+	}
+	return unique, pushed
+}
+
+func (m *MeshCoordinator) hasOtherProvider(chunkHash string) bool {
+	for _, provider := range m.dht.LocalProviders(chunkHash) {
+		if provider != m.nodeID {
+			return true
+		}
+	}
+	return false
+}
+
+// handlePeerLeave forgets a departed peer right away: its provider records,
+// metrics and routes go, and any of our jobs it held are re-announced.
+func (m *MeshCoordinator) handlePeerLeave(peerID string) {
+	removed := m.dht.RemoveProvider(peerID)
+
+	m.peerMetricsMu.Lock()
+	delete(m.peerMetrics, peerID)
+	m.peerMetricsMu.Unlock()
+
+	m.pendingWorkMu.Lock()
+	var held []string
+	for jobID, item := range m.pendingWork {
+		if item.claimedBy == peerID {
+			held = append(held, jobID)
+		}
+	}
+	m.pendingWorkMu.Unlock()
+	for _, jobID := range held {
+		_ = m.releaseWork(peerID, jobID)
+	}
+
+	m.handleTransportPeerEvent(peerID, false)
+	m.logger.Debug("peer left mesh", "peer", getShortID(peerID), "provider_records", removed, "released_jobs", len(held))
+}
+
+func (m *MeshCoordinator) registerDepartureHandler() {
+	m.registerRPC(workReleaseMethod, func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error) {
+		var req workReleaseRequest
+		if err := json.Unmarshal(args, &req); err != nil {
+			return nil, fmt.Errorf("failed to decode job release: %w", err)
+		}
+		if err := m.releaseWork(peerID, req.JobID); err != nil {
+			return nil, err
+		}
+		return workCompletionAck{Accepted: true}, nil
+	})
+}
+
+func (m *MeshCoordinator) registerDepartureGossip() {
+	m.gossip.RegisterHandler(peerLeaveTopic, func(msg *common.GossipMessage) error {
+		payload, ok := msg.Payload.(map[string]interface{})
+		if !ok {
+			return errors.New("invalid payload type for peer_leave")
+		}
+
+		peerID, _ := payload["peer_id"].(string)
+		if peerID != msg.Sender {
+			return errors.New("peer leave does not match sender")
+		}
+		if peerID == "" || peerID == m.nodeID {
+			return nil
+		}
+
+		m.handlePeerLeave(peerID)
+		return nil
+	})
+}
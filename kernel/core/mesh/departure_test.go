@@ -0,0 +1,182 @@
+package mesh
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/threads/foundation"
+)
+
+type memoryLedgerStore struct {
+	snapshot *LedgerSnapshot
+}
+
+func (s *memoryLedgerStore) SaveLedger(snapshot LedgerSnapshot) error {
+	s.snapshot = &snapshot
+	return nil
+}
+
+func (s *memoryLedgerStore) LoadLedger() (LedgerSnapshot, error) {
+	if s.snapshot == nil {
+		return LedgerSnapshot{}, ErrNoLedgerSnapshot
+	}
+	return *s.snapshot, nil
+}
+
+func TestDepart_ReleasedJobIsReannounced(t *testing.T) {
+	tr := &MockTransport{nodeID: "requester"}
+	coord := NewMeshCoordinator("requester", "us-east", tr, nil)
+
+	done := make(chan *foundation.Result, 1)
+	go func() {
+		res, _ := coord.SubmitWork(context.Background(), &foundation.Job{ID: "job-1", Operation: "hash", Data: []byte("abc")})
+		done <- res
+	}()
+	waitForPendingWork(t, coord, "job-1")
+
+	claim := tr.registeredRPCHandlers[workClaimMethod]
+	release := tr.registeredRPCHandlers[workReleaseMethod]
+	complete := tr.registeredRPCHandlers[workCompleteMethod]
+	args := json.RawMessage(`{"job_id":"job-1"}`)
+
+	if _, err := claim(context.Background(), "leaver", args); err != nil {
+		t.Fatalf("claim failed: %v", err)
+	}
+	if _, err := release(context.Background(), "other", args); err == nil {
+		t.Fatal("expected release from a non-claimant to be rejected")
+	}
+	if _, err := release(context.Background(), "leaver", args); err != nil {
+		t.Fatalf("release failed: %v", err)
+	}
+	if active := coord.activeJobs["leaver"]; active != 0 {
+		t.Fatalf("expected released slot to be freed, got %d", active)
+	}
+
+	// The job goes back on the queue; give the requester a moment to re-announce
+	deadline := time.Now().Add(time.Second)
+	for {
+		if _, err := claim(context.Background(), "worker", args); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("released job was never claimable again")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	completion, _ := json.Marshal(workCompletion{JobID: "job-1", Success: true, Data: []byte("digest")})
+	if _, err := complete(context.Background(), "leaver", completion); err == nil {
+		t.Fatal("expected a late completion from the departed claimant to be rejected")
+	}
+	if _, err := complete(context.Background(), "worker", completion); err != nil {
+		t.Fatalf("completion failed: %v", err)
+	}
+
+	select {
+	case res := <-done:
+		if res == nil || string(res.Data) != "digest" {
+			t.Fatalf("unexpected result %+v", res)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("SubmitWork did not return")
+	}
+}
+
+func TestDepart_PersistsStateAndRefusesWork(t *testing.T) {
+	coord, tr := newDelegationTestCoordinator(t)
+	store := &memoryLedgerStore{}
+	if err := coord.SetLedgerStore(store); err != nil {
+		t.Fatalf("SetLedgerStore failed: %v", err)
+	}
+	coord.ledger.GrantEarlyAdopterBonus("did:alice", 250)
+
+	var released []string
+	tr.rpcHandlers[workReleaseMethod] = func(args interface{}) (interface{}, error) {
+		released = append(released, args.(workReleaseRequest).JobID)
+		return workCompletionAck{Accepted: true}, nil
+	}
+	coord.pulledWork["pulled-1"] = "requester"
+
+	report := coord.Depart(context.Background(), "test")
+	if !report.Persisted || report.ReleasedJobs != 1 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	if len(released) != 1 || released[0] != "pulled-1" {
+		t.Fatalf("expected pulled job to be released, got %v", released)
+	}
+	if again := coord.Depart(context.Background(), "test"); len(again.Errors) == 0 {
+		t.Fatal("expected a second Depart to be a no-op")
+	}
+
+	job := json.RawMessage(`{"id":"late","operation":"hash"}`)
+	if _, err := tr.registeredRPCHandlers[executeJobMethod](capabilityContextFor(t, coord, "peer-1", executeJobMethod), "peer-1", job); !errors.Is(err, ErrDeparting) {
+		t.Fatalf("expected ErrDeparting, got %v", err)
+	}
+
+	next, _ := newDelegationTestCoordinator(t)
+	if err := next.SetLedgerStore(store); err != nil {
+		t.Fatalf("restore failed: %v", err)
+	}
+	if balance := next.ledger.GetBalance("did:alice"); balance != 250 {
+		t.Fatalf("expected restored balance 250, got %d", balance)
+	}
+}
+
+func TestDepart_PeerLeaveForgetsPeer(t *testing.T) {
+	tr := &MockTransport{nodeID: "requester"}
+	coord := NewMeshCoordinator("requester", "us-east", tr, nil)
+	_ = coord.dht.Store("chunk-1", "leaver", 3600)
+	coord.peerMetrics["leaver"] = coord.peerMetrics["requester"]
+
+	go func() {
+		_, _ = coord.SubmitWork(context.Background(), &foundation.Job{ID: "job-1", Operation: "hash"})
+	}()
+	waitForPendingWork(t, coord, "job-1")
+	if _, err := coord.claimWork("leaver", "job-1"); err != nil {
+		t.Fatalf("claim failed: %v", err)
+	}
+
+	coord.handlePeerLeave("leaver")
+
+	if providers := coord.dht.LocalProviders("chunk-1"); len(providers) != 0 {
+		t.Fatalf("expected provider records to be dropped, got %v", providers)
+	}
+	if _, ok := coord.peerMetrics["leaver"]; ok {
+		t.Fatal("expected peer metrics to be dropped")
+	}
+	coord.pendingWorkMu.Lock()
+	claimedBy := coord.pendingWork["job-1"].claimedBy
+	coord.pendingWorkMu.Unlock()
+	if claimedBy != "" {
+		t.Fatalf("expected job held by departed peer to be released, still claimed by %q", claimedBy)
+	}
+}
+
+func TestLedgerSnapshot_RoundTrip(t *testing.T) {
+	ledger := NewEconomicLedger()
+	ledger.GrantEarlyAdopterBonus("did:bob", 40)
+	ledger.RecordWorkClaim("peer-1")
+	ledger.RecordWorkOutcome("peer-1", true, 12)
+
+	data, err := json.Marshal(ledger.Snapshot())
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	var snapshot LedgerSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+
+	restored := NewEconomicLedger()
+	restored.Restore(snapshot)
+	if restored.GetBalance("did:bob") != 40 {
+		t.Fatalf("expected balance 40, got %d", restored.GetBalance("did:bob"))
+	}
+	share, ok := restored.GetWorkShare("peer-1")
+	if !ok || share.Completed != 1 {
+		t.Fatalf("expected work share to survive, got %+v", share)
+	}
+}
@@ -0,0 +1,57 @@
+package mesh
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/routing"
+)
+
+// configureDHTSecurity applies the DHT hardening settings and installs the
+// node ID check.
+func (m *MeshCoordinator) configureDHTSecurity() {
+	cfg := m.config.DHT
+	m.dht.SetSecurityConfig(routing.DHTSecurityConfig{
+		DisjointPaths:      cfg.DisjointPaths,
+		MaxPerSubnet:       cfg.MaxPerSubnet,
+		RequireVerifiedIDs: cfg.RequireVerifiedIDs,
+	})
+	m.dht.SetNodeIDVerifier(m.verifyDHTNodeID)
+}
+
+// verifyDHTNodeID accepts a peer whose ID was derived from its key, so IDs
+// near a target cannot be picked at will. With IDPuzzleBits set the key must
+// also solve a static puzzle, which makes minting many IDs expensive. A node
+// keeps its ID across key rotations, so a key that no longer derives the ID
+// is accepted only once this node has attested the peer holding it.
+func (m *MeshCoordinator) verifyDHTNodeID(peer PeerInfo) error {
+	if len(peer.PublicKey) != ed25519.PublicKeySize {
+		return fmt.Errorf("%w: malformed key", routing.ErrUnverifiedNodeID)
+	}
+	pub := ed25519.PublicKey(peer.PublicKey)
+
+	if NodeIDFromPublicKey(pub) != peer.ID {
+		m.attestationMu.RLock()
+		record, attested := m.attestedPeers[peer.ID]
+		m.attestationMu.RUnlock()
+		if !attested || !bytes.Equal(record.PublicKey, pub) {
+			return fmt.Errorf("%w: %s does not match its key", routing.ErrUnverifiedNodeID, getShortID(peer.ID))
+		}
+		return nil
+	}
+
+	if need := m.config.DHT.IDPuzzleBits; need > 0 && nodeIDPuzzleBits(pub) < need {
+		return errors.New("node ID key does not solve the ID puzzle")
+	}
+	return nil
+}
+
+// nodeIDPuzzleBits counts the leading zero bits of sha256(sha256(key)).
+func nodeIDPuzzleBits(pub ed25519.PublicKey) int {
+	first := sha256.Sum256(pub)
+	second := sha256.Sum256(first[:])
+	return leadingZeroBits(second[:])
+}
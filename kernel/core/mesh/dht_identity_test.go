@@ -0,0 +1,41 @@
+package mesh
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/routing"
+)
+
+func TestDHTIdentity_NodeIDBoundToKey(t *testing.T) {
+	coord := NewMeshCoordinator("self", "us-east", &MockTransport{nodeID: "self"}, nil)
+	pub, _, _ := ed25519.GenerateKey(rand.Reader)
+	id := NodeIDFromPublicKey(pub)
+
+	if err := coord.verifyDHTNodeID(PeerInfo{ID: id, PublicKey: pub}); err != nil {
+		t.Fatalf("derived ID rejected: %v", err)
+	}
+	if err := coord.dht.AddPeer(PeerInfo{ID: "node:chosen-near-target", PublicKey: pub}); !errors.Is(err, routing.ErrUnverifiedNodeID) {
+		t.Fatalf("expected a chosen ID to be refused, got %v", err)
+	}
+
+	// After a key rotation the ID stays; the new key counts once attested
+	rotated, _, _ := ed25519.GenerateKey(rand.Reader)
+	if err := coord.verifyDHTNodeID(PeerInfo{ID: id, PublicKey: rotated}); err == nil {
+		t.Fatal("expected an unattested rotated key to be refused")
+	}
+	coord.attestedPeers[id] = AttestationRecord{PublicKey: rotated, Attested: time.Now()}
+	if err := coord.verifyDHTNodeID(PeerInfo{ID: id, PublicKey: rotated}); err != nil {
+		t.Fatalf("attested rotated key rejected: %v", err)
+	}
+
+	coord.config.DHT.IDPuzzleBits = 24
+	if nodeIDPuzzleBits(pub) < 24 {
+		if err := coord.verifyDHTNodeID(PeerInfo{ID: id, PublicKey: pub}); err == nil {
+			t.Fatal("expected a key without puzzle work to be refused")
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package mesh
+
+import (
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/routing"
+	system "github.com/nmxmxh/inos_v1/kernel/gen/system/v1"
+	"github.com/nmxmxh/inos_v1/kernel/runtime"
+)
+
+// applyDHTMode picks the DHT mode for a role config. In auto mode low-memory
+// devices stay clients for good, sentries start as clients and may be
+// promoted once they prove stable, and everything else serves.
+func (m *MeshCoordinator) applyDHTMode(config runtime.RoleConfig) {
+	mode := routing.DHTModeServer
+	promotable := false
+
+	switch m.config.DHT.Mode {
+	case string(routing.DHTModeServer):
+	case string(routing.DHTModeClient):
+		mode = routing.DHTModeClient
+	default:
+		if config.Memory.Tier == runtime.MemoryLow {
+			mode = routing.DHTModeClient
+		} else if config.Role == system.Runtime_RuntimeRole_sentry {
+			mode = routing.DHTModeClient
+			promotable = true
+		}
+	}
+
+	m.dhtMu.Lock()
+	m.dhtRole = config.Role
+	m.dhtPromotable = promotable
+	if mode == routing.DHTModeClient && m.dht.Mode() != routing.DHTModeClient {
+		m.dhtClientSince = time.Now()
+	}
+	m.dhtMu.Unlock()
+
+	m.dht.SetMode(mode)
+	m.logger.Info("dht mode selected", "mode", mode, "promotable", promotable)
+	m.advertiseDHTMode()
+}
+
+// maybePromoteDHT switches a promotable client to server mode once it has
+// been up for PromoteAfter with enough connected peers.
+func (m *MeshCoordinator) maybePromoteDHT() {
+	if m.dht.Mode() != routing.DHTModeClient {
+		return
+	}
+
+	m.dhtMu.Lock()
+	eligible := m.dhtPromotable && time.Since(m.dhtClientSince) >= m.config.DHT.PromoteAfter
+	m.dhtMu.Unlock()
+	if !eligible {
+		return
+	}
+
+	peers := len(m.transport.GetConnectedPeers())
+	if peers < m.config.DHT.PromoteMinPeers {
+		return
+	}
+
+	m.dhtMu.Lock()
+	m.dhtPromotable = false
+	m.dhtMu.Unlock()
+
+	m.dht.SetMode(routing.DHTModeServer)
+	m.logger.Info("promoted to dht server", "connected_peers", peers)
+	m.advertiseDHTMode()
+}
+
+// advertiseDHTMode announces the current mode so peers stop routing queries
+// to clients. The announcement replaces the cached capability, so it also
+// carries the GPU adapter, if any, the power state (a constrained node
+// withholds its GPU and marks itself power-saving), whether it is busy,
+// the contributions the role profile withholds, whether it relays for
+// others and the hardware descriptor placement ranks it by.
+func (m *MeshCoordinator) advertiseDHTMode() {
+	m.dhtMu.Lock()
+	role := m.dhtRole
+	m.dhtMu.Unlock()
+
+	capabilities := append([]string{m.dht.Mode().Capability()}, m.contributionCapabilities()...)
+	gpu := m.getLocalGPU()
+	if m.PowerConstrained() {
+		gpu = nil
+		capabilities = append(capabilities, powerSavingCapability)
+	}
+	if gpu != nil {
+		capabilities = append(capabilities, "gpu")
+	}
+	if m.busy.Load() {
+		capabilities = append(capabilities, busyCapability)
+	}
+	if m.offersRelay() {
+		capabilities = append(capabilities, relayCapability)
+	}
+	hardware := m.getLocalHardware()
+	if m.decider != nil {
+		m.decider.SetLocalHardware(hardware)
+	}
+	if err := m.AnnounceCapability(&PeerCapability{
+		PeerID:        m.nodeID,
+		Region:        m.region,
+		Role:          role,
+		Capabilities:  capabilities,
+		LastSeen:      time.Now().UnixNano(),
+		GPU:           gpu,
+		SchemaVersion: common.CapabilitySchemaVersion,
+		Hardware:      hardware,
+	}); err != nil {
+		m.logger.Debug("failed to advertise dht mode", "error", err)
+	}
+}
@@ -0,0 +1,72 @@
+package mesh
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/routing"
+	system "github.com/nmxmxh/inos_v1/kernel/gen/system/v1"
+	"github.com/nmxmxh/inos_v1/kernel/runtime"
+)
+
+type connectedPeersTransport struct {
+	MockTransport
+	peers []string
+}
+
+func (t *connectedPeersTransport) GetConnectedPeers() []string { return t.peers }
+
+func TestApplyRoleConfig_SelectsDHTMode(t *testing.T) {
+	cases := []struct {
+		role       system.Runtime_RuntimeRole
+		tier       runtime.MemoryTier
+		want       routing.DHTMode
+		promotable bool
+	}{
+		{system.Runtime_RuntimeRole_sentry, runtime.MemoryLow, routing.DHTModeClient, false},
+		{system.Runtime_RuntimeRole_neuron, runtime.MemoryLow, routing.DHTModeClient, false},
+		{system.Runtime_RuntimeRole_sentry, runtime.MemoryMedium, routing.DHTModeClient, true},
+		{system.Runtime_RuntimeRole_synapse, runtime.MemoryHigh, routing.DHTModeServer, false},
+	}
+	for _, tc := range cases {
+		coord := NewMeshCoordinator("self", "us-east", &MockTransport{nodeID: "self"}, nil)
+		coord.ApplyRoleConfig(runtime.RoleConfig{Role: tc.role, Memory: runtime.MemoryProfileFor(tc.tier)})
+		if got := coord.dht.Mode(); got != tc.want {
+			t.Fatalf("%s/%s: expected %s mode, got %s", tc.role, tc.tier, tc.want, got)
+		}
+		if coord.dhtPromotable != tc.promotable {
+			t.Fatalf("%s/%s: expected promotable=%v", tc.role, tc.tier, tc.promotable)
+		}
+		if mode := coord.GetTelemetry()["dht_mode"]; mode != string(tc.want) {
+			t.Fatalf("expected dht_mode %s in telemetry, got %v", tc.want, mode)
+		}
+	}
+}
+
+func TestMaybePromoteDHT(t *testing.T) {
+	tr := &connectedPeersTransport{MockTransport: MockTransport{nodeID: "self"}}
+	coord := NewMeshCoordinator("self", "us-east", tr, nil)
+	coord.config.DHT.PromoteMinPeers = 2
+	coord.ApplyRoleConfig(runtime.RoleConfig{
+		Role:   system.Runtime_RuntimeRole_sentry,
+		Memory: runtime.MemoryProfileFor(runtime.MemoryMedium),
+	})
+
+	coord.maybePromoteDHT()
+	if coord.dht.Mode() != routing.DHTModeClient {
+		t.Fatal("promoted before PromoteAfter elapsed")
+	}
+
+	coord.dhtClientSince = time.Now().Add(-coord.config.DHT.PromoteAfter)
+	tr.peers = []string{"peer-1"}
+	coord.maybePromoteDHT()
+	if coord.dht.Mode() != routing.DHTModeClient {
+		t.Fatal("promoted without enough connected peers")
+	}
+
+	tr.peers = []string{"peer-1", "peer-2"}
+	coord.maybePromoteDHT()
+	if coord.dht.Mode() != routing.DHTModeServer {
+		t.Fatal("stable sentry was not promoted to server")
+	}
+}
@@ -0,0 +1,429 @@
+package mesh
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/routing"
+	"github.com/nmxmxh/inos_v1/kernel/threads/foundation"
+)
+
+// A requester that doubts a delegated result disputes the executor's
+// receipt: a committee of high-reputation peers re-executes the job on the
+// same input and each signs a verdict. When a quorum agrees on an output
+// other than the one the executor signed for, the executor is slashed on
+// the ledger and the requester compensated.
+const disputeReviewMethod = "mesh.DisputeReview"
+
+const disputeVerdictVersion = "inos-dispute-verdict-v1"
+
+// Dispute outcomes.
+const (
+	DisputeConfirmed    = "confirmed"    // A quorum disproved the receipt
+	DisputeRejected     = "rejected"     // A quorum reproduced the receipt's output
+	DisputeInconclusive = "inconclusive" // Too few reviewers agreed either way
+)
+
+// Verdict outcomes.
+const (
+	VerdictUpheld    = "upheld"    // Re-execution produced a different output
+	VerdictDismissed = "dismissed" // Re-execution produced the receipt's output
+	VerdictAbstained = "abstained" // The reviewer could not re-execute the job
+)
+
+var ErrDisputeFiled = errors.New("dispute already decided for this job")
+
+// Dispute is a requester's challenge of one receipt and its outcome.
+type Dispute struct {
+	ID        string            `json:"id"`
+	Receipt   DelegationReceipt `json:"receipt"`
+	Committee []string          `json:"committee"`
+	Verdicts  []DisputeVerdict  `json:"verdicts"` // Valid verdicts only
+	Status    string            `json:"status"`
+	Expected  string            `json:"expected_digest,omitempty"` // Output digest the upholding quorum agreed on
+	Slashed   uint64            `json:"slashed,omitempty"`         // Credits taken from the executor
+	Paid      uint64            `json:"paid,omitempty"`            // Credits paid to this node
+	FiledAt   time.Time         `json:"filed_at"`
+	Resolved  time.Time         `json:"resolved_at"`
+}
+
+// DisputeVerdict is one reviewer's signed finding on a dispute.
+type DisputeVerdict struct {
+	DisputeID    string `json:"dispute_id"`
+	JobID        string `json:"job_id"`
+	Reviewer     string `json:"reviewer"`
+	ReviewerKey  []byte `json:"reviewer_key"`
+	Outcome      string `json:"outcome"`
+	OutputDigest string `json:"output_digest,omitempty"` // What re-execution produced
+	Reason       string `json:"reason,omitempty"`
+	Signature    []byte `json:"signature"`
+}
+
+// Verify checks the reviewer's signature over the verdict.
+func (v *DisputeVerdict) Verify() error {
+	if len(v.ReviewerKey) != ed25519.PublicKeySize || !ed25519.Verify(v.ReviewerKey, disputeVerdictPayload(v), v.Signature) {
+		return errors.New("invalid dispute verdict signature")
+	}
+	return nil
+}
+
+func disputeVerdictPayload(v *DisputeVerdict) []byte {
+	buf := make([]byte, 0, 256)
+	buf = append(buf, disputeVerdictVersion...)
+	for _, field := range []string{v.DisputeID, v.JobID, v.Reviewer, string(v.ReviewerKey), v.Outcome, v.OutputDigest, v.Reason} {
+		buf = append(buf, 0)
+		buf = append(buf, field...)
+	}
+	return buf
+}
+
+type disputeReviewRequest struct {
+	DisputeID string            `json:"dispute_id"`
+	Receipt   DelegationReceipt `json:"receipt"`
+	Input     []byte            `json:"input"` // The job's raw input, matching the receipt's input digest
+}
+
+func (m *MeshCoordinator) registerDisputeHandler() {
+	m.registerRPC(disputeReviewMethod, func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error) {
+		var req disputeReviewRequest
+		if err := json.Unmarshal(args, &req); err != nil {
+			return nil, fmt.Errorf("failed to decode dispute: %w", err)
+		}
+		if err := m.checkDisputeEvidence(&req.Receipt, req.Input); err != nil {
+			return nil, err
+		}
+		if req.Receipt.Requester != peerID {
+			return nil, errors.New("only the requester can dispute a receipt")
+		}
+		if req.Receipt.Executor == m.nodeID {
+			return nil, errors.New("cannot review own receipt")
+		}
+
+		verdict := m.reviewDispute(ctx, &req)
+		if err := m.signDisputeVerdict(&verdict); err != nil {
+			return nil, err
+		}
+		return verdict, nil
+	})
+}
+
+// checkDisputeEvidence checks that a receipt can be disputed with input.
+func (m *MeshCoordinator) checkDisputeEvidence(receipt *DelegationReceipt, input []byte) error {
+	if receipt.Status != "success" {
+		return errors.New("only successful jobs can be disputed")
+	}
+	if limit := m.config.Disputes.MaxInputBytes; limit > 0 && len(input) > limit {
+		return fmt.Errorf("disputed input is %d bytes, limit is %d", len(input), limit)
+	}
+	if err := m.VerifyDelegationReceipt(receipt, input, nil); err != nil {
+		return fmt.Errorf("dispute evidence rejected: %w", err)
+	}
+	return nil
+}
+
+// reviewDispute re-executes a disputed job and compares the output with
+// the receipt. The run is sandboxed and charged to the requester.
+func (m *MeshCoordinator) reviewDispute(ctx context.Context, req *disputeReviewRequest) DisputeVerdict {
+	verdict := DisputeVerdict{DisputeID: req.DisputeID, JobID: req.Receipt.JobID}
+	if m.dispatcher == nil {
+		verdict.Outcome, verdict.Reason = VerdictAbstained, "no dispatcher"
+		return verdict
+	}
+
+	if timeout := m.config.Disputes.Timeout; timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	job := &foundation.Job{
+		ID:        req.DisputeID,
+		Operation: req.Receipt.Operation,
+		Data:      req.Input,
+	}
+	applyRPCScheduling(ctx, job, 50) // Below delegated work: a review can wait
+	result, err := m.runSandboxed(ctx, req.Receipt.Requester, job)
+	switch {
+	case err != nil:
+		verdict.Outcome, verdict.Reason = VerdictAbstained, err.Error()
+	case !result.Success:
+		verdict.Outcome, verdict.Reason = VerdictAbstained, result.Error
+	default:
+		verdict.OutputDigest = m.computeResourceDigest(result.Data)
+		verdict.Outcome = VerdictDismissed
+		if verdict.OutputDigest != req.Receipt.OutputDigest {
+			verdict.Outcome = VerdictUpheld
+		}
+	}
+	return verdict
+}
+
+// signDisputeVerdict stamps the verdict with this node's identity.
+func (m *MeshCoordinator) signDisputeVerdict(v *DisputeVerdict) error {
+	if m.gossip == nil {
+		return errors.New("gossip manager unavailable for verdict signing")
+	}
+	v.Reviewer = m.nodeID
+	v.ReviewerKey = m.gossip.PublicKey()
+
+	sig, pub, err := m.gossip.SignAttestation(disputeVerdictPayload(v))
+	if err != nil {
+		return fmt.Errorf("failed to sign dispute verdict: %w", err)
+	}
+	if !bytes.Equal(pub, v.ReviewerKey) {
+		return errors.New("identity key rotated while signing dispute verdict")
+	}
+	v.Signature = sig
+	return nil
+}
+
+// FileDispute challenges the receipt this node holds for jobID, whose
+// input was input. It asks a committee to re-execute the job, decides the
+// dispute from their verdicts and, when it is confirmed, slashes the
+// executor. An inconclusive dispute may be filed again.
+func (m *MeshCoordinator) FileDispute(ctx context.Context, jobID string, input []byte) (Dispute, error) {
+	receipt, ok := m.GetDelegationReceipt(jobID)
+	if !ok {
+		return Dispute{}, fmt.Errorf("no receipt for job %s", jobID)
+	}
+	if receipt.Requester != m.nodeID || receipt.Executor == m.nodeID {
+		return Dispute{}, errors.New("only the requester can dispute another node's receipt")
+	}
+	if err := m.checkDisputeEvidence(&receipt, input); err != nil {
+		return Dispute{}, err
+	}
+	if m.disputeDecided(jobID) {
+		return Dispute{}, ErrDisputeFiled
+	}
+
+	config := m.config.Disputes
+	committee := m.disputeCommittee(receipt.Executor)
+	if len(committee) < config.Quorum {
+		return Dispute{}, fmt.Errorf("not enough reviewers for a dispute: have %d, need %d", len(committee), config.Quorum)
+	}
+
+	dispute := Dispute{
+		ID:        fmt.Sprintf("dispute_%d", time.Now().UnixNano()),
+		Receipt:   receipt,
+		Committee: committee,
+		FiledAt:   time.Now(),
+	}
+	dispute.Verdicts = m.collectVerdicts(ctx, &dispute, input)
+	m.decideDispute(&dispute)
+	m.storeDispute(dispute)
+
+	m.logger.Info("dispute resolved", "id", dispute.ID, "executor", getShortID(receipt.Executor), "status", dispute.Status, "slashed", dispute.Slashed)
+	m.publishEvent(MeshEventDisputeResolved, receipt.Executor, map[string]interface{}{
+		"id":       dispute.ID,
+		"job_id":   jobID,
+		"status":   dispute.Status,
+		"verdicts": len(dispute.Verdicts),
+		"slashed":  dispute.Slashed,
+		"paid":     dispute.Paid,
+	})
+	return dispute, nil
+}
+
+// disputeCommittee picks up to CommitteeSize connected peers with enough
+// reputation, most trusted first, leaving out the executor.
+func (m *MeshCoordinator) disputeCommittee(executor string) []string {
+	type candidate struct {
+		peerID string
+		score  float64
+	}
+	var candidates []candidate
+	for _, peerID := range m.transport.GetConnectedPeers() {
+		if peerID == m.nodeID || peerID == executor || m.isPeerQuarantined(peerID) {
+			continue
+		}
+		if score, _ := m.reputation.GetTrustScore(peerID); score >= m.config.Disputes.MinReputation {
+			candidates = append(candidates, candidate{peerID, score})
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].score != candidates[j].score {
+			return candidates[i].score > candidates[j].score
+		}
+		return candidates[i].peerID < candidates[j].peerID
+	})
+	if size := m.config.Disputes.CommitteeSize; size > 0 && len(candidates) > size {
+		candidates = candidates[:size]
+	}
+
+	committee := make([]string, len(candidates))
+	for i, c := range candidates {
+		committee[i] = c.peerID
+	}
+	return committee
+}
+
+// collectVerdicts asks every committee member for a verdict at once and
+// keeps the valid ones. A reviewer that answers with a verdict it did not
+// sign, or for another dispute, is penalized.
+func (m *MeshCoordinator) collectVerdicts(ctx context.Context, dispute *Dispute, input []byte) []DisputeVerdict {
+	req := disputeReviewRequest{DisputeID: dispute.ID, Receipt: dispute.Receipt, Input: input}
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		verdicts []DisputeVerdict
+	)
+	for _, reviewer := range dispute.Committee {
+		wg.Add(1)
+		go func(reviewer string) {
+			defer wg.Done()
+			reviewCtx := ctx
+			if timeout := m.config.Disputes.Timeout; timeout > 0 {
+				var cancel context.CancelFunc
+				reviewCtx, cancel = context.WithTimeout(ctx, timeout)
+				defer cancel()
+			}
+
+			var verdict DisputeVerdict
+			if err := m.transport.SendRPC(reviewCtx, reviewer, disputeReviewMethod, req, &verdict); err != nil {
+				m.logger.Debug("dispute review failed", "reviewer", getShortID(reviewer), "error", err)
+				return
+			}
+			if err := m.checkDisputeVerdict(dispute, reviewer, &verdict); err != nil {
+				m.logger.Warn("rejected dispute verdict", "reviewer", getShortID(reviewer), "error", err)
+				m.reputation.ReportPenalty(reviewer, routing.PenaltyInvalidData)
+				return
+			}
+			mu.Lock()
+			verdicts = append(verdicts, verdict)
+			mu.Unlock()
+		}(reviewer)
+	}
+	wg.Wait()
+
+	sort.Slice(verdicts, func(i, j int) bool { return verdicts[i].Reviewer < verdicts[j].Reviewer })
+	return verdicts
+}
+
+func (m *MeshCoordinator) checkDisputeVerdict(dispute *Dispute, reviewer string, v *DisputeVerdict) error {
+	if v.Reviewer != reviewer || v.DisputeID != dispute.ID || v.JobID != dispute.Receipt.JobID {
+		return errors.New("verdict is for another dispute or reviewer")
+	}
+	switch v.Outcome {
+	case VerdictUpheld, VerdictDismissed, VerdictAbstained:
+	default:
+		return fmt.Errorf("unknown verdict outcome %q", v.Outcome)
+	}
+	if err := v.Verify(); err != nil {
+		return err
+	}
+	return m.checkIdentityKey(v.Reviewer, v.ReviewerKey)
+}
+
+// decideDispute settles a dispute from its verdicts. Upholding verdicts
+// only count together when they agree on the output, so reviewers that
+// each get something different from a nondeterministic job confirm nothing.
+func (m *MeshCoordinator) decideDispute(dispute *Dispute) {
+	dispute.Resolved = time.Now()
+	quorum := m.config.Disputes.Quorum
+	if quorum < 1 {
+		quorum = 1
+	}
+
+	dismissed := 0
+	upheld := make(map[string]int)
+	for _, v := range dispute.Verdicts {
+		switch v.Outcome {
+		case VerdictDismissed:
+			dismissed++
+		case VerdictUpheld:
+			upheld[v.OutputDigest]++
+		}
+	}
+	for digest, n := range upheld {
+		if n >= quorum {
+			dispute.Status, dispute.Expected = DisputeConfirmed, digest
+		}
+	}
+
+	switch {
+	case dispute.Status == DisputeConfirmed:
+		m.slashExecutor(dispute)
+	case dismissed >= quorum:
+		dispute.Status = DisputeRejected
+	default:
+		dispute.Status = DisputeInconclusive
+	}
+}
+
+// slashExecutor penalizes the executor of a confirmed dispute: its
+// reputation is cut to the floor and its account slashed, with part of the
+// credits paid to this node.
+func (m *MeshCoordinator) slashExecutor(dispute *Dispute) {
+	executor := dispute.Receipt.Executor
+	m.reputation.ReportPenalty(executor, routing.PenaltyMaliciousBehavior)
+	if m.ledger == nil || m.config.Disputes.SlashAmount <= 0 {
+		return
+	}
+
+	slashed, paid, err := m.ledger.Slash(m.peerAccount(executor), m.localAccount(), uint64(m.config.Disputes.SlashAmount), m.config.Disputes.CompensationShare, dispute.ID)
+	if err != nil {
+		m.logger.Warn("failed to slash executor", "executor", getShortID(executor), "dispute", dispute.ID, "error", err)
+		return
+	}
+	dispute.Slashed, dispute.Paid = slashed, paid
+}
+
+// peerAccount is the ledger account of a peer: its DID when it proved it
+// owns one during attestation, otherwise its node ID.
+func (m *MeshCoordinator) peerAccount(peerID string) string {
+	m.attestationMu.RLock()
+	defer m.attestationMu.RUnlock()
+	if record, ok := m.attestedPeers[peerID]; ok && record.DIDVerified && record.DID != "" {
+		return record.DID
+	}
+	return peerID
+}
+
+// localAccount is this node's ledger account: its DID once set, otherwise
+// its node ID.
+func (m *MeshCoordinator) localAccount() string {
+	m.identityMu.RLock()
+	defer m.identityMu.RUnlock()
+	if m.did != "" {
+		return m.did
+	}
+	return m.nodeID
+}
+
+// disputeDecided reports whether a dispute of jobID was confirmed or
+// rejected already.
+func (m *MeshCoordinator) disputeDecided(jobID string) bool {
+	m.disputesMu.Lock()
+	defer m.disputesMu.Unlock()
+	for _, d := range m.disputes {
+		if d.Receipt.JobID == jobID && d.Status != DisputeInconclusive {
+			return true
+		}
+	}
+	return false
+}
+
+// storeDispute keeps a filed dispute, dropping the oldest once the log is
+// full.
+func (m *MeshCoordinator) storeDispute(dispute Dispute) {
+	m.disputesMu.Lock()
+	defer m.disputesMu.Unlock()
+	if limit := m.config.Disputes.LogSize; limit > 0 && len(m.disputes) >= limit {
+		m.disputes = append(m.disputes[:0], m.disputes[len(m.disputes)-limit+1:]...)
+	}
+	m.disputes = append(m.disputes, dispute)
+}
+
+// GetDisputes returns the disputes this node filed, oldest first.
+func (m *MeshCoordinator) GetDisputes() []Dispute {
+	m.disputesMu.Lock()
+	defer m.disputesMu.Unlock()
+	return append([]Dispute(nil), m.disputes...)
+}
@@ -0,0 +1,148 @@
+package mesh
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"testing"
+
+	"github.com/nmxmxh/inos_v1/kernel/threads/foundation"
+)
+
+// disputeTransport answers dispute reviews with each reviewer's own
+// coordinator, so every verdict is signed by the peer it names.
+type disputeTransport struct {
+	*MockTransport
+	reviewers map[string]*MeshCoordinator
+}
+
+func (d *disputeTransport) GetConnectedPeers() []string {
+	peers := make([]string, 0, len(d.reviewers))
+	for peerID := range d.reviewers {
+		peers = append(peers, peerID)
+	}
+	sort.Strings(peers)
+	return peers
+}
+
+func (d *disputeTransport) SendRPC(ctx context.Context, peerID string, method string, args interface{}, reply interface{}) error {
+	reviewer, ok := d.reviewers[peerID]
+	if method != disputeReviewMethod || !ok {
+		return d.MockTransport.SendRPC(ctx, peerID, method, args, reply)
+	}
+	raw, _ := json.Marshal(args)
+	result, err := reviewer.transport.(*MockTransport).registeredRPCHandlers[method](ctx, d.nodeID, raw)
+	if err != nil {
+		return err
+	}
+	data, _ := json.Marshal(result)
+	return json.Unmarshal(data, reply)
+}
+
+func TestDisputes_CommitteeConfirmsAndSlashes(t *testing.T) {
+	honest := &mockDispatcher{
+		run: func(job *foundation.Job) *foundation.Result {
+			return &foundation.Result{JobID: job.ID, Success: true, Data: append([]byte("done:"), job.Data...)}
+		},
+	}
+	executor := NewMeshCoordinator("executor", "us-east", &MockTransport{nodeID: "executor"}, nil)
+	tr := &disputeTransport{MockTransport: &MockTransport{nodeID: "requester"}, reviewers: map[string]*MeshCoordinator{}}
+	requester := NewMeshCoordinator("requester", "us-east", tr, nil)
+	requester.gossip.SetPeerIdentityKey("executor", executor.gossip.PublicKey())
+	for _, id := range []string{"reviewer-1", "reviewer-2", "reviewer-3"} {
+		reviewer := NewMeshCoordinator(id, "us-east", &MockTransport{nodeID: id}, nil)
+		reviewer.SetDispatcher(honest)
+		reviewer.gossip.SetPeerIdentityKey("executor", executor.gossip.PublicKey())
+		reviewer.gossip.SetPeerIdentityKey("requester", requester.gossip.PublicKey())
+		requester.gossip.SetPeerIdentityKey(id, reviewer.gossip.PublicKey())
+		tr.reviewers[id] = reviewer
+	}
+	requester.SetIdentity("did:inos:requester", "", "")
+	requester.ledger.RegisterAccount("executor", 800)
+
+	input := []byte("source")
+	receiptFor := func(jobID string, output []byte) {
+		receipt := &DelegationReceipt{
+			JobID:        jobID,
+			Operation:    "compress",
+			Requester:    "requester",
+			InputDigest:  requester.computeResourceDigest(input),
+			OutputDigest: requester.computeResourceDigest(output),
+			Status:       "success",
+			StartedAt:    1,
+			FinishedAt:   2,
+		}
+		if err := executor.signDelegationReceipt(receipt); err != nil {
+			t.Fatalf("signDelegationReceipt failed: %v", err)
+		}
+		requester.storeDelegationReceipt(receipt)
+	}
+
+	// The executor signed for output no honest run produces
+	receiptFor("job-forged", []byte("forged"))
+	dispute, err := requester.FileDispute(context.Background(), "job-forged", input)
+	if err != nil {
+		t.Fatalf("FileDispute failed: %v", err)
+	}
+	if dispute.Status != DisputeConfirmed || len(dispute.Verdicts) != 3 {
+		t.Fatalf("expected a confirmed dispute with 3 verdicts, got %s with %d", dispute.Status, len(dispute.Verdicts))
+	}
+	if dispute.Expected != requester.computeResourceDigest([]byte("done:source")) {
+		t.Fatalf("unexpected agreed output %s", dispute.Expected)
+	}
+	if dispute.Slashed != 500 || dispute.Paid != 250 {
+		t.Fatalf("expected 500 slashed and 250 paid, got %d and %d", dispute.Slashed, dispute.Paid)
+	}
+	if got := requester.GetEconomicBalance("executor"); got != 300 {
+		t.Fatalf("expected the executor left with 300, got %d", got)
+	}
+	if got := requester.GetEconomicBalance("did:inos:requester"); got != 250 {
+		t.Fatalf("expected the requester compensated, got %d", got)
+	}
+	if score, _ := requester.reputation.GetTrustScore("executor"); score >= 0.5 {
+		t.Fatalf("expected the executor's reputation cut, got %v", score)
+	}
+	if _, err := requester.FileDispute(context.Background(), "job-forged", input); !errors.Is(err, ErrDisputeFiled) {
+		t.Fatalf("expected a decided dispute to stay decided, got %v", err)
+	}
+
+	// An honest receipt is reproduced and nobody is slashed
+	receiptFor("job-honest", []byte("done:source"))
+	dispute, err = requester.FileDispute(context.Background(), "job-honest", input)
+	if err != nil || dispute.Status != DisputeRejected || dispute.Slashed != 0 {
+		t.Fatalf("expected a rejected dispute, got %+v %v", dispute, err)
+	}
+	if _, err := requester.FileDispute(context.Background(), "job-honest", []byte("other input")); err == nil {
+		t.Fatal("expected input that does not match the receipt to be refused")
+	}
+	if got := len(requester.GetDisputes()); got != 2 {
+		t.Fatalf("expected 2 disputes kept, got %d", got)
+	}
+}
+
+func TestEconomicLedger_SlashStopsAtZero(t *testing.T) {
+	ledger := NewEconomicLedger()
+	ledger.RegisterAccount("offender", 100)
+	ledger.RegisterAccount("victim", 0)
+
+	var changes []LedgerChange
+	ledger.SetChangeHandler(func(change LedgerChange) { changes = append(changes, change) })
+
+	taken, paid, err := ledger.Slash("offender", "victim", 500, 0.5, "dispute-1")
+	if err != nil || taken != 100 || paid != 50 {
+		t.Fatalf("expected 100 taken and 50 paid, got %d %d %v", taken, paid, err)
+	}
+	if ledger.GetBalance("offender") != 0 || ledger.GetBalance("victim") != 50 {
+		t.Fatalf("unexpected balances %d %d", ledger.GetBalance("offender"), ledger.GetBalance("victim"))
+	}
+	if len(changes) != 2 || changes[0].Reason != "slash" || changes[1].DisputeID != "dispute-1" {
+		t.Fatalf("unexpected ledger changes %+v", changes)
+	}
+	if taken, _, _ := ledger.Slash("offender", "victim", 500, 0.5, "dispute-2"); taken != 0 {
+		t.Fatalf("expected nothing left to slash, got %d", taken)
+	}
+	if totals := ledger.Snapshot().Totals; totals.Slashed != 100 || totals.Compensated != 50 {
+		t.Fatalf("unexpected totals %+v", totals)
+	}
+}
@@ -0,0 +1,27 @@
+package mesh
+
+// relaySealer is implemented by transports that gossip SDPs through
+// intermediate peers (sdp.relay) and can encrypt them for the target.
+type relaySealer interface {
+	SetRelaySealer(seal func(targetID string, sdp []byte) ([]byte, error))
+}
+
+func (m *MeshCoordinator) injectRelaySealer(tr Transport) {
+	sealer, ok := tr.(relaySealer)
+	if !ok {
+		return
+	}
+	sealer.SetRelaySealer(func(targetID string, sdp []byte) ([]byte, error) {
+		return m.gossip.SealSDP(targetID, sdp)
+	})
+}
+
+// signalingTarget returns the peer a signaling message is addressed to.
+func signalingTarget(payload interface{}) string {
+	msg, ok := payload.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	targetID, _ := msg["target_id"].(string)
+	return targetID
+}
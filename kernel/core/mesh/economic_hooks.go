@@ -1,8 +1,10 @@
 package mesh
 
 import (
+	"crypto/sha256"
 	"errors"
 	"fmt"
+	"strconv"
 	"sync"
 	"time"
 
@@ -48,6 +50,7 @@ type DelegationEscrow struct {
 	ExpiresAt   time.Time
 	SettledAt   time.Time
 	JobID       string // Associated job ID
+	Signature   []byte // Node identity signature over the settlement
 }
 
 // EconomicLedger manages credit escrow and settlement for delegated jobs
@@ -59,11 +62,37 @@ type EconomicLedger struct {
 	// Authority for grounded state (optional)
 	vault foundation.EconomicVault
 
+	// Pulled-work accounting per provider (work-stealing fairness)
+	workShares map[string]*WorkShare
+
+	// Relayed bytes per account not yet worth a whole credit
+	relayRemainder map[string]uint64
+
+	// Signs settlements with the node identity key (optional)
+	signer func([]byte) ([]byte, error)
+
+	// Balance and escrow changes, queued under mu and delivered after unlock
+	onChange       func(LedgerChange)
+	pendingChanges []LedgerChange
+
 	// Statistics
 	totalEscrowed    uint64
 	totalSettled     uint64
 	totalRefunded    uint64
 	settlementsCount uint64
+	totalSlashed     uint64
+	totalCompensated uint64
+	totalRelayCredit uint64
+}
+
+// LedgerChange describes one credit movement.
+type LedgerChange struct {
+	Account  string `json:"account"`
+	Delta    int64  `json:"delta"`
+	Reason   string `json:"reason"` // bonus, escrow_lock, escrow_release, escrow_refund, escrow_expire, slash, compensation, relay
+	EscrowID string `json:"escrow_id,omitempty"`
+	// DisputeID names the confirmed dispute behind a slash or compensation
+	DisputeID string `json:"dispute_id,omitempty"`
 }
 
 // SealedCreditsVault adds pending credit support for escrow settlement.
@@ -74,11 +103,19 @@ type SealedCreditsVault interface {
 	RefundPending(did string, amount uint64) error
 }
 
+// SlashableVault lets a grounded vault take slashed credits.
+type SlashableVault interface {
+	Slash(did string, amount uint64) error
+}
+
 // NewEconomicLedger creates a new economic ledger for delegation
 func NewEconomicLedger() *EconomicLedger {
 	return &EconomicLedger{
-		escrows:  make(map[string]*DelegationEscrow),
-		balances: make(map[string]int64),
+		escrows:    make(map[string]*DelegationEscrow),
+		balances:   make(map[string]int64),
+		workShares: make(map[string]*WorkShare),
+
+		relayRemainder: make(map[string]uint64),
 	}
 }
 
@@ -104,6 +141,64 @@ func (el *EconomicLedger) SetVault(vault foundation.EconomicVault) {
 	}
 }
 
+// SetSigner sets the function used to sign escrow settlements
+func (el *EconomicLedger) SetSigner(signer func([]byte) ([]byte, error)) {
+	el.mu.Lock()
+	el.signer = signer
+	el.mu.Unlock()
+}
+
+// SettlementDigest is the hash a settled escrow's signature covers.
+func SettlementDigest(escrow *DelegationEscrow) []byte {
+	h := sha256.New()
+	for _, part := range []string{
+		escrow.ID, escrow.JobID, escrow.RequesterID, escrow.ProviderID,
+		strconv.FormatUint(escrow.Amount, 10), escrow.Status.String(),
+		strconv.FormatInt(escrow.SettledAt.UnixNano(), 10),
+	} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return h.Sum(nil)
+}
+
+// signSettlementLocked signs a settled escrow. Caller must hold el.mu.
+func (el *EconomicLedger) signSettlementLocked(escrow *DelegationEscrow) {
+	if el.signer == nil {
+		return
+	}
+	if sig, err := el.signer(SettlementDigest(escrow)); err == nil {
+		escrow.Signature = sig
+	}
+}
+
+// SetChangeHandler registers fn to receive every balance change. It is
+// called without the ledger lock held.
+func (el *EconomicLedger) SetChangeHandler(fn func(LedgerChange)) {
+	el.mu.Lock()
+	el.onChange = fn
+	el.mu.Unlock()
+}
+
+// noteChangeLocked queues a change for flushChanges. Callers hold el.mu.
+func (el *EconomicLedger) noteChangeLocked(change LedgerChange) {
+	if el.onChange != nil {
+		el.pendingChanges = append(el.pendingChanges, change)
+	}
+}
+
+// flushChanges delivers queued changes. Callers must not hold el.mu.
+func (el *EconomicLedger) flushChanges() {
+	el.mu.Lock()
+	changes, fn := el.pendingChanges, el.onChange
+	el.pendingChanges = nil
+	el.mu.Unlock()
+
+	for _, change := range changes {
+		fn(change)
+	}
+}
+
 // RegisterAccount initializes an account with optional starting balance
 func (el *EconomicLedger) RegisterAccount(did string, initialBalance int64) {
 	el.mu.Lock()
@@ -135,14 +230,42 @@ func (el *EconomicLedger) EnsureAccount(did string, initialBalance int64) {
 func (el *EconomicLedger) GrantEarlyAdopterBonus(did string, bonus int64) {
 	el.mu.Lock()
 	el.balances[did] += bonus
+	el.noteChangeLocked(LedgerChange{Account: did, Delta: bonus, Reason: "bonus"})
 	v := el.vault
 	el.mu.Unlock()
+	el.flushChanges()
 
 	if v != nil {
 		v.GrantBonus(did, bonus)
 	}
 }
 
+// RelayCreditPerMB is what a relay earns per MiB it forwards for others,
+// matching the per-MB rate of delegated work.
+const RelayCreditPerMB = 1
+
+// CreditRelay pays a relay for forwarding bytes. Bytes short of a whole
+// MiB carry over to the next call; it returns the credits paid now.
+func (el *EconomicLedger) CreditRelay(did string, bytes uint64) int64 {
+	el.mu.Lock()
+	total := el.relayRemainder[did] + bytes
+	credits := int64(total / (1 << 20) * RelayCreditPerMB)
+	el.relayRemainder[did] = total % (1 << 20)
+	if credits > 0 {
+		el.balances[did] += credits
+		el.totalRelayCredit += uint64(credits)
+		el.noteChangeLocked(LedgerChange{Account: did, Delta: credits, Reason: "relay"})
+	}
+	v := el.vault
+	el.mu.Unlock()
+	el.flushChanges()
+
+	if v != nil && credits > 0 {
+		v.GrantBonus(did, credits)
+	}
+	return credits
+}
+
 // GetBalance returns the current balance for an account
 func (el *EconomicLedger) GetBalance(did string) int64 {
 	el.mu.RLock()
@@ -166,6 +289,7 @@ func (el *EconomicLedger) CreateEscrow(
 	ttl time.Duration,
 	jobID string,
 ) (*DelegationEscrow, error) {
+	defer el.flushChanges()
 	el.mu.Lock()
 	defer el.mu.Unlock()
 
@@ -206,6 +330,7 @@ func (el *EconomicLedger) CreateEscrow(
 
 	el.escrows[escrowID] = escrow
 	el.totalEscrowed += amount
+	el.noteChangeLocked(LedgerChange{Account: requesterID, Delta: -int64(amount), Reason: "escrow_lock", EscrowID: escrowID})
 
 	return escrow, nil
 }
@@ -230,6 +355,7 @@ func (el *EconomicLedger) AssignProvider(escrowID, providerID string) error {
 
 // ReleaseToProvider settles the escrow to the provider (success case)
 func (el *EconomicLedger) ReleaseToProvider(escrowID string, verified bool) error {
+	defer el.flushChanges()
 	el.mu.Lock()
 	defer el.mu.Unlock()
 
@@ -260,15 +386,18 @@ func (el *EconomicLedger) ReleaseToProvider(escrowID string, verified bool) erro
 	}
 	escrow.Status = EscrowReleased
 	escrow.SettledAt = time.Now()
+	el.signSettlementLocked(escrow)
 
 	el.totalSettled += escrow.Amount
 	el.settlementsCount++
+	el.noteChangeLocked(LedgerChange{Account: escrow.ProviderID, Delta: int64(escrow.Amount), Reason: "escrow_release", EscrowID: escrowID})
 
 	return nil
 }
 
 // RefundToRequester returns escrowed credits to the requester (failure/timeout)
 func (el *EconomicLedger) RefundToRequester(escrowID string) error {
+	defer el.flushChanges()
 	el.mu.Lock()
 	defer el.mu.Unlock()
 
@@ -291,14 +420,17 @@ func (el *EconomicLedger) RefundToRequester(escrowID string) error {
 	}
 	escrow.Status = EscrowRefunded
 	escrow.SettledAt = time.Now()
+	el.signSettlementLocked(escrow)
 
 	el.totalRefunded += escrow.Amount
+	el.noteChangeLocked(LedgerChange{Account: escrow.RequesterID, Delta: int64(escrow.Amount), Reason: "escrow_refund", EscrowID: escrowID})
 
 	return nil
 }
 
 // ExpireStaleEscrows marks expired escrows and refunds them
 func (el *EconomicLedger) ExpireStaleEscrows() int {
+	defer el.flushChanges()
 	el.mu.Lock()
 	defer el.mu.Unlock()
 
@@ -315,15 +447,69 @@ func (el *EconomicLedger) ExpireStaleEscrows() int {
 			}
 			escrow.Status = EscrowExpired
 			escrow.SettledAt = now
+			el.signSettlementLocked(escrow)
 			el.totalRefunded += escrow.Amount
+			el.noteChangeLocked(LedgerChange{Account: escrow.RequesterID, Delta: int64(escrow.Amount), Reason: "escrow_expire", EscrowID: id})
 			expired++
-			_ = id // Track for logging
 		}
 	}
 
 	return expired
 }
 
+// Slash takes up to amount credits from the offender and pays share of what
+// was taken to the beneficiary; the rest is burned. An offender cannot be
+// slashed below zero, so it returns the credits actually taken and paid.
+func (el *EconomicLedger) Slash(offender, beneficiary string, amount uint64, share float64, disputeID string) (uint64, uint64, error) {
+	if offender == beneficiary {
+		return 0, 0, errors.New("offender and beneficiary are the same account")
+	}
+	if share < 0 {
+		share = 0
+	} else if share > 1 {
+		share = 1
+	}
+
+	defer el.flushChanges()
+	el.mu.Lock()
+	defer el.mu.Unlock()
+
+	balance := el.balances[offender]
+	if el.vault != nil {
+		if vb, err := el.vault.GetBalance(offender); err == nil {
+			balance = vb
+		}
+	}
+	if balance <= 0 {
+		return 0, 0, nil
+	}
+	taken := amount
+	if uint64(balance) < taken {
+		taken = uint64(balance)
+	}
+
+	if slashable, ok := el.vault.(SlashableVault); ok && el.vault != nil {
+		if err := slashable.Slash(offender, taken); err != nil {
+			return 0, 0, err
+		}
+	} else {
+		el.balances[offender] -= int64(taken)
+	}
+	el.totalSlashed += taken
+	el.noteChangeLocked(LedgerChange{Account: offender, Delta: -int64(taken), Reason: "slash", DisputeID: disputeID})
+
+	paid := uint64(float64(taken) * share)
+	if paid > 0 {
+		el.balances[beneficiary] += int64(paid)
+		if el.vault != nil {
+			_ = el.vault.GrantBonus(beneficiary, int64(paid))
+		}
+		el.totalCompensated += paid
+		el.noteChangeLocked(LedgerChange{Account: beneficiary, Delta: int64(paid), Reason: "compensation", DisputeID: disputeID})
+	}
+	return taken, paid, nil
+}
+
 // GetEscrow returns the escrow by ID
 func (el *EconomicLedger) GetEscrow(escrowID string) (*DelegationEscrow, bool) {
 	el.mu.RLock()
@@ -338,12 +524,16 @@ func (el *EconomicLedger) GetStats() map[string]interface{} {
 	defer el.mu.RUnlock()
 
 	return map[string]interface{}{
-		"total_escrowed":    el.totalEscrowed,
-		"total_settled":     el.totalSettled,
-		"total_refunded":    el.totalRefunded,
-		"settlements_count": el.settlementsCount,
-		"active_escrows":    len(el.escrows),
-		"accounts":          len(el.balances),
+		"total_escrowed":     el.totalEscrowed,
+		"total_settled":      el.totalSettled,
+		"total_refunded":     el.totalRefunded,
+		"settlements_count":  el.settlementsCount,
+		"total_slashed":      el.totalSlashed,
+		"total_compensated":  el.totalCompensated,
+		"total_relay_credit": el.totalRelayCredit,
+		"active_escrows":     len(el.escrows),
+		"accounts":           len(el.balances),
+		"work_providers":     len(el.workShares),
 	}
 }
 
@@ -432,3 +622,84 @@ func (el *EconomicLedger) SettleDelegation(
 	result.SettledAt = time.Now()
 	return result, nil
 }
+
+// WorkShare accounts for jobs a provider pulled from the shared work queue
+type WorkShare struct {
+	Claimed       uint64
+	Completed     uint64
+	Failed        uint64
+	CreditsEarned uint64
+	LastClaim     time.Time
+}
+
+// RecordWorkClaim notes that a provider pulled a job from the work queue
+func (el *EconomicLedger) RecordWorkClaim(providerID string) {
+	el.mu.Lock()
+	defer el.mu.Unlock()
+
+	share := el.workShareLocked(providerID)
+	share.Claimed++
+	share.LastClaim = time.Now()
+}
+
+// RecordWorkOutcome settles the accounting for a pulled job
+func (el *EconomicLedger) RecordWorkOutcome(providerID string, success bool, credits uint64) {
+	el.mu.Lock()
+	defer el.mu.Unlock()
+
+	share := el.workShareLocked(providerID)
+	if success {
+		share.Completed++
+		share.CreditsEarned += credits
+	} else {
+		share.Failed++
+	}
+}
+
+// GetWorkShare returns the work accounting for a provider
+func (el *EconomicLedger) GetWorkShare(providerID string) (WorkShare, bool) {
+	el.mu.RLock()
+	defer el.mu.RUnlock()
+
+	share, exists := el.workShares[providerID]
+	if !exists {
+		return WorkShare{}, false
+	}
+	return *share, true
+}
+
+// FairShareRatio compares a provider's earned credits with the mean across all
+// providers. 1.0 is a fair share; values above 1.0 mean the provider is taking
+// more than its share of pulled work.
+func (el *EconomicLedger) FairShareRatio(providerID string) float64 {
+	el.mu.RLock()
+	defer el.mu.RUnlock()
+
+	if len(el.workShares) < 2 {
+		return 1.0
+	}
+
+	var total uint64
+	for _, share := range el.workShares {
+		total += share.CreditsEarned
+	}
+	if total == 0 {
+		return 1.0
+	}
+
+	share, exists := el.workShares[providerID]
+	if !exists {
+		return 0
+	}
+	mean := float64(total) / float64(len(el.workShares))
+	return float64(share.CreditsEarned) / mean
+}
+
+func (el *EconomicLedger) workShareLocked(providerID string) *WorkShare {
+	share, exists := el.workShares[providerID]
+	if !exists {
+		share = &WorkShare{}
+		el.workShares[providerID] = share
+	}
+	return share
+}
@@ -201,6 +201,8 @@ func (m *MeshCoordinator) emitPeerUpdateEvent(capability *PeerCapability) {
 	if capability == nil {
 		return
 	}
+	m.publishPeerEvent(capability)
+
 	payload, err := marshalMeshEvent(func(ev p2p.MeshEvent) error {
 		capnpCap, err := capability.ToCapnp(ev.Struct.Segment())
 		if err != nil {
@@ -215,6 +217,11 @@ func (m *MeshCoordinator) emitPeerUpdateEvent(capability *PeerCapability) {
 }
 
 func (m *MeshCoordinator) emitChunkDiscoveredEvent(chunkHash, peerID string, priority p2p.ChunkPriority) {
+	m.publishEvent(MeshEventChunkDiscovered, peerID, map[string]interface{}{
+		"chunk_hash": chunkHash,
+		"priority":   priority.String(),
+	})
+
 	payload, err := marshalMeshEvent(func(ev p2p.MeshEvent) error {
 		chunk, err := ev.NewChunkDiscovered()
 		if err != nil {
@@ -249,6 +256,13 @@ func (m *MeshCoordinator) emitReputationUpdate(peerID string, score float64, rea
 }
 
 func (m *MeshCoordinator) emitDelegationRequestEvent(operation string, id string, digest []byte, rawSize uint32) {
+	m.publishEvent(MeshEventDelegationRequest, "", map[string]interface{}{
+		"id":        id,
+		"operation": operation,
+		"digest":    string(digest),
+		"size":      rawSize,
+	})
+
 	msg, seg, err := capnp.NewMessage(capnp.SingleSegment(nil))
 	if err != nil {
 		return
@@ -279,6 +293,15 @@ func (m *MeshCoordinator) emitDelegationRequestEvent(operation string, id string
 }
 
 func (m *MeshCoordinator) emitDelegationResponseEvent(status p2p.DelegateResponse_Status, id string, digest []byte, rawSize uint32, latencyMs float32, errMsg string) {
+	m.publishEvent(MeshEventDelegationResponse, "", map[string]interface{}{
+		"id":         id,
+		"status":     status.String(),
+		"digest":     string(digest),
+		"size":       rawSize,
+		"latency_ms": latencyMs,
+		"error":      errMsg,
+	})
+
 	msg, seg, err := capnp.NewMessage(capnp.SingleSegment(nil))
 	if err != nil {
 		return
@@ -0,0 +1,495 @@
+package mesh
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/threads/sab"
+)
+
+// Mesh event subscriptions stream JSON events to the host through a
+// broadcast ring in the "mesh_events" dynamic SAB region. The kernel is the
+// only writer; every subscription reads the ring through its own cursor, so
+// a slow subscriber loses its oldest events without holding back the rest.
+//
+// Region layout (little-endian):
+//
+//	0x00 magic u32 "MEVS"   0x04 version u32   0x08 slot size u32
+//	0x0C slot count u32     0x10 tail u32 (sequence of the next event)
+//	0x40 cursors            MaxMeshEventSubscriptions x 16 bytes:
+//	                        active u32, cursor u32, lost u32, reserved u32
+//	slots                   seq+1 u32, subscriber mask u32, length u32,
+//	                        reserved u32, JSON MeshEventRecord
+//
+// Event n lives in slot n % slot count. A reader whose cursor has fallen more
+// than slot count behind the tail skips ahead and adds the gap to lost. Each
+// slot's mask has bit i set when subscription i's filter matched, so readers
+// skip events they did not ask for.
+const (
+	MeshEventRegionName       = "mesh_events"
+	MaxMeshEventSubscriptions = 32
+
+	meshEventRingMagic       = 0x5356454D // "MEVS"
+	meshEventRingVersion     = 1
+	meshEventRingHeaderSize  = 64
+	meshEventRingTailOffset  = 0x10
+	meshEventCursorSize      = 16
+	meshEventSlotHeaderSize  = 16
+	meshEventRingSlotSize    = 512
+	meshEventRingSlotCount   = 256
+	meshEventRingCursorsSize = MaxMeshEventSubscriptions * meshEventCursorSize
+	meshEventRingSlotsOffset = meshEventRingHeaderSize + meshEventRingCursorsSize
+	meshEventRingSize        = meshEventRingSlotsOffset + meshEventRingSlotCount*meshEventRingSlotSize
+)
+
+// Mesh event topics.
+const (
+	MeshEventPeerJoin                = "peer.join"
+	MeshEventPeerLeave               = "peer.leave"
+	MeshEventPeerUpdate              = "peer.update"
+	MeshEventPeerEvicted             = "peer.evicted" // Dropped to stay under MaxConnections
+	MeshEventChunkDiscovered         = "chunk.discovered"
+	MeshEventChunkStored             = "chunk.stored"
+	MeshEventChunkEvicted            = "chunk.evicted"
+	MeshEventChunkReplicated         = "chunk.replicated"
+	MeshEventChunkDistributed        = "chunk.distributed"
+	MeshEventChunkDistributionFailed = "chunk.distribution_failed"
+	MeshEventChunkAccessDenied       = "chunk.access_denied"
+	MeshEventObjectAnnounced         = "object.announced"
+	MeshEventDelegationRequest       = "delegation.request"
+	MeshEventDelegationResponse      = "delegation.response"
+	MeshEventDelegationExecuted      = "delegation.executed"
+	MeshEventLedgerChange            = "ledger.change"
+	MeshEventTranscriptMismatch      = "security.transcript_mismatch"
+	MeshEventManifestWarm            = "manifest.warm"
+	MeshEventModuleAnnounced         = "module.announced"
+	MeshEventPowerChanged            = "power.changed"
+	MeshEventLoadChanged             = "load.changed"
+	MeshEventBackgroundChanged       = "background.changed"
+	MeshEventNetworkChanged          = "network.changed"
+	MeshEventSectorChanged           = "sector.changed"
+	MeshEventDisputeResolved         = "dispute.resolved"
+
+	// MeshEventTopicPrefix prefixes pub/sub notifications: a message on
+	// topic "chat" is announced as "topic.chat", so "topic.*" follows them
+	// all.
+	MeshEventTopicPrefix = "topic."
+)
+
+// MeshEventFilter selects the events a subscription receives. Topics match
+// exactly, by "prefix.*", or "*"; no topics means every topic. Peers, when
+// set, drops events about other peers; events not about a peer still pass.
+type MeshEventFilter struct {
+	Topics []string `json:"topics,omitempty"`
+	Peers  []string `json:"peers,omitempty"`
+}
+
+// MeshEventRecord is one event as written to the ring.
+type MeshEventRecord struct {
+	Seq   uint32          `json:"seq"`
+	Topic string          `json:"topic"`
+	Peer  string          `json:"peer,omitempty"`
+	Time  int64           `json:"ts"` // Unix milliseconds
+	Data  json.RawMessage `json:"data,omitempty"`
+}
+
+// MeshEventSubscription describes where a subscriber finds its events.
+// Offsets are absolute SAB offsets.
+type MeshEventSubscription struct {
+	ID           string          `json:"id"`
+	Slot         int             `json:"slot"`
+	Filter       MeshEventFilter `json:"filter"`
+	RegionOffset uint32          `json:"region_offset"`
+	TailOffset   uint32          `json:"tail_offset"`
+	CursorOffset uint32          `json:"cursor_offset"`
+	SlotsOffset  uint32          `json:"slots_offset"`
+	SlotSize     uint32          `json:"slot_size"`
+	SlotCount    uint32          `json:"slot_count"`
+	EpochIndex   uint32          `json:"epoch_index"`
+}
+
+// MeshEventRegionAllocator is the dynamic region access the ring needs;
+// the SAB bridge provides it.
+type MeshEventRegionAllocator interface {
+	AllocateRegion(name string, size, alignment uint32) (sab.DynamicRegion, error)
+	LookupRegion(name string) (sab.DynamicRegion, bool)
+}
+
+type meshEventSubscriber struct {
+	id     string
+	slot   int
+	topics map[string]struct{}
+	peers  map[string]struct{}
+}
+
+func (s *meshEventSubscriber) matches(topic, peer string) bool {
+	if !topicMatches(s.topics, topic) {
+		return false
+	}
+	if len(s.peers) == 0 || peer == "" {
+		return true
+	}
+	_, ok := s.peers[peer]
+	return ok
+}
+
+func stringSet(values []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(values))
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			set[v] = struct{}{}
+		}
+	}
+	return set
+}
+
+// meshEventRing is the writer side of the broadcast ring.
+type meshEventRing struct {
+	bridge SABWriter
+	base   uint32
+
+	mu   sync.Mutex
+	tail uint32
+	subs [MaxMeshEventSubscriptions]*meshEventSubscriber
+	ids  map[string]*meshEventSubscriber
+
+	active    atomic.Int32
+	published atomic.Uint64
+	dropped   atomic.Uint64 // Events too large for a slot
+}
+
+// openMeshEventRing finds or allocates the ring region. A ring left by an
+// earlier kernel keeps its tail so host readers do not see sequence numbers
+// go backwards; its cursors are cleared since none of its subscriptions
+// survive.
+func openMeshEventRing(bridge SABWriter) (*meshEventRing, error) {
+	alloc, ok := unwrapSAB(bridge).(MeshEventRegionAllocator)
+	if !ok {
+		return nil, errors.New("SAB bridge cannot allocate dynamic regions")
+	}
+	region, found := alloc.LookupRegion(MeshEventRegionName)
+	if found && region.Size < meshEventRingSize {
+		return nil, fmt.Errorf("mesh event region too small (%d < %d)", region.Size, meshEventRingSize)
+	}
+	if !found {
+		var err error
+		region, err = alloc.AllocateRegion(MeshEventRegionName, meshEventRingSize, 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to allocate mesh event region: %w", err)
+		}
+	}
+
+	r := &meshEventRing{bridge: bridge, base: region.Offset, ids: make(map[string]*meshEventSubscriber)}
+	header, err := bridge.ReadRaw(r.base, meshEventRingHeaderSize)
+	if err == nil && len(header) == meshEventRingHeaderSize &&
+		binary.LittleEndian.Uint32(header[0:4]) == meshEventRingMagic &&
+		binary.LittleEndian.Uint32(header[8:12]) == meshEventRingSlotSize &&
+		binary.LittleEndian.Uint32(header[12:16]) == meshEventRingSlotCount {
+		r.tail = binary.LittleEndian.Uint32(header[meshEventRingTailOffset:])
+	}
+
+	header = make([]byte, meshEventRingHeaderSize)
+	binary.LittleEndian.PutUint32(header[0:4], meshEventRingMagic)
+	binary.LittleEndian.PutUint32(header[4:8], meshEventRingVersion)
+	binary.LittleEndian.PutUint32(header[8:12], meshEventRingSlotSize)
+	binary.LittleEndian.PutUint32(header[12:16], meshEventRingSlotCount)
+	binary.LittleEndian.PutUint32(header[meshEventRingTailOffset:], r.tail)
+	if err := bridge.WriteRaw(r.base, header); err != nil {
+		return nil, err
+	}
+	if err := bridge.WriteRaw(r.base+meshEventRingHeaderSize, make([]byte, meshEventRingCursorsSize)); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// setBridge moves the ring onto a new bridge over the same SAB, as after
+// supervisor failover.
+func (r *meshEventRing) setBridge(bridge SABWriter) {
+	r.mu.Lock()
+	r.bridge = bridge
+	r.mu.Unlock()
+}
+
+func (r *meshEventRing) cursorOffset(slot int) uint32 {
+	return r.base + meshEventRingHeaderSize + uint32(slot)*meshEventCursorSize
+}
+
+func (r *meshEventRing) slotOffset(seq uint32) uint32 {
+	return r.base + meshEventRingSlotsOffset + (seq%meshEventRingSlotCount)*meshEventRingSlotSize
+}
+
+func (r *meshEventRing) subscribe(filter MeshEventFilter) (MeshEventSubscription, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	slot := -1
+	for i, s := range r.subs {
+		if s == nil {
+			slot = i
+			break
+		}
+	}
+	if slot < 0 {
+		return MeshEventSubscription{}, fmt.Errorf("mesh event subscriptions exhausted (max %d)", MaxMeshEventSubscriptions)
+	}
+
+	// New subscribers start at the tail: they see events from now on
+	cursor := make([]byte, meshEventCursorSize)
+	binary.LittleEndian.PutUint32(cursor[0:4], 1)
+	binary.LittleEndian.PutUint32(cursor[4:8], r.tail)
+	if err := r.bridge.WriteRaw(r.cursorOffset(slot), cursor); err != nil {
+		return MeshEventSubscription{}, err
+	}
+
+	sub := &meshEventSubscriber{
+		id:     fmt.Sprintf("mesh_events_%d_%d", slot, time.Now().UnixNano()),
+		slot:   slot,
+		topics: stringSet(filter.Topics),
+		peers:  stringSet(filter.Peers),
+	}
+	r.subs[slot] = sub
+	r.ids[sub.id] = sub
+	r.active.Add(1)
+
+	return MeshEventSubscription{
+		ID:           sub.id,
+		Slot:         slot,
+		Filter:       filter,
+		RegionOffset: r.base,
+		TailOffset:   r.base + meshEventRingTailOffset,
+		CursorOffset: r.cursorOffset(slot),
+		SlotsOffset:  r.base + meshEventRingSlotsOffset,
+		SlotSize:     meshEventRingSlotSize,
+		SlotCount:    meshEventRingSlotCount,
+		EpochIndex:   sab.IDX_MESH_EVENT_EPOCH,
+	}, nil
+}
+
+func (r *meshEventRing) unsubscribe(id string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	sub, ok := r.ids[id]
+	if !ok {
+		return false
+	}
+	delete(r.ids, id)
+	r.subs[sub.slot] = nil
+	r.active.Add(-1)
+	_ = r.bridge.WriteRaw(r.cursorOffset(sub.slot), make([]byte, meshEventCursorSize))
+	return true
+}
+
+// publish writes one event for every subscription whose filter matches.
+func (r *meshEventRing) publish(topic, peer string, data interface{}) {
+	if r.active.Load() == 0 {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var mask uint32
+	for i, s := range r.subs {
+		if s != nil && s.matches(topic, peer) {
+			mask |= 1 << i
+		}
+	}
+	if mask == 0 {
+		return
+	}
+
+	record := MeshEventRecord{Seq: r.tail, Topic: topic, Peer: peer, Time: time.Now().UnixMilli()}
+	if data != nil {
+		raw, err := json.Marshal(data)
+		if err != nil {
+			r.dropped.Add(1)
+			return
+		}
+		record.Data = raw
+	}
+	payload, err := json.Marshal(record)
+	if err != nil || len(payload) > meshEventRingSlotSize-meshEventSlotHeaderSize {
+		r.dropped.Add(1)
+		return
+	}
+
+	slot := make([]byte, meshEventSlotHeaderSize+len(payload))
+	binary.LittleEndian.PutUint32(slot[0:4], r.tail+1)
+	binary.LittleEndian.PutUint32(slot[4:8], mask)
+	binary.LittleEndian.PutUint32(slot[8:12], uint32(len(payload)))
+	copy(slot[meshEventSlotHeaderSize:], payload)
+	if err := r.bridge.WriteRaw(r.slotOffset(r.tail), slot); err != nil {
+		r.dropped.Add(1)
+		return
+	}
+
+	// Publish the slot before the tail that makes it visible
+	r.tail++
+	tail := make([]byte, 4)
+	binary.LittleEndian.PutUint32(tail, r.tail)
+	_ = r.bridge.WriteRaw(r.base+meshEventRingTailOffset, tail)
+	r.published.Add(1)
+	r.bridge.SignalEpoch(sab.IDX_MESH_EVENT_EPOCH)
+}
+
+// read returns up to max events for subscription id and advances its
+// cursor, reporting how many events it lost to overruns along the way.
+func (r *meshEventRing) read(id string, max int) ([]MeshEventRecord, uint32, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	sub, ok := r.ids[id]
+	if !ok {
+		return nil, 0, fmt.Errorf("unknown mesh event subscription %q", id)
+	}
+	if max <= 0 {
+		max = meshEventRingSlotCount
+	}
+
+	raw, err := r.bridge.ReadRaw(r.cursorOffset(sub.slot), meshEventCursorSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(raw) < meshEventCursorSize {
+		return nil, 0, errors.New("mesh event cursor unreadable")
+	}
+	cursor := binary.LittleEndian.Uint32(raw[4:8])
+	lost := binary.LittleEndian.Uint32(raw[8:12])
+
+	var newlyLost uint32
+	if behind := r.tail - cursor; behind > meshEventRingSlotCount {
+		newlyLost = behind - meshEventRingSlotCount
+		cursor = r.tail - meshEventRingSlotCount
+	}
+
+	var events []MeshEventRecord
+	bit := uint32(1) << sub.slot
+	for cursor != r.tail && len(events) < max {
+		slot, err := r.bridge.ReadRaw(r.slotOffset(cursor), meshEventRingSlotSize)
+		if err != nil || len(slot) < meshEventSlotHeaderSize {
+			return events, newlyLost, fmt.Errorf("mesh event slot unreadable: %w", err)
+		}
+		seq := binary.LittleEndian.Uint32(slot[0:4])
+		mask := binary.LittleEndian.Uint32(slot[4:8])
+		length := binary.LittleEndian.Uint32(slot[8:12])
+		cursor++
+		if seq != cursor || mask&bit == 0 || length > meshEventRingSlotSize-meshEventSlotHeaderSize {
+			continue
+		}
+		var ev MeshEventRecord
+		if json.Unmarshal(slot[meshEventSlotHeaderSize:meshEventSlotHeaderSize+length], &ev) == nil {
+			events = append(events, ev)
+		}
+	}
+
+	binary.LittleEndian.PutUint32(raw[4:8], cursor)
+	binary.LittleEndian.PutUint32(raw[8:12], lost+newlyLost)
+	if err := r.bridge.WriteRaw(r.cursorOffset(sub.slot), raw); err != nil {
+		return events, newlyLost, err
+	}
+	return events, newlyLost, nil
+}
+
+// SubscribeMeshEvents opens a filtered subscription on the mesh event ring.
+func (m *MeshCoordinator) SubscribeMeshEvents(filter MeshEventFilter) (MeshEventSubscription, error) {
+	ring, err := m.meshEventRing()
+	if err != nil {
+		return MeshEventSubscription{}, err
+	}
+	return ring.subscribe(filter)
+}
+
+// UnsubscribeMeshEvents closes a subscription and frees its cursor.
+func (m *MeshCoordinator) UnsubscribeMeshEvents(id string) bool {
+	m.eventRingMu.Lock()
+	ring := m.eventRing
+	m.eventRingMu.Unlock()
+	return ring != nil && ring.unsubscribe(id)
+}
+
+// ReadMeshEvents drains up to max pending events for a subscription, for
+// hosts that would rather not parse the ring themselves. It returns the
+// number of events lost since the last read.
+func (m *MeshCoordinator) ReadMeshEvents(id string, max int) ([]MeshEventRecord, uint32, error) {
+	m.eventRingMu.Lock()
+	ring := m.eventRing
+	m.eventRingMu.Unlock()
+	if ring == nil {
+		return nil, 0, fmt.Errorf("unknown mesh event subscription %q", id)
+	}
+	return ring.read(id, max)
+}
+
+// GetMeshEventStats reports ring activity.
+func (m *MeshCoordinator) GetMeshEventStats() map[string]interface{} {
+	m.eventRingMu.Lock()
+	ring := m.eventRing
+	m.eventRingMu.Unlock()
+	if ring == nil {
+		return map[string]interface{}{"subscriptions": 0}
+	}
+	return map[string]interface{}{
+		"subscriptions": int(ring.active.Load()),
+		"published":     ring.published.Load(),
+		"dropped":       ring.dropped.Load(),
+	}
+}
+
+func (m *MeshCoordinator) meshEventRing() (*meshEventRing, error) {
+	m.eventRingMu.Lock()
+	defer m.eventRingMu.Unlock()
+
+	if m.eventRing != nil {
+		return m.eventRing, nil
+	}
+	if m.bridge == nil {
+		return nil, errors.New("mesh SAB bridge unavailable")
+	}
+	ring, err := openMeshEventRing(m.bridge)
+	if err != nil {
+		return nil, err
+	}
+	m.eventRing = ring
+	return ring, nil
+}
+
+// publishEvent streams an event to matching subscriptions. It is a no-op
+// until someone subscribes.
+func (m *MeshCoordinator) publishEvent(topic, peer string, data interface{}) {
+	m.eventRingMu.Lock()
+	ring := m.eventRing
+	m.eventRingMu.Unlock()
+	if ring != nil {
+		ring.publish(topic, peer, data)
+	}
+}
+
+// publishPeerEvent maps a peer capability change onto join, leave or update.
+func (m *MeshCoordinator) publishPeerEvent(capability *PeerCapability) {
+	topic := MeshEventPeerUpdate
+	switch capability.ConnectionState {
+	case ConnectionStateConnected:
+		topic = MeshEventPeerJoin
+	case ConnectionStateDisconnected, ConnectionStateFailed:
+		topic = MeshEventPeerLeave
+	}
+	m.publishEvent(topic, capability.PeerID, map[string]interface{}{
+		"state":      int(capability.ConnectionState),
+		"region":     capability.Region,
+		"reputation": capability.Reputation,
+		"latency_ms": capability.LatencyMs,
+	})
+}
+
+// publishLedgerChange is the ledger's change handler.
+func (m *MeshCoordinator) publishLedgerChange(change LedgerChange) {
+	m.publishEvent(MeshEventLedgerChange, "", change)
+}
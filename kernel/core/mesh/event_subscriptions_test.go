@@ -0,0 +1,171 @@
+package mesh
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/nmxmxh/inos_v1/kernel/threads/sab"
+)
+
+// regionSABBridge is an in-memory SAB with a single-region allocator.
+type regionSABBridge struct {
+	data    []byte
+	regions map[string]sab.DynamicRegion
+	next    uint32
+}
+
+func newRegionSABBridge(size int) *regionSABBridge {
+	return &regionSABBridge{data: make([]byte, size), regions: make(map[string]sab.DynamicRegion), next: 4096}
+}
+
+func (b *regionSABBridge) WriteRaw(offset uint32, data []byte) error {
+	if int(offset)+len(data) > len(b.data) {
+		return fmt.Errorf("out of bounds write")
+	}
+	copy(b.data[offset:], data)
+	return nil
+}
+
+func (b *regionSABBridge) ReadRaw(offset uint32, size uint32) ([]byte, error) {
+	if int(offset)+int(size) > len(b.data) {
+		return nil, fmt.Errorf("out of bounds read")
+	}
+	return append([]byte(nil), b.data[offset:offset+size]...), nil
+}
+
+func (b *regionSABBridge) SignalEpoch(index uint32)              {}
+func (b *regionSABBridge) GetAddress(data []byte) (uint32, bool) { return 0, false }
+func (b *regionSABBridge) Size() uint32                          { return uint32(len(b.data)) }
+func (b *regionSABBridge) AtomicLoad(index uint32) uint32        { return 0 }
+func (b *regionSABBridge) AtomicAdd(index uint32, delta uint32) uint32 {
+	return 0
+}
+
+func (b *regionSABBridge) AllocateRegion(name string, size, alignment uint32) (sab.DynamicRegion, error) {
+	if _, ok := b.regions[name]; ok {
+		return sab.DynamicRegion{}, sab.ErrRegionExists
+	}
+	region := sab.DynamicRegion{Name: name, Offset: b.next, Size: size}
+	if int(region.Offset+size) > len(b.data) {
+		return sab.DynamicRegion{}, sab.ErrRegionNoSpace
+	}
+	b.next += size
+	b.regions[name] = region
+	return region, nil
+}
+
+func (b *regionSABBridge) LookupRegion(name string) (sab.DynamicRegion, bool) {
+	region, ok := b.regions[name]
+	return region, ok
+}
+
+func newEventTestCoordinator(t *testing.T) (*MeshCoordinator, *regionSABBridge) {
+	t.Helper()
+	coord := NewMeshCoordinator("node-a", "us-east", &MockTransport{nodeID: "node-a"}, nil)
+	bridge := newRegionSABBridge(4096 + meshEventRingSize)
+	coord.SetSABBridge(bridge)
+	return coord, bridge
+}
+
+func TestMeshEvents_FilteredPerSubscription(t *testing.T) {
+	coord, _ := newEventTestCoordinator(t)
+
+	peers, err := coord.SubscribeMeshEvents(MeshEventFilter{Topics: []string{"peer.*"}})
+	if err != nil {
+		t.Fatalf("subscribe failed: %v", err)
+	}
+	ledger, err := coord.SubscribeMeshEvents(MeshEventFilter{Topics: []string{MeshEventLedgerChange}})
+	if err != nil {
+		t.Fatalf("subscribe failed: %v", err)
+	}
+	onlyB, err := coord.SubscribeMeshEvents(MeshEventFilter{Peers: []string{"peer-b"}})
+	if err != nil {
+		t.Fatalf("subscribe failed: %v", err)
+	}
+	if peers.Slot == ledger.Slot || peers.CursorOffset == ledger.CursorOffset {
+		t.Fatalf("subscriptions share a cursor: %+v %+v", peers, ledger)
+	}
+
+	coord.emitPeerUpdateEvent(&PeerCapability{PeerID: "peer-a", ConnectionState: ConnectionStateConnected})
+	coord.emitPeerUpdateEvent(&PeerCapability{PeerID: "peer-b", ConnectionState: ConnectionStateDisconnected})
+	coord.ledger.GrantEarlyAdopterBonus("did:inos:alice", 50)
+
+	events, lost, err := coord.ReadMeshEvents(peers.ID, 0)
+	if err != nil || lost != 0 {
+		t.Fatalf("read failed: %v lost=%d", err, lost)
+	}
+	if len(events) != 2 || events[0].Topic != MeshEventPeerJoin || events[1].Topic != MeshEventPeerLeave || events[1].Peer != "peer-b" {
+		t.Fatalf("unexpected peer events: %+v", events)
+	}
+
+	events, _, _ = coord.ReadMeshEvents(ledger.ID, 0)
+	if len(events) != 1 || events[0].Topic != MeshEventLedgerChange {
+		t.Fatalf("unexpected ledger events: %+v", events)
+	}
+	var change LedgerChange
+	if err := json.Unmarshal(events[0].Data, &change); err != nil || change.Delta != 50 || change.Reason != "bonus" {
+		t.Fatalf("unexpected ledger change %+v: %v", change, err)
+	}
+
+	events, _, _ = coord.ReadMeshEvents(onlyB.ID, 0)
+	if len(events) != 2 || events[0].Peer != "peer-b" || events[1].Topic != MeshEventLedgerChange {
+		t.Fatalf("peer filter should keep peer-b and peerless events: %+v", events)
+	}
+
+	// Cursors advanced: nothing left to read
+	if events, _, _ := coord.ReadMeshEvents(peers.ID, 0); len(events) != 0 {
+		t.Fatalf("cursor did not advance: %+v", events)
+	}
+}
+
+func TestMeshEvents_SlowSubscriberLosesOldest(t *testing.T) {
+	coord, _ := newEventTestCoordinator(t)
+	sub, err := coord.SubscribeMeshEvents(MeshEventFilter{})
+	if err != nil {
+		t.Fatalf("subscribe failed: %v", err)
+	}
+
+	total := meshEventRingSlotCount + 10
+	for i := 0; i < total; i++ {
+		coord.publishEvent(MeshEventChunkStored, "", map[string]int{"n": i})
+	}
+
+	events, lost, err := coord.ReadMeshEvents(sub.ID, total)
+	if err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	if lost != 10 || len(events) != meshEventRingSlotCount {
+		t.Fatalf("expected 10 lost and a full ring, got lost=%d events=%d", lost, len(events))
+	}
+	if events[0].Seq != 10 {
+		t.Fatalf("oldest surviving event should be seq 10, got %d", events[0].Seq)
+	}
+}
+
+func TestMeshEvents_UnsubscribeFreesSlot(t *testing.T) {
+	coord, _ := newEventTestCoordinator(t)
+	var first MeshEventSubscription
+	for i := 0; i < MaxMeshEventSubscriptions; i++ {
+		sub, err := coord.SubscribeMeshEvents(MeshEventFilter{})
+		if err != nil {
+			t.Fatalf("subscribe %d failed: %v", i, err)
+		}
+		if i == 0 {
+			first = sub
+		}
+	}
+	if _, err := coord.SubscribeMeshEvents(MeshEventFilter{}); err == nil {
+		t.Fatal("expected subscriptions to be exhausted")
+	}
+	if !coord.UnsubscribeMeshEvents(first.ID) {
+		t.Fatal("unsubscribe failed")
+	}
+	sub, err := coord.SubscribeMeshEvents(MeshEventFilter{})
+	if err != nil || sub.Slot != first.Slot {
+		t.Fatalf("freed slot not reused: %+v %v", sub, err)
+	}
+	if _, _, err := coord.ReadMeshEvents(first.ID, 0); err == nil {
+		t.Fatal("closed subscription should not be readable")
+	}
+}
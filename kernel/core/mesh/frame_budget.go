@@ -0,0 +1,43 @@
+package mesh
+
+import "github.com/nmxmxh/inos_v1/kernel/threads/foundation"
+
+// SetFrameBudget attaches the kernel's per-frame budget. Delegation packing
+// and verification are charged to it, gossip reports its own share, and
+// periodic background work (metrics gossip, storage challenges, bandwidth
+// probes, anti-entropy) skips its tick while frames run over budget.
+func (m *MeshCoordinator) SetFrameBudget(fb *foundation.FrameBudget) {
+	m.frameBudget.Store(fb)
+	if m.gossip != nil {
+		if fb == nil {
+			m.gossip.SetFrameBudget(nil)
+		} else {
+			m.gossip.SetFrameBudget(fb)
+		}
+	}
+}
+
+// trackDelegation charges the caller's work to the delegation subsystem.
+func (m *MeshCoordinator) trackDelegation() func() {
+	return m.frameBudget.Load().Track(foundation.FrameSubsystemDelegation)
+}
+
+// deferLowPriority reports whether background work should skip this tick:
+// while the host page is hidden, or while frames run over budget.
+func (m *MeshCoordinator) deferLowPriority() bool {
+	if m.background.Load() {
+		m.backgroundTicks.Add(1)
+		return true
+	}
+	if !m.frameBudget.Load().DeferLowPriority() {
+		return false
+	}
+	m.deferredTicks.Add(1)
+	return true
+}
+
+// DeferredTicks returns how many background ticks were skipped for the
+// frame budget.
+func (m *MeshCoordinator) DeferredTicks() uint64 {
+	return m.deferredTicks.Load()
+}
@@ -0,0 +1,35 @@
+package mesh
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/threads/foundation"
+)
+
+func TestFrameBudget_DelegationIsChargedAndBackgroundWorkDefers(t *testing.T) {
+	coord, _ := newDelegationTestCoordinator(t)
+	budget := foundation.NewFrameBudget(foundation.FrameBudgetConfig{Target: time.Millisecond, DeferAfter: 1})
+	coord.SetFrameBudget(budget)
+
+	if coord.deferLowPriority() {
+		t.Fatal("fresh budget should not defer")
+	}
+	if _, err := coord.DelegateCompute(context.Background(), "compress", "input-digest", []byte("source")); err != nil {
+		t.Fatalf("DelegateCompute failed: %v", err)
+	}
+
+	frame := budget.EndFrame(time.Second)
+	if frame.Subsystems[foundation.FrameSubsystemDelegation] <= 0 {
+		t.Fatalf("delegation work not charged to the frame: %+v", frame)
+	}
+	if !coord.deferLowPriority() || coord.DeferredTicks() != 1 {
+		t.Fatalf("blown frame should defer background work, deferred=%d", coord.DeferredTicks())
+	}
+
+	coord.SetFrameBudget(nil)
+	if coord.deferLowPriority() {
+		t.Fatal("detached budget should not defer")
+	}
+}
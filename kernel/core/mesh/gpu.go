@@ -0,0 +1,175 @@
+package mesh
+
+import (
+	"strings"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/threads/foundation"
+)
+
+// gpuOperationPrefix marks the GPU job class: an operation such as
+// "gpu.matmul" only runs on peers that advertise a WebGPU adapter.
+const gpuOperationPrefix = "gpu."
+
+// Job parameters a GPU job may set to ask more of the adapter than just
+// having one. The buffer size is a number; features is a list of strings.
+const (
+	JobParamGPUMinBufferSize = "gpu_min_buffer_size"
+	JobParamGPUFeatures      = "gpu_features"
+)
+
+// gpuTimingScaleMs is the average GPU time at which a peer's score for GPU
+// jobs is halved.
+const gpuTimingScaleMs = 100.0
+
+// IsGPUOperation reports whether an operation belongs to the GPU job class.
+func IsGPUOperation(operation string) bool {
+	return strings.HasPrefix(operation, gpuOperationPrefix)
+}
+
+// GPURequirements lists what a GPU job needs from the adapter that runs it.
+type GPURequirements struct {
+	MinBufferSize uint64   `json:"min_buffer_size,omitempty"`
+	Features      []string `json:"features,omitempty"`
+}
+
+// jobGPURequirements returns the requirements of a GPU job, or nil for any
+// other operation.
+func jobGPURequirements(operation string, params map[string]interface{}) *GPURequirements {
+	if !IsGPUOperation(operation) {
+		return nil
+	}
+	return &GPURequirements{
+		MinBufferSize: foundation.UintParam(params[JobParamGPUMinBufferSize]),
+		Features:      mergeStrings(nil, stringListParam(params[JobParamGPUFeatures])),
+	}
+}
+
+// mergeGPURequirements keeps the stricter of each limit.
+func mergeGPURequirements(a, b *GPURequirements) *GPURequirements {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	return &GPURequirements{
+		MinBufferSize: max(a.MinBufferSize, b.MinBufferSize),
+		Features:      mergeStrings(a.Features, b.Features),
+	}
+}
+
+// missing lists what the adapter lacks, in the MissingFeatures form of an
+// UnsupportedFeatureError.
+func (r *GPURequirements) missing(gpu *GPUCapability) []string {
+	if gpu == nil {
+		return []string{"gpu"}
+	}
+	var out []string
+	if r.MinBufferSize > 0 && gpu.MaxBufferSize < r.MinBufferSize {
+		out = append(out, "gpu.max_buffer_size")
+	}
+	for _, f := range r.Features {
+		found := false
+		for _, have := range gpu.Features {
+			if have == f {
+				found = true
+				break
+			}
+		}
+		if !found {
+			out = append(out, "gpu."+f)
+		}
+	}
+	return out
+}
+
+// SetGPUCapability records this node's WebGPU adapter and re-advertises the
+// node's capability with it. nil withdraws the adapter.
+func (m *MeshCoordinator) SetGPUCapability(gpu *GPUCapability) {
+	m.gpuMu.Lock()
+	m.localGPU = gpu
+	m.gpuMu.Unlock()
+	m.advertiseDHTMode()
+}
+
+func (m *MeshCoordinator) getLocalGPU() *GPUCapability {
+	m.gpuMu.Lock()
+	defer m.gpuMu.Unlock()
+	return m.localGPU
+}
+
+// peerGPU returns the adapter a peer advertised.
+func (m *MeshCoordinator) peerGPU(peerID string) *GPUCapability {
+	capability := m.getCachedPeer(peerID)
+	if capability == nil {
+		return nil
+	}
+	return capability.GPU
+}
+
+// checkLocalGPU refuses a GPU job this node's adapter cannot run.
+func (m *MeshCoordinator) checkLocalGPU(req *GPURequirements) error {
+	if req == nil {
+		return nil
+	}
+	if missing := req.missing(m.getLocalGPU()); len(missing) > 0 {
+		return &UnsupportedFeatureError{MissingFeatures: missing}
+	}
+	return nil
+}
+
+// GPUTimingStats is the GPU time a peer reported for the jobs it ran for
+// this node.
+type GPUTimingStats struct {
+	Jobs    uint64        `json:"jobs"`
+	Average time.Duration `json:"average"` // Exponential moving average
+	Last    time.Duration `json:"last"`
+}
+
+// recordGPUTiming folds a peer's reported GPU time into its average, which
+// ranks peers for later GPU jobs.
+func (m *MeshCoordinator) recordGPUTiming(peerID string, gpuTime time.Duration) {
+	if gpuTime <= 0 {
+		return
+	}
+	m.gpuMu.Lock()
+	defer m.gpuMu.Unlock()
+	stats, ok := m.gpuTimings[peerID]
+	if !ok {
+		stats = &GPUTimingStats{Average: gpuTime}
+		m.gpuTimings[peerID] = stats
+	}
+	stats.Jobs++
+	stats.Last = gpuTime
+	stats.Average = time.Duration(0.8*float64(stats.Average) + 0.2*float64(gpuTime))
+}
+
+func (m *MeshCoordinator) recordResultGPUTiming(peerID string, result *foundation.Result) {
+	if result != nil && result.Metrics != nil {
+		m.recordGPUTiming(peerID, result.Metrics.GPUTime)
+	}
+}
+
+// GetPeerGPUStats returns the GPU timing recorded for a peer.
+func (m *MeshCoordinator) GetPeerGPUStats(peerID string) (GPUTimingStats, bool) {
+	m.gpuMu.Lock()
+	defer m.gpuMu.Unlock()
+	stats, ok := m.gpuTimings[peerID]
+	if !ok {
+		return GPUTimingStats{}, false
+	}
+	return *stats, true
+}
+
+// gpuScore scales a peer's score down by its average GPU time, so slower
+// adapters lose GPU jobs to faster ones. Peers without timings keep their
+// score and get a chance to report some.
+func (m *MeshCoordinator) gpuScore(peerID string, score float32) float32 {
+	stats, ok := m.GetPeerGPUStats(peerID)
+	if !ok {
+		return score
+	}
+	ms := float64(stats.Average) / float64(time.Millisecond)
+	return score / float32(1+ms/gpuTimingScaleMs)
+}
@@ -0,0 +1,78 @@
+package mesh
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+	"github.com/nmxmxh/inos_v1/kernel/threads/foundation"
+)
+
+func TestGPU_JobsRouteOnlyToCapablePeers(t *testing.T) {
+	coord := NewMeshCoordinator("self", "us-east", &MockTransport{nodeID: "self"}, nil)
+	coord.peerMetricsMu.Lock()
+	coord.peerMetrics["peer-cpu"] = common.MeshMetrics{AvgReputation: 1.0, P50LatencyMs: 1.0}
+	coord.peerMetrics["peer-small"] = common.MeshMetrics{AvgReputation: 1.0, P50LatencyMs: 2.0}
+	coord.peerMetrics["peer-gpu"] = common.MeshMetrics{AvgReputation: 1.0, P50LatencyMs: 5.0}
+	coord.peerMetricsMu.Unlock()
+	coord.cachePeer("peer-cpu", &common.PeerCapability{PeerID: "peer-cpu"})
+	coord.cachePeer("peer-small", &common.PeerCapability{PeerID: "peer-small", GPU: &GPUCapability{MaxBufferSize: 1 << 20}})
+	coord.cachePeer("peer-gpu", &common.PeerCapability{PeerID: "peer-gpu", GPU: &GPUCapability{MaxBufferSize: 1 << 30, Features: []string{"shader-f16"}}})
+
+	job := &foundation.Job{ID: "j", Operation: "gpu.matmul", Parameters: map[string]interface{}{
+		JobParamGPUMinBufferSize: float64(256 << 20),
+	}}
+	if decision := NewDelegationEngine(nil).Analyze(context.Background(), job); !decision.RequiresGPU || decision.TargetType != TargetDedicatedHW {
+		t.Fatalf("expected a GPU decision, got %+v", decision)
+	}
+	peer, _, err := coord.selectPeerForRequirements(context.Background(), JobWASMRequirements(job))
+	if err != nil || peer != "peer-gpu" {
+		t.Fatalf("expected peer-gpu, got %q %v", peer, err)
+	}
+
+	job.Parameters[JobParamGPUFeatures] = []interface{}{"timestamp-query"}
+	if _, _, err := coord.selectPeerForRequirements(context.Background(), JobWASMRequirements(job)); !errors.Is(err, ErrUnsupportedFeature) {
+		t.Fatalf("expected ErrUnsupportedFeature without a matching adapter, got %v", err)
+	}
+
+	// Non-GPU jobs still pick the best peer overall
+	peer, _, _ = coord.selectPeerForRequirements(context.Background(), JobWASMRequirements(&foundation.Job{Operation: "compress"}))
+	if peer != "peer-cpu" {
+		t.Fatalf("expected peer-cpu for a CPU job, got %q", peer)
+	}
+
+	// This node refuses GPU jobs until it has an adapter
+	if err := coord.checkLocalFeatures(WASMRequirements{GPU: &GPURequirements{}}); !errors.Is(err, ErrUnsupportedFeature) {
+		t.Fatalf("expected a GPU job to be refused locally, got %v", err)
+	}
+	coord.SetGPUCapability(&GPUCapability{MaxBufferSize: 1 << 30})
+	if err := coord.checkLocalFeatures(WASMRequirements{GPU: &GPURequirements{}}); err != nil {
+		t.Fatalf("expected a GPU job to be accepted, got %v", err)
+	}
+}
+
+func TestGPU_TimingsRankPeers(t *testing.T) {
+	coord := NewMeshCoordinator("self", "us-east", &MockTransport{nodeID: "self"}, nil)
+	coord.peerMetricsMu.Lock()
+	coord.peerMetrics["fast-net"] = common.MeshMetrics{AvgReputation: 1.0, P50LatencyMs: 1.0}
+	coord.peerMetrics["fast-gpu"] = common.MeshMetrics{AvgReputation: 1.0, P50LatencyMs: 2.0}
+	coord.peerMetricsMu.Unlock()
+	for _, id := range []string{"fast-net", "fast-gpu"} {
+		coord.cachePeer(id, &common.PeerCapability{PeerID: id, GPU: &GPUCapability{MaxBufferSize: 1 << 30}})
+	}
+	req := WASMRequirements{GPU: &GPURequirements{}}
+
+	if peer, _, _ := coord.selectPeerForRequirements(context.Background(), req); peer != "fast-net" {
+		t.Fatalf("expected fast-net before any timings, got %q", peer)
+	}
+	coord.recordResultGPUTiming("fast-net", &foundation.Result{Metrics: &foundation.ExecutionMetrics{GPUTime: 400 * time.Millisecond}})
+	coord.recordResultGPUTiming("fast-gpu", &foundation.Result{Metrics: &foundation.ExecutionMetrics{GPUTime: 10 * time.Millisecond}})
+	if peer, _, _ := coord.selectPeerForRequirements(context.Background(), req); peer != "fast-gpu" {
+		t.Fatalf("expected fast-gpu once timings are known, got %q", peer)
+	}
+	if stats, ok := coord.GetPeerGPUStats("fast-net"); !ok || stats.Jobs != 1 || stats.Last != 400*time.Millisecond {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
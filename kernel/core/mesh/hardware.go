@@ -0,0 +1,136 @@
+package mesh
+
+import (
+	"runtime"
+	"slices"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+	"github.com/nmxmxh/inos_v1/kernel/threads/foundation"
+)
+
+// Job parameters asking for more of the machine than any peer offers. The
+// sizes and core count are numbers; simd is a list of flags such as
+// "simd128" or "avx2".
+const (
+	JobParamMinMemoryBytes = "min_memory_bytes"
+	JobParamMinCPUCores    = "min_cpu_cores"
+	JobParamSIMD           = "simd"
+)
+
+// HardwareRequirements lists what a job needs from the machine that runs
+// it. Peers qualify on what their capability's Hardware descriptor says; a
+// peer that reports no value for a limit does not meet it.
+type HardwareRequirements struct {
+	MinMemoryBytes uint64   `json:"min_memory_bytes,omitempty"`
+	MinCPUCores    uint16   `json:"min_cpu_cores,omitempty"`
+	SIMD           []string `json:"simd,omitempty"`
+}
+
+// jobHardwareRequirements reads a job's hardware requirements from its
+// parameters, or returns nil if it sets none.
+func jobHardwareRequirements(params map[string]interface{}) *HardwareRequirements {
+	req := &HardwareRequirements{
+		MinMemoryBytes: foundation.UintParam(params[JobParamMinMemoryBytes]),
+		MinCPUCores:    uint16(min(foundation.UintParam(params[JobParamMinCPUCores]), 1<<16-1)),
+		SIMD:           mergeStrings(nil, stringListParam(params[JobParamSIMD])),
+	}
+	if req.MinMemoryBytes == 0 && req.MinCPUCores == 0 && len(req.SIMD) == 0 {
+		return nil
+	}
+	return req
+}
+
+// mergeHardwareRequirements keeps the stricter of each limit.
+func mergeHardwareRequirements(a, b *HardwareRequirements) *HardwareRequirements {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	return &HardwareRequirements{
+		MinMemoryBytes: max(a.MinMemoryBytes, b.MinMemoryBytes),
+		MinCPUCores:    max(a.MinCPUCores, b.MinCPUCores),
+		SIMD:           mergeStrings(a.SIMD, b.SIMD),
+	}
+}
+
+// missing lists what the machine lacks, in the MissingFeatures form of an
+// UnsupportedFeatureError.
+func (r *HardwareRequirements) missing(hw *HardwareDescriptor) []string {
+	if r == nil {
+		return nil
+	}
+	if hw == nil {
+		hw = &HardwareDescriptor{}
+	}
+	var out []string
+	if r.MinMemoryBytes > 0 && hw.MemoryBytes < r.MinMemoryBytes {
+		out = append(out, "hardware.memory_bytes")
+	}
+	if r.MinCPUCores > 0 && hw.CPUCores < r.MinCPUCores {
+		out = append(out, "hardware.cpu_cores")
+	}
+	for _, flag := range r.SIMD {
+		if !hw.HasSIMD(flag) {
+			out = append(out, "simd."+flag)
+		}
+	}
+	return out
+}
+
+// SetHardwareDescriptor records what the host knows about this machine,
+// such as its memory, and re-advertises the node's capability. Fields left
+// zero are detected where the kernel can detect them.
+func (m *MeshCoordinator) SetHardwareDescriptor(hw *HardwareDescriptor) {
+	m.gpuMu.Lock()
+	m.localHardware = hw
+	m.gpuMu.Unlock()
+	m.advertiseDHTMode()
+}
+
+// getLocalHardware returns the descriptor this node advertises: what the
+// host set, with cores, architecture, WASM SIMD and free storage filled in.
+func (m *MeshCoordinator) getLocalHardware() *HardwareDescriptor {
+	hw := HardwareDescriptor{}
+	m.gpuMu.Lock()
+	if m.localHardware != nil {
+		hw = *m.localHardware
+		hw.SIMD = slices.Clone(hw.SIMD)
+	}
+	m.gpuMu.Unlock()
+
+	if hw.CPUCores == 0 {
+		hw.CPUCores = uint16(min(runtime.NumCPU(), 1<<16-1))
+	}
+	if hw.CPUArch == "" {
+		hw.CPUArch = runtime.GOARCH
+	}
+	if prober := m.getFeatureProber(); prober != nil && slices.Contains(prober.WASMFeatures(), WASMFeatureSIMD) &&
+		!hw.HasSIMD(common.SIMDFlagWASM128) {
+		hw.SIMD = append(hw.SIMD, common.SIMDFlagWASM128)
+	}
+	if hw.StorageFreeBytes == 0 && m.Contribution().AcceptStorage {
+		if quota := m.storageQuota.Usage(); quota.MaxBytes > quota.UsedBytes {
+			hw.StorageFreeBytes = quota.MaxBytes - quota.UsedBytes
+		}
+	}
+	return &hw
+}
+
+// peerHardware returns the descriptor a peer advertised.
+func (m *MeshCoordinator) peerHardware(peerID string) *HardwareDescriptor {
+	capability := m.getCachedPeer(peerID)
+	if capability == nil {
+		return nil
+	}
+	return capability.Hardware
+}
+
+// checkLocalHardware refuses a job this machine cannot run.
+func (m *MeshCoordinator) checkLocalHardware(req *HardwareRequirements) error {
+	if missing := req.missing(m.getLocalHardware()); len(missing) > 0 {
+		return &UnsupportedFeatureError{MissingFeatures: missing}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package mesh
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+	"github.com/nmxmxh/inos_v1/kernel/threads/foundation"
+)
+
+func TestHardware_JobsRouteOnlyToPeersThatFit(t *testing.T) {
+	coord := NewMeshCoordinator("self", "us-east", &MockTransport{nodeID: "self"}, nil)
+	coord.peerMetricsMu.Lock()
+	coord.peerMetrics["peer-legacy"] = common.MeshMetrics{AvgReputation: 1.0, P50LatencyMs: 1.0}
+	coord.peerMetrics["peer-small"] = common.MeshMetrics{AvgReputation: 1.0, P50LatencyMs: 2.0}
+	coord.peerMetrics["peer-big"] = common.MeshMetrics{AvgReputation: 1.0, P50LatencyMs: 5.0}
+	coord.peerMetricsMu.Unlock()
+	coord.cachePeer("peer-legacy", &common.PeerCapability{PeerID: "peer-legacy", RuntimeCaps: &common.RuntimeCapabilities{HasSimd: true}})
+	coord.cachePeer("peer-small", &common.PeerCapability{PeerID: "peer-small", SchemaVersion: common.CapabilitySchemaVersion,
+		Hardware: &HardwareDescriptor{CPUCores: 4, MemoryBytes: 4 << 30, SIMD: []string{common.SIMDFlagWASM128}}})
+	coord.cachePeer("peer-big", &common.PeerCapability{PeerID: "peer-big", SchemaVersion: common.CapabilitySchemaVersion,
+		Hardware: &HardwareDescriptor{CPUCores: 32, MemoryBytes: 64 << 30, SIMD: []string{common.SIMDFlagWASM128, "avx2"}}})
+
+	// A schema 1 peer's SIMD support carries over into a descriptor
+	if hw := coord.peerHardware("peer-legacy"); !hw.HasSIMD(common.SIMDFlagWASM128) {
+		t.Fatalf("expected simd128 for the legacy peer, got %+v", hw)
+	}
+	peer, _, err := coord.selectPeerForRequirements(context.Background(), WASMRequirements{Hardware: &HardwareRequirements{SIMD: []string{common.SIMDFlagWASM128}}})
+	if err != nil || peer != "peer-legacy" {
+		t.Fatalf("expected peer-legacy, got %q %v", peer, err)
+	}
+
+	job := &foundation.Job{ID: "j", Operation: "compress", Parameters: map[string]interface{}{
+		JobParamMinMemoryBytes: float64(16 << 30),
+		JobParamMinCPUCores:    float64(8),
+	}}
+	peer, _, err = coord.selectPeerForRequirements(context.Background(), JobWASMRequirements(job))
+	if err != nil || peer != "peer-big" {
+		t.Fatalf("expected peer-big, got %q %v", peer, err)
+	}
+
+	job.Parameters[JobParamSIMD] = []interface{}{"neon"}
+	_, _, err = coord.selectPeerForRequirements(context.Background(), JobWASMRequirements(job))
+	var unsupported *UnsupportedFeatureError
+	if !errors.As(err, &unsupported) || !slices.Contains(unsupported.MissingFeatures, "simd.neon") {
+		t.Fatalf("expected simd.neon to be missing, got %v", err)
+	}
+}
+
+func TestHardware_RequirementsMissing(t *testing.T) {
+	if req := jobHardwareRequirements(map[string]interface{}{"other": 1}); req != nil {
+		t.Fatalf("expected no requirements, got %+v", req)
+	}
+	req := jobHardwareRequirements(map[string]interface{}{
+		JobParamMinMemoryBytes: float64(1 << 30),
+		JobParamSIMD:           []interface{}{"avx2"},
+	})
+	if missing := req.missing(nil); !slices.Equal(missing, []string{"hardware.memory_bytes", "simd.avx2"}) {
+		t.Fatalf("unexpected missing list %v", missing)
+	}
+	if missing := req.missing(&HardwareDescriptor{MemoryBytes: 2 << 30, SIMD: []string{"avx2"}}); len(missing) != 0 {
+		t.Fatalf("expected the descriptor to fit, got %v", missing)
+	}
+
+	merged := mergeHardwareRequirements(req, &HardwareRequirements{MinMemoryBytes: 4 << 30, MinCPUCores: 2})
+	if merged.MinMemoryBytes != 4<<30 || merged.MinCPUCores != 2 || !slices.Equal(merged.SIMD, []string{"avx2"}) {
+		t.Fatalf("unexpected merge %+v", merged)
+	}
+}
+
+func TestHardware_AnalyzeDelegatesWhatDoesNotFitLocally(t *testing.T) {
+	engine := NewDelegationEngine(nil)
+	job := &foundation.Job{ID: "j", Operation: "compress", Parameters: map[string]interface{}{
+		JobParamMinMemoryBytes: float64(8 << 30),
+	}}
+
+	engine.SetLocalHardware(&HardwareDescriptor{MemoryBytes: 2 << 30})
+	decision := engine.Analyze(context.Background(), job)
+	if !decision.ShouldDelegate || decision.TargetType != TargetDedicatedHW || decision.Hardware == nil {
+		t.Fatalf("expected a forced delegation, got %+v", decision)
+	}
+
+	// A machine that fits decides on efficiency alone
+	engine.SetLocalHardware(&HardwareDescriptor{MemoryBytes: 16 << 30})
+	want := NewDelegationEngine(nil).Analyze(context.Background(), job)
+	if decision := engine.Analyze(context.Background(), job); decision.ShouldDelegate != want.ShouldDelegate || decision.TargetType != want.TargetType {
+		t.Fatalf("expected %+v, got %+v", want, decision)
+	}
+}
+
+func TestHardware_LocalDescriptorFillsDetectedFields(t *testing.T) {
+	coord := NewMeshCoordinator("self", "us-east", &MockTransport{nodeID: "self"}, nil)
+	coord.SetHardwareDescriptor(&HardwareDescriptor{MemoryBytes: 8 << 30})
+
+	hw := coord.getLocalHardware()
+	if hw.MemoryBytes != 8<<30 || hw.CPUCores == 0 || hw.CPUArch == "" {
+		t.Fatalf("unexpected local descriptor %+v", hw)
+	}
+	if err := coord.checkLocalHardware(&HardwareRequirements{MinMemoryBytes: 16 << 30}); !errors.Is(err, ErrUnsupportedFeature) {
+		t.Fatalf("expected the job to be refused locally, got %v", err)
+	}
+	if err := coord.checkLocalHardware(&HardwareRequirements{MinMemoryBytes: 4 << 30}); err != nil {
+		t.Fatalf("expected the job to be accepted, got %v", err)
+	}
+}
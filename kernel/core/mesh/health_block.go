@@ -0,0 +1,191 @@
+package mesh
+
+import (
+	"hash/crc32"
+	"sync"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/threads/sab"
+)
+
+// SupervisorHealthSource reports the thread supervisor's health for the SAB
+// health block. The kernel provides it; the mesh does not own threads.
+type SupervisorHealthSource interface {
+	SupervisorHealth() sab.SupervisorHealth
+}
+
+// healthBlockState serializes health block writes so the seqlock has one
+// writer.
+type healthBlockState struct {
+	mu       sync.Mutex
+	sequence uint32
+	source   SupervisorHealthSource
+}
+
+// SetSupervisorHealthSource sets where the supervisor section of the health
+// block comes from. Without one the section reads HealthStatusUnknown.
+func (m *MeshCoordinator) SetSupervisorHealthSource(source SupervisorHealthSource) {
+	m.healthBlock.mu.Lock()
+	m.healthBlock.source = source
+	m.healthBlock.mu.Unlock()
+}
+
+// HealthBlock builds the per-subsystem health the SAB health block carries.
+func (m *MeshCoordinator) HealthBlock() *sab.HealthBlock {
+	m.healthBlock.mu.Lock()
+	source := m.healthBlock.source
+	m.healthBlock.mu.Unlock()
+
+	b := &sab.HealthBlock{Version: sab.HEALTH_BLOCK_VERSION, WrittenAt: time.Now()}
+	metrics := m.GetMetrics()
+	sector := m.sector.Load()
+
+	b.Mesh = sab.MeshHealth{
+		TotalPeers:       metrics.TotalPeers,
+		ConnectedPeers:   metrics.ConnectedPeers,
+		LocalChunks:      metrics.LocalChunks,
+		ChunksAvailable:  metrics.TotalChunksAvailable,
+		AvgReputation:    metrics.AvgReputation,
+		SectorID:         sector,
+		SectorMembers:    uint32(len(m.sectorMembers()[sector])) + 1,
+		RegionID:         crc32.ChecksumIEEE([]byte(m.region)),
+		FetchSuccessRate: metrics.ChunkFetchSuccessRate,
+		Busy:             m.Busy(),
+		InboundHeldBytes: m.GetInboundStorageStats().HeldBytes,
+	}
+	b.Mesh.Status, b.Mesh.Score = sab.HealthStatusOK, 1
+	if metrics.ConnectedPeers == 0 {
+		b.Mesh.Status, b.Mesh.Score = sab.HealthStatusDegraded, 0.5
+	}
+
+	if m.gossip != nil {
+		g := m.gossip.GetMetrics()
+		b.Gossip = sab.GossipHealth{
+			MessagesSent:        g.MessagesSent,
+			MessagesReceived:    g.MessagesReceived,
+			MessagesDropped:     g.MessagesDropped,
+			RateLimited:         g.RateLimited,
+			FailedSignatures:    g.FailedSignatures,
+			QueueLength:         g.QueueLength,
+			Fanout:              uint32(g.Fanout),
+			MessageRate:         m.gossip.GetMessageRate(),
+			DeliveryRatio:       float32(g.DeliveryRatio),
+			DuplicateRatio:      float32(g.DuplicateRatio),
+			PropagationP95Ms:    float32(g.PropagationLatencyP95),
+			NetworkSizeEstimate: uint32(g.NetworkSizeEstimate),
+		}
+		b.Gossip.Status, b.Gossip.Score = sab.HealthStatusDegraded, m.gossip.GetHealthScore()
+		if m.gossip.IsHealthy() {
+			b.Gossip.Status = sab.HealthStatusOK
+		}
+	}
+
+	if m.dht != nil {
+		d := m.dht.GetMetrics()
+		b.DHT = sab.DHTHealth{
+			Entries:             m.dht.GetEntryCount(),
+			Peers:               m.dht.TotalPeers(),
+			NetworkSizeEstimate: uint32(m.dht.EstimateNetworkSize()),
+			LookupSuccessRate:   float32(d.SuccessRate),
+			LookupP95Ms:         uint32(d.LookupLatencyP95),
+			Queries:             uint64(d.TotalQueries),
+			FailedQueries:       uint64(d.FailedQueries),
+			RejectedNodeIDs:     uint64(d.RejectedNodeIDs),
+			InvalidNodeReplies:  uint64(d.InvalidNodeReplies),
+		}
+		b.DHT.Status, b.DHT.Score = sab.HealthStatusOK, m.dht.GetHealthScore()
+		if b.DHT.Peers == 0 {
+			b.DHT.Status = sab.HealthStatusDegraded
+		}
+	}
+
+	conn := m.transport.GetConnectionMetrics()
+	health := m.transport.GetHealth()
+	b.Transport = sab.TransportHealth{
+		HealthSection:      sab.HealthSection{Status: transportHealthStatus(health.Status), Score: health.Score},
+		ActiveConnections:  conn.ActiveConnections,
+		WebSocketFallbacks: conn.WebSocketFallbacks,
+		BytesSent:          conn.BytesSent,
+		BytesReceived:      conn.BytesReceived,
+		MessagesSent:       conn.MessagesSent,
+		MessagesReceived:   conn.MessagesReceived,
+		FailedMessages:     conn.FailedMessages,
+		LatencyP50Ms:       conn.LatencyP50,
+		LatencyP95Ms:       conn.LatencyP95,
+		LatencyP99Ms:       conn.LatencyP99,
+		ErrorRate:          conn.ErrorRate,
+		TotalConnections:   conn.TotalConnections,
+	}
+
+	if m.ledger != nil {
+		b.Economy = m.ledger.health()
+		b.Economy.Balance = m.ledger.GetBalance(m.economicAccount())
+		execution := m.GetExecutionStats()
+		b.Economy.JobsExecuted, b.Economy.JobsFailed = execution.Executed, execution.Failed
+		b.Economy.Status, b.Economy.Score = sab.HealthStatusOK, 1
+	}
+
+	if source != nil {
+		b.Supervisor = source.SupervisorHealth()
+	}
+	return b
+}
+
+// economicAccount is the ledger account this node's credits are held under.
+func (m *MeshCoordinator) economicAccount() string {
+	m.identityMu.RLock()
+	defer m.identityMu.RUnlock()
+	if m.did != "" {
+		return m.did
+	}
+	return m.nodeID
+}
+
+func transportHealthStatus(status string) uint32 {
+	switch status {
+	case "healthy", "running":
+		return sab.HealthStatusOK
+	case "degraded":
+		return sab.HealthStatusDegraded
+	case "unhealthy", "stopped":
+		return sab.HealthStatusDown
+	default:
+		return sab.HealthStatusUnknown
+	}
+}
+
+// health returns the ledger totals for the health block.
+func (el *EconomicLedger) health() sab.EconomyHealth {
+	el.mu.RLock()
+	defer el.mu.RUnlock()
+	return sab.EconomyHealth{
+		Escrowed:      el.totalEscrowed,
+		Settled:       el.totalSettled,
+		Refunded:      el.totalRefunded,
+		Slashed:       el.totalSlashed,
+		ActiveEscrows: uint32(len(el.escrows)),
+		Accounts:      uint32(len(el.balances)),
+		Settlements:   el.settlementsCount,
+	}
+}
+
+// packHealthBlock writes the health block to the SAB for the host.
+func (m *MeshCoordinator) packHealthBlock() {
+	if m.bridge == nil {
+		return
+	}
+	block := m.HealthBlock()
+
+	m.healthBlock.mu.Lock()
+	m.healthBlock.sequence += 2
+	if m.healthBlock.sequence == 0 {
+		m.healthBlock.sequence = 2 // 0 reads as never written
+	}
+	err := sab.WriteHealthBlock(m.bridge, m.healthBlock.sequence, block)
+	m.healthBlock.mu.Unlock()
+	if err != nil {
+		m.logger.Debug("failed to write health block", "error", err)
+		return
+	}
+	m.bridge.SignalEpoch(sab.IDX_HEALTH_EPOCH)
+}
@@ -0,0 +1,47 @@
+package mesh
+
+import (
+	"testing"
+
+	"github.com/nmxmxh/inos_v1/kernel/threads/sab"
+)
+
+type fixedSupervisorHealth sab.SupervisorHealth
+
+func (f fixedSupervisorHealth) SupervisorHealth() sab.SupervisorHealth {
+	return sab.SupervisorHealth(f)
+}
+
+func TestHealthBlock_PackedForHost(t *testing.T) {
+	coord := NewMeshCoordinator("node-a", "us-east", &MockTransport{nodeID: "node-a"}, nil)
+	bridge := newRegionSABBridge(int(sab.OFFSET_HEALTH_BLOCK + sab.SIZE_HEALTH_BLOCK))
+	coord.SetSABBridge(bridge)
+	coord.ledger.RegisterAccount(coord.economicAccount(), 42)
+	coord.SetSupervisorHealthSource(fixedSupervisorHealth{
+		HealthSection: sab.HealthSection{Status: sab.HealthStatusDegraded, Score: 0.5},
+		ActiveThreads: 4,
+		FailedThreads: 2,
+	})
+
+	coord.packHealthBlock()
+	coord.packHealthBlock()
+	block, err := sab.ReadHealthBlock(sab.ByteRegionMemory(bridge.data))
+	if err != nil {
+		t.Fatalf("ReadHealthBlock failed: %v", err)
+	}
+	if block.Sequence != 4 || block.Version != sab.HEALTH_BLOCK_VERSION {
+		t.Fatalf("expected the second write at sequence 4, got %+v", block)
+	}
+	if block.Transport.Status != sab.HealthStatusOK {
+		t.Errorf("expected a healthy transport, got status %d", block.Transport.Status)
+	}
+	if block.Mesh.Status != sab.HealthStatusDegraded {
+		t.Errorf("expected the mesh degraded without peers, got status %d", block.Mesh.Status)
+	}
+	if block.Economy.Status != sab.HealthStatusOK || block.Economy.Balance != 42 {
+		t.Errorf("expected the node's balance in the economy section, got %+v", block.Economy)
+	}
+	if block.Supervisor.ActiveThreads != 4 || block.Supervisor.FailedThreads != 2 || block.Supervisor.Status != sab.HealthStatusDegraded {
+		t.Errorf("expected the supervisor section from its source, got %+v", block.Supervisor)
+	}
+}
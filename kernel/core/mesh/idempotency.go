@@ -0,0 +1,146 @@
+package mesh
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrRequestInProgress is returned for a retried request whose first
+// execution is still running. Retry with the same idempotency key to get
+// its result; a new key runs the job again.
+var ErrRequestInProgress = errors.New("request with this idempotency key is still executing")
+
+// maxIdempotencyKeyLen bounds the keys an executor remembers.
+const maxIdempotencyKeyLen = 128
+
+type idempotencyKey struct{}
+
+// WithIdempotencyKey makes DelegateCompute and DelegateJob calls made with
+// ctx at-most-once on the executing peer: a retry carrying the same key
+// gets the first execution's response instead of running the job again.
+// Keys are remembered per requester for Delegation.IdempotencyTTL. They do
+// not follow a retry to a different peer.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKey{}, key)
+}
+
+func idempotencyKeyFromContext(ctx context.Context) string {
+	key, _ := ctx.Value(idempotencyKey{}).(string)
+	return key
+}
+
+func validateIdempotencyKey(key string) error {
+	if len(key) > maxIdempotencyKeyLen {
+		return fmt.Errorf("idempotency key longer than %d bytes", maxIdempotencyKeyLen)
+	}
+	return nil
+}
+
+// idempotencyCache remembers the outcome of recently executed requests by
+// key, so a duplicate gets the first execution's answer.
+type idempotencyCache struct {
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List // *idempotentCall, oldest first
+	replays uint64
+}
+
+type idempotentCall struct {
+	key      string
+	done     chan struct{}
+	value    interface{}
+	err      error
+	finished time.Time // Zero while running
+}
+
+// do runs fn once per key within ttl and returns its value to every caller
+// with that key, reporting whether the value is a replay. A duplicate of a
+// running call waits for it until ctx ends, then fails with
+// ErrRequestInProgress. Failed calls are forgotten so they can be retried;
+// an empty key always runs fn.
+func (c *idempotencyCache) do(ctx context.Context, key string, ttl time.Duration, size int, fn func() (interface{}, error)) (interface{}, bool, error) {
+	if key == "" {
+		value, err := fn()
+		return value, false, err
+	}
+
+	now := time.Now()
+	c.mu.Lock()
+	if c.entries == nil {
+		c.entries = make(map[string]*list.Element)
+		c.order = list.New()
+	}
+	c.expire(now, ttl, size)
+	if elem, ok := c.entries[key]; ok {
+		call := elem.Value.(*idempotentCall)
+		c.replays++
+		c.mu.Unlock()
+
+		select {
+		case <-call.done:
+			return call.value, true, call.err
+		case <-ctx.Done():
+			return nil, false, ErrRequestInProgress
+		}
+	}
+	call := &idempotentCall{key: key, done: make(chan struct{})}
+	c.entries[key] = c.order.PushBack(call)
+	c.mu.Unlock()
+
+	value, err := fn()
+
+	c.mu.Lock()
+	call.value, call.err, call.finished = value, err, time.Now()
+	if err != nil {
+		if elem, ok := c.entries[key]; ok && elem.Value == call {
+			c.order.Remove(elem)
+			delete(c.entries, key)
+		}
+	}
+	c.mu.Unlock()
+	close(call.done)
+	return value, false, err
+}
+
+// expire drops finished calls older than ttl, then the oldest finished
+// ones while more than size are held. Running calls are never dropped.
+func (c *idempotencyCache) expire(now time.Time, ttl time.Duration, size int) {
+	for elem := c.order.Front(); elem != nil; {
+		next := elem.Next()
+		call := elem.Value.(*idempotentCall)
+		finished := !call.finished.IsZero()
+		if finished && (now.Sub(call.finished) > ttl || (size > 0 && c.order.Len() >= size)) {
+			c.order.Remove(elem)
+			delete(c.entries, call.key)
+		} else if finished {
+			break
+		}
+		elem = next
+	}
+}
+
+// replayCount returns how many duplicates have been answered or held.
+func (c *idempotencyCache) replayCount() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.replays
+}
+
+// idempotencyCacheKey scopes a request's key to the method and the peer
+// that sent it, so peers cannot collide with or read each other's results.
+func idempotencyCacheKey(method, peerID, key string) string {
+	if key == "" {
+		return ""
+	}
+	return method + "\x00" + peerID + "\x00" + key
+}
+
+// runIdempotent executes fn at most once per key from peerID for method.
+func (m *MeshCoordinator) runIdempotent(ctx context.Context, method, peerID, key string, fn func() (interface{}, error)) (interface{}, bool, error) {
+	return m.idempotency.do(ctx, idempotencyCacheKey(method, peerID, key),
+		m.config.Delegation.IdempotencyTTL, m.config.Delegation.IdempotencyCacheSize, fn)
+}
@@ -0,0 +1,94 @@
+package mesh
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/threads/foundation"
+)
+
+func TestIdempotency_RetriedDelegationRunsOnce(t *testing.T) {
+	coord, _ := newDelegationTestCoordinator(t)
+	var runs atomic.Int32
+	coord.SetDispatcher(&mockDispatcher{
+		run: func(job *foundation.Job) *foundation.Result {
+			n := runs.Add(1)
+			return &foundation.Result{JobID: job.ID, Success: true, Data: []byte{byte(n)}}
+		},
+	})
+
+	ctx := WithIdempotencyKey(context.Background(), "job-7")
+	first, err := coord.DelegateCompute(ctx, "compress", "input-digest", []byte("source"))
+	if err != nil {
+		t.Fatalf("DelegateCompute failed: %v", err)
+	}
+	retry, err := coord.DelegateCompute(ctx, "compress", "input-digest", []byte("source"))
+	if err != nil {
+		t.Fatalf("retried DelegateCompute failed: %v", err)
+	}
+	if runs.Load() != 1 || string(first) != string(retry) {
+		t.Fatalf("expected one execution replayed to the retry, got %d runs", runs.Load())
+	}
+	if coord.idempotency.replayCount() != 1 {
+		t.Fatalf("expected one replay, got %d", coord.idempotency.replayCount())
+	}
+
+	if _, err := coord.DelegateCompute(WithIdempotencyKey(context.Background(), "job-8"), "compress", "input-digest", []byte("source")); err != nil {
+		t.Fatalf("DelegateCompute with a new key failed: %v", err)
+	}
+	if _, err := coord.DelegateCompute(context.Background(), "compress", "input-digest", []byte("source")); err != nil {
+		t.Fatalf("DelegateCompute without a key failed: %v", err)
+	}
+	if runs.Load() != 3 {
+		t.Fatalf("expected new and missing keys to run again, got %d runs", runs.Load())
+	}
+}
+
+func TestIdempotencyCache_DuplicatesWaitAndFailuresRetry(t *testing.T) {
+	var cache idempotencyCache
+	ctx := context.Background()
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	go func() {
+		_, _, _ = cache.do(ctx, "k", time.Minute, 8, func() (interface{}, error) {
+			close(started)
+			<-release
+			return "done", nil
+		})
+	}()
+	<-started
+
+	short, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if _, _, err := cache.do(short, "k", time.Minute, 8, nil); !errors.Is(err, ErrRequestInProgress) {
+		t.Fatalf("expected in-progress error for a running duplicate, got %v", err)
+	}
+	close(release)
+	value, replayed, err := cache.do(ctx, "k", time.Minute, 8, nil)
+	if err != nil || !replayed || value != "done" {
+		t.Fatalf("expected replayed result, got %v %v %v", value, replayed, err)
+	}
+
+	runs := 0
+	fail := func() (interface{}, error) {
+		runs++
+		return nil, errors.New("not run")
+	}
+	_, _, _ = cache.do(ctx, "f", time.Minute, 8, fail)
+	_, _, _ = cache.do(ctx, "f", time.Minute, 8, fail)
+	if runs != 2 {
+		t.Fatalf("expected a failed call to be retried, got %d runs", runs)
+	}
+
+	// Past the size limit the oldest finished result is dropped
+	for _, key := range []string{"a", "b"} {
+		_, _, _ = cache.do(ctx, key, time.Minute, 2, func() (interface{}, error) { return key, nil })
+	}
+	if _, ok := cache.entries["k"]; ok {
+		t.Fatal("expected the oldest entry to be dropped at the size limit")
+	}
+}
@@ -0,0 +1,220 @@
+package mesh
+
+import (
+	"sync"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/threads/sab"
+)
+
+// Deferrable work the coordinator hands to RunWhenIdle. Gossip names its
+// anti-entropy rounds "anti_entropy".
+const (
+	idleTaskCacheCleanup = "cache_cleanup"
+	idleTaskMetricsPack  = "metrics_pack"
+)
+
+// idleRunKind is how a deferred task came to run.
+type idleRunKind int
+
+const (
+	idleRunDirect idleRunKind = iota // No host frames to wait for
+	idleRunWindow                    // Started inside a host idle window
+	idleRunForced                    // Waited IdleSchedule.MaxDelay without a window
+)
+
+// IdleTaskStats counts the runs of one kind of deferrable work.
+type IdleTaskStats struct {
+	Runs           uint64        `json:"runs"`
+	IdleRuns       uint64        `json:"idle_runs"`       // Started inside a host idle window
+	ForcedRuns     uint64        `json:"forced_runs"`     // Ran after IdleSchedule.MaxDelay without a window
+	DeadlineMisses uint64        `json:"deadline_misses"` // Started in a window but ran past the next frame's deadline
+	LastDuration   time.Duration `json:"last_duration"`
+}
+
+// IdleScheduleStats reports the idle scheduler.
+type IdleScheduleStats struct {
+	HostFrames     bool                     `json:"host_frames"` // The host is reporting frames; without them work runs at once
+	FrameSequence  uint32                   `json:"frame_sequence"`
+	Pending        int                      `json:"pending"`
+	DeadlineMisses uint64                   `json:"deadline_misses"` // Across all tasks
+	Tasks          map[string]IdleTaskStats `json:"tasks"`
+}
+
+type idleTask struct {
+	name   string
+	fn     func()
+	queued time.Time
+}
+
+type idleScheduleState struct {
+	mu      sync.Mutex
+	pending []*idleTask
+	stats   map[string]*IdleTaskStats
+	wake    chan struct{}
+}
+
+// RunWhenIdle runs fn in the host's next idle window: the time between a
+// frame's work finishing and the next frame being due, as the host reports
+// it in the frame schedule region. Without a bridge, or while the host is
+// not reporting frames (hidden page, native node), fn runs at once. Work
+// that finds no window within IdleSchedule.MaxDelay runs anyway. A task
+// queued again before it ran keeps its place and runs the newer fn.
+func (m *MeshCoordinator) RunWhenIdle(task string, fn func()) {
+	if m.config.IdleSchedule.PollInterval <= 0 {
+		m.runIdleTask(task, fn, idleRunDirect, time.Time{})
+		return
+	}
+	if _, rendering := m.frameSchedule(); !rendering {
+		m.runIdleTask(task, fn, idleRunDirect, time.Time{})
+		return
+	}
+
+	s := &m.idle
+	s.mu.Lock()
+	queued := false
+	for _, t := range s.pending {
+		if t.name == task {
+			t.fn = fn
+			queued = true
+			break
+		}
+	}
+	if !queued {
+		s.pending = append(s.pending, &idleTask{name: task, fn: fn, queued: time.Now()})
+	}
+	s.mu.Unlock()
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// frameSchedule reads the host's frame report and whether it is current.
+// A host that stopped rendering leaves its last report behind, which goes
+// stale after IdleSchedule.StaleAfter.
+func (m *MeshCoordinator) frameSchedule() (sab.FrameSchedule, bool) {
+	if m.bridge == nil {
+		return sab.FrameSchedule{}, false
+	}
+	buf, err := m.bridge.ReadRaw(sab.OFFSET_FRAME_SCHEDULE, sab.SIZE_FRAME_SCHEDULE)
+	if err != nil {
+		return sab.FrameSchedule{}, false
+	}
+	frame, err := sab.DecodeFrameSchedule(buf)
+	if err != nil || frame.Sequence == 0 {
+		return frame, false
+	}
+	return frame, time.Since(frame.FrameStart) <= m.config.IdleSchedule.StaleAfter
+}
+
+// runIdleTasks runs queued work that may start now, oldest first, and
+// returns how many tasks are still waiting for a window.
+func (m *MeshCoordinator) runIdleTasks() int {
+	cfg := m.config.IdleSchedule
+	s := &m.idle
+	for {
+		frame, rendering := m.frameSchedule()
+		now := time.Now()
+
+		s.mu.Lock()
+		if len(s.pending) == 0 {
+			s.mu.Unlock()
+			return 0
+		}
+		task := s.pending[0]
+		kind := idleRunDirect
+		switch {
+		case !rendering:
+		case frame.IdleAt(now) >= cfg.MinIdle:
+			kind = idleRunWindow
+		case now.Sub(task.queued) >= cfg.MaxDelay:
+			kind = idleRunForced
+		default:
+			waiting := len(s.pending)
+			s.mu.Unlock()
+			return waiting
+		}
+		s.pending = s.pending[1:]
+		s.mu.Unlock()
+
+		m.runIdleTask(task.name, task.fn, kind, frame.Deadline)
+	}
+}
+
+// runIdleTask runs fn and records it. A run started in a window misses its
+// deadline if it is still going when the next frame is due.
+func (m *MeshCoordinator) runIdleTask(name string, fn func(), kind idleRunKind, deadline time.Time) {
+	start := time.Now()
+	fn()
+	end := time.Now()
+
+	s := &m.idle
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stats == nil {
+		s.stats = make(map[string]*IdleTaskStats)
+	}
+	st := s.stats[name]
+	if st == nil {
+		st = &IdleTaskStats{}
+		s.stats[name] = st
+	}
+	st.Runs++
+	st.LastDuration = end.Sub(start)
+	switch kind {
+	case idleRunWindow:
+		st.IdleRuns++
+		if end.After(deadline) {
+			st.DeadlineMisses++
+		}
+	case idleRunForced:
+		st.ForcedRuns++
+	}
+}
+
+// GetIdleScheduleStats reports deferred work and deadline misses.
+func (m *MeshCoordinator) GetIdleScheduleStats() IdleScheduleStats {
+	frame, rendering := m.frameSchedule()
+
+	s := &m.idle
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stats := IdleScheduleStats{
+		HostFrames:    rendering,
+		FrameSequence: frame.Sequence,
+		Pending:       len(s.pending),
+		Tasks:         make(map[string]IdleTaskStats, len(s.stats)),
+	}
+	for name, st := range s.stats {
+		stats.Tasks[name] = *st
+		stats.DeadlineMisses += st.DeadlineMisses
+	}
+	return stats
+}
+
+// idleScheduleLoop polls for idle windows while work is queued, and sleeps
+// otherwise.
+func (m *MeshCoordinator) idleScheduleLoop() {
+	interval := m.config.IdleSchedule.PollInterval
+	if interval <= 0 {
+		return
+	}
+
+	timer := time.NewTimer(interval)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-m.idle.wake:
+		case <-timer.C:
+		case <-m.shutdown:
+			return
+		}
+		if m.runIdleTasks() > 0 {
+			timer.Reset(interval)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package mesh
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/threads/sab"
+)
+
+func newIdleTestCoordinator(t *testing.T) (*MeshCoordinator, *regionSABBridge) {
+	t.Helper()
+	coord := NewMeshCoordinator("node-a", "us-east", &MockTransport{nodeID: "node-a"}, nil)
+	bridge := newRegionSABBridge(int(sab.OFFSET_FRAME_SCHEDULE + sab.SIZE_FRAME_SCHEDULE))
+	coord.SetSABBridge(bridge)
+	return coord, bridge
+}
+
+// reportFrame writes a host frame report: work runs for busy, then the
+// window stays open for idle.
+func reportFrame(t *testing.T, bridge *regionSABBridge, seq uint32, start time.Time, busy, idle time.Duration) {
+	t.Helper()
+	buf := sab.EncodeFrameSchedule(sab.FrameSchedule{
+		Sequence:   seq,
+		FrameStart: start,
+		IdleStart:  start.Add(busy),
+		Deadline:   start.Add(busy + idle),
+	})
+	if err := bridge.WriteRaw(sab.OFFSET_FRAME_SCHEDULE, buf); err != nil {
+		t.Fatalf("write frame schedule: %v", err)
+	}
+}
+
+func TestIdleSchedule_RunsAtOnceWithoutHostFrames(t *testing.T) {
+	coord, _ := newIdleTestCoordinator(t)
+
+	ran := 0
+	coord.RunWhenIdle("task", func() { ran++ })
+	if ran != 1 {
+		t.Fatalf("expected work to run at once before the host reports frames, ran %d", ran)
+	}
+
+	stats := coord.GetIdleScheduleStats()
+	if stats.HostFrames || stats.Pending != 0 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+	if task := stats.Tasks["task"]; task.Runs != 1 || task.IdleRuns != 0 {
+		t.Fatalf("expected one direct run, got %+v", task)
+	}
+}
+
+func TestIdleSchedule_WaitsForIdleWindow(t *testing.T) {
+	coord, bridge := newIdleTestCoordinator(t)
+
+	// Mid-frame: work is still running
+	now := time.Now()
+	reportFrame(t, bridge, 1, now.Add(-time.Millisecond), 10*time.Millisecond, 6*time.Millisecond)
+
+	ran := 0
+	coord.RunWhenIdle("task", func() { ran++ })
+	coord.RunWhenIdle("task", func() { ran += 10 })
+	if ran != 0 {
+		t.Fatal("expected work to wait for the idle window")
+	}
+	if waiting := coord.runIdleTasks(); waiting != 1 {
+		t.Fatalf("expected one coalesced task waiting, got %d", waiting)
+	}
+
+	// The frame's work is done and the window is open
+	reportFrame(t, bridge, 2, time.Now().Add(-time.Millisecond), 0, time.Second)
+	if waiting := coord.runIdleTasks(); waiting != 0 {
+		t.Fatalf("expected the queue to drain, %d waiting", waiting)
+	}
+	if ran != 10 {
+		t.Fatalf("expected the newer fn to run once, ran %d", ran)
+	}
+	if task := coord.GetIdleScheduleStats().Tasks["task"]; task.IdleRuns != 1 || task.DeadlineMisses != 0 {
+		t.Fatalf("expected one idle run without a miss, got %+v", task)
+	}
+}
+
+func TestIdleSchedule_ForcesWorkAfterMaxDelay(t *testing.T) {
+	coord, bridge := newIdleTestCoordinator(t)
+	coord.config.IdleSchedule.MaxDelay = time.Millisecond
+
+	reportFrame(t, bridge, 1, time.Now(), time.Hour, time.Millisecond)
+
+	ran := false
+	coord.RunWhenIdle("task", func() { ran = true })
+	time.Sleep(2 * time.Millisecond)
+	coord.runIdleTasks()
+	if !ran {
+		t.Fatal("expected work to run after waiting MaxDelay")
+	}
+	if task := coord.GetIdleScheduleStats().Tasks["task"]; task.ForcedRuns != 1 {
+		t.Fatalf("expected a forced run, got %+v", task)
+	}
+}
+
+func TestIdleSchedule_CountsDeadlineMisses(t *testing.T) {
+	coord, bridge := newIdleTestCoordinator(t)
+
+	reportFrame(t, bridge, 1, time.Now().Add(-time.Millisecond), 0, 5*time.Millisecond)
+
+	coord.RunWhenIdle("slow", func() { time.Sleep(10 * time.Millisecond) })
+	coord.runIdleTasks()
+
+	stats := coord.GetIdleScheduleStats()
+	if stats.Tasks["slow"].DeadlineMisses != 1 || stats.DeadlineMisses != 1 {
+		t.Fatalf("expected a deadline miss, got %+v", stats)
+	}
+}
+
+func TestIdleSchedule_StaleReportRunsAtOnce(t *testing.T) {
+	coord, bridge := newIdleTestCoordinator(t)
+
+	// A hidden page stops reporting frames
+	reportFrame(t, bridge, 7, time.Now().Add(-time.Minute), time.Hour, 0)
+
+	ran := false
+	coord.RunWhenIdle("task", func() { ran = true })
+	if !ran {
+		t.Fatal("expected work to run at once once the frame report is stale")
+	}
+}
@@ -0,0 +1,429 @@
+package mesh
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/routing"
+)
+
+const replicaAnnouncementVersion = "inos-replica-ann-v1"
+
+// Codes a chunk.store is rejected with.
+const (
+	StoreRejectRateLimited     = "rate_limited"     // Sender pushed over InboundStorage.PeerRateBytes this window
+	StoreRejectPeerQuota       = "peer_quota"       // Announcer's held bytes would pass InboundStorage.PeerBytes
+	StoreRejectGlobalQuota     = "global_quota"     // Bytes held for peers would pass InboundStorage.GlobalBytes
+	StoreRejectUnjustified     = "unjustified"      // No announcement or payment covers the replica
+	StoreRejectBadAnnouncement = "bad_announcement" // Announcement forged or not for this chunk
+)
+
+// ErrChunkStoreRejected matches every ChunkStoreRejection.
+var ErrChunkStoreRejected = errors.New("chunk.store rejected")
+
+// ReplicaAnnouncement is a node's signed statement that it wants a chunk
+// kept at Target copies. A holder takes a replica when it knows of fewer
+// providers than that, and charges the bytes to the announcer.
+type ReplicaAnnouncement struct {
+	Version   string            `json:"version"`
+	ChunkHash string            `json:"chunk_hash"`
+	Size      int               `json:"size"`
+	Target    int               `json:"target"`
+	Announcer string            `json:"announcer"`
+	PublicKey ed25519.PublicKey `json:"public_key"`
+	IssuedAt  int64             `json:"issued_at"` // Unix milliseconds
+	Signature []byte            `json:"signature"`
+}
+
+// ChunkStoreRejection is the structured answer to a refused chunk.store. It
+// is also the error the sender gets back.
+type ChunkStoreRejection struct {
+	Code         string `json:"code"`
+	Message      string `json:"message"`
+	LimitBytes   uint64 `json:"limit_bytes,omitempty"`
+	UsedBytes    uint64 `json:"used_bytes,omitempty"`
+	RetryAfterMs int64  `json:"retry_after_ms,omitempty"`
+}
+
+func (r *ChunkStoreRejection) Error() string {
+	return fmt.Sprintf("chunk.store rejected (%s): %s", r.Code, r.Message)
+}
+
+// Is makes errors.Is(err, ErrChunkStoreRejected) hold.
+func (r *ChunkStoreRejection) Is(target error) bool {
+	return target == ErrChunkStoreRejected
+}
+
+// InboundPeerUsage is what one peer has stored here.
+type InboundPeerUsage struct {
+	PeerID           string `json:"peer_id"`
+	HeldBytes        uint64 `json:"held_bytes"`        // Charged to the peer as announcer
+	UnjustifiedBytes uint64 `json:"unjustified_bytes"` // Sent with no announcement or payment
+	Rejections       int    `json:"rejections"`        // In the current abuse window
+}
+
+// InboundStorageStats reports storage held on behalf of peers.
+type InboundStorageStats struct {
+	HeldBytes   uint64             `json:"held_bytes"`
+	GlobalLimit uint64             `json:"global_limit"`
+	Chunks      int                `json:"chunks"`
+	Accepted    uint64             `json:"accepted"`
+	Rejected    map[string]uint64  `json:"rejected"` // By code
+	Peers       []InboundPeerUsage `json:"peers"`    // Largest holders first
+}
+
+// inboundHolding is a replica stored for a peer.
+type inboundHolding struct {
+	account      string // Charged: the announcer, or the sender if unannounced
+	sender       string
+	size         uint64
+	unjustified  bool
+	announcement *ReplicaAnnouncement
+}
+
+type inboundPeer struct {
+	held           uint64
+	unjustified    uint64
+	windowStart    time.Time
+	windowBytes    uint64
+	rejections     int
+	rejectionStart time.Time
+}
+
+// inboundStorageState accounts replicas peers push through chunk.store.
+type inboundStorageState struct {
+	mu       sync.Mutex
+	chunks   map[string]*inboundHolding
+	peers    map[string]*inboundPeer
+	held     uint64
+	accepted uint64
+	rejected map[string]uint64
+}
+
+func newInboundStorageState() inboundStorageState {
+	return inboundStorageState{
+		chunks:   make(map[string]*inboundHolding),
+		peers:    make(map[string]*inboundPeer),
+		rejected: make(map[string]uint64),
+	}
+}
+
+func (s *inboundStorageState) peer(id string) *inboundPeer {
+	p, ok := s.peers[id]
+	if !ok {
+		p = &inboundPeer{}
+		s.peers[id] = p
+	}
+	return p
+}
+
+// Most peers remembered before idle ones are pruned.
+const inboundPeerPruneAt = 4096
+
+func (s *inboundStorageState) pruneIdle(now time.Time, window, abuseWindow time.Duration) {
+	if len(s.peers) < inboundPeerPruneAt {
+		return
+	}
+	for id, p := range s.peers {
+		if p.held == 0 && p.unjustified == 0 && now.Sub(p.windowStart) > window && now.Sub(p.rejectionStart) > abuseWindow {
+			delete(s.peers, id)
+		}
+	}
+}
+
+func replicaAnnouncementPayload(a *ReplicaAnnouncement) []byte {
+	buf := make([]byte, 0, 192)
+	for _, s := range []string{a.Version, a.ChunkHash, a.Announcer} {
+		buf = append(buf, s...)
+		buf = append(buf, 0)
+	}
+	buf = binary.BigEndian.AppendUint64(buf, uint64(a.Size))
+	buf = binary.BigEndian.AppendUint32(buf, uint32(a.Target))
+	buf = binary.BigEndian.AppendUint64(buf, uint64(a.IssuedAt))
+	return append(buf, a.PublicKey...)
+}
+
+type replicaTargetKey struct{}
+
+// withReplicaTarget tells the replica sends under ctx how many copies the
+// chunk is being placed at, for their announcements.
+func withReplicaTarget(ctx context.Context, target int) context.Context {
+	return context.WithValue(ctx, replicaTargetKey{}, target)
+}
+
+// replicaAnnouncementFor returns the announcement a replica of chunkHash is
+// sent with: the one it arrived with if this node holds it for a peer, or
+// a fresh one from this node.
+func (m *MeshCoordinator) replicaAnnouncementFor(ctx context.Context, chunkHash string, size int) *ReplicaAnnouncement {
+	m.inbound.mu.Lock()
+	holding, ok := m.inbound.chunks[chunkHash]
+	m.inbound.mu.Unlock()
+	if ok && holding.announcement != nil {
+		return holding.announcement
+	}
+
+	target, _ := ctx.Value(replicaTargetKey{}).(int)
+	if target <= 0 {
+		target = m.config.StorageQuota.ReplicaTarget
+	}
+	ann := &ReplicaAnnouncement{
+		Version:   replicaAnnouncementVersion,
+		ChunkHash: chunkHash,
+		Size:      size,
+		Target:    target,
+		Announcer: m.nodeID,
+		PublicKey: m.gossip.PublicKey(),
+		IssuedAt:  time.Now().UnixMilli(),
+	}
+	sig, pub, err := m.gossip.SignAttestation(replicaAnnouncementPayload(ann))
+	if err != nil || !pub.Equal(ann.PublicKey) {
+		m.logger.Debug("sending replica without announcement", "chunk", getShortID(chunkHash), "error", err)
+		return nil
+	}
+	ann.Signature = sig
+	return ann
+}
+
+// verifyReplicaAnnouncement checks an announcement against the replica it
+// came with.
+func (m *MeshCoordinator) verifyReplicaAnnouncement(a *ReplicaAnnouncement, chunkHash string, size int) error {
+	if a.Version != replicaAnnouncementVersion || a.ChunkHash != chunkHash || a.Size != size {
+		return errors.New("announcement is not for this replica")
+	}
+	if a.Target <= 0 || (m.config.InboundStorage.MaxReplicaTarget > 0 && a.Target > m.config.InboundStorage.MaxReplicaTarget) {
+		return fmt.Errorf("replica target %d out of range", a.Target)
+	}
+	if !ed25519.Verify(a.PublicKey, replicaAnnouncementPayload(a), a.Signature) {
+		return errors.New("invalid announcement signature")
+	}
+	return nil
+}
+
+// knownProviders counts the other nodes this node knows store chunkHash.
+func (m *MeshCoordinator) knownProviders(chunkHash string) int {
+	n := 0
+	for _, provider := range m.dht.LocalProviders(chunkHash) {
+		if provider != m.nodeID {
+			n++
+		}
+	}
+	return n
+}
+
+// paidForStorage reports whether peerID, or the DID it attested, holds
+// InboundStorage.PaidMinBalance credits on our ledger.
+func (m *MeshCoordinator) paidForStorage(peerID string) bool {
+	minBalance := m.config.InboundStorage.PaidMinBalance
+	if minBalance <= 0 || m.ledger == nil {
+		return false
+	}
+	if m.ledger.GetBalance(peerID) >= minBalance {
+		return true
+	}
+	m.attestationMu.RLock()
+	did := m.attestedPeers[peerID].DID
+	m.attestationMu.RUnlock()
+	return did != "" && m.ledger.GetBalance(did) >= minBalance
+}
+
+// admitInboundChunk decides whether a replica peerID pushes may be stored
+// and, if so, charges it. A replica needs an announcement whose target is
+// not yet met, a sender with InboundStorage.PaidMinBalance credits, or room
+// in the sender's small InboundStorage.UnjustifiedBytes allowance; it must
+// then fit the sender's rate and the announcer's and global quotas.
+func (m *MeshCoordinator) admitInboundChunk(peerID, chunkHash string, ann *ReplicaAnnouncement, size int) *ChunkStoreRejection {
+	cfg := m.config.InboundStorage
+	if !cfg.Enabled {
+		return nil
+	}
+	bytes := uint64(size)
+
+	account, justified := peerID, false
+	if ann != nil {
+		if err := m.verifyReplicaAnnouncement(ann, chunkHash, size); err != nil {
+			return &ChunkStoreRejection{Code: StoreRejectBadAnnouncement, Message: err.Error()}
+		}
+	}
+	if ann != nil && m.checkIdentityKey(ann.Announcer, ann.PublicKey) != nil {
+		// A well-formed announcement from a node whose key we have not
+		// learned yet is not forged, but cannot be charged to it either
+		m.logger.Debug("announcer key unknown; replica treated as unannounced", "announcer", getShortID(ann.Announcer))
+		ann = nil
+	}
+	if ann != nil {
+		if providers := m.knownProviders(chunkHash); providers >= ann.Target {
+			return &ChunkStoreRejection{
+				Code:    StoreRejectUnjustified,
+				Message: fmt.Sprintf("%d providers known, announced target is %d", providers, ann.Target),
+			}
+		}
+		account, justified = ann.Announcer, true
+	} else if m.paidForStorage(peerID) {
+		justified = true
+	}
+
+	now := time.Now()
+	s := &m.inbound
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.chunks[chunkHash]; ok {
+		return nil // Already held; nothing more to charge
+	}
+	s.pruneIdle(now, cfg.RateWindow, cfg.AbuseWindow)
+
+	sender := s.peer(peerID)
+	if now.Sub(sender.windowStart) >= cfg.RateWindow {
+		sender.windowStart, sender.windowBytes = now, 0
+	}
+	if cfg.PeerRateBytes > 0 && sender.windowBytes+bytes > cfg.PeerRateBytes {
+		return &ChunkStoreRejection{
+			Code:         StoreRejectRateLimited,
+			Message:      "inbound storage rate exceeded",
+			LimitBytes:   cfg.PeerRateBytes,
+			UsedBytes:    sender.windowBytes,
+			RetryAfterMs: sender.windowStart.Add(cfg.RateWindow).Sub(now).Milliseconds(),
+		}
+	}
+	if !justified && sender.unjustified+bytes > cfg.UnjustifiedBytes {
+		return &ChunkStoreRejection{
+			Code:       StoreRejectUnjustified,
+			Message:    "replica not covered by an announcement or payment",
+			LimitBytes: cfg.UnjustifiedBytes,
+			UsedBytes:  sender.unjustified,
+		}
+	}
+	if cfg.GlobalBytes > 0 && s.held+bytes > cfg.GlobalBytes {
+		return &ChunkStoreRejection{
+			Code:       StoreRejectGlobalQuota,
+			Message:    "storage for peers is full",
+			LimitBytes: cfg.GlobalBytes,
+			UsedBytes:  s.held,
+		}
+	}
+	charged := s.peer(account)
+	if cfg.PeerBytes > 0 && charged.held+bytes > cfg.PeerBytes {
+		return &ChunkStoreRejection{
+			Code:       StoreRejectPeerQuota,
+			Message:    fmt.Sprintf("quota for %s exhausted", getShortID(account)),
+			LimitBytes: cfg.PeerBytes,
+			UsedBytes:  charged.held,
+		}
+	}
+
+	s.held += bytes
+	s.accepted++
+	charged.held += bytes
+	sender.windowBytes += bytes
+	if !justified {
+		sender.unjustified += bytes
+	}
+	s.chunks[chunkHash] = &inboundHolding{
+		account:      account,
+		sender:       peerID,
+		size:         bytes,
+		unjustified:  !justified,
+		announcement: ann,
+	}
+	return nil
+}
+
+// releaseInboundChunk returns a replica's bytes to its quotas once it is no
+// longer stored.
+func (m *MeshCoordinator) releaseInboundChunk(chunkHash string) {
+	s := &m.inbound
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	holding, ok := s.chunks[chunkHash]
+	if !ok {
+		return
+	}
+	delete(s.chunks, chunkHash)
+	s.held -= holding.size
+	if p, ok := s.peers[holding.account]; ok {
+		p.held -= holding.size
+	}
+	if p, ok := s.peers[holding.sender]; ok && holding.unjustified {
+		p.unjustified -= holding.size
+	}
+}
+
+// rejectInboundChunk counts a rejection against the sender. A forged
+// announcement is penalized at once; other rejections once the sender
+// collects InboundStorage.AbuseThreshold of them within AbuseWindow.
+func (m *MeshCoordinator) rejectInboundChunk(ctx context.Context, peerID, chunkHash string, rejection *ChunkStoreRejection) {
+	cfg := m.config.InboundStorage
+	now := time.Now()
+
+	s := &m.inbound
+	s.mu.Lock()
+	s.rejected[rejection.Code]++
+	p := s.peer(peerID)
+	if now.Sub(p.rejectionStart) >= cfg.AbuseWindow {
+		p.rejectionStart, p.rejections = now, 0
+	}
+	p.rejections++
+	abusive := cfg.AbuseThreshold > 0 && p.rejections >= cfg.AbuseThreshold
+	if abusive {
+		p.rejectionStart, p.rejections = now, 0
+	}
+	s.mu.Unlock()
+
+	m.rpcLogger(ctx).Debug("rejected chunk from peer",
+		"peer", getShortID(peerID),
+		"chunk", getShortID(chunkHash),
+		"code", rejection.Code,
+		"reason", rejection.Message,
+	)
+	if m.reputation == nil {
+		return
+	}
+	if rejection.Code == StoreRejectBadAnnouncement {
+		m.reputation.ReportPenalty(peerID, routing.PenaltyInvalidData)
+	}
+	if abusive {
+		m.logger.Warn("peer keeps pushing rejected chunks", "peer", getShortID(peerID), "code", rejection.Code)
+		m.reputation.ReportPenalty(peerID, routing.PenaltyMaliciousBehavior)
+	}
+}
+
+// GetInboundStorageStats reports storage held for peers and rejections.
+func (m *MeshCoordinator) GetInboundStorageStats() InboundStorageStats {
+	s := &m.inbound
+	s.mu.Lock()
+	stats := InboundStorageStats{
+		HeldBytes:   s.held,
+		GlobalLimit: m.config.InboundStorage.GlobalBytes,
+		Chunks:      len(s.chunks),
+		Accepted:    s.accepted,
+		Rejected:    make(map[string]uint64, len(s.rejected)),
+	}
+	for code, n := range s.rejected {
+		stats.Rejected[code] = n
+	}
+	for id, p := range s.peers {
+		if p.held == 0 && p.unjustified == 0 && p.rejections == 0 {
+			continue
+		}
+		stats.Peers = append(stats.Peers, InboundPeerUsage{
+			PeerID:           id,
+			HeldBytes:        p.held,
+			UnjustifiedBytes: p.unjustified,
+			Rejections:       p.rejections,
+		})
+	}
+	s.mu.Unlock()
+
+	sort.Slice(stats.Peers, func(i, j int) bool {
+		if stats.Peers[i].HeldBytes != stats.Peers[j].HeldBytes {
+			return stats.Peers[i].HeldBytes > stats.Peers[j].HeldBytes
+		}
+		return stats.Peers[i].PeerID < stats.Peers[j].PeerID
+	})
+	return stats
+}
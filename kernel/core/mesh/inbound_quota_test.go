@@ -0,0 +1,178 @@
+package mesh
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func newInboundHolder(t *testing.T) *MeshCoordinator {
+	t.Helper()
+	holder := NewMeshCoordinator("holder", "us-east", &MockTransport{nodeID: "holder"}, nil)
+	holder.SetStorage(&MockStorage{chunks: map[string][]byte{}})
+	holder.config.InboundStorage.UnjustifiedBytes = 64
+	holder.config.InboundStorage.PeerBytes = 256
+	holder.config.InboundStorage.GlobalBytes = 384
+	holder.config.InboundStorage.PeerRateBytes = 1 << 20
+	return holder
+}
+
+// pushChunk sends a chunk.store to holder as coming from peerID.
+func pushChunk(t *testing.T, holder *MeshCoordinator, peerID string, req ChunkStoreRequest) *ChunkStoreRejection {
+	t.Helper()
+	params, _ := json.Marshal(req)
+	handler := holder.transport.(*MockTransport).registeredRPCHandlers[chunkStoreMethod]
+	out, err := handler(context.Background(), peerID, params)
+	if err != nil {
+		t.Fatalf("chunk.store failed: %v", err)
+	}
+	resp := out.(ChunkStoreResponse)
+	if resp.Rejected == nil && !resp.Stored {
+		t.Fatalf("expected the chunk stored or rejected, got %+v", resp)
+	}
+	return resp.Rejected
+}
+
+func announcedReplica(t *testing.T, announcer *MeshCoordinator, hash string, data []byte, target int) ChunkStoreRequest {
+	t.Helper()
+	ann := announcer.replicaAnnouncementFor(withReplicaTarget(context.Background(), target), hash, len(data))
+	if ann == nil {
+		t.Fatal("expected a signed announcement")
+	}
+	return ChunkStoreRequest{ChunkHash: hash, Data: data, Announcement: ann}
+}
+
+func TestInboundQuota_UnjustifiedReplicasAreCapped(t *testing.T) {
+	holder := newInboundHolder(t)
+	chunk := bytes.Repeat([]byte{1}, 48)
+
+	if r := pushChunk(t, holder, "stranger", ChunkStoreRequest{ChunkHash: "c1", Data: chunk}); r != nil {
+		t.Fatalf("expected the first unannounced chunk within the allowance, got %v", r)
+	}
+	r := pushChunk(t, holder, "stranger", ChunkStoreRequest{ChunkHash: "c2", Data: chunk})
+	if r == nil || r.Code != StoreRejectUnjustified || r.LimitBytes != 64 || r.UsedBytes != 48 {
+		t.Fatalf("expected an unjustified rejection, got %+v", r)
+	}
+	if !errors.Is(r, ErrChunkStoreRejected) {
+		t.Fatal("expected the rejection to match ErrChunkStoreRejected")
+	}
+
+	// Storing a held chunk again costs nothing
+	if r := pushChunk(t, holder, "stranger", ChunkStoreRequest{ChunkHash: "c1", Data: chunk}); r != nil {
+		t.Fatalf("expected a held chunk accepted again, got %v", r)
+	}
+
+	// Evicting the chunk gives the allowance back
+	holder.evictChunk(context.Background(), "c1")
+	if r := pushChunk(t, holder, "stranger", ChunkStoreRequest{ChunkHash: "c2", Data: chunk}); r != nil {
+		t.Fatalf("expected room after eviction, got %v", r)
+	}
+
+	// Paying peers are not held to the allowance
+	holder.config.InboundStorage.PaidMinBalance = 100
+	holder.ledger.RegisterAccount("stranger", 100)
+	if r := pushChunk(t, holder, "stranger", ChunkStoreRequest{ChunkHash: "c3", Data: chunk}); r != nil {
+		t.Fatalf("expected a paying peer accepted, got %v", r)
+	}
+}
+
+func TestInboundQuota_AnnouncementsChargeTheAnnouncer(t *testing.T) {
+	holder := newInboundHolder(t)
+	owner := NewMeshCoordinator("owner", "us-east", &MockTransport{nodeID: "owner"}, nil)
+	holder.gossip.SetPeerIdentityKey("owner", owner.gossip.PublicKey())
+	chunk := bytes.Repeat([]byte{2}, 100)
+
+	// Another holder forwards the owner's announcement while repairing
+	for i := 0; i < 2; i++ {
+		req := announcedReplica(t, owner, fmt.Sprintf("a%d", i), chunk, 3)
+		if r := pushChunk(t, holder, "repairer", req); r != nil {
+			t.Fatalf("expected announced replica %d accepted, got %v", i, r)
+		}
+	}
+	if again := holder.replicaAnnouncementFor(context.Background(), "a0", len(chunk)); again == nil || again.Announcer != "owner" {
+		t.Fatalf("expected a held replica to keep the owner's announcement, got %+v", again)
+	}
+	r := pushChunk(t, holder, "repairer", announcedReplica(t, owner, "a2", chunk, 3))
+	if r == nil || r.Code != StoreRejectPeerQuota || r.UsedBytes != 200 {
+		t.Fatalf("expected the owner's quota exhausted, got %+v", r)
+	}
+
+	stats := holder.GetInboundStorageStats()
+	if stats.HeldBytes != 200 || stats.Chunks != 2 || len(stats.Peers) == 0 || stats.Peers[0].PeerID != "owner" {
+		t.Fatalf("expected bytes charged to the owner, got %+v", stats)
+	}
+
+	// A target already met does not justify another copy
+	for _, provider := range []string{"p1", "p2", "p3"} {
+		_ = holder.dht.Store("a3", provider, 3600)
+	}
+	if r := pushChunk(t, holder, "repairer", announcedReplica(t, owner, "a3", chunk, 3)); r == nil || r.Code != StoreRejectUnjustified {
+		t.Fatalf("expected a met target rejected, got %+v", r)
+	}
+
+	// The global quota holds across announcers
+	other := NewMeshCoordinator("other", "us-east", &MockTransport{nodeID: "other"}, nil)
+	holder.gossip.SetPeerIdentityKey("other", other.gossip.PublicKey())
+	if r := pushChunk(t, holder, "other", announcedReplica(t, other, "b0", chunk, 3)); r != nil {
+		t.Fatalf("expected room under the global quota, got %v", r)
+	}
+	if r := pushChunk(t, holder, "other", announcedReplica(t, other, "b1", chunk, 3)); r == nil || r.Code != StoreRejectGlobalQuota {
+		t.Fatalf("expected the global quota enforced, got %+v", r)
+	}
+}
+
+func TestInboundQuota_RateLimitAndAbuse(t *testing.T) {
+	holder := newInboundHolder(t)
+	holder.config.InboundStorage.PeerRateBytes = 64
+	holder.config.InboundStorage.AbuseThreshold = 3
+	owner := NewMeshCoordinator("owner", "us-east", &MockTransport{nodeID: "owner"}, nil)
+	holder.gossip.SetPeerIdentityKey("owner", owner.gossip.PublicKey())
+	chunk := bytes.Repeat([]byte{3}, 48)
+
+	if r := pushChunk(t, holder, "owner", announcedReplica(t, owner, "r0", chunk, 3)); r != nil {
+		t.Fatalf("expected the first chunk within the rate, got %v", r)
+	}
+	r := pushChunk(t, holder, "owner", announcedReplica(t, owner, "r1", chunk, 3))
+	if r == nil || r.Code != StoreRejectRateLimited || r.RetryAfterMs <= 0 {
+		t.Fatalf("expected a rate limit with a retry hint, got %+v", r)
+	}
+
+	before, _ := holder.reputation.GetTrustScore("forger")
+	forged := announcedReplica(t, owner, "f0", chunk, 3)
+	forged.Announcement.Target = 1
+	if r := pushChunk(t, holder, "forger", forged); r == nil || r.Code != StoreRejectBadAnnouncement {
+		t.Fatalf("expected a tampered announcement rejected, got %+v", r)
+	}
+	afterForgery, _ := holder.reputation.GetTrustScore("forger")
+	if afterForgery >= before {
+		t.Fatalf("expected a forged announcement penalized, trust %.3f -> %.3f", before, afterForgery)
+	}
+
+	for i := 0; i < 2; i++ {
+		pushChunk(t, holder, "forger", ChunkStoreRequest{ChunkHash: fmt.Sprintf("u%d", i), Data: bytes.Repeat([]byte{4}, 65)})
+	}
+	abused, _ := holder.reputation.GetTrustScore("forger")
+	if abused >= afterForgery {
+		t.Fatalf("expected repeated rejections penalized, trust %.3f -> %.3f", afterForgery, abused)
+	}
+	if stats := holder.GetInboundStorageStats(); stats.Rejected[StoreRejectRateLimited] != 3 || stats.Rejected[StoreRejectBadAnnouncement] != 1 {
+		t.Fatalf("expected rejections counted by code, got %+v", stats.Rejected)
+	}
+}
+
+func TestInboundQuota_SenderSeesRejection(t *testing.T) {
+	sender := NewMeshCoordinator("sender", "us-east", &MockTransport{nodeID: "sender"}, nil)
+	sender.transport.(*MockTransport).rpcHandlers = map[string]func(args interface{}) (interface{}, error){
+		chunkStoreMethod: func(args interface{}) (interface{}, error) {
+			return ChunkStoreResponse{Rejected: &ChunkStoreRejection{Code: StoreRejectPeerQuota, Message: "full"}}, nil
+		},
+	}
+	err := sender.storeChunkOnPeer(context.Background(), "peer", "chunk", "", []byte("data"))
+	var rejection *ChunkStoreRejection
+	if !errors.As(err, &rejection) || rejection.Code != StoreRejectPeerQuota {
+		t.Fatalf("expected the structured rejection returned, got %v", err)
+	}
+}
@@ -110,6 +110,24 @@ func (cc *ChunkCache) Put(chunkHash string, peerIDs []string, confidence float32
 	}
 }
 
+// Resize changes the capacity, evicting least recently used entries that no
+// longer fit.
+func (cc *ChunkCache) Resize(maxSize int) {
+	if maxSize < 1 {
+		maxSize = 1
+	}
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	cc.maxSize = maxSize
+	for cc.lruList.Len() > cc.maxSize {
+		oldest := cc.lruList.Back()
+		cc.lruList.Remove(oldest)
+		delete(cc.cache, oldest.Value.(*cacheEntry).key)
+		cc.evictions++
+	}
+}
+
 // AddPeer adds a peer to an existing chunk mapping
 func (cc *ChunkCache) AddPeer(chunkHash, peerID string) {
 	cc.mu.Lock()
@@ -85,6 +85,17 @@ func TestChunkCache(t *testing.T) {
 		// c1-c4 should have been removed by Get or CleanupExpired
 	}
 
+	// Test Resize
+	cache.Put("c6", []string{"p6"}, 0.6)
+	cache.Put("c7", []string{"p7"}, 0.7)
+	cache.Resize(1)
+	if metrics := cache.GetMetrics(); metrics.Size != 1 || metrics.MaxSize != 1 {
+		t.Errorf("Expected size 1 after resize, got %d (max %d)", metrics.Size, metrics.MaxSize)
+	}
+	if _, ok := cache.Get("c7"); !ok {
+		t.Error("Most recent entry should survive resize")
+	}
+
 	// Test Clear
 	cache.Clear()
 	if metrics := cache.GetMetrics(); metrics.Size != 0 {
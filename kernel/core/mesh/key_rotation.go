@@ -0,0 +1,246 @@
+package mesh
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/routing"
+)
+
+const (
+	keyRotationVersion = "inos-rotate-v1"
+	keyRotationTopic   = "identity.rotate"
+	keyRotationMaxSkew = 10 * time.Minute
+)
+
+// KeyRotation announces a new identity key. The continuity proof is signed by
+// the outgoing key and the possession proof by the incoming one, so peers can
+// move trust from the old key to the new one without re-attesting. The node ID
+// and DID are unchanged, so reputation, DHT records and ledger balances carry
+// over to the new key.
+type KeyRotation struct {
+	Version         string `json:"version"`
+	PeerID          string `json:"peer_id"`
+	DID             string `json:"did,omitempty"`
+	OldPublicKey    string `json:"old_public_key"`
+	NewPublicKey    string `json:"new_public_key"`
+	Timestamp       int64  `json:"timestamp"`
+	ContinuityProof string `json:"continuity_proof"`
+	PossessionProof string `json:"possession_proof"`
+}
+
+// KeyRevocation records a retired key and the key that replaced it.
+type KeyRevocation struct {
+	PeerID       string    `json:"peer_id"`
+	PublicKey    string    `json:"public_key"`
+	SupersededBy string    `json:"superseded_by"`
+	RevokedAt    time.Time `json:"revoked_at"`
+}
+
+// RotateIdentityKey generates a new identity key, proves continuity with the
+// current key, switches signing over and gossips the rotation to the mesh.
+// With a NodeIdentity installed the new key is persisted first.
+func (m *MeshCoordinator) RotateIdentityKey() (*KeyRotation, error) {
+	if m.gossip == nil {
+		return nil, errors.New("gossip manager unavailable for key rotation")
+	}
+
+	newPublic, newPrivate, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate identity key: %w", err)
+	}
+	oldPublic := m.gossip.PublicKey()
+
+	m.identityMu.RLock()
+	did := m.did
+	m.identityMu.RUnlock()
+
+	rotation := &KeyRotation{
+		Version:      keyRotationVersion,
+		PeerID:       m.nodeID,
+		DID:          did,
+		OldPublicKey: base64.StdEncoding.EncodeToString(oldPublic),
+		NewPublicKey: base64.StdEncoding.EncodeToString(newPublic),
+		Timestamp:    m.MeshTime().UnixNano(),
+	}
+	payload := keyRotationPayload(rotation)
+
+	continuity, _, err := m.gossip.SignAttestation(payload)
+	if err != nil {
+		return nil, fmt.Errorf("continuity proof failed: %w", err)
+	}
+	rotation.ContinuityProof = base64.StdEncoding.EncodeToString(continuity)
+	rotation.PossessionProof = base64.StdEncoding.EncodeToString(ed25519.Sign(newPrivate, payload))
+
+	if identity := m.NodeIdentity(); identity != nil {
+		if err := identity.replaceKey(newPrivate); err != nil {
+			return nil, err
+		}
+	}
+	if err := m.gossip.SetSigningKey(newPrivate); err != nil {
+		return nil, err
+	}
+	m.recordKeyRevocation(rotation)
+	m.resignChunkACLs()
+
+	if err := m.PublishOrQueue(keyRotationTopic, "identity:rotation", rotation); err != nil {
+		m.logger.Warn("failed to announce key rotation", "error", err)
+	}
+	m.logger.Info("identity key rotated")
+	return rotation, nil
+}
+
+// IsKeyRevoked reports whether a public key was retired by a known rotation.
+func (m *MeshCoordinator) IsKeyRevoked(publicKey ed25519.PublicKey) bool {
+	m.keyRotationMu.RLock()
+	defer m.keyRotationMu.RUnlock()
+	_, revoked := m.keyRevocations[base64.StdEncoding.EncodeToString(publicKey)]
+	return revoked
+}
+
+// GetKeyRevocations returns all known key revocations.
+func (m *MeshCoordinator) GetKeyRevocations() []KeyRevocation {
+	m.keyRotationMu.RLock()
+	defer m.keyRotationMu.RUnlock()
+	out := make([]KeyRevocation, 0, len(m.keyRevocations))
+	for _, r := range m.keyRevocations {
+		out = append(out, r)
+	}
+	return out
+}
+
+// applyKeyRotation verifies a peer's rotation and moves its trust to the new key.
+func (m *MeshCoordinator) applyKeyRotation(sender string, rotation KeyRotation) error {
+	if rotation.Version != keyRotationVersion {
+		return fmt.Errorf("unsupported key rotation version: %s", rotation.Version)
+	}
+	if rotation.PeerID == "" || rotation.PeerID != sender {
+		return errors.New("key rotation peer does not match sender")
+	}
+	if skew := m.MeshTime().Sub(time.Unix(0, rotation.Timestamp)); skew > keyRotationMaxSkew || skew < -keyRotationMaxSkew {
+		return errors.New("key rotation timestamp outside allowed window")
+	}
+
+	oldKey, err := decodeRotationKey(rotation.OldPublicKey)
+	if err != nil {
+		return fmt.Errorf("invalid old key: %w", err)
+	}
+	newKey, err := decodeRotationKey(rotation.NewPublicKey)
+	if err != nil {
+		return fmt.Errorf("invalid new key: %w", err)
+	}
+	if m.IsKeyRevoked(oldKey) || m.IsKeyRevoked(newKey) {
+		return errors.New("key rotation references a revoked key")
+	}
+
+	// The outgoing key must be the one this peer attested with or, for a
+	// peer that never attested, the key its ID is bound to. Otherwise any
+	// key could be rotated into the peer's identity.
+	m.attestationMu.RLock()
+	record, attested := m.attestedPeers[rotation.PeerID]
+	m.attestationMu.RUnlock()
+	if attested && len(record.PublicKey) > 0 {
+		if !record.PublicKey.Equal(oldKey) {
+			return errors.New("key rotation old key does not match attested key")
+		}
+	} else if err := m.gossip.CheckPeerKey(rotation.PeerID, oldKey); err != nil {
+		return fmt.Errorf("key rotation old key: %w", err)
+	}
+
+	payload := keyRotationPayload(&rotation)
+	continuity, err := base64.StdEncoding.DecodeString(rotation.ContinuityProof)
+	if err != nil || !ed25519.Verify(oldKey, payload, continuity) {
+		return errors.New("invalid continuity proof")
+	}
+	possession, err := base64.StdEncoding.DecodeString(rotation.PossessionProof)
+	if err != nil || !ed25519.Verify(newKey, payload, possession) {
+		return errors.New("invalid possession proof")
+	}
+
+	if attested {
+		m.attestationMu.Lock()
+		record.PublicKey = newKey
+		m.attestedPeers[rotation.PeerID] = record
+		m.attestationMu.Unlock()
+	}
+	m.gossip.SetPeerIdentityKey(rotation.PeerID, newKey)
+	m.recordKeyRevocation(&rotation)
+	m.gossip.RevokePublicKey(oldKey)
+
+	m.logger.Info("peer rotated identity key", "peer", getShortID(rotation.PeerID))
+	return nil
+}
+
+func (m *MeshCoordinator) recordKeyRevocation(rotation *KeyRotation) {
+	m.keyRotationMu.Lock()
+	m.keyRevocations[rotation.OldPublicKey] = KeyRevocation{
+		PeerID:       rotation.PeerID,
+		PublicKey:    rotation.OldPublicKey,
+		SupersededBy: rotation.NewPublicKey,
+		RevokedAt:    time.Unix(0, rotation.Timestamp),
+	}
+	m.keyRotationMu.Unlock()
+}
+
+func (m *MeshCoordinator) registerKeyRotationGossip() {
+	m.gossip.RegisterHandler(keyRotationTopic, func(msg *common.GossipMessage) error {
+		payload, ok := msg.Payload.(map[string]interface{})
+		if !ok {
+			return errors.New("invalid payload type for identity.rotate")
+		}
+
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+
+		var rotation KeyRotation
+		if err := json.Unmarshal(data, &rotation); err != nil {
+			return err
+		}
+		if rotation.PeerID == m.nodeID {
+			return nil
+		}
+		if err := m.applyKeyRotation(msg.Sender, rotation); err != nil {
+			m.logger.Warn("rejected key rotation", "peer", getShortID(msg.Sender), "error", err)
+			if m.reputation != nil {
+				m.reputation.ReportPenalty(msg.Sender, routing.PenaltyInvalidData)
+			}
+			return err
+		}
+		return nil
+	})
+}
+
+func keyRotationPayload(rotation *KeyRotation) []byte {
+	buf := make([]byte, 0, 256)
+	buf = append(buf, rotation.Version...)
+	buf = append(buf, 0)
+	buf = append(buf, rotation.PeerID...)
+	buf = append(buf, 0)
+	buf = append(buf, rotation.DID...)
+	buf = append(buf, 0)
+	buf = append(buf, rotation.OldPublicKey...)
+	buf = append(buf, 0)
+	buf = append(buf, rotation.NewPublicKey...)
+	buf = append(buf, 0)
+	return binary.BigEndian.AppendUint64(buf, uint64(rotation.Timestamp))
+}
+
+func decodeRotationKey(encoded string) (ed25519.PublicKey, error) {
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) != ed25519.PublicKeySize {
+		return nil, errors.New("public key has invalid size")
+	}
+	return ed25519.PublicKey(raw), nil
+}
@@ -0,0 +1,108 @@
+package mesh
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"testing"
+	"time"
+)
+
+func TestMeshCoordinator_KeyRotationTransfersTrust(t *testing.T) {
+	alice := NewMeshCoordinator("alice", "us-east", &MockTransport{nodeID: "alice"}, nil)
+	bob := NewMeshCoordinator("bob", "us-east", &MockTransport{nodeID: "bob"}, nil)
+
+	oldKey := alice.gossip.PublicKey()
+	bob.attestedPeers["alice"] = AttestationRecord{PublicKey: oldKey, Attested: time.Now()}
+
+	rotation, err := alice.RotateIdentityKey()
+	if err != nil {
+		t.Fatalf("RotateIdentityKey failed: %v", err)
+	}
+	if alice.gossip.PublicKey().Equal(oldKey) {
+		t.Fatal("expected signing key to change")
+	}
+	if !alice.IsKeyRevoked(oldKey) {
+		t.Fatal("expected rotating node to record its own revocation")
+	}
+
+	if err := bob.applyKeyRotation("alice", *rotation); err != nil {
+		t.Fatalf("applyKeyRotation failed: %v", err)
+	}
+	if !bob.attestedPeers["alice"].PublicKey.Equal(alice.gossip.PublicKey()) {
+		t.Fatal("expected attested key to move to the new key")
+	}
+	if !bob.gossip.IsKeyRevoked(oldKey) {
+		t.Fatal("expected gossip layer to reject the old key")
+	}
+
+	if err := bob.applyKeyRotation("alice", *rotation); err == nil {
+		t.Fatal("expected replayed rotation to be rejected")
+	}
+}
+
+func TestMeshCoordinator_KeyRotationRejectsForgedProofs(t *testing.T) {
+	alice := NewMeshCoordinator("alice", "us-east", &MockTransport{nodeID: "alice"}, nil)
+	bob := NewMeshCoordinator("bob", "us-east", &MockTransport{nodeID: "bob"}, nil)
+	mallory := NewMeshCoordinator("mallory", "us-east", &MockTransport{nodeID: "mallory"}, nil)
+
+	bob.attestedPeers["alice"] = AttestationRecord{PublicKey: alice.gossip.PublicKey(), Attested: time.Now()}
+
+	// Mallory rotates her own key but claims to be alice.
+	forged, err := mallory.RotateIdentityKey()
+	if err != nil {
+		t.Fatalf("RotateIdentityKey failed: %v", err)
+	}
+	forged.PeerID = "alice"
+	if err := bob.applyKeyRotation("alice", *forged); err == nil {
+		t.Fatal("expected rotation from a non-attested key to be rejected")
+	}
+
+	rotation, _ := alice.RotateIdentityKey()
+	tampered := *rotation
+	tampered.PossessionProof = base64.StdEncoding.EncodeToString(make([]byte, 64))
+	if err := bob.applyKeyRotation("alice", tampered); err == nil {
+		t.Fatal("expected invalid possession proof to be rejected")
+	}
+	if err := bob.applyKeyRotation("mallory", *rotation); err == nil {
+		t.Fatal("expected sender mismatch to be rejected")
+	}
+}
+
+func TestMeshCoordinator_KeyRotationBindsUnattestedPeers(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+	aliceID := NodeIDFromPublicKey(pub)
+	alice := NewMeshCoordinator(aliceID, "us-east", &MockTransport{nodeID: aliceID}, nil)
+	if err := alice.gossip.SetSigningKey(priv); err != nil {
+		t.Fatalf("SetSigningKey failed: %v", err)
+	}
+	bob := NewMeshCoordinator("bob", "us-east", &MockTransport{nodeID: "bob"}, nil)
+
+	// The first rotation moves away from the key alice's ID was derived from
+	rotation, err := alice.RotateIdentityKey()
+	if err != nil {
+		t.Fatalf("RotateIdentityKey failed: %v", err)
+	}
+	if err := bob.applyKeyRotation(aliceID, *rotation); err != nil {
+		t.Fatalf("applyKeyRotation failed: %v", err)
+	}
+	if known, ok := bob.gossip.PeerIdentityKey(aliceID); !ok || !known.Equal(alice.gossip.PublicKey()) {
+		t.Fatal("expected the directory to hold the rotated key")
+	}
+
+	// Later rotations chain from the key the directory holds
+	rotation, _ = alice.RotateIdentityKey()
+	if err := bob.applyKeyRotation(aliceID, *rotation); err != nil {
+		t.Fatalf("second rotation failed: %v", err)
+	}
+
+	// A key that never spoke for the peer cannot be rotated into its identity
+	mallory := NewMeshCoordinator("mallory", "us-east", &MockTransport{nodeID: "mallory"}, nil)
+	forged, _ := mallory.RotateIdentityKey()
+	if err := bob.applyKeyRotation("mallory", *forged); err == nil {
+		t.Fatal("expected rotation from a key not bound to the peer ID to be rejected")
+	}
+	if _, ok := bob.gossip.PeerIdentityKey("mallory"); ok {
+		t.Fatal("rejected rotation must not file a key")
+	}
+}
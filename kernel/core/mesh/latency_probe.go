@@ -0,0 +1,349 @@
+package mesh
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Latency probing measures round trips to connected peers before anything
+// needs them: each round pings the few peers probed longest ago and folds
+// the result into an EWMA. Replies carry the responder's own freshest
+// estimates, so the matrix also holds rows measured by other nodes.
+const latencyProbeMethod = "mesh.LatencyProbe"
+
+// latencyProbeMaxRow bounds the estimates a probe reply shares.
+const latencyProbeMaxRow = 16
+
+// maxSharedLatencyMs drops shared estimates no real link produces.
+const maxSharedLatencyMs = 60_000
+
+var errLatencyProbeLimited = errors.New("latency probe rate limited")
+
+type latencyProbeRequest struct {
+	Sent int64 `json:"sent"` // Requester clock, Unix nanoseconds; echoed back
+}
+
+type latencyProbeResponse struct {
+	Sent int64              `json:"sent"`
+	Row  map[string]float32 `json:"row,omitempty"` // Responder's round trips in milliseconds, by peer
+}
+
+// latencyEstimate is an EWMA of the round trip from one node to another.
+type latencyEstimate struct {
+	rtt     time.Duration
+	samples uint32
+	updated time.Time
+}
+
+// latencyProbeState holds the latency matrix and the probe schedule.
+type latencyProbeState struct {
+	mu      sync.RWMutex
+	matrix  map[string]map[string]*latencyEstimate // From node, to node
+	probed  map[string]time.Time                   // Last outgoing probe per peer
+	inbound map[string]time.Time                   // Last probe answered per peer
+
+	sent    atomic.Uint64
+	failed  atomic.Uint64
+	limited atomic.Uint64 // Inbound probes refused for coming too often
+}
+
+// LatencyProbeStats reports the probe scheduler's activity.
+type LatencyProbeStats struct {
+	Sent     uint64 `json:"sent"`
+	Failed   uint64 `json:"failed"`
+	Limited  uint64 `json:"limited"`
+	Measured int    `json:"measured"` // Peers with a fresh estimate from this node
+	Rows     int    `json:"rows"`     // Nodes the matrix holds estimates from
+}
+
+// observe folds a round trip from one node to another into the matrix.
+func (s *latencyProbeState) observe(from, to string, rtt time.Duration, alpha float64, now time.Time) {
+	if alpha <= 0 || alpha > 1 {
+		alpha = 1
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.matrix == nil {
+		s.matrix = make(map[string]map[string]*latencyEstimate)
+	}
+	row, ok := s.matrix[from]
+	if !ok {
+		row = make(map[string]*latencyEstimate)
+		s.matrix[from] = row
+	}
+	estimate, ok := row[to]
+	if !ok || estimate.samples == 0 {
+		row[to] = &latencyEstimate{rtt: rtt, samples: 1, updated: now}
+		return
+	}
+	estimate.rtt = time.Duration(alpha*float64(rtt) + (1-alpha)*float64(estimate.rtt))
+	estimate.samples++
+	estimate.updated = now
+}
+
+// replaceRow stores the estimates another node shared, which are already
+// averaged, in place of its previous row.
+func (s *latencyProbeState) replaceRow(from string, shared map[string]float32, now time.Time) {
+	row := make(map[string]*latencyEstimate, len(shared))
+	for to, ms := range shared {
+		if to == "" || to == from || ms <= 0 || ms > maxSharedLatencyMs || len(row) == latencyProbeMaxRow {
+			continue
+		}
+		row[to] = &latencyEstimate{rtt: time.Duration(ms * float32(time.Millisecond)), samples: 1, updated: now}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.matrix == nil {
+		s.matrix = make(map[string]map[string]*latencyEstimate)
+	}
+	if len(row) == 0 {
+		delete(s.matrix, from)
+		return
+	}
+	s.matrix[from] = row
+}
+
+// estimate returns the round trip from one node to another while it is
+// younger than ttl.
+func (s *latencyProbeState) estimate(from, to string, ttl time.Duration, now time.Time) (time.Duration, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	estimate, ok := s.matrix[from][to]
+	if !ok || (ttl > 0 && now.Sub(estimate.updated) > ttl) {
+		return 0, false
+	}
+	return estimate.rtt, true
+}
+
+// row returns up to limit of from's freshest estimates in milliseconds.
+func (s *latencyProbeState) row(from string, ttl time.Duration, limit int, now time.Time) map[string]float32 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	type entry struct {
+		to       string
+		estimate *latencyEstimate
+	}
+	entries := make([]entry, 0, len(s.matrix[from]))
+	for to, estimate := range s.matrix[from] {
+		if ttl <= 0 || now.Sub(estimate.updated) <= ttl {
+			entries = append(entries, entry{to, estimate})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].estimate.updated.After(entries[j].estimate.updated) })
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+
+	out := make(map[string]float32, len(entries))
+	for _, e := range entries {
+		out[e.to] = float32(e.estimate.rtt) / float32(time.Millisecond)
+	}
+	return out
+}
+
+// prune drops estimates older than ttl and schedule entries older than
+// interval, which no longer hold anything back.
+func (s *latencyProbeState) prune(ttl, interval time.Duration, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for from, row := range s.matrix {
+		for to, estimate := range row {
+			if ttl > 0 && now.Sub(estimate.updated) > ttl {
+				delete(row, to)
+			}
+		}
+		if len(row) == 0 {
+			delete(s.matrix, from)
+		}
+	}
+	for peerID, at := range s.inbound {
+		if now.Sub(at) > interval {
+			delete(s.inbound, peerID)
+		}
+	}
+	for peerID, at := range s.probed {
+		if now.Sub(at) > interval {
+			delete(s.probed, peerID)
+		}
+	}
+}
+
+// admitInbound reports whether a probe from peerID may be answered: a peer
+// probes at most once per PeerInterval, so faster probes are refused.
+func (s *latencyProbeState) admitInbound(peerID string, window time.Duration, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if last, ok := s.inbound[peerID]; ok && now.Sub(last) < window {
+		return false
+	}
+	if s.inbound == nil {
+		s.inbound = make(map[string]time.Time)
+	}
+	s.inbound[peerID] = now
+	return true
+}
+
+// probeCandidates picks up to n of peers not probed within interval, the
+// ones probed longest ago (or never) first, and marks them probed.
+func (s *latencyProbeState) probeCandidates(peers []string, n int, interval time.Duration, now time.Time) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.probed == nil {
+		s.probed = make(map[string]time.Time)
+	}
+
+	candidates := make([]string, 0, len(peers))
+	for _, peerID := range peers {
+		if last, ok := s.probed[peerID]; !ok || now.Sub(last) >= interval {
+			candidates = append(candidates, peerID)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := s.probed[candidates[i]], s.probed[candidates[j]]
+		if !a.Equal(b) {
+			return a.Before(b)
+		}
+		return candidates[i] < candidates[j]
+	})
+	if n > 0 && len(candidates) > n {
+		candidates = candidates[:n]
+	}
+	for _, peerID := range candidates {
+		s.probed[peerID] = now
+	}
+	return candidates
+}
+
+func (m *MeshCoordinator) registerLatencyProbeHandler() {
+	m.registerRPC(latencyProbeMethod, func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error) {
+		var req latencyProbeRequest
+		if err := json.Unmarshal(args, &req); err != nil {
+			return nil, fmt.Errorf("failed to decode latency probe: %w", err)
+		}
+		now := time.Now()
+		if !m.latencyProbes.admitInbound(peerID, m.config.LatencyProbe.PeerInterval/2, now) {
+			m.latencyProbes.limited.Add(1)
+			return nil, errLatencyProbeLimited
+		}
+		return latencyProbeResponse{
+			Sent: req.Sent,
+			Row:  m.latencyProbes.row(m.nodeID, m.config.LatencyProbe.TTL, latencyProbeMaxRow, now),
+		}, nil
+	})
+}
+
+func (m *MeshCoordinator) latencyProbeLoop() {
+	if m.config.LatencyProbe.Interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(m.config.LatencyProbe.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if !m.Online() || m.deferLowPriority() {
+				continue
+			}
+			m.probeLatencies(context.Background())
+		case <-m.shutdown:
+			return
+		}
+	}
+}
+
+// probeLatencies runs one probe round. Probes go out one at a time so a
+// round never adds more than one request in flight.
+func (m *MeshCoordinator) probeLatencies(ctx context.Context) {
+	config := m.config.LatencyProbe
+	now := time.Now()
+	m.latencyProbes.prune(config.TTL, config.PeerInterval, now)
+
+	peers := m.transport.GetConnectedPeers()
+	eligible := make([]string, 0, len(peers))
+	for _, peerID := range peers {
+		if peerID != m.nodeID && !m.isPeerQuarantined(peerID) {
+			eligible = append(eligible, peerID)
+		}
+	}
+
+	for _, peerID := range m.latencyProbes.probeCandidates(eligible, config.SampleSize, config.PeerInterval, now) {
+		probeCtx, cancel := context.WithTimeout(ctx, config.Timeout)
+		if _, err := m.ProbePeerLatency(probeCtx, peerID); err != nil {
+			m.logger.Debug("latency probe failed", "peer", getShortID(peerID), "error", err)
+		}
+		cancel()
+	}
+}
+
+// ProbePeerLatency measures one round trip to peerID and folds it into the
+// latency matrix.
+func (m *MeshCoordinator) ProbePeerLatency(ctx context.Context, peerID string) (time.Duration, error) {
+	m.latencyProbes.sent.Add(1)
+	sent := time.Now()
+	var resp latencyProbeResponse
+	if err := m.transport.SendRPC(ctx, peerID, latencyProbeMethod, latencyProbeRequest{Sent: sent.UnixNano()}, &resp); err != nil {
+		// Not held against the peer: a probe is the first thing a busy
+		// or throttling peer drops
+		m.latencyProbes.failed.Add(1)
+		return 0, err
+	}
+	now := time.Now()
+	if resp.Sent != sent.UnixNano() {
+		m.latencyProbes.failed.Add(1)
+		return 0, errors.New("latency probe reply does not match request")
+	}
+
+	rtt := now.Sub(sent)
+	m.latencyProbes.observe(m.nodeID, peerID, rtt, m.config.LatencyProbe.Alpha, now)
+	m.latencyProbes.replaceRow(peerID, resp.Row, now)
+	return rtt, nil
+}
+
+// probedLatency returns the EWMA round trip to peerID while it is fresh.
+func (m *MeshCoordinator) probedLatency(peerID string) (time.Duration, bool) {
+	return m.latencyProbes.estimate(m.nodeID, peerID, m.config.LatencyProbe.TTL, time.Now())
+}
+
+// GetLatencyMatrix returns the fresh round trips the node knows of in
+// milliseconds, keyed by measuring node, then by peer. This node's row is
+// its own probes; the others are what probed peers reported.
+func (m *MeshCoordinator) GetLatencyMatrix() map[string]map[string]float32 {
+	now := time.Now()
+	ttl := m.config.LatencyProbe.TTL
+
+	m.latencyProbes.mu.RLock()
+	from := make([]string, 0, len(m.latencyProbes.matrix))
+	for node := range m.latencyProbes.matrix {
+		from = append(from, node)
+	}
+	m.latencyProbes.mu.RUnlock()
+
+	matrix := make(map[string]map[string]float32, len(from))
+	for _, node := range from {
+		if row := m.latencyProbes.row(node, ttl, 0, now); len(row) > 0 {
+			matrix[node] = row
+		}
+	}
+	return matrix
+}
+
+// GetLatencyProbeStats reports probe activity.
+func (m *MeshCoordinator) GetLatencyProbeStats() LatencyProbeStats {
+	matrix := m.GetLatencyMatrix()
+	return LatencyProbeStats{
+		Sent:     m.latencyProbes.sent.Load(),
+		Failed:   m.latencyProbes.failed.Load(),
+		Limited:  m.latencyProbes.limited.Load(),
+		Measured: len(matrix[m.nodeID]),
+		Rows:     len(matrix),
+	}
+}
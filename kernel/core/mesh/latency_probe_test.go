@@ -0,0 +1,67 @@
+package mesh
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLatencyProbeState_EWMAAndRotation(t *testing.T) {
+	var s latencyProbeState
+	now := time.Now()
+	s.observe("self", "a", 100*time.Millisecond, 0.5, now)
+	s.observe("self", "a", 200*time.Millisecond, 0.5, now)
+	if rtt, ok := s.estimate("self", "a", time.Minute, now); !ok || rtt != 150*time.Millisecond {
+		t.Fatalf("expected a 150ms average, got %v %v", rtt, ok)
+	}
+	if _, ok := s.estimate("self", "a", time.Minute, now.Add(2*time.Minute)); ok {
+		t.Fatal("expected a stale estimate to be ignored")
+	}
+
+	peers := []string{"a", "b", "c"}
+	if got := s.probeCandidates(peers, 2, time.Minute, now); len(got) != 2 || got[0] != "a" || got[1] != "b" {
+		t.Fatalf("unexpected first round %v", got)
+	}
+	if got := s.probeCandidates(peers, 2, time.Minute, now.Add(time.Second)); len(got) != 1 || got[0] != "c" {
+		t.Fatalf("expected only the unprobed peer inside the interval, got %v", got)
+	}
+	if got := s.probeCandidates(peers, 2, time.Minute, now.Add(time.Minute)); len(got) != 2 || got[0] != "a" {
+		t.Fatalf("expected the rotation to come back around, got %v", got)
+	}
+
+	// Shared rows are bounded and sanitized
+	shared := map[string]float32{"x": 12, "self": -1, "peer": 5}
+	s.replaceRow("peer", shared, now)
+	if row := s.row("peer", time.Minute, 0, now); len(row) != 1 || row["x"] != 12 {
+		t.Fatalf("unexpected shared row %v", row)
+	}
+}
+
+func TestMeshCoordinator_LatencyProbeFeedsScoring(t *testing.T) {
+	tr := &measuringTransport{
+		MockTransport: &MockTransport{nodeID: "self"},
+		latency:       map[string]time.Duration{"peer": 400 * time.Millisecond},
+	}
+	coord := NewMeshCoordinator("self", "us-east", tr, nil)
+
+	// The mock answers with this node's own handler, as if "peer" did
+	rtt, err := coord.ProbePeerLatency(context.Background(), "peer")
+	if err != nil {
+		t.Fatalf("latency probe failed: %v", err)
+	}
+	if got, ok := coord.peerLatency("peer"); !ok || got != rtt {
+		t.Fatalf("expected the probed round trip to replace the RPC timing, got %v %v", got, ok)
+	}
+	if _, ok := coord.GetLatencyMatrix()["self"]["peer"]; !ok {
+		t.Fatal("expected the probe in this node's matrix row")
+	}
+
+	if _, err := coord.ProbePeerLatency(context.Background(), "peer"); err == nil {
+		t.Fatal("expected an immediate second probe to be refused")
+	}
+	if stats := coord.GetLatencyProbeStats(); stats.Sent != 2 || stats.Failed != 1 || stats.Limited != 1 || stats.Measured != 1 {
+		t.Fatalf("unexpected probe stats %+v", stats)
+	}
+
+	callConformant(t, tr.MockTransport, latencyProbeMethod, "sim-peer", `{"sent":1}`)
+}
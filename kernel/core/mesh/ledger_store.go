@@ -0,0 +1,145 @@
+package mesh
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/routing"
+)
+
+// LedgerSnapshot is the persisted form of an EconomicLedger.
+type LedgerSnapshot struct {
+	Balances   map[string]int64     `json:"balances"`
+	Escrows    []DelegationEscrow   `json:"escrows"`
+	WorkShares map[string]WorkShare `json:"work_shares"`
+	Totals     LedgerSnapshotTotals `json:"totals"`
+	TakenAt    time.Time            `json:"taken_at"`
+}
+
+// LedgerSnapshotTotals carries the ledger's lifetime counters.
+type LedgerSnapshotTotals struct {
+	Escrowed    uint64 `json:"escrowed"`
+	Settled     uint64 `json:"settled"`
+	Refunded    uint64 `json:"refunded"`
+	Settlements uint64 `json:"settlements"`
+	Slashed     uint64 `json:"slashed,omitempty"`
+	Compensated uint64 `json:"compensated,omitempty"`
+}
+
+// ErrNoLedgerSnapshot is returned by a LedgerStore with nothing saved yet.
+var ErrNoLedgerSnapshot = errors.New("no ledger snapshot")
+
+// LedgerStore persists the economic ledger across sessions.
+type LedgerStore interface {
+	SaveLedger(snapshot LedgerSnapshot) error
+	LoadLedger() (LedgerSnapshot, error)
+}
+
+// Snapshot copies the ledger's balances, escrows and work shares.
+func (el *EconomicLedger) Snapshot() LedgerSnapshot {
+	el.mu.RLock()
+	defer el.mu.RUnlock()
+
+	snapshot := LedgerSnapshot{
+		Balances:   make(map[string]int64, len(el.balances)),
+		Escrows:    make([]DelegationEscrow, 0, len(el.escrows)),
+		WorkShares: make(map[string]WorkShare, len(el.workShares)),
+		Totals: LedgerSnapshotTotals{
+			Escrowed:    el.totalEscrowed,
+			Settled:     el.totalSettled,
+			Refunded:    el.totalRefunded,
+			Settlements: el.settlementsCount,
+			Slashed:     el.totalSlashed,
+			Compensated: el.totalCompensated,
+		},
+		TakenAt: time.Now(),
+	}
+	for did, balance := range el.balances {
+		snapshot.Balances[did] = balance
+	}
+	for _, escrow := range el.escrows {
+		snapshot.Escrows = append(snapshot.Escrows, *escrow)
+	}
+	for did, share := range el.workShares {
+		snapshot.WorkShares[did] = *share
+	}
+	return snapshot
+}
+
+// Restore replaces the ledger's state with a snapshot. The vault is not
+// touched; it keeps its own grounded balances.
+func (el *EconomicLedger) Restore(snapshot LedgerSnapshot) {
+	el.mu.Lock()
+	defer el.mu.Unlock()
+
+	el.balances = make(map[string]int64, len(snapshot.Balances))
+	for did, balance := range snapshot.Balances {
+		el.balances[did] = balance
+	}
+	el.escrows = make(map[string]*DelegationEscrow, len(snapshot.Escrows))
+	for i := range snapshot.Escrows {
+		escrow := snapshot.Escrows[i]
+		el.escrows[escrow.ID] = &escrow
+	}
+	el.workShares = make(map[string]*WorkShare, len(snapshot.WorkShares))
+	for did, share := range snapshot.WorkShares {
+		share := share
+		el.workShares[did] = &share
+	}
+	el.totalEscrowed = snapshot.Totals.Escrowed
+	el.totalSettled = snapshot.Totals.Settled
+	el.totalRefunded = snapshot.Totals.Refunded
+	el.settlementsCount = snapshot.Totals.Settlements
+	el.totalSlashed = snapshot.Totals.Slashed
+	el.totalCompensated = snapshot.Totals.Compensated
+}
+
+// SetLedgerStore attaches persistent storage for the ledger and restores
+// any saved snapshot.
+func (m *MeshCoordinator) SetLedgerStore(store LedgerStore) error {
+	m.stateStoreMu.Lock()
+	m.ledgerStore = store
+	m.stateStoreMu.Unlock()
+	if store == nil || m.ledger == nil {
+		return nil
+	}
+
+	snapshot, err := store.LoadLedger()
+	if errors.Is(err, ErrNoLedgerSnapshot) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load ledger: %w", err)
+	}
+	m.ledger.Restore(snapshot)
+	m.logger.Info("restored ledger", "accounts", len(snapshot.Balances), "escrows", len(snapshot.Escrows))
+	return nil
+}
+
+// SetReputationStore attaches persistent storage for peer reputation and
+// restores any saved scores.
+func (m *MeshCoordinator) SetReputationStore(store routing.ReputationStore) {
+	m.reputation.SetStore(store)
+}
+
+// persistState saves the ledger and reputation scores to their stores.
+func (m *MeshCoordinator) persistState() error {
+	var errs []error
+	if err := m.reputation.Snapshot(); err != nil {
+		errs = append(errs, fmt.Errorf("reputation: %w", err))
+	}
+
+	m.stateStoreMu.Lock()
+	store := m.ledgerStore
+	m.stateStoreMu.Unlock()
+	if store != nil && m.ledger != nil {
+		if err := store.SaveLedger(m.ledger.Snapshot()); err != nil {
+			errs = append(errs, fmt.Errorf("ledger: %w", err))
+		}
+	}
+	if err := m.persistPins(); err != nil {
+		errs = append(errs, fmt.Errorf("pins: %w", err))
+	}
+	return errors.Join(errs...)
+}
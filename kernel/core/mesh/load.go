@@ -0,0 +1,62 @@
+package mesh
+
+import "time"
+
+// busyCapability is advertised while the node's load is high, so peers
+// place work on idle nodes first.
+const busyCapability = "busy"
+
+// Busy reports whether the node advertises itself busy.
+func (m *MeshCoordinator) Busy() bool {
+	return m.busy.Load()
+}
+
+// observeLoad reads the monitor's load and flips the busy flag when it
+// crosses Load.BusyThreshold or falls back to Load.IdleThreshold. A change
+// is re-advertised.
+func (m *MeshCoordinator) observeLoad() {
+	m.decider.mu.RLock()
+	provider := m.decider.loadProvider
+	m.decider.mu.RUnlock()
+	cfg := m.config.Load
+	if provider == nil || cfg.BusyThreshold <= 0 {
+		return
+	}
+
+	load := provider.GetSystemLoad()
+	busy := m.busy.Load()
+	switch {
+	case !busy && load >= cfg.BusyThreshold:
+		busy = true
+	case busy && load <= cfg.IdleThreshold:
+		busy = false
+	default:
+		return
+	}
+	m.busy.Store(busy)
+	m.advertiseDHTMode()
+
+	m.logger.Info("load state changed", "busy", busy, "load", load)
+	m.publishEvent(MeshEventLoadChanged, "", map[string]interface{}{
+		"busy": busy,
+		"load": load,
+	})
+}
+
+func (m *MeshCoordinator) loadLoop() {
+	if m.config.Load.SampleInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(m.config.Load.SampleInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			m.observeLoad()
+		case <-m.shutdown:
+			return
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package mesh
+
+import (
+	"testing"
+	"time"
+)
+
+type staticLoad float64
+
+func (l *staticLoad) GetSystemLoad() float64 { return float64(*l) }
+
+func TestLoad_BusyFlagFollowsLoadWithHysteresis(t *testing.T) {
+	coord := NewMeshCoordinator("self", "us-east", &MockTransport{nodeID: "self"}, nil)
+	load := staticLoad(0.5)
+	coord.SetMonitor(&load)
+
+	coord.observeLoad()
+	if coord.Busy() {
+		t.Fatal("expected a half-loaded node not to be busy")
+	}
+
+	load = 0.9
+	coord.observeLoad()
+	if !coord.Busy() {
+		t.Fatal("expected the node to be busy past the threshold")
+	}
+
+	// Between the thresholds the flag holds
+	load = 0.7
+	coord.observeLoad()
+	if !coord.Busy() {
+		t.Fatal("expected the busy flag to hold above the idle threshold")
+	}
+
+	load = 0.5
+	coord.observeLoad()
+	if coord.Busy() {
+		t.Fatal("expected the busy flag to clear at the idle threshold")
+	}
+}
+
+func TestLoad_BusyPeersScoreLower(t *testing.T) {
+	coord := NewMeshCoordinator("self", "us-east", &MockTransport{nodeID: "self"}, nil)
+	idle := coord.calculatePeerScore(&PeerCapability{PeerID: "a", LatencyMs: 20, LastSeen: time.Now().UnixNano()})
+	busy := coord.calculatePeerScore(&PeerCapability{PeerID: "a", LatencyMs: 20, LastSeen: time.Now().UnixNano(), Capabilities: []string{busyCapability}})
+	if busy >= idle {
+		t.Fatalf("expected a busy peer to score lower: %v >= %v", busy, idle)
+	}
+}
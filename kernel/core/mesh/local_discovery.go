@@ -0,0 +1,65 @@
+package mesh
+
+import (
+	"context"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/transport"
+)
+
+type localDiscoverer interface {
+	StartLocalDiscovery(ctx context.Context, onPeer func(transport.LocalPeer)) error
+}
+
+type localPeerTracker interface {
+	IsLocalPeer(peerID string) bool
+	LocalPeers() []transport.LocalPeer
+}
+
+// startLocalDiscovery enables mDNS on transports that support it. Browser
+// transports return an error here and rely on ICE locality instead.
+func (m *MeshCoordinator) startLocalDiscovery(ctx context.Context) {
+	discoverer, ok := m.transport.(localDiscoverer)
+	if !ok {
+		return
+	}
+	if err := discoverer.StartLocalDiscovery(ctx, m.handleLocalPeer); err != nil {
+		m.logger.Debug("local network discovery unavailable", "error", err)
+	}
+}
+
+// handleLocalPeer connects to a node found on the LAN. Its signaling URL is
+// registered so the offer can reach it without internet signaling.
+func (m *MeshCoordinator) handleLocalPeer(peer transport.LocalPeer) {
+	if peer.PeerID == "" || peer.PeerID == m.nodeID {
+		return
+	}
+	_ = m.dht.AddPeer(PeerInfo{ID: peer.PeerID})
+
+	if peer.Signaling != "" {
+		if err := m.addSignalingServer(peer.Signaling); err != nil {
+			m.logger.Debug("failed to add local signaling server", "server", peer.Signaling, "error", err)
+		}
+	}
+	if m.transport.IsConnected(peer.PeerID) {
+		return
+	}
+
+	m.logger.Info("discovered local peer", "peer", getShortID(peer.PeerID), "addresses", peer.Addresses)
+	if err := m.ConnectToPeer(context.Background(), peer.PeerID); err != nil {
+		m.logger.Debug("failed to connect to local peer", "peer", getShortID(peer.PeerID), "error", err)
+	}
+}
+
+func (m *MeshCoordinator) isLocalPeer(peerID string) bool {
+	tracker, ok := m.transport.(localPeerTracker)
+	return ok && tracker.IsLocalPeer(peerID)
+}
+
+// GetLocalSector returns peers reachable on the local network.
+func (m *MeshCoordinator) GetLocalSector() []transport.LocalPeer {
+	tracker, ok := m.transport.(localPeerTracker)
+	if !ok {
+		return nil
+	}
+	return tracker.LocalPeers()
+}
@@ -0,0 +1,357 @@
+package mesh
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+)
+
+const (
+	manifestAnnounceTopic = "manifest_announce"
+
+	// ObjectManifestVersion is the manifest format written by PutObject.
+	ObjectManifestVersion = 1
+)
+
+// ErrManifestMismatch is returned when a manifest or object chunk does not
+// match the hash it was fetched by.
+var ErrManifestMismatch = errors.New("content does not match its hash")
+
+// ObjectManifest describes a multi-chunk object. It is stored as a chunk of
+// its own; the object is addressed by the manifest's hash.
+type ObjectManifest struct {
+	Version     int                 `json:"version"`
+	Size        uint64              `json:"size"`
+	ContentType string              `json:"content_type,omitempty"`
+	ChunkSize   int                 `json:"chunk_size"`
+	Chunks      []ManifestChunk     `json:"chunks"` // In object order
+	Encryption  *ManifestEncryption `json:"encryption,omitempty"`
+	ACL         *ObjectACL          `json:"acl,omitempty"` // Who holders serve the chunks and manifest to
+	Created     time.Time           `json:"created"`
+}
+
+// ManifestChunk is one chunk of an object.
+type ManifestChunk struct {
+	Hash string `json:"hash"`
+	Size int    `json:"size"`
+}
+
+// ManifestEncryption records how the object bytes were encrypted. With
+// ChunkEncryptionAlgorithm the mesh sealed each chunk with the namespace key
+// KeyID names, and GetObject opens them for nodes holding it. Otherwise the
+// caller encrypted the object before PutObject, decrypts it after GetObject,
+// and this only tells readers how.
+type ManifestEncryption struct {
+	Algorithm string `json:"algorithm"`
+	KeyID     string `json:"key_id,omitempty"`
+	Nonce     []byte `json:"nonce,omitempty"`
+	Namespace string `json:"namespace,omitempty"` // Sealed chunks: the key's namespace
+	Owner     string `json:"owner,omitempty"`     // Sealed chunks: the DID that derived the key
+}
+
+// sealed reports whether the mesh sealed the object's chunks.
+func (e *ManifestEncryption) sealed() bool {
+	return e != nil && e.Algorithm == ChunkEncryptionAlgorithm
+}
+
+// ObjectOptions controls how PutObject chunks and describes an object.
+type ObjectOptions struct {
+	ContentType string
+	ChunkSize   int // 0 uses the configured default
+	Encryption  *ManifestEncryption
+
+	// EncryptNamespace seals every chunk with this node's key for the
+	// namespace before it leaves the node. Encryption must then be nil.
+	EncryptNamespace string
+
+	// ACL restricts fetching the chunks and manifest; nil leaves them open.
+	ACL *ObjectACL
+}
+
+// ObjectAnnouncement is a manifest a peer announced over gossip.
+type ObjectAnnouncement struct {
+	ManifestHash string    `json:"manifest_hash"`
+	Size         uint64    `json:"size"`
+	ContentType  string    `json:"content_type,omitempty"`
+	Chunks       int       `json:"chunks"`
+	Publisher    string    `json:"publisher"`
+	SeenAt       time.Time `json:"seen_at"`
+}
+
+// PutObject splits r into chunks, distributes each one and then the manifest,
+// and announces the manifest. It returns the manifest hash that GetObject
+// takes. Chunks of a sealed object are addressed by their ciphertext; the
+// manifest itself stays readable.
+func (m *MeshCoordinator) PutObject(ctx context.Context, r io.Reader, opts ObjectOptions) (string, *ObjectManifest, error) {
+	chunkSize := opts.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = m.config.Objects.ChunkSize
+	}
+	var key *NamespaceKey
+	if opts.EncryptNamespace != "" {
+		if opts.Encryption != nil {
+			return "", nil, errors.New("EncryptNamespace and Encryption are exclusive")
+		}
+		var err error
+		if key, err = m.NamespaceKey(opts.EncryptNamespace); err != nil {
+			return "", nil, err
+		}
+		opts.Encryption = &ManifestEncryption{
+			Algorithm: ChunkEncryptionAlgorithm,
+			KeyID:     key.KeyID,
+			Namespace: key.Namespace,
+			Owner:     key.Owner,
+		}
+	}
+	var acl *ObjectACL
+	if opts.ACL != nil {
+		var err error
+		if acl, err = m.newObjectACL(opts.ACL); err != nil {
+			return "", nil, err
+		}
+	}
+	manifest := &ObjectManifest{
+		Version:     ObjectManifestVersion,
+		ContentType: opts.ContentType,
+		ChunkSize:   chunkSize,
+		Chunks:      []ManifestChunk{},
+		Encryption:  opts.Encryption,
+		ACL:         acl,
+		Created:     time.Now().UTC(),
+	}
+
+	distributed := make(map[string]bool)
+	buf := make([]byte, chunkSize)
+	for {
+		n, err := io.ReadFull(r, buf)
+		if n > 0 {
+			data := append([]byte(nil), buf[:n]...)
+			if key != nil {
+				sealed, sealErr := sealChunk(key, data)
+				if sealErr != nil {
+					return "", nil, fmt.Errorf("chunk %d: %w", len(manifest.Chunks), sealErr)
+				}
+				data = sealed
+			}
+			hash := m.computeResourceDigest(data)
+			if !distributed[hash] {
+				if acl != nil {
+					if err := m.restrictChunk(hash, acl); err != nil {
+						return "", nil, err
+					}
+				}
+				if _, err := m.DistributeChunk(ctx, hash, data); err != nil {
+					return "", nil, fmt.Errorf("chunk %d: %w", len(manifest.Chunks), err)
+				}
+				distributed[hash] = true
+			}
+			manifest.Chunks = append(manifest.Chunks, ManifestChunk{Hash: hash, Size: len(data)})
+			manifest.Size += uint64(n)
+			if limit := m.config.Objects.MaxChunks; limit > 0 && len(manifest.Chunks) > limit {
+				return "", nil, fmt.Errorf("object exceeds %d chunks of %d bytes", limit, chunkSize)
+			}
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to read object: %w", err)
+		}
+		if ctx.Err() != nil {
+			return "", nil, ctx.Err()
+		}
+	}
+
+	encoded, err := json.Marshal(manifest)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	manifestHash := m.computeResourceDigest(encoded)
+	if acl != nil {
+		if err := m.restrictChunk(manifestHash, acl); err != nil {
+			return "", nil, err
+		}
+	}
+	if _, err := m.DistributeChunk(ctx, manifestHash, encoded); err != nil {
+		return "", nil, fmt.Errorf("manifest: %w", err)
+	}
+
+	m.announceManifest(manifestHash, manifest)
+	m.logger.Info("stored object",
+		"manifest", getShortID(manifestHash),
+		"size", manifest.Size,
+		"chunks", len(manifest.Chunks),
+	)
+	return manifestHash, manifest, nil
+}
+
+// GetManifest fetches and verifies an object manifest.
+func (m *MeshCoordinator) GetManifest(ctx context.Context, manifestHash string) (*ObjectManifest, error) {
+	data, err := m.fetchStoredChunk(ctx, manifestHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch manifest: %w", err)
+	}
+	if m.computeResourceDigest(data) != manifestHash {
+		return nil, fmt.Errorf("manifest %s: %w", getShortID(manifestHash), ErrManifestMismatch)
+	}
+	return decodeObjectManifest(data)
+}
+
+// GetObject writes the object named by manifestHash to w, verifying every
+// chunk against its hash and opening sealed chunks. It returns the number of
+// bytes written.
+func (m *MeshCoordinator) GetObject(ctx context.Context, manifestHash string, w io.Writer) (int64, error) {
+	manifest, err := m.GetManifest(ctx, manifestHash)
+	if err != nil {
+		return 0, err
+	}
+
+	var written int64
+	for i, chunk := range manifest.Chunks {
+		data, err := m.fetchStoredChunk(ctx, chunk.Hash)
+		if err != nil {
+			return written, fmt.Errorf("chunk %d: %w", i, err)
+		}
+		if len(data) != chunk.Size || m.computeResourceDigest(data) != chunk.Hash {
+			return written, fmt.Errorf("chunk %d (%s): %w", i, getShortID(chunk.Hash), ErrManifestMismatch)
+		}
+		if manifest.Encryption.sealed() {
+			if !isSealedChunk(data) || sealedChunkKeyID(data) != manifest.Encryption.KeyID {
+				return written, fmt.Errorf("chunk %d (%s) is not sealed with the manifest key: %w", i, getShortID(chunk.Hash), ErrManifestMismatch)
+			}
+			if data, err = m.openChunk(data); err != nil {
+				return written, fmt.Errorf("chunk %d: %w", i, err)
+			}
+		}
+		n, err := w.Write(data)
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+	if uint64(written) != manifest.Size {
+		return written, fmt.Errorf("object is %d bytes, manifest says %d: %w", written, manifest.Size, ErrManifestMismatch)
+	}
+	return written, nil
+}
+
+// GetKnownObjects returns manifests announced by peers, newest first.
+func (m *MeshCoordinator) GetKnownObjects() []ObjectAnnouncement {
+	m.knownObjectsMu.Lock()
+	objects := make([]ObjectAnnouncement, 0, len(m.knownObjects))
+	for _, obj := range m.knownObjects {
+		objects = append(objects, *obj)
+	}
+	m.knownObjectsMu.Unlock()
+
+	sort.Slice(objects, func(i, j int) bool { return objects[i].SeenAt.After(objects[j].SeenAt) })
+	return objects
+}
+
+func decodeObjectManifest(data []byte) (*ObjectManifest, error) {
+	var manifest ObjectManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	if manifest.Version != ObjectManifestVersion {
+		return nil, fmt.Errorf("unsupported manifest version %d", manifest.Version)
+	}
+	var total uint64
+	for _, chunk := range manifest.Chunks {
+		if chunk.Hash == "" || chunk.Size <= 0 {
+			return nil, errors.New("invalid manifest: empty chunk entry")
+		}
+		total += uint64(chunk.Size)
+	}
+	if manifest.Encryption.sealed() {
+		overhead := uint64(len(manifest.Chunks)) * sealedChunkOverhead
+		if total < overhead {
+			return nil, errors.New("invalid manifest: sealed chunks smaller than their overhead")
+		}
+		total -= overhead
+	}
+	if total != manifest.Size {
+		return nil, fmt.Errorf("invalid manifest: chunks add up to %d bytes, size is %d", total, manifest.Size)
+	}
+	return &manifest, nil
+}
+
+func (m *MeshCoordinator) announceManifest(manifestHash string, manifest *ObjectManifest) {
+	m.recordObjectAnnouncement(ObjectAnnouncement{
+		ManifestHash: manifestHash,
+		Size:         manifest.Size,
+		ContentType:  manifest.ContentType,
+		Chunks:       len(manifest.Chunks),
+		Publisher:    m.nodeID,
+		SeenAt:       time.Now(),
+	})
+	payload := map[string]interface{}{
+		"manifest_hash": manifestHash,
+		"size":          manifest.Size,
+		"content_type":  manifest.ContentType,
+		"chunks":        len(manifest.Chunks),
+	}
+	if err := m.PublishOrQueue(manifestAnnounceTopic, "manifest:"+manifestHash, payload); err != nil {
+		m.logger.Debug("failed to announce manifest", "manifest", getShortID(manifestHash), "error", err)
+	}
+}
+
+// recordObjectAnnouncement remembers a manifest, dropping the oldest once
+// the configured limit is reached.
+func (m *MeshCoordinator) recordObjectAnnouncement(obj ObjectAnnouncement) {
+	m.knownObjectsMu.Lock()
+	defer m.knownObjectsMu.Unlock()
+
+	if _, ok := m.knownObjects[obj.ManifestHash]; !ok && m.config.Objects.MaxKnown > 0 && len(m.knownObjects) >= m.config.Objects.MaxKnown {
+		var oldest string
+		for hash, known := range m.knownObjects {
+			if oldest == "" || known.SeenAt.Before(m.knownObjects[oldest].SeenAt) {
+				oldest = hash
+			}
+		}
+		delete(m.knownObjects, oldest)
+	}
+	m.knownObjects[obj.ManifestHash] = &obj
+}
+
+func (m *MeshCoordinator) registerManifestGossip() {
+	m.gossip.RegisterHandler(manifestAnnounceTopic, func(msg *common.GossipMessage) error {
+		payload, ok := msg.Payload.(map[string]interface{})
+		if !ok {
+			return errors.New("invalid payload type for manifest_announce")
+		}
+
+		manifestHash, _ := payload["manifest_hash"].(string)
+		if manifestHash == "" {
+			return errors.New("manifest announcement without hash")
+		}
+		obj := ObjectAnnouncement{
+			ManifestHash: manifestHash,
+			Publisher:    msg.Sender,
+			SeenAt:       time.Now(),
+		}
+		if size, ok := payload["size"].(float64); ok && size > 0 {
+			obj.Size = uint64(size)
+		}
+		if chunks, ok := payload["chunks"].(float64); ok && chunks > 0 {
+			obj.Chunks = int(chunks)
+		}
+		obj.ContentType, _ = payload["content_type"].(string)
+
+		m.recordObjectAnnouncement(obj)
+		_ = m.storeChunkRecord(manifestHash, msg.Sender)
+		m.publishEvent(MeshEventObjectAnnounced, msg.Sender, map[string]interface{}{
+			"manifest_hash": manifestHash,
+			"size":          obj.Size,
+			"content_type":  obj.ContentType,
+			"chunks":        obj.Chunks,
+		})
+		return nil
+	})
+}
@@ -0,0 +1,95 @@
+package mesh
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+)
+
+func TestObject_PutAndGetRoundTrip(t *testing.T) {
+	coord := NewMeshCoordinator("self", "us-east", &MockTransport{nodeID: "self"}, nil)
+	storage := &MockStorage{chunks: map[string][]byte{}}
+	coord.SetStorage(storage)
+
+	// Three full chunks with a repeated block and a short tail
+	object := append(append(bytes.Repeat([]byte("a"), 64), bytes.Repeat([]byte("b"), 64)...), bytes.Repeat([]byte("a"), 64)...)
+	object = append(object, []byte("tail")...)
+
+	hash, manifest, err := coord.PutObject(context.Background(), bytes.NewReader(object), ObjectOptions{
+		ContentType: "application/octet-stream",
+		ChunkSize:   64,
+		Encryption:  &ManifestEncryption{Algorithm: "aes-256-gcm", KeyID: "k1"},
+	})
+	if err != nil {
+		t.Fatalf("PutObject failed: %v", err)
+	}
+	if len(manifest.Chunks) != 4 || manifest.Size != uint64(len(object)) || manifest.Chunks[0].Hash != manifest.Chunks[2].Hash {
+		t.Fatalf("unexpected manifest %+v", manifest)
+	}
+	if len(storage.chunks) != 4 { // Three distinct data chunks plus the manifest
+		t.Fatalf("expected 4 stored chunks, got %d", len(storage.chunks))
+	}
+
+	var out bytes.Buffer
+	n, err := coord.GetObject(context.Background(), hash, &out)
+	if err != nil || n != int64(len(object)) || !bytes.Equal(out.Bytes(), object) {
+		t.Fatalf("round trip failed: n=%d err=%v", n, err)
+	}
+	got, err := coord.GetManifest(context.Background(), hash)
+	if err != nil || got.Encryption == nil || got.Encryption.KeyID != "k1" || got.ContentType != "application/octet-stream" {
+		t.Fatalf("unexpected manifest %+v %v", got, err)
+	}
+
+	storage.chunks[manifest.Chunks[1].Hash] = bytes.Repeat([]byte("x"), 64)
+	if _, err := coord.GetObject(context.Background(), hash, &bytes.Buffer{}); !errors.Is(err, ErrManifestMismatch) {
+		t.Fatalf("expected ErrManifestMismatch for a tampered chunk, got %v", err)
+	}
+}
+
+func TestObject_RejectsOversizedObject(t *testing.T) {
+	coord := NewMeshCoordinator("self", "us-east", &MockTransport{nodeID: "self"}, nil)
+	coord.SetStorage(&MockStorage{chunks: map[string][]byte{}})
+	coord.config.Objects.MaxChunks = 2
+
+	if _, _, err := coord.PutObject(context.Background(), bytes.NewReader(make([]byte, 300)), ObjectOptions{ChunkSize: 100}); err == nil {
+		t.Fatal("expected an object over the chunk limit to be rejected")
+	}
+}
+
+func TestObject_ManifestAnnouncementIsRecorded(t *testing.T) {
+	coord := NewMeshCoordinator("self", "us-east", &MockTransport{nodeID: "self"}, nil)
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+
+	msg := &common.GossipMessage{
+		ID:        "manifest-1",
+		Sender:    "publisher",
+		Type:      manifestAnnounceTopic,
+		Timestamp: time.Now().UnixNano(),
+		MaxHops:   10,
+		Payload: map[string]interface{}{
+			"manifest_hash": "manifest-hash",
+			"size":          float64(4096),
+			"content_type":  "video/mp4",
+			"chunks":        float64(4),
+		},
+		PublicKey: []byte(pub),
+	}
+	signGossipMessage(msg, priv)
+	if err := coord.gossip.ReceiveMessage("publisher", msg); err != nil {
+		t.Fatalf("ReceiveMessage failed: %v", err)
+	}
+
+	known := coord.GetKnownObjects()
+	if len(known) != 1 || known[0].Publisher != "publisher" || known[0].Size != 4096 || known[0].Chunks != 4 {
+		t.Fatalf("unexpected known objects %+v", known)
+	}
+	if providers := coord.dht.LocalProviders("manifest-hash"); len(providers) != 1 || providers[0] != "publisher" {
+		t.Fatalf("expected publisher recorded as manifest provider, got %v", providers)
+	}
+}
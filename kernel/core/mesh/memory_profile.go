@@ -0,0 +1,35 @@
+package mesh
+
+import "github.com/nmxmxh/inos_v1/kernel/runtime"
+
+// applyMemoryProfile shrinks or grows the coordinator's caches and the
+// gossip queues to the profile's bounds.
+func (m *MeshCoordinator) applyMemoryProfile(profile runtime.MemoryProfile) {
+	m.memoryProfileMu.Lock()
+	m.memoryProfile = profile
+	m.memoryProfileMu.Unlock()
+
+	if profile.ChunkCacheEntries > 0 {
+		m.chunkCache.Resize(profile.ChunkCacheEntries)
+	}
+	if m.gossip != nil {
+		m.gossip.SetMemoryLimits(profile.BloomFilterElements, profile.SeenCacheEntries, profile.GossipQueueSize)
+	}
+
+	m.logger.Info("applied memory profile",
+		"tier", profile.Tier,
+		"chunk_cache", profile.ChunkCacheEntries,
+		"bloom_elements", profile.BloomFilterElements,
+		"gossip_queue", profile.GossipQueueSize)
+}
+
+// MemoryProfile returns the active memory profile. Until a role config is
+// applied this is the high profile, matching the built-in defaults.
+func (m *MeshCoordinator) MemoryProfile() runtime.MemoryProfile {
+	m.memoryProfileMu.RLock()
+	defer m.memoryProfileMu.RUnlock()
+	if m.memoryProfile.Tier == "" {
+		return runtime.MemoryProfileFor(runtime.MemoryHigh)
+	}
+	return m.memoryProfile
+}
@@ -0,0 +1,60 @@
+package mesh
+
+import (
+	"fmt"
+	"testing"
+
+	system "github.com/nmxmxh/inos_v1/kernel/gen/system/v1"
+	"github.com/nmxmxh/inos_v1/kernel/runtime"
+)
+
+func TestSelectMemoryProfile(t *testing.T) {
+	cases := []struct {
+		deviceMemory float64
+		role         system.Runtime_RuntimeRole
+		want         runtime.MemoryTier
+	}{
+		{2, system.Runtime_RuntimeRole_synapse, runtime.MemoryLow},
+		{0.5, system.Runtime_RuntimeRole_neuron, runtime.MemoryLow},
+		{4, system.Runtime_RuntimeRole_neuron, runtime.MemoryMedium},
+		{8, system.Runtime_RuntimeRole_synapse, runtime.MemoryHigh},
+		{8, system.Runtime_RuntimeRole_sentry, runtime.MemoryMedium},
+		{0, system.Runtime_RuntimeRole_synapse, runtime.MemoryHigh},
+		{0, system.Runtime_RuntimeRole_sentry, runtime.MemoryMedium},
+	}
+	for _, tc := range cases {
+		got := runtime.SelectMemoryProfile(runtime.RuntimeCapabilities{DeviceMemoryGB: tc.deviceMemory}, tc.role)
+		if got.Tier != tc.want {
+			t.Fatalf("deviceMemory=%v role=%s: expected %s, got %s", tc.deviceMemory, tc.role, tc.want, got.Tier)
+		}
+	}
+
+	low, high := runtime.MemoryProfileFor(runtime.MemoryLow), runtime.MemoryProfileFor(runtime.MemoryHigh)
+	if low.ChunkCacheEntries >= high.ChunkCacheEntries || low.BloomFilterElements >= high.BloomFilterElements ||
+		low.GossipQueueSize >= high.GossipQueueSize || low.TransportQueueSize >= high.TransportQueueSize ||
+		low.MaxWorkers >= high.MaxWorkers {
+		t.Fatalf("expected every low-profile bound below the high profile: %+v vs %+v", low, high)
+	}
+}
+
+func TestApplyRoleConfig_BoundsCachesToMemoryProfile(t *testing.T) {
+	coord := NewMeshCoordinator("self", "us-east", &MockTransport{nodeID: "self"}, nil)
+	if tier := coord.GetTelemetry()["memory_profile"]; tier != string(runtime.MemoryHigh) {
+		t.Fatalf("expected high profile before a role is applied, got %v", tier)
+	}
+
+	for i := 0; i < 2000; i++ {
+		coord.chunkCache.Put(fmt.Sprintf("chunk-%d", i), []string{"peer-1"}, 1)
+	}
+
+	profile := runtime.MemoryProfileFor(runtime.MemoryLow)
+	coord.ApplyRoleConfig(runtime.RoleConfig{Role: system.Runtime_RuntimeRole_sentry, GossipFanout: 2, Memory: profile})
+
+	metrics := coord.chunkCache.GetMetrics()
+	if metrics.MaxSize != profile.ChunkCacheEntries || metrics.Size != profile.ChunkCacheEntries {
+		t.Fatalf("expected chunk cache bounded to %d, got size %d max %d", profile.ChunkCacheEntries, metrics.Size, metrics.MaxSize)
+	}
+	if tier := coord.GetTelemetry()["memory_profile"]; tier != string(runtime.MemoryLow) {
+		t.Fatalf("expected low profile in telemetry, got %v", tier)
+	}
+}
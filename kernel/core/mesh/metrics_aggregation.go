@@ -0,0 +1,249 @@
+package mesh
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+	system "github.com/nmxmxh/inos_v1/kernel/gen/system/v1"
+)
+
+// Capability classes used to bound what a peer can plausibly report.
+const (
+	MetricsClassLight    = "light"    // Synapse-role or unknown peers
+	MetricsClassStandard = "standard" // Neuron and sentry peers without a GPU
+	MetricsClassGPU      = "gpu"
+)
+
+// MetricsBounds caps the per-node values a reporter of one class can claim.
+type MetricsBounds struct {
+	MaxComputeGFLOPS float32 `json:"max_compute_gflops"`
+	MaxOpsPerSec     float32 `json:"max_ops_per_sec"`
+	MaxStorageBytes  uint64  `json:"max_storage_bytes"`
+}
+
+// MetricsOutlier is a reporter whose gossiped metrics were clamped or fell far
+// outside the mesh consensus. Outliers are kept for operator review; they are
+// not penalized automatically.
+type MetricsOutlier struct {
+	PeerID    string    `json:"peer_id"`
+	Class     string    `json:"class"`
+	Metric    string    `json:"metric"`
+	Reason    string    `json:"reason"`
+	Reported  float64   `json:"reported"`
+	Accepted  float64   `json:"accepted"`
+	Count     uint64    `json:"count"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+const metricsOutlierRetention = time.Hour
+
+// metricsSample is one reporter's contribution to the global aggregate.
+type metricsSample struct {
+	peerID  string
+	class   string
+	weight  float64
+	gflops  float64
+	ops     float64
+	storage float64
+}
+
+// metricsClass derives a peer's capability class from its cached capability.
+func (m *MeshCoordinator) metricsClass(peerID string) string {
+	capability := m.getCachedPeer(peerID)
+	if capability == nil {
+		return MetricsClassLight
+	}
+	if (capability.RuntimeCaps != nil && capability.RuntimeCaps.HasGpu) || slices.Contains(capability.Capabilities, "gpu") {
+		return MetricsClassGPU
+	}
+	if capability.Role == system.Runtime_RuntimeRole_synapse {
+		return MetricsClassLight
+	}
+	return MetricsClassStandard
+}
+
+// metricsWeight scales a reporter's influence by its reputation, with a floor
+// so new peers still count for something.
+func (m *MeshCoordinator) metricsWeight(peerID string) float64 {
+	score, _ := m.reputation.GetTrustScore(peerID)
+	return min(max(score, m.config.MetricsAggregation.MinWeight), 1)
+}
+
+// aggregateGlobalMetrics combines the local and peer samples robustly. Values
+// are clamped to the reporter's class bounds, then each total is a
+// reputation-weighted trimmed mean scaled back up by the node count, so a
+// single reporter moves the total by at most its bounded share. Reporters far
+// above the median are flagged but otherwise count as clamped.
+func (m *MeshCoordinator) aggregateGlobalMetrics(samples []metricsSample) (gflops, ops float64, storage uint64) {
+	now := time.Now()
+	cfg := m.config.MetricsAggregation
+
+	for i := range samples {
+		s := &samples[i]
+		bounds, ok := cfg.Bounds[s.class]
+		if !ok || s.peerID == m.nodeID {
+			continue
+		}
+		s.gflops = m.clampMetric(now, s, "compute_gflops", s.gflops, float64(bounds.MaxComputeGFLOPS))
+		s.ops = m.clampMetric(now, s, "ops_per_sec", s.ops, float64(bounds.MaxOpsPerSec))
+		s.storage = m.clampMetric(now, s, "storage_bytes", s.storage, float64(bounds.MaxStorageBytes))
+	}
+
+	n := float64(len(samples))
+	total := func(metric string, value func(metricsSample) float64) float64 {
+		m.flagConsensusOutliers(now, samples, metric, value)
+		return trimmedWeightedMean(samples, value, cfg.TrimFraction) * n
+	}
+	gflops = total("compute_gflops", func(s metricsSample) float64 { return s.gflops })
+	ops = total("ops_per_sec", func(s metricsSample) float64 { return s.ops })
+	storage = uint64(total("storage_bytes", func(s metricsSample) float64 { return s.storage }))
+	return gflops, ops, storage
+}
+
+// flagConsensusOutliers flags reporters more than OutlierMADs deviations above
+// the median. It needs three reporters and a non-zero median to mean anything.
+func (m *MeshCoordinator) flagConsensusOutliers(now time.Time, samples []metricsSample, metric string, value func(metricsSample) float64) {
+	if len(samples) < 3 {
+		return
+	}
+	values := make([]float64, len(samples))
+	for i, s := range samples {
+		values[i] = value(s)
+	}
+	center, spread := medianAndMAD(values)
+	if center == 0 {
+		return
+	}
+	limit := center + m.config.MetricsAggregation.OutlierMADs*spread
+	for _, s := range samples {
+		if s.peerID != m.nodeID && value(s) > limit {
+			m.flagMetricsOutlier(now, s, metric, "far above mesh consensus", value(s), limit)
+		}
+	}
+}
+
+func (m *MeshCoordinator) clampMetric(now time.Time, s *metricsSample, metric string, value, bound float64) float64 {
+	if bound <= 0 || value <= bound {
+		return max(value, 0)
+	}
+	m.flagMetricsOutlier(now, *s, metric, fmt.Sprintf("above %s class bound", s.class), value, bound)
+	return bound
+}
+
+func (m *MeshCoordinator) flagMetricsOutlier(now time.Time, s metricsSample, metric, reason string, reported, accepted float64) {
+	key := s.peerID + "/" + metric
+
+	m.metricsOutliersMu.Lock()
+	defer m.metricsOutliersMu.Unlock()
+
+	entry, ok := m.metricsOutliers[key]
+	if !ok {
+		entry = &MetricsOutlier{PeerID: s.peerID, Metric: metric, FirstSeen: now}
+		m.metricsOutliers[key] = entry
+		m.logger.Warn("peer reported implausible metrics",
+			"peer", getShortID(s.peerID),
+			"metric", metric,
+			"reported", reported,
+			"accepted", accepted,
+			"reason", reason,
+		)
+	}
+	entry.Class = s.class
+	entry.Reason = reason
+	entry.Reported = reported
+	entry.Accepted = accepted
+	entry.Count++
+	entry.LastSeen = now
+}
+
+// GetMetricsOutliers returns reporters flagged within the last hour, most
+// frequently flagged first.
+func (m *MeshCoordinator) GetMetricsOutliers() []MetricsOutlier {
+	cutoff := time.Now().Add(-metricsOutlierRetention)
+
+	m.metricsOutliersMu.Lock()
+	outliers := make([]MetricsOutlier, 0, len(m.metricsOutliers))
+	for key, entry := range m.metricsOutliers {
+		if entry.LastSeen.Before(cutoff) {
+			delete(m.metricsOutliers, key)
+			continue
+		}
+		outliers = append(outliers, *entry)
+	}
+	m.metricsOutliersMu.Unlock()
+
+	sort.Slice(outliers, func(i, j int) bool {
+		if outliers[i].Count != outliers[j].Count {
+			return outliers[i].Count > outliers[j].Count
+		}
+		return outliers[i].PeerID < outliers[j].PeerID
+	})
+	return outliers
+}
+
+// trimmedWeightedMean drops the top and bottom trim fraction of samples by
+// value and returns the weighted mean of the rest.
+func trimmedWeightedMean(samples []metricsSample, value func(metricsSample) float64, trim float64) float64 {
+	if len(samples) == 0 {
+		return 0
+	}
+	sorted := slices.Clone(samples)
+	sort.Slice(sorted, func(i, j int) bool { return value(sorted[i]) < value(sorted[j]) })
+
+	cut := int(math.Floor(float64(len(sorted)) * trim))
+	if 2*cut >= len(sorted) {
+		cut = (len(sorted) - 1) / 2
+	}
+	kept := sorted[cut : len(sorted)-cut]
+
+	var sum, weights float64
+	for _, s := range kept {
+		sum += s.weight * value(s)
+		weights += s.weight
+	}
+	if weights == 0 {
+		return 0
+	}
+	return sum / weights
+}
+
+// medianAndMAD returns the median and a deviation scale (the median absolute
+// deviation, floored at a tenth of the median so identical honest values
+// still leave room for normal variation).
+func medianAndMAD(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	median := medianOf(values)
+	deviations := make([]float64, len(values))
+	for i, v := range values {
+		deviations[i] = math.Abs(v - median)
+	}
+	return median, max(medianOf(deviations), median/10)
+}
+
+func medianOf(values []float64) float64 {
+	sorted := slices.Clone(values)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+func peerMetricsSample(peerID string, pm common.MeshMetrics, class string, weight float64) metricsSample {
+	return metricsSample{
+		peerID:  peerID,
+		class:   class,
+		weight:  weight,
+		gflops:  float64(pm.TotalComputeGFLOPS),
+		ops:     float64(pm.GlobalOpsPerSec),
+		storage: float64(pm.TotalStorageBytes),
+	}
+}
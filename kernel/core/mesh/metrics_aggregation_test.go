@@ -0,0 +1,81 @@
+package mesh
+
+import (
+	"testing"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+	system "github.com/nmxmxh/inos_v1/kernel/gen/system/v1"
+)
+
+func newAggregationTestCoordinator(t *testing.T, peers map[string]common.MeshMetrics) *MeshCoordinator {
+	t.Helper()
+	coord := NewMeshCoordinator("self", "us-east", &MockTransport{nodeID: "self"}, nil)
+	coord.metrics.TotalComputeGFLOPS = 50
+	coord.metrics.GlobalOpsPerSec = 1000
+	coord.metrics.TotalStorageBytes = 1 << 30
+	for peerID, pm := range peers {
+		coord.cachePeer(peerID, &PeerCapability{PeerID: peerID, Role: system.Runtime_RuntimeRole_neuron})
+		coord.peerMetrics[peerID] = pm
+	}
+	return coord
+}
+
+func TestGlobalMetrics_ClampsImplausibleReporter(t *testing.T) {
+	honest := common.MeshMetrics{TotalComputeGFLOPS: 50, GlobalOpsPerSec: 1000, TotalStorageBytes: 1 << 30}
+	coord := newAggregationTestCoordinator(t, map[string]common.MeshMetrics{
+		"honest-1": honest,
+		"honest-2": honest,
+		"liar":     {TotalComputeGFLOPS: 1e9, GlobalOpsPerSec: 1000, TotalStorageBytes: 1 << 30},
+	})
+
+	global := coord.GetGlobalMetrics()
+	if global.ActiveNodeCount != 4 {
+		t.Fatalf("expected 4 nodes, got %d", global.ActiveNodeCount)
+	}
+	bound := coord.config.MetricsAggregation.Bounds[MetricsClassStandard].MaxComputeGFLOPS
+	if global.TotalComputeGFLOPS > 3*50+bound {
+		t.Fatalf("liar inflated compute to %.0f GFLOPS", global.TotalComputeGFLOPS)
+	}
+	if global.GlobalOpsPerSec < 3999 || global.GlobalOpsPerSec > 4001 {
+		t.Fatalf("expected honest ops total of 4000, got %.0f", global.GlobalOpsPerSec)
+	}
+
+	outliers := coord.GetMetricsOutliers()
+	if len(outliers) == 0 || outliers[0].PeerID != "liar" || outliers[0].Metric != "compute_gflops" {
+		t.Fatalf("expected liar flagged for compute, got %+v", outliers)
+	}
+	for _, o := range outliers {
+		if o.PeerID != "liar" {
+			t.Fatalf("honest reporter flagged: %+v", o)
+		}
+	}
+}
+
+func TestGlobalMetrics_TrimsAndWeightsRates(t *testing.T) {
+	peers := map[string]common.MeshMetrics{}
+	for _, id := range []string{"p1", "p2", "p3", "p4", "p5", "p6", "p7", "p8", "p9"} {
+		peers[id] = common.MeshMetrics{TotalComputeGFLOPS: 50}
+	}
+	// Within the class bound, but far above everyone else
+	peers["p9"] = common.MeshMetrics{TotalComputeGFLOPS: 1900}
+	coord := newAggregationTestCoordinator(t, peers)
+
+	global := coord.GetGlobalMetrics()
+	if global.TotalComputeGFLOPS < 499 || global.TotalComputeGFLOPS > 501 {
+		t.Fatalf("expected trimmed total of 500 GFLOPS, got %.1f", global.TotalComputeGFLOPS)
+	}
+	if outliers := coord.GetMetricsOutliers(); len(outliers) != 1 || outliers[0].PeerID != "p9" {
+		t.Fatalf("expected p9 flagged against consensus, got %+v", outliers)
+	}
+}
+
+func TestTrimmedWeightedMean_FavorsReputableReporters(t *testing.T) {
+	samples := []metricsSample{
+		{peerID: "trusted", weight: 1, gflops: 100},
+		{peerID: "new", weight: 0.1, gflops: 1000},
+	}
+	mean := trimmedWeightedMean(samples, func(s metricsSample) float64 { return s.gflops }, 0.1)
+	if mean < 181 || mean > 182 {
+		t.Fatalf("expected weighted mean near 181.8, got %.1f", mean)
+	}
+}
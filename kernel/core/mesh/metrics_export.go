@@ -0,0 +1,93 @@
+package mesh
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/utils/metrics"
+)
+
+// ExecutionStats counts delegated jobs this node ran through its
+// dispatcher, i.e. WASM module executions on behalf of peers.
+type ExecutionStats struct {
+	Executed    uint64        `json:"executed"`
+	Failed      uint64        `json:"failed"`
+	InputBytes  uint64        `json:"input_bytes"`
+	OutputBytes uint64        `json:"output_bytes"`
+	Busy        time.Duration `json:"busy"` // Cumulative dispatcher time
+
+	ResourceExceeded uint64 `json:"resource_exceeded"` // Jobs stopped by a sandbox limit
+}
+
+type executionCounters struct {
+	executed, failed        atomic.Uint64
+	inputBytes, outputBytes atomic.Uint64
+	busy                    atomic.Int64
+	resourceExceeded        atomic.Uint64
+}
+
+func (c *executionCounters) record(in, out int, elapsed time.Duration, ok bool) {
+	c.executed.Add(1)
+	if !ok {
+		c.failed.Add(1)
+	}
+	c.inputBytes.Add(uint64(in))
+	c.outputBytes.Add(uint64(out))
+	c.busy.Add(int64(elapsed))
+}
+
+// GetExecutionStats returns the delegated execution counters.
+func (m *MeshCoordinator) GetExecutionStats() ExecutionStats {
+	c := &m.execution
+	return ExecutionStats{
+		Executed:    c.executed.Load(),
+		Failed:      c.failed.Load(),
+		InputBytes:  c.inputBytes.Load(),
+		OutputBytes: c.outputBytes.Load(),
+		Busy:        time.Duration(c.busy.Load()),
+
+		ResourceExceeded: c.resourceExceeded.Load(),
+	}
+}
+
+// RegisterMetrics adds the coordinator's transport, gossip, DHT, ledger and
+// execution stats to reg, e.g. for a native node's /metrics endpoint.
+func (m *MeshCoordinator) RegisterMetrics(reg *metrics.Registry) {
+	reg.Register("mesh", "Mesh coordinator metrics", func() interface{} {
+		return m.GetMetrics()
+	})
+	reg.Register("transport", "Peer transport metrics", func() interface{} {
+		return map[string]interface{}{
+			"connections": m.transport.GetConnectionMetrics(),
+			"health":      m.transport.GetHealth(),
+		}
+	})
+	if m.gossip != nil {
+		reg.Register("gossip", "Gossip protocol metrics", func() interface{} {
+			return m.gossip.GetMetrics()
+		})
+	}
+	if m.dht != nil {
+		reg.Register("dht", "Kademlia DHT metrics", func() interface{} {
+			return m.dht.GetMetrics()
+		})
+	}
+	reg.Register("ledger", "Economic ledger totals", func() interface{} {
+		return m.GetEconomicStats()
+	})
+	reg.Register("execution", "Delegated job execution", func() interface{} {
+		return m.GetExecutionStats()
+	})
+	reg.Register("storage_proof", "Storage proof challenges", func() interface{} {
+		return m.GetStorageProofStats()
+	})
+	reg.Register("chunk_ttl", "Demand-scaled chunk provider records", func() interface{} {
+		return m.GetChunkTTLStats()
+	})
+	reg.Register("storage_quota", "Local chunk storage against its quota", func() interface{} {
+		return m.GetStorageQuotaUsage()
+	})
+	reg.Register("metrics_outliers", "Peers flagged for implausible gossiped metrics", func() interface{} {
+		return len(m.GetMetricsOutliers())
+	})
+}
@@ -0,0 +1,37 @@
+package mesh
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nmxmxh/inos_v1/kernel/utils/metrics"
+)
+
+func TestRegisterMetrics_ExposesSubsystems(t *testing.T) {
+	coord, _ := newDelegationTestCoordinator(t)
+	if _, err := coord.DelegateCompute(context.Background(), "compress", "input-digest", []byte("source")); err != nil {
+		t.Fatalf("DelegateCompute failed: %v", err)
+	}
+
+	reg := metrics.NewRegistry("inos")
+	coord.RegisterMetrics(reg)
+	values := make(map[string]float64)
+	for _, s := range reg.Gather() {
+		values[s.Name] = s.Value
+	}
+
+	for _, name := range []string{
+		"inos_mesh_total_peers",
+		"inos_transport_connections_active_connections",
+		"inos_gossip_messages_sent",
+		"inos_dht_total_queries",
+		"inos_execution_executed",
+	} {
+		if _, ok := values[name]; !ok {
+			t.Fatalf("missing %s in %v", name, values)
+		}
+	}
+	if values["inos_execution_executed"] != 1 || values["inos_execution_input_bytes"] != float64(len("source")) {
+		t.Fatalf("delegated execution not counted: %v", values)
+	}
+}
@@ -0,0 +1,171 @@
+package mesh
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/routing"
+)
+
+const meshMetricsVersion = "inos-mesh-metrics-v1"
+
+// SignedMeshMetrics is a node's metrics as it gossips them. The node they
+// describe signs them, so a forwarder cannot forge or alter another node's
+// numbers. Metrics travels as encoded JSON so the signed bytes survive
+// re-encoding along the gossip path.
+type SignedMeshMetrics struct {
+	NodeID    string            `json:"node_id"`
+	Sequence  uint64            `json:"sequence"`  // Increases with every report from the node
+	IssuedAt  int64             `json:"issued_at"` // Unix milliseconds, mesh time; gossip JSON carries numbers as float64
+	Metrics   []byte            `json:"metrics"`
+	PublicKey ed25519.PublicKey `json:"public_key"`
+	Signature []byte            `json:"signature"`
+}
+
+// peerMetricsVersion is the newest report accepted from a node.
+type peerMetricsVersion struct {
+	sequence uint64
+	received time.Time
+}
+
+func meshMetricsPayload(s *SignedMeshMetrics) []byte {
+	sum := sha256.Sum256(s.Metrics)
+	buf := make([]byte, 0, 160)
+	buf = append(buf, meshMetricsVersion...)
+	buf = append(buf, 0)
+	buf = append(buf, s.NodeID...)
+	buf = append(buf, 0)
+	buf = binary.BigEndian.AppendUint64(buf, s.Sequence)
+	buf = binary.BigEndian.AppendUint64(buf, uint64(s.IssuedAt))
+	buf = append(buf, s.PublicKey...)
+	return append(buf, sum[:]...)
+}
+
+// nextMetricsSequence starts from the clock, so a restarted node's reports
+// are not taken for stale ones.
+func (m *MeshCoordinator) nextMetricsSequence() uint64 {
+	for {
+		last := m.metricsSequence.Load()
+		next := max(last+1, uint64(time.Now().UnixMilli()))
+		if m.metricsSequence.CompareAndSwap(last, next) {
+			return next
+		}
+	}
+}
+
+// signMeshMetrics stamps metrics with this node's identity.
+func (m *MeshCoordinator) signMeshMetrics(metrics common.MeshMetrics) (*SignedMeshMetrics, error) {
+	data, err := json.Marshal(metrics)
+	if err != nil {
+		return nil, err
+	}
+	signed := &SignedMeshMetrics{
+		NodeID:    m.nodeID,
+		Sequence:  m.nextMetricsSequence(),
+		IssuedAt:  m.MeshTime().UnixMilli(),
+		Metrics:   data,
+		PublicKey: m.gossip.PublicKey(),
+	}
+	sig, pub, err := m.gossip.SignAttestation(meshMetricsPayload(signed))
+	if err != nil {
+		return nil, err
+	}
+	if !pub.Equal(signed.PublicKey) {
+		return nil, errors.New("identity key rotated while signing metrics")
+	}
+	signed.Signature = sig
+	return signed, nil
+}
+
+// verifyMeshMetrics checks that the node the metrics describe signed them
+// and that they are recent.
+func (m *MeshCoordinator) verifyMeshMetrics(s *SignedMeshMetrics) error {
+	if len(s.Signature) == 0 {
+		return errors.New("metrics are not signed")
+	}
+	if s.NodeID == m.nodeID {
+		return errors.New("metrics claim to be from this node")
+	}
+	maxAge := m.config.MetricsGossip.MaxAge
+	if age := m.MeshTime().Sub(time.UnixMilli(s.IssuedAt)); maxAge > 0 && (age > maxAge || age < -maxAge) {
+		return errors.New("metrics timestamp outside allowed window")
+	}
+	if err := m.checkIdentityKey(s.NodeID, s.PublicKey); err != nil {
+		return fmt.Errorf("metrics signer: %w", err)
+	}
+	if !ed25519.Verify(s.PublicKey, meshMetricsPayload(s), s.Signature) {
+		return errors.New("invalid metrics signature")
+	}
+	return nil
+}
+
+// handleMeshMetrics takes in a gossiped metrics report. It is stored under
+// the node that signed it, whoever relayed it, and only if its sequence is
+// newer than the last one accepted from that node.
+func (m *MeshCoordinator) handleMeshMetrics(msg *common.GossipMessage) error {
+	data, err := json.Marshal(msg.Payload)
+	if err != nil {
+		return err
+	}
+	var signed SignedMeshMetrics
+	if err := json.Unmarshal(data, &signed); err != nil {
+		return err
+	}
+	if err := m.verifyMeshMetrics(&signed); err != nil {
+		m.logger.Debug("rejected peer metrics", "node", getShortID(signed.NodeID), "sender", getShortID(msg.Sender), "error", err)
+		if len(signed.Signature) > 0 && m.reputation != nil {
+			m.reputation.ReportPenalty(msg.Sender, routing.PenaltyInvalidData)
+		}
+		return err
+	}
+
+	var peerMetrics common.MeshMetrics
+	if err := json.Unmarshal(signed.Metrics, &peerMetrics); err != nil {
+		return err
+	}
+	if peerMetrics.Synthetic {
+		return nil // demo-mode output must not enter real stats
+	}
+
+	m.peerMetricsMu.Lock()
+	if last, ok := m.peerMetricsVersions[signed.NodeID]; ok && signed.Sequence <= last.sequence {
+		m.peerMetricsMu.Unlock()
+		return errors.New("stale metrics sequence")
+	}
+	m.peerMetricsVersions[signed.NodeID] = peerMetricsVersion{sequence: signed.Sequence, received: time.Now()}
+	m.peerMetrics[signed.NodeID] = peerMetrics
+	m.peerMetricsMu.Unlock()
+
+	m.gossip.SetPeerSector(signed.NodeID, peerMetrics.SectorID)
+	return nil
+}
+
+// expirePeerMetrics drops metrics from nodes that have not reported within
+// MetricsGossip.TTL, with their sequence. It returns how many went.
+func (m *MeshCoordinator) expirePeerMetrics(now time.Time) int {
+	ttl := m.config.MetricsGossip.TTL
+	if ttl <= 0 {
+		return 0
+	}
+
+	m.peerMetricsMu.Lock()
+	defer m.peerMetricsMu.Unlock()
+	expired := 0
+	for nodeID, version := range m.peerMetricsVersions {
+		if now.Sub(version.received) <= ttl {
+			continue
+		}
+		delete(m.peerMetricsVersions, nodeID)
+		if _, ok := m.peerMetrics[nodeID]; ok {
+			delete(m.peerMetrics, nodeID)
+			expired++
+		}
+	}
+	return expired
+}
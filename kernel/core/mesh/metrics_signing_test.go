@@ -0,0 +1,146 @@
+package mesh
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+)
+
+// metricsPair returns a reporting node and a receiver that knows its key.
+func metricsPair(t *testing.T) (reporter, receiver *MeshCoordinator) {
+	t.Helper()
+	reporter = NewMeshCoordinator("reporter", "us-east", &MockTransport{nodeID: "reporter"}, nil)
+	receiver = NewMeshCoordinator("receiver", "us-east", &MockTransport{nodeID: "receiver"}, nil)
+	receiver.gossip.SetPeerIdentityKey("reporter", reporter.gossip.PublicKey())
+	return reporter, receiver
+}
+
+// metricsMessage gossips signed as relayed by sender, through a JSON round
+// trip as on the wire.
+func metricsMessage(t *testing.T, sender string, signed *SignedMeshMetrics) *common.GossipMessage {
+	t.Helper()
+	data, err := json.Marshal(signed)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var payload map[string]interface{}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	return &common.GossipMessage{Type: "mesh_metrics", Sender: sender, Payload: payload}
+}
+
+func signTestMetrics(t *testing.T, coord *MeshCoordinator, peers uint32) *SignedMeshMetrics {
+	t.Helper()
+	signed, err := coord.signMeshMetrics(common.MeshMetrics{TotalPeers: peers, SectorID: 3})
+	if err != nil {
+		t.Fatalf("sign metrics: %v", err)
+	}
+	return signed
+}
+
+func TestMetricsSigning_StoredUnderSubjectWhoeverRelays(t *testing.T) {
+	reporter, receiver := metricsPair(t)
+
+	if err := receiver.handleMeshMetrics(metricsMessage(t, "relay", signTestMetrics(t, reporter, 7))); err != nil {
+		t.Fatalf("signed metrics rejected: %v", err)
+	}
+	receiver.peerMetricsMu.RLock()
+	got, ok := receiver.peerMetrics["reporter"]
+	_, relayed := receiver.peerMetrics["relay"]
+	receiver.peerMetricsMu.RUnlock()
+	if !ok || got.TotalPeers != 7 || got.SectorID != 3 {
+		t.Fatalf("expected metrics stored under the reporter, got %+v (ok=%v)", got, ok)
+	}
+	if relayed {
+		t.Fatal("metrics must not be stored under the relaying node")
+	}
+}
+
+func TestMetricsSigning_RejectsForgedAndUnsigned(t *testing.T) {
+	reporter, receiver := metricsPair(t)
+	forger := NewMeshCoordinator("forger", "us-east", &MockTransport{nodeID: "forger"}, nil)
+
+	// The forger signs metrics naming the reporter with its own key
+	forged, err := forger.signMeshMetrics(common.MeshMetrics{TotalPeers: 999})
+	if err != nil {
+		t.Fatalf("sign metrics: %v", err)
+	}
+	forged.NodeID = "reporter"
+	if err := receiver.handleMeshMetrics(metricsMessage(t, "forger", forged)); err == nil {
+		t.Fatal("expected metrics signed by another key to be rejected")
+	}
+
+	// Altered after signing
+	tampered := signTestMetrics(t, reporter, 7)
+	tampered.Metrics, _ = json.Marshal(common.MeshMetrics{TotalPeers: 999})
+	if err := receiver.handleMeshMetrics(metricsMessage(t, "relay", tampered)); err == nil {
+		t.Fatal("expected tampered metrics to be rejected")
+	}
+
+	unsigned := signTestMetrics(t, reporter, 7)
+	unsigned.Signature = nil
+	if err := receiver.handleMeshMetrics(metricsMessage(t, "reporter", unsigned)); err == nil {
+		t.Fatal("expected unsigned metrics to be rejected")
+	}
+
+	receiver.peerMetricsMu.RLock()
+	defer receiver.peerMetricsMu.RUnlock()
+	if len(receiver.peerMetrics) != 0 {
+		t.Fatalf("expected no metrics stored, got %v", receiver.peerMetrics)
+	}
+}
+
+func TestMetricsSigning_RejectsStaleSequenceAndAge(t *testing.T) {
+	reporter, receiver := metricsPair(t)
+
+	older := signTestMetrics(t, reporter, 1)
+	newer := signTestMetrics(t, reporter, 2)
+	if newer.Sequence <= older.Sequence {
+		t.Fatalf("expected increasing sequences, got %d then %d", older.Sequence, newer.Sequence)
+	}
+
+	if err := receiver.handleMeshMetrics(metricsMessage(t, "reporter", newer)); err != nil {
+		t.Fatalf("metrics rejected: %v", err)
+	}
+	if err := receiver.handleMeshMetrics(metricsMessage(t, "relay", newer)); err == nil {
+		t.Fatal("expected a replayed sequence to be rejected")
+	}
+	if err := receiver.handleMeshMetrics(metricsMessage(t, "relay", older)); err == nil {
+		t.Fatal("expected an older sequence to be rejected")
+	}
+	receiver.peerMetricsMu.RLock()
+	peers := receiver.peerMetrics["reporter"].TotalPeers
+	receiver.peerMetricsMu.RUnlock()
+	if peers != 2 {
+		t.Fatalf("expected the newest metrics kept, got %d peers", peers)
+	}
+
+	// Signed long ago, though never seen
+	reporter.timeSync.offset.Store(int64(-time.Hour))
+	old := signTestMetrics(t, reporter, 3)
+	if err := receiver.handleMeshMetrics(metricsMessage(t, "reporter", old)); err == nil {
+		t.Fatal("expected metrics older than MaxAge to be rejected")
+	}
+}
+
+func TestMetricsSigning_ExpireAfterTTL(t *testing.T) {
+	reporter, receiver := metricsPair(t)
+	if err := receiver.handleMeshMetrics(metricsMessage(t, "reporter", signTestMetrics(t, reporter, 7))); err != nil {
+		t.Fatalf("metrics rejected: %v", err)
+	}
+
+	if n := receiver.expirePeerMetrics(time.Now()); n != 0 {
+		t.Fatalf("expected fresh metrics kept, expired %d", n)
+	}
+	if n := receiver.expirePeerMetrics(time.Now().Add(receiver.config.MetricsGossip.TTL + time.Second)); n != 1 {
+		t.Fatalf("expected one node's metrics to expire, got %d", n)
+	}
+	receiver.peerMetricsMu.RLock()
+	defer receiver.peerMetricsMu.RUnlock()
+	if len(receiver.peerMetrics) != 0 || len(receiver.peerMetricsVersions) != 0 {
+		t.Fatal("expected metrics and sequence dropped after the TTL")
+	}
+}
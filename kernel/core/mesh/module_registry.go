@@ -0,0 +1,378 @@
+package mesh
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+	"github.com/nmxmxh/inos_v1/kernel/threads/foundation"
+)
+
+const moduleAnnounceTopic = "module_announce"
+
+// JobParamModule names the registry module a job runs, by content hash. The
+// executing node loads it through its registry before dispatching the job.
+const JobParamModule = "wasm_module"
+
+// Module run policies.
+const (
+	ModulePolicyOpen      = "open"      // Any module that validates may run, unless denied
+	ModulePolicyAllowlist = "allowlist" // Only hashes passed to AllowModule may run
+)
+
+var (
+	ErrModuleNotAllowed = errors.New("module is not allowed to run on this node")
+	ErrInvalidModule    = errors.New("not a valid wasm module")
+)
+
+// wasmHeader is the binary magic "\0asm" followed by format version 1.
+var wasmHeader = []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}
+
+// ModuleCompiler turns validated module bytes into whatever the local
+// runtime executes. A browser node compiles to a WebAssembly.Module; the
+// default only checks the header and keeps the bytes.
+type ModuleCompiler interface {
+	CompileModule(hash string, wasm []byte) (interface{}, error)
+}
+
+type headerOnlyCompiler struct{}
+
+func (headerOnlyCompiler) CompileModule(_ string, wasm []byte) (interface{}, error) {
+	return wasm, nil
+}
+
+// ModuleAnnouncement is a module a peer announced over gossip.
+type ModuleAnnouncement struct {
+	Hash      string    `json:"hash"`
+	Name      string    `json:"name,omitempty"`
+	Size      int       `json:"size"`
+	Publisher string    `json:"publisher"`
+	SeenAt    time.Time `json:"seen_at"`
+}
+
+// CompiledModule is a module loaded into this node's cache.
+type CompiledModule struct {
+	Hash     string      `json:"hash"`
+	Size     int         `json:"size"`
+	Artifact interface{} `json:"-"` // Result of ModuleCompiler.CompileModule
+	LoadedAt time.Time   `json:"loaded_at"`
+	lastUsed time.Time
+}
+
+// moduleLoad lets concurrent LoadModule calls for one hash share a fetch.
+type moduleLoad struct {
+	done   chan struct{}
+	module *CompiledModule
+	err    error
+}
+
+type moduleRegistry struct {
+	mu       sync.Mutex
+	known    map[string]*ModuleAnnouncement
+	compiled map[string]*CompiledModule
+	loading  map[string]*moduleLoad
+	allowed  map[string]bool
+	denied   map[string]bool
+	compiler ModuleCompiler
+}
+
+func newModuleRegistry() moduleRegistry {
+	return moduleRegistry{
+		known:    make(map[string]*ModuleAnnouncement),
+		compiled: make(map[string]*CompiledModule),
+		loading:  make(map[string]*moduleLoad),
+		allowed:  make(map[string]bool),
+		denied:   make(map[string]bool),
+	}
+}
+
+// validateWASM checks the module header and size limit.
+func (m *MeshCoordinator) validateWASM(wasm []byte) error {
+	if !bytes.HasPrefix(wasm, wasmHeader) {
+		return ErrInvalidModule
+	}
+	if limit := m.config.Modules.MaxSize; limit > 0 && len(wasm) > limit {
+		return fmt.Errorf("%w: %d bytes exceeds the %d byte limit", ErrInvalidModule, len(wasm), limit)
+	}
+	return nil
+}
+
+// SetModuleCompiler installs the runtime's compiler. Modules already cached
+// keep the artifact they were compiled to.
+func (m *MeshCoordinator) SetModuleCompiler(c ModuleCompiler) {
+	m.modules.mu.Lock()
+	defer m.modules.mu.Unlock()
+	m.modules.compiler = c
+}
+
+// AllowModule lets a module hash run under the allowlist policy and lifts
+// any earlier DenyModule.
+func (m *MeshCoordinator) AllowModule(hash string) {
+	m.modules.mu.Lock()
+	defer m.modules.mu.Unlock()
+	delete(m.modules.denied, hash)
+	m.modules.allowed[hash] = true
+}
+
+// DenyModule stops a module hash from running under any policy and drops
+// it from the cache.
+func (m *MeshCoordinator) DenyModule(hash string) {
+	m.modules.mu.Lock()
+	defer m.modules.mu.Unlock()
+	delete(m.modules.allowed, hash)
+	delete(m.modules.compiled, hash)
+	m.modules.denied[hash] = true
+}
+
+// ModuleAllowed reports whether the run policy lets a module hash run.
+func (m *MeshCoordinator) ModuleAllowed(hash string) error {
+	m.modules.mu.Lock()
+	defer m.modules.mu.Unlock()
+	return m.moduleAllowedLocked(hash)
+}
+
+func (m *MeshCoordinator) moduleAllowedLocked(hash string) error {
+	if m.modules.denied[hash] {
+		return fmt.Errorf("%w: %s is denied", ErrModuleNotAllowed, getShortID(hash))
+	}
+	switch m.config.Modules.Policy {
+	case "", ModulePolicyOpen:
+		return nil
+	case ModulePolicyAllowlist:
+		if m.modules.allowed[hash] {
+			return nil
+		}
+		return fmt.Errorf("%w: %s is not allowlisted", ErrModuleNotAllowed, getShortID(hash))
+	default:
+		return fmt.Errorf("unknown module policy %q", m.config.Modules.Policy)
+	}
+}
+
+// RegisterModule stores a module in the mesh by content hash and announces
+// it, so peers can load it by hash instead of receiving the bytes with every
+// request. The module is also allowed and cached locally.
+func (m *MeshCoordinator) RegisterModule(ctx context.Context, name string, wasm []byte) (string, error) {
+	if m.storage == nil {
+		return "", errors.New("storage provider not configured")
+	}
+	if err := m.validateWASM(wasm); err != nil {
+		return "", err
+	}
+
+	hash := m.computeResourceDigest(wasm)
+	if _, err := m.DistributeChunk(ctx, hash, wasm); err != nil {
+		return "", fmt.Errorf("failed to store module: %w", err)
+	}
+	m.AllowModule(hash)
+	if _, err := m.compileModule(hash, wasm); err != nil {
+		return "", err
+	}
+
+	m.recordModuleAnnouncement(ModuleAnnouncement{
+		Hash:      hash,
+		Name:      name,
+		Size:      len(wasm),
+		Publisher: m.nodeID,
+		SeenAt:    time.Now(),
+	})
+	payload := map[string]interface{}{
+		"hash": hash,
+		"name": name,
+		"size": len(wasm),
+	}
+	if err := m.PublishOrQueue(moduleAnnounceTopic, "module:"+hash, payload); err != nil {
+		m.logger.Debug("failed to announce module", "module", getShortID(hash), "error", err)
+	}
+	return hash, nil
+}
+
+// LoadModule returns the compiled module for a hash, fetching, verifying and
+// compiling it the first time it is asked for. Concurrent loads of one hash
+// share a single fetch. The run policy is checked on every call.
+func (m *MeshCoordinator) LoadModule(ctx context.Context, hash string) (*CompiledModule, error) {
+	m.modules.mu.Lock()
+	if err := m.moduleAllowedLocked(hash); err != nil {
+		m.modules.mu.Unlock()
+		return nil, err
+	}
+	if mod, ok := m.modules.compiled[hash]; ok {
+		mod.lastUsed = time.Now()
+		m.modules.mu.Unlock()
+		return mod, nil
+	}
+	load, inflight := m.modules.loading[hash]
+	if !inflight {
+		load = &moduleLoad{done: make(chan struct{})}
+		m.modules.loading[hash] = load
+	}
+	m.modules.mu.Unlock()
+
+	if inflight {
+		select {
+		case <-load.done:
+			return load.module, load.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	load.module, load.err = m.fetchModule(ctx, hash)
+	m.modules.mu.Lock()
+	delete(m.modules.loading, hash)
+	m.modules.mu.Unlock()
+	close(load.done)
+	return load.module, load.err
+}
+
+func (m *MeshCoordinator) fetchModule(ctx context.Context, hash string) (*CompiledModule, error) {
+	wasm, err := m.FetchChunk(ctx, hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch module %s: %w", getShortID(hash), err)
+	}
+	if m.computeResourceDigest(wasm) != hash {
+		return nil, fmt.Errorf("module %s: %w", getShortID(hash), ErrManifestMismatch)
+	}
+	if err := m.validateWASM(wasm); err != nil {
+		return nil, err
+	}
+	return m.compileModule(hash, wasm)
+}
+
+// compileModule compiles and caches a verified module, evicting the least
+// recently used entry once the cache is full.
+func (m *MeshCoordinator) compileModule(hash string, wasm []byte) (*CompiledModule, error) {
+	m.modules.mu.Lock()
+	compiler := m.modules.compiler
+	m.modules.mu.Unlock()
+	if compiler == nil {
+		compiler = headerOnlyCompiler{}
+	}
+
+	artifact, err := compiler.CompileModule(hash, wasm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile module %s: %w", getShortID(hash), err)
+	}
+	now := time.Now()
+	mod := &CompiledModule{Hash: hash, Size: len(wasm), Artifact: artifact, LoadedAt: now, lastUsed: now}
+
+	m.modules.mu.Lock()
+	defer m.modules.mu.Unlock()
+	if m.modules.denied[hash] {
+		return nil, fmt.Errorf("%w: %s is denied", ErrModuleNotAllowed, getShortID(hash))
+	}
+	if _, ok := m.modules.compiled[hash]; !ok && m.config.Modules.MaxCached > 0 && len(m.modules.compiled) >= m.config.Modules.MaxCached {
+		var oldest string
+		for h, cached := range m.modules.compiled {
+			if oldest == "" || cached.lastUsed.Before(m.modules.compiled[oldest].lastUsed) {
+				oldest = h
+			}
+		}
+		delete(m.modules.compiled, oldest)
+	}
+	m.modules.compiled[hash] = mod
+	return mod, nil
+}
+
+// GetCompiledModule returns a cached module without fetching it.
+func (m *MeshCoordinator) GetCompiledModule(hash string) (*CompiledModule, bool) {
+	m.modules.mu.Lock()
+	defer m.modules.mu.Unlock()
+	mod, ok := m.modules.compiled[hash]
+	return mod, ok
+}
+
+// GetKnownModules returns the modules announced by peers and by this node,
+// newest first.
+func (m *MeshCoordinator) GetKnownModules() []ModuleAnnouncement {
+	m.modules.mu.Lock()
+	out := make([]ModuleAnnouncement, 0, len(m.modules.known))
+	for _, known := range m.modules.known {
+		out = append(out, *known)
+	}
+	m.modules.mu.Unlock()
+
+	sort.Slice(out, func(i, j int) bool { return out[i].SeenAt.After(out[j].SeenAt) })
+	return out
+}
+
+// recordModuleAnnouncement remembers a module, dropping the oldest once the
+// configured limit is reached.
+func (m *MeshCoordinator) recordModuleAnnouncement(mod ModuleAnnouncement) {
+	m.modules.mu.Lock()
+	defer m.modules.mu.Unlock()
+
+	if _, ok := m.modules.known[mod.Hash]; !ok && m.config.Modules.MaxKnown > 0 && len(m.modules.known) >= m.config.Modules.MaxKnown {
+		var oldest string
+		for hash, known := range m.modules.known {
+			if oldest == "" || known.SeenAt.Before(m.modules.known[oldest].SeenAt) {
+				oldest = hash
+			}
+		}
+		delete(m.modules.known, oldest)
+	}
+	m.modules.known[mod.Hash] = &mod
+}
+
+type moduleKey struct{}
+
+// WithModule names the registry module DelegateCompute asks the executor to
+// run the operation with.
+func WithModule(ctx context.Context, hash string) context.Context {
+	return context.WithValue(ctx, moduleKey{}, hash)
+}
+
+func moduleFromContext(ctx context.Context) string {
+	hash, _ := ctx.Value(moduleKey{}).(string)
+	return hash
+}
+
+// jobModule loads the registry module a job names, if any, before it is
+// dispatched.
+func (m *MeshCoordinator) jobModule(ctx context.Context, job *foundation.Job) error {
+	hash, _ := job.Parameters[JobParamModule].(string)
+	if hash == "" {
+		return nil
+	}
+	_, err := m.LoadModule(ctx, hash)
+	return err
+}
+
+func (m *MeshCoordinator) registerModuleGossip() {
+	m.gossip.RegisterHandler(moduleAnnounceTopic, func(msg *common.GossipMessage) error {
+		payload, ok := msg.Payload.(map[string]interface{})
+		if !ok {
+			return errors.New("invalid payload type for module_announce")
+		}
+
+		hash, _ := payload["hash"].(string)
+		if hash == "" {
+			return errors.New("module announcement without hash")
+		}
+		mod := ModuleAnnouncement{
+			Hash:      hash,
+			Publisher: msg.Sender,
+			SeenAt:    time.Now(),
+		}
+		mod.Name, _ = payload["name"].(string)
+		if size, ok := payload["size"].(float64); ok && size > 0 {
+			mod.Size = int(size)
+		}
+		if limit := m.config.Modules.MaxSize; limit > 0 && mod.Size > limit {
+			return fmt.Errorf("announced module %s exceeds the size limit", getShortID(hash))
+		}
+
+		m.recordModuleAnnouncement(mod)
+		_ = m.storeChunkRecord(hash, msg.Sender)
+		m.publishEvent(MeshEventModuleAnnounced, msg.Sender, map[string]interface{}{
+			"hash": hash,
+			"name": mod.Name,
+			"size": mod.Size,
+		})
+		return nil
+	})
+}
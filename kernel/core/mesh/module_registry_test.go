@@ -0,0 +1,87 @@
+package mesh
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+)
+
+type countingCompiler struct{ calls atomic.Int32 }
+
+func (c *countingCompiler) CompileModule(hash string, wasm []byte) (interface{}, error) {
+	c.calls.Add(1)
+	return "compiled:" + hash, nil
+}
+
+func TestModuleRegistry_LoadsByHashOnceAndEnforcesPolicy(t *testing.T) {
+	ctx := context.Background()
+	publisher := NewMeshCoordinator("publisher", "us-east", &MockTransport{nodeID: "publisher"}, nil)
+	publisher.SetStorage(&MockStorage{chunks: map[string][]byte{}})
+
+	if _, err := publisher.RegisterModule(ctx, "bad", []byte("not wasm")); !errors.Is(err, ErrInvalidModule) {
+		t.Fatalf("expected ErrInvalidModule, got %v", err)
+	}
+	wasm := append(append([]byte(nil), wasmHeader...), 0x01, 0x04, 0x01, 0x60, 0x00, 0x00)
+	hash, err := publisher.RegisterModule(ctx, "noop", wasm)
+	if err != nil {
+		t.Fatalf("RegisterModule failed: %v", err)
+	}
+
+	coord := NewMeshCoordinator("self", "us-east", &MockTransport{nodeID: "self"}, nil)
+	coord.SetStorage(&MockStorage{chunks: map[string][]byte{}})
+	compiler := &countingCompiler{}
+	coord.SetModuleCompiler(compiler)
+	tr := coord.transport.(*MockTransport)
+	tr.registeredRPCHandlers[chunkFetchMethod] = publisher.transport.(*MockTransport).registeredRPCHandlers[chunkFetchMethod]
+	coord.cachePeer("publisher", &common.PeerCapability{PeerID: "publisher", LatencyMs: 5})
+	_ = coord.dht.Store(hash, "publisher", 3600)
+
+	coord.config.Modules.Policy = ModulePolicyAllowlist
+	if _, err := coord.LoadModule(ctx, hash); !errors.Is(err, ErrModuleNotAllowed) {
+		t.Fatalf("expected ErrModuleNotAllowed before allowlisting, got %v", err)
+	}
+	coord.AllowModule(hash)
+
+	for i := 0; i < 3; i++ {
+		mod, err := coord.LoadModule(ctx, hash)
+		if err != nil {
+			t.Fatalf("LoadModule failed: %v", err)
+		}
+		if mod.Artifact != "compiled:"+hash || mod.Size != len(wasm) {
+			t.Fatalf("unexpected module %+v", mod)
+		}
+	}
+	if n := compiler.calls.Load(); n != 1 {
+		t.Fatalf("expected one compile, got %d", n)
+	}
+
+	coord.DenyModule(hash)
+	if _, ok := coord.GetCompiledModule(hash); ok {
+		t.Fatal("denied module should leave the cache")
+	}
+	job := wasmJob(nil, nil)
+	job.Parameters[JobParamModule] = hash
+	if err := coord.jobModule(ctx, job); !errors.Is(err, ErrModuleNotAllowed) {
+		t.Fatalf("expected a job naming a denied module to be refused, got %v", err)
+	}
+}
+
+func TestModuleRegistry_ModuleIsSignedIntoDelegateRequest(t *testing.T) {
+	coord := NewMeshCoordinator("self", "us-east", &MockTransport{nodeID: "self"}, nil)
+	req := DelegateRequest{ID: "d1", Operation: "op"}
+	plain := delegationRequestPayload(&req)
+	req.Module = "module-hash"
+	if string(delegationRequestPayload(&req)) == string(plain) {
+		t.Fatal("module hash must be covered by the request signature")
+	}
+	if err := coord.signDelegationRequest(&req); err != nil {
+		t.Fatalf("signDelegationRequest failed: %v", err)
+	}
+	req.Module = "other-hash"
+	if err := coord.verifyDelegationRequest(&req); err == nil {
+		t.Fatal("expected a swapped module hash to fail verification")
+	}
+}
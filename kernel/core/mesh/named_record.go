@@ -0,0 +1,178 @@
+package mesh
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/routing"
+)
+
+// publishedRecord is a named record this node keeps alive in the DHT.
+type publishedRecord struct {
+	value    []byte
+	ttl      time.Duration
+	record   *routing.MutableRecord
+	lastErr  string
+	lastPush time.Time
+}
+
+// PublishedRecord reports a record this node publishes.
+type PublishedRecord struct {
+	Name      string    `json:"name"`
+	Key       string    `json:"key"`
+	Sequence  uint64    `json:"sequence"`
+	Size      int       `json:"size"`
+	ExpiresAt time.Time `json:"expires_at"`
+	LastError string    `json:"last_error,omitempty"`
+}
+
+// PublishRecord signs value under name with the node identity key and
+// stores it in the DHT, superseding any earlier value. The record is
+// re-signed and republished until UnpublishRecord, e.g. to keep "latest
+// manifest for app X" resolvable while this node serves it.
+func (m *MeshCoordinator) PublishRecord(ctx context.Context, name string, value []byte, ttl time.Duration) (*routing.MutableRecord, error) {
+	if name == "" {
+		return nil, errors.New("record name is required")
+	}
+	if m.gossip == nil {
+		return nil, errors.New("gossip signing key not initialized")
+	}
+	if ttl <= 0 {
+		ttl = m.config.Records.DefaultTTL
+	}
+
+	var sequence uint64
+	m.publishedRecordsMu.Lock()
+	if prev, ok := m.publishedRecords[name]; ok && prev.record != nil {
+		sequence = prev.record.Sequence
+	}
+	m.publishedRecordsMu.Unlock()
+
+	// A restarted node does not remember its sequence; continue from the
+	// newest copy the mesh still holds.
+	resolveCtx, cancel := context.WithTimeout(ctx, m.config.Records.ResolveTimeout)
+	if existing, err := m.dht.GetRecord(resolveCtx, m.gossip.PublicKey(), name); err == nil && existing.Sequence > sequence {
+		sequence = existing.Sequence
+	}
+	cancel()
+
+	record, err := m.signRecord(name, value, sequence+1, ttl)
+	if err != nil {
+		return nil, err
+	}
+	pub := &publishedRecord{value: append([]byte(nil), value...), ttl: ttl, record: record}
+	m.publishedRecordsMu.Lock()
+	m.publishedRecords[name] = pub
+	m.publishedRecordsMu.Unlock()
+
+	if err := m.pushRecord(ctx, pub, record); err != nil {
+		return nil, err
+	}
+	m.logger.Info("published record", "name", name, "sequence", record.Sequence, "ttl", ttl)
+	return record, nil
+}
+
+// UnpublishRecord stops republishing a record. Copies already in the DHT
+// remain resolvable until their TTL lapses.
+func (m *MeshCoordinator) UnpublishRecord(name string) bool {
+	m.publishedRecordsMu.Lock()
+	defer m.publishedRecordsMu.Unlock()
+	_, ok := m.publishedRecords[name]
+	delete(m.publishedRecords, name)
+	return ok
+}
+
+// ResolveRecord returns the newest verified record publicKey wrote under
+// name. Resolving this node's own records needs no network round trip.
+func (m *MeshCoordinator) ResolveRecord(ctx context.Context, publicKey ed25519.PublicKey, name string) (*routing.MutableRecord, error) {
+	ctx, cancel := context.WithTimeout(ctx, m.config.Records.ResolveTimeout)
+	defer cancel()
+	return m.dht.GetRecord(ctx, publicKey, name)
+}
+
+// GetPublishedRecords lists the records this node publishes.
+func (m *MeshCoordinator) GetPublishedRecords() []PublishedRecord {
+	m.publishedRecordsMu.Lock()
+	out := make([]PublishedRecord, 0, len(m.publishedRecords))
+	for name, pub := range m.publishedRecords {
+		entry := PublishedRecord{Name: name, Size: len(pub.value), LastError: pub.lastErr}
+		if pub.record != nil {
+			entry.Key = pub.record.Key()
+			entry.Sequence = pub.record.Sequence
+			entry.ExpiresAt = pub.record.ExpiresAt()
+		}
+		out = append(out, entry)
+	}
+	m.publishedRecordsMu.Unlock()
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+func (m *MeshCoordinator) signRecord(name string, value []byte, sequence uint64, ttl time.Duration) (*routing.MutableRecord, error) {
+	record := routing.NewMutableRecord(m.gossip.PublicKey(), name, value, sequence, ttl)
+	signature, publicKey, err := m.gossip.SignAttestation(record.SigningPayload())
+	if err != nil {
+		return nil, err
+	}
+	if !publicKey.Equal(ed25519.PublicKey(record.PublicKey)) {
+		return nil, errors.New("identity key rotated while signing record")
+	}
+	record.Signature = signature
+	return record, nil
+}
+
+func (m *MeshCoordinator) pushRecord(ctx context.Context, pub *publishedRecord, record *routing.MutableRecord) error {
+	err := m.dht.PutRecord(ctx, record)
+	m.publishedRecordsMu.Lock()
+	pub.lastPush = time.Now()
+	pub.lastErr = ""
+	if err != nil {
+		pub.lastErr = err.Error()
+	}
+	m.publishedRecordsMu.Unlock()
+	return err
+}
+
+// refreshNamedRecords re-signs and republishes records once half their TTL
+// has passed. Each republish takes the next sequence, so holders replace
+// the old copy rather than keep both.
+func (m *MeshCoordinator) refreshNamedRecords(ctx context.Context, now time.Time) int {
+	type due struct {
+		name string
+		pub  *publishedRecord
+	}
+	var pending []due
+	m.publishedRecordsMu.Lock()
+	for name, pub := range m.publishedRecords {
+		if pub.record == nil || now.Sub(pub.lastPush) >= pub.ttl/2 || pub.lastErr != "" {
+			pending = append(pending, due{name: name, pub: pub})
+		}
+	}
+	m.publishedRecordsMu.Unlock()
+
+	for _, d := range pending {
+		var sequence uint64
+		m.publishedRecordsMu.Lock()
+		if d.pub.record != nil {
+			sequence = d.pub.record.Sequence
+		}
+		m.publishedRecordsMu.Unlock()
+
+		record, err := m.signRecord(d.name, d.pub.value, sequence+1, d.pub.ttl)
+		if err != nil {
+			m.logger.Debug("failed to re-sign record", "name", d.name, "error", err)
+			continue
+		}
+		m.publishedRecordsMu.Lock()
+		d.pub.record = record
+		m.publishedRecordsMu.Unlock()
+		if err := m.pushRecord(ctx, d.pub, record); err != nil {
+			m.logger.Debug("failed to republish record", "name", d.name, "error", err)
+		}
+	}
+	return len(pending)
+}
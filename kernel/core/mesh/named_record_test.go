@@ -0,0 +1,48 @@
+package mesh
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestNamedRecord_PublishResolveAndRepublish(t *testing.T) {
+	coord := NewMeshCoordinator("self", "us-east", &MockTransport{nodeID: "self"}, nil)
+	ctx := context.Background()
+
+	first, err := coord.PublishRecord(ctx, "app/x", []byte("manifest-1"), time.Hour)
+	if err != nil {
+		t.Fatalf("publish failed: %v", err)
+	}
+	second, err := coord.PublishRecord(ctx, "app/x", []byte("manifest-2"), time.Hour)
+	if err != nil {
+		t.Fatalf("second publish failed: %v", err)
+	}
+	if second.Sequence != first.Sequence+1 {
+		t.Fatalf("expected sequence %d, got %d", first.Sequence+1, second.Sequence)
+	}
+
+	resolved, err := coord.ResolveRecord(ctx, coord.gossip.PublicKey(), "app/x")
+	if err != nil {
+		t.Fatalf("resolve failed: %v", err)
+	}
+	if string(resolved.Value) != "manifest-2" {
+		t.Fatalf("expected newest value, got %q", resolved.Value)
+	}
+
+	// Nothing is due until half the TTL has passed.
+	if n := coord.refreshNamedRecords(ctx, time.Now()); n != 0 {
+		t.Fatalf("expected no republish, got %d", n)
+	}
+	if n := coord.refreshNamedRecords(ctx, time.Now().Add(31*time.Minute)); n != 1 {
+		t.Fatalf("expected one republish, got %d", n)
+	}
+	records := coord.GetPublishedRecords()
+	if len(records) != 1 || records[0].Sequence != second.Sequence+1 {
+		t.Fatalf("unexpected published records %+v", records)
+	}
+
+	if !coord.UnpublishRecord("app/x") || len(coord.GetPublishedRecords()) != 0 {
+		t.Fatal("expected record to stop being republished")
+	}
+}
@@ -0,0 +1,28 @@
+package mesh
+
+// networkTransport is implemented by transports that can move their
+// connections onto a new network path.
+type networkTransport interface {
+	NetworkChanged(online bool)
+}
+
+// HandleNetworkChange is called when the host goes offline, comes back or
+// switches networks (connectionType is the browser's connection type, if
+// known). Coming back restarts ICE on every connection so they migrate to
+// the new path instead of dying at the keepalive timeout.
+func (m *MeshCoordinator) HandleNetworkChange(online bool, connectionType string) {
+	m.offline.Store(!online)
+	if nt, ok := m.transport.(networkTransport); ok {
+		nt.NetworkChanged(online)
+	}
+	m.logger.Info("network changed", "online", online, "type", connectionType)
+	m.publishEvent(MeshEventNetworkChanged, "", map[string]interface{}{
+		"online": online,
+		"type":   connectionType,
+	})
+}
+
+// Online reports whether the host last said it was online.
+func (m *MeshCoordinator) Online() bool {
+	return !m.offline.Load()
+}
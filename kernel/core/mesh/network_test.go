@@ -0,0 +1,32 @@
+package mesh
+
+import "testing"
+
+type networkTestTransport struct {
+	*MockTransport
+	changes []bool
+}
+
+func (t *networkTestTransport) NetworkChanged(online bool) {
+	t.changes = append(t.changes, online)
+}
+
+func TestHandleNetworkChange_ForwardsToTransport(t *testing.T) {
+	tr := &networkTestTransport{MockTransport: &MockTransport{
+		nodeID:      "node-a",
+		rpcHandlers: make(map[string]func(args interface{}) (interface{}, error)),
+	}}
+	coord := NewMeshCoordinator("node-a", "us-east", tr, nil)
+
+	coord.HandleNetworkChange(false, "none")
+	if coord.Online() {
+		t.Fatal("expected node to report offline")
+	}
+	coord.HandleNetworkChange(true, "wifi")
+	if !coord.Online() {
+		t.Fatal("expected node to report online")
+	}
+	if len(tr.changes) != 2 || tr.changes[0] || !tr.changes[1] {
+		t.Fatalf("expected offline then online forwarded, got %v", tr.changes)
+	}
+}
@@ -0,0 +1,304 @@
+package mesh
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+)
+
+const didBindingVersion = "inos-did-bind-v1"
+
+// ErrNoIdentityKey is returned by an IdentityKeyStore that has nothing saved yet.
+var ErrNoIdentityKey = errors.New("no identity key stored")
+
+// StoredIdentity is the persisted form of a NodeIdentity. NodeID is fixed at
+// creation; rotating the key later keeps it.
+type StoredIdentity struct {
+	NodeID    string    `json:"node_id"`
+	Seed      []byte    `json:"seed"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// IdentityKeyStore persists the node identity (a keyfile natively, IndexedDB
+// through the host in the browser).
+type IdentityKeyStore interface {
+	LoadIdentity() (StoredIdentity, error)
+	SaveIdentity(StoredIdentity) error
+}
+
+// FileIdentityKeyStore keeps the identity in a 0600 JSON keyfile.
+type FileIdentityKeyStore struct {
+	Path string
+}
+
+// LoadIdentity reads the keyfile; a missing file yields ErrNoIdentityKey.
+func (s FileIdentityKeyStore) LoadIdentity() (StoredIdentity, error) {
+	var stored StoredIdentity
+	data, err := os.ReadFile(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return stored, ErrNoIdentityKey
+	}
+	if err != nil {
+		return stored, err
+	}
+	return stored, json.Unmarshal(data, &stored)
+}
+
+// SaveIdentity writes the keyfile atomically.
+func (s FileIdentityKeyStore) SaveIdentity(stored StoredIdentity) error {
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return err
+	}
+	tmp := s.Path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.Path)
+}
+
+// DIDBinding is a signed statement tying a DID to a node ID and identity key.
+type DIDBinding struct {
+	Version   string `json:"version"`
+	DID       string `json:"did"`
+	NodeID    string `json:"node_id"`
+	PublicKey string `json:"public_key"`
+	Timestamp int64  `json:"timestamp"`
+	Signature string `json:"signature"`
+}
+
+func didBindingPayload(b *DIDBinding) []byte {
+	h := sha256.New()
+	for _, part := range []string{b.Version, b.DID, b.NodeID, b.PublicKey, strconv.FormatInt(b.Timestamp, 10)} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return h.Sum(nil)
+}
+
+// Verify checks the binding signature and that it names nodeID and key.
+func (b *DIDBinding) Verify(nodeID string, key ed25519.PublicKey) error {
+	if b == nil || b.Version != didBindingVersion {
+		return errors.New("unsupported DID binding")
+	}
+	if b.NodeID != nodeID {
+		return errors.New("DID binding is for another node")
+	}
+	if b.PublicKey != base64.StdEncoding.EncodeToString(key) {
+		return errors.New("DID binding is for another key")
+	}
+	sig, err := base64.StdEncoding.DecodeString(b.Signature)
+	if err != nil || !ed25519.Verify(key, didBindingPayload(b), sig) {
+		return errors.New("invalid DID binding signature")
+	}
+	return nil
+}
+
+// NodeIdentity owns the node's long-lived ed25519 keypair. The node ID is
+// derived from the first public key, so it survives restarts; the same key
+// signs gossip, attestation and ledger records.
+type NodeIdentity struct {
+	mu      sync.RWMutex
+	nodeID  string
+	key     ed25519.PrivateKey
+	created time.Time
+	store   IdentityKeyStore
+	binding *DIDBinding
+}
+
+// NodeIDFromPublicKey derives a stable node ID from an identity key.
+func NodeIDFromPublicKey(pub ed25519.PublicKey) string {
+	return common.NodeIDFromPublicKey(pub)
+}
+
+// NewEphemeralNodeIdentity creates an unpersisted identity.
+func NewEphemeralNodeIdentity() (*NodeIdentity, error) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate identity key: %w", err)
+	}
+	return &NodeIdentity{nodeID: NodeIDFromPublicKey(pub), key: priv, created: time.Now()}, nil
+}
+
+// LoadOrCreateNodeIdentity restores the identity from store, creating and
+// saving a fresh one on first boot.
+func LoadOrCreateNodeIdentity(store IdentityKeyStore) (*NodeIdentity, error) {
+	if store == nil {
+		return NewEphemeralNodeIdentity()
+	}
+
+	stored, err := store.LoadIdentity()
+	if err == nil {
+		if len(stored.Seed) != ed25519.SeedSize {
+			return nil, errors.New("stored identity key has invalid size")
+		}
+		key := ed25519.NewKeyFromSeed(stored.Seed)
+		nodeID := stored.NodeID
+		if nodeID == "" {
+			nodeID = NodeIDFromPublicKey(key.Public().(ed25519.PublicKey))
+		}
+		return &NodeIdentity{nodeID: nodeID, key: key, created: stored.CreatedAt, store: store}, nil
+	}
+	if !errors.Is(err, ErrNoIdentityKey) {
+		return nil, fmt.Errorf("failed to load node identity: %w", err)
+	}
+
+	id, err := NewEphemeralNodeIdentity()
+	if err != nil {
+		return nil, err
+	}
+	id.store = store
+	if err := id.persist(); err != nil {
+		return nil, fmt.Errorf("failed to save node identity: %w", err)
+	}
+	return id, nil
+}
+
+// NodeID returns the stable node ID.
+func (id *NodeIdentity) NodeID() string {
+	return id.nodeID
+}
+
+// PublicKey returns the current identity public key.
+func (id *NodeIdentity) PublicKey() ed25519.PublicKey {
+	id.mu.RLock()
+	defer id.mu.RUnlock()
+	return id.key.Public().(ed25519.PublicKey)
+}
+
+// PrivateKey returns the current signing key.
+func (id *NodeIdentity) PrivateKey() ed25519.PrivateKey {
+	id.mu.RLock()
+	defer id.mu.RUnlock()
+	return id.key
+}
+
+// Sign signs data with the identity key.
+func (id *NodeIdentity) Sign(data []byte) ([]byte, error) {
+	id.mu.RLock()
+	defer id.mu.RUnlock()
+	if id.key == nil {
+		return nil, errors.New("identity key not initialized")
+	}
+	return ed25519.Sign(id.key, data), nil
+}
+
+// BindDID signs a binding between did and this node's ID and key.
+func (id *NodeIdentity) BindDID(did string) (*DIDBinding, error) {
+	if did == "" {
+		return nil, errors.New("DID is required")
+	}
+	id.mu.Lock()
+	defer id.mu.Unlock()
+
+	binding := &DIDBinding{
+		Version:   didBindingVersion,
+		DID:       did,
+		NodeID:    id.nodeID,
+		PublicKey: base64.StdEncoding.EncodeToString(id.key.Public().(ed25519.PublicKey)),
+		Timestamp: time.Now().UnixNano(),
+	}
+	binding.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(id.key, didBindingPayload(binding)))
+	id.binding = binding
+	return binding, nil
+}
+
+// DIDBinding returns the current DID binding, if a DID has been bound.
+func (id *NodeIdentity) DIDBinding() *DIDBinding {
+	id.mu.RLock()
+	defer id.mu.RUnlock()
+	if id.binding == nil {
+		return nil
+	}
+	copied := *id.binding
+	return &copied
+}
+
+// replaceKey persists a rotated key, then swaps it in under the same node ID
+// and re-signs the DID binding. The old key stays active if saving fails.
+func (id *NodeIdentity) replaceKey(key ed25519.PrivateKey) error {
+	if id.store != nil {
+		stored := StoredIdentity{NodeID: id.nodeID, Seed: key.Seed(), CreatedAt: id.created}
+		if err := id.store.SaveIdentity(stored); err != nil {
+			return fmt.Errorf("failed to save rotated identity key: %w", err)
+		}
+	}
+
+	id.mu.Lock()
+	id.key = key
+	did := ""
+	if id.binding != nil {
+		did = id.binding.DID
+	}
+	id.mu.Unlock()
+
+	if did != "" {
+		_, err := id.BindDID(did)
+		return err
+	}
+	return nil
+}
+
+func (id *NodeIdentity) persist() error {
+	if id.store == nil {
+		return nil
+	}
+	id.mu.RLock()
+	stored := StoredIdentity{NodeID: id.nodeID, Seed: id.key.Seed(), CreatedAt: id.created}
+	id.mu.RUnlock()
+	return id.store.SaveIdentity(stored)
+}
+
+// ========== Coordinator integration ==========
+
+// SetNodeIdentity installs the persistent identity. Its node ID must match
+// the coordinator's, since transport and routing are keyed by it.
+func (m *MeshCoordinator) SetNodeIdentity(id *NodeIdentity) error {
+	if id == nil {
+		return errors.New("node identity is required")
+	}
+	if id.NodeID() != m.nodeID {
+		return fmt.Errorf("identity node ID %s does not match coordinator %s", id.NodeID(), m.nodeID)
+	}
+
+	m.identityMu.Lock()
+	m.nodeIdentity = id
+	did := m.did
+	m.identityMu.Unlock()
+
+	m.applyNodeIdentity(id, did)
+	return nil
+}
+
+// NodeIdentity returns the identity backing this node's signatures.
+func (m *MeshCoordinator) NodeIdentity() *NodeIdentity {
+	m.identityMu.RLock()
+	defer m.identityMu.RUnlock()
+	return m.nodeIdentity
+}
+
+func (m *MeshCoordinator) applyNodeIdentity(id *NodeIdentity, did string) {
+	if m.gossip != nil {
+		if err := m.gossip.SetSigningKey(id.PrivateKey()); err != nil {
+			m.logger.Warn("failed to install identity key in gossip", "error", err)
+		}
+	}
+	if m.ledger != nil {
+		m.ledger.SetSigner(id.Sign)
+	}
+	if did != "" {
+		if _, err := id.BindDID(did); err != nil {
+			m.logger.Warn("failed to bind DID to node identity", "did", did, "error", err)
+		}
+	}
+}
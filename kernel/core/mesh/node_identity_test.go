@@ -0,0 +1,157 @@
+package mesh
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	sab_layout "github.com/nmxmxh/inos_v1/kernel/threads/sab"
+)
+
+func TestNodeIdentity_PersistsAcrossRestarts(t *testing.T) {
+	store := FileIdentityKeyStore{Path: filepath.Join(t.TempDir(), "node.key")}
+
+	first, err := LoadOrCreateNodeIdentity(store)
+	if err != nil {
+		t.Fatalf("LoadOrCreateNodeIdentity failed: %v", err)
+	}
+	if first.NodeID() != NodeIDFromPublicKey(first.PublicKey()) {
+		t.Fatalf("expected node ID derived from the public key, got %s", first.NodeID())
+	}
+
+	second, err := LoadOrCreateNodeIdentity(store)
+	if err != nil {
+		t.Fatalf("reload failed: %v", err)
+	}
+	if second.NodeID() != first.NodeID() || !second.PublicKey().Equal(first.PublicKey()) {
+		t.Fatal("expected the same identity after reload")
+	}
+}
+
+func TestNodeIdentity_RotationKeepsNodeIDAndRebindsDID(t *testing.T) {
+	store := FileIdentityKeyStore{Path: filepath.Join(t.TempDir(), "node.key")}
+	identity, err := LoadOrCreateNodeIdentity(store)
+	if err != nil {
+		t.Fatalf("LoadOrCreateNodeIdentity failed: %v", err)
+	}
+
+	coord := NewMeshCoordinator(identity.NodeID(), "us-east", &MockTransport{nodeID: identity.NodeID()}, nil)
+	if err := coord.SetNodeIdentity(identity); err != nil {
+		t.Fatalf("SetNodeIdentity failed: %v", err)
+	}
+	coord.SetIdentity("did:inos:alice", "", "")
+	if !coord.gossip.PublicKey().Equal(identity.PublicKey()) {
+		t.Fatal("expected gossip to sign with the node identity key")
+	}
+
+	if _, err := coord.RotateIdentityKey(); err != nil {
+		t.Fatalf("RotateIdentityKey failed: %v", err)
+	}
+	binding := identity.DIDBinding()
+	if binding == nil || binding.DID != "did:inos:alice" {
+		t.Fatalf("expected DID binding to survive rotation, got %+v", binding)
+	}
+	if err := binding.Verify(identity.NodeID(), coord.gossip.PublicKey()); err != nil {
+		t.Fatalf("expected binding re-signed with the rotated key: %v", err)
+	}
+
+	reloaded, err := LoadOrCreateNodeIdentity(store)
+	if err != nil {
+		t.Fatalf("reload failed: %v", err)
+	}
+	if reloaded.NodeID() != identity.NodeID() || !reloaded.PublicKey().Equal(coord.gossip.PublicKey()) {
+		t.Fatal("expected the rotated key to be persisted under the original node ID")
+	}
+}
+
+func TestNodeIdentity_RejectsMismatchedCoordinator(t *testing.T) {
+	identity, err := NewEphemeralNodeIdentity()
+	if err != nil {
+		t.Fatalf("NewEphemeralNodeIdentity failed: %v", err)
+	}
+	coord := NewMeshCoordinator("other", "us-east", &MockTransport{nodeID: "other"}, nil)
+	if err := coord.SetNodeIdentity(identity); err == nil {
+		t.Fatal("expected an identity for another node ID to be refused")
+	}
+}
+
+func TestDIDBinding_RejectsTampering(t *testing.T) {
+	identity, _ := NewEphemeralNodeIdentity()
+	binding, err := identity.BindDID("did:inos:alice")
+	if err != nil {
+		t.Fatalf("BindDID failed: %v", err)
+	}
+	if err := binding.Verify(identity.NodeID(), identity.PublicKey()); err != nil {
+		t.Fatalf("expected valid binding: %v", err)
+	}
+
+	forged := *binding
+	forged.DID = "did:inos:mallory"
+	if err := forged.Verify(identity.NodeID(), identity.PublicKey()); err == nil {
+		t.Fatal("expected a rewritten DID to fail verification")
+	}
+
+	other, _ := NewEphemeralNodeIdentity()
+	if err := binding.Verify(identity.NodeID(), other.PublicKey()); err == nil {
+		t.Fatal("expected a binding for another key to fail verification")
+	}
+}
+
+func TestAttestation_VerifiesDIDBinding(t *testing.T) {
+	identity, _ := NewEphemeralNodeIdentity()
+	bobID := identity.NodeID()
+	bobTransport := &MockTransport{nodeID: bobID}
+	bob := NewMeshCoordinator(bobID, "us-east", bobTransport, nil)
+	if err := bob.SetNodeIdentity(identity); err != nil {
+		t.Fatalf("SetNodeIdentity failed: %v", err)
+	}
+	bob.SetIdentity("did:inos:bob", "", "")
+	sab := make([]byte, sab_layout.SAB_SIZE_DEFAULT)
+	bob.SetSABBridge(&testSABBridge{data: sab})
+
+	aliceTransport := &MockTransport{nodeID: "alice", rpcHandlers: map[string]func(args interface{}) (interface{}, error){
+		attestationMethod: func(args interface{}) (interface{}, error) {
+			raw, _ := json.Marshal(args)
+			bobTransport.mu.RLock()
+			handler := bobTransport.registeredRPCHandlers[attestationMethod]
+			bobTransport.mu.RUnlock()
+			return handler(context.Background(), "alice", raw)
+		},
+	}}
+	alice := NewMeshCoordinator("alice", "us-east", aliceTransport, nil)
+	alice.SetSABBridge(&testSABBridge{data: sab})
+
+	record, err := alice.requestAttestation(bobID)
+	if err != nil {
+		t.Fatalf("requestAttestation failed: %v", err)
+	}
+	if record.DID != "did:inos:bob" || !record.DIDVerified {
+		t.Fatalf("expected verified DID, got %+v", record)
+	}
+}
+
+func TestEconomicLedger_SignsSettlements(t *testing.T) {
+	identity, _ := NewEphemeralNodeIdentity()
+	ledger := NewEconomicLedger()
+	ledger.SetSigner(identity.Sign)
+	ledger.RegisterAccount("requester", 1000)
+
+	escrow, err := ledger.CreateEscrow("escrow-1", "requester", 100, time.Minute, "job-1")
+	if err != nil {
+		t.Fatalf("CreateEscrow failed: %v", err)
+	}
+	if err := ledger.AssignProvider(escrow.ID, "provider"); err != nil {
+		t.Fatalf("AssignProvider failed: %v", err)
+	}
+	if err := ledger.ReleaseToProvider(escrow.ID, true); err != nil {
+		t.Fatalf("ReleaseToProvider failed: %v", err)
+	}
+
+	settled, _ := ledger.GetEscrow(escrow.ID)
+	if !ed25519.Verify(identity.PublicKey(), SettlementDigest(settled), settled.Signature) {
+		t.Fatal("expected settlement signed by the node identity")
+	}
+}
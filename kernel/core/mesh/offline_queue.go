@@ -0,0 +1,324 @@
+package mesh
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// OfflineOpKind identifies how a queued operation is replayed.
+type OfflineOpKind string
+
+const (
+	OfflineOpChunkAnnounce    OfflineOpKind = "chunk_announce"
+	OfflineOpCapabilityUpdate OfflineOpKind = "capability_update"
+	OfflineOpGossip           OfflineOpKind = "gossip" // settlements and other topic broadcasts
+)
+
+const defaultOfflineQueueSize = 1024
+
+// OfflineOperation is an outbound mesh operation deferred while disconnected.
+type OfflineOperation struct {
+	Seq      uint64          `json:"seq"`
+	Kind     OfflineOpKind   `json:"kind"`
+	Key      string          `json:"key,omitempty"` // Newer ops with the same key supersede older ones
+	Topic    string          `json:"topic,omitempty"`
+	Payload  json.RawMessage `json:"payload,omitempty"`
+	QueuedAt time.Time       `json:"queued_at"`
+	Attempts int             `json:"attempts"`
+}
+
+// OfflineQueueStore persists queued operations across restarts.
+type OfflineQueueStore interface {
+	SaveOperations(ops []OfflineOperation) error
+	LoadOperations() ([]OfflineOperation, error)
+}
+
+// OfflineQueue keeps outbound operations in order until the node reconnects.
+type OfflineQueue struct {
+	ops     []OfflineOperation
+	mu      sync.Mutex
+	maxSize int
+	nextSeq uint64
+	store   OfflineQueueStore
+
+	superseded atomic.Uint64
+	dropped    atomic.Uint64
+	replayed   atomic.Uint64
+}
+
+// NewOfflineQueue creates a bounded offline queue, restoring persisted operations.
+func NewOfflineQueue(maxSize int, store OfflineQueueStore) (*OfflineQueue, error) {
+	if maxSize <= 0 {
+		maxSize = defaultOfflineQueueSize
+	}
+	q := &OfflineQueue{maxSize: maxSize, store: store}
+	if store == nil {
+		return q, nil
+	}
+
+	ops, err := store.LoadOperations()
+	if err != nil {
+		return q, fmt.Errorf("failed to load offline queue: %w", err)
+	}
+	for _, op := range ops {
+		if op.Seq > q.nextSeq {
+			q.nextSeq = op.Seq
+		}
+	}
+	q.ops = ops
+	if len(q.ops) > q.maxSize {
+		q.dropped.Add(uint64(len(q.ops) - q.maxSize))
+		q.ops = q.ops[len(q.ops)-q.maxSize:]
+	}
+	return q, nil
+}
+
+// Enqueue appends an operation. An older operation with the same key is
+// superseded; when full, the oldest operation is dropped.
+func (q *OfflineQueue) Enqueue(op OfflineOperation) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if op.Key != "" {
+		q.removeKeyLocked(op.Key)
+	}
+
+	q.nextSeq++
+	op.Seq = q.nextSeq
+	if op.QueuedAt.IsZero() {
+		op.QueuedAt = time.Now()
+	}
+	q.ops = append(q.ops, op)
+
+	if over := len(q.ops) - q.maxSize; over > 0 {
+		q.ops = append([]OfflineOperation(nil), q.ops[over:]...)
+		q.dropped.Add(uint64(over))
+	}
+	q.persistLocked()
+}
+
+// Cancel removes a pending operation by key, returning true if one existed.
+func (q *OfflineQueue) Cancel(key string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	removed := q.removeKeyLocked(key)
+	if removed {
+		q.persistLocked()
+	}
+	return removed
+}
+
+// Drain removes and returns all pending operations in order.
+func (q *OfflineQueue) Drain() []OfflineOperation {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	ops := q.ops
+	q.ops = nil
+	q.persistLocked()
+	return ops
+}
+
+// Requeue puts operations that failed to replay back at the head of the queue,
+// skipping any that were superseded while the replay was in flight.
+func (q *OfflineQueue) Requeue(ops []OfflineOperation) {
+	if len(ops) == 0 {
+		return
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	pending := make(map[string]struct{}, len(q.ops))
+	for _, op := range q.ops {
+		if op.Key != "" {
+			pending[op.Key] = struct{}{}
+		}
+	}
+
+	head := make([]OfflineOperation, 0, len(ops))
+	for _, op := range ops {
+		if _, newer := pending[op.Key]; op.Key != "" && newer {
+			q.superseded.Add(1)
+			continue
+		}
+		op.Attempts++
+		head = append(head, op)
+	}
+
+	q.ops = append(head, q.ops...)
+	if over := len(q.ops) - q.maxSize; over > 0 {
+		q.ops = append([]OfflineOperation(nil), q.ops[over:]...)
+		q.dropped.Add(uint64(over))
+	}
+	q.persistLocked()
+}
+
+// Len returns the number of pending operations.
+func (q *OfflineQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.ops)
+}
+
+// Stats returns queue counters.
+func (q *OfflineQueue) Stats() map[string]interface{} {
+	return map[string]interface{}{
+		"pending":    q.Len(),
+		"max_size":   q.maxSize,
+		"superseded": q.superseded.Load(),
+		"dropped":    q.dropped.Load(),
+		"replayed":   q.replayed.Load(),
+	}
+}
+
+func (q *OfflineQueue) removeKeyLocked(key string) bool {
+	for i, existing := range q.ops {
+		if existing.Key == key {
+			q.ops = append(q.ops[:i], q.ops[i+1:]...)
+			q.superseded.Add(1)
+			return true
+		}
+	}
+	return false
+}
+
+func (q *OfflineQueue) persistLocked() {
+	if q.store == nil {
+		return
+	}
+	snapshot := append([]OfflineOperation(nil), q.ops...)
+	_ = q.store.SaveOperations(snapshot)
+}
+
+// ========== Coordinator Integration ==========
+
+// SetOfflineQueueStore enables persistence of deferred operations and restores
+// any operations left over from a previous session.
+func (m *MeshCoordinator) SetOfflineQueueStore(store OfflineQueueStore) error {
+	q, err := NewOfflineQueue(m.offlineQueue.maxSize, store)
+	if err != nil {
+		return err
+	}
+
+	// Carry over anything queued before persistence was configured.
+	for _, op := range m.offlineQueue.Drain() {
+		q.Enqueue(op)
+	}
+	m.offlineQueue = q
+	return nil
+}
+
+// GetOfflineQueueStats returns offline queue counters.
+func (m *MeshCoordinator) GetOfflineQueueStats() map[string]interface{} {
+	return m.offlineQueue.Stats()
+}
+
+func (m *MeshCoordinator) isMeshReachable() bool {
+	return m.gossip != nil && len(m.transport.GetConnectedPeers()) > 0
+}
+
+// PublishOrQueue broadcasts a gossip topic now, or defers it until the node
+// reconnects. A non-empty key lets later publishes supersede queued ones.
+func (m *MeshCoordinator) PublishOrQueue(topic, key string, payload interface{}) error {
+	if topic == "" {
+		return errors.New("topic is required")
+	}
+	if m.isMeshReachable() {
+		if err := m.gossip.Broadcast(topic, payload); err == nil {
+			return nil
+		}
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode offline operation: %w", err)
+	}
+	m.offlineQueue.Enqueue(OfflineOperation{Kind: OfflineOpGossip, Key: key, Topic: topic, Payload: data})
+	return nil
+}
+
+// AnnounceCapability publishes local capabilities, deferring while offline.
+// Only the latest capability update is kept in the queue.
+func (m *MeshCoordinator) AnnounceCapability(capability *PeerCapability) error {
+	if capability == nil {
+		return errors.New("capability is required")
+	}
+	if m.isMeshReachable() {
+		if err := m.gossip.AnnouncePeerCapability(capability); err == nil {
+			return nil
+		}
+	}
+
+	data, err := json.Marshal(capability)
+	if err != nil {
+		return fmt.Errorf("failed to encode capability: %w", err)
+	}
+	m.offlineQueue.Enqueue(OfflineOperation{Kind: OfflineOpCapabilityUpdate, Key: "capability", Payload: data})
+	return nil
+}
+
+func (m *MeshCoordinator) announceChunkOrQueue(chunkHash string) error {
+	if m.isMeshReachable() {
+		if err := m.gossip.AnnounceChunk(chunkHash); err == nil {
+			return nil
+		}
+	}
+	m.offlineQueue.Enqueue(OfflineOperation{Kind: OfflineOpChunkAnnounce, Key: "chunk:" + chunkHash, Topic: chunkHash})
+	return nil
+}
+
+// replayOfflineQueue flushes deferred operations in order once peers are back.
+func (m *MeshCoordinator) replayOfflineQueue() {
+	if !m.offlineReplaying.CompareAndSwap(false, true) {
+		return
+	}
+	defer m.offlineReplaying.Store(false)
+
+	ops := m.offlineQueue.Drain()
+	if len(ops) == 0 {
+		return
+	}
+
+	for i, op := range ops {
+		if !m.isMeshReachable() {
+			m.offlineQueue.Requeue(ops[i:])
+			return
+		}
+		if err := m.replayOfflineOperation(op); err != nil {
+			m.logger.Debug("offline replay paused", "kind", op.Kind, "error", err)
+			m.offlineQueue.Requeue(ops[i:])
+			return
+		}
+		m.offlineQueue.replayed.Add(1)
+	}
+
+	m.logger.Info("replayed offline operations", "count", len(ops))
+}
+
+func (m *MeshCoordinator) replayOfflineOperation(op OfflineOperation) error {
+	switch op.Kind {
+	case OfflineOpChunkAnnounce:
+		return m.gossip.AnnounceChunk(op.Topic)
+	case OfflineOpCapabilityUpdate:
+		var capability PeerCapability
+		if err := json.Unmarshal(op.Payload, &capability); err != nil {
+			return nil // Corrupt entries are dropped rather than blocking the queue
+		}
+		return m.gossip.AnnouncePeerCapability(&capability)
+	case OfflineOpGossip:
+		var payload interface{}
+		if err := json.Unmarshal(op.Payload, &payload); err != nil {
+			return nil
+		}
+		return m.gossip.Broadcast(op.Topic, payload)
+	default:
+		m.logger.Warn("dropping unknown offline operation", "kind", op.Kind)
+		return nil
+	}
+}
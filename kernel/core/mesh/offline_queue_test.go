@@ -0,0 +1,101 @@
+package mesh
+
+import (
+	"context"
+	"testing"
+)
+
+type memoryOfflineStore struct {
+	ops []OfflineOperation
+}
+
+func (s *memoryOfflineStore) SaveOperations(ops []OfflineOperation) error {
+	s.ops = ops
+	return nil
+}
+
+func (s *memoryOfflineStore) LoadOperations() ([]OfflineOperation, error) {
+	return s.ops, nil
+}
+
+func TestOfflineQueue_SupersedeAndLimit(t *testing.T) {
+	q, _ := NewOfflineQueue(3, nil)
+
+	q.Enqueue(OfflineOperation{Kind: OfflineOpCapabilityUpdate, Key: "capability", Payload: []byte(`{"v":1}`)})
+	q.Enqueue(OfflineOperation{Kind: OfflineOpChunkAnnounce, Key: "chunk:a", Topic: "a"})
+	q.Enqueue(OfflineOperation{Kind: OfflineOpCapabilityUpdate, Key: "capability", Payload: []byte(`{"v":2}`)})
+	if q.Len() != 2 {
+		t.Fatalf("expected superseded capability to be replaced, got %d ops", q.Len())
+	}
+
+	q.Enqueue(OfflineOperation{Kind: OfflineOpChunkAnnounce, Key: "chunk:b", Topic: "b"})
+	q.Enqueue(OfflineOperation{Kind: OfflineOpChunkAnnounce, Key: "chunk:c", Topic: "c"})
+
+	ops := q.Drain()
+	if len(ops) != 3 {
+		t.Fatalf("expected queue capped at 3, got %d", len(ops))
+	}
+	if ops[0].Key != "capability" || string(ops[0].Payload) != `{"v":2}` {
+		t.Fatalf("expected oldest op dropped and latest capability kept, got %+v", ops[0])
+	}
+	for i := 1; i < len(ops); i++ {
+		if ops[i].Seq <= ops[i-1].Seq {
+			t.Fatalf("operations out of order: %d after %d", ops[i].Seq, ops[i-1].Seq)
+		}
+	}
+	if dropped := q.Stats()["dropped"].(uint64); dropped != 1 {
+		t.Fatalf("expected 1 dropped op, got %d", dropped)
+	}
+}
+
+func TestOfflineQueue_RequeueSkipsSuperseded(t *testing.T) {
+	store := &memoryOfflineStore{}
+	q, _ := NewOfflineQueue(10, store)
+
+	q.Enqueue(OfflineOperation{Kind: OfflineOpGossip, Key: "settlement:1", Topic: "settle"})
+	q.Enqueue(OfflineOperation{Kind: OfflineOpChunkAnnounce, Key: "chunk:a", Topic: "a"})
+	inflight := q.Drain()
+
+	// A newer settlement arrives while the replay is in flight.
+	q.Enqueue(OfflineOperation{Kind: OfflineOpGossip, Key: "settlement:1", Topic: "settle"})
+	q.Requeue(inflight)
+
+	ops := q.Drain()
+	if len(ops) != 2 || ops[0].Key != "chunk:a" || ops[0].Attempts != 1 || ops[1].Key != "settlement:1" {
+		t.Fatalf("unexpected queue after requeue: %+v", ops)
+	}
+
+	q.Enqueue(OfflineOperation{Kind: OfflineOpChunkAnnounce, Key: "chunk:b", Topic: "b"})
+	restored, err := NewOfflineQueue(10, store)
+	if err != nil {
+		t.Fatalf("restore failed: %v", err)
+	}
+	restored.Enqueue(OfflineOperation{Kind: OfflineOpChunkAnnounce, Key: "chunk:c", Topic: "c"})
+	ops = restored.Drain()
+	if len(ops) != 2 || ops[0].Key != "chunk:b" || ops[1].Seq <= ops[0].Seq {
+		t.Fatalf("expected persisted ops restored in order, got %+v", ops)
+	}
+}
+
+func TestMeshCoordinator_QueuesChunkAnnouncementsWhileOffline(t *testing.T) {
+	tr := &MockTransport{nodeID: "node-a"}
+	coord := NewMeshCoordinator("node-a", "us-east", tr, nil)
+
+	if err := coord.RegisterChunk(context.Background(), "chunk-1"); err != nil {
+		t.Fatalf("RegisterChunk failed while offline: %v", err)
+	}
+	if err := coord.RegisterChunk(context.Background(), "chunk-2"); err != nil {
+		t.Fatalf("RegisterChunk failed while offline: %v", err)
+	}
+	if n := coord.offlineQueue.Len(); n != 2 {
+		t.Fatalf("expected 2 queued announcements, got %d", n)
+	}
+
+	if err := coord.UnregisterChunk(context.Background(), "chunk-1"); err != nil {
+		t.Fatalf("UnregisterChunk failed: %v", err)
+	}
+	ops := coord.offlineQueue.Drain()
+	if len(ops) != 1 || ops[0].Topic != "chunk-2" {
+		t.Fatalf("expected withdrawn chunk to be cancelled, got %+v", ops)
+	}
+}
@@ -245,6 +245,7 @@ type EpochTicker struct {
 	optimizer *EpochAwareOptimizer
 	stop      chan struct{}
 	logger    *slog.Logger
+	onEpoch   func(epoch uint32)
 }
 
 // NewEpochTicker creates a new epoch ticker
@@ -256,6 +257,12 @@ func NewEpochTicker(optimizer *EpochAwareOptimizer, logger *slog.Logger) *EpochT
 	}
 }
 
+// SetEpochHook registers fn to run after each epoch boundary. It must be
+// called before Start.
+func (et *EpochTicker) SetEpochHook(fn func(epoch uint32)) {
+	et.onEpoch = fn
+}
+
 // Start begins the epoch ticker
 func (et *EpochTicker) Start(ctx context.Context) {
 	et.ticker = time.NewTicker(et.optimizer.EpochDuration)
@@ -267,6 +274,9 @@ func (et *EpochTicker) Start(ctx context.Context) {
 			case <-et.ticker.C:
 				epoch++
 				et.optimizer.OnEpochBoundary(epoch)
+				if et.onEpoch != nil {
+					et.onEpoch(epoch)
+				}
 
 			case <-et.stop:
 				et.ticker.Stop()
@@ -0,0 +1,468 @@
+package mesh
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// ErrNotPinned is returned when unpinning something that is not pinned.
+	ErrNotPinned = errors.New("not pinned")
+	// ErrNoPinSet is returned by a PinStore that has nothing saved yet.
+	ErrNoPinSet = errors.New("no pin set stored")
+)
+
+// PinnedChunk keeps one chunk at Replicas copies and out of eviction.
+type PinnedChunk struct {
+	Hash     string    `json:"hash"`
+	Replicas int       `json:"replicas"`
+	PinnedAt time.Time `json:"pinned_at"`
+}
+
+// PinnedNamespace pins every chunk this node stores under a namespace.
+type PinnedNamespace struct {
+	Namespace string    `json:"namespace"`
+	Replicas  int       `json:"replicas"`
+	PinnedAt  time.Time `json:"pinned_at"`
+}
+
+// PinSet is the persisted form of the node's pins.
+type PinSet struct {
+	Chunks     []PinnedChunk     `json:"chunks"`
+	Namespaces []PinnedNamespace `json:"namespaces"`
+}
+
+// PinStore persists the pin set across restarts.
+type PinStore interface {
+	SavePins(PinSet) error
+	LoadPins() (PinSet, error)
+}
+
+// PinStatus reports how well one pinned chunk is replicated.
+type PinStatus struct {
+	Hash       string    `json:"hash"`
+	Namespace  string    `json:"namespace,omitempty"` // Set when pinned through its namespace
+	Target     int       `json:"target"`
+	Replicas   int       `json:"replicas"` // Known copies, including a local one
+	Local      bool      `json:"local"`
+	Healthy    bool      `json:"healthy"`
+	LastRepair time.Time `json:"last_repair,omitempty"`
+	LastError  string    `json:"last_error,omitempty"`
+}
+
+type pinRepairState struct {
+	lastRepair time.Time
+	lastError  string
+}
+
+type pinCounters struct {
+	repairs  atomic.Uint64 // Replicas placed by repair passes
+	degraded atomic.Uint64 // Pins a repair pass left below target
+}
+
+// pinTarget is one chunk the repair pass must keep at target replicas.
+type pinTarget struct {
+	hash      string
+	namespace string
+	replicas  int
+}
+
+// PinChunk protects a chunk from eviction and keeps at least replicas copies
+// of it on the mesh; 0 uses the configured default. Re-pinning changes the
+// target. The chunk does not have to be stored locally.
+func (m *MeshCoordinator) PinChunk(chunkHash string, replicas int) error {
+	if chunkHash == "" {
+		return errors.New("chunk hash is required")
+	}
+	replicas = m.pinReplicas(replicas)
+
+	m.pinsMu.Lock()
+	pin, ok := m.pinnedChunks[chunkHash]
+	if !ok {
+		pin = &PinnedChunk{Hash: chunkHash, PinnedAt: time.Now()}
+		m.pinnedChunks[chunkHash] = pin
+	}
+	pin.Replicas = replicas
+	m.pinsMu.Unlock()
+
+	m.kickPinRepair()
+	return m.persistPins()
+}
+
+// UnpinChunk drops a chunk pin. The chunk stays stored but becomes evictable
+// again unless its namespace is pinned.
+func (m *MeshCoordinator) UnpinChunk(chunkHash string) error {
+	m.pinsMu.Lock()
+	_, ok := m.pinnedChunks[chunkHash]
+	delete(m.pinnedChunks, chunkHash)
+	delete(m.pinRepairs, chunkHash)
+	m.pinsMu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("chunk %s: %w", getShortID(chunkHash), ErrNotPinned)
+	}
+	return m.persistPins()
+}
+
+// PinNamespace pins every chunk stored locally under namespace, including
+// ones stored later.
+func (m *MeshCoordinator) PinNamespace(namespace string, replicas int) error {
+	if namespace == "" {
+		return errors.New("namespace is required")
+	}
+	replicas = m.pinReplicas(replicas)
+
+	m.pinsMu.Lock()
+	pin, ok := m.pinnedNamespaces[namespace]
+	if !ok {
+		pin = &PinnedNamespace{Namespace: namespace, PinnedAt: time.Now()}
+		m.pinnedNamespaces[namespace] = pin
+	}
+	pin.Replicas = replicas
+	m.pinsMu.Unlock()
+
+	m.kickPinRepair()
+	return m.persistPins()
+}
+
+// UnpinNamespace drops a namespace pin.
+func (m *MeshCoordinator) UnpinNamespace(namespace string) error {
+	m.pinsMu.Lock()
+	_, ok := m.pinnedNamespaces[namespace]
+	delete(m.pinnedNamespaces, namespace)
+	m.pinsMu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("namespace %s: %w", namespace, ErrNotPinned)
+	}
+	return m.persistPins()
+}
+
+// IsPinned reports whether a chunk is pinned directly, through the
+// namespace it was stored under, or held by a recent WarmManifest.
+func (m *MeshCoordinator) IsPinned(chunkHash string) bool {
+	if m.isWarmHeld(chunkHash) {
+		return true
+	}
+	namespace, tracked := m.storageQuota.Namespace(chunkHash)
+
+	m.pinsMu.Lock()
+	defer m.pinsMu.Unlock()
+	if _, ok := m.pinnedChunks[chunkHash]; ok {
+		return true
+	}
+	_, ok := m.pinnedNamespaces[namespace]
+	return tracked && ok
+}
+
+// SetPinStore installs pin persistence and merges in the saved pin set; pins
+// made before this call win over saved ones.
+func (m *MeshCoordinator) SetPinStore(store PinStore) error {
+	m.pinsMu.Lock()
+	m.pinStore = store
+	m.pinsMu.Unlock()
+	if store == nil {
+		return nil
+	}
+
+	saved, err := store.LoadPins()
+	if errors.Is(err, ErrNoPinSet) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	m.pinsMu.Lock()
+	for _, pin := range saved.Chunks {
+		if _, ok := m.pinnedChunks[pin.Hash]; !ok && pin.Hash != "" {
+			pin := pin
+			m.pinnedChunks[pin.Hash] = &pin
+		}
+	}
+	for _, pin := range saved.Namespaces {
+		if _, ok := m.pinnedNamespaces[pin.Namespace]; !ok && pin.Namespace != "" {
+			pin := pin
+			m.pinnedNamespaces[pin.Namespace] = &pin
+		}
+	}
+	m.pinsMu.Unlock()
+
+	m.kickPinRepair()
+	return nil
+}
+
+// GetPins returns the current pin set.
+func (m *MeshCoordinator) GetPins() PinSet {
+	m.pinsMu.Lock()
+	defer m.pinsMu.Unlock()
+	return m.pinSetLocked()
+}
+
+// GetPinStatus returns the replication state of every pinned chunk, least
+// replicated first.
+func (m *MeshCoordinator) GetPinStatus() []PinStatus {
+	targets := m.pinTargets()
+	statuses := make([]PinStatus, 0, len(targets))
+	for _, target := range targets {
+		local := m.storageQuota.Tracked(target.hash)
+		replicas := len(m.otherProviders(m.dht.LocalProviders(target.hash)))
+		if local {
+			replicas++
+		}
+		status := PinStatus{
+			Hash:      target.hash,
+			Namespace: target.namespace,
+			Target:    target.replicas,
+			Replicas:  replicas,
+			Local:     local,
+			Healthy:   replicas >= target.replicas,
+		}
+		m.pinsMu.Lock()
+		if state, ok := m.pinRepairs[target.hash]; ok {
+			status.LastRepair = state.lastRepair
+			status.LastError = state.lastError
+		}
+		m.pinsMu.Unlock()
+		statuses = append(statuses, status)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		di := statuses[i].Target - statuses[i].Replicas
+		dj := statuses[j].Target - statuses[j].Replicas
+		if di != dj {
+			return di > dj
+		}
+		return statuses[i].Hash < statuses[j].Hash
+	})
+	return statuses
+}
+
+// repairPins brings under-replicated pins back to target, keeping a local copy
+// of each so the pin cannot be lost to eviction elsewhere. Returns the number
+// of replicas placed.
+func (m *MeshCoordinator) repairPins(ctx context.Context) int {
+	targets := m.pinTargets()
+	m.prunePinRepairs(targets)
+
+	placed := 0
+	budget := m.config.Pinning.MaxRepairsPerPass
+	for _, target := range targets {
+		if ctx.Err() != nil || (budget > 0 && placed >= budget) {
+			break
+		}
+		n, err := m.repairPin(ctx, target, budget-placed)
+		placed += n
+
+		m.pinsMu.Lock()
+		state, ok := m.pinRepairs[target.hash]
+		if !ok {
+			state = &pinRepairState{}
+			m.pinRepairs[target.hash] = state
+		}
+		if n > 0 || err != nil {
+			state.lastRepair = time.Now()
+		}
+		state.lastError = ""
+		if err != nil {
+			state.lastError = err.Error()
+		}
+		m.pinsMu.Unlock()
+
+		if err != nil {
+			m.pins.degraded.Add(1)
+			m.logger.Warn("pinned chunk below replica target",
+				"chunk", getShortID(target.hash),
+				"target", target.replicas,
+				"error", err,
+			)
+		}
+	}
+	if placed > 0 {
+		m.pins.repairs.Add(uint64(placed))
+		m.logger.Info("repaired pinned chunks", "replicas_placed", placed)
+	}
+	return placed
+}
+
+// repairPin places up to limit replicas of one pinned chunk (limit <= 0 means
+// no limit) and reports an error if it is still below target afterwards.
+func (m *MeshCoordinator) repairPin(ctx context.Context, target pinTarget, limit int) (int, error) {
+	providers, _ := m.dht.FindPeers(target.hash)
+	others := m.otherProviders(providers)
+
+	var data []byte
+	local := false
+	if m.storage != nil {
+		if ok, _ := m.storage.HasChunk(ctx, target.hash); ok {
+			data, _ = m.storage.FetchChunk(ctx, target.hash)
+			local = data != nil
+		}
+	}
+	replicas := len(others)
+	if local {
+		replicas++
+	}
+	if replicas >= target.replicas {
+		return 0, nil
+	}
+
+	if !local {
+		fetched, err := m.fetchStoredChunk(ctx, target.hash)
+		if err != nil {
+			return 0, fmt.Errorf("no reachable copy: %w", err)
+		}
+		data = fetched
+		if m.storage != nil && m.storage.StoreChunk(ctx, target.hash, data) == nil {
+			m.localChunksMu.Lock()
+			m.localChunks[target.hash] = struct{}{}
+			m.localChunksMu.Unlock()
+			m.trackStoredChunk(ctx, target.hash, len(data))
+			_ = m.storeChunkRecord(target.hash, m.nodeID)
+			replicas++
+		}
+	}
+
+	placed := 0
+	for replicas < target.replicas && (limit <= 0 || placed < limit) {
+		if _, err := m.placeReplica(ctx, target.hash, data, ""); err != nil {
+			return placed, fmt.Errorf("%d of %d replicas: %w", replicas, target.replicas, err)
+		}
+		placed++
+		replicas++
+	}
+	if replicas < target.replicas {
+		return placed, fmt.Errorf("%d of %d replicas after repair budget", replicas, target.replicas)
+	}
+	return placed, nil
+}
+
+// pinTargets expands direct and namespace pins into per-chunk targets. A chunk
+// pinned both ways uses the higher replica count.
+func (m *MeshCoordinator) pinTargets() []pinTarget {
+	entries := m.storageQuota.entries()
+
+	m.pinsMu.Lock()
+	byHash := make(map[string]pinTarget, len(m.pinnedChunks))
+	for hash, pin := range m.pinnedChunks {
+		byHash[hash] = pinTarget{hash: hash, replicas: pin.Replicas}
+	}
+	for _, entry := range entries {
+		pin, ok := m.pinnedNamespaces[entry.namespace]
+		if !ok {
+			continue
+		}
+		if existing, ok := byHash[entry.hash]; ok && existing.replicas >= pin.Replicas {
+			continue
+		}
+		byHash[entry.hash] = pinTarget{hash: entry.hash, namespace: entry.namespace, replicas: pin.Replicas}
+	}
+	m.pinsMu.Unlock()
+
+	targets := make([]pinTarget, 0, len(byHash))
+	for _, target := range byHash {
+		targets = append(targets, target)
+	}
+	sort.Slice(targets, func(i, j int) bool { return targets[i].hash < targets[j].hash })
+	return targets
+}
+
+// prunePinRepairs drops repair state for chunks that are no longer pinned.
+func (m *MeshCoordinator) prunePinRepairs(targets []pinTarget) {
+	live := make(map[string]bool, len(targets))
+	for _, target := range targets {
+		live[target.hash] = true
+	}
+	m.pinsMu.Lock()
+	for hash := range m.pinRepairs {
+		if !live[hash] {
+			delete(m.pinRepairs, hash)
+		}
+	}
+	m.pinsMu.Unlock()
+}
+
+func (m *MeshCoordinator) otherProviders(providers []string) []string {
+	others := providers[:0:0]
+	for _, p := range providers {
+		if p != m.nodeID {
+			others = append(others, p)
+		}
+	}
+	return others
+}
+
+func (m *MeshCoordinator) pinReplicas(replicas int) int {
+	if replicas <= 0 {
+		replicas = m.config.Pinning.DefaultReplicas
+	}
+	if limit := m.config.Pinning.MaxReplicas; limit > 0 && replicas > limit {
+		replicas = limit
+	}
+	return max(replicas, 1)
+}
+
+func (m *MeshCoordinator) pinSetLocked() PinSet {
+	set := PinSet{
+		Chunks:     make([]PinnedChunk, 0, len(m.pinnedChunks)),
+		Namespaces: make([]PinnedNamespace, 0, len(m.pinnedNamespaces)),
+	}
+	for _, pin := range m.pinnedChunks {
+		set.Chunks = append(set.Chunks, *pin)
+	}
+	for _, pin := range m.pinnedNamespaces {
+		set.Namespaces = append(set.Namespaces, *pin)
+	}
+	sort.Slice(set.Chunks, func(i, j int) bool { return set.Chunks[i].Hash < set.Chunks[j].Hash })
+	sort.Slice(set.Namespaces, func(i, j int) bool { return set.Namespaces[i].Namespace < set.Namespaces[j].Namespace })
+	return set
+}
+
+func (m *MeshCoordinator) persistPins() error {
+	m.pinsMu.Lock()
+	store := m.pinStore
+	set := m.pinSetLocked()
+	m.pinsMu.Unlock()
+
+	if store == nil {
+		return nil
+	}
+	if err := store.SavePins(set); err != nil {
+		return fmt.Errorf("failed to save pins: %w", err)
+	}
+	return nil
+}
+
+// kickPinRepair asks the repair loop for an early pass.
+func (m *MeshCoordinator) kickPinRepair() {
+	select {
+	case m.pinRepairKick <- struct{}{}:
+	default:
+	}
+}
+
+func (m *MeshCoordinator) pinRepairLoop() {
+	if m.config.Pinning.RepairInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(m.config.Pinning.RepairInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if m.deferLowPriority() {
+				continue
+			}
+		case <-m.pinRepairKick:
+		case <-m.shutdown:
+			return
+		}
+		m.repairPins(context.Background())
+	}
+}
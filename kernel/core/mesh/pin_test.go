@@ -0,0 +1,112 @@
+package mesh
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+)
+
+type memoryPinStore struct {
+	set *PinSet
+}
+
+func (s *memoryPinStore) SavePins(set PinSet) error {
+	s.set = &set
+	return nil
+}
+
+func (s *memoryPinStore) LoadPins() (PinSet, error) {
+	if s.set == nil {
+		return PinSet{}, ErrNoPinSet
+	}
+	return *s.set, nil
+}
+
+func TestPin_RepairRestoresReplicaTarget(t *testing.T) {
+	coord := NewMeshCoordinator("self", "us-east", &MockTransport{nodeID: "self"}, nil)
+	storage := &MockStorage{chunks: map[string][]byte{"chunk-a": []byte("pinned data")}}
+	coord.SetStorage(storage)
+	coord.storageQuota.Track("chunk-a", common.DefaultNamespace, 11)
+	for _, id := range []string{"peer-1", "peer-2", "peer-3"} {
+		_ = coord.dht.AddPeer(PeerInfo{ID: id, Capabilities: &PeerCapability{PeerID: id, Reputation: 0.9}})
+	}
+
+	if err := coord.PinChunk("chunk-a", 3); err != nil {
+		t.Fatalf("PinChunk failed: %v", err)
+	}
+	if status := coord.GetPinStatus(); len(status) != 1 || status[0].Replicas != 1 || status[0].Healthy {
+		t.Fatalf("expected an under-replicated pin, got %+v", status)
+	}
+
+	if placed := coord.repairPins(context.Background()); placed != 2 {
+		t.Fatalf("expected 2 replicas placed, got %d", placed)
+	}
+	status := coord.GetPinStatus()
+	if !status[0].Healthy || status[0].Replicas != 3 || status[0].LastError != "" {
+		t.Fatalf("expected healthy pin after repair, got %+v", status[0])
+	}
+	if placed := coord.repairPins(context.Background()); placed != 0 {
+		t.Fatalf("expected no work for a healthy pin, got %d", placed)
+	}
+}
+
+func TestPin_ProtectsFromEviction(t *testing.T) {
+	storage := &MockStorage{chunks: map[string][]byte{}}
+	coord := NewMeshCoordinator("self", "us-east", &MockTransport{nodeID: "self"}, nil)
+	coord.SetStorage(storage)
+	coord.config.StorageQuota.LowWatermark = 1
+	coord.storageQuota.SetLimit(100)
+
+	for _, hash := range []string{"direct", "tenant-chunk", "loose"} {
+		namespace := common.DefaultNamespace
+		if hash == "tenant-chunk" {
+			namespace = "tenant"
+		}
+		storage.chunks[hash] = make([]byte, 100)
+		coord.storageQuota.Track(hash, namespace, 100)
+		_ = coord.dht.Store(hash, "peer-x", 3600)
+	}
+	_ = coord.PinChunk("direct", 0)
+	_ = coord.PinNamespace("tenant", 2)
+
+	if !coord.IsPinned("tenant-chunk") || coord.IsPinned("loose") {
+		t.Fatal("expected the namespace pin to cover only its chunks")
+	}
+	if evicted := coord.enforceStorageQuota(context.Background()); evicted != 1 {
+		t.Fatalf("expected only the unpinned chunk evicted, got %d", evicted)
+	}
+	if _, ok := storage.chunks["loose"]; ok {
+		t.Fatal("expected the unpinned chunk to be evicted")
+	}
+
+	if err := coord.UnpinNamespace("tenant"); err != nil {
+		t.Fatalf("UnpinNamespace failed: %v", err)
+	}
+	if err := coord.UnpinChunk("loose"); !errors.Is(err, ErrNotPinned) {
+		t.Fatalf("expected ErrNotPinned, got %v", err)
+	}
+}
+
+func TestPin_StorePersistsPinSet(t *testing.T) {
+	store := &memoryPinStore{}
+	coord := NewMeshCoordinator("self", "us-east", &MockTransport{nodeID: "self"}, nil)
+	if err := coord.SetPinStore(store); err != nil {
+		t.Fatalf("SetPinStore failed: %v", err)
+	}
+	_ = coord.PinChunk("chunk-a", 100)
+	_ = coord.PinNamespace("tenant", 2)
+
+	next := NewMeshCoordinator("self", "us-east", &MockTransport{nodeID: "self"}, nil)
+	if err := next.SetPinStore(store); err != nil {
+		t.Fatalf("restore failed: %v", err)
+	}
+	pins := next.GetPins()
+	if len(pins.Chunks) != 1 || pins.Chunks[0].Replicas != next.config.Pinning.MaxReplicas {
+		t.Fatalf("expected capped chunk pin restored, got %+v", pins.Chunks)
+	}
+	if len(pins.Namespaces) != 1 || pins.Namespaces[0].Namespace != "tenant" {
+		t.Fatalf("expected namespace pin restored, got %+v", pins.Namespaces)
+	}
+}
@@ -0,0 +1,258 @@
+package mesh
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrPowerConstrained is returned for compute offered to a node that is on
+// battery or thermally throttled.
+var ErrPowerConstrained = errors.New("node is on battery or thermally throttled")
+
+// powerSavingCapability is advertised while the node is power constrained,
+// so peers rank it below unconstrained ones.
+const powerSavingCapability = "power-saving"
+
+// PowerStatus is the node's power state and what it has throttled.
+type PowerStatus struct {
+	BatteryKnown   bool          `json:"battery_known"`
+	Charging       bool          `json:"charging"`
+	BatteryLevel   float64       `json:"battery_level"` // 0-1
+	OnBattery      bool          `json:"on_battery"`    // Discharging at or below Power.BatteryThreshold
+	Thermal        bool          `json:"thermal"`       // Frame latency suggests the device is throttling
+	FrameLatency   time.Duration `json:"frame_latency"` // Smoothed
+	BaseLatency    time.Duration `json:"base_latency"`  // Lowest smoothed latency seen
+	Constrained    bool          `json:"constrained"`
+	GossipFanout   int           `json:"gossip_fanout"`
+	DeclinedJobs   uint64        `json:"declined_jobs"`
+	ConstrainedFor time.Duration `json:"constrained_for,omitempty"`
+}
+
+// frameLatencyReporter is implemented by the SAB bridge, which measures the
+// time between physics frames.
+type frameLatencyReporter interface {
+	GetFrameLatency() time.Duration
+}
+
+type powerState struct {
+	mu sync.Mutex
+
+	batteryKnown bool
+	charging     bool
+	level        float64
+
+	latency     float64 // Smoothed frame latency, ns
+	baseline    float64 // Lowest smoothed latency seen, drifting up slowly, ns
+	hotSamples  int
+	coolSamples int
+	thermal     bool
+
+	constrained      bool
+	constrainedSince time.Time
+	baseFanout       int // Fanout the role asked for; 0 until first set
+	declined         uint64
+}
+
+// SetBatteryStatus records the host's Battery Status API reading. level is
+// 0-1. Discharging at or below Power.BatteryThreshold makes the node power
+// constrained.
+func (m *MeshCoordinator) SetBatteryStatus(charging bool, level float64) {
+	m.power.mu.Lock()
+	m.power.batteryKnown = true
+	m.power.charging = charging
+	m.power.level = min(max(level, 0), 1)
+	m.power.mu.Unlock()
+	m.applyPowerState()
+}
+
+// GetPowerStatus returns the power state and the throttling in effect.
+func (m *MeshCoordinator) GetPowerStatus() PowerStatus {
+	p := &m.power
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	status := PowerStatus{
+		BatteryKnown: p.batteryKnown,
+		Charging:     p.charging,
+		BatteryLevel: p.level,
+		OnBattery:    m.onBatteryLocked(),
+		Thermal:      p.thermal,
+		FrameLatency: time.Duration(p.latency),
+		BaseLatency:  time.Duration(p.baseline),
+		Constrained:  p.constrained,
+		DeclinedJobs: p.declined,
+	}
+	if m.gossip != nil {
+		status.GossipFanout = m.gossip.Fanout()
+	}
+	if p.constrained {
+		status.ConstrainedFor = time.Since(p.constrainedSince)
+	}
+	return status
+}
+
+// PowerConstrained reports whether the node is on battery or thermally
+// throttled.
+func (m *MeshCoordinator) PowerConstrained() bool {
+	m.power.mu.Lock()
+	defer m.power.mu.Unlock()
+	return m.power.constrained
+}
+
+func (m *MeshCoordinator) onBatteryLocked() bool {
+	p := &m.power
+	return p.batteryKnown && !p.charging && p.level <= m.config.Power.BatteryThreshold
+}
+
+// admitRemoteCompute declines incoming jobs the role profile does not offer,
+// or any while the node is power constrained.
+func (m *MeshCoordinator) admitRemoteCompute() error {
+	if err := m.admitProfileCompute(); err != nil {
+		return err
+	}
+	if !m.config.Power.DeclineJobs {
+		return nil
+	}
+	m.power.mu.Lock()
+	defer m.power.mu.Unlock()
+	if !m.power.constrained {
+		return nil
+	}
+	m.power.declined++
+	return ErrPowerConstrained
+}
+
+// sampleFrameLatency folds one frame latency reading into the thermal
+// heuristic. Browsers slow a hot device's frames well past what the same
+// page achieved when cool, so a smoothed latency that stays above
+// Power.ThermalRatio times the best seen is read as thermal throttling.
+func (m *MeshCoordinator) sampleFrameLatency(latency time.Duration) {
+	if latency <= 0 {
+		return
+	}
+	cfg := m.config.Power
+	p := &m.power
+	p.mu.Lock()
+	if p.latency == 0 {
+		p.latency = float64(latency)
+		p.baseline = p.latency
+	} else {
+		p.latency = 0.7*p.latency + 0.3*float64(latency)
+	}
+	// Let the baseline creep towards the current latency so one unusually
+	// fast stretch does not mark the device hot forever.
+	p.baseline = min(p.latency, p.baseline+0.002*(p.latency-p.baseline))
+
+	hot := p.latency >= cfg.ThermalRatio*p.baseline
+	if hot {
+		p.hotSamples++
+		p.coolSamples = 0
+	} else {
+		p.coolSamples++
+		p.hotSamples = 0
+	}
+	changed := false
+	switch {
+	case !p.thermal && p.hotSamples >= cfg.ThermalSamples:
+		p.thermal, changed = true, true
+	case p.thermal && p.coolSamples >= cfg.ThermalSamples:
+		p.thermal, changed = false, true
+	}
+	p.mu.Unlock()
+
+	if changed {
+		m.applyPowerState()
+	}
+}
+
+// applyPowerState re-evaluates whether the node is constrained and, on a
+// change, lowers or restores gossip fanout, re-advertises the node's
+// capability and tells the delegation engine.
+func (m *MeshCoordinator) applyPowerState() {
+	p := &m.power
+	p.mu.Lock()
+	onBattery := m.onBatteryLocked()
+	constrained := onBattery || p.thermal
+	if constrained == p.constrained {
+		p.mu.Unlock()
+		return
+	}
+	p.constrained = constrained
+	if constrained {
+		p.constrainedSince = time.Now()
+	}
+	thermal, level, charging := p.thermal, p.level, p.charging
+	p.mu.Unlock()
+
+	m.applyGossipFanout()
+	m.decider.SetPowerConstrained(constrained)
+	m.advertiseDHTMode()
+
+	m.logger.Info("power state changed",
+		"constrained", constrained,
+		"on_battery", onBattery,
+		"thermal", thermal,
+		"battery_level", level,
+	)
+	m.publishEvent(MeshEventPowerChanged, "", map[string]interface{}{
+		"constrained":   constrained,
+		"on_battery":    onBattery,
+		"charging":      charging,
+		"battery_level": level,
+		"thermal":       thermal,
+	})
+}
+
+// setGossipFanout sets the fanout the node wants when unconstrained.
+func (m *MeshCoordinator) setGossipFanout(fanout int) {
+	m.power.mu.Lock()
+	m.power.baseFanout = fanout
+	m.power.mu.Unlock()
+	m.applyGossipFanout()
+}
+
+// applyGossipFanout scales the base fanout by Power.FanoutFactor while
+// constrained.
+func (m *MeshCoordinator) applyGossipFanout() {
+	if m.gossip == nil {
+		return
+	}
+	p := &m.power
+	p.mu.Lock()
+	if p.baseFanout == 0 {
+		p.baseFanout = m.gossip.Fanout()
+	}
+	fanout := p.baseFanout
+	if p.constrained && m.config.Power.FanoutFactor > 0 {
+		fanout = max(int(float64(fanout)*m.config.Power.FanoutFactor), 1)
+	}
+	p.mu.Unlock()
+	if fanout != m.gossip.Fanout() {
+		m.gossip.SetFanout(fanout)
+	}
+}
+
+// powerLoop samples the bridge's frame latency for the thermal heuristic.
+func (m *MeshCoordinator) powerLoop() {
+	if m.config.Power.SampleInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(m.config.Power.SampleInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			// Hidden pages get no frames; their latency says nothing
+			if m.background.Load() {
+				continue
+			}
+			if reporter, ok := unwrapSAB(m.bridge).(frameLatencyReporter); ok {
+				m.sampleFrameLatency(reporter.GetFrameLatency())
+			}
+		case <-m.shutdown:
+			return
+		}
+	}
+}
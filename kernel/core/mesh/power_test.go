@@ -0,0 +1,82 @@
+package mesh
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPower_BatteryThrottlesParticipation(t *testing.T) {
+	coord, tr := newDelegationTestCoordinator(t)
+	coord.setGossipFanout(6)
+
+	coord.SetBatteryStatus(true, 0.2)
+	if coord.PowerConstrained() {
+		t.Fatal("expected a charging device not to be constrained")
+	}
+
+	coord.SetBatteryStatus(false, 0.8)
+	status := coord.GetPowerStatus()
+	if !status.Constrained || !status.OnBattery || status.GossipFanout != 3 {
+		t.Fatalf("expected a discharging device to halve fanout, got %+v", status)
+	}
+
+	job := json.RawMessage(`{"id":"job-1","operation":"hash"}`)
+	if _, err := tr.registeredRPCHandlers[executeJobMethod](capabilityContextFor(t, coord, "peer-1", executeJobMethod), "peer-1", job); !errors.Is(err, ErrPowerConstrained) {
+		t.Fatalf("expected ErrPowerConstrained, got %v", err)
+	}
+	if status := coord.GetPowerStatus(); status.DeclinedJobs != 1 {
+		t.Fatalf("expected one declined job, got %d", status.DeclinedJobs)
+	}
+	if !coord.decider.powerConstrained {
+		t.Fatal("expected the delegation engine to know the device is constrained")
+	}
+
+	coord.SetBatteryStatus(true, 0.8)
+	if status := coord.GetPowerStatus(); status.Constrained || status.GossipFanout != 6 {
+		t.Fatalf("expected fanout restored once charging, got %+v", status)
+	}
+}
+
+func TestPower_ThermalHeuristicFollowsFrameLatency(t *testing.T) {
+	coord := NewMeshCoordinator("self", "us-east", &MockTransport{nodeID: "self"}, nil)
+	coord.config.Power.ThermalSamples = 3
+
+	for i := 0; i < 5; i++ {
+		coord.sampleFrameLatency(16 * time.Millisecond)
+	}
+	if coord.GetPowerStatus().Thermal {
+		t.Fatal("expected steady frames not to read as thermal throttling")
+	}
+
+	// A single slow frame is not enough.
+	coord.sampleFrameLatency(80 * time.Millisecond)
+	coord.sampleFrameLatency(16 * time.Millisecond)
+	if coord.PowerConstrained() {
+		t.Fatal("expected a lone slow frame to be ignored")
+	}
+
+	for i := 0; i < 6; i++ {
+		coord.sampleFrameLatency(50 * time.Millisecond)
+	}
+	if status := coord.GetPowerStatus(); !status.Thermal || !status.Constrained {
+		t.Fatalf("expected sustained slow frames to read as throttling, got %+v", status)
+	}
+
+	for i := 0; i < 10; i++ {
+		coord.sampleFrameLatency(16 * time.Millisecond)
+	}
+	if coord.PowerConstrained() {
+		t.Fatal("expected recovery once frames are fast again")
+	}
+}
+
+func TestPower_PowerSavingPeersScoreLower(t *testing.T) {
+	coord := NewMeshCoordinator("self", "us-east", &MockTransport{nodeID: "self"}, nil)
+	normal := coord.calculatePeerScore(&PeerCapability{PeerID: "a", LatencyMs: 20, LastSeen: time.Now().UnixNano()})
+	saving := coord.calculatePeerScore(&PeerCapability{PeerID: "a", LatencyMs: 20, LastSeen: time.Now().UnixNano(), Capabilities: []string{powerSavingCapability}})
+	if saving >= normal {
+		t.Fatalf("expected a power-saving peer to score lower: %v >= %v", saving, normal)
+	}
+}
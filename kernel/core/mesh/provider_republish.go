@@ -0,0 +1,124 @@
+package mesh
+
+import (
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// maxRepublishJitter bounds the jitter fraction so a pass is never more
+// than half an interval late.
+const maxRepublishJitter = 0.5
+
+// providerRepublishLoop renews the provider records of every chunk this
+// node holds before they expire. Hot chunks are renewed early by the chunk
+// TTL loop; this pass is what keeps cold ones findable.
+func (m *MeshCoordinator) providerRepublishLoop() {
+	if m.config.ChunkTTL.RepublishInterval <= 0 {
+		return
+	}
+
+	timer := time.NewTimer(m.nextRepublishDelay())
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			if !m.deferLowPriority() {
+				m.republishProviderRecords(time.Now())
+			}
+			timer.Reset(m.nextRepublishDelay())
+		case <-m.shutdown:
+			return
+		}
+	}
+}
+
+// nextRepublishDelay spreads passes by up to RepublishJitter of the
+// interval either way, so nodes that started together do not all
+// republish at once.
+func (m *MeshCoordinator) nextRepublishDelay() time.Duration {
+	interval := m.config.ChunkTTL.RepublishInterval
+	jitter := m.republishJitter()
+	if jitter == 0 {
+		return interval
+	}
+	return interval + time.Duration((rand.Float64()*2-1)*jitter*float64(interval))
+}
+
+func (m *MeshCoordinator) republishJitter() float64 {
+	return min(max(m.config.ChunkTTL.RepublishJitter, 0), maxRepublishJitter)
+}
+
+// dueProviderRecords returns the held chunks whose record is missing or
+// would expire before a pass two intervals out, soonest expiry first. The
+// second interval covers a pass skipped under frame pressure.
+func (m *MeshCoordinator) dueProviderRecords(now time.Time) []string {
+	horizon := 2 * time.Duration(float64(m.config.ChunkTTL.RepublishInterval)*(1+m.republishJitter()))
+
+	type due struct {
+		chunk   string
+		expires time.Time
+	}
+	var pending []due
+
+	m.localChunksMu.RLock()
+	m.chunkRecordsMu.Lock()
+	for chunkHash := range m.localChunks {
+		record, ok := m.chunkRecords[chunkHash]
+		if !ok {
+			pending = append(pending, due{chunk: chunkHash})
+			continue
+		}
+		if expires := record.announced.Add(record.ttl); expires.Sub(now) < horizon {
+			pending = append(pending, due{chunk: chunkHash, expires: expires})
+		}
+	}
+	m.chunkRecordsMu.Unlock()
+	m.localChunksMu.RUnlock()
+
+	sort.Slice(pending, func(i, j int) bool {
+		if pending[i].expires.Equal(pending[j].expires) {
+			return pending[i].chunk < pending[j].chunk
+		}
+		return pending[i].expires.Before(pending[j].expires)
+	})
+	out := make([]string, len(pending))
+	for i, p := range pending {
+		out[i] = p.chunk
+	}
+	return out
+}
+
+// republishProviderRecords re-announces due records in batches of
+// RepublishBatch, pausing RepublishPause between batches so a node holding
+// many chunks does not flood the DHT. It returns the records renewed.
+func (m *MeshCoordinator) republishProviderRecords(now time.Time) int {
+	due := m.dueProviderRecords(now)
+	batch := m.config.ChunkTTL.RepublishBatch
+	if batch <= 0 {
+		batch = len(due)
+	}
+
+	renewed := 0
+	for i, chunkHash := range due {
+		if i > 0 && i%batch == 0 && m.config.ChunkTTL.RepublishPause > 0 {
+			select {
+			case <-time.After(m.config.ChunkTTL.RepublishPause):
+			case <-m.shutdown:
+				m.chunkTTL.renewed.Add(uint64(renewed))
+				return renewed
+			}
+		}
+		if err := m.storeChunkRecord(chunkHash, m.nodeID); err != nil {
+			m.logger.Debug("provider record renewal failed", "chunk", getShortID(chunkHash), "error", err)
+			continue
+		}
+		renewed++
+	}
+	m.chunkTTL.renewed.Add(uint64(renewed))
+	if renewed > 0 {
+		m.logger.Debug("renewed provider records", "count", renewed, "due", len(due))
+	}
+	return renewed
+}
@@ -0,0 +1,326 @@
+package mesh
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+	"github.com/nmxmxh/inos_v1/kernel/threads/sab"
+)
+
+// ErrPublishAborted is returned when a transactional publish rolled back.
+var ErrPublishAborted = errors.New("publish aborted")
+
+// PublishChunk is one data chunk of a transactional publish.
+type PublishChunk struct {
+	Hash string
+	Data []byte
+}
+
+// PublishTransaction describes a multi-chunk object. The manifest is itself
+// a chunk and is only announced once every data chunk is replicated, so no
+// peer can discover a manifest whose chunks are missing.
+type PublishTransaction struct {
+	Chunks       []PublishChunk
+	ManifestHash string
+	Manifest     []byte
+	MinReplicas  int // Peer acks required per chunk; 0 uses the config default
+}
+
+// ChunkPublishStatus reports the outcome for one chunk of a publish.
+type ChunkPublishStatus struct {
+	ChunkHash   string            `json:"chunk_hash"`
+	AckedPeers  []string          `json:"acked_peers"`
+	FailedPeers map[string]string `json:"failed_peers,omitempty"` // Peer ID to error
+	Error       string            `json:"error,omitempty"`
+}
+
+// PublishReport is the per-chunk result of a transactional publish.
+type PublishReport struct {
+	TxID         string               `json:"tx_id"`
+	ManifestHash string               `json:"manifest_hash"`
+	Committed    bool                 `json:"committed"`
+	Chunks       []ChunkPublishStatus `json:"chunks"`
+	RolledBack   int                  `json:"rolled_back"` // Staged replicas removed on abort
+	Duration     time.Duration        `json:"duration"`
+}
+
+// stagedTransaction records replicas a peer stored on this node under a
+// publish that has not committed yet.
+type stagedTransaction struct {
+	chunks  map[string]struct{}
+	created time.Time
+}
+
+// Publish stores a multi-chunk object all-or-nothing: every chunk and then
+// the manifest is staged on peers, and only when all of them reached
+// MinReplicas acks are the chunks announced, manifest last. On any failure
+// the staged replicas are removed and the report says which chunks failed.
+func (m *MeshCoordinator) Publish(ctx context.Context, tx PublishTransaction) (*PublishReport, error) {
+	if tx.ManifestHash == "" || len(tx.Manifest) == 0 {
+		return nil, errors.New("publish requires a manifest")
+	}
+	minReplicas := tx.MinReplicas
+	if minReplicas <= 0 {
+		minReplicas = m.config.Publish.MinReplicas
+	}
+
+	start := time.Now()
+	report := &PublishReport{TxID: newPublishTxID(), ManifestHash: tx.ManifestHash}
+
+	// Phase 1: stage data chunks, then the manifest
+	all := append(append([]PublishChunk{}, tx.Chunks...), PublishChunk{Hash: tx.ManifestHash, Data: tx.Manifest})
+	staged := make(map[string][]string) // Peer ID to chunk hashes
+	var localStaged []string
+	var failure error
+
+	for i, chunk := range all {
+		if failure != nil {
+			report.Chunks = append(report.Chunks, ChunkPublishStatus{ChunkHash: chunk.Hash, Error: "not attempted: transaction aborted"})
+			continue
+		}
+
+		status, isNew := m.stageChunk(ctx, report.TxID, chunk, minReplicas)
+		for _, peerID := range status.AckedPeers {
+			staged[peerID] = append(staged[peerID], chunk.Hash)
+		}
+		if isNew {
+			localStaged = append(localStaged, chunk.Hash)
+		}
+		if status.Error != "" {
+			failure = fmt.Errorf("chunk %d (%s): %s", i, getShortID(chunk.Hash), status.Error)
+		}
+		report.Chunks = append(report.Chunks, status)
+	}
+
+	if failure != nil {
+		report.RolledBack = m.rollbackPublish(report.TxID, staged, localStaged)
+		report.Duration = time.Since(start)
+		m.logger.Warn("publish rolled back",
+			"tx", report.TxID,
+			"manifest", getShortID(tx.ManifestHash),
+			"rolled_back", report.RolledBack,
+			"error", failure)
+		return report, fmt.Errorf("%w: %v", ErrPublishAborted, failure)
+	}
+
+	// Phase 2: commit. Announce data chunks first so the manifest never
+	// points at an undiscoverable chunk.
+	for i, chunk := range all {
+		m.commitChunk(chunk.Hash, report.Chunks[i].AckedPeers)
+	}
+	report.Committed = true
+	report.Duration = time.Since(start)
+
+	if m.bridge != nil {
+		m.bridge.SignalEpoch(sab.IDX_DELEGATED_CHUNK_EPOCH)
+	}
+
+	m.logger.Info("publish committed",
+		"tx", report.TxID,
+		"manifest", getShortID(tx.ManifestHash),
+		"chunks", len(tx.Chunks),
+		"duration", report.Duration)
+	return report, nil
+}
+
+// stageChunk stores a chunk locally and on peers without announcing it. It
+// reports whether the local copy is new, so rollback never deletes a chunk
+// this node already held.
+func (m *MeshCoordinator) stageChunk(ctx context.Context, txID string, chunk PublishChunk, minReplicas int) (ChunkPublishStatus, bool) {
+	status := ChunkPublishStatus{ChunkHash: chunk.Hash, AckedPeers: []string{}}
+
+	localNew := false
+	if m.storage != nil {
+		if held, _ := m.storage.HasChunk(ctx, chunk.Hash); !held {
+			if err := m.storage.StoreChunk(ctx, chunk.Hash, chunk.Data); err != nil {
+				status.Error = fmt.Sprintf("local store: %v", err)
+				return status, false
+			}
+			localNew = true
+			m.trackStoredChunk(ctx, chunk.Hash, len(chunk.Data))
+		}
+	}
+
+	replicas := m.allocator.CalculateReplicas(common.Resource{
+		Size:        uint64(len(chunk.Data)),
+		Type:        "chunk",
+		DemandScore: m.demandTracker.GetDemandScore(chunk.Hash),
+	})
+	if replicas < minReplicas {
+		replicas = minReplicas
+	}
+	scored := m.scorePeers(m.dht.FindNode(chunk.Hash))
+	selected := scored[:minInt(replicas, len(scored))]
+
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+	)
+	for _, peer := range selected {
+		wg.Add(1)
+		go func(peerID string) {
+			defer wg.Done()
+			err := m.storeChunkOnPeer(ctx, peerID, chunk.Hash, txID, chunk.Data)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if status.FailedPeers == nil {
+					status.FailedPeers = make(map[string]string)
+				}
+				status.FailedPeers[peerID] = err.Error()
+				return
+			}
+			status.AckedPeers = append(status.AckedPeers, peerID)
+		}(peer.ID)
+	}
+	wg.Wait()
+
+	if len(status.AckedPeers) < minReplicas {
+		status.Error = fmt.Sprintf("%d of %d required replica acks", len(status.AckedPeers), minReplicas)
+	}
+	return status, localNew
+}
+
+// commitChunk makes a staged chunk discoverable.
+func (m *MeshCoordinator) commitChunk(chunkHash string, peers []string) {
+	if m.storage != nil {
+		m.localChunksMu.Lock()
+		m.localChunks[chunkHash] = struct{}{}
+		m.localChunksMu.Unlock()
+	}
+	if err := m.storeChunkRecord(chunkHash, m.nodeID); err != nil {
+		m.logger.Warn("failed to store in DHT", "error", err)
+	}
+	if len(peers) > 0 {
+		m.chunkCache.Put(chunkHash, peers, 1.0)
+	}
+	m.announceChunkOrQueue(chunkHash)
+}
+
+// rollbackPublish asks every peer that acked a staged replica to drop it and
+// removes the chunks this node stored for the transaction. It returns how
+// many replicas were removed.
+func (m *MeshCoordinator) rollbackPublish(txID string, staged map[string][]string, localStaged []string) int {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	removed := 0
+	for peerID, hashes := range staged {
+		var resp ChunkAbortResponse
+		if err := m.transport.SendRPC(ctx, peerID, chunkAbortMethod, ChunkAbortRequest{TxID: txID}, &resp); err != nil {
+			// The replica was never announced, so it stays undiscoverable;
+			// the peer forgets the staged entry after StagedTTL.
+			m.logger.Warn("failed to abort staged replicas",
+				"peer", getShortID(peerID),
+				"chunks", len(hashes),
+				"error", err)
+			continue
+		}
+		removed += resp.Removed
+	}
+
+	if m.storage != nil {
+		for _, hash := range localStaged {
+			if err := m.storage.DeleteChunk(ctx, hash); err != nil {
+				m.logger.Warn("failed to remove staged chunk", "chunk", getShortID(hash), "error", err)
+				continue
+			}
+			m.storageQuota.Forget(hash)
+			m.forgetChunkACL(hash)
+			m.releaseInboundChunk(hash)
+			removed++
+		}
+	}
+	return removed
+}
+
+// stageReplica records that peerID stored chunkHash on this node under
+// txID. Chunks this node already held are not recorded, so an abort never
+// removes data that predates the transaction.
+func (m *MeshCoordinator) stageReplica(ctx context.Context, peerID, txID, chunkHash string) {
+	if m.storage != nil {
+		if held, _ := m.storage.HasChunk(ctx, chunkHash); held {
+			return
+		}
+	}
+
+	m.stagedMu.Lock()
+	defer m.stagedMu.Unlock()
+	m.pruneStagedLocked(time.Now())
+
+	key := peerID + "/" + txID
+	staged, ok := m.stagedReplicas[key]
+	if !ok {
+		staged = &stagedTransaction{chunks: make(map[string]struct{}), created: time.Now()}
+		m.stagedReplicas[key] = staged
+	}
+	staged.chunks[chunkHash] = struct{}{}
+}
+
+// pruneStagedLocked forgets transactions older than StagedTTL; their
+// replicas become ordinary chunks.
+func (m *MeshCoordinator) pruneStagedLocked(now time.Time) {
+	for key, staged := range m.stagedReplicas {
+		if now.Sub(staged.created) > m.config.Publish.StagedTTL {
+			delete(m.stagedReplicas, key)
+		}
+	}
+}
+
+// abortStaged removes the replicas peerID staged under txID.
+func (m *MeshCoordinator) abortStaged(ctx context.Context, peerID, txID string) int {
+	m.stagedMu.Lock()
+	m.pruneStagedLocked(time.Now())
+	key := peerID + "/" + txID
+	staged := m.stagedReplicas[key]
+	delete(m.stagedReplicas, key)
+	m.stagedMu.Unlock()
+
+	if staged == nil || m.storage == nil {
+		return 0
+	}
+
+	removed := 0
+	for hash := range staged.chunks {
+		if err := m.storage.DeleteChunk(ctx, hash); err != nil {
+			m.logger.Debug("failed to remove aborted replica", "chunk", getShortID(hash), "error", err)
+			continue
+		}
+		m.localChunksMu.Lock()
+		delete(m.localChunks, hash)
+		m.localChunksMu.Unlock()
+		_ = m.dht.RemoveChunkPeer(hash, m.nodeID)
+		m.storageQuota.Forget(hash)
+		m.forgetChunkACL(hash)
+		m.releaseInboundChunk(hash)
+		removed++
+	}
+	return removed
+}
+
+func (m *MeshCoordinator) registerPublishHandlers() {
+	m.registerRPC(chunkAbortMethod, func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error) {
+		var req ChunkAbortRequest
+		if err := json.Unmarshal(args, &req); err != nil {
+			return nil, fmt.Errorf("failed to decode chunk.abort request: %w", err)
+		}
+		if req.TxID == "" {
+			return nil, errors.New("missing tx_id")
+		}
+		return ChunkAbortResponse{Removed: m.abortStaged(ctx, peerID, req.TxID)}, nil
+	})
+}
+
+func newPublishTxID() string {
+	buf := make([]byte, 12)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
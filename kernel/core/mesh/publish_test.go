@@ -0,0 +1,142 @@
+package mesh
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+)
+
+func newPublishTestCoordinator(t *testing.T, rejectChunk string) (*MeshCoordinator, *MockStorage, *sync.Map) {
+	t.Helper()
+	aborts := &sync.Map{}
+	tr := &MockTransport{
+		nodeID: "self",
+		rpcHandlers: map[string]func(args interface{}) (interface{}, error){
+			chunkStoreMethod: func(args interface{}) (interface{}, error) {
+				req := args.(ChunkStoreRequest)
+				if req.TxID == "" {
+					return nil, errors.New("expected a staged store")
+				}
+				if req.ChunkHash == rejectChunk {
+					return ChunkStoreResponse{Stored: false}, nil
+				}
+				return ChunkStoreResponse{Stored: true, Size: req.RawSize}, nil
+			},
+			chunkAbortMethod: func(args interface{}) (interface{}, error) {
+				aborts.Store(args.(ChunkAbortRequest).TxID, true)
+				return ChunkAbortResponse{Removed: 1}, nil
+			},
+		},
+	}
+
+	coord := NewMeshCoordinator("self", "us-east", tr, nil)
+	storage := &MockStorage{chunks: make(map[string][]byte)}
+	coord.SetStorage(storage)
+	for _, id := range []string{"peer-1", "peer-2"} {
+		coord.dht.AddPeer(common.PeerInfo{ID: id, Capabilities: &common.PeerCapability{PeerID: id, Reputation: 0.9}})
+	}
+	return coord, storage, aborts
+}
+
+func TestPublish_CommitsManifestLast(t *testing.T) {
+	coord, storage, _ := newPublishTestCoordinator(t, "")
+
+	report, err := coord.Publish(context.Background(), PublishTransaction{
+		Chunks:       []PublishChunk{{Hash: "c1", Data: []byte("one")}, {Hash: "c2", Data: []byte("two")}},
+		ManifestHash: "m1",
+		Manifest:     []byte(`["c1","c2"]`),
+	})
+	if err != nil {
+		t.Fatalf("publish failed: %v", err)
+	}
+	if !report.Committed || len(report.Chunks) != 3 || report.Chunks[2].ChunkHash != "m1" {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	for _, hash := range []string{"c1", "c2", "m1"} {
+		if _, ok := storage.chunks[hash]; !ok {
+			t.Fatalf("chunk %s not stored locally", hash)
+		}
+		coord.localChunksMu.RLock()
+		_, announced := coord.localChunks[hash]
+		coord.localChunksMu.RUnlock()
+		if !announced {
+			t.Fatalf("chunk %s not committed", hash)
+		}
+	}
+}
+
+func TestPublish_RollsBackOnMissingAcks(t *testing.T) {
+	coord, storage, aborts := newPublishTestCoordinator(t, "c2")
+	storage.chunks["c1"] = []byte("one") // Held before the publish
+
+	report, err := coord.Publish(context.Background(), PublishTransaction{
+		Chunks: []PublishChunk{
+			{Hash: "c1", Data: []byte("one")},
+			{Hash: "c2", Data: []byte("two")},
+			{Hash: "c3", Data: []byte("three")},
+		},
+		ManifestHash: "m1",
+		Manifest:     []byte(`["c1","c2","c3"]`),
+		MinReplicas:  1,
+	})
+	if !errors.Is(err, ErrPublishAborted) {
+		t.Fatalf("expected aborted publish, got %v", err)
+	}
+	if report.Committed {
+		t.Fatal("aborted publish reported as committed")
+	}
+
+	if report.Chunks[0].Error != "" || len(report.Chunks[0].AckedPeers) != 2 {
+		t.Fatalf("c1 should have staged on both peers: %+v", report.Chunks[0])
+	}
+	if report.Chunks[1].Error == "" || len(report.Chunks[1].FailedPeers) != 2 {
+		t.Fatalf("c2 should report both peer failures: %+v", report.Chunks[1])
+	}
+	if report.Chunks[2].Error == "" || report.Chunks[3].ChunkHash != "m1" || report.Chunks[3].Error == "" {
+		t.Fatalf("remaining chunks and manifest should be skipped: %+v", report.Chunks[2:])
+	}
+
+	if _, ok := aborts.Load(report.TxID); !ok {
+		t.Fatal("peers were not asked to abort staged replicas")
+	}
+	if _, ok := storage.chunks["c1"]; !ok {
+		t.Fatal("rollback removed a chunk held before the publish")
+	}
+	if _, ok := storage.chunks["c2"]; ok {
+		t.Fatal("rollback left the staged local chunk behind")
+	}
+	coord.localChunksMu.RLock()
+	defer coord.localChunksMu.RUnlock()
+	if len(coord.localChunks) != 0 {
+		t.Fatalf("aborted publish announced chunks: %v", coord.localChunks)
+	}
+}
+
+func TestPublish_AbortRemovesOnlyCallersStagedReplicas(t *testing.T) {
+	tr := &MockTransport{nodeID: "self"}
+	coord := NewMeshCoordinator("self", "us-east", tr, nil)
+	storage := &MockStorage{chunks: map[string][]byte{"held": []byte("hello")}}
+	coord.SetStorage(storage)
+
+	callConformant(t, tr, chunkStoreMethod, "origin", `{"chunk_hash":"new","data":"aGVsbG8=","tx_id":"tx1"}`)
+	callConformant(t, tr, chunkStoreMethod, "origin", `{"chunk_hash":"held","data":"aGVsbG8=","tx_id":"tx1"}`)
+
+	callConformant(t, tr, chunkAbortMethod, "intruder", `{"tx_id":"tx1"}`)
+	if _, ok := storage.chunks["new"]; !ok {
+		t.Fatal("another peer aborted the origin's transaction")
+	}
+
+	raw := callConformant(t, tr, chunkAbortMethod, "origin", `{"tx_id":"tx1"}`)
+	if string(raw) != `{"removed":1}` {
+		t.Fatalf("unexpected abort response %s", raw)
+	}
+	if _, ok := storage.chunks["new"]; ok {
+		t.Fatal("staged replica survived abort")
+	}
+	if _, ok := storage.chunks["held"]; !ok {
+		t.Fatal("abort removed a chunk held before staging")
+	}
+}
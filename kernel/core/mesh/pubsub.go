@@ -0,0 +1,476 @@
+package mesh
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+	"github.com/yasserelgammal/rate-limiter/limiter"
+	"github.com/yasserelgammal/rate-limiter/store"
+)
+
+const (
+	pubsubGossipTopic   = "pubsub.message"
+	pubsubHistoryMethod = "pubsub.history"
+
+	// MaxTopicNameLength bounds application topic names.
+	MaxTopicNameLength = 128
+)
+
+var (
+	ErrInvalidTopic         = errors.New("pubsub: invalid topic name")
+	ErrTopicPayloadTooLarge = errors.New("pubsub: payload exceeds topic limit")
+	ErrTopicRateLimited     = errors.New("pubsub: topic rate limit exceeded")
+)
+
+// TopicOptions tunes one application topic. Zero fields fall back to the
+// PubSub config; Retain 0 keeps nothing for late joiners.
+type TopicOptions struct {
+	MaxPayload    int           `json:"max_payload"`     // Largest message accepted, in bytes
+	RatePerSecond int           `json:"rate_per_second"` // Messages per publisher per second
+	Burst         int           `json:"burst"`
+	Retain        int           `json:"retain"`     // Recent messages kept and served to late subscribers
+	RetainTTL     time.Duration `json:"retain_ttl"` // Age after which a retained message is dropped; 0 keeps it until displaced
+}
+
+// TopicMessage is one application message on a topic.
+type TopicMessage struct {
+	ID          string `json:"id"`
+	Topic       string `json:"topic"`
+	Publisher   string `json:"publisher"`
+	Data        []byte `json:"data"`
+	PublishedAt int64  `json:"published_at"` // Unix milliseconds
+}
+
+// TopicSubscription is a local subscriber's handle. Backlog counts the
+// retained messages already waiting in its inbox.
+type TopicSubscription struct {
+	ID      string `json:"id"`
+	Topic   string `json:"topic"`
+	Backlog int    `json:"backlog"`
+}
+
+type topicHistoryRequest struct {
+	Topic string `json:"topic"`
+	Since int64  `json:"since,omitempty"` // Unix milliseconds
+}
+
+type topicHistoryResponse struct {
+	Messages []TopicMessage `json:"messages"`
+}
+
+type topicState struct {
+	opts     TopicOptions
+	limiter  *limiter.TokenBucket
+	store    *store.MemoryStore
+	retained []TopicMessage
+}
+
+type topicSubscriber struct {
+	id      string
+	topic   string
+	inbox   []TopicMessage
+	dropped uint64
+}
+
+// validTopic accepts printable names without whitespace.
+func validTopic(topic string) bool {
+	if topic == "" || len(topic) > MaxTopicNameLength {
+		return false
+	}
+	for _, r := range topic {
+		if r <= ' ' || r == 0x7f {
+			return false
+		}
+	}
+	return true
+}
+
+// topicOptions merges a topic's options with the configured defaults.
+func (m *MeshCoordinator) topicOptions(opts TopicOptions) TopicOptions {
+	if opts.MaxPayload <= 0 {
+		opts.MaxPayload = m.config.PubSub.MaxPayload
+	}
+	if opts.RatePerSecond <= 0 {
+		opts.RatePerSecond = m.config.PubSub.RatePerSecond
+	}
+	if opts.Burst <= 0 {
+		opts.Burst = m.config.PubSub.Burst
+	}
+	if opts.Burst < opts.RatePerSecond {
+		opts.Burst = opts.RatePerSecond
+	}
+	return opts
+}
+
+func newTopicState(opts TopicOptions) (*topicState, error) {
+	rate := int64(opts.RatePerSecond)
+	if rate <= 0 {
+		rate = 1
+	}
+	burst := int64(opts.Burst)
+	if burst <= 0 {
+		burst = rate
+	}
+	s := store.NewMemoryStore(time.Minute)
+	tb, err := limiter.NewTokenBucket(limiter.Config{Rate: rate, Duration: time.Second, Burst: burst}, s)
+	if err != nil {
+		s.Close()
+		return nil, err
+	}
+	return &topicState{opts: opts, limiter: tb, store: s}, nil
+}
+
+// topic returns the state for a topic, creating it with default options on
+// first use. The caller holds topicsMu.
+func (m *MeshCoordinator) topic(name string) (*topicState, error) {
+	if state, ok := m.topics[name]; ok {
+		return state, nil
+	}
+	state, err := newTopicState(m.topicOptions(TopicOptions{}))
+	if err != nil {
+		return nil, err
+	}
+	m.topics[name] = state
+	return state, nil
+}
+
+// ConfigureTopic sets a topic's limits and retention. Retained messages
+// are kept, trimmed to the new bound.
+func (m *MeshCoordinator) ConfigureTopic(topic string, opts TopicOptions) error {
+	if !validTopic(topic) {
+		return ErrInvalidTopic
+	}
+	state, err := newTopicState(m.topicOptions(opts))
+	if err != nil {
+		return err
+	}
+
+	m.topicsMu.Lock()
+	defer m.topicsMu.Unlock()
+	if old, ok := m.topics[topic]; ok {
+		state.retained = old.retained
+		old.store.Close()
+	}
+	state.trimRetained(time.Now())
+	m.topics[topic] = state
+	return nil
+}
+
+// GetTopicOptions returns the effective options for a topic.
+func (m *MeshCoordinator) GetTopicOptions(topic string) TopicOptions {
+	m.topicsMu.Lock()
+	defer m.topicsMu.Unlock()
+	if state, ok := m.topics[topic]; ok {
+		return state.opts
+	}
+	return m.topicOptions(TopicOptions{})
+}
+
+// trimRetained drops expired messages and the oldest beyond the bound.
+func (s *topicState) trimRetained(now time.Time) {
+	if s.opts.Retain <= 0 {
+		s.retained = nil
+		return
+	}
+	if s.opts.RetainTTL > 0 {
+		cutoff := now.Add(-s.opts.RetainTTL).UnixMilli()
+		keep := s.retained[:0]
+		for _, msg := range s.retained {
+			if msg.PublishedAt >= cutoff {
+				keep = append(keep, msg)
+			}
+		}
+		s.retained = keep
+	}
+	if excess := len(s.retained) - s.opts.Retain; excess > 0 {
+		s.retained = append([]TopicMessage(nil), s.retained[excess:]...)
+	}
+}
+
+// admitTopicMessage applies the topic's size limit and the publisher's
+// rate limit.
+func (m *MeshCoordinator) admitTopicMessage(topic, publisher string, size int) error {
+	m.topicsMu.Lock()
+	state, err := m.topic(topic)
+	m.topicsMu.Unlock()
+	if err != nil {
+		return err
+	}
+	if size > state.opts.MaxPayload {
+		return fmt.Errorf("%w (%d > %d bytes)", ErrTopicPayloadTooLarge, size, state.opts.MaxPayload)
+	}
+	if !state.limiter.Allow(publisher) {
+		return ErrTopicRateLimited
+	}
+	return nil
+}
+
+// PublishTopic sends data to every subscriber of topic across the mesh,
+// including local ones. While offline the message is queued and sent on
+// reconnect.
+func (m *MeshCoordinator) PublishTopic(ctx context.Context, topic string, data []byte) (TopicMessage, error) {
+	if err := ctx.Err(); err != nil {
+		return TopicMessage{}, err
+	}
+	if !validTopic(topic) {
+		return TopicMessage{}, ErrInvalidTopic
+	}
+	if err := m.admitTopicMessage(topic, m.nodeID, len(data)); err != nil {
+		return TopicMessage{}, err
+	}
+
+	now := time.Now()
+	msg := TopicMessage{
+		ID:          fmt.Sprintf("%s:%d", m.nodeID, now.UnixNano()),
+		Topic:       topic,
+		Publisher:   m.nodeID,
+		Data:        append([]byte(nil), data...),
+		PublishedAt: now.UnixMilli(),
+	}
+	m.deliverTopicMessage(msg)
+
+	payload := map[string]interface{}{
+		"id":           msg.ID,
+		"topic":        msg.Topic,
+		"data":         base64.StdEncoding.EncodeToString(msg.Data),
+		"published_at": msg.PublishedAt,
+	}
+	if err := m.PublishOrQueue(pubsubGossipTopic, "pubsub:"+msg.ID, payload); err != nil {
+		return msg, err
+	}
+	return msg, nil
+}
+
+// deliverTopicMessage retains a message if the topic keeps history, queues
+// it for local subscribers and notifies them through the mesh event ring.
+func (m *MeshCoordinator) deliverTopicMessage(msg TopicMessage) {
+	m.topicsMu.Lock()
+	if state, err := m.topic(msg.Topic); err == nil && state.opts.Retain > 0 {
+		duplicate := false
+		for _, kept := range state.retained {
+			if kept.ID == msg.ID {
+				duplicate = true
+				break
+			}
+		}
+		if duplicate {
+			m.topicsMu.Unlock()
+			return
+		}
+		state.retained = append(state.retained, msg)
+		state.trimRetained(time.Now())
+	}
+	m.topicsMu.Unlock()
+
+	delivered := 0
+	m.topicSubsMu.Lock()
+	for _, sub := range m.topicSubs {
+		if sub.topic != msg.Topic {
+			continue
+		}
+		m.enqueueTopicMessage(sub, msg)
+		delivered++
+	}
+	m.topicSubsMu.Unlock()
+
+	if delivered > 0 {
+		m.publishEvent(MeshEventTopicPrefix+msg.Topic, msg.Publisher, map[string]interface{}{
+			"id":           msg.ID,
+			"size":         len(msg.Data),
+			"published_at": msg.PublishedAt,
+		})
+	}
+}
+
+// enqueueTopicMessage appends to a subscriber's inbox, dropping its oldest
+// message when full. The caller holds topicSubsMu.
+func (m *MeshCoordinator) enqueueTopicMessage(sub *topicSubscriber, msg TopicMessage) {
+	if limit := m.config.PubSub.InboxSize; limit > 0 && len(sub.inbox) >= limit {
+		sub.inbox = sub.inbox[1:]
+		sub.dropped++
+	}
+	sub.inbox = append(sub.inbox, msg)
+}
+
+// SubscribeTopic starts collecting messages on topic. Retained messages, local
+// or fetched from connected peers, are waiting in the inbox straight away.
+func (m *MeshCoordinator) SubscribeTopic(ctx context.Context, topic string) (TopicSubscription, error) {
+	if !validTopic(topic) {
+		return TopicSubscription{}, ErrInvalidTopic
+	}
+
+	m.topicsMu.Lock()
+	state, err := m.topic(topic)
+	var backlog []TopicMessage
+	retain := 0
+	if err == nil {
+		state.trimRetained(time.Now())
+		backlog = append(backlog, state.retained...)
+		retain = state.opts.Retain
+	}
+	m.topicsMu.Unlock()
+	if err != nil {
+		return TopicSubscription{}, err
+	}
+
+	if retain > 0 && len(backlog) == 0 {
+		peers := m.transport.GetConnectedPeers()
+		if n := m.config.PubSub.HistoryPeers; n > 0 && len(peers) > n {
+			peers = peers[:n]
+		}
+		if fetched := m.fetchTopicHistory(ctx, topic, peers); len(fetched) > 0 {
+			for _, msg := range fetched {
+				m.deliverTopicMessage(msg)
+			}
+			m.topicsMu.Lock()
+			backlog = append([]TopicMessage(nil), m.topics[topic].retained...)
+			m.topicsMu.Unlock()
+		}
+	}
+
+	sub := &topicSubscriber{
+		id:    fmt.Sprintf("topic_sub_%d", time.Now().UnixNano()),
+		topic: topic,
+	}
+	m.topicSubsMu.Lock()
+	for _, msg := range backlog {
+		m.enqueueTopicMessage(sub, msg)
+	}
+	m.topicSubs[sub.id] = sub
+	m.topicSubsMu.Unlock()
+
+	return TopicSubscription{ID: sub.id, Topic: topic, Backlog: len(sub.inbox)}, nil
+}
+
+// UnsubscribeTopic drops a subscription and anything left in its inbox.
+func (m *MeshCoordinator) UnsubscribeTopic(id string) bool {
+	m.topicSubsMu.Lock()
+	defer m.topicSubsMu.Unlock()
+	if _, ok := m.topicSubs[id]; !ok {
+		return false
+	}
+	delete(m.topicSubs, id)
+	return true
+}
+
+// ReadTopic drains up to max messages from a subscription's inbox, oldest
+// first, and reports how many it has dropped to overflow since the last
+// read.
+func (m *MeshCoordinator) ReadTopic(id string, max int) ([]TopicMessage, uint64, error) {
+	m.topicSubsMu.Lock()
+	defer m.topicSubsMu.Unlock()
+
+	sub, ok := m.topicSubs[id]
+	if !ok {
+		return nil, 0, fmt.Errorf("unknown topic subscription %q", id)
+	}
+	n := len(sub.inbox)
+	if max > 0 && max < n {
+		n = max
+	}
+	out := append([]TopicMessage(nil), sub.inbox[:n]...)
+	sub.inbox = append([]TopicMessage(nil), sub.inbox[n:]...)
+	dropped := sub.dropped
+	sub.dropped = 0
+	return out, dropped, nil
+}
+
+// fetchTopicHistory asks peers for a topic's retained messages, returning
+// the union ordered by publish time.
+func (m *MeshCoordinator) fetchTopicHistory(ctx context.Context, topic string, peers []string) []TopicMessage {
+	if len(peers) == 0 {
+		return nil
+	}
+	if timeout := m.config.PubSub.HistoryTimeout; timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	seen := make(map[string]struct{})
+	var out []TopicMessage
+	for _, peerID := range peers {
+		var resp topicHistoryResponse
+		if err := m.transport.SendRPC(ctx, peerID, pubsubHistoryMethod, topicHistoryRequest{Topic: topic}, &resp); err != nil {
+			m.logger.Debug("topic history fetch failed", "topic", topic, "peer", getShortID(peerID), "error", err)
+			continue
+		}
+		for _, msg := range resp.Messages {
+			if _, dup := seen[msg.ID]; dup || msg.Topic != topic {
+				continue
+			}
+			if err := m.admitTopicMessage(topic, msg.Publisher, len(msg.Data)); errors.Is(err, ErrTopicPayloadTooLarge) {
+				continue
+			}
+			seen[msg.ID] = struct{}{}
+			out = append(out, msg)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].PublishedAt < out[j].PublishedAt })
+	return out
+}
+
+func (m *MeshCoordinator) registerPubSubHandler() {
+	m.registerRPC(pubsubHistoryMethod, func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error) {
+		var req topicHistoryRequest
+		if err := json.Unmarshal(args, &req); err != nil {
+			return nil, fmt.Errorf("failed to decode pubsub.history request: %w", err)
+		}
+		if !validTopic(req.Topic) {
+			return nil, ErrInvalidTopic
+		}
+
+		resp := topicHistoryResponse{Messages: []TopicMessage{}}
+		m.topicsMu.Lock()
+		if state, ok := m.topics[req.Topic]; ok {
+			state.trimRetained(time.Now())
+			for _, msg := range state.retained {
+				if msg.PublishedAt > req.Since {
+					resp.Messages = append(resp.Messages, msg)
+				}
+			}
+		}
+		m.topicsMu.Unlock()
+		return resp, nil
+	})
+}
+
+func (m *MeshCoordinator) registerPubSubGossip() {
+	m.gossip.RegisterHandler(pubsubGossipTopic, func(msg *common.GossipMessage) error {
+		payload, ok := msg.Payload.(map[string]interface{})
+		if !ok {
+			return errors.New("invalid payload type for pubsub.message")
+		}
+
+		topic, _ := payload["topic"].(string)
+		id, _ := payload["id"].(string)
+		if !validTopic(topic) || id == "" {
+			return ErrInvalidTopic
+		}
+		encoded, _ := payload["data"].(string)
+		data, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return fmt.Errorf("invalid pubsub payload: %w", err)
+		}
+		if err := m.admitTopicMessage(topic, msg.Sender, len(data)); err != nil {
+			m.logger.Debug("dropped topic message", "topic", topic, "peer", getShortID(msg.Sender), "error", err)
+			return nil
+		}
+
+		tm := TopicMessage{ID: id, Topic: topic, Publisher: msg.Sender, Data: data}
+		if ts, ok := payload["published_at"].(float64); ok {
+			tm.PublishedAt = int64(ts)
+		}
+		if tm.PublishedAt == 0 {
+			tm.PublishedAt = time.Now().UnixMilli()
+		}
+		m.deliverTopicMessage(tm)
+		return nil
+	})
+}
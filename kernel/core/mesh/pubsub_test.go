@@ -0,0 +1,126 @@
+package mesh
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+)
+
+func TestPubSub_LocalDeliveryAndRetention(t *testing.T) {
+	coord := NewMeshCoordinator("self", "us-east", &MockTransport{nodeID: "self"}, nil)
+	ctx := context.Background()
+	if err := coord.ConfigureTopic("chat", TopicOptions{Retain: 2}); err != nil {
+		t.Fatalf("ConfigureTopic failed: %v", err)
+	}
+
+	early, err := coord.SubscribeTopic(ctx, "chat")
+	if err != nil {
+		t.Fatalf("SubscribeTopic failed: %v", err)
+	}
+	for _, text := range []string{"one", "two", "three"} {
+		if _, err := coord.PublishTopic(ctx, "chat", []byte(text)); err != nil {
+			t.Fatalf("PublishTopic failed: %v", err)
+		}
+	}
+
+	msgs, dropped, err := coord.ReadTopic(early.ID, 0)
+	if err != nil || dropped != 0 || len(msgs) != 3 || string(msgs[0].Data) != "one" {
+		t.Fatalf("unexpected read %+v dropped=%d err=%v", msgs, dropped, err)
+	}
+	if msgs, _, _ := coord.ReadTopic(early.ID, 0); len(msgs) != 0 {
+		t.Fatalf("expected the inbox to be drained, got %d", len(msgs))
+	}
+
+	// A late joiner finds the two most recent messages waiting.
+	late, err := coord.SubscribeTopic(ctx, "chat")
+	if err != nil || late.Backlog != 2 {
+		t.Fatalf("expected a backlog of 2, got %+v (%v)", late, err)
+	}
+	msgs, _, _ = coord.ReadTopic(late.ID, 1)
+	if len(msgs) != 1 || string(msgs[0].Data) != "two" {
+		t.Fatalf("unexpected backlog %+v", msgs)
+	}
+
+	if !coord.UnsubscribeTopic(late.ID) {
+		t.Fatal("expected unsubscribe to succeed")
+	}
+	if _, _, err := coord.ReadTopic(late.ID, 0); err == nil {
+		t.Fatal("expected reads on a closed subscription to fail")
+	}
+}
+
+func TestPubSub_EnforcesTopicLimits(t *testing.T) {
+	coord := NewMeshCoordinator("self", "us-east", &MockTransport{nodeID: "self"}, nil)
+	ctx := context.Background()
+	if err := coord.ConfigureTopic("telemetry", TopicOptions{MaxPayload: 8, RatePerSecond: 2, Burst: 2}); err != nil {
+		t.Fatalf("ConfigureTopic failed: %v", err)
+	}
+
+	if _, err := coord.PublishTopic(ctx, "telemetry", make([]byte, 9)); !errors.Is(err, ErrTopicPayloadTooLarge) {
+		t.Fatalf("expected payload limit error, got %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := coord.PublishTopic(ctx, "telemetry", []byte("ok")); err != nil {
+			t.Fatalf("publish %d failed: %v", i, err)
+		}
+	}
+	if _, err := coord.PublishTopic(ctx, "telemetry", []byte("ok")); !errors.Is(err, ErrTopicRateLimited) {
+		t.Fatalf("expected rate limit error, got %v", err)
+	}
+	if _, err := coord.PublishTopic(ctx, "bad topic", nil); !errors.Is(err, ErrInvalidTopic) {
+		t.Fatalf("expected invalid topic error, got %v", err)
+	}
+}
+
+func TestPubSub_GossipDeliveryAndHistoryFetch(t *testing.T) {
+	coord := NewMeshCoordinator("self", "us-east", &MockTransport{nodeID: "self"}, nil)
+	ctx := context.Background()
+	sub, err := coord.SubscribeTopic(ctx, "chat")
+	if err != nil {
+		t.Fatalf("SubscribeTopic failed: %v", err)
+	}
+
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+	msg := &common.GossipMessage{
+		ID:        "pubsub-1",
+		Sender:    "remote",
+		Type:      pubsubGossipTopic,
+		Timestamp: time.Now().UnixNano(),
+		MaxHops:   10,
+		Payload: map[string]interface{}{
+			"id":           "remote:1",
+			"topic":        "chat",
+			"data":         base64.StdEncoding.EncodeToString([]byte("hello")),
+			"published_at": float64(time.Now().UnixMilli()),
+		},
+		PublicKey: []byte(pub),
+	}
+	signGossipMessage(msg, priv)
+	if err := coord.gossip.ReceiveMessage("remote", msg); err != nil {
+		t.Fatalf("ReceiveMessage failed: %v", err)
+	}
+	msgs, _, _ := coord.ReadTopic(sub.ID, 0)
+	if len(msgs) != 1 || msgs[0].Publisher != "remote" || string(msgs[0].Data) != "hello" {
+		t.Fatalf("unexpected gossip delivery %+v", msgs)
+	}
+
+	// A peer that retains the topic serves its history to new subscribers.
+	holder := NewMeshCoordinator("holder", "us-east", &MockTransport{nodeID: "holder"}, nil)
+	_ = holder.ConfigureTopic("news", TopicOptions{Retain: 5})
+	_, _ = holder.PublishTopic(ctx, "news", []byte("old"))
+	_, _ = holder.PublishTopic(ctx, "news", []byte("older"))
+	handler := holder.transport.(*MockTransport).registeredRPCHandlers[pubsubHistoryMethod]
+	coord.transport.(*MockTransport).registeredRPCHandlers[pubsubHistoryMethod] = handler
+
+	_ = coord.ConfigureTopic("news", TopicOptions{Retain: 5})
+	history := coord.fetchTopicHistory(ctx, "news", []string{"holder"})
+	if len(history) != 2 || history[0].Publisher != "holder" {
+		t.Fatalf("unexpected history %+v", history)
+	}
+}
@@ -0,0 +1,193 @@
+package mesh
+
+import (
+	"sort"
+	"time"
+)
+
+// QuarantineStatus describes a peer under escalating quarantine.
+type QuarantineStatus struct {
+	PeerID        string    `json:"peer_id"`
+	Level         int       `json:"level"`
+	FailedCycles  int       `json:"failed_cycles"`
+	Until         time.Time `json:"until"`
+	RemainingSecs float64   `json:"remaining_secs"`
+}
+
+// quarantineEntry tracks breaker cycles for a peer. A cycle is one breaker
+// opening that was not followed by a successful close.
+type quarantineEntry struct {
+	failedCycles int
+	level        int
+	until        time.Time
+	releasedAt   time.Time
+}
+
+func (e *quarantineEntry) active(now time.Time) bool {
+	return now.Before(e.until)
+}
+
+// quarantineDuration doubles (by Multiplier) with each level, up to MaxDuration.
+func (m *MeshCoordinator) quarantineDuration(level int) time.Duration {
+	cfg := m.config.Quarantine
+	d := float64(cfg.BaseDuration)
+	for i := 1; i < level; i++ {
+		d *= cfg.Multiplier
+		if d >= float64(cfg.MaxDuration) {
+			return cfg.MaxDuration
+		}
+	}
+	return time.Duration(d)
+}
+
+// noteBreakerOpened counts a failed breaker cycle and quarantines the peer
+// once enough cycles pile up without a recovery.
+func (m *MeshCoordinator) noteBreakerOpened(peerID string) {
+	cfg := m.config.Quarantine
+	if !cfg.Enabled {
+		return
+	}
+	now := time.Now()
+
+	m.quarantineMu.Lock()
+	entry, ok := m.quarantined[peerID]
+	if !ok {
+		entry = &quarantineEntry{}
+		m.quarantined[peerID] = entry
+	}
+	// A long clean run after the last release forgives earlier escalation.
+	if entry.level > 0 && !entry.releasedAt.IsZero() && now.Sub(entry.releasedAt) > cfg.DecayAfter {
+		entry.level = 0
+	}
+	entry.failedCycles++
+	if entry.failedCycles < cfg.CyclesBeforeQuarantine || entry.active(now) {
+		m.quarantineMu.Unlock()
+		return
+	}
+	entry.level++
+	entry.failedCycles = 0
+	duration := m.quarantineDuration(entry.level)
+	entry.until = now.Add(duration)
+	level := entry.level
+	m.quarantineMu.Unlock()
+
+	m.gossip.RemovePeer(peerID)
+	m.logger.Warn("peer quarantined",
+		"peer", getShortID(peerID),
+		"level", level,
+		"duration", duration,
+	)
+}
+
+// noteBreakerClosed clears the failed cycle count once a peer recovers.
+func (m *MeshCoordinator) noteBreakerClosed(peerID string) {
+	m.quarantineMu.Lock()
+	if entry, ok := m.quarantined[peerID]; ok {
+		entry.failedCycles = 0
+	}
+	m.quarantineMu.Unlock()
+}
+
+// isPeerQuarantined reports whether a peer is excluded from gossip and selection.
+func (m *MeshCoordinator) isPeerQuarantined(peerID string) bool {
+	m.quarantineMu.Lock()
+	defer m.quarantineMu.Unlock()
+	entry, ok := m.quarantined[peerID]
+	return ok && entry.active(time.Now())
+}
+
+// UnquarantinePeer lifts a quarantine immediately and resets the peer's
+// escalation level and circuit breaker. It reports whether the peer was
+// quarantined.
+func (m *MeshCoordinator) UnquarantinePeer(peerID string) bool {
+	m.quarantineMu.Lock()
+	entry, ok := m.quarantined[peerID]
+	wasActive := ok && entry.active(time.Now())
+	delete(m.quarantined, peerID)
+	m.quarantineMu.Unlock()
+
+	m.cbMu.Lock()
+	delete(m.circuitBreakers, "peer:"+peerID)
+	m.cbMu.Unlock()
+
+	if wasActive {
+		m.restoreQuarantinedPeer(peerID)
+		m.logger.Info("peer unquarantined manually", "peer", getShortID(peerID))
+	}
+	return wasActive
+}
+
+// GetQuarantinedPeers lists peers currently in quarantine.
+func (m *MeshCoordinator) GetQuarantinedPeers() []QuarantineStatus {
+	now := time.Now()
+	m.quarantineMu.Lock()
+	defer m.quarantineMu.Unlock()
+
+	out := make([]QuarantineStatus, 0)
+	for peerID, entry := range m.quarantined {
+		if !entry.active(now) {
+			continue
+		}
+		out = append(out, QuarantineStatus{
+			PeerID:        peerID,
+			Level:         entry.level,
+			FailedCycles:  entry.failedCycles,
+			Until:         entry.until,
+			RemainingSecs: entry.until.Sub(now).Seconds(),
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Until.Before(out[j].Until) })
+	return out
+}
+
+func (m *MeshCoordinator) restoreQuarantinedPeer(peerID string) {
+	if m.transport.IsConnected(peerID) {
+		m.gossip.AddPeer(peerID)
+	}
+}
+
+// releaseExpiredQuarantines returns peers whose quarantine ran out to gossip
+// and drops entries that have been clean for the decay window.
+func (m *MeshCoordinator) releaseExpiredQuarantines() {
+	now := time.Now()
+	var released []string
+
+	m.quarantineMu.Lock()
+	for peerID, entry := range m.quarantined {
+		if entry.until.IsZero() || entry.active(now) {
+			continue
+		}
+		if entry.releasedAt.IsZero() || entry.releasedAt.Before(entry.until) {
+			entry.releasedAt = now
+			released = append(released, peerID)
+			continue
+		}
+		if entry.failedCycles == 0 && now.Sub(entry.releasedAt) > m.config.Quarantine.DecayAfter {
+			delete(m.quarantined, peerID)
+		}
+	}
+	m.quarantineMu.Unlock()
+
+	for _, peerID := range released {
+		m.restoreQuarantinedPeer(peerID)
+		m.logger.Info("peer quarantine expired", "peer", getShortID(peerID))
+	}
+}
+
+func (m *MeshCoordinator) quarantineLoop() {
+	if !m.config.Quarantine.Enabled {
+		return
+	}
+
+	ticker := time.NewTicker(m.config.Quarantine.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			m.releaseExpiredQuarantines()
+		case <-m.shutdown:
+			return
+		}
+	}
+}
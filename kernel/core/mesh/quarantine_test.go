@@ -0,0 +1,86 @@
+package mesh
+
+import (
+	"testing"
+	"time"
+)
+
+// tripBreaker drives one full failed breaker cycle for a peer.
+func tripBreaker(coord *MeshCoordinator, peerID string) {
+	coord.updateCircuitBreaker(peerID, false)
+	coord.cbMu.Lock()
+	cb := coord.circuitBreakers["peer:"+peerID]
+	coord.cbMu.Unlock()
+	cb.mu.Lock()
+	cb.state = BreakerHalfOpen
+	cb.mu.Unlock()
+}
+
+func TestMeshCoordinator_QuarantineEscalates(t *testing.T) {
+	tr := &MockTransport{nodeID: "self"}
+	coord := NewMeshCoordinator("self", "us-east", tr, nil)
+	coord.config.CircuitBreaker.FailureThreshold = 1
+	coord.config.Quarantine.CyclesBeforeQuarantine = 2
+	coord.config.Quarantine.BaseDuration = time.Minute
+	coord.config.Quarantine.MaxDuration = 3 * time.Minute
+	_ = coord.admission.SetAutoBan(AdmissionAutoBan{})
+
+	tripBreaker(coord, "flaky")
+	if coord.isPeerQuarantined("flaky") {
+		t.Fatal("one failed cycle should not quarantine")
+	}
+	tripBreaker(coord, "flaky")
+	if !coord.isPeerQuarantined("flaky") || !coord.isCircuitBreakerOpenForPeer("flaky") {
+		t.Fatal("expected quarantine after repeated failed cycles")
+	}
+	first := coord.GetQuarantinedPeers()
+	if len(first) != 1 || first[0].Level != 1 || first[0].RemainingSecs > 60 {
+		t.Fatalf("unexpected first quarantine %+v", first)
+	}
+
+	// Expire the quarantine and fail again: the next level lasts longer,
+	// bounded by MaxDuration.
+	for _, want := range []time.Duration{2 * time.Minute, 3 * time.Minute} {
+		coord.quarantineMu.Lock()
+		coord.quarantined["flaky"].until = time.Now().Add(-time.Second)
+		coord.quarantineMu.Unlock()
+		coord.releaseExpiredQuarantines()
+
+		tripBreaker(coord, "flaky")
+		tripBreaker(coord, "flaky")
+		status := coord.GetQuarantinedPeers()
+		if len(status) != 1 {
+			t.Fatalf("expected peer back in quarantine, got %+v", status)
+		}
+		if got := time.Duration(status[0].RemainingSecs * float64(time.Second)); got > want || got < want-time.Second {
+			t.Fatalf("level %d: expected ~%s, got %s", status[0].Level, want, got)
+		}
+	}
+}
+
+func TestMeshCoordinator_UnquarantinePeer(t *testing.T) {
+	tr := &MockTransport{nodeID: "self"}
+	coord := NewMeshCoordinator("self", "us-east", tr, nil)
+	coord.config.CircuitBreaker.FailureThreshold = 1
+	coord.config.Quarantine.CyclesBeforeQuarantine = 1
+	_ = coord.admission.SetAutoBan(AdmissionAutoBan{})
+
+	tripBreaker(coord, "peer-q")
+	peers := []*PeerCapability{{PeerID: "peer-q", Reputation: 0.9}}
+	if _, err := coord.selectBestPeer(peers); err == nil {
+		t.Fatal("quarantined peer must not be selected")
+	}
+
+	if !coord.UnquarantinePeer("peer-q") {
+		t.Fatal("expected an active quarantine to be lifted")
+	}
+	if coord.isCircuitBreakerOpenForPeer("peer-q") {
+		t.Fatal("unquarantine should also reset the breaker")
+	}
+	if best, err := coord.selectBestPeer(peers); err != nil || best.PeerID != "peer-q" {
+		t.Fatalf("expected peer to be selectable again, got %v %v", best, err)
+	}
+	if coord.UnquarantinePeer("peer-q") {
+		t.Fatal("second unquarantine should report nothing to lift")
+	}
+}
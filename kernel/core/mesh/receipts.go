@@ -0,0 +1,166 @@
+package mesh
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const delegationReceiptVersion = "inos-delegate-receipt-v1"
+
+// DelegationReceipt is an executor's signed statement that it ran a job:
+// which job, on what input, with what output, and when. The executor signs
+// it with its identity key, so a requester can later prove who produced a
+// result, or show that a wrong one came from that executor.
+//
+// Digests are hex sha256 of the raw input and output bytes, as computed by
+// computeResourceDigest. Jobs refused before running get no receipt.
+type DelegationReceipt struct {
+	JobID        string `json:"job_id"`
+	Operation    string `json:"operation"`
+	Requester    string `json:"requester,omitempty"`
+	Executor     string `json:"executor"`
+	ExecutorKey  []byte `json:"executor_key"`
+	InputDigest  string `json:"input_digest"`
+	OutputDigest string `json:"output_digest,omitempty"` // Empty when the job did not succeed
+	Status       string `json:"status"`
+	StartedAt    int64  `json:"started_at"`  // Executor mesh time, Unix nanoseconds
+	FinishedAt   int64  `json:"finished_at"` // Executor mesh time, Unix nanoseconds
+	Signature    []byte `json:"signature"`
+}
+
+// Duration returns how long the executor says the job ran.
+func (r *DelegationReceipt) Duration() time.Duration {
+	return time.Duration(r.FinishedAt - r.StartedAt)
+}
+
+// Verify checks the executor's signature over the receipt. It does not
+// check that the key belongs to the executor; VerifyDelegationReceipt does.
+func (r *DelegationReceipt) Verify() error {
+	if len(r.ExecutorKey) != ed25519.PublicKeySize || !ed25519.Verify(r.ExecutorKey, delegationReceiptPayload(r), r.Signature) {
+		return errors.New("invalid delegation receipt signature")
+	}
+	if r.FinishedAt < r.StartedAt {
+		return errors.New("delegation receipt finishes before it starts")
+	}
+	return nil
+}
+
+func delegationReceiptPayload(r *DelegationReceipt) []byte {
+	buf := make([]byte, 0, 256)
+	buf = append(buf, delegationReceiptVersion...)
+	for _, field := range []string{r.JobID, r.Operation, r.Requester, r.Executor, string(r.ExecutorKey), r.InputDigest, r.OutputDigest, r.Status} {
+		buf = append(buf, 0)
+		buf = append(buf, field...)
+	}
+	buf = binary.BigEndian.AppendUint64(buf, uint64(r.StartedAt))
+	return binary.BigEndian.AppendUint64(buf, uint64(r.FinishedAt))
+}
+
+// signDelegationReceipt stamps the receipt with this node's identity.
+func (m *MeshCoordinator) signDelegationReceipt(r *DelegationReceipt) error {
+	if m.gossip == nil {
+		return errors.New("gossip manager unavailable for receipt signing")
+	}
+	r.Executor = m.nodeID
+	r.ExecutorKey = m.gossip.PublicKey()
+
+	sig, pub, err := m.gossip.SignAttestation(delegationReceiptPayload(r))
+	if err != nil {
+		return fmt.Errorf("failed to sign delegation receipt: %w", err)
+	}
+	if !bytes.Equal(pub, r.ExecutorKey) {
+		return errors.New("identity key rotated while signing delegation receipt")
+	}
+	r.Signature = sig
+	return nil
+}
+
+// VerifyDelegationReceipt checks a receipt's signature and that the key is
+// the executor's. Non-nil input and output are also checked against the
+// receipt's digests, so a requester holding the bytes can tell whether the
+// executor really returned them for that input.
+func (m *MeshCoordinator) VerifyDelegationReceipt(r *DelegationReceipt, input, output []byte) error {
+	if r == nil {
+		return errors.New("missing delegation receipt")
+	}
+	if err := r.Verify(); err != nil {
+		return err
+	}
+	if err := m.checkIdentityKey(r.Executor, r.ExecutorKey); err != nil {
+		return fmt.Errorf("delegation receipt executor: %w", err)
+	}
+	if input != nil && m.computeResourceDigest(input) != r.InputDigest {
+		return errors.New("delegation receipt input digest does not match")
+	}
+	if output != nil && m.computeResourceDigest(output) != r.OutputDigest {
+		return errors.New("delegation receipt output digest does not match")
+	}
+	return nil
+}
+
+// checkDelegationReceipt checks the receipt an executor returned for req,
+// whose input was input. Responses for jobs that ran must carry one when
+// signatures are required; a replayed response carries the receipt of the
+// request that ran first.
+func (m *MeshCoordinator) checkDelegationReceipt(req *DelegateRequest, resp *DelegationResponse, input []byte) error {
+	receipt := resp.Receipt
+	if receipt == nil {
+		if m.config.Delegation.RequireSignatures && delegationRan(resp.Status) {
+			return errors.New("delegation response has no receipt")
+		}
+		return nil
+	}
+	if err := m.VerifyDelegationReceipt(receipt, input, nil); err != nil {
+		return err
+	}
+	if receipt.JobID != req.ID && !resp.Replayed {
+		return errors.New("delegation receipt is for a different job")
+	}
+	if receipt.Executor != resp.Executor || receipt.Status != resp.Status || receipt.Operation != req.Operation {
+		return errors.New("delegation receipt does not match response")
+	}
+	return nil
+}
+
+// delegationRan reports whether a response status means the job ran.
+func delegationRan(status string) bool {
+	return status == "success" || status == "failed" || status == "resource_exceeded"
+}
+
+// storeDelegationReceipt keeps a receipt this node issued or received,
+// dropping the oldest once the log is full.
+func (m *MeshCoordinator) storeDelegationReceipt(r *DelegationReceipt) {
+	if r == nil {
+		return
+	}
+	m.delegationAuditMu.Lock()
+	defer m.delegationAuditMu.Unlock()
+	if limit := m.config.Delegation.ReceiptLogSize; limit > 0 && len(m.delegationReceipts) >= limit {
+		m.delegationReceipts = append(m.delegationReceipts[:0], m.delegationReceipts[len(m.delegationReceipts)-limit+1:]...)
+	}
+	m.delegationReceipts = append(m.delegationReceipts, *r)
+}
+
+// GetDelegationReceipt returns the latest stored receipt for a job.
+func (m *MeshCoordinator) GetDelegationReceipt(jobID string) (DelegationReceipt, bool) {
+	m.delegationAuditMu.Lock()
+	defer m.delegationAuditMu.Unlock()
+	for i := len(m.delegationReceipts) - 1; i >= 0; i-- {
+		if m.delegationReceipts[i].JobID == jobID {
+			return m.delegationReceipts[i], true
+		}
+	}
+	return DelegationReceipt{}, false
+}
+
+// GetDelegationReceipts returns the stored receipts, oldest first: those
+// this node issued as executor and those it received as requester.
+func (m *MeshCoordinator) GetDelegationReceipts() []DelegationReceipt {
+	m.delegationAuditMu.Lock()
+	defer m.delegationAuditMu.Unlock()
+	return append([]DelegationReceipt(nil), m.delegationReceipts...)
+}
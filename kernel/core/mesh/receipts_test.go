@@ -0,0 +1,75 @@
+package mesh
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestDelegationReceipts_IssuedVerifiedAndStored(t *testing.T) {
+	coord, _ := newDelegationTestCoordinator(t)
+
+	input := []byte("source")
+	output, err := coord.DelegateCompute(context.Background(), "compress", "input-digest", input)
+	if err != nil {
+		t.Fatalf("DelegateCompute failed: %v", err)
+	}
+
+	// One receipt issued as executor, the same one received as requester
+	receipts := coord.GetDelegationReceipts()
+	if len(receipts) != 2 || receipts[0].JobID != receipts[1].JobID {
+		t.Fatalf("expected the receipt on both sides, got %d", len(receipts))
+	}
+	receipt, ok := coord.GetDelegationReceipt(receipts[0].JobID)
+	if !ok || receipt.Executor != "node-a" || receipt.Requester != "node-a" || receipt.Status != "success" {
+		t.Fatalf("unexpected receipt %+v", receipt)
+	}
+	if receipt.Duration() < 0 {
+		t.Fatalf("unexpected receipt timing %v", receipt.Duration())
+	}
+	if err := coord.VerifyDelegationReceipt(&receipt, input, output); err != nil {
+		t.Fatalf("receipt does not verify: %v", err)
+	}
+	if err := coord.VerifyDelegationReceipt(&receipt, input, []byte("other output")); err == nil {
+		t.Fatal("expected a different output to fail verification")
+	}
+
+	receipt.FinishedAt++
+	if err := receipt.Verify(); err == nil {
+		t.Fatal("expected a tampered receipt to fail verification")
+	}
+}
+
+func TestDelegationReceipts_RequesterRejectsForgedReceipt(t *testing.T) {
+	coord, tr := newDelegationTestCoordinator(t)
+
+	tr.mu.Lock()
+	tr.rpcHandlers[delegateComputeMethod] = func(args interface{}) (interface{}, error) {
+		req := args.(DelegateRequest)
+		raw, _ := json.Marshal(req)
+		value, err := tr.registeredRPCHandlers[delegateComputeMethod](capabilityContextFor(t, coord, "peer-1", delegateComputeMethod), "peer-1", raw)
+		if err != nil {
+			return nil, err
+		}
+		// Claim a different output without re-signing the receipt, then
+		// sign the response so only the receipt is wrong
+		resp := value.(DelegationResponse)
+		forged := *resp.Receipt
+		forged.OutputDigest = coord.computeResourceDigest([]byte("other output"))
+		resp.Receipt = &forged
+		if err := coord.signDelegationResponse(&req, &resp); err != nil {
+			return nil, err
+		}
+		return resp, nil
+	}
+	tr.mu.Unlock()
+
+	_, err := coord.DelegateCompute(context.Background(), "compress", "input-digest", []byte("source"))
+	if err == nil || !strings.Contains(err.Error(), "receipt rejected") {
+		t.Fatalf("expected the forged receipt to be rejected, got %v", err)
+	}
+	if n := len(coord.GetDelegationReceipts()); n != 1 {
+		t.Fatalf("expected only the executor's receipt to be kept, got %d", n)
+	}
+}
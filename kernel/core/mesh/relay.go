@@ -0,0 +1,98 @@
+package mesh
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/transport"
+)
+
+// relayCapability marks peers that forward circuits for others.
+const relayCapability = "relay"
+
+// ErrNoRelay is returned when no connected peer offers to relay.
+var ErrNoRelay = errors.New("no relay peer available")
+
+type relayTransport interface {
+	RelayEnabled() bool
+	ConnectViaRelay(ctx context.Context, relayID, peerID string) error
+}
+
+// offersRelay reports whether to advertise relaying: the transport must be
+// configured for it and the node well connected. A node behind a symmetric
+// NAT, or with UDP blocked, would only add a hop that also fails.
+func (m *MeshCoordinator) offersRelay() bool {
+	relay, ok := m.transport.(relayTransport)
+	if !ok || !relay.RelayEnabled() {
+		return false
+	}
+	if report := m.LastConnectivityReport(); report != nil {
+		switch report.NATType {
+		case transport.NATSymmetric, transport.NATUDPBlocked, transport.NATRelayOnly:
+			return false
+		}
+	}
+	return true
+}
+
+// RelayPeers lists connected peers that advertise relaying, lowest
+// latency first.
+func (m *MeshCoordinator) RelayPeers() []string {
+	type candidate struct {
+		peerID  string
+		latency float32
+	}
+	var candidates []candidate
+	for _, peerID := range m.transport.GetConnectedPeers() {
+		if peer := m.getCachedPeer(peerID); peer != nil && slices.Contains(peer.Capabilities, relayCapability) {
+			candidates = append(candidates, candidate{peerID, peer.LatencyMs})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].latency < candidates[j].latency })
+
+	relays := make([]string, len(candidates))
+	for i, c := range candidates {
+		relays[i] = c.peerID
+	}
+	return relays
+}
+
+// ConnectViaRelay reaches peerID through the first relay peer that will
+// carry the circuit. Use it for peers a direct connection cannot reach.
+func (m *MeshCoordinator) ConnectViaRelay(ctx context.Context, peerID string) error {
+	relay, ok := m.transport.(relayTransport)
+	if !ok {
+		return errors.New("transport does not support relaying")
+	}
+	relays := m.RelayPeers()
+	if len(relays) == 0 {
+		return ErrNoRelay
+	}
+	var lastErr error
+	for _, relayID := range relays {
+		if relayID == peerID {
+			continue
+		}
+		if err := relay.ConnectViaRelay(ctx, relayID, peerID); err != nil {
+			m.logger.Debug("relay refused circuit", "relay", getShortID(relayID), "peer", getShortID(peerID), "error", err)
+			lastErr = err
+			continue
+		}
+		return nil
+	}
+	if lastErr == nil {
+		return ErrNoRelay
+	}
+	return fmt.Errorf("all relays failed: %w", lastErr)
+}
+
+// creditRelay pays this node for the bytes it relayed.
+func (m *MeshCoordinator) creditRelay(usage transport.RelayUsage) {
+	if usage.Bytes == 0 {
+		return
+	}
+	m.ledger.CreditRelay(m.localAccount(), usage.Bytes)
+}
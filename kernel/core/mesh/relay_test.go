@@ -0,0 +1,87 @@
+package mesh
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+// relayingTransport connects to peers and relays through those it is told to.
+type relayingTransport struct {
+	*MockTransport
+	connected []string
+	relaying  bool
+	refusing  map[string]bool
+	circuits  []string // relay->peer
+}
+
+func (r *relayingTransport) GetConnectedPeers() []string { return r.connected }
+func (r *relayingTransport) RelayEnabled() bool          { return r.relaying }
+
+func (r *relayingTransport) ConnectViaRelay(ctx context.Context, relayID, peerID string) error {
+	if r.refusing[relayID] {
+		return errors.New("relay circuit refused")
+	}
+	r.circuits = append(r.circuits, relayID+"->"+peerID)
+	return nil
+}
+
+func TestMeshCoordinator_ConnectViaRelayPrefersFastRelays(t *testing.T) {
+	tr := &relayingTransport{
+		MockTransport: &MockTransport{nodeID: "self"},
+		connected:     []string{"slow-relay", "fast-relay", "plain"},
+		refusing:      map[string]bool{"fast-relay": true},
+	}
+	coord := NewMeshCoordinator("self", "us-east", tr, nil)
+	if err := coord.ConnectViaRelay(context.Background(), "nat-peer"); !errors.Is(err, ErrNoRelay) {
+		t.Fatalf("expected ErrNoRelay before any peer advertises relaying, got %v", err)
+	}
+
+	now := time.Now().UnixNano()
+	coord.cachePeer("slow-relay", &PeerCapability{PeerID: "slow-relay", LatencyMs: 80, Capabilities: []string{relayCapability}, LastSeen: now})
+	coord.cachePeer("fast-relay", &PeerCapability{PeerID: "fast-relay", LatencyMs: 10, Capabilities: []string{relayCapability}, LastSeen: now})
+	coord.cachePeer("plain", &PeerCapability{PeerID: "plain", LatencyMs: 1, LastSeen: now})
+
+	if got := coord.RelayPeers(); !slices.Equal(got, []string{"fast-relay", "slow-relay"}) {
+		t.Fatalf("expected relays by latency, got %v", got)
+	}
+	if err := coord.ConnectViaRelay(context.Background(), "nat-peer"); err != nil {
+		t.Fatalf("ConnectViaRelay failed: %v", err)
+	}
+	if !slices.Equal(tr.circuits, []string{"slow-relay->nat-peer"}) {
+		t.Fatalf("expected the refused relay to be skipped, got %v", tr.circuits)
+	}
+}
+
+func TestMeshCoordinator_OffersRelayOnlyWhenConfigured(t *testing.T) {
+	tr := &relayingTransport{MockTransport: &MockTransport{nodeID: "self"}}
+	coord := NewMeshCoordinator("self", "us-east", tr, nil)
+	if coord.offersRelay() {
+		t.Fatal("expected no relay offer while the transport does not relay")
+	}
+	tr.relaying = true
+	if !coord.offersRelay() {
+		t.Fatal("expected a relay offer once the transport relays")
+	}
+}
+
+func TestEconomicLedger_CreditRelayCarriesRemainder(t *testing.T) {
+	ledger := NewEconomicLedger()
+	var reasons []string
+	ledger.SetChangeHandler(func(change LedgerChange) { reasons = append(reasons, change.Reason) })
+
+	if credits := ledger.CreditRelay("relay", 700<<10); credits != 0 {
+		t.Fatalf("expected no credit below a MiB, got %d", credits)
+	}
+	if credits := ledger.CreditRelay("relay", 2<<20); credits != 2*RelayCreditPerMB {
+		t.Fatalf("expected the remainder to carry over, got %d", credits)
+	}
+	if balance := ledger.GetBalance("relay"); balance != 2*RelayCreditPerMB {
+		t.Fatalf("unexpected balance %d", balance)
+	}
+	if !slices.Equal(reasons, []string{"relay"}) {
+		t.Fatalf("expected one relay change, got %v", reasons)
+	}
+}
@@ -0,0 +1,556 @@
+package mesh
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/transport"
+)
+
+// ReplayEventKind identifies what a replay log entry captured.
+type ReplayEventKind string
+
+const (
+	ReplayEventStart  ReplayEventKind = "start"  // Identity of the recorded coordinator; always first
+	ReplayEventRPC    ReplayEventKind = "rpc"    // Inbound RPC and what the local handler returned
+	ReplayEventReply  ReplayEventKind = "reply"  // Reply to an RPC this node sent
+	ReplayEventStream ReplayEventKind = "stream" // Body of a streamed RPC this node sent
+	ReplayEventPeer   ReplayEventKind = "peer"   // Transport connect or disconnect
+	ReplayEventPush   ReplayEventKind = "push"   // Message a peer pushed outside of an RPC
+	ReplayEventEpoch  ReplayEventKind = "epoch"  // Epoch boundary
+)
+
+// ErrReplayExhausted is returned by ReplayTransport when the coordinator
+// sends an RPC the log holds no (further) reply for.
+var ErrReplayExhausted = errors.New("replay log has no recorded reply")
+
+// ReplayEvent is one entry in a replay log. Entries are written as JSON
+// lines with single-letter keys to keep long recordings small.
+type ReplayEvent struct {
+	Seq       uint64          `json:"s"`
+	At        int64           `json:"t"` // Nanoseconds since recording started
+	Kind      ReplayEventKind `json:"k"`
+	Peer      string          `json:"p,omitempty"` // Remote peer; the local node ID for start
+	Region    string          `json:"g,omitempty"`
+	Method    string          `json:"m,omitempty"`
+	Args      json.RawMessage `json:"a,omitempty"`
+	Result    json.RawMessage `json:"r,omitempty"`
+	Err       string          `json:"e,omitempty"`
+	Connected bool            `json:"c,omitempty"`
+	Epoch     uint32          `json:"n,omitempty"`
+}
+
+// ReplayRecorder appends replay events to a writer. Inbound RPCs are logged
+// when their handler returns, so the log order is the order in which their
+// effects became visible.
+type ReplayRecorder struct {
+	mu    sync.Mutex
+	enc   *json.Encoder
+	start time.Time
+	seq   uint64
+	err   error
+}
+
+// NewReplayRecorder writes events to w.
+func NewReplayRecorder(w io.Writer) *ReplayRecorder {
+	return &ReplayRecorder{enc: json.NewEncoder(w), start: time.Now()}
+}
+
+func (r *ReplayRecorder) now() int64 {
+	return int64(time.Since(r.start))
+}
+
+func (r *ReplayRecorder) record(ev ReplayEvent) {
+	if ev.At == 0 {
+		ev.At = r.now()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return
+	}
+	r.seq++
+	ev.Seq = r.seq
+	r.err = r.enc.Encode(ev)
+}
+
+// Events returns the number of events written.
+func (r *ReplayRecorder) Events() uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.seq
+}
+
+// Err returns the first write error; recording stops once one occurs.
+func (r *ReplayRecorder) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+// ReadReplayLog decodes a log written by a ReplayRecorder.
+func ReadReplayLog(r io.Reader) ([]ReplayEvent, error) {
+	dec := json.NewDecoder(r)
+	var events []ReplayEvent
+	for {
+		var ev ReplayEvent
+		if err := dec.Decode(&ev); err != nil {
+			if errors.Is(err, io.EOF) {
+				return events, nil
+			}
+			return events, fmt.Errorf("replay log entry %d: %w", len(events)+1, err)
+		}
+		events = append(events, ev)
+	}
+}
+
+func marshalReplayValue(v interface{}) json.RawMessage {
+	if v == nil {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+func replayErrString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+// RecordReplay logs every inbound RPC and pushed message, reply to an
+// outbound RPC, peer connection change and epoch boundary to w, for later
+// replay through a ReplayHarness. It must be called before Start.
+func (m *MeshCoordinator) RecordReplay(w io.Writer) *ReplayRecorder {
+	rec := NewReplayRecorder(w)
+	rec.record(ReplayEvent{Kind: ReplayEventStart, Peer: m.nodeID, Region: m.region})
+
+	m.ReplaceTransport(&recordingTransport{Transport: m.transport, rec: rec})
+	m.epochTicker.SetEpochHook(func(epoch uint32) {
+		rec.record(ReplayEvent{Kind: ReplayEventEpoch, Epoch: epoch})
+	})
+	return rec
+}
+
+// recordingTransport wraps the live transport and logs what reaches the
+// coordinator through it.
+type recordingTransport struct {
+	Transport
+	rec *ReplayRecorder
+}
+
+func (t *recordingTransport) RegisterRPCHandler(method string, handler func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error)) {
+	t.Transport.RegisterRPCHandler(method, func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error) {
+		at := t.rec.now()
+		params := append(json.RawMessage(nil), args...)
+		result, err := handler(ctx, peerID, args)
+		t.rec.record(ReplayEvent{
+			At:     at,
+			Kind:   ReplayEventRPC,
+			Peer:   peerID,
+			Method: method,
+			Args:   params,
+			Result: marshalReplayValue(result),
+			Err:    replayErrString(err),
+		})
+		return result, err
+	})
+}
+
+func (t *recordingTransport) SendRPC(ctx context.Context, peerID string, method string, args interface{}, reply interface{}) error {
+	err := t.Transport.SendRPC(ctx, peerID, method, args, reply)
+	ev := ReplayEvent{Kind: ReplayEventReply, Peer: peerID, Method: method, Err: replayErrString(err)}
+	if err == nil {
+		ev.Result = marshalReplayValue(reply)
+	}
+	t.rec.record(ev)
+	return err
+}
+
+func (t *recordingTransport) StreamRPC(ctx context.Context, peerID string, method string, args interface{}, writer io.Writer) (int64, error) {
+	var body bytes.Buffer
+	n, err := t.Transport.StreamRPC(ctx, peerID, method, args, io.MultiWriter(writer, &body))
+	t.rec.record(ReplayEvent{
+		Kind:   ReplayEventStream,
+		Peer:   peerID,
+		Method: method,
+		Result: marshalReplayValue(body.Bytes()),
+		Err:    replayErrString(err),
+	})
+	return n, err
+}
+
+func (t *recordingTransport) SetPeerEventHandler(handler func(peerID string, connected bool)) {
+	hook, ok := t.Transport.(interface {
+		SetPeerEventHandler(func(peerID string, connected bool))
+	})
+	if !ok {
+		return
+	}
+	hook.SetPeerEventHandler(func(peerID string, connected bool) {
+		t.rec.record(ReplayEvent{Kind: ReplayEventPeer, Peer: peerID, Connected: connected})
+		handler(peerID, connected)
+	})
+}
+
+func (t *recordingTransport) SetMessageHandler(handler func(peerID string, payload json.RawMessage)) {
+	hook, ok := t.Transport.(interface {
+		SetMessageHandler(func(peerID string, payload json.RawMessage))
+	})
+	if !ok {
+		return
+	}
+	hook.SetMessageHandler(func(peerID string, payload json.RawMessage) {
+		t.rec.record(ReplayEvent{Kind: ReplayEventPush, Peer: peerID, Args: append(json.RawMessage(nil), payload...)})
+		handler(peerID, payload)
+	})
+}
+
+func (t *recordingTransport) SetSecurityEventHandler(handler func(transport.TranscriptMismatch)) {
+	if hook, ok := t.Transport.(interface {
+		SetSecurityEventHandler(func(transport.TranscriptMismatch))
+	}); ok {
+		hook.SetSecurityEventHandler(handler)
+	}
+}
+
+func (t *recordingTransport) InjectSignalingChannel(url string, ch transport.SignalingChannel) {
+	if injector, ok := t.Transport.(interface {
+		InjectSignalingChannel(url string, ch transport.SignalingChannel)
+	}); ok {
+		injector.InjectSignalingChannel(url, ch)
+	}
+}
+
+func (t *recordingTransport) SetBackground(background bool) {
+	if bt, ok := t.Transport.(backgroundTransport); ok {
+		bt.SetBackground(background)
+	}
+}
+
+func (t *recordingTransport) NetworkChanged(online bool) {
+	if nt, ok := t.Transport.(networkTransport); ok {
+		nt.NetworkChanged(online)
+	}
+}
+
+func (t *recordingTransport) SetFaultInjector(injector common.FaultInjector) {
+	if hook, ok := t.Transport.(interface {
+		SetFaultInjector(common.FaultInjector)
+	}); ok {
+		hook.SetFaultInjector(injector)
+	}
+}
+
+// ReplayTransport stands in for the network during a replay. RPCs the
+// coordinator sends are answered from the recorded replies, in the order
+// they were recorded per peer and method; nothing leaves the process.
+type ReplayTransport struct {
+	mu        sync.Mutex
+	handlers  map[string]func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error)
+	replies   map[string][]ReplayEvent
+	connected map[string]struct{}
+	missing   int
+}
+
+// NewReplayTransport indexes the replies in events.
+func NewReplayTransport(events []ReplayEvent) *ReplayTransport {
+	t := &ReplayTransport{
+		handlers:  make(map[string]func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error)),
+		replies:   make(map[string][]ReplayEvent),
+		connected: make(map[string]struct{}),
+	}
+	for _, ev := range events {
+		if ev.Kind == ReplayEventReply || ev.Kind == ReplayEventStream {
+			key := replayReplyKey(ev.Kind, ev.Peer, ev.Method)
+			t.replies[key] = append(t.replies[key], ev)
+		}
+	}
+	return t
+}
+
+func replayReplyKey(kind ReplayEventKind, peerID, method string) string {
+	return string(kind) + "\x00" + peerID + "\x00" + method
+}
+
+// nextReply pops the next recorded reply for peerID and method.
+func (t *ReplayTransport) nextReply(kind ReplayEventKind, peerID, method string) (ReplayEvent, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	key := replayReplyKey(kind, peerID, method)
+	queue := t.replies[key]
+	if len(queue) == 0 {
+		t.missing++
+		return ReplayEvent{}, fmt.Errorf("%w: %s to %s", ErrReplayExhausted, method, peerID)
+	}
+	t.replies[key] = queue[1:]
+	return queue[0], nil
+}
+
+// MissingReplies returns how many sent RPCs found no recorded reply.
+func (t *ReplayTransport) MissingReplies() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.missing
+}
+
+func (t *ReplayTransport) handler(method string) func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.handlers[method]
+}
+
+func (t *ReplayTransport) setConnected(peerID string, connected bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if connected {
+		t.connected[peerID] = struct{}{}
+	} else {
+		delete(t.connected, peerID)
+	}
+}
+
+func (t *ReplayTransport) Start(ctx context.Context) error { return nil }
+func (t *ReplayTransport) Stop() error                     { return nil }
+
+func (t *ReplayTransport) Connect(ctx context.Context, peerID string) error {
+	if !t.IsConnected(peerID) {
+		return fmt.Errorf("peer %s not connected in replay", peerID)
+	}
+	return nil
+}
+
+func (t *ReplayTransport) Disconnect(peerID string) error { return nil }
+
+func (t *ReplayTransport) IsConnected(peerID string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	_, ok := t.connected[peerID]
+	return ok
+}
+
+func (t *ReplayTransport) GetConnectedPeers() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	peers := make([]string, 0, len(t.connected))
+	for peerID := range t.connected {
+		peers = append(peers, peerID)
+	}
+	return peers
+}
+
+func (t *ReplayTransport) Advertise(ctx context.Context, key string, value string) error {
+	return nil
+}
+
+func (t *ReplayTransport) FindPeers(ctx context.Context, key string) ([]PeerInfo, error) {
+	return nil, nil
+}
+
+func (t *ReplayTransport) SendRPC(ctx context.Context, peerID string, method string, args interface{}, reply interface{}) error {
+	ev, err := t.nextReply(ReplayEventReply, peerID, method)
+	if err != nil {
+		return err
+	}
+	if ev.Err != "" {
+		return errors.New(ev.Err)
+	}
+	if reply == nil || len(ev.Result) == 0 {
+		return nil
+	}
+	return json.Unmarshal(ev.Result, reply)
+}
+
+func (t *ReplayTransport) StreamRPC(ctx context.Context, peerID string, method string, args interface{}, writer io.Writer) (int64, error) {
+	ev, err := t.nextReply(ReplayEventStream, peerID, method)
+	if err != nil {
+		return 0, err
+	}
+	var body []byte
+	if len(ev.Result) > 0 {
+		if err := json.Unmarshal(ev.Result, &body); err != nil {
+			return 0, err
+		}
+	}
+	n, werr := writer.Write(body)
+	if werr != nil {
+		return int64(n), werr
+	}
+	if ev.Err != "" {
+		return int64(n), errors.New(ev.Err)
+	}
+	return int64(n), nil
+}
+
+func (t *ReplayTransport) SendMessage(ctx context.Context, peerID string, msg interface{}) error {
+	return nil
+}
+
+func (t *ReplayTransport) Broadcast(topic string, message interface{}) error { return nil }
+
+func (t *ReplayTransport) RegisterRPCHandler(method string, handler func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error)) {
+	t.mu.Lock()
+	t.handlers[method] = handler
+	t.mu.Unlock()
+}
+
+func (t *ReplayTransport) FindNode(ctx context.Context, peerID, targetID string) ([]PeerInfo, error) {
+	return nil, nil
+}
+
+func (t *ReplayTransport) FindValue(ctx context.Context, peerID, chunkHash string) ([]string, []PeerInfo, error) {
+	return nil, nil, nil
+}
+
+func (t *ReplayTransport) Store(ctx context.Context, peerID string, key string, value []byte) error {
+	return nil
+}
+
+func (t *ReplayTransport) Ping(ctx context.Context, peerID string) error {
+	return t.Connect(ctx, peerID)
+}
+
+func (t *ReplayTransport) GetPeerCapabilities(peerID string) (*PeerCapability, error) {
+	return nil, fmt.Errorf("peer %s unknown in replay", peerID)
+}
+
+func (t *ReplayTransport) UpdateLocalCapabilities(capabilities *PeerCapability) error {
+	return nil
+}
+
+func (t *ReplayTransport) GetConnectionMetrics() ConnectionMetrics {
+	return ConnectionMetrics{}
+}
+
+func (t *ReplayTransport) GetHealth() TransportHealth {
+	return TransportHealth{Status: "replay"}
+}
+
+func (t *ReplayTransport) GetStats() map[string]interface{} {
+	return map[string]interface{}{
+		"active_connections": uint32(len(t.GetConnectedPeers())),
+		"missing_replies":    t.MissingReplies(),
+	}
+}
+
+// ReplayDivergence is an inbound RPC whose replayed outcome differs from
+// the recorded one.
+type ReplayDivergence struct {
+	Seq      uint64 `json:"seq"`
+	Peer     string `json:"peer"`
+	Method   string `json:"method"`
+	Recorded string `json:"recorded"`
+	Replayed string `json:"replayed"`
+}
+
+// ReplayReport summarizes a replay run.
+type ReplayReport struct {
+	RPCs           int                `json:"rpcs"`
+	PeerEvents     int                `json:"peer_events"`
+	Messages       int                `json:"messages"`
+	Epochs         int                `json:"epochs"`
+	MissingReplies int                `json:"missing_replies"`
+	Divergences    []ReplayDivergence `json:"divergences,omitempty"`
+}
+
+// ReplayHarness feeds a recorded log into a fresh MeshCoordinator. The
+// coordinator is never started, so no background loop runs alongside the
+// log; events are applied one at a time in recorded order, without waiting
+// out the gaps between them.
+type ReplayHarness struct {
+	// Coordinator under replay. Storage, dispatcher and similar
+	// dependencies can be set on it before Run.
+	Coordinator *MeshCoordinator
+
+	transport *ReplayTransport
+	events    []ReplayEvent
+}
+
+// NewReplayHarness builds a coordinator with the recorded node ID and
+// region on top of a ReplayTransport.
+func NewReplayHarness(events []ReplayEvent, logger *slog.Logger) (*ReplayHarness, error) {
+	if len(events) == 0 || events[0].Kind != ReplayEventStart {
+		return nil, errors.New("replay log does not begin with a start event")
+	}
+	tr := NewReplayTransport(events)
+	return &ReplayHarness{
+		Coordinator: NewMeshCoordinator(events[0].Peer, events[0].Region, tr, logger),
+		transport:   tr,
+		events:      events,
+	}, nil
+}
+
+// Transport returns the replay transport behind the coordinator.
+func (h *ReplayHarness) Transport() *ReplayTransport {
+	return h.transport
+}
+
+// Run applies every event in the log and reports inbound RPCs whose
+// results no longer match the recording.
+func (h *ReplayHarness) Run(ctx context.Context) (ReplayReport, error) {
+	var report ReplayReport
+	for _, ev := range h.events {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		switch ev.Kind {
+		case ReplayEventRPC:
+			report.RPCs++
+			handler := h.transport.handler(ev.Method)
+			if handler == nil {
+				report.Divergences = append(report.Divergences, ReplayDivergence{
+					Seq: ev.Seq, Peer: ev.Peer, Method: ev.Method,
+					Recorded: replayOutcome(ev.Result, ev.Err),
+					Replayed: "no handler registered",
+				})
+				continue
+			}
+			result, err := handler(ctx, ev.Peer, ev.Args)
+			replayed := replayOutcome(marshalReplayValue(result), replayErrString(err))
+			if recorded := replayOutcome(ev.Result, ev.Err); recorded != replayed {
+				report.Divergences = append(report.Divergences, ReplayDivergence{
+					Seq: ev.Seq, Peer: ev.Peer, Method: ev.Method,
+					Recorded: recorded,
+					Replayed: replayed,
+				})
+			}
+
+		case ReplayEventPeer:
+			report.PeerEvents++
+			h.transport.setConnected(ev.Peer, ev.Connected)
+			h.Coordinator.handleTransportPeerEvent(ev.Peer, ev.Connected)
+
+		case ReplayEventPush:
+			report.Messages++
+			h.Coordinator.handleTransportMessage(ev.Peer, ev.Args)
+
+		case ReplayEventEpoch:
+			report.Epochs++
+			h.Coordinator.epochOptimizer.OnEpochBoundary(ev.Epoch)
+		}
+	}
+
+	report.MissingReplies = h.transport.MissingReplies()
+	return report, nil
+}
+
+func replayOutcome(result json.RawMessage, errText string) string {
+	if errText != "" {
+		return "error: " + errText
+	}
+	return string(result)
+}
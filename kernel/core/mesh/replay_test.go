@@ -0,0 +1,106 @@
+package mesh
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestReplay_RecordedSessionReplaysIntoFreshCoordinator(t *testing.T) {
+	tr := &MockTransport{
+		nodeID: "self",
+		rpcHandlers: map[string]func(args interface{}) (interface{}, error){
+			"test.echo": func(args interface{}) (interface{}, error) { return args, nil },
+		},
+	}
+	coord := NewMeshCoordinator("self", "eu-west", tr, nil)
+	coord.SetStorage(&MockStorage{chunks: make(map[string][]byte)})
+
+	var log bytes.Buffer
+	rec := coord.RecordReplay(&log)
+
+	// An inbound chunk.store from a peer, an outbound RPC and an epoch.
+	params, _ := json.Marshal(ChunkStoreRequest{ChunkHash: "chunk-1", Data: []byte("replayed payload")})
+	tr.mu.RLock()
+	handler := tr.registeredRPCHandlers[chunkStoreMethod]
+	tr.mu.RUnlock()
+	if _, err := handler(context.Background(), "peer-a", params); err != nil {
+		t.Fatalf("chunk.store failed: %v", err)
+	}
+	var echoed map[string]string
+	if err := coord.transport.SendRPC(context.Background(), "peer-b", "test.echo", map[string]string{"v": "1"}, &echoed); err != nil {
+		t.Fatalf("SendRPC failed: %v", err)
+	}
+	rec.record(ReplayEvent{Kind: ReplayEventEpoch, Epoch: 7})
+
+	if rec.Err() != nil || rec.Events() != 4 {
+		t.Fatalf("expected 4 recorded events, got %d (err %v)", rec.Events(), rec.Err())
+	}
+
+	events, err := ReadReplayLog(&log)
+	if err != nil {
+		t.Fatalf("ReadReplayLog failed: %v", err)
+	}
+	harness, err := NewReplayHarness(events, nil)
+	if err != nil {
+		t.Fatalf("NewReplayHarness failed: %v", err)
+	}
+	if harness.Coordinator.GetNodeID() != "self" || harness.Coordinator.region != "eu-west" {
+		t.Fatalf("harness did not restore node identity: %s/%s", harness.Coordinator.GetNodeID(), harness.Coordinator.region)
+	}
+	storage := &MockStorage{chunks: make(map[string][]byte)}
+	harness.Coordinator.SetStorage(storage)
+
+	report, err := harness.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if report.RPCs != 1 || report.Epochs != 1 || len(report.Divergences) != 0 {
+		t.Fatalf("unexpected replay report %+v", report)
+	}
+	if string(storage.chunks["chunk-1"]) != "replayed payload" {
+		t.Fatalf("replayed chunk.store did not reach storage: %q", storage.chunks["chunk-1"])
+	}
+	if epoch := harness.Coordinator.epochOptimizer.GetMetrics()["current_epoch"]; epoch != uint32(7) {
+		t.Fatalf("expected epoch 7 after replay, got %v", epoch)
+	}
+
+	// Outbound RPCs are answered from the log, once per recorded reply.
+	var replayed map[string]string
+	if err := harness.Coordinator.transport.SendRPC(context.Background(), "peer-b", "test.echo", nil, &replayed); err != nil {
+		t.Fatalf("replayed SendRPC failed: %v", err)
+	}
+	if replayed["v"] != "1" {
+		t.Fatalf("expected recorded reply, got %v", replayed)
+	}
+	err = harness.Coordinator.transport.SendRPC(context.Background(), "peer-b", "test.echo", nil, &replayed)
+	if !errors.Is(err, ErrReplayExhausted) {
+		t.Fatalf("expected ErrReplayExhausted, got %v", err)
+	}
+}
+
+func TestReplay_ReportsDivergingResults(t *testing.T) {
+	events := []ReplayEvent{
+		{Seq: 1, Kind: ReplayEventStart, Peer: "self"},
+		{Seq: 2, Kind: ReplayEventRPC, Peer: "peer-a", Method: chunkStoreMethod, Args: json.RawMessage(`{"chunk_hash":"c","data":"eA=="}`), Result: json.RawMessage(`{"stored":true}`)},
+	}
+	harness, err := NewReplayHarness(events, nil)
+	if err != nil {
+		t.Fatalf("NewReplayHarness failed: %v", err)
+	}
+
+	// No storage configured: the handler now fails where it once succeeded.
+	report, err := harness.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(report.Divergences) != 1 || report.Divergences[0].Seq != 2 {
+		t.Fatalf("expected one divergence at seq 2, got %+v", report.Divergences)
+	}
+
+	if _, err := NewReplayHarness(events[1:], nil); err == nil {
+		t.Fatal("expected a log without a start event to be rejected")
+	}
+}
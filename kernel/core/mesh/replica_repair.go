@@ -0,0 +1,290 @@
+package mesh
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+)
+
+// ReplicaRepairStats summarizes the re-replication loop.
+type ReplicaRepairStats struct {
+	Tracked         int    `json:"tracked"`          // Chunks this node originated and still watches
+	Checked         uint64 `json:"checked"`          // Chunk checks across all passes
+	UnderReplicated uint64 `json:"under_replicated"` // Checks that found fewer live replicas than the target
+	ReplicasPlaced  uint64 `json:"replicas_placed"`
+	BytesSent       uint64 `json:"bytes_sent"`
+	Failures        uint64 `json:"failures"` // Checks that left a chunk below target
+}
+
+// replicaRepairState remembers the chunks this node distributed, so their
+// replication can be restored after the peers holding them go away.
+type replicaRepairState struct {
+	mu         sync.Mutex
+	originated map[string]originatedChunk
+	cursor     int // Where the next sample starts in the sorted targets
+
+	throttle replicaThrottle
+
+	checked         atomic.Uint64
+	underReplicated atomic.Uint64
+	placed          atomic.Uint64
+	bytesSent       atomic.Uint64
+	failures        atomic.Uint64
+}
+
+type originatedChunk struct {
+	size int
+	at   time.Time // When it was distributed
+}
+
+// replicaThrottle spaces repair sends so they average out to a byte rate.
+type replicaThrottle struct {
+	mu   sync.Mutex
+	next time.Time // Earliest time the next send may start
+}
+
+// reserve books size bytes at rate and returns how long the caller must
+// wait before sending them.
+func (t *replicaThrottle) reserve(size int, rate int64, now time.Time) time.Duration {
+	if rate <= 0 {
+		return 0
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	start := t.next
+	if start.Before(now) {
+		start = now
+	}
+	t.next = start.Add(time.Duration(float64(size) / float64(rate) * float64(time.Second)))
+	return start.Sub(now)
+}
+
+// replicaRepairTarget is one chunk the repair pass checks.
+type replicaRepairTarget struct {
+	hash     string
+	size     int
+	replicas int // 0 means the allocator decides from size and demand
+}
+
+// trackOriginatedChunk adds a distributed chunk to the set the repair loop
+// watches, dropping the oldest entries beyond Repair.MaxTracked.
+func (m *MeshCoordinator) trackOriginatedChunk(chunkHash string, size int) {
+	s := &m.replicaRepair
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.originated == nil {
+		s.originated = make(map[string]originatedChunk)
+	}
+	s.originated[chunkHash] = originatedChunk{size: size, at: time.Now()}
+
+	limit := m.config.Repair.MaxTracked
+	if limit <= 0 || len(s.originated) <= limit {
+		return
+	}
+	hashes := make([]string, 0, len(s.originated))
+	for hash := range s.originated {
+		hashes = append(hashes, hash)
+	}
+	sort.Slice(hashes, func(i, j int) bool { return s.originated[hashes[i]].at.Before(s.originated[hashes[j]].at) })
+	for _, hash := range hashes[:len(hashes)-limit] {
+		delete(s.originated, hash)
+	}
+}
+
+// UntrackOriginatedChunk stops the repair loop watching a chunk this node
+// distributed, e.g. once the application deletes it.
+func (m *MeshCoordinator) UntrackOriginatedChunk(chunkHash string) {
+	s := &m.replicaRepair
+	s.mu.Lock()
+	delete(s.originated, chunkHash)
+	s.mu.Unlock()
+}
+
+// GetReplicaRepairStats returns the re-replication counters.
+func (m *MeshCoordinator) GetReplicaRepairStats() ReplicaRepairStats {
+	s := &m.replicaRepair
+	s.mu.Lock()
+	tracked := len(s.originated)
+	s.mu.Unlock()
+	return ReplicaRepairStats{
+		Tracked:         tracked,
+		Checked:         s.checked.Load(),
+		UnderReplicated: s.underReplicated.Load(),
+		ReplicasPlaced:  s.placed.Load(),
+		BytesSent:       s.bytesSent.Load(),
+		Failures:        s.failures.Load(),
+	}
+}
+
+// repairReplicationSample checks the next Repair.SampleSize chunks among
+// those this node originated or pins, and re-distributes each one whose live
+// replica count fell below target. It returns the replicas placed.
+func (m *MeshCoordinator) repairReplicationSample(ctx context.Context) int {
+	targets := m.replicaRepairTargets()
+	if len(targets) == 0 {
+		return 0
+	}
+
+	s := &m.replicaRepair
+	n := len(targets)
+	if size := m.config.Repair.SampleSize; size > 0 && size < n {
+		n = size
+	}
+	s.mu.Lock()
+	start := s.cursor % len(targets)
+	s.cursor = start + n
+	s.mu.Unlock()
+
+	placed := 0
+	for i := 0; i < n; i++ {
+		if ctx.Err() != nil {
+			break
+		}
+		target := targets[(start+i)%len(targets)]
+		p, err := m.repairReplication(ctx, target)
+		placed += p
+		if err != nil {
+			s.failures.Add(1)
+			m.logger.Warn("chunk below replica target",
+				"chunk", getShortID(target.hash),
+				"error", err,
+			)
+		}
+	}
+	if placed > 0 {
+		m.logger.Info("re-replicated chunks", "replicas_placed", placed)
+	}
+	return placed
+}
+
+// repairReplication brings one chunk back to its target. Only providers the
+// routing table still knows count: a departed peer's provider record can
+// outlive it by a full TTL.
+func (m *MeshCoordinator) repairReplication(ctx context.Context, target replicaRepairTarget) (int, error) {
+	s := &m.replicaRepair
+	s.checked.Add(1)
+
+	providers, _ := m.dht.FindPeers(target.hash)
+	live := 0
+	for _, p := range m.otherProviders(providers) {
+		if _, ok := m.dht.GetPeer(p); ok {
+			live++
+		}
+	}
+
+	local := false
+	if m.storage != nil {
+		local, _ = m.storage.HasChunk(ctx, target.hash)
+	}
+
+	replicas := live
+	if local {
+		replicas++
+	}
+	want := target.replicas
+	if want <= 0 {
+		want = m.allocator.CalculateReplicas(common.Resource{
+			Size:        uint64(target.size),
+			Type:        "chunk",
+			DemandScore: m.demandTracker.GetDemandScore(target.hash),
+		})
+	}
+	if replicas >= want {
+		return 0, nil
+	}
+	s.underReplicated.Add(1)
+
+	var data []byte
+	if local {
+		data, _ = m.storage.FetchChunk(ctx, target.hash)
+	}
+	if data == nil {
+		fetched, err := m.fetchStoredChunk(ctx, target.hash)
+		if err != nil {
+			return 0, fmt.Errorf("no reachable copy: %w", err)
+		}
+		data = fetched
+	}
+
+	placed := 0
+	for replicas < want {
+		if !m.waitRepairBandwidth(len(data)) {
+			return placed, context.Canceled
+		}
+		if _, err := m.placeReplica(ctx, target.hash, data, ""); err != nil {
+			return placed, fmt.Errorf("%d of %d live replicas: %w", replicas, want, err)
+		}
+		s.placed.Add(1)
+		s.bytesSent.Add(uint64(len(data)))
+		placed++
+		replicas++
+	}
+	return placed, nil
+}
+
+// waitRepairBandwidth holds a repair send until Repair.MaxBytesPerSecond
+// allows it. It returns false if the coordinator shuts down meanwhile.
+func (m *MeshCoordinator) waitRepairBandwidth(size int) bool {
+	wait := m.replicaRepair.throttle.reserve(size, m.config.Repair.MaxBytesPerSecond, time.Now())
+	if wait <= 0 {
+		return true
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-m.shutdown:
+		return false
+	}
+}
+
+// replicaRepairTargets lists originated and pinned chunks in hash order. A
+// pin's replica count overrides the allocator's target.
+func (m *MeshCoordinator) replicaRepairTargets() []replicaRepairTarget {
+	byHash := make(map[string]replicaRepairTarget)
+	s := &m.replicaRepair
+	s.mu.Lock()
+	for hash, chunk := range s.originated {
+		byHash[hash] = replicaRepairTarget{hash: hash, size: chunk.size}
+	}
+	s.mu.Unlock()
+	for _, pin := range m.pinTargets() {
+		target := byHash[pin.hash]
+		target.hash, target.replicas = pin.hash, pin.replicas
+		byHash[pin.hash] = target
+	}
+
+	targets := make([]replicaRepairTarget, 0, len(byHash))
+	for _, target := range byHash {
+		targets = append(targets, target)
+	}
+	sort.Slice(targets, func(i, j int) bool { return targets[i].hash < targets[j].hash })
+	return targets
+}
+
+func (m *MeshCoordinator) replicaRepairLoop() {
+	if m.config.Repair.Interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(m.config.Repair.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if m.deferLowPriority() {
+				continue
+			}
+			m.repairReplicationSample(context.Background())
+		case <-m.shutdown:
+			return
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package mesh
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+)
+
+func TestReplicaRepair_RestoresOriginatedChunkIgnoringDepartedProviders(t *testing.T) {
+	coord := NewMeshCoordinator("self", "us-east", &MockTransport{nodeID: "self"}, nil)
+	data := []byte("originated data")
+	coord.SetStorage(&MockStorage{chunks: map[string][]byte{"chunk-a": data}})
+	coord.config.Repair.MaxBytesPerSecond = 0
+	for i := 1; i <= 12; i++ {
+		id := fmt.Sprintf("peer-%d", i)
+		_ = coord.dht.AddPeer(PeerInfo{ID: id, Capabilities: &PeerCapability{PeerID: id, Reputation: 0.9}})
+	}
+	// peer-gone left the mesh but its provider record has not expired.
+	_ = coord.dht.Store("chunk-a", "peer-1", 3600)
+	_ = coord.dht.Store("chunk-a", "peer-gone", 3600)
+	coord.trackOriginatedChunk("chunk-a", len(data))
+
+	want := coord.allocator.CalculateReplicas(common.Resource{
+		Size:        uint64(len(data)),
+		Type:        "chunk",
+		DemandScore: coord.demandTracker.GetDemandScore("chunk-a"),
+	})
+	// Live copies are the local one and peer-1.
+	if placed := coord.repairReplicationSample(context.Background()); placed != want-2 {
+		t.Fatalf("expected %d replicas placed, got %d", want-2, placed)
+	}
+	if placed := coord.repairReplicationSample(context.Background()); placed != 0 {
+		t.Fatalf("expected no work once at target, got %d", placed)
+	}
+
+	stats := coord.GetReplicaRepairStats()
+	if stats.Tracked != 1 || stats.Checked != 2 || stats.UnderReplicated != 1 ||
+		stats.ReplicasPlaced != uint64(want-2) || stats.BytesSent != uint64((want-2)*len(data)) {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestReplicaRepair_SamplesRoundRobinAndCapsTracking(t *testing.T) {
+	coord := NewMeshCoordinator("self", "us-east", &MockTransport{nodeID: "self"}, nil)
+	coord.config.Repair.SampleSize = 2
+	coord.config.Repair.MaxTracked = 3
+	for _, hash := range []string{"a", "b", "c", "d"} {
+		coord.trackOriginatedChunk(hash, 10)
+	}
+	if stats := coord.GetReplicaRepairStats(); stats.Tracked != 3 {
+		t.Fatalf("expected the oldest chunk dropped, tracking %d", stats.Tracked)
+	}
+	_ = coord.PinChunk("pinned", 1)
+
+	// No copies are reachable, so every check fails; only the count matters.
+	for pass := 0; pass < 2; pass++ {
+		coord.repairReplicationSample(context.Background())
+	}
+	if stats := coord.GetReplicaRepairStats(); stats.Checked != 4 || stats.Failures != 4 {
+		t.Fatalf("expected 4 distinct checks over two passes, got %+v", stats)
+	}
+}
+
+func TestReplicaThrottle_SpacesSendsToRate(t *testing.T) {
+	var throttle replicaThrottle
+	now := time.Now()
+	if wait := throttle.reserve(1000, 1000, now); wait != 0 {
+		t.Fatalf("expected the first send to go at once, waited %v", wait)
+	}
+	if wait := throttle.reserve(500, 1000, now); wait != time.Second {
+		t.Fatalf("expected a one second wait, got %v", wait)
+	}
+	if wait := throttle.reserve(500, 1000, now.Add(5*time.Second)); wait != 0 {
+		t.Fatalf("expected idle time not to bank a wait, got %v", wait)
+	}
+	if wait := throttle.reserve(1<<20, 0, now); wait != 0 {
+		t.Fatalf("expected no throttling at rate 0, got %v", wait)
+	}
+}
@@ -0,0 +1,86 @@
+package mesh
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/nmxmxh/inos_v1/kernel/runtime"
+)
+
+var (
+	// ErrComputeNotOffered is returned for jobs sent to a node whose role
+	// profile does not run remote compute.
+	ErrComputeNotOffered = errors.New("node's role profile does not accept remote compute")
+	// ErrStorageNotOffered is returned for replicas pushed to a node whose
+	// role profile does not store chunks for peers.
+	ErrStorageNotOffered = errors.New("node's role profile does not accept chunk storage")
+)
+
+// Advertised by nodes whose profile withholds a contribution, so peers leave
+// them out of job and replica placement.
+const (
+	noComputeCapability = "no-compute"
+	noStorageCapability = "no-storage"
+)
+
+// applyContribution installs what the role profile offers peers. A zero
+// contribution is the balanced profile.
+func (m *MeshCoordinator) applyContribution(c runtime.Contribution) {
+	if c.Profile == "" {
+		c = runtime.Contribution{Profile: runtime.ProfileBalanced, AcceptCompute: true, AcceptStorage: true}
+	}
+	m.contribution.Store(&c)
+	if c.StorageQuotaBytes > 0 {
+		m.SetStorageQuota(c.StorageQuotaBytes)
+	}
+	m.logger.Info("role profile applied",
+		"profile", c.Profile,
+		"accept_compute", c.AcceptCompute,
+		"accept_storage", c.AcceptStorage,
+		"storage_quota", c.StorageQuotaBytes)
+}
+
+// Contribution returns what this node offers peers.
+func (m *MeshCoordinator) Contribution() runtime.Contribution {
+	if c := m.contribution.Load(); c != nil {
+		return *c
+	}
+	return runtime.Contribution{Profile: runtime.ProfileBalanced, AcceptCompute: true, AcceptStorage: true}
+}
+
+// admitProfileCompute refuses remote jobs the role profile does not offer.
+func (m *MeshCoordinator) admitProfileCompute() error {
+	if !m.Contribution().AcceptCompute {
+		return ErrComputeNotOffered
+	}
+	return nil
+}
+
+// admitProfileStorage refuses replicas the role profile does not offer.
+func (m *MeshCoordinator) admitProfileStorage() error {
+	if !m.Contribution().AcceptStorage {
+		return ErrStorageNotOffered
+	}
+	return nil
+}
+
+// contributionCapabilities lists the markers advertised for withheld
+// contributions.
+func (m *MeshCoordinator) contributionCapabilities() []string {
+	c := m.Contribution()
+	var capabilities []string
+	if !c.AcceptCompute {
+		capabilities = append(capabilities, noComputeCapability)
+	}
+	if !c.AcceptStorage {
+		capabilities = append(capabilities, noStorageCapability)
+	}
+	return capabilities
+}
+
+// peerWithholds reports whether a peer advertised that it does not offer
+// the contribution marked by capability.
+func (m *MeshCoordinator) peerWithholds(peerID, capability string) bool {
+	peer := m.getCachedPeer(peerID)
+	return peer != nil && slices.Contains(peer.Capabilities, capability)
+}
@@ -0,0 +1,89 @@
+package mesh
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+
+	system "github.com/nmxmxh/inos_v1/kernel/gen/system/v1"
+	"github.com/nmxmxh/inos_v1/kernel/runtime"
+)
+
+func TestApplyRoleProfile_AdjustsSubsystemsTogether(t *testing.T) {
+	detected := runtime.RoleConfig{
+		Role:         system.Runtime_RuntimeRole_synapse,
+		GossipFanout: 6,
+		MaxPeers:     50,
+		CanRelay:     true,
+		Memory:       runtime.MemoryProfileFor(runtime.MemoryHigh),
+	}
+
+	balanced, err := runtime.ApplyRoleProfile(detected, "")
+	if err != nil {
+		t.Fatalf("ApplyRoleProfile failed: %v", err)
+	}
+	if balanced.Contribution.Profile != runtime.ProfileBalanced || !balanced.Contribution.AcceptCompute ||
+		!balanced.Contribution.AcceptStorage || balanced.GossipFanout != 6 || balanced.Memory != detected.Memory {
+		t.Fatalf("expected the empty profile to keep the detected config, got %+v", balanced)
+	}
+
+	relay, _ := runtime.ApplyRoleProfile(detected, runtime.ProfileRelay)
+	if relay.GossipFanout != 12 || relay.MaxPeers != 100 || !relay.CanRelay || relay.Memory.MaxWorkers != 1 ||
+		relay.Memory.WarmStandby || relay.Contribution.AcceptCompute || relay.Contribution.AcceptStorage {
+		t.Fatalf("unexpected relay config: %+v", relay)
+	}
+
+	observer, _ := runtime.ApplyRoleProfile(detected, runtime.ProfileObserver)
+	if observer.GossipFanout != 3 || observer.CanRelay || observer.Memory.ChunkCacheEntries >= detected.Memory.ChunkCacheEntries {
+		t.Fatalf("unexpected observer config: %+v", observer)
+	}
+
+	if _, err := runtime.ApplyRoleProfile(detected, "miner"); err == nil {
+		t.Fatal("expected an unknown profile to be rejected")
+	}
+}
+
+func TestRoleProfile_DeclinesWithheldContributions(t *testing.T) {
+	coord, tr := newDelegationTestCoordinator(t)
+	coord.SetStorage(&MockStorage{chunks: make(map[string][]byte)})
+
+	config, _ := runtime.ApplyRoleProfile(runtime.RoleConfig{GossipFanout: 4}, runtime.ProfileStorageHeavy)
+	coord.ApplyRoleConfig(config)
+
+	job := json.RawMessage(`{"id":"job-1","operation":"hash"}`)
+	if _, err := tr.registeredRPCHandlers[executeJobMethod](capabilityContextFor(t, coord, "peer-1", executeJobMethod), "peer-1", job); !errors.Is(err, ErrComputeNotOffered) {
+		t.Fatalf("expected ErrComputeNotOffered, got %v", err)
+	}
+	if coord.storageQuota.Usage().MaxBytes != config.Contribution.StorageQuotaBytes {
+		t.Fatalf("expected the profile's storage quota, got %+v", coord.storageQuota.Usage())
+	}
+	if caps := coord.contributionCapabilities(); !slices.Equal(caps, []string{noComputeCapability}) {
+		t.Fatalf("expected only no-compute advertised, got %v", caps)
+	}
+
+	config, _ = runtime.ApplyRoleProfile(runtime.RoleConfig{GossipFanout: 4}, runtime.ProfileComputeOnly)
+	coord.ApplyRoleConfig(config)
+	store := json.RawMessage(`{"chunk_hash":"abc","data":"aGk="}`)
+	if _, err := tr.registeredRPCHandlers[chunkStoreMethod](context.Background(), "peer-1", store); !errors.Is(err, ErrStorageNotOffered) {
+		t.Fatalf("expected ErrStorageNotOffered, got %v", err)
+	}
+}
+
+func TestRoleProfile_PlacementSkipsPeersWithholding(t *testing.T) {
+	coord, _ := newDelegationTestCoordinator(t)
+	coord.cachePeer("peer-1", &PeerCapability{PeerID: "peer-1", Capabilities: []string{noComputeCapability}})
+
+	if peer, _ := coord.selectBestPeerForJob(); peer != "" {
+		t.Fatalf("expected no job peer when the only peer withholds compute, got %q", peer)
+	}
+
+	peers := []PeerInfo{
+		{ID: "peer-1", Capabilities: &PeerCapability{PeerID: "peer-1", Capabilities: []string{noStorageCapability}}},
+		{ID: "peer-2", Capabilities: &PeerCapability{PeerID: "peer-2"}},
+	}
+	if scored := coord.scorePeers(peers); len(scored) != 1 || scored[0].ID != "peer-2" {
+		t.Fatalf("expected only peer-2 for replicas, got %+v", scored)
+	}
+}
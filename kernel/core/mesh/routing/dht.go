@@ -8,6 +8,7 @@ import (
 	"math/big"
 	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
@@ -24,7 +25,14 @@ type DHTMetrics struct {
 	TotalQueries      int64   `json:"total_queries"`
 	SuccessfulLookups int64   `json:"successful_lookups"`
 	FailedQueries     int64   `json:"failed_queries"`
-	storeMu           sync.RWMutex
+	ExpiredProviders  int64   `json:"expired_providers"`
+
+	// Attack counters (see dht_security.go)
+	RejectedNodeIDs     int64 `json:"rejected_node_ids"`    // Peers refused because their ID does not match their key
+	InvalidNodeReplies  int64 `json:"invalid_node_replies"` // Forged entries dropped from lookup replies
+	DiversityRejections int64 `json:"diversity_rejections"` // Peers refused by the per-bucket subnet limit
+	DisjointLookups     int64 `json:"disjoint_lookups"`     // Lookups run over more than one path
+	storeMu             sync.RWMutex
 }
 
 // DHT implements a Kademlia-like distributed hash table.
@@ -35,11 +43,25 @@ type DHT struct {
 	// Local storage of values (ChunkHash -> Peers)
 	store   sync.Map
 	storeMu sync.RWMutex
+	expiry  map[string]map[string]time.Time // ChunkHash -> provider -> expiry, guarded by storeMu
+
+	// Signed mutable records by record key (see dht_records.go)
+	records   map[string]*MutableRecord
+	recordsMu sync.RWMutex
 
 	// Known peers lookup (ID -> PeerInfo)
 	peers   map[string]common.PeerInfo
 	peersMu sync.RWMutex
 
+	// Client mode: no record hosting or query serving (see dht_mode.go)
+	clientMode  atomic.Bool
+	clientPeers map[string]struct{} // Peers that advertised client mode, guarded by peersMu
+
+	// Lookup and routing table hardening (see dht_security.go)
+	security   DHTSecurityConfig
+	verifyID   NodeIDVerifier
+	securityMu sync.RWMutex
+
 	alpha int // Concurrency parameter (default 3)
 	k     int // Replication factor (default 20)
 
@@ -50,19 +72,27 @@ type DHT struct {
 
 func NewDHT(nodeID string, transport common.Transport, logger interface{}) *DHT { // Updated signature
 	dht := &DHT{
-		nodeID:    nodeID,
-		buckets:   make([][]common.PeerInfo, 160),
-		peers:     make(map[string]common.PeerInfo),
-		alpha:     3,
-		k:         20,
-		transport: transport,
-		metrics:   &DHTMetrics{},
+		nodeID:      nodeID,
+		buckets:     make([][]common.PeerInfo, 160),
+		peers:       make(map[string]common.PeerInfo),
+		clientPeers: make(map[string]struct{}),
+		expiry:      make(map[string]map[string]time.Time),
+		records:     make(map[string]*MutableRecord),
+		security:    DefaultDHTSecurityConfig(),
+		alpha:       3,
+		k:           20,
+		transport:   transport,
+		metrics:     &DHTMetrics{},
 	}
 
 	for i := range dht.buckets {
 		dht.buckets[i] = make([]common.PeerInfo, 0)
 	}
 
+	if transport != nil {
+		dht.registerRPCHandlers()
+	}
+
 	return dht
 }
 
@@ -224,10 +254,27 @@ func (d *DHT) getAverageChunksPerNode() float64 {
 	return float64(localCount) // Assume we are average
 }
 
-// Store advertises that a specific peer has a chunk.
+// Store advertises that a specific peer has a chunk for ttlSeconds; zero
+// means DefaultProviderTTL.
 func (d *DHT) Store(chunkHash string, peerID string, ttlSeconds int64) error {
+	d.storeProvider(chunkHash, peerID, providerTTL(ttlSeconds))
+
+	// Replicate to K closest nodes to ensure persistence
+	go d.replicateChunk(chunkHash, peerID, ttlSeconds)
+
+	return nil
+}
+
+// storeLocal records a provider with the default TTL without replicating it.
+func (d *DHT) storeLocal(chunkHash string, peerID string) {
+	d.storeProvider(chunkHash, peerID, DefaultProviderTTL)
+}
+
+// storeProvider records a provider for ttl without replicating it.
+func (d *DHT) storeProvider(chunkHash string, peerID string, ttl time.Duration) {
 	d.storeMu.Lock()
 	defer d.storeMu.Unlock()
+	d.setExpiryLocked(chunkHash, peerID, ttl)
 
 	// Update local knowledge first (Optimistic)
 	existing, exists := d.store.Load(chunkHash)
@@ -251,11 +298,6 @@ func (d *DHT) Store(chunkHash string, peerID string, ttlSeconds int64) error {
 	}
 
 	d.store.Store(chunkHash, peerList)
-
-	// Replicate to K closest nodes to ensure persistence
-	go d.replicateChunk(chunkHash, peerID)
-
-	return nil
 }
 
 // RemoveChunkPeer removes a peer from a chunk's advertisement list.
@@ -263,6 +305,13 @@ func (d *DHT) RemoveChunkPeer(chunkHash string, peerID string) error {
 	d.storeMu.Lock()
 	defer d.storeMu.Unlock()
 
+	if providers, ok := d.expiry[chunkHash]; ok {
+		delete(providers, peerID)
+		if len(providers) == 0 {
+			delete(d.expiry, chunkHash)
+		}
+	}
+
 	existing, exists := d.store.Load(chunkHash)
 	if !exists {
 		return nil
@@ -282,6 +331,36 @@ func (d *DHT) RemoveChunkPeer(chunkHash string, peerID string) error {
 	return nil
 }
 
+// RemoveProvider drops every record naming peerID as a provider and returns
+// how many chunks it was removed from.
+func (d *DHT) RemoveProvider(peerID string) int {
+	var chunks []string
+	d.store.Range(func(key, value interface{}) bool {
+		for _, p := range value.([]string) {
+			if p == peerID {
+				chunks = append(chunks, key.(string))
+				break
+			}
+		}
+		return true
+	})
+	for _, chunkHash := range chunks {
+		_ = d.RemoveChunkPeer(chunkHash, peerID)
+	}
+	return len(chunks)
+}
+
+// LocalProviders returns the providers this node knows for a chunk without
+// querying the network.
+func (d *DHT) LocalProviders(chunkHash string) []string {
+	d.storeMu.RLock()
+	defer d.storeMu.RUnlock()
+	if peers, ok := d.store.Load(chunkHash); ok {
+		return append([]string(nil), peers.([]string)...)
+	}
+	return nil
+}
+
 // FindPeers locates nodes that possess the given chunk.
 func (d *DHT) FindPeers(chunkHash string) ([]string, error) {
 	d.storeMu.RLock()
@@ -323,6 +402,10 @@ func (d *DHT) AddPeer(peer common.PeerInfo) error {
 	if peer.ID == d.nodeID {
 		return nil
 	}
+	if err := d.verifyPeer(peer); err != nil {
+		d.incMetric(&d.metrics.RejectedNodeIDs)
+		return err
+	}
 
 	bucketIdx := d.getBucketIndex(peer.ID)
 
@@ -344,6 +427,10 @@ func (d *DHT) AddPeer(peer common.PeerInfo) error {
 	}
 
 	// Not in bucket
+	if !d.bucketAllows(bucket, peer) {
+		d.incMetric(&d.metrics.DiversityRejections)
+		return ErrBucketDiversity
+	}
 	if len(bucket) < d.k {
 		// Add to end
 		bucket = append(bucket, peer)
@@ -427,103 +514,15 @@ func (d *DHT) lookupChunk(chunkHash string) ([]string, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	// 1. Get k closest nodes from local routing table
-	shortlist := d.FindNode(chunkHash)
-	if len(shortlist) == 0 {
-		return nil, errors.New("no peers in routing table")
-	}
-
-	// 2. Sort by distance
-	sort.Slice(shortlist, func(i, j int) bool {
-		distI := d.distance(shortlist[i].ID, chunkHash)
-		distJ := d.distance(shortlist[j].ID, chunkHash)
-		return distI.Cmp(distJ) < 0
-	})
-
-	// 3. Track visited and found
-	visited := make(map[string]bool)
-	visited[d.nodeID] = true
-
-	var providers []string
-	const maxRounds = 5
-
-	for round := 0; round < maxRounds; round++ {
-		// 4. Pick alpha unvisited nodes
-		var candidates []common.PeerInfo
-		for _, peer := range shortlist {
-			if !visited[peer.ID] && len(candidates) < d.alpha {
-				candidates = append(candidates, peer)
-				visited[peer.ID] = true
-			}
-		}
-
-		if len(candidates) == 0 {
-			break
-		}
-
-		// 5. Parallel queries
-		var mu sync.Mutex
-		var wg sync.WaitGroup
-
-		for _, peer := range candidates {
-			wg.Add(1)
-			go func(p common.PeerInfo) {
-				defer wg.Done()
-
-				if d.transport == nil {
-					return
-				}
-
-				// Call transport
-				values, closerPeers, err := d.transport.FindValue(ctx, p.ID, chunkHash)
-				if err != nil {
-					d.metrics.FailedQueries++
-					return
-				}
-
-				mu.Lock()
-				defer mu.Unlock()
-
-				// Add providers if found
-				if len(values) > 0 {
-					providers = append(providers, values...)
-				}
-
-				// Update shortlist with closer peers
-				for _, closer := range closerPeers {
-					// Add if not already in shortlist
-					found := false
-					for _, existing := range shortlist {
-						if existing.ID == closer.ID {
-							found = true
-							break
-						}
-					}
-					if !found {
-						shortlist = append(shortlist, closer)
-					}
-				}
-			}(peer)
-		}
-
-		wg.Wait()
-
-		// 6. Check if we found enough providers
-		if len(providers) >= d.k {
-			break
-		}
-
-		// 7. Re-sort shortlist for next round
-		sort.Slice(shortlist, func(i, j int) bool {
-			distI := d.distance(shortlist[i].ID, chunkHash)
-			distJ := d.distance(shortlist[j].ID, chunkHash)
-			return distI.Cmp(distJ) < 0
-		})
-
-		// Keep only k closest
-		if len(shortlist) > d.k {
-			shortlist = shortlist[:d.k]
+	_, providers, err := d.disjointLookup(ctx, chunkHash, d.k, func(ctx context.Context, p common.PeerInfo) ([]common.PeerInfo, []string, error) {
+		if d.transport == nil {
+			return nil, nil, nil
 		}
+		values, closer, err := d.transport.FindValue(ctx, p.ID, chunkHash)
+		return closer, values, err
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	d.metrics.storeMu.Lock()
@@ -542,7 +541,7 @@ func (d *DHT) lookupChunk(chunkHash string) ([]string, error) {
 	return providers, nil
 }
 
-func (d *DHT) replicateChunk(chunkHash, peerID string) {
+func (d *DHT) replicateChunk(chunkHash, peerID string, ttlSeconds int64) {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
@@ -561,99 +560,24 @@ func (d *DHT) replicateChunk(chunkHash, peerID string) {
 			defer wg.Done()
 			if d.transport != nil {
 				// We store the provider ID as the value
-				_ = d.transport.Store(ctx, peer.ID, chunkHash, []byte(peerID))
+				_ = d.sendStore(ctx, peer.ID, chunkHash, peerID, ttlSeconds)
 			}
 		}(p)
 	}
 	wg.Wait()
 }
 
-// iterativeFindNode performs the Kademlia Node Lookup to find the K closest nodes to a target
+// iterativeFindNode performs the Kademlia Node Lookup to find the K closest
+// nodes to a target, over disjoint paths.
 func (d *DHT) iterativeFindNode(ctx context.Context, targetID string) ([]common.PeerInfo, error) {
-	// 1. Start with alpha closest nodes from local routing table
-	shortlist := d.FindNode(targetID)
-	if len(shortlist) == 0 {
-		return nil, errors.New("no peers in routing table")
-	}
-
-	// 2. Sort by distance
-	sort.Slice(shortlist, func(i, j int) bool {
-		distI := d.distance(shortlist[i].ID, targetID)
-		distJ := d.distance(shortlist[j].ID, targetID)
-		return distI.Cmp(distJ) < 0
-	})
-
-	visited := make(map[string]bool)
-	visited[d.nodeID] = true
-
-	// Keep track of the closest node found so far to detect convergence
-	// closestNode := shortlist[0]
-
-	const maxRounds = 5
-	for round := 0; round < maxRounds; round++ {
-		// Pick alpha unvisited
-		var candidates []common.PeerInfo
-		for _, peer := range shortlist {
-			if !visited[peer.ID] && len(candidates) < d.alpha {
-				candidates = append(candidates, peer)
-				visited[peer.ID] = true
-			}
+	closest, _, err := d.disjointLookup(ctx, targetID, 0, func(ctx context.Context, p common.PeerInfo) ([]common.PeerInfo, []string, error) {
+		if d.transport == nil {
+			return nil, nil, nil
 		}
-
-		if len(candidates) == 0 {
-			break
-		}
-
-		var mu sync.Mutex
-		var wg sync.WaitGroup
-
-		for _, peer := range candidates {
-			wg.Add(1)
-			go func(p common.PeerInfo) {
-				defer wg.Done()
-				if d.transport == nil {
-					return
-				}
-
-				// FIND_NODE RPC
-				nodes, err := d.transport.FindNode(ctx, p.ID, targetID)
-				if err != nil {
-					return
-				}
-
-				mu.Lock()
-				defer mu.Unlock()
-
-				for _, n := range nodes {
-					// Add if new
-					found := false
-					for _, existing := range shortlist {
-						if existing.ID == n.ID {
-							found = true
-							break
-						}
-					}
-					if !found && n.ID != d.nodeID {
-						shortlist = append(shortlist, n)
-					}
-				}
-			}(peer)
-		}
-		wg.Wait()
-
-		// Sort and trim
-		sort.Slice(shortlist, func(i, j int) bool {
-			distI := d.distance(shortlist[i].ID, targetID)
-			distJ := d.distance(shortlist[j].ID, targetID)
-			return distI.Cmp(distJ) < 0
-		})
-
-		if len(shortlist) > d.k {
-			shortlist = shortlist[:d.k]
-		}
-	}
-
-	return shortlist, nil
+		nodes, err := d.transport.FindNode(ctx, p.ID, targetID)
+		return nodes, nil, err
+	})
+	return closest, err
 }
 
 func (d *DHT) Refresh() {
@@ -725,10 +649,15 @@ func (d *DHT) LoadState(store DHTStore) error {
 		return err
 	}
 
-	// Restore store
+	// Restore store; restored records get a fresh default TTL
+	d.storeMu.Lock()
 	for key, value := range storeMap {
 		d.store.Store(key, value)
+		for _, peerID := range value {
+			d.setExpiryLocked(key, peerID, DefaultProviderTTL)
+		}
 	}
+	d.storeMu.Unlock()
 
 	// Restore routing table
 	d.peersMu.Lock()
@@ -751,6 +680,7 @@ func (d *DHT) LoadState(store DHTStore) error {
 func (d *DHT) RemovePeer(peerID string) {
 	d.peersMu.Lock()
 	delete(d.peers, peerID)
+	delete(d.clientPeers, peerID)
 	for i := range d.buckets {
 		bucket := d.buckets[i]
 		next := bucket[:0]
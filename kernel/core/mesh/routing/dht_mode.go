@@ -0,0 +1,170 @@
+package routing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+)
+
+// DHTMode decides whether a node hosts provider records and answers DHT
+// queries (server) or only performs lookups (client).
+type DHTMode string
+
+const (
+	DHTModeServer DHTMode = "server"
+	DHTModeClient DHTMode = "client"
+)
+
+// Capability strings advertised in PeerCapability.Capabilities. Peers that
+// advertise neither are treated as servers, which is how nodes predating
+// the split behave.
+const (
+	CapabilityDHTServer = "dht-server"
+	CapabilityDHTClient = "dht-client"
+)
+
+var ErrDHTClientMode = errors.New("dht: node is in client mode")
+
+// Capability returns the capability string that advertises mode.
+func (mode DHTMode) Capability() string {
+	if mode == DHTModeClient {
+		return CapabilityDHTClient
+	}
+	return CapabilityDHTServer
+}
+
+// DHTModeFromCapabilities reads a peer's advertised mode.
+func DHTModeFromCapabilities(capabilities []string) DHTMode {
+	for _, c := range capabilities {
+		if c == CapabilityDHTClient {
+			return DHTModeClient
+		}
+	}
+	return DHTModeServer
+}
+
+type findNodeRequest struct {
+	TargetID string `json:"target_id"`
+}
+
+type findValueRequest struct {
+	Key string `json:"key"`
+}
+
+type findValueResponse struct {
+	Values []string          `json:"values"`
+	Nodes  []common.PeerInfo `json:"nodes"`
+}
+
+type storeRequest struct {
+	Key   string `json:"key"`
+	Value []byte `json:"value"`         // Provider peer ID
+	TTL   int64  `json:"ttl,omitempty"` // Seconds; zero means DefaultProviderTTL
+}
+
+// Mode returns the node's current DHT mode.
+func (d *DHT) Mode() DHTMode {
+	if d.clientMode.Load() {
+		return DHTModeClient
+	}
+	return DHTModeServer
+}
+
+// SetMode switches between server and client mode. A client keeps its own
+// provider records and can still look up others, but refuses to host
+// records for or answer queries from other peers.
+func (d *DHT) SetMode(mode DHTMode) {
+	d.clientMode.Store(mode == DHTModeClient)
+}
+
+// SetPeerMode records the mode a peer advertised so lookups skip clients.
+func (d *DHT) SetPeerMode(peerID string, mode DHTMode) {
+	d.peersMu.Lock()
+	defer d.peersMu.Unlock()
+	if mode == DHTModeClient {
+		d.clientPeers[peerID] = struct{}{}
+	} else {
+		delete(d.clientPeers, peerID)
+	}
+}
+
+// isClientPeer reports whether a peer advertised client mode.
+func (d *DHT) isClientPeer(peerID string) bool {
+	d.peersMu.RLock()
+	defer d.peersMu.RUnlock()
+	_, ok := d.clientPeers[peerID]
+	return ok
+}
+
+// closestServers returns the K closest known peers that serve DHT queries.
+func (d *DHT) closestServers(targetID string) []common.PeerInfo {
+	d.peersMu.RLock()
+	servers := make([]common.PeerInfo, 0, len(d.peers))
+	for id, p := range d.peers {
+		if _, client := d.clientPeers[id]; !client {
+			servers = append(servers, p)
+		}
+	}
+	d.peersMu.RUnlock()
+
+	sort.Slice(servers, func(i, j int) bool {
+		return d.distance(servers[i].ID, targetID).Cmp(d.distance(servers[j].ID, targetID)) < 0
+	})
+	if len(servers) > d.k {
+		return servers[:d.k]
+	}
+	return servers
+}
+
+// registerRPCHandlers serves find_node, find_value, store and the mutable
+// record RPCs. The handlers stay registered in client mode and refuse
+// instead, so a promotion needs no re-registration.
+func (d *DHT) registerRPCHandlers() {
+	d.transport.RegisterRPCHandler("find_node", func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error) {
+		if d.Mode() == DHTModeClient {
+			return nil, ErrDHTClientMode
+		}
+		var req findNodeRequest
+		if err := json.Unmarshal(args, &req); err != nil {
+			return nil, err
+		}
+		return d.closestServers(req.TargetID), nil
+	})
+
+	d.transport.RegisterRPCHandler("find_value", func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error) {
+		if d.Mode() == DHTModeClient {
+			return nil, ErrDHTClientMode
+		}
+		var req findValueRequest
+		if err := json.Unmarshal(args, &req); err != nil {
+			return nil, err
+		}
+		resp := findValueResponse{Values: []string{}}
+		if providers, ok := d.store.Load(req.Key); ok {
+			resp.Values = providers.([]string)
+		} else {
+			resp.Nodes = d.closestServers(req.Key)
+		}
+		return resp, nil
+	})
+
+	d.transport.RegisterRPCHandler("store", func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error) {
+		if d.Mode() == DHTModeClient {
+			return nil, ErrDHTClientMode
+		}
+		var req storeRequest
+		if err := json.Unmarshal(args, &req); err != nil {
+			return nil, err
+		}
+		if req.Key == "" || len(req.Value) == 0 {
+			return nil, errors.New("store requires key and value")
+		}
+		d.storeProvider(req.Key, string(req.Value), providerTTL(req.TTL))
+		return nil, nil
+	})
+
+	d.registerRecordHandlers()
+}
@@ -0,0 +1,49 @@
+package routing
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDHT_ClientModeRefusesQueries(t *testing.T) {
+	transport := NewMockDHTTransport()
+	dht := NewDHT(getSHA256ID("node1"), transport, nil)
+	dht.storeLocal("chunk", "provider")
+
+	args := json.RawMessage(`{"key":"chunk"}`)
+	resp, err := transport.handlers["find_value"](context.Background(), "peer", args)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"provider"}, resp.(findValueResponse).Values)
+
+	dht.SetMode(DHTModeClient)
+	assert.Equal(t, DHTModeClient, dht.Mode())
+	for _, method := range []string{"find_node", "find_value", "store"} {
+		_, err := transport.handlers[method](context.Background(), "peer", json.RawMessage(`{"key":"k","value":"cA=="}`))
+		assert.ErrorIs(t, err, ErrDHTClientMode, method)
+	}
+
+	// Own records survive and lookups still work
+	peers, err := dht.FindPeers("chunk")
+	assert.NoError(t, err)
+	assert.Contains(t, peers, "provider")
+}
+
+func TestDHT_LookupsSkipClientPeers(t *testing.T) {
+	dht := NewDHT(getSHA256ID("node1"), NewMockDHTTransport(), nil)
+	server := getSHA256ID("server")
+	phone := getSHA256ID("phone")
+	dht.AddPeer(common.PeerInfo{ID: server})
+	dht.AddPeer(common.PeerInfo{ID: phone})
+
+	dht.SetPeerMode(phone, DHTModeFromCapabilities([]string{"gpu", CapabilityDHTClient}))
+	servers := dht.closestServers(getSHA256ID("target"))
+	assert.Len(t, servers, 1)
+	assert.Equal(t, server, servers[0].ID)
+
+	dht.SetPeerMode(phone, DHTModeServer)
+	assert.Len(t, dht.closestServers(getSHA256ID("target")), 2)
+}
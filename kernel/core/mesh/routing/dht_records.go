@@ -0,0 +1,277 @@
+package routing
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Bounds on mutable records hosted for other peers.
+const (
+	MaxRecordNameLength = 256
+	MaxRecordValueSize  = 4 * 1024
+)
+
+var (
+	ErrRecordNotFound = errors.New("dht: record not found")
+	// ErrStaleRecord is returned when a record does not advance the sequence
+	// of the one already held.
+	ErrStaleRecord = errors.New("dht: record sequence is not newer")
+)
+
+// MutableRecord is a signed, named value that its publisher can replace.
+// It lives under a key derived from the publisher's key and the name, so
+// only that key can write it; a higher Sequence supersedes a lower one.
+type MutableRecord struct {
+	PublicKey []byte `json:"public_key"`
+	Name      string `json:"name"`
+	Value     []byte `json:"value"`
+	Sequence  uint64 `json:"sequence"`
+	Expires   int64  `json:"expires"` // Unix nanoseconds
+	Signature []byte `json:"signature"`
+}
+
+type putRecordRequest struct {
+	Record *MutableRecord `json:"record"`
+}
+
+type getRecordRequest struct {
+	Key string `json:"key"`
+}
+
+type getRecordResponse struct {
+	Record *MutableRecord `json:"record,omitempty"`
+}
+
+// MutableRecordKey returns the DHT key for a publisher's named record.
+func MutableRecordKey(publicKey ed25519.PublicKey, name string) string {
+	h := sha256.New()
+	h.Write([]byte("inos-record:"))
+	h.Write(publicKey)
+	h.Write([]byte{0})
+	h.Write([]byte(name))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// NewMutableRecord builds an unsigned record valid for ttl (capped at
+// MaxProviderTTL). Sign it with SigningPayload before publishing.
+func NewMutableRecord(publicKey ed25519.PublicKey, name string, value []byte, sequence uint64, ttl time.Duration) *MutableRecord {
+	return &MutableRecord{
+		PublicKey: append([]byte(nil), publicKey...),
+		Name:      name,
+		Value:     append([]byte(nil), value...),
+		Sequence:  sequence,
+		Expires:   time.Now().Add(min(ttl, MaxProviderTTL)).UnixNano(),
+	}
+}
+
+// Key returns the record's DHT key.
+func (r *MutableRecord) Key() string {
+	return MutableRecordKey(r.PublicKey, r.Name)
+}
+
+// SigningPayload is the byte string the publisher signs.
+func (r *MutableRecord) SigningPayload() []byte {
+	var buf bytes.Buffer
+	buf.WriteString("inos-record-v1\x00")
+	buf.Write(r.PublicKey)
+	buf.WriteByte(0)
+	buf.WriteString(r.Name)
+	buf.WriteByte(0)
+	_ = binary.Write(&buf, binary.BigEndian, r.Sequence)
+	_ = binary.Write(&buf, binary.BigEndian, r.Expires)
+	buf.Write(r.Value)
+	return buf.Bytes()
+}
+
+// ExpiresAt returns when the record lapses.
+func (r *MutableRecord) ExpiresAt() time.Time {
+	return time.Unix(0, r.Expires)
+}
+
+// Verify checks the record's bounds, expiry and signature.
+func (r *MutableRecord) Verify(now time.Time) error {
+	switch {
+	case len(r.PublicKey) != ed25519.PublicKeySize:
+		return errors.New("record public key has invalid size")
+	case r.Name == "" || len(r.Name) > MaxRecordNameLength:
+		return fmt.Errorf("record name must be 1-%d bytes", MaxRecordNameLength)
+	case len(r.Value) > MaxRecordValueSize:
+		return fmt.Errorf("record value exceeds %d bytes", MaxRecordValueSize)
+	case !now.Before(r.ExpiresAt()):
+		return errors.New("record expired")
+	case r.ExpiresAt().After(now.Add(MaxProviderTTL + maxGossipFutureSkew)):
+		return errors.New("record expiry too far in the future")
+	}
+	if !ed25519.Verify(r.PublicKey, r.SigningPayload(), r.Signature) {
+		return errors.New("invalid record signature")
+	}
+	return nil
+}
+
+// supersedes reports whether r replaces other: a higher sequence, or the
+// same sequence with a later expiry (a republish of the same value).
+func (r *MutableRecord) supersedes(other *MutableRecord) bool {
+	if other == nil {
+		return true
+	}
+	if r.Sequence != other.Sequence {
+		return r.Sequence > other.Sequence
+	}
+	return r.Expires > other.Expires && bytes.Equal(r.Value, other.Value)
+}
+
+// PutRecord verifies and stores a record locally, then replicates it to the
+// K closest servers to its key.
+func (d *DHT) PutRecord(ctx context.Context, record *MutableRecord) error {
+	if err := d.storeRecord(record, time.Now()); err != nil {
+		return err
+	}
+
+	closest, err := d.iterativeFindNode(ctx, record.Key())
+	if err != nil || d.transport == nil {
+		return nil // Held locally; lookups will still find it here
+	}
+	var wg sync.WaitGroup
+	for _, p := range closest {
+		if p.ID == d.nodeID {
+			continue
+		}
+		wg.Add(1)
+		go func(peerID string) {
+			defer wg.Done()
+			_ = d.transport.SendRPC(ctx, peerID, "put_record", putRecordRequest{Record: record}, nil)
+		}(p.ID)
+	}
+	wg.Wait()
+	return nil
+}
+
+// GetRecord resolves the newest valid record a publisher wrote under name,
+// asking the closest servers and keeping the highest sequence seen.
+func (d *DHT) GetRecord(ctx context.Context, publicKey ed25519.PublicKey, name string) (*MutableRecord, error) {
+	key := MutableRecordKey(publicKey, name)
+	best := d.LocalRecord(key)
+
+	if d.transport != nil {
+		var (
+			mu sync.Mutex
+			wg sync.WaitGroup
+		)
+		for _, p := range d.closestServers(key) {
+			wg.Add(1)
+			go func(peerID string) {
+				defer wg.Done()
+				var resp getRecordResponse
+				if err := d.transport.SendRPC(ctx, peerID, "get_record", getRecordRequest{Key: key}, &resp); err != nil || resp.Record == nil {
+					return
+				}
+				rec := resp.Record
+				if rec.Key() != key || rec.Verify(time.Now()) != nil {
+					return
+				}
+				mu.Lock()
+				if rec.supersedes(best) {
+					best = rec
+				}
+				mu.Unlock()
+			}(p.ID)
+		}
+		wg.Wait()
+	}
+
+	d.metrics.storeMu.Lock()
+	d.metrics.TotalQueries++
+	if best != nil {
+		d.metrics.SuccessfulLookups++
+	}
+	d.metrics.storeMu.Unlock()
+
+	if best == nil {
+		return nil, ErrRecordNotFound
+	}
+	_ = d.storeRecord(best, time.Now())
+	return best, nil
+}
+
+// LocalRecord returns the record held locally under key, if still valid.
+func (d *DHT) LocalRecord(key string) *MutableRecord {
+	d.recordsMu.RLock()
+	defer d.recordsMu.RUnlock()
+	rec, ok := d.records[key]
+	if !ok || !time.Now().Before(rec.ExpiresAt()) {
+		return nil
+	}
+	return rec
+}
+
+// ExpireRecords drops records whose TTL lapsed before now.
+func (d *DHT) ExpireRecords(now time.Time) int {
+	d.recordsMu.Lock()
+	defer d.recordsMu.Unlock()
+	expired := 0
+	for key, rec := range d.records {
+		if !now.Before(rec.ExpiresAt()) {
+			delete(d.records, key)
+			expired++
+		}
+	}
+	return expired
+}
+
+func (d *DHT) storeRecord(record *MutableRecord, now time.Time) error {
+	if record == nil {
+		return errors.New("record is required")
+	}
+	if err := record.Verify(now); err != nil {
+		return err
+	}
+	key := record.Key()
+
+	d.recordsMu.Lock()
+	defer d.recordsMu.Unlock()
+	if existing, ok := d.records[key]; ok && now.Before(existing.ExpiresAt()) {
+		if existing.Sequence == record.Sequence && existing.Expires == record.Expires {
+			return nil
+		}
+		if !record.supersedes(existing) {
+			return ErrStaleRecord
+		}
+	}
+	d.records[key] = record
+	return nil
+}
+
+// registerRecordHandlers serves put_record and get_record; like the other
+// DHT handlers they refuse in client mode.
+func (d *DHT) registerRecordHandlers() {
+	d.transport.RegisterRPCHandler("put_record", func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error) {
+		if d.Mode() == DHTModeClient {
+			return nil, ErrDHTClientMode
+		}
+		var req putRecordRequest
+		if err := json.Unmarshal(args, &req); err != nil {
+			return nil, err
+		}
+		return nil, d.storeRecord(req.Record, time.Now())
+	})
+
+	d.transport.RegisterRPCHandler("get_record", func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error) {
+		if d.Mode() == DHTModeClient {
+			return nil, ErrDHTClientMode
+		}
+		var req getRecordRequest
+		if err := json.Unmarshal(args, &req); err != nil {
+			return nil, err
+		}
+		return getRecordResponse{Record: d.LocalRecord(req.Key)}, nil
+	})
+}
@@ -0,0 +1,74 @@
+package routing
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func signedRecord(t *testing.T, priv ed25519.PrivateKey, name, value string, seq uint64) *MutableRecord {
+	t.Helper()
+	rec := NewMutableRecord(priv.Public().(ed25519.PublicKey), name, []byte(value), seq, time.Hour)
+	rec.Signature = ed25519.Sign(priv, rec.SigningPayload())
+	return rec
+}
+
+func TestMutableRecord_ReplicatesAndResolvesNewest(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	holderID := getSHA256ID("holder")
+	holderT, publisherT, resolverT := NewMockDHTTransport(), NewMockDHTTransport(), NewMockDHTTransport()
+	holder := NewDHT(holderID, holderT, nil)
+	publisher := NewDHT(getSHA256ID("publisher"), publisherT, nil)
+	resolver := NewDHT(getSHA256ID("resolver"), resolverT, nil)
+	publisherT.peers[holderID] = holderT
+	resolverT.peers[holderID] = holderT
+	require.NoError(t, publisher.AddPeer(common.PeerInfo{ID: holderID}))
+	require.NoError(t, resolver.AddPeer(common.PeerInfo{ID: holderID}))
+
+	ctx := context.Background()
+	require.NoError(t, publisher.PutRecord(ctx, signedRecord(t, priv, "app/x", "manifest-1", 1)))
+	require.NoError(t, publisher.PutRecord(ctx, signedRecord(t, priv, "app/x", "manifest-2", 2)))
+	assert.NotNil(t, holder.LocalRecord(MutableRecordKey(pub, "app/x")), "record must replicate to the closest server")
+
+	rec, err := resolver.GetRecord(ctx, pub, "app/x")
+	require.NoError(t, err)
+	assert.Equal(t, "manifest-2", string(rec.Value))
+	assert.Equal(t, uint64(2), rec.Sequence)
+
+	// An older sequence cannot roll the record back.
+	assert.ErrorIs(t, holder.storeRecord(signedRecord(t, priv, "app/x", "manifest-1", 1), time.Now()), ErrStaleRecord)
+
+	_, err = resolver.GetRecord(ctx, pub, "app/y")
+	assert.ErrorIs(t, err, ErrRecordNotFound)
+}
+
+func TestMutableRecord_RejectsForgeriesAndExpires(t *testing.T) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	_, mallory, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	dht := NewDHT(getSHA256ID("node1"), NewMockDHTTransport(), nil)
+
+	rec := signedRecord(t, priv, "app/x", "manifest", 1)
+	rec.Value = []byte("tampered")
+	assert.Error(t, dht.storeRecord(rec, time.Now()))
+
+	// Another key's signature over the victim's key and name does not verify.
+	forged := signedRecord(t, priv, "app/x", "manifest", 5)
+	forged.Signature = ed25519.Sign(mallory, forged.SigningPayload())
+	assert.Error(t, dht.storeRecord(forged, time.Now()))
+
+	valid := signedRecord(t, priv, "app/x", "manifest", 1)
+	require.NoError(t, dht.storeRecord(valid, time.Now()))
+	assert.Zero(t, dht.ExpireRecords(time.Now()))
+	assert.Equal(t, 1, dht.ExpireRecords(time.Now().Add(2*time.Hour)))
+	assert.Nil(t, dht.LocalRecord(valid.Key()))
+}
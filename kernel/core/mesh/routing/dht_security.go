@@ -0,0 +1,287 @@
+package routing
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/url"
+	"sort"
+	"sync"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+)
+
+// S-Kademlia style hardening. Plain Kademlia believes whatever a peer says
+// in a find_node reply, so one attacker that is asked early can steer a
+// lookup into a set of nodes it controls. Three defences close that off:
+// node IDs must be backed by the identity key they were derived from, a
+// routing bucket holds only a few peers from the same network, and lookups
+// run over disjoint paths so a single poisoned reply only affects one path.
+
+var (
+	ErrUnverifiedNodeID = errors.New("node ID not bound to an identity key")
+	ErrBucketDiversity  = errors.New("bucket already holds the maximum peers from this network")
+)
+
+// maxLookupRounds bounds how many hops each lookup path takes.
+const maxLookupRounds = 5
+
+// NodeIDVerifier checks that a peer's ID is bound to its public key.
+type NodeIDVerifier func(peer common.PeerInfo) error
+
+// DHTSecurityConfig tunes lookup and routing table hardening.
+type DHTSecurityConfig struct {
+	DisjointPaths      int  `json:"disjoint_paths"`       // Independent lookup paths; 1 is plain Kademlia
+	MaxPerSubnet       int  `json:"max_per_subnet"`       // Peers per bucket from one /24 (IPv4) or /48 (IPv6); 0 is unlimited
+	RequireVerifiedIDs bool `json:"require_verified_ids"` // Refuse peers that carry no identity key
+}
+
+// DefaultDHTSecurityConfig returns the hardening used unless overridden.
+func DefaultDHTSecurityConfig() DHTSecurityConfig {
+	return DHTSecurityConfig{DisjointPaths: 3, MaxPerSubnet: 2}
+}
+
+// SetSecurityConfig replaces the hardening settings.
+func (d *DHT) SetSecurityConfig(cfg DHTSecurityConfig) {
+	d.securityMu.Lock()
+	defer d.securityMu.Unlock()
+	d.security = cfg
+}
+
+// SetNodeIDVerifier installs the check applied to peers that carry a
+// public key. Without one, keys are accepted as given.
+func (d *DHT) SetNodeIDVerifier(verify NodeIDVerifier) {
+	d.securityMu.Lock()
+	defer d.securityMu.Unlock()
+	d.verifyID = verify
+}
+
+func (d *DHT) securitySettings() (DHTSecurityConfig, NodeIDVerifier) {
+	d.securityMu.RLock()
+	defer d.securityMu.RUnlock()
+	return d.security, d.verifyID
+}
+
+// verifyPeer checks a peer's ID against its key before it may enter the
+// routing table or a lookup shortlist.
+func (d *DHT) verifyPeer(peer common.PeerInfo) error {
+	cfg, verify := d.securitySettings()
+	if len(peer.PublicKey) == 0 {
+		if cfg.RequireVerifiedIDs {
+			return ErrUnverifiedNodeID
+		}
+		return nil
+	}
+	if verify == nil {
+		return nil
+	}
+	return verify(peer)
+}
+
+// subnetKey groups addresses by network for the bucket diversity limit.
+// Addresses that carry no IP, such as WebRTC peers known only by ID, are
+// not grouped.
+func subnetKey(address string) string {
+	host := address
+	if u, err := url.Parse(address); err == nil && u.Host != "" {
+		host = u.Host
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return ""
+	}
+	if v4 := ip.To4(); v4 != nil {
+		return v4.Mask(net.CIDRMask(24, 32)).String()
+	}
+	return ip.Mask(net.CIDRMask(48, 128)).String()
+}
+
+// bucketAllows reports whether a bucket may take another peer from the
+// new peer's network. The caller holds peersMu.
+func (d *DHT) bucketAllows(bucket []common.PeerInfo, peer common.PeerInfo) bool {
+	cfg, _ := d.securitySettings()
+	key := subnetKey(peer.Address)
+	if cfg.MaxPerSubnet <= 0 || key == "" {
+		return true
+	}
+	same := 0
+	for _, p := range bucket {
+		if p.ID != peer.ID && subnetKey(p.Address) == key {
+			same++
+		}
+	}
+	return same < cfg.MaxPerSubnet
+}
+
+func (d *DHT) incMetric(counter *int64) {
+	d.metrics.storeMu.Lock()
+	*counter++
+	d.metrics.storeMu.Unlock()
+}
+
+// lookupQuery asks one peer about the lookup target, returning closer peers
+// and, for value lookups, any values it holds.
+type lookupQuery func(ctx context.Context, peer common.PeerInfo) ([]common.PeerInfo, []string, error)
+
+// lookupState is shared by the paths of one lookup. A peer queried by one
+// path is never queried by another, which keeps the paths disjoint.
+type lookupState struct {
+	mu      sync.Mutex
+	visited map[string]bool
+	values  []string
+	seen    map[string]bool
+}
+
+// disjointLookup splits the closest known servers across DisjointPaths
+// paths and walks each toward targetID independently. An attacker has to
+// be on every path to hide the true closest nodes. Value lookups stop once
+// stopAfter values are found; zero never stops early.
+func (d *DHT) disjointLookup(ctx context.Context, targetID string, stopAfter int, query lookupQuery) ([]common.PeerInfo, []string, error) {
+	shortlist := d.closestServers(targetID)
+	if len(shortlist) == 0 {
+		return nil, nil, errors.New("no peers in routing table")
+	}
+
+	cfg, _ := d.securitySettings()
+	paths := min(max(cfg.DisjointPaths, 1), len(shortlist))
+	if paths > 1 {
+		d.incMetric(&d.metrics.DisjointLookups)
+	}
+
+	starts := make([][]common.PeerInfo, paths)
+	for i, peer := range shortlist {
+		starts[i%paths] = append(starts[i%paths], peer)
+	}
+
+	state := &lookupState{visited: map[string]bool{d.nodeID: true}, seen: make(map[string]bool)}
+	results := make([][]common.PeerInfo, paths)
+	var wg sync.WaitGroup
+	for i := range starts {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = d.lookupPath(ctx, targetID, starts[i], stopAfter, query, state)
+		}(i)
+	}
+	wg.Wait()
+
+	var closest []common.PeerInfo
+	included := make(map[string]bool)
+	for _, path := range results {
+		for _, peer := range path {
+			if !included[peer.ID] {
+				included[peer.ID] = true
+				closest = append(closest, peer)
+			}
+		}
+	}
+	d.sortByDistance(closest, targetID)
+	if len(closest) > d.k {
+		closest = closest[:d.k]
+	}
+	return closest, state.values, nil
+}
+
+// lookupPath runs the iterative lookup for one path.
+func (d *DHT) lookupPath(ctx context.Context, targetID string, shortlist []common.PeerInfo, stopAfter int, query lookupQuery, state *lookupState) []common.PeerInfo {
+	d.sortByDistance(shortlist, targetID)
+
+	for round := 0; round < maxLookupRounds; round++ {
+		state.mu.Lock()
+		var candidates []common.PeerInfo
+		for _, peer := range shortlist {
+			if !state.visited[peer.ID] && len(candidates) < d.alpha {
+				candidates = append(candidates, peer)
+				state.visited[peer.ID] = true
+			}
+		}
+		state.mu.Unlock()
+
+		if len(candidates) == 0 {
+			break
+		}
+
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		for _, peer := range candidates {
+			wg.Add(1)
+			go func(p common.PeerInfo) {
+				defer wg.Done()
+				closer, values, err := query(ctx, p)
+				if err != nil {
+					d.incMetric(&d.metrics.FailedQueries)
+					return
+				}
+				closer = d.filterLookupReply(closer)
+
+				if len(values) > 0 {
+					state.mu.Lock()
+					for _, v := range values {
+						if !state.seen[v] {
+							state.seen[v] = true
+							state.values = append(state.values, v)
+						}
+					}
+					state.mu.Unlock()
+				}
+
+				mu.Lock()
+				defer mu.Unlock()
+				for _, n := range closer {
+					found := false
+					for _, existing := range shortlist {
+						if existing.ID == n.ID {
+							found = true
+							break
+						}
+					}
+					if !found {
+						shortlist = append(shortlist, n)
+					}
+				}
+			}(peer)
+		}
+		wg.Wait()
+
+		if stopAfter > 0 {
+			state.mu.Lock()
+			done := len(state.values) >= stopAfter
+			state.mu.Unlock()
+			if done {
+				break
+			}
+		}
+
+		d.sortByDistance(shortlist, targetID)
+		if len(shortlist) > d.k {
+			shortlist = shortlist[:d.k]
+		}
+	}
+	return shortlist
+}
+
+// filterLookupReply drops ourselves, client-mode peers and any peer whose
+// ID does not check out against its key. Each forged entry is counted.
+func (d *DHT) filterLookupReply(nodes []common.PeerInfo) []common.PeerInfo {
+	out := nodes[:0:0]
+	for _, n := range nodes {
+		if n.ID == d.nodeID || d.isClientPeer(n.ID) {
+			continue
+		}
+		if err := d.verifyPeer(n); err != nil {
+			d.incMetric(&d.metrics.InvalidNodeReplies)
+			continue
+		}
+		out = append(out, n)
+	}
+	return out
+}
+
+func (d *DHT) sortByDistance(peers []common.PeerInfo, targetID string) {
+	sort.Slice(peers, func(i, j int) bool {
+		return d.distance(peers[i].ID, targetID).Cmp(d.distance(peers[j].ID, targetID)) < 0
+	})
+}
@@ -0,0 +1,133 @@
+package routing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+)
+
+// lookupTransport answers find_node from a fixed table and records who was asked.
+type lookupTransport struct {
+	*MockDHTTransport
+	mu      sync.Mutex
+	replies map[string][]common.PeerInfo
+	queried []string
+}
+
+func (l *lookupTransport) FindNode(ctx context.Context, peerID, targetID string) ([]common.PeerInfo, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.queried = append(l.queried, peerID)
+	return l.replies[peerID], nil
+}
+
+func TestDHTSecurity_DisjointPathsSurviveAPoisonedReply(t *testing.T) {
+	tr := &lookupTransport{MockDHTTransport: NewMockDHTTransport(), replies: map[string][]common.PeerInfo{}}
+	dht := NewDHT("self", tr, nil)
+	for _, id := range []string{"honest-a", "honest-b", "attacker"} {
+		if err := dht.AddPeer(common.PeerInfo{ID: id}); err != nil {
+			t.Fatalf("AddPeer %s failed: %v", id, err)
+		}
+	}
+	// The attacker only ever points at its own sybils
+	tr.replies["attacker"] = []common.PeerInfo{{ID: "sybil-1"}, {ID: "sybil-2"}}
+	tr.replies["sybil-1"] = []common.PeerInfo{{ID: "sybil-2"}}
+	tr.replies["honest-a"] = []common.PeerInfo{{ID: "target-holder"}}
+	tr.replies["honest-b"] = []common.PeerInfo{{ID: "target-holder"}}
+
+	closest, err := dht.iterativeFindNode(context.Background(), "target")
+	if err != nil {
+		t.Fatalf("lookup failed: %v", err)
+	}
+	found := false
+	for _, p := range closest {
+		found = found || p.ID == "target-holder"
+	}
+	if !found {
+		t.Fatalf("honest paths should reach target-holder, got %+v", closest)
+	}
+
+	seen := map[string]bool{}
+	for _, id := range tr.queried {
+		if seen[id] {
+			t.Fatalf("%s was queried by more than one path: %v", id, tr.queried)
+		}
+		seen[id] = true
+	}
+	if n := dht.GetMetrics().DisjointLookups; n != 1 {
+		t.Fatalf("expected one disjoint lookup, got %d", n)
+	}
+}
+
+func TestDHTSecurity_RejectsForgedIDs(t *testing.T) {
+	tr := &lookupTransport{MockDHTTransport: NewMockDHTTransport(), replies: map[string][]common.PeerInfo{}}
+	dht := NewDHT("self", tr, nil)
+	dht.SetNodeIDVerifier(func(p common.PeerInfo) error {
+		if strings.HasPrefix(p.ID, "forged") {
+			return ErrUnverifiedNodeID
+		}
+		return nil
+	})
+
+	if err := dht.AddPeer(common.PeerInfo{ID: "forged-1", PublicKey: []byte("k")}); !errors.Is(err, ErrUnverifiedNodeID) {
+		t.Fatalf("expected forged peer to be refused, got %v", err)
+	}
+	if err := dht.AddPeer(common.PeerInfo{ID: "good", PublicKey: []byte("k")}); err != nil {
+		t.Fatalf("AddPeer failed: %v", err)
+	}
+	tr.replies["good"] = []common.PeerInfo{{ID: "forged-2", PublicKey: []byte("k")}, {ID: "other"}}
+
+	closest, _ := dht.iterativeFindNode(context.Background(), "target")
+	for _, p := range closest {
+		if strings.HasPrefix(p.ID, "forged") {
+			t.Fatalf("forged entry leaked into the lookup: %+v", closest)
+		}
+	}
+	m := dht.GetMetrics()
+	if m.RejectedNodeIDs != 1 || m.InvalidNodeReplies != 1 {
+		t.Fatalf("unexpected attack counters %+v", m)
+	}
+
+	dht.SetSecurityConfig(DHTSecurityConfig{DisjointPaths: 1, RequireVerifiedIDs: true})
+	if err := dht.AddPeer(common.PeerInfo{ID: "keyless"}); !errors.Is(err, ErrUnverifiedNodeID) {
+		t.Fatalf("expected keyless peer to be refused, got %v", err)
+	}
+}
+
+func TestDHTSecurity_BucketSubnetDiversity(t *testing.T) {
+	dht := NewDHT("self", nil, nil)
+
+	// Collect three IDs that land in the same bucket
+	var ids []string
+	bucket := -1
+	for i := 0; len(ids) < 3; i++ {
+		id := fmt.Sprintf("peer-%d", i)
+		idx := dht.getBucketIndex(id)
+		if bucket == -1 {
+			bucket = idx
+		}
+		if idx == bucket {
+			ids = append(ids, id)
+		}
+	}
+
+	for i, id := range ids[:2] {
+		if err := dht.AddPeer(common.PeerInfo{ID: id, Address: fmt.Sprintf("10.1.2.%d:9000", i+1)}); err != nil {
+			t.Fatalf("AddPeer failed: %v", err)
+		}
+	}
+	if err := dht.AddPeer(common.PeerInfo{ID: ids[2], Address: "wss://10.1.2.99:443"}); !errors.Is(err, ErrBucketDiversity) {
+		t.Fatalf("expected a third peer from 10.1.2.0/24 to be refused, got %v", err)
+	}
+	if err := dht.AddPeer(common.PeerInfo{ID: ids[2], Address: "10.1.3.1:9000"}); err != nil {
+		t.Fatalf("peer from another subnet should be accepted: %v", err)
+	}
+	if n := dht.GetMetrics().DiversityRejections; n != 1 {
+		t.Fatalf("expected one diversity rejection, got %d", n)
+	}
+}
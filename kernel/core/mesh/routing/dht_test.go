@@ -234,6 +234,18 @@ func TestDHT_Store(t *testing.T) {
 	}
 }
 
+func TestDHT_RemoveProvider(t *testing.T) {
+	dht := NewDHT(getSHA256ID("node1"), NewMockDHTTransport(), nil)
+	dht.storeProvider("chunk-a", "leaver", DefaultProviderTTL)
+	dht.storeProvider("chunk-a", "stayer", DefaultProviderTTL)
+	dht.storeProvider("chunk-b", "leaver", DefaultProviderTTL)
+
+	assert.Equal(t, 2, dht.RemoveProvider("leaver"))
+	assert.Equal(t, []string{"stayer"}, dht.LocalProviders("chunk-a"))
+	assert.Empty(t, dht.LocalProviders("chunk-b"))
+	assert.Zero(t, dht.RemoveProvider("leaver"))
+}
+
 // TestDHT_FindNode tests finding closest nodes
 func TestDHT_FindNode(t *testing.T) {
 	transport := NewMockDHTTransport()
@@ -0,0 +1,108 @@
+package routing
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultProviderTTL applies to provider records stored without a TTL,
+// including stores from peers that predate TTL-carrying records.
+const DefaultProviderTTL = time.Hour
+
+// MaxProviderTTL caps how long a remote peer can make us hold a record.
+const MaxProviderTTL = 24 * time.Hour
+
+// TTLStorer is implemented by transports whose store RPC carries a TTL.
+// Transports without it fall back to Store and the receiver's default TTL.
+type TTLStorer interface {
+	StoreWithTTL(ctx context.Context, peerID string, key string, value []byte, ttlSeconds int64) error
+}
+
+func providerTTL(ttlSeconds int64) time.Duration {
+	if ttlSeconds <= 0 {
+		return DefaultProviderTTL
+	}
+	return min(time.Duration(ttlSeconds)*time.Second, MaxProviderTTL)
+}
+
+// setExpiryLocked records when peerID's record for chunkHash lapses. A
+// re-store replaces the expiry, so a record can shorten as well as extend.
+// Callers hold storeMu.
+func (d *DHT) setExpiryLocked(chunkHash, peerID string, ttl time.Duration) {
+	providers, ok := d.expiry[chunkHash]
+	if !ok {
+		providers = make(map[string]time.Time)
+		d.expiry[chunkHash] = providers
+	}
+	providers[peerID] = time.Now().Add(ttl)
+}
+
+// ProviderExpiry returns when peerID's record for chunkHash lapses.
+func (d *DHT) ProviderExpiry(chunkHash, peerID string) (time.Time, bool) {
+	d.storeMu.RLock()
+	defer d.storeMu.RUnlock()
+	expires, ok := d.expiry[chunkHash][peerID]
+	return expires, ok
+}
+
+// ExpireProviders drops every provider record whose TTL lapsed before now
+// and returns how many were dropped. Chunks left without providers are
+// removed from the store.
+func (d *DHT) ExpireProviders(now time.Time) int {
+	d.storeMu.Lock()
+	defer d.storeMu.Unlock()
+
+	expired := 0
+	for chunkHash, providers := range d.expiry {
+		var lapsed map[string]struct{}
+		for peerID, expires := range providers {
+			if now.After(expires) {
+				if lapsed == nil {
+					lapsed = make(map[string]struct{})
+				}
+				lapsed[peerID] = struct{}{}
+				delete(providers, peerID)
+			}
+		}
+		if len(providers) == 0 {
+			delete(d.expiry, chunkHash)
+		}
+		if len(lapsed) == 0 {
+			continue
+		}
+
+		existing, ok := d.store.Load(chunkHash)
+		if !ok {
+			continue
+		}
+		kept := make([]string, 0, len(existing.([]string)))
+		for _, peerID := range existing.([]string) {
+			if _, drop := lapsed[peerID]; drop {
+				expired++
+				continue
+			}
+			kept = append(kept, peerID)
+		}
+		if len(kept) == 0 {
+			d.store.Delete(chunkHash)
+		} else {
+			d.store.Store(chunkHash, kept)
+		}
+	}
+
+	if expired > 0 {
+		d.metrics.storeMu.Lock()
+		d.metrics.ExpiredProviders += int64(expired)
+		d.metrics.storeMu.Unlock()
+	}
+	return expired
+}
+
+// sendStore replicates a record to peerID, carrying its TTL when the
+// transport supports it.
+func (d *DHT) sendStore(ctx context.Context, peerID, chunkHash, provider string, ttlSeconds int64) error {
+	if ts, ok := d.transport.(TTLStorer); ok {
+		return ts.StoreWithTTL(ctx, peerID, chunkHash, []byte(provider), ttlSeconds)
+	}
+	return d.transport.Store(ctx, peerID, chunkHash, []byte(provider))
+}
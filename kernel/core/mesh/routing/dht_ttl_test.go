@@ -0,0 +1,65 @@
+package routing
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDHT_ProviderRecordsExpire(t *testing.T) {
+	dht := NewDHT(getSHA256ID("node1"), NewMockDHTTransport(), nil)
+	dht.storeProvider("chunk", "short", time.Minute)
+	dht.storeProvider("chunk", "long", time.Hour)
+	dht.storeProvider("other", "short", time.Minute)
+
+	assert.Zero(t, dht.ExpireProviders(time.Now()))
+
+	later := time.Now().Add(10 * time.Minute)
+	assert.Equal(t, 2, dht.ExpireProviders(later))
+
+	providers, ok := dht.store.Load("chunk")
+	require.True(t, ok)
+	assert.Equal(t, []string{"long"}, providers.([]string))
+	_, ok = dht.store.Load("other")
+	assert.False(t, ok, "chunks without providers leave the store")
+	assert.Equal(t, int64(2), dht.GetMetrics().ExpiredProviders)
+}
+
+func TestDHT_StoreReplacesExpiry(t *testing.T) {
+	dht := NewDHT(getSHA256ID("node1"), NewMockDHTTransport(), nil)
+	dht.storeProvider("chunk", "provider", 6*time.Hour)
+	dht.storeProvider("chunk", "provider", 10*time.Minute)
+
+	expires, ok := dht.ProviderExpiry("chunk", "provider")
+	require.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(10*time.Minute), expires, time.Second, "a colder re-store shortens the record")
+
+	require.NoError(t, dht.RemoveChunkPeer("chunk", "provider"))
+	_, ok = dht.ProviderExpiry("chunk", "provider")
+	assert.False(t, ok)
+}
+
+func TestDHT_StoreRPCHonorsTTL(t *testing.T) {
+	transport := NewMockDHTTransport()
+	dht := NewDHT(getSHA256ID("node1"), transport, nil)
+
+	_, err := transport.handlers["store"](context.Background(), "peer", json.RawMessage(`{"key":"hot","value":"cA==","ttl":7200}`))
+	require.NoError(t, err)
+	expires, _ := dht.ProviderExpiry("hot", "p")
+	assert.WithinDuration(t, time.Now().Add(2*time.Hour), expires, time.Second)
+
+	// Stores from peers that send no TTL get the default; oversized TTLs are capped
+	_, err = transport.handlers["store"](context.Background(), "peer", json.RawMessage(`{"key":"legacy","value":"cA=="}`))
+	require.NoError(t, err)
+	expires, _ = dht.ProviderExpiry("legacy", "p")
+	assert.WithinDuration(t, time.Now().Add(DefaultProviderTTL), expires, time.Second)
+
+	_, err = transport.handlers["store"](context.Background(), "peer", json.RawMessage(`{"key":"greedy","value":"cA==","ttl":31536000}`))
+	require.NoError(t, err)
+	expires, _ = dht.ProviderExpiry("greedy", "p")
+	assert.WithinDuration(t, time.Now().Add(MaxProviderTTL), expires, time.Second)
+}
@@ -0,0 +1,395 @@
+package routing
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/hkdf"
+)
+
+const e2eEnvelopeVersion = 1
+
+var (
+	ErrNoRecipientKey = errors.New("no identity key known for recipient")
+	ErrNotRecipient   = errors.New("envelope is addressed to another node")
+	ErrUnboundPeerKey = errors.New("public key is not bound to peer id")
+)
+
+// E2EEnvelope carries a payload only the recipient can read. Relaying peers
+// see the routing fields but not the plaintext.
+//
+// The key is derived from two X25519 exchanges, both against the recipient's
+// identity: one with a fresh ephemeral key (forward secrecy for the sender)
+// and one with the sender's identity (authenticates the sender).
+type E2EEnvelope struct {
+	Version    int    `json:"v"`
+	Sender     string `json:"sender"`
+	Recipient  string `json:"recipient"`
+	Ephemeral  []byte `json:"epk"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ct"`
+}
+
+// sealedPayload is the gossip payload wrapper for an envelope.
+type sealedPayload struct {
+	E2E *E2EEnvelope `json:"e2e"`
+}
+
+// x25519PrivateFromEd25519 derives the X25519 scalar from an ed25519 seed
+// (RFC 8032 §5.1.5 key expansion).
+func x25519PrivateFromEd25519(key ed25519.PrivateKey) []byte {
+	h := sha512.Sum512(key.Seed())
+	scalar := make([]byte, curve25519.ScalarSize)
+	copy(scalar, h[:32])
+	scalar[0] &= 248
+	scalar[31] &= 127
+	scalar[31] |= 64
+	return scalar
+}
+
+var curve25519P, _ = new(big.Int).SetString("7fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffed", 16)
+
+// x25519PublicFromEd25519 maps an Edwards point to its Montgomery u-coordinate:
+// u = (1 + y) / (1 - y) mod p.
+func x25519PublicFromEd25519(key ed25519.PublicKey) ([]byte, error) {
+	if len(key) != ed25519.PublicKeySize {
+		return nil, errors.New("invalid ed25519 public key size")
+	}
+	le := make([]byte, len(key))
+	copy(le, key)
+	le[31] &= 0x7f // drop the x sign bit
+	y := new(big.Int).SetBytes(reverseBytes(le))
+	if y.Cmp(curve25519P) >= 0 {
+		return nil, errors.New("non-canonical ed25519 public key")
+	}
+
+	one := big.NewInt(1)
+	den := new(big.Int).Sub(one, y)
+	den.Mod(den, curve25519P)
+	if den.Sign() == 0 {
+		return nil, errors.New("ed25519 public key maps to the point at infinity")
+	}
+	num := new(big.Int).Add(one, y)
+	u := num.Mul(num, den.ModInverse(den, curve25519P))
+	u.Mod(u, curve25519P)
+
+	out := make([]byte, curve25519.PointSize)
+	u.FillBytes(out)
+	return reverseBytes(out), nil
+}
+
+func reverseBytes(b []byte) []byte {
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+	return b
+}
+
+func e2eKey(ephemeralShared, staticShared, ephemeralPub []byte, sender, recipient string) ([]byte, error) {
+	secret := append(append([]byte{}, ephemeralShared...), staticShared...)
+	info := []byte("inos-e2e-v1|" + sender + "|" + recipient)
+	key := make([]byte, chacha20poly1305.KeySize)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, ephemeralPub, info), key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func e2eAAD(env *E2EEnvelope) []byte {
+	return []byte(fmt.Sprintf("%d|%s|%s|%x", env.Version, env.Sender, env.Recipient, env.Ephemeral))
+}
+
+// SetPeerIdentityKey records a peer's verified identity key (e.g. from
+// attestation or key rotation), replacing any key learned from gossip.
+func (g *GossipManager) SetPeerIdentityKey(peerID string, key ed25519.PublicKey) {
+	if peerID == "" || len(key) != ed25519.PublicKeySize {
+		return
+	}
+	g.keyMu.Lock()
+	g.peerKeys[peerID] = append(ed25519.PublicKey(nil), key...)
+	g.keyMu.Unlock()
+}
+
+// PeerIdentityKey returns the identity key known for a peer.
+func (g *GossipManager) PeerIdentityKey(peerID string) (ed25519.PublicKey, bool) {
+	g.keyMu.RLock()
+	defer g.keyMu.RUnlock()
+	key, ok := g.peerKeys[peerID]
+	return key, ok
+}
+
+// CheckPeerKey reports whether key speaks for peerID: it must be the key on
+// file for the peer, or, with none on file, the key the peer's ID was
+// derived from.
+func (g *GossipManager) CheckPeerKey(peerID string, key ed25519.PublicKey) error {
+	if len(key) != ed25519.PublicKeySize {
+		return errors.New("invalid public key")
+	}
+	if g.IsKeyRevoked(key) {
+		return errors.New("public key is revoked")
+	}
+	if known, ok := g.PeerIdentityKey(peerID); ok {
+		if !known.Equal(key) {
+			return errors.New("public key does not match known identity key")
+		}
+		return nil
+	}
+	if common.NodeIDFromPublicKey(key) != peerID {
+		return fmt.Errorf("%w: %s", ErrUnboundPeerKey, getShortID(peerID))
+	}
+	return nil
+}
+
+// learnPeerKey files the key a sender signed with when the sender's ID was
+// derived from it. Any other key reaches the directory only through
+// SetPeerIdentityKey, after attestation or a verified rotation.
+func (g *GossipManager) learnPeerKey(peerID string, key ed25519.PublicKey) {
+	if peerID == "" || peerID == g.nodeID || len(key) != ed25519.PublicKeySize || g.IsKeyRevoked(key) {
+		return
+	}
+	if common.NodeIDFromPublicKey(key) != peerID {
+		return
+	}
+	g.keyMu.Lock()
+	if _, known := g.peerKeys[peerID]; !known {
+		g.peerKeys[peerID] = append(ed25519.PublicKey(nil), key...)
+	}
+	g.keyMu.Unlock()
+}
+
+// SealBytes encrypts data for recipientID.
+func (g *GossipManager) SealBytes(recipientID string, data []byte) (*E2EEnvelope, error) {
+	recipientKey, ok := g.PeerIdentityKey(recipientID)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNoRecipientKey, getShortID(recipientID))
+	}
+	recipientX, err := x25519PublicFromEd25519(recipientKey)
+	if err != nil {
+		return nil, err
+	}
+	signKey, _ := g.signingKeys()
+	if signKey == nil {
+		return nil, errors.New("identity key not initialized")
+	}
+
+	ephemeral := make([]byte, curve25519.ScalarSize)
+	if _, err := rand.Read(ephemeral); err != nil {
+		return nil, err
+	}
+	ephemeralPub, err := curve25519.X25519(ephemeral, curve25519.Basepoint)
+	if err != nil {
+		return nil, err
+	}
+	ephemeralShared, err := curve25519.X25519(ephemeral, recipientX)
+	if err != nil {
+		return nil, err
+	}
+	staticShared, err := curve25519.X25519(x25519PrivateFromEd25519(signKey), recipientX)
+	if err != nil {
+		return nil, err
+	}
+
+	env := &E2EEnvelope{
+		Version:   e2eEnvelopeVersion,
+		Sender:    g.nodeID,
+		Recipient: recipientID,
+		Ephemeral: ephemeralPub,
+		Nonce:     make([]byte, chacha20poly1305.NonceSizeX),
+	}
+	if _, err := rand.Read(env.Nonce); err != nil {
+		return nil, err
+	}
+	key, err := e2eKey(ephemeralShared, staticShared, ephemeralPub, env.Sender, env.Recipient)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, err
+	}
+	env.Ciphertext = aead.Seal(nil, env.Nonce, data, e2eAAD(env))
+	return env, nil
+}
+
+// OpenBytes decrypts an envelope addressed to this node, authenticating the
+// sender against the identity key on file for it.
+func (g *GossipManager) OpenBytes(env *E2EEnvelope) ([]byte, error) {
+	if env == nil || env.Version != e2eEnvelopeVersion {
+		return nil, errors.New("unsupported e2e envelope")
+	}
+	if env.Recipient != g.nodeID {
+		return nil, ErrNotRecipient
+	}
+	senderKey, ok := g.PeerIdentityKey(env.Sender)
+	if !ok {
+		return nil, fmt.Errorf("no identity key known for sender %s", getShortID(env.Sender))
+	}
+	senderX, err := x25519PublicFromEd25519(senderKey)
+	if err != nil {
+		return nil, err
+	}
+	signKey, _ := g.signingKeys()
+	if signKey == nil {
+		return nil, errors.New("identity key not initialized")
+	}
+	own := x25519PrivateFromEd25519(signKey)
+
+	ephemeralShared, err := curve25519.X25519(own, env.Ephemeral)
+	if err != nil {
+		return nil, err
+	}
+	staticShared, err := curve25519.X25519(own, senderX)
+	if err != nil {
+		return nil, err
+	}
+	key, err := e2eKey(ephemeralShared, staticShared, env.Ephemeral, env.Sender, env.Recipient)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(env.Nonce) != aead.NonceSize() {
+		return nil, errors.New("invalid e2e nonce")
+	}
+	plaintext, err := aead.Open(nil, env.Nonce, env.Ciphertext, e2eAAD(env))
+	if err != nil {
+		return nil, errors.New("e2e envelope authentication failed")
+	}
+	return plaintext, nil
+}
+
+// BroadcastTo delivers a payload to a single peer. A directly connected
+// target is the next hop, so the message goes to it alone over the already
+// encrypted data channel; otherwise the payload is sealed before it is
+// gossiped through intermediaries.
+func (g *GossipManager) BroadcastTo(topic, targetID string, payload interface{}) error {
+	if g.transport.IsConnected(targetID) {
+		msg := g.newMessage(topic, payload)
+		msg.MaxHops = 1
+		msg.TTL = 1
+		g.signMessageIfKeyed(msg)
+		return g.queueMessage(msg, []string{targetID})
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode payload: %w", err)
+	}
+	env, err := g.SealBytes(targetID, data)
+	if err != nil {
+		if g.config.EndToEnd.Required || !errors.Is(err, ErrNoRecipientKey) {
+			g.recordE2E(func(m *GossipMetrics) { m.E2EFailures++ })
+			return err
+		}
+		// First contact: no key for the target yet, so the payload can only
+		// travel in the clear.
+		g.recordE2E(func(m *GossipMetrics) { m.E2EPlaintext++ })
+		return g.Broadcast(topic, payload)
+	}
+	g.recordE2E(func(m *GossipMetrics) { m.E2ESealed++ })
+	return g.Broadcast(topic, sealedPayload{E2E: env})
+}
+
+// unsealForLocal inspects an incoming message. Sealed payloads for another
+// node are not processed locally (forwarded only); sealed payloads for this
+// node are returned as a decrypted copy so the original keeps propagating.
+func (g *GossipManager) unsealForLocal(msg *common.GossipMessage) (*common.GossipMessage, bool) {
+	env := sealedEnvelope(msg.Payload)
+	if env == nil {
+		return msg, true
+	}
+	if env.Recipient != g.nodeID {
+		return nil, false
+	}
+	if env.Sender != msg.Sender {
+		g.recordE2E(func(m *GossipMetrics) { m.E2EFailures++ })
+		return nil, false
+	}
+
+	plaintext, err := g.OpenBytes(env)
+	if err != nil {
+		g.recordE2E(func(m *GossipMetrics) { m.E2EFailures++ })
+		g.logger.Warn("failed to open e2e envelope", "sender", getShortID(msg.Sender), "type", msg.Type, "error", err)
+		return nil, false
+	}
+	var payload interface{}
+	if err := json.Unmarshal(plaintext, &payload); err != nil {
+		g.recordE2E(func(m *GossipMetrics) { m.E2EFailures++ })
+		return nil, false
+	}
+	g.recordE2E(func(m *GossipMetrics) { m.E2EOpened++ })
+
+	opened := *msg
+	opened.Payload = payload
+	return &opened, true
+}
+
+// sealedEnvelope extracts an envelope from a gossip payload, if it is one.
+func sealedEnvelope(payload interface{}) *E2EEnvelope {
+	switch p := payload.(type) {
+	case sealedPayload:
+		return p.E2E
+	case *sealedPayload:
+		return p.E2E
+	case map[string]interface{}:
+		if _, ok := p["e2e"]; !ok || len(p) != 1 {
+			return nil
+		}
+		data, err := json.Marshal(p)
+		if err != nil {
+			return nil
+		}
+		var sealed sealedPayload
+		if json.Unmarshal(data, &sealed) != nil {
+			return nil
+		}
+		return sealed.E2E
+	}
+	return nil
+}
+
+// openSealedSDP decrypts an SDP relayed inside an sdp.relay payload. SDPs
+// sent by older nodes are plain and returned unchanged.
+func (g *GossipManager) openSealedSDP(relay *SDPRelayPayload) error {
+	var env E2EEnvelope
+	if json.Unmarshal(relay.SDP, &env) != nil || env.Version != e2eEnvelopeVersion || len(env.Ciphertext) == 0 {
+		return nil
+	}
+	if env.Sender != relay.OriginatorID {
+		return errors.New("sdp envelope sender does not match originator")
+	}
+	sdp, err := g.OpenBytes(&env)
+	if err != nil {
+		return err
+	}
+	relay.SDP = sdp
+	return nil
+}
+
+// SealSDP encrypts an SDP for an sdp.relay broadcast. The transport calls it
+// through its relay sealer hook.
+func (g *GossipManager) SealSDP(targetID string, sdp []byte) ([]byte, error) {
+	env, err := g.SealBytes(targetID, sdp)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(env)
+}
+
+func (g *GossipManager) recordE2E(update func(*GossipMetrics)) {
+	g.metricsMu.Lock()
+	update(&g.metrics)
+	g.metricsMu.Unlock()
+}
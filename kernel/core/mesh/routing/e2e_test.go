@@ -0,0 +1,161 @@
+package routing
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"testing"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/curve25519"
+)
+
+func TestX25519FromEd25519MatchesScalarMult(t *testing.T) {
+	for i := 0; i < 8; i++ {
+		pub, priv, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		fromPub, err := x25519PublicFromEd25519(pub)
+		require.NoError(t, err)
+		fromPriv, err := curve25519.X25519(x25519PrivateFromEd25519(priv), curve25519.Basepoint)
+		require.NoError(t, err)
+		assert.Equal(t, fromPriv, fromPub)
+	}
+}
+
+func newE2EPair(t *testing.T) (*GossipManager, *GossipManager, *GossipManager) {
+	t.Helper()
+	alice, err := NewGossipManager("alice", NewMockDHTTransport(), nil)
+	require.NoError(t, err)
+	bob, err := NewGossipManager("bob", NewMockDHTTransport(), nil)
+	require.NoError(t, err)
+	relay, err := NewGossipManager("relay", NewMockDHTTransport(), nil)
+	require.NoError(t, err)
+
+	alice.SetPeerIdentityKey("bob", bob.PublicKey())
+	bob.SetPeerIdentityKey("alice", alice.PublicKey())
+	relay.SetPeerIdentityKey("alice", alice.PublicKey())
+	return alice, bob, relay
+}
+
+func TestE2EEnvelope_RoundTripAndTamper(t *testing.T) {
+	alice, bob, relay := newE2EPair(t)
+
+	env, err := alice.SealBytes("bob", []byte("v=0 secret sdp"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(env.Ciphertext), "secret")
+
+	plaintext, err := bob.OpenBytes(env)
+	require.NoError(t, err)
+	assert.Equal(t, "v=0 secret sdp", string(plaintext))
+
+	_, err = relay.OpenBytes(env)
+	assert.ErrorIs(t, err, ErrNotRecipient)
+
+	env.Ciphertext[0] ^= 0xFF
+	_, err = bob.OpenBytes(env)
+	assert.Error(t, err, "tampered ciphertext must not open")
+
+	_, err = alice.SealBytes("stranger", []byte("x"))
+	assert.ErrorIs(t, err, ErrNoRecipientKey)
+}
+
+func TestE2EEnvelope_RelayForwardsWithoutReading(t *testing.T) {
+	alice, bob, relay := newE2EPair(t)
+
+	env, err := alice.SealBytes("bob", mustJSON(t, map[string]interface{}{"target_id": "bob", "sdp": "offer"}))
+	require.NoError(t, err)
+
+	// Payloads arrive as decoded JSON maps off the wire.
+	var wire interface{}
+	require.NoError(t, json.Unmarshal(mustJSON(t, sealedPayload{E2E: env}), &wire))
+	msg := &common.GossipMessage{Type: "webrtc.signaling", Sender: "alice", Payload: wire, PublicKey: alice.PublicKey()}
+
+	_, ok := relay.unsealForLocal(msg)
+	assert.False(t, ok, "relay must not process a payload sealed for bob")
+
+	opened, ok := bob.unsealForLocal(msg)
+	require.True(t, ok)
+	assert.Equal(t, "offer", opened.Payload.(map[string]interface{})["sdp"])
+	assert.Equal(t, wire, msg.Payload, "the forwarded message must stay sealed")
+	assert.Equal(t, uint64(1), bob.GetMetrics().E2EOpened)
+
+	// A sender cannot claim someone else's envelope.
+	msg.Sender = "mallory"
+	_, ok = bob.unsealForLocal(msg)
+	assert.False(t, ok)
+}
+
+func TestGossipManager_FilesOnlyBoundKeys(t *testing.T) {
+	bob, err := NewGossipManager("bob", NewMockDHTTransport(), nil)
+	require.NoError(t, err)
+	victimPub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	malloryPub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	victim := common.NodeIDFromPublicKey(victimPub)
+
+	// A key that does not derive the sender's ID is neither filed nor trusted
+	bob.learnPeerKey(victim, malloryPub)
+	_, ok := bob.PeerIdentityKey(victim)
+	assert.False(t, ok)
+	assert.ErrorIs(t, bob.CheckPeerKey(victim, malloryPub), ErrUnboundPeerKey)
+
+	bob.learnPeerKey(victim, victimPub)
+	known, ok := bob.PeerIdentityKey(victim)
+	require.True(t, ok)
+	assert.True(t, known.Equal(victimPub))
+	assert.NoError(t, bob.CheckPeerKey(victim, victimPub))
+
+	// Once a verified key is on file, the derived key no longer speaks for the peer
+	rotatedPub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	bob.SetPeerIdentityKey(victim, rotatedPub)
+	assert.Error(t, bob.CheckPeerKey(victim, victimPub))
+	assert.NoError(t, bob.CheckPeerKey(victim, rotatedPub))
+}
+
+func TestE2EEnvelope_ImpersonatorCannotSealAsPeer(t *testing.T) {
+	bob, err := NewGossipManager("bob", NewMockDHTTransport(), nil)
+	require.NoError(t, err)
+	victimPub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	victim := common.NodeIDFromPublicKey(victimPub)
+
+	// Mallory claims the victim's ID but can only sign with her own key
+	mallory, err := NewGossipManager(victim, NewMockDHTTransport(), nil)
+	require.NoError(t, err)
+	mallory.SetPeerIdentityKey("bob", bob.PublicKey())
+	env, err := mallory.SealBytes("bob", mustJSON(t, map[string]interface{}{"sdp": "forged"}))
+	require.NoError(t, err)
+
+	var wire interface{}
+	require.NoError(t, json.Unmarshal(mustJSON(t, sealedPayload{E2E: env}), &wire))
+	msg := &common.GossipMessage{Type: "webrtc.signaling", Sender: victim, Payload: wire, PublicKey: mallory.PublicKey()}
+	bob.learnPeerKey(msg.Sender, msg.PublicKey)
+	_, ok := bob.unsealForLocal(msg)
+	assert.False(t, ok, "an envelope must open only under the key bound to its sender")
+}
+
+func TestOpenSealedSDP(t *testing.T) {
+	alice, bob, _ := newE2EPair(t)
+
+	sealed, err := alice.SealSDP("bob", []byte("v=0"))
+	require.NoError(t, err)
+	relay := SDPRelayPayload{OriginatorID: "alice", TargetID: "bob", SDP: sealed}
+	require.NoError(t, bob.openSealedSDP(&relay))
+	assert.Equal(t, "v=0", string(relay.SDP))
+
+	plain := SDPRelayPayload{OriginatorID: "alice", TargetID: "bob", SDP: []byte("v=0 legacy")}
+	require.NoError(t, bob.openSealedSDP(&plain))
+	assert.Equal(t, "v=0 legacy", string(plain.SDP))
+}
+
+func mustJSON(t *testing.T, v interface{}) []byte {
+	t.Helper()
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	return data
+}
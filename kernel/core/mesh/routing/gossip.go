@@ -17,17 +17,28 @@ import (
 	"sync/atomic"
 	"time"
 
-	"github.com/bits-and-blooms/bloom/v3"
 	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+	"github.com/nmxmxh/inos_v1/kernel/utils/tracing"
 	"github.com/yasserelgammal/rate-limiter/limiter"
 	"github.com/yasserelgammal/rate-limiter/store"
 )
 
+// ErrRateLimited is returned for messages and RPCs from a peer over its
+// rate limit.
+var ErrRateLimited = errors.New("gossip: rate limited")
+
 // GossipManager handles epidemic propagation with anti-entropy and rate limiting
 type GossipManager struct {
 	nodeID    string
 	publicKey ed25519.PublicKey
 	signKey   ed25519.PrivateKey
+	keyMu     sync.RWMutex
+
+	// Keys retired through identity rotation; messages signed by them are rejected
+	revokedKeys map[string]time.Time
+
+	// Peer identity keys for end-to-end envelopes, guarded by keyMu
+	peerKeys map[string]ed25519.PublicKey
 
 	// Local state - Merkle tree for anti-entropy
 	state        *MerkleTree
@@ -42,11 +53,14 @@ type GossipManager struct {
 	peers   []string
 	peersMu sync.RWMutex
 
-	// Deduplication with Bloom filter and TTL-based cleanup
-	seenFilter     *bloom.BloomFilter
-	seenTimestamps map[string]time.Time
-	seenMu         sync.RWMutex
-	seenTTL        time.Duration
+	// Sector overlays, guarded by peersMu; see ShardConfig
+	sector      uint32
+	peerSectors map[string]uint32
+
+	// Deduplication with a rotating pair of Bloom filters
+	seen    *seenFilter
+	seenMu  sync.RWMutex
+	seenTTL time.Duration
 
 	// Transport
 	transport common.Transport
@@ -64,6 +78,14 @@ type GossipManager struct {
 	handlers   map[string]GossipHandler
 	handlersMu sync.RWMutex
 
+	// Bodies of digest-only messages, served to peers that fetch them
+	payloadCache   map[string]*cachedPayload
+	payloadCacheMu sync.Mutex
+
+	// Lazily pushed messages, served to peers that answer an IHAVE with IWANT
+	lazyCache map[string]*lazyMessage
+	lazyMu    sync.Mutex
+
 	// Metrics
 	metrics        GossipMetrics
 	metricsMu      sync.RWMutex
@@ -80,6 +102,18 @@ type GossipManager struct {
 	// Merkle sync state
 	syncState map[string]*MerkleSyncState
 	syncMu    sync.RWMutex
+
+	// Adaptive round interval
+	roundInterval    atomic.Int64 // nanoseconds
+	lastRoundTraffic uint64
+	loadProvider     func() float64
+	frameBudget      FrameBudget   // guarded by intervalMu
+	idleScheduler    IdleScheduler // guarded by intervalMu
+	fanout           fanoutState   // guarded by intervalMu
+	intervalMu       sync.Mutex
+	adaptiveFanout   atomic.Int64 // 0 until the first round; see fanoutFor
+	sends            fanoutCounters
+	suspended        atomic.Bool // Background mode: rounds and anti-entropy skip
 }
 
 // GossipConfig holds gossip configuration
@@ -93,6 +127,7 @@ type GossipConfig struct {
 	MaxHops             int           `json:"max_hops"`              // Maximum propagation hops
 	MaxMessageSize      int           `json:"max_message_size"`      // Maximum message size in bytes
 	QueueSize           int           `json:"queue_size"`            // Size of message queue
+	SeenCacheSize       int           `json:"seen_cache_size"`       // IDs per seen-filter generation before it rotates
 	RateLimit           struct {
 		MessagesPerSecond float64 `json:"messages_per_second"`
 		BurstSize         int     `json:"burst_size"`
@@ -101,6 +136,47 @@ type GossipConfig struct {
 		ExpectedElements  uint    `json:"expected_elements"`
 		FalsePositiveRate float64 `json:"false_positive_rate"`
 	} `json:"bloom_filter"`
+	AdaptiveInterval struct {
+		Enabled     bool          `json:"enabled"`
+		MinInterval time.Duration `json:"min_interval"`
+		MaxInterval time.Duration `json:"max_interval"`
+	} `json:"adaptive_interval"`
+	EndToEnd struct {
+		// Required refuses to gossip targeted payloads in the clear when the
+		// target's identity key is unknown.
+		Required bool `json:"required"`
+	} `json:"end_to_end"`
+	DigestGossip struct {
+		// Payloads above Threshold bytes travel as a digest; peers with a
+		// handler for the type fetch the body on demand. 0 disables.
+		Threshold    int           `json:"threshold"`
+		CacheTTL     time.Duration `json:"cache_ttl"`     // How long bodies stay fetchable
+		MaxCached    int           `json:"max_cached"`    // Bodies kept at once
+		FetchTimeout time.Duration `json:"fetch_timeout"` // Per-peer fetch timeout
+	} `json:"digest_gossip"`
+	LazyPush struct {
+		// Messages whose priority number is at least MinPriority (1 is the
+		// most urgent) go in full to EagerPeers of their targets; the rest
+		// get an IHAVE and fetch the body with IWANT if they lack it.
+		Enabled      bool          `json:"enabled"`
+		MinPriority  int           `json:"min_priority"`
+		EagerPeers   int           `json:"eager_peers"`
+		CacheTTL     time.Duration `json:"cache_ttl"`     // How long announced messages stay fetchable
+		MaxCached    int           `json:"max_cached"`    // Messages kept at once
+		FetchTimeout time.Duration `json:"fetch_timeout"` // IWANT timeout
+	} `json:"lazy_push"`
+	AdaptiveFanout struct {
+		// Fanout scales with the estimated network size and is corrected
+		// for failed sends, duplicates and propagation latency each round;
+		// see adaptiveFanout.
+		Enabled           bool          `json:"enabled"`
+		MinFanout         int           `json:"min_fanout"`
+		MaxFanout         int           `json:"max_fanout"`
+		TargetLatency     time.Duration `json:"target_latency"`      // P95 propagation goal; above it fanout widens and rounds shorten
+		MaxDuplicateRatio float64       `json:"max_duplicate_ratio"` // Duplicates per received message above which fanout shrinks
+		UrgentBoost       int           `json:"urgent_boost"`        // Extra peers for priority 1 messages
+	} `json:"adaptive_fanout"`
+	Sharding ShardConfig `json:"sharding"`
 }
 
 // DefaultGossipConfig returns production-ready defaults
@@ -115,6 +191,7 @@ func DefaultGossipConfig() GossipConfig {
 		MaxHops:             10,
 		MaxMessageSize:      10 * 1024 * 1024, // 10MB
 		QueueSize:           1000,
+		SeenCacheSize:       10000,
 	}
 
 	config.RateLimit.MessagesPerSecond = 100.0
@@ -123,6 +200,31 @@ func DefaultGossipConfig() GossipConfig {
 	config.BloomFilter.ExpectedElements = 100000
 	config.BloomFilter.FalsePositiveRate = 0.01
 
+	config.AdaptiveInterval.Enabled = true
+	config.AdaptiveInterval.MinInterval = 200 * time.Millisecond
+	config.AdaptiveInterval.MaxInterval = 10 * time.Second
+
+	config.DigestGossip.Threshold = 4 * 1024
+	config.DigestGossip.CacheTTL = 10 * time.Minute
+	config.DigestGossip.MaxCached = 1024
+	config.DigestGossip.FetchTimeout = 5 * time.Second
+
+	config.LazyPush.Enabled = true
+	config.LazyPush.MinPriority = 3
+	config.LazyPush.EagerPeers = 1
+	config.LazyPush.CacheTTL = 2 * time.Minute
+	config.LazyPush.MaxCached = 1024
+	config.LazyPush.FetchTimeout = 5 * time.Second
+
+	config.AdaptiveFanout.Enabled = true
+	config.AdaptiveFanout.MinFanout = 2
+	config.AdaptiveFanout.MaxFanout = 12
+	config.AdaptiveFanout.TargetLatency = 2 * time.Second
+	config.AdaptiveFanout.MaxDuplicateRatio = 0.6
+	config.AdaptiveFanout.UrgentBoost = 1
+
+	config.Sharding = DefaultShardConfig()
+
 	return config
 }
 
@@ -139,6 +241,27 @@ type GossipMetrics struct {
 	SyncOperations        uint64    `json:"sync_operations"`
 	FailedSignatures      uint64    `json:"failed_signatures"`
 	RateLimited           uint64    `json:"rate_limited"`
+	RoundIntervalMs       float64   `json:"round_interval_ms"`
+	Fanout                int       `json:"fanout"`                // Normal-priority fanout of the last round
+	NetworkSizeEstimate   int       `json:"network_size_estimate"` // Mesh size the fanout was scaled for
+	DeliveryRatio         float64   `json:"delivery_ratio"`        // Smoothed share of sends that reached their peer
+	DuplicateRatio        float64   `json:"duplicate_ratio"`       // Smoothed duplicates per message received
+	DeferredRounds        uint64    `json:"deferred_rounds"`       // Rounds skipped while the frame budget was blown
+	E2ESealed             uint64    `json:"e2e_sealed"`
+	E2EOpened             uint64    `json:"e2e_opened"`
+	E2EFailures           uint64    `json:"e2e_failures"`
+	E2EPlaintext          uint64    `json:"e2e_plaintext"`
+	DigestsSent           uint64    `json:"digests_sent"`           // Broadcasts sent as a digest only
+	DigestBytesSaved      uint64    `json:"digest_bytes_saved"`     // Payload bytes kept out of those broadcasts
+	PayloadsFetched       uint64    `json:"payloads_fetched"`       // Bodies fetched behind a digest
+	PayloadFetchFailures  uint64    `json:"payload_fetch_failures"` // Digests no peer could supply
+	IHavesSent            uint64    `json:"ihaves_sent"`            // Lazy-push announcements in place of a payload
+	IWantsSent            uint64    `json:"iwants_sent"`            // Fetches for announced messages this node lacked
+	IWantsServed          uint64    `json:"iwants_served"`          // Announced messages sent on request
+	LazyBytesSaved        uint64    `json:"lazy_bytes_saved"`       // Duplicate payload bytes not sent to peers that had them
+	LateDuplicates        uint64    `json:"late_duplicates"`        // Duplicates only the previous seen-filter generation caught
+	SeenFilterRotations   uint64    `json:"seen_filter_rotations"`
+	SeenFilterFPRate      float64   `json:"seen_filter_fp_rate"` // Estimated chance a new message is taken for a duplicate
 	StartTime             time.Time `json:"start_time"`
 }
 
@@ -206,29 +329,27 @@ func NewGossipManager(nodeID string, transport common.Transport, logger *slog.Lo
 
 	config := DefaultGossipConfig()
 
-	// Initialize bloom filter
-	bf := bloom.NewWithEstimates(
-		config.BloomFilter.ExpectedElements,
-		config.BloomFilter.FalsePositiveRate,
-	)
-
 	gossip := &GossipManager{
-		nodeID:         nodeID,
-		publicKey:      publicKey,
-		signKey:        privateKey,
-		state:          NewMerkleTree(),
-		messages:       make(map[string]*common.GossipMessage),
-		seenFilter:     bf,
-		seenTimestamps: make(map[string]time.Time),
-		seenTTL:        config.MessageTTL,
-		transport:      transport,
-		messageQueue:   make(chan QueuedGossipMessage, config.QueueSize),
-		queueSize:      config.QueueSize,
-		handlers:       make(map[string]GossipHandler),
-		config:         config,
-		shutdown:       make(chan struct{}),
-		logger:         logger.With("component", "gossip", "node_id", getShortID(nodeID)),
-		syncState:      make(map[string]*MerkleSyncState),
+		nodeID:       nodeID,
+		publicKey:    publicKey,
+		signKey:      privateKey,
+		revokedKeys:  make(map[string]time.Time),
+		peerKeys:     make(map[string]ed25519.PublicKey),
+		state:        NewMerkleTree(),
+		messages:     make(map[string]*common.GossipMessage),
+		seen:         newSeenFilter(config.BloomFilter.ExpectedElements, config.BloomFilter.FalsePositiveRate, config.MessageTTL, config.SeenCacheSize),
+		seenTTL:      config.MessageTTL,
+		transport:    transport,
+		messageQueue: make(chan QueuedGossipMessage, config.QueueSize),
+		queueSize:    config.QueueSize,
+		handlers:     make(map[string]GossipHandler),
+		payloadCache: make(map[string]*cachedPayload),
+		lazyCache:    make(map[string]*lazyMessage),
+		peerSectors:  make(map[string]uint32),
+		config:       config,
+		shutdown:     make(chan struct{}),
+		logger:       logger.With("component", "gossip", "node_id", getShortID(nodeID)),
+		syncState:    make(map[string]*MerkleSyncState),
 	}
 
 	// Initialize rate limiter
@@ -238,6 +359,7 @@ func NewGossipManager(nodeID string, transport common.Transport, logger *slog.Lo
 
 	// Initialize metrics
 	gossip.metrics.StartTime = time.Now()
+	gossip.metrics.RoundIntervalMs = float64(config.RoundInterval) / float64(time.Millisecond)
 
 	// Register default handlers
 	gossip.registerDefaultHandlers()
@@ -250,17 +372,17 @@ func NewGossipManager(nodeID string, transport common.Transport, logger *slog.Lo
 
 // registerRPCHandlers registers RPC handlers for the transport layer
 func (g *GossipManager) registerRPCHandlers() {
-	g.transport.RegisterRPCHandler("merkle.root", func(ctx context.Context, peerID string, _ json.RawMessage) (interface{}, error) {
+	g.registerRPC("merkle.root", func(ctx context.Context, peerID string, _ json.RawMessage) (interface{}, error) {
 		g.stateMu.RLock()
 		defer g.stateMu.RUnlock()
 		return g.state.Root, nil
 	})
 
-	g.transport.RegisterRPCHandler("merkle.hashes", func(ctx context.Context, peerID string, _ json.RawMessage) (interface{}, error) {
+	g.registerRPC("merkle.hashes", func(ctx context.Context, peerID string, _ json.RawMessage) (interface{}, error) {
 		return g.getAllMessageHashes(), nil
 	})
 
-	g.transport.RegisterRPCHandler("merkle.children", func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error) {
+	g.registerRPC("merkle.children", func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error) {
 		var hashStr string
 		if err := json.Unmarshal(args, &hashStr); err != nil {
 			return nil, err
@@ -282,7 +404,7 @@ func (g *GossipManager) registerRPCHandlers() {
 		return encoded, nil
 	})
 
-	g.transport.RegisterRPCHandler("merkle.bucket_ids", func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error) {
+	g.registerRPC("merkle.bucket_ids", func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error) {
 		var hashStr string
 		if err := json.Unmarshal(args, &hashStr); err != nil {
 			return nil, err
@@ -305,12 +427,12 @@ func (g *GossipManager) registerRPCHandlers() {
 		return nil, errors.New("bucket not found")
 	})
 
-	g.transport.RegisterRPCHandler("gossip.pull", func(ctx context.Context, peerID string, _ json.RawMessage) (interface{}, error) {
+	g.registerRPC("gossip.pull", func(ctx context.Context, peerID string, _ json.RawMessage) (interface{}, error) {
 		// Return summary of all messages (IDs)
 		return g.getAllMessageIDs(), nil
 	})
 
-	g.transport.RegisterRPCHandler("gossip.messages", func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error) {
+	g.registerRPC("gossip.messages", func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error) {
 		var ids []string
 		if err := json.Unmarshal(args, &ids); err != nil {
 			return nil, err
@@ -318,13 +440,16 @@ func (g *GossipManager) registerRPCHandlers() {
 		return g.getMessagesByIDs(ids), nil
 	})
 
-	g.transport.RegisterRPCHandler("gossip.by_hash", func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error) {
+	g.registerRPC("gossip.by_hash", func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error) {
 		var hashes []string
 		if err := json.Unmarshal(args, &hashes); err != nil {
 			return nil, err
 		}
 		return g.getMessagesByHashes(hashes), nil
 	})
+
+	g.registerPayloadHandler()
+	g.registerLazyPushHandlers()
 }
 
 // Start begins the gossip loops
@@ -384,6 +509,7 @@ func (g *GossipManager) AnnounceChunk(chunkHash string) error {
 		HopCount: 0,
 		MaxHops:  g.config.MaxHops,
 	}
+	g.scopeMessage(msg)
 
 	// Sign the message
 	if err := g.signMessage(msg); err != nil {
@@ -479,19 +605,18 @@ func (g *GossipManager) ReceiveMessage(sender string, msg *common.GossipMessage)
 	g.metricsMu.Unlock()
 
 	// Check rate limiting
-	if !g.checkRateLimit(sender) {
-		g.metricsMu.Lock()
-		g.metrics.RateLimited++
-		g.metricsMu.Unlock()
-		g.logger.Debug("rate limited", "sender", getShortID(sender))
-		return errors.New("rate limited")
+	if err := g.rateLimit(sender); err != nil {
+		return err
 	}
 
 	// Check deduplication
 	msgID := g.computeMessageID(msg)
-	if g.isDuplicate(msgID) {
+	if seen, late := g.checkSeen(msgID); seen {
 		g.metricsMu.Lock()
 		g.metrics.DuplicateMessages++
+		if late {
+			g.metrics.LateDuplicates++
+		}
 		g.metricsMu.Unlock()
 		return errors.New("duplicate message")
 	}
@@ -518,10 +643,19 @@ func (g *GossipManager) ReceiveMessage(sender string, msg *common.GossipMessage)
 
 	// Mark as seen
 	g.markSeen(msgID)
+	if msg.ID != "" {
+		g.markSeen(haveKey(msg.ID))
+	}
+	g.learnPeerKey(msg.Sender, msg.PublicKey)
 
-	// Process message
-	if err := g.processMessage(msg); err != nil {
-		return fmt.Errorf("failed to process message: %w", err)
+	// Process message; digest-only payloads are fetched when a handler wants
+	// them, and sealed payloads for other nodes are only forwarded
+	if full, ok := g.expandDigest(sender, msg); ok {
+		if local, ok := g.unsealForLocal(full); ok {
+			if err := g.processMessage(local); err != nil {
+				return fmt.Errorf("failed to process message: %w", err)
+			}
+		}
 	}
 
 	// Update propagation latency (if timestamp is in payload)
@@ -542,8 +676,8 @@ func (g *GossipManager) ReceiveMessage(sender string, msg *common.GossipMessage)
 		g.recordPropagationLatency(latency)
 	}
 
-	// Forward if not at max hops
-	if msg.HopCount < msg.MaxHops-1 {
+	// Forward if not at max hops; sector-local messages only inside their sector
+	if msg.HopCount < msg.MaxHops-1 && g.forwardsScope(msg.Sector) {
 		msg.HopCount++
 		go g.forwardMessage(msg)
 	}
@@ -559,6 +693,8 @@ func (g *GossipManager) ReceiveMessage(sender string, msg *common.GossipMessage)
 
 // processMessage handles a message based on its type
 func (g *GossipManager) processMessage(msg *common.GossipMessage) error {
+	defer g.trackFrame()()
+
 	g.handlersMu.RLock()
 	handler, exists := g.handlers[msg.Type]
 	g.handlersMu.RUnlock()
@@ -568,14 +704,23 @@ func (g *GossipManager) processMessage(msg *common.GossipMessage) error {
 		g.logger.Debug("no handler for message type", "type", msg.Type)
 		return nil
 	}
+	if msg.TraceParent == "" {
+		return handler(msg)
+	}
 
-	return handler(msg)
+	span := tracing.StartRemote(msg.TraceParent, "gossip."+msg.Type, tracing.SpanKindConsumer)
+	span.SetAttribute("gossip.sender", getShortID(msg.Sender))
+	span.SetAttribute("gossip.hops", msg.HopCount)
+	err := handler(msg)
+	span.SetError(err)
+	span.End()
+	return err
 }
 
 // forwardMessage forwards a message to fanout peers
 func (g *GossipManager) forwardMessage(msg *common.GossipMessage) {
 	// Get random peers to forward to
-	peers := g.getRandomPeers(g.config.Fanout)
+	peers := g.gossipPeers(g.fanoutFor(g.getMessagePriority(msg.Type)), msg.Sector)
 	if len(peers) == 0 {
 		return
 	}
@@ -586,6 +731,29 @@ func (g *GossipManager) forwardMessage(msg *common.GossipMessage) {
 
 // Broadcast propagates a message to the entire network
 func (g *GossipManager) Broadcast(topic string, payload interface{}) error {
+	return g.BroadcastContext(context.Background(), topic, payload)
+}
+
+// BroadcastContext is Broadcast for callers inside a trace: receivers handle
+// the message in a span linked to the caller's.
+func (g *GossipManager) BroadcastContext(ctx context.Context, topic string, payload interface{}) error {
+	msg := g.newMessage(topic, payload)
+	if tracing.SpanFromContext(ctx) != nil {
+		_, span := tracing.Start(ctx, "gossip.publish "+topic, tracing.SpanKindProducer)
+		msg.TraceParent = span.Traceparent()
+		span.End()
+	}
+
+	// Large payloads go out as a digest; the signature covers the digest
+	g.digestIfLarge(msg)
+
+	// Sign the message using consistent signatureData
+	g.signMessageIfKeyed(msg)
+
+	return g.queueMessage(msg, nil)
+}
+
+func (g *GossipManager) newMessage(topic string, payload interface{}) *common.GossipMessage {
 	msg := &common.GossipMessage{
 		ID:        fmt.Sprintf("msg_%d_%d", time.Now().UnixNano(), rand.Uint64()),
 		Type:      topic,
@@ -595,16 +763,15 @@ func (g *GossipManager) Broadcast(topic string, payload interface{}) error {
 		TTL:       g.config.MaxHops,
 		MaxHops:   g.config.MaxHops,
 	}
+	g.scopeMessage(msg)
+	return msg
+}
 
-	// Sign the message using consistent signatureData
-	if g.signKey != nil {
-		data := g.signatureData(msg)
-		sig := ed25519.Sign(g.signKey, data)
-		msg.Signature = sig
-		msg.PublicKey = g.publicKey
+func (g *GossipManager) signMessageIfKeyed(msg *common.GossipMessage) {
+	if signKey, publicKey := g.signingKeys(); signKey != nil {
+		msg.Signature = ed25519.Sign(signKey, g.signatureData(msg))
+		msg.PublicKey = publicKey
 	}
-
-	return g.queueMessage(msg, nil)
 }
 
 // queueMessage adds a message to the send queue
@@ -679,7 +846,7 @@ func (g *GossipManager) sendMessageToPeers(queued QueuedGossipMessage) {
 	targets := queued.Targets
 	if len(targets) == 0 {
 		// No specific targets - select random peers based on fanout
-		targets = g.getRandomPeers(g.config.Fanout)
+		targets = g.gossipPeers(g.fanoutFor(queued.Priority), queued.Message.Sector)
 	}
 
 	if len(targets) == 0 {
@@ -691,25 +858,45 @@ func (g *GossipManager) sendMessageToPeers(queued QueuedGossipMessage) {
 		return
 	}
 
+	// Low-priority messages go in full to a few targets and as an IHAVE to
+	// the rest, which mostly have them already
+	eager, lazy := g.splitLazyTargets(targets, queued.Priority)
+	if g.lazyPushes(queued.Priority) {
+		g.rememberLazy(queued.Message)
+	}
+
 	// Send to each target peer
 	var wg sync.WaitGroup
 	errs := make(chan error, len(targets))
 	successCount := int32(0)
 
-	for _, peer := range targets {
-		wg.Add(1)
-		go func(p string) {
-			defer wg.Done()
+	send := func(p string, announce bool) {
+		defer wg.Done()
 
-			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-			defer cancel()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
 
-			if sendErr := g.transport.SendMessage(ctx, p, queued.Message); sendErr != nil {
-				errs <- fmt.Errorf("peer %s: %w", getShortID(p), sendErr)
-			} else {
-				atomic.AddInt32(&successCount, 1)
-			}
-		}(peer)
+		var sendErr error
+		if announce {
+			sendErr = g.sendIHave(ctx, p, queued.Message)
+		} else {
+			sendErr = g.transport.SendMessage(ctx, p, queued.Message)
+		}
+		g.sends.attempts.Add(1)
+		if sendErr != nil {
+			errs <- fmt.Errorf("peer %s: %w", getShortID(p), sendErr)
+		} else {
+			atomic.AddInt32(&successCount, 1)
+			g.sends.successes.Add(1)
+		}
+	}
+	for _, peer := range eager {
+		wg.Add(1)
+		go send(peer, false)
+	}
+	for _, peer := range lazy {
+		wg.Add(1)
+		go send(peer, true)
 	}
 
 	wg.Wait()
@@ -743,21 +930,28 @@ func (g *GossipManager) sendMessageToPeers(queued QueuedGossipMessage) {
 
 // gossipLoop runs the main gossip protocol
 func (g *GossipManager) gossipLoop() {
-	ticker := time.NewTicker(g.config.RoundInterval)
-	defer ticker.Stop()
+	timer := time.NewTimer(g.nextRoundInterval())
+	defer timer.Stop()
 
 	for {
 		select {
 		case <-g.shutdown:
 			return
-		case <-ticker.C:
+		case <-timer.C:
 			g.gossipRound()
+			g.nextFanout()
+			timer.Reset(g.nextRoundInterval())
 		}
 	}
 }
 
 // gossipRound performs one round of gossip
 func (g *GossipManager) gossipRound() {
+	if g.deferForFrameBudget() {
+		return
+	}
+	defer g.trackFrame()()
+
 	// Push: Send recent messages to random peers
 	g.pushGossip()
 
@@ -785,6 +979,9 @@ func (g *GossipManager) pushGossip() {
 	// Send each message to each peer
 	for _, msg := range recent {
 		for _, peer := range peers {
+			if !g.peerInScope(peer, msg.Sector) {
+				continue
+			}
 			go func(p string, m *common.GossipMessage) {
 				ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 				defer cancel()
@@ -808,17 +1005,14 @@ func (g *GossipManager) pullGossip() {
 			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 			defer cancel()
 
-			var response struct {
-				MessageIDs []string `json:"message_ids"`
-			}
-
-			if err := g.transport.SendRPC(ctx, p, "gossip.pull", nil, &response); err != nil {
+			var messageIDs []string
+			if err := g.transport.SendRPC(ctx, p, "gossip.pull", nil, &messageIDs); err != nil {
 				g.logger.Debug("pull request failed", "peer", getShortID(p), "error", err)
 				return
 			}
 
 			// Request missing messages
-			g.requestMissingMessages(p, response.MessageIDs)
+			g.requestMissingMessages(p, messageIDs)
 		}(peer)
 	}
 }
@@ -833,22 +1027,64 @@ func (g *GossipManager) antiEntropyLoop() {
 		case <-g.shutdown:
 			return
 		case <-ticker.C:
-			g.performAntiEntropy()
+			g.runWhenIdle(idleTaskAntiEntropy, g.performAntiEntropy)
 		}
 	}
 }
 
-// SetFanout updates the gossip fanout parameter
+// SetFanout updates the base gossip fanout. With AdaptiveFanout enabled
+// the fanout in use is scaled from it.
 func (g *GossipManager) SetFanout(fanout int) {
 	if fanout < 1 {
 		fanout = 1
 	}
 	g.config.Fanout = fanout
+	g.nextFanout()
 	g.logger.Info("updated gossip fanout", "fanout", fanout)
 }
 
+// Fanout returns the base gossip fanout; see CurrentFanout.
+func (g *GossipManager) Fanout() int {
+	return g.config.Fanout
+}
+
+// SetMemoryLimits resizes the dedup filter, seen cache and message queue.
+// The filter rotates into a generation of the new size, and the old one is
+// still consulted until the next rotation. The queue can only be resized
+// before Start.
+func (g *GossipManager) SetMemoryLimits(bloomElements uint, seenEntries, queueSize int) {
+	g.seenMu.Lock()
+	if bloomElements > 0 {
+		g.config.BloomFilter.ExpectedElements = bloomElements
+	}
+	if seenEntries > 0 {
+		g.config.SeenCacheSize = seenEntries
+	}
+	g.seen.resize(g.config.BloomFilter.ExpectedElements, g.config.SeenCacheSize, time.Now())
+	g.seenMu.Unlock()
+
+	if queueSize > 0 && queueSize != g.queueSize {
+		if g.running.Load() {
+			g.logger.Warn("gossip queue size change ignored while running", "queue_size", queueSize)
+		} else {
+			g.config.QueueSize = queueSize
+			g.queueSize = queueSize
+			g.messageQueue = make(chan QueuedGossipMessage, queueSize)
+		}
+	}
+
+	g.logger.Info("updated gossip memory limits",
+		"bloom_elements", g.config.BloomFilter.ExpectedElements,
+		"seen_entries", g.config.SeenCacheSize,
+		"queue_size", g.queueSize)
+}
+
 // performAntiEntropy performs anti-entropy with a random peer
 func (g *GossipManager) performAntiEntropy() {
+	if g.deferForFrameBudget() {
+		return
+	}
+
 	// Get random peer
 	peers := g.getRandomPeers(1)
 	if len(peers) == 0 {
@@ -1230,7 +1466,7 @@ func (g *GossipManager) requestMissingMessages(peerID string, messageIDs []strin
 
 	g.seenMu.RLock()
 	for _, id := range messageIDs {
-		if !g.seenFilter.Test([]byte(id)) {
+		if seen, _ := g.seen.test(id); !seen {
 			missing = append(missing, id)
 		}
 	}
@@ -1309,23 +1545,17 @@ func (g *GossipManager) sendMessagesByHash(peerID string, hashes []string) {
 	}
 }
 
-// getRandomPeers returns random peers
+// getRandomPeers returns random peers, from this node's shard when
+// gossip is sharded
 func (g *GossipManager) getRandomPeers(count int) []string {
 	g.peersMu.RLock()
 	defer g.peersMu.RUnlock()
 
-	if len(g.peers) <= count {
-		return g.peers
-	}
-
-	// Fisher-Yates shuffle
-	indices := rand.Perm(len(g.peers))
-	selected := make([]string, 0, count)
-	for i := 0; i < count && i < len(indices); i++ {
-		selected = append(selected, g.peers[indices[i]])
+	peers := g.peers
+	if g.config.Sharding.Enabled {
+		peers, _ = g.splitShardLocked(nil)
 	}
-
-	return selected
+	return pickPeers(peers, count)
 }
 
 // UpdatePeers updates the peer list
@@ -1351,6 +1581,7 @@ func (g *GossipManager) AddPeer(peerID string) {
 func (g *GossipManager) RemovePeer(peerID string) {
 	g.peersMu.Lock()
 	defer g.peersMu.Unlock()
+	delete(g.peerSectors, peerID)
 	for i, p := range g.peers {
 		if p == peerID {
 			g.peers = append(g.peers[:i], g.peers[i+1:]...)
@@ -1375,6 +1606,35 @@ func (g *GossipManager) checkRateLimit(peerID string) bool {
 	return g.limiter.Allow(peerID)
 }
 
+// rateLimit takes a token from key's bucket, counting a refusal.
+func (g *GossipManager) rateLimit(key string) error {
+	if g.checkRateLimit(key) {
+		return nil
+	}
+	g.metricsMu.Lock()
+	g.metrics.RateLimited++
+	g.metricsMu.Unlock()
+	g.logger.Debug("rate limited", "key", getShortID(key))
+	return ErrRateLimited
+}
+
+// rpcRateKey is the bucket a peer's gossip RPCs draw from. It is separate
+// from its pushed messages, so an anti-entropy walk does not starve them.
+func rpcRateKey(peerID string) string {
+	return "rpc:" + peerID
+}
+
+// registerRPC registers a gossip RPC served to peers behind their rate
+// limit.
+func (g *GossipManager) registerRPC(method string, handler func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error)) {
+	g.transport.RegisterRPCHandler(method, func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error) {
+		if err := g.rateLimit(rpcRateKey(peerID)); err != nil {
+			return nil, err
+		}
+		return handler(ctx, peerID, args)
+	})
+}
+
 func (g *GossipManager) rebuildRateLimiter() error {
 	g.rateMu.Lock()
 	defer g.rateMu.Unlock()
@@ -1466,12 +1726,13 @@ func (g *GossipManager) computeMessageID(msg *common.GossipMessage) string {
 func (g *GossipManager) signMessage(msg *common.GossipMessage) error {
 	// Create signature data
 	data := g.signatureData(msg)
+	signKey, publicKey := g.signingKeys()
 
 	// Attach public key
-	msg.PublicKey = g.publicKey
+	msg.PublicKey = publicKey
 
 	// Sign
-	signature := ed25519.Sign(g.signKey, data)
+	signature := ed25519.Sign(signKey, data)
 	msg.Signature = signature
 
 	return nil
@@ -1487,9 +1748,13 @@ func (g *GossipManager) verifyMessage(msg *common.GossipMessage) error {
 		return errors.New("message has no public key")
 	}
 
-	// Verify public key matches sender (if sender is derived from key)
-	// For now, we just verify the signature against the attached key
-	// In a real system, we'd verify Sender == Hash(PublicKey)
+	if g.IsKeyRevoked(msg.PublicKey) {
+		return errors.New("message signed with revoked key")
+	}
+
+	// The signature only shows the attached key signed the message; whether
+	// that key speaks for Sender is CheckPeerKey's call, so the key is filed
+	// or used only once it is bound to Sender
 
 	data := g.signatureData(msg)
 
@@ -1508,64 +1773,123 @@ func (g *GossipManager) signatureData(msg *common.GossipMessage) []byte {
 	h.Write([]byte(fmt.Sprintf("%d", msg.Timestamp)))
 	h.Write([]byte(fmt.Sprintf("%d", msg.HopCount)))
 	h.Write([]byte(fmt.Sprintf("%d", msg.MaxHops)))
+	if msg.Sector != nil {
+		h.Write([]byte(fmt.Sprintf("sector:%d", *msg.Sector)))
+	}
 
 	// Add payload
 	if msg.Payload != nil {
-		// Go's json.Marshal sorts map keys, which is deterministic for stable hashing
-		data, _ := json.Marshal(msg.Payload)
-		h.Write(data)
+		h.Write(canonicalPayload(msg.Payload))
 	}
 
 	return h.Sum(nil)
 }
 
+// canonicalPayload encodes a payload the same way before and after it
+// crosses the wire. A struct marshals in field order but arrives as a map,
+// which marshals with sorted keys; decoding into a generic value first
+// gives both sides the sorted form. Numbers stay json.Number so large
+// integers keep their digits.
+func canonicalPayload(payload interface{}) []byte {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var generic interface{}
+	if err := dec.Decode(&generic); err != nil {
+		return data
+	}
+	canonical, err := json.Marshal(generic)
+	if err != nil {
+		return data
+	}
+	return canonical
+}
+
 // SignAttestation signs a mesh attestation payload using the gossip identity key.
 func (g *GossipManager) SignAttestation(data []byte) ([]byte, ed25519.PublicKey, error) {
-	if g.signKey == nil {
+	signKey, publicKey := g.signingKeys()
+	if signKey == nil {
 		return nil, nil, errors.New("gossip signing key not initialized")
 	}
-	signature := ed25519.Sign(g.signKey, data)
-	return signature, g.publicKey, nil
+	signature := ed25519.Sign(signKey, data)
+	return signature, publicKey, nil
+}
+
+func (g *GossipManager) signingKeys() (ed25519.PrivateKey, ed25519.PublicKey) {
+	g.keyMu.RLock()
+	defer g.keyMu.RUnlock()
+	return g.signKey, g.publicKey
+}
+
+// PublicKey returns the current identity public key.
+func (g *GossipManager) PublicKey() ed25519.PublicKey {
+	_, publicKey := g.signingKeys()
+	return publicKey
+}
+
+// SetSigningKey replaces the identity key used for gossip and attestation.
+// The previous key is not revoked; callers publish a rotation record first.
+func (g *GossipManager) SetSigningKey(key ed25519.PrivateKey) error {
+	if len(key) != ed25519.PrivateKeySize {
+		return errors.New("invalid signing key size")
+	}
+	g.keyMu.Lock()
+	g.signKey = key
+	g.publicKey = key.Public().(ed25519.PublicKey)
+	g.keyMu.Unlock()
+	return nil
+}
+
+// RevokePublicKey rejects any future message signed by the given key.
+func (g *GossipManager) RevokePublicKey(key ed25519.PublicKey) {
+	g.keyMu.Lock()
+	if g.revokedKeys == nil {
+		g.revokedKeys = make(map[string]time.Time)
+	}
+	g.revokedKeys[string(key)] = time.Now()
+	g.keyMu.Unlock()
+}
+
+// IsKeyRevoked reports whether the key was retired by an identity rotation.
+func (g *GossipManager) IsKeyRevoked(key ed25519.PublicKey) bool {
+	g.keyMu.RLock()
+	defer g.keyMu.RUnlock()
+	_, revoked := g.revokedKeys[string(key)]
+	return revoked
 }
 
 // isDuplicate checks if we've seen a message
 func (g *GossipManager) isDuplicate(msgID string) bool {
+	seen, _ := g.checkSeen(msgID)
+	return seen
+}
+
+// checkSeen reports whether a message was seen, and whether only the
+// previous filter generation remembered it.
+func (g *GossipManager) checkSeen(msgID string) (seen, late bool) {
 	g.seenMu.RLock()
 	defer g.seenMu.RUnlock()
-
-	return g.seenFilter.Test([]byte(msgID))
+	return g.seen.test(msgID)
 }
 
 // markSeen marks a message as seen
 func (g *GossipManager) markSeen(msgID string) {
 	g.seenMu.Lock()
-	g.seenFilter.Add([]byte(msgID))
-	g.seenTimestamps[msgID] = time.Now()
+	g.seen.add(msgID, time.Now())
 	g.seenMu.Unlock()
 }
 
-// cleanupOldMessages removes old messages from the seen cache
+// cleanupOldMessages rotates the seen filter once its current generation
+// has aged out, so expiry happens even when no new messages arrive.
 func (g *GossipManager) cleanupOldMessages() {
 	g.seenMu.Lock()
 	defer g.seenMu.Unlock()
 
-	now := time.Now()
-	for msgID, timestamp := range g.seenTimestamps {
-		if now.Sub(timestamp) > g.seenTTL {
-			delete(g.seenTimestamps, msgID)
-			// Production-grade bloom filter maintenance: reset periodically
-			if len(g.seenTimestamps) > 10000 {
-				g.resetSeenFilter()
-			}
-		}
-	}
-
-	// Reset bloom filter periodically (e.g., every hour)
-	if len(g.seenTimestamps) == 0 {
-		g.seenFilter = bloom.NewWithEstimates(
-			g.config.BloomFilter.ExpectedElements,
-			g.config.BloomFilter.FalsePositiveRate,
-		)
+	if g.seen.rotateIfDue(time.Now()) {
+		g.logger.Debug("seen filter rotated")
 	}
 }
 
@@ -1587,8 +1911,8 @@ func (g *GossipManager) updateStateWithMessage(msgID string, msg *common.GossipM
 // getMessagePriority returns priority for message type
 func (g *GossipManager) getMessagePriority(msgType string) int {
 	switch msgType {
-	case "chunk_announce":
-		return 1 // High priority
+	case "chunk_announce", "sdp.relay", "sdp.notify", "ice.relay":
+		return 1 // High priority; signaling stalls connection setup
 	case "peer_capability":
 		return 2 // Medium priority
 	default:
@@ -1662,20 +1986,10 @@ func (g *GossipManager) cleanup() {
 	}
 	g.messagesMu.Unlock()
 
-	// Reset seen filter if needed
-	if len(g.seenTimestamps) > 10000 {
-		g.resetSeenFilter()
-	}
-}
-
-// resetSeenFilter clears the seen filter and timestamps
-func (g *GossipManager) resetSeenFilter() {
-	g.seenMu.Lock()
-	defer g.seenMu.Unlock()
+	g.prunePayloadCache(time.Now())
+	g.pruneLazyCache(time.Now())
 
-	g.seenFilter = bloom.NewWithEstimates(100000, 0.01)
-	g.seenTimestamps = make(map[string]time.Time)
-	g.logger.Debug("seen filter reset")
+	g.cleanupOldMessages()
 }
 
 // recordPropagationLatency records message propagation latency
@@ -1704,9 +2018,16 @@ func (g *GossipManager) recordPropagationLatency(latency time.Duration) {
 
 // GetMetrics returns gossip metrics
 func (g *GossipManager) GetMetrics() GossipMetrics {
+	g.seenMu.RLock()
+	rotations, fpRate := g.seen.rotations, g.seen.falsePositiveRate()
+	g.seenMu.RUnlock()
+
 	g.metricsMu.RLock()
 	defer g.metricsMu.RUnlock()
-	return g.metrics
+	metrics := g.metrics
+	metrics.SeenFilterRotations = rotations
+	metrics.SeenFilterFPRate = fpRate
+	return metrics
 }
 
 // GetMessageRate returns messages per second
@@ -1908,9 +2229,17 @@ func (g *GossipManager) handleSDPRelay(msg *common.GossipMessage) error {
 			"from", getShortID(relay.OriginatorID),
 			"session", getShortID(relay.SessionID))
 
-		// Pass to transport for WebRTC handshake
+		if err := g.openSealedSDP(&relay); err != nil {
+			g.logger.Warn("failed to open sealed SDP", "from", getShortID(relay.OriginatorID), "error", err)
+			return nil
+		}
+
+		// Pass to transport for WebRTC handshake; the original message keeps
+		// its sealed SDP for forwarding.
 		if handler, exists := g.handlers["sdp.offer"]; exists {
-			return handler(msg)
+			opened := *msg
+			opened.Payload = relay
+			return handler(&opened)
 		}
 		return nil
 	}
@@ -2052,35 +2381,105 @@ func NewMerkleTree() *MerkleTree {
 	return mt
 }
 
-// AddMessage adds a message to the tree, routing it to the correct bucket
-// Uses copy-on-write to ensure thread safety
+// AddMessage adds a message to its bucket and rehashes only that bucket and
+// its path to the root. Buckets are copy-on-write, so snapshots that share
+// them stay valid.
 func (mt *MerkleTree) AddMessage(msgID string) {
-	h := sha256.Sum256([]byte(msgID))
-	bucketIdx := h[0]
-
-	// Create a copy of the bucket array for modification (copy-on-write)
-	newBuckets := mt.Buckets
-	bucket := make([]string, len(newBuckets[bucketIdx]))
-	copy(bucket, newBuckets[bucketIdx])
-
-	// Add to bucket if not already present
-	found := false
-	for _, id := range bucket {
-		if id == msgID {
-			found = true
-			break
+	if idx, ok := mt.insert(msgID); ok {
+		mt.rehashBucket(idx)
+		mt.rehashPaths([]int{idx})
+	}
+}
+
+// AddMessages adds a batch of messages, rehashing each touched bucket once
+// and every shared ancestor once. It returns how many were new.
+func (mt *MerkleTree) AddMessages(msgIDs []string) int {
+	var dirty [256]bool
+	var touched []int
+	added := 0
+	for _, id := range msgIDs {
+		idx, ok := mt.insert(id)
+		if !ok {
+			continue
 		}
+		added++
+		if !dirty[idx] {
+			dirty[idx] = true
+			touched = append(touched, idx)
+		}
+	}
+	if len(touched) == 0 {
+		return 0
+	}
+	sort.Ints(touched)
+	for _, idx := range touched {
+		mt.rehashBucket(idx)
+	}
+	mt.rehashPaths(touched)
+	return added
+}
+
+// insert places msgID in its bucket, keeping the bucket sorted, and reports
+// the bucket index and whether the ID was new.
+func (mt *MerkleTree) insert(msgID string) (int, bool) {
+	h := sha256.Sum256([]byte(msgID))
+	idx := int(h[0])
+
+	old := mt.Buckets[idx]
+	pos := sort.SearchStrings(old, msgID)
+	if pos < len(old) && old[pos] == msgID {
+		return idx, false
+	}
+	bucket := make([]string, len(old)+1)
+	copy(bucket, old[:pos])
+	bucket[pos] = msgID
+	copy(bucket[pos+1:], old[pos:])
+	mt.Buckets[idx] = bucket
+	return idx, true
+}
+
+// bucketHash hashes a bucket's sorted IDs; an empty bucket hashes to zero.
+func bucketHash(ids []string) []byte {
+	if len(ids) == 0 {
+		return make([]byte, 32)
 	}
+	h := sha256.New()
+	for _, id := range ids {
+		h.Write([]byte(id))
+	}
+	return h.Sum(nil)
+}
+
+func hashPair(left, right []byte) []byte {
+	h := sha256.New()
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
 
-	if !found {
-		bucket = append(bucket, msgID)
-		sort.Strings(bucket)
+func (mt *MerkleTree) rehashBucket(idx int) {
+	mt.Layers[0][idx] = bucketHash(mt.Buckets[idx])
+}
 
-		// Update the bucket atomically
-		newBuckets[bucketIdx] = bucket
-		mt.Buckets = newBuckets
-		mt.rebuild()
+// rehashPaths recomputes the ancestors of the given sorted leaf indices,
+// layer by layer, each parent once. Changed nodes get fresh slices, so a
+// root read out under the lock is never rewritten.
+func (mt *MerkleTree) rehashPaths(leaves []int) {
+	indices := leaves
+	for level := 1; level < len(mt.Layers); level++ {
+		below := mt.Layers[level-1]
+		parents := indices[:0:0]
+		for _, i := range indices {
+			p := i / 2
+			if len(parents) > 0 && parents[len(parents)-1] == p {
+				continue
+			}
+			parents = append(parents, p)
+			mt.Layers[level][p] = hashPair(below[2*p], below[2*p+1])
+		}
+		indices = parents
 	}
+	mt.Root = mt.Layers[len(mt.Layers)-1][0]
 }
 
 // rebuild rebuilds the stable bucket Merkle tree
@@ -2088,41 +2487,23 @@ func (mt *MerkleTree) rebuild() {
 	// 1. Compute 256 bucket hashes (Layer 0)
 	bucketHashes := make([][]byte, 256)
 	for i := 0; i < 256; i++ {
-		if len(mt.Buckets[i]) == 0 {
-			bucketHashes[i] = make([]byte, 32) // Zero hash for empty bucket
-		} else {
-			h := sha256.New()
-			for _, id := range mt.Buckets[i] {
-				h.Write([]byte(id))
-			}
-			bucketHashes[i] = h.Sum(nil)
-		}
+		bucketHashes[i] = bucketHash(mt.Buckets[i])
 	}
 
-	// 2. Build tree levels (fixed depth)
+	// 2. Build tree levels (fixed depth, 256 leaves)
 	mt.Layers = [][][]byte{bucketHashes}
 	current := bucketHashes
 
 	for len(current) > 1 {
-		var next [][]byte
+		next := make([][]byte, 0, len(current)/2)
 		for i := 0; i < len(current); i += 2 {
-			h := sha256.New()
-			h.Write(current[i])
-			if i+1 < len(current) {
-				h.Write(current[i+1])
-			} else {
-				// Should not happen with 256 leaves (power of 2)
-				h.Write(current[i])
-			}
-			next = append(next, h.Sum(nil))
+			next = append(next, hashPair(current[i], current[i+1]))
 		}
 		mt.Layers = append(mt.Layers, next)
 		current = next
 	}
 
-	if len(current) > 0 {
-		mt.Root = current[0]
-	}
+	mt.Root = current[0]
 }
 
 // GetChildren returns the children hashes of a node hash
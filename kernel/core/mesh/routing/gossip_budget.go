@@ -0,0 +1,87 @@
+package routing
+
+// FrameBudget is the slice of the kernel's per-frame budget that gossip
+// reports to and yields to. *foundation.FrameBudget satisfies it.
+type FrameBudget interface {
+	Track(subsystem string) func()
+	DeferLowPriority() bool
+}
+
+// frameSubsystemGossip is the budget subsystem gossip time is charged to.
+const frameSubsystemGossip = "gossip"
+
+// SetFrameBudget attaches the frame budget. While it is blown, push/pull
+// rounds and anti-entropy are skipped; delivery of received messages is not.
+func (g *GossipManager) SetFrameBudget(budget FrameBudget) {
+	g.intervalMu.Lock()
+	g.frameBudget = budget
+	g.intervalMu.Unlock()
+}
+
+func (g *GossipManager) currentFrameBudget() FrameBudget {
+	g.intervalMu.Lock()
+	defer g.intervalMu.Unlock()
+	return g.frameBudget
+}
+
+// trackFrame charges the caller's work to the gossip subsystem.
+func (g *GossipManager) trackFrame() func() {
+	if budget := g.currentFrameBudget(); budget != nil {
+		return budget.Track(frameSubsystemGossip)
+	}
+	return func() {}
+}
+
+// IdleScheduler runs deferrable work in the host's idle time between
+// frames. The mesh coordinator satisfies it.
+type IdleScheduler interface {
+	RunWhenIdle(task string, fn func())
+}
+
+// idleTaskAntiEntropy names anti-entropy rounds to the idle scheduler.
+const idleTaskAntiEntropy = "anti_entropy"
+
+// SetIdleScheduler hands anti-entropy rounds to s, which starts them in an
+// idle window. Nil runs them on their tick.
+func (g *GossipManager) SetIdleScheduler(s IdleScheduler) {
+	g.intervalMu.Lock()
+	g.idleScheduler = s
+	g.intervalMu.Unlock()
+}
+
+// runWhenIdle runs fn through the idle scheduler, or now if there is none.
+func (g *GossipManager) runWhenIdle(task string, fn func()) {
+	g.intervalMu.Lock()
+	s := g.idleScheduler
+	g.intervalMu.Unlock()
+	if s == nil {
+		fn()
+		return
+	}
+	s.RunWhenIdle(task, fn)
+}
+
+// SetSuspended pauses push/pull rounds and anti-entropy while the host page
+// is hidden. Received messages are still delivered and forwarded.
+func (g *GossipManager) SetSuspended(suspended bool) {
+	g.suspended.Store(suspended)
+}
+
+// deferForFrameBudget reports whether background gossip work should skip
+// this tick, counting the skip.
+func (g *GossipManager) deferForFrameBudget() bool {
+	if g.suspended.Load() {
+		g.metricsMu.Lock()
+		g.metrics.DeferredRounds++
+		g.metricsMu.Unlock()
+		return true
+	}
+	budget := g.currentFrameBudget()
+	if budget == nil || !budget.DeferLowPriority() {
+		return false
+	}
+	g.metricsMu.Lock()
+	g.metrics.DeferredRounds++
+	g.metricsMu.Unlock()
+	return true
+}
@@ -0,0 +1,86 @@
+package routing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeFrameBudget struct {
+	deferring bool
+	tracked   map[string]int
+}
+
+func (f *fakeFrameBudget) Track(subsystem string) func() {
+	return func() { f.tracked[subsystem]++ }
+}
+
+func (f *fakeFrameBudget) DeferLowPriority() bool { return f.deferring }
+
+func TestGossipManager_FrameBudget(t *testing.T) {
+	gossip, err := NewGossipManager("node1", NewMockDHTTransport(), nil)
+	require.NoError(t, err)
+	budget := &fakeFrameBudget{tracked: map[string]int{}}
+	gossip.SetFrameBudget(budget)
+
+	gossip.RegisterHandler("test", func(msg *common.GossipMessage) error { return nil })
+	require.NoError(t, gossip.processMessage(&common.GossipMessage{Type: "test", Timestamp: time.Now().UnixNano()}))
+	assert.Equal(t, 1, budget.tracked[frameSubsystemGossip], "delivery should be charged to gossip")
+
+	gossip.gossipRound()
+	assert.Equal(t, 2, budget.tracked[frameSubsystemGossip])
+	assert.Zero(t, gossip.GetMetrics().DeferredRounds)
+
+	budget.deferring = true
+	gossip.gossipRound()
+	gossip.performAntiEntropy()
+	assert.Equal(t, 2, budget.tracked[frameSubsystemGossip], "deferred rounds do no work")
+	assert.Equal(t, uint64(2), gossip.GetMetrics().DeferredRounds)
+
+	require.NoError(t, gossip.processMessage(&common.GossipMessage{Type: "test", Timestamp: time.Now().UnixNano()}))
+	assert.Equal(t, 3, budget.tracked[frameSubsystemGossip], "received messages are still delivered")
+}
+
+func TestGossipManager_SuspendedSkipsRounds(t *testing.T) {
+	gossip, err := NewGossipManager("node1", NewMockDHTTransport(), nil)
+	require.NoError(t, err)
+	budget := &fakeFrameBudget{tracked: map[string]int{}}
+	gossip.SetFrameBudget(budget)
+
+	gossip.SetSuspended(true)
+	gossip.gossipRound()
+	gossip.performAntiEntropy()
+	assert.Zero(t, budget.tracked[frameSubsystemGossip], "suspended rounds do no work")
+	assert.Equal(t, uint64(2), gossip.GetMetrics().DeferredRounds)
+
+	gossip.SetSuspended(false)
+	gossip.gossipRound()
+	assert.Equal(t, 1, budget.tracked[frameSubsystemGossip])
+}
+
+type recordingIdleScheduler struct {
+	tasks []string
+}
+
+func (s *recordingIdleScheduler) RunWhenIdle(task string, fn func()) {
+	s.tasks = append(s.tasks, task)
+	fn()
+}
+
+func TestGossipManager_IdleScheduler(t *testing.T) {
+	gossip, err := NewGossipManager("node1", NewMockDHTTransport(), nil)
+	require.NoError(t, err)
+
+	ran := 0
+	gossip.runWhenIdle(idleTaskAntiEntropy, func() { ran++ })
+	assert.Equal(t, 1, ran, "without a scheduler work runs on its tick")
+
+	scheduler := &recordingIdleScheduler{}
+	gossip.SetIdleScheduler(scheduler)
+	gossip.runWhenIdle(idleTaskAntiEntropy, func() { ran++ })
+	assert.Equal(t, 2, ran)
+	assert.Equal(t, []string{idleTaskAntiEntropy}, scheduler.tasks)
+}
@@ -0,0 +1,234 @@
+package routing
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+)
+
+const payloadFetchMethod = "gossip.payload"
+
+// PayloadDigest stands in for a gossip payload above the digest threshold.
+// Hash is the hex SHA-256 of the payload's JSON encoding.
+type PayloadDigest struct {
+	Hash string `json:"hash"`
+	Size int    `json:"size"`
+}
+
+// digestPayload is the gossip payload wrapper for a digest. The wrapper is
+// what the publisher signs, so a fetched body is authenticated by its hash.
+type digestPayload struct {
+	Digest *PayloadDigest `json:"digest_only"`
+}
+
+type payloadFetchRequest struct {
+	Hash string `json:"hash"`
+}
+
+type payloadFetchResponse struct {
+	Payload []byte `json:"payload,omitempty"`
+}
+
+type cachedPayload struct {
+	data    []byte
+	expires time.Time
+}
+
+// digestIfLarge swaps a payload above the threshold for its digest and keeps
+// the body to serve peers that ask for it. Must run before signing.
+func (g *GossipManager) digestIfLarge(msg *common.GossipMessage) {
+	threshold := g.config.DigestGossip.Threshold
+	if threshold <= 0 || msg.Payload == nil || payloadDigestOf(msg.Payload) != nil {
+		return
+	}
+	data, err := json.Marshal(msg.Payload)
+	if err != nil || len(data) <= threshold {
+		return
+	}
+
+	sum := sha256.Sum256(data)
+	digest := &PayloadDigest{Hash: hex.EncodeToString(sum[:]), Size: len(data)}
+	g.cachePayload(digest.Hash, data)
+	msg.Payload = digestPayload{Digest: digest}
+
+	g.metricsMu.Lock()
+	g.metrics.DigestsSent++
+	g.metrics.DigestBytesSaved += uint64(len(data) - len(digest.Hash))
+	g.metricsMu.Unlock()
+}
+
+// expandDigest returns the message to process locally. Digest-only messages
+// are fetched only when a handler wants their type; otherwise, or when no
+// peer can supply a matching body, they are forwarded without processing.
+func (g *GossipManager) expandDigest(sender string, msg *common.GossipMessage) (*common.GossipMessage, bool) {
+	digest := payloadDigestOf(msg.Payload)
+	if digest == nil {
+		return msg, true
+	}
+
+	g.handlersMu.RLock()
+	_, wanted := g.handlers[msg.Type]
+	g.handlersMu.RUnlock()
+	if !wanted {
+		return nil, false
+	}
+
+	payload, err := g.fetchPayload(digest, sender, msg.Sender)
+	if err != nil {
+		g.metricsMu.Lock()
+		g.metrics.PayloadFetchFailures++
+		g.metricsMu.Unlock()
+		g.logger.Debug("failed to fetch gossip payload",
+			"sender", getShortID(msg.Sender),
+			"type", msg.Type,
+			"digest", getShortID(digest.Hash),
+			"error", err)
+		return nil, false
+	}
+
+	full := *msg
+	full.Payload = payload
+	return &full, true
+}
+
+// fetchPayload asks each peer in turn for the body behind a digest and keeps
+// the first one that hashes correctly, so this node can serve it onwards.
+func (g *GossipManager) fetchPayload(digest *PayloadDigest, peers ...string) (interface{}, error) {
+	if data, ok := g.cachedPayloadData(digest.Hash); ok {
+		return decodePayload(data)
+	}
+	if g.config.MaxMessageSize > 0 && digest.Size > g.config.MaxMessageSize {
+		return nil, errors.New("digest exceeds max message size")
+	}
+
+	timeout := g.config.DigestGossip.FetchTimeout
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+
+	lastErr := errors.New("no peer to fetch from")
+	tried := make(map[string]bool, len(peers))
+	for _, peerID := range peers {
+		if peerID == "" || peerID == g.nodeID || tried[peerID] {
+			continue
+		}
+		tried[peerID] = true
+
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		var resp payloadFetchResponse
+		err := g.transport.SendRPC(ctx, peerID, payloadFetchMethod, payloadFetchRequest{Hash: digest.Hash}, &resp)
+		cancel()
+		if err != nil {
+			lastErr = fmt.Errorf("peer %s: %w", getShortID(peerID), err)
+			continue
+		}
+		sum := sha256.Sum256(resp.Payload)
+		if len(resp.Payload) == 0 || hex.EncodeToString(sum[:]) != digest.Hash {
+			lastErr = fmt.Errorf("peer %s returned a payload not matching the digest", getShortID(peerID))
+			continue
+		}
+
+		payload, err := decodePayload(resp.Payload)
+		if err != nil {
+			return nil, err
+		}
+		g.cachePayload(digest.Hash, resp.Payload)
+		g.metricsMu.Lock()
+		g.metrics.PayloadsFetched++
+		g.metricsMu.Unlock()
+		return payload, nil
+	}
+	return nil, lastErr
+}
+
+func decodePayload(data []byte) (interface{}, error) {
+	var payload interface{}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, fmt.Errorf("invalid payload: %w", err)
+	}
+	return payload, nil
+}
+
+// cachePayload keeps a body for the configured TTL. When full, the entry
+// closest to expiry makes room.
+func (g *GossipManager) cachePayload(hash string, data []byte) {
+	g.payloadCacheMu.Lock()
+	defer g.payloadCacheMu.Unlock()
+
+	if _, ok := g.payloadCache[hash]; !ok && g.config.DigestGossip.MaxCached > 0 && len(g.payloadCache) >= g.config.DigestGossip.MaxCached {
+		var oldest string
+		for h, cached := range g.payloadCache {
+			if oldest == "" || cached.expires.Before(g.payloadCache[oldest].expires) {
+				oldest = h
+			}
+		}
+		delete(g.payloadCache, oldest)
+	}
+	g.payloadCache[hash] = &cachedPayload{
+		data:    data,
+		expires: time.Now().Add(g.config.DigestGossip.CacheTTL),
+	}
+}
+
+func (g *GossipManager) cachedPayloadData(hash string) ([]byte, bool) {
+	g.payloadCacheMu.Lock()
+	defer g.payloadCacheMu.Unlock()
+
+	cached, ok := g.payloadCache[hash]
+	if !ok || time.Now().After(cached.expires) {
+		return nil, false
+	}
+	return cached.data, true
+}
+
+func (g *GossipManager) prunePayloadCache(now time.Time) {
+	g.payloadCacheMu.Lock()
+	for hash, cached := range g.payloadCache {
+		if now.After(cached.expires) {
+			delete(g.payloadCache, hash)
+		}
+	}
+	g.payloadCacheMu.Unlock()
+}
+
+func (g *GossipManager) registerPayloadHandler() {
+	g.registerRPC(payloadFetchMethod, func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error) {
+		var req payloadFetchRequest
+		if err := json.Unmarshal(args, &req); err != nil {
+			return nil, err
+		}
+		data, ok := g.cachedPayloadData(req.Hash)
+		if !ok {
+			return nil, errors.New("payload not found")
+		}
+		return payloadFetchResponse{Payload: data}, nil
+	})
+}
+
+// payloadDigestOf extracts a digest from a gossip payload, if it is one.
+func payloadDigestOf(payload interface{}) *PayloadDigest {
+	switch p := payload.(type) {
+	case digestPayload:
+		return p.Digest
+	case *digestPayload:
+		return p.Digest
+	case map[string]interface{}:
+		raw, ok := p["digest_only"].(map[string]interface{})
+		if !ok || len(p) != 1 {
+			return nil
+		}
+		hash, _ := raw["hash"].(string)
+		size, _ := raw["size"].(float64)
+		if len(hash) != sha256.Size*2 {
+			return nil
+		}
+		return &PayloadDigest{Hash: hash, Size: int(size)}
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package routing
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDigestGossip_FetchesLargePayloadOnDemand(t *testing.T) {
+	aliceT, bobT, relayT := NewMockDHTTransport(), NewMockDHTTransport(), NewMockDHTTransport()
+	bobT.peers["alice"] = aliceT
+	relayT.peers["alice"] = aliceT
+
+	alice, err := NewGossipManager("alice", aliceT, nil)
+	require.NoError(t, err)
+	bob, err := NewGossipManager("bob", bobT, nil)
+	require.NoError(t, err)
+	relay, err := NewGossipManager("relay", relayT, nil)
+	require.NoError(t, err)
+
+	var got map[string]interface{}
+	bob.RegisterHandler("capability.full", func(msg *common.GossipMessage) error {
+		got, _ = msg.Payload.(map[string]interface{})
+		return nil
+	})
+
+	blob := strings.Repeat("x", 8*1024)
+	msg := alice.newMessage("capability.full", map[string]interface{}{"blob": blob})
+	alice.digestIfLarge(msg)
+	alice.signMessageIfKeyed(msg)
+
+	digest := payloadDigestOf(msg.Payload)
+	require.NotNil(t, digest, "payload above the threshold must travel as a digest")
+	assert.Greater(t, digest.Size, len(blob))
+	assert.Less(t, alice.estimateMessageSize(msg), 1024)
+	assert.Equal(t, uint64(1), alice.GetMetrics().DigestsSent)
+
+	// A relay with no handler forwards the digest without fetching.
+	require.NoError(t, relay.ReceiveMessage("alice", msg))
+	assert.NotContains(t, relayT.calls, "rpc:alice:"+payloadFetchMethod)
+	assert.Equal(t, uint64(0), relay.GetMetrics().PayloadsFetched)
+
+	relayed := *msg
+	relayed.HopCount = 0
+	require.NoError(t, bob.ReceiveMessage("alice", &relayed))
+	require.NotNil(t, got)
+	assert.Equal(t, blob, got["blob"])
+	assert.Equal(t, uint64(1), bob.GetMetrics().PayloadsFetched)
+
+	// Bob now serves the body to its own peers.
+	_, ok := bob.cachedPayloadData(digest.Hash)
+	assert.True(t, ok)
+}
+
+func TestDigestGossip_RejectsMismatchedPayload(t *testing.T) {
+	aliceT, bobT := NewMockDHTTransport(), NewMockDHTTransport()
+	bobT.peers["alice"] = aliceT
+
+	alice, err := NewGossipManager("alice", aliceT, nil)
+	require.NoError(t, err)
+	bob, err := NewGossipManager("bob", bobT, nil)
+	require.NoError(t, err)
+
+	handled := false
+	bob.RegisterHandler("metrics.full", func(msg *common.GossipMessage) error {
+		handled = true
+		return nil
+	})
+
+	msg := alice.newMessage("metrics.full", map[string]interface{}{"blob": strings.Repeat("y", 8*1024)})
+	alice.digestIfLarge(msg)
+	alice.signMessageIfKeyed(msg)
+
+	digest := payloadDigestOf(msg.Payload)
+	require.NotNil(t, digest)
+	alice.cachePayload(digest.Hash, []byte(`{"blob":"forged"}`))
+
+	require.NoError(t, bob.ReceiveMessage("alice", msg))
+	assert.False(t, handled, "a body that does not match the signed digest must not be processed")
+	assert.Equal(t, uint64(1), bob.GetMetrics().PayloadFetchFailures)
+}
+
+func TestDigestGossip_SmallPayloadsStayInline(t *testing.T) {
+	g, err := NewGossipManager("alice", NewMockDHTTransport(), nil)
+	require.NoError(t, err)
+
+	msg := g.newMessage("peer_capability", map[string]interface{}{"peer_id": "alice"})
+	g.digestIfLarge(msg)
+	assert.Nil(t, payloadDigestOf(msg.Payload))
+	assert.Equal(t, uint64(0), g.GetMetrics().DigestsSent)
+}
@@ -0,0 +1,180 @@
+package routing
+
+import (
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+// Network size at which the configured Fanout is used unscaled. Epidemic
+// delivery needs a fanout near ln(N)+1, which the default of 3 meets here.
+const adaptiveFanoutReferenceSize = 8
+
+// Weight of the newest round in the smoothed delivery and duplicate ratios.
+const fanoutRatioSmoothing = 0.3
+
+// fanoutState is what the fanout is derived from, refreshed every round.
+// Guarded by intervalMu.
+type fanoutState struct {
+	estimator func() int
+
+	lastAttempts   uint64
+	lastSuccesses  uint64
+	lastReceived   uint64
+	lastDuplicates uint64
+	deliveryRatio  float64 // Smoothed share of sends that reached their peer
+	duplicateRatio float64 // Smoothed duplicates per message received
+}
+
+// fanoutCounters count individual sends. They are atomics because every
+// send goroutine updates them.
+type fanoutCounters struct {
+	attempts  atomic.Uint64
+	successes atomic.Uint64
+}
+
+// SetNetworkSizeEstimator supplies the mesh size estimate the fanout scales
+// with, typically the DHT's bucket-fill estimate. Without one the known peer
+// count is used.
+func (g *GossipManager) SetNetworkSizeEstimator(estimate func() int) {
+	g.intervalMu.Lock()
+	g.fanout.estimator = estimate
+	g.intervalMu.Unlock()
+}
+
+// CurrentFanout returns the fanout normal-priority messages go out with.
+func (g *GossipManager) CurrentFanout() int {
+	return g.fanoutFor(0)
+}
+
+// fanoutFor returns the fanout for a message of the given priority (1 is
+// the most urgent). Urgent messages get AdaptiveFanout.UrgentBoost more
+// peers; lazily pushed ones need no more than the base, as most targets
+// only get an IHAVE.
+func (g *GossipManager) fanoutFor(priority int) int {
+	fanout := int(g.adaptiveFanout.Load())
+	if fanout <= 0 {
+		fanout = g.config.Fanout
+	}
+	cfg := g.config.AdaptiveFanout
+	if cfg.Enabled && priority == 1 {
+		fanout += cfg.UrgentBoost
+		if cfg.MaxFanout > 0 {
+			fanout = min(fanout, max(cfg.MaxFanout, g.config.Fanout))
+		}
+	}
+	return max(fanout, 1)
+}
+
+// networkSize returns the estimated mesh size, including this node.
+func (g *GossipManager) networkSize() int {
+	g.intervalMu.Lock()
+	estimate := g.fanout.estimator
+	g.intervalMu.Unlock()
+	if estimate != nil {
+		if n := estimate(); n > 0 {
+			return n
+		}
+	}
+	return g.TotalPeers() + 1
+}
+
+// nextFanout folds the last round's sends and receipts into the delivery
+// and duplicate ratios, recomputes the fanout and publishes it to metrics.
+func (g *GossipManager) nextFanout() int {
+	size := g.networkSize()
+	attempts, successes := g.sends.attempts.Load(), g.sends.successes.Load()
+
+	g.metricsMu.RLock()
+	received, duplicates := g.metrics.MessagesReceived, g.metrics.DuplicateMessages
+	p95 := time.Duration(g.metrics.PropagationLatencyP95 * float64(time.Millisecond))
+	g.metricsMu.RUnlock()
+
+	g.intervalMu.Lock()
+	f := &g.fanout
+	if sent := attempts - f.lastAttempts; sent > 0 {
+		ratio := float64(successes-f.lastSuccesses) / float64(sent)
+		f.deliveryRatio = smoothRatio(f.deliveryRatio, ratio, f.lastAttempts == 0)
+	}
+	if got := received - f.lastReceived; got > 0 {
+		ratio := float64(duplicates-f.lastDuplicates) / float64(got)
+		f.duplicateRatio = smoothRatio(f.duplicateRatio, ratio, f.lastReceived == 0)
+	}
+	f.lastAttempts, f.lastSuccesses = attempts, successes
+	f.lastReceived, f.lastDuplicates = received, duplicates
+	delivery, dups := f.deliveryRatio, f.duplicateRatio
+	g.intervalMu.Unlock()
+	if attempts == 0 {
+		delivery = 1 // Nothing sent yet
+	}
+
+	fanout := adaptiveFanout(g.config, size, delivery, dups, p95)
+	g.adaptiveFanout.Store(int64(fanout))
+
+	g.metricsMu.Lock()
+	g.metrics.Fanout = fanout
+	g.metrics.NetworkSizeEstimate = size
+	g.metrics.DeliveryRatio = delivery
+	g.metrics.DuplicateRatio = dups
+	g.metricsMu.Unlock()
+	return fanout
+}
+
+func smoothRatio(current, sample float64, first bool) float64 {
+	if first {
+		return sample
+	}
+	return current + fanoutRatioSmoothing*(sample-current)
+}
+
+// adaptiveFanout scales the configured fanout:
+//   - size: by (ln N + 1) relative to the reference size, so a 5000-node
+//     mesh fans out about three times wider than an 8-node one;
+//   - delivery: failed sends are made up for, up to 2x;
+//   - duplicates: above MaxDuplicateRatio the fanout is overshooting and
+//     shrinks, down to half, unless sends are failing;
+//   - latency: a P95 propagation latency over TargetLatency widens it by up
+//     to 1.5x, so messages need fewer hops.
+func adaptiveFanout(cfg GossipConfig, size int, delivery, duplicates float64, p95 time.Duration) int {
+	base := cfg.Fanout
+	adaptive := cfg.AdaptiveFanout
+	if !adaptive.Enabled || base <= 0 {
+		return max(base, 1)
+	}
+
+	factor := 1.0
+	if size > 1 {
+		factor = (math.Log(float64(size)) + 1) / (math.Log(adaptiveFanoutReferenceSize) + 1)
+	}
+
+	factor /= clampFloat(delivery, 0.5, 1)
+
+	if adaptive.MaxDuplicateRatio > 0 && duplicates > adaptive.MaxDuplicateRatio && delivery >= 0.9 {
+		factor *= clampFloat(adaptive.MaxDuplicateRatio/duplicates, 0.5, 1)
+	}
+
+	if adaptive.TargetLatency > 0 && p95 > adaptive.TargetLatency {
+		factor *= clampFloat(float64(p95)/float64(adaptive.TargetLatency), 1, 1.5)
+	}
+
+	// As with the round interval, bounds never pull the fanout across the
+	// configured base, so a node that lowered it (power saving) stays low.
+	fanout := int(math.Round(float64(base) * factor))
+	if lo := min(adaptive.MinFanout, base); fanout < lo {
+		fanout = lo
+	}
+	if hi := max(adaptive.MaxFanout, base); adaptive.MaxFanout > 0 && fanout > hi {
+		fanout = hi
+	}
+	return max(fanout, 1)
+}
+
+// latencyIntervalFactor shortens rounds, down to half, while the P95
+// propagation latency is over TargetLatency.
+func latencyIntervalFactor(cfg GossipConfig, p95 time.Duration) float64 {
+	target := cfg.AdaptiveFanout.TargetLatency
+	if !cfg.AdaptiveFanout.Enabled || target <= 0 || p95 <= target {
+		return 1
+	}
+	return clampFloat(float64(target)/float64(p95), 0.5, 1)
+}
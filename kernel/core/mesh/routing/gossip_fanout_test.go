@@ -0,0 +1,86 @@
+package routing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdaptiveFanout(t *testing.T) {
+	cfg := DefaultGossipConfig()
+	base := cfg.Fanout
+
+	assert.Equal(t, base, adaptiveFanout(cfg, adaptiveFanoutReferenceSize, 1, 0, 0),
+		"the reference size should keep the base fanout")
+	assert.Equal(t, base, adaptiveFanout(cfg, 5, 1, 0, 0))
+	large := adaptiveFanout(cfg, 5000, 1, 0, 0)
+	assert.Equal(t, 9, large, "a large mesh should fan out near ln(N)+1")
+
+	assert.Equal(t, 2*base, adaptiveFanout(cfg, adaptiveFanoutReferenceSize, 0.4, 0, 0),
+		"failed sends should be made up for, up to 2x")
+	assert.Equal(t, 2, adaptiveFanout(cfg, adaptiveFanoutReferenceSize, 1, 1.2, 0),
+		"heavy duplication should shrink the fanout")
+	assert.Equal(t, 6, adaptiveFanout(cfg, adaptiveFanoutReferenceSize, 0.5, 1.2, 0),
+		"duplicates do not shrink a fanout whose sends are failing")
+	assert.Equal(t, 5, adaptiveFanout(cfg, adaptiveFanoutReferenceSize, 1, 0, time.Minute),
+		"slow propagation should widen the fanout by up to 1.5x")
+
+	assert.Equal(t, cfg.AdaptiveFanout.MaxFanout, adaptiveFanout(cfg, 1<<30, 0.1, 0, time.Minute))
+
+	// A lowered base stays below the minimum
+	cfg.Fanout = 1
+	assert.Equal(t, 1, adaptiveFanout(cfg, adaptiveFanoutReferenceSize, 1, 1.2, 0))
+
+	cfg.AdaptiveFanout.Enabled = false
+	cfg.Fanout = 3
+	assert.Equal(t, 3, adaptiveFanout(cfg, 5000, 0.1, 0, time.Minute))
+}
+
+func TestGossipManager_FanoutFollowsNetworkSize(t *testing.T) {
+	gossip, err := NewGossipManager("node1", NewMockDHTTransport(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, gossip.config.Fanout, gossip.CurrentFanout(), "before the first round the base fanout is used")
+
+	size := 5000
+	gossip.SetNetworkSizeEstimator(func() int { return size })
+	fanout := gossip.nextFanout()
+	assert.Equal(t, fanout, gossip.CurrentFanout())
+	assert.Greater(t, fanout, gossip.config.Fanout)
+	assert.Equal(t, fanout+gossip.config.AdaptiveFanout.UrgentBoost, gossip.fanoutFor(1), "urgent messages get extra peers")
+	assert.Equal(t, fanout, gossip.fanoutFor(gossip.config.LazyPush.MinPriority))
+
+	metrics := gossip.GetMetrics()
+	assert.Equal(t, fanout, metrics.Fanout)
+	assert.Equal(t, size, metrics.NetworkSizeEstimate)
+
+	// Power saving lowers the base; the fanout follows at once
+	gossip.SetFanout(1)
+	assert.Less(t, gossip.CurrentFanout(), fanout)
+}
+
+func TestGossipManager_FanoutTracksDelivery(t *testing.T) {
+	gossip, err := NewGossipManager("node1", NewMockDHTTransport(), nil)
+	require.NoError(t, err)
+	gossip.SetNetworkSizeEstimator(func() int { return adaptiveFanoutReferenceSize })
+
+	gossip.sends.attempts.Add(10)
+	gossip.sends.successes.Add(5)
+	assert.Equal(t, 2*gossip.config.Fanout, gossip.nextFanout())
+	assert.InDelta(t, 0.5, gossip.GetMetrics().DeliveryRatio, 0.01)
+
+	// Later rounds are smoothed in
+	gossip.sends.attempts.Add(10)
+	gossip.sends.successes.Add(10)
+	gossip.nextFanout()
+	assert.InDelta(t, 0.65, gossip.GetMetrics().DeliveryRatio, 0.01)
+}
+
+func TestLatencyIntervalFactor(t *testing.T) {
+	cfg := DefaultGossipConfig()
+	target := cfg.AdaptiveFanout.TargetLatency
+	assert.Equal(t, 1.0, latencyIntervalFactor(cfg, target))
+	assert.InDelta(t, 0.8, latencyIntervalFactor(cfg, target*5/4), 0.001)
+	assert.Equal(t, 0.5, latencyIntervalFactor(cfg, 10*target))
+}
@@ -382,6 +382,47 @@ func TestGossipManager_RateLimiting(t *testing.T) {
 	}
 }
 
+// TestGossipManager_RPCRateLimiting tests that RPCs served to peers draw
+// from their own per-peer bucket
+func TestGossipManager_RPCRateLimiting(t *testing.T) {
+	transport := NewMockDHTTransport()
+	gossip, _ := NewGossipManager("node1", transport, nil)
+	gossip.config.RateLimit.MessagesPerSecond = 0.1
+	gossip.config.RateLimit.BurstSize = 1
+	if err := gossip.rebuildRateLimiter(); err != nil {
+		t.Fatalf("rebuild rate limiter: %v", err)
+	}
+
+	for _, method := range []string{"gossip.pull", iHaveMethod, "merkle.root"} {
+		if _, ok := transport.handlers[method]; !ok {
+			t.Fatalf("%s not registered", method)
+		}
+	}
+	pull := transport.handlers["gossip.pull"]
+	if _, err := pull(context.Background(), "peer2", nil); err != nil {
+		t.Fatalf("first RPC should be allowed, got %v", err)
+	}
+	if _, err := pull(context.Background(), "peer2", nil); err != ErrRateLimited {
+		t.Fatalf("expected the second RPC to be rate limited, got %v", err)
+	}
+	if _, err := transport.handlers[iHaveMethod](context.Background(), "peer2", []byte(`{}`)); err != ErrRateLimited {
+		t.Fatalf("expected IHAVE to share the peer's RPC bucket, got %v", err)
+	}
+	if _, err := pull(context.Background(), "peer3", nil); err != nil {
+		t.Fatalf("other peers keep their own bucket, got %v", err)
+	}
+	if gossip.GetMetrics().RateLimited != 2 {
+		t.Fatalf("expected two rate limited RPCs, got %d", gossip.GetMetrics().RateLimited)
+	}
+
+	// Pushed messages are limited separately from RPCs
+	msg := &common.GossipMessage{ID: "m1", Type: "t", Payload: []byte("d"), Sender: "peer2", Timestamp: time.Now().UnixNano(), MaxHops: 10}
+	gossip.signMessage(msg)
+	if err := gossip.ReceiveMessage("peer2", msg); err == ErrRateLimited {
+		t.Fatal("expected a pushed message not to draw from the RPC bucket")
+	}
+}
+
 // Mock transport with Peer discovery
 type GossipMockTransport struct {
 	MockDHTTransport
@@ -440,20 +481,18 @@ func TestGossipManager_Maintenance(t *testing.T) {
 	}
 	gossip.messagesMu.RUnlock()
 
-	// Test seen filter reset (manually trigger by filling)
-	gossip.seenMu.Lock()
+	// Filling the seen filter rotates it without forgetting what was seen
 	for i := 0; i < 10001; i++ {
-		gossip.seenTimestamps[fmt.Sprintf("m%d", i)] = time.Now()
+		gossip.markSeen(fmt.Sprintf("m%d", i))
 	}
-	gossip.seenMu.Unlock()
-
 	gossip.cleanup()
 
-	gossip.seenMu.RLock()
-	if len(gossip.seenTimestamps) != 0 {
-		t.Error("Seen timestamps should have been reset")
+	if gossip.GetMetrics().SeenFilterRotations != 1 {
+		t.Error("Seen filter should have rotated once")
+	}
+	if !gossip.isDuplicate("m0") || !gossip.isDuplicate("m10000") {
+		t.Error("Seen IDs should survive a rotation")
 	}
-	gossip.seenMu.RUnlock()
 }
 
 // TestGossipManager_UpdatePeers tests the peer list update logic
@@ -0,0 +1,124 @@
+package routing
+
+import (
+	"math"
+	"time"
+)
+
+// Peer count at which the configured RoundInterval is used unscaled.
+const adaptiveIntervalReferencePeers = 8
+
+// SetLoadProvider supplies the local CPU load (0-1) used to stretch gossip
+// rounds when the node is short on compute budget.
+func (g *GossipManager) SetLoadProvider(load func() float64) {
+	g.intervalMu.Lock()
+	g.loadProvider = load
+	g.intervalMu.Unlock()
+}
+
+// CurrentRoundInterval returns the interval that will separate the next rounds.
+func (g *GossipManager) CurrentRoundInterval() time.Duration {
+	if d := time.Duration(g.roundInterval.Load()); d > 0 {
+		return d
+	}
+	return g.config.RoundInterval
+}
+
+// nextRoundInterval recomputes the round interval from current conditions
+// and publishes it to metrics.
+func (g *GossipManager) nextRoundInterval() time.Duration {
+	g.peersMu.RLock()
+	peerCount := len(g.peers)
+	g.peersMu.RUnlock()
+
+	g.metricsMu.RLock()
+	traffic := g.metrics.MessagesSent + g.metrics.MessagesReceived
+	g.metricsMu.RUnlock()
+
+	g.intervalMu.Lock()
+	idle := traffic == g.lastRoundTraffic
+	g.lastRoundTraffic = traffic
+	load := g.loadProvider
+	g.intervalMu.Unlock()
+
+	cpu := 0.0
+	if load != nil {
+		cpu = load()
+	}
+
+	interval := adaptiveRoundInterval(g.config, peerCount, len(g.messageQueue), cap(g.messageQueue), idle, cpu)
+	if g.config.AdaptiveInterval.Enabled {
+		g.metricsMu.RLock()
+		p95 := time.Duration(g.metrics.PropagationLatencyP95 * float64(time.Millisecond))
+		g.metricsMu.RUnlock()
+		if f := latencyIntervalFactor(g.config, p95); f < 1 {
+			floor := minDuration(g.config.AdaptiveInterval.MinInterval, g.config.RoundInterval)
+			interval = maxDuration(time.Duration(float64(interval)*f).Round(time.Millisecond), floor)
+		}
+	}
+	g.roundInterval.Store(int64(interval))
+
+	g.metricsMu.Lock()
+	g.metrics.RoundIntervalMs = float64(interval) / float64(time.Millisecond)
+	g.metricsMu.Unlock()
+	return interval
+}
+
+// adaptiveRoundInterval scales the base interval:
+//   - peers: larger meshes need more rounds to converge, so rounds get faster
+//     (sqrt scaling, 2x slower at 2 peers, 2x faster at 32);
+//   - queue depth: a backlog shortens rounds by up to 4x;
+//   - idle: no traffic since the last round doubles the interval;
+//   - CPU: load above 80% stretches rounds by up to 3x.
+func adaptiveRoundInterval(cfg GossipConfig, peers, queued, queueCap int, idle bool, cpu float64) time.Duration {
+	base := cfg.RoundInterval
+	adaptive := cfg.AdaptiveInterval
+	if !adaptive.Enabled {
+		return base
+	}
+
+	factor := 1.0
+	if peers > 0 {
+		factor *= clampFloat(math.Sqrt(float64(adaptiveIntervalReferencePeers)/float64(peers)), 0.5, 2.0)
+	}
+
+	if queueCap > 0 && queued > 0 {
+		backlog := math.Min(float64(queued)/float64(queueCap)*4, 1)
+		factor *= 1 - 0.75*backlog
+	} else if idle {
+		factor *= 2
+	}
+
+	if cpu > 0.8 {
+		factor *= 1 + math.Min(cpu-0.8, 0.2)*10
+	}
+
+	// Bounds never pull the interval across the configured base, so an
+	// explicitly fast or slow RoundInterval keeps its direction.
+	interval := time.Duration(float64(base) * factor).Round(time.Millisecond)
+	if lo := minDuration(adaptive.MinInterval, base); lo > 0 && interval < lo {
+		interval = lo
+	}
+	if hi := maxDuration(adaptive.MaxInterval, base); adaptive.MaxInterval > 0 && interval > hi {
+		interval = hi
+	}
+	return interval
+}
+
+func clampFloat(v, lo, hi float64) float64 {
+	return math.Max(lo, math.Min(hi, v))
+}
+
+func minDuration(a, b time.Duration) time.Duration {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxDuration(a, b time.Duration) time.Duration {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,50 @@
+package routing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdaptiveRoundInterval(t *testing.T) {
+	cfg := DefaultGossipConfig()
+	base := cfg.RoundInterval
+
+	assert.Equal(t, base, adaptiveRoundInterval(cfg, adaptiveIntervalReferencePeers, 0, 1000, false, 0),
+		"reference conditions should keep the base interval")
+
+	small := adaptiveRoundInterval(cfg, 2, 0, 1000, false, 0)
+	large := adaptiveRoundInterval(cfg, 500, 0, 1000, false, 0)
+	assert.Greater(t, small, base, "tiny meshes should gossip less often")
+	assert.Less(t, large, base, "large meshes should gossip more often")
+
+	backlog := adaptiveRoundInterval(cfg, adaptiveIntervalReferencePeers, 500, 1000, false, 0)
+	assert.Equal(t, base/4, backlog, "a backed-up queue should speed rounds up")
+
+	idle := adaptiveRoundInterval(cfg, adaptiveIntervalReferencePeers, 0, 1000, true, 0)
+	assert.Equal(t, 2*base, idle)
+
+	busy := adaptiveRoundInterval(cfg, adaptiveIntervalReferencePeers, 0, 1000, false, 1.0)
+	assert.Equal(t, 3*base, busy, "CPU pressure should stretch rounds")
+
+	bounded := adaptiveRoundInterval(cfg, 1, 0, 1000, true, 1.0)
+	assert.Equal(t, cfg.AdaptiveInterval.MaxInterval, bounded)
+	floor := adaptiveRoundInterval(cfg, 10000, 1000, 1000, false, 0)
+	assert.Equal(t, cfg.AdaptiveInterval.MinInterval, floor)
+
+	cfg.AdaptiveInterval.Enabled = false
+	assert.Equal(t, base, adaptiveRoundInterval(cfg, 500, 1000, 1000, false, 1.0))
+}
+
+func TestGossipManager_RoundIntervalInMetrics(t *testing.T) {
+	gossip, err := NewGossipManager("node1", NewMockDHTTransport(), nil)
+	require.NoError(t, err)
+	gossip.SetLoadProvider(func() float64 { return 1.0 })
+
+	interval := gossip.nextRoundInterval()
+	assert.Equal(t, interval, gossip.CurrentRoundInterval())
+	assert.Equal(t, float64(interval)/float64(time.Millisecond), gossip.GetMetrics().RoundIntervalMs)
+	assert.Greater(t, interval, gossip.config.RoundInterval)
+}
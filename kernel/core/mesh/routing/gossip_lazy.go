@@ -0,0 +1,206 @@
+package routing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+)
+
+const (
+	iHaveMethod = "gossip.ihave"
+	iWantMethod = "gossip.iwant"
+)
+
+// IHave announces a gossip message by ID without its payload. Peers that
+// lack it ask for the body with an IWANT.
+type IHave struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Size int    `json:"size"` // Estimated bytes of the full message
+}
+
+type iHaveRequest struct {
+	From     string  `json:"from"`
+	Messages []IHave `json:"messages"`
+}
+
+type lazyMessage struct {
+	msg     *common.GossipMessage
+	expires time.Time
+}
+
+// lazyPushes reports whether a message of this priority goes out as an
+// IHAVE to all but the eager targets.
+func (g *GossipManager) lazyPushes(priority int) bool {
+	lp := g.config.LazyPush
+	return lp.Enabled && priority >= lp.MinPriority
+}
+
+// splitLazyTargets keeps the first EagerPeers targets for the full message
+// and returns the rest for an IHAVE.
+func (g *GossipManager) splitLazyTargets(targets []string, priority int) (eager, lazy []string) {
+	eagerPeers := g.config.LazyPush.EagerPeers
+	if !g.lazyPushes(priority) || len(targets) <= eagerPeers {
+		return targets, nil
+	}
+	if eagerPeers < 0 {
+		eagerPeers = 0
+	}
+	return targets[:eagerPeers], targets[eagerPeers:]
+}
+
+// haveKey is the seen-filter entry for holding a message by its ID. The
+// content hash dedupes pushes; IHAVEs carry only the ID.
+func haveKey(id string) string {
+	return "have:" + id
+}
+
+func (g *GossipManager) hasMessage(id string) bool {
+	return g.isDuplicate(haveKey(id))
+}
+
+// rememberLazy keeps a message to answer IWANTs for it, and marks it held.
+func (g *GossipManager) rememberLazy(msg *common.GossipMessage) {
+	if msg.ID == "" {
+		return
+	}
+	g.markSeen(haveKey(msg.ID))
+
+	g.lazyMu.Lock()
+	defer g.lazyMu.Unlock()
+	if _, ok := g.lazyCache[msg.ID]; !ok && g.config.LazyPush.MaxCached > 0 && len(g.lazyCache) >= g.config.LazyPush.MaxCached {
+		var oldest string
+		for id, cached := range g.lazyCache {
+			if oldest == "" || cached.expires.Before(g.lazyCache[oldest].expires) {
+				oldest = id
+			}
+		}
+		delete(g.lazyCache, oldest)
+	}
+	g.lazyCache[msg.ID] = &lazyMessage{msg: msg, expires: time.Now().Add(g.config.LazyPush.CacheTTL)}
+}
+
+func (g *GossipManager) lazyMessages(ids []string) []*common.GossipMessage {
+	now := time.Now()
+	g.lazyMu.Lock()
+	defer g.lazyMu.Unlock()
+	out := make([]*common.GossipMessage, 0, len(ids))
+	for _, id := range ids {
+		if cached, ok := g.lazyCache[id]; ok && now.Before(cached.expires) {
+			out = append(out, cached.msg)
+		}
+	}
+	return out
+}
+
+func (g *GossipManager) pruneLazyCache(now time.Time) {
+	g.lazyMu.Lock()
+	for id, cached := range g.lazyCache {
+		if now.After(cached.expires) {
+			delete(g.lazyCache, id)
+		}
+	}
+	g.lazyMu.Unlock()
+}
+
+// sendIHave announces a message to one peer.
+func (g *GossipManager) sendIHave(ctx context.Context, peerID string, msg *common.GossipMessage) error {
+	req := iHaveRequest{
+		From:     g.nodeID,
+		Messages: []IHave{{ID: msg.ID, Type: msg.Type, Size: g.estimateMessageSize(msg)}},
+	}
+	var wanted int
+	if err := g.transport.SendRPC(ctx, peerID, iHaveMethod, req, &wanted); err != nil {
+		return err
+	}
+	g.metricsMu.Lock()
+	g.metrics.IHavesSent++
+	g.metricsMu.Unlock()
+	return nil
+}
+
+// handleIHave counts the announced messages this node already holds as
+// duplicate bytes saved and fetches the rest from the announcer. It returns
+// how many it will fetch.
+func (g *GossipManager) handleIHave(peerID string, req iHaveRequest) int {
+	var want []string
+	var saved uint64
+	for _, have := range req.Messages {
+		if have.ID == "" {
+			continue
+		}
+		if g.hasMessage(have.ID) {
+			saved += uint64(max(have.Size, 0))
+			continue
+		}
+		want = append(want, have.ID)
+	}
+	if saved > 0 {
+		g.metricsMu.Lock()
+		g.metrics.LazyBytesSaved += saved
+		g.metricsMu.Unlock()
+	}
+
+	want = normalizeIDBatch(want, maxMerkleSyncBatch)
+	if len(want) == 0 {
+		return 0
+	}
+	from := req.From
+	if from == "" {
+		from = peerID
+	}
+	go g.requestIWant(from, want)
+	return len(want)
+}
+
+// requestIWant fetches announced messages and takes them in as if pushed.
+func (g *GossipManager) requestIWant(peerID string, ids []string) {
+	timeout := g.config.LazyPush.FetchTimeout
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var messages []*common.GossipMessage
+	err := g.transport.SendRPC(ctx, peerID, iWantMethod, ids, &messages)
+	g.metricsMu.Lock()
+	g.metrics.IWantsSent++
+	g.metricsMu.Unlock()
+	if err != nil {
+		g.logger.Debug("IWANT failed", "peer", getShortID(peerID), "error", err)
+		return
+	}
+	for _, msg := range messages {
+		g.ReceiveMessage(msg.Sender, msg)
+	}
+}
+
+func (g *GossipManager) registerLazyPushHandlers() {
+	g.registerRPC(iHaveMethod, func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error) {
+		var req iHaveRequest
+		if err := json.Unmarshal(args, &req); err != nil {
+			return nil, err
+		}
+		return g.handleIHave(peerID, req), nil
+	})
+
+	g.registerRPC(iWantMethod, func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error) {
+		var ids []string
+		if err := json.Unmarshal(args, &ids); err != nil {
+			return nil, err
+		}
+		ids = normalizeIDBatch(ids, maxMerkleSyncBatch)
+		if len(ids) == 0 {
+			return nil, errors.New("no message IDs")
+		}
+		messages := g.lazyMessages(ids)
+		g.metricsMu.Lock()
+		g.metrics.IWantsServed += uint64(len(messages))
+		g.metricsMu.Unlock()
+		return messages, nil
+	})
+}
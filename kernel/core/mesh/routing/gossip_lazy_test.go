@@ -0,0 +1,70 @@
+package routing
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLazyPush_IHaveFetchesOnlyWhatIsMissing(t *testing.T) {
+	aliceT, bobT, carolT := NewMockDHTTransport(), NewMockDHTTransport(), NewMockDHTTransport()
+	aliceT.peers["bob"] = bobT
+	aliceT.peers["carol"] = carolT
+	bobT.peers["alice"] = aliceT
+
+	alice, err := NewGossipManager("alice", aliceT, nil)
+	require.NoError(t, err)
+	bob, err := NewGossipManager("bob", bobT, nil)
+	require.NoError(t, err)
+	carol, err := NewGossipManager("carol", carolT, nil)
+	require.NoError(t, err)
+	alice.config.LazyPush.EagerPeers = 0
+
+	var bobGot, carolGot atomic.Int32
+	bob.RegisterHandler("metrics.report", func(*common.GossipMessage) error { bobGot.Add(1); return nil })
+	carol.RegisterHandler("metrics.report", func(*common.GossipMessage) error { carolGot.Add(1); return nil })
+
+	msg := alice.newMessage("metrics.report", map[string]interface{}{"cpu": 0.5})
+	alice.signMessageIfKeyed(msg)
+	delivered := *msg
+	require.NoError(t, carol.ReceiveMessage("alice", &delivered))
+	require.Equal(t, int32(1), carolGot.Load())
+
+	alice.sendMessageToPeers(QueuedGossipMessage{
+		Message:  msg,
+		Targets:  []string{"bob", "carol"},
+		Priority: alice.getMessagePriority(msg.Type),
+	})
+
+	// Bob lacked the message and fetched it; Carol had it and did not
+	require.Eventually(t, func() bool { return bobGot.Load() == 1 }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, uint64(2), alice.GetMetrics().IHavesSent)
+	assert.Equal(t, uint64(1), alice.GetMetrics().IWantsServed)
+	assert.Equal(t, uint64(1), bob.GetMetrics().IWantsSent)
+	assert.Zero(t, carol.GetMetrics().IWantsSent)
+	assert.Equal(t, uint64(alice.estimateMessageSize(msg)), carol.GetMetrics().LazyBytesSaved)
+	assert.Equal(t, int32(1), carolGot.Load())
+}
+
+func TestLazyPush_UrgentMessagesStayEager(t *testing.T) {
+	g, err := NewGossipManager("alice", NewMockDHTTransport(), nil)
+	require.NoError(t, err)
+	targets := []string{"a", "b", "c"}
+
+	eager, lazy := g.splitLazyTargets(targets, g.getMessagePriority("sdp.relay"))
+	assert.Equal(t, targets, eager)
+	assert.Empty(t, lazy)
+
+	eager, lazy = g.splitLazyTargets(targets, g.getMessagePriority("metrics.report"))
+	assert.Equal(t, []string{"a"}, eager)
+	assert.Equal(t, []string{"b", "c"}, lazy)
+
+	g.config.LazyPush.Enabled = false
+	eager, lazy = g.splitLazyTargets(targets, g.getMessagePriority("metrics.report"))
+	assert.Equal(t, targets, eager)
+	assert.Empty(t, lazy)
+}
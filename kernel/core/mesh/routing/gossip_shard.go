@@ -0,0 +1,193 @@
+package routing
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"math/rand"
+	"slices"
+	"sort"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+)
+
+// ShardConfig splits gossip into per-sector overlays. A sharded node
+// gossips with the peers of its own sector, plus a few bridge peers in
+// other sectors that carry mesh-wide messages across, so fanout stays
+// bounded however large the mesh grows.
+type ShardConfig struct {
+	Enabled     bool     `json:"enabled"`
+	BridgePeers int      `json:"bridge_peers"` // Peers in other sectors every mesh-wide message also goes to
+	Sectors     []uint32 `json:"sectors"`      // Sectors this node gossips in besides its own
+	LocalTypes  []string `json:"local_types"`  // Message types that stay in the sector they were published in
+}
+
+// DefaultShardConfig keeps chunk announcements sector-local; peers outside
+// the sector find providers with a DHT lookup instead.
+func DefaultShardConfig() ShardConfig {
+	return ShardConfig{
+		Enabled:     true,
+		BridgePeers: 2,
+		LocalTypes:  []string{"chunk_announce"},
+	}
+}
+
+// SetSharding replaces the shard configuration.
+func (g *GossipManager) SetSharding(config ShardConfig) {
+	if config.BridgePeers < 0 {
+		config.BridgePeers = 0
+	}
+	g.peersMu.Lock()
+	g.config.Sharding = config
+	g.peersMu.Unlock()
+}
+
+// SetSector moves this node's shard to sector.
+func (g *GossipManager) SetSector(sector uint32) {
+	g.peersMu.Lock()
+	g.sector = sector
+	g.peersMu.Unlock()
+}
+
+// SetPeerSector records the sector peerID advertises. Until one is known a
+// peer counts as a member of every shard.
+func (g *GossipManager) SetPeerSector(peerID string, sector uint32) {
+	g.peersMu.Lock()
+	g.peerSectors[peerID] = sector
+	g.peersMu.Unlock()
+}
+
+// ShardPeers splits the gossip peers into members of this node's shard and
+// the bridge peers mesh-wide messages cross sectors through.
+func (g *GossipManager) ShardPeers() (members, bridges []string) {
+	g.peersMu.RLock()
+	defer g.peersMu.RUnlock()
+	if !g.config.Sharding.Enabled {
+		return slices.Clone(g.peers), nil
+	}
+	members, others := g.splitShardLocked(nil)
+	return members, g.bridgePeersLocked(others)
+}
+
+// messageScope returns the sector a new message of msgType stays in, or
+// nil for a mesh-wide message.
+func (g *GossipManager) messageScope(msgType string) *uint32 {
+	g.peersMu.RLock()
+	defer g.peersMu.RUnlock()
+	if !g.config.Sharding.Enabled || !slices.Contains(g.config.Sharding.LocalTypes, msgType) {
+		return nil
+	}
+	sector := g.sector
+	return &sector
+}
+
+// forwardsScope reports whether this node relays messages scoped to
+// sector. Nodes outside a sector handle what reaches them but do not
+// spread it.
+func (g *GossipManager) forwardsScope(sector *uint32) bool {
+	if sector == nil {
+		return true
+	}
+	g.peersMu.RLock()
+	defer g.peersMu.RUnlock()
+	return g.inShardLocked(*sector)
+}
+
+// gossipPeers picks up to count peers for a message scoped to sector, or
+// mesh-wide when sector is nil. Sharded, a mesh-wide message goes to count
+// shard members plus the bridge peers; a scoped one only to members of
+// its sector.
+func (g *GossipManager) gossipPeers(count int, sector *uint32) []string {
+	g.peersMu.RLock()
+	defer g.peersMu.RUnlock()
+	if !g.config.Sharding.Enabled {
+		return pickPeers(g.peers, count)
+	}
+	members, others := g.splitShardLocked(sector)
+	selected := pickPeers(members, count)
+	if sector == nil {
+		selected = append(selected, g.bridgePeersLocked(others)...)
+	}
+	return selected
+}
+
+// peerInScope reports whether peerID may receive a message scoped to sector.
+func (g *GossipManager) peerInScope(peerID string, sector *uint32) bool {
+	if sector == nil {
+		return true
+	}
+	g.peersMu.RLock()
+	defer g.peersMu.RUnlock()
+	peerSector, known := g.peerSectors[peerID]
+	return !known || peerSector == *sector
+}
+
+// splitShardLocked separates the peers a message scoped to sector (or
+// this node's shard, when nil) reaches from the rest.
+func (g *GossipManager) splitShardLocked(sector *uint32) (members, others []string) {
+	for _, peerID := range g.peers {
+		peerSector, known := g.peerSectors[peerID]
+		switch {
+		case !known:
+			members = append(members, peerID)
+		case sector != nil:
+			if peerSector == *sector {
+				members = append(members, peerID)
+			}
+		case g.inShardLocked(peerSector):
+			members = append(members, peerID)
+		default:
+			others = append(others, peerID)
+		}
+	}
+	return members, others
+}
+
+func (g *GossipManager) inShardLocked(sector uint32) bool {
+	return sector == g.sector || slices.Contains(g.config.Sharding.Sectors, sector)
+}
+
+// bridgePeersLocked picks the bridge peers out of others. The choice is a
+// stable hash of both IDs, so a node keeps its bridges while they stay
+// connected and the load of bridging spreads across each sector.
+func (g *GossipManager) bridgePeersLocked(others []string) []string {
+	n := g.config.Sharding.BridgePeers
+	if n <= 0 || len(others) == 0 {
+		return nil
+	}
+	type ranked struct {
+		peerID string
+		rank   [sha256.Size]byte
+	}
+	candidates := make([]ranked, len(others))
+	for i, peerID := range others {
+		candidates[i] = ranked{peerID, sha256.Sum256([]byte(g.nodeID + "|" + peerID))}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return bytes.Compare(candidates[i].rank[:], candidates[j].rank[:]) < 0
+	})
+
+	bridges := make([]string, 0, min(n, len(candidates)))
+	for _, c := range candidates[:min(n, len(candidates))] {
+		bridges = append(bridges, c.peerID)
+	}
+	return bridges
+}
+
+// pickPeers returns up to count of peers in random order.
+func pickPeers(peers []string, count int) []string {
+	count = max(count, 0)
+	if len(peers) <= count {
+		return slices.Clone(peers)
+	}
+	selected := make([]string, 0, count)
+	for _, i := range rand.Perm(len(peers))[:count] {
+		selected = append(selected, peers[i])
+	}
+	return selected
+}
+
+// scopeMessage confines a new message to this node's sector if its type
+// is sector-local. Call it before signing: the scope is signed.
+func (g *GossipManager) scopeMessage(msg *common.GossipMessage) {
+	msg.Sector = g.messageScope(msg.Type)
+}
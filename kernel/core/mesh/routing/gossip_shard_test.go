@@ -0,0 +1,82 @@
+package routing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newShardTestGossip(t *testing.T) *GossipManager {
+	g, err := NewGossipManager("alice", NewMockDHTTransport(), nil)
+	require.NoError(t, err)
+	g.SetSector(1)
+	g.UpdatePeers([]string{"a", "b", "c", "d", "e", "f"})
+	for peerID, sector := range map[string]uint32{"a": 1, "b": 1, "c": 2, "d": 2, "e": 3} {
+		g.SetPeerSector(peerID, sector)
+	}
+	return g
+}
+
+func TestGossipShard_MeshWideMessagesCrossThroughBridges(t *testing.T) {
+	g := newShardTestGossip(t)
+
+	// Peers with no known sector count as members until they advertise one
+	members, bridges := g.ShardPeers()
+	assert.ElementsMatch(t, []string{"a", "b", "f"}, members)
+	require.Len(t, bridges, 2)
+	for _, peerID := range bridges {
+		assert.Contains(t, []string{"c", "d", "e"}, peerID)
+	}
+	_, again := g.ShardPeers()
+	assert.Equal(t, bridges, again, "bridges are stable")
+
+	assert.ElementsMatch(t, append([]string{"a", "b", "f"}, bridges...), g.gossipPeers(10, nil))
+	assert.ElementsMatch(t, []string{"a", "b", "f"}, g.getRandomPeers(10))
+	assert.Len(t, g.gossipPeers(1, nil), 3)
+
+	// Joining another sector makes its peers members
+	config := DefaultShardConfig()
+	config.Sectors = []uint32{2}
+	g.SetSharding(config)
+	members, bridges = g.ShardPeers()
+	assert.ElementsMatch(t, []string{"a", "b", "c", "d", "f"}, members)
+	assert.Equal(t, []string{"e"}, bridges)
+
+	g.SetSharding(ShardConfig{})
+	assert.Len(t, g.gossipPeers(10, nil), 6)
+}
+
+func TestGossipShard_ChunkAnnouncementsStayInSector(t *testing.T) {
+	g := newShardTestGossip(t)
+
+	msg := g.newMessage("chunk_announce", map[string]interface{}{"chunk_hash": "abc"})
+	require.NotNil(t, msg.Sector)
+	assert.Equal(t, uint32(1), *msg.Sector)
+	assert.Nil(t, g.newMessage("mesh_metrics", nil).Sector)
+
+	assert.ElementsMatch(t, []string{"a", "b", "f"}, g.gossipPeers(10, msg.Sector))
+	assert.True(t, g.peerInScope("f", msg.Sector))
+	assert.False(t, g.peerInScope("c", msg.Sector))
+
+	// Only members of the sector spread its messages
+	other := uint32(2)
+	assert.True(t, g.forwardsScope(msg.Sector))
+	assert.False(t, g.forwardsScope(&other))
+	assert.True(t, g.forwardsScope(nil))
+
+	// The scope is signed, so a relay cannot widen it
+	g.signMessageIfKeyed(msg)
+	require.NoError(t, g.verifyMessage(msg))
+	msg.Sector = nil
+	assert.Error(t, g.verifyMessage(msg))
+}
+
+func TestGossipShard_RemovePeerForgetsSector(t *testing.T) {
+	g := newShardTestGossip(t)
+	g.RemovePeer("c")
+	g.AddPeer("c")
+
+	members, _ := g.ShardPeers()
+	assert.Contains(t, members, "c")
+}
@@ -13,6 +13,51 @@ func BenchmarkMerkleTree_AddMessage(b *testing.B) {
 	}
 }
 
+// populatedMerkleTree holds n messages, so inserts are measured against a
+// tree of realistic size rather than an empty one.
+func populatedMerkleTree(n int) *MerkleTree {
+	mt := NewMerkleTree()
+	for i := 0; i < n; i++ {
+		mt.AddMessage(fmt.Sprintf("seed_%d", i))
+	}
+	return mt
+}
+
+// BenchmarkMerkleTree_AddMessageIncremental rehashes one bucket and its path.
+func BenchmarkMerkleTree_AddMessageIncremental(b *testing.B) {
+	mt := populatedMerkleTree(10000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		mt.AddMessage(fmt.Sprintf("msg_%d", i))
+	}
+}
+
+// BenchmarkMerkleTree_AddMessageFullRebuild is the per-insert cost of the
+// old approach: insert, then rehash every bucket and layer.
+func BenchmarkMerkleTree_AddMessageFullRebuild(b *testing.B) {
+	mt := populatedMerkleTree(10000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		mt.insert(fmt.Sprintf("msg_%d", i))
+		mt.rebuild()
+	}
+}
+
+// BenchmarkMerkleTree_AddMessagesBatch applies messages 64 per recompute;
+// ns/op is per message, comparable with the single-insert benchmarks.
+func BenchmarkMerkleTree_AddMessagesBatch(b *testing.B) {
+	mt := populatedMerkleTree(10000)
+	batch := make([]string, 0, 64)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		batch = append(batch, fmt.Sprintf("msg_%d", i))
+		if len(batch) == cap(batch) || i == b.N-1 {
+			mt.AddMessages(batch)
+			batch = batch[:0]
+		}
+	}
+}
+
 func BenchmarkMerkleTree_Rebuild(b *testing.B) {
 	mt := NewMerkleTree()
 	for i := 0; i < 1000; i++ {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"io"
 	"testing"
 	"time"
@@ -194,3 +195,34 @@ func TestMerkleSync(t *testing.T) {
 	// We don't strictly assert calls here to avoid fragility,
 	// but we verify the handling didn't error.
 }
+
+func TestMerkleTree_IncrementalUpdatesMatchFullRebuild(t *testing.T) {
+	incremental := NewMerkleTree()
+	batched := NewMerkleTree()
+	var ids []string
+	for i := 0; i < 2000; i++ {
+		id := fmt.Sprintf("msg_%d", i)
+		ids = append(ids, id)
+		incremental.AddMessage(id)
+	}
+	incremental.AddMessage("msg_7") // Duplicates change nothing
+
+	assert.Equal(t, 2000, batched.AddMessages(append(ids, "msg_7")))
+	assert.Equal(t, 0, batched.AddMessages(ids[:10]))
+
+	full := NewMerkleTree()
+	full.Buckets = incremental.Buckets
+	full.rebuild()
+
+	assert.Equal(t, full.Root, incremental.Root)
+	assert.Equal(t, full.Layers, incremental.Layers)
+	assert.Equal(t, full.Root, batched.Root)
+	assert.Equal(t, full.Layers, batched.Layers)
+
+	// A root handed out earlier keeps its value
+	before := append([]byte(nil), incremental.Root...)
+	held := incremental.Root
+	incremental.AddMessage("msg_new")
+	assert.Equal(t, before, held)
+	assert.NotEqual(t, held, incremental.Root)
+}
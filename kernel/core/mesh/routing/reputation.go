@@ -26,6 +26,7 @@ const (
 	PenaltyPoRFailure
 	PenaltyMaliciousBehavior
 	PenaltyCongestion
+	PenaltyResourceExceeded // Sent a job that ran past its sandbox limits
 )
 
 // ReputationScore matches Rust's PeerReputation and extends it.
@@ -88,20 +89,43 @@ func NewReputationManager(decayHalflife time.Duration, store ReputationStore, lo
 	return rm
 }
 
+// SetStore attaches persistence and merges in any saved scores. Peers
+// already scored this session keep their live score.
+func (r *ReputationManager) SetStore(store ReputationStore) {
+	var loaded map[string]ReputationScore
+	if store != nil {
+		loaded, _ = store.LoadScores()
+	}
+
+	r.scoresMu.Lock()
+	r.store = store
+	for peerID, score := range loaded {
+		if _, live := r.scores[peerID]; !live {
+			r.scores[peerID] = score
+		}
+	}
+	r.scoresMu.Unlock()
+
+	if len(loaded) > 0 {
+		r.logger.Info("restored reputation scores", "count", len(loaded))
+	}
+}
+
 // Snapshot persists current scores.
 func (r *ReputationManager) Snapshot() error {
-	if r.store == nil {
+	r.scoresMu.RLock()
+	store := r.store
+	if store == nil {
+		r.scoresMu.RUnlock()
 		return nil
 	}
-
-	r.scoresMu.RLock()
 	snapshot := make(map[string]ReputationScore, len(r.scores))
 	for k, v := range r.scores {
 		snapshot[k] = v
 	}
 	r.scoresMu.RUnlock()
 
-	return r.store.SaveScores(snapshot)
+	return store.SaveScores(snapshot)
 }
 
 // Report ingests a new interaction result.
@@ -192,6 +216,8 @@ func (r *ReputationManager) ReportPenalty(peerID string, reason PenaltyReason) {
 			isCritical = true
 		case PenaltyCongestion:
 			penalty = 0.01
+		case PenaltyResourceExceeded:
+			penalty = 0.05
 		default:
 			penalty = 0.05
 		}
@@ -189,6 +189,30 @@ func TestReputation_Persistence(t *testing.T) {
 	}
 }
 
+func TestReputation_SetStoreKeepsLiveScores(t *testing.T) {
+	store := &MockReputationStore{}
+	saved := NewReputationManager(24*time.Hour, store, nil)
+	for i := 0; i < 4; i++ {
+		saved.Report("old-peer", true, 10.0)
+	}
+	saved.Report("live-peer", false, 0)
+	if err := saved.Snapshot(); err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+
+	rm := NewReputationManager(24*time.Hour, nil, nil)
+	rm.Report("live-peer", true, 5.0)
+	live, _ := rm.GetTrustScore("live-peer")
+
+	rm.SetStore(store)
+	if _, confidence := rm.GetTrustScore("old-peer"); confidence == 0 {
+		t.Error("expected saved peer to be restored")
+	}
+	if score, _ := rm.GetTrustScore("live-peer"); math.Abs(score-live) > 0.0001 {
+		t.Errorf("restored score %f replaced live score %f", score, live)
+	}
+}
+
 // Benchmarks
 
 func BenchmarkReputation_Report(b *testing.B) {
@@ -0,0 +1,103 @@
+package routing
+
+import "github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+
+// Merkle and gossip sync RPCs, and the Kademlia RPCs served in DHT server
+// mode. Hashes travel as base64 strings inside JSON
+// arrays; a bare []byte is also base64 under encoding/json.
+func init() {
+	for _, spec := range []common.RPCMethodSpec{
+		{
+			Name:        "merkle.root",
+			Description: "Return the root hash of the peer's gossip Merkle tree.",
+			Request:     nil,
+			Response:    []byte{},
+		},
+		{
+			Name:        "merkle.hashes",
+			Description: "Return hashes of every message the peer currently holds.",
+			Request:     nil,
+			Response:    []string{},
+		},
+		{
+			Name:        "merkle.children",
+			Description: "Return the child hashes of a Merkle node identified by its base64 hash.",
+			Request:     "",
+			Response:    []string{},
+		},
+		{
+			Name:        "merkle.bucket_ids",
+			Description: "Return the message IDs in the leaf bucket identified by its base64 hash.",
+			Request:     "",
+			Response:    []string{},
+		},
+		{
+			Name:        "gossip.pull",
+			Description: "Return the IDs of recent gossip messages for pull-based repair.",
+			Request:     nil,
+			Response:    []string{},
+		},
+		{
+			Name:        "gossip.messages",
+			Description: "Return gossip messages by ID; unknown IDs are omitted.",
+			Request:     []string{},
+			Response:    []*common.GossipMessage{},
+		},
+		{
+			Name:        "gossip.by_hash",
+			Description: "Return gossip messages by content hash; unknown hashes are omitted.",
+			Request:     []string{},
+			Response:    []*common.GossipMessage{},
+		},
+		{
+			Name:        "gossip.payload",
+			Description: "Return the body of a digest-only gossip message by its SHA-256 hex digest.",
+			Request:     payloadFetchRequest{},
+			Response:    payloadFetchResponse{},
+		},
+		{
+			Name:        "gossip.ihave",
+			Description: "Announce gossip messages by ID; returns how many the receiver lacks and will fetch with gossip.iwant.",
+			Request:     iHaveRequest{},
+			Response:    0,
+		},
+		{
+			Name:        "gossip.iwant",
+			Description: "Return lazily pushed gossip messages by ID; unknown or expired IDs are omitted.",
+			Request:     []string{},
+			Response:    []*common.GossipMessage{},
+		},
+		{
+			Name:        "find_node",
+			Description: "Return the closest DHT servers to target_id. Refused by nodes in DHT client mode.",
+			Request:     findNodeRequest{},
+			Response:    []common.PeerInfo{},
+		},
+		{
+			Name:        "find_value",
+			Description: "Return providers of key, or the closest DHT servers when none are known. Refused in client mode.",
+			Request:     findValueRequest{},
+			Response:    findValueResponse{},
+		},
+		{
+			Name:        "store",
+			Description: "Host a provider record: value is the ID of a peer holding key. Refused in client mode.",
+			Request:     storeRequest{},
+			Response:    nil,
+		},
+		{
+			Name:        "put_record",
+			Description: "Host a signed mutable record; refused if it does not supersede the held one. Refused in client mode.",
+			Request:     putRecordRequest{},
+			Response:    nil,
+		},
+		{
+			Name:        "get_record",
+			Description: "Return the mutable record held under key, if any. Refused in client mode.",
+			Request:     getRecordRequest{},
+			Response:    getRecordResponse{},
+		},
+	} {
+		common.MustRegisterRPCMethod(spec)
+	}
+}
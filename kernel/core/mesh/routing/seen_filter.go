@@ -0,0 +1,108 @@
+package routing
+
+import (
+	"math"
+	"time"
+
+	"github.com/bits-and-blooms/bloom/v3"
+)
+
+// seenFilter remembers message IDs in two generations of bloom filters.
+// IDs go into the current generation and lookups check both. When the
+// current generation has been filling for the rotation period, or holds
+// its quota of IDs, the previous one is dropped and the current takes its
+// place. Every ID is remembered for at least one period and forgetting is
+// spread out, instead of a reset that drops everything at once.
+type seenFilter struct {
+	current  *bloom.BloomFilter
+	previous *bloom.BloomFilter
+	added    uint // IDs added to the current generation
+	started  time.Time
+
+	period   time.Duration
+	quota    int
+	elements uint
+	fpRate   float64
+
+	rotations uint64
+}
+
+func newSeenFilter(elements uint, fpRate float64, period time.Duration, quota int) *seenFilter {
+	return &seenFilter{
+		current:  bloom.NewWithEstimates(elements, fpRate),
+		started:  time.Now(),
+		period:   period,
+		quota:    quota,
+		elements: elements,
+		fpRate:   fpRate,
+	}
+}
+
+// test reports whether id was seen, and whether only the previous
+// generation still remembered it.
+func (f *seenFilter) test(id string) (seen, fromPrevious bool) {
+	if f.current.TestString(id) {
+		return true, false
+	}
+	if f.previous != nil && f.previous.TestString(id) {
+		return true, true
+	}
+	return false, false
+}
+
+func (f *seenFilter) add(id string, now time.Time) {
+	f.rotateIfDue(now)
+	f.current.AddString(id)
+	f.added++
+}
+
+// rotateIfDue starts a new generation once the current one has aged out or
+// filled up.
+func (f *seenFilter) rotateIfDue(now time.Time) bool {
+	aged := f.period > 0 && now.Sub(f.started) >= f.period
+	full := f.quota > 0 && f.added >= uint(f.quota)
+	if !aged && !full {
+		return false
+	}
+	f.rotate(now)
+	return true
+}
+
+func (f *seenFilter) rotate(now time.Time) {
+	f.previous = f.current
+	f.current = bloom.NewWithEstimates(f.elements, f.fpRate)
+	f.added = 0
+	f.started = now
+	f.rotations++
+}
+
+// resize rotates into a generation of the new size. The old generation
+// stays readable as the previous one, so nothing is forgotten early.
+func (f *seenFilter) resize(elements uint, quota int, now time.Time) {
+	if elements > 0 {
+		f.elements = elements
+	}
+	if quota > 0 {
+		f.quota = quota
+	}
+	f.rotate(now)
+}
+
+// falsePositiveRate estimates the chance an unseen ID tests as seen, from
+// each generation's fill.
+func (f *seenFilter) falsePositiveRate() float64 {
+	miss := 1 - filterFPRate(f.current)
+	if f.previous != nil {
+		miss *= 1 - filterFPRate(f.previous)
+	}
+	return 1 - miss
+}
+
+func filterFPRate(b *bloom.BloomFilter) float64 {
+	m, k := float64(b.Cap()), float64(b.K())
+	if m == 0 {
+		return 0
+	}
+	n := float64(b.ApproximatedSize())
+	return math.Pow(1-math.Exp(-k*n/m), k)
+}
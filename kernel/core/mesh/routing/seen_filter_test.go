@@ -0,0 +1,58 @@
+package routing
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestSeenFilter_ForgetsOneGenerationAtATime(t *testing.T) {
+	f := newSeenFilter(1000, 0.01, time.Minute, 0)
+	start := f.started
+	f.add("old", start)
+
+	// The first rotation keeps "old" readable in the previous generation
+	f.add("newer", start.Add(time.Minute))
+	if seen, late := f.test("old"); !seen || !late {
+		t.Fatalf("expected old caught by the previous generation, got seen=%v late=%v", seen, late)
+	}
+	if seen, late := f.test("newer"); !seen || late {
+		t.Fatalf("expected newer in the current generation, got seen=%v late=%v", seen, late)
+	}
+
+	// The second drops it, but not what came after
+	if !f.rotateIfDue(start.Add(2 * time.Minute)) {
+		t.Fatal("expected the aged generation to rotate")
+	}
+	if seen, _ := f.test("old"); seen {
+		t.Fatal("expected old forgotten after two periods")
+	}
+	if seen, _ := f.test("newer"); !seen {
+		t.Fatal("expected newer still remembered")
+	}
+	if f.rotations != 2 {
+		t.Fatalf("expected 2 rotations, got %d", f.rotations)
+	}
+}
+
+func TestSeenFilter_QuotaRotationAndFalsePositiveEstimate(t *testing.T) {
+	f := newSeenFilter(1000, 0.01, time.Hour, 100)
+	if f.falsePositiveRate() != 0 {
+		t.Fatalf("expected an empty filter to estimate 0, got %v", f.falsePositiveRate())
+	}
+	now := time.Now()
+	for i := 0; i < 150; i++ {
+		f.add(fmt.Sprintf("m%d", i), now)
+	}
+	if f.rotations != 1 || f.added != 50 {
+		t.Fatalf("expected one quota rotation, got %d rotations and %d in the current generation", f.rotations, f.added)
+	}
+	if rate := f.falsePositiveRate(); rate <= 0 || rate > 0.01 {
+		t.Fatalf("expected a small positive estimate, got %v", rate)
+	}
+
+	f.resize(2000, 200, now)
+	if seen, late := f.test("m120"); !seen || !late || f.current.Cap() <= 1000*9 {
+		t.Fatalf("expected resize to keep the old generation readable and grow the new one")
+	}
+}
@@ -0,0 +1,386 @@
+package mesh
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+	"github.com/nmxmxh/inos_v1/kernel/utils/tracing"
+)
+
+const (
+	capabilityVersion       = "inos-cap-v1"
+	capabilityRequestMethod = "mesh.RequestCapability"
+
+	// capabilityRefreshMargin renews a cached capability before it lapses
+	// mid-call.
+	capabilityRefreshMargin = 30 * time.Second
+
+	// capabilityRetryBackoff is how long a peer's refusal to issue us a
+	// capability stands before we ask again.
+	capabilityRetryBackoff = time.Minute
+)
+
+var (
+	ErrCapabilityRequired = errors.New("rpc: capability required")
+	ErrCapabilityInvalid  = errors.New("rpc: invalid capability")
+	ErrRPCDenied          = errors.New("rpc: method denied by policy")
+)
+
+// RPCPolicyMode decides who may call a method.
+type RPCPolicyMode string
+
+const (
+	// RPCPolicyOpen lets any connected peer call the method.
+	RPCPolicyOpen RPCPolicyMode = "open"
+	// RPCPolicyCapability requires a capability issued by this node.
+	RPCPolicyCapability RPCPolicyMode = "capability"
+	// RPCPolicyDeny refuses the method to every peer.
+	RPCPolicyDeny RPCPolicyMode = "deny"
+)
+
+// RPCMethodPolicy is the authorization policy for one RPC method. A
+// capability is issued when the caller meets MinReputation or, if
+// MinBalance is set, holds that many credits on our ledger.
+type RPCMethodPolicy struct {
+	Mode          RPCPolicyMode `json:"mode"`
+	MinReputation float64       `json:"min_reputation"`
+	MinBalance    int64         `json:"min_balance"`
+}
+
+// RPCCapability is a token this node signs to let one peer call a set of
+// privileged methods until it expires.
+type RPCCapability struct {
+	Version   string   `json:"version"`
+	Issuer    string   `json:"issuer"`
+	Subject   string   `json:"subject"`
+	Methods   []string `json:"methods"`
+	IssuedAt  int64    `json:"issued_at"`  // Unix milliseconds
+	ExpiresAt int64    `json:"expires_at"` // Unix milliseconds
+	Signature string   `json:"signature"`
+}
+
+// CapabilityRequest asks a peer for a capability covering Methods.
+type CapabilityRequest struct {
+	Methods []string `json:"methods"`
+}
+
+func capabilityPayload(c *RPCCapability) []byte {
+	h := sha256.New()
+	parts := []string{c.Version, c.Issuer, c.Subject, strings.Join(c.Methods, ","),
+		strconv.FormatInt(c.IssuedAt, 10), strconv.FormatInt(c.ExpiresAt, 10)}
+	for _, part := range parts {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return h.Sum(nil)
+}
+
+// Allows reports whether the capability covers method at time now.
+func (c *RPCCapability) Allows(method string, now time.Time) bool {
+	if now.UnixMilli() >= c.ExpiresAt {
+		return false
+	}
+	for _, m := range c.Methods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// allowsAll reports whether c covers every method at t; a nil c covers none.
+func (c *RPCCapability) allowsAll(methods []string, t time.Time) bool {
+	if c == nil {
+		return false
+	}
+	for _, method := range methods {
+		if !c.Allows(method, t) {
+			return false
+		}
+	}
+	return true
+}
+
+func encodeCapability(c *RPCCapability) (string, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+func decodeCapability(token string) (*RPCCapability, error) {
+	data, err := base64.StdEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrCapabilityInvalid
+	}
+	var c RPCCapability
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, ErrCapabilityInvalid
+	}
+	return &c, nil
+}
+
+// rpcPolicy returns the configured policy for method.
+func (m *MeshCoordinator) rpcPolicy(method string) RPCMethodPolicy {
+	auth := m.config.Authorization
+	if !auth.Enabled {
+		return RPCMethodPolicy{Mode: RPCPolicyOpen}
+	}
+	// Requesting a capability must stay reachable or nothing could be issued.
+	if method == capabilityRequestMethod {
+		return RPCMethodPolicy{Mode: RPCPolicyOpen}
+	}
+	if policy, ok := auth.Policies[method]; ok {
+		return policy
+	}
+	mode := auth.DefaultMode
+	if mode == "" {
+		mode = RPCPolicyOpen
+	}
+	return RPCMethodPolicy{Mode: mode}
+}
+
+// registerRPC registers a handler behind the method's authorization policy.
+func (m *MeshCoordinator) registerRPC(method string, handler func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error)) {
+	m.transport.RegisterRPCHandler(method, func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error) {
+		if err := m.authorizeRPC(ctx, peerID, method); err != nil {
+			m.rpcLogger(ctx).Debug("rpc refused", "method", method, "peer", getShortID(peerID), "error", err)
+			return nil, err
+		}
+		if md, _ := common.RPCMetadataFromContext(ctx); md.TraceParent == "" {
+			return handler(ctx, peerID, args)
+		}
+
+		ctx, span := tracing.Start(ctx, method, tracing.SpanKindServer)
+		span.SetAttribute("rpc.method", method)
+		span.SetAttribute("peer", getShortID(peerID))
+		result, err := handler(ctx, peerID, args)
+		span.SetError(err)
+		span.End()
+		return result, err
+	})
+}
+
+// authorizeRPC checks a caller against the policy for method.
+func (m *MeshCoordinator) authorizeRPC(ctx context.Context, peerID, method string) error {
+	return m.authorizeCapability(common.CapabilityFromContext(ctx), peerID, method)
+}
+
+// authorizeCapability checks a capability token against the policy for
+// method. Pulled work carries its token in the job rather than the context.
+func (m *MeshCoordinator) authorizeCapability(token, peerID, method string) error {
+	switch m.rpcPolicy(method).Mode {
+	case RPCPolicyOpen:
+		return nil
+	case RPCPolicyDeny:
+		return ErrRPCDenied
+	}
+
+	if token == "" {
+		return ErrCapabilityRequired
+	}
+	capability, err := decodeCapability(token)
+	if err != nil {
+		return err
+	}
+	return m.verifyCapability(capability, peerID, method)
+}
+
+// verifyCapability checks that this node issued c to peerID for method and
+// that it has not expired or been revoked.
+func (m *MeshCoordinator) verifyCapability(c *RPCCapability, peerID, method string) error {
+	if c.Version != capabilityVersion || c.Issuer != m.nodeID || c.Subject != peerID {
+		return ErrCapabilityInvalid
+	}
+	if !c.Allows(method, time.Now()) {
+		return fmt.Errorf("%w: not valid for %s", ErrCapabilityInvalid, method)
+	}
+	sig, err := base64.StdEncoding.DecodeString(c.Signature)
+	if err != nil || m.gossip == nil || !ed25519.Verify(m.gossip.PublicKey(), capabilityPayload(c), sig) {
+		return ErrCapabilityInvalid
+	}
+
+	m.capabilityMu.RLock()
+	revokedAt, revoked := m.capabilityRevocations[peerID]
+	m.capabilityMu.RUnlock()
+	if revoked && c.IssuedAt <= revokedAt.UnixMilli() {
+		return fmt.Errorf("%w: revoked", ErrCapabilityInvalid)
+	}
+	return nil
+}
+
+// IssueCapability signs a capability letting peerID call methods. Each
+// method must require a capability and the peer must pass its policy.
+func (m *MeshCoordinator) IssueCapability(peerID string, methods []string) (*RPCCapability, error) {
+	if m.gossip == nil {
+		return nil, errors.New("gossip manager unavailable for signing capabilities")
+	}
+	if len(methods) == 0 {
+		return nil, errors.New("no methods requested")
+	}
+	if m.isPeerQuarantined(peerID) {
+		return nil, fmt.Errorf("%w: peer is quarantined", ErrRPCDenied)
+	}
+
+	granted := make([]string, 0, len(methods))
+	for _, method := range methods {
+		policy := m.rpcPolicy(method)
+		switch policy.Mode {
+		case RPCPolicyOpen:
+			continue
+		case RPCPolicyDeny:
+			return nil, fmt.Errorf("%w: %s", ErrRPCDenied, method)
+		}
+		if !m.meetsCapabilityPolicy(peerID, policy) {
+			return nil, fmt.Errorf("%w: %s requires reputation %.2f or balance %d", ErrRPCDenied, method, policy.MinReputation, policy.MinBalance)
+		}
+		granted = append(granted, method)
+	}
+	if len(granted) == 0 {
+		return nil, errors.New("requested methods do not require a capability")
+	}
+
+	now := time.Now()
+	capability := &RPCCapability{
+		Version:   capabilityVersion,
+		Issuer:    m.nodeID,
+		Subject:   peerID,
+		Methods:   granted,
+		IssuedAt:  now.UnixMilli(),
+		ExpiresAt: now.Add(m.config.Authorization.CapabilityTTL).UnixMilli(),
+	}
+	sig, _, err := m.gossip.SignAttestation(capabilityPayload(capability))
+	if err != nil {
+		return nil, fmt.Errorf("capability signing failed: %w", err)
+	}
+	capability.Signature = base64.StdEncoding.EncodeToString(sig)
+
+	m.logger.Debug("issued capability", "peer", getShortID(peerID), "methods", granted)
+	return capability, nil
+}
+
+// meetsCapabilityPolicy is the reputation-or-payment check behind issuance.
+func (m *MeshCoordinator) meetsCapabilityPolicy(peerID string, policy RPCMethodPolicy) bool {
+	if score, _ := m.reputation.GetTrustScore(peerID); score >= policy.MinReputation {
+		return true
+	}
+	// Demo-mode peers live in this process and run jobs on our own handlers
+	if m.sim != nil && IsSyntheticPeer(peerID) {
+		if _, ok := m.sim.Peer(peerID); ok {
+			return true
+		}
+	}
+	if policy.MinBalance <= 0 || m.ledger == nil {
+		return false
+	}
+	if m.ledger.GetBalance(peerID) >= policy.MinBalance {
+		return true
+	}
+	m.attestationMu.RLock()
+	did := m.attestedPeers[peerID].DID
+	m.attestationMu.RUnlock()
+	return did != "" && m.ledger.GetBalance(did) >= policy.MinBalance
+}
+
+// RevokeCapabilities invalidates every capability issued to peerID so far.
+func (m *MeshCoordinator) RevokeCapabilities(peerID string) {
+	m.capabilityMu.Lock()
+	m.capabilityRevocations[peerID] = time.Now()
+	m.capabilityMu.Unlock()
+}
+
+func (m *MeshCoordinator) registerCapabilityHandler() {
+	m.registerRPC(capabilityRequestMethod, func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error) {
+		var req CapabilityRequest
+		if err := json.Unmarshal(args, &req); err != nil {
+			return nil, fmt.Errorf("capability request decode failed: %w", err)
+		}
+		return m.IssueCapability(peerID, req.Methods)
+	})
+}
+
+// capabilityContext attaches a capability for calling method on peerID.
+// If the peer issues none the call goes out bare and the remote handler
+// decides.
+func (m *MeshCoordinator) capabilityContext(ctx context.Context, peerID, method string) context.Context {
+	if token := m.heldCapabilityToken(ctx, peerID, method); token != "" {
+		return common.WithCapability(ctx, token)
+	}
+	return ctx
+}
+
+// heldCapabilityToken returns the encoded capability peerID issued us for
+// methods, requesting a fresh one covering them all if the cached token is
+// missing one or about to expire. Methods our policy leaves open are not
+// asked for, and a refused request is not repeated for
+// capabilityRetryBackoff. It is empty when no method needs a capability or
+// the peer issues none.
+func (m *MeshCoordinator) heldCapabilityToken(ctx context.Context, peerID string, methods ...string) string {
+	if !m.config.Authorization.Enabled {
+		return ""
+	}
+	needed := make([]string, 0, len(methods))
+	for _, method := range methods {
+		if m.rpcPolicy(method).Mode == RPCPolicyCapability && !slices.Contains(needed, method) {
+			needed = append(needed, method)
+		}
+	}
+	if len(needed) == 0 {
+		return ""
+	}
+
+	now := time.Now()
+	m.capabilityMu.RLock()
+	held := m.heldCapabilities[peerID]
+	refusedUntil := m.capabilityRefusals[peerID]
+	m.capabilityMu.RUnlock()
+	if !held.allowsAll(needed, now.Add(capabilityRefreshMargin)) {
+		if now.Before(refusedUntil) {
+			return ""
+		}
+		var fresh RPCCapability
+		if err := m.transport.SendRPC(ctx, peerID, capabilityRequestMethod, CapabilityRequest{Methods: needed}, &fresh); err != nil {
+			m.logger.Debug("capability request failed", "peer", getShortID(peerID), "methods", needed, "error", err)
+			m.capabilityMu.Lock()
+			m.capabilityRefusals[peerID] = now.Add(capabilityRetryBackoff)
+			m.capabilityMu.Unlock()
+			return ""
+		}
+		held = &fresh
+		m.capabilityMu.Lock()
+		m.heldCapabilities[peerID] = held
+		delete(m.capabilityRefusals, peerID)
+		m.capabilityMu.Unlock()
+	}
+
+	token, err := encodeCapability(held)
+	if err != nil {
+		return ""
+	}
+	return token
+}
+
+// dropHeldCapability forgets a capability the peer no longer accepts.
+func (m *MeshCoordinator) dropHeldCapability(peerID string, err error) {
+	if err == nil {
+		return
+	}
+	msg := err.Error()
+	if !strings.Contains(msg, ErrCapabilityInvalid.Error()) && !strings.Contains(msg, ErrCapabilityRequired.Error()) {
+		return
+	}
+	m.capabilityMu.Lock()
+	delete(m.heldCapabilities, peerID)
+	m.capabilityMu.Unlock()
+}
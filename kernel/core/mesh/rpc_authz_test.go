@@ -0,0 +1,153 @@
+package mesh
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+	"github.com/nmxmxh/inos_v1/kernel/threads/foundation"
+)
+
+// trustPeers gives each peer the reputation the default policies require
+// before coord issues it a compute capability.
+func trustPeers(coord *MeshCoordinator, peerIDs ...string) {
+	for _, peerID := range peerIDs {
+		for i := 0; i < 3; i++ {
+			coord.reputation.Report(peerID, true, 1)
+		}
+	}
+}
+
+// capabilityContextFor returns a context carrying a capability coord issued
+// to peerID, as a remote caller that has earned one would present it.
+func capabilityContextFor(t *testing.T, coord *MeshCoordinator, peerID, method string) context.Context {
+	t.Helper()
+	trustPeers(coord, peerID)
+	capability, err := coord.IssueCapability(peerID, []string{method})
+	if err != nil {
+		t.Fatalf("IssueCapability failed: %v", err)
+	}
+	token, err := encodeCapability(capability)
+	if err != nil {
+		t.Fatalf("encodeCapability failed: %v", err)
+	}
+	return common.WithCapability(context.Background(), token)
+}
+
+func TestRPCAuthz_ComputeDeniedWithoutCapability(t *testing.T) {
+	tr := &MockTransport{nodeID: "self"}
+	coord := NewMeshCoordinator("self", "us-east", tr, nil)
+	coord.SetDispatcher(&mockDispatcher{})
+
+	tr.mu.Lock()
+	handler := tr.registeredRPCHandlers[executeJobMethod]
+	tr.mu.Unlock()
+
+	args := []byte(`{"id":"job-1","operation":"noop"}`)
+	if _, err := handler(context.Background(), "peer-1", args); !errors.Is(err, ErrCapabilityRequired) {
+		t.Fatalf("expected capability to be required, got %v", err)
+	}
+
+	// A capability issued to another peer is not transferable.
+	if _, err := handler(capabilityContextFor(t, coord, "peer-2", executeJobMethod), "peer-1", args); !errors.Is(err, ErrCapabilityInvalid) {
+		t.Fatalf("expected capability for another peer to be rejected, got %v", err)
+	}
+	// Nor does it extend to other privileged methods.
+	if _, err := handler(capabilityContextFor(t, coord, "peer-1", delegateComputeMethod), "peer-1", args); !errors.Is(err, ErrCapabilityInvalid) {
+		t.Fatalf("expected capability for another method to be rejected, got %v", err)
+	}
+	if _, err := handler(capabilityContextFor(t, coord, "peer-1", executeJobMethod), "peer-1", args); err != nil {
+		t.Fatalf("expected valid capability to be accepted, got %v", err)
+	}
+}
+
+func TestRPCAuthz_IssuanceRequiresReputationOrBalance(t *testing.T) {
+	coord := NewMeshCoordinator("self", "us-east", &MockTransport{nodeID: "self"}, nil)
+	coord.config.Authorization.Policies[executeJobMethod] = RPCMethodPolicy{Mode: RPCPolicyCapability, MinReputation: 0.9, MinBalance: 50}
+
+	if _, err := coord.IssueCapability("peer-1", []string{executeJobMethod}); !errors.Is(err, ErrRPCDenied) {
+		t.Fatalf("expected low-reputation peer without credits to be refused, got %v", err)
+	}
+
+	coord.ledger.RegisterAccount("peer-1", 100)
+	if _, err := coord.IssueCapability("peer-1", []string{executeJobMethod}); err != nil {
+		t.Fatalf("expected paying peer to be issued a capability, got %v", err)
+	}
+
+	coord.config.Authorization.Policies[executeJobMethod] = RPCMethodPolicy{Mode: RPCPolicyDeny}
+	if _, err := coord.IssueCapability("peer-1", []string{executeJobMethod}); !errors.Is(err, ErrRPCDenied) {
+		t.Fatalf("expected denied method to never be issued, got %v", err)
+	}
+}
+
+func TestRPCAuthz_UnknownPeerIsNotIssuedComputeCapability(t *testing.T) {
+	coord := NewMeshCoordinator("self", "us-east", &MockTransport{nodeID: "self"}, nil)
+
+	for _, method := range []string{executeJobMethod, delegateComputeMethod} {
+		if _, err := coord.IssueCapability("stranger", []string{method}); !errors.Is(err, ErrRPCDenied) {
+			t.Fatalf("expected %s refused to a peer at the default reputation, got %v", method, err)
+		}
+	}
+	trustPeers(coord, "stranger")
+	if _, err := coord.IssueCapability("stranger", []string{executeJobMethod, delegateComputeMethod}); err != nil {
+		t.Fatalf("expected a capability once the peer earned reputation, got %v", err)
+	}
+}
+
+func TestRPCAuthz_RevocationAndExpiry(t *testing.T) {
+	coord := NewMeshCoordinator("self", "us-east", &MockTransport{nodeID: "self"}, nil)
+	trustPeers(coord, "peer-1")
+
+	capability, err := coord.IssueCapability("peer-1", []string{executeJobMethod})
+	if err != nil {
+		t.Fatalf("IssueCapability failed: %v", err)
+	}
+	if err := coord.verifyCapability(capability, "peer-1", executeJobMethod); err != nil {
+		t.Fatalf("expected capability to verify: %v", err)
+	}
+
+	tampered := *capability
+	tampered.ExpiresAt = time.Now().Add(time.Hour * 24 * 365).UnixMilli()
+	if err := coord.verifyCapability(&tampered, "peer-1", executeJobMethod); err == nil {
+		t.Fatal("expected extended expiry to break the signature")
+	}
+
+	time.Sleep(2 * time.Millisecond)
+	coord.RevokeCapabilities("peer-1")
+	if err := coord.verifyCapability(capability, "peer-1", executeJobMethod); !errors.Is(err, ErrCapabilityInvalid) {
+		t.Fatalf("expected revoked capability to be rejected, got %v", err)
+	}
+	time.Sleep(2 * time.Millisecond)
+	fresh, err := coord.IssueCapability("peer-1", []string{executeJobMethod})
+	if err != nil {
+		t.Fatalf("IssueCapability after revocation failed: %v", err)
+	}
+	if err := coord.verifyCapability(fresh, "peer-1", executeJobMethod); err != nil {
+		t.Fatalf("expected capability issued after revocation to verify: %v", err)
+	}
+}
+
+func TestRPCAuthz_DelegateJobAcquiresCapability(t *testing.T) {
+	tr := &MockTransport{nodeID: "self"}
+	coord := NewMeshCoordinator("self", "us-east", tr, nil)
+	coord.config.WorkQueue.Enabled = false
+	coord.SetDispatcher(&mockDispatcher{})
+	coord.peerMetricsMu.Lock()
+	coord.peerMetrics["peer-1"] = common.MeshMetrics{AvgReputation: 1.0, P50LatencyMs: 1.0}
+	coord.peerMetricsMu.Unlock()
+	trustPeers(coord, "peer-1")
+
+	job := &foundation.Job{ID: "job-1", Operation: "noop"}
+	if _, err := coord.DelegateJob(context.Background(), job); err != nil {
+		t.Fatalf("DelegateJob failed: %v", err)
+	}
+
+	coord.capabilityMu.RLock()
+	held := coord.heldCapabilities["peer-1"]
+	coord.capabilityMu.RUnlock()
+	if held == nil || !held.Allows(executeJobMethod, time.Now()) {
+		t.Fatalf("expected a cached capability for peer-1, got %+v", held)
+	}
+}
@@ -0,0 +1,58 @@
+package mesh
+
+import (
+	"context"
+	"sync"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+)
+
+// SendRPCBatch sends several calls to one peer and fills in each call's
+// Reply and Err. Transports that support it carry the calls in a single
+// frame, each call with its own capability; others send the calls
+// individually, in parallel.
+func (m *MeshCoordinator) SendRPCBatch(ctx context.Context, peerID string, calls []common.RPCCall) error {
+	if len(calls) == 0 {
+		return nil
+	}
+
+	if bt, ok := m.transport.(common.BatchTransport); ok {
+		methods := make([]string, len(calls))
+		for i := range calls {
+			methods[i] = calls[i].Method
+		}
+		token := m.heldCapabilityToken(ctx, peerID, methods...)
+		for i := range calls {
+			if token != "" && m.rpcPolicy(calls[i].Method).Mode == RPCPolicyCapability {
+				calls[i].Capability = token
+			}
+		}
+		if err := bt.SendRPCBatch(ctx, peerID, calls); err != nil {
+			m.recordRPCFailure(peerID, calls[0].Method, err)
+			return err
+		}
+		for i := range calls {
+			if calls[i].Err != nil {
+				m.dropHeldCapability(peerID, calls[i].Err)
+				m.recordRPCFailure(peerID, calls[i].Method, calls[i].Err)
+			}
+		}
+		return nil
+	}
+
+	var wg sync.WaitGroup
+	for i := range calls {
+		wg.Add(1)
+		go func(call *common.RPCCall) {
+			defer wg.Done()
+			callCtx := m.capabilityContext(ctx, peerID, call.Method)
+			call.Err = m.transport.SendRPC(callCtx, peerID, call.Method, call.Args, call.Reply)
+			if call.Err != nil {
+				m.dropHeldCapability(peerID, call.Err)
+				m.recordRPCFailure(peerID, call.Method, call.Err)
+			}
+		}(&calls[i])
+	}
+	wg.Wait()
+	return nil
+}
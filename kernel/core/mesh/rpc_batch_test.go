@@ -0,0 +1,107 @@
+package mesh
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+)
+
+// batchingTransport is a MockTransport that carries batches in one frame
+// and records what each frame held.
+type batchingTransport struct {
+	*MockTransport
+	frames [][]common.RPCCall
+	mu     sync.Mutex
+}
+
+func (b *batchingTransport) SendRPCBatch(ctx context.Context, peerID string, calls []common.RPCCall) error {
+	b.mu.Lock()
+	b.frames = append(b.frames, append([]common.RPCCall(nil), calls...))
+	b.mu.Unlock()
+	for i := range calls {
+		calls[i].Err = b.SendRPC(ctx, peerID, calls[i].Method, calls[i].Args, calls[i].Reply)
+	}
+	return nil
+}
+
+func newBatchingCoordinator(t *testing.T, issue func() (interface{}, error)) (*MeshCoordinator, *batchingTransport, *int) {
+	t.Helper()
+	requests := 0
+	tr := &batchingTransport{MockTransport: &MockTransport{nodeID: "self", rpcHandlers: map[string]func(args interface{}) (interface{}, error){
+		capabilityRequestMethod: func(args interface{}) (interface{}, error) {
+			requests++
+			return issue()
+		},
+		executeJobMethod: func(args interface{}) (interface{}, error) { return "ok", nil },
+		"mesh.Ping":      func(args interface{}) (interface{}, error) { return "pong", nil },
+	}}}
+	coord := NewMeshCoordinator("self", "us-east", tr, nil)
+	if !coord.config.Authorization.Enabled {
+		t.Fatal("expected authorization to be enabled by default")
+	}
+	return coord, tr, &requests
+}
+
+func TestSendRPCBatch_BatchesWithAuthorizationEnabled(t *testing.T) {
+	issuer := NewMeshCoordinator("peer-1", "us-east", &MockTransport{nodeID: "peer-1"}, nil)
+	trustPeers(issuer, "self")
+	coord, tr, requests := newBatchingCoordinator(t, func() (interface{}, error) {
+		return issuer.IssueCapability("self", []string{executeJobMethod})
+	})
+
+	if err := coord.SendRPCBatch(context.Background(), "peer-1", nil); err != nil {
+		t.Fatalf("empty batch failed: %v", err)
+	}
+
+	// Open methods go out in one frame without asking for a capability
+	calls := []common.RPCCall{{Method: "mesh.Ping"}, {Method: "mesh.Ping"}}
+	if err := coord.SendRPCBatch(context.Background(), "peer-1", calls); err != nil {
+		t.Fatalf("SendRPCBatch failed: %v", err)
+	}
+	if *requests != 0 || len(tr.frames) != 1 || tr.frames[0][0].Capability != "" {
+		t.Fatalf("expected one bare frame and no capability request, got %d requests and frames %+v", *requests, tr.frames)
+	}
+
+	// A mixed batch asks once and attaches the token only where it is needed
+	var replies [3]string
+	calls = []common.RPCCall{
+		{Method: executeJobMethod, Reply: &replies[0]},
+		{Method: "mesh.Ping", Reply: &replies[1]},
+		{Method: executeJobMethod, Reply: &replies[2]},
+	}
+	if err := coord.SendRPCBatch(context.Background(), "peer-1", calls); err != nil {
+		t.Fatalf("SendRPCBatch failed: %v", err)
+	}
+	if *requests != 1 || len(tr.frames) != 2 {
+		t.Fatalf("expected one capability request and two frames, got %d and %d", *requests, len(tr.frames))
+	}
+	frame := tr.frames[1]
+	if frame[0].Capability == "" || frame[2].Capability == "" || frame[1].Capability != "" {
+		t.Fatalf("expected capabilities on the privileged calls only, got %+v", frame)
+	}
+	if replies != [3]string{"ok", "pong", "ok"} {
+		t.Fatalf("unexpected replies %v", replies)
+	}
+}
+
+func TestSendRPCBatch_RefusedCapabilityIsNotReRequested(t *testing.T) {
+	coord, tr, requests := newBatchingCoordinator(t, func() (interface{}, error) {
+		return nil, errors.New("rpc denied: requires reputation")
+	})
+
+	for i := 0; i < 3; i++ {
+		calls := []common.RPCCall{{Method: executeJobMethod}}
+		if err := coord.SendRPCBatch(context.Background(), "peer-1", calls); err != nil {
+			t.Fatalf("SendRPCBatch failed: %v", err)
+		}
+	}
+	if *requests != 1 {
+		t.Fatalf("expected the refusal to be remembered, got %d capability requests", *requests)
+	}
+	if len(tr.frames) != 3 || tr.frames[2][0].Capability != "" {
+		t.Fatalf("expected bare calls to still be sent, got %+v", tr.frames)
+	}
+}
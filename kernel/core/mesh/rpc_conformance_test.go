@@ -0,0 +1,107 @@
+package mesh
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+	"github.com/nmxmxh/inos_v1/kernel/threads/foundation"
+)
+
+// callConformant invokes a registered handler and checks both sides of the
+// exchange against the published schemas.
+func callConformant(t *testing.T, tr *MockTransport, method, peerID, args string) json.RawMessage {
+	t.Helper()
+	if err := common.DefaultRPCRegistry.ValidateRequest(method, json.RawMessage(args)); err != nil {
+		t.Fatalf("%s request does not conform: %v", method, err)
+	}
+	handler, ok := tr.registeredRPCHandlers[method]
+	if !ok {
+		t.Fatalf("no handler registered for %s", method)
+	}
+	result, err := handler(context.Background(), peerID, json.RawMessage(args))
+	if err != nil {
+		t.Fatalf("%s handler failed: %v", method, err)
+	}
+	raw, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("%s response not encodable: %v", method, err)
+	}
+	if err := common.DefaultRPCRegistry.ValidateResponse(method, raw); err != nil {
+		t.Fatalf("%s response does not conform: %v (%s)", method, err, raw)
+	}
+	return raw
+}
+
+func TestRPCConformance_EveryHandlerIsDocumented(t *testing.T) {
+	tr := &MockTransport{nodeID: "self"}
+	NewMeshCoordinator("self", "us-east", tr, nil)
+
+	for method := range tr.registeredRPCHandlers {
+		if _, ok := common.DefaultRPCRegistry.Lookup(method); !ok {
+			t.Errorf("RPC handler %s has no registered schema", method)
+		}
+	}
+
+	desc := RPCProtocolDescription()
+	if desc.Version != common.RPCProtocolVersion || len(desc.Methods) == 0 {
+		t.Fatalf("unexpected protocol description %+v", desc)
+	}
+	if _, err := json.Marshal(desc); err != nil {
+		t.Fatalf("protocol description not encodable: %v", err)
+	}
+}
+
+// Fixtures below are hand-written JSON as a non-Go peer would send it.
+func TestRPCConformance_SimulatedPeerFixtures(t *testing.T) {
+	tr := &MockTransport{nodeID: "self"}
+	coord := NewMeshCoordinator("self", "us-east", tr, nil)
+	coord.SetStorage(&MockStorage{chunks: make(map[string][]byte)})
+
+	callConformant(t, tr, chunkStoreMethod, "sim-peer", `{"chunk_hash":"c1","data":"aGVsbG8="}`)
+
+	var fetched ChunkFetchResponse
+	raw := callConformant(t, tr, chunkFetchMethod, "sim-peer", `{"chunk_hash":"c1"}`)
+	if err := json.Unmarshal(raw, &fetched); err != nil || fetched.Size != 5 {
+		t.Fatalf("unexpected chunk.fetch response %s", raw)
+	}
+
+	callConformant(t, tr, bandwidthProbeMethod, "sim-peer", `{"data":"AAECAw=="}`)
+
+	malformed := map[string]string{
+		chunkStoreMethod:     `{"data":"aGVsbG8="}`,
+		chunkFetchMethod:     `{"chunk_hash":42}`,
+		bandwidthProbeMethod: `{"data":"not base64!"}`,
+		workClaimMethod:      `{}`,
+		workCompleteMethod:   `{"job_id":"j","success":"yes","latency_ms":1}`,
+		"gossip.messages":    `{"ids":["a"]}`,
+		"merkle.children":    `["AAEC"]`,
+	}
+	for method, args := range malformed {
+		if err := common.DefaultRPCRegistry.ValidateRequest(method, json.RawMessage(args)); err == nil {
+			t.Errorf("expected malformed %s request to be rejected: %s", method, args)
+		}
+	}
+}
+
+func TestRPCConformance_WorkQueueAndGossip(t *testing.T) {
+	tr := &MockTransport{nodeID: "requester"}
+	coord := NewMeshCoordinator("requester", "us-east", tr, nil)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = coord.SubmitWork(context.Background(), &foundation.Job{ID: "job-c", Operation: "hash", Data: []byte("abc")})
+	}()
+	waitForPendingWork(t, coord, "job-c")
+
+	callConformant(t, tr, workClaimMethod, "sim-peer", `{"job_id":"job-c"}`)
+	callConformant(t, tr, workCompleteMethod, "sim-peer", `{"job_id":"job-c","success":true,"data":"ZGlnZXN0","latency_ms":3}`)
+	<-done
+
+	callConformant(t, tr, "gossip.pull", "sim-peer", `null`)
+	callConformant(t, tr, "merkle.hashes", "sim-peer", ``)
+	callConformant(t, tr, "merkle.root", "sim-peer", ``)
+	callConformant(t, tr, "gossip.messages", "sim-peer", `["missing"]`)
+}
@@ -0,0 +1,102 @@
+package mesh
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+	"github.com/nmxmxh/inos_v1/kernel/threads/foundation"
+	"github.com/nmxmxh/inos_v1/kernel/utils/tracing"
+)
+
+// NamespaceUsage is the work this node served for one caller namespace.
+type NamespaceUsage struct {
+	Namespace string `json:"namespace"`
+	Requests  uint64 `json:"requests"`
+	Bytes     uint64 `json:"bytes"`
+}
+
+// jobRPCContext carries a job's priority and deadline as RPC metadata so the
+// executing peer schedules it the way the submitter asked.
+func jobRPCContext(ctx context.Context, job *foundation.Job) (context.Context, context.CancelFunc) {
+	if _, ok := common.PriorityFromContext(ctx); !ok && job.Priority != 0 {
+		ctx = common.WithPriority(ctx, job.Priority)
+	}
+	if !job.Deadline.IsZero() {
+		if current, ok := ctx.Deadline(); !ok || job.Deadline.Before(current) {
+			return context.WithDeadline(ctx, job.Deadline)
+		}
+	}
+	return ctx, func() {}
+}
+
+// applyRPCScheduling fills a received job's priority and deadline from the
+// caller's metadata when the job itself does not set them.
+func applyRPCScheduling(ctx context.Context, job *foundation.Job, defaultPriority int) {
+	if job.Priority == 0 {
+		job.Priority = defaultPriority
+		if priority, ok := common.PriorityFromContext(ctx); ok {
+			job.Priority = priority
+		}
+	}
+	if deadline, ok := ctx.Deadline(); ok && (job.Deadline.IsZero() || deadline.Before(job.Deadline)) {
+		job.Deadline = deadline
+	}
+}
+
+// traceContext adopts a traceparent carried in a payload (a queued job, a
+// relayed request) when ctx is not already inside a span.
+func traceContext(ctx context.Context, traceparent string) context.Context {
+	if traceparent == "" || tracing.SpanFromContext(ctx) != nil {
+		return ctx
+	}
+	return tracing.ContextWithTraceparent(ctx, traceparent)
+}
+
+// rpcLogger tags handler logs with the caller's trace ID and namespace.
+func (m *MeshCoordinator) rpcLogger(ctx context.Context) *slog.Logger {
+	if traceID := common.TraceIDFromContext(ctx); traceID != "" {
+		return m.logger.With("trace_id", traceID, "namespace", common.NamespaceFromContext(ctx))
+	}
+	return m.logger
+}
+
+// recordNamespaceUsage accounts served work to the caller's namespace.
+func (m *MeshCoordinator) recordNamespaceUsage(ctx context.Context, bytes int) {
+	namespace := common.NamespaceFromContext(ctx)
+	m.namespaceUsageMu.Lock()
+	usage, ok := m.namespaceUsage[namespace]
+	if !ok {
+		usage = &NamespaceUsage{Namespace: namespace}
+		m.namespaceUsage[namespace] = usage
+	}
+	usage.Requests++
+	if bytes > 0 {
+		usage.Bytes += uint64(bytes)
+	}
+	m.namespaceUsageMu.Unlock()
+}
+
+// GetNamespaceUsage returns served work per caller namespace.
+func (m *MeshCoordinator) GetNamespaceUsage() []NamespaceUsage {
+	m.namespaceUsageMu.Lock()
+	out := make([]NamespaceUsage, 0, len(m.namespaceUsage))
+	for _, usage := range m.namespaceUsage {
+		out = append(out, *usage)
+	}
+	m.namespaceUsageMu.Unlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].Namespace < out[j].Namespace })
+	return out
+}
+
+// remainingBudget reports how long a handler may run before the caller's
+// deadline; ok is false when the caller set none.
+func remainingBudget(ctx context.Context) (time.Duration, bool) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return 0, false
+	}
+	return time.Until(deadline), true
+}
@@ -0,0 +1,70 @@
+package mesh
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+	"github.com/nmxmxh/inos_v1/kernel/threads/foundation"
+)
+
+func TestMeshCoordinator_RPCMetadataReachesRemoteScheduler(t *testing.T) {
+	tr := &MockTransport{nodeID: "self"}
+	coord := NewMeshCoordinator("self", "us-east", tr, nil)
+	coord.config.WorkQueue.Enabled = false
+
+	var executed *foundation.Job
+	coord.SetDispatcher(&mockDispatcher{run: func(job *foundation.Job) *foundation.Result {
+		executed = job
+		return &foundation.Result{JobID: job.ID, Success: true}
+	}})
+	coord.peerMetricsMu.Lock()
+	coord.peerMetrics["peer-1"] = common.MeshMetrics{AvgReputation: 1.0, P50LatencyMs: 1.0}
+	coord.peerMetricsMu.Unlock()
+	trustPeers(coord, "peer-1")
+
+	deadline := time.Now().Add(time.Minute)
+	ctx := common.WithNamespace(context.Background(), "tenant-a")
+	job := &foundation.Job{ID: "job-1", Operation: "noop", Data: []byte("abc"), Priority: 230, Deadline: deadline}
+
+	if _, err := coord.DelegateJob(ctx, job); err != nil {
+		t.Fatalf("DelegateJob failed: %v", err)
+	}
+	if executed == nil || executed.Priority != 230 || executed.Deadline.After(deadline) {
+		t.Fatalf("expected job priority and deadline to survive, got %+v", executed)
+	}
+
+	// A job sent without a priority takes the caller's metadata priority.
+	tr.mu.Lock()
+	handler := tr.registeredRPCHandlers[executeJobMethod]
+	tr.mu.Unlock()
+	handlerCtx := common.WithPriority(common.WithNamespace(capabilityContextFor(t, coord, "peer-2", executeJobMethod), "tenant-b"), 42)
+	if _, err := handler(handlerCtx, "peer-2", []byte(`{"id":"job-2","operation":"noop","data":"eHl6"}`)); err != nil {
+		t.Fatalf("handler failed: %v", err)
+	}
+	if executed.Priority != 42 {
+		t.Fatalf("expected metadata priority for job without one, got %d", executed.Priority)
+	}
+
+	usage := coord.GetNamespaceUsage()
+	if len(usage) != 2 || usage[0].Namespace != "tenant-a" || usage[1].Namespace != "tenant-b" || usage[1].Bytes != 3 {
+		t.Fatalf("unexpected namespace usage %+v", usage)
+	}
+}
+
+func TestMeshCoordinator_RPCRejectsExpiredDeadline(t *testing.T) {
+	tr := &MockTransport{nodeID: "self"}
+	coord := NewMeshCoordinator("self", "us-east", tr, nil)
+	coord.SetDispatcher(&mockDispatcher{})
+
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+
+	tr.mu.Lock()
+	handler := tr.registeredRPCHandlers[executeJobMethod]
+	tr.mu.Unlock()
+	if _, err := handler(ctx, "peer-1", []byte(`{"id":"late"}`)); err == nil {
+		t.Fatal("expected a job past its deadline to be refused")
+	}
+}
@@ -0,0 +1,205 @@
+package mesh
+
+import (
+	"crypto/ed25519"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+	"github.com/nmxmxh/inos_v1/kernel/threads/foundation"
+)
+
+const (
+	chunkStoreMethod      = "chunk.store"
+	chunkFetchMethod      = "chunk.fetch"
+	chunkAbortMethod      = "chunk.abort"
+	delegateComputeMethod = "mesh.DelegateCompute"
+	executeJobMethod      = "mesh.ExecuteJob"
+)
+
+// ChunkStoreRequest asks a peer to persist a replica of a chunk.
+type ChunkStoreRequest struct {
+	ChunkHash    string               `json:"chunk_hash"`
+	Data         []byte               `json:"data"`
+	RawSize      int                  `json:"raw_size,omitempty"`
+	WireSize     int                  `json:"wire_size,omitempty"`
+	Compression  string               `json:"compression,omitempty"`
+	TxID         string               `json:"tx_id,omitempty"`        // Stage under a publish transaction
+	Framed       bool                 `json:"framed,omitempty"`       // Data came just before as a binary chunk frame
+	ACL          *ChunkACL            `json:"acl,omitempty"`          // Owner-signed; fetches are checked against it
+	Announcement *ReplicaAnnouncement `json:"announcement,omitempty"` // Justifies the replica against quotas
+}
+
+// ChunkStoreResponse acknowledges a stored chunk, or says why it was not.
+type ChunkStoreResponse struct {
+	Stored      bool                 `json:"stored"`
+	Size        int                  `json:"size"`
+	RawSize     int                  `json:"raw_size"`
+	WireSize    int                  `json:"wire_size"`
+	Compression string               `json:"compression"`
+	Rejected    *ChunkStoreRejection `json:"rejected,omitempty"`
+}
+
+// ChunkFetchRequest asks a peer for a chunk it advertises. A chunk stored
+// under an ACL is only served to a signed request the ACL admits.
+type ChunkFetchRequest struct {
+	ChunkHash  string            `json:"chunk_hash"`
+	Requester  string            `json:"requester,omitempty"`
+	Nonce      string            `json:"nonce,omitempty"`     // Random per request; a holder serves each once
+	IssuedAt   int64             `json:"issued_at,omitempty"` // Unix milliseconds, mesh time
+	PublicKey  ed25519.PublicKey `json:"public_key,omitempty"`
+	DIDBinding *DIDBinding       `json:"did_binding,omitempty"`
+	Grants     []*ChunkGrant     `json:"grants,omitempty"`
+	Signature  []byte            `json:"signature,omitempty"`
+}
+
+// ChunkFetchResponse carries chunk bytes in their wire encoding.
+type ChunkFetchResponse struct {
+	Data        []byte `json:"data"`
+	Size        int    `json:"size"`
+	RawSize     int    `json:"raw_size"`
+	WireSize    int    `json:"wire_size"`
+	Compression string `json:"compression"`
+}
+
+// ChunkAbortRequest removes the replicas a caller staged under a publish
+// transaction that did not commit.
+type ChunkAbortRequest struct {
+	TxID string `json:"tx_id"`
+}
+
+// ChunkAbortResponse reports how many staged replicas were removed.
+type ChunkAbortResponse struct {
+	Removed int `json:"removed"`
+}
+
+type workClaimRequest struct {
+	JobID string `json:"job_id"`
+}
+
+type workReleaseRequest struct {
+	JobID string `json:"job_id"`
+}
+
+type workCompletionAck struct {
+	Accepted bool `json:"accepted"`
+}
+
+func init() {
+	for _, spec := range []common.RPCMethodSpec{
+		{
+			Name:        chunkStoreMethod,
+			Description: "Store a chunk replica. Data may be brotli-compressed; compression and raw_size describe the encoding. An owner-signed acl restricts later fetches. Replicas count against per-peer and global inbound quotas and must be justified by a signed announcement or payment; a refused store answers with rejected.",
+			Request:     ChunkStoreRequest{},
+			Response:    ChunkStoreResponse{},
+		},
+		{
+			Name:        chunkFetchMethod,
+			Description: "Fetch a chunk by hash. Also served as a stream for large chunks. Chunks stored under an ACL need a signed request from an allowed node or DID, or one carrying the owner's grant.",
+			Request:     ChunkFetchRequest{},
+			Response:    ChunkFetchResponse{},
+		},
+		{
+			Name:        chunkAbortMethod,
+			Description: "Remove replicas the caller staged with chunk.store under tx_id; only the staging peer may abort.",
+			Request:     ChunkAbortRequest{},
+			Response:    ChunkAbortResponse{},
+		},
+		{
+			Name:        delegateComputeMethod,
+			Description: "Execute an operation over a Cap'n Proto system.Resource and return a packed result resource.",
+			Request:     DelegateRequest{},
+			Response:    DelegationResponse{},
+		},
+		{
+			Name:        executeJobMethod,
+			Description: "Execute a job on the remote dispatcher and return its result.",
+			Request:     workJob{},
+			Response:    foundation.Result{},
+		},
+		{
+			Name:        attestationMethod,
+			Description: "Answer an attestation challenge by hashing SAB regions and signing with the identity key.",
+			Request:     AttestationChallenge{},
+			Response:    AttestationResponse{},
+		},
+		{
+			Name:        bandwidthProbeMethod,
+			Description: "Echo a payload so the caller can measure round-trip throughput.",
+			Request:     bandwidthProbeRequest{},
+			Response:    bandwidthProbeResponse{},
+		},
+		{
+			Name:        featureProbeMethod,
+			Description: "Report supported WASM features and whether the named modules load.",
+			Request:     featureProbeRequest{},
+			Response:    featureProbeResponse{},
+		},
+		{
+			Name:        workClaimMethod,
+			Description: "Claim an announced job from the requester's work queue.",
+			Request:     workClaimRequest{},
+			Response:    workJob{},
+		},
+		{
+			Name:        workCompleteMethod,
+			Description: "Report the outcome of a claimed job to its requester.",
+			Request:     workCompletion{},
+			Response:    workCompletionAck{},
+		},
+		{
+			Name:        workReleaseMethod,
+			Description: "Hand a claimed job back to its requester for re-announcement; sent by a departing peer.",
+			Request:     workReleaseRequest{},
+			Response:    workCompletionAck{},
+		},
+		{
+			Name:        serviceInfoMethod,
+			Description: "Report whether this node still serves a named service, its health and its metadata.",
+			Request:     serviceInfoRequest{},
+			Response:    serviceInfoResponse{},
+		},
+		{
+			Name:        capabilityRequestMethod,
+			Description: "Request a signed capability for privileged methods; issued after reputation or ledger balance checks.",
+			Request:     CapabilityRequest{},
+			Response:    RPCCapability{},
+		},
+		{
+			Name:        storageChallengeMethod,
+			Description: "Prove possession of a chunk by returning sha256(nonce || data[offset:offset+length]).",
+			Request:     StorageChallenge{},
+			Response:    StorageProof{},
+		},
+		{
+			Name:        pubsubHistoryMethod,
+			Description: "Return the messages this node retains for a pub/sub topic, published after since.",
+			Request:     topicHistoryRequest{},
+			Response:    topicHistoryResponse{},
+		},
+		{
+			Name:        timeSyncMethod,
+			Description: "Return the request's send time with this node's mesh time on arrival and on reply.",
+			Request:     timeSyncRequest{},
+			Response:    timeSyncResponse{},
+		},
+		{
+			Name:        latencyProbeMethod,
+			Description: "Echo the request's send time with up to 16 of this node's round-trip estimates in milliseconds; refused when a peer probes more than once per half probe interval.",
+			Request:     latencyProbeRequest{},
+			Response:    latencyProbeResponse{},
+		},
+		{
+			Name:        disputeReviewMethod,
+			Description: "Re-execute a disputed job on its input and return a signed verdict: upheld when the output differs from the executor's receipt, dismissed when it matches, abstained when the job could not run. Only the receipt's requester may ask.",
+			Request:     disputeReviewRequest{},
+			Response:    DisputeVerdict{},
+		},
+	} {
+		common.MustRegisterRPCMethod(spec)
+	}
+}
+
+// RPCProtocolDescription returns the machine-readable description of every
+// registered mesh RPC method.
+func RPCProtocolDescription() common.RPCProtocolDescription {
+	return common.DefaultRPCRegistry.Describe()
+}
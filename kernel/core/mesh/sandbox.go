@@ -0,0 +1,178 @@
+package mesh
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/routing"
+	"github.com/nmxmxh/inos_v1/kernel/threads/foundation"
+)
+
+// Job parameters carrying the sandbox limits to the runtime. The supervisor
+// bridge copies them into the compute JobRequest, whose engine lowers its
+// unit limits to them and fails the job with a resource_exceeded error.
+const (
+	JobParamFuelLimit   = foundation.JobParamFuelLimit
+	JobParamMemoryLimit = foundation.JobParamMemoryLimit
+)
+
+// Resources a sandbox limits.
+const (
+	SandboxResourceFuel      = "fuel"
+	SandboxResourceMemory    = "memory"
+	SandboxResourceWallClock = "wall_clock"
+)
+
+// resourceExceededPrefix marks a runtime error, or a delegation response
+// error, as a resource limit hit: "resource_exceeded:<resource>:<limit>:<used>".
+const resourceExceededPrefix = "resource_exceeded:"
+
+// ErrResourceExceeded is wrapped by every ResourceExceededError.
+var ErrResourceExceeded = errors.New("wasm job exceeded a resource limit")
+
+// ResourceExceededError reports which sandbox limit stopped a job. Used is
+// zero when the runtime did not say how far over the job went.
+type ResourceExceededError struct {
+	Resource string
+	Limit    uint64
+	Used     uint64
+}
+
+func (e *ResourceExceededError) Error() string {
+	if e.Resource == SandboxResourceWallClock {
+		return fmt.Sprintf("wasm job exceeded its %s deadline", time.Duration(e.Limit))
+	}
+	if e.Used > 0 {
+		return fmt.Sprintf("wasm job exceeded its %s limit: used %d of %d", e.Resource, e.Used, e.Limit)
+	}
+	return fmt.Sprintf("wasm job exceeded its %s limit of %d", e.Resource, e.Limit)
+}
+
+func (e *ResourceExceededError) Unwrap() error { return ErrResourceExceeded }
+
+// wire encodes the error for a result or delegation response.
+func (e *ResourceExceededError) wire() string {
+	return fmt.Sprintf("%s%s:%d:%d", resourceExceededPrefix, e.Resource, e.Limit, e.Used)
+}
+
+// ParseResourceExceeded recovers the structured error from a job result or
+// delegation response error string.
+func ParseResourceExceeded(s string) (*ResourceExceededError, bool) {
+	rest, ok := strings.CutPrefix(s, resourceExceededPrefix)
+	if !ok {
+		return nil, false
+	}
+	parts := strings.SplitN(rest, ":", 3)
+	e := &ResourceExceededError{Resource: parts[0]}
+	if len(parts) > 1 {
+		e.Limit, _ = strconv.ParseUint(parts[1], 10, 64)
+	}
+	if len(parts) > 2 {
+		e.Used, _ = strconv.ParseUint(parts[2], 10, 64)
+	}
+	return e, e.Resource != ""
+}
+
+// SandboxLimits bounds one delegated job. Zero leaves a resource unlimited.
+type SandboxLimits struct {
+	Fuel        uint64        `json:"fuel"`
+	MemoryBytes uint64        `json:"memory_bytes"`
+	WallClock   time.Duration `json:"wall_clock"`
+}
+
+// sandboxLimits returns the configured caps, lowered by any limits the job
+// itself asks for. A job can tighten the sandbox but never loosen it.
+func (m *MeshCoordinator) sandboxLimits(job *foundation.Job) SandboxLimits {
+	limits := SandboxLimits{
+		Fuel:        m.config.Sandbox.Fuel,
+		MemoryBytes: m.config.Sandbox.MemoryBytes,
+		WallClock:   m.config.Sandbox.WallClock,
+	}
+	limits.Fuel = lowerLimit(limits.Fuel, foundation.UintParam(job.Parameters[JobParamFuelLimit]))
+	limits.MemoryBytes = lowerLimit(limits.MemoryBytes, foundation.UintParam(job.Parameters[JobParamMemoryLimit]))
+	if !job.Deadline.IsZero() {
+		if until := time.Until(job.Deadline); limits.WallClock <= 0 || until < limits.WallClock {
+			limits.WallClock = max(until, 0)
+		}
+	}
+	return limits
+}
+
+func lowerLimit(limit, requested uint64) uint64 {
+	if requested > 0 && (limit == 0 || requested < limit) {
+		return requested
+	}
+	return limit
+}
+
+// runSandboxed runs a delegated job under the sandbox limits. The limits go
+// to the runtime as job parameters and a deadline; the wall clock is also
+// enforced here, and reported memory use is checked against the cap, so a
+// runtime that ignores them still cannot hold a result hostage. A job that
+// hits a limit is charged to the peer that sent it.
+func (m *MeshCoordinator) runSandboxed(ctx context.Context, requester string, job *foundation.Job) (*foundation.Result, error) {
+	limits := m.sandboxLimits(job)
+	if job.Parameters == nil {
+		job.Parameters = make(map[string]interface{})
+	}
+	if limits.Fuel > 0 {
+		job.Parameters[JobParamFuelLimit] = limits.Fuel
+	}
+	if limits.MemoryBytes > 0 {
+		job.Parameters[JobParamMemoryLimit] = limits.MemoryBytes
+	}
+
+	runCtx := ctx
+	if limits.WallClock > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, limits.WallClock)
+		defer cancel()
+		job.Deadline = time.Now().Add(limits.WallClock)
+	}
+
+	done := make(chan *foundation.Result, 1)
+	go func() { done <- m.dispatcher.ExecuteJob(job) }()
+
+	var result *foundation.Result
+	select {
+	case result = <-done:
+	case <-runCtx.Done():
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		// The runtime was handed the deadline and is expected to abort; its
+		// late result is dropped
+		return nil, m.sandboxExceeded(requester, job, &ResourceExceededError{
+			Resource: SandboxResourceWallClock,
+			Limit:    uint64(limits.WallClock),
+		})
+	}
+	if result == nil {
+		return &foundation.Result{JobID: job.ID, Error: "dispatcher returned no result"}, nil
+	}
+
+	if exceeded, ok := ParseResourceExceeded(result.Error); ok && !result.Success {
+		return nil, m.sandboxExceeded(requester, job, exceeded)
+	}
+	if limits.MemoryBytes > 0 && result.Metrics != nil && result.Metrics.MemoryUsed > limits.MemoryBytes {
+		return nil, m.sandboxExceeded(requester, job, &ResourceExceededError{
+			Resource: SandboxResourceMemory,
+			Limit:    limits.MemoryBytes,
+			Used:     result.Metrics.MemoryUsed,
+		})
+	}
+	return result, nil
+}
+
+func (m *MeshCoordinator) sandboxExceeded(requester string, job *foundation.Job, err *ResourceExceededError) error {
+	m.execution.resourceExceeded.Add(1)
+	m.logger.Warn("delegated job exceeded its sandbox", "job_id", job.ID, "peer", getShortID(requester), "resource", err.Resource, "limit", err.Limit)
+	if requester != "" && m.reputation != nil {
+		m.reputation.ReportPenalty(requester, routing.PenaltyResourceExceeded)
+	}
+	return err
+}
@@ -0,0 +1,119 @@
+package mesh
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+	"github.com/nmxmxh/inos_v1/kernel/threads/foundation"
+)
+
+func TestSandbox_ResourceExceededIsStructuredAndPenalized(t *testing.T) {
+	coord, _ := newDelegationTestCoordinator(t)
+	coord.config.Sandbox.MemoryBytes = 1 << 20
+	var seen map[string]interface{}
+	coord.SetDispatcher(&mockDispatcher{
+		run: func(job *foundation.Job) *foundation.Result {
+			seen = job.Parameters
+			return &foundation.Result{JobID: job.ID, Success: true, Data: []byte("out"), Metrics: &foundation.ExecutionMetrics{MemoryUsed: 2 << 20}}
+		},
+	})
+	before, _ := coord.reputation.GetTrustScore("node-a")
+
+	_, err := coord.DelegateCompute(context.Background(), "compress", "input-digest", []byte("source"))
+	var exceeded *ResourceExceededError
+	if !errors.As(err, &exceeded) || !errors.Is(err, ErrResourceExceeded) {
+		t.Fatalf("expected ResourceExceededError, got %v", err)
+	}
+	if exceeded.Resource != SandboxResourceMemory || exceeded.Limit != 1<<20 || exceeded.Used != 2<<20 {
+		t.Fatalf("unexpected error %+v", exceeded)
+	}
+	if seen[JobParamMemoryLimit] != uint64(1<<20) {
+		t.Fatalf("memory limit not passed to the runtime: %v", seen)
+	}
+	if n := coord.GetExecutionStats().ResourceExceeded; n != 1 {
+		t.Fatalf("expected one resource-exceeded job, got %d", n)
+	}
+	if after, _ := coord.reputation.GetTrustScore("node-a"); after >= before {
+		t.Fatalf("expected the requester to be penalized: %v -> %v", before, after)
+	}
+}
+
+func TestSandbox_WallClockAndJobLimits(t *testing.T) {
+	coord, _ := newDelegationTestCoordinator(t)
+	coord.config.Sandbox.WallClock = 20 * time.Millisecond
+	release := make(chan struct{})
+	defer close(release)
+	coord.SetDispatcher(&mockDispatcher{
+		run: func(job *foundation.Job) *foundation.Result {
+			<-release
+			return &foundation.Result{JobID: job.ID, Success: true}
+		},
+	})
+
+	job := &foundation.Job{ID: "spin", Parameters: map[string]interface{}{}}
+	_, err := coord.runSandboxed(context.Background(), "peer-1", job)
+	var exceeded *ResourceExceededError
+	if !errors.As(err, &exceeded) || exceeded.Resource != SandboxResourceWallClock {
+		t.Fatalf("expected a wall clock error, got %v", err)
+	}
+	parsed, ok := ParseResourceExceeded(exceeded.wire())
+	if !ok || *parsed != *exceeded {
+		t.Fatalf("wire form did not round trip: %+v", parsed)
+	}
+
+	// A job may tighten the caps but not loosen them
+	coord.config.Sandbox.Fuel = 1000
+	limits := coord.sandboxLimits(&foundation.Job{Parameters: map[string]interface{}{JobParamFuelLimit: float64(10)}})
+	if limits.Fuel != 10 {
+		t.Fatalf("expected the job's lower fuel limit, got %d", limits.Fuel)
+	}
+	limits = coord.sandboxLimits(&foundation.Job{Parameters: map[string]interface{}{JobParamFuelLimit: float64(1e6)}})
+	if limits.Fuel != 1000 {
+		t.Fatalf("expected the configured cap, got %d", limits.Fuel)
+	}
+}
+
+func TestSandbox_PulledWorkRunsSandboxed(t *testing.T) {
+	coord, tr := newDelegationTestCoordinator(t)
+	coord.config.WorkQueue.Enabled = true
+	coord.config.Sandbox.MemoryBytes = 1 << 20
+	var seen map[string]interface{}
+	coord.SetDispatcher(&mockDispatcher{
+		run: func(job *foundation.Job) *foundation.Result {
+			seen = job.Parameters
+			return &foundation.Result{JobID: job.ID, Success: true, Metrics: &foundation.ExecutionMetrics{MemoryUsed: 2 << 20}}
+		},
+	})
+
+	capability := common.CapabilityFromContext(capabilityContextFor(t, coord, "peer-1", executeJobMethod))
+	tr.rpcHandlers[workClaimMethod] = func(args interface{}) (interface{}, error) {
+		return &workJob{ID: args.(workClaimRequest).JobID, Operation: "noop", Capability: capability}, nil
+	}
+	completed := make(chan workCompletion, 1)
+	tr.rpcHandlers[workCompleteMethod] = func(args interface{}) (interface{}, error) {
+		completed <- args.(workCompletion)
+		return workCompletionAck{Accepted: true}, nil
+	}
+	before, _ := coord.reputation.GetTrustScore("peer-1")
+
+	coord.handleWorkAnnouncement(WorkAnnouncement{JobID: "greedy", Requester: "peer-1"})
+	var completion workCompletion
+	select {
+	case completion = <-completed:
+	case <-time.After(time.Second):
+		t.Fatal("pulled job never completed")
+	}
+	exceeded, ok := ParseResourceExceeded(completion.Error)
+	if completion.Success || !ok || exceeded.Resource != SandboxResourceMemory {
+		t.Fatalf("expected a memory limit error, got %+v", completion)
+	}
+	if seen[JobParamMemoryLimit] != uint64(1<<20) || seen[JobParamFuelLimit] == nil {
+		t.Fatalf("sandbox limits not passed to the runtime: %v", seen)
+	}
+	if after, _ := coord.reputation.GetTrustScore("peer-1"); after >= before {
+		t.Fatalf("expected the requester to be penalized: %v -> %v", before, after)
+	}
+}
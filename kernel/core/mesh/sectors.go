@@ -0,0 +1,279 @@
+package mesh
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// sectorCount bounds sector IDs to 0..255, the range the UI map draws.
+const sectorCount = 256
+
+// RegionCode is a hierarchical region such as "eu/west/paris", broadest
+// level first. Parse free-form region strings with ParseRegion.
+type RegionCode string
+
+// ParseRegion normalizes a region string: levels may be separated by '/',
+// '-', '_', '.' or spaces, and case is ignored, so "EU-West" and "eu/west"
+// are the same region.
+func ParseRegion(s string) RegionCode {
+	levels := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return r == '/' || r == '-' || r == '_' || r == '.' || r == ' '
+	})
+	return RegionCode(strings.Join(levels, "/"))
+}
+
+// Levels returns the region's levels, broadest first.
+func (r RegionCode) Levels() []string {
+	if r == "" {
+		return nil
+	}
+	return strings.Split(string(r), "/")
+}
+
+// Parent returns the region one level up; the parent of a top-level region
+// is empty.
+func (r RegionCode) Parent() RegionCode {
+	i := strings.LastIndexByte(string(r), '/')
+	if i < 0 {
+		return ""
+	}
+	return r[:i]
+}
+
+// CommonLevels returns how many leading levels r and other share.
+func (r RegionCode) CommonLevels(other RegionCode) int {
+	a, b := r.Levels(), other.Levels()
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n
+}
+
+// commonRegion returns the deepest region containing both a and b.
+func commonRegion(a, b RegionCode) RegionCode {
+	return RegionCode(strings.Join(a.Levels()[:a.CommonLevels(b)], "/"))
+}
+
+// regionProximity scores how close two regions are from 0.1 (nothing in
+// common) to 1.0 (the same region), by the share of levels they have in
+// common.
+func regionProximity(a, b RegionCode) float32 {
+	if a == b {
+		return 1.0
+	}
+	common := a.CommonLevels(b)
+	if common == 0 {
+		return 0.1
+	}
+	depth := max(len(a.Levels()), len(b.Levels()))
+	return 0.1 + 0.8*float32(common)/float32(depth)
+}
+
+// homeSector is the sector a node founds before it has measured anyone: a
+// hash of its node ID. Peers that do not advertise a sector are placed in
+// theirs.
+func homeSector(nodeID string) uint32 {
+	hash := 0
+	for _, c := range nodeID {
+		hash = (hash * 31) + int(c)
+	}
+	return uint32((hash & 0x7FFFFFFF) % sectorCount)
+}
+
+// SectorInfo describes one sector as seen from this node.
+type SectorInfo struct {
+	ID              uint32  `json:"id"`
+	Members         int     `json:"members"`           // Known peers in the sector, and this node if Local
+	Region          string  `json:"region,omitempty"`  // Deepest region containing every member that reports one
+	MedianLatencyMs float32 `json:"median_latency_ms"` // Over members with a measured round trip; 0 if none
+	Measured        int     `json:"measured"`          // Members with a measured round trip
+	Local           bool    `json:"local"`             // This node's sector
+}
+
+// chooseSector picks a node's next sector from the measured round trips to
+// the members of each sector. A node leaves its sector for its home sector
+// once the sector's members are no longer near, and joins the lowest
+// numbered sector whose members are, so nearby nodes settle on the same
+// sector without coordinating.
+func chooseSector(current, home uint32, latencies map[uint32][]time.Duration, join time.Duration, leaveFactor float64, minSamples int) uint32 {
+	if current != home {
+		samples := latencies[current]
+		if len(samples) == 0 || float64(medianDuration(samples)) > float64(join)*leaveFactor {
+			current = home
+		}
+	}
+
+	best := current
+	for sector, samples := range latencies {
+		if sector < best && len(samples) >= max(minSamples, 1) && medianDuration(samples) <= join {
+			best = sector
+		}
+	}
+	return best
+}
+
+// GetSectorID returns the sector this node is in.
+func (m *MeshCoordinator) GetSectorID() int {
+	return int(m.sector.Load())
+}
+
+// peerSector returns the sector peerID advertised in its metrics.
+func (m *MeshCoordinator) peerSector(peerID string) (uint32, bool) {
+	m.peerMetricsMu.RLock()
+	defer m.peerMetricsMu.RUnlock()
+	metrics, ok := m.peerMetrics[peerID]
+	return metrics.SectorID, ok
+}
+
+// inSector reports whether peerID is in this node's sector.
+func (m *MeshCoordinator) inSector(peerID string) bool {
+	sector, ok := m.peerSector(peerID)
+	return ok && sector == m.sector.Load()
+}
+
+// sectorAffinity scales score up for peers in this node's sector, so chunk
+// placement and delegation stay near when a near peer is as good.
+func (m *MeshCoordinator) sectorAffinity(peerID string, score float32) float32 {
+	if affinity := m.config.Sectors.Affinity; affinity > 0 && m.inSector(peerID) {
+		return score * affinity
+	}
+	return score
+}
+
+// sectorMembers groups known peers by the sector they advertise.
+func (m *MeshCoordinator) sectorMembers() map[uint32][]string {
+	m.peerMetricsMu.RLock()
+	defer m.peerMetricsMu.RUnlock()
+
+	members := make(map[uint32][]string)
+	for peerID, metrics := range m.peerMetrics {
+		if metrics.Synthetic || m.isPeerQuarantined(peerID) {
+			continue
+		}
+		members[metrics.SectorID] = append(members[metrics.SectorID], peerID)
+	}
+	return members
+}
+
+// sectorLatencies returns the measured round trips to the members of each
+// sector; members without a measurement are left out.
+func (m *MeshCoordinator) sectorLatencies(members map[uint32][]string) map[uint32][]time.Duration {
+	latencies := make(map[uint32][]time.Duration, len(members))
+	for sector, peers := range members {
+		for _, peerID := range peers {
+			if latency, ok := m.peerLatency(peerID); ok {
+				latencies[sector] = append(latencies[sector], latency)
+			}
+		}
+	}
+	return latencies
+}
+
+// reassignSector moves this node to the sector its measured round trips
+// put it in, and reports whether it moved.
+func (m *MeshCoordinator) reassignSector() bool {
+	config := m.config.Sectors
+	current := m.sector.Load()
+	next := chooseSector(current, homeSector(m.nodeID), m.sectorLatencies(m.sectorMembers()),
+		config.JoinLatency, config.LeaveFactor, config.MinSamples)
+	if next == current || !m.sector.CompareAndSwap(current, next) {
+		return false
+	}
+
+	m.gossip.SetSector(next)
+	m.logger.Info("sector changed", "from", current, "to", next)
+	m.publishEvent(MeshEventSectorChanged, "", map[string]interface{}{
+		"sector_id": next,
+		"previous":  current,
+	})
+	return true
+}
+
+func (m *MeshCoordinator) sectorLoop() {
+	if m.config.Sectors.ReassignInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(m.config.Sectors.ReassignInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			m.reassignSector()
+		case <-m.shutdown:
+			return
+		}
+	}
+}
+
+// GetSectors returns every sector this node knows members of, its own
+// included, ordered by ID.
+func (m *MeshCoordinator) GetSectors() []SectorInfo {
+	own := m.sector.Load()
+	members := m.sectorMembers()
+	if _, ok := members[own]; !ok {
+		members[own] = nil
+	}
+	latencies := m.sectorLatencies(members)
+
+	regions := make(map[string]RegionCode)
+	m.peerCacheMu.RLock()
+	for _, peers := range members {
+		for _, peerID := range peers {
+			if entry, ok := m.peerCache[peerID]; ok && entry.Capability != nil && entry.Capability.Region != "" {
+				regions[peerID] = ParseRegion(entry.Capability.Region)
+			}
+		}
+	}
+	m.peerCacheMu.RUnlock()
+
+	sectors := make([]SectorInfo, 0, len(members))
+	for id, peers := range members {
+		info := SectorInfo{ID: id, Members: len(peers), Local: id == own}
+		var region RegionCode
+		seen := false
+		if info.Local {
+			info.Members++
+			if m.region != "" {
+				region, seen = ParseRegion(m.region), true
+			}
+		}
+		for _, peerID := range peers {
+			if code, ok := regions[peerID]; ok {
+				if seen {
+					region = commonRegion(region, code)
+				} else {
+					region, seen = code, true
+				}
+			}
+		}
+		info.Region = string(region)
+		if samples := latencies[id]; len(samples) > 0 {
+			info.Measured = len(samples)
+			info.MedianLatencyMs = float32(medianDuration(samples)) / float32(time.Millisecond)
+		}
+		sectors = append(sectors, info)
+	}
+	slices.SortFunc(sectors, func(a, b SectorInfo) int { return int(a.ID) - int(b.ID) })
+	return sectors
+}
+
+// sectorTelemetry flattens sectors into the plain maps and slices the JS
+// bridge accepts.
+func sectorTelemetry(sectors []SectorInfo) []interface{} {
+	out := make([]interface{}, len(sectors))
+	for i, s := range sectors {
+		out[i] = map[string]interface{}{
+			"id":                int(s.ID),
+			"members":           s.Members,
+			"region":            s.Region,
+			"median_latency_ms": s.MedianLatencyMs,
+			"measured":          s.Measured,
+			"local":             s.Local,
+		}
+	}
+	return out
+}
@@ -0,0 +1,100 @@
+package mesh
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+)
+
+func TestRegionCode_Hierarchy(t *testing.T) {
+	region := ParseRegion(" EU-West_Paris ")
+	if region != "eu/west/paris" {
+		t.Fatalf("unexpected parsed region %q", region)
+	}
+	if region.Parent() != "eu/west" || region.Parent().Parent().Parent() != "" {
+		t.Fatalf("unexpected parents of %q", region)
+	}
+	if n := region.CommonLevels(ParseRegion("eu/west/london")); n != 2 {
+		t.Fatalf("expected 2 common levels, got %d", n)
+	}
+
+	same := regionProximity(region, ParseRegion("eu.west.paris"))
+	sibling := regionProximity(region, ParseRegion("eu-west-london"))
+	continent := regionProximity(region, ParseRegion("eu-central"))
+	other := regionProximity(region, ParseRegion("us-east"))
+	if !(same == 1 && same > sibling && sibling > continent && continent > other) {
+		t.Fatalf("expected proximity to fall with shared levels, got %v %v %v %v", same, sibling, continent, other)
+	}
+}
+
+func TestChooseSector_JoinsNearAndLeavesFar(t *testing.T) {
+	join := 40 * time.Millisecond
+	near := []time.Duration{10 * time.Millisecond, 20 * time.Millisecond}
+	far := []time.Duration{150 * time.Millisecond}
+
+	latencies := map[uint32][]time.Duration{3: far, 7: near, 9: near}
+	if got := chooseSector(12, 12, latencies, join, 2, 1); got != 7 {
+		t.Fatalf("expected the lowest near sector, got %d", got)
+	}
+	if got := chooseSector(7, 12, latencies, join, 2, 3); got != 7 {
+		t.Fatalf("expected to stay without enough samples elsewhere, got %d", got)
+	}
+
+	// Drifting a little past the join latency is not enough to leave
+	drifted := map[uint32][]time.Duration{7: {60 * time.Millisecond}}
+	if got := chooseSector(7, 12, drifted, join, 2, 1); got != 7 {
+		t.Fatalf("expected to stay in a slightly slower sector, got %d", got)
+	}
+	if got := chooseSector(7, 12, map[uint32][]time.Duration{7: far}, join, 2, 1); got != 12 {
+		t.Fatalf("expected to fall back to the home sector, got %d", got)
+	}
+}
+
+func TestMeshCoordinator_SectorAssignmentAndAffinity(t *testing.T) {
+	tr := &measuringTransport{
+		MockTransport: &MockTransport{nodeID: "self"},
+		latency: map[string]time.Duration{
+			"near-a": 8 * time.Millisecond,
+			"near-b": 12 * time.Millisecond,
+			"far":    180 * time.Millisecond,
+		},
+	}
+	coord := NewMeshCoordinator("self", "eu-west-paris", tr, nil)
+	if coord.GetSectorID() != int(homeSector("self")) {
+		t.Fatalf("expected the home sector before any measurement, got %d", coord.GetSectorID())
+	}
+
+	coord.peerMetrics["near-a"] = common.MeshMetrics{SectorID: 4, AvgReputation: 0.8, P50LatencyMs: 10}
+	coord.peerMetrics["near-b"] = common.MeshMetrics{SectorID: 4, AvgReputation: 0.8, P50LatencyMs: 10}
+	coord.peerMetrics["far"] = common.MeshMetrics{SectorID: 1, AvgReputation: 0.8, P50LatencyMs: 10}
+	coord.cachePeer("near-a", &PeerCapability{PeerID: "near-a", Region: "eu-west-london"})
+	coord.cachePeer("near-b", &PeerCapability{PeerID: "near-b", Region: "eu-west-paris"})
+
+	if !coord.reassignSector() || coord.GetSectorID() != 4 {
+		t.Fatalf("expected to join the near sector, got %d", coord.GetSectorID())
+	}
+	if coord.reassignSector() {
+		t.Fatal("expected the assignment to be stable")
+	}
+
+	var local SectorInfo
+	for _, sector := range coord.GetSectors() {
+		if sector.Local {
+			local = sector
+		}
+	}
+	if local.ID != 4 || local.Members != 3 || local.Region != "eu/west" || local.Measured != 2 {
+		t.Fatalf("unexpected local sector %+v", local)
+	}
+	telemetry := coord.GetTelemetry()
+	if telemetry["sector_id"] != 4 || telemetry["sector_members"] != 3 {
+		t.Fatalf("unexpected sector telemetry %v %v", telemetry["sector_id"], telemetry["sector_members"])
+	}
+
+	// Otherwise equal peers: the same-sector one wins delegation
+	delete(coord.peerMetrics, "near-b")
+	if best, _ := coord.selectBestPeerForJob(); best != "near-a" {
+		t.Fatalf("expected the same-sector peer, got %q", best)
+	}
+}
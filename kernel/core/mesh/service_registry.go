@@ -0,0 +1,332 @@
+package mesh
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+const serviceInfoMethod = "mesh.ServiceInfo"
+
+// ErrServiceNotFound is returned when no healthy provider of a service is known.
+var ErrServiceNotFound = errors.New("service not found")
+
+// ServiceRegistration describes a named service this node offers, e.g. a
+// "transcoder" with {"codecs": "h264,vp9"}. Providers are advertised in the
+// DHT under a key derived from the name.
+type ServiceRegistration struct {
+	Name         string            `json:"name"`
+	Metadata     map[string]string `json:"metadata,omitempty"`
+	Capabilities []string          `json:"capabilities,omitempty"`
+	TTL          time.Duration     `json:"ttl"` // 0 uses the configured default
+
+	// Health, when set, is checked before every refresh. A failing check
+	// withdraws the DHT record until the service passes again.
+	Health func(ctx context.Context) error `json:"-"`
+}
+
+// ServiceEndpoint is one provider a service name resolved to.
+type ServiceEndpoint struct {
+	PeerID       string            `json:"peer_id"`
+	Name         string            `json:"name"`
+	Metadata     map[string]string `json:"metadata,omitempty"`
+	Capabilities []string          `json:"capabilities,omitempty"`
+	Score        float32           `json:"score"`
+	LatencyMs    float32           `json:"latency_ms"`
+}
+
+// ServiceQuery selects providers of a service. Metadata entries must match
+// exactly; Limit caps the result (0 means the configured maximum).
+type ServiceQuery struct {
+	Name     string
+	Metadata map[string]string
+	Limit    int
+}
+
+// LocalService reports a registration and its health on this node.
+type LocalService struct {
+	Name        string            `json:"name"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+	Healthy     bool              `json:"healthy"`
+	LastError   string            `json:"last_error,omitempty"`
+	RefreshedAt time.Time         `json:"refreshed_at"`
+	ExpiresAt   time.Time         `json:"expires_at"`
+}
+
+type localService struct {
+	reg         ServiceRegistration
+	healthy     bool
+	lastError   string
+	refreshedAt time.Time
+}
+
+type serviceInfoRequest struct {
+	Name string `json:"name"`
+}
+
+type serviceInfoResponse struct {
+	Registered   bool              `json:"registered"`
+	Healthy      bool              `json:"healthy"`
+	Metadata     map[string]string `json:"metadata,omitempty"`
+	Capabilities []string          `json:"capabilities,omitempty"`
+}
+
+// serviceKey maps a service name into the DHT keyspace alongside chunk hashes.
+func (m *MeshCoordinator) serviceKey(name string) string {
+	return m.computeResourceDigest([]byte("inos-service:" + name))
+}
+
+// RegisterService advertises a service from this node. Registering a name
+// again replaces its metadata. The record is refreshed until
+// UnregisterService, and withdrawn while the health check fails.
+func (m *MeshCoordinator) RegisterService(reg ServiceRegistration) error {
+	if reg.Name == "" {
+		return errors.New("service name is required")
+	}
+	if reg.TTL <= 0 {
+		reg.TTL = m.config.Services.DefaultTTL
+	}
+
+	svc := &localService{reg: reg, healthy: true}
+	m.servicesMu.Lock()
+	m.services[reg.Name] = svc
+	m.servicesMu.Unlock()
+
+	m.refreshService(context.Background(), svc)
+	m.logger.Info("registered service", "service", reg.Name, "ttl", reg.TTL)
+	return nil
+}
+
+// UnregisterService stops advertising a service and withdraws its record.
+func (m *MeshCoordinator) UnregisterService(name string) error {
+	m.servicesMu.Lock()
+	_, ok := m.services[name]
+	delete(m.services, name)
+	m.servicesMu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("service %q: %w", name, ErrServiceNotFound)
+	}
+	return m.dht.RemoveChunkPeer(m.serviceKey(name), m.nodeID)
+}
+
+// GetLocalServices lists the services this node registered.
+func (m *MeshCoordinator) GetLocalServices() []LocalService {
+	m.servicesMu.Lock()
+	out := make([]LocalService, 0, len(m.services))
+	for _, svc := range m.services {
+		out = append(out, LocalService{
+			Name:        svc.reg.Name,
+			Metadata:    svc.reg.Metadata,
+			Healthy:     svc.healthy,
+			LastError:   svc.lastError,
+			RefreshedAt: svc.refreshedAt,
+			ExpiresAt:   svc.refreshedAt.Add(svc.reg.TTL),
+		})
+	}
+	m.servicesMu.Unlock()
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// ResolveService finds providers of a service, confirms each is still
+// serving it and ranks them with the same peer scoring used for chunks and
+// compute. Providers that no longer answer for the service are dropped from
+// the local DHT view.
+func (m *MeshCoordinator) ResolveService(ctx context.Context, query ServiceQuery) ([]ServiceEndpoint, error) {
+	if query.Name == "" {
+		return nil, errors.New("service name is required")
+	}
+	key := m.serviceKey(query.Name)
+	providers, err := m.dht.FindPeers(key)
+	if err != nil || len(providers) == 0 {
+		return nil, fmt.Errorf("service %q: %w", query.Name, ErrServiceNotFound)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, m.config.Services.ResolveTimeout)
+	defer cancel()
+
+	var (
+		mu        sync.Mutex
+		wg        sync.WaitGroup
+		endpoints []ServiceEndpoint
+	)
+	for _, peerID := range providers {
+		if m.isPeerQuarantined(peerID) {
+			continue
+		}
+		wg.Add(1)
+		go func(peerID string) {
+			defer wg.Done()
+			info, err := m.serviceInfo(ctx, peerID, query.Name)
+			if err != nil || !info.Registered || !info.Healthy {
+				if peerID != m.nodeID && (err == nil || ctx.Err() == nil) {
+					_ = m.dht.RemoveChunkPeer(key, peerID)
+				}
+				return
+			}
+			if !metadataMatches(info.Metadata, query.Metadata) {
+				return
+			}
+
+			endpoint := ServiceEndpoint{
+				PeerID:       peerID,
+				Name:         query.Name,
+				Metadata:     info.Metadata,
+				Capabilities: info.Capabilities,
+			}
+			if capability := m.getCachedPeer(peerID); capability != nil {
+				endpoint.Score = m.calculatePeerScore(capability)
+				endpoint.LatencyMs = capability.LatencyMs
+			}
+			mu.Lock()
+			endpoints = append(endpoints, endpoint)
+			mu.Unlock()
+		}(peerID)
+	}
+	wg.Wait()
+
+	if len(endpoints) == 0 {
+		return nil, fmt.Errorf("service %q: %w", query.Name, ErrServiceNotFound)
+	}
+	sort.Slice(endpoints, func(i, j int) bool {
+		if endpoints[i].Score != endpoints[j].Score {
+			return endpoints[i].Score > endpoints[j].Score
+		}
+		return endpoints[i].PeerID < endpoints[j].PeerID
+	})
+
+	limit := query.Limit
+	if limit <= 0 || limit > m.config.Services.MaxResults {
+		limit = m.config.Services.MaxResults
+	}
+	if limit > 0 && len(endpoints) > limit {
+		endpoints = endpoints[:limit]
+	}
+	return endpoints, nil
+}
+
+// serviceInfo asks a provider about a service; this node answers locally.
+func (m *MeshCoordinator) serviceInfo(ctx context.Context, peerID, name string) (serviceInfoResponse, error) {
+	if peerID == m.nodeID {
+		return m.localServiceInfo(name), nil
+	}
+	var resp serviceInfoResponse
+	if err := m.transport.SendRPC(ctx, peerID, serviceInfoMethod, serviceInfoRequest{Name: name}, &resp); err != nil {
+		m.recordRPCFailure(peerID, serviceInfoMethod, err)
+		return serviceInfoResponse{}, err
+	}
+	return resp, nil
+}
+
+func (m *MeshCoordinator) localServiceInfo(name string) serviceInfoResponse {
+	m.servicesMu.Lock()
+	defer m.servicesMu.Unlock()
+	svc, ok := m.services[name]
+	if !ok {
+		return serviceInfoResponse{}
+	}
+	return serviceInfoResponse{
+		Registered:   true,
+		Healthy:      svc.healthy,
+		Metadata:     svc.reg.Metadata,
+		Capabilities: svc.reg.Capabilities,
+	}
+}
+
+// refreshService runs the health check and republishes or withdraws the
+// service's DHT record accordingly.
+func (m *MeshCoordinator) refreshService(ctx context.Context, svc *localService) {
+	var healthErr error
+	if svc.reg.Health != nil {
+		hctx, cancel := context.WithTimeout(ctx, m.config.Services.HealthTimeout)
+		healthErr = svc.reg.Health(hctx)
+		cancel()
+	}
+
+	key := m.serviceKey(svc.reg.Name)
+	m.servicesMu.Lock()
+	wasHealthy := svc.healthy
+	svc.healthy = healthErr == nil
+	svc.lastError = ""
+	if healthErr != nil {
+		svc.lastError = healthErr.Error()
+	} else {
+		svc.refreshedAt = time.Now()
+	}
+	m.servicesMu.Unlock()
+
+	if healthErr != nil {
+		_ = m.dht.RemoveChunkPeer(key, m.nodeID)
+		if wasHealthy {
+			m.logger.Warn("service failed health check, withdrawn", "service", svc.reg.Name, "error", healthErr)
+		}
+		return
+	}
+	if !wasHealthy {
+		m.logger.Info("service healthy again, re-advertised", "service", svc.reg.Name)
+	}
+	if err := m.dht.Store(key, m.nodeID, int64(svc.reg.TTL/time.Second)); err != nil {
+		m.logger.Debug("failed to advertise service", "service", svc.reg.Name, "error", err)
+	}
+}
+
+// refreshServices re-checks every registration that is due: failing ones
+// each pass, healthy ones once half their TTL has passed.
+func (m *MeshCoordinator) refreshServices(ctx context.Context, now time.Time) int {
+	m.servicesMu.Lock()
+	due := make([]*localService, 0, len(m.services))
+	for _, svc := range m.services {
+		if !svc.healthy || svc.reg.Health != nil || now.Sub(svc.refreshedAt) >= svc.reg.TTL/2 {
+			due = append(due, svc)
+		}
+	}
+	m.servicesMu.Unlock()
+
+	for _, svc := range due {
+		m.refreshService(ctx, svc)
+	}
+	return len(due)
+}
+
+func (m *MeshCoordinator) serviceRefreshLoop() {
+	if m.config.Services.CheckInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(m.config.Services.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			m.refreshServices(context.Background(), now)
+		case <-m.shutdown:
+			return
+		}
+	}
+}
+
+func (m *MeshCoordinator) registerServiceHandler() {
+	m.registerRPC(serviceInfoMethod, func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error) {
+		var req serviceInfoRequest
+		if err := json.Unmarshal(args, &req); err != nil {
+			return nil, fmt.Errorf("failed to decode service info request: %w", err)
+		}
+		return m.localServiceInfo(req.Name), nil
+	})
+}
+
+func metadataMatches(have, want map[string]string) bool {
+	for k, v := range want {
+		if have[k] != v {
+			return false
+		}
+	}
+	return true
+}
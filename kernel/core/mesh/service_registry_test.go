@@ -0,0 +1,92 @@
+package mesh
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestServiceRegistry_ResolveRanksLiveProviders(t *testing.T) {
+	tr := &MockTransport{
+		nodeID:      "client",
+		rpcHandlers: make(map[string]func(args interface{}) (interface{}, error)),
+		rpcFailures: map[string]error{serviceInfoMethod + "@gone": errors.New("unreachable")},
+	}
+	coord := NewMeshCoordinator("client", "us-east", tr, nil)
+	tr.rpcHandlers[serviceInfoMethod] = func(args interface{}) (interface{}, error) {
+		return serviceInfoResponse{Registered: true, Healthy: true, Metadata: map[string]string{"codec": "h264"}}, nil
+	}
+
+	key := coord.serviceKey("transcoder")
+	now := time.Now().UnixNano()
+	for id, latency := range map[string]float32{"near": 10, "far": 400, "gone": 10} {
+		_ = coord.dht.Store(key, id, 600)
+		coord.cachePeer(id, &PeerCapability{PeerID: id, Region: "us-east", LatencyMs: latency, LastSeen: now})
+	}
+
+	endpoints, err := coord.ResolveService(context.Background(), ServiceQuery{Name: "transcoder"})
+	if err != nil {
+		t.Fatalf("ResolveService failed: %v", err)
+	}
+	if len(endpoints) != 2 || endpoints[0].PeerID != "near" || endpoints[1].PeerID != "far" {
+		t.Fatalf("expected near ranked over far, got %+v", endpoints)
+	}
+	if endpoints[0].Metadata["codec"] != "h264" {
+		t.Fatalf("expected provider metadata, got %+v", endpoints[0].Metadata)
+	}
+	for _, p := range coord.dht.LocalProviders(key) {
+		if p == "gone" {
+			t.Fatal("expected the unreachable provider to be dropped")
+		}
+	}
+
+	if _, err := coord.ResolveService(context.Background(), ServiceQuery{Name: "transcoder", Metadata: map[string]string{"codec": "av1"}}); !errors.Is(err, ErrServiceNotFound) {
+		t.Fatalf("expected no av1 transcoder, got %v", err)
+	}
+}
+
+func TestServiceRegistry_HealthCheckWithdrawsRecord(t *testing.T) {
+	coord := NewMeshCoordinator("self", "us-east", &MockTransport{nodeID: "self"}, nil)
+	healthy := true
+	err := coord.RegisterService(ServiceRegistration{
+		Name:     "inference",
+		Metadata: map[string]string{"model": "small"},
+		Health: func(context.Context) error {
+			if !healthy {
+				return errors.New("model unloaded")
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("RegisterService failed: %v", err)
+	}
+
+	endpoints, err := coord.ResolveService(context.Background(), ServiceQuery{Name: "inference"})
+	if err != nil || len(endpoints) != 1 || endpoints[0].PeerID != "self" {
+		t.Fatalf("expected to resolve the local registration, got %+v %v", endpoints, err)
+	}
+
+	healthy = false
+	coord.refreshServices(context.Background(), time.Now())
+	if providers := coord.dht.LocalProviders(coord.serviceKey("inference")); len(providers) != 0 {
+		t.Fatalf("expected record withdrawn, providers=%v", providers)
+	}
+	if services := coord.GetLocalServices(); len(services) != 1 || services[0].Healthy || services[0].LastError == "" {
+		t.Fatalf("expected unhealthy local service, got %+v", services)
+	}
+
+	healthy = true
+	coord.refreshServices(context.Background(), time.Now())
+	if providers := coord.dht.LocalProviders(coord.serviceKey("inference")); len(providers) != 1 {
+		t.Fatalf("expected record re-advertised, providers=%v", providers)
+	}
+
+	if err := coord.UnregisterService("inference"); err != nil {
+		t.Fatalf("UnregisterService failed: %v", err)
+	}
+	if _, err := coord.ResolveService(context.Background(), ServiceQuery{Name: "inference"}); !errors.Is(err, ErrServiceNotFound) {
+		t.Fatalf("expected ErrServiceNotFound after unregister, got %v", err)
+	}
+}
@@ -0,0 +1,168 @@
+package mesh
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/nmxmxh/inos_v1/kernel/threads/sab"
+)
+
+// ErrRegionFull is returned when assembled output outgrows its SAB region.
+var ErrRegionFull = errors.New("sab region full")
+
+// ShardInput is one slice of a job split across peers. Digest names the
+// input chunk the executor verifies, as for DelegateCompute.
+type ShardInput struct {
+	Digest string
+	Data   []byte
+}
+
+// DelegateShards delegates each shard in parallel and streams the verified
+// results to w in index order. At most ShardAssembly.MaxPendingShards
+// finished results wait in memory for an earlier shard; past that, no new
+// shard is dispatched until the gap closes. The first failure cancels the
+// outstanding shards and is returned with the bytes already written.
+func (m *MeshCoordinator) DelegateShards(ctx context.Context, operation string, shards []ShardInput, w io.Writer) (int64, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	parallel := max(m.config.ShardAssembly.Parallelism, 1)
+	asm := newShardAssembler(w, max(m.config.ShardAssembly.MaxPendingShards, parallel))
+	stop := context.AfterFunc(ctx, func() { asm.fail(ctx.Err()) })
+	defer stop()
+
+	slots := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+	for i := range shards {
+		if asm.reserve(i) != nil {
+			break
+		}
+		slots <- struct{}{}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			out, err := m.delegateCompute(ctx, operation, shards[i].Digest, shards[i].Data)
+			if err != nil {
+				asm.fail(fmt.Errorf("shard %d: %w", i, err))
+				cancel()
+				return
+			}
+			if err := asm.deliver(i, out); err != nil {
+				cancel()
+			}
+		}(i)
+	}
+	wg.Wait()
+	return asm.result()
+}
+
+// shardAssembler writes shard results in index order as they complete.
+type shardAssembler struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	w       io.Writer
+	window  int
+	next    int // Lowest index not yet written
+	pending map[int][]byte
+	written int64
+	err     error
+}
+
+func newShardAssembler(w io.Writer, window int) *shardAssembler {
+	a := &shardAssembler{w: w, window: window, pending: make(map[int][]byte)}
+	a.cond = sync.NewCond(&a.mu)
+	return a
+}
+
+// reserve blocks until shard i is within the window of the next shard to
+// write, or the assembly has failed.
+func (a *shardAssembler) reserve(i int) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for a.err == nil && i >= a.next+a.window {
+		a.cond.Wait()
+	}
+	return a.err
+}
+
+// deliver hands over shard i's result and writes out every shard that is
+// now contiguous with what has been written.
+func (a *shardAssembler) deliver(i int, data []byte) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.err != nil {
+		return a.err
+	}
+	a.pending[i] = data
+	for {
+		out, ok := a.pending[a.next]
+		if !ok {
+			break
+		}
+		delete(a.pending, a.next)
+		n, err := a.w.Write(out)
+		a.written += int64(n)
+		if err != nil {
+			a.err = fmt.Errorf("shard %d: write: %w", a.next, err)
+			break
+		}
+		a.next++
+	}
+	a.cond.Broadcast()
+	return a.err
+}
+
+// fail records the first error and wakes a dispatcher waiting in reserve.
+func (a *shardAssembler) fail(err error) {
+	a.mu.Lock()
+	if a.err == nil {
+		a.err = err
+		a.pending = nil
+	}
+	a.cond.Broadcast()
+	a.mu.Unlock()
+}
+
+func (a *shardAssembler) result() (int64, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.written, a.err
+}
+
+// SABRegionWriter is an io.Writer over a pre-allocated SAB region, so
+// assembled results land where WASM modules can read them in place.
+type SABRegionWriter struct {
+	bridge SABWriter
+	region sab.DynamicRegion
+	offset uint32
+}
+
+// NewSABRegionWriter writes from the start of region.
+func NewSABRegionWriter(bridge SABWriter, region sab.DynamicRegion) *SABRegionWriter {
+	return &SABRegionWriter{bridge: bridge, region: region}
+}
+
+// Write copies p into the region after what has been written so far. A
+// write that does not fit writes nothing and returns ErrRegionFull.
+func (s *SABRegionWriter) Write(p []byte) (int, error) {
+	if uint64(s.offset)+uint64(len(p)) > uint64(s.region.Size) {
+		return 0, ErrRegionFull
+	}
+	if err := s.bridge.WriteRaw(s.region.Offset+s.offset, p); err != nil {
+		return 0, err
+	}
+	s.offset += uint32(len(p))
+	return len(p), nil
+}
+
+// Written is the number of bytes written into the region.
+func (s *SABRegionWriter) Written() uint32 {
+	return s.offset
+}
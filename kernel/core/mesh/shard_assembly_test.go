@@ -0,0 +1,97 @@
+package mesh
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/threads/foundation"
+)
+
+func shardInputs(n int) []ShardInput {
+	shards := make([]ShardInput, n)
+	for i := range shards {
+		shards[i] = ShardInput{Digest: fmt.Sprintf("shard-%d", i), Data: []byte(fmt.Sprintf("[%d]", i))}
+	}
+	return shards
+}
+
+func TestDelegateShards_StreamsInOrderWithinWindow(t *testing.T) {
+	coord, _ := newDelegationTestCoordinator(t)
+	coord.config.ShardAssembly.Parallelism = 2
+	coord.config.ShardAssembly.MaxPendingShards = 4
+
+	release := make(chan struct{})
+	var started atomic.Int32
+	coord.SetDispatcher(&mockDispatcher{
+		run: func(job *foundation.Job) *foundation.Result {
+			started.Add(1)
+			if string(job.Data) == "[0]" {
+				<-release
+			}
+			return &foundation.Result{JobID: job.ID, Success: true, Data: job.Data}
+		},
+	})
+
+	var out bytes.Buffer
+	done := make(chan error, 1)
+	var written int64
+	go func() {
+		var err error
+		written, err = coord.DelegateShards(context.Background(), "compress", shardInputs(10), &out)
+		done <- err
+	}()
+
+	// With shard 0 outstanding only the window's worth is dispatched
+	time.Sleep(50 * time.Millisecond)
+	if n := started.Load(); n != 4 {
+		t.Fatalf("expected 4 shards dispatched behind a stalled first shard, got %d", n)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("DelegateShards failed: %v", err)
+	}
+	want := "[0][1][2][3][4][5][6][7][8][9]"
+	if out.String() != want || written != int64(len(want)) {
+		t.Fatalf("got %q (%d bytes)", out.String(), written)
+	}
+}
+
+func TestDelegateShards_FirstFailureStopsAssembly(t *testing.T) {
+	coord, _ := newDelegationTestCoordinator(t)
+	coord.config.ShardAssembly.Parallelism = 1
+	coord.SetDispatcher(&mockDispatcher{
+		run: func(job *foundation.Job) *foundation.Result {
+			if string(job.Data) == "[2]" {
+				return &foundation.Result{JobID: job.ID, Success: false, Error: "boom"}
+			}
+			return &foundation.Result{JobID: job.ID, Success: true, Data: job.Data}
+		},
+	})
+
+	var out bytes.Buffer
+	written, err := coord.DelegateShards(context.Background(), "compress", shardInputs(6), &out)
+	if err == nil || !strings.Contains(err.Error(), "shard 2") {
+		t.Fatalf("expected shard 2 to fail the assembly, got %v", err)
+	}
+	if out.String() != "[0][1]" || written != 6 {
+		t.Fatalf("expected only the shards before the failure, got %q", out.String())
+	}
+
+	// A region too small for the output fails on the shard that overflows it
+	bridge := newRegionSABBridge(8192)
+	allocated, _ := bridge.AllocateRegion("shards", 8, 0)
+	region := NewSABRegionWriter(bridge, allocated)
+	coord, _ = newDelegationTestCoordinator(t)
+	coord.SetDispatcher(&mockDispatcher{})
+	if _, err := coord.DelegateShards(context.Background(), "compress", shardInputs(3), region); err == nil || !strings.Contains(err.Error(), ErrRegionFull.Error()) {
+		t.Fatalf("expected the region to overflow, got %v", err)
+	}
+	if got := bridge.data[allocated.Offset : allocated.Offset+8]; region.Written() != 6 || string(got[:6]) != "[0][1]" {
+		t.Fatalf("unexpected region contents %q", got)
+	}
+}
@@ -0,0 +1,246 @@
+package mesh
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/transport"
+)
+
+// SimClusterConfig describes an in-process cluster of coordinators.
+type SimClusterConfig struct {
+	Nodes int                         // Nodes created by NewSimCluster
+	Link  transport.MemoryLinkProfile // Default link between any two nodes
+	Seed  int64                       // Seeds jitter and loss so runs repeat
+
+	// Configure runs on each new node before it starts, e.g. to set a
+	// dispatcher or adjust the coordinator's config.
+	Configure func(node *SimClusterNode)
+
+	Logger *slog.Logger
+}
+
+// SimClusterNode is one coordinator in a SimCluster.
+type SimClusterNode struct {
+	ID          string
+	Coordinator *MeshCoordinator
+	Transport   *transport.MemoryTransport
+	Storage     StorageProvider
+}
+
+// SimCluster runs several MeshCoordinators over a MemoryNetwork so
+// coordinator behaviour (churn, partitions, delegation under load) can be
+// exercised in one process without WebRTC.
+type SimCluster struct {
+	Network *transport.MemoryNetwork
+
+	config SimClusterConfig
+	logger *slog.Logger
+
+	mu      sync.Mutex
+	nodes   map[string]*SimClusterNode
+	order   []string
+	stopped map[string]bool
+	created int
+}
+
+// NewSimCluster creates config.Nodes coordinators. Nothing runs until Start.
+func NewSimCluster(config SimClusterConfig) *SimCluster {
+	logger := config.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	c := &SimCluster{
+		Network: transport.NewMemoryNetwork(config.Link, config.Seed),
+		config:  config,
+		logger:  logger,
+		nodes:   make(map[string]*SimClusterNode),
+		stopped: make(map[string]bool),
+	}
+	for i := 0; i < config.Nodes; i++ {
+		c.newNode()
+	}
+	return c
+}
+
+func (c *SimCluster) newNode() *SimClusterNode {
+	c.mu.Lock()
+	c.created++
+	id := fmt.Sprintf("node-%02d", c.created)
+	region := demoRegions[(c.created-1)%len(demoRegions)]
+	c.mu.Unlock()
+
+	tr := c.Network.NewTransport(id)
+	storage := newMemoryChunkStore()
+	coord := NewMeshCoordinator(id, region, tr, c.logger)
+	coord.SetStorage(storage)
+	coord.config.LocalDiscovery.Enabled = false
+	// Attestation needs a SAB bridge; Configure can install one and turn it back on.
+	coord.config.AttestationEnabled = false
+
+	node := &SimClusterNode{ID: id, Coordinator: coord, Transport: tr, Storage: storage}
+	if c.config.Configure != nil {
+		c.config.Configure(node)
+	}
+
+	c.mu.Lock()
+	// Node IDs here are not derived from keys and attestation is off, so the
+	// cluster vouches for each node's key the way attestation would
+	for _, other := range c.nodes {
+		other.Coordinator.gossip.SetPeerIdentityKey(id, coord.gossip.PublicKey())
+		coord.gossip.SetPeerIdentityKey(other.ID, other.Coordinator.gossip.PublicKey())
+	}
+	c.nodes[id] = node
+	c.order = append(c.order, id)
+	c.mu.Unlock()
+	return node
+}
+
+// Start starts every node and connects each pair.
+func (c *SimCluster) Start(ctx context.Context) error {
+	for _, node := range c.Nodes() {
+		if err := node.Coordinator.Start(ctx); err != nil {
+			return fmt.Errorf("%s: %w", node.ID, err)
+		}
+	}
+	return c.ConnectAll(ctx)
+}
+
+// Node returns the node with id.
+func (c *SimCluster) Node(id string) (*SimClusterNode, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	node, ok := c.nodes[id]
+	return node, ok
+}
+
+// Nodes returns the running nodes in creation order.
+func (c *SimCluster) Nodes() []*SimClusterNode {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	nodes := make([]*SimClusterNode, 0, len(c.order))
+	for _, id := range c.order {
+		if !c.stopped[id] {
+			nodes = append(nodes, c.nodes[id])
+		}
+	}
+	return nodes
+}
+
+// ConnectAll connects every reachable pair of running nodes that is not
+// already connected. Failures on lossy links are joined into the error;
+// pairs that did connect stay connected.
+func (c *SimCluster) ConnectAll(ctx context.Context) error {
+	nodes := c.Nodes()
+	var errs []error
+	for i, a := range nodes {
+		for _, b := range nodes[i+1:] {
+			if a.Transport.IsConnected(b.ID) || !c.Network.Reachable(a.ID, b.ID) {
+				continue
+			}
+			if err := a.Transport.Connect(ctx, b.ID); err != nil {
+				errs = append(errs, fmt.Errorf("%s -> %s: %w", a.ID, b.ID, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Join adds a node, starts it and connects it to every reachable node.
+func (c *SimCluster) Join(ctx context.Context) (*SimClusterNode, error) {
+	node := c.newNode()
+	if err := node.Coordinator.Start(ctx); err != nil {
+		return nil, fmt.Errorf("%s: %w", node.ID, err)
+	}
+	return node, c.ConnectAll(ctx)
+}
+
+// Leave stops a node abruptly; its peers see the connections drop.
+func (c *SimCluster) Leave(id string) error {
+	c.mu.Lock()
+	node, ok := c.nodes[id]
+	if !ok || c.stopped[id] {
+		c.mu.Unlock()
+		return fmt.Errorf("node %s not running", id)
+	}
+	c.stopped[id] = true
+	c.mu.Unlock()
+	return node.Coordinator.Stop()
+}
+
+// Partition splits the cluster; see MemoryNetwork.Partition.
+func (c *SimCluster) Partition(groups ...[]string) {
+	c.Network.Partition(groups...)
+}
+
+// Heal removes partitions and reconnects the nodes they separated.
+func (c *SimCluster) Heal(ctx context.Context) error {
+	c.Network.Heal()
+	return c.ConnectAll(ctx)
+}
+
+// WaitFor polls cond until it holds or ctx ends.
+func (c *SimCluster) WaitFor(ctx context.Context, cond func() bool) error {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for !cond() {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// Close stops every running node.
+func (c *SimCluster) Close() {
+	for _, node := range c.Nodes() {
+		_ = c.Leave(node.ID)
+	}
+}
+
+// memoryChunkStore is the StorageProvider behind each SimCluster node.
+type memoryChunkStore struct {
+	mu     sync.RWMutex
+	chunks map[string][]byte
+}
+
+func newMemoryChunkStore() *memoryChunkStore {
+	return &memoryChunkStore{chunks: make(map[string][]byte)}
+}
+
+func (s *memoryChunkStore) StoreChunk(ctx context.Context, hash string, data []byte) error {
+	s.mu.Lock()
+	s.chunks[hash] = append([]byte(nil), data...)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *memoryChunkStore) FetchChunk(ctx context.Context, hash string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	data, ok := s.chunks[hash]
+	if !ok {
+		return nil, errors.New("chunk not found")
+	}
+	return append([]byte(nil), data...), nil
+}
+
+func (s *memoryChunkStore) HasChunk(ctx context.Context, hash string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.chunks[hash]
+	return ok, nil
+}
+
+func (s *memoryChunkStore) DeleteChunk(ctx context.Context, hash string) error {
+	s.mu.Lock()
+	delete(s.chunks, hash)
+	s.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,150 @@
+package mesh
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/transport"
+	"github.com/nmxmxh/inos_v1/kernel/threads/foundation"
+)
+
+func startSimCluster(t *testing.T, config SimClusterConfig) *SimCluster {
+	t.Helper()
+	cluster := NewSimCluster(config)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := cluster.Start(ctx); err != nil {
+		t.Fatalf("cluster start failed: %v", err)
+	}
+	t.Cleanup(cluster.Close)
+	return cluster
+}
+
+func waitForSimCluster(t *testing.T, cluster *SimCluster, what string, cond func() bool) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := cluster.WaitFor(ctx, cond); err != nil {
+		t.Fatalf("timed out waiting for %s", what)
+	}
+}
+
+func TestSimCluster_ChurnUpdatesPeerViews(t *testing.T) {
+	cluster := startSimCluster(t, SimClusterConfig{Nodes: 4, Link: transport.MemoryLinkProfile{Latency: time.Millisecond}})
+	first, _ := cluster.Node("node-01")
+
+	waitForSimCluster(t, cluster, "full mesh", func() bool { return first.Coordinator.gossip.TotalPeers() == 3 })
+
+	if err := cluster.Leave("node-02"); err != nil {
+		t.Fatalf("Leave failed: %v", err)
+	}
+	waitForSimCluster(t, cluster, "departed peer to drop", func() bool { return first.Coordinator.gossip.TotalPeers() == 2 })
+
+	joined, err := cluster.Join(context.Background())
+	if err != nil {
+		t.Fatalf("Join failed: %v", err)
+	}
+	waitForSimCluster(t, cluster, "new peer to be admitted", func() bool {
+		return first.Coordinator.gossip.TotalPeers() == 3 && joined.Coordinator.gossip.TotalPeers() == 3
+	})
+}
+
+func TestSimCluster_PartitionHeal(t *testing.T) {
+	cluster := startSimCluster(t, SimClusterConfig{Nodes: 4})
+	a, _ := cluster.Node("node-01")
+	c, _ := cluster.Node("node-03")
+
+	cluster.Partition([]string{"node-01", "node-02"}, []string{"node-03", "node-04"})
+	if a.Transport.IsConnected(c.ID) {
+		t.Fatal("partition should drop cross-group connections")
+	}
+	if err := a.Transport.SendRPC(context.Background(), c.ID, "ping", nil, nil); err == nil {
+		t.Fatal("expected RPC across the partition to fail")
+	}
+
+	if err := cluster.Heal(context.Background()); err != nil {
+		t.Fatalf("Heal failed: %v", err)
+	}
+	waitForSimCluster(t, cluster, "partition to heal", func() bool {
+		return a.Coordinator.gossip.TotalPeers() == 3 && c.Coordinator.gossip.TotalPeers() == 3
+	})
+}
+
+func TestSimCluster_DelegationStorm(t *testing.T) {
+	var served sync.Map
+	cluster := startSimCluster(t, SimClusterConfig{
+		Nodes: 5,
+		Link:  transport.MemoryLinkProfile{Latency: 2 * time.Millisecond, Jitter: time.Millisecond},
+		Seed:  7,
+		Configure: func(node *SimClusterNode) {
+			node.Coordinator.SetDispatcher(&mockDispatcher{run: func(job *foundation.Job) *foundation.Result {
+				served.Store(job.ID, node.ID)
+				return &foundation.Result{JobID: job.ID, Success: true, Data: append([]byte("ok:"), job.Data...)}
+			}})
+		},
+	})
+	origin, _ := cluster.Node("node-01")
+	waitForSimCluster(t, cluster, "full mesh", func() bool { return origin.Coordinator.gossip.TotalPeers() == 4 })
+	// Metrics gossip reaches the origin as pushed messages. Fanout is below
+	// the peer count, so keep gossiping until every node has reached it
+	// directly rather than waiting for the periodic round.
+	waitForSimCluster(t, cluster, "peer metrics", func() bool {
+		origin.Coordinator.peerMetricsMu.RLock()
+		known := len(origin.Coordinator.peerMetrics)
+		origin.Coordinator.peerMetricsMu.RUnlock()
+		if known == 4 {
+			return true
+		}
+		for _, node := range cluster.Nodes()[1:] {
+			node.Coordinator.updateMetrics()
+			node.Coordinator.gossipMetrics()
+		}
+		return false
+	})
+
+	// Executors only issue compute capabilities to requesters they trust
+	for _, node := range cluster.Nodes()[1:] {
+		trustPeers(node.Coordinator, origin.ID)
+	}
+
+	const jobs = 40
+	var wg sync.WaitGroup
+	errs := make(chan error, jobs)
+	for i := 0; i < jobs; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			job := &foundation.Job{ID: fmt.Sprintf("storm-%d", i), Operation: "noop", Data: []byte{byte(i)}}
+			result, err := origin.Coordinator.DelegateJob(context.Background(), job)
+			if err != nil {
+				errs <- err
+				return
+			}
+			if !result.Success || len(result.Data) != 4 {
+				errs <- fmt.Errorf("job %s returned %+v", job.ID, result)
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("delegation failed: %v", err)
+	}
+
+	remote := 0
+	served.Range(func(_, nodeID any) bool {
+		if nodeID != origin.ID {
+			remote++
+		}
+		return true
+	})
+	if remote == 0 {
+		t.Fatal("expected jobs to run on remote nodes")
+	}
+	if delivered, _ := cluster.Network.Stats(); delivered == 0 {
+		t.Fatal("expected traffic on the memory network")
+	}
+}
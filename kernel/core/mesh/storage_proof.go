@@ -0,0 +1,274 @@
+package mesh
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	storageChallengeMethod = "mesh.StorageChallenge"
+	storageChallengeNonce  = 16
+)
+
+// StorageChallenge asks a peer to prove it holds a chunk by hashing a random
+// byte range salted with a fresh nonce.
+type StorageChallenge struct {
+	ChunkHash string `json:"chunk_hash"`
+	Offset    int    `json:"offset"`
+	Length    int    `json:"length"`
+	Nonce     []byte `json:"nonce"`
+}
+
+// StorageProof answers a StorageChallenge.
+type StorageProof struct {
+	ChunkHash string `json:"chunk_hash"`
+	Digest    []byte `json:"digest"`
+	Size      int    `json:"size"`
+}
+
+// StorageProofStats summarizes challenges issued by this node.
+type StorageProofStats struct {
+	Issued       uint64 `json:"issued"`
+	Passed       uint64 `json:"passed"`
+	Failed       uint64 `json:"failed"`
+	Rereplicated uint64 `json:"rereplicated"`
+}
+
+type storageProofCounters struct {
+	issued       atomic.Uint64
+	passed       atomic.Uint64
+	failed       atomic.Uint64
+	rereplicated atomic.Uint64
+}
+
+// storageProofDigest binds the nonce to the requested range so responses
+// cannot be precomputed or replayed.
+func storageProofDigest(nonce, data []byte, offset, length int) ([]byte, error) {
+	if offset < 0 || length <= 0 || offset+length > len(data) {
+		return nil, fmt.Errorf("range [%d,%d) out of bounds for %d bytes", offset, offset+length, len(data))
+	}
+	h := sha256.New()
+	h.Write(nonce)
+	h.Write(data[offset : offset+length])
+	return h.Sum(nil), nil
+}
+
+func (m *MeshCoordinator) registerStorageProofHandler() {
+	m.registerRPC(storageChallengeMethod, func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error) {
+		if m.storage == nil {
+			return nil, errors.New("storage provider not configured")
+		}
+
+		var challenge StorageChallenge
+		if err := json.Unmarshal(args, &challenge); err != nil {
+			return nil, fmt.Errorf("failed to decode storage challenge: %w", err)
+		}
+		if challenge.ChunkHash == "" || len(challenge.Nonce) == 0 {
+			return nil, errors.New("malformed storage challenge")
+		}
+		if challenge.Length > m.config.StorageProof.MaxRangeBytes {
+			return nil, fmt.Errorf("challenge range too large: %d bytes", challenge.Length)
+		}
+
+		data, err := m.storage.FetchChunk(ctx, challenge.ChunkHash)
+		if err != nil {
+			return nil, fmt.Errorf("chunk unavailable: %w", err)
+		}
+		digest, err := storageProofDigest(challenge.Nonce, data, challenge.Offset, challenge.Length)
+		if err != nil {
+			return nil, err
+		}
+		return StorageProof{ChunkHash: challenge.ChunkHash, Digest: digest, Size: len(data)}, nil
+	})
+}
+
+func newStorageChallenge(chunkHash string, size, maxRange int) (StorageChallenge, error) {
+	if size <= 0 {
+		return StorageChallenge{}, errors.New("cannot challenge an empty chunk")
+	}
+	length := minInt(size, maxRange)
+	offset := 0
+	if size > length {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(size-length+1)))
+		if err != nil {
+			return StorageChallenge{}, err
+		}
+		offset = int(n.Int64())
+	}
+	nonce := make([]byte, storageChallengeNonce)
+	if _, err := rand.Read(nonce); err != nil {
+		return StorageChallenge{}, err
+	}
+	return StorageChallenge{ChunkHash: chunkHash, Offset: offset, Length: length, Nonce: nonce}, nil
+}
+
+// ChallengePeerStorage verifies that peerID still holds chunkHash. The
+// challenger must hold the chunk itself to check the response. A failed
+// proof costs the peer reputation, drops it as a provider and re-replicates
+// the chunk elsewhere.
+func (m *MeshCoordinator) ChallengePeerStorage(ctx context.Context, peerID, chunkHash string) error {
+	if m.storage == nil {
+		return errors.New("storage provider not configured")
+	}
+	data, err := m.storage.FetchChunk(ctx, chunkHash)
+	if err != nil {
+		return fmt.Errorf("cannot verify chunk %s without a local copy: %w", getShortID(chunkHash), err)
+	}
+
+	challenge, err := newStorageChallenge(chunkHash, len(data), m.config.StorageProof.MaxRangeBytes)
+	if err != nil {
+		return err
+	}
+	expected, _ := storageProofDigest(challenge.Nonce, data, challenge.Offset, challenge.Length)
+
+	m.storageProofs.issued.Add(1)
+	challengeCtx, cancel := context.WithTimeout(ctx, m.config.StorageProof.Timeout)
+	defer cancel()
+
+	var proof StorageProof
+	err = m.transport.SendRPC(challengeCtx, peerID, storageChallengeMethod, challenge, &proof)
+	if err == nil && proof.Size != len(data) {
+		err = fmt.Errorf("peer reported size %d, want %d", proof.Size, len(data))
+	}
+	if err == nil && subtle.ConstantTimeCompare(proof.Digest, expected) != 1 {
+		err = errors.New("storage proof digest mismatch")
+	}
+
+	difficulty := float64(challenge.Length) / float64(m.config.StorageProof.MaxRangeBytes)
+	if err == nil {
+		m.storageProofs.passed.Add(1)
+		m.reputation.PoRReport(peerID, true, difficulty)
+		return nil
+	}
+
+	m.storageProofs.failed.Add(1)
+	m.reputation.PoRReport(peerID, false, difficulty)
+	m.logger.Warn("peer failed storage proof",
+		"peer", getShortID(peerID),
+		"chunk", getShortID(chunkHash),
+		"error", err,
+	)
+
+	_ = m.dht.RemoveChunkPeer(chunkHash, peerID)
+	m.chunkCache.Remove(chunkHash)
+	if rerr := m.rereplicateChunk(ctx, chunkHash, data, peerID); rerr != nil {
+		m.logger.Debug("re-replication after failed proof did not complete", "chunk", getShortID(chunkHash), "error", rerr)
+	}
+
+	return fmt.Errorf("peer %s failed storage proof: %w", getShortID(peerID), err)
+}
+
+// rereplicateChunk places one replacement replica on a peer that is neither
+// the failed holder nor an existing provider.
+func (m *MeshCoordinator) rereplicateChunk(ctx context.Context, chunkHash string, data []byte, failedPeer string) error {
+	target, err := m.placeReplica(ctx, chunkHash, data, failedPeer)
+	if err != nil {
+		return err
+	}
+	m.storageProofs.rereplicated.Add(1)
+	m.logger.Info("re-replicated chunk after failed storage proof",
+		"chunk", getShortID(chunkHash),
+		"from", getShortID(failedPeer),
+		"to", getShortID(target),
+	)
+	return nil
+}
+
+// placeReplica stores one more copy of a chunk on the best-scoring peer that
+// is not already a provider, is not this node and is not exclude.
+func (m *MeshCoordinator) placeReplica(ctx context.Context, chunkHash string, data []byte, exclude string) (string, error) {
+	providers, _ := m.dht.FindPeers(chunkHash)
+	skip := map[string]bool{m.nodeID: true, exclude: true}
+	for _, p := range providers {
+		skip[p] = true
+	}
+
+	for _, candidate := range m.scorePeers(m.dht.FindNode(chunkHash)) {
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		if skip[candidate.ID] {
+			continue
+		}
+		if err := m.sendChunkToPeer(ctx, candidate.ID, chunkHash, data); err != nil {
+			continue
+		}
+		_ = m.storeChunkRecord(chunkHash, candidate.ID)
+		return candidate.ID, nil
+	}
+	return "", errors.New("no replacement peer available")
+}
+
+// GetStorageProofStats returns storage challenge counters.
+func (m *MeshCoordinator) GetStorageProofStats() StorageProofStats {
+	return StorageProofStats{
+		Issued:       m.storageProofs.issued.Load(),
+		Passed:       m.storageProofs.passed.Load(),
+		Failed:       m.storageProofs.failed.Load(),
+		Rereplicated: m.storageProofs.rereplicated.Load(),
+	}
+}
+
+// storageProofLoop periodically challenges providers of a random local chunk.
+func (m *MeshCoordinator) storageProofLoop() {
+	if m.config.StorageProof.Interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(m.config.StorageProof.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if !m.deferLowPriority() {
+				m.runStorageChallenges()
+			}
+		case <-m.shutdown:
+			return
+		}
+	}
+}
+
+func (m *MeshCoordinator) runStorageChallenges() {
+	if m.storage == nil {
+		return
+	}
+
+	// Map iteration order gives a cheap random pick.
+	var chunkHash string
+	m.localChunksMu.RLock()
+	for hash := range m.localChunks {
+		chunkHash = hash
+		break
+	}
+	m.localChunksMu.RUnlock()
+	if chunkHash == "" {
+		return
+	}
+
+	providers, err := m.dht.FindPeers(chunkHash)
+	if err != nil {
+		return
+	}
+
+	challenged := 0
+	for _, peerID := range providers {
+		if challenged >= m.config.StorageProof.MaxPeers {
+			break
+		}
+		if peerID == m.nodeID || !m.transport.IsConnected(peerID) {
+			continue
+		}
+		challenged++
+		_ = m.ChallengePeerStorage(context.Background(), peerID, chunkHash)
+	}
+}
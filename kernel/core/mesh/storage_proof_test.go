@@ -0,0 +1,76 @@
+package mesh
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestMeshCoordinator_StorageProofHonestPeer(t *testing.T) {
+	tr := &MockTransport{nodeID: "self"}
+	coord := NewMeshCoordinator("self", "us-east", tr, nil)
+	coord.SetStorage(&MockStorage{chunks: map[string][]byte{
+		"chunk-a": bytes.Repeat([]byte("replica"), 2000),
+	}})
+
+	// The registered handler answers from the same storage, i.e. an honest holder.
+	if err := coord.ChallengePeerStorage(context.Background(), "holder", "chunk-a"); err != nil {
+		t.Fatalf("honest peer failed challenge: %v", err)
+	}
+	if stats := coord.GetStorageProofStats(); stats.Issued != 1 || stats.Passed != 1 || stats.Failed != 0 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
+
+func TestMeshCoordinator_StorageProofFailureSlashesAndRereplicates(t *testing.T) {
+	tr := &MockTransport{
+		nodeID:      "self",
+		rpcHandlers: make(map[string]func(args interface{}) (interface{}, error)),
+	}
+	coord := NewMeshCoordinator("self", "us-east", tr, nil)
+	data := bytes.Repeat([]byte{0xAB}, 10000)
+	coord.SetStorage(&MockStorage{chunks: map[string][]byte{"chunk-a": data}})
+
+	// The cheater kept only the size, not the bytes.
+	tr.rpcHandlers[storageChallengeMethod] = func(args interface{}) (interface{}, error) {
+		return StorageProof{ChunkHash: "chunk-a", Digest: make([]byte, 32), Size: len(data)}, nil
+	}
+
+	_ = coord.dht.Store("chunk-a", "cheater", 3600)
+	_ = coord.dht.AddPeer(PeerInfo{ID: "fresh", Capabilities: &PeerCapability{PeerID: "fresh", Reputation: 0.9}})
+	before, _ := coord.reputation.GetTrustScore("cheater")
+
+	if err := coord.ChallengePeerStorage(context.Background(), "cheater", "chunk-a"); err == nil {
+		t.Fatal("expected forged proof to fail")
+	}
+
+	after, _ := coord.reputation.GetTrustScore("cheater")
+	if after >= before {
+		t.Fatalf("expected reputation slash, before=%f after=%f", before, after)
+	}
+
+	providers, _ := coord.dht.FindPeers("chunk-a")
+	hasFresh := false
+	for _, p := range providers {
+		if p == "cheater" {
+			t.Fatal("failed peer should be dropped as a provider")
+		}
+		hasFresh = hasFresh || p == "fresh"
+	}
+	if !hasFresh {
+		t.Fatalf("expected chunk re-replicated to fresh peer, providers=%v", providers)
+	}
+	if stats := coord.GetStorageProofStats(); stats.Failed != 1 || stats.Rereplicated != 1 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
+
+func TestStorageProofDigestRejectsOutOfRange(t *testing.T) {
+	if _, err := storageProofDigest([]byte("n"), []byte("abc"), 2, 5); err == nil {
+		t.Fatal("expected out-of-range challenge to fail")
+	}
+	challenge, err := newStorageChallenge("c", 10, 4096)
+	if err != nil || challenge.Offset != 0 || challenge.Length != 10 {
+		t.Fatalf("small chunk should be challenged in full, got %+v %v", challenge, err)
+	}
+}
@@ -0,0 +1,412 @@
+package mesh
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+)
+
+// StorageQuota tracks the bytes of locally stored chunks per namespace and
+// reports how far usage is over the configured limits. It makes no eviction
+// decisions itself; the coordinator ranks and removes chunks.
+type StorageQuota struct {
+	mu              sync.Mutex
+	maxBytes        uint64 // 0 means unlimited
+	namespaceLimits map[string]uint64
+	chunks          map[string]*quotaEntry
+	used            uint64
+	byNamespace     map[string]uint64
+}
+
+type quotaEntry struct {
+	hash       string
+	namespace  string
+	size       uint64
+	lastAccess time.Time
+}
+
+// NamespaceStorage is one namespace's share of local chunk storage.
+type NamespaceStorage struct {
+	Namespace  string `json:"namespace"`
+	UsedBytes  uint64 `json:"used_bytes"`
+	LimitBytes uint64 `json:"limit_bytes,omitempty"`
+	Chunks     int    `json:"chunks"`
+}
+
+// StorageQuotaUsage reports local chunk storage against its limits.
+type StorageQuotaUsage struct {
+	MaxBytes      uint64             `json:"max_bytes"`
+	UsedBytes     uint64             `json:"used_bytes"`
+	Chunks        int                `json:"chunks"`
+	Namespaces    []NamespaceStorage `json:"namespaces"`
+	Evicted       uint64             `json:"evicted"`
+	EvictedBytes  uint64             `json:"evicted_bytes"`
+	Redistributed uint64             `json:"redistributed"` // Last replicas pushed to a peer before eviction
+	Retained      uint64             `json:"retained"`      // Last replicas kept because no peer took them
+}
+
+// NewStorageQuota creates a quota of maxBytes across all namespaces.
+func NewStorageQuota(maxBytes uint64) *StorageQuota {
+	return &StorageQuota{
+		maxBytes:        maxBytes,
+		namespaceLimits: make(map[string]uint64),
+		chunks:          make(map[string]*quotaEntry),
+		byNamespace:     make(map[string]uint64),
+	}
+}
+
+// SetLimit changes the overall limit; 0 removes it.
+func (q *StorageQuota) SetLimit(maxBytes uint64) {
+	q.mu.Lock()
+	q.maxBytes = maxBytes
+	q.mu.Unlock()
+}
+
+// SetNamespaceLimit caps one namespace; 0 removes the cap.
+func (q *StorageQuota) SetNamespaceLimit(namespace string, maxBytes uint64) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if maxBytes == 0 {
+		delete(q.namespaceLimits, namespace)
+		return
+	}
+	q.namespaceLimits[namespace] = maxBytes
+}
+
+// Track records a stored chunk. A chunk keeps the namespace that stored it
+// first; re-storing it only refreshes its size and access time.
+func (q *StorageQuota) Track(hash, namespace string, size uint64) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if entry, ok := q.chunks[hash]; ok {
+		q.used = q.used - entry.size + size
+		q.byNamespace[entry.namespace] = q.byNamespace[entry.namespace] - entry.size + size
+		entry.size = size
+		entry.lastAccess = time.Now()
+		return
+	}
+	q.chunks[hash] = &quotaEntry{hash: hash, namespace: namespace, size: size, lastAccess: time.Now()}
+	q.used += size
+	q.byNamespace[namespace] += size
+}
+
+// Namespace returns the namespace a tracked chunk is accounted to.
+func (q *StorageQuota) Namespace(hash string) (string, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	entry, ok := q.chunks[hash]
+	if !ok {
+		return "", false
+	}
+	return entry.namespace, true
+}
+
+// Touch marks a chunk as recently used.
+func (q *StorageQuota) Touch(hash string) {
+	q.mu.Lock()
+	if entry, ok := q.chunks[hash]; ok {
+		entry.lastAccess = time.Now()
+	}
+	q.mu.Unlock()
+}
+
+// Forget stops tracking a chunk and returns the bytes it accounted for.
+func (q *StorageQuota) Forget(hash string) uint64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	entry, ok := q.chunks[hash]
+	if !ok {
+		return 0
+	}
+	delete(q.chunks, hash)
+	q.used -= entry.size
+	q.byNamespace[entry.namespace] -= entry.size
+	if q.byNamespace[entry.namespace] == 0 {
+		delete(q.byNamespace, entry.namespace)
+	}
+	return entry.size
+}
+
+// Tracked reports whether a chunk is accounted for.
+func (q *StorageQuota) Tracked(hash string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	_, ok := q.chunks[hash]
+	return ok
+}
+
+// OverLimit reports whether any limit is exceeded.
+func (q *StorageQuota) OverLimit() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.maxBytes > 0 && q.used > q.maxBytes {
+		return true
+	}
+	for namespace, limit := range q.namespaceLimits {
+		if q.byNamespace[namespace] > limit {
+			return true
+		}
+	}
+	return false
+}
+
+// excess returns how many bytes must go, overall and per namespace, to bring
+// usage down to watermark times each limit.
+func (q *StorageQuota) excess(watermark float64) (uint64, map[string]uint64) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	target := func(limit uint64) uint64 { return uint64(float64(limit) * watermark) }
+	var overall uint64
+	if q.maxBytes > 0 && q.used > q.maxBytes {
+		overall = q.used - target(q.maxBytes)
+	}
+	namespaces := make(map[string]uint64)
+	for namespace, limit := range q.namespaceLimits {
+		if used := q.byNamespace[namespace]; used > limit {
+			namespaces[namespace] = used - target(limit)
+		}
+	}
+	return overall, namespaces
+}
+
+func (q *StorageQuota) entries() []quotaEntry {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	out := make([]quotaEntry, 0, len(q.chunks))
+	for _, entry := range q.chunks {
+		out = append(out, *entry)
+	}
+	return out
+}
+
+// Usage returns current usage per namespace, largest first.
+func (q *StorageQuota) Usage() StorageQuotaUsage {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	usage := StorageQuotaUsage{MaxBytes: q.maxBytes, UsedBytes: q.used, Chunks: len(q.chunks)}
+	counts := make(map[string]int)
+	for _, entry := range q.chunks {
+		counts[entry.namespace]++
+	}
+	seen := make(map[string]bool)
+	for namespace, used := range q.byNamespace {
+		seen[namespace] = true
+		usage.Namespaces = append(usage.Namespaces, NamespaceStorage{
+			Namespace:  namespace,
+			UsedBytes:  used,
+			LimitBytes: q.namespaceLimits[namespace],
+			Chunks:     counts[namespace],
+		})
+	}
+	for namespace, limit := range q.namespaceLimits {
+		if !seen[namespace] {
+			usage.Namespaces = append(usage.Namespaces, NamespaceStorage{Namespace: namespace, LimitBytes: limit})
+		}
+	}
+	sort.Slice(usage.Namespaces, func(i, j int) bool {
+		if usage.Namespaces[i].UsedBytes != usage.Namespaces[j].UsedBytes {
+			return usage.Namespaces[i].UsedBytes > usage.Namespaces[j].UsedBytes
+		}
+		return usage.Namespaces[i].Namespace < usage.Namespaces[j].Namespace
+	})
+	return usage
+}
+
+type storageQuotaCounters struct {
+	evicted       atomic.Uint64
+	evictedBytes  atomic.Uint64
+	redistributed atomic.Uint64
+	retained      atomic.Uint64
+}
+
+// trackStoredChunk accounts for a chunk written to local storage and starts
+// an eviction pass if that pushed usage over a limit.
+func (m *MeshCoordinator) trackStoredChunk(ctx context.Context, chunkHash string, size int) {
+	m.storageQuota.Track(chunkHash, common.NamespaceFromContext(ctx), uint64(size))
+	if m.storageQuota.OverLimit() {
+		go m.enforceStorageQuota(context.Background())
+	}
+}
+
+// touchStoredChunk refreshes a chunk's LRU position on access.
+func (m *MeshCoordinator) touchStoredChunk(chunkHash string) {
+	m.storageQuota.Touch(chunkHash)
+}
+
+// evictionPriority ranks chunks for eviction: higher goes first. Idle time is
+// discounted by demand, and chunks with fewer known replicas are kept longer.
+func (m *MeshCoordinator) evictionPriority(entry quotaEntry, replicas int, now time.Time) float64 {
+	target := max(m.config.StorageQuota.ReplicaTarget, 1)
+	idle := now.Sub(entry.lastAccess).Seconds() + 1
+	demand := m.demandTracker.GetDemandScore(entry.hash)
+	coverage := float64(min(max(replicas, 1), target)) / float64(target)
+	return idle * coverage / (1 + demand*m.config.StorageQuota.DemandWeight)
+}
+
+// enforceStorageQuota evicts chunks until usage is back under each limit's
+// low watermark. Pinned chunks are never evicted. A chunk with no other known provider is only evicted after a
+// copy is placed on a peer; if no peer takes it, it stays. Returns the number
+// of chunks evicted.
+func (m *MeshCoordinator) enforceStorageQuota(ctx context.Context) int {
+	if m.storage == nil || !m.quotaEnforcing.CompareAndSwap(false, true) {
+		return 0
+	}
+	defer m.quotaEnforcing.Store(false)
+
+	overall, namespaces := m.storageQuota.excess(m.config.StorageQuota.LowWatermark)
+	if overall == 0 && len(namespaces) == 0 {
+		return 0
+	}
+
+	type candidate struct {
+		entry    quotaEntry
+		replicas int
+		priority float64
+	}
+	now := time.Now()
+	var candidates []candidate
+	for _, entry := range m.storageQuota.entries() {
+		if overall == 0 && namespaces[entry.namespace] == 0 {
+			continue
+		}
+		if m.IsPinned(entry.hash) {
+			continue
+		}
+		replicas := 0
+		for _, provider := range m.dht.LocalProviders(entry.hash) {
+			if provider != m.nodeID {
+				replicas++
+			}
+		}
+		candidates = append(candidates, candidate{entry, replicas, m.evictionPriority(entry, replicas, now)})
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].priority > candidates[j].priority })
+
+	evicted := 0
+	for _, c := range candidates {
+		if overall == 0 && namespaces[c.entry.namespace] == 0 {
+			if len(namespaces) == 0 {
+				break
+			}
+			continue
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		if c.replicas == 0 {
+			data, err := m.storage.FetchChunk(ctx, c.entry.hash)
+			if err != nil {
+				continue
+			}
+			if _, err := m.placeReplica(ctx, c.entry.hash, data, ""); err != nil {
+				m.storageQuotaStats.retained.Add(1)
+				continue
+			}
+			m.storageQuotaStats.redistributed.Add(1)
+		}
+
+		if !m.evictChunk(ctx, c.entry.hash) {
+			continue
+		}
+		evicted++
+		overall -= min(overall, c.entry.size)
+		if remaining := namespaces[c.entry.namespace]; remaining > 0 {
+			remaining -= min(remaining, c.entry.size)
+			if remaining == 0 {
+				delete(namespaces, c.entry.namespace)
+			} else {
+				namespaces[c.entry.namespace] = remaining
+			}
+		}
+	}
+
+	if evicted > 0 {
+		usage := m.storageQuota.Usage()
+		m.logger.Info("evicted chunks over storage quota",
+			"evicted", evicted,
+			"used_bytes", usage.UsedBytes,
+			"max_bytes", usage.MaxBytes,
+		)
+	}
+	return evicted
+}
+
+// evictChunk removes a chunk from local storage and withdraws this node's
+// provider record for it.
+func (m *MeshCoordinator) evictChunk(ctx context.Context, chunkHash string) bool {
+	if err := m.storage.DeleteChunk(ctx, chunkHash); err != nil {
+		m.logger.Debug("failed to evict chunk", "chunk", getShortID(chunkHash), "error", err)
+		return false
+	}
+
+	m.localChunksMu.Lock()
+	delete(m.localChunks, chunkHash)
+	m.localChunksMu.Unlock()
+	m.chunkRecordsMu.Lock()
+	delete(m.chunkRecords, chunkHash)
+	m.chunkRecordsMu.Unlock()
+	_ = m.dht.RemoveChunkPeer(chunkHash, m.nodeID)
+
+	size := m.storageQuota.Forget(chunkHash)
+	m.forgetChunkACL(chunkHash)
+	m.releaseInboundChunk(chunkHash)
+	m.storageQuotaStats.evicted.Add(1)
+	m.storageQuotaStats.evictedBytes.Add(size)
+	m.publishEvent(MeshEventChunkEvicted, m.nodeID, map[string]interface{}{
+		"chunk_hash": chunkHash,
+		"size":       size,
+	})
+	return true
+}
+
+func (m *MeshCoordinator) storageQuotaLoop() {
+	if m.config.StorageQuota.CheckInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(m.config.StorageQuota.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if m.storageQuota.OverLimit() && !m.deferLowPriority() {
+				m.enforceStorageQuota(context.Background())
+			}
+		case <-m.shutdown:
+			return
+		}
+	}
+}
+
+// SetStorageQuota changes the overall local chunk storage limit and enforces
+// it right away. 0 removes the limit.
+func (m *MeshCoordinator) SetStorageQuota(maxBytes uint64) {
+	m.storageQuota.SetLimit(maxBytes)
+	go m.enforceStorageQuota(context.Background())
+}
+
+// SetNamespaceStorageQuota caps the bytes one namespace may keep on this node.
+func (m *MeshCoordinator) SetNamespaceStorageQuota(namespace string, maxBytes uint64) {
+	m.storageQuota.SetNamespaceLimit(namespace, maxBytes)
+	go m.enforceStorageQuota(context.Background())
+}
+
+// GetStorageQuotaUsage returns local chunk storage usage and eviction counts.
+func (m *MeshCoordinator) GetStorageQuotaUsage() StorageQuotaUsage {
+	usage := m.storageQuota.Usage()
+	usage.Evicted = m.storageQuotaStats.evicted.Load()
+	usage.EvictedBytes = m.storageQuotaStats.evictedBytes.Load()
+	usage.Redistributed = m.storageQuotaStats.redistributed.Load()
+	usage.Retained = m.storageQuotaStats.retained.Load()
+	return usage
+}
@@ -0,0 +1,85 @@
+package mesh
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+)
+
+func TestStorageQuota_TracksNamespacesAndExcess(t *testing.T) {
+	q := NewStorageQuota(1000)
+	q.SetNamespaceLimit("tenant", 300)
+	q.Track("a", "tenant", 200)
+	q.Track("b", "tenant", 200)
+	q.Track("c", common.DefaultNamespace, 500)
+	q.Track("a", "other", 250) // re-store keeps the original namespace
+
+	if !q.OverLimit() {
+		t.Fatal("expected tenant over its limit")
+	}
+	overall, namespaces := q.excess(1.0)
+	if overall != 0 || namespaces["tenant"] != 150 {
+		t.Fatalf("unexpected excess %d %v", overall, namespaces)
+	}
+
+	usage := q.Usage()
+	if usage.UsedBytes != 950 || usage.Chunks != 3 {
+		t.Fatalf("unexpected usage %+v", usage)
+	}
+	if usage.Namespaces[0].Namespace != common.DefaultNamespace || usage.Namespaces[1].LimitBytes != 300 {
+		t.Fatalf("unexpected namespace breakdown %+v", usage.Namespaces)
+	}
+
+	if freed := q.Forget("a"); freed != 250 {
+		t.Fatalf("expected 250 bytes freed, got %d", freed)
+	}
+	if q.OverLimit() {
+		t.Fatal("expected usage within limits after forget")
+	}
+}
+
+func TestStorageQuota_EvictsColdReplicatedChunksFirst(t *testing.T) {
+	storage := &MockStorage{chunks: map[string][]byte{}}
+	coord := NewMeshCoordinator("self", "us-east", &MockTransport{nodeID: "self"}, nil)
+	coord.SetStorage(storage)
+	coord.config.StorageQuota.LowWatermark = 1
+	coord.storageQuota.SetLimit(300)
+
+	now := time.Now()
+	idle := map[string]time.Duration{
+		"unique": 10000 * time.Second, // Oldest, but no other replica and no peer to take it
+		"hot":    3000 * time.Second,
+		"cold":   1000 * time.Second,
+		"fresh":  0,
+	}
+	for hash, age := range idle {
+		storage.chunks[hash] = make([]byte, 100)
+		coord.storageQuota.Track(hash, common.DefaultNamespace, 100)
+		coord.storageQuota.chunks[hash].lastAccess = now.Add(-age)
+		if hash != "unique" {
+			_ = coord.dht.Store(hash, "peer-x", 3600)
+		}
+	}
+	for i := 0; i < 100; i++ {
+		coord.demandTracker.RecordAccess("hot")
+	}
+
+	if evicted := coord.enforceStorageQuota(context.Background()); evicted != 1 {
+		t.Fatalf("expected one eviction, got %d", evicted)
+	}
+	if _, ok := storage.chunks["cold"]; ok {
+		t.Fatal("expected the cold replicated chunk to be evicted")
+	}
+	for _, kept := range []string{"unique", "hot", "fresh"} {
+		if _, ok := storage.chunks[kept]; !ok {
+			t.Fatalf("expected %s to be kept", kept)
+		}
+	}
+
+	usage := coord.GetStorageQuotaUsage()
+	if usage.UsedBytes != 300 || usage.Evicted != 1 || usage.EvictedBytes != 100 || usage.Retained != 1 {
+		t.Fatalf("unexpected usage %+v", usage)
+	}
+}
@@ -0,0 +1,137 @@
+package mesh
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/bits"
+	"sync"
+)
+
+// Sybil admission modes. With a mode other than off a peer is only added to
+// DHT buckets and gossip peer lists once it has shown a proof-of-work bound
+// to its identity key, a staked ledger balance, or (either) one of the two.
+// Minting node IDs then costs CPU time or credits instead of nothing.
+const (
+	SybilAdmissionOff    = "off"
+	SybilAdmissionPoW    = "pow"
+	SybilAdmissionStake  = "stake"
+	SybilAdmissionEither = "either"
+)
+
+const (
+	admissionPoWDomain = "inos-admission-pow-v1"
+
+	// maxAdmissionPoWBits caps the work a challenger can demand, so a peer
+	// cannot be made to burn CPU by an absurd difficulty.
+	maxAdmissionPoWBits = 28
+)
+
+var (
+	ErrInsufficientWork  = errors.New("peer proof-of-work is below the required difficulty")
+	ErrInsufficientStake = errors.New("peer stake is below the required balance")
+)
+
+// admissionPoWState caches this node's solved proof for its current key.
+type admissionPoWState struct {
+	mu    sync.Mutex
+	key   ed25519.PublicKey
+	nonce uint64
+	bits  int
+}
+
+// admissionPoWBits returns the leading zero bits of
+// sha256(domain || key || nonce).
+func admissionPoWBits(pub ed25519.PublicKey, nonce uint64) int {
+	h := sha256.New()
+	h.Write([]byte(admissionPoWDomain))
+	h.Write(pub)
+	var n [8]byte
+	binary.BigEndian.PutUint64(n[:], nonce)
+	h.Write(n[:])
+	return leadingZeroBits(h.Sum(nil))
+}
+
+func leadingZeroBits(sum []byte) int {
+	n := 0
+	for _, b := range sum {
+		if b != 0 {
+			return n + bits.LeadingZeros8(b)
+		}
+		n += 8
+	}
+	return n
+}
+
+// admissionProof returns a nonce for pub with at least want bits of work,
+// solving it once and reusing it until the key changes or more is asked.
+func (m *MeshCoordinator) admissionProof(pub ed25519.PublicKey, want int) uint64 {
+	want = min(want, maxAdmissionPoWBits)
+	s := &m.admissionPoW
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.key.Equal(pub) {
+		s.key, s.nonce, s.bits = append(ed25519.PublicKey(nil), pub...), 0, admissionPoWBits(pub, 0)
+	}
+	for s.bits < want {
+		s.nonce++
+		s.bits = admissionPoWBits(pub, s.nonce)
+	}
+	return s.nonce
+}
+
+// requestedPoWBits is the difficulty put in outgoing attestation challenges.
+func (m *MeshCoordinator) requestedPoWBits() int {
+	switch m.config.SybilAdmission.Mode {
+	case SybilAdmissionPoW, SybilAdmissionEither:
+		return m.config.SybilAdmission.PoWDifficulty
+	}
+	return 0
+}
+
+// checkSybilAdmission applies the configured proof requirement to an
+// attested peer.
+func (m *MeshCoordinator) checkSybilAdmission(record AttestationRecord) error {
+	switch m.config.SybilAdmission.Mode {
+	case "", SybilAdmissionOff:
+		return nil
+	case SybilAdmissionPoW:
+		return m.checkAdmissionWork(record)
+	case SybilAdmissionStake:
+		return m.checkAdmissionStake(record)
+	case SybilAdmissionEither:
+		workErr := m.checkAdmissionWork(record)
+		if workErr == nil {
+			return nil
+		}
+		stakeErr := m.checkAdmissionStake(record)
+		if stakeErr == nil {
+			return nil
+		}
+		return errors.Join(workErr, stakeErr)
+	default:
+		return fmt.Errorf("unknown sybil admission mode %q", m.config.SybilAdmission.Mode)
+	}
+}
+
+func (m *MeshCoordinator) checkAdmissionWork(record AttestationRecord) error {
+	if need := m.config.SybilAdmission.PoWDifficulty; record.PoWBits < need {
+		return fmt.Errorf("%w: %d of %d bits", ErrInsufficientWork, record.PoWBits, need)
+	}
+	return nil
+}
+
+// checkAdmissionStake only counts balances held by a DID the peer proved it
+// owns; a self-asserted DID would let anyone borrow a rich account.
+func (m *MeshCoordinator) checkAdmissionStake(record AttestationRecord) error {
+	if !record.DIDVerified {
+		return fmt.Errorf("%w: no verified DID", ErrInsufficientStake)
+	}
+	if balance, need := m.GetEconomicBalance(record.DID), m.config.SybilAdmission.MinStake; balance < need {
+		return fmt.Errorf("%w: %d of %d", ErrInsufficientStake, balance, need)
+	}
+	return nil
+}
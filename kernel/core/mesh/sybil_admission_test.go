@@ -0,0 +1,73 @@
+package mesh
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSybilAdmission_ProofOfWorkIsBoundToKey(t *testing.T) {
+	coord := NewMeshCoordinator("self", "us-east", &MockTransport{nodeID: "self"}, nil)
+	pub, _, _ := ed25519.GenerateKey(rand.Reader)
+
+	nonce := coord.admissionProof(pub, 10)
+	if bits := admissionPoWBits(pub, nonce); bits < 10 {
+		t.Fatalf("proof has %d bits, want 10", bits)
+	}
+	if again := coord.admissionProof(pub, 8); again != nonce {
+		t.Fatal("expected the solved proof to be reused")
+	}
+}
+
+func TestSybilAdmission_ModesGateRoutingAdmission(t *testing.T) {
+	coord := NewMeshCoordinator("self", "us-east", &MockTransport{nodeID: "self"}, nil)
+	coord.config.SybilAdmission.PoWDifficulty = 12
+	coord.config.SybilAdmission.MinStake = 100
+	coord.ledger.RegisterAccount("did:inos:staker", 500)
+
+	worker := AttestationRecord{PoWBits: 14, Attested: time.Now()}
+	staker := AttestationRecord{DID: "did:inos:staker", DIDVerified: true, Attested: time.Now()}
+	claimed := AttestationRecord{DID: "did:inos:staker", Attested: time.Now()}
+
+	coord.config.SybilAdmission.Mode = SybilAdmissionOff
+	if err := coord.checkSybilAdmission(AttestationRecord{}); err != nil {
+		t.Fatalf("off mode should admit everyone: %v", err)
+	}
+
+	coord.config.SybilAdmission.Mode = SybilAdmissionPoW
+	if err := coord.checkSybilAdmission(worker); err != nil {
+		t.Fatalf("sufficient work rejected: %v", err)
+	}
+	if err := coord.checkSybilAdmission(staker); !errors.Is(err, ErrInsufficientWork) {
+		t.Fatalf("expected ErrInsufficientWork, got %v", err)
+	}
+
+	coord.config.SybilAdmission.Mode = SybilAdmissionStake
+	if err := coord.checkSybilAdmission(staker); err != nil {
+		t.Fatalf("sufficient stake rejected: %v", err)
+	}
+	if err := coord.checkSybilAdmission(claimed); !errors.Is(err, ErrInsufficientStake) {
+		t.Fatalf("an unverified DID must not count as stake, got %v", err)
+	}
+
+	coord.config.SybilAdmission.Mode = SybilAdmissionEither
+	for _, record := range []AttestationRecord{worker, staker} {
+		if err := coord.checkSybilAdmission(record); err != nil {
+			t.Fatalf("either mode rejected %+v: %v", record, err)
+		}
+	}
+
+	// A peer without proof never reaches the DHT
+	coord.attestedPeers["sybil"] = claimed
+	coord.acceptConnectedPeer("sybil")
+	if _, ok := coord.dht.GetPeer("sybil"); ok {
+		t.Fatal("expected the unproven peer to be kept out of the DHT")
+	}
+	coord.attestedPeers["honest"] = worker
+	coord.acceptConnectedPeer("honest")
+	if _, ok := coord.dht.GetPeer("honest"); !ok {
+		t.Fatal("expected the proven peer in the DHT")
+	}
+}
@@ -0,0 +1,412 @@
+package mesh
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+)
+
+// Mesh time follows reference nodes. References gossip signed beacons so
+// the rest of the mesh knows whom to follow; a node then measures its
+// offset to each reference with a four-timestamp exchange, keeps the
+// lowest-delay sample per reference, drops references that disagree with
+// the median, and averages the rest.
+const (
+	timeBeaconTopic  = "time.beacon"
+	timeSyncMethod   = "time.sync"
+	timeBeaconDomain = "inos-time-beacon-v1"
+
+	// timeSamplesPerReference is the window the lowest-delay sample is
+	// picked from; queueing delay only ever adds to the round trip.
+	timeSamplesPerReference = 8
+
+	// minOutlierBound keeps outlier rejection from discarding references
+	// that agree within ordinary jitter.
+	minOutlierBound = 20 * time.Millisecond
+)
+
+// TimeBeacon announces a reference clock.
+type TimeBeacon struct {
+	PeerID    string `json:"peer_id"`
+	Time      int64  `json:"time"` // Unix milliseconds; gossip payloads decode numbers as float64
+	Seq       uint64 `json:"seq"`
+	PublicKey []byte `json:"public_key"`
+	Signature []byte `json:"signature"`
+}
+
+func timeBeaconPayload(b *TimeBeacon) []byte {
+	buf := make([]byte, 0, len(timeBeaconDomain)+len(b.PeerID)+17)
+	buf = append(buf, timeBeaconDomain...)
+	buf = append(buf, 0)
+	buf = append(buf, b.PeerID...)
+	buf = binary.BigEndian.AppendUint64(buf, uint64(b.Time))
+	buf = binary.BigEndian.AppendUint64(buf, b.Seq)
+	return buf
+}
+
+type timeSyncRequest struct {
+	Sent int64 `json:"sent"` // Requester clock, Unix nanoseconds
+}
+
+type timeSyncResponse struct {
+	Sent     int64 `json:"sent"`
+	Received int64 `json:"received"` // Reference clock on arrival
+	Replied  int64 `json:"replied"`  // Reference clock on reply
+}
+
+// clockSample is one measurement against a reference.
+type clockSample struct {
+	offset time.Duration // Reference clock minus local clock
+	delay  time.Duration // Round trip excluding the reference's processing
+	at     time.Time
+}
+
+type timeReference struct {
+	lastBeacon time.Time
+	seq        uint64
+	samples    []clockSample
+}
+
+// TimeSyncStatus reports how this node's mesh clock was derived.
+type TimeSyncStatus struct {
+	Reference  bool          `json:"reference"` // This node is a time reference
+	Synced     bool          `json:"synced"`
+	Offset     time.Duration `json:"offset"`     // Added to the local clock to get mesh time
+	References int           `json:"references"` // References heard from recently
+	Used       int           `json:"used"`       // References the current offset came from
+	Rejected   uint64        `json:"rejected"`   // Samples and references discarded as outliers or too slow
+	LastSync   time.Time     `json:"last_sync"`
+}
+
+type timeSyncState struct {
+	mu         sync.Mutex
+	references map[string]*timeReference
+	beaconSeq  uint64
+	used       int
+	lastSync   time.Time
+
+	offset   atomic.Int64
+	synced   atomic.Bool
+	rejected atomic.Uint64
+}
+
+// MeshTime returns the local clock corrected by the mesh offset.
+func (m *MeshCoordinator) MeshTime() time.Time {
+	return time.Now().Add(time.Duration(m.timeSync.offset.Load()))
+}
+
+// MeshEpoch returns the index of the mesh-wide epoch of the given length
+// that contains the current mesh time, so nodes agree on boundaries.
+func (m *MeshCoordinator) MeshEpoch(length time.Duration) uint64 {
+	if length <= 0 {
+		return 0
+	}
+	return uint64(m.MeshTime().UnixNano() / int64(length))
+}
+
+// ClockOffset returns the correction applied to the local clock.
+func (m *MeshCoordinator) ClockOffset() time.Duration {
+	return time.Duration(m.timeSync.offset.Load())
+}
+
+// GetTimeSyncStatus reports the current offset and where it came from.
+func (m *MeshCoordinator) GetTimeSyncStatus() TimeSyncStatus {
+	now := time.Now()
+	m.timeSync.mu.Lock()
+	defer m.timeSync.mu.Unlock()
+
+	status := TimeSyncStatus{
+		Reference: m.config.TimeSync.Reference,
+		Synced:    m.timeSync.synced.Load(),
+		Offset:    m.ClockOffset(),
+		Used:      m.timeSync.used,
+		Rejected:  m.timeSync.rejected.Load(),
+		LastSync:  m.timeSync.lastSync,
+	}
+	for _, ref := range m.timeSync.references {
+		if m.referenceLive(ref, now) {
+			status.References++
+		}
+	}
+	return status
+}
+
+func (m *MeshCoordinator) referenceLive(ref *timeReference, now time.Time) bool {
+	return now.Sub(ref.lastBeacon) <= 3*m.config.TimeSync.BeaconInterval
+}
+
+func (m *MeshCoordinator) trustedTimeReference(peerID string) bool {
+	trusted := m.config.TimeSync.TrustedReferences
+	if len(trusted) == 0 {
+		return true
+	}
+	for _, id := range trusted {
+		if id == peerID {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *MeshCoordinator) timeSyncLoop() {
+	cfg := m.config.TimeSync
+	if cfg.Reference {
+		// References are the source of mesh time and never adjust
+		m.timeSync.synced.Store(true)
+		if cfg.BeaconInterval <= 0 {
+			return
+		}
+		ticker := time.NewTicker(cfg.BeaconInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := m.broadcastTimeBeacon(); err != nil {
+					m.logger.Debug("time beacon failed", "error", err)
+				}
+			case <-m.shutdown:
+				return
+			}
+		}
+	}
+
+	if cfg.SyncInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(cfg.SyncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if !m.deferLowPriority() {
+				m.syncClock(context.Background())
+			}
+		case <-m.shutdown:
+			return
+		}
+	}
+}
+
+func (m *MeshCoordinator) broadcastTimeBeacon() error {
+	m.timeSync.mu.Lock()
+	m.timeSync.beaconSeq++
+	seq := m.timeSync.beaconSeq
+	m.timeSync.mu.Unlock()
+
+	beacon := &TimeBeacon{PeerID: m.nodeID, Time: time.Now().UnixMilli(), Seq: seq}
+	sig, pub, err := m.gossip.SignAttestation(timeBeaconPayload(beacon))
+	if err != nil {
+		return err
+	}
+	beacon.Signature, beacon.PublicKey = sig, pub
+	return m.gossip.Broadcast(timeBeaconTopic, beacon)
+}
+
+// acceptTimeBeacon verifies a beacon and records its sender as a reference.
+func (m *MeshCoordinator) acceptTimeBeacon(beacon TimeBeacon, now time.Time) error {
+	if m.config.TimeSync.Reference || beacon.PeerID == m.nodeID {
+		return nil
+	}
+	if !m.trustedTimeReference(beacon.PeerID) {
+		return fmt.Errorf("untrusted time reference %s", getShortID(beacon.PeerID))
+	}
+	if err := m.checkIdentityKey(beacon.PeerID, beacon.PublicKey); err != nil {
+		return fmt.Errorf("time beacon: %w", err)
+	}
+	if !ed25519.Verify(beacon.PublicKey, timeBeaconPayload(&beacon), beacon.Signature) {
+		return errors.New("invalid time beacon signature")
+	}
+
+	m.timeSync.mu.Lock()
+	defer m.timeSync.mu.Unlock()
+	ref, ok := m.timeSync.references[beacon.PeerID]
+	if !ok {
+		ref = &timeReference{}
+		m.timeSync.references[beacon.PeerID] = ref
+	}
+	if beacon.Seq <= ref.seq {
+		return nil // Replayed or reordered
+	}
+	ref.seq = beacon.Seq
+	ref.lastBeacon = now
+	return nil
+}
+
+// measureClock runs one four-timestamp exchange with a reference.
+func (m *MeshCoordinator) measureClock(ctx context.Context, peerID string) (clockSample, error) {
+	var resp timeSyncResponse
+	t1 := time.Now()
+	if err := m.transport.SendRPC(ctx, peerID, timeSyncMethod, timeSyncRequest{Sent: t1.UnixNano()}, &resp); err != nil {
+		return clockSample{}, err
+	}
+	t4 := time.Now()
+	if resp.Sent != t1.UnixNano() {
+		return clockSample{}, errors.New("time sync reply does not match request")
+	}
+	return clockSampleFrom(t1, time.Unix(0, resp.Received), time.Unix(0, resp.Replied), t4), nil
+}
+
+func clockSampleFrom(t1, t2, t3, t4 time.Time) clockSample {
+	return clockSample{
+		offset: (t2.Sub(t1) + t3.Sub(t4)) / 2,
+		delay:  t4.Sub(t1) - t3.Sub(t2),
+		at:     t4,
+	}
+}
+
+// addClockSample records a measurement, discarding ones slower than
+// MaxDelay since their offset error is bounded only by half the delay.
+func (m *MeshCoordinator) addClockSample(peerID string, sample clockSample) {
+	if max := m.config.TimeSync.MaxDelay; max > 0 && (sample.delay > max || sample.delay < 0) {
+		m.timeSync.rejected.Add(1)
+		return
+	}
+	m.timeSync.mu.Lock()
+	defer m.timeSync.mu.Unlock()
+	ref, ok := m.timeSync.references[peerID]
+	if !ok {
+		return
+	}
+	ref.samples = append(ref.samples, sample)
+	if len(ref.samples) > timeSamplesPerReference {
+		ref.samples = ref.samples[len(ref.samples)-timeSamplesPerReference:]
+	}
+}
+
+// syncClock measures live references and recomputes the offset.
+func (m *MeshCoordinator) syncClock(ctx context.Context) {
+	now := time.Now()
+	m.timeSync.mu.Lock()
+	var peers []string
+	for peerID, ref := range m.timeSync.references {
+		if m.referenceLive(ref, now) {
+			peers = append(peers, peerID)
+		} else {
+			delete(m.timeSync.references, peerID)
+		}
+	}
+	m.timeSync.mu.Unlock()
+
+	sort.Strings(peers)
+	if n := m.config.TimeSync.MaxReferences; n > 0 && len(peers) > n {
+		peers = peers[:n]
+	}
+	for _, peerID := range peers {
+		rctx, cancel := context.WithTimeout(ctx, m.config.TimeSync.MaxDelay+time.Second)
+		sample, err := m.measureClock(rctx, peerID)
+		cancel()
+		if err != nil {
+			m.logger.Debug("clock measurement failed", "peer", getShortID(peerID), "error", err)
+			continue
+		}
+		m.addClockSample(peerID, sample)
+	}
+	m.updateClockOffset(now)
+}
+
+// updateClockOffset takes each reference's lowest-delay sample, rejects
+// references further from the median than three median absolute
+// deviations, and adopts the mean of the rest.
+func (m *MeshCoordinator) updateClockOffset(now time.Time) bool {
+	m.timeSync.mu.Lock()
+	defer m.timeSync.mu.Unlock()
+
+	var offsets []time.Duration
+	for _, ref := range m.timeSync.references {
+		if len(ref.samples) == 0 {
+			continue
+		}
+		best := ref.samples[0]
+		for _, s := range ref.samples[1:] {
+			if s.delay < best.delay {
+				best = s
+			}
+		}
+		offsets = append(offsets, best.offset)
+	}
+	if len(offsets) == 0 {
+		return false
+	}
+
+	median := medianDuration(offsets)
+	deviations := make([]time.Duration, len(offsets))
+	for i, o := range offsets {
+		deviations[i] = absDuration(o - median)
+	}
+	bound := max(3*medianDuration(deviations), minOutlierBound)
+
+	var sum time.Duration
+	used := 0
+	for _, o := range offsets {
+		if absDuration(o-median) > bound {
+			m.timeSync.rejected.Add(1)
+			continue
+		}
+		sum += o
+		used++
+	}
+
+	m.timeSync.offset.Store(int64(sum / time.Duration(used)))
+	m.timeSync.used = used
+	m.timeSync.lastSync = now
+	m.timeSync.synced.Store(true)
+	return true
+}
+
+func medianDuration(values []time.Duration) time.Duration {
+	sorted := append([]time.Duration(nil), values...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+func (m *MeshCoordinator) registerTimeSyncHandler() {
+	m.registerRPC(timeSyncMethod, func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error) {
+		received := m.MeshTime().UnixNano()
+		var req timeSyncRequest
+		if err := json.Unmarshal(args, &req); err != nil {
+			return nil, fmt.Errorf("failed to decode time.sync request: %w", err)
+		}
+		return timeSyncResponse{Sent: req.Sent, Received: received, Replied: m.MeshTime().UnixNano()}, nil
+	})
+}
+
+func (m *MeshCoordinator) registerTimeSyncGossip() {
+	m.gossip.RegisterHandler(timeBeaconTopic, func(msg *common.GossipMessage) error {
+		payload, ok := msg.Payload.(map[string]interface{})
+		if !ok {
+			return errors.New("invalid payload type for time.beacon")
+		}
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		var beacon TimeBeacon
+		if err := json.Unmarshal(data, &beacon); err != nil {
+			return err
+		}
+		if beacon.PeerID != msg.Sender {
+			return errors.New("time beacon peer does not match sender")
+		}
+		return m.acceptTimeBeacon(beacon, time.Now())
+	})
+}
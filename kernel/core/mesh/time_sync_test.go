@@ -0,0 +1,103 @@
+package mesh
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+)
+
+func TestTimeSync_OffsetFromFourTimestamps(t *testing.T) {
+	base := time.Unix(1_700_000_000, 0)
+	// The reference runs 40ms ahead; 10ms each way, 2ms processing.
+	t1 := base
+	t2 := base.Add(50 * time.Millisecond)
+	t3 := t2.Add(2 * time.Millisecond)
+	t4 := base.Add(22 * time.Millisecond)
+
+	s := clockSampleFrom(t1, t2, t3, t4)
+	if s.offset != 40*time.Millisecond || s.delay != 20*time.Millisecond {
+		t.Fatalf("expected offset 40ms delay 20ms, got %v %v", s.offset, s.delay)
+	}
+}
+
+func TestTimeSync_RejectsOutlierReferences(t *testing.T) {
+	coord := NewMeshCoordinator("self", "us-east", &MockTransport{nodeID: "self"}, nil)
+	now := time.Now()
+	offsets := map[string]time.Duration{
+		"ref-a": 100 * time.Millisecond,
+		"ref-b": 104 * time.Millisecond,
+		"ref-c": 96 * time.Millisecond,
+		"ref-d": 5 * time.Second, // Badly wrong clock
+	}
+	for peer, offset := range offsets {
+		coord.timeSync.references[peer] = &timeReference{lastBeacon: now, seq: 1}
+		// A slow sample with a skewed offset loses to the fast one
+		coord.addClockSample(peer, clockSample{offset: offset + 80*time.Millisecond, delay: 200 * time.Millisecond, at: now})
+		coord.addClockSample(peer, clockSample{offset: offset, delay: 10 * time.Millisecond, at: now})
+	}
+	coord.addClockSample("ref-a", clockSample{offset: 0, delay: time.Second, at: now})
+
+	if !coord.updateClockOffset(now) {
+		t.Fatal("expected an offset to be computed")
+	}
+	status := coord.GetTimeSyncStatus()
+	if status.Offset != 100*time.Millisecond || status.Used != 3 || !status.Synced {
+		t.Fatalf("unexpected status %+v", status)
+	}
+	// The slow sample and the outlying reference
+	if status.Rejected != 2 {
+		t.Fatalf("expected 2 rejections, got %d", status.Rejected)
+	}
+	if got := coord.MeshTime().Sub(time.Now()); got < 90*time.Millisecond || got > 110*time.Millisecond {
+		t.Fatalf("mesh time not corrected: %v", got)
+	}
+}
+
+func TestTimeSync_SignedBeaconRegistersReference(t *testing.T) {
+	coord := NewMeshCoordinator("self", "us-east", &MockTransport{nodeID: "self"}, nil)
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+
+	ref := NodeIDFromPublicKey(pub)
+	beacon := TimeBeacon{PeerID: ref, Time: time.Now().UnixMilli(), Seq: 1, PublicKey: pub}
+	beacon.Signature = ed25519.Sign(priv, timeBeaconPayload(&beacon))
+	msg := &common.GossipMessage{
+		ID:        "beacon-1",
+		Sender:    ref,
+		Type:      timeBeaconTopic,
+		Timestamp: time.Now().UnixNano(),
+		MaxHops:   10,
+		Payload: map[string]interface{}{
+			"peer_id":    beacon.PeerID,
+			"time":       float64(beacon.Time),
+			"seq":        float64(beacon.Seq),
+			"public_key": beacon.PublicKey,
+			"signature":  beacon.Signature,
+		},
+		PublicKey: []byte(pub),
+	}
+	signGossipMessage(msg, priv)
+	if err := coord.gossip.ReceiveMessage(ref, msg); err != nil {
+		t.Fatalf("ReceiveMessage failed: %v", err)
+	}
+	if n := coord.GetTimeSyncStatus().References; n != 1 {
+		t.Fatalf("expected one reference, got %d", n)
+	}
+
+	// A forged beacon from another peer is not accepted
+	forged := beacon
+	forged.PeerID = "mallory"
+	if err := coord.acceptTimeBeacon(forged, time.Now()); err == nil {
+		t.Fatal("expected a beacon signed for another peer to be rejected")
+	}
+
+	// With a trust list, unlisted references are ignored
+	coord.config.TimeSync.TrustedReferences = []string{"other"}
+	beacon.Seq = 2
+	beacon.Signature = ed25519.Sign(priv, timeBeaconPayload(&beacon))
+	if err := coord.acceptTimeBeacon(beacon, time.Now()); err == nil {
+		t.Fatal("expected an untrusted reference to be rejected")
+	}
+}
@@ -0,0 +1,272 @@
+package mesh
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrTopologyRateLimited      = errors.New("topology export rate limited")
+	ErrTopologyIdentitiesDenied = errors.New("topology export with identities is not allowed")
+)
+
+// TopologyFormat selects the serialization of a topology export.
+type TopologyFormat string
+
+const (
+	TopologyFormatJSON    TopologyFormat = "json"
+	TopologyFormatGraphML TopologyFormat = "graphml"
+)
+
+// TopologyExportOptions controls a topology export. Raw peer IDs are only
+// included when IncludeIdentities is set and the node config allows it;
+// otherwise nodes carry per-session pseudonyms.
+type TopologyExportOptions struct {
+	Format            TopologyFormat
+	IncludeIdentities bool
+}
+
+// TopologyNode is one mesh member as seen from this node.
+type TopologyNode struct {
+	ID          string  `json:"id"`
+	Self        bool    `json:"self,omitempty"`
+	Region      string  `json:"region,omitempty"`
+	Role        string  `json:"role,omitempty"`
+	Reputation  float32 `json:"reputation"`
+	Synthetic   bool    `json:"synthetic,omitempty"`
+	Quarantined bool    `json:"quarantined,omitempty"`
+	LastSeenSec int64   `json:"last_seen_sec"` // Seconds since last contact
+}
+
+// TopologyEdge is a link between two members. Only links from this node are
+// observable, so every edge has the local node as its source.
+type TopologyEdge struct {
+	Source        string  `json:"source"`
+	Target        string  `json:"target"`
+	Connected     bool    `json:"connected"`
+	LatencyMs     float32 `json:"latency_ms"`
+	BandwidthKbps float32 `json:"bandwidth_kbps"`
+}
+
+// TopologySnapshot is the membership view exported for offline analysis.
+type TopologySnapshot struct {
+	GeneratedAt int64          `json:"generated_at"` // Unix milliseconds
+	Anonymized  bool           `json:"anonymized"`
+	Nodes       []TopologyNode `json:"nodes"`
+	Edges       []TopologyEdge `json:"edges"`
+}
+
+// ExportTopology serializes the current membership view. Exports are rate
+// limited by TopologyExport.MinInterval because building one walks every
+// cached peer.
+func (m *MeshCoordinator) ExportTopology(opts TopologyExportOptions) ([]byte, error) {
+	if opts.IncludeIdentities && !m.config.TopologyExport.AllowIdentities {
+		return nil, ErrTopologyIdentitiesDenied
+	}
+	if opts.Format == "" {
+		opts.Format = TopologyFormatJSON
+	}
+	if opts.Format != TopologyFormatJSON && opts.Format != TopologyFormatGraphML {
+		return nil, fmt.Errorf("unsupported topology format %q", opts.Format)
+	}
+
+	m.topologyMu.Lock()
+	if wait := m.config.TopologyExport.MinInterval - time.Since(m.lastTopologyExport); wait > 0 {
+		m.topologyMu.Unlock()
+		return nil, fmt.Errorf("%w: retry in %s", ErrTopologyRateLimited, wait.Round(time.Second))
+	}
+	m.lastTopologyExport = time.Now()
+	m.topologyMu.Unlock()
+
+	snapshot := m.TopologySnapshot(opts.IncludeIdentities)
+	if opts.Format == TopologyFormatGraphML {
+		return encodeGraphML(snapshot)
+	}
+	return json.Marshal(snapshot)
+}
+
+// TopologySnapshot builds the membership view without rate limiting.
+func (m *MeshCoordinator) TopologySnapshot(includeIdentities bool) TopologySnapshot {
+	now := time.Now()
+	name := func(id string) string { return id }
+	if !includeIdentities {
+		name = m.topologyPseudonym
+	}
+
+	self := name(m.nodeID)
+	snapshot := TopologySnapshot{
+		GeneratedAt: now.UnixMilli(),
+		Anonymized:  !includeIdentities,
+		Nodes:       []TopologyNode{{ID: self, Self: true, Region: m.region, Reputation: 1}},
+		Edges:       []TopologyEdge{},
+	}
+
+	m.peerCacheMu.RLock()
+	members := make(map[string]*PeerCapability, len(m.peerCache))
+	for id, entry := range m.peerCache {
+		if entry.Capability != nil {
+			members[id] = entry.Capability
+		}
+	}
+	m.peerCacheMu.RUnlock()
+	for _, id := range m.transport.GetConnectedPeers() {
+		if _, ok := members[id]; !ok {
+			members[id] = nil // Connected but never announced
+		}
+	}
+
+	ids := make([]string, 0, len(members))
+	for id := range members {
+		if id != m.nodeID {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		node := TopologyNode{
+			ID:          name(id),
+			Synthetic:   IsSyntheticPeer(id),
+			Quarantined: m.isPeerQuarantined(id),
+		}
+		capability := members[id]
+		if capability == nil {
+			capability = &PeerCapability{PeerID: id}
+		} else {
+			node.Region = capability.Region
+			node.Role = capability.Role.String()
+			node.Reputation = capability.Reputation
+		}
+		if capability.LastSeen > 0 {
+			node.LastSeenSec = int64(now.Sub(time.Unix(0, capability.LastSeen)).Seconds())
+		}
+		snapshot.Nodes = append(snapshot.Nodes, node)
+
+		connected := m.transport.IsConnected(id)
+		if !connected && capability.LatencyMs == 0 {
+			continue
+		}
+		edge := TopologyEdge{
+			Source:        self,
+			Target:        node.ID,
+			Connected:     connected,
+			LatencyMs:     capability.LatencyMs,
+			BandwidthKbps: capability.BandwidthKbps,
+		}
+		if measured, ok := m.GetBandwidthMeasurement(id); ok {
+			edge.BandwidthKbps = measured.MeasuredKbps
+		}
+		snapshot.Edges = append(snapshot.Edges, edge)
+	}
+	return snapshot
+}
+
+// topologyPseudonym maps a peer ID to a stable pseudonym for this session.
+// The salt never leaves the node, so pseudonyms cannot be reversed or linked
+// across restarts.
+func (m *MeshCoordinator) topologyPseudonym(id string) string {
+	m.topologyMu.Lock()
+	if m.topologySalt == nil {
+		m.topologySalt = make([]byte, 32)
+		_, _ = rand.Read(m.topologySalt)
+	}
+	salt := m.topologySalt
+	m.topologyMu.Unlock()
+
+	mac := hmac.New(sha256.New, salt)
+	mac.Write([]byte(id))
+	return "n-" + hex.EncodeToString(mac.Sum(nil)[:8])
+}
+
+type graphMLDoc struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+var graphMLKeys = []graphMLKey{
+	{ID: "self", For: "node", Name: "self", Type: "boolean"},
+	{ID: "region", For: "node", Name: "region", Type: "string"},
+	{ID: "role", For: "node", Name: "role", Type: "string"},
+	{ID: "reputation", For: "node", Name: "reputation", Type: "double"},
+	{ID: "synthetic", For: "node", Name: "synthetic", Type: "boolean"},
+	{ID: "quarantined", For: "node", Name: "quarantined", Type: "boolean"},
+	{ID: "last_seen_sec", For: "node", Name: "last_seen_sec", Type: "long"},
+	{ID: "connected", For: "edge", Name: "connected", Type: "boolean"},
+	{ID: "latency_ms", For: "edge", Name: "latency_ms", Type: "double"},
+	{ID: "bandwidth_kbps", For: "edge", Name: "bandwidth_kbps", Type: "double"},
+}
+
+func encodeGraphML(s TopologySnapshot) ([]byte, error) {
+	float := func(v float32) string { return strconv.FormatFloat(float64(v), 'f', -1, 32) }
+
+	doc := graphMLDoc{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys:  graphMLKeys,
+		Graph: graphMLGraph{ID: "inos-mesh", EdgeDefault: "undirected"},
+	}
+	for _, n := range s.Nodes {
+		doc.Graph.Nodes = append(doc.Graph.Nodes, graphMLNode{ID: n.ID, Data: []graphMLData{
+			{Key: "self", Value: strconv.FormatBool(n.Self)},
+			{Key: "region", Value: n.Region},
+			{Key: "role", Value: n.Role},
+			{Key: "reputation", Value: float(n.Reputation)},
+			{Key: "synthetic", Value: strconv.FormatBool(n.Synthetic)},
+			{Key: "quarantined", Value: strconv.FormatBool(n.Quarantined)},
+			{Key: "last_seen_sec", Value: strconv.FormatInt(n.LastSeenSec, 10)},
+		}})
+	}
+	for _, e := range s.Edges {
+		doc.Graph.Edges = append(doc.Graph.Edges, graphMLEdge{Source: e.Source, Target: e.Target, Data: []graphMLData{
+			{Key: "connected", Value: strconv.FormatBool(e.Connected)},
+			{Key: "latency_ms", Value: float(e.LatencyMs)},
+			{Key: "bandwidth_kbps", Value: float(e.BandwidthKbps)},
+		}})
+	}
+
+	out, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), out...), nil
+}
@@ -0,0 +1,107 @@
+package mesh
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTopologyTestCoordinator() *MeshCoordinator {
+	coord := NewMeshCoordinator("self", "us-east", &MockTransport{nodeID: "self"}, nil)
+	now := time.Now()
+	coord.peerCache["peer-a"] = PeerCacheEntry{
+		Capability:  &PeerCapability{PeerID: "peer-a", Region: "eu-west", LatencyMs: 40, BandwidthKbps: 900, Reputation: 0.8, LastSeen: now.UnixNano()},
+		LastUpdated: now,
+	}
+	coord.peerCache["peer-b"] = PeerCacheEntry{
+		Capability:  &PeerCapability{PeerID: "peer-b", Region: "us-east", LatencyMs: 12, Reputation: 0.5, LastSeen: now.UnixNano()},
+		LastUpdated: now,
+	}
+	return coord
+}
+
+func TestTopologyExport_AnonymizesByDefault(t *testing.T) {
+	coord := newTopologyTestCoordinator()
+
+	data, err := coord.ExportTopology(TopologyExportOptions{})
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if strings.Contains(string(data), "peer-a") || strings.Contains(string(data), `"id":"self"`) {
+		t.Fatalf("anonymized export leaked a raw ID: %s", data)
+	}
+
+	var snap TopologySnapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !snap.Anonymized || len(snap.Nodes) != 3 || len(snap.Edges) != 2 {
+		t.Fatalf("unexpected snapshot %+v", snap)
+	}
+	if !snap.Nodes[0].Self || snap.Edges[0].Source != snap.Nodes[0].ID {
+		t.Fatalf("edges should originate at the local node: %+v", snap)
+	}
+	if coord.topologyPseudonym("peer-a") != snap.Nodes[1].ID {
+		t.Fatal("pseudonyms should be stable within a session")
+	}
+}
+
+func TestTopologyExport_IdentitiesRequireConfig(t *testing.T) {
+	coord := newTopologyTestCoordinator()
+	coord.config.TopologyExport.MinInterval = 0
+
+	if _, err := coord.ExportTopology(TopologyExportOptions{IncludeIdentities: true}); !errors.Is(err, ErrTopologyIdentitiesDenied) {
+		t.Fatalf("expected identities to be denied, got %v", err)
+	}
+
+	coord.config.TopologyExport.AllowIdentities = true
+	data, err := coord.ExportTopology(TopologyExportOptions{IncludeIdentities: true})
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if !strings.Contains(string(data), `"peer-a"`) {
+		t.Fatalf("expected raw IDs when allowed: %s", data)
+	}
+}
+
+func TestTopologyExport_GraphML(t *testing.T) {
+	coord := newTopologyTestCoordinator()
+
+	data, err := coord.ExportTopology(TopologyExportOptions{Format: TopologyFormatGraphML})
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	var doc graphMLDoc
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("invalid GraphML: %v", err)
+	}
+	if len(doc.Graph.Nodes) != 3 || len(doc.Graph.Edges) != 2 || len(doc.Keys) != len(graphMLKeys) {
+		t.Fatalf("unexpected GraphML document %+v", doc.Graph)
+	}
+
+	if _, err := coord.ExportTopology(TopologyExportOptions{Format: "dot"}); err == nil {
+		t.Fatal("expected unsupported format error")
+	}
+}
+
+func TestTopologyExport_RateLimited(t *testing.T) {
+	coord := newTopologyTestCoordinator()
+	coord.config.TopologyExport.MinInterval = time.Minute
+
+	if _, err := coord.ExportTopology(TopologyExportOptions{}); err != nil {
+		t.Fatalf("first export: %v", err)
+	}
+	if _, err := coord.ExportTopology(TopologyExportOptions{}); !errors.Is(err, ErrTopologyRateLimited) {
+		t.Fatalf("expected rate limit, got %v", err)
+	}
+
+	coord.topologyMu.Lock()
+	coord.lastTopologyExport = time.Now().Add(-2 * time.Minute)
+	coord.topologyMu.Unlock()
+	if _, err := coord.ExportTopology(TopologyExportOptions{}); err != nil {
+		t.Fatalf("export after interval: %v", err)
+	}
+}
@@ -0,0 +1,108 @@
+package mesh
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+	"github.com/nmxmxh/inos_v1/kernel/utils/tracing"
+)
+
+func useTestRecorder(t *testing.T) *tracing.Recorder {
+	t.Helper()
+	previous := tracing.Default()
+	rec := tracing.NewRecorder("test", 64)
+	tracing.SetDefault(rec)
+	t.Cleanup(func() { tracing.SetDefault(previous) })
+	return rec
+}
+
+func spansByName(rec *tracing.Recorder) map[string]tracing.SpanData {
+	out := make(map[string]tracing.SpanData)
+	for _, s := range rec.Snapshot() {
+		out[s.Name] = s
+	}
+	return out
+}
+
+func TestTracing_DelegateComputeJoinsExecutorSpans(t *testing.T) {
+	rec := useTestRecorder(t)
+	coord, _ := newDelegationTestCoordinator(t)
+
+	if _, err := coord.DelegateCompute(context.Background(), "compress", "input-digest", []byte("source")); err != nil {
+		t.Fatalf("DelegateCompute failed: %v", err)
+	}
+
+	spans := spansByName(rec)
+	client, ok := spans["mesh.delegate_compute"]
+	if !ok {
+		t.Fatalf("missing client span, got %v", spans)
+	}
+	exec, ok := spans["mesh.delegate_compute.execute"]
+	if !ok {
+		t.Fatalf("missing executor span, got %v", spans)
+	}
+	if exec.TraceID != client.TraceID || exec.ParentSpanID != client.SpanID {
+		t.Fatalf("executor span not linked to client span: client=%+v exec=%+v", client, exec)
+	}
+	if client.Kind != tracing.SpanKindClient || client.Status == tracing.StatusError {
+		t.Fatalf("unexpected client span: %+v", client)
+	}
+}
+
+func TestTracing_DelegateRequestCarriesTraceparent(t *testing.T) {
+	rec := useTestRecorder(t)
+	coord, tr := newDelegationTestCoordinator(t)
+
+	resource, err := coord.packResource("deleg-1", "input-digest", []byte("source"))
+	if err != nil {
+		t.Fatalf("packResource failed: %v", err)
+	}
+	req := DelegateRequest{ID: "deleg-1", Operation: "compress", Resource: resource}
+	if err := coord.signDelegationRequest(&req); err != nil {
+		t.Fatalf("signDelegationRequest failed: %v", err)
+	}
+	caller := rec.StartRemote("", "caller", tracing.SpanKindClient)
+	req.TraceParent = caller.Traceparent()
+
+	args, _ := json.Marshal(req)
+	handler := tr.registeredRPCHandlers[delegateComputeMethod]
+	if _, err := handler(capabilityContextFor(t, coord, "peer-1", delegateComputeMethod), "peer-1", args); err != nil {
+		t.Fatalf("delegate handler failed: %v", err)
+	}
+
+	exec := spansByName(rec)["mesh.delegate_compute.execute"]
+	if exec.TraceID != caller.Context().TraceIDString() || exec.ParentSpanID == "" {
+		t.Fatalf("executor span did not adopt the request's traceparent: %+v", exec)
+	}
+}
+
+func TestTracing_RPCMetadataStartsServerSpan(t *testing.T) {
+	rec := useTestRecorder(t)
+	coord, tr := newDelegationTestCoordinator(t)
+	coord.registerRPC("test.Echo", func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error) {
+		return tracing.TraceparentFromContext(ctx), nil
+	})
+
+	callerCtx, caller := tracing.Start(context.Background(), "caller", tracing.SpanKindClient)
+	md := common.OutgoingRPCMetadata(callerCtx)
+	if md.TraceParent != caller.Traceparent() || md.TraceID != caller.Context().TraceIDString() {
+		t.Fatalf("outgoing metadata does not carry the caller span: %+v", md)
+	}
+
+	ctx, cancel := common.IncomingRPCContext(context.Background(), &md, 0)
+	defer cancel()
+	inner, err := tr.registeredRPCHandlers["test.Echo"](ctx, "peer-1", nil)
+	if err != nil {
+		t.Fatalf("handler failed: %v", err)
+	}
+
+	server := spansByName(rec)["test.Echo"]
+	if server.Kind != tracing.SpanKindServer || server.ParentSpanID != caller.Context().SpanIDString() {
+		t.Fatalf("server span not parented by caller: %+v", server)
+	}
+	if inner == caller.Traceparent() || inner == "" {
+		t.Fatalf("handler should run inside the server span, got %v", inner)
+	}
+}
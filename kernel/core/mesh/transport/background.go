@@ -0,0 +1,31 @@
+package transport
+
+import "time"
+
+// backgroundState stretches keepalives while the host page is hidden.
+// Browsers throttle timers in background tabs, so pinging at the foreground
+// rate only queues work that runs late in bursts.
+type backgroundState struct {
+	reset chan struct{} // Wakes the connection manager to re-arm its ticker
+}
+
+// SetBackground switches keepalives and the stale-connection threshold to
+// BackgroundKeepAliveInterval, or back to KeepAliveInterval.
+func (t *WebRTCTransport) SetBackground(background bool) {
+	if t.inBackground.Swap(background) == background {
+		return
+	}
+	t.logger.Debug("keepalive interval changed", "background", background, "interval", t.keepAliveInterval())
+	select {
+	case t.background.reset <- struct{}{}:
+	default:
+	}
+}
+
+// keepAliveInterval is the keepalive period for the current mode.
+func (t *WebRTCTransport) keepAliveInterval() time.Duration {
+	if t.inBackground.Load() && t.config.BackgroundKeepAliveInterval > t.config.KeepAliveInterval {
+		return t.config.BackgroundKeepAliveInterval
+	}
+	return t.config.KeepAliveInterval
+}
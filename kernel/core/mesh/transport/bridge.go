@@ -0,0 +1,173 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+)
+
+// Bridge addresses name a peer's WebSocket bridge listener rather than a
+// signaling server: "inos+ws://host:port/path" or "inos+wss://...". A
+// bridge link carries the same envelope frames as a WebRTC data channel,
+// so browser kernels can dial native nodes directly and both share one
+// DHT and gossip mesh.
+const bridgeSchemePrefix = "inos+"
+
+// bridgeHelloType opens every bridge link. Each side sends one hello naming
+// its node ID; the dialer speaks first. The ID is not authenticated here:
+// the mesh attests peers over the link like any other connection.
+const bridgeHelloType = "bridge_hello"
+
+// ErrBridgeListenUnsupported is returned in the browser, which cannot
+// accept connections.
+var ErrBridgeListenUnsupported = errors.New("bridge listener is not available in the browser")
+
+// bridgeState tracks bridge addresses learned for peers and the listener.
+type bridgeState struct {
+	mu       sync.RWMutex
+	addrs    map[string]string // peerID -> bridge address
+	server   *http.Server
+	listenAt string
+}
+
+type bridgeHello struct {
+	NodeID string `json:"node_id"`
+}
+
+// IsBridgeAddress reports whether addr names a WebSocket bridge listener.
+func IsBridgeAddress(addr string) bool {
+	return strings.HasPrefix(addr, bridgeSchemePrefix+"ws://") || strings.HasPrefix(addr, bridgeSchemePrefix+"wss://")
+}
+
+// SetPeerAddress records the bridge address a peer listens on. Connect
+// dials it directly instead of negotiating WebRTC through signaling.
+func (t *WebRTCTransport) SetPeerAddress(peerID, addr string) error {
+	if peerID == "" {
+		return errors.New("peer ID is required")
+	}
+	if !IsBridgeAddress(addr) {
+		return fmt.Errorf("not a bridge address: %q", addr)
+	}
+	t.bridge.mu.Lock()
+	defer t.bridge.mu.Unlock()
+	if t.bridge.addrs == nil {
+		t.bridge.addrs = make(map[string]string)
+	}
+	t.bridge.addrs[peerID] = addr
+	return nil
+}
+
+func (t *WebRTCTransport) peerBridgeAddress(peerID string) (string, bool) {
+	t.bridge.mu.RLock()
+	defer t.bridge.mu.RUnlock()
+	addr, ok := t.bridge.addrs[peerID]
+	return addr, ok
+}
+
+// DialBridge connects to the bridge listener at addr and returns the node
+// ID it announced. Use it for bootstrap entries that name no peer.
+func (t *WebRTCTransport) DialBridge(ctx context.Context, addr string) (string, error) {
+	if !IsBridgeAddress(addr) {
+		return "", fmt.Errorf("not a bridge address: %q", addr)
+	}
+	if !t.started.Load() {
+		if err := t.Start(ctx); err != nil {
+			t.logger.Warn("failed to auto-start transport", "error", err)
+		}
+	}
+	ctx, cancel := context.WithTimeout(ctx, t.config.ConnectionTimeout)
+	defer cancel()
+
+	peerID, err := t.dialBridge(ctx, strings.TrimPrefix(addr, bridgeSchemePrefix), "")
+	if err != nil {
+		return "", err
+	}
+	_ = t.SetPeerAddress(peerID, addr)
+	return peerID, nil
+}
+
+// BridgeListenAddr returns the address the bridge listener is bound to, or
+// "" when none is running.
+func (t *WebRTCTransport) BridgeListenAddr() string {
+	t.bridge.mu.RLock()
+	defer t.bridge.mu.RUnlock()
+	return t.bridge.listenAt
+}
+
+func (t *WebRTCTransport) stopBridgeListener() {
+	t.bridge.mu.Lock()
+	server := t.bridge.server
+	t.bridge.server = nil
+	t.bridge.listenAt = ""
+	t.bridge.mu.Unlock()
+	if server != nil {
+		_ = server.Close()
+	}
+}
+
+func (t *WebRTCTransport) bridgeHelloFrame() ([]byte, error) {
+	payload, err := json.Marshal(bridgeHello{NodeID: t.nodeID})
+	if err != nil {
+		return nil, err
+	}
+	env := &common.Envelope{
+		ID:        t.nodeID,
+		Type:      bridgeHelloType,
+		Timestamp: time.Now().UnixNano(),
+		Payload:   payload,
+	}
+	return env.Marshal()
+}
+
+// checkBridgeHello parses a peer's hello. expected, when set, is the peer
+// the dialer meant to reach.
+func (t *WebRTCTransport) checkBridgeHello(data []byte, expected string) (string, error) {
+	env := &common.Envelope{}
+	if err := env.Unmarshal(data); err != nil {
+		return "", fmt.Errorf("invalid bridge hello: %w", err)
+	}
+	if env.Type != bridgeHelloType {
+		return "", fmt.Errorf("expected bridge hello, got %q", env.Type)
+	}
+	var hello bridgeHello
+	if err := json.Unmarshal(env.Payload, &hello); err != nil {
+		return "", fmt.Errorf("invalid bridge hello: %w", err)
+	}
+	switch {
+	case hello.NodeID == "":
+		return "", errors.New("bridge hello carries no node ID")
+	case hello.NodeID == t.nodeID:
+		return "", errors.New("bridge peer is this node")
+	case expected != "" && hello.NodeID != expected:
+		return "", fmt.Errorf("bridge peer is %s, expected %s", getShortID(hello.NodeID), getShortID(expected))
+	}
+	return hello.NodeID, nil
+}
+
+// addBridgeConnection registers a bridge link once its hello is through.
+// A peer that is already connected keeps its existing link.
+func (t *WebRTCTransport) addBridgeConnection(peerID string, conn Connection) error {
+	t.connMu.Lock()
+	if existing, ok := t.connections[peerID]; ok && existing.Connected && existing.Connection != nil && existing.Connection.IsOpen() {
+		t.connMu.Unlock()
+		return fmt.Errorf("peer %s is already connected", getShortID(peerID))
+	}
+	t.connections[peerID] = &PeerConnection{
+		PeerID:      peerID,
+		Connection:  conn,
+		Connected:   true,
+		LastContact: time.Now(),
+	}
+	t.connMu.Unlock()
+
+	t.logger.Info("bridge connection established", "peer", getShortID(peerID))
+	t.notifyPeerEvent(peerID, true)
+	return nil
+}
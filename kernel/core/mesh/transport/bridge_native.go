@@ -0,0 +1,141 @@
+//go:build !js || !wasm
+
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// ListenBridge accepts bridge links on addr until the transport stops.
+// Browser kernels and other nodes reach it as "inos+ws://<addr>/".
+func (t *WebRTCTransport) ListenBridge(addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen for bridge links: %w", err)
+	}
+	server := &http.Server{
+		Handler:           t.BridgeHandler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	t.bridge.mu.Lock()
+	if t.bridge.server != nil {
+		t.bridge.mu.Unlock()
+		_ = ln.Close()
+		return errors.New("bridge listener already running")
+	}
+	t.bridge.server = server
+	t.bridge.listenAt = ln.Addr().String()
+	t.bridge.mu.Unlock()
+
+	t.logger.Info("bridge listener started", "addr", ln.Addr().String())
+	go func() {
+		if err := server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			t.logger.Warn("bridge listener stopped", "error", err)
+		}
+	}()
+	return nil
+}
+
+// BridgeHandler upgrades HTTP requests to bridge links, for nodes that
+// mount the bridge on a server of their own.
+func (t *WebRTCTransport) BridgeHandler() http.Handler {
+	upgrader := websocket.Upgrader{
+		HandshakeTimeout: t.config.ConnectionTimeout,
+		ReadBufferSize:   t.config.MaxMessageSize,
+		WriteBufferSize:  t.config.MaxMessageSize,
+		// Browser kernels are served from any origin; peers are attested
+		// by the mesh, not by where their page came from.
+		CheckOrigin: func(*http.Request) bool { return true },
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return // The upgrader has already replied
+		}
+		if err := t.acceptBridge(conn); err != nil {
+			t.logger.Debug("bridge link refused", "remote", r.RemoteAddr, "error", err)
+			_ = conn.Close()
+		}
+	})
+}
+
+// acceptBridge answers a dialer's hello and registers the link.
+func (t *WebRTCTransport) acceptBridge(conn *websocket.Conn) error {
+	_ = conn.SetReadDeadline(time.Now().Add(t.config.ConnectionTimeout))
+	_, data, err := conn.ReadMessage()
+	if err != nil {
+		return err
+	}
+	peerID, err := t.checkBridgeHello(data, "")
+	if err != nil {
+		return err
+	}
+	hello, err := t.bridgeHelloFrame()
+	if err != nil {
+		return err
+	}
+	if err := conn.WriteMessage(websocket.BinaryMessage, hello); err != nil {
+		return err
+	}
+	_ = conn.SetReadDeadline(time.Time{})
+	return t.startBridgeConnection(peerID, conn)
+}
+
+// dialBridge opens a bridge link to wsURL. expected, when set, is the peer
+// the link must reach.
+func (t *WebRTCTransport) dialBridge(ctx context.Context, wsURL, expected string) (string, error) {
+	conn, err := t.dialWebSocket(ctx, wsURL)
+	if err != nil {
+		return "", err
+	}
+	peerID, err := t.bridgeHandshake(ctx, conn, expected)
+	if err == nil {
+		err = t.startBridgeConnection(peerID, conn)
+	}
+	if err != nil {
+		_ = conn.Close()
+		return "", err
+	}
+	return peerID, nil
+}
+
+func (t *WebRTCTransport) bridgeHandshake(ctx context.Context, conn *websocket.Conn, expected string) (string, error) {
+	hello, err := t.bridgeHelloFrame()
+	if err != nil {
+		return "", err
+	}
+	if err := conn.WriteMessage(websocket.BinaryMessage, hello); err != nil {
+		return "", err
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(t.config.ConnectionTimeout)
+	}
+	_ = conn.SetReadDeadline(deadline)
+	_, data, err := conn.ReadMessage()
+	if err != nil {
+		return "", fmt.Errorf("no bridge hello: %w", err)
+	}
+	_ = conn.SetReadDeadline(time.Time{})
+	return t.checkBridgeHello(data, expected)
+}
+
+func (t *WebRTCTransport) startBridgeConnection(peerID string, conn *websocket.Conn) error {
+	wsConn := newWebSocketConnection(peerID, conn)
+	if err := t.addBridgeConnection(peerID, wsConn); err != nil {
+		return err
+	}
+	go func() {
+		wsConn.receiveLoop(t.handleIncomingMessage)
+		t.linkClosed(peerID, wsConn)
+	}()
+	return nil
+}
@@ -0,0 +1,84 @@
+//go:build !js || !wasm
+
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newBridgeTestTransport(t *testing.T, nodeID, listen string) *WebRTCTransport {
+	config := DefaultTransportConfig()
+	config.BridgeListen = listen
+	config.SignalingServers = nil
+	config.ConnectionTimeout = 5 * time.Second
+	tr, err := NewWebRTCTransport(nodeID, config, nil)
+	require.NoError(t, err)
+	require.NoError(t, tr.Start(context.Background()))
+	t.Cleanup(func() { _ = tr.Stop() })
+	return tr
+}
+
+func TestBridge_DialCarriesRPCsBothWays(t *testing.T) {
+	native := newBridgeTestTransport(t, "native-node", "127.0.0.1:0")
+	browser := newBridgeTestTransport(t, "browser-node", "")
+
+	echo := func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error) {
+		return map[string]string{"from": peerID}, nil
+	}
+	native.RegisterRPCHandler("whoami", echo)
+	browser.RegisterRPCHandler("whoami", echo)
+
+	addr := "inos+ws://" + native.BridgeListenAddr() + "/"
+	peerID, err := browser.DialBridge(context.Background(), addr)
+	require.NoError(t, err)
+	assert.Equal(t, "native-node", peerID)
+	require.Eventually(t, func() bool { return native.IsConnected("browser-node") }, 2*time.Second, 10*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var reply map[string]string
+	require.NoError(t, browser.SendRPC(ctx, "native-node", "whoami", nil, &reply))
+	assert.Equal(t, "browser-node", reply["from"])
+	require.NoError(t, native.SendRPC(ctx, "browser-node", "whoami", nil, &reply))
+	assert.Equal(t, "native-node", reply["from"])
+
+	// The dialed address is remembered for reconnects
+	got, ok := browser.peerBridgeAddress("native-node")
+	assert.True(t, ok)
+	assert.Equal(t, addr, got)
+
+	require.NoError(t, browser.Disconnect("native-node"))
+	require.Eventually(t, func() bool { return !native.IsConnected("browser-node") }, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestBridge_ConnectUsesPeerAddress(t *testing.T) {
+	native := newBridgeTestTransport(t, "native-node", "127.0.0.1:0")
+	browser := newBridgeTestTransport(t, "browser-node", "")
+	addr := "inos+ws://" + native.BridgeListenAddr() + "/"
+
+	// A listener that answers as someone else is refused
+	require.NoError(t, browser.SetPeerAddress("impostor", addr))
+	assert.Error(t, browser.Connect(context.Background(), "impostor"))
+	assert.False(t, browser.IsConnected("native-node"))
+
+	require.NoError(t, browser.SetPeerAddress("native-node", addr))
+	require.NoError(t, browser.Connect(context.Background(), "native-node"))
+	assert.True(t, browser.IsConnected("native-node"))
+}
+
+func TestIsBridgeAddress(t *testing.T) {
+	assert.True(t, IsBridgeAddress("inos+ws://127.0.0.1:7469/"))
+	assert.True(t, IsBridgeAddress("inos+wss://node.example.com/bridge"))
+	assert.False(t, IsBridgeAddress("wss://signal.example.com"))
+	assert.False(t, IsBridgeAddress("gossip://mesh"))
+
+	tr, err := NewWebRTCTransport("self", DefaultTransportConfig(), nil)
+	require.NoError(t, err)
+	assert.Error(t, tr.SetPeerAddress("peer", "wss://signal.example.com"))
+}
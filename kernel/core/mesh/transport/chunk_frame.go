@@ -0,0 +1,159 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"sync"
+)
+
+// chunkFrameMagic opens a chunk frame header. Envelopes are Cap'n Proto
+// messages whose first word is a small segment count, so the two cannot be
+// confused.
+var chunkFrameMagic = []byte("INCF")
+
+// maxParkedChunkFrames bounds the chunk bodies held per peer waiting for
+// the request that claims them.
+const maxParkedChunkFrames = 8
+
+// chunkFrameHeader announces a raw chunk body sent as the next message on
+// the same connection.
+type chunkFrameHeader struct {
+	ChunkHash string
+	Size      uint32
+}
+
+func encodeChunkFrameHeader(chunkHash string, size int) []byte {
+	buf := make([]byte, 0, len(chunkFrameMagic)+2+len(chunkHash)+4)
+	buf = append(buf, chunkFrameMagic...)
+	buf = binary.BigEndian.AppendUint16(buf, uint16(len(chunkHash)))
+	buf = append(buf, chunkHash...)
+	return binary.BigEndian.AppendUint32(buf, uint32(size))
+}
+
+func decodeChunkFrameHeader(data []byte) (chunkFrameHeader, bool) {
+	if !bytes.HasPrefix(data, chunkFrameMagic) {
+		return chunkFrameHeader{}, false
+	}
+	rest := data[len(chunkFrameMagic):]
+	if len(rest) < 2 {
+		return chunkFrameHeader{}, false
+	}
+	hashLen := int(binary.BigEndian.Uint16(rest))
+	rest = rest[2:]
+	if hashLen == 0 || len(rest) != hashLen+4 {
+		return chunkFrameHeader{}, false
+	}
+	return chunkFrameHeader{
+		ChunkHash: string(rest[:hashLen]),
+		Size:      binary.BigEndian.Uint32(rest[hashLen:]),
+	}, true
+}
+
+type parkedChunkFrame struct {
+	chunkHash string
+	data      []byte
+}
+
+// chunkFrameState tracks, per peer, a header whose body is due next and the
+// bodies received but not yet claimed.
+type chunkFrameState struct {
+	mu        sync.Mutex
+	expecting map[string]chunkFrameHeader
+	parked    map[string][]parkedChunkFrame
+}
+
+// SendChunkFrame sends a chunk as a binary header followed by the chunk
+// bytes as their own message. data is handed to the connection as is, so a
+// chunk that lives in the SAB reaches the data channel without a Go-side
+// copy or a JSON/base64 encoding. Both messages go out under the
+// connection's send lock, so nothing else lands between them.
+func (t *WebRTCTransport) SendChunkFrame(ctx context.Context, peerID, chunkHash string, data []byte) error {
+	if chunkHash == "" || len(chunkHash) > 0xFFFF {
+		return errors.New("invalid chunk hash")
+	}
+	if !t.IsConnected(peerID) {
+		return errors.New("not connected to peer")
+	}
+	t.connMu.RLock()
+	conn, exists := t.connections[peerID]
+	t.connMu.RUnlock()
+	if !exists || conn.Connection == nil {
+		return errors.New("connection not found")
+	}
+
+	header := encodeChunkFrameHeader(chunkHash, len(data))
+	if err := t.sendFrames(ctx, conn, header, data); err != nil {
+		t.metricsMu.Lock()
+		t.metrics.FailedMessages++
+		t.metricsMu.Unlock()
+		return err
+	}
+	t.recordMessageSent(len(header) + len(data))
+	return nil
+}
+
+// TakeChunkFrame claims a chunk body a peer sent with SendChunkFrame. Each
+// body can be taken once.
+func (t *WebRTCTransport) TakeChunkFrame(peerID, chunkHash string) ([]byte, bool) {
+	s := &t.chunkFrames
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	frames := s.parked[peerID]
+	for i, frame := range frames {
+		if frame.chunkHash == chunkHash {
+			s.parked[peerID] = append(frames[:i:i], frames[i+1:]...)
+			if len(s.parked[peerID]) == 0 {
+				delete(s.parked, peerID)
+			}
+			return frame.data, true
+		}
+	}
+	return nil, false
+}
+
+// handleChunkFrame takes a chunk frame header or the body it announced, and
+// reports whether the message was one. A message that does not match the
+// announced size is not treated as the body, so a lost body costs only its
+// header.
+func (t *WebRTCTransport) handleChunkFrame(peerID string, data []byte) bool {
+	s := &t.chunkFrames
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if header, ok := s.expecting[peerID]; ok {
+		delete(s.expecting, peerID)
+		if int(header.Size) == len(data) {
+			if s.parked == nil {
+				s.parked = make(map[string][]parkedChunkFrame)
+			}
+			frames := s.parked[peerID]
+			if len(frames) >= maxParkedChunkFrames {
+				frames = frames[1:]
+			}
+			s.parked[peerID] = append(frames, parkedChunkFrame{chunkHash: header.ChunkHash, data: data})
+			return true
+		}
+		t.logger.Debug("chunk frame body missing", "peer", getShortID(peerID), "chunk", getShortID(header.ChunkHash))
+	}
+
+	header, ok := decodeChunkFrameHeader(data)
+	if !ok {
+		return false
+	}
+	if s.expecting == nil {
+		s.expecting = make(map[string]chunkFrameHeader)
+	}
+	s.expecting[peerID] = header
+	return true
+}
+
+// dropChunkFrames forgets a disconnected peer's pending chunk frames.
+func (t *WebRTCTransport) dropChunkFrames(peerID string) {
+	s := &t.chunkFrames
+	s.mu.Lock()
+	delete(s.expecting, peerID)
+	delete(s.parked, peerID)
+	s.mu.Unlock()
+}
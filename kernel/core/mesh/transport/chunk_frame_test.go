@@ -0,0 +1,59 @@
+//go:build !js || !wasm
+
+package transport
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+)
+
+func TestChunkFrame_BodyIsSentUncopiedAndParkedForTheClaim(t *testing.T) {
+	a, b, aConn := pipedTransports(t)
+	data := bytes.Repeat([]byte("chunk"), 200)
+	if err := a.SendChunkFrame(context.Background(), "node-b", "hash-1", data); err != nil {
+		t.Fatalf("SendChunkFrame failed: %v", err)
+	}
+
+	aConn.mu.RLock()
+	sent := aConn.sent
+	aConn.mu.RUnlock()
+	if len(sent) != 2 || &sent[1][0] != &data[0] {
+		t.Fatalf("expected a header and the caller's own buffer, got %d frames", len(sent))
+	}
+
+	var body []byte
+	deadline := time.Now().Add(time.Second)
+	for body == nil && time.Now().Before(deadline) {
+		body, _ = b.TakeChunkFrame("node-a", "hash-1")
+		time.Sleep(5 * time.Millisecond)
+	}
+	if !bytes.Equal(body, data) {
+		t.Fatalf("expected the body parked for node-a, got %d bytes", len(body))
+	}
+	if _, ok := b.TakeChunkFrame("node-a", "hash-1"); ok {
+		t.Fatal("expected a body to be claimable once")
+	}
+}
+
+func TestChunkFrame_MessageOfWrongSizeIsNotTakenAsBody(t *testing.T) {
+	cfg := DefaultTransportConfig()
+	tr, _ := NewWebRTCTransport("node-b", cfg, nil)
+
+	if !tr.handleChunkFrame("node-a", encodeChunkFrameHeader("hash-1", 64)) {
+		t.Fatal("expected the header to be taken")
+	}
+	env, _ := (&common.Envelope{ID: "1", Type: "ping"}).Marshal()
+	if tr.handleChunkFrame("node-a", env) {
+		t.Fatal("expected an envelope after a header to be handled normally")
+	}
+	if _, ok := tr.TakeChunkFrame("node-a", "hash-1"); ok {
+		t.Fatal("expected no body parked")
+	}
+	if tr.handleChunkFrame("node-a", make([]byte, 64)) {
+		t.Fatal("expected the header to be forgotten")
+	}
+}
@@ -0,0 +1,382 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pion/webrtc/v3"
+)
+
+// NATType classifies how this node is reachable from the wider mesh.
+type NATType string
+
+const (
+	NATUnknown         NATType = "unknown"
+	NATOpenInternet    NATType = "open_internet" // host address is public
+	NATEndpointMapping NATType = "cone"          // same mapping for every STUN server
+	NATSymmetric       NATType = "symmetric"     // mapping varies per destination
+	NATUDPBlocked      NATType = "udp_blocked"   // no server-reflexive or relay candidates
+	NATRelayOnly       NATType = "relay_only"    // only TURN relay candidates gathered
+)
+
+// Candidate types as reported by ICE gathering
+const (
+	CandidateHost  = "host"
+	CandidateSrflx = "srflx"
+	CandidatePrflx = "prflx"
+	CandidateRelay = "relay"
+)
+
+const connectivityProbeTimeout = 5 * time.Second
+
+// ICEServerProbe is the outcome of an ICE gathering test against one server.
+type ICEServerProbe struct {
+	URL           string         `json:"url"`
+	Kind          string         `json:"kind"` // stun | turn
+	Success       bool           `json:"success"`
+	Candidates    map[string]int `json:"candidates"`
+	MappedAddress string         `json:"mapped_address,omitempty"`
+	DurationMs    int64          `json:"duration_ms"`
+	Error         string         `json:"error,omitempty"`
+}
+
+// CandidateTypeStats tracks gathering success per ICE candidate type across probes.
+type CandidateTypeStats struct {
+	Attempts    uint64  `json:"attempts"`
+	Successes   uint64  `json:"successes"`
+	SuccessRate float64 `json:"success_rate"`
+}
+
+// ConnectivityReport summarises NAT traversal capability for diagnostics.
+type ConnectivityReport struct {
+	NATType         NATType                       `json:"nat_type"`
+	PublicAddresses []string                      `json:"public_addresses"`
+	HostAddresses   []string                      `json:"host_addresses"`
+	Servers         []ICEServerProbe              `json:"servers"`
+	CandidateStats  map[string]CandidateTypeStats `json:"candidate_stats"`
+	WebRTCSupported bool                          `json:"webrtc_supported"`
+	GeneratedAt     time.Time                     `json:"generated_at"`
+}
+
+// connectivityState holds the latest report and cumulative candidate stats.
+type connectivityState struct {
+	mu      sync.RWMutex
+	last    *ConnectivityReport
+	stats   map[string]*CandidateTypeStats
+	probing bool
+}
+
+// ProbeConnectivity runs ICE gathering against every configured STUN/TURN
+// server, classifies the NAT type and records per-candidate-type success rates.
+func (t *WebRTCTransport) ProbeConnectivity(ctx context.Context) (*ConnectivityReport, error) {
+	t.connectivity.mu.Lock()
+	if t.connectivity.probing {
+		t.connectivity.mu.Unlock()
+		return nil, errors.New("connectivity probe already running")
+	}
+	t.connectivity.probing = true
+	t.connectivity.mu.Unlock()
+
+	defer func() {
+		t.connectivity.mu.Lock()
+		t.connectivity.probing = false
+		t.connectivity.mu.Unlock()
+	}()
+
+	report := &ConnectivityReport{
+		NATType:         NATUnknown,
+		WebRTCSupported: t.isWebRTCSupported(),
+		GeneratedAt:     time.Now(),
+	}
+
+	servers := t.iceServerURLs()
+	if !report.WebRTCSupported || len(servers) == 0 {
+		t.storeConnectivityReport(report)
+		return report, nil
+	}
+
+	hostSet := make(map[string]struct{})
+	for _, server := range servers {
+		probe, hosts := t.probeICEServer(ctx, server)
+		for _, h := range hosts {
+			hostSet[h] = struct{}{}
+		}
+		report.Servers = append(report.Servers, probe)
+		t.recordCandidateOutcome(probe)
+	}
+
+	for h := range hostSet {
+		report.HostAddresses = append(report.HostAddresses, h)
+	}
+	report.NATType, report.PublicAddresses = classifyNAT(report.HostAddresses, report.Servers)
+	t.storeConnectivityReport(report)
+
+	t.logger.Info("connectivity probe complete",
+		"nat_type", report.NATType,
+		"servers", len(report.Servers),
+		"public_addresses", report.PublicAddresses)
+
+	return report, nil
+}
+
+// GetConnectivityReport returns the most recent probe result, or nil.
+func (t *WebRTCTransport) GetConnectivityReport() *ConnectivityReport {
+	t.connectivity.mu.RLock()
+	defer t.connectivity.mu.RUnlock()
+	if t.connectivity.last == nil {
+		return nil
+	}
+	report := *t.connectivity.last
+	return &report
+}
+
+func (t *WebRTCTransport) storeConnectivityReport(report *ConnectivityReport) {
+	t.connectivity.mu.Lock()
+	defer t.connectivity.mu.Unlock()
+
+	report.CandidateStats = make(map[string]CandidateTypeStats, len(t.connectivity.stats))
+	for typ, s := range t.connectivity.stats {
+		report.CandidateStats[typ] = *s
+	}
+	t.connectivity.last = report
+}
+
+// recordCandidateOutcome counts an attempt for every candidate type the server
+// kind should yield, and a success when gathering produced it.
+func (t *WebRTCTransport) recordCandidateOutcome(probe ICEServerProbe) {
+	expected := []string{CandidateHost}
+	switch probe.Kind {
+	case "stun":
+		expected = append(expected, CandidateSrflx)
+	case "turn":
+		expected = append(expected, CandidateRelay)
+	}
+
+	t.connectivity.mu.Lock()
+	defer t.connectivity.mu.Unlock()
+	if t.connectivity.stats == nil {
+		t.connectivity.stats = make(map[string]*CandidateTypeStats)
+	}
+	for _, typ := range expected {
+		s, ok := t.connectivity.stats[typ]
+		if !ok {
+			s = &CandidateTypeStats{}
+			t.connectivity.stats[typ] = s
+		}
+		s.Attempts++
+		if probe.Candidates[typ] > 0 {
+			s.Successes++
+		}
+		s.SuccessRate = float64(s.Successes) / float64(s.Attempts)
+	}
+}
+
+func (t *WebRTCTransport) iceServerURLs() []string {
+	servers := make([]string, 0, len(t.config.ICEServers)+len(t.config.STUNServers)+len(t.config.TURNServers))
+	servers = append(servers, t.config.ICEServers...)
+	servers = append(servers, t.config.STUNServers...)
+	servers = append(servers, t.config.TURNServers...)
+	return dedupeStrings(servers)
+}
+
+// probeICEServer gathers candidates using a single ICE server in isolation.
+func (t *WebRTCTransport) probeICEServer(ctx context.Context, server string) (ICEServerProbe, []string) {
+	probe := ICEServerProbe{
+		URL:        server,
+		Kind:       iceServerKind(server),
+		Candidates: make(map[string]int),
+	}
+	start := time.Now()
+
+	pc, err := webrtc.NewPeerConnection(webrtc.Configuration{
+		ICEServers: []webrtc.ICEServer{{URLs: []string{server}}},
+	})
+	if err != nil {
+		probe.Error = err.Error()
+		probe.DurationMs = time.Since(start).Milliseconds()
+		return probe, nil
+	}
+	defer pc.Close()
+
+	var (
+		mu    sync.Mutex
+		hosts []string
+	)
+	pc.OnICECandidate(func(c *webrtc.ICECandidate) {
+		if c == nil {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		typ := c.Typ.String()
+		probe.Candidates[typ]++
+		addr := net.JoinHostPort(c.Address, fmt.Sprint(c.Port))
+		switch typ {
+		case CandidateHost:
+			hosts = append(hosts, c.Address)
+		case CandidateSrflx, CandidateRelay:
+			if probe.MappedAddress == "" {
+				probe.MappedAddress = addr
+			}
+		}
+	})
+
+	if _, err := pc.CreateDataChannel("probe", nil); err != nil {
+		probe.Error = err.Error()
+		probe.DurationMs = time.Since(start).Milliseconds()
+		return probe, nil
+	}
+	offer, err := pc.CreateOffer(nil)
+	if err != nil {
+		probe.Error = err.Error()
+		probe.DurationMs = time.Since(start).Milliseconds()
+		return probe, nil
+	}
+	gathered := webrtc.GatheringCompletePromise(pc)
+	if err := pc.SetLocalDescription(offer); err != nil {
+		probe.Error = err.Error()
+		probe.DurationMs = time.Since(start).Milliseconds()
+		return probe, nil
+	}
+
+	timeout := time.NewTimer(connectivityProbeTimeout)
+	defer timeout.Stop()
+	select {
+	case <-gathered:
+	case <-timeout.C:
+		probe.Error = "ice gathering timed out"
+	case <-ctx.Done():
+		probe.Error = ctx.Err().Error()
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	switch probe.Kind {
+	case "stun":
+		probe.Success = probe.Candidates[CandidateSrflx] > 0
+	case "turn":
+		probe.Success = probe.Candidates[CandidateRelay] > 0
+	default:
+		probe.Success = probe.Candidates[CandidateHost] > 0
+	}
+	if !probe.Success && probe.Error == "" {
+		probe.Error = "no " + probe.Kind + " candidates gathered"
+	}
+	probe.DurationMs = time.Since(start).Milliseconds()
+	return probe, append([]string(nil), hosts...)
+}
+
+func iceServerKind(server string) string {
+	switch {
+	case strings.HasPrefix(server, "stun:"), strings.HasPrefix(server, "stuns:"):
+		return "stun"
+	case strings.HasPrefix(server, "turn:"), strings.HasPrefix(server, "turns:"):
+		return "turn"
+	default:
+		return "unknown"
+	}
+}
+
+// classifyNAT infers NAT behaviour from gathered candidates. Identical mapped
+// addresses across STUN servers indicate endpoint-independent mapping; differing
+// ports indicate a symmetric NAT that will need TURN for most peers.
+func classifyNAT(hostAddrs []string, probes []ICEServerProbe) (NATType, []string) {
+	mapped := make(map[string]struct{})
+	mappedIPs := make(map[string]struct{})
+	stunOK, relayOK := 0, 0
+
+	for _, p := range probes {
+		if !p.Success {
+			continue
+		}
+		switch p.Kind {
+		case "stun":
+			stunOK++
+			mapped[p.MappedAddress] = struct{}{}
+			if host, _, err := net.SplitHostPort(p.MappedAddress); err == nil {
+				mappedIPs[host] = struct{}{}
+			}
+		case "turn":
+			relayOK++
+		}
+	}
+
+	public := make([]string, 0, len(mapped))
+	for addr := range mapped {
+		public = append(public, addr)
+	}
+
+	if stunOK == 0 {
+		if relayOK > 0 {
+			return NATRelayOnly, public
+		}
+		if len(probes) > 0 {
+			return NATUDPBlocked, public
+		}
+		return NATUnknown, public
+	}
+
+	for _, h := range hostAddrs {
+		if _, ok := mappedIPs[h]; ok {
+			return NATOpenInternet, public
+		}
+	}
+
+	if stunOK >= 2 && len(mapped) > 1 {
+		return NATSymmetric, public
+	}
+	return NATEndpointMapping, public
+}
+
+// ToMap converts the report into JS-friendly primitives.
+func (r *ConnectivityReport) ToMap() map[string]interface{} {
+	servers := make([]interface{}, 0, len(r.Servers))
+	for _, s := range r.Servers {
+		candidates := make(map[string]interface{}, len(s.Candidates))
+		for typ, n := range s.Candidates {
+			candidates[typ] = n
+		}
+		servers = append(servers, map[string]interface{}{
+			"url":            s.URL,
+			"kind":           s.Kind,
+			"success":        s.Success,
+			"candidates":     candidates,
+			"mapped_address": s.MappedAddress,
+			"duration_ms":    s.DurationMs,
+			"error":          s.Error,
+		})
+	}
+
+	stats := make(map[string]interface{}, len(r.CandidateStats))
+	for typ, s := range r.CandidateStats {
+		stats[typ] = map[string]interface{}{
+			"attempts":     s.Attempts,
+			"successes":    s.Successes,
+			"success_rate": s.SuccessRate,
+		}
+	}
+
+	public := make([]interface{}, len(r.PublicAddresses))
+	for i, a := range r.PublicAddresses {
+		public[i] = a
+	}
+	hosts := make([]interface{}, len(r.HostAddresses))
+	for i, a := range r.HostAddresses {
+		hosts[i] = a
+	}
+
+	return map[string]interface{}{
+		"nat_type":         string(r.NATType),
+		"public_addresses": public,
+		"host_addresses":   hosts,
+		"servers":          servers,
+		"candidate_stats":  stats,
+		"webrtc_supported": r.WebRTCSupported,
+		"generated_at":     r.GeneratedAt.Format(time.RFC3339),
+	}
+}
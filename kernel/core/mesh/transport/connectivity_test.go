@@ -0,0 +1,71 @@
+//go:build !js || !wasm
+
+package transport
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyNAT(t *testing.T) {
+	stun := func(mapped string) ICEServerProbe {
+		return ICEServerProbe{Kind: "stun", Success: true, MappedAddress: mapped}
+	}
+
+	nat, public := classifyNAT([]string{"192.168.1.10"}, []ICEServerProbe{
+		stun("203.0.113.5:40000"),
+		stun("203.0.113.5:40000"),
+	})
+	assert.Equal(t, NATEndpointMapping, nat)
+	assert.Equal(t, []string{"203.0.113.5:40000"}, public)
+
+	nat, _ = classifyNAT([]string{"192.168.1.10"}, []ICEServerProbe{
+		stun("203.0.113.5:40000"),
+		stun("203.0.113.5:40123"),
+	})
+	assert.Equal(t, NATSymmetric, nat)
+
+	nat, _ = classifyNAT([]string{"203.0.113.5"}, []ICEServerProbe{stun("203.0.113.5:5000")})
+	assert.Equal(t, NATOpenInternet, nat)
+
+	nat, _ = classifyNAT([]string{"10.0.0.2"}, []ICEServerProbe{
+		{Kind: "stun", Success: false},
+		{Kind: "turn", Success: true, MappedAddress: "198.51.100.1:3478"},
+	})
+	assert.Equal(t, NATRelayOnly, nat)
+
+	nat, _ = classifyNAT([]string{"10.0.0.2"}, []ICEServerProbe{{Kind: "stun", Success: false}})
+	assert.Equal(t, NATUDPBlocked, nat)
+
+	nat, _ = classifyNAT(nil, nil)
+	assert.Equal(t, NATUnknown, nat)
+}
+
+func TestWebRTCTransport_ConnectivityCandidateStats(t *testing.T) {
+	tr, _ := NewWebRTCTransport("node-a", DefaultTransportConfig(), nil)
+
+	tr.recordCandidateOutcome(ICEServerProbe{Kind: "stun", Candidates: map[string]int{"host": 2, "srflx": 1}})
+	tr.recordCandidateOutcome(ICEServerProbe{Kind: "stun", Candidates: map[string]int{"host": 2}})
+	tr.recordCandidateOutcome(ICEServerProbe{Kind: "turn", Candidates: map[string]int{"host": 1}})
+	tr.storeConnectivityReport(&ConnectivityReport{NATType: NATEndpointMapping})
+
+	report := tr.GetConnectivityReport()
+	assert.NotNil(t, report)
+	assert.Equal(t, uint64(3), report.CandidateStats[CandidateHost].Attempts)
+	assert.Equal(t, 1.0, report.CandidateStats[CandidateHost].SuccessRate)
+	assert.Equal(t, 0.5, report.CandidateStats[CandidateSrflx].SuccessRate)
+	assert.Equal(t, 0.0, report.CandidateStats[CandidateRelay].SuccessRate)
+
+	stats := tr.GetStats()
+	assert.Equal(t, report, stats["connectivity"])
+
+	m := report.ToMap()
+	assert.Equal(t, "cone", m["nat_type"])
+}
+
+func TestICEServerKind(t *testing.T) {
+	assert.Equal(t, "stun", iceServerKind("stun:stun.l.google.com:19302"))
+	assert.Equal(t, "turn", iceServerKind("turns:relay.example.com:443"))
+	assert.Equal(t, "unknown", iceServerKind("gossip://mesh"))
+}
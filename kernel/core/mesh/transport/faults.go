@@ -0,0 +1,13 @@
+package transport
+
+import "github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+
+// SetFaultInjector installs injector on sends and RPC handling; nil removes it.
+func (t *WebRTCTransport) SetFaultInjector(injector common.FaultInjector) {
+	t.faults.Set(injector)
+}
+
+// SetFaultInjector installs injector on sends and RPC handling; nil removes it.
+func (t *MemoryTransport) SetFaultInjector(injector common.FaultInjector) {
+	t.faults.Set(injector)
+}
@@ -0,0 +1,110 @@
+package transport
+
+import (
+	"slices"
+	"sync/atomic"
+	"time"
+)
+
+// latencySamples is how many recent RPC round trips each ring keeps.
+const latencySamples = 512
+
+// latencyRing is a fixed window of recent latencies. Writers claim a slot
+// with one atomic add and store into it, so recording never blocks; a
+// reader may see a slot mid-overwrite, which only swaps one sample for a
+// newer one.
+type latencyRing struct {
+	next    atomic.Uint64
+	samples [latencySamples]atomic.Int64 // Nanoseconds
+}
+
+func (r *latencyRing) record(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	i := r.next.Add(1) - 1
+	r.samples[i%latencySamples].Store(int64(d))
+}
+
+// LatencyStats summarizes a window of RPC round trips.
+type LatencyStats struct {
+	Samples int     `json:"samples"`
+	P50     float32 `json:"p50_ms"`
+	P95     float32 `json:"p95_ms"`
+	P99     float32 `json:"p99_ms"`
+}
+
+func (r *latencyRing) stats() LatencyStats {
+	n := min(r.next.Load(), latencySamples)
+	if n == 0 {
+		return LatencyStats{}
+	}
+	window := make([]int64, 0, n)
+	for i := uint64(0); i < n; i++ {
+		if v := r.samples[i].Load(); v > 0 {
+			window = append(window, v)
+		}
+	}
+	if len(window) == 0 {
+		return LatencyStats{}
+	}
+	slices.Sort(window)
+	at := func(q float64) float32 {
+		idx := min(int(float64(len(window))*q), len(window)-1)
+		return float32(time.Duration(window[idx])) / float32(time.Millisecond)
+	}
+	return LatencyStats{Samples: len(window), P50: at(0.50), P95: at(0.95), P99: at(0.99)}
+}
+
+// recordRPCLatency adds a round trip to the transport-wide window and to the
+// peer's own.
+func (t *WebRTCTransport) recordRPCLatency(peerID string, latency time.Duration) {
+	t.rpcLatency.record(latency)
+	ring, ok := t.peerLatency.Load(peerID)
+	if !ok {
+		ring, _ = t.peerLatency.LoadOrStore(peerID, &latencyRing{})
+	}
+	ring.(*latencyRing).record(latency)
+}
+
+// PeerLatency returns the RPC round-trip percentiles measured to a peer.
+func (t *WebRTCTransport) PeerLatency(peerID string) (LatencyStats, bool) {
+	ring, ok := t.peerLatency.Load(peerID)
+	if !ok {
+		return LatencyStats{}, false
+	}
+	stats := ring.(*latencyRing).stats()
+	return stats, stats.Samples > 0
+}
+
+// PeerRPCLatency is the median round trip to a peer, for peer scoring.
+func (t *WebRTCTransport) PeerRPCLatency(peerID string) (time.Duration, bool) {
+	stats, ok := t.PeerLatency(peerID)
+	if !ok {
+		return 0, false
+	}
+	return time.Duration(stats.P50 * float32(time.Millisecond)), true
+}
+
+// peerLatencyStats reports every peer with samples, for GetStats.
+func (t *WebRTCTransport) peerLatencyStats() map[string]LatencyStats {
+	out := make(map[string]LatencyStats)
+	t.peerLatency.Range(func(key, value any) bool {
+		if stats := value.(*latencyRing).stats(); stats.Samples > 0 {
+			out[key.(string)] = stats
+		}
+		return true
+	})
+	return out
+}
+
+// updateLatencyPercentiles publishes the transport-wide window into the
+// connection metrics.
+func (t *WebRTCTransport) updateLatencyPercentiles() {
+	stats := t.rpcLatency.stats()
+	t.metricsMu.Lock()
+	t.metrics.LatencyP50 = stats.P50
+	t.metrics.LatencyP95 = stats.P95
+	t.metrics.LatencyP99 = stats.P99
+	t.metricsMu.Unlock()
+}
@@ -0,0 +1,73 @@
+//go:build !js || !wasm
+
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLatencyRing_PercentilesOverRecentWindow(t *testing.T) {
+	var ring latencyRing
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 1; i <= 100; i++ {
+				ring.record(time.Duration(i) * time.Millisecond)
+			}
+		}()
+	}
+	wg.Wait()
+
+	stats := ring.stats()
+	if stats.Samples != 400 || stats.P50 != 51 || stats.P95 != 96 || stats.P99 != 100 {
+		t.Fatalf("unexpected percentiles %+v", stats)
+	}
+
+	// Once full, old samples give way to new ones
+	for i := 0; i < latencySamples; i++ {
+		ring.record(500 * time.Millisecond)
+	}
+	if stats := ring.stats(); stats.Samples != latencySamples || stats.P50 != 500 {
+		t.Fatalf("expected the window to hold only recent samples, got %+v", stats)
+	}
+}
+
+func TestLatency_RPCRoundTripsFeedPeerAndGlobalStats(t *testing.T) {
+	a, b, _ := pipedTransports(t)
+	b.RegisterRPCHandler("echo", func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error) {
+		time.Sleep(5 * time.Millisecond)
+		return "ok", nil
+	})
+	for i := 0; i < 3; i++ {
+		if err := a.SendRPC(context.Background(), "node-b", "echo", nil, nil); err != nil {
+			t.Fatalf("SendRPC failed: %v", err)
+		}
+	}
+
+	stats, ok := a.PeerLatency("node-b")
+	if !ok || stats.Samples != 3 || stats.P50 < 5 {
+		t.Fatalf("unexpected peer latency %+v", stats)
+	}
+	if median, ok := a.PeerRPCLatency("node-b"); !ok || median < 5*time.Millisecond {
+		t.Fatalf("unexpected median %v", median)
+	}
+	if _, ok := a.GetStats()["peer_latency"].(map[string]LatencyStats)["node-b"]; !ok {
+		t.Fatal("peer latency missing from stats")
+	}
+
+	a.updateLatencyPercentiles()
+	if m := a.GetConnectionMetrics(); m.LatencyP50 < 5 || m.LatencyP99 < m.LatencyP50 {
+		t.Fatalf("unexpected transport percentiles %+v", m)
+	}
+
+	a.Disconnect("node-b")
+	if _, ok := a.PeerLatency("node-b"); ok {
+		t.Fatal("expected peer latency dropped on disconnect")
+	}
+}
@@ -0,0 +1,182 @@
+package transport
+
+import (
+	"context"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pion/webrtc/v3"
+)
+
+// localPeerTTL bounds how long a LAN sighting keeps a peer in the local sector.
+const localPeerTTL = 5 * time.Minute
+
+// LocalPeer is a peer reachable on the local network, found through mDNS or
+// inferred from host ICE candidates.
+type LocalPeer struct {
+	PeerID    string    `json:"peer_id"`
+	Addresses []string  `json:"addresses,omitempty"`
+	Signaling string    `json:"signaling,omitempty"`
+	Source    string    `json:"source"` // mdns | ice
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// localNetworkState tracks the local sector and the mDNS responder.
+type localNetworkState struct {
+	mu        sync.RWMutex
+	peers     map[string]LocalPeer
+	discovery *MDNSDiscovery
+}
+
+// MarkLocalPeer records that a peer shares our network segment. Connections
+// to local peers skip STUN/TURN and negotiate on host candidates only.
+func (t *WebRTCTransport) MarkLocalPeer(peer LocalPeer) {
+	if peer.PeerID == "" || peer.PeerID == t.nodeID {
+		return
+	}
+	if peer.LastSeen.IsZero() {
+		peer.LastSeen = time.Now()
+	}
+
+	t.localNetwork.mu.Lock()
+	defer t.localNetwork.mu.Unlock()
+	if t.localNetwork.peers == nil {
+		t.localNetwork.peers = make(map[string]LocalPeer)
+	}
+	if existing, ok := t.localNetwork.peers[peer.PeerID]; ok {
+		// An ICE sighting must not discard addresses learned over mDNS.
+		if len(peer.Addresses) == 0 {
+			peer.Addresses = existing.Addresses
+		}
+		if peer.Signaling == "" {
+			peer.Signaling = existing.Signaling
+		}
+	}
+	t.localNetwork.peers[peer.PeerID] = peer
+}
+
+// IsLocalPeer reports whether a peer was recently seen on the local network.
+func (t *WebRTCTransport) IsLocalPeer(peerID string) bool {
+	t.localNetwork.mu.RLock()
+	defer t.localNetwork.mu.RUnlock()
+	peer, ok := t.localNetwork.peers[peerID]
+	return ok && time.Since(peer.LastSeen) < localPeerTTL
+}
+
+// LocalPeers returns the current local sector, sorted by peer ID.
+func (t *WebRTCTransport) LocalPeers() []LocalPeer {
+	t.localNetwork.mu.RLock()
+	defer t.localNetwork.mu.RUnlock()
+	peers := make([]LocalPeer, 0, len(t.localNetwork.peers))
+	for _, peer := range t.localNetwork.peers {
+		if time.Since(peer.LastSeen) < localPeerTTL {
+			peers = append(peers, peer)
+		}
+	}
+	sort.Slice(peers, func(i, j int) bool { return peers[i].PeerID < peers[j].PeerID })
+	return peers
+}
+
+// StartLocalDiscovery advertises this node over mDNS and reports LAN peers to
+// onPeer. It is a no-op error on platforms without multicast sockets.
+func (t *WebRTCTransport) StartLocalDiscovery(ctx context.Context, onPeer func(LocalPeer)) error {
+	t.localNetwork.mu.Lock()
+	if t.localNetwork.discovery != nil {
+		t.localNetwork.mu.Unlock()
+		return nil
+	}
+	discovery := NewMDNSDiscovery(t.nodeID, t.firstSignalingServer(), t.logger)
+	t.localNetwork.discovery = discovery
+	t.localNetwork.mu.Unlock()
+
+	err := discovery.Start(ctx, func(peer LocalPeer) {
+		t.MarkLocalPeer(peer)
+		if onPeer != nil {
+			onPeer(peer)
+		}
+	})
+	if err != nil {
+		t.localNetwork.mu.Lock()
+		t.localNetwork.discovery = nil
+		t.localNetwork.mu.Unlock()
+	}
+	return err
+}
+
+func (t *WebRTCTransport) stopLocalDiscovery() {
+	t.localNetwork.mu.Lock()
+	discovery := t.localNetwork.discovery
+	t.localNetwork.discovery = nil
+	t.localNetwork.mu.Unlock()
+	if discovery != nil {
+		discovery.Stop()
+	}
+}
+
+func (t *WebRTCTransport) firstSignalingServer() string {
+	if servers := t.signalingServerList(); len(servers) > 0 {
+		return servers[0]
+	}
+	return ""
+}
+
+// webrtcConfigFor returns the ICE configuration for a peer. Local peers are
+// reachable on host candidates, so STUN/TURN gathering would only add delay.
+func (t *WebRTCTransport) webrtcConfigFor(peerID string) webrtc.Configuration {
+	config := t.webrtcConfig
+	if t.IsLocalPeer(peerID) {
+		config.ICEServers = nil
+	}
+	return config
+}
+
+// isLocalCandidatePair reports whether ICE settled on a direct host-to-host
+// path over private, link-local or mDNS-obfuscated (.local) addresses. Host
+// candidates alone prove nothing; every NATed peer advertises private ones.
+func isLocalCandidatePair(pair *webrtc.ICECandidatePair) bool {
+	if pair == nil || pair.Local == nil || pair.Remote == nil {
+		return false
+	}
+	return isLocalHostCandidate(pair.Local) && isLocalHostCandidate(pair.Remote)
+}
+
+func isLocalHostCandidate(c *webrtc.ICECandidate) bool {
+	if c.Typ != webrtc.ICECandidateTypeHost {
+		return false
+	}
+	if strings.HasSuffix(c.Address, ".local") {
+		return true
+	}
+	ip := net.ParseIP(c.Address)
+	return ip != nil && (ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLoopback())
+}
+
+// noteConnectionLocality adds a freshly connected peer to the local sector
+// when its selected candidate pair is host-to-host on the LAN.
+func (t *WebRTCTransport) noteConnectionLocality(peerID string, pc *webrtc.PeerConnection) {
+	pair, ok := selectedCandidatePair(pc)
+	if !ok || !isLocalCandidatePair(pair) {
+		return
+	}
+	t.MarkLocalPeer(LocalPeer{
+		PeerID:    peerID,
+		Addresses: []string{pair.Remote.Address},
+		Source:    "ice",
+	})
+	t.logger.Debug("peer joined local sector", "peer", getShortID(peerID), "address", pair.Remote.Address)
+}
+
+func selectedCandidatePair(pc *webrtc.PeerConnection) (*webrtc.ICECandidatePair, bool) {
+	if pc == nil {
+		return nil, false
+	}
+	sctp := pc.SCTP()
+	if sctp == nil || sctp.Transport() == nil || sctp.Transport().ICETransport() == nil {
+		return nil, false
+	}
+	pair, err := sctp.Transport().ICETransport().GetSelectedCandidatePair()
+	return pair, err == nil && pair != nil
+}
@@ -0,0 +1,65 @@
+//go:build !js || !wasm
+
+package transport
+
+import (
+	"net"
+	"testing"
+
+	"github.com/pion/webrtc/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMDNSResponseRoundTrip(t *testing.T) {
+	packet, err := buildMDNSResponse("node.alpha", "ws://192.168.1.20:8080/ws", []net.IP{net.ParseIP("192.168.1.20")})
+	require.NoError(t, err)
+
+	peers, isQuery, err := parseMDNSMessage(packet)
+	require.NoError(t, err)
+	assert.False(t, isQuery)
+	require.Len(t, peers, 1)
+	assert.Equal(t, "node.alpha", peers[0].PeerID)
+	assert.Equal(t, "ws://192.168.1.20:8080/ws", peers[0].Signaling)
+	assert.Equal(t, []string{"192.168.1.20"}, peers[0].Addresses)
+	assert.Equal(t, "mdns", peers[0].Source)
+
+	query, err := buildMDNSQuery()
+	require.NoError(t, err)
+	peers, isQuery, err = parseMDNSMessage(query)
+	require.NoError(t, err)
+	assert.True(t, isQuery)
+	assert.Empty(t, peers)
+}
+
+func TestIsLocalCandidatePair(t *testing.T) {
+	host := func(addr string) *webrtc.ICECandidate {
+		return &webrtc.ICECandidate{Typ: webrtc.ICECandidateTypeHost, Address: addr}
+	}
+
+	assert.True(t, isLocalCandidatePair(webrtc.NewICECandidatePair(host("192.168.1.2"), host("192.168.1.9"))))
+	assert.True(t, isLocalCandidatePair(webrtc.NewICECandidatePair(host("10.0.0.4"), host("3f1c2a.local"))))
+	assert.False(t, isLocalCandidatePair(webrtc.NewICECandidatePair(host("192.168.1.2"), host("203.0.113.7"))))
+
+	srflx := &webrtc.ICECandidate{Typ: webrtc.ICECandidateTypeSrflx, Address: "192.168.1.9"}
+	assert.False(t, isLocalCandidatePair(webrtc.NewICECandidatePair(host("192.168.1.2"), srflx)))
+	assert.False(t, isLocalCandidatePair(nil))
+}
+
+func TestLocalPeersSkipICEServers(t *testing.T) {
+	tr, err := NewWebRTCTransport("self", DefaultTransportConfig(), nil)
+	require.NoError(t, err)
+	require.NotEmpty(t, tr.webrtcConfigFor("lan-peer").ICEServers)
+
+	tr.MarkLocalPeer(LocalPeer{PeerID: "lan-peer", Addresses: []string{"192.168.1.9"}, Source: "mdns"})
+	tr.MarkLocalPeer(LocalPeer{PeerID: "lan-peer", Source: "ice"})
+	tr.MarkLocalPeer(LocalPeer{PeerID: "self"})
+
+	assert.True(t, tr.IsLocalPeer("lan-peer"))
+	assert.Empty(t, tr.webrtcConfigFor("lan-peer").ICEServers)
+	assert.NotEmpty(t, tr.webrtcConfigFor("remote-peer").ICEServers)
+
+	peers := tr.LocalPeers()
+	require.Len(t, peers, 1)
+	assert.Equal(t, []string{"192.168.1.9"}, peers[0].Addresses, "ICE sighting should keep mDNS addresses")
+}
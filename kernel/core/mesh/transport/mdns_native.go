@@ -0,0 +1,300 @@
+//go:build !js || !wasm
+
+package transport
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+const (
+	mdnsServiceName   = "_inos-mesh._udp.local."
+	mdnsQueryInterval = 30 * time.Second
+	mdnsRecordTTL     = 120
+	mdnsMaxPacket     = 9000
+)
+
+var mdnsGroup = &net.UDPAddr{IP: net.IPv4(224, 0, 0, 251), Port: 5353}
+
+// MDNSDiscovery advertises the node on the LAN and listens for other INOS
+// nodes. Each node answers PTR queries for _inos-mesh._udp.local with a TXT
+// record carrying its peer ID and optional signaling URL.
+type MDNSDiscovery struct {
+	nodeID    string
+	signaling string
+	logger    *slog.Logger
+
+	mu       sync.Mutex
+	conn     *net.UDPConn
+	shutdown chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewMDNSDiscovery creates a discovery responder for nodeID.
+func NewMDNSDiscovery(nodeID, signaling string, logger *slog.Logger) *MDNSDiscovery {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &MDNSDiscovery{
+		nodeID:    nodeID,
+		signaling: signaling,
+		logger:    logger.With("component", "mdns"),
+	}
+}
+
+// Start joins the mDNS multicast group, announces this node and begins
+// periodic queries. onPeer is called for every sighting of another node.
+func (d *MDNSDiscovery) Start(ctx context.Context, onPeer func(LocalPeer)) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.conn != nil {
+		return errors.New("mdns discovery already running")
+	}
+
+	conn, err := net.ListenMulticastUDP("udp4", nil, mdnsGroup)
+	if err != nil {
+		return err
+	}
+	d.conn = conn
+	d.shutdown = make(chan struct{})
+
+	d.wg.Add(2)
+	go d.receiveLoop(conn, onPeer)
+	go d.queryLoop(ctx, conn)
+
+	d.logger.Info("mdns discovery started", "service", mdnsServiceName)
+	return nil
+}
+
+// Stop leaves the multicast group.
+func (d *MDNSDiscovery) Stop() {
+	d.mu.Lock()
+	conn := d.conn
+	d.conn = nil
+	if conn != nil {
+		close(d.shutdown)
+	}
+	d.mu.Unlock()
+
+	if conn != nil {
+		_ = conn.Close()
+		d.wg.Wait()
+	}
+}
+
+func (d *MDNSDiscovery) queryLoop(ctx context.Context, conn *net.UDPConn) {
+	defer d.wg.Done()
+
+	d.announce(conn)
+	d.query(conn)
+
+	ticker := time.NewTicker(mdnsQueryInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			d.query(conn)
+		case <-ctx.Done():
+			return
+		case <-d.shutdown:
+			return
+		}
+	}
+}
+
+func (d *MDNSDiscovery) receiveLoop(conn *net.UDPConn, onPeer func(LocalPeer)) {
+	defer d.wg.Done()
+
+	buf := make([]byte, mdnsMaxPacket)
+	for {
+		n, src, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			select {
+			case <-d.shutdown:
+			default:
+				d.logger.Debug("mdns read failed", "error", err)
+			}
+			return
+		}
+
+		peers, isQuery, err := parseMDNSMessage(buf[:n])
+		if err != nil {
+			continue
+		}
+		if isQuery {
+			d.announce(conn)
+			continue
+		}
+		for _, peer := range peers {
+			if peer.PeerID == d.nodeID {
+				continue
+			}
+			if len(peer.Addresses) == 0 && src != nil {
+				peer.Addresses = []string{src.IP.String()}
+			}
+			if onPeer != nil {
+				onPeer(peer)
+			}
+		}
+	}
+}
+
+func (d *MDNSDiscovery) announce(conn *net.UDPConn) {
+	packet, err := buildMDNSResponse(d.nodeID, d.signaling, localIPv4Addrs())
+	d.send(conn, packet, err)
+}
+
+func (d *MDNSDiscovery) query(conn *net.UDPConn) {
+	packet, err := buildMDNSQuery()
+	d.send(conn, packet, err)
+}
+
+func (d *MDNSDiscovery) send(conn *net.UDPConn, packet []byte, err error) {
+	if err != nil {
+		d.logger.Debug("failed to build mdns packet", "error", err)
+		return
+	}
+	if _, err := conn.WriteToUDP(packet, mdnsGroup); err != nil {
+		d.logger.Debug("mdns send failed", "error", err)
+	}
+}
+
+func buildMDNSQuery() ([]byte, error) {
+	service, err := dnsmessage.NewName(mdnsServiceName)
+	if err != nil {
+		return nil, err
+	}
+	msg := dnsmessage.Message{
+		Questions: []dnsmessage.Question{{Name: service, Type: dnsmessage.TypePTR, Class: dnsmessage.ClassINET}},
+	}
+	return msg.Pack()
+}
+
+// mdnsInstanceLabel turns a peer ID into a valid DNS label. The full ID is
+// carried in the TXT record, so truncation is harmless.
+func mdnsInstanceLabel(nodeID string) string {
+	label := strings.NewReplacer(".", "-", " ", "-").Replace(nodeID)
+	if len(label) > 63 {
+		label = label[:63]
+	}
+	if label == "" {
+		label = "node"
+	}
+	return label
+}
+
+func buildMDNSResponse(nodeID, signaling string, addrs []net.IP) ([]byte, error) {
+	service, err := dnsmessage.NewName(mdnsServiceName)
+	if err != nil {
+		return nil, err
+	}
+	instance, err := dnsmessage.NewName(mdnsInstanceLabel(nodeID) + "." + mdnsServiceName)
+	if err != nil {
+		return nil, err
+	}
+
+	txt := []string{"peer=" + nodeID}
+	if signaling != "" {
+		txt = append(txt, "signal="+signaling)
+	}
+
+	header := func(name dnsmessage.Name, typ dnsmessage.Type) dnsmessage.ResourceHeader {
+		return dnsmessage.ResourceHeader{Name: name, Type: typ, Class: dnsmessage.ClassINET, TTL: mdnsRecordTTL}
+	}
+	msg := dnsmessage.Message{
+		Header: dnsmessage.Header{Response: true, Authoritative: true},
+		Answers: []dnsmessage.Resource{
+			{Header: header(service, dnsmessage.TypePTR), Body: &dnsmessage.PTRResource{PTR: instance}},
+			{Header: header(instance, dnsmessage.TypeTXT), Body: &dnsmessage.TXTResource{TXT: txt}},
+		},
+	}
+	for _, ip := range addrs {
+		if v4 := ip.To4(); v4 != nil {
+			var a [4]byte
+			copy(a[:], v4)
+			msg.Additionals = append(msg.Additionals, dnsmessage.Resource{
+				Header: header(instance, dnsmessage.TypeA),
+				Body:   &dnsmessage.AResource{A: a},
+			})
+		}
+	}
+	return msg.Pack()
+}
+
+// parseMDNSMessage extracts INOS peers from a response, or reports whether
+// the packet is a query for our service.
+func parseMDNSMessage(packet []byte) ([]LocalPeer, bool, error) {
+	var msg dnsmessage.Message
+	if err := msg.Unpack(packet); err != nil {
+		return nil, false, err
+	}
+
+	if !msg.Header.Response {
+		for _, q := range msg.Questions {
+			if q.Type == dnsmessage.TypePTR && strings.EqualFold(q.Name.String(), mdnsServiceName) {
+				return nil, true, nil
+			}
+		}
+		return nil, false, nil
+	}
+
+	byInstance := make(map[string]*LocalPeer)
+	addrs := make(map[string][]string)
+	now := time.Now()
+	for _, res := range append(msg.Answers, msg.Additionals...) {
+		name := strings.ToLower(res.Header.Name.String())
+		switch body := res.Body.(type) {
+		case *dnsmessage.TXTResource:
+			if !strings.HasSuffix(name, mdnsServiceName) {
+				continue
+			}
+			peer := &LocalPeer{Source: "mdns", LastSeen: now}
+			for _, entry := range body.TXT {
+				key, value, _ := strings.Cut(entry, "=")
+				switch key {
+				case "peer":
+					peer.PeerID = value
+				case "signal":
+					peer.Signaling = value
+				}
+			}
+			if peer.PeerID != "" {
+				byInstance[name] = peer
+			}
+		case *dnsmessage.AResource:
+			addrs[name] = append(addrs[name], net.IP(body.A[:]).String())
+		}
+	}
+
+	peers := make([]LocalPeer, 0, len(byInstance))
+	for name, peer := range byInstance {
+		peer.Addresses = addrs[name]
+		peers = append(peers, *peer)
+	}
+	return peers, false, nil
+}
+
+func localIPv4Addrs() []net.IP {
+	ifaceAddrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return nil
+	}
+	var ips []net.IP
+	for _, addr := range ifaceAddrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok || ipNet.IP.IsLoopback() || ipNet.IP.To4() == nil {
+			continue
+		}
+		if ipNet.IP.IsPrivate() || ipNet.IP.IsLinkLocalUnicast() {
+			ips = append(ips, ipNet.IP)
+		}
+	}
+	return ips
+}
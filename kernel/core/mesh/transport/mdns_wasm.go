@@ -0,0 +1,30 @@
+//go:build js && wasm
+
+package transport
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+)
+
+// ErrMDNSUnsupported is returned in the browser, which cannot open multicast
+// sockets. Browser kernels still join a local sector through ICE: a
+// host-to-host candidate pair marks the peer local.
+var ErrMDNSUnsupported = errors.New("mdns discovery is not available in the browser")
+
+// MDNSDiscovery is a stub on js/wasm.
+type MDNSDiscovery struct{}
+
+// NewMDNSDiscovery returns a stub discovery.
+func NewMDNSDiscovery(nodeID, signaling string, logger *slog.Logger) *MDNSDiscovery {
+	return &MDNSDiscovery{}
+}
+
+// Start always fails with ErrMDNSUnsupported.
+func (d *MDNSDiscovery) Start(ctx context.Context, onPeer func(LocalPeer)) error {
+	return ErrMDNSUnsupported
+}
+
+// Stop is a no-op.
+func (d *MDNSDiscovery) Stop() {}
@@ -0,0 +1,644 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+)
+
+var (
+	// ErrMemoryPeerUnreachable is returned when the target is offline, not
+	// connected or on the other side of a partition.
+	ErrMemoryPeerUnreachable = errors.New("peer unreachable on memory network")
+	// ErrMemoryPacketLost is returned when the link drops a request or its
+	// reply. It surfaces after the link delay, where a real transport would
+	// only notice at its timeout.
+	ErrMemoryPacketLost = errors.New("packet lost on memory network")
+)
+
+// MemoryLinkProfile describes one link of a MemoryNetwork. The delay of a
+// single hop is Latency plus a uniform jitter in [-Jitter, +Jitter].
+type MemoryLinkProfile struct {
+	Latency  time.Duration
+	Jitter   time.Duration
+	LossRate float64 // Probability (0-1) that a hop is dropped
+}
+
+// MemoryNetwork connects MemoryTransports in one process. It stands in for
+// WebRTC in multi-node tests: links can be slowed, made lossy and
+// partitioned, and nodes can come and go.
+type MemoryNetwork struct {
+	mu         sync.RWMutex
+	nodes      map[string]*MemoryTransport
+	defaults   MemoryLinkProfile
+	links      map[string]MemoryLinkProfile
+	partitions map[string]int // Node -> partition group; unlisted nodes are group 0
+
+	rngMu sync.Mutex
+	rng   *rand.Rand
+
+	delivered atomic.Uint64
+	dropped   atomic.Uint64
+}
+
+// NewMemoryNetwork creates a network whose links default to profile. seed
+// makes jitter and loss reproducible.
+func NewMemoryNetwork(profile MemoryLinkProfile, seed int64) *MemoryNetwork {
+	return &MemoryNetwork{
+		nodes:      make(map[string]*MemoryTransport),
+		defaults:   profile,
+		links:      make(map[string]MemoryLinkProfile),
+		partitions: make(map[string]int),
+		rng:        rand.New(rand.NewSource(seed)),
+	}
+}
+
+func memoryLinkKey(a, b string) string {
+	if a > b {
+		a, b = b, a
+	}
+	return a + "\x00" + b
+}
+
+// NewTransport adds a node to the network. The transport is offline until
+// Start.
+func (n *MemoryNetwork) NewTransport(nodeID string) *MemoryTransport {
+	t := &MemoryTransport{
+		nodeID:   nodeID,
+		network:  n,
+		peers:    make(map[string]struct{}),
+		handlers: make(map[string]func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error)),
+	}
+	n.mu.Lock()
+	n.nodes[nodeID] = t
+	n.mu.Unlock()
+	return t
+}
+
+// SetLink overrides the profile of the link between a and b.
+func (n *MemoryNetwork) SetLink(a, b string, profile MemoryLinkProfile) {
+	n.mu.Lock()
+	n.links[memoryLinkKey(a, b)] = profile
+	n.mu.Unlock()
+}
+
+// SetDefaultLink changes the profile of links without an override.
+func (n *MemoryNetwork) SetDefaultLink(profile MemoryLinkProfile) {
+	n.mu.Lock()
+	n.defaults = profile
+	n.mu.Unlock()
+}
+
+func (n *MemoryNetwork) link(a, b string) MemoryLinkProfile {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	if profile, ok := n.links[memoryLinkKey(a, b)]; ok {
+		return profile
+	}
+	return n.defaults
+}
+
+// Partition splits the network into groups. Nodes in different groups
+// cannot reach each other and existing connections between them drop;
+// nodes not listed form one more group together. Calling Partition again
+// replaces the previous split.
+func (n *MemoryNetwork) Partition(groups ...[]string) {
+	n.mu.Lock()
+	n.partitions = make(map[string]int)
+	for i, group := range groups {
+		for _, nodeID := range group {
+			n.partitions[nodeID] = i + 1
+		}
+	}
+	nodes := make([]*MemoryTransport, 0, len(n.nodes))
+	for _, t := range n.nodes {
+		nodes = append(nodes, t)
+	}
+	n.mu.Unlock()
+
+	for _, t := range nodes {
+		for _, peerID := range t.GetConnectedPeers() {
+			if !n.Reachable(t.nodeID, peerID) {
+				t.dropPeer(peerID)
+			}
+		}
+	}
+}
+
+// Heal removes all partitions. Connections dropped by a partition stay
+// down until a node reconnects.
+func (n *MemoryNetwork) Heal() {
+	n.mu.Lock()
+	n.partitions = make(map[string]int)
+	n.mu.Unlock()
+}
+
+// Reachable reports whether a and b are both online and in the same
+// partition group.
+func (n *MemoryNetwork) Reachable(a, b string) bool {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	ta, okA := n.nodes[a]
+	tb, okB := n.nodes[b]
+	if !okA || !okB || !ta.online.Load() || !tb.online.Load() {
+		return false
+	}
+	return n.partitions[a] == n.partitions[b]
+}
+
+func (n *MemoryNetwork) node(nodeID string) (*MemoryTransport, bool) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	t, ok := n.nodes[nodeID]
+	return t, ok
+}
+
+// hop waits out one traversal of the link from a to b and reports whether
+// the packet survived it.
+func (n *MemoryNetwork) hop(ctx context.Context, a, b string) error {
+	profile := n.link(a, b)
+
+	n.rngMu.Lock()
+	lost := profile.LossRate > 0 && n.rng.Float64() < profile.LossRate
+	delay := profile.Latency
+	if profile.Jitter > 0 {
+		delay += time.Duration(n.rng.Int63n(int64(2*profile.Jitter)+1)) - profile.Jitter
+	}
+	n.rngMu.Unlock()
+
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if lost {
+		n.dropped.Add(1)
+		return ErrMemoryPacketLost
+	}
+	if !n.Reachable(a, b) {
+		n.dropped.Add(1)
+		return ErrMemoryPeerUnreachable
+	}
+	n.delivered.Add(1)
+	return nil
+}
+
+// Stats returns delivered and dropped hop counts.
+func (n *MemoryNetwork) Stats() (delivered, dropped uint64) {
+	return n.delivered.Load(), n.dropped.Load()
+}
+
+// MemoryTransport is a Transport on a MemoryNetwork. Payloads cross it as
+// JSON, as they would on the wire, so handlers see exactly what a remote
+// peer would send.
+type MemoryTransport struct {
+	nodeID  string
+	network *MemoryNetwork
+	online  atomic.Bool
+
+	mu    sync.RWMutex
+	peers map[string]struct{}
+
+	handlersMu sync.RWMutex
+	handlers   map[string]func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error)
+
+	eventsMu       sync.RWMutex
+	peerEvents     func(peerID string, connected bool)
+	messageHandler func(peerID string, payload json.RawMessage)
+
+	capabilityMu    sync.RWMutex
+	localCapability *common.PeerCapability
+
+	started          time.Time
+	totalConnections atomic.Uint64
+	messagesSent     atomic.Uint64
+	messagesReceived atomic.Uint64
+	bytesSent        atomic.Uint64
+	bytesReceived    atomic.Uint64
+	failed           atomic.Uint64
+
+	faults common.FaultHook
+}
+
+// NodeID returns the transport's node ID.
+func (t *MemoryTransport) NodeID() string {
+	return t.nodeID
+}
+
+// SetPeerEventHandler registers a callback for connects and disconnects.
+func (t *MemoryTransport) SetPeerEventHandler(handler func(peerID string, connected bool)) {
+	t.eventsMu.Lock()
+	t.peerEvents = handler
+	t.eventsMu.Unlock()
+}
+
+// SetMessageHandler registers a callback for messages sent with
+// SendMessage and Broadcast.
+func (t *MemoryTransport) SetMessageHandler(handler func(peerID string, payload json.RawMessage)) {
+	t.eventsMu.Lock()
+	t.messageHandler = handler
+	t.eventsMu.Unlock()
+}
+
+func (t *MemoryTransport) emitPeerEvent(peerID string, connected bool) {
+	t.eventsMu.RLock()
+	handler := t.peerEvents
+	t.eventsMu.RUnlock()
+	if handler != nil {
+		handler(peerID, connected)
+	}
+}
+
+func (t *MemoryTransport) Start(ctx context.Context) error {
+	t.started = time.Now()
+	t.online.Store(true)
+	return nil
+}
+
+// Stop takes the node offline and drops all of its connections, as a
+// closed tab would.
+func (t *MemoryTransport) Stop() error {
+	t.online.Store(false)
+	for _, peerID := range t.GetConnectedPeers() {
+		t.dropPeer(peerID)
+	}
+	return nil
+}
+
+// dropPeer tears down the connection on both ends and notifies both.
+func (t *MemoryTransport) dropPeer(peerID string) {
+	if t.removePeer(peerID) {
+		t.emitPeerEvent(peerID, false)
+	}
+	if remote, ok := t.network.node(peerID); ok && remote.removePeer(t.nodeID) {
+		remote.emitPeerEvent(t.nodeID, false)
+	}
+}
+
+func (t *MemoryTransport) addPeer(peerID string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.peers[peerID]; ok {
+		return false
+	}
+	t.peers[peerID] = struct{}{}
+	t.totalConnections.Add(1)
+	return true
+}
+
+func (t *MemoryTransport) removePeer(peerID string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.peers[peerID]; !ok {
+		return false
+	}
+	delete(t.peers, peerID)
+	return true
+}
+
+// Connect links both ends after one round trip.
+func (t *MemoryTransport) Connect(ctx context.Context, peerID string) error {
+	if peerID == t.nodeID {
+		return errors.New("cannot connect to self")
+	}
+	if t.IsConnected(peerID) {
+		return nil
+	}
+	remote, ok := t.network.node(peerID)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrMemoryPeerUnreachable, peerID)
+	}
+	if err := t.roundTrip(ctx, peerID); err != nil {
+		return err
+	}
+
+	if t.addPeer(peerID) {
+		t.emitPeerEvent(peerID, true)
+	}
+	if remote.addPeer(t.nodeID) {
+		remote.emitPeerEvent(t.nodeID, true)
+	}
+	return nil
+}
+
+func (t *MemoryTransport) Disconnect(peerID string) error {
+	t.dropPeer(peerID)
+	return nil
+}
+
+func (t *MemoryTransport) IsConnected(peerID string) bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	_, ok := t.peers[peerID]
+	return ok
+}
+
+func (t *MemoryTransport) GetConnectedPeers() []string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	peers := make([]string, 0, len(t.peers))
+	for peerID := range t.peers {
+		peers = append(peers, peerID)
+	}
+	return peers
+}
+
+func (t *MemoryTransport) roundTrip(ctx context.Context, peerID string) error {
+	if err := t.network.hop(ctx, t.nodeID, peerID); err != nil {
+		return err
+	}
+	return t.network.hop(ctx, peerID, t.nodeID)
+}
+
+// remote returns the connected peer's transport, or an error if the
+// connection is down or partitioned.
+func (t *MemoryTransport) remote(peerID string) (*MemoryTransport, error) {
+	if !t.IsConnected(peerID) || !t.network.Reachable(t.nodeID, peerID) {
+		t.failed.Add(1)
+		return nil, fmt.Errorf("%w: %s", ErrMemoryPeerUnreachable, peerID)
+	}
+	remote, ok := t.network.node(peerID)
+	if !ok {
+		t.failed.Add(1)
+		return nil, fmt.Errorf("%w: %s", ErrMemoryPeerUnreachable, peerID)
+	}
+	return remote, nil
+}
+
+func (t *MemoryTransport) SendRPC(ctx context.Context, peerID string, method string, args interface{}, reply interface{}) error {
+	remote, err := t.remote(peerID)
+	if err != nil {
+		return err
+	}
+	params, err := json.Marshal(args)
+	if err != nil {
+		return err
+	}
+	if params, err = t.applySendFault(ctx, peerID, params); err != nil {
+		return err
+	}
+	t.messagesSent.Add(1)
+	t.bytesSent.Add(uint64(len(params)))
+
+	if err := t.network.hop(ctx, t.nodeID, peerID); err != nil {
+		t.failed.Add(1)
+		return err
+	}
+	result, err := remote.serve(ctx, t.nodeID, method, params)
+	if err != nil {
+		// Handler errors still cross the link back.
+		if hopErr := t.network.hop(ctx, peerID, t.nodeID); hopErr != nil {
+			t.failed.Add(1)
+			return hopErr
+		}
+		return err
+	}
+	if err := t.network.hop(ctx, peerID, t.nodeID); err != nil {
+		t.failed.Add(1)
+		return err
+	}
+
+	t.messagesReceived.Add(1)
+	t.bytesReceived.Add(uint64(len(result)))
+	if reply == nil {
+		return nil
+	}
+	return json.Unmarshal(result, reply)
+}
+
+// serve runs the local handler for an RPC arriving from peerID.
+func (t *MemoryTransport) serve(ctx context.Context, peerID, method string, params json.RawMessage) (json.RawMessage, error) {
+	t.messagesReceived.Add(1)
+	t.bytesReceived.Add(uint64(len(params)))
+
+	t.handlersMu.RLock()
+	handler, ok := t.handlers[method]
+	t.handlersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("method not found: %s", method)
+	}
+
+	fault := t.faults.Inject(common.FaultPointRPCHandle, method)
+	if err := fault.Apply(ctx); err != nil {
+		return nil, err
+	}
+	if fault.Corrupt {
+		params = common.CorruptBytes(params)
+	}
+	result, err := handler(ctx, peerID, params)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	t.messagesSent.Add(1)
+	t.bytesSent.Add(uint64(len(data)))
+	return data, nil
+}
+
+func (t *MemoryTransport) StreamRPC(ctx context.Context, peerID string, method string, args interface{}, writer io.Writer) (int64, error) {
+	var result struct {
+		Data []byte `json:"data"`
+	}
+	if err := t.SendRPC(ctx, peerID, method, args, &result); err != nil {
+		return 0, err
+	}
+	n, err := writer.Write(result.Data)
+	return int64(n), err
+}
+
+// SendMessage delivers msg to the peer's message handler after the link
+// delay. Like a datagram it may be lost; loss after the send is not
+// reported to the sender.
+func (t *MemoryTransport) SendMessage(ctx context.Context, peerID string, msg interface{}) error {
+	remote, err := t.remote(peerID)
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+	if payload, err = t.applySendFault(ctx, peerID, payload); err != nil {
+		return err
+	}
+	t.messagesSent.Add(1)
+	t.bytesSent.Add(uint64(len(payload)))
+
+	go func() {
+		if err := t.network.hop(context.Background(), t.nodeID, peerID); err != nil {
+			return
+		}
+		remote.deliver(t.nodeID, payload)
+	}()
+	return nil
+}
+
+// applySendFault runs the injected send fault for an outgoing payload.
+func (t *MemoryTransport) applySendFault(ctx context.Context, peerID string, payload []byte) ([]byte, error) {
+	fault := t.faults.Inject(common.FaultPointSend, peerID)
+	if err := fault.Apply(ctx); err != nil {
+		t.failed.Add(1)
+		return nil, err
+	}
+	if fault.Corrupt {
+		payload = common.CorruptBytes(payload)
+	}
+	return payload, nil
+}
+
+func (t *MemoryTransport) deliver(peerID string, payload json.RawMessage) {
+	t.messagesReceived.Add(1)
+	t.bytesReceived.Add(uint64(len(payload)))
+
+	t.eventsMu.RLock()
+	handler := t.messageHandler
+	t.eventsMu.RUnlock()
+	if handler != nil {
+		handler(peerID, payload)
+	}
+}
+
+func (t *MemoryTransport) Broadcast(topic string, message interface{}) error {
+	msg := map[string]interface{}{
+		"type":    "broadcast",
+		"topic":   topic,
+		"message": message,
+	}
+	var errs []error
+	for _, peerID := range t.GetConnectedPeers() {
+		if err := t.SendMessage(context.Background(), peerID, msg); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (t *MemoryTransport) RegisterRPCHandler(method string, handler func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error)) {
+	t.handlersMu.Lock()
+	t.handlers[method] = handler
+	t.handlersMu.Unlock()
+}
+
+func (t *MemoryTransport) Advertise(ctx context.Context, key string, value string) error {
+	return t.Broadcast("discovery.advertise", map[string]string{"key": key, "value": value})
+}
+
+func (t *MemoryTransport) FindPeers(ctx context.Context, key string) ([]common.PeerInfo, error) {
+	return nil, nil
+}
+
+func (t *MemoryTransport) FindNode(ctx context.Context, peerID, targetID string) ([]common.PeerInfo, error) {
+	var nodes []common.PeerInfo
+	err := t.SendRPC(ctx, peerID, "find_node", map[string]string{"target_id": targetID}, &nodes)
+	return nodes, err
+}
+
+func (t *MemoryTransport) FindValue(ctx context.Context, peerID, chunkHash string) ([]string, []common.PeerInfo, error) {
+	var result struct {
+		Values []string          `json:"values"`
+		Nodes  []common.PeerInfo `json:"nodes"`
+	}
+	err := t.SendRPC(ctx, peerID, "find_value", map[string]string{"key": chunkHash}, &result)
+	return result.Values, result.Nodes, err
+}
+
+func (t *MemoryTransport) Store(ctx context.Context, peerID string, key string, value []byte) error {
+	return t.SendRPC(ctx, peerID, "store", map[string]interface{}{"key": key, "value": value}, nil)
+}
+
+// StoreWithTTL is Store with the record's lifetime in seconds.
+func (t *MemoryTransport) StoreWithTTL(ctx context.Context, peerID string, key string, value []byte, ttlSeconds int64) error {
+	return t.SendRPC(ctx, peerID, "store", map[string]interface{}{"key": key, "value": value, "ttl": ttlSeconds}, nil)
+}
+
+func (t *MemoryTransport) Ping(ctx context.Context, peerID string) error {
+	if _, err := t.remote(peerID); err != nil {
+		return err
+	}
+	return t.roundTrip(ctx, peerID)
+}
+
+// GetPeerCapabilities returns what the peer last set with
+// UpdateLocalCapabilities, or a bare record if it never did.
+func (t *MemoryTransport) GetPeerCapabilities(peerID string) (*common.PeerCapability, error) {
+	remote, err := t.remote(peerID)
+	if err != nil {
+		return nil, err
+	}
+	remote.capabilityMu.RLock()
+	capability := remote.localCapability
+	remote.capabilityMu.RUnlock()
+
+	if capability == nil {
+		return &common.PeerCapability{
+			PeerID:          peerID,
+			LatencyMs:       float32(t.network.link(t.nodeID, peerID).Latency.Milliseconds()),
+			ConnectionState: common.ConnectionStateConnected,
+			LastSeen:        time.Now().UnixNano(),
+		}, nil
+	}
+	cp := *capability
+	cp.PeerID = peerID
+	return &cp, nil
+}
+
+func (t *MemoryTransport) UpdateLocalCapabilities(capabilities *common.PeerCapability) error {
+	t.capabilityMu.Lock()
+	t.localCapability = capabilities
+	t.capabilityMu.Unlock()
+	return nil
+}
+
+func (t *MemoryTransport) GetConnectionMetrics() common.ConnectionMetrics {
+	metrics := common.ConnectionMetrics{
+		ActiveConnections: uint32(len(t.GetConnectedPeers())),
+		TotalConnections:  t.totalConnections.Load(),
+		BytesSent:         t.bytesSent.Load(),
+		BytesReceived:     t.bytesReceived.Load(),
+		MessagesSent:      t.messagesSent.Load(),
+		MessagesReceived:  t.messagesReceived.Load(),
+		FailedMessages:    t.failed.Load(),
+	}
+	if metrics.MessagesSent > 0 {
+		metrics.ErrorRate = float32(metrics.FailedMessages) / float32(metrics.MessagesSent)
+		metrics.SuccessRate = 1 - metrics.ErrorRate
+	}
+	return metrics
+}
+
+func (t *MemoryTransport) GetHealth() common.TransportHealth {
+	status := "healthy"
+	if !t.online.Load() {
+		status = "offline"
+	}
+	return common.TransportHealth{Status: status, Score: 1, Uptime: time.Since(t.started).String()}
+}
+
+func (t *MemoryTransport) GetStats() map[string]interface{} {
+	metrics := t.GetConnectionMetrics()
+	return map[string]interface{}{
+		"node_id":            t.nodeID,
+		"connected_peers":    t.GetConnectedPeers(),
+		"active_connections": metrics.ActiveConnections,
+		"bytes_sent":         metrics.BytesSent,
+		"bytes_received":     metrics.BytesReceived,
+		"messages_sent":      metrics.MessagesSent,
+		"messages_received":  metrics.MessagesReceived,
+		"metrics":            metrics,
+	}
+}
@@ -0,0 +1,125 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newMemoryPair(t *testing.T, profile MemoryLinkProfile) (*MemoryNetwork, *MemoryTransport, *MemoryTransport) {
+	t.Helper()
+	network := NewMemoryNetwork(profile, 1)
+	a := network.NewTransport("a")
+	b := network.NewTransport("b")
+	_ = a.Start(context.Background())
+	_ = b.Start(context.Background())
+	if err := a.Connect(context.Background(), "b"); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	return network, a, b
+}
+
+func TestMemoryTransport_RPCRoundTripAndLatency(t *testing.T) {
+	_, a, b := newMemoryPair(t, MemoryLinkProfile{Latency: 5 * time.Millisecond})
+	b.RegisterRPCHandler("echo", func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error) {
+		var in map[string]string
+		_ = json.Unmarshal(args, &in)
+		return map[string]string{"from": peerID, "v": in["v"]}, nil
+	})
+
+	start := time.Now()
+	var reply map[string]string
+	if err := a.SendRPC(context.Background(), "b", "echo", map[string]string{"v": "x"}, &reply); err != nil {
+		t.Fatalf("SendRPC failed: %v", err)
+	}
+	if reply["from"] != "a" || reply["v"] != "x" {
+		t.Fatalf("unexpected reply %v", reply)
+	}
+	if elapsed := time.Since(start); elapsed < 10*time.Millisecond {
+		t.Fatalf("expected a full round trip of link latency, took %v", elapsed)
+	}
+	if !b.IsConnected("a") {
+		t.Fatal("connections should be symmetric")
+	}
+}
+
+func TestMemoryTransport_LossIsReproducible(t *testing.T) {
+	run := func() []bool {
+		_, a, b := newMemoryPair(t, MemoryLinkProfile{})
+		b.RegisterRPCHandler("noop", func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error) {
+			return nil, nil
+		})
+		a.network.SetLink("a", "b", MemoryLinkProfile{LossRate: 0.5})
+		outcomes := make([]bool, 32)
+		for i := range outcomes {
+			err := a.SendRPC(context.Background(), "b", "noop", nil, nil)
+			if err != nil && !errors.Is(err, ErrMemoryPacketLost) {
+				t.Fatalf("unexpected error %v", err)
+			}
+			outcomes[i] = err == nil
+		}
+		return outcomes
+	}
+
+	first, second := run(), run()
+	lost := 0
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("outcome %d differs between runs with the same seed", i)
+		}
+		if !first[i] {
+			lost++
+		}
+	}
+	if lost == 0 || lost == len(first) {
+		t.Fatalf("expected some but not all calls lost at 50%% loss, lost %d", lost)
+	}
+}
+
+func TestMemoryTransport_PartitionDropsConnectionsAndMessages(t *testing.T) {
+	network, a, b := newMemoryPair(t, MemoryLinkProfile{})
+
+	var mu sync.Mutex
+	var events []bool
+	a.SetPeerEventHandler(func(peerID string, connected bool) {
+		mu.Lock()
+		events = append(events, connected)
+		mu.Unlock()
+	})
+	received := make(chan json.RawMessage, 1)
+	b.SetMessageHandler(func(peerID string, payload json.RawMessage) { received <- payload })
+
+	if err := a.SendMessage(context.Background(), "b", map[string]string{"hello": "b"}); err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+	select {
+	case payload := <-received:
+		if string(payload) != `{"hello":"b"}` {
+			t.Fatalf("unexpected payload %s", payload)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("message not delivered")
+	}
+
+	network.Partition([]string{"a"}, []string{"b"})
+	if a.IsConnected("b") || b.IsConnected("a") {
+		t.Fatal("partition should drop the connection on both ends")
+	}
+	if err := a.Connect(context.Background(), "b"); !errors.Is(err, ErrMemoryPeerUnreachable) {
+		t.Fatalf("expected unreachable across the partition, got %v", err)
+	}
+
+	network.Heal()
+	if err := a.Connect(context.Background(), "b"); err != nil {
+		t.Fatalf("reconnect after heal failed: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(events) != 2 || events[0] || !events[1] {
+		t.Fatalf("expected disconnect then connect events, got %v", events)
+	}
+}
@@ -0,0 +1,288 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/pion/webrtc/v3"
+)
+
+// migrationState tracks connections whose network path dropped. The data
+// channel and its SCTP association outlive an ICE restart, so a connection
+// is kept open while ICE finds a new path instead of being torn down and
+// redialled; only a migration that does not finish in MigrationTimeout
+// disconnects the peer.
+type migrationState struct {
+	mu      sync.Mutex
+	offline bool
+	pending map[string]*peerMigration
+
+	restarts  uint64
+	recovered uint64
+	abandoned uint64
+}
+
+type peerMigration struct {
+	started time.Time
+	timer   *time.Timer
+	done    chan struct{} // Closed when the migration finishes either way
+	ok      bool
+}
+
+// MigrationStats counts ICE restarts and how connection migrations ended.
+type MigrationStats struct {
+	Offline   bool   `json:"offline"`
+	Migrating int    `json:"migrating"`
+	Restarts  uint64 `json:"restarts"`
+	Recovered uint64 `json:"recovered"`
+	Abandoned uint64 `json:"abandoned"`
+}
+
+// NetworkChanged tells the transport the host's network changed, from the
+// browser's online/offline and connection change events. Going offline
+// only stops restarts from being attempted; coming back (or switching
+// networks while online) restarts ICE on every connection so they migrate
+// to the new path rather than waiting for the keepalive timeout.
+func (t *WebRTCTransport) NetworkChanged(online bool) {
+	t.migration.mu.Lock()
+	t.migration.offline = !online
+	t.migration.mu.Unlock()
+
+	t.logger.Info("network changed", "online", online)
+	if !online {
+		return
+	}
+
+	t.pcMu.RLock()
+	peers := make([]string, 0, len(t.peerConnections))
+	for peerID := range t.peerConnections {
+		peers = append(peers, peerID)
+	}
+	t.pcMu.RUnlock()
+
+	for _, peerID := range peers {
+		if err := t.requestICERestart(peerID); err != nil {
+			t.logger.Debug("ICE restart failed", "peer", getShortID(peerID), "error", err)
+		}
+	}
+}
+
+// GetMigrationStats reports ICE restarts and connection migrations.
+func (t *WebRTCTransport) GetMigrationStats() MigrationStats {
+	t.migration.mu.Lock()
+	defer t.migration.mu.Unlock()
+	return MigrationStats{
+		Offline:   t.migration.offline,
+		Migrating: len(t.migration.pending),
+		Restarts:  t.migration.restarts,
+		Recovered: t.migration.recovered,
+		Abandoned: t.migration.abandoned,
+	}
+}
+
+// requestICERestart restarts ICE on the connection to peerID. Only the end
+// with the lower node ID offers, so the two ends never offer at once; the
+// other end asks it to over signaling.
+func (t *WebRTCTransport) requestICERestart(peerID string) error {
+	if t.nodeID < peerID {
+		return t.restartICE(peerID)
+	}
+	return t.sendSignalingMessage(map[string]interface{}{
+		"type":      "ice_restart",
+		"peer_id":   t.nodeID,
+		"target_id": peerID,
+	})
+}
+
+// restartICE sends an ICE-restart offer on the existing peer connection.
+// Offers already in flight are left to finish.
+func (t *WebRTCTransport) restartICE(peerID string) error {
+	t.pcMu.RLock()
+	pc, ok := t.peerConnections[peerID]
+	t.pcMu.RUnlock()
+	if !ok {
+		return errors.New("no peer connection")
+	}
+	if pc.SignalingState() != webrtc.SignalingStateStable {
+		return errors.New("negotiation already in progress")
+	}
+
+	offer, err := pc.CreateOffer(&webrtc.OfferOptions{ICERestart: true})
+	if err != nil {
+		return err
+	}
+	if err := pc.SetLocalDescription(offer); err != nil {
+		return err
+	}
+	t.migration.mu.Lock()
+	t.migration.restarts++
+	t.migration.mu.Unlock()
+
+	t.logger.Debug("restarting ICE", "peer", getShortID(peerID))
+	return t.sendSignalingMessage(map[string]interface{}{
+		"type":        "webrtc_offer",
+		"peer_id":     t.nodeID,
+		"target_id":   peerID,
+		"offer":       offer,
+		"ice_restart": true,
+	})
+}
+
+// handleICERestartOffer answers a restart offer on the existing peer
+// connection.
+func (t *WebRTCTransport) handleICERestartOffer(senderID string, offer webrtc.SessionDescription) {
+	t.pcMu.RLock()
+	pc, ok := t.peerConnections[senderID]
+	t.pcMu.RUnlock()
+	if !ok {
+		// The data channel was negotiated on a connection we no longer
+		// have; the sender gives up on the migration and redials.
+		t.logger.Debug("ICE restart offer for unknown connection", "peer", getShortID(senderID))
+		return
+	}
+
+	if err := pc.SetRemoteDescription(offer); err != nil {
+		t.logger.Debug("failed to apply ICE restart offer", "peer", getShortID(senderID), "error", err)
+		return
+	}
+	answer, err := pc.CreateAnswer(nil)
+	if err != nil {
+		t.logger.Debug("failed to answer ICE restart", "peer", getShortID(senderID), "error", err)
+		return
+	}
+	if err := pc.SetLocalDescription(answer); err != nil {
+		t.logger.Debug("failed to set ICE restart answer", "peer", getShortID(senderID), "error", err)
+		return
+	}
+	if err := t.sendSignalingMessage(map[string]interface{}{
+		"type":      "webrtc_answer",
+		"peer_id":   t.nodeID,
+		"target_id": senderID,
+		"answer":    answer,
+	}); err != nil {
+		t.logger.Debug("failed to send ICE restart answer", "peer", getShortID(senderID), "error", err)
+	}
+}
+
+// beginMigration holds a connection whose ICE path dropped and restarts
+// ICE if the host is online. It reports false when migration is disabled
+// and the caller should tear the connection down as before.
+func (t *WebRTCTransport) beginMigration(peerID string) bool {
+	timeout := t.config.MigrationTimeout
+	if timeout <= 0 {
+		return false
+	}
+
+	t.migration.mu.Lock()
+	if t.migration.pending == nil {
+		t.migration.pending = make(map[string]*peerMigration)
+	}
+	if _, ok := t.migration.pending[peerID]; ok {
+		t.migration.mu.Unlock()
+		return true
+	}
+	migration := &peerMigration{started: time.Now(), done: make(chan struct{})}
+	migration.timer = time.AfterFunc(timeout, func() { t.abandonMigration(peerID, migration) })
+	t.migration.pending[peerID] = migration
+	offline := t.migration.offline
+	t.migration.mu.Unlock()
+
+	t.logger.Info("connection path lost, migrating", "peer", getShortID(peerID), "offline", offline)
+	if !offline {
+		go func() {
+			if err := t.requestICERestart(peerID); err != nil {
+				t.logger.Debug("ICE restart failed", "peer", getShortID(peerID), "error", err)
+			}
+		}()
+	}
+	return true
+}
+
+// finishMigration marks a migrating connection as back on a working path.
+// It reports whether the peer was migrating.
+func (t *WebRTCTransport) finishMigration(peerID string) bool {
+	t.migration.mu.Lock()
+	migration, ok := t.migration.pending[peerID]
+	if ok {
+		delete(t.migration.pending, peerID)
+		migration.timer.Stop()
+		migration.ok = true
+		t.migration.recovered++
+		close(migration.done)
+	}
+	t.migration.mu.Unlock()
+
+	if ok {
+		t.touchPeer(peerID)
+		t.logger.Info("connection migrated", "peer", getShortID(peerID), "took", time.Since(migration.started))
+	}
+	return ok
+}
+
+// cancelMigration drops migration state for a connection that closed.
+func (t *WebRTCTransport) cancelMigration(peerID string) {
+	t.migration.mu.Lock()
+	if migration, ok := t.migration.pending[peerID]; ok {
+		delete(t.migration.pending, peerID)
+		migration.timer.Stop()
+		close(migration.done)
+	}
+	t.migration.mu.Unlock()
+}
+
+func (t *WebRTCTransport) abandonMigration(peerID string, migration *peerMigration) {
+	t.migration.mu.Lock()
+	if t.migration.pending[peerID] != migration {
+		t.migration.mu.Unlock()
+		return
+	}
+	delete(t.migration.pending, peerID)
+	t.migration.abandoned++
+	close(migration.done)
+	t.migration.mu.Unlock()
+
+	t.logger.Info("connection migration timed out", "peer", getShortID(peerID))
+	_ = t.Disconnect(peerID)
+}
+
+// connectionUses reports whether the established connection to peerID
+// runs over pc, as opposed to one being dialled or already replaced.
+func (t *WebRTCTransport) connectionUses(peerID string, pc *webrtc.PeerConnection) bool {
+	t.connMu.RLock()
+	defer t.connMu.RUnlock()
+	conn, ok := t.connections[peerID]
+	if !ok || conn.Connection == nil {
+		return false
+	}
+	rtc, ok := conn.Connection.(*WebRTCConnection)
+	return ok && rtc.pc == pc
+}
+
+func (t *WebRTCTransport) isMigrating(peerID string) bool {
+	t.migration.mu.Lock()
+	defer t.migration.mu.Unlock()
+	_, ok := t.migration.pending[peerID]
+	return ok
+}
+
+// awaitMigration blocks while peerID is migrating and reports whether the
+// connection came back, so an RPC whose send failed on the dead path can be
+// sent again on the restarted one.
+func (t *WebRTCTransport) awaitMigration(ctx context.Context, peerID string) bool {
+	t.migration.mu.Lock()
+	migration, ok := t.migration.pending[peerID]
+	t.migration.mu.Unlock()
+	if !ok {
+		return false
+	}
+	select {
+	case <-migration.done:
+		t.migration.mu.Lock()
+		defer t.migration.mu.Unlock()
+		return migration.ok
+	case <-ctx.Done():
+		return false
+	}
+}
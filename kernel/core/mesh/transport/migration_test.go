@@ -0,0 +1,88 @@
+//go:build !js || !wasm
+
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMigration_ICERestartKeepsConnectionAndRPCs(t *testing.T) {
+	server := NewMockSignalingServer()
+	defer server.Close()
+
+	newTransport := func(id string) *WebRTCTransport {
+		config := DefaultTransportConfig()
+		config.SignalingServers = []string{server.URL()}
+		config.RPCTimeout = 5 * time.Second
+		tr, _ := NewWebRTCTransport(id, config, nil)
+		tr.Start(context.Background())
+		return tr
+	}
+	t1 := newTransport("node1")
+	defer t1.Stop()
+	t2 := newTransport("node2")
+	defer t2.Stop()
+	t2.RegisterRPCHandler("echo", func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error) {
+		return "ok", nil
+	})
+
+	time.Sleep(2 * time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	if err := t1.connectViaWebRTC(ctx, "node2"); err != nil {
+		t.Fatalf("WebRTC connection failed: %v", err)
+	}
+	time.Sleep(200 * time.Millisecond)
+
+	var events atomic.Int32
+	t1.SetPeerEventHandler(func(string, bool) { events.Add(1) })
+	t2.SetPeerEventHandler(func(string, bool) { events.Add(1) })
+
+	// Both ends see the network change at once; the restarts collide and
+	// one of them has to yield.
+	t1.NetworkChanged(false)
+	t1.NetworkChanged(true)
+	t2.NetworkChanged(true)
+
+	var reply string
+	if err := t1.SendRPC(ctx, "node2", "echo", nil, &reply); err != nil || reply != "ok" {
+		t.Fatalf("RPC across the restart failed: %q, %v", reply, err)
+	}
+	time.Sleep(500 * time.Millisecond)
+
+	if stats := t1.GetMigrationStats(); stats.Restarts == 0 || stats.Offline {
+		t.Fatalf("expected an ICE restart while online, got %+v", stats)
+	}
+	if n := events.Load(); n != 0 {
+		t.Fatalf("expected the connection to survive the restart, got %d peer events", n)
+	}
+	if !t1.IsConnected("node2") || !t2.IsConnected("node1") {
+		t.Fatal("expected both ends to stay connected")
+	}
+}
+
+func TestMigration_AbandonedAfterTimeout(t *testing.T) {
+	config := DefaultTransportConfig()
+	config.MigrationTimeout = 50 * time.Millisecond
+	tr, _ := NewWebRTCTransport("node1", config, nil)
+	tr.NetworkChanged(false)
+
+	if !tr.beginMigration("node2") || !tr.isMigrating("node2") {
+		t.Fatal("expected the connection to be held for migration")
+	}
+	if tr.awaitMigration(context.Background(), "node2") {
+		t.Fatal("expected the migration to time out")
+	}
+	if stats := tr.GetMigrationStats(); stats.Abandoned != 1 || stats.Migrating != 0 || stats.Restarts != 0 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+
+	tr.config.MigrationTimeout = 0
+	if tr.beginMigration("node2") {
+		t.Fatal("expected migration to be disabled without a timeout")
+	}
+}
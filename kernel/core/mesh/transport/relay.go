@@ -0,0 +1,606 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+)
+
+// Relayed circuits connect peers that can never reach each other directly,
+// e.g. two nodes behind symmetric NATs: A opens a circuit through a relay R
+// it is connected to, R asks B to accept, and from then on A and B see each
+// other as an ordinary connection whose frames R forwards. R learns nothing
+// beyond frame sizes that the link itself would not reveal; the peer IDs it
+// announces are attested by the mesh as for any other link.
+const (
+	relayOpenEnvelopeType   = "relay_open"
+	relayAcceptEnvelopeType = "relay_accept"
+	relayDataEnvelopeType   = "relay_data"
+	relayCloseEnvelopeType  = "relay_close"
+)
+
+// relayUsageReportBytes is how much a circuit forwards between usage reports.
+const relayUsageReportBytes = 1 << 20
+
+var (
+	// ErrRelayDisabled is returned by relays that do not forward circuits.
+	ErrRelayDisabled = errors.New("relaying is disabled")
+	// ErrRelayRefused is returned when a relay or the target closes a
+	// circuit before it opens.
+	ErrRelayRefused = errors.New("relay circuit refused")
+)
+
+// RelayConfig controls forwarding circuits for other peers.
+type RelayConfig struct {
+	// Enabled offers this node as a relay; roles that can relay turn it
+	// on (runtime.RoleConfig.CanRelay). Any node can use relays.
+	Enabled bool `json:"enabled"`
+	// MaxCircuits bounds the circuits forwarded at once.
+	MaxCircuits int `json:"max_circuits"`
+	// CircuitBandwidth caps each circuit in bytes per second, each way
+	// combined; frames over the cap are dropped. 0 leaves circuits uncapped.
+	CircuitBandwidth int `json:"circuit_bandwidth"`
+}
+
+// RelayUsage reports bytes a relay forwarded on one circuit since the last
+// report. Reports come every relayUsageReportBytes and when a circuit closes.
+type RelayUsage struct {
+	CircuitID string `json:"circuit_id"`
+	Source    string `json:"source"`
+	Target    string `json:"target"`
+	Bytes     uint64 `json:"bytes"`
+	Dropped   uint64 `json:"dropped"` // Frames over the bandwidth cap
+	Closed    bool   `json:"closed"`
+}
+
+// RelayStats summarises the circuits this node forwards and uses.
+type RelayStats struct {
+	Enabled         bool   `json:"enabled"`
+	Circuits        int    `json:"circuits"`         // Forwarded for others
+	RelayedPeers    int    `json:"relayed_peers"`    // Reached through a relay
+	BytesRelayed    uint64 `json:"bytes_relayed"`    // Forwarded, all circuits
+	FramesDropped   uint64 `json:"frames_dropped"`   // Over a bandwidth cap
+	CircuitsRefused uint64 `json:"circuits_refused"` // Disabled, full or unreachable
+}
+
+// relayState tracks circuits forwarded for others, circuits this node is an
+// end of, and circuits still waiting to open.
+type relayState struct {
+	mu       sync.Mutex
+	circuits map[string]*relayCircuit    // As the relay, by circuit ID
+	ends     map[string]*relayConnection // As an end, by circuit ID
+	pending  map[string]*relayPending    // Opened by us, not yet accepted
+	onUsage  func(RelayUsage)
+
+	bytesRelayed uint64
+	dropped      uint64
+	refused      uint64
+}
+
+type relayCircuit struct {
+	id         string
+	source     string
+	target     string
+	accepted   bool
+	bucket     relayBucket
+	unreported uint64
+	dropped    uint64
+}
+
+type relayPending struct {
+	relayID string
+	done    chan error
+}
+
+// relayMessage is the payload of relay control frames. An open names the
+// target when sent to the relay and the source when the relay forwards it.
+type relayMessage struct {
+	Target string `json:"target,omitempty"`
+	Source string `json:"source,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// relayBucket caps a circuit's throughput. A frame larger than the burst
+// passes once the bucket is full and leaves it in debt.
+type relayBucket struct {
+	rate   float64 // bytes per second; 0 is uncapped
+	tokens float64
+	last   time.Time
+}
+
+func (b *relayBucket) allow(n int, now time.Time) bool {
+	if b.rate <= 0 {
+		return true
+	}
+	if !b.last.IsZero() {
+		b.tokens = min(b.tokens+now.Sub(b.last).Seconds()*b.rate, b.rate)
+	}
+	b.last = now
+	if b.tokens >= float64(n) || b.tokens >= b.rate {
+		b.tokens -= float64(n)
+		return true
+	}
+	return false
+}
+
+// SetRelayUsageHandler registers fn to hear how much this node relays, so
+// the mesh can credit it.
+func (t *WebRTCTransport) SetRelayUsageHandler(fn func(RelayUsage)) {
+	t.relay.mu.Lock()
+	t.relay.onUsage = fn
+	t.relay.mu.Unlock()
+}
+
+// RelayEnabled reports whether this node forwards circuits for others.
+func (t *WebRTCTransport) RelayEnabled() bool {
+	t.relay.mu.Lock()
+	defer t.relay.mu.Unlock()
+	return t.config.Relay.Enabled
+}
+
+// setRelayEnabled turns relaying on or off. Open circuits keep running.
+func (t *WebRTCTransport) setRelayEnabled(enabled bool) {
+	t.relay.mu.Lock()
+	t.config.Relay.Enabled = enabled
+	t.relay.mu.Unlock()
+}
+
+// RelayStats returns relay counters.
+func (t *WebRTCTransport) RelayStats() RelayStats {
+	t.relay.mu.Lock()
+	defer t.relay.mu.Unlock()
+	return RelayStats{
+		Enabled:         t.config.Relay.Enabled,
+		Circuits:        len(t.relay.circuits),
+		RelayedPeers:    len(t.relay.ends),
+		BytesRelayed:    t.relay.bytesRelayed,
+		FramesDropped:   t.relay.dropped,
+		CircuitsRefused: t.relay.refused,
+	}
+}
+
+// ConnectViaRelay reaches peerID through relayID, a connected peer willing
+// to relay. Once the circuit opens peerID is connected like any other peer.
+func (t *WebRTCTransport) ConnectViaRelay(ctx context.Context, relayID, peerID string) error {
+	switch {
+	case peerID == t.nodeID || relayID == t.nodeID:
+		return errors.New("cannot relay to self")
+	case relayID == peerID:
+		return errors.New("relay and target are the same peer")
+	case t.IsConnected(peerID):
+		return nil
+	case !t.IsConnected(relayID):
+		return fmt.Errorf("relay %s is not connected", getShortID(relayID))
+	}
+
+	circuitID, err := generateRPCID()
+	if err != nil {
+		return err
+	}
+	pending := &relayPending{relayID: relayID, done: make(chan error, 1)}
+	t.relay.mu.Lock()
+	if t.relay.pending == nil {
+		t.relay.pending = make(map[string]*relayPending)
+	}
+	t.relay.pending[circuitID] = pending
+	t.relay.mu.Unlock()
+	defer func() {
+		t.relay.mu.Lock()
+		delete(t.relay.pending, circuitID)
+		t.relay.mu.Unlock()
+	}()
+
+	if err := t.sendRelayControl(ctx, relayID, relayOpenEnvelopeType, circuitID, relayMessage{Target: peerID}); err != nil {
+		return fmt.Errorf("failed to open relay circuit: %w", err)
+	}
+
+	timeout := time.NewTimer(t.config.ConnectionTimeout)
+	defer timeout.Stop()
+	select {
+	case err := <-pending.done:
+		if err != nil {
+			return err
+		}
+	case <-ctx.Done():
+		t.sendRelayClose(relayID, circuitID, "cancelled")
+		return ctx.Err()
+	case <-timeout.C:
+		t.sendRelayClose(relayID, circuitID, "timeout")
+		return errors.New("timeout opening relay circuit")
+	}
+	if err := t.addRelayConnection(circuitID, relayID, peerID); err != nil {
+		// Connected some other way while the circuit opened
+		t.sendRelayClose(relayID, circuitID, "already connected")
+	}
+	return nil
+}
+
+// handleRelayEnvelope serves relay frames from peerID. It reports whether
+// env was one.
+func (t *WebRTCTransport) handleRelayEnvelope(peerID string, env *common.Envelope) bool {
+	switch env.Type {
+	case relayDataEnvelopeType:
+		t.handleRelayData(peerID, env)
+	case relayOpenEnvelopeType:
+		t.handleRelayOpen(peerID, env.ID, decodeRelayMessage(env.Payload))
+	case relayAcceptEnvelopeType:
+		t.handleRelayAccept(peerID, env.ID)
+	case relayCloseEnvelopeType:
+		t.handleRelayClose(peerID, env.ID, decodeRelayMessage(env.Payload).Reason)
+	default:
+		return false
+	}
+	return true
+}
+
+// handleRelayOpen either starts forwarding a circuit, when peerID asks us to
+// relay to msg.Target, or accepts one, when relay peerID brings us a circuit
+// from msg.Source.
+func (t *WebRTCTransport) handleRelayOpen(peerID, circuitID string, msg relayMessage) {
+	switch {
+	case circuitID == "":
+	case msg.Source != "":
+		t.acceptRelayCircuit(peerID, circuitID, msg.Source)
+	case msg.Target != "":
+		t.forwardRelayCircuit(peerID, circuitID, msg.Target)
+	}
+}
+
+// forwardRelayCircuit offers a circuit from source to target, if we relay
+// and have room.
+func (t *WebRTCTransport) forwardRelayCircuit(source, circuitID, target string) {
+	reachable := target != source && target != t.nodeID && t.IsConnected(target)
+	reason := ""
+	t.relay.mu.Lock()
+	switch {
+	case !t.config.Relay.Enabled:
+		reason = ErrRelayDisabled.Error()
+	case t.config.Relay.MaxCircuits > 0 && len(t.relay.circuits) >= t.config.Relay.MaxCircuits:
+		reason = "relay circuit limit reached"
+	case t.relay.circuits[circuitID] != nil:
+		reason = "duplicate circuit"
+	case !reachable:
+		reason = "target not connected to relay"
+	default:
+		if t.relay.circuits == nil {
+			t.relay.circuits = make(map[string]*relayCircuit)
+		}
+		bandwidth := float64(t.config.Relay.CircuitBandwidth)
+		t.relay.circuits[circuitID] = &relayCircuit{
+			id:     circuitID,
+			source: source,
+			target: target,
+			bucket: relayBucket{rate: bandwidth, tokens: bandwidth},
+		}
+	}
+	if reason != "" {
+		t.relay.refused++
+	}
+	t.relay.mu.Unlock()
+
+	if reason != "" {
+		t.sendRelayClose(source, circuitID, reason)
+		return
+	}
+	if err := t.sendRelayControl(context.Background(), target, relayOpenEnvelopeType, circuitID, relayMessage{Source: source}); err != nil {
+		t.closeRelayCircuit(circuitID, target, "target unreachable")
+	}
+}
+
+// acceptRelayCircuit takes a circuit from source that relayID forwards.
+func (t *WebRTCTransport) acceptRelayCircuit(relayID, circuitID, source string) {
+	if source == t.nodeID || source == relayID {
+		t.sendRelayClose(relayID, circuitID, "malformed circuit")
+		return
+	}
+	if err := t.addRelayConnection(circuitID, relayID, source); err != nil {
+		t.sendRelayClose(relayID, circuitID, err.Error())
+		return
+	}
+	if err := t.sendRelayControl(context.Background(), relayID, relayAcceptEnvelopeType, circuitID, relayMessage{}); err != nil {
+		if conn := t.relayEnd(circuitID); conn != nil {
+			t.linkClosed(source, conn)
+		}
+	}
+}
+
+// handleRelayAccept completes a circuit: the target accepted it, so the
+// relay starts forwarding and the source adds the connection.
+func (t *WebRTCTransport) handleRelayAccept(peerID, circuitID string) {
+	t.relay.mu.Lock()
+	if circuit, ok := t.relay.circuits[circuitID]; ok && circuit.target == peerID {
+		circuit.accepted = true
+		source := circuit.source
+		t.relay.mu.Unlock()
+		if err := t.sendRelayControl(context.Background(), source, relayAcceptEnvelopeType, circuitID, relayMessage{}); err != nil {
+			t.closeRelayCircuit(circuitID, "", "source unreachable")
+		}
+		return
+	}
+	pending, ok := t.relay.pending[circuitID]
+	t.relay.mu.Unlock()
+	if ok && pending.relayID == peerID {
+		select {
+		case pending.done <- nil:
+		default:
+		}
+	}
+}
+
+// handleRelayClose ends a circuit, whichever role we play in it.
+func (t *WebRTCTransport) handleRelayClose(peerID, circuitID, reason string) {
+	t.relay.mu.Lock()
+	if circuit, ok := t.relay.circuits[circuitID]; ok && (circuit.source == peerID || circuit.target == peerID) {
+		t.relay.mu.Unlock()
+		t.closeRelayCircuit(circuitID, peerID, reason)
+		return
+	}
+	if pending, ok := t.relay.pending[circuitID]; ok && pending.relayID == peerID {
+		t.relay.mu.Unlock()
+		select {
+		case pending.done <- fmt.Errorf("%w: %s", ErrRelayRefused, reason):
+		default:
+		}
+		return
+	}
+	conn, ok := t.relay.ends[circuitID]
+	t.relay.mu.Unlock()
+	if ok && conn.relayID == peerID {
+		conn.markClosed()
+		t.linkClosed(conn.peerID, conn)
+	}
+}
+
+// handleRelayData forwards a circuit frame, as the relay, or delivers it,
+// as an end.
+func (t *WebRTCTransport) handleRelayData(peerID string, env *common.Envelope) {
+	t.relay.mu.Lock()
+	if circuit, ok := t.relay.circuits[env.ID]; ok && circuit.accepted {
+		next := ""
+		switch peerID {
+		case circuit.source:
+			next = circuit.target
+		case circuit.target:
+			next = circuit.source
+		}
+		if next == "" {
+			t.relay.mu.Unlock()
+			return
+		}
+		if !circuit.bucket.allow(len(env.Payload), time.Now()) {
+			circuit.dropped++
+			t.relay.dropped++
+			t.relay.mu.Unlock()
+			return
+		}
+		circuit.unreported += uint64(len(env.Payload))
+		t.relay.bytesRelayed += uint64(len(env.Payload))
+		var usage *RelayUsage
+		if circuit.unreported >= relayUsageReportBytes {
+			usage = circuit.takeUsage(false)
+		}
+		onUsage := t.relay.onUsage
+		t.relay.mu.Unlock()
+
+		if usage != nil && onUsage != nil {
+			onUsage(*usage)
+		}
+		if err := t.SendMessage(context.Background(), next, env); err != nil {
+			t.closeRelayCircuit(env.ID, "", "peer unreachable")
+		}
+		return
+	}
+	conn, ok := t.relay.ends[env.ID]
+	t.relay.mu.Unlock()
+	if ok && conn.relayID == peerID {
+		t.handleIncomingMessage(conn.peerID, env.Payload)
+	}
+}
+
+// closeRelayCircuit stops forwarding a circuit and tells its ends, except
+// from, which closed it.
+func (t *WebRTCTransport) closeRelayCircuit(circuitID, from, reason string) {
+	t.relay.mu.Lock()
+	circuit, ok := t.relay.circuits[circuitID]
+	if !ok {
+		t.relay.mu.Unlock()
+		return
+	}
+	delete(t.relay.circuits, circuitID)
+	usage := circuit.takeUsage(true)
+	onUsage := t.relay.onUsage
+	t.relay.mu.Unlock()
+
+	for _, end := range []string{circuit.source, circuit.target} {
+		if end != from {
+			t.sendRelayClose(end, circuitID, reason)
+		}
+	}
+	if onUsage != nil && (usage.Bytes > 0 || usage.Dropped > 0) {
+		onUsage(*usage)
+	}
+}
+
+// dropRelayCircuits closes the circuits that ran over a peer that went away.
+func (t *WebRTCTransport) dropRelayCircuits(peerID string) {
+	t.relay.mu.Lock()
+	var circuits []string
+	for id, circuit := range t.relay.circuits {
+		if circuit.source == peerID || circuit.target == peerID {
+			circuits = append(circuits, id)
+		}
+	}
+	var ends []*relayConnection
+	for _, conn := range t.relay.ends {
+		if conn.relayID == peerID {
+			ends = append(ends, conn)
+		}
+	}
+	t.relay.mu.Unlock()
+
+	for _, id := range circuits {
+		t.closeRelayCircuit(id, peerID, "peer disconnected")
+	}
+	for _, conn := range ends {
+		conn.markClosed()
+		t.linkClosed(conn.peerID, conn)
+	}
+}
+
+func (c *relayCircuit) takeUsage(closed bool) *RelayUsage {
+	usage := &RelayUsage{
+		CircuitID: c.id,
+		Source:    c.source,
+		Target:    c.target,
+		Bytes:     c.unreported,
+		Dropped:   c.dropped,
+		Closed:    closed,
+	}
+	c.unreported, c.dropped = 0, 0
+	return usage
+}
+
+func (t *WebRTCTransport) sendRelayControl(ctx context.Context, peerID, kind, circuitID string, msg relayMessage) error {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return t.SendMessage(ctx, peerID, &common.Envelope{
+		ID:        circuitID,
+		Type:      kind,
+		Timestamp: time.Now().UnixNano(),
+		Payload:   payload,
+	})
+}
+
+// sendRelayClose tells peerID a circuit is closed. It does not block, so
+// connections can close while the transport holds connMu.
+func (t *WebRTCTransport) sendRelayClose(peerID, circuitID, reason string) {
+	go func() {
+		_ = t.sendRelayControl(context.Background(), peerID, relayCloseEnvelopeType, circuitID, relayMessage{Reason: reason})
+	}()
+}
+
+func decodeRelayMessage(payload []byte) relayMessage {
+	var msg relayMessage
+	_ = json.Unmarshal(payload, &msg)
+	return msg
+}
+
+// relayConnection is one end of a circuit: frames to the peer travel to
+// the relay as relay_data envelopes.
+type relayConnection struct {
+	t         *WebRTCTransport
+	circuitID string
+	relayID   string
+	peerID    string
+	closed    atomic.Bool
+	stats     ConnectionStats
+	mu        sync.RWMutex
+}
+
+// addRelayConnection registers an open circuit to peerID. A peer that is
+// already connected keeps its existing link.
+func (t *WebRTCTransport) addRelayConnection(circuitID, relayID, peerID string) error {
+	conn := &relayConnection{
+		t:         t,
+		circuitID: circuitID,
+		relayID:   relayID,
+		peerID:    peerID,
+		stats:     ConnectionStats{OpenedAt: time.Now()},
+	}
+
+	t.connMu.Lock()
+	if existing, ok := t.connections[peerID]; ok && existing.Connected && existing.Connection != nil && existing.Connection.IsOpen() {
+		t.connMu.Unlock()
+		return fmt.Errorf("peer %s is already connected", getShortID(peerID))
+	}
+	t.connections[peerID] = &PeerConnection{
+		PeerID:      peerID,
+		Connection:  conn,
+		Connected:   true,
+		LastContact: time.Now(),
+	}
+	t.connMu.Unlock()
+
+	t.relay.mu.Lock()
+	if t.relay.ends == nil {
+		t.relay.ends = make(map[string]*relayConnection)
+	}
+	t.relay.ends[circuitID] = conn
+	t.relay.mu.Unlock()
+
+	t.logger.Info("relayed connection established", "peer", getShortID(peerID), "relay", getShortID(relayID))
+	t.notifyPeerEvent(peerID, true)
+	return nil
+}
+
+func (t *WebRTCTransport) relayEnd(circuitID string) Connection {
+	t.relay.mu.Lock()
+	defer t.relay.mu.Unlock()
+	if conn, ok := t.relay.ends[circuitID]; ok {
+		return conn
+	}
+	return nil
+}
+
+func (c *relayConnection) Send(ctx context.Context, data []byte) error {
+	if c.closed.Load() {
+		return errors.New("relay circuit closed")
+	}
+	if err := c.t.SendMessage(ctx, c.relayID, &common.Envelope{
+		ID:        c.circuitID,
+		Type:      relayDataEnvelopeType,
+		Timestamp: time.Now().UnixNano(),
+		Payload:   data,
+	}); err != nil {
+		c.mu.Lock()
+		c.stats.LastError = err.Error()
+		c.mu.Unlock()
+		return err
+	}
+	c.mu.Lock()
+	c.stats.BytesSent += uint64(len(data))
+	c.stats.MessagesSent++
+	c.mu.Unlock()
+	return nil
+}
+
+// Receive is unsupported: circuit frames are delivered as they arrive.
+func (c *relayConnection) Receive(ctx context.Context) ([]byte, error) {
+	return nil, errors.New("relayed connections deliver frames to the transport")
+}
+
+func (c *relayConnection) Close() error {
+	if c.markClosed() {
+		c.t.sendRelayClose(c.relayID, c.circuitID, "closed")
+	}
+	return nil
+}
+
+// markClosed retires the connection and reports whether it was open.
+func (c *relayConnection) markClosed() bool {
+	if !c.closed.CompareAndSwap(false, true) {
+		return false
+	}
+	c.t.relay.mu.Lock()
+	delete(c.t.relay.ends, c.circuitID)
+	c.t.relay.mu.Unlock()
+	return true
+}
+
+func (c *relayConnection) IsOpen() bool {
+	return !c.closed.Load()
+}
+
+func (c *relayConnection) GetStats() ConnectionStats {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.stats
+}
@@ -0,0 +1,120 @@
+//go:build !js || !wasm
+
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newRelayTestMesh links two NATed peers to a relay over bridge links.
+func newRelayTestMesh(t *testing.T, relay RelayConfig) (a, r, b *WebRTCTransport) {
+	config := DefaultTransportConfig()
+	config.SignalingServers = nil
+	config.ConnectionTimeout = 5 * time.Second
+	config.BridgeListen = "127.0.0.1:0"
+	config.Relay = relay
+	r, err := NewWebRTCTransport("relay-node", config, nil)
+	require.NoError(t, err)
+	require.NoError(t, r.Start(context.Background()))
+	t.Cleanup(func() { _ = r.Stop() })
+
+	addr := "inos+ws://" + r.BridgeListenAddr() + "/"
+	a = newBridgeTestTransport(t, "nat-a", "")
+	b = newBridgeTestTransport(t, "nat-b", "")
+	for _, tr := range []*WebRTCTransport{a, b} {
+		_, err := tr.DialBridge(context.Background(), addr)
+		require.NoError(t, err)
+	}
+	require.Eventually(t, func() bool {
+		return r.IsConnected("nat-a") && r.IsConnected("nat-b")
+	}, 2*time.Second, 10*time.Millisecond)
+	return a, r, b
+}
+
+func TestRelay_CircuitCarriesRPCsAndReportsUsage(t *testing.T) {
+	a, r, b := newRelayTestMesh(t, RelayConfig{Enabled: true, MaxCircuits: 4})
+
+	var mu sync.Mutex
+	var usage []RelayUsage
+	r.SetRelayUsageHandler(func(u RelayUsage) {
+		mu.Lock()
+		usage = append(usage, u)
+		mu.Unlock()
+	})
+	b.RegisterRPCHandler("whoami", func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error) {
+		return map[string]string{"from": peerID}, nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, a.ConnectViaRelay(ctx, "relay-node", "nat-b"))
+	assert.True(t, a.IsConnected("nat-b"))
+	require.Eventually(t, func() bool { return b.IsConnected("nat-a") }, 2*time.Second, 10*time.Millisecond)
+
+	var reply map[string]string
+	require.NoError(t, a.SendRPC(ctx, "nat-b", "whoami", nil, &reply))
+	assert.Equal(t, "nat-a", reply["from"])
+	stats := r.RelayStats()
+	assert.Equal(t, 1, stats.Circuits)
+	assert.NotZero(t, stats.BytesRelayed)
+
+	// Closing one end closes the circuit everywhere and settles usage
+	require.NoError(t, a.Disconnect("nat-b"))
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(usage) > 0 && usage[len(usage)-1].Closed
+	}, 2*time.Second, 10*time.Millisecond)
+	require.Eventually(t, func() bool { return !b.IsConnected("nat-a") }, 2*time.Second, 10*time.Millisecond)
+	assert.Zero(t, r.RelayStats().Circuits)
+
+	mu.Lock()
+	defer mu.Unlock()
+	var total uint64
+	for _, u := range usage {
+		assert.Equal(t, "nat-a", u.Source)
+		assert.Equal(t, "nat-b", u.Target)
+		total += u.Bytes
+	}
+	assert.Equal(t, stats.BytesRelayed, total)
+}
+
+func TestRelay_RefusesWhenDisabledOrUnreachable(t *testing.T) {
+	a, r, _ := newRelayTestMesh(t, RelayConfig{})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := a.ConnectViaRelay(ctx, "relay-node", "nat-b")
+	assert.ErrorIs(t, err, ErrRelayRefused)
+	assert.True(t, strings.Contains(err.Error(), ErrRelayDisabled.Error()))
+	assert.False(t, a.IsConnected("nat-b"))
+
+	r.config.Relay.MaxCircuits = 1
+	r.setRelayEnabled(true)
+	err = a.ConnectViaRelay(ctx, "relay-node", "nobody")
+	assert.ErrorIs(t, err, ErrRelayRefused)
+	assert.Equal(t, uint64(2), r.RelayStats().CircuitsRefused)
+}
+
+func TestRelayBucket_CapsThroughput(t *testing.T) {
+	now := time.Now()
+	bucket := relayBucket{rate: 1000, tokens: 1000}
+	assert.True(t, bucket.allow(600, now))
+	assert.False(t, bucket.allow(600, now))
+	assert.True(t, bucket.allow(600, now.Add(200*time.Millisecond)))
+
+	// An oversized frame passes once the bucket is full, then waits out its debt
+	assert.True(t, bucket.allow(5000, now.Add(5*time.Second)))
+	assert.False(t, bucket.allow(1, now.Add(6*time.Second)))
+
+	unlimited := relayBucket{}
+	assert.True(t, unlimited.allow(1<<30, now))
+}
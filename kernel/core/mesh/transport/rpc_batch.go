@@ -0,0 +1,251 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+)
+
+const (
+	rpcBatchEnvelopeType         = "rpc_batch"
+	rpcBatchResponseEnvelopeType = "rpc_batch_response"
+)
+
+// rpcBatch carries several requests to one peer in a single frame. The
+// responses come back together in an rpcBatchResponse under the same ID.
+type rpcBatch struct {
+	Requests []RPCRequest `json:"requests"`
+}
+
+type rpcBatchResponse struct {
+	Responses []RPCResponse `json:"responses"`
+}
+
+// SendRPCBatch sends several calls to one peer, packed into frames of at
+// most MaxBatchCalls calls; the frames are in flight together. Each call's
+// Reply and Err are filled in. The returned error is set only when no call
+// could be sent at all.
+func (t *WebRTCTransport) SendRPCBatch(ctx context.Context, peerID string, calls []common.RPCCall) error {
+	if len(calls) == 0 {
+		return nil
+	}
+	if !t.IsConnected(peerID) {
+		if err := t.Connect(ctx, peerID); err != nil {
+			return fmt.Errorf("failed to connect to peer: %w", err)
+		}
+	}
+
+	size := t.config.MaxBatchCalls
+	if size <= 0 {
+		size = len(calls)
+	}
+	var wg sync.WaitGroup
+	errs := make([]error, (len(calls)+size-1)/size)
+	for i := 0; i*size < len(calls); i++ {
+		end := min((i+1)*size, len(calls))
+		wg.Add(1)
+		go func(i int, frame []common.RPCCall) {
+			defer wg.Done()
+			errs[i] = t.sendRPCFrame(ctx, peerID, frame)
+		}(i, calls[i*size:end])
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err == nil {
+			return nil
+		}
+	}
+	return errs[0]
+}
+
+// sendRPCFrame sends one batch frame and waits for its response. A failure
+// to send or to hear back is recorded on every call in the frame.
+func (t *WebRTCTransport) sendRPCFrame(ctx context.Context, peerID string, calls []common.RPCCall) error {
+	start := time.Now()
+	batchID, err := generateRPCID()
+	if err != nil {
+		return fmt.Errorf("failed to generate RPC ID: %w", err)
+	}
+
+	metadata := common.OutgoingRPCMetadata(ctx)
+	batch := rpcBatch{Requests: make([]RPCRequest, len(calls))}
+	index := make(map[string]int, len(calls))
+	for i, call := range calls {
+		id := fmt.Sprintf("%s.%d", batchID, i)
+		index[id] = i
+		callMetadata := metadata
+		if call.Capability != "" {
+			callMetadata.Capability = call.Capability
+		}
+		batch.Requests[i] = RPCRequest{
+			ID:       id,
+			Method:   call.Method,
+			Params:   call.Args,
+			Timeout:  t.config.RPCTimeout.Milliseconds(),
+			Metadata: &callMetadata,
+		}
+	}
+	payload, err := json.Marshal(batch)
+	if err != nil {
+		return failCalls(calls, fmt.Errorf("failed to marshal RPC batch: %w", err))
+	}
+
+	responseChan := make(chan rpcBatchResponse, 1)
+	t.rpcMu.Lock()
+	t.rpcBatches[batchID] = responseChan
+	t.rpcMu.Unlock()
+	defer func() {
+		t.rpcMu.Lock()
+		delete(t.rpcBatches, batchID)
+		t.rpcMu.Unlock()
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, t.config.RPCTimeout)
+	defer cancel()
+	err = t.SendMessage(ctx, peerID, &common.Envelope{
+		ID:        batchID,
+		Type:      rpcBatchEnvelopeType,
+		Timestamp: time.Now().UnixNano(),
+		Version:   "1.0",
+		Payload:   payload,
+	})
+	if err != nil {
+		return failCalls(calls, fmt.Errorf("failed to send RPC batch: %w", err))
+	}
+	t.rpcBatchesSent.Add(1)
+	t.rpcBatchedCalls.Add(uint64(len(calls)))
+
+	select {
+	case <-ctx.Done():
+		return failCalls(calls, ctx.Err())
+	case response := <-responseChan:
+		t.recordRPCLatency(peerID, time.Since(start))
+		answered := make([]bool, len(calls))
+		for _, r := range response.Responses {
+			i, ok := index[r.ID]
+			if !ok || answered[i] {
+				continue
+			}
+			answered[i] = true
+			calls[i].Err = decodeRPCResponse(r, calls[i].Reply)
+		}
+		for i := range calls {
+			if !answered[i] {
+				calls[i].Err = errors.New("RPC batch response omitted the call")
+			}
+		}
+		return nil
+	}
+}
+
+func failCalls(calls []common.RPCCall, err error) error {
+	for i := range calls {
+		calls[i].Err = err
+	}
+	return err
+}
+
+// decodeRPCResponse turns a response into the caller's reply or error.
+func decodeRPCResponse(response RPCResponse, reply interface{}) error {
+	if response.Error != nil {
+		return fmt.Errorf("RPC error: %s (code: %d)", response.Error.Message, response.Error.Code)
+	}
+	if reply == nil || response.Result == nil {
+		return nil
+	}
+	resultBytes, err := json.Marshal(response.Result)
+	if err != nil {
+		return fmt.Errorf("failed to marshal result: %w", err)
+	}
+	if err := json.Unmarshal(resultBytes, reply); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return nil
+}
+
+// handleRPCBatch runs every request in a batch concurrently and answers
+// with one frame once all have finished.
+func (t *WebRTCTransport) handleRPCBatch(peerID, batchID string, data []byte) {
+	var batch rpcBatch
+	if err := json.Unmarshal(data, &batch); err != nil {
+		t.logger.Debug("failed to decode RPC batch", "peer", getShortID(peerID), "error", err)
+		return
+	}
+	if limit := t.config.MaxBatchCalls; limit > 0 && len(batch.Requests) > limit {
+		batch.Requests = batch.Requests[:limit]
+	}
+
+	response := rpcBatchResponse{Responses: make([]RPCResponse, len(batch.Requests))}
+	var wg sync.WaitGroup
+	for i, request := range batch.Requests {
+		wg.Add(1)
+		go func(i int, request RPCRequest) {
+			defer wg.Done()
+			response.Responses[i] = t.serveRPC(peerID, request)
+		}(i, request)
+	}
+	wg.Wait()
+
+	payload, _ := json.Marshal(response)
+	t.SendMessage(context.Background(), peerID, &common.Envelope{
+		ID:        batchID,
+		Type:      rpcBatchResponseEnvelopeType,
+		Timestamp: time.Now().UnixNano(),
+		Payload:   payload,
+	})
+}
+
+// deliverRPCBatchResponse hands a batch response to the waiting sender. A
+// sender that already gave up is skipped rather than blocked on.
+func (t *WebRTCTransport) deliverRPCBatchResponse(batchID string, data []byte) {
+	t.rpcMu.RLock()
+	responseChan, exists := t.rpcBatches[batchID]
+	t.rpcMu.RUnlock()
+	if !exists {
+		return
+	}
+	var response rpcBatchResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		return
+	}
+	select {
+	case responseChan <- response:
+	default:
+	}
+}
+
+// dispatchRPC runs an incoming request off the receive loop, so a slow
+// handler does not hold up later requests or responses from the same peer.
+// At most MaxPipelinedRPCs requests per peer run at once; beyond that the
+// receive loop waits, pushing back on the sender.
+func (t *WebRTCTransport) dispatchRPC(peerID string, serve func()) {
+	t.connMu.RLock()
+	conn, exists := t.connections[peerID]
+	t.connMu.RUnlock()
+	if !exists || t.config.MaxPipelinedRPCs <= 0 {
+		serve()
+		return
+	}
+
+	slots := conn.rpcSlotsFor(t.config.MaxPipelinedRPCs)
+	slots <- struct{}{}
+	go func() {
+		defer func() { <-slots }()
+		serve()
+	}()
+}
+
+func (c *PeerConnection) rpcSlotsFor(limit int) chan struct{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.rpcSlots == nil {
+		c.rpcSlots = make(chan struct{}, limit)
+	}
+	return c.rpcSlots
+}
@@ -0,0 +1,142 @@
+//go:build !js || !wasm
+
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+)
+
+// pipeConnection delivers each sent frame, in order, to the peer transport.
+type pipeConnection struct {
+	*MockConnection
+	frames chan []byte
+}
+
+func newPipeConnection(t *testing.T, to *WebRTCTransport, from string) *pipeConnection {
+	c := &pipeConnection{MockConnection: NewMockConnection(), frames: make(chan []byte, 64)}
+	done := make(chan struct{})
+	t.Cleanup(func() { close(done) })
+	go func() {
+		for {
+			select {
+			case frame := <-c.frames:
+				to.handleIncomingMessage(from, frame)
+			case <-done:
+				return
+			}
+		}
+	}()
+	return c
+}
+
+func (c *pipeConnection) Send(ctx context.Context, data []byte) error {
+	c.frames <- append([]byte(nil), data...)
+	return c.MockConnection.Send(ctx, data)
+}
+
+func pipedTransports(t *testing.T) (a, b *WebRTCTransport, aConn *pipeConnection) {
+	t.Helper()
+	cfg := DefaultTransportConfig()
+	cfg.TranscriptCheckpointFrames = 0
+	cfg.RPCTimeout = 2 * time.Second
+	a, _ = NewWebRTCTransport("node-a", cfg, nil)
+	b, _ = NewWebRTCTransport("node-b", cfg, nil)
+	aConn = newPipeConnection(t, b, "node-a")
+	a.connections["node-b"] = &PeerConnection{PeerID: "node-b", Connection: aConn, Connected: true}
+	b.connections["node-a"] = &PeerConnection{PeerID: "node-a", Connection: newPipeConnection(t, a, "node-b"), Connected: true}
+	return a, b, aConn
+}
+
+func TestRPCBatch_OneFrameCarriesEveryCall(t *testing.T) {
+	a, b, aConn := pipedTransports(t)
+	b.RegisterRPCHandler("echo", func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error) {
+		var n int
+		_ = json.Unmarshal(args, &n)
+		return n * 2, nil
+	})
+	b.RegisterRPCHandler("fail", func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error) {
+		return nil, errors.New("boom")
+	})
+
+	replies := make([]int, 3)
+	calls := []common.RPCCall{
+		{Method: "echo", Args: 1, Reply: &replies[0]},
+		{Method: "echo", Args: 2, Reply: &replies[1]},
+		{Method: "fail"},
+		{Method: "echo", Args: 3, Reply: &replies[2]},
+		{Method: "missing"},
+	}
+	if err := a.SendRPCBatch(context.Background(), "node-b", calls); err != nil {
+		t.Fatalf("SendRPCBatch failed: %v", err)
+	}
+	if replies[0] != 2 || replies[1] != 4 || replies[2] != 6 {
+		t.Fatalf("unexpected replies %v", replies)
+	}
+	for i, want := range []bool{false, false, true, false, true} {
+		if (calls[i].Err != nil) != want {
+			t.Fatalf("call %d: unexpected error state %v", i, calls[i].Err)
+		}
+	}
+	if n := len(aConn.getSent()); n != 1 {
+		t.Fatalf("expected the batch in one frame, sent %d", n)
+	}
+	if n := a.GetStats()["rpc_batched_calls"].(uint64); n != 5 {
+		t.Fatalf("expected 5 batched calls, got %d", n)
+	}
+}
+
+func TestRPCBatch_CapabilityTravelsPerCall(t *testing.T) {
+	a, b, _ := pipedTransports(t)
+	b.RegisterRPCHandler("whoami", func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error) {
+		return common.CapabilityFromContext(ctx), nil
+	})
+
+	replies := make([]string, 3)
+	calls := []common.RPCCall{
+		{Method: "whoami", Reply: &replies[0], Capability: "token-a"},
+		{Method: "whoami", Reply: &replies[1]},
+		{Method: "whoami", Reply: &replies[2], Capability: "token-b"},
+	}
+	if err := a.SendRPCBatch(context.Background(), "node-b", calls); err != nil {
+		t.Fatalf("SendRPCBatch failed: %v", err)
+	}
+	if replies[0] != "token-a" || replies[1] != "" || replies[2] != "token-b" {
+		t.Fatalf("expected each call to carry only its own capability, got %q", replies)
+	}
+}
+
+func TestRPCBatch_SlowHandlerDoesNotBlockPipelinedCalls(t *testing.T) {
+	a, b, _ := pipedTransports(t)
+	release := make(chan struct{})
+	b.RegisterRPCHandler("slow", func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error) {
+		<-release
+		return "slow", nil
+	})
+	b.RegisterRPCHandler("fast", func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error) {
+		return "fast", nil
+	})
+
+	slowDone := make(chan error, 1)
+	go func() { slowDone <- a.SendRPC(context.Background(), "node-b", "slow", nil, nil) }()
+	time.Sleep(20 * time.Millisecond)
+
+	var reply string
+	if err := a.SendRPC(context.Background(), "node-b", "fast", nil, &reply); err != nil || reply != "fast" {
+		t.Fatalf("fast call behind a slow one failed: %q %v", reply, err)
+	}
+	select {
+	case <-slowDone:
+		t.Fatal("slow call finished before it was released")
+	default:
+	}
+	close(release)
+	if err := <-slowDone; err != nil {
+		t.Fatalf("slow call failed: %v", err)
+	}
+}
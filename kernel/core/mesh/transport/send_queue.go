@@ -0,0 +1,324 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+)
+
+// MessagePriority orders the messages waiting in a peer's send queue.
+type MessagePriority int
+
+const (
+	// PriorityLow is best-effort traffic such as broadcasts. It is dropped,
+	// not waited for, when the peer's queue is full, and is never retried.
+	PriorityLow MessagePriority = -1
+	// PriorityNormal is the default.
+	PriorityNormal MessagePriority = 0
+	// PriorityHigh jumps ahead of everything else queued for the peer.
+	PriorityHigh MessagePriority = 1
+)
+
+// ErrSendQueueFull is returned for a low-priority message that found its
+// peer's queue full, or that was pushed out to make room for another.
+var ErrSendQueueFull = errors.New("peer send queue full")
+
+var errSendQueueClosed = errors.New("peer send queue closed")
+
+// PeerQueueStatus describes one peer's outbound queue.
+type PeerQueueStatus struct {
+	Length    int    `json:"length"`
+	Capacity  int    `json:"capacity"`
+	Saturated bool   `json:"saturated"` // Past the high watermark, not yet back under the low one
+	Dropped   uint64 `json:"dropped"`
+}
+
+// peerSendQueue is one peer's outbound queue. A writer goroutine runs
+// while it holds messages, so a peer that is slow to accept frames only
+// delays its own traffic.
+type peerSendQueue struct {
+	mu        sync.Mutex
+	lanes     [3][]QueuedMessage // High, normal, low
+	length    int
+	space     chan struct{} // Closed when a message leaves the queue
+	writing   bool
+	closed    bool
+	saturated bool
+	dropped   uint64
+}
+
+func laneFor(p MessagePriority) int {
+	switch {
+	case p > PriorityNormal:
+		return 0
+	case p < PriorityNormal:
+		return 2
+	}
+	return 1
+}
+
+func (q *peerSendQueue) push(msg QueuedMessage) {
+	lane := laneFor(msg.Priority)
+	q.lanes[lane] = append(q.lanes[lane], msg)
+	q.length++
+}
+
+func (q *peerSendQueue) pop() (QueuedMessage, bool) {
+	for i := range q.lanes {
+		if len(q.lanes[i]) > 0 {
+			msg := q.lanes[i][0]
+			q.lanes[i][0] = QueuedMessage{}
+			q.lanes[i] = q.lanes[i][1:]
+			q.length--
+			close(q.space)
+			q.space = make(chan struct{})
+			return msg, true
+		}
+	}
+	return QueuedMessage{}, false
+}
+
+// evictLow removes the newest low-priority message, which has waited the
+// least, to make room for more important traffic.
+func (q *peerSendQueue) evictLow() (QueuedMessage, bool) {
+	low := q.lanes[2]
+	if len(low) == 0 {
+		return QueuedMessage{}, false
+	}
+	msg := low[len(low)-1]
+	q.lanes[2] = low[:len(low)-1]
+	q.length--
+	q.dropped++
+	return msg, true
+}
+
+// updateSaturation applies the watermarks and reports a change. A queue is
+// saturated at three quarters full and clears again at half.
+func (q *peerSendQueue) updateSaturation(limit int) (changed bool) {
+	switch {
+	case !q.saturated && q.length*4 >= limit*3:
+		q.saturated = true
+		return true
+	case q.saturated && q.length*2 <= limit:
+		q.saturated = false
+		return true
+	}
+	return false
+}
+
+// QueueMessage queues a message for a peer and returns a channel that
+// receives the outcome once it was sent, retried up to MaxRetries, or
+// dropped. Normal and high priority messages wait for room in a full
+// queue until ctx is done; low priority ones are dropped at once.
+func (t *WebRTCTransport) QueueMessage(ctx context.Context, peerID string, message interface{}, priority MessagePriority) <-chan error {
+	result := make(chan error, 1)
+	err := t.enqueue(QueuedMessage{
+		PeerID:   peerID,
+		Message:  message,
+		Priority: priority,
+		Context:  ctx,
+		Result:   result,
+	})
+	if err != nil {
+		result <- err
+	}
+	return result
+}
+
+// SetBackpressureHandler registers a callback for peers whose send queue
+// crosses into or out of saturation. Senders may use it to slow down.
+func (t *WebRTCTransport) SetBackpressureHandler(handler func(peerID string, saturated bool)) {
+	t.sendQueueMu.Lock()
+	t.backpressureHandler = handler
+	t.sendQueueMu.Unlock()
+}
+
+// PeerQueueStatus reports the state of a peer's send queue.
+func (t *WebRTCTransport) PeerQueueStatus(peerID string) PeerQueueStatus {
+	status := PeerQueueStatus{Capacity: t.sendQueueLimit()}
+	t.sendQueueMu.Lock()
+	q := t.sendQueues[peerID]
+	t.sendQueueMu.Unlock()
+	if q == nil {
+		return status
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	status.Length = q.length
+	status.Saturated = q.saturated
+	status.Dropped = q.dropped
+	return status
+}
+
+func (t *WebRTCTransport) sendQueueLimit() int {
+	return int(t.sendQueueSize.Load())
+}
+
+func (t *WebRTCTransport) peerQueue(peerID string) *peerSendQueue {
+	t.sendQueueMu.Lock()
+	defer t.sendQueueMu.Unlock()
+	q, ok := t.sendQueues[peerID]
+	if !ok {
+		q = &peerSendQueue{space: make(chan struct{})}
+		t.sendQueues[peerID] = q
+	}
+	return q
+}
+
+func (t *WebRTCTransport) enqueue(msg QueuedMessage) error {
+	if msg.Context == nil {
+		msg.Context = context.Background()
+	}
+	for {
+		q := t.peerQueue(msg.PeerID)
+		limit := t.sendQueueLimit()
+
+		q.mu.Lock()
+		if q.closed {
+			// Replaced after a disconnect; go again with the new queue
+			q.mu.Unlock()
+			continue
+		}
+		var evicted *QueuedMessage
+		if q.length >= limit {
+			if msg.Priority < PriorityNormal {
+				q.dropped++
+				q.mu.Unlock()
+				t.messagesDropped.Add(1)
+				return ErrSendQueueFull
+			}
+			if low, ok := q.evictLow(); ok {
+				evicted = &low
+			}
+		}
+		if q.length < limit {
+			q.push(msg)
+			changed := q.updateSaturation(limit)
+			start := !q.writing
+			q.writing = true
+			saturated := q.saturated
+			q.mu.Unlock()
+
+			if evicted != nil {
+				t.messagesDropped.Add(1)
+				deliverQueued(*evicted, ErrSendQueueFull)
+			}
+			if changed {
+				t.notifyBackpressure(msg.PeerID, saturated)
+			}
+			if start {
+				go t.drainSendQueue(msg.PeerID, q)
+			}
+			return nil
+		}
+
+		// Full of normal and high priority traffic: wait for the writer
+		space := q.space
+		q.mu.Unlock()
+		select {
+		case <-space:
+		case <-msg.Context.Done():
+			return msg.Context.Err()
+		case <-t.shutdown:
+			return errSendQueueClosed
+		}
+	}
+}
+
+// drainSendQueue is a peer's writer. It sends queued messages in priority
+// order and exits once the queue is empty.
+func (t *WebRTCTransport) drainSendQueue(peerID string, q *peerSendQueue) {
+	for {
+		q.mu.Lock()
+		msg, ok := q.pop()
+		if !ok {
+			q.writing = false
+			q.mu.Unlock()
+			return
+		}
+		changed := q.updateSaturation(t.sendQueueLimit())
+		saturated := q.saturated
+		q.mu.Unlock()
+		if changed {
+			t.notifyBackpressure(peerID, saturated)
+		}
+
+		err := t.SendMessage(msg.Context, peerID, msg.Message)
+		if err != nil && msg.Priority >= PriorityNormal && msg.Retries < t.config.MaxRetries && msg.Context.Err() == nil {
+			msg.Retries++
+			backoff := time.Duration(math.Pow(2, float64(msg.Retries))) * 100 * time.Millisecond
+			time.AfterFunc(backoff, func() {
+				if err := t.enqueue(msg); err != nil {
+					deliverQueued(msg, err)
+				}
+			})
+			continue
+		}
+		deliverQueued(msg, err)
+	}
+}
+
+// closeSendQueue fails everything still queued for a peer.
+func (t *WebRTCTransport) closeSendQueue(peerID string) {
+	t.sendQueueMu.Lock()
+	q := t.sendQueues[peerID]
+	delete(t.sendQueues, peerID)
+	t.sendQueueMu.Unlock()
+	if q == nil {
+		return
+	}
+
+	q.mu.Lock()
+	q.closed = true
+	var pending []QueuedMessage
+	for {
+		msg, ok := q.pop()
+		if !ok {
+			break
+		}
+		pending = append(pending, msg)
+	}
+	q.mu.Unlock()
+	for _, msg := range pending {
+		deliverQueued(msg, errSendQueueClosed)
+	}
+}
+
+// queuedMessageCount is the number of messages waiting across all peers.
+func (t *WebRTCTransport) queuedMessageCount() int {
+	t.sendQueueMu.Lock()
+	queues := make([]*peerSendQueue, 0, len(t.sendQueues))
+	for _, q := range t.sendQueues {
+		queues = append(queues, q)
+	}
+	t.sendQueueMu.Unlock()
+
+	total := 0
+	for _, q := range queues {
+		q.mu.Lock()
+		total += q.length
+		q.mu.Unlock()
+	}
+	return total
+}
+
+func (t *WebRTCTransport) notifyBackpressure(peerID string, saturated bool) {
+	t.sendQueueMu.Lock()
+	handler := t.backpressureHandler
+	t.sendQueueMu.Unlock()
+	if handler != nil {
+		handler(peerID, saturated)
+	}
+}
+
+func deliverQueued(msg QueuedMessage, err error) {
+	if msg.Result == nil {
+		return
+	}
+	select {
+	case msg.Result <- err:
+	default:
+	}
+}
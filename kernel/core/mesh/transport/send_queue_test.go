@@ -0,0 +1,108 @@
+//go:build !js || !wasm
+
+package transport
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// stalledConnection holds every Send until released.
+type stalledConnection struct {
+	*MockConnection
+	release chan struct{}
+}
+
+func (c *stalledConnection) Send(ctx context.Context, data []byte) error {
+	select {
+	case <-c.release:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return c.MockConnection.Send(ctx, data)
+}
+
+func TestSendQueue_SlowPeerOnlyDelaysItself(t *testing.T) {
+	tr, _ := NewWebRTCTransport("n1", DefaultTransportConfig(), nil)
+	slow := &stalledConnection{MockConnection: NewMockConnection(), release: make(chan struct{})}
+	fast := NewMockConnection()
+	tr.connections["slow"] = &PeerConnection{PeerID: "slow", Connection: slow, Connected: true}
+	tr.connections["fast"] = &PeerConnection{PeerID: "fast", Connection: fast, Connected: true}
+
+	stuck := tr.QueueMessage(context.Background(), "slow", "first", PriorityNormal)
+	select {
+	case err := <-tr.QueueMessage(context.Background(), "fast", "second", PriorityNormal):
+		if err != nil {
+			t.Fatalf("send to the fast peer failed: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the fast peer waited behind the slow one")
+	}
+
+	close(slow.release)
+	if err := <-stuck; err != nil {
+		t.Fatalf("send to the slow peer failed: %v", err)
+	}
+}
+
+func TestSendQueue_DropsLowPriorityWhenSaturated(t *testing.T) {
+	config := DefaultTransportConfig()
+	config.MessageQueueSize = 4
+	tr, _ := NewWebRTCTransport("n1", config, nil)
+	conn := &stalledConnection{MockConnection: NewMockConnection(), release: make(chan struct{})}
+	tr.connections["p1"] = &PeerConnection{PeerID: "p1", Connection: conn, Connected: true}
+
+	var mu sync.Mutex
+	var signals []bool
+	tr.SetBackpressureHandler(func(peerID string, saturated bool) {
+		mu.Lock()
+		signals = append(signals, saturated)
+		mu.Unlock()
+	})
+
+	// The writer takes the first message and stalls on it
+	first := tr.QueueMessage(context.Background(), "p1", "first", PriorityNormal)
+	deadline := time.Now().Add(time.Second)
+	for tr.PeerQueueStatus("p1").Length != 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	low := make([]<-chan error, 4)
+	for i := range low {
+		low[i] = tr.QueueMessage(context.Background(), "p1", i, PriorityLow)
+	}
+	if status := tr.PeerQueueStatus("p1"); status.Length != 4 || !status.Saturated {
+		t.Fatalf("expected a full, saturated queue: %+v", status)
+	}
+	if err := <-tr.QueueMessage(context.Background(), "p1", "extra", PriorityLow); !errors.Is(err, ErrSendQueueFull) {
+		t.Fatalf("expected the extra low-priority message dropped, got %v", err)
+	}
+
+	// A high-priority message pushes out the newest low-priority one
+	high := tr.QueueMessage(context.Background(), "p1", "urgent", PriorityHigh)
+	if err := <-low[3]; !errors.Is(err, ErrSendQueueFull) {
+		t.Fatalf("expected the newest low-priority message evicted, got %v", err)
+	}
+	if status := tr.PeerQueueStatus("p1"); status.Dropped != 2 {
+		t.Fatalf("expected two drops, got %+v", status)
+	}
+
+	close(conn.release)
+	for _, result := range []<-chan error{first, high, low[0], low[1], low[2]} {
+		if err := <-result; err != nil {
+			t.Fatalf("queued send failed: %v", err)
+		}
+	}
+	if sent := conn.getSent(); len(sent) != 5 {
+		t.Fatalf("expected 5 frames, got %d", len(sent))
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(signals) != 2 || !signals[0] || signals[1] {
+		t.Fatalf("expected saturation to be raised then cleared, got %v", signals)
+	}
+}
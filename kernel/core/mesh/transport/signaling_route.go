@@ -0,0 +1,97 @@
+package transport
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+const gossipSignalingScheme = "gossip://"
+
+// Signaling modes reported in transport stats.
+const (
+	SignalingModeMesh      = "mesh"      // offers/answers/ICE travel over gossip
+	SignalingModeBootstrap = "bootstrap" // no mesh peers yet; WebSocket servers are used
+)
+
+func isGossipSignalingURL(url string) bool {
+	return strings.HasPrefix(url, gossipSignalingScheme)
+}
+
+// meshSignalingReady reports whether signaling can be completed over the mesh:
+// a gossip channel is attached and at least one peer is connected to carry it.
+func (t *WebRTCTransport) meshSignalingReady() bool {
+	t.signalingMu.RLock()
+	hasGossip := false
+	for url, ch := range t.signaling {
+		if isGossipSignalingURL(url) && ch != nil && ch.IsConnected() {
+			hasGossip = true
+			break
+		}
+	}
+	t.signalingMu.RUnlock()
+
+	return hasGossip && len(t.GetConnectedPeers()) > 0
+}
+
+// SignalingMode returns the active signaling mode.
+func (t *WebRTCTransport) SignalingMode() string {
+	if t.meshSignalingReady() {
+		return SignalingModeMesh
+	}
+	return SignalingModeBootstrap
+}
+
+// recordSignalingRoute remembers which channel a peer last reached us on, so
+// replies to a bootstrapping peer go back over the server it is listening to.
+func (t *WebRTCTransport) recordSignalingRoute(url string, message []byte) {
+	var envelope struct {
+		PeerID string `json:"peer_id"`
+	}
+	if err := json.Unmarshal(message, &envelope); err != nil || envelope.PeerID == "" || envelope.PeerID == t.nodeID {
+		return
+	}
+
+	t.signalingMu.Lock()
+	if t.signalingRoutes == nil {
+		t.signalingRoutes = make(map[string]string)
+	}
+	t.signalingRoutes[envelope.PeerID] = url
+	t.signalingMu.Unlock()
+}
+
+// selectSignalingChannels picks where a message should be sent. Targeted
+// messages follow the peer's known route; otherwise gossip is used whenever the
+// mesh can carry it and WebSocket servers are only used for cold bootstrap.
+// The second return value lists every channel for fallback.
+func (t *WebRTCTransport) selectSignalingChannels(message interface{}) ([]SignalingChannel, []SignalingChannel) {
+	targetID := ""
+	if msg, ok := message.(map[string]interface{}); ok {
+		targetID, _ = msg["target_id"].(string)
+	}
+	meshReady := !t.config.BootstrapSignalingAlways && t.meshSignalingReady()
+
+	t.signalingMu.RLock()
+	defer t.signalingMu.RUnlock()
+
+	all := make([]SignalingChannel, 0, len(t.signaling))
+	gossip := make([]SignalingChannel, 0, 1)
+	for url, ch := range t.signaling {
+		if ch == nil || !ch.IsConnected() {
+			continue
+		}
+		all = append(all, ch)
+		if isGossipSignalingURL(url) {
+			gossip = append(gossip, ch)
+		}
+	}
+
+	if route, ok := t.signalingRoutes[targetID]; ok && targetID != "" {
+		if ch := t.signaling[route]; ch != nil && ch.IsConnected() {
+			return []SignalingChannel{ch}, all
+		}
+	}
+	if meshReady && len(gossip) > 0 {
+		return gossip, all
+	}
+	return all, all
+}
@@ -0,0 +1,72 @@
+//go:build !js || !wasm
+
+package transport
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingSignalingChannel struct {
+	mu   sync.Mutex
+	sent int
+}
+
+func (r *recordingSignalingChannel) Send(message interface{}) error {
+	r.mu.Lock()
+	r.sent++
+	r.mu.Unlock()
+	return nil
+}
+
+func (r *recordingSignalingChannel) Receive() ([]byte, error) { select {} }
+func (r *recordingSignalingChannel) Close() error             { return nil }
+func (r *recordingSignalingChannel) IsConnected() bool        { return true }
+
+func (r *recordingSignalingChannel) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.sent
+}
+
+func TestWebRTCTransport_SignalingPrefersMeshOnceConnected(t *testing.T) {
+	tr, _ := NewWebRTCTransport("node-self", DefaultTransportConfig(), nil)
+	gossip := &recordingSignalingChannel{}
+	ws := &recordingSignalingChannel{}
+	tr.signaling["gossip://mesh"] = gossip
+	tr.signaling["wss://bootstrap.example"] = ws
+
+	offer := map[string]interface{}{"type": "webrtc_offer", "peer_id": "node-self", "target_id": "peer-b"}
+
+	// Cold bootstrap: no mesh peers, every channel is used.
+	assert.Equal(t, SignalingModeBootstrap, tr.SignalingMode())
+	assert.NoError(t, tr.sendSignalingMessage(offer))
+	assert.Equal(t, 1, gossip.count())
+	assert.Equal(t, 1, ws.count())
+
+	tr.connections["peer-a"] = &PeerConnection{PeerID: "peer-a", Connection: NewMockConnection(), Connected: true}
+	assert.Equal(t, SignalingModeMesh, tr.SignalingMode())
+	assert.NoError(t, tr.sendSignalingMessage(offer))
+	assert.Equal(t, 2, gossip.count())
+	assert.Equal(t, 1, ws.count(), "websocket should not be used once the mesh can relay")
+	assert.NoError(t, tr.ensureSignaling())
+}
+
+func TestWebRTCTransport_SignalingRepliesOnArrivalRoute(t *testing.T) {
+	tr, _ := NewWebRTCTransport("node-self", DefaultTransportConfig(), nil)
+	gossip := &recordingSignalingChannel{}
+	ws := &recordingSignalingChannel{}
+	tr.signaling["gossip://mesh"] = gossip
+	tr.signaling["wss://bootstrap.example"] = ws
+	tr.connections["peer-a"] = &PeerConnection{PeerID: "peer-a", Connection: NewMockConnection(), Connected: true}
+
+	// A bootstrapping peer reached us over WebSocket and has no mesh path yet.
+	tr.recordSignalingRoute("wss://bootstrap.example", []byte(`{"type":"webrtc_offer","peer_id":"cold-peer"}`))
+
+	answer := map[string]interface{}{"type": "webrtc_answer", "peer_id": "node-self", "target_id": "cold-peer"}
+	assert.NoError(t, tr.sendSignalingMessage(answer))
+	assert.Equal(t, 1, ws.count())
+	assert.Equal(t, 0, gossip.count())
+}
@@ -0,0 +1,270 @@
+package transport
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+)
+
+// Every frame on a connection is folded into a rolling transcript hash,
+// one per direction: h' = sha256(h || len(frame) || frame). Each side
+// periodically sends a checkpoint of its send transcript; the receiver
+// compares it with its receive transcript at the same frame count. A relay
+// that drops, reorders, injects or rewrites frames makes the two disagree,
+// which a checksum inside each frame cannot show.
+const (
+	transcriptEnvelopeType = "transcript"
+
+	// transcriptHistory is how many receive checkpoints are kept, so a
+	// checkpoint still verifies when frames raced past it.
+	transcriptHistory = 64
+)
+
+// TranscriptMismatch is the security event raised when a peer's transcript
+// checkpoint does not match what this node received.
+type TranscriptMismatch struct {
+	PeerID     string    `json:"peer_id"`
+	Frames     uint64    `json:"frames"`
+	Local      string    `json:"local"`  // Receive transcript at Frames, hex
+	Remote     string    `json:"remote"` // Peer's send transcript at Frames, hex
+	DetectedAt time.Time `json:"detected_at"`
+}
+
+type transcriptCheckpoint struct {
+	Frames uint64 `json:"frames"`
+	Hash   string `json:"hash"`
+}
+
+type transcriptStream struct {
+	frames  uint64
+	hash    [sha256.Size]byte
+	history map[uint64][sha256.Size]byte // receive side only
+}
+
+func (s *transcriptStream) update(frame []byte) {
+	var n [8]byte
+	binary.BigEndian.PutUint64(n[:], uint64(len(frame)))
+	h := sha256.New()
+	h.Write(s.hash[:])
+	h.Write(n[:])
+	h.Write(frame)
+	copy(s.hash[:], h.Sum(nil))
+	s.frames++
+
+	if s.history != nil {
+		s.history[s.frames] = s.hash
+		delete(s.history, s.frames-transcriptHistory)
+	}
+}
+
+// connectionTranscript holds both directions for one connection. sendMu
+// serializes sends so frames hit the wire in transcript order.
+type connectionTranscript struct {
+	sendMu sync.Mutex
+	sent   transcriptStream
+
+	recvMu sync.Mutex
+	recv   transcriptStream
+
+	sinceCheckpoint int
+}
+
+func newConnectionTranscript() *connectionTranscript {
+	return &connectionTranscript{recv: transcriptStream{history: make(map[uint64][sha256.Size]byte)}}
+}
+
+// transcriptState returns the connection's transcript, creating it on first use.
+func (c *PeerConnection) transcriptState() *connectionTranscript {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.transcript == nil {
+		c.transcript = newConnectionTranscript()
+	}
+	return c.transcript
+}
+
+// SetSecurityEventHandler registers a callback for transcript mismatches.
+func (t *WebRTCTransport) SetSecurityEventHandler(handler func(TranscriptMismatch)) {
+	t.securityMu.Lock()
+	defer t.securityMu.Unlock()
+	t.securityHandler = handler
+}
+
+// sendFrame writes one frame and folds it into the send transcript, sending
+// a checkpoint every TranscriptCheckpointFrames frames.
+func (t *WebRTCTransport) sendFrame(ctx context.Context, conn *PeerConnection, data []byte) error {
+	return t.sendFrames(ctx, conn, data)
+}
+
+// sendFrames sends consecutive frames with nothing interleaved, checkpoint
+// included.
+func (t *WebRTCTransport) sendFrames(ctx context.Context, conn *PeerConnection, frames ...[]byte) error {
+	tr := conn.transcriptState()
+	tr.sendMu.Lock()
+	defer tr.sendMu.Unlock()
+
+	for _, data := range frames {
+		if err := conn.Connection.Send(ctx, data); err != nil {
+			return err
+		}
+		tr.sent.update(data)
+		tr.sinceCheckpoint++
+	}
+
+	if every := t.config.TranscriptCheckpointFrames; every > 0 && tr.sinceCheckpoint >= every {
+		if err := t.sendCheckpointLocked(ctx, conn, tr); err != nil {
+			t.logger.Debug("transcript checkpoint failed", "peer", getShortID(conn.PeerID), "error", err)
+		}
+	}
+	return nil
+}
+
+// sendCheckpointLocked sends the send transcript as it stands, then folds
+// the checkpoint frame in like any other. The caller holds tr.sendMu.
+func (t *WebRTCTransport) sendCheckpointLocked(ctx context.Context, conn *PeerConnection, tr *connectionTranscript) error {
+	payload, _ := json.Marshal(transcriptCheckpoint{Frames: tr.sent.frames, Hash: hex.EncodeToString(tr.sent.hash[:])})
+	env := &common.Envelope{
+		ID:        fmt.Sprintf("transcript_%d", tr.sent.frames),
+		Type:      transcriptEnvelopeType,
+		Timestamp: time.Now().UnixNano(),
+		Payload:   payload,
+	}
+	data, err := env.Marshal()
+	if err != nil {
+		return err
+	}
+	if err := conn.Connection.Send(ctx, data); err != nil {
+		return err
+	}
+	tr.sent.update(data)
+	tr.sinceCheckpoint = 0
+	return nil
+}
+
+// sendTranscriptCheckpoints checkpoints every connection that sent frames
+// since its last checkpoint, so quiet links are still compared.
+func (t *WebRTCTransport) sendTranscriptCheckpoints() {
+	t.connMu.RLock()
+	conns := make([]*PeerConnection, 0, len(t.connections))
+	for _, conn := range t.connections {
+		if conn.Connected && conn.Connection != nil {
+			conns = append(conns, conn)
+		}
+	}
+	t.connMu.RUnlock()
+
+	for _, conn := range conns {
+		tr := conn.transcriptState()
+		tr.sendMu.Lock()
+		if tr.sinceCheckpoint > 0 {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			if err := t.sendCheckpointLocked(ctx, conn, tr); err != nil {
+				t.logger.Debug("transcript checkpoint failed", "peer", getShortID(conn.PeerID), "error", err)
+			}
+			cancel()
+		}
+		tr.sendMu.Unlock()
+	}
+}
+
+// recordReceivedFrame folds an incoming frame into the receive transcript.
+// It runs before the frame is parsed so malformed frames count too.
+func (t *WebRTCTransport) recordReceivedFrame(peerID string, data []byte) {
+	t.connMu.RLock()
+	conn, ok := t.connections[peerID]
+	t.connMu.RUnlock()
+	if !ok {
+		return
+	}
+	tr := conn.transcriptState()
+	tr.recvMu.Lock()
+	tr.recv.update(data)
+	tr.recvMu.Unlock()
+}
+
+// verifyTranscriptCheckpoint compares a peer's checkpoint with what was
+// received. Checkpoints older than the kept history cannot be checked and
+// are ignored; a mismatch is reported and the connection rebuilt.
+func (t *WebRTCTransport) verifyTranscriptCheckpoint(peerID string, payload []byte) {
+	var cp transcriptCheckpoint
+	if err := json.Unmarshal(payload, &cp); err != nil {
+		t.logger.Debug("invalid transcript checkpoint", "peer", getShortID(peerID), "error", err)
+		return
+	}
+
+	t.connMu.RLock()
+	conn, ok := t.connections[peerID]
+	t.connMu.RUnlock()
+	if !ok {
+		return
+	}
+	tr := conn.transcriptState()
+
+	var local [sha256.Size]byte
+	tr.recvMu.Lock()
+	if cp.Frames == 0 {
+		local, ok = [sha256.Size]byte{}, true
+	} else {
+		local, ok = tr.recv.history[cp.Frames]
+	}
+	received := tr.recv.frames
+	tr.recvMu.Unlock()
+
+	if !ok {
+		if cp.Frames > received {
+			// The peer claims frames that never arrived
+			t.reportTranscriptMismatch(peerID, cp, "")
+		}
+		return
+	}
+	if localHex := hex.EncodeToString(local[:]); localHex != cp.Hash {
+		t.reportTranscriptMismatch(peerID, cp, localHex)
+	}
+}
+
+func (t *WebRTCTransport) transcriptMismatchCount() uint64 {
+	t.metricsMu.RLock()
+	defer t.metricsMu.RUnlock()
+	return t.transcriptMismatches
+}
+
+func (t *WebRTCTransport) reportTranscriptMismatch(peerID string, cp transcriptCheckpoint, local string) {
+	t.metricsMu.Lock()
+	t.transcriptMismatches++
+	t.metricsMu.Unlock()
+
+	event := TranscriptMismatch{
+		PeerID:     peerID,
+		Frames:     cp.Frames,
+		Local:      local,
+		Remote:     cp.Hash,
+		DetectedAt: time.Now(),
+	}
+	t.logger.Warn("connection transcript mismatch, reconnecting", "peer", getShortID(peerID), "frames", cp.Frames)
+
+	t.securityMu.RLock()
+	handler := t.securityHandler
+	t.securityMu.RUnlock()
+	if handler != nil {
+		handler(event)
+	}
+
+	// A fresh connection starts fresh transcripts on both ends
+	_ = t.Disconnect(peerID)
+	if t.started.Load() {
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), t.config.ConnectionTimeout)
+			defer cancel()
+			if err := t.Connect(ctx, peerID); err != nil {
+				t.logger.Debug("reconnect after transcript mismatch failed", "peer", getShortID(peerID), "error", err)
+			}
+		}()
+	}
+}
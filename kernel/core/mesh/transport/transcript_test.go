@@ -0,0 +1,71 @@
+//go:build !js || !wasm
+
+package transport
+
+import (
+	"context"
+	"testing"
+)
+
+// linkedTransports returns two transports connected to each other through
+// mock connections. Tests replay a's sent frames into b by hand.
+func linkedTransports(t *testing.T) (a, b *WebRTCTransport, aConn *MockConnection) {
+	t.Helper()
+	cfg := DefaultTransportConfig()
+	cfg.TranscriptCheckpointFrames = 0
+	a, _ = NewWebRTCTransport("node-a", cfg, nil)
+	b, _ = NewWebRTCTransport("node-b", cfg, nil)
+	aConn = NewMockConnection()
+	a.connections["node-b"] = &PeerConnection{PeerID: "node-b", Connection: aConn, Connected: true}
+	b.connections["node-a"] = &PeerConnection{PeerID: "node-a", Connection: NewMockConnection(), Connected: true}
+	return a, b, aConn
+}
+
+func TestTranscript_MatchingCheckpointPasses(t *testing.T) {
+	a, b, aConn := linkedTransports(t)
+	var events []TranscriptMismatch
+	b.SetSecurityEventHandler(func(e TranscriptMismatch) { events = append(events, e) })
+
+	for i := 0; i < 3; i++ {
+		if err := a.SendMessage(context.Background(), "node-b", map[string]interface{}{"n": i}); err != nil {
+			t.Fatalf("SendMessage failed: %v", err)
+		}
+	}
+	a.sendTranscriptCheckpoints()
+	for _, frame := range aConn.getSent() {
+		b.handleIncomingMessage("node-a", frame)
+	}
+
+	if len(events) != 0 {
+		t.Fatalf("expected no mismatch, got %+v", events)
+	}
+	if !b.IsConnected("node-a") {
+		t.Fatal("expected the connection to survive a matching checkpoint")
+	}
+}
+
+func TestTranscript_TamperedFrameTriggersSecurityEvent(t *testing.T) {
+	a, b, aConn := linkedTransports(t)
+	var events []TranscriptMismatch
+	b.SetSecurityEventHandler(func(e TranscriptMismatch) { events = append(events, e) })
+
+	for i := 0; i < 2; i++ {
+		_ = a.SendMessage(context.Background(), "node-b", map[string]interface{}{"n": i})
+	}
+	a.sendTranscriptCheckpoints()
+
+	frames := aConn.getSent()
+	// A relay drops the second frame and forwards the rest
+	b.handleIncomingMessage("node-a", frames[0])
+	b.handleIncomingMessage("node-a", frames[2])
+
+	if len(events) != 1 || events[0].PeerID != "node-a" || events[0].Frames != 2 {
+		t.Fatalf("expected one mismatch at frame 2, got %+v", events)
+	}
+	if b.IsConnected("node-a") {
+		t.Fatal("expected the tampered connection to be torn down")
+	}
+	if n := b.GetStats()["transcript_mismatches"].(uint64); n != 1 {
+		t.Fatalf("expected one recorded mismatch, got %d", n)
+	}
+}
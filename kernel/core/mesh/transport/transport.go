@@ -9,9 +9,7 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
-	"math"
 	"net/url"
-	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -42,6 +40,7 @@ type WebRTCTransport struct {
 	signalingURL    string
 	signaling       map[string]SignalingChannel
 	signalingLoops  map[string]struct{}
+	signalingRoutes map[string]string // peerID -> channel URL last heard from
 	signalingMu     sync.RWMutex
 	signalingStatus atomic.Value // "connected", "connecting", "disconnected"
 
@@ -50,17 +49,28 @@ type WebRTCTransport struct {
 
 	// Channels
 	rpcResponses map[string]chan RPCResponse
+	rpcBatches   map[string]chan rpcBatchResponse
 	rpcMu        sync.RWMutex
 	rpcHandlers  map[string]func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error)
 	handlerMu    sync.RWMutex
 
-	messageQueue chan QueuedMessage
-	shutdown     chan struct{}
+	// Per-peer outbound queues, bounded by sendQueueSize
+	sendQueues          map[string]*peerSendQueue
+	sendQueueSize       atomic.Int32
+	sendQueueMu         sync.Mutex
+	backpressureHandler func(peerID string, saturated bool)
+	messagesDropped     atomic.Uint64
+
+	shutdown chan struct{}
 
 	// Metrics
 	metrics   common.ConnectionMetrics
 	metricsMu sync.RWMutex
 
+	// RPC round trips, transport-wide and per peer (peerID -> *latencyRing)
+	rpcLatency  latencyRing
+	peerLatency sync.Map
+
 	// Health monitoring
 	health       common.TransportHealth
 	healthMu     sync.RWMutex
@@ -83,14 +93,53 @@ type WebRTCTransport struct {
 	connWaiters  map[string]chan struct{}
 	waiterMu     sync.Mutex
 	reconnecting atomic.Bool
+
+	// NAT traversal diagnostics
+	connectivity connectivityState
+
+	// Chaos testing; inert unless an injector is set
+	faults common.FaultHook
+
+	// Peers sharing our LAN segment
+	localNetwork localNetworkState
+
+	// Raw chunk bodies sent outside envelopes
+	chunkFrames chunkFrameState
+
+	// WebSocket bridge links to and from nodes that skip WebRTC
+	bridge bridgeState
+
+	// Circuits relayed for others and through others
+	relay relayState
+
+	// Hidden-page mode: keepalives slow down
+	inBackground atomic.Bool
+	background   backgroundState
+
+	// Connections riding out a network change
+	migration migrationState
+
+	// Encrypts relayed SDPs for their target (set by the mesh coordinator)
+	relaySealer   func(targetID string, sdp []byte) ([]byte, error)
+	relaySealerMu sync.RWMutex
+
+	// Transcript mismatches (counted under metricsMu) and who hears of them
+	transcriptMismatches uint64
+	securityHandler      func(TranscriptMismatch)
+	securityMu           sync.RWMutex
+
+	// Batched RPC frames sent and the calls they carried
+	rpcBatchesSent  atomic.Uint64
+	rpcBatchedCalls atomic.Uint64
 }
 
 // RPCRequest represents a remote procedure call
 type RPCRequest struct {
-	ID      string      `json:"id"`
-	Method  string      `json:"method"`
-	Params  interface{} `json:"params"`
-	Timeout int64       `json:"timeout"` // Milliseconds
+	ID       string              `json:"id"`
+	Method   string              `json:"method"`
+	Params   interface{}         `json:"params"`
+	Timeout  int64               `json:"timeout"` // Milliseconds
+	Metadata *common.RPCMetadata `json:"metadata,omitempty"`
 }
 
 // RPCResponse represents an RPC response
@@ -115,6 +164,8 @@ type PeerConnection struct {
 	LastContact time.Time
 	Latency     time.Duration
 	Connected   bool
+	transcript  *connectionTranscript
+	rpcSlots    chan struct{} // Incoming RPCs in progress, bounded by MaxPipelinedRPCs
 	mu          sync.RWMutex
 }
 
@@ -150,17 +201,51 @@ type TransportConfig struct {
 	WebSocketURL     string   `json:"websocket_url"`
 	SignalingServers []string `json:"signaling_servers"`
 
+	// BridgeListen, when set, accepts WebSocket bridge links on this
+	// address so browser kernels can dial the node directly. Native only.
+	BridgeListen string `json:"bridge_listen"`
+
+	// Relay lets well-connected nodes forward circuits for peers that
+	// cannot connect directly.
+	Relay RelayConfig `json:"relay"`
+
+	// BootstrapSignalingAlways keeps sending through WebSocket servers even when
+	// the mesh can carry signaling. By default they are only used for cold bootstrap.
+	BootstrapSignalingAlways bool `json:"bootstrap_signaling_always"`
+
 	// Connection settings
 	MaxConnections    int           `json:"max_connections"`
 	ConnectionTimeout time.Duration `json:"connection_timeout"`
 	ReconnectDelay    time.Duration `json:"reconnect_delay"`
 	KeepAliveInterval time.Duration `json:"keepalive_interval"`
 	MaxMessageSize    int           `json:"max_message_size"`
+	MessageQueueSize  int           `json:"message_queue_size"` // Per peer; see QueueMessage
+
+	// BackgroundKeepAliveInterval replaces KeepAliveInterval while the
+	// host page is hidden.
+	BackgroundKeepAliveInterval time.Duration `json:"background_keepalive_interval"`
+
+	// MigrationTimeout is how long a connection whose network path dropped
+	// is kept while an ICE restart looks for a new one. 0 tears such
+	// connections down straight away.
+	MigrationTimeout time.Duration `json:"migration_timeout"`
+
+	// TranscriptCheckpointFrames is how many frames a connection sends
+	// between transcript checkpoints; idle links are also checkpointed on
+	// every keep-alive. 0 leaves only the keep-alive checkpoints.
+	TranscriptCheckpointFrames int `json:"transcript_checkpoint_frames"`
 
 	// RPC settings
 	RPCTimeout time.Duration `json:"rpc_timeout"`
 	MaxRetries int           `json:"max_retries"`
 
+	// MaxBatchCalls is the most calls SendRPCBatch packs into one frame,
+	// and the most a received batch may carry.
+	MaxBatchCalls int `json:"max_batch_calls"`
+	// MaxPipelinedRPCs bounds the requests from one peer handled at once.
+	// 0 handles them one at a time on the receive loop.
+	MaxPipelinedRPCs int `json:"max_pipelined_rpcs"`
+
 	// Pool settings
 	PoolSize    int           `json:"pool_size"`
 	PoolMaxIdle time.Duration `json:"pool_max_idle"`
@@ -173,13 +258,14 @@ type TransportConfig struct {
 	BatchInterval time.Duration `json:"batch_interval"`
 }
 
-// QueuedMessage represents a message in the send queue
+// QueuedMessage represents a message in a peer's send queue
 type QueuedMessage struct {
-	PeerID  string
-	Message interface{}
-	Retries int
-	Context context.Context
-	Result  chan error
+	PeerID   string
+	Message  interface{}
+	Priority MessagePriority
+	Retries  int
+	Context  context.Context
+	Result   chan error
 }
 
 // ConnectionPool manages a pool of connections
@@ -217,10 +303,24 @@ func DefaultTransportConfig() TransportConfig {
 		ReconnectDelay:    5 * time.Second,
 		KeepAliveInterval: 30 * time.Second,
 		MaxMessageSize:    1024 * 1024 * 10, // 10MB
+		MessageQueueSize:  1000,
+
+		BackgroundKeepAliveInterval: 2 * time.Minute,
+		MigrationTimeout:            15 * time.Second,
+
+		TranscriptCheckpointFrames: 256,
 
 		RPCTimeout: 30 * time.Second,
 		MaxRetries: 3,
 
+		MaxBatchCalls:    64,
+		MaxPipelinedRPCs: 32,
+
+		Relay: RelayConfig{
+			MaxCircuits:      32,
+			CircuitBandwidth: 256 * 1024,
+		},
+
 		PoolSize:    50,
 		PoolMaxIdle: 5 * time.Minute,
 
@@ -241,21 +341,27 @@ func NewWebRTCTransport(nodeID string, config TransportConfig, logger *slog.Logg
 	if len(config.ICEServers) == 0 {
 		config.ICEServers = DefaultTransportConfig().ICEServers
 	}
+	if config.MessageQueueSize <= 0 {
+		config.MessageQueueSize = DefaultTransportConfig().MessageQueueSize
+	}
 
 	transport := &WebRTCTransport{
 		nodeID:          nodeID,
 		connections:     make(map[string]*PeerConnection),
 		peerConnections: make(map[string]*webrtc.PeerConnection),
 		rpcResponses:    make(map[string]chan RPCResponse),
+		rpcBatches:      make(map[string]chan rpcBatchResponse),
 		rpcHandlers:     make(map[string]func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error)),
-		messageQueue:    make(chan QueuedMessage, 1000),
+		sendQueues:      make(map[string]*peerSendQueue),
 		shutdown:        make(chan struct{}),
 		signaling:       make(map[string]SignalingChannel),
 		signalingLoops:  make(map[string]struct{}),
+		signalingRoutes: make(map[string]string),
 		config:          config,
 		logger:          logger.With("component", "transport", "node_id", getShortID(nodeID)),
 		startTime:       time.Now(),
 		connWaiters:     make(map[string]chan struct{}),
+		background:      backgroundState{reset: make(chan struct{}, 1)},
 	}
 
 	// Initialize WebRTC configuration
@@ -277,6 +383,7 @@ func NewWebRTCTransport(nodeID string, config TransportConfig, logger *slog.Logg
 	}
 
 	transport.signalingStatus.Store("disconnected")
+	transport.sendQueueSize.Store(int32(config.MessageQueueSize))
 
 	return transport, nil
 }
@@ -289,6 +396,12 @@ func (t *WebRTCTransport) Start(ctx context.Context) error {
 
 	t.logger.Info("starting transport", "signaling_servers", t.signalingServerList())
 
+	if t.config.BridgeListen != "" {
+		if err := t.ListenBridge(t.config.BridgeListen); err != nil {
+			return err
+		}
+	}
+
 	// Connect to signaling server (if any configured)
 	servers := t.signalingServerList()
 	if len(servers) == 0 {
@@ -299,7 +412,6 @@ func (t *WebRTCTransport) Start(ctx context.Context) error {
 	}
 
 	// Start background workers
-	go t.messageProcessor()
 	go t.connectionManager()
 	go t.healthMonitor()
 	go t.metricsCollector()
@@ -325,7 +437,7 @@ func (t *WebRTCTransport) ensureSignaling() error {
 	}
 	t.signalingMu.RUnlock()
 
-	if hasConnected {
+	if hasConnected || t.meshSignalingReady() {
 		return nil
 	}
 	t.logger.Debug("signaling not connected; dialing", "servers", t.signalingServerList())
@@ -470,6 +582,9 @@ func (t *WebRTCTransport) notifyPeerEvent(peerID string, connected bool) {
 	if handler != nil {
 		handler(peerID, connected)
 	}
+	if !connected {
+		t.dropRelayCircuits(peerID)
+	}
 }
 
 // Stop gracefully shuts down the transport
@@ -480,6 +595,8 @@ func (t *WebRTCTransport) Stop() error {
 
 	t.logger.Info("stopping transport")
 	close(t.shutdown)
+	t.stopLocalDiscovery()
+	t.stopBridgeListener()
 
 	// Close signaling connections
 	t.signalingMu.Lock()
@@ -504,6 +621,16 @@ func (t *WebRTCTransport) Stop() error {
 	}
 	t.connMu.Unlock()
 
+	t.sendQueueMu.Lock()
+	queued := make([]string, 0, len(t.sendQueues))
+	for peerID := range t.sendQueues {
+		queued = append(queued, peerID)
+	}
+	t.sendQueueMu.Unlock()
+	for _, peerID := range queued {
+		t.closeSendQueue(peerID)
+	}
+
 	// Close WebRTC connections
 	t.pcMu.Lock()
 	for peerID, pc := range t.peerConnections {
@@ -573,8 +700,8 @@ func (t *WebRTCTransport) Connect(ctx context.Context, peerID string) error {
 
 	t.logger.Debug("connecting to peer", "peer", getShortID(peerID))
 
-	// Try WebRTC first if enabled
-	if t.config.WebRTCEnabled {
+	// Try WebRTC first if enabled, unless the peer runs a bridge listener
+	if _, bridged := t.peerBridgeAddress(peerID); t.config.WebRTCEnabled && !bridged {
 		if err := t.connectViaWebRTC(ctx, peerID); err == nil {
 			t.logger.Debug("connected via WebRTC", "peer", getShortID(peerID))
 			t.metricsMu.Lock()
@@ -608,7 +735,7 @@ func (t *WebRTCTransport) connectViaWebRTC(ctx context.Context, peerID string) e
 		return errors.New("WebRTC not supported on this platform/browser")
 	}
 
-	config := t.webrtcConfig
+	config := t.webrtcConfigFor(peerID)
 	peerConnection, err := webrtc.NewPeerConnection(config)
 	if err != nil {
 		t.logger.Error("failed to create peer connection", "error", err)
@@ -628,6 +755,9 @@ func (t *WebRTCTransport) connectViaWebRTC(ctx context.Context, peerID string) e
 		peerConnection.Close()
 		return fmt.Errorf("failed to create data channel: %w", err)
 	}
+	dataChannel.OnMessage(func(msg webrtc.DataChannelMessage) {
+		t.handleIncomingMessage(peerID, msg.Data)
+	})
 
 	peerConnection.OnICECandidate(func(candidate *webrtc.ICECandidate) {
 		if candidate == nil {
@@ -657,6 +787,13 @@ func (t *WebRTCTransport) connectViaWebRTC(ctx context.Context, peerID string) e
 
 		switch state {
 		case webrtc.PeerConnectionStateConnected:
+			// An ICE restart brings the same connection back to connected;
+			// its data channel never closed, so there is nothing to rebuild.
+			if t.connectionUses(peerID, peerConnection) {
+				t.finishMigration(peerID)
+				return
+			}
+
 			// Connection established
 			conn := &WebRTCConnection{
 				peerID:   peerID,
@@ -676,6 +813,7 @@ func (t *WebRTCTransport) connectViaWebRTC(ctx context.Context, peerID string) e
 			t.connMu.Unlock()
 
 			t.logger.Info("WebRTC connection established", "peer", getShortID(peerID))
+			t.noteConnectionLocality(peerID, peerConnection)
 			t.notifyPeerEvent(peerID, true)
 
 			// Signal success if waiting
@@ -691,6 +829,12 @@ func (t *WebRTCTransport) connectViaWebRTC(ctx context.Context, peerID string) e
 			webrtc.PeerConnectionStateFailed,
 			webrtc.PeerConnectionStateClosed:
 
+			if state == webrtc.PeerConnectionStateDisconnected &&
+				t.connectionUses(peerID, peerConnection) && t.beginMigration(peerID) {
+				return
+			}
+			t.cancelMigration(peerID)
+
 			// Clean up
 			t.connMu.Lock()
 			delete(t.connections, peerID)
@@ -758,11 +902,26 @@ func (t *WebRTCTransport) Disconnect(peerID string) error {
 	}
 	t.pcMu.Unlock()
 
+	t.closeSendQueue(peerID)
+	t.peerLatency.Delete(peerID)
+	t.dropChunkFrames(peerID)
 	t.notifyPeerEvent(peerID, false)
 	t.logger.Debug("disconnected from peer", "peer", getShortID(peerID))
 	return nil
 }
 
+// linkClosed cleans up after a bridge or relayed link that ended, unless
+// the peer has since reconnected over another link.
+func (t *WebRTCTransport) linkClosed(peerID string, conn Connection) {
+	t.connMu.RLock()
+	current, ok := t.connections[peerID]
+	same := ok && current.Connection == conn
+	t.connMu.RUnlock()
+	if same {
+		_ = t.Disconnect(peerID)
+	}
+}
+
 // IsConnected checks if connected to a peer
 func (t *WebRTCTransport) IsConnected(peerID string) bool {
 	t.connMu.RLock()
@@ -806,11 +965,13 @@ func (t *WebRTCTransport) SendRPC(ctx context.Context, peerID string, method str
 	}
 
 	// Create RPC request
+	metadata := common.OutgoingRPCMetadata(ctx)
 	request := RPCRequest{
-		ID:      rpcID,
-		Method:  method,
-		Params:  args,
-		Timeout: t.config.RPCTimeout.Milliseconds(),
+		ID:       rpcID,
+		Method:   method,
+		Params:   args,
+		Timeout:  t.config.RPCTimeout.Milliseconds(),
+		Metadata: &metadata,
 	}
 
 	// Marshal request to JSON for the payload (temporary until full RPC schema)
@@ -837,12 +998,12 @@ func (t *WebRTCTransport) SendRPC(ctx context.Context, peerID string, method str
 	t.rpcResponses[rpcID] = responseChan
 	t.rpcMu.Unlock()
 
-	// Clean up response channel when done
+	// Clean up the response channel when done. It is left open: a response
+	// racing the cleanup must not land on a closed channel.
 	defer func() {
 		t.rpcMu.Lock()
 		delete(t.rpcResponses, rpcID)
 		t.rpcMu.Unlock()
-		close(responseChan)
 	}()
 
 	// Send the request
@@ -850,7 +1011,14 @@ func (t *WebRTCTransport) SendRPC(ctx context.Context, peerID string, method str
 	defer cancel()
 
 	if err := t.SendMessage(ctx, peerID, env); err != nil {
-		return fmt.Errorf("failed to send RPC request: %w", err)
+		// A request that hit a connection mid-migration goes out again
+		// once the restarted path is up.
+		if !t.awaitMigration(ctx, peerID) {
+			return fmt.Errorf("failed to send RPC request: %w", err)
+		}
+		if err := t.SendMessage(ctx, peerID, env); err != nil {
+			return fmt.Errorf("failed to send RPC request: %w", err)
+		}
 	}
 
 	// Wait for response
@@ -859,26 +1027,9 @@ func (t *WebRTCTransport) SendRPC(ctx context.Context, peerID string, method str
 		return ctx.Err()
 	case response := <-responseChan:
 		// Update metrics
-		latency := time.Since(start)
-		t.recordRPCLatency(latency)
-
-		if response.Error != nil {
-			return fmt.Errorf("RPC error: %s (code: %d)", response.Error.Message, response.Error.Code)
-		}
-
-		// Unmarshal response into reply
-		if reply != nil && response.Result != nil {
-			resultBytes, err := json.Marshal(response.Result)
-			if err != nil {
-				return fmt.Errorf("failed to marshal result: %w", err)
-			}
-
-			if err := json.Unmarshal(resultBytes, reply); err != nil {
-				return fmt.Errorf("failed to unmarshal response: %w", err)
-			}
-		}
+		t.recordRPCLatency(peerID, time.Since(start))
 
-		return nil
+		return decodeRPCResponse(response, reply)
 	}
 }
 
@@ -937,8 +1088,17 @@ func (t *WebRTCTransport) SendMessage(ctx context.Context, peerID string, messag
 		return fmt.Errorf("failed to marshal message: %w", err)
 	}
 
+	if fault := t.faults.Inject(common.FaultPointSend, peerID); fault != (common.Fault{}) {
+		if err := fault.Apply(ctx); err != nil {
+			return fmt.Errorf("failed to send message: %w", err)
+		}
+		if fault.Corrupt {
+			messageBytes = common.CorruptBytes(messageBytes)
+		}
+	}
+
 	// Send via connection
-	if err := conn.Connection.Send(ctx, messageBytes); err != nil {
+	if err := t.sendFrame(ctx, conn, messageBytes); err != nil {
 		// Mark as disconnected on send error
 		conn.mu.Lock()
 		conn.Connected = false
@@ -962,29 +1122,35 @@ func (t *WebRTCTransport) SendMessage(ctx context.Context, peerID string, messag
 	return nil
 }
 
-// Broadcast sends a message to all connected peers
+// Broadcast sends a message to all connected peers. It goes through each
+// peer's send queue at low priority, so a saturated peer misses it rather
+// than holding it up.
 func (t *WebRTCTransport) Broadcast(topic string, message interface{}) error {
 	peers := t.GetConnectedPeers()
 
 	var wg sync.WaitGroup
 	errs := make(chan error, len(peers))
 
+	broadcastMsg := map[string]interface{}{
+		"type":    "broadcast",
+		"topic":   topic,
+		"message": message,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), t.config.RPCTimeout)
+	defer cancel()
+
 	for _, peerID := range peers {
+		result := t.QueueMessage(ctx, peerID, broadcastMsg, PriorityLow)
 		wg.Add(1)
 		go func(pid string) {
 			defer wg.Done()
-
-			ctx, cancel := context.WithTimeout(context.Background(), t.config.RPCTimeout)
-			defer cancel()
-
-			broadcastMsg := map[string]interface{}{
-				"type":    "broadcast",
-				"topic":   topic,
-				"message": message,
-			}
-
-			if err := t.SendMessage(ctx, pid, broadcastMsg); err != nil {
-				errs <- fmt.Errorf("failed to broadcast to %s: %w", getShortID(pid), err)
+			select {
+			case err := <-result:
+				if err != nil {
+					errs <- fmt.Errorf("failed to broadcast to %s: %w", getShortID(pid), err)
+				}
+			case <-ctx.Done():
+				errs <- fmt.Errorf("failed to broadcast to %s: %w", getShortID(pid), ctx.Err())
 			}
 		}(peerID)
 	}
@@ -1128,6 +1294,11 @@ func (t *WebRTCTransport) GetStats() map[string]interface{} {
 	metrics := t.GetConnectionMetrics()
 	health := t.GetHealth()
 
+	var connectivity interface{}
+	if report := t.GetConnectivityReport(); report != nil {
+		connectivity = report
+	}
+
 	return map[string]interface{}{
 		"node_id":            t.nodeID,
 		"uptime":             t.startTime.Format(time.RFC3339),
@@ -1145,9 +1316,19 @@ func (t *WebRTCTransport) GetStats() map[string]interface{} {
 			"ice_servers":     len(t.config.ICEServers),
 			"max_connections": t.config.MaxConnections,
 		},
-		"signaling_status":  t.signalingStatus.Load(),
-		"message_queue_len": len(t.messageQueue),
-		"rpc_pending":       len(t.rpcResponses),
+		"signaling_status":      t.signalingStatus.Load(),
+		"signaling_mode":        t.SignalingMode(),
+		"local_peers":           len(t.LocalPeers()),
+		"message_queue_len":     t.queuedMessageCount(),
+		"messages_dropped":      t.messagesDropped.Load(),
+		"rpc_pending":           len(t.rpcResponses),
+		"peer_latency":          t.peerLatencyStats(),
+		"rpc_batches_sent":      t.rpcBatchesSent.Load(),
+		"rpc_batched_calls":     t.rpcBatchedCalls.Load(),
+		"transcript_mismatches": t.transcriptMismatchCount(),
+		"connectivity":          connectivity,
+		"migration":             t.GetMigrationStats(),
+		"relay":                 t.RelayStats(),
 	}
 }
 
@@ -1303,6 +1484,17 @@ func (t *WebRTCTransport) reconnectSignaling() {
 		case <-t.shutdown:
 			return
 		default:
+			// Peers already relay signaling over gossip; the bootstrap servers are
+			// not needed until the mesh drains.
+			if t.meshSignalingReady() {
+				select {
+				case <-t.shutdown:
+					return
+				case <-time.After(t.applyJitter(maxBackoff)):
+				}
+				continue
+			}
+
 			attempts++
 			if err := t.connectSignaling(); err == nil {
 				return // Successfully reconnected
@@ -1348,13 +1540,8 @@ func (t *WebRTCTransport) applyJitter(d time.Duration) time.Duration {
 
 // sendSignalingMessage sends a message to signaling server
 func (t *WebRTCTransport) sendSignalingMessage(message interface{}) error {
-	t.signalingMu.RLock()
-	// Copy channels to avoid holding lock during network I/O
-	channels := make([]SignalingChannel, 0, len(t.signaling))
-	for _, s := range t.signaling {
-		channels = append(channels, s)
-	}
-	t.signalingMu.RUnlock()
+	// Channels are copied so the lock is not held during network I/O
+	channels, all := t.selectSignalingChannels(message)
 
 	if len(channels) == 0 {
 		t.logger.Warn("no active signaling channels", "servers", t.signalingServerList())
@@ -1376,6 +1563,22 @@ func (t *WebRTCTransport) sendSignalingMessage(message interface{}) error {
 		}
 	}
 
+	if sentCount == 0 && len(channels) < len(all) {
+		// Preferred route failed; fall back to the remaining channels.
+		tried := make(map[SignalingChannel]struct{}, len(channels))
+		for _, s := range channels {
+			tried[s] = struct{}{}
+		}
+		for _, s := range all {
+			if _, ok := tried[s]; ok {
+				continue
+			}
+			if err := s.Send(message); err == nil {
+				return nil
+			}
+		}
+	}
+
 	if sentCount == 0 && firstErr != nil {
 		return firstErr
 	}
@@ -1393,6 +1596,11 @@ func (t *WebRTCTransport) receiveSignalingMessages(s SignalingChannel, url strin
 			delete(t.signaling, url)
 		}
 		delete(t.signalingLoops, url)
+		for peerID, route := range t.signalingRoutes {
+			if route == url {
+				delete(t.signalingRoutes, peerID)
+			}
+		}
 
 		if len(t.signaling) == 0 {
 			t.signalingStatus.Store("disconnected")
@@ -1429,6 +1637,7 @@ func (t *WebRTCTransport) receiveSignalingMessages(s SignalingChannel, url strin
 			}
 
 			t.logger.Debug("received signaling data", "server", url, "len", len(message))
+			t.recordSignalingRoute(url, message)
 			t.handleSignalingMessage(message)
 		}
 	}
@@ -1449,7 +1658,7 @@ func (t *WebRTCTransport) handleSignalingMessage(message []byte) {
 
 	msgType, _ := msg["type"].(string)
 	switch msgType {
-	case "webrtc_offer", "webrtc_answer", "ice_candidate", "peer_discovery", "ping", "pong":
+	case "webrtc_offer", "webrtc_answer", "ice_candidate", "ice_restart", "peer_discovery", "ping", "pong":
 	default:
 		t.logger.Debug("ignoring unknown signaling message type", "type", msgType)
 		return
@@ -1462,7 +1671,7 @@ func (t *WebRTCTransport) handleSignalingMessage(message []byte) {
 	}
 
 	switch msgType {
-	case "webrtc_offer", "webrtc_answer", "ice_candidate":
+	case "webrtc_offer", "webrtc_answer", "ice_candidate", "ice_restart":
 		if targetID == "" {
 			t.logger.Debug("dropping signaling message with missing target_id", "type", msgType, "from", getShortID(senderID))
 			return
@@ -1486,6 +1695,10 @@ func (t *WebRTCTransport) handleSignalingMessage(message []byte) {
 		t.handleWebRTCAnswer(senderID, msg)
 	case "ice_candidate":
 		t.handleICECandidate(senderID, msg)
+	case "ice_restart":
+		if err := t.restartICE(senderID); err != nil {
+			t.logger.Debug("requested ICE restart failed", "peer", getShortID(senderID), "error", err)
+		}
 	case "peer_discovery":
 		t.handlePeerDiscovery(msg)
 	case "ping":
@@ -1514,9 +1727,14 @@ func (t *WebRTCTransport) handleWebRTCOffer(senderID string, msg map[string]inte
 		return
 	}
 
+	if restart, _ := msg["ice_restart"].(bool); restart {
+		t.handleICERestartOffer(senderID, offer)
+		return
+	}
+
 	// Create peer connection
 	t.logger.Info("handling WebRTC offer", "from", getShortID(senderID))
-	peerConnection, err := webrtc.NewPeerConnection(t.webrtcConfig)
+	peerConnection, err := webrtc.NewPeerConnection(t.webrtcConfigFor(senderID))
 	if err != nil {
 		t.logger.Error("failed to create peer connection for offer", "error", err)
 		return
@@ -1546,6 +1764,21 @@ func (t *WebRTCTransport) handleWebRTCOffer(senderID string, msg map[string]inte
 		}
 	})
 
+	// Hold the connection through a lost path instead of waiting out the
+	// keep-alive; the offering side owns teardown otherwise.
+	peerConnection.OnConnectionStateChange(func(state webrtc.PeerConnectionState) {
+		switch state {
+		case webrtc.PeerConnectionStateConnected:
+			t.finishMigration(senderID)
+		case webrtc.PeerConnectionStateDisconnected:
+			if t.connectionUses(senderID, peerConnection) {
+				t.beginMigration(senderID)
+			}
+		case webrtc.PeerConnectionStateFailed, webrtc.PeerConnectionStateClosed:
+			t.cancelMigration(senderID)
+		}
+	})
+
 	// Set up data channel
 	peerConnection.OnDataChannel(func(dc *webrtc.DataChannel) {
 		dc.OnOpen(func() {
@@ -1565,6 +1798,7 @@ func (t *WebRTCTransport) handleWebRTCOffer(senderID string, msg map[string]inte
 				LastContact: time.Now(),
 			}
 			t.connMu.Unlock()
+			t.noteConnectionLocality(senderID, peerConnection)
 			t.notifyPeerEvent(senderID, true)
 
 			go conn.receiveLoop()
@@ -1742,6 +1976,8 @@ func (t *WebRTCTransport) handlePeerDiscovery(msg map[string]interface{}) {
 
 // handleIncomingMessage processes incoming messages from peers
 func (t *WebRTCTransport) handleIncomingMessage(peerID string, data []byte) {
+	t.recordReceivedFrame(peerID, data)
+
 	if t.config.MaxMessageSize > 0 && len(data) > t.config.MaxMessageSize {
 		t.metricsMu.Lock()
 		t.metrics.FailedMessages++
@@ -1754,6 +1990,10 @@ func (t *WebRTCTransport) handleIncomingMessage(peerID string, data []byte) {
 	t.recordMessageReceived(len(data))
 	t.touchPeer(peerID)
 
+	if t.handleChunkFrame(peerID, data) {
+		return
+	}
+
 	// Parse Envelope
 	env := &common.Envelope{}
 	if err := env.Unmarshal(data); err != nil {
@@ -1771,16 +2011,31 @@ func (t *WebRTCTransport) handleIncomingMessage(peerID string, data []byte) {
 		if exists {
 			var response RPCResponse
 			if err := json.Unmarshal(env.Payload, &response); err == nil {
-				responseChan <- response
+				select {
+				case responseChan <- response:
+				default: // Duplicate response; the caller already has one
+				}
 			}
 		}
 		return
 	}
+	if env.Type == rpcBatchResponseEnvelopeType {
+		t.deliverRPCBatchResponse(env.ID, env.Payload)
+		return
+	}
+
+	if t.handleRelayEnvelope(peerID, env) {
+		return
+	}
 
 	// Handle other message types based on Envelope Type
 	switch env.Type {
 	case "rpc_request":
-		t.handleRPCRequest(peerID, env.Payload)
+		t.dispatchRPC(peerID, func() { t.handleRPCRequest(peerID, env.Payload) })
+	case rpcBatchEnvelopeType:
+		t.dispatchRPC(peerID, func() { t.handleRPCBatch(peerID, env.ID, env.Payload) })
+	case transcriptEnvelopeType:
+		t.verifyTranscriptCheckpoint(peerID, env.Payload)
 	case "json_payload":
 		t.handleJSONPayload(peerID, env.Payload)
 	case "ping":
@@ -1842,6 +2097,18 @@ func (t *WebRTCTransport) handleRPCRequest(peerID string, data []byte) {
 		return
 	}
 
+	response := t.serveRPC(peerID, request)
+	payload, _ := json.Marshal(response)
+	t.SendMessage(context.Background(), peerID, &common.Envelope{
+		ID:        request.ID,
+		Type:      "rpc_response",
+		Timestamp: time.Now().UnixNano(),
+		Payload:   payload,
+	})
+}
+
+// serveRPC runs the handler for one request and builds its response.
+func (t *WebRTCTransport) serveRPC(peerID string, request RPCRequest) RPCResponse {
 	t.handlerMu.RLock()
 	handler, exists := t.rpcHandlers[request.Method]
 	t.handlerMu.RUnlock()
@@ -1854,10 +2121,24 @@ func (t *WebRTCTransport) handleRPCRequest(peerID string, data []byte) {
 	} else {
 		// Convert params to RawMessage for the handler
 		paramsBytes, _ := json.Marshal(request.Params)
-		result, err = handler(context.Background(), peerID, json.RawMessage(paramsBytes))
+		ctx, cancel := common.IncomingRPCContext(context.Background(), request.Metadata, time.Duration(request.Timeout)*time.Millisecond)
+		fault := t.faults.Inject(common.FaultPointRPCHandle, request.Method)
+		if err = fault.Apply(ctx); err == nil {
+			if fault.Corrupt {
+				paramsBytes = common.CorruptBytes(paramsBytes)
+			}
+			result, err = handler(ctx, peerID, json.RawMessage(paramsBytes))
+		}
+		cancel()
+		if err != nil {
+			t.logger.Debug("RPC handler failed",
+				"method", request.Method,
+				"peer", getShortID(peerID),
+				"trace_id", common.TraceIDFromContext(ctx),
+				"error", err)
+		}
 	}
 
-	// Send response
 	response := RPCResponse{
 		ID:     request.ID,
 		Result: result,
@@ -1868,14 +2149,7 @@ func (t *WebRTCTransport) handleRPCRequest(peerID string, data []byte) {
 			Message: err.Error(),
 		}
 	}
-
-	payload, _ := json.Marshal(response)
-	t.SendMessage(context.Background(), peerID, &common.Envelope{
-		ID:        request.ID,
-		Type:      "rpc_response",
-		Timestamp: time.Now().UnixNano(),
-		Payload:   payload,
-	})
+	return response
 }
 
 // getPeerWebSocketURL returns WebSocket URL for a peer
@@ -1902,33 +2176,6 @@ func (t *WebRTCTransport) getPeerWebSocketURL(peerID string) (string, error) {
 	return u.String(), nil
 }
 
-// messageProcessor handles queued messages
-func (t *WebRTCTransport) messageProcessor() {
-	for {
-		select {
-		case <-t.shutdown:
-			return
-		case queued := <-t.messageQueue:
-			// Try to send the message
-			err := t.SendMessage(queued.Context, queued.PeerID, queued.Message)
-			if err != nil && queued.Retries < t.config.MaxRetries {
-				// Retry with backoff
-				queued.Retries++
-				go func(q QueuedMessage) {
-					backoff := time.Duration(math.Pow(2, float64(q.Retries))) * 100 * time.Millisecond
-					time.Sleep(backoff)
-					t.messageQueue <- q
-				}(queued)
-			}
-
-			// Notify sender
-			if queued.Result != nil {
-				queued.Result <- err
-			}
-		}
-	}
-}
-
 // RegisterRPCHandler registers a handler for an RPC method
 func (t *WebRTCTransport) RegisterRPCHandler(method string, handler func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error)) {
 	t.handlerMu.Lock()
@@ -1938,7 +2185,7 @@ func (t *WebRTCTransport) RegisterRPCHandler(method string, handler func(ctx con
 
 // connectionManager manages connection lifecycle
 func (t *WebRTCTransport) connectionManager() {
-	keepAliveTicker := time.NewTicker(t.config.KeepAliveInterval)
+	keepAliveTicker := time.NewTicker(t.keepAliveInterval())
 	defer keepAliveTicker.Stop()
 
 	cleanupTicker := time.NewTicker(1 * time.Minute)
@@ -1950,6 +2197,9 @@ func (t *WebRTCTransport) connectionManager() {
 			return
 		case <-keepAliveTicker.C:
 			t.sendKeepAlives()
+			t.sendTranscriptCheckpoints()
+		case <-t.background.reset:
+			keepAliveTicker.Reset(t.keepAliveInterval())
 		case <-cleanupTicker.C:
 			t.cleanupStaleConnections()
 		}
@@ -1979,7 +2229,7 @@ func (t *WebRTCTransport) sendKeepAlives() {
 
 // cleanupStaleConnections removes stale connections
 func (t *WebRTCTransport) cleanupStaleConnections() {
-	staleThreshold := 2 * t.config.KeepAliveInterval
+	staleThreshold := 2 * t.keepAliveInterval()
 	if staleThreshold <= 0 {
 		staleThreshold = 1 * time.Minute
 	}
@@ -1996,7 +2246,7 @@ func (t *WebRTCTransport) cleanupStaleConnections() {
 
 		age := now.Sub(conn.LastContact)
 		if conn.Connected {
-			if age <= staleThreshold {
+			if age <= staleThreshold || t.isMigrating(peerID) {
 				continue
 			}
 
@@ -2081,9 +2331,6 @@ func (t *WebRTCTransport) metricsCollector() {
 	ticker := time.NewTicker(t.config.MetricsInterval)
 	defer ticker.Stop()
 
-	// Store latency samples for percentile calculation
-	latencySamples := make([]float64, 0, 1000)
-
 	for {
 		select {
 		case <-t.shutdown:
@@ -2094,24 +2341,9 @@ func (t *WebRTCTransport) metricsCollector() {
 
 			t.metricsMu.Lock()
 			t.metrics.ActiveConnections = uint32(connected)
-
-			// Calculate latency percentiles if we have samples
-			if len(latencySamples) > 0 {
-				sort.Float64s(latencySamples)
-				p50Idx := int(float64(len(latencySamples)) * 0.5)
-				p95Idx := int(float64(len(latencySamples)) * 0.95)
-
-				if p50Idx < len(latencySamples) {
-					t.metrics.LatencyP50 = float32(latencySamples[p50Idx])
-				}
-				if p95Idx < len(latencySamples) {
-					t.metrics.LatencyP95 = float32(latencySamples[p95Idx])
-				}
-
-				// Reset samples for next interval
-				latencySamples = make([]float64, 0, 1000)
-			}
 			t.metricsMu.Unlock()
+
+			t.updateLatencyPercentiles()
 		}
 	}
 }
@@ -2132,23 +2364,6 @@ func (t *WebRTCTransport) recordMessageReceived(bytes int) {
 	t.metricsMu.Unlock()
 }
 
-// recordRPCLatency records RPC latency for percentile calculation
-// recordRPCLatency records RPC latency for percentile calculation
-func (t *WebRTCTransport) recordRPCLatency(_ time.Duration) {
-	// t.metricsMu.Lock()
-	// t.metrics.SuccessRate++ // Removed: SuccessRate is calculated from MessagesSent and FailedMessages
-	// t.metricsMu.Unlock()
-
-	// Store latency sample (implement circular buffer in production)
-	// latencyMs := float64(latency.Milliseconds())
-
-	// In production, use a proper circular buffer
-	// For simplicity, we'll just add to slice and let metrics collector handle it
-	go func() {
-		// This would add to a thread-safe buffer in production
-	}()
-}
-
 // updatePeerLatency updates latency for a peer
 func (t *WebRTCTransport) updatePeerLatency(peerID string, latency time.Duration) {
 	t.connMu.Lock()
@@ -2384,6 +2599,11 @@ func (t *WebRTCTransport) Store(ctx context.Context, peerID string, key string,
 	return t.SendRPC(ctx, peerID, "store", map[string]interface{}{"key": key, "value": value}, nil)
 }
 
+// StoreWithTTL is Store with the record's lifetime in seconds.
+func (t *WebRTCTransport) StoreWithTTL(ctx context.Context, peerID string, key string, value []byte, ttlSeconds int64) error {
+	return t.SendRPC(ctx, peerID, "store", map[string]interface{}{"key": key, "value": value, "ttl": ttlSeconds}, nil)
+}
+
 func (t *WebRTCTransport) Ping(ctx context.Context, peerID string) error {
 	return t.SendRPC(ctx, peerID, "ping", nil, nil)
 }
@@ -2416,6 +2636,15 @@ func (t *WebRTCTransport) ApplyRoleConfig(config runtime.RoleConfig) {
 		t.connectionPool.maxSize = config.MaxPeers
 	}
 
+	// Per-peer send queues pick up the new bound on their next message
+	if size := config.Memory.TransportQueueSize; size > 0 {
+		t.config.MessageQueueSize = size
+		t.sendQueueSize.Store(int32(size))
+	}
+
+	// Relay roles forward circuits for peers behind restrictive NATs
+	t.setRelayEnabled(config.CanRelay)
+
 	// Update local capability cache (to be broadcasted)
 	if t.localCapability != nil {
 		t.localCapability.Role = config.Role
@@ -2426,6 +2655,19 @@ func (t *WebRTCTransport) ApplyRoleConfig(config runtime.RoleConfig) {
 
 // BroadcastSDPRelay broadcasts an SDP offer/answer via gossip when no signaling server is available
 func (t *WebRTCTransport) BroadcastSDPRelay(targetID string, sessionID string, sdp []byte, maxHops uint8) error {
+	t.relaySealerMu.RLock()
+	seal := t.relaySealer
+	t.relaySealerMu.RUnlock()
+	if seal != nil {
+		// Relaying peers must not read the SDP; without the target's key it
+		// can only go out in the clear.
+		if sealed, err := seal(targetID, sdp); err == nil {
+			sdp = sealed
+		} else {
+			t.logger.Debug("sending unsealed SDP relay", "target", getShortID(targetID), "error", err)
+		}
+	}
+
 	relay := map[string]interface{}{
 		"originator_id": t.nodeID,
 		"target_id":     targetID,
@@ -2443,6 +2685,14 @@ func (t *WebRTCTransport) BroadcastSDPRelay(targetID string, sessionID string, s
 	return t.Broadcast("sdp.relay", relay)
 }
 
+// SetRelaySealer installs the function used to encrypt SDPs relayed through
+// gossip.
+func (t *WebRTCTransport) SetRelaySealer(seal func(targetID string, sdp []byte) ([]byte, error)) {
+	t.relaySealerMu.Lock()
+	t.relaySealer = seal
+	t.relaySealerMu.Unlock()
+}
+
 // BroadcastSDPNotify sends a lightweight notification that SDP is available (stored in DHT)
 func (t *WebRTCTransport) BroadcastSDPNotify(targetID string, sessionID string) error {
 	nonce := make([]byte, 8)
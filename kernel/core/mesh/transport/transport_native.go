@@ -6,6 +6,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -18,6 +19,12 @@ func (t *WebRTCTransport) isWebRTCSupported() bool {
 
 // connectViaWebSocket establishes a WebSocket connection as fallback
 func (t *WebRTCTransport) connectViaWebSocket(ctx context.Context, peerID string) error {
+	// Peers with a bridge listener are dialed directly
+	if addr, ok := t.peerBridgeAddress(peerID); ok {
+		_, err := t.dialBridge(ctx, strings.TrimPrefix(addr, bridgeSchemePrefix), peerID)
+		return err
+	}
+
 	// Check if we have a direct WebSocket URL for the peer
 	wsURL, err := t.getPeerWebSocketURL(peerID)
 	if err != nil {
@@ -25,24 +32,13 @@ func (t *WebRTCTransport) connectViaWebSocket(ctx context.Context, peerID string
 	}
 
 	// Connect to peer's WebSocket
-	dialer := websocket.Dialer{
-		HandshakeTimeout: t.config.ConnectionTimeout,
-		ReadBufferSize:   t.config.MaxMessageSize,
-		WriteBufferSize:  t.config.MaxMessageSize,
-	}
-
-	conn, _, err := dialer.DialContext(ctx, wsURL, nil)
+	conn, err := t.dialWebSocket(ctx, wsURL)
 	if err != nil {
-		return fmt.Errorf("failed to dial WebSocket: %w", err)
+		return err
 	}
 
 	// Create WebSocket connection wrapper
-	wsConn := &WebSocketConnection{
-		peerID:   peerID,
-		conn:     conn,
-		stats:    ConnectionStats{OpenedAt: time.Now()},
-		shutdown: make(chan struct{}),
-	}
+	wsConn := newWebSocketConnection(peerID, conn)
 
 	// Store connection
 	t.connMu.Lock()
@@ -61,6 +57,20 @@ func (t *WebRTCTransport) connectViaWebSocket(ctx context.Context, peerID string
 	return nil
 }
 
+func (t *WebRTCTransport) dialWebSocket(ctx context.Context, wsURL string) (*websocket.Conn, error) {
+	dialer := websocket.Dialer{
+		HandshakeTimeout: t.config.ConnectionTimeout,
+		ReadBufferSize:   t.config.MaxMessageSize,
+		WriteBufferSize:  t.config.MaxMessageSize,
+	}
+
+	conn, _, err := dialer.DialContext(ctx, wsURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial WebSocket: %w", err)
+	}
+	return conn, nil
+}
+
 // WebSocketConnection implements Connection for WebSocket
 type WebSocketConnection struct {
 	peerID   string
@@ -70,6 +80,15 @@ type WebSocketConnection struct {
 	mu       sync.RWMutex
 }
 
+func newWebSocketConnection(peerID string, conn *websocket.Conn) *WebSocketConnection {
+	return &WebSocketConnection{
+		peerID:   peerID,
+		conn:     conn,
+		stats:    ConnectionStats{OpenedAt: time.Now()},
+		shutdown: make(chan struct{}),
+	}
+}
+
 func (c *WebSocketConnection) Send(ctx context.Context, data []byte) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -78,7 +97,7 @@ func (c *WebSocketConnection) Send(ctx context.Context, data []byte) error {
 		return errors.New("connection not open")
 	}
 
-	if err := c.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+	if err := c.conn.WriteMessage(websocket.BinaryMessage, data); err != nil {
 		c.stats.LastError = err.Error()
 		return err
 	}
@@ -122,6 +141,11 @@ func (c *WebSocketConnection) IsOpen() bool {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
+	select {
+	case <-c.shutdown:
+		return false
+	default:
+	}
 	return c.conn != nil
 }
 
@@ -917,18 +917,12 @@ func TestWebRTCTransport_MessageRetries(t *testing.T) {
 	tr.connections["p2"] = &PeerConnection{PeerID: "p2", Connected: true}
 	tr.connMu.Unlock()
 
-	result := make(chan error, 1)
-	tr.messageQueue <- QueuedMessage{
-		Context: context.Background(),
-		PeerID:  "p2",
-		Message: "test",
-		Result:  result,
-	}
+	result := tr.QueueMessage(context.Background(), "p2", "test", PriorityNormal)
 
 	// Wait for retry
 	err := <-result
 	if err == nil {
-		t.Error("Expected error from message processor")
+		t.Error("Expected error from the peer's send queue")
 	}
 }
 
@@ -1425,3 +1419,17 @@ func TestWebSocketConnection_SendError(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "not open")
 }
+
+func TestWebRTCTransport_BackgroundKeepAlive(t *testing.T) {
+	config := DefaultTransportConfig()
+	config.KeepAliveInterval = 30 * time.Second
+	config.BackgroundKeepAliveInterval = 2 * time.Minute
+	tr, err := NewWebRTCTransport("node-bg", config, nil)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 30*time.Second, tr.keepAliveInterval())
+	tr.SetBackground(true)
+	assert.Equal(t, 2*time.Minute, tr.keepAliveInterval())
+	tr.SetBackground(false)
+	assert.Equal(t, 30*time.Second, tr.keepAliveInterval())
+}
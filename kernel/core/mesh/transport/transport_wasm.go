@@ -5,6 +5,7 @@ package transport
 import (
 	"context"
 	"errors"
+	"strings"
 	"sync"
 	"syscall/js"
 	"time"
@@ -17,12 +18,92 @@ func (t *WebRTCTransport) isWebRTCSupported() bool {
 }
 
 func (t *WebRTCTransport) connectViaWebSocket(ctx context.Context, peerID string) error {
+	// Peers with a bridge listener are dialed directly
+	if addr, ok := t.peerBridgeAddress(peerID); ok {
+		_, err := t.dialBridge(ctx, strings.TrimPrefix(addr, bridgeSchemePrefix), peerID)
+		return err
+	}
+
 	wsURL, err := t.getPeerWebSocketURL(peerID)
 	if err != nil {
 		return err
 	}
 
+	conn, err := t.openWebSocket(ctx, peerID, wsURL)
+	if err != nil {
+		return err
+	}
+
+	// Store connection
+	t.connMu.Lock()
+	t.connections[peerID] = &PeerConnection{
+		PeerID:      peerID,
+		Connection:  conn,
+		Connected:   true,
+		LastContact: time.Now(),
+	}
+	t.connMu.Unlock()
+	t.notifyPeerEvent(peerID, true)
+
+	// Start receiving messages
+	go conn.receiveLoop(t.handleIncomingMessage)
+
+	return nil
+}
+
+// ListenBridge always fails with ErrBridgeListenUnsupported.
+func (t *WebRTCTransport) ListenBridge(addr string) error {
+	return ErrBridgeListenUnsupported
+}
+
+// dialBridge opens a bridge link to wsURL. expected, when set, is the peer
+// the link must reach.
+func (t *WebRTCTransport) dialBridge(ctx context.Context, wsURL, expected string) (string, error) {
+	conn, err := t.openWebSocket(ctx, expected, wsURL)
+	if err != nil {
+		return "", err
+	}
+	peerID, err := t.bridgeHandshake(ctx, conn, expected)
+	if err == nil {
+		conn.peerID = peerID
+		err = t.addBridgeConnection(peerID, conn)
+	}
+	if err != nil {
+		conn.Close()
+		return "", err
+	}
+	go func() {
+		conn.receiveLoop(t.handleIncomingMessage)
+		t.linkClosed(peerID, conn)
+	}()
+	return peerID, nil
+}
+
+func (t *WebRTCTransport) bridgeHandshake(ctx context.Context, conn *WebSocketConnection, expected string) (string, error) {
+	hello, err := t.bridgeHelloFrame()
+	if err != nil {
+		return "", err
+	}
+	if err := conn.Send(ctx, hello); err != nil {
+		return "", err
+	}
+	select {
+	case data := <-conn.messages:
+		return t.checkBridgeHello(data, expected)
+	case <-conn.shutdown:
+		return "", errors.New("bridge closed before hello")
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case <-time.After(t.config.ConnectionTimeout):
+		return "", errors.New("timeout waiting for bridge hello")
+	}
+}
+
+// openWebSocket dials wsURL and waits for it to open. Frames are binary
+// envelopes; text frames from older peers are still accepted.
+func (t *WebRTCTransport) openWebSocket(ctx context.Context, peerID, wsURL string) (*WebSocketConnection, error) {
 	ws := js.Global().Get("WebSocket").New(wsURL)
+	ws.Set("binaryType", "arraybuffer")
 	conn := &WebSocketConnection{
 		peerID:   peerID,
 		ws:       ws,
@@ -31,14 +112,21 @@ func (t *WebRTCTransport) connectViaWebSocket(ctx context.Context, peerID string
 		stats:    ConnectionStats{OpenedAt: time.Now()},
 	}
 
-	opened := make(chan bool)
+	opened := make(chan bool, 1)
 	ws.Set("onopen", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
 		opened <- true
 		return nil
 	}))
 	ws.Set("onmessage", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
-		data := args[0].Get("data").String()
-		conn.messages <- []byte(data)
+		data := args[0].Get("data")
+		if data.Type() == js.TypeString {
+			conn.messages <- []byte(data.String())
+			return nil
+		}
+		bytes := js.Global().Get("Uint8Array").New(data)
+		msg := make([]byte, bytes.Get("length").Int())
+		js.CopyBytesToGo(msg, bytes)
+		conn.messages <- msg
 		return nil
 	}))
 	ws.Set("onclose", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
@@ -48,30 +136,14 @@ func (t *WebRTCTransport) connectViaWebSocket(ctx context.Context, peerID string
 
 	select {
 	case <-opened:
-		// success
+		return conn, nil
 	case <-ctx.Done():
 		ws.Call("close")
-		return ctx.Err()
+		return nil, ctx.Err()
 	case <-time.After(t.config.ConnectionTimeout):
 		ws.Call("close")
-		return errors.New("timeout connecting to websocket")
-	}
-
-	// Store connection
-	t.connMu.Lock()
-	t.connections[peerID] = &PeerConnection{
-		PeerID:      peerID,
-		Connection:  conn,
-		Connected:   true,
-		LastContact: time.Now(),
+		return nil, errors.New("timeout connecting to websocket")
 	}
-	t.connMu.Unlock()
-	t.notifyPeerEvent(peerID, true)
-
-	// Start receiving messages
-	go conn.receiveLoop(t.handleIncomingMessage)
-
-	return nil
 }
 
 type WebSocketConnection struct {
@@ -84,7 +156,9 @@ type WebSocketConnection struct {
 }
 
 func (c *WebSocketConnection) Send(ctx context.Context, data []byte) error {
-	c.ws.Call("send", string(data))
+	bytes := js.Global().Get("Uint8Array").New(len(data))
+	js.CopyBytesToJS(bytes, data)
+	c.ws.Call("send", bytes)
 	c.mu.Lock()
 	c.stats.BytesSent += uint64(len(data))
 	c.stats.MessagesSent++
@@ -8,6 +8,8 @@ import (
 
 // Re-export common types for convenience within the mesh package
 type PeerCapability = common.PeerCapability
+type GPUCapability = common.GPUCapability
+type HardwareDescriptor = common.HardwareDescriptor
 type GeoCoordinates = common.GeoCoordinates
 type Envelope = common.Envelope
 type EnvelopeMetadata = common.EnvelopeMetadata
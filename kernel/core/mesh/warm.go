@@ -0,0 +1,295 @@
+package mesh
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// WarmPriority sets how much of the warm bandwidth budget a manifest gets.
+type WarmPriority int
+
+const (
+	WarmPriorityLow WarmPriority = iota
+	WarmPriorityNormal
+	WarmPriorityHigh
+)
+
+// Warm job states.
+const (
+	WarmStateWarming   = "warming"
+	WarmStateReady     = "ready"
+	WarmStateFailed    = "failed"
+	WarmStateCancelled = "cancelled"
+)
+
+// budgetShare is the fraction of Warm.BandwidthBytesPerSec a job may use.
+func (p WarmPriority) budgetShare() float64 {
+	switch p {
+	case WarmPriorityHigh:
+		return 1
+	case WarmPriorityLow:
+		return 0.25
+	default:
+		return 0.5
+	}
+}
+
+// WarmStatus reports the progress of one WarmManifest call.
+type WarmStatus struct {
+	ManifestHash string       `json:"manifest_hash"`
+	Priority     WarmPriority `json:"priority"`
+	State        string       `json:"state"`
+	Chunks       int          `json:"chunks"`  // Distinct chunks in the manifest
+	Ready        int          `json:"ready"`   // Chunks held locally so far
+	Fetched      int          `json:"fetched"` // Of those, chunks this job had to fetch
+	Bytes        uint64       `json:"bytes"`   // Bytes fetched
+	StartedAt    time.Time    `json:"started_at"`
+	ReadyAt      time.Time    `json:"ready_at,omitempty"`
+	ExpiresAt    time.Time    `json:"expires_at,omitempty"` // When the chunks stop being held
+	Error        string       `json:"error,omitempty"`
+}
+
+type warmJob struct {
+	status WarmStatus
+	cancel context.CancelFunc
+}
+
+// WarmManifest prefetches every chunk of an object that is not already
+// local, so a later GetObject never waits on the network. The manifest is
+// fetched before returning; chunks are fetched in the background within the
+// bandwidth budget and held against eviction for Warm.HoldTTL. Completion
+// is published as a MeshEventManifestWarm event. Warming a manifest that is
+// already warming returns the running job's status.
+func (m *MeshCoordinator) WarmManifest(ctx context.Context, manifestHash string, priority WarmPriority) (WarmStatus, error) {
+	if m.storage == nil {
+		return WarmStatus{}, errors.New("storage provider not configured")
+	}
+
+	m.warmMu.Lock()
+	m.pruneWarmLocked(time.Now())
+	if job, ok := m.warmJobs[manifestHash]; ok && job.status.State == WarmStateWarming {
+		status := job.status
+		m.warmMu.Unlock()
+		return status, nil
+	}
+	m.warmMu.Unlock()
+
+	local, _ := m.storage.HasChunk(ctx, manifestHash)
+	data, err := m.fetchStoredChunk(ctx, manifestHash)
+	if err != nil {
+		return WarmStatus{}, fmt.Errorf("failed to fetch manifest: %w", err)
+	}
+	if m.computeResourceDigest(data) != manifestHash {
+		return WarmStatus{}, fmt.Errorf("manifest %s: %w", getShortID(manifestHash), ErrManifestMismatch)
+	}
+	manifest, err := decodeObjectManifest(data)
+	if err != nil {
+		return WarmStatus{}, err
+	}
+	if local {
+		m.setWarmHold(manifestHash)
+	} else if err := m.holdWarmChunk(ctx, manifestHash, data); err != nil {
+		return WarmStatus{}, fmt.Errorf("manifest: %w", err)
+	}
+
+	var chunks []ManifestChunk
+	seen := make(map[string]bool)
+	for _, c := range manifest.Chunks {
+		if !seen[c.Hash] {
+			seen[c.Hash] = true
+			chunks = append(chunks, c)
+		}
+	}
+
+	jobCtx, cancel := context.WithTimeout(context.Background(), m.config.Warm.Timeout)
+	job := &warmJob{
+		status: WarmStatus{
+			ManifestHash: manifestHash,
+			Priority:     priority,
+			State:        WarmStateWarming,
+			Chunks:       len(chunks),
+			StartedAt:    time.Now(),
+		},
+		cancel: cancel,
+	}
+	m.warmMu.Lock()
+	if running, ok := m.warmJobs[manifestHash]; ok && running.status.State == WarmStateWarming {
+		status := running.status
+		m.warmMu.Unlock()
+		cancel()
+		return status, nil
+	}
+	m.warmJobs[manifestHash] = job
+	status := job.status
+	m.warmMu.Unlock()
+
+	go m.runWarmJob(jobCtx, job, chunks)
+	return status, nil
+}
+
+// GetWarmStatus returns the latest status for a warmed manifest.
+func (m *MeshCoordinator) GetWarmStatus(manifestHash string) (WarmStatus, bool) {
+	m.warmMu.Lock()
+	defer m.warmMu.Unlock()
+	job, ok := m.warmJobs[manifestHash]
+	if !ok {
+		return WarmStatus{}, false
+	}
+	return job.status, true
+}
+
+// CancelWarm stops a running warm job. Chunks already fetched stay held
+// until their hold expires.
+func (m *MeshCoordinator) CancelWarm(manifestHash string) bool {
+	m.warmMu.Lock()
+	job, ok := m.warmJobs[manifestHash]
+	running := ok && job.status.State == WarmStateWarming
+	m.warmMu.Unlock()
+	if running {
+		job.cancel()
+	}
+	return running
+}
+
+// isWarmHeld reports whether a warm job is holding a chunk.
+func (m *MeshCoordinator) isWarmHeld(chunkHash string) bool {
+	m.warmMu.Lock()
+	defer m.warmMu.Unlock()
+	expires, ok := m.warmHolds[chunkHash]
+	if ok && time.Now().After(expires) {
+		delete(m.warmHolds, chunkHash)
+		return false
+	}
+	return ok
+}
+
+// pruneWarmLocked drops expired holds and the status of jobs that finished
+// more than HoldTTL ago. The caller holds warmMu.
+func (m *MeshCoordinator) pruneWarmLocked(now time.Time) {
+	for chunkHash, expires := range m.warmHolds {
+		if now.After(expires) {
+			delete(m.warmHolds, chunkHash)
+		}
+	}
+	for manifestHash, job := range m.warmJobs {
+		if job.status.State != WarmStateWarming && now.Sub(job.status.StartedAt) > m.config.Warm.Timeout+m.config.Warm.HoldTTL {
+			delete(m.warmJobs, manifestHash)
+		}
+	}
+}
+
+func (m *MeshCoordinator) runWarmJob(ctx context.Context, job *warmJob, chunks []ManifestChunk) {
+	defer job.cancel()
+
+	budget := float64(m.config.Warm.BandwidthBytesPerSec) * job.status.Priority.budgetShare()
+	start := time.Now()
+	var fetchedBytes uint64
+	var jobErr error
+
+	for _, chunk := range chunks {
+		if ctx.Err() != nil {
+			jobErr = ctx.Err()
+			break
+		}
+		fetched, err := m.warmChunk(ctx, chunk)
+		if err != nil {
+			jobErr = fmt.Errorf("chunk %s: %w", getShortID(chunk.Hash), err)
+			break
+		}
+
+		m.warmMu.Lock()
+		job.status.Ready++
+		if fetched {
+			job.status.Fetched++
+			job.status.Bytes += uint64(chunk.Size)
+		}
+		m.warmMu.Unlock()
+
+		// Pace fetches so the job averages at most its budget
+		if fetched && budget > 0 {
+			fetchedBytes += uint64(chunk.Size)
+			due := start.Add(time.Duration(float64(fetchedBytes) / budget * float64(time.Second)))
+			if wait := time.Until(due); wait > 0 {
+				select {
+				case <-time.After(wait):
+				case <-ctx.Done():
+				}
+			}
+		}
+	}
+
+	m.finishWarmJob(job, jobErr)
+}
+
+// warmChunk makes one chunk local and holds it. It reports whether the
+// chunk had to be fetched.
+func (m *MeshCoordinator) warmChunk(ctx context.Context, chunk ManifestChunk) (bool, error) {
+	if has, err := m.storage.HasChunk(ctx, chunk.Hash); err == nil && has {
+		m.setWarmHold(chunk.Hash)
+		return false, nil
+	}
+	data, err := m.fetchStoredChunk(ctx, chunk.Hash)
+	if err != nil {
+		return false, err
+	}
+	if len(data) != chunk.Size || m.computeResourceDigest(data) != chunk.Hash {
+		return false, ErrManifestMismatch
+	}
+	return true, m.holdWarmChunk(ctx, chunk.Hash, data)
+}
+
+// holdWarmChunk stores a fetched chunk, announces this node as a provider
+// and holds the chunk against eviction.
+func (m *MeshCoordinator) holdWarmChunk(ctx context.Context, chunkHash string, data []byte) error {
+	if err := m.storage.StoreChunk(ctx, chunkHash, data); err != nil {
+		return fmt.Errorf("failed to store chunk: %w", err)
+	}
+	m.setWarmHold(chunkHash)
+	m.trackStoredChunk(ctx, chunkHash, len(data))
+
+	m.localChunksMu.Lock()
+	m.localChunks[chunkHash] = struct{}{}
+	m.localChunksMu.Unlock()
+	_ = m.storeChunkRecord(chunkHash, m.nodeID)
+	return nil
+}
+
+func (m *MeshCoordinator) setWarmHold(chunkHash string) {
+	expires := time.Now().Add(m.config.Warm.HoldTTL)
+	m.warmMu.Lock()
+	if expires.After(m.warmHolds[chunkHash]) {
+		m.warmHolds[chunkHash] = expires
+	}
+	m.warmMu.Unlock()
+}
+
+func (m *MeshCoordinator) finishWarmJob(job *warmJob, err error) {
+	m.warmMu.Lock()
+	switch {
+	case err == nil:
+		job.status.State = WarmStateReady
+		job.status.ReadyAt = time.Now()
+		job.status.ExpiresAt = job.status.ReadyAt.Add(m.config.Warm.HoldTTL)
+	case errors.Is(err, context.Canceled):
+		job.status.State = WarmStateCancelled
+	default:
+		job.status.State = WarmStateFailed
+		job.status.Error = err.Error()
+	}
+	status := job.status
+	m.warmMu.Unlock()
+
+	if err != nil && status.State == WarmStateFailed {
+		m.logger.Warn("manifest warm failed", "manifest", getShortID(status.ManifestHash), "error", err)
+	} else {
+		m.logger.Debug("manifest warm finished", "manifest", getShortID(status.ManifestHash), "state", status.State, "fetched", status.Fetched)
+	}
+	m.publishEvent(MeshEventManifestWarm, "", map[string]interface{}{
+		"manifest_hash": status.ManifestHash,
+		"state":         status.State,
+		"ready":         status.Ready,
+		"chunks":        status.Chunks,
+	})
+}
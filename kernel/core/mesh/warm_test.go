@@ -0,0 +1,64 @@
+package mesh
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+)
+
+func TestWarm_PrefetchesAndHoldsManifestChunks(t *testing.T) {
+	ctx := context.Background()
+	holder := NewMeshCoordinator("holder", "us-east", &MockTransport{nodeID: "holder"}, nil)
+	holder.SetStorage(&MockStorage{chunks: map[string][]byte{}})
+	object := append(bytes.Repeat([]byte("a"), 64), bytes.Repeat([]byte("b"), 40)...)
+	hash, manifest, err := holder.PutObject(ctx, bytes.NewReader(object), ObjectOptions{ChunkSize: 64})
+	if err != nil {
+		t.Fatalf("PutObject failed: %v", err)
+	}
+
+	coord := NewMeshCoordinator("self", "us-east", &MockTransport{nodeID: "self"}, nil)
+	storage := &MockStorage{chunks: map[string][]byte{}}
+	coord.SetStorage(storage)
+	coord.config.Warm.BandwidthBytesPerSec = 0
+	tr := coord.transport.(*MockTransport)
+	tr.registeredRPCHandlers[chunkFetchMethod] = holder.transport.(*MockTransport).registeredRPCHandlers[chunkFetchMethod]
+	coord.cachePeer("holder", &common.PeerCapability{PeerID: "holder", LatencyMs: 5})
+	for _, h := range []string{hash, manifest.Chunks[0].Hash, manifest.Chunks[1].Hash} {
+		_ = coord.dht.Store(h, "holder", 3600)
+	}
+
+	status, err := coord.WarmManifest(ctx, hash, WarmPriorityHigh)
+	if err != nil || status.Chunks != 2 {
+		t.Fatalf("WarmManifest failed: %+v %v", status, err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for status.State == WarmStateWarming && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+		status, _ = coord.GetWarmStatus(hash)
+	}
+	if status.State != WarmStateReady || status.Ready != 2 || status.Fetched != 2 || status.Bytes != uint64(len(object)) {
+		t.Fatalf("unexpected warm status %+v", status)
+	}
+
+	for _, h := range []string{hash, manifest.Chunks[0].Hash, manifest.Chunks[1].Hash} {
+		if _, ok := storage.chunks[h]; !ok {
+			t.Fatalf("chunk %s was not made local", h)
+		}
+		if !coord.IsPinned(h) {
+			t.Fatalf("chunk %s is not held against eviction", h)
+		}
+	}
+
+	// Holds lapse after HoldTTL
+	coord.warmMu.Lock()
+	for h := range coord.warmHolds {
+		coord.warmHolds[h] = time.Now().Add(-time.Second)
+	}
+	coord.warmMu.Unlock()
+	if coord.IsPinned(manifest.Chunks[0].Hash) {
+		t.Fatal("expected the hold to expire")
+	}
+}
@@ -0,0 +1,437 @@
+package mesh
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/threads/foundation"
+)
+
+const featureProbeMethod = "mesh.FeatureProbe"
+
+// WASM features a delegated module may depend on.
+const (
+	WASMFeatureSIMD       = "simd"
+	WASMFeatureThreads    = "threads"
+	WASMFeatureBulkMemory = "bulk_memory"
+	WASMFeatureExceptions = "exceptions"
+	WASMFeatureTailCall   = "tail_call"
+)
+
+// Job parameters naming what the executing runtime must support. Both take a
+// list of strings.
+const (
+	JobParamWASMFeatures = "wasm_features"
+	JobParamWASMModules  = "wasm_modules"
+)
+
+// featureProbeTTL is how long a peer's feature report is trusted. Browser
+// features do not change within a session, but module loads can.
+const featureProbeTTL = 10 * time.Minute
+
+// featureProbeMaxCandidates bounds how many peers are probed for one job
+// before delegation gives up.
+const featureProbeMaxCandidates = 4
+
+// featureProbeMaxModules bounds the module loads a peer may ask us to check.
+const featureProbeMaxModules = 32
+
+// ErrUnsupportedFeature is wrapped by every UnsupportedFeatureError.
+var ErrUnsupportedFeature = errors.New("unsupported wasm feature")
+
+// UnsupportedFeatureError reports why a peer cannot run a job.
+type UnsupportedFeatureError struct {
+	PeerID          string
+	MissingFeatures []string
+	FailedModules   map[string]string // Module ID -> load error
+}
+
+func (e *UnsupportedFeatureError) Error() string {
+	var parts []string
+	if len(e.MissingFeatures) > 0 {
+		parts = append(parts, "missing wasm features ["+strings.Join(e.MissingFeatures, " ")+"]")
+	}
+	modules := make([]string, 0, len(e.FailedModules))
+	for module := range e.FailedModules {
+		modules = append(modules, module)
+	}
+	sort.Strings(modules)
+	for _, module := range modules {
+		parts = append(parts, fmt.Sprintf("module %s failed to load: %s", module, e.FailedModules[module]))
+	}
+
+	peer := "local runtime"
+	if e.PeerID != "" {
+		peer = "peer " + getShortID(e.PeerID)
+	}
+	return fmt.Sprintf("%s cannot run job: %s", peer, strings.Join(parts, "; "))
+}
+
+func (e *UnsupportedFeatureError) Unwrap() error { return ErrUnsupportedFeature }
+
+// FeatureProber reports what this node's WASM runtime can execute.
+type FeatureProber interface {
+	// WASMFeatures returns the supported features (WASMFeature* names).
+	WASMFeatures() []string
+	// ModuleLoadError returns nil if the module is loaded and runnable.
+	ModuleLoadError(moduleID string) error
+}
+
+// WASMRequirements lists what a job needs from the runtime that executes it.
+type WASMRequirements struct {
+	Features []string              `json:"features,omitempty"`
+	Modules  []string              `json:"modules,omitempty"`
+	GPU      *GPURequirements      `json:"gpu,omitempty"` // Set for GPU-class jobs
+	Hardware *HardwareRequirements `json:"hardware,omitempty"`
+}
+
+// IsEmpty reports whether any runtime can satisfy the requirements.
+func (r WASMRequirements) IsEmpty() bool {
+	return len(r.Features) == 0 && len(r.Modules) == 0 && r.GPU == nil && r.Hardware == nil
+}
+
+// needsProbe reports whether the requirements go beyond what peers
+// advertise, so a candidate must be probed.
+func (r WASMRequirements) needsProbe() bool {
+	return len(r.Features) > 0 || len(r.Modules) > 0
+}
+
+func (r WASMRequirements) merge(other WASMRequirements) WASMRequirements {
+	return WASMRequirements{
+		Features: mergeStrings(r.Features, other.Features),
+		Modules:  mergeStrings(r.Modules, other.Modules),
+		GPU:      mergeGPURequirements(r.GPU, other.GPU),
+		Hardware: mergeHardwareRequirements(r.Hardware, other.Hardware),
+	}
+}
+
+// JobWASMRequirements reads a job's requirements from its parameters.
+func JobWASMRequirements(job *foundation.Job) WASMRequirements {
+	if job == nil {
+		return WASMRequirements{}
+	}
+	return WASMRequirements{
+		Features: mergeStrings(nil, stringListParam(job.Parameters[JobParamWASMFeatures])),
+		Modules:  mergeStrings(nil, stringListParam(job.Parameters[JobParamWASMModules])),
+		GPU:      jobGPURequirements(job.Operation, job.Parameters),
+		Hardware: jobHardwareRequirements(job.Parameters),
+	}
+}
+
+type wasmRequirementsKey struct{}
+
+// WithWASMRequirements attaches requirements to ctx for DelegateCompute, whose
+// arguments have no room for them. DelegateJob merges them with the job's own.
+func WithWASMRequirements(ctx context.Context, req WASMRequirements) context.Context {
+	return context.WithValue(ctx, wasmRequirementsKey{}, req)
+}
+
+func wasmRequirementsFromContext(ctx context.Context) WASMRequirements {
+	req, _ := ctx.Value(wasmRequirementsKey{}).(WASMRequirements)
+	return req
+}
+
+// stringListParam accepts both []string and the []interface{} a job's
+// parameters hold after a JSON round trip.
+func stringListParam(v interface{}) []string {
+	switch list := v.(type) {
+	case []string:
+		return list
+	case []interface{}:
+		out := make([]string, 0, len(list))
+		for _, item := range list {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	case string:
+		return []string{list}
+	}
+	return nil
+}
+
+// mergeStrings returns the sorted union of a and b without empty entries.
+func mergeStrings(a, b []string) []string {
+	seen := make(map[string]struct{}, len(a)+len(b))
+	var out []string
+	for _, s := range append(append([]string(nil), a...), b...) {
+		s = strings.TrimSpace(s)
+		if _, dup := seen[s]; s == "" || dup {
+			continue
+		}
+		seen[s] = struct{}{}
+		out = append(out, s)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// PeerFeatureReport is the latest probe result for a peer.
+type PeerFeatureReport struct {
+	PeerID   string            `json:"peer_id"`
+	Features []string          `json:"features"`
+	Modules  map[string]string `json:"modules"` // Module ID -> load error, "" if loaded
+	ProbedAt time.Time         `json:"probed_at"`
+}
+
+// unprobedModules returns the required modules the report has no answer for.
+func (r *PeerFeatureReport) unprobedModules(req WASMRequirements) []string {
+	var out []string
+	for _, module := range req.Modules {
+		if _, ok := r.Modules[module]; !ok {
+			out = append(out, module)
+		}
+	}
+	return out
+}
+
+// check returns an UnsupportedFeatureError listing every unmet requirement,
+// or nil if the report satisfies req.
+func (r *PeerFeatureReport) check(req WASMRequirements) error {
+	return checkWASMSupport(r.PeerID, r.Features, req, func(module string) (string, bool) {
+		loadErr, ok := r.Modules[module]
+		return loadErr, ok
+	})
+}
+
+func checkWASMSupport(peerID string, features []string, req WASMRequirements, moduleError func(string) (string, bool)) error {
+	supported := make(map[string]struct{}, len(features))
+	for _, f := range features {
+		supported[f] = struct{}{}
+	}
+
+	unsupported := &UnsupportedFeatureError{PeerID: peerID}
+	for _, f := range req.Features {
+		if _, ok := supported[f]; !ok {
+			unsupported.MissingFeatures = append(unsupported.MissingFeatures, f)
+		}
+	}
+	for _, module := range req.Modules {
+		loadErr, probed := moduleError(module)
+		if !probed {
+			loadErr = "not probed"
+		}
+		if loadErr != "" {
+			if unsupported.FailedModules == nil {
+				unsupported.FailedModules = make(map[string]string)
+			}
+			unsupported.FailedModules[module] = loadErr
+		}
+	}
+
+	if len(unsupported.MissingFeatures) == 0 && len(unsupported.FailedModules) == 0 {
+		return nil
+	}
+	return unsupported
+}
+
+type featureProbeRequest struct {
+	Modules []string `json:"modules,omitempty"`
+}
+
+type featureProbeResponse struct {
+	Features []string          `json:"features"`
+	Modules  map[string]string `json:"modules,omitempty"`
+}
+
+// SetFeatureProber installs the runtime that answers feature probes. Nodes
+// without one refuse probes, so jobs with requirements are never sent to them.
+func (m *MeshCoordinator) SetFeatureProber(p FeatureProber) {
+	m.featureProbesMu.Lock()
+	defer m.featureProbesMu.Unlock()
+	m.featureProber = p
+}
+
+func (m *MeshCoordinator) getFeatureProber() FeatureProber {
+	m.featureProbesMu.RLock()
+	defer m.featureProbesMu.RUnlock()
+	return m.featureProber
+}
+
+// localFeatureReport answers a probe from this node's runtime.
+func (m *MeshCoordinator) localFeatureReport(modules []string) (featureProbeResponse, error) {
+	prober := m.getFeatureProber()
+	if prober == nil {
+		return featureProbeResponse{}, errors.New("wasm feature probing not supported")
+	}
+
+	resp := featureProbeResponse{Features: mergeStrings(nil, prober.WASMFeatures())}
+	if len(modules) > 0 {
+		resp.Modules = make(map[string]string, len(modules))
+		for _, module := range modules {
+			resp.Modules[module] = ""
+			if err := prober.ModuleLoadError(module); err != nil {
+				resp.Modules[module] = err.Error()
+			}
+		}
+	}
+	return resp, nil
+}
+
+// checkLocalFeatures reports whether this node can run a job with req. A node
+// without a prober cannot confirm any requirement, matching how it answers probes.
+func (m *MeshCoordinator) checkLocalFeatures(req WASMRequirements) error {
+	if err := m.checkLocalGPU(req.GPU); err != nil {
+		return err
+	}
+	if err := m.checkLocalHardware(req.Hardware); err != nil {
+		return err
+	}
+	if !req.needsProbe() {
+		return nil
+	}
+	local, err := m.localFeatureReport(req.Modules)
+	if err != nil {
+		return err
+	}
+	return checkWASMSupport("", local.Features, req, func(module string) (string, bool) {
+		loadErr, ok := local.Modules[module]
+		return loadErr, ok
+	})
+}
+
+func (m *MeshCoordinator) registerFeatureProbeHandler() {
+	m.registerRPC(featureProbeMethod, func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error) {
+		var req featureProbeRequest
+		if err := json.Unmarshal(args, &req); err != nil {
+			return nil, fmt.Errorf("failed to decode feature probe: %w", err)
+		}
+		if len(req.Modules) > featureProbeMaxModules {
+			return nil, fmt.Errorf("feature probe names too many modules: %d", len(req.Modules))
+		}
+		return m.localFeatureReport(req.Modules)
+	})
+}
+
+// ProbePeerFeatures asks a peer which WASM features it supports and whether
+// the given modules load there, and caches the answer.
+func (m *MeshCoordinator) ProbePeerFeatures(ctx context.Context, peerID string, modules []string) (PeerFeatureReport, error) {
+	var resp featureProbeResponse
+	if err := m.transport.SendRPC(ctx, peerID, featureProbeMethod, featureProbeRequest{Modules: modules}, &resp); err != nil {
+		m.recordRPCFailure(peerID, featureProbeMethod, err)
+		return PeerFeatureReport{}, err
+	}
+
+	m.featureProbesMu.Lock()
+	defer m.featureProbesMu.Unlock()
+
+	report, ok := m.featureProbes[peerID]
+	if !ok || time.Since(report.ProbedAt) > featureProbeTTL {
+		report = &PeerFeatureReport{PeerID: peerID, Modules: make(map[string]string)}
+		m.featureProbes[peerID] = report
+	}
+	report.Features = mergeStrings(nil, resp.Features)
+	for _, module := range modules {
+		// A module missing from the answer was not loadable
+		loadErr, answered := resp.Modules[module]
+		if !answered {
+			loadErr = "no load result"
+		}
+		report.Modules[module] = loadErr
+	}
+	report.ProbedAt = time.Now()
+	return copyFeatureReport(report), nil
+}
+
+// GetPeerFeatures returns the cached feature report for a peer.
+func (m *MeshCoordinator) GetPeerFeatures(peerID string) (PeerFeatureReport, bool) {
+	m.featureProbesMu.RLock()
+	defer m.featureProbesMu.RUnlock()
+
+	report, ok := m.featureProbes[peerID]
+	if !ok {
+		return PeerFeatureReport{}, false
+	}
+	return copyFeatureReport(report), true
+}
+
+func copyFeatureReport(r *PeerFeatureReport) PeerFeatureReport {
+	out := *r
+	out.Features = append([]string(nil), r.Features...)
+	out.Modules = make(map[string]string, len(r.Modules))
+	for k, v := range r.Modules {
+		out.Modules[k] = v
+	}
+	return out
+}
+
+// checkPeerFeatures verifies a peer can run a job with req, probing it when
+// the cached report is stale or lacks an answer for a required module.
+func (m *MeshCoordinator) checkPeerFeatures(ctx context.Context, peerID string, req WASMRequirements) error {
+	if !req.needsProbe() {
+		return nil
+	}
+
+	report, ok := m.GetPeerFeatures(peerID)
+	if !ok || time.Since(report.ProbedAt) > featureProbeTTL || len(report.unprobedModules(req)) > 0 {
+		probeCtx, cancel := context.WithTimeout(ctx, m.config.LookupTimeout)
+		defer cancel()
+		var err error
+		if report, err = m.ProbePeerFeatures(probeCtx, peerID, req.Modules); err != nil {
+			return fmt.Errorf("feature probe to %s failed: %w", getShortID(peerID), err)
+		}
+	}
+	return report.check(req)
+}
+
+// selectPeerForRequirements returns the best-scoring peer that can run a job
+// with req. If every candidate lacks support, the first UnsupportedFeatureError
+// seen is returned so the caller learns what was missing.
+func (m *MeshCoordinator) selectPeerForRequirements(ctx context.Context, req WASMRequirements) (string, float32, error) {
+	if req.IsEmpty() {
+		peer, score := m.selectBestPeerForJob()
+		return peer, score, nil
+	}
+
+	// GPU jobs only go to peers whose advertised adapter fits, ranked by the
+	// GPU time they reported for earlier jobs
+	var adjust func(peerID string, score float32) float32
+	if req.GPU != nil {
+		adjust = m.gpuScore
+	}
+
+	rejected := make(map[string]struct{})
+	var unsupported, lastErr error
+	for i := 0; i < featureProbeMaxCandidates && ctx.Err() == nil; i++ {
+		peer, score := m.selectBestPeerScored(func(peerID string) bool {
+			_, skip := rejected[peerID]
+			return !skip && (req.GPU == nil || len(req.GPU.missing(m.peerGPU(peerID))) == 0) &&
+				len(req.Hardware.missing(m.peerHardware(peerID))) == 0
+		}, adjust)
+		if peer == "" {
+			switch {
+			case unsupported != nil:
+			case req.GPU != nil:
+				unsupported = &UnsupportedFeatureError{MissingFeatures: []string{"gpu"}}
+			case req.Hardware != nil:
+				unsupported = &UnsupportedFeatureError{MissingFeatures: req.Hardware.missing(nil)}
+			}
+			break
+		}
+
+		err := m.checkPeerFeatures(ctx, peer, req)
+		if err == nil {
+			return peer, score, nil
+		}
+		m.logger.Debug("peer rejected for delegation", "peer", getShortID(peer), "error", err)
+		rejected[peer] = struct{}{}
+		if unsupported == nil && errors.Is(err, ErrUnsupportedFeature) {
+			unsupported = err
+		}
+		lastErr = err
+	}
+
+	switch {
+	case unsupported != nil:
+		return "", 0, fmt.Errorf("no peer supports the job's wasm requirements: %w", unsupported)
+	case lastErr != nil:
+		return "", 0, fmt.Errorf("no peer confirmed the job's wasm requirements: %w", lastErr)
+	}
+	return "", 0, nil
+}
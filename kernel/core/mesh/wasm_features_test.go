@@ -0,0 +1,183 @@
+package mesh
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+	"github.com/nmxmxh/inos_v1/kernel/threads/foundation"
+)
+
+type staticFeatureProber struct {
+	features []string
+	modules  map[string]error // Module ID -> load error; absent modules are not loaded
+}
+
+func (p *staticFeatureProber) WASMFeatures() []string { return p.features }
+
+func (p *staticFeatureProber) ModuleLoadError(moduleID string) error {
+	err, ok := p.modules[moduleID]
+	if !ok {
+		return fmt.Errorf("module %s not registered", moduleID)
+	}
+	return err
+}
+
+func wasmJob(features, modules []interface{}) *foundation.Job {
+	return &foundation.Job{
+		ID:        "job-wasm",
+		Type:      "compute",
+		Operation: "simulate",
+		Data:      []byte("input"),
+		Parameters: map[string]interface{}{
+			JobParamWASMFeatures: features,
+			JobParamWASMModules:  modules,
+		},
+	}
+}
+
+func TestFeatureProbe_RejectsPeerMissingFeatures(t *testing.T) {
+	coord, _ := newDelegationTestCoordinator(t)
+	coord.SetFeatureProber(&staticFeatureProber{features: []string{WASMFeatureBulkMemory}})
+
+	_, err := coord.DelegateJob(context.Background(), wasmJob([]interface{}{"threads", "simd"}, nil))
+	if !errors.Is(err, ErrUnsupportedFeature) {
+		t.Fatalf("expected ErrUnsupportedFeature, got %v", err)
+	}
+	var unsupported *UnsupportedFeatureError
+	if !errors.As(err, &unsupported) {
+		t.Fatalf("expected UnsupportedFeatureError, got %T", err)
+	}
+	if unsupported.PeerID != "peer-1" || strings.Join(unsupported.MissingFeatures, ",") != "simd,threads" {
+		t.Fatalf("unexpected error detail: %+v", unsupported)
+	}
+	if !strings.Contains(err.Error(), "missing wasm features [simd threads]") {
+		t.Fatalf("error does not name the missing features: %v", err)
+	}
+}
+
+func TestFeatureProbe_ReportsModuleLoadFailure(t *testing.T) {
+	coord, _ := newDelegationTestCoordinator(t)
+	coord.SetFeatureProber(&staticFeatureProber{
+		features: []string{WASMFeatureSIMD},
+		modules:  map[string]error{"physics": errors.New("instantiate: out of memory")},
+	})
+
+	_, err := coord.DelegateJob(context.Background(), wasmJob([]interface{}{"simd"}, []interface{}{"physics"}))
+	if !errors.Is(err, ErrUnsupportedFeature) {
+		t.Fatalf("expected ErrUnsupportedFeature, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "module physics failed to load: instantiate: out of memory") {
+		t.Fatalf("error does not carry the load failure: %v", err)
+	}
+}
+
+func TestFeatureProbe_DelegatesToCapablePeerAndCaches(t *testing.T) {
+	coord, _ := newDelegationTestCoordinator(t)
+	coord.SetFeatureProber(&staticFeatureProber{
+		features: []string{WASMFeatureSIMD, WASMFeatureThreads},
+		modules:  map[string]error{"physics": nil},
+	})
+
+	result, err := coord.DelegateJob(context.Background(), wasmJob([]interface{}{"simd", "threads"}, []interface{}{"physics"}))
+	if err != nil {
+		t.Fatalf("DelegateJob failed: %v", err)
+	}
+	if !result.Success {
+		t.Fatalf("expected successful result, got %+v", result)
+	}
+
+	report, ok := coord.GetPeerFeatures("peer-1")
+	if !ok {
+		t.Fatal("expected cached feature report for peer-1")
+	}
+	if loadErr, probed := report.Modules["physics"]; !probed || loadErr != "" {
+		t.Fatalf("expected physics recorded as loaded, got %+v", report.Modules)
+	}
+}
+
+func TestFeatureProbe_SkipsUnreachablePeer(t *testing.T) {
+	coord, tr := newDelegationTestCoordinator(t)
+	coord.SetFeatureProber(&staticFeatureProber{features: []string{WASMFeatureSIMD}})
+	coord.peerMetricsMu.Lock()
+	coord.peerMetrics["peer-2"] = common.MeshMetrics{AvgReputation: 0.5, P50LatencyMs: 1.0}
+	coord.peerMetricsMu.Unlock()
+	trustPeers(coord, "peer-2")
+	tr.rpcFailures = map[string]error{featureProbeMethod + "@peer-1": errors.New("unknown method")}
+
+	if _, err := coord.DelegateJob(context.Background(), wasmJob([]interface{}{"simd"}, nil)); err != nil {
+		t.Fatalf("expected fallback to peer-2, got %v", err)
+	}
+	if _, ok := coord.GetPeerFeatures("peer-2"); !ok {
+		t.Fatal("expected peer-2 to be probed")
+	}
+}
+
+func TestFeatureProbe_NodeWithoutProberRefusesRequirements(t *testing.T) {
+	coord, _ := newDelegationTestCoordinator(t)
+
+	_, err := coord.DelegateJob(context.Background(), wasmJob([]interface{}{"simd"}, nil))
+	if err == nil || !strings.Contains(err.Error(), "no peer confirmed") {
+		t.Fatalf("expected unconfirmed requirements error, got %v", err)
+	}
+
+	// Jobs without requirements are unaffected
+	if _, err := coord.DelegateJob(context.Background(), &foundation.Job{ID: "plain", Operation: "noop"}); err != nil {
+		t.Fatalf("plain delegation failed: %v", err)
+	}
+}
+
+func TestFeatureProbe_ComputeRequirementsFromContext(t *testing.T) {
+	coord, _ := newDelegationTestCoordinator(t)
+	coord.SetFeatureProber(&staticFeatureProber{})
+
+	ctx := WithWASMRequirements(context.Background(), WASMRequirements{Features: []string{WASMFeatureTailCall}})
+	if _, err := coord.DelegateCompute(ctx, "compress", "input-digest", []byte("source")); !errors.Is(err, ErrUnsupportedFeature) {
+		t.Fatalf("expected ErrUnsupportedFeature, got %v", err)
+	}
+}
+
+func TestJobWASMRequirements_NormalizesParameters(t *testing.T) {
+	job := wasmJob([]interface{}{"threads", " simd", "threads", 7}, nil)
+	job.Parameters[JobParamWASMModules] = []string{"physics", ""}
+
+	req := JobWASMRequirements(job)
+	if strings.Join(req.Features, ",") != "simd,threads" {
+		t.Fatalf("unexpected features: %v", req.Features)
+	}
+	if strings.Join(req.Modules, ",") != "physics" {
+		t.Fatalf("unexpected modules: %v", req.Modules)
+	}
+	if !JobWASMRequirements(&foundation.Job{}).IsEmpty() {
+		t.Fatal("job without parameters should have no requirements")
+	}
+}
+
+func TestFeatureProbe_IncapableNodeDoesNotClaimWork(t *testing.T) {
+	coord, tr := newDelegationTestCoordinator(t)
+	coord.config.WorkQueue.Enabled = true
+	coord.SetFeatureProber(&staticFeatureProber{features: []string{WASMFeatureSIMD}})
+
+	claimed := make(chan struct{}, 1)
+	tr.rpcHandlers[workClaimMethod] = func(args interface{}) (interface{}, error) {
+		claimed <- struct{}{}
+		return nil, errors.New("test claim")
+	}
+
+	coord.handleWorkAnnouncement(WorkAnnouncement{
+		JobID:        "job-threads",
+		Requester:    "peer-1",
+		Requirements: &WASMRequirements{Features: []string{WASMFeatureThreads}},
+	})
+	if n := coord.localWorkers.Load(); n != 0 {
+		t.Fatalf("expected no local worker for unsupported job, got %d", n)
+	}
+	select {
+	case <-claimed:
+		t.Fatal("node claimed a job it cannot run")
+	default:
+	}
+}
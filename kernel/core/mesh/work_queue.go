@@ -0,0 +1,458 @@
+package mesh
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+	"github.com/nmxmxh/inos_v1/kernel/threads/foundation"
+)
+
+const (
+	workAnnounceTopic  = "job_announce"
+	workClaimMethod    = "mesh.ClaimJob"
+	workCompleteMethod = "mesh.CompleteJob"
+)
+
+// ErrNoWorkClaimed is returned when no idle peer pulled a job before the claim timeout.
+var ErrNoWorkClaimed = errors.New("no peer claimed job")
+
+// WorkAnnouncement is gossiped so idle peers can pull a job.
+type WorkAnnouncement struct {
+	JobID     string `json:"job_id"`
+	Requester string `json:"requester"`
+	Operation string `json:"operation"`
+	Size      int    `json:"size"`
+	Priority  int    `json:"priority"`
+	Credits   uint64 `json:"credits"`
+
+	// Runtime support the job needs; peers that lack it do not claim
+	Requirements *WASMRequirements `json:"requirements,omitempty"`
+}
+
+// workJob is the wire form of foundation.Job (which carries a result channel).
+type workJob struct {
+	ID         string                 `json:"id"`
+	Type       string                 `json:"type"`
+	Operation  string                 `json:"operation"`
+	Data       []byte                 `json:"data"`
+	Parameters map[string]interface{} `json:"parameters,omitempty"`
+	Priority   int                    `json:"priority"`
+	Deadline   time.Time              `json:"deadline"`
+
+	// IdempotencyKey is only sent on direct mesh.ExecuteJob calls
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
+
+	// Capability is only sent on claims: the mesh.ExecuteJob capability the
+	// claimant issued the requester, checked before the pulled job runs
+	Capability string `json:"capability,omitempty"`
+}
+
+type workCompletion struct {
+	JobID     string  `json:"job_id"`
+	Success   bool    `json:"success"`
+	Data      []byte  `json:"data,omitempty"`
+	Error     string  `json:"error,omitempty"`
+	LatencyMs float64 `json:"latency_ms"`
+}
+
+type workItem struct {
+	job       *foundation.Job
+	credits   uint64
+	createdAt time.Time
+	claimedBy string
+	claimed   chan struct{}
+	released  chan struct{} // Signalled when the claimant hands the job back
+	done      chan *foundation.Result
+
+	// Claimants that handed the job back, refused or departing; they are not
+	// offered it again
+	releasedBy map[string]bool
+}
+
+// SubmitWork announces a job on the shared work queue and waits for an idle
+// peer to pull and complete it.
+func (m *MeshCoordinator) SubmitWork(ctx context.Context, job *foundation.Job) (*foundation.Result, error) {
+	return m.submitWork(ctx, job, JobWASMRequirements(job))
+}
+
+func (m *MeshCoordinator) submitWork(ctx context.Context, job *foundation.Job, requirements WASMRequirements) (*foundation.Result, error) {
+	if job == nil || job.ID == "" {
+		return nil, errors.New("job with ID is required")
+	}
+
+	item := &workItem{
+		job:       job,
+		credits:   CalculateDelegationCost(job.Operation, uint64(len(job.Data)), job.Priority),
+		createdAt: time.Now(),
+		claimed:   make(chan struct{}),
+		released:  make(chan struct{}, 1),
+		done:      make(chan *foundation.Result, 1),
+	}
+
+	m.pendingWorkMu.Lock()
+	if _, exists := m.pendingWork[job.ID]; exists {
+		m.pendingWorkMu.Unlock()
+		return nil, fmt.Errorf("job %s already queued", job.ID)
+	}
+	m.pendingWork[job.ID] = item
+	m.pendingWorkMu.Unlock()
+	defer func() {
+		m.pendingWorkMu.Lock()
+		delete(m.pendingWork, job.ID)
+		m.pendingWorkMu.Unlock()
+	}()
+
+	announcement := WorkAnnouncement{
+		JobID:     job.ID,
+		Requester: m.nodeID,
+		Operation: job.Operation,
+		Size:      len(job.Data),
+		Priority:  job.Priority,
+		Credits:   item.credits,
+	}
+	if !requirements.IsEmpty() {
+		announcement.Requirements = &requirements
+	}
+
+	cfg := m.config.WorkQueue
+	for {
+		if err := m.awaitClaim(ctx, item, announcement); err != nil {
+			return nil, err
+		}
+
+		resultTimer := time.NewTimer(cfg.ResultTimeout)
+		select {
+		case result := <-item.done:
+			resultTimer.Stop()
+			return result, nil
+		case <-item.released:
+			// The claimant is leaving the mesh; offer the job again
+			resultTimer.Stop()
+			m.logger.Debug("job released by claimant, re-announcing", "job_id", job.ID)
+		case <-resultTimer.C:
+			m.finishWork(item, false)
+			return nil, fmt.Errorf("job %s timed out waiting for result", job.ID)
+		case <-ctx.Done():
+			resultTimer.Stop()
+			m.finishWork(item, false)
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// awaitClaim announces a job until a peer claims it or the claim timeout passes.
+func (m *MeshCoordinator) awaitClaim(ctx context.Context, item *workItem, announcement WorkAnnouncement) error {
+	cfg := m.config.WorkQueue
+	claimDeadline := time.NewTimer(cfg.ClaimTimeout)
+	defer claimDeadline.Stop()
+	reannounce := time.NewTicker(cfg.AnnounceInterval)
+	defer reannounce.Stop()
+
+	m.pendingWorkMu.Lock()
+	claimed := item.claimed
+	m.pendingWorkMu.Unlock()
+
+	m.announceWork(announcement)
+	for {
+		select {
+		case <-claimed:
+			return nil
+		case <-reannounce.C:
+			m.announceWork(announcement)
+		case <-claimDeadline.C:
+			m.pendingWorkMu.Lock()
+			isClaimed := item.claimedBy != ""
+			m.pendingWorkMu.Unlock()
+			if isClaimed {
+				return nil
+			}
+			return ErrNoWorkClaimed
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (m *MeshCoordinator) announceWork(announcement WorkAnnouncement) {
+	if m.gossip == nil {
+		return
+	}
+	if err := m.gossip.Broadcast(workAnnounceTopic, announcement); err != nil {
+		m.logger.Debug("failed to announce job", "job_id", announcement.JobID, "error", err)
+	}
+}
+
+// finishWork releases the provider's concurrency slot and records the outcome.
+func (m *MeshCoordinator) finishWork(item *workItem, success bool) {
+	m.pendingWorkMu.Lock()
+	provider := item.claimedBy
+	item.claimedBy = ""
+	m.pendingWorkMu.Unlock()
+
+	if provider == "" {
+		return
+	}
+	m.decrementActiveJobs(provider)
+	if m.ledger != nil {
+		m.ledger.RecordWorkOutcome(provider, success, item.credits)
+	}
+	m.updateCircuitBreaker(provider, success)
+}
+
+// claimWork hands a queued job to a peer if the peer is under its concurrency
+// limit and is not hogging pulled work while the job is still fresh.
+func (m *MeshCoordinator) claimWork(peerID, jobID string) (*workJob, error) {
+	m.pendingWorkMu.Lock()
+	defer m.pendingWorkMu.Unlock()
+
+	item, exists := m.pendingWork[jobID]
+	if !exists {
+		return nil, fmt.Errorf("job %s not available", jobID)
+	}
+	if item.claimedBy != "" {
+		return nil, fmt.Errorf("job %s already claimed", jobID)
+	}
+	// finishWork clears claimedBy once a job completes, but the item stays
+	// queued until the submitter collects the result. A closed claimed
+	// channel means the job was handed out and never released.
+	select {
+	case <-item.claimed:
+		return nil, fmt.Errorf("job %s already claimed", jobID)
+	default:
+	}
+	if item.releasedBy[peerID] {
+		return nil, fmt.Errorf("claim rejected: job %s already handed back by peer", jobID)
+	}
+	if m.isCircuitBreakerOpenForPeer(peerID) {
+		return nil, errors.New("claim rejected: circuit breaker open")
+	}
+
+	cfg := m.config.WorkQueue
+	m.activeJobsMu.RLock()
+	active := m.activeJobs[peerID]
+	m.activeJobsMu.RUnlock()
+	if cfg.MaxJobsPerPeer > 0 && int(active) >= cfg.MaxJobsPerPeer {
+		return nil, errors.New("claim rejected: peer at concurrency limit")
+	}
+
+	if m.ledger != nil && time.Since(item.createdAt) < cfg.FairShareGrace &&
+		m.ledger.FairShareRatio(peerID) > cfg.FairShareCap {
+		return nil, errors.New("claim rejected: peer above fair share")
+	}
+
+	item.claimedBy = peerID
+	close(item.claimed)
+	m.incrementActiveJobs(peerID)
+	if m.ledger != nil {
+		m.ledger.RecordWorkClaim(peerID)
+	}
+
+	job := item.job
+	return toWorkJob(job), nil
+}
+
+// releaseWork returns a claimed job to the queue so another peer can pull it.
+// The releasing peer may not claim it again.
+func (m *MeshCoordinator) releaseWork(peerID, jobID string) error {
+	m.pendingWorkMu.Lock()
+	item, exists := m.pendingWork[jobID]
+	if !exists || item.claimedBy != peerID {
+		m.pendingWorkMu.Unlock()
+		return fmt.Errorf("job %s not claimed by peer", jobID)
+	}
+	if item.releasedBy == nil {
+		item.releasedBy = make(map[string]bool)
+	}
+	item.releasedBy[peerID] = true
+	item.claimedBy = ""
+	item.claimed = make(chan struct{})
+	m.pendingWorkMu.Unlock()
+
+	m.decrementActiveJobs(peerID)
+	select {
+	case item.released <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func toWorkJob(job *foundation.Job) *workJob {
+	return &workJob{
+		ID:         job.ID,
+		Type:       job.Type,
+		Operation:  job.Operation,
+		Data:       job.Data,
+		Parameters: job.Parameters,
+		Priority:   job.Priority,
+		Deadline:   job.Deadline,
+	}
+}
+
+// toJob turns a received job back into the canonical model, attributed to
+// the peer it came from.
+func (w *workJob) toJob(source string) *foundation.Job {
+	return &foundation.Job{
+		ID:         w.ID,
+		Type:       w.Type,
+		Operation:  w.Operation,
+		Data:       w.Data,
+		Parameters: w.Parameters,
+		Priority:   w.Priority,
+		Deadline:   w.Deadline,
+		Source:     source,
+	}
+}
+
+func (m *MeshCoordinator) completeWork(peerID string, completion workCompletion) error {
+	m.pendingWorkMu.Lock()
+	item, exists := m.pendingWork[completion.JobID]
+	if !exists || item.claimedBy != peerID {
+		m.pendingWorkMu.Unlock()
+		return fmt.Errorf("job %s not claimed by peer", completion.JobID)
+	}
+	m.pendingWorkMu.Unlock()
+
+	m.finishWork(item, completion.Success)
+
+	select {
+	case item.done <- &foundation.Result{
+		JobID:       completion.JobID,
+		Success:     completion.Success,
+		Data:        completion.Data,
+		Error:       completion.Error,
+		Latency:     time.Duration(completion.LatencyMs * float64(time.Millisecond)),
+		CompletedAt: time.Now(),
+	}:
+	default:
+	}
+	return nil
+}
+
+// handleWorkAnnouncement pulls announced work when this node is idle and
+// has opted in to the work queue.
+func (m *MeshCoordinator) handleWorkAnnouncement(announcement WorkAnnouncement) {
+	if !m.config.WorkQueue.Enabled || m.dispatcher == nil || announcement.Requester == "" || announcement.Requester == m.nodeID {
+		return
+	}
+	if m.departing.Load() || m.admitProfileCompute() != nil {
+		return
+	}
+	if announcement.Requirements != nil {
+		if err := m.checkLocalFeatures(*announcement.Requirements); err != nil {
+			m.logger.Debug("skipping announced job", "job_id", announcement.JobID, "error", err)
+			return
+		}
+	}
+
+	limit := int32(m.config.WorkQueue.MaxLocalWorkers)
+	if limit > 0 && m.localWorkers.Add(1) > limit {
+		m.localWorkers.Add(-1)
+		return
+	}
+
+	go func() {
+		defer m.localWorkers.Add(-1)
+
+		ctx, cancel := context.WithTimeout(context.Background(), m.config.WorkQueue.ResultTimeout)
+		defer cancel()
+
+		var job workJob
+		if err := m.transport.SendRPC(ctx, announcement.Requester, workClaimMethod, workClaimRequest{JobID: announcement.JobID}, &job); err != nil {
+			m.logger.Debug("job claim rejected", "job_id", announcement.JobID, "error", err)
+			return
+		}
+		// Pulled work needs the same authorization as a direct mesh.ExecuteJob
+		if err := m.authorizeCapability(job.Capability, announcement.Requester, executeJobMethod); err != nil {
+			m.logger.Debug("refusing pulled job", "job_id", job.ID, "peer", getShortID(announcement.Requester), "error", err)
+			var ack workCompletionAck
+			_ = m.transport.SendRPC(ctx, announcement.Requester, workReleaseMethod, workReleaseRequest{JobID: job.ID}, &ack)
+			return
+		}
+		m.pulledWorkMu.Lock()
+		m.pulledWork[job.ID] = announcement.Requester
+		m.pulledWorkMu.Unlock()
+		defer func() {
+			m.pulledWorkMu.Lock()
+			delete(m.pulledWork, job.ID)
+			m.pulledWorkMu.Unlock()
+		}()
+
+		// Pulled work runs under the same sandbox as a direct mesh.ExecuteJob
+		start := time.Now()
+		result, err := m.runSandboxed(ctx, announcement.Requester, job.toJob(announcement.Requester))
+		var exceeded *ResourceExceededError
+		if errors.As(err, &exceeded) {
+			result = &foundation.Result{JobID: job.ID, Error: exceeded.wire()}
+		} else if err != nil {
+			m.logger.Debug("pulled job abandoned", "job_id", job.ID, "error", err)
+			return
+		}
+
+		completion := workCompletion{
+			JobID:     job.ID,
+			Success:   result.Success,
+			Data:      result.Data,
+			Error:     result.Error,
+			LatencyMs: float64(time.Since(start).Milliseconds()),
+		}
+
+		if err := m.transport.SendRPC(ctx, announcement.Requester, workCompleteMethod, completion, nil); err != nil {
+			m.logger.Warn("failed to report job completion", "job_id", job.ID, "error", err)
+		}
+	}()
+}
+
+func (m *MeshCoordinator) registerWorkQueueHandlers() {
+	m.registerRPC(workClaimMethod, func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error) {
+		var req workClaimRequest
+		if err := json.Unmarshal(args, &req); err != nil {
+			return nil, fmt.Errorf("failed to decode job claim: %w", err)
+		}
+		job, err := m.claimWork(peerID, req.JobID)
+		if err != nil {
+			return nil, err
+		}
+		job.Capability = m.heldCapabilityToken(ctx, peerID, executeJobMethod)
+		return job, nil
+	})
+
+	m.registerRPC(workCompleteMethod, func(ctx context.Context, peerID string, args json.RawMessage) (interface{}, error) {
+		var completion workCompletion
+		if err := json.Unmarshal(args, &completion); err != nil {
+			return nil, fmt.Errorf("failed to decode job completion: %w", err)
+		}
+		if err := m.completeWork(peerID, completion); err != nil {
+			return nil, err
+		}
+		return workCompletionAck{Accepted: true}, nil
+	})
+}
+
+func (m *MeshCoordinator) registerWorkQueueGossip() {
+	m.gossip.RegisterHandler(workAnnounceTopic, func(msg *common.GossipMessage) error {
+		payload, ok := msg.Payload.(map[string]interface{})
+		if !ok {
+			return errors.New("invalid payload type for job_announce")
+		}
+
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+
+		var announcement WorkAnnouncement
+		if err := json.Unmarshal(data, &announcement); err != nil {
+			return err
+		}
+		if announcement.Requester != msg.Sender {
+			return errors.New("job announcement requester does not match sender")
+		}
+
+		m.handleWorkAnnouncement(announcement)
+		return nil
+	})
+}
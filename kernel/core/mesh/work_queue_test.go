@@ -0,0 +1,220 @@
+package mesh
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
+	"github.com/nmxmxh/inos_v1/kernel/threads/foundation"
+)
+
+func waitForPendingWork(t *testing.T, coord *MeshCoordinator, jobID string) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		coord.pendingWorkMu.Lock()
+		_, ok := coord.pendingWork[jobID]
+		coord.pendingWorkMu.Unlock()
+		if ok {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("job %s never queued", jobID)
+}
+
+func TestMeshCoordinator_WorkQueueClaimAndComplete(t *testing.T) {
+	tr := &MockTransport{nodeID: "requester"}
+	coord := NewMeshCoordinator("requester", "us-east", tr, nil)
+
+	type outcome struct {
+		result *foundation.Result
+		err    error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		res, err := coord.SubmitWork(context.Background(), &foundation.Job{ID: "job-1", Operation: "hash", Data: []byte("abc")})
+		done <- outcome{res, err}
+	}()
+	waitForPendingWork(t, coord, "job-1")
+
+	claim := tr.registeredRPCHandlers[workClaimMethod]
+	complete := tr.registeredRPCHandlers[workCompleteMethod]
+
+	raw, err := claim(context.Background(), "worker-1", json.RawMessage(`{"job_id":"job-1"}`))
+	if err != nil {
+		t.Fatalf("claim failed: %v", err)
+	}
+	if job := raw.(*workJob); string(job.Data) != "abc" {
+		t.Fatalf("unexpected job payload %q", job.Data)
+	}
+
+	if _, err := claim(context.Background(), "worker-2", json.RawMessage(`{"job_id":"job-1"}`)); err == nil {
+		t.Fatal("expected second claim to be rejected")
+	}
+
+	args, _ := json.Marshal(workCompletion{JobID: "job-1", Success: true, Data: []byte("digest")})
+	if _, err := complete(context.Background(), "worker-2", args); err == nil {
+		t.Fatal("expected completion from non-claimant to be rejected")
+	}
+	if _, err := complete(context.Background(), "worker-1", args); err != nil {
+		t.Fatalf("completion failed: %v", err)
+	}
+
+	out := <-done
+	if out.err != nil {
+		t.Fatalf("SubmitWork failed: %v", out.err)
+	}
+	if !out.result.Success || string(out.result.Data) != "digest" {
+		t.Fatalf("unexpected result %+v", out.result)
+	}
+
+	share, ok := coord.ledger.GetWorkShare("worker-1")
+	if !ok || share.Completed != 1 || share.CreditsEarned == 0 {
+		t.Fatalf("expected fairness accounting for worker-1, got %+v", share)
+	}
+	if active := coord.activeJobs["worker-1"]; active != 0 {
+		t.Fatalf("expected concurrency slot released, got %d", active)
+	}
+}
+
+func TestMeshCoordinator_WorkQueueEnforcesPerPeerLimit(t *testing.T) {
+	tr := &MockTransport{nodeID: "requester"}
+	coord := NewMeshCoordinator("requester", "us-east", tr, nil)
+	coord.config.WorkQueue.MaxJobsPerPeer = 1
+
+	for _, id := range []string{"a", "b"} {
+		coord.pendingWork[id] = &workItem{
+			job:       &foundation.Job{ID: id},
+			createdAt: time.Now(),
+			claimed:   make(chan struct{}),
+			done:      make(chan *foundation.Result, 1),
+		}
+	}
+
+	if _, err := coord.claimWork("worker", "a"); err != nil {
+		t.Fatalf("first claim failed: %v", err)
+	}
+	if _, err := coord.claimWork("worker", "b"); err == nil {
+		t.Fatal("expected claim beyond per-peer limit to be rejected")
+	}
+	if _, err := coord.claimWork("other", "b"); err != nil {
+		t.Fatalf("other peer should be able to claim: %v", err)
+	}
+}
+
+func TestMeshCoordinator_RefusedJobIsNotOfferedBackToClaimant(t *testing.T) {
+	tr := &MockTransport{nodeID: "requester"}
+	coord := NewMeshCoordinator("requester", "us-east", tr, nil)
+	coord.pendingWork["job-1"] = &workItem{
+		job:       &foundation.Job{ID: "job-1"},
+		createdAt: time.Now(),
+		claimed:   make(chan struct{}),
+		released:  make(chan struct{}, 1),
+		done:      make(chan *foundation.Result, 1),
+	}
+
+	if _, err := coord.claimWork("refuser", "job-1"); err != nil {
+		t.Fatalf("first claim failed: %v", err)
+	}
+	if err := coord.releaseWork("refuser", "job-1"); err != nil {
+		t.Fatalf("release failed: %v", err)
+	}
+	if _, err := coord.claimWork("refuser", "job-1"); err == nil {
+		t.Fatal("expected a claimant that handed the job back to be refused")
+	}
+	if _, err := coord.claimWork("worker", "job-1"); err != nil {
+		t.Fatalf("another peer should be able to claim: %v", err)
+	}
+}
+
+func TestMeshCoordinator_SubmitWorkTimesOutWithoutClaim(t *testing.T) {
+	tr := &MockTransport{nodeID: "requester"}
+	coord := NewMeshCoordinator("requester", "us-east", tr, nil)
+	coord.config.WorkQueue.ClaimTimeout = 20 * time.Millisecond
+	coord.config.WorkQueue.AnnounceInterval = 5 * time.Millisecond
+
+	_, err := coord.SubmitWork(context.Background(), &foundation.Job{ID: "lonely"})
+	if err != ErrNoWorkClaimed {
+		t.Fatalf("expected ErrNoWorkClaimed, got %v", err)
+	}
+}
+
+func TestMeshCoordinator_WorkQueueIsOptIn(t *testing.T) {
+	if DefaultCoordinatorConfig().WorkQueue.Enabled {
+		t.Fatal("expected the pull-based work queue disabled by default")
+	}
+
+	tr := &MockTransport{nodeID: "worker", rpcHandlers: make(map[string]func(args interface{}) (interface{}, error))}
+	coord := NewMeshCoordinator("worker", "us-east", tr, nil)
+	coord.SetDispatcher(&mockDispatcher{})
+	claimed := make(chan struct{}, 1)
+	tr.rpcHandlers[workClaimMethod] = func(args interface{}) (interface{}, error) {
+		claimed <- struct{}{}
+		return nil, errors.New("test claim")
+	}
+	coord.handleWorkAnnouncement(WorkAnnouncement{JobID: "job-1", Requester: "peer-1"})
+	if n := coord.localWorkers.Load(); n != 0 {
+		t.Fatalf("expected no pulled work without opting in, got %d workers", n)
+	}
+	select {
+	case <-claimed:
+		t.Fatal("node claimed announced work without opting in")
+	default:
+	}
+}
+
+func TestMeshCoordinator_PulledWorkRequiresCapability(t *testing.T) {
+	coord, tr := newDelegationTestCoordinator(t)
+	coord.config.WorkQueue.Enabled = true
+	ran := make(chan string, 2)
+	coord.SetDispatcher(&mockDispatcher{run: func(job *foundation.Job) *foundation.Result {
+		ran <- job.ID
+		return &foundation.Result{JobID: job.ID, Success: true}
+	}})
+
+	var capability string
+	tr.rpcHandlers[workClaimMethod] = func(args interface{}) (interface{}, error) {
+		id := args.(workClaimRequest).JobID
+		return &workJob{ID: id, Operation: "noop", Capability: capability}, nil
+	}
+	released := make(chan string, 1)
+	tr.rpcHandlers[workReleaseMethod] = func(args interface{}) (interface{}, error) {
+		released <- args.(workReleaseRequest).JobID
+		return workCompletionAck{Accepted: true}, nil
+	}
+	completed := make(chan string, 1)
+	tr.rpcHandlers[workCompleteMethod] = func(args interface{}) (interface{}, error) {
+		completed <- args.(workCompletion).JobID
+		return workCompletionAck{Accepted: true}, nil
+	}
+
+	coord.handleWorkAnnouncement(WorkAnnouncement{JobID: "bare", Requester: "peer-1"})
+	select {
+	case id := <-released:
+		if id != "bare" {
+			t.Fatalf("released %s, expected bare", id)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("pulled job without a capability was not released")
+	}
+	select {
+	case id := <-ran:
+		t.Fatalf("pulled job %s ran without a capability", id)
+	default:
+	}
+
+	capability = common.CapabilityFromContext(capabilityContextFor(t, coord, "peer-1", executeJobMethod))
+	coord.handleWorkAnnouncement(WorkAnnouncement{JobID: "granted", Requester: "peer-1"})
+	select {
+	case id := <-completed:
+		if id != "granted" {
+			t.Fatalf("completed %s, expected granted", id)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("pulled job with a valid capability did not complete")
+	}
+}
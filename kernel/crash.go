@@ -0,0 +1,148 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"syscall/js"
+	"time"
+
+	sab_layout "github.com/nmxmxh/inos_v1/kernel/threads/sab"
+	"github.com/nmxmxh/inos_v1/kernel/threads/supervisor"
+	"github.com/nmxmxh/inos_v1/kernel/utils"
+)
+
+// crashLogLines is how many recent log lines a crash report carries.
+const crashLogLines = 64
+
+// captureCrashReport snapshots kernel state after a panic. The full report is
+// kept in memory for jsGetLastCrashReport; a compacted copy goes to the SAB
+// crash dump region so it outlives this kernel instance.
+func (k *Kernel) captureCrashReport(reason interface{}, stack string) []byte {
+	// A panic while collecting state must not mask the original one
+	defer func() {
+		if r := recover(); r != nil {
+			k.logger.Error("Crash report capture failed", utils.Any("reason", r))
+		}
+	}()
+
+	report := &supervisor.CrashReport{
+		Reason: fmt.Sprintf("%v", reason),
+		Time:   time.Now(),
+		State:  k.StateName(),
+		Uptime: time.Since(k.startTime).String(),
+		Stack:  stack,
+		Logs:   utils.RecentLogs(crashLogLines),
+	}
+
+	var bridge *supervisor.SABBridge
+	if k.supervisor != nil {
+		stats := k.supervisor.GetStats()
+		report.Supervisor = map[string]interface{}{
+			"activeThreads":    stats.ActiveThreads,
+			"totalMessages":    stats.TotalMessages,
+			"failedThreads":    stats.FailedThreads,
+			"restartedThreads": stats.RestartedThreads,
+		}
+		bridge = k.supervisor.GetBridge()
+	}
+	if bridge != nil {
+		report.Rings = bridge.RingStats()
+		report.Epochs = make([]int32, sab_layout.SIZE_ATOMIC_FLAGS/4)
+		for i := range report.Epochs {
+			report.Epochs[i] = bridge.ReadAtomicI32(uint32(i))
+		}
+		if previous := readCrashDump(bridge); previous != nil {
+			report.Sequence = previous.Sequence
+		}
+	}
+	report.Sequence++
+	if k.meshCoordinator != nil {
+		report.Mesh = k.meshCoordinator.GetTelemetry()
+	}
+
+	full, err := json.Marshal(report)
+	if err != nil {
+		k.logger.Error("Failed to encode crash report", utils.Err(err))
+		return nil
+	}
+	k.crashMu.Lock()
+	k.lastCrash = full
+	k.crashMu.Unlock()
+
+	if bridge != nil {
+		if err := writeCrashDump(bridge, report); err != nil {
+			k.logger.Warn("Failed to write crash dump to SAB", utils.Err(err))
+		}
+	}
+	return full
+}
+
+func writeCrashDump(bridge *supervisor.SABBridge, report *supervisor.CrashReport) error {
+	compact, err := report.Compact(int(sab_layout.MAX_CRASH_REPORT_SIZE))
+	if err != nil {
+		return err
+	}
+	dump, err := sab_layout.EncodeCrashDump(report.Sequence, compact)
+	if err != nil {
+		return err
+	}
+	return bridge.WriteRaw(sab_layout.OFFSET_CRASH_DUMP, dump)
+}
+
+// readCrashDump returns the crash dump in the SAB, or nil if there is none.
+func readCrashDump(bridge *supervisor.SABBridge) *sab_layout.CrashDump {
+	buf, err := bridge.ReadRaw(sab_layout.OFFSET_CRASH_DUMP, sab_layout.SIZE_CRASH_DUMP)
+	if err != nil {
+		return nil
+	}
+	dump, err := sab_layout.DecodeCrashDump(buf)
+	if err != nil {
+		return nil
+	}
+	return dump
+}
+
+// lastCrashReport returns this instance's last crash report, falling back to
+// the compacted dump a previous kernel left in the SAB.
+func (k *Kernel) lastCrashReport() ([]byte, string) {
+	k.crashMu.Lock()
+	report := k.lastCrash
+	k.crashMu.Unlock()
+	if report != nil {
+		return report, "memory"
+	}
+
+	if bridge := kernelBridge(); bridge != nil {
+		if dump := readCrashDump(bridge); dump != nil {
+			return dump.Report, "sab"
+		}
+	}
+	return nil, ""
+}
+
+// jsGetLastCrashReport returns the last crash report as JSON text and as a
+// Blob the host can offer for download.
+func jsGetLastCrashReport(this js.Value, args []js.Value) interface{} {
+	if kernelInstance == nil {
+		return js.ValueOf(map[string]interface{}{"error": "kernel not initialized"})
+	}
+
+	report, source := kernelInstance.lastCrashReport()
+	if report == nil {
+		return js.ValueOf(map[string]interface{}{"error": "no crash report recorded"})
+	}
+
+	blob := js.Global().Get("Blob").New(
+		[]interface{}{string(report)},
+		map[string]interface{}{"type": "application/json"},
+	)
+	return js.ValueOf(map[string]interface{}{
+		"success": true,
+		"source":  source,
+		"report":  string(report),
+		"blob":    blob,
+	})
+}
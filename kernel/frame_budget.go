@@ -0,0 +1,70 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/threads/foundation"
+	"github.com/nmxmxh/inos_v1/kernel/utils"
+)
+
+// startFrameBudget creates the per-frame budget. The SAB bridge closes a
+// frame on every physics pulse; when frames keep overrunning, the mesh
+// defers its background work until they recover.
+func (k *Kernel) startFrameBudget() {
+	cfg := foundation.DefaultFrameBudgetConfig()
+	if k.config.FrameBudget > 0 {
+		cfg.Target = k.config.FrameBudget
+	}
+	cfg.OnDeferChange = func(deferring bool) {
+		if deferring {
+			k.logger.Warn("Frame budget blown; deferring background mesh work",
+				utils.Duration("target", cfg.Target))
+		} else {
+			k.logger.Info("Frame budget recovered; resuming background mesh work")
+		}
+		k.notifyHost("kernel:frame_budget", map[string]interface{}{"deferring": deferring})
+	}
+	k.frameBudget = foundation.NewFrameBudget(cfg)
+	k.attachFrameBudget()
+}
+
+// attachFrameBudget wires the budget into the current bridge and the mesh.
+// Failover calls it again for the promoted supervisor's bridge.
+func (k *Kernel) attachFrameBudget() {
+	if bridge := k.supervisor.GetBridge(); bridge != nil {
+		bridge.SetFrameBudget(k.frameBudget)
+	}
+	if k.meshCoordinator != nil {
+		k.meshCoordinator.SetFrameBudget(k.frameBudget)
+	}
+}
+
+// frameBudgetStatsMap renders budget stats for getKernelStats, in ms.
+func frameBudgetStatsMap(stats foundation.FrameBudgetStats) map[string]interface{} {
+	ms := func(d time.Duration) float64 { return float64(d.Microseconds()) / 1000 }
+
+	average := map[string]interface{}{}
+	for name, d := range stats.Average {
+		average[name] = ms(d)
+	}
+	last := map[string]interface{}{}
+	for name, d := range stats.Last.Subsystems {
+		last[name] = ms(d)
+	}
+	last["other"] = ms(stats.Last.Other)
+
+	return map[string]interface{}{
+		"targetMs":    ms(stats.Target),
+		"frames":      float64(stats.Frames),
+		"overBudget":  float64(stats.OverBudgetFrames),
+		"deferring":   stats.Deferring,
+		"deferEvents": float64(stats.DeferEvents),
+		"avgFrameMs":  ms(stats.AverageTotal),
+		"avgMs":       average,
+		"lastFrameMs": ms(stats.Last.Total),
+		"lastMs":      last,
+	}
+}
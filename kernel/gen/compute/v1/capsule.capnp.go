@@ -39,12 +39,12 @@ type Compute_JobRequest struct{ capnp.Struct }
 const Compute_JobRequest_TypeID = 0xc3a67580d014f041
 
 func NewCompute_JobRequest(s *capnp.Segment) (Compute_JobRequest, error) {
-	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 24, PointerCount: 6})
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 40, PointerCount: 6})
 	return Compute_JobRequest{st}, err
 }
 
 func NewRootCompute_JobRequest(s *capnp.Segment) (Compute_JobRequest, error) {
-	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 24, PointerCount: 6})
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 40, PointerCount: 6})
 	return Compute_JobRequest{st}, err
 }
 
@@ -203,12 +203,28 @@ func (s Compute_JobRequest) NewMetadata() (base.Base_Metadata, error) {
 	return ss, err
 }
 
+func (s Compute_JobRequest) FuelLimit() uint64 {
+	return s.Struct.Uint64(24)
+}
+
+func (s Compute_JobRequest) SetFuelLimit(v uint64) {
+	s.Struct.SetUint64(24, v)
+}
+
+func (s Compute_JobRequest) MemoryLimit() uint64 {
+	return s.Struct.Uint64(32)
+}
+
+func (s Compute_JobRequest) SetMemoryLimit(v uint64) {
+	s.Struct.SetUint64(32, v)
+}
+
 // Compute_JobRequest_List is a list of Compute_JobRequest.
 type Compute_JobRequest_List struct{ capnp.List }
 
 // NewCompute_JobRequest creates a new list of Compute_JobRequest.
 func NewCompute_JobRequest_List(s *capnp.Segment, sz int32) (Compute_JobRequest_List, error) {
-	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 24, PointerCount: 6}, sz)
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 40, PointerCount: 6}, sz)
 	return Compute_JobRequest_List{l}, err
 }
 
@@ -3833,12 +3833,12 @@ type PeerCapability struct{ capnp.Struct }
 const PeerCapability_TypeID = 0xc7faf55c26776162
 
 func NewPeerCapability(s *capnp.Segment) (PeerCapability, error) {
-	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 24, PointerCount: 6})
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 32, PointerCount: 7})
 	return PeerCapability{st}, err
 }
 
 func NewRootPeerCapability(s *capnp.Segment) (PeerCapability, error) {
-	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 24, PointerCount: 6})
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 32, PointerCount: 7})
 	return PeerCapability{st}, err
 }
 
@@ -4038,12 +4038,45 @@ func (s PeerCapability) NewRuntimeCaps() (system.Runtime_RuntimeCapabilities, er
 	return ss, err
 }
 
+func (s PeerCapability) SchemaVersion() uint16 {
+	return s.Struct.Uint16(24)
+}
+
+func (s PeerCapability) SetSchemaVersion(v uint16) {
+	s.Struct.SetUint16(24, v)
+}
+
+func (s PeerCapability) Hardware() (HardwareDescriptor, error) {
+	p, err := s.Struct.Ptr(6)
+	return HardwareDescriptor{Struct: p.Struct()}, err
+}
+
+func (s PeerCapability) HasHardware() bool {
+	p, err := s.Struct.Ptr(6)
+	return p.IsValid() || err != nil
+}
+
+func (s PeerCapability) SetHardware(v HardwareDescriptor) error {
+	return s.Struct.SetPtr(6, v.Struct.ToPtr())
+}
+
+// NewHardware sets the hardware field to a newly
+// allocated HardwareDescriptor struct, preferring placement in s's segment.
+func (s PeerCapability) NewHardware() (HardwareDescriptor, error) {
+	ss, err := NewHardwareDescriptor(s.Struct.Segment())
+	if err != nil {
+		return HardwareDescriptor{}, err
+	}
+	err = s.Struct.SetPtr(6, ss.Struct.ToPtr())
+	return ss, err
+}
+
 // PeerCapability_List is a list of PeerCapability.
 type PeerCapability_List struct{ capnp.List }
 
 // NewPeerCapability creates a new list of PeerCapability.
 func NewPeerCapability_List(s *capnp.Segment, sz int32) (PeerCapability_List, error) {
-	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 24, PointerCount: 6}, sz)
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 32, PointerCount: 7}, sz)
 	return PeerCapability_List{l}, err
 }
 
@@ -4072,6 +4105,10 @@ func (p PeerCapability_Promise) RuntimeCaps() system.Runtime_RuntimeCapabilities
 	return system.Runtime_RuntimeCapabilities_Promise{Pipeline: p.Pipeline.GetPipeline(5)}
 }
 
+func (p PeerCapability_Promise) Hardware() HardwareDescriptor_Promise {
+	return HardwareDescriptor_Promise{Pipeline: p.Pipeline.GetPipeline(6)}
+}
+
 type GeoCoordinates struct{ capnp.Struct }
 
 // GeoCoordinates_TypeID is the unique identifier for the type GeoCoordinates.
@@ -4139,6 +4176,331 @@ func (p GeoCoordinates_Promise) Struct() (GeoCoordinates, error) {
 	return GeoCoordinates{s}, err
 }
 
+type HardwareDescriptor struct{ capnp.Struct }
+
+// HardwareDescriptor_TypeID is the unique identifier for the type HardwareDescriptor.
+const HardwareDescriptor_TypeID = 0x9976bf0bc78636df
+
+func NewHardwareDescriptor(s *capnp.Segment) (HardwareDescriptor, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 24, PointerCount: 3})
+	return HardwareDescriptor{st}, err
+}
+
+func NewRootHardwareDescriptor(s *capnp.Segment) (HardwareDescriptor, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 24, PointerCount: 3})
+	return HardwareDescriptor{st}, err
+}
+
+func ReadRootHardwareDescriptor(msg *capnp.Message) (HardwareDescriptor, error) {
+	root, err := msg.RootPtr()
+	return HardwareDescriptor{root.Struct()}, err
+}
+
+func (s HardwareDescriptor) String() string {
+	str, _ := text.Marshal(0x9976bf0bc78636df, s.Struct)
+	return str
+}
+
+func (s HardwareDescriptor) CpuCores() uint16 {
+	return s.Struct.Uint16(0)
+}
+
+func (s HardwareDescriptor) SetCpuCores(v uint16) {
+	s.Struct.SetUint16(0, v)
+}
+
+func (s HardwareDescriptor) CpuArch() (string, error) {
+	p, err := s.Struct.Ptr(0)
+	return p.Text(), err
+}
+
+func (s HardwareDescriptor) HasCpuArch() bool {
+	p, err := s.Struct.Ptr(0)
+	return p.IsValid() || err != nil
+}
+
+func (s HardwareDescriptor) CpuArchBytes() ([]byte, error) {
+	p, err := s.Struct.Ptr(0)
+	return p.TextBytes(), err
+}
+
+func (s HardwareDescriptor) SetCpuArch(v string) error {
+	return s.Struct.SetText(0, v)
+}
+
+func (s HardwareDescriptor) Simd() (capnp.TextList, error) {
+	p, err := s.Struct.Ptr(1)
+	return capnp.TextList{List: p.List()}, err
+}
+
+func (s HardwareDescriptor) HasSimd() bool {
+	p, err := s.Struct.Ptr(1)
+	return p.IsValid() || err != nil
+}
+
+func (s HardwareDescriptor) SetSimd(v capnp.TextList) error {
+	return s.Struct.SetPtr(1, v.List.ToPtr())
+}
+
+// NewSimd sets the simd field to a newly
+// allocated capnp.TextList, preferring placement in s's segment.
+func (s HardwareDescriptor) NewSimd(n int32) (capnp.TextList, error) {
+	l, err := capnp.NewTextList(s.Struct.Segment(), n)
+	if err != nil {
+		return capnp.TextList{}, err
+	}
+	err = s.Struct.SetPtr(1, l.List.ToPtr())
+	return l, err
+}
+
+func (s HardwareDescriptor) MemoryBytes() uint64 {
+	return s.Struct.Uint64(8)
+}
+
+func (s HardwareDescriptor) SetMemoryBytes(v uint64) {
+	s.Struct.SetUint64(8, v)
+}
+
+func (s HardwareDescriptor) StorageFreeBytes() uint64 {
+	return s.Struct.Uint64(16)
+}
+
+func (s HardwareDescriptor) SetStorageFreeBytes(v uint64) {
+	s.Struct.SetUint64(16, v)
+}
+
+func (s HardwareDescriptor) Gpu() (GpuDescriptor, error) {
+	p, err := s.Struct.Ptr(2)
+	return GpuDescriptor{Struct: p.Struct()}, err
+}
+
+func (s HardwareDescriptor) HasGpu() bool {
+	p, err := s.Struct.Ptr(2)
+	return p.IsValid() || err != nil
+}
+
+func (s HardwareDescriptor) SetGpu(v GpuDescriptor) error {
+	return s.Struct.SetPtr(2, v.Struct.ToPtr())
+}
+
+// NewGpu sets the gpu field to a newly
+// allocated GpuDescriptor struct, preferring placement in s's segment.
+func (s HardwareDescriptor) NewGpu() (GpuDescriptor, error) {
+	ss, err := NewGpuDescriptor(s.Struct.Segment())
+	if err != nil {
+		return GpuDescriptor{}, err
+	}
+	err = s.Struct.SetPtr(2, ss.Struct.ToPtr())
+	return ss, err
+}
+
+// HardwareDescriptor_List is a list of HardwareDescriptor.
+type HardwareDescriptor_List struct{ capnp.List }
+
+// NewHardwareDescriptor creates a new list of HardwareDescriptor.
+func NewHardwareDescriptor_List(s *capnp.Segment, sz int32) (HardwareDescriptor_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 24, PointerCount: 3}, sz)
+	return HardwareDescriptor_List{l}, err
+}
+
+func (s HardwareDescriptor_List) At(i int) HardwareDescriptor {
+	return HardwareDescriptor{s.List.Struct(i)}
+}
+
+func (s HardwareDescriptor_List) Set(i int, v HardwareDescriptor) error {
+	return s.List.SetStruct(i, v.Struct)
+}
+
+func (s HardwareDescriptor_List) String() string {
+	str, _ := text.MarshalList(0x9976bf0bc78636df, s.List)
+	return str
+}
+
+// HardwareDescriptor_Promise is a wrapper for a HardwareDescriptor promised by a client call.
+type HardwareDescriptor_Promise struct{ *capnp.Pipeline }
+
+func (p HardwareDescriptor_Promise) Struct() (HardwareDescriptor, error) {
+	s, err := p.Pipeline.Struct()
+	return HardwareDescriptor{s}, err
+}
+
+func (p HardwareDescriptor_Promise) Gpu() GpuDescriptor_Promise {
+	return GpuDescriptor_Promise{Pipeline: p.Pipeline.GetPipeline(2)}
+}
+
+type GpuDescriptor struct{ capnp.Struct }
+
+// GpuDescriptor_TypeID is the unique identifier for the type GpuDescriptor.
+const GpuDescriptor_TypeID = 0xbc50c3436c5acb49
+
+func NewGpuDescriptor(s *capnp.Segment) (GpuDescriptor, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 32, PointerCount: 4})
+	return GpuDescriptor{st}, err
+}
+
+func NewRootGpuDescriptor(s *capnp.Segment) (GpuDescriptor, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 32, PointerCount: 4})
+	return GpuDescriptor{st}, err
+}
+
+func ReadRootGpuDescriptor(msg *capnp.Message) (GpuDescriptor, error) {
+	root, err := msg.RootPtr()
+	return GpuDescriptor{root.Struct()}, err
+}
+
+func (s GpuDescriptor) String() string {
+	str, _ := text.Marshal(0xbc50c3436c5acb49, s.Struct)
+	return str
+}
+
+func (s GpuDescriptor) Vendor() (string, error) {
+	p, err := s.Struct.Ptr(0)
+	return p.Text(), err
+}
+
+func (s GpuDescriptor) HasVendor() bool {
+	p, err := s.Struct.Ptr(0)
+	return p.IsValid() || err != nil
+}
+
+func (s GpuDescriptor) VendorBytes() ([]byte, error) {
+	p, err := s.Struct.Ptr(0)
+	return p.TextBytes(), err
+}
+
+func (s GpuDescriptor) SetVendor(v string) error {
+	return s.Struct.SetText(0, v)
+}
+
+func (s GpuDescriptor) Architecture() (string, error) {
+	p, err := s.Struct.Ptr(1)
+	return p.Text(), err
+}
+
+func (s GpuDescriptor) HasArchitecture() bool {
+	p, err := s.Struct.Ptr(1)
+	return p.IsValid() || err != nil
+}
+
+func (s GpuDescriptor) ArchitectureBytes() ([]byte, error) {
+	p, err := s.Struct.Ptr(1)
+	return p.TextBytes(), err
+}
+
+func (s GpuDescriptor) SetArchitecture(v string) error {
+	return s.Struct.SetText(1, v)
+}
+
+func (s GpuDescriptor) MaxBufferSize() uint64 {
+	return s.Struct.Uint64(0)
+}
+
+func (s GpuDescriptor) SetMaxBufferSize(v uint64) {
+	s.Struct.SetUint64(0, v)
+}
+
+func (s GpuDescriptor) MaxStorageBufferBindingSize() uint64 {
+	return s.Struct.Uint64(8)
+}
+
+func (s GpuDescriptor) SetMaxStorageBufferBindingSize(v uint64) {
+	s.Struct.SetUint64(8, v)
+}
+
+func (s GpuDescriptor) MaxComputeWorkgroupStorageSize() uint32 {
+	return s.Struct.Uint32(16)
+}
+
+func (s GpuDescriptor) SetMaxComputeWorkgroupStorageSize(v uint32) {
+	s.Struct.SetUint32(16, v)
+}
+
+func (s GpuDescriptor) MaxComputeInvocationsPerWorkgroup() uint32 {
+	return s.Struct.Uint32(20)
+}
+
+func (s GpuDescriptor) SetMaxComputeInvocationsPerWorkgroup(v uint32) {
+	s.Struct.SetUint32(20, v)
+}
+
+func (s GpuDescriptor) MaxComputeWorkgroupsPerDimension() uint32 {
+	return s.Struct.Uint32(24)
+}
+
+func (s GpuDescriptor) SetMaxComputeWorkgroupsPerDimension(v uint32) {
+	s.Struct.SetUint32(24, v)
+}
+
+func (s GpuDescriptor) Features() (capnp.TextList, error) {
+	p, err := s.Struct.Ptr(2)
+	return capnp.TextList{List: p.List()}, err
+}
+
+func (s GpuDescriptor) HasFeatures() bool {
+	p, err := s.Struct.Ptr(2)
+	return p.IsValid() || err != nil
+}
+
+func (s GpuDescriptor) SetFeatures(v capnp.TextList) error {
+	return s.Struct.SetPtr(2, v.List.ToPtr())
+}
+
+// NewFeatures sets the features field to a newly
+// allocated capnp.TextList, preferring placement in s's segment.
+func (s GpuDescriptor) NewFeatures(n int32) (capnp.TextList, error) {
+	l, err := capnp.NewTextList(s.Struct.Segment(), n)
+	if err != nil {
+		return capnp.TextList{}, err
+	}
+	err = s.Struct.SetPtr(2, l.List.ToPtr())
+	return l, err
+}
+
+func (s GpuDescriptor) Device() (string, error) {
+	p, err := s.Struct.Ptr(3)
+	return p.Text(), err
+}
+
+func (s GpuDescriptor) HasDevice() bool {
+	p, err := s.Struct.Ptr(3)
+	return p.IsValid() || err != nil
+}
+
+func (s GpuDescriptor) DeviceBytes() ([]byte, error) {
+	p, err := s.Struct.Ptr(3)
+	return p.TextBytes(), err
+}
+
+func (s GpuDescriptor) SetDevice(v string) error {
+	return s.Struct.SetText(3, v)
+}
+
+// GpuDescriptor_List is a list of GpuDescriptor.
+type GpuDescriptor_List struct{ capnp.List }
+
+// NewGpuDescriptor creates a new list of GpuDescriptor.
+func NewGpuDescriptor_List(s *capnp.Segment, sz int32) (GpuDescriptor_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 32, PointerCount: 4}, sz)
+	return GpuDescriptor_List{l}, err
+}
+
+func (s GpuDescriptor_List) At(i int) GpuDescriptor { return GpuDescriptor{s.List.Struct(i)} }
+
+func (s GpuDescriptor_List) Set(i int, v GpuDescriptor) error { return s.List.SetStruct(i, v.Struct) }
+
+func (s GpuDescriptor_List) String() string {
+	str, _ := text.MarshalList(0xbc50c3436c5acb49, s.List)
+	return str
+}
+
+// GpuDescriptor_Promise is a wrapper for a GpuDescriptor promised by a client call.
+type GpuDescriptor_Promise struct{ *capnp.Pipeline }
+
+func (p GpuDescriptor_Promise) Struct() (GpuDescriptor, error) {
+	s, err := p.Pipeline.Struct()
+	return GpuDescriptor{s}, err
+}
+
 type ConnectionState uint16
 
 // ConnectionState_TypeID is the unique identifier for the type ConnectionState.
@@ -8,134 +8,250 @@ import (
 
 // Constants defined in sab_layout-go-temp.capnp.
 const (
-	OffsetSystemBase         = uint32(0)
-	SabSizeDefault           = uint32(33554432)
-	SabSizeMin               = uint32(33554432)
-	SabSizeMax               = uint32(1073741824)
-	SabSizeLight             = uint32(33554432)
-	SabSizeModerate          = uint32(67108864)
-	SabSizeHeavy             = uint32(134217728)
-	SabSizeDedicated         = uint32(268435456)
-	OffsetAtomicFlags        = uint32(0)
-	SizeAtomicFlags          = uint32(128)
-	OffsetSupervisorAlloc    = uint32(128)
-	SizeSupervisorAlloc      = uint32(176)
-	OffsetRegistryLock       = uint32(304)
-	SizeRegistryLock         = uint32(16)
-	OffsetModuleRegistry     = uint32(320)
-	SizeModuleRegistry       = uint32(6144)
-	ModuleEntrySize          = uint32(96)
-	MaxModulesInline         = uint32(64)
-	MaxModulesTotal          = uint32(1024)
-	OffsetBloomFilter        = uint32(6464)
-	SizeBloomFilter          = uint32(256)
-	OffsetSupervisorHeaders  = uint32(8192)
-	SizeSupervisorHeaders    = uint32(4096)
-	SupervisorHeaderSize     = uint32(128)
-	MaxSupervisorsInline     = uint32(32)
-	MaxSupervisorsTotal      = uint32(256)
-	OffsetSyscallTable       = uint32(12288)
-	SizeSyscallTable         = uint32(4096)
-	OffsetMeshMetrics        = uint32(16384)
-	SizeMeshMetrics          = uint32(256)
-	OffsetGlobalAnalytics    = uint32(16640)
-	SizeGlobalAnalytics      = uint32(256)
-	OffsetEconomics          = uint32(16896)
-	SizeEconomics            = uint32(15872)
-	OffsetIdentityRegistry   = uint32(32768)
-	SizeIdentityRegistry     = uint32(16384)
-	OffsetSocialGraph        = uint32(49152)
-	SizeSocialGraph          = uint32(16384)
-	OffsetPatternExchange    = uint32(65536)
-	SizePatternExchange      = uint32(65536)
-	PatternEntrySize         = uint32(64)
-	MaxPatternsInline        = uint32(1024)
-	MaxPatternsTotal         = uint32(16384)
-	OffsetJobHistory         = uint32(131072)
-	SizeJobHistory           = uint32(131072)
-	OffsetCoordination       = uint32(262144)
-	SizeCoordination         = uint32(65536)
-	OffsetInboxOutbox        = uint32(327680)
-	SizeInboxOutbox          = uint32(1048576)
-	OffsetInboxBase          = uint32(327680)
-	SizeInboxTotal           = uint32(524288)
-	OffsetOutboxHostBase     = uint32(851968)
-	SizeOutboxHostTotal      = uint32(262144)
-	OffsetOutboxKernelBase   = uint32(1114112)
-	SizeOutboxKernelTotal    = uint32(262144)
-	OffsetArena              = uint32(1376256)
-	OffsetArenaMetadata      = uint32(1376256)
-	SizeArenaMetadata        = uint32(65536)
-	OffsetDiagnostics        = uint32(1376256)
-	SizeDiagnostics          = uint32(4096)
-	OffsetBridgeMetrics      = uint32(1378304)
-	SizeBridgeMetrics        = uint32(256)
-	OffsetArenaRequestQueue  = uint32(1380352)
-	OffsetArenaResponseQueue = uint32(1384448)
-	ArenaQueueEntrySize      = uint32(64)
-	MaxArenaRequests         = uint32(64)
-	OffsetMeshEventQueue     = uint32(1388544)
-	SizeMeshEventQueue       = uint32(53248)
-	MeshEventSlotSize        = uint32(1024)
-	MeshEventSlotCount       = uint32(52)
-	OffsetBirdState          = uint32(1441792)
-	SizeBirdState            = uint32(4096)
-	OffsetPingpongControl    = uint32(1445888)
-	SizePingpongControl      = uint32(64)
-	OffsetBirdBufferA        = uint32(1449984)
-	OffsetBirdBufferB        = uint32(3940352)
-	SizeBirdBuffer           = uint32(2360000)
-	BirdStride               = uint32(236)
-	OffsetMatrixBufferA      = uint32(6430720)
-	OffsetMatrixBufferB      = uint32(11673600)
-	SizeMatrixBuffer         = uint32(5120000)
-	MatrixStride             = uint32(64)
-	IdxKernelReady           = uint32(0)
-	IdxInboxDirty            = uint32(1)
-	IdxOutboxHostDirty       = uint32(2)
-	IdxPanicState            = uint32(3)
-	IdxSensorEpoch           = uint32(4)
-	IdxActorEpoch            = uint32(5)
-	IdxStorageEpoch          = uint32(6)
-	IdxSystemEpoch           = uint32(7)
-	IdxSystemPulse           = uint32(8)
-	IdxSystemVisibility      = uint32(9)
-	IdxSystemPowerState      = uint32(10)
-	IdxReservedPulse1        = uint32(11)
-	IdxReservedPulse2        = uint32(12)
-	IdxReservedPulse3        = uint32(13)
-	IdxReservedPulse4        = uint32(14)
-	IdxReservedPulse5        = uint32(15)
-	IdxArenaAllocator        = uint32(16)
-	IdxOutboxMutex           = uint32(17)
-	IdxInboxMutex            = uint32(18)
-	IdxMetricsEpoch          = uint32(19)
-	IdxBirdEpoch             = uint32(20)
-	IdxMatrixEpoch           = uint32(21)
-	IdxPingpongActive        = uint32(22)
-	IdxRegistryEpoch         = uint32(23)
-	IdxEvolutionEpoch        = uint32(24)
-	IdxHealthEpoch           = uint32(25)
-	IdxLearningEpoch         = uint32(26)
-	IdxEconomyEpoch          = uint32(27)
-	IdxBirdCount             = uint32(28)
-	IdxGlobalMetricsEpoch    = uint32(29)
-	IdxOutboxKernelDirty     = uint32(30)
-	IdxContextIdHash         = uint32(31)
-	IdxDelegatedJobEpoch     = uint32(32)
-	IdxUserJobEpoch          = uint32(33)
-	IdxDelegatedChunkEpoch   = uint32(34)
-	IdxMeshEventEpoch        = uint32(35)
-	IdxMeshEventHead         = uint32(36)
-	IdxMeshEventTail         = uint32(37)
-	IdxMeshEventDropped      = uint32(38)
-	SupervisorPoolBase       = uint32(64)
-	SupervisorPoolSize       = uint32(128)
-	ReservedPoolBase         = uint32(128)
-	ReservedPoolSize         = uint32(128)
-	AlignmentCacheLine       = uint32(64)
-	AlignmentPage            = uint32(4096)
-	AlignmentLarge           = uint32(65536)
+	OffsetSystemBase                  = uint32(0)
+	SabSizeDefault                    = uint32(33554432)
+	SabSizeMin                        = uint32(33554432)
+	SabSizeMax                        = uint32(1073741824)
+	SabSizeLight                      = uint32(33554432)
+	SabSizeModerate                   = uint32(67108864)
+	SabSizeHeavy                      = uint32(134217728)
+	SabSizeDedicated                  = uint32(268435456)
+	OffsetAtomicFlags                 = uint32(0)
+	SizeAtomicFlags                   = uint32(128)
+	OffsetSupervisorAlloc             = uint32(128)
+	SizeSupervisorAlloc               = uint32(176)
+	OffsetRegistryLock                = uint32(304)
+	SizeRegistryLock                  = uint32(16)
+	OffsetModuleRegistry              = uint32(320)
+	SizeModuleRegistry                = uint32(6144)
+	ModuleEntrySize                   = uint32(96)
+	MaxModulesInline                  = uint32(64)
+	MaxModulesTotal                   = uint32(1024)
+	OffsetBloomFilter                 = uint32(6464)
+	SizeBloomFilter                   = uint32(256)
+	OffsetLayoutHeader                = uint32(7168)
+	SizeLayoutHeader                  = uint32(1024)
+	LayoutMagic                       = uint32(1397706313)
+	LayoutVersion                     = uint32(131075)
+	LayoutRegionEntrySize             = uint32(28)
+	OffsetSupervisorHeaders           = uint32(8192)
+	SizeSupervisorHeaders             = uint32(4096)
+	SupervisorHeaderSize              = uint32(128)
+	MaxSupervisorsInline              = uint32(32)
+	MaxSupervisorsTotal               = uint32(256)
+	OffsetSyscallTable                = uint32(12288)
+	SizeSyscallTable                  = uint32(4096)
+	OffsetMeshMetrics                 = uint32(16384)
+	SizeMeshMetrics                   = uint32(256)
+	MeshMetricsTotalPeers             = uint32(0)
+	MeshMetricsConnectedPeers         = uint32(4)
+	MeshMetricsDhtEntries             = uint32(8)
+	MeshMetricsGossipRate             = uint32(12)
+	MeshMetricsAvgReputation          = uint32(16)
+	MeshMetricsRegionId               = uint32(20)
+	MeshMetricsBytesSent              = uint32(24)
+	MeshMetricsBytesReceived          = uint32(32)
+	MeshMetricsP50Latency             = uint32(40)
+	MeshMetricsP95Latency             = uint32(44)
+	MeshMetricsConnectionSuccess      = uint32(48)
+	MeshMetricsFetchSuccess           = uint32(52)
+	MeshMetricsLocalChunks            = uint32(56)
+	MeshMetricsTotalChunks            = uint32(60)
+	MeshMetricsSectorId               = uint32(64)
+	MeshMetricsSectorMembers          = uint32(68)
+	MeshMetricsRecordSize             = uint32(72)
+	OffsetGlobalAnalytics             = uint32(16640)
+	SizeGlobalAnalytics               = uint32(256)
+	OffsetEconomics                   = uint32(16896)
+	SizeEconomics                     = uint32(15872)
+	OffsetIdentityRegistry            = uint32(32768)
+	SizeIdentityRegistry              = uint32(16384)
+	OffsetSocialGraph                 = uint32(49152)
+	SizeSocialGraph                   = uint32(16384)
+	OffsetPatternExchange             = uint32(65536)
+	SizePatternExchange               = uint32(65536)
+	PatternEntrySize                  = uint32(64)
+	MaxPatternsInline                 = uint32(1024)
+	MaxPatternsTotal                  = uint32(16384)
+	OffsetJobHistory                  = uint32(131072)
+	SizeJobHistory                    = uint32(131072)
+	OffsetCoordination                = uint32(262144)
+	SizeCoordination                  = uint32(65536)
+	OffsetInboxOutbox                 = uint32(327680)
+	SizeInboxOutbox                   = uint32(1048576)
+	OffsetInboxBase                   = uint32(327680)
+	SizeInboxTotal                    = uint32(524288)
+	OffsetInboxControl                = uint32(327680)
+	SizeInboxControl                  = uint32(65536)
+	OffsetInboxCompute                = uint32(393216)
+	SizeInboxCompute                  = uint32(262144)
+	OffsetInboxBulk                   = uint32(655360)
+	SizeInboxBulk                     = uint32(196608)
+	InboxWeightControl                = uint32(8)
+	InboxWeightCompute                = uint32(4)
+	InboxWeightBulk                   = uint32(1)
+	OffsetOutboxHostBase              = uint32(851968)
+	SizeOutboxHostTotal               = uint32(262144)
+	OffsetOutboxKernelBase            = uint32(1114112)
+	SizeOutboxKernelTotal             = uint32(262144)
+	OffsetArena                       = uint32(1376256)
+	OffsetArenaMetadata               = uint32(1376256)
+	SizeArenaMetadata                 = uint32(65536)
+	OffsetDiagnostics                 = uint32(1376256)
+	SizeDiagnostics                   = uint32(4096)
+	OffsetBridgeMetrics               = uint32(1378304)
+	SizeBridgeMetrics                 = uint32(256)
+	OffsetFrameSchedule               = uint32(1378560)
+	SizeFrameSchedule                 = uint32(64)
+	OffsetHealthBlock                 = uint32(1378816)
+	SizeHealthBlock                   = uint32(1024)
+	HealthBlockVersion                = uint32(1)
+	HealthHeaderSequence              = uint32(0)
+	HealthHeaderVersion               = uint32(4)
+	HealthHeaderSectionCount          = uint32(8)
+	HealthHeaderSectionSize           = uint32(12)
+	HealthHeaderWrittenAt             = uint32(16)
+	HealthHeaderSize                  = uint32(32)
+	HealthSectionSize                 = uint32(96)
+	HealthSectionCount                = uint32(6)
+	HealthSectionMesh                 = uint32(0)
+	HealthSectionGossip               = uint32(1)
+	HealthSectionDht                  = uint32(2)
+	HealthSectionTransport            = uint32(3)
+	HealthSectionEconomy              = uint32(4)
+	HealthSectionSupervisor           = uint32(5)
+	HealthSectionStatus               = uint32(0)
+	HealthSectionScore                = uint32(4)
+	HealthMeshTotalPeers              = uint32(8)
+	HealthMeshConnectedPeers          = uint32(12)
+	HealthMeshLocalChunks             = uint32(16)
+	HealthMeshChunksAvailable         = uint32(20)
+	HealthMeshAvgReputation           = uint32(24)
+	HealthMeshSectorId                = uint32(28)
+	HealthMeshSectorMembers           = uint32(32)
+	HealthMeshRegionId                = uint32(36)
+	HealthMeshFetchSuccess            = uint32(40)
+	HealthMeshBusy                    = uint32(44)
+	HealthMeshInboundHeldBytes        = uint32(48)
+	HealthGossipSent                  = uint32(8)
+	HealthGossipReceived              = uint32(16)
+	HealthGossipDropped               = uint32(24)
+	HealthGossipRateLimited           = uint32(32)
+	HealthGossipFailedSignatures      = uint32(40)
+	HealthGossipQueueLength           = uint32(48)
+	HealthGossipFanout                = uint32(52)
+	HealthGossipMessageRate           = uint32(56)
+	HealthGossipDeliveryRatio         = uint32(60)
+	HealthGossipDuplicateRatio        = uint32(64)
+	HealthGossipPropagationP95        = uint32(68)
+	HealthGossipNetworkSize           = uint32(72)
+	HealthDhtEntries                  = uint32(8)
+	HealthDhtPeers                    = uint32(12)
+	HealthDhtNetworkSize              = uint32(16)
+	HealthDhtLookupSuccess            = uint32(20)
+	HealthDhtLookupP95                = uint32(24)
+	HealthDhtQueries                  = uint32(32)
+	HealthDhtFailedQueries            = uint32(40)
+	HealthDhtRejectedNodeIds          = uint32(48)
+	HealthDhtInvalidNodeReplies       = uint32(56)
+	HealthTransportActiveConnections  = uint32(8)
+	HealthTransportWebsocketFallbacks = uint32(12)
+	HealthTransportBytesSent          = uint32(16)
+	HealthTransportBytesReceived      = uint32(24)
+	HealthTransportMessagesSent       = uint32(32)
+	HealthTransportMessagesReceived   = uint32(40)
+	HealthTransportFailedMessages     = uint32(48)
+	HealthTransportLatencyP50         = uint32(56)
+	HealthTransportLatencyP95         = uint32(60)
+	HealthTransportLatencyP99         = uint32(64)
+	HealthTransportErrorRate          = uint32(68)
+	HealthTransportTotalConnections   = uint32(72)
+	HealthEconomyBalance              = uint32(8)
+	HealthEconomyEscrowed             = uint32(16)
+	HealthEconomySettled              = uint32(24)
+	HealthEconomyRefunded             = uint32(32)
+	HealthEconomySlashed              = uint32(40)
+	HealthEconomyActiveEscrows        = uint32(48)
+	HealthEconomyAccounts             = uint32(52)
+	HealthEconomySettlements          = uint32(56)
+	HealthEconomyJobsExecuted         = uint32(64)
+	HealthEconomyJobsFailed           = uint32(72)
+	HealthSupervisorActiveThreads     = uint32(8)
+	HealthSupervisorFailedThreads     = uint32(12)
+	HealthSupervisorRestartedThreads  = uint32(16)
+	HealthSupervisorMessages          = uint32(24)
+	OffsetArenaRequestQueue           = uint32(1380352)
+	OffsetArenaResponseQueue          = uint32(1384448)
+	ArenaQueueEntrySize               = uint32(64)
+	MaxArenaRequests                  = uint32(64)
+	OffsetMeshEventQueue              = uint32(1388544)
+	SizeMeshEventQueue                = uint32(53248)
+	MeshEventSlotSize                 = uint32(1024)
+	MeshEventSlotCount                = uint32(52)
+	OffsetBirdState                   = uint32(1441792)
+	SizeBirdState                     = uint32(4096)
+	OffsetPingpongControl             = uint32(1445888)
+	SizePingpongControl               = uint32(64)
+	OffsetCrashDump                   = uint32(1447936)
+	SizeCrashDump                     = uint32(2048)
+	CrashDumpMagic                    = uint32(1213420099)
+	OffsetBirdBufferA                 = uint32(1449984)
+	OffsetBirdBufferB                 = uint32(3940352)
+	SizeBirdBuffer                    = uint32(2360000)
+	BirdStride                        = uint32(236)
+	OffsetMatrixBufferA               = uint32(6430720)
+	OffsetMatrixBufferB               = uint32(11673600)
+	SizeMatrixBuffer                  = uint32(5120000)
+	MatrixStride                      = uint32(64)
+	OffsetDynamicRegistry             = uint32(16793600)
+	SizeDynamicRegistry               = uint32(4096)
+	DynamicRegionEntrySize            = uint32(32)
+	IdxKernelReady                    = uint32(0)
+	IdxInboxDirty                     = uint32(1)
+	IdxOutboxHostDirty                = uint32(2)
+	IdxPanicState                     = uint32(3)
+	IdxSensorEpoch                    = uint32(4)
+	IdxActorEpoch                     = uint32(5)
+	IdxStorageEpoch                   = uint32(6)
+	IdxSystemEpoch                    = uint32(7)
+	IdxSystemPulse                    = uint32(8)
+	IdxSystemVisibility               = uint32(9)
+	IdxSystemPowerState               = uint32(10)
+	IdxReservedPulse1                 = uint32(11)
+	IdxReservedPulse2                 = uint32(12)
+	IdxReservedPulse3                 = uint32(13)
+	IdxReservedPulse4                 = uint32(14)
+	IdxReservedPulse5                 = uint32(15)
+	IdxArenaAllocator                 = uint32(16)
+	IdxOutboxMutex                    = uint32(17)
+	IdxInboxMutex                     = uint32(18)
+	IdxMetricsEpoch                   = uint32(19)
+	IdxBirdEpoch                      = uint32(20)
+	IdxMatrixEpoch                    = uint32(21)
+	IdxPingpongActive                 = uint32(22)
+	IdxRegistryEpoch                  = uint32(23)
+	IdxEvolutionEpoch                 = uint32(24)
+	IdxHealthEpoch                    = uint32(25)
+	IdxLearningEpoch                  = uint32(26)
+	IdxEconomyEpoch                   = uint32(27)
+	IdxBirdCount                      = uint32(28)
+	IdxGlobalMetricsEpoch             = uint32(29)
+	IdxOutboxKernelDirty              = uint32(30)
+	IdxContextIdHash                  = uint32(31)
+	IdxDelegatedJobEpoch              = uint32(32)
+	IdxUserJobEpoch                   = uint32(33)
+	IdxDelegatedChunkEpoch            = uint32(34)
+	IdxMeshEventEpoch                 = uint32(35)
+	IdxMeshEventHead                  = uint32(36)
+	IdxMeshEventTail                  = uint32(37)
+	IdxMeshEventDropped               = uint32(38)
+	SupervisorPoolBase                = uint32(64)
+	SupervisorPoolSize                = uint32(128)
+	ReservedPoolBase                  = uint32(128)
+	ReservedPoolSize                  = uint32(128)
+	AlignmentCacheLine                = uint32(64)
+	AlignmentPage                     = uint32(4096)
+	AlignmentLarge                    = uint32(65536)
 )
 
 const schema_f1a2b3c4d5e6f7a8 = "x\xda\x9c\xd9}XT\xd5\xba\x00\xf0Y\x0c8cj" +
@@ -11,6 +11,7 @@ require (
 	github.com/sony/gobreaker v1.0.0
 	github.com/stretchr/testify v1.9.0
 	github.com/yasserelgammal/rate-limiter v1.0.0
+	golang.org/x/crypto v0.46.0
 	golang.org/x/net v0.48.0
 	zombiezen.com/go/capnproto2 v2.18.2+incompatible
 )
@@ -38,7 +39,6 @@ require (
 	github.com/stretchr/objx v0.5.2 // indirect
 	github.com/tinylib/msgp v1.6.1 // indirect
 	github.com/wlynxg/anet v0.0.3 // indirect
-	golang.org/x/crypto v0.46.0 // indirect
 	golang.org/x/sys v0.39.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
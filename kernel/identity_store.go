@@ -0,0 +1,73 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"encoding/base64"
+	"syscall/js"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh"
+)
+
+// hostIdentityStore keeps the node identity key with the host page, which
+// persists it to IndexedDB. The host hands the saved key back on boot as
+// __INOS_IDENTITY__.nodeKey = {nodeId, seed, createdAt} (seed base64) and is
+// told about new or rotated keys through the "identity_key_changed" event.
+type hostIdentityStore struct{}
+
+func (hostIdentityStore) LoadIdentity() (mesh.StoredIdentity, error) {
+	var stored mesh.StoredIdentity
+	raw := js.Global().Get("__INOS_IDENTITY__")
+	if raw.Type() != js.TypeObject {
+		return stored, mesh.ErrNoIdentityKey
+	}
+	key := raw.Get("nodeKey")
+	if key.Type() != js.TypeObject {
+		return stored, mesh.ErrNoIdentityKey
+	}
+	seed := key.Get("seed")
+	if seed.Type() != js.TypeString {
+		return stored, mesh.ErrNoIdentityKey
+	}
+	decoded, err := base64.StdEncoding.DecodeString(seed.String())
+	if err != nil {
+		return stored, err
+	}
+	stored.Seed = decoded
+	if nodeID := key.Get("nodeId"); nodeID.Type() == js.TypeString {
+		stored.NodeID = nodeID.String()
+	}
+	if created := key.Get("createdAt"); created.Type() == js.TypeNumber {
+		stored.CreatedAt = time.UnixMilli(int64(created.Float()))
+	}
+	return stored, nil
+}
+
+func (hostIdentityStore) SaveIdentity(stored mesh.StoredIdentity) error {
+	record := map[string]interface{}{
+		"nodeId":    stored.NodeID,
+		"seed":      base64.StdEncoding.EncodeToString(stored.Seed),
+		"createdAt": stored.CreatedAt.UnixMilli(),
+	}
+
+	global := js.Global()
+	identity := global.Get("__INOS_IDENTITY__")
+	if identity.Type() != js.TypeObject {
+		identity = global.Get("Object").New()
+		global.Set("__INOS_IDENTITY__", identity)
+	}
+	identity.Set("nodeKey", js.ValueOf(record))
+
+	global.Call("dispatchEvent",
+		global.Get("CustomEvent").New("inos:kernel", map[string]interface{}{
+			"detail": map[string]interface{}{
+				"event":     "identity_key_changed",
+				"timestamp": time.Now().UnixNano(),
+				"data":      record,
+			},
+		}),
+	)
+	return nil
+}
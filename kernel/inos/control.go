@@ -0,0 +1,391 @@
+package inos
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh"
+	"github.com/nmxmxh/inos_v1/kernel/threads/foundation"
+)
+
+// ErrControlToken is returned when a control server is created without a
+// token; the API is never served unauthenticated.
+var ErrControlToken = errors.New("inos: control token is required")
+
+// controlMaxRequestBytes bounds JSON request bodies. Object uploads are
+// streamed and bounded by the mesh's object limits instead.
+const controlMaxRequestBytes = 64 << 20
+
+// ControlServer serves a node's control API, JSON over HTTP, so operators
+// can script against a headless node. Every request must carry the server's
+// token as "Authorization: Bearer <token>".
+//
+//	GET    /v1/status               node ID, state and telemetry
+//	GET    /v1/peers                known peers with reputation and link state
+//	POST   /v1/objects              store the request body; returns its manifest hash
+//	GET    /v1/objects/{hash}       the object's bytes
+//	GET    /v1/chunks/{hash}        a chunk, from local storage or the mesh
+//	PUT    /v1/chunks/{hash}/pin    pin a chunk (?replicas=n)
+//	DELETE /v1/chunks/{hash}/pin    unpin a chunk
+//	POST   /v1/delegate             run a job on the mesh and wait for it
+//	GET    /v1/ledger               ledger totals
+//	GET    /v1/ledger/balance       an account's balance (?account=, default this node's)
+//	GET    /v1/config               runtime-adjustable settings
+//	PATCH  /v1/config               change them
+type ControlServer struct {
+	node  *Node
+	token string
+	mux   *http.ServeMux
+}
+
+// ControlStatus is the answer to GET /v1/status.
+type ControlStatus struct {
+	NodeID    string                 `json:"node_id"`
+	Region    string                 `json:"region"`
+	Running   bool                   `json:"running"`
+	Online    bool                   `json:"online"`
+	MeshTime  time.Time              `json:"mesh_time"`
+	Telemetry map[string]interface{} `json:"telemetry"`
+}
+
+// ControlPeer is one entry of GET /v1/peers.
+type ControlPeer struct {
+	ID          string  `json:"id"`
+	Region      string  `json:"region,omitempty"`
+	Role        string  `json:"role,omitempty"`
+	Reputation  float32 `json:"reputation"`
+	Connected   bool    `json:"connected"`
+	LatencyMs   float32 `json:"latency_ms,omitempty"`
+	Quarantined bool    `json:"quarantined,omitempty"`
+	LastSeenSec int64   `json:"last_seen_sec"`
+}
+
+// ControlObject is the answer to POST /v1/objects.
+type ControlObject struct {
+	Hash   string `json:"hash"`
+	Size   uint64 `json:"size"`
+	Chunks int    `json:"chunks"`
+}
+
+// ControlDelegateRequest is the body of POST /v1/delegate.
+type ControlDelegateRequest struct {
+	Operation  string                 `json:"operation"`
+	Data       []byte                 `json:"data,omitempty"`
+	Parameters map[string]interface{} `json:"parameters,omitempty"`
+	Priority   int                    `json:"priority,omitempty"`
+	TimeoutMs  int64                  `json:"timeout_ms,omitempty"` // 0 waits as long as the client does
+}
+
+// ControlDelegateResult is the answer to POST /v1/delegate.
+type ControlDelegateResult struct {
+	JobID     string  `json:"job_id"`
+	Success   bool    `json:"success"`
+	Data      []byte  `json:"data,omitempty"`
+	Error     string  `json:"error,omitempty"`
+	LatencyMs float64 `json:"latency_ms"`
+}
+
+// ControlBalance is the answer to GET /v1/ledger/balance.
+type ControlBalance struct {
+	Account string `json:"account"`
+	Balance int64  `json:"balance"`
+}
+
+// ControlConfig holds the settings that can change while a node runs. In
+// a PATCH, nil fields are left alone and bootstrap peers are added.
+type ControlConfig struct {
+	StorageQuotaBytes *uint64  `json:"storage_quota_bytes,omitempty"`
+	Background        *bool    `json:"background,omitempty"`
+	BootstrapPeers    []string `json:"bootstrap_peers,omitempty"`
+}
+
+// NewControlToken returns a random token for a control server.
+func NewControlToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("inos: failed to generate control token: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// NewControlServer creates a control server for node guarded by token.
+func NewControlServer(node *Node, token string) (*ControlServer, error) {
+	if token == "" {
+		return nil, ErrControlToken
+	}
+	s := &ControlServer{node: node, token: token, mux: http.NewServeMux()}
+	s.mux.HandleFunc("GET /v1/status", s.handleStatus)
+	s.mux.HandleFunc("GET /v1/peers", s.handlePeers)
+	s.mux.HandleFunc("POST /v1/objects", s.handlePutObject)
+	s.mux.HandleFunc("GET /v1/objects/{hash}", s.handleGetObject)
+	s.mux.HandleFunc("GET /v1/chunks/{hash}", s.handleGetChunk)
+	s.mux.HandleFunc("PUT /v1/chunks/{hash}/pin", s.handlePinChunk)
+	s.mux.HandleFunc("DELETE /v1/chunks/{hash}/pin", s.handleUnpinChunk)
+	s.mux.HandleFunc("POST /v1/delegate", s.handleDelegate)
+	s.mux.HandleFunc("GET /v1/ledger", s.handleLedger)
+	s.mux.HandleFunc("GET /v1/ledger/balance", s.handleBalance)
+	s.mux.HandleFunc("GET /v1/config", s.handleGetConfig)
+	s.mux.HandleFunc("PATCH /v1/config", s.handlePatchConfig)
+	return s, nil
+}
+
+// ServeHTTP checks the token and routes the request.
+func (s *ControlServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="inos-node"`)
+		writeControlError(w, http.StatusUnauthorized, errors.New("missing or invalid control token"))
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// ListenAndServe serves the control API on addr until ctx ends.
+func (s *ControlServer) ListenAndServe(ctx context.Context, addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("inos: control listener: %w", err)
+	}
+	return s.Serve(ctx, ln)
+}
+
+// Serve serves the control API on ln until ctx ends, then lets in-flight
+// requests finish for a few seconds.
+func (s *ControlServer) Serve(ctx context.Context, ln net.Listener) error {
+	srv := &http.Server{Handler: s, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+	if err := srv.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func (s *ControlServer) handleStatus(w http.ResponseWriter, r *http.Request) {
+	coord := s.node.Mesh()
+	writeControlJSON(w, http.StatusOK, ControlStatus{
+		NodeID:    s.node.NodeID(),
+		Region:    s.node.config.Region,
+		Running:   s.node.Running(),
+		Online:    coord.Online(),
+		MeshTime:  coord.MeshTime(),
+		Telemetry: coord.GetTelemetry(),
+	})
+}
+
+func (s *ControlServer) handlePeers(w http.ResponseWriter, r *http.Request) {
+	snapshot := s.node.Mesh().TopologySnapshot(true)
+	links := make(map[string]mesh.TopologyEdge, len(snapshot.Edges))
+	for _, edge := range snapshot.Edges {
+		links[edge.Target] = edge
+	}
+
+	peers := make([]ControlPeer, 0, len(snapshot.Nodes))
+	for _, node := range snapshot.Nodes {
+		if node.Self {
+			continue
+		}
+		link := links[node.ID]
+		peers = append(peers, ControlPeer{
+			ID:          node.ID,
+			Region:      node.Region,
+			Role:        node.Role,
+			Reputation:  node.Reputation,
+			Connected:   link.Connected,
+			LatencyMs:   link.LatencyMs,
+			Quarantined: node.Quarantined,
+			LastSeenSec: node.LastSeenSec,
+		})
+	}
+	writeControlJSON(w, http.StatusOK, peers)
+}
+
+func (s *ControlServer) handlePutObject(w http.ResponseWriter, r *http.Request) {
+	hash, manifest, err := s.node.Mesh().PutObject(r.Context(), r.Body, mesh.ObjectOptions{
+		ContentType: r.Header.Get("Content-Type"),
+	})
+	if err != nil {
+		writeControlError(w, http.StatusBadGateway, err)
+		return
+	}
+	writeControlJSON(w, http.StatusCreated, ControlObject{Hash: hash, Size: manifest.Size, Chunks: len(manifest.Chunks)})
+}
+
+func (s *ControlServer) handleGetObject(w http.ResponseWriter, r *http.Request) {
+	coord := s.node.Mesh()
+	manifest, err := coord.GetManifest(r.Context(), r.PathValue("hash"))
+	if err != nil {
+		writeControlError(w, http.StatusNotFound, err)
+		return
+	}
+	if manifest.ContentType != "" {
+		w.Header().Set("Content-Type", manifest.ContentType)
+	} else {
+		w.Header().Set("Content-Type", "application/octet-stream")
+	}
+	w.Header().Set("Content-Length", strconv.FormatUint(manifest.Size, 10))
+	// Headers are gone once bytes are written, so a failure part way
+	// through can only cut the body short
+	if _, err := coord.GetObject(r.Context(), r.PathValue("hash"), w); err != nil {
+		s.node.logger.Warn("control object read failed", "hash", r.PathValue("hash"), "error", err)
+	}
+}
+
+func (s *ControlServer) handleGetChunk(w http.ResponseWriter, r *http.Request) {
+	data, err := s.node.Mesh().FetchChunk(r.Context(), r.PathValue("hash"))
+	if err != nil {
+		writeControlError(w, http.StatusNotFound, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	_, _ = w.Write(data)
+}
+
+func (s *ControlServer) handlePinChunk(w http.ResponseWriter, r *http.Request) {
+	replicas := 0
+	if value := r.URL.Query().Get("replicas"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			writeControlError(w, http.StatusBadRequest, fmt.Errorf("invalid replicas %q", value))
+			return
+		}
+		replicas = n
+	}
+	if err := s.node.Mesh().PinChunk(r.PathValue("hash"), replicas); err != nil {
+		writeControlError(w, http.StatusBadRequest, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *ControlServer) handleUnpinChunk(w http.ResponseWriter, r *http.Request) {
+	if err := s.node.Mesh().UnpinChunk(r.PathValue("hash")); err != nil {
+		writeControlError(w, http.StatusNotFound, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *ControlServer) handleDelegate(w http.ResponseWriter, r *http.Request) {
+	var req ControlDelegateRequest
+	if !readControlJSON(w, r, &req) {
+		return
+	}
+	if req.Operation == "" {
+		writeControlError(w, http.StatusBadRequest, errors.New("operation is required"))
+		return
+	}
+
+	ctx := r.Context()
+	if req.TimeoutMs > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(req.TimeoutMs)*time.Millisecond)
+		defer cancel()
+	}
+	job := &foundation.Job{
+		ID:          fmt.Sprintf("ctl_%d", time.Now().UnixNano()),
+		Operation:   req.Operation,
+		Data:        req.Data,
+		Parameters:  req.Parameters,
+		Priority:    req.Priority,
+		Source:      "control",
+		SubmittedAt: time.Now(),
+	}
+	result, err := s.node.Delegate(ctx, job)
+	switch {
+	case errors.Is(err, ErrNotStarted):
+		writeControlError(w, http.StatusServiceUnavailable, err)
+		return
+	case err != nil:
+		writeControlError(w, http.StatusBadGateway, err)
+		return
+	}
+	writeControlJSON(w, http.StatusOK, ControlDelegateResult{
+		JobID:     job.ID,
+		Success:   result.Success,
+		Data:      result.Data,
+		Error:     result.Error,
+		LatencyMs: float64(result.Latency.Microseconds()) / 1000,
+	})
+}
+
+func (s *ControlServer) handleLedger(w http.ResponseWriter, r *http.Request) {
+	writeControlJSON(w, http.StatusOK, s.node.Mesh().GetEconomicStats())
+}
+
+func (s *ControlServer) handleBalance(w http.ResponseWriter, r *http.Request) {
+	account := r.URL.Query().Get("account")
+	if account == "" {
+		account = s.node.Account()
+	}
+	writeControlJSON(w, http.StatusOK, ControlBalance{Account: account, Balance: s.node.Mesh().GetEconomicBalance(account)})
+}
+
+func (s *ControlServer) handleGetConfig(w http.ResponseWriter, r *http.Request) {
+	writeControlJSON(w, http.StatusOK, s.currentConfig())
+}
+
+func (s *ControlServer) handlePatchConfig(w http.ResponseWriter, r *http.Request) {
+	var patch ControlConfig
+	if !readControlJSON(w, r, &patch) {
+		return
+	}
+	coord := s.node.Mesh()
+	if patch.StorageQuotaBytes != nil {
+		coord.SetStorageQuota(*patch.StorageQuotaBytes)
+	}
+	if patch.Background != nil {
+		coord.SetBackgroundMode(*patch.Background)
+	}
+	if len(patch.BootstrapPeers) > 0 {
+		coord.AddBootstrapPeers(patch.BootstrapPeers...)
+	}
+	s.node.logger.Info("config changed over control API")
+	writeControlJSON(w, http.StatusOK, s.currentConfig())
+}
+
+func (s *ControlServer) currentConfig() ControlConfig {
+	coord := s.node.Mesh()
+	quota := coord.GetStorageQuotaUsage().MaxBytes
+	background := coord.InBackground()
+	return ControlConfig{
+		StorageQuotaBytes: &quota,
+		Background:        &background,
+		BootstrapPeers:    coord.GetBootstrapStatus().Peers,
+	}
+}
+
+// readControlJSON decodes a bounded JSON body, answering 400 on failure.
+func readControlJSON(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	body := http.MaxBytesReader(w, r.Body, controlMaxRequestBytes)
+	if err := json.NewDecoder(body).Decode(v); err != nil && !errors.Is(err, io.EOF) {
+		writeControlError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return false
+	}
+	return true
+}
+
+func writeControlJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeControlError(w http.ResponseWriter, status int, err error) {
+	writeControlJSON(w, status, map[string]string{"error": err.Error()})
+}
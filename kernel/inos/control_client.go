@@ -0,0 +1,201 @@
+package inos
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ControlError is a non-success answer from a control server.
+type ControlError struct {
+	Status  int
+	Message string
+}
+
+func (e *ControlError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("control API: %s", http.StatusText(e.Status))
+	}
+	return fmt.Sprintf("control API: %s (%d)", e.Message, e.Status)
+}
+
+// ControlClient calls a node's control API (see ControlServer).
+type ControlClient struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+// NewControlClient returns a client for the control server at addr, either
+// host:port or a full http(s) URL.
+func NewControlClient(addr, token string) *ControlClient {
+	if !strings.Contains(addr, "://") {
+		addr = "http://" + addr
+	}
+	return &ControlClient{
+		baseURL: strings.TrimRight(addr, "/"),
+		token:   token,
+		http:    &http.Client{},
+	}
+}
+
+// Status returns the node's status.
+func (c *ControlClient) Status(ctx context.Context) (*ControlStatus, error) {
+	var status ControlStatus
+	if err := c.doJSON(ctx, http.MethodGet, "/v1/status", nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// Peers returns the peers the node knows.
+func (c *ControlClient) Peers(ctx context.Context) ([]ControlPeer, error) {
+	var peers []ControlPeer
+	if err := c.doJSON(ctx, http.MethodGet, "/v1/peers", nil, &peers); err != nil {
+		return nil, err
+	}
+	return peers, nil
+}
+
+// PutObject stores r's bytes on the node and returns the object's hash.
+func (c *ControlClient) PutObject(ctx context.Context, r io.Reader, contentType string) (*ControlObject, error) {
+	resp, err := c.do(ctx, http.MethodPost, "/v1/objects", r, contentType)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var object ControlObject
+	if err := json.NewDecoder(resp.Body).Decode(&object); err != nil {
+		return nil, fmt.Errorf("control API: invalid response: %w", err)
+	}
+	return &object, nil
+}
+
+// GetObject writes the object's bytes to w.
+func (c *ControlClient) GetObject(ctx context.Context, hash string, w io.Writer) (int64, error) {
+	resp, err := c.do(ctx, http.MethodGet, "/v1/objects/"+url.PathEscape(hash), nil, "")
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	return io.Copy(w, resp.Body)
+}
+
+// PinChunk pins a chunk on the node with the given replica target.
+func (c *ControlClient) PinChunk(ctx context.Context, hash string, replicas int) error {
+	path := "/v1/chunks/" + url.PathEscape(hash) + "/pin?replicas=" + strconv.Itoa(replicas)
+	return c.doJSON(ctx, http.MethodPut, path, nil, nil)
+}
+
+// UnpinChunk removes a chunk's pin.
+func (c *ControlClient) UnpinChunk(ctx context.Context, hash string) error {
+	return c.doJSON(ctx, http.MethodDelete, "/v1/chunks/"+url.PathEscape(hash)+"/pin", nil, nil)
+}
+
+// Delegate runs a job on the mesh through the node and waits for it.
+func (c *ControlClient) Delegate(ctx context.Context, req ControlDelegateRequest) (*ControlDelegateResult, error) {
+	var result ControlDelegateResult
+	if err := c.doJSON(ctx, http.MethodPost, "/v1/delegate", req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Ledger returns the node's ledger totals.
+func (c *ControlClient) Ledger(ctx context.Context) (map[string]interface{}, error) {
+	var stats map[string]interface{}
+	if err := c.doJSON(ctx, http.MethodGet, "/v1/ledger", nil, &stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// Balance returns an account's balance; an empty account is the node's own.
+func (c *ControlClient) Balance(ctx context.Context, account string) (*ControlBalance, error) {
+	path := "/v1/ledger/balance"
+	if account != "" {
+		path += "?account=" + url.QueryEscape(account)
+	}
+	var balance ControlBalance
+	if err := c.doJSON(ctx, http.MethodGet, path, nil, &balance); err != nil {
+		return nil, err
+	}
+	return &balance, nil
+}
+
+// Config returns the node's runtime settings.
+func (c *ControlClient) Config(ctx context.Context) (*ControlConfig, error) {
+	var config ControlConfig
+	if err := c.doJSON(ctx, http.MethodGet, "/v1/config", nil, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// UpdateConfig applies patch and returns the resulting settings.
+func (c *ControlClient) UpdateConfig(ctx context.Context, patch ControlConfig) (*ControlConfig, error) {
+	var config ControlConfig
+	if err := c.doJSON(ctx, http.MethodPatch, "/v1/config", patch, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// doJSON sends in as JSON, when non-nil, and decodes the answer into out,
+// when non-nil.
+func (c *ControlClient) doJSON(ctx context.Context, method, path string, in, out interface{}) error {
+	var body io.Reader
+	contentType := ""
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+		contentType = "application/json"
+	}
+	resp, err := c.do(ctx, method, path, body, contentType)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("control API: invalid response: %w", err)
+	}
+	return nil
+}
+
+// do sends an authenticated request, turning error answers into
+// *ControlError. The caller closes the body of a successful response.
+func (c *ControlClient) do(ctx context.Context, method, path string, body io.Reader, contentType string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("control API: %w", err)
+	}
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		var answer struct {
+			Error string `json:"error"`
+		}
+		_ = json.NewDecoder(io.LimitReader(resp.Body, 1<<16)).Decode(&answer)
+		return nil, &ControlError{Status: resp.StatusCode, Message: answer.Error}
+	}
+	return resp, nil
+}
@@ -0,0 +1,45 @@
+package inos
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestControlClient_RoundTrip(t *testing.T) {
+	node, srv := newControlTestServer(t)
+	client := NewControlClient(srv.URL, "secret")
+	ctx := context.Background()
+
+	status, err := client.Status(ctx)
+	if err != nil || status.NodeID != node.NodeID() {
+		t.Fatalf("unexpected status %+v %v", status, err)
+	}
+
+	object, err := client.PutObject(ctx, strings.NewReader("payload"), "text/plain")
+	if err != nil {
+		t.Fatalf("PutObject failed: %v", err)
+	}
+	var buf bytes.Buffer
+	if n, err := client.GetObject(ctx, object.Hash, &buf); err != nil || n != 7 || buf.String() != "payload" {
+		t.Fatalf("GetObject returned %d %q %v", n, buf.String(), err)
+	}
+
+	patched, err := client.UpdateConfig(ctx, ControlConfig{BootstrapPeers: []string{"peer-boot"}})
+	if err != nil || len(patched.BootstrapPeers) != 1 {
+		t.Fatalf("unexpected config %+v %v", patched, err)
+	}
+
+	_, err = client.Delegate(ctx, ControlDelegateRequest{Operation: "compress"})
+	var controlErr *ControlError
+	if !errors.As(err, &controlErr) || controlErr.Status != http.StatusServiceUnavailable {
+		t.Fatalf("expected a 503 ControlError, got %v", err)
+	}
+
+	if _, err := NewControlClient(srv.URL, "wrong").Peers(ctx); !errors.As(err, &controlErr) || controlErr.Status != http.StatusUnauthorized {
+		t.Fatalf("expected a 401 ControlError, got %v", err)
+	}
+}
@@ -0,0 +1,133 @@
+package inos
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newControlTestServer(t *testing.T) (*Node, *httptest.Server) {
+	t.Helper()
+	node, err := New(Config{Region: "eu-west", DID: "did:inos:operator"})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	server, err := NewControlServer(node, "secret")
+	if err != nil {
+		t.Fatalf("NewControlServer failed: %v", err)
+	}
+	srv := httptest.NewServer(server)
+	t.Cleanup(srv.Close)
+	return node, srv
+}
+
+func controlRequest(t *testing.T, srv *httptest.Server, method, path string, body io.Reader, out interface{}) int {
+	t.Helper()
+	req, _ := http.NewRequest(method, srv.URL+path, body)
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatalf("%s %s failed: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+	if out != nil {
+		if raw, ok := out.(*[]byte); ok {
+			*raw, _ = io.ReadAll(resp.Body)
+		} else if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatalf("%s %s: invalid response: %v", method, path, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func TestControlServer_RequiresToken(t *testing.T) {
+	if _, err := NewControlServer(nil, ""); err != ErrControlToken {
+		t.Fatalf("expected ErrControlToken, got %v", err)
+	}
+	_, srv := newControlTestServer(t)
+
+	for _, header := range []string{"", "Bearer wrong", "secret"} {
+		req, _ := http.NewRequest(http.MethodGet, srv.URL+"/v1/status", nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		resp, err := srv.Client().Do(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusUnauthorized || resp.Header.Get("WWW-Authenticate") == "" {
+			t.Fatalf("expected 401 for %q, got %d", header, resp.StatusCode)
+		}
+	}
+}
+
+func TestControlServer_StatusObjectsAndLedger(t *testing.T) {
+	node, srv := newControlTestServer(t)
+
+	var status ControlStatus
+	if code := controlRequest(t, srv, http.MethodGet, "/v1/status", nil, &status); code != http.StatusOK {
+		t.Fatalf("status returned %d", code)
+	}
+	if status.NodeID != node.NodeID() || status.Region != "eu-west" || status.Running {
+		t.Fatalf("unexpected status %+v", status)
+	}
+
+	var peers []ControlPeer
+	if code := controlRequest(t, srv, http.MethodGet, "/v1/peers", nil, &peers); code != http.StatusOK || len(peers) != 0 {
+		t.Fatalf("expected no peers, got %d %+v", code, peers)
+	}
+
+	var object ControlObject
+	if code := controlRequest(t, srv, http.MethodPost, "/v1/objects", strings.NewReader("hello mesh"), &object); code != http.StatusCreated {
+		t.Fatalf("put returned %d", code)
+	}
+	if object.Hash == "" || object.Size != 10 {
+		t.Fatalf("unexpected object %+v", object)
+	}
+	var data []byte
+	if code := controlRequest(t, srv, http.MethodGet, "/v1/objects/"+object.Hash, nil, &data); code != http.StatusOK || !bytes.Equal(data, []byte("hello mesh")) {
+		t.Fatalf("get returned %d %q", code, data)
+	}
+	if code := controlRequest(t, srv, http.MethodGet, "/v1/objects/missing", nil, nil); code != http.StatusNotFound {
+		t.Fatalf("expected 404 for a missing object, got %d", code)
+	}
+
+	node.Ledger().RegisterAccount("did:inos:operator", 42)
+	var balance ControlBalance
+	if code := controlRequest(t, srv, http.MethodGet, "/v1/ledger/balance", nil, &balance); code != http.StatusOK {
+		t.Fatalf("balance returned %d", code)
+	}
+	if balance.Account != "did:inos:operator" || balance.Balance != 42 {
+		t.Fatalf("unexpected balance %+v", balance)
+	}
+
+	var result map[string]string
+	body := strings.NewReader(`{"operation":"compress","data":"aGVsbG8="}`)
+	if code := controlRequest(t, srv, http.MethodPost, "/v1/delegate", body, &result); code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 before the node starts, got %d %v", code, result)
+	}
+}
+
+func TestControlServer_PatchConfig(t *testing.T) {
+	node, srv := newControlTestServer(t)
+
+	var config ControlConfig
+	body := strings.NewReader(`{"storage_quota_bytes":1048576,"background":true}`)
+	if code := controlRequest(t, srv, http.MethodPatch, "/v1/config", body, &config); code != http.StatusOK {
+		t.Fatalf("patch returned %d", code)
+	}
+	if *config.StorageQuotaBytes != 1<<20 || !*config.Background {
+		t.Fatalf("unexpected config %+v", config)
+	}
+	if !node.Mesh().InBackground() {
+		t.Fatal("expected the node in background mode")
+	}
+	if code := controlRequest(t, srv, http.MethodPatch, "/v1/config", strings.NewReader("{"), nil); code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a malformed patch, got %d", code)
+	}
+}
@@ -0,0 +1,300 @@
+// Package inos embeds an INOS mesh node in a Go program.
+//
+// The browser kernel wires its node from host globals inside main(); this
+// package does the same wiring from a plain Config so server-side programs can
+// join the mesh as a library:
+//
+//	node, err := inos.New(inos.Config{Region: "eu-west", BootstrapPeers: peers})
+//	if err != nil { ... }
+//	if err := node.Start(ctx); err != nil { ... }
+//	defer node.Close()
+//
+//	result, err := node.Delegate(ctx, job)
+package inos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh"
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/routing"
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/transport"
+	"github.com/nmxmxh/inos_v1/kernel/threads/foundation"
+	"github.com/nmxmxh/inos_v1/kernel/utils/metrics"
+)
+
+var (
+	// ErrNotStarted is returned by calls that need a running node.
+	ErrNotStarted = errors.New("inos: node not started")
+	// ErrClosed is returned once a node has been stopped; nodes cannot restart.
+	ErrClosed = errors.New("inos: node closed")
+)
+
+// Config describes an embedded node. The zero value is usable: an ephemeral
+// identity, in-memory storage and default transport settings.
+type Config struct {
+	Region      string
+	DID         string
+	DeviceID    string
+	DisplayName string
+
+	// Transport falls back to transport.DefaultTransportConfig() when left
+	// zero; start from DefaultConfig() to override individual settings.
+	Transport      transport.TransportConfig
+	BootstrapPeers []string // peerID, peerID@wss://... or wss://...
+	Rendezvous     []string // DNS names with "inos-peer=" TXT records
+
+	// IdentityStore keeps the node key, and with it the node ID, across
+	// restarts (see mesh.FileIdentityKeyStore). Nil uses an ephemeral identity.
+	IdentityStore mesh.IdentityKeyStore
+	// Storage holds chunks this node serves. Nil uses a MemoryStorage.
+	Storage mesh.StorageProvider
+	// LedgerStore and ReputationStore persist economic and trust state.
+	LedgerStore     mesh.LedgerStore
+	ReputationStore routing.ReputationStore
+
+	// MeshTransport replaces the WebRTC transport, e.g. with an in-process
+	// transport in tests. Transport settings are ignored when set.
+	MeshTransport mesh.Transport
+
+	// MetricsListen is the address to serve Prometheus metrics on at
+	// /metrics, e.g. "127.0.0.1:9464". Empty disables the listener; the
+	// registry is still available through Node.Metrics. Native builds only.
+	MetricsListen string
+
+	Logger *slog.Logger
+}
+
+// DefaultConfig returns a config with default transport settings.
+func DefaultConfig() Config {
+	return Config{
+		Region:    "global",
+		Transport: transport.DefaultTransportConfig(),
+	}
+}
+
+// Node is an embedded mesh node.
+type Node struct {
+	config   Config
+	identity *mesh.NodeIdentity
+	mesh     *mesh.MeshCoordinator
+	storage  mesh.StorageProvider
+	metrics  *metrics.Registry
+	logger   *slog.Logger
+
+	mu          sync.Mutex
+	started     bool
+	closed      bool
+	metricsAddr string
+	stopMetrics context.CancelFunc
+}
+
+// New builds a node from config without connecting to the mesh; call Start
+// to join.
+func New(config Config) (*Node, error) {
+	if config.Region == "" {
+		config.Region = "global"
+	}
+	if config.Transport.MaxConnections == 0 {
+		config.Transport = transport.DefaultTransportConfig()
+	}
+	if len(config.Transport.SignalingServers) == 0 && config.Transport.WebSocketURL != "" {
+		config.Transport.SignalingServers = []string{config.Transport.WebSocketURL}
+	}
+	logger := config.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	identity, err := mesh.LoadOrCreateNodeIdentity(config.IdentityStore)
+	if err != nil {
+		return nil, fmt.Errorf("inos: %w", err)
+	}
+	nodeID := identity.NodeID()
+
+	tr := config.MeshTransport
+	if tr == nil {
+		webrtc, err := transport.NewWebRTCTransport(nodeID, config.Transport, logger)
+		if err != nil {
+			return nil, fmt.Errorf("inos: failed to create transport: %w", err)
+		}
+		tr = webrtc
+	}
+
+	coord := mesh.NewMeshCoordinator(nodeID, config.Region, tr, logger)
+	if err := coord.SetNodeIdentity(identity); err != nil {
+		return nil, fmt.Errorf("inos: failed to install node identity: %w", err)
+	}
+	if config.DID != "" || config.DeviceID != "" || config.DisplayName != "" {
+		coord.SetIdentity(valueOr(config.DID, "did:inos:system"), valueOr(config.DeviceID, "device:unknown"), valueOr(config.DisplayName, "Guest"))
+	}
+
+	storage := config.Storage
+	if storage == nil {
+		storage = NewMemoryStorage()
+	}
+	coord.SetStorage(storage)
+
+	if config.ReputationStore != nil {
+		coord.SetReputationStore(config.ReputationStore)
+	}
+	if config.LedgerStore != nil {
+		if err := coord.SetLedgerStore(config.LedgerStore); err != nil {
+			return nil, fmt.Errorf("inos: failed to restore ledger: %w", err)
+		}
+	}
+
+	coord.AddBootstrapPeers(config.BootstrapPeers...)
+	coord.AddRendezvousDomains(config.Rendezvous...)
+
+	reg := metrics.NewRegistry("inos")
+	coord.RegisterMetrics(reg)
+
+	return &Node{
+		config:   config,
+		identity: identity,
+		mesh:     coord,
+		storage:  storage,
+		metrics:  reg,
+		logger:   logger.With("component", "inos_node", "node_id", nodeID),
+	}, nil
+}
+
+// NodeID returns the node's stable ID, derived from its identity key.
+func (n *Node) NodeID() string {
+	return n.identity.NodeID()
+}
+
+// Mesh returns the underlying coordinator for anything the Node does not wrap.
+func (n *Node) Mesh() *mesh.MeshCoordinator {
+	return n.mesh
+}
+
+// Ledger returns the node's economic ledger.
+func (n *Node) Ledger() *mesh.EconomicLedger {
+	return n.mesh.Ledger()
+}
+
+// Account returns the ledger account the node earns into: its DID when
+// configured, otherwise its node ID.
+func (n *Node) Account() string {
+	return valueOr(n.config.DID, n.NodeID())
+}
+
+// Storage returns the chunk store the node serves from.
+func (n *Node) Storage() mesh.StorageProvider {
+	return n.storage
+}
+
+// Metrics returns the registry holding the node's mesh, transport, gossip,
+// DHT, ledger and execution stats.
+func (n *Node) Metrics() *metrics.Registry {
+	return n.metrics
+}
+
+// MetricsAddr returns the address the metrics listener is bound to, or ""
+// when Config.MetricsListen is unset or the node is not running.
+func (n *Node) MetricsAddr() string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.metricsAddr
+}
+
+// Identity returns the node's signing identity.
+func (n *Node) Identity() *mesh.NodeIdentity {
+	return n.identity
+}
+
+// Start joins the mesh. The context bounds background discovery; stopping the
+// node is done with Stop or Close.
+func (n *Node) Start(ctx context.Context) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.closed {
+		return ErrClosed
+	}
+	if n.started {
+		return nil
+	}
+	if n.config.MetricsListen != "" {
+		if err := n.serveMetrics(n.config.MetricsListen); err != nil {
+			return fmt.Errorf("inos: metrics listener: %w", err)
+		}
+	}
+	if err := n.mesh.Start(ctx); err != nil {
+		n.closeMetrics()
+		return fmt.Errorf("inos: %w", err)
+	}
+	n.started = true
+	n.logger.Info("node joined mesh", "region", n.config.Region)
+	return nil
+}
+
+// Running reports whether the node has started and not yet stopped.
+func (n *Node) Running() bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.started && !n.closed
+}
+
+// Delegate runs a job on the mesh and waits for its result.
+func (n *Node) Delegate(ctx context.Context, job *foundation.Job) (*foundation.Result, error) {
+	if !n.Running() {
+		return nil, ErrNotStarted
+	}
+	return n.mesh.DelegateJob(ctx, job)
+}
+
+// Depart announces the node is leaving and hands off its work, unique chunks
+// and state, without stopping it. Close does this automatically.
+func (n *Node) Depart(ctx context.Context, reason string) mesh.DepartureReport {
+	return n.mesh.Depart(ctx, reason)
+}
+
+// Stop leaves the mesh abruptly, without handing anything off. Peers notice
+// through connection loss.
+func (n *Node) Stop() error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.closed {
+		return nil
+	}
+	n.closed = true
+	if !n.started {
+		return nil
+	}
+	n.closeMetrics()
+	return n.mesh.Stop()
+}
+
+// Close departs gracefully and then stops the node.
+func (n *Node) Close() error {
+	if n.Running() && !n.mesh.IsDeparting() {
+		report := n.mesh.Depart(context.Background(), "shutdown")
+		if len(report.Errors) > 0 {
+			n.logger.Warn("departure incomplete", "errors", report.Errors)
+		}
+	}
+	return n.Stop()
+}
+
+// closeMetrics shuts the metrics listener down. Callers hold n.mu.
+func (n *Node) closeMetrics() {
+	if n.stopMetrics != nil {
+		n.stopMetrics()
+		n.stopMetrics = nil
+	}
+	n.metricsAddr = ""
+}
+
+func valueOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,129 @@
+package inos
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh"
+	"github.com/nmxmxh/inos_v1/kernel/threads/foundation"
+)
+
+func TestNode_Lifecycle(t *testing.T) {
+	node, err := New(Config{Region: "eu-west", DID: "did:inos:server"})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if node.Mesh().GetNodeID() != node.NodeID() {
+		t.Fatalf("mesh node ID %s does not match %s", node.Mesh().GetNodeID(), node.NodeID())
+	}
+	if node.Ledger() == nil || node.Storage() == nil {
+		t.Fatal("expected default ledger and storage")
+	}
+	if _, err := node.Delegate(context.Background(), &foundation.Job{ID: "early"}); !errors.Is(err, ErrNotStarted) {
+		t.Fatalf("expected ErrNotStarted, got %v", err)
+	}
+
+	if err := node.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if !node.Running() {
+		t.Fatal("expected node to be running")
+	}
+	if err := node.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if !node.Mesh().IsDeparting() {
+		t.Fatal("expected Close to depart before stopping")
+	}
+	if err := node.Stop(); err != nil {
+		t.Fatalf("second stop failed: %v", err)
+	}
+	if err := node.Start(context.Background()); !errors.Is(err, ErrClosed) {
+		t.Fatalf("expected ErrClosed on restart, got %v", err)
+	}
+}
+
+func TestNode_IdentityStoreKeepsNodeID(t *testing.T) {
+	store := &mesh.FileIdentityKeyStore{Path: filepath.Join(t.TempDir(), "node.key")}
+
+	first, err := New(Config{IdentityStore: store})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	second, err := New(Config{IdentityStore: store})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if first.NodeID() != second.NodeID() {
+		t.Fatalf("node ID changed across restarts: %s != %s", first.NodeID(), second.NodeID())
+	}
+}
+
+func TestNode_ServesMetrics(t *testing.T) {
+	node, err := New(Config{MetricsListen: "127.0.0.1:0"})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if err := node.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	addr := node.MetricsAddr()
+	if addr == "" {
+		t.Fatal("expected a bound metrics address")
+	}
+
+	resp, err := http.Get("http://" + addr + "/metrics")
+	if err != nil {
+		t.Fatalf("scrape failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	for _, name := range []string{"inos_mesh_total_peers", "inos_execution_executed"} {
+		if !strings.Contains(string(body), name) {
+			t.Fatalf("scrape is missing %s:\n%s", name, body)
+		}
+	}
+
+	if err := node.Stop(); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+	if node.MetricsAddr() != "" {
+		t.Fatal("expected the metrics listener closed with the node")
+	}
+}
+
+func TestNode_MetricsListenErrorFailsStart(t *testing.T) {
+	node, err := New(Config{MetricsListen: "127.0.0.1:-1"})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer node.Stop()
+	if err := node.Start(context.Background()); err == nil {
+		t.Fatal("expected a bad metrics address to fail Start")
+	}
+	if node.Running() {
+		t.Fatal("node should not run after a failed Start")
+	}
+}
+
+func TestMemoryStorage_CopiesData(t *testing.T) {
+	ctx := context.Background()
+	storage := NewMemoryStorage()
+	data := []byte("chunk")
+	_ = storage.StoreChunk(ctx, "h1", data)
+	data[0] = 'X'
+
+	got, err := storage.FetchChunk(ctx, "h1")
+	if err != nil || string(got) != "chunk" {
+		t.Fatalf("unexpected fetch %q %v", got, err)
+	}
+	_ = storage.DeleteChunk(ctx, "h1")
+	if ok, _ := storage.HasChunk(ctx, "h1"); ok || storage.Len() != 0 {
+		t.Fatal("expected chunk to be deleted")
+	}
+}
@@ -0,0 +1,58 @@
+package inos
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// MemoryStorage is a StorageProvider that keeps chunks in process memory. It
+// is the default for embedded nodes; anything that must survive a restart
+// needs a durable provider.
+type MemoryStorage struct {
+	mu     sync.RWMutex
+	chunks map[string][]byte
+}
+
+// NewMemoryStorage returns an empty in-memory chunk store.
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{chunks: make(map[string][]byte)}
+}
+
+func (s *MemoryStorage) StoreChunk(_ context.Context, hash string, data []byte) error {
+	s.mu.Lock()
+	s.chunks[hash] = append([]byte(nil), data...)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *MemoryStorage) FetchChunk(_ context.Context, hash string) ([]byte, error) {
+	s.mu.RLock()
+	data, ok := s.chunks[hash]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("chunk %s not found", hash)
+	}
+	return append([]byte(nil), data...), nil
+}
+
+func (s *MemoryStorage) HasChunk(_ context.Context, hash string) (bool, error) {
+	s.mu.RLock()
+	_, ok := s.chunks[hash]
+	s.mu.RUnlock()
+	return ok, nil
+}
+
+func (s *MemoryStorage) DeleteChunk(_ context.Context, hash string) error {
+	s.mu.Lock()
+	delete(s.chunks, hash)
+	s.mu.Unlock()
+	return nil
+}
+
+// Len returns the number of stored chunks.
+func (s *MemoryStorage) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.chunks)
+}
@@ -0,0 +1,30 @@
+//go:build !js || !wasm
+
+package inos
+
+import (
+	"context"
+	"net"
+
+	"github.com/nmxmxh/inos_v1/kernel/utils/metrics"
+)
+
+// serveMetrics binds addr and serves the node's registry at /metrics until
+// the node stops. Callers hold n.mu.
+func (n *Node) serveMetrics(addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	n.stopMetrics = cancel
+	n.metricsAddr = ln.Addr().String()
+
+	go func() {
+		if err := metrics.Serve(ctx, ln, n.metrics); err != nil {
+			n.logger.Warn("metrics listener stopped", "addr", ln.Addr().String(), "error", err)
+		}
+	}()
+	n.logger.Info("serving metrics", "addr", n.metricsAddr)
+	return nil
+}
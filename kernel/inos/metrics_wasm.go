@@ -0,0 +1,11 @@
+//go:build js && wasm
+
+package inos
+
+import "errors"
+
+// serveMetrics is unavailable in the browser, which has no listening
+// sockets; read Node.Metrics directly instead.
+func (n *Node) serveMetrics(string) error {
+	return errors.New("not supported in browser builds")
+}
@@ -0,0 +1,235 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	goruntime "runtime"
+	"syscall/js"
+	"time"
+
+	inosruntime "github.com/nmxmxh/inos_v1/kernel/runtime"
+	sab_layout "github.com/nmxmxh/inos_v1/kernel/threads/sab"
+	"github.com/nmxmxh/inos_v1/kernel/utils"
+	"github.com/nmxmxh/inos_v1/kernel/utils/tracing"
+)
+
+// maxConfiguredWorkers bounds host-requested worker counts.
+const maxConfiguredWorkers = 64
+
+// errBootOnlyConfig is returned for boot-time fields sent after the SAB was
+// injected.
+var errBootOnlyConfig = errors.New("field can only be set before SAB injection")
+
+// HostKernelConfig is the JSON a host passes to configureKernel. Missing
+// fields keep the detected or current value. Workers, threading, cache size,
+// region, signaling servers and SAB size are boot-time settings; the rest
+// can change while the kernel runs.
+type HostKernelConfig struct {
+	// Boot-time
+	EnableThreading  *bool    `json:"enableThreading,omitempty"`
+	MaxWorkers       int      `json:"maxWorkers,omitempty"`
+	CacheSize        uint64   `json:"cacheSize,omitempty"`
+	Region           string   `json:"region,omitempty"`
+	SignalingServers []string `json:"signalingServers,omitempty"`
+	SABSize          uint32   `json:"sabSize,omitempty"`
+
+	// Runtime
+	LogLevel          string   `json:"logLevel,omitempty"`
+	RoleProfile       string   `json:"roleProfile,omitempty"`
+	FrameBudgetMs     float64  `json:"frameBudgetMs,omitempty"`
+	RingWaitTimeoutMs float64  `json:"ringWaitTimeoutMs,omitempty"`
+	TraceSampleRate   *float64 `json:"traceSampleRate,omitempty"`
+}
+
+// bootOnly reports whether any boot-time field is set.
+func (c *HostKernelConfig) bootOnly() bool {
+	return c.EnableThreading != nil || c.MaxWorkers != 0 || c.CacheSize != 0 ||
+		c.Region != "" || len(c.SignalingServers) > 0 || c.SABSize != 0
+}
+
+// Validate checks every field without applying any of them.
+func (c *HostKernelConfig) Validate() error {
+	var errs []error
+	if c.MaxWorkers < 0 || c.MaxWorkers > maxConfiguredWorkers {
+		errs = append(errs, fmt.Errorf("maxWorkers must be between 1 and %d", maxConfiguredWorkers))
+	}
+	if c.SABSize != 0 && c.SABSize < uint32(sab_layout.SAB_SIZE_MIN) {
+		errs = append(errs, fmt.Errorf("sabSize %d is below the minimum %d", c.SABSize, sab_layout.SAB_SIZE_MIN))
+	}
+	for _, server := range c.SignalingServers {
+		if server == "" {
+			errs = append(errs, errors.New("signalingServers contains an empty URL"))
+			break
+		}
+	}
+	if c.LogLevel != "" {
+		if _, err := utils.ParseLogLevel(c.LogLevel); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if c.RoleProfile != "" {
+		if _, err := inosruntime.ParseRoleProfile(c.RoleProfile); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if c.FrameBudgetMs < 0 {
+		errs = append(errs, errors.New("frameBudgetMs must not be negative"))
+	}
+	if c.RingWaitTimeoutMs < 0 {
+		errs = append(errs, errors.New("ringWaitTimeoutMs must not be negative"))
+	}
+	if c.TraceSampleRate != nil && (*c.TraceSampleRate < 0 || *c.TraceSampleRate > 1) {
+		errs = append(errs, errors.New("traceSampleRate must be between 0 and 1"))
+	}
+	return errors.Join(errs...)
+}
+
+// Configure validates and applies a host config. Nothing is applied unless
+// the whole config is valid.
+func (k *Kernel) Configure(cfg HostKernelConfig) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	if cfg.bootOnly() && KernelState(k.state.Load()) >= StateRunning {
+		return errBootOnlyConfig
+	}
+
+	k.configMu.Lock()
+	defer k.configMu.Unlock()
+
+	if cfg.EnableThreading != nil {
+		k.config.EnableThreading = *cfg.EnableThreading
+	}
+	if cfg.MaxWorkers > 0 {
+		k.config.MaxWorkers = cfg.MaxWorkers
+	}
+	if cfg.EnableThreading != nil || cfg.MaxWorkers > 0 {
+		if k.config.EnableThreading {
+			goruntime.GOMAXPROCS(k.config.MaxWorkers)
+		} else {
+			goruntime.GOMAXPROCS(1)
+		}
+	}
+	if cfg.CacheSize > 0 {
+		k.config.CacheSize = cfg.CacheSize
+	}
+	if cfg.SABSize > 0 {
+		k.config.SABSize = cfg.SABSize
+	}
+	if cfg.Region != "" && k.meshCoordinator != nil {
+		k.meshCoordinator.SetRegion(cfg.Region)
+	}
+	if len(cfg.SignalingServers) > 0 {
+		tc := k.transportConfig
+		tc.SignalingServers = append([]string(nil), cfg.SignalingServers...)
+		tc.WebSocketURL = tc.SignalingServers[0]
+		if err := k.replaceTransport(tc); err != nil {
+			return fmt.Errorf("failed to apply signaling servers: %w", err)
+		}
+	}
+
+	if cfg.LogLevel != "" {
+		level, _ := utils.ParseLogLevel(cfg.LogLevel)
+		k.config.LogLevel = level
+		utils.SetLogLevel(level)
+	}
+	if cfg.FrameBudgetMs > 0 {
+		k.config.FrameBudget = time.Duration(cfg.FrameBudgetMs * float64(time.Millisecond))
+		k.frameBudget.SetTarget(k.config.FrameBudget)
+	}
+	if cfg.RingWaitTimeoutMs > 0 {
+		k.config.RingWaitTimeout = time.Duration(cfg.RingWaitTimeoutMs * float64(time.Millisecond))
+		if k.supervisor != nil {
+			if bridge := k.supervisor.GetBridge(); bridge != nil {
+				bridge.SetRingWaitTimeout(k.config.RingWaitTimeout)
+			}
+		}
+	}
+	if cfg.TraceSampleRate != nil {
+		k.config.TraceSampleRate = *cfg.TraceSampleRate
+		tracing.Default().SetSampleRate(k.config.TraceSampleRate)
+	}
+	if cfg.RoleProfile != "" {
+		profile, _ := inosruntime.ParseRoleProfile(cfg.RoleProfile)
+		if err := k.SetRoleProfile(profile); err != nil {
+			return err
+		}
+	}
+
+	k.logger.Info("Kernel configuration applied",
+		utils.Int("workers", k.config.MaxWorkers),
+		utils.String("state", k.StateName()))
+	return nil
+}
+
+// configMap renders the effective config for getKernelConfig.
+func (k *Kernel) configMap() map[string]interface{} {
+	k.configMu.Lock()
+	defer k.configMu.Unlock()
+
+	servers := make([]interface{}, 0, len(k.transportConfig.SignalingServers))
+	for _, server := range k.transportConfig.SignalingServers {
+		servers = append(servers, server)
+	}
+	region := ""
+	if k.meshCoordinator != nil {
+		region, _ = k.meshCoordinator.GetTelemetry()["region"].(string)
+	}
+	return map[string]interface{}{
+		"enableThreading":   k.config.EnableThreading,
+		"maxWorkers":        k.config.MaxWorkers,
+		"cacheSize":         float64(k.config.CacheSize),
+		"region":            region,
+		"signalingServers":  servers,
+		"sabSize":           float64(k.config.SABSize),
+		"logLevel":          k.config.LogLevel.String(),
+		"roleProfile":       string(k.config.RoleProfile),
+		"frameBudgetMs":     float64(k.config.FrameBudget.Microseconds()) / 1000,
+		"ringWaitTimeoutMs": float64(k.config.RingWaitTimeout.Microseconds()) / 1000,
+		"traceSampleRate":   k.config.TraceSampleRate,
+		"state":             k.StateName(),
+	}
+}
+
+// jsConfigureKernel applies a host config given as a JSON string or object:
+// configureKernel({maxWorkers: 2, logLevel: "debug"}). Boot-time fields are
+// rejected once the SAB has been injected.
+func jsConfigureKernel(this js.Value, args []js.Value) interface{} {
+	if len(args) < 1 {
+		return js.ValueOf(map[string]interface{}{"error": "missing kernel config"})
+	}
+	if kernelInstance == nil {
+		return js.ValueOf(map[string]interface{}{"error": "kernel not initialized"})
+	}
+
+	raw := args[0]
+	if raw.Type() == js.TypeObject {
+		raw = js.Global().Get("JSON").Call("stringify", raw)
+	}
+	if raw.Type() != js.TypeString {
+		return js.ValueOf(map[string]interface{}{"error": "kernel config must be a JSON string or object"})
+	}
+
+	var cfg HostKernelConfig
+	if err := json.Unmarshal([]byte(raw.String()), &cfg); err != nil {
+		return js.ValueOf(map[string]interface{}{"error": fmt.Sprintf("invalid kernel config: %v", err)})
+	}
+	if err := kernelInstance.Configure(cfg); err != nil {
+		return js.ValueOf(map[string]interface{}{"error": err.Error()})
+	}
+	result := kernelInstance.configMap()
+	result["success"] = true
+	return js.ValueOf(result)
+}
+
+// jsGetKernelConfig returns the effective kernel config.
+func jsGetKernelConfig(this js.Value, args []js.Value) interface{} {
+	if kernelInstance == nil {
+		return js.ValueOf(map[string]interface{}{"error": "kernel not initialized"})
+	}
+	return js.ValueOf(kernelInstance.configMap())
+}
@@ -17,6 +17,8 @@ import (
 	"github.com/nmxmxh/inos_v1/kernel/core/mesh/transport"
 	inosruntime "github.com/nmxmxh/inos_v1/kernel/runtime"
 	"github.com/nmxmxh/inos_v1/kernel/threads"
+	"github.com/nmxmxh/inos_v1/kernel/threads/foundation"
+	"github.com/nmxmxh/inos_v1/kernel/threads/supervisor"
 	"github.com/nmxmxh/inos_v1/kernel/utils"
 )
 
@@ -28,6 +30,7 @@ const (
 	StateBooting
 	StateWaitingForSAB
 	StateRunning
+	StateBackground // Running with the host page hidden
 	StateStopping
 	StateStopped
 	StatePanic
@@ -38,6 +41,7 @@ var stateNames = map[KernelState]string{
 	StateBooting:       "BOOTING",
 	StateWaitingForSAB: "WAITING_FOR_SAB",
 	StateRunning:       "RUNNING",
+	StateBackground:    "BACKGROUND",
 	StateStopping:      "STOPPING",
 	StateStopped:       "STOPPED",
 	StatePanic:         "PANIC",
@@ -48,21 +52,34 @@ type KernelConfig struct {
 	EnableThreading bool
 	MaxWorkers      int
 	CacheSize       uint64
+	SABSize         uint32 // Expected SAB size from the host; 0 accepts any valid size
 	LogLevel        utils.LogLevel
+	RingWaitTimeout time.Duration // Bounded wait for space on a full SAB ring
+
+	TraceExportInterval time.Duration // How often finished spans go to the host outbox; 0 disables
+	TraceSampleRate     float64       // Fraction of new traces recorded
+
+	FrameBudget time.Duration // Frame period above which a frame counts as blown
+
+	RoleProfile inosruntime.RoleProfile // What the node contributes; empty is balanced
 }
 
 // Kernel is the root object managing the INOS runtime
 type Kernel struct {
-	state  atomic.Int32
-	config *KernelConfig
-	logger *utils.Logger
+	state    atomic.Int32
+	config   *KernelConfig
+	configMu sync.Mutex // Serializes host reconfiguration
+	logger   *utils.Logger
 
 	// Core Components
 	supervisor      *threads.Supervisor
 	meshCoordinator *mesh.MeshCoordinator
 	sabSize         atomic.Uint32
 	meshIdentity    MeshIdentity
+	transportConfig transport.TransportConfig
 	roleConfig      inosruntime.RoleConfig
+	detectedRole    inosruntime.RoleConfig // Before the role profile is applied
+	roleMu          sync.Mutex
 
 	// Lifecycle
 	startTime time.Time
@@ -72,12 +89,32 @@ type Kernel struct {
 
 	// Reactive Synchronization
 	sabReady chan struct{}
+
+	// Host command bus, ready once the SAB bridge exists
+	commands   *supervisor.CommandBus
+	commandsMu sync.RWMutex
+
+	// Last crash report, JSON encoded
+	lastCrash []byte
+	crashMu   sync.Mutex
+
+	// Failover: in-flight job journal and an idle standby supervisor
+	journal   *supervisor.JobJournal
+	standby   *threads.Supervisor
+	standbyMu sync.Mutex
+
+	// Per-frame time attribution shared by the bridge and the mesh
+	frameBudget *foundation.FrameBudget
+
+	// Load sampled for the delegation engine and the mesh
+	loadMonitor *foundation.LoadMonitor
 }
 
 // NewKernel creates a new kernel instance
 func NewKernel() *Kernel {
 	config := detectOptimalConfig()
 	meshConfig := loadMeshConfig()
+	config.RoleProfile = meshConfig.RoleProfile
 
 	logger := utils.NewLogger(utils.LoggerConfig{
 		Level:      config.LogLevel,
@@ -88,11 +125,32 @@ func NewKernel() *Kernel {
 
 	ctx, cancel := context.WithCancel(context.Background())
 
-	// Initialize Mesh Components
-	nodeID := meshConfig.Identity.NodeID
+	// Initialize Mesh Components. The node ID comes from the persisted
+	// identity key so it is stable across reloads.
+	identity, err := mesh.LoadOrCreateNodeIdentity(hostIdentityStore{})
+	if err != nil {
+		logger.Error("Failed to load node identity, using an ephemeral key", utils.Err(err))
+		identity, _ = mesh.NewEphemeralNodeIdentity()
+	}
+	nodeID := identity.NodeID()
+	if configured := meshConfig.Identity.NodeID; configured != "" && configured != nodeID {
+		logger.Warn("Ignoring configured nodeId; node ID is derived from the identity key",
+			utils.String("configured", configured),
+			utils.String("node_id", nodeID))
+	}
+	meshConfig.Identity.NodeID = nodeID
+
 	tr, _ := transport.NewWebRTCTransport(nodeID, meshConfig.Transport, nil)
 	m := mesh.NewMeshCoordinator(nodeID, meshConfig.Region, tr, nil)
+	if err := m.SetNodeIdentity(identity); err != nil {
+		logger.Error("Failed to install node identity", utils.Err(err))
+	}
 	m.SetIdentity(meshConfig.Identity.DID, meshConfig.Identity.DeviceID, meshConfig.Identity.DisplayName)
+	m.AddBootstrapPeers(meshConfig.Peers...)
+	m.AddRendezvousDomains(meshConfig.Rendezvous...)
+	if meshConfig.Demo {
+		m.EnableDemoMode(meshConfig.DemoPeers)
+	}
 
 	k := &Kernel{
 		config:          config,
@@ -101,6 +159,7 @@ func NewKernel() *Kernel {
 		cancel:          cancel,
 		meshCoordinator: m,
 		meshIdentity:    meshConfig.Identity,
+		transportConfig: meshConfig.Transport,
 		sabReady:        make(chan struct{}),
 	}
 
@@ -141,7 +200,11 @@ func (k *Kernel) Boot() {
 	// or block here. Since it's CPU bound (compute test), we do it here.
 	profiler := inosruntime.NewProfiler()
 	caps := profiler.Profile()
-	k.roleConfig = inosruntime.AssignRole(caps)
+	k.roleMu.Lock()
+	k.detectedRole = inosruntime.AssignRole(caps)
+	k.roleConfig = k.selectRole(k.detectedRole)
+	k.roleMu.Unlock()
+	k.applyMemoryProfile(k.roleConfig.Memory)
 
 	// Phase 2: Reactive Synchronization
 	// Wait for InjectSAB to signal the channel
@@ -170,19 +233,49 @@ func (k *Kernel) Boot() {
 		k.logger.Error("Failed to initialize compute layer", utils.Err(err))
 	}
 
+	k.watchRingBackpressure()
+	k.startCommandBus()
+	k.startJobJournal()
+	k.startTraceExport()
+
 	k.logger.Info("Starting supervisor hierarchy")
 	k.supervisor.Start()
+	go k.prepareWarmStandby()
+
+	k.startFrameBudget()
+	k.startLoadMonitor(caps)
 
 	// Finalize Mesh Integration
 	if k.meshCoordinator != nil {
 		k.meshCoordinator.SetStorage(k.supervisor)
 		// Inject SAB bridge for metrics reporting
 		k.meshCoordinator.SetSABBridge(k.supervisor.GetBridge())
-		// Inject monitor for delegation engine
-		k.meshCoordinator.SetMonitor(k.supervisor)
+		// Sampled load drives offloading, gossip pacing and the busy flag
+		k.meshCoordinator.SetMonitor(k.loadMonitor)
+		// Answer feature probes so peers only delegate modules we can run
+		k.meshCoordinator.SetFeatureProber(&kernelFeatureProber{k: k})
+		// Fill the supervisor section of the SAB health block
+		k.meshCoordinator.SetSupervisorHealthSource(&kernelSupervisorHealth{k: k})
+		// Advertise the memory the profiler saw; cores and SIMD are detected
+		k.meshCoordinator.SetHardwareDescriptor(&mesh.HardwareDescriptor{
+			MemoryBytes: uint64(caps.DeviceMemoryGB * (1 << 30)),
+		})
+		// Restore reputation, credits and pins from the previous session
+		stateStore := localMeshStateStore{}
+		k.meshCoordinator.SetReputationStore(stateStore)
+		if err := k.meshCoordinator.SetLedgerStore(stateStore); err != nil {
+			k.logger.Warn("Failed to restore mesh ledger", utils.Err(err))
+		}
+		if err := k.meshCoordinator.SetPinStore(stateStore); err != nil {
+			k.logger.Warn("Failed to restore pinned chunks", utils.Err(err))
+		}
 
 		// Adaptive Mesh: Apply Role Configuration
 		k.meshCoordinator.ApplyRoleConfig(k.roleConfig)
+		k.watchAdmissionPolicy()
+		k.watchBattery()
+		k.watchVisibility()
+		k.watchNetwork()
 
 		if err := k.meshCoordinator.Start(k.ctx); err != nil {
 			k.logger.Warn("Failed to start Mesh Coordinator", utils.Err(err))
@@ -194,6 +287,39 @@ func (k *Kernel) Boot() {
 		"threading": k.config.EnableThreading,
 		"workers":   k.config.MaxWorkers,
 		"role":      k.roleConfig.Role.String(),
+		"profile":   string(k.roleConfig.Contribution.Profile),
+	})
+}
+
+// applyMemoryProfile caps the worker pool at the profile's bound. Caches and
+// queues are bounded by the mesh when the role config is applied.
+func (k *Kernel) applyMemoryProfile(profile inosruntime.MemoryProfile) {
+	if profile.MaxWorkers > 0 && k.config.MaxWorkers > profile.MaxWorkers {
+		k.config.MaxWorkers = profile.MaxWorkers
+		if k.config.EnableThreading {
+			goruntime.GOMAXPROCS(k.config.MaxWorkers)
+		}
+	}
+	k.logger.Info("Memory profile selected",
+		utils.String("tier", string(profile.Tier)),
+		utils.Int("workers", k.config.MaxWorkers))
+}
+
+// watchRingBackpressure applies the ring wait timeout and tells the host
+// when it stops draining an outbox, so the UI can surface a stalled consumer
+// instead of silently losing messages.
+func (k *Kernel) watchRingBackpressure() {
+	bridge := k.supervisor.GetBridge()
+	if bridge == nil {
+		return
+	}
+	bridge.SetRingWaitTimeout(k.config.RingWaitTimeout)
+	bridge.SetRingStallHandler(func(rs supervisor.RingStats) {
+		k.logger.Warn("SAB ring consumer stalled",
+			utils.String("ring", rs.Ring),
+			utils.Uint64("pending_bytes", uint64(rs.PendingBytes)),
+			utils.Uint64("epochs", uint64(rs.StalledEpochs)))
+		k.notifyHost("sab:consumer_stalled", ringStatsToMap(rs))
 	})
 }
 
@@ -202,7 +328,7 @@ func (k *Kernel) InjectSAB(ptr unsafe.Pointer, size uint32) error {
 	defer k.recoverPanic()
 
 	if KernelState(k.state.Load()) != StateWaitingForSAB {
-		if KernelState(k.state.Load()) == StateRunning {
+		if state := KernelState(k.state.Load()); state == StateRunning || state == StateBackground {
 			k.logger.Warn("InjectSAB called but kernel already RUNNING")
 			return nil
 		}
@@ -212,6 +338,13 @@ func (k *Kernel) InjectSAB(ptr unsafe.Pointer, size uint32) error {
 	if size == 0 {
 		return fmt.Errorf("injected SAB size cannot be 0")
 	}
+	if expected := k.config.SABSize; expected != 0 && size != expected {
+		return fmt.Errorf("injected SAB size %d does not match configured size %d", size, expected)
+	}
+
+	if err := k.validateSABLayout(size); err != nil {
+		return err
+	}
 
 	time.AfterFunc(0, func() {
 		k.logger.Info("Injecting SharedArrayBuffer", utils.Uint64("size", uint64(size)))
@@ -219,13 +352,7 @@ func (k *Kernel) InjectSAB(ptr unsafe.Pointer, size uint32) error {
 	k.sabSize.Store(size)
 
 	// Initialize Root Supervisor with the real pointer immediately
-	k.supervisor = threads.NewRootSupervisor(k.ctx, threads.SupervisorConfig{
-		MeshCoordinator: k.meshCoordinator,
-		Logger:          k.logger,
-		SAB:             ptr,
-		MaxWorkers:      k.config.MaxWorkers,
-		Role:            k.roleConfig,
-	})
+	k.supervisor = threads.NewRootSupervisor(k.ctx, k.supervisorConfig(ptr))
 
 	k.setState(StateRunning)
 	time.AfterFunc(0, func() {
@@ -242,6 +369,21 @@ func (k *Kernel) Shutdown() {
 	k.setState(StateStopping)
 	k.logger.Info("Kernel Shutting Down...")
 
+	// Hand off mesh state while storage and the network are still up
+	if k.meshCoordinator != nil && !k.meshCoordinator.IsDeparting() {
+		report := k.meshCoordinator.Depart(context.Background(), "shutdown")
+		k.notifyHost("kernel:departure", map[string]interface{}{
+			"announced":    report.Announced,
+			"releasedJobs": report.ReleasedJobs,
+			"uniqueChunks": report.UniqueChunks,
+			"pushedChunks": report.PushedChunks,
+			"persisted":    report.Persisted,
+			"elapsedMs":    report.Elapsed.Milliseconds(),
+			"errors":       len(report.Errors),
+		})
+		_ = k.meshCoordinator.Stop()
+	}
+
 	if k.supervisor != nil {
 		k.supervisor.Stop()
 	}
@@ -272,6 +414,23 @@ func (k *Kernel) StateName() string {
 	return stateNames[KernelState(k.state.Load())]
 }
 
+// restartPolicy is the supervisor restart policy; children that keep
+// failing escalate to the host.
+func (k *Kernel) restartPolicy() threads.RestartPolicy {
+	policy := threads.DefaultRestartPolicy()
+	policy.OnEscalate = func(child string, err error) {
+		k.logger.Error("Supervisor escalated thread failure",
+			utils.String("thread", child),
+			utils.Err(err))
+		k.notifyHost("kernel:escalation", map[string]interface{}{
+			"thread": child,
+			"error":  err.Error(),
+		})
+		go k.failover(child)
+	}
+	return policy
+}
+
 // Helper: Global Panic Recovery
 func (k *Kernel) recoverPanic() {
 	if r := recover(); r != nil {
@@ -281,9 +440,11 @@ func (k *Kernel) recoverPanic() {
 			utils.Any("reason", r),
 			utils.String("stack", stack))
 
+		report := k.captureCrashReport(r, stack)
 		k.notifyHost("kernel:panic", map[string]interface{}{
-			"reason": fmt.Sprintf("%v", r),
-			"stack":  stack,
+			"reason":      fmt.Sprintf("%v", r),
+			"stack":       stack,
+			"crashReport": report != nil,
 		})
 	}
 }
@@ -0,0 +1,71 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"time"
+
+	inosruntime "github.com/nmxmxh/inos_v1/kernel/runtime"
+	"github.com/nmxmxh/inos_v1/kernel/threads/foundation"
+)
+
+// loadSampleInterval is how often the kernel samples its load.
+const loadSampleInterval = time.Second
+
+// wasmMaxMemory is the most linear memory a wasm32 module can address.
+const wasmMaxMemory = 4 << 30
+
+// startLoadMonitor samples the kernel's load for the delegation engine and
+// the mesh. Heap counts as full at half the device memory, bounded by what
+// wasm32 can address.
+func (k *Kernel) startLoadMonitor(caps inosruntime.RuntimeCapabilities) {
+	cfg := foundation.DefaultLoadMonitorConfig()
+	cfg.FrameTarget = k.frameBudget.Stats().Target
+	cfg.MaxQueueDepth = max(cfg.MaxQueueDepth, 8*k.config.MaxWorkers)
+	if caps.DeviceMemoryGB > 0 {
+		cfg.MemoryLimit = min(uint64(caps.DeviceMemoryGB*(1<<30))/2, wasmMaxMemory)
+	}
+
+	k.loadMonitor = foundation.NewLoadMonitor(cfg, k.sampleLoad)
+	go k.loadMonitor.Run(k.ctx, loadSampleInterval)
+}
+
+// sampleLoad reads the current supervisor, so a promoted standby is
+// sampled without rewiring the monitor.
+func (k *Kernel) sampleLoad(s *foundation.LoadSample) {
+	sup := k.supervisor
+	if sup == nil {
+		return
+	}
+	s.QueueDepth = sup.QueueDepth()
+
+	bridge := sup.GetBridge()
+	if bridge == nil {
+		return
+	}
+	s.FrameLatency = bridge.GetFrameLatency()
+	for _, rs := range bridge.RingStats() {
+		s.RingOccupancy = max(s.RingOccupancy, rs.Occupancy())
+	}
+}
+
+// loadStatsMap renders load stats for getKernelStats.
+func loadStatsMap(stats foundation.LoadStats) map[string]interface{} {
+	return map[string]interface{}{
+		"load":          stats.Load,
+		"samples":       float64(stats.Samples),
+		"goroutines":    float64(stats.Last.Goroutines),
+		"heapBytes":     float64(stats.Last.HeapBytes),
+		"ringOccupancy": stats.Last.RingOccupancy,
+		"frameMs":       float64(stats.Last.FrameLatency.Microseconds()) / 1000,
+		"queueDepth":    float64(stats.Last.QueueDepth),
+		"pressure": map[string]interface{}{
+			"goroutines": stats.Breakdown.Goroutines,
+			"memory":     stats.Breakdown.Memory,
+			"rings":      stats.Breakdown.Rings,
+			"frames":     stats.Breakdown.Frames,
+			"queue":      stats.Breakdown.Queue,
+		},
+	}
+}
@@ -0,0 +1,117 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"encoding/json"
+	"log/slog"
+	"syscall/js"
+
+	"github.com/nmxmxh/inos_v1/kernel/utils"
+)
+
+// installSlogBridge routes log/slog output, which the mesh uses, through the
+// kernel logger so level overrides and log subscriptions cover it. It must
+// run before subsystems capture slog.Default.
+func installSlogBridge() {
+	slog.SetDefault(slog.New(utils.NewSlogHandler(utils.DefaultLogger("mesh"))))
+}
+
+// jsSetLogLevel changes log levels at runtime: setLogLevel("debug") for
+// every component, setLogLevel("debug", "transport") for one, and
+// setLogLevel("reset") to drop all overrides.
+func jsSetLogLevel(this js.Value, args []js.Value) interface{} {
+	if len(args) < 1 || args[0].Type() != js.TypeString {
+		return js.ValueOf(map[string]interface{}{"error": "missing log level"})
+	}
+	if args[0].String() == "reset" {
+		utils.ClearLogLevels()
+		return js.ValueOf(map[string]interface{}{"success": true})
+	}
+	level, err := utils.ParseLogLevel(args[0].String())
+	if err != nil {
+		return js.ValueOf(map[string]interface{}{"error": err.Error()})
+	}
+	if len(args) > 1 && args[1].Type() == js.TypeString && args[1].String() != "" {
+		utils.SetComponentLogLevel(args[1].String(), level)
+	} else {
+		utils.SetLogLevel(level)
+	}
+	return js.ValueOf(map[string]interface{}{"success": true, "level": level.String()})
+}
+
+// jsGetLogLevels returns the active overrides.
+func jsGetLogLevels(this js.Value, args []js.Value) interface{} {
+	global, components := utils.LogLevels()
+	overrides := make(map[string]interface{}, len(components))
+	for component, level := range components {
+		overrides[component] = level.String()
+	}
+	result := map[string]interface{}{"success": true, "components": overrides}
+	if global != nil {
+		result["global"] = global.String()
+	}
+	return js.ValueOf(result)
+}
+
+// jsSubscribeLogs starts buffering structured log records:
+// subscribeLogs({level: "warn", components: ["transport"], buffer: 512}).
+// It returns a subscription ID for readLogs.
+func jsSubscribeLogs(this js.Value, args []js.Value) interface{} {
+	filter := utils.LogFilter{MinLevel: utils.DEBUG}
+	buffer := 0
+	if len(args) > 0 && args[0].Type() == js.TypeObject {
+		opts := args[0]
+		if v := opts.Get("level"); v.Type() == js.TypeString {
+			level, err := utils.ParseLogLevel(v.String())
+			if err != nil {
+				return js.ValueOf(map[string]interface{}{"error": err.Error()})
+			}
+			filter.MinLevel = level
+		}
+		if v := opts.Get("components"); v.Type() == js.TypeObject {
+			filter.Components = readStringSlice(v)
+		}
+		if v := opts.Get("buffer"); v.Type() == js.TypeNumber {
+			buffer = v.Int()
+		}
+	}
+	return js.ValueOf(map[string]interface{}{
+		"success":        true,
+		"subscriptionId": utils.SubscribeLogs(filter, buffer),
+	})
+}
+
+// jsReadLogs drains buffered records as JSON: readLogs(id, max).
+func jsReadLogs(this js.Value, args []js.Value) interface{} {
+	if len(args) < 1 || args[0].Type() != js.TypeString {
+		return js.ValueOf(map[string]interface{}{"error": "missing subscription ID"})
+	}
+	max := 0
+	if len(args) > 1 && args[1].Type() == js.TypeNumber {
+		max = args[1].Int()
+	}
+	records, lost, err := utils.ReadLogs(args[0].String(), max)
+	if err != nil {
+		return js.ValueOf(map[string]interface{}{"error": err.Error()})
+	}
+	data, err := json.Marshal(records)
+	if err != nil {
+		return js.ValueOf(map[string]interface{}{"error": err.Error()})
+	}
+	return js.ValueOf(map[string]interface{}{
+		"success": true,
+		"count":   len(records),
+		"lost":    float64(lost),
+		"records": string(data),
+	})
+}
+
+// jsUnsubscribeLogs closes a log subscription.
+func jsUnsubscribeLogs(this js.Value, args []js.Value) interface{} {
+	if len(args) < 1 || args[0].Type() != js.TypeString {
+		return js.ValueOf(map[string]interface{}{"error": "missing subscription ID"})
+	}
+	return js.ValueOf(map[string]interface{}{"success": utils.UnsubscribeLogs(args[0].String())})
+}
@@ -7,6 +7,7 @@ import (
 	"runtime"
 	"runtime/debug"
 	"syscall/js"
+	"time"
 )
 
 // Global singleton
@@ -20,12 +21,14 @@ var sabBasePtr uintptr
 
 func main() {
 	// 1. Create Kernel Instance
+	installSlogBridge()
 	kernelInstance = NewKernel()
 
 	// 2. Export Functions
 	js.Global().Set("initializeSharedMemory", js.FuncOf(jsInitializeSharedMemory))
 	js.Global().Set("getSharedArrayBuffer", js.FuncOf(jsGetSharedArrayBuffer))
 	js.Global().Set("getKernelStats", js.FuncOf(jsGetKernelStats))
+	js.Global().Set("configureKernel", js.FuncOf(jsConfigureKernel))
 	js.Global().Set("shutdown", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
 		if kernelInstance != nil {
 			kernelInstance.Shutdown()
@@ -38,6 +41,18 @@ func main() {
 	kernel.Set("submitJob", js.FuncOf(jsSubmitJob))
 	kernel.Set("deserializeResult", js.FuncOf(jsDeserializeResult))
 	kernel.Set("getStats", js.FuncOf(jsGetKernelStats))
+	kernel.Set("submitCommand", js.FuncOf(jsSubmitCommand))
+	kernel.Set("getLastCrashReport", js.FuncOf(jsGetLastCrashReport))
+	kernel.Set("getTraces", js.FuncOf(jsGetTraces))
+	kernel.Set("setBackground", js.FuncOf(jsSetBackground))
+	kernel.Set("setNetworkStatus", js.FuncOf(jsSetNetworkStatus))
+	kernel.Set("configure", js.FuncOf(jsConfigureKernel))
+	kernel.Set("getConfig", js.FuncOf(jsGetKernelConfig))
+	kernel.Set("setLogLevel", js.FuncOf(jsSetLogLevel))
+	kernel.Set("getLogLevels", js.FuncOf(jsGetLogLevels))
+	kernel.Set("subscribeLogs", js.FuncOf(jsSubscribeLogs))
+	kernel.Set("readLogs", js.FuncOf(jsReadLogs))
+	kernel.Set("unsubscribeLogs", js.FuncOf(jsUnsubscribeLogs))
 	js.Global().Set("kernel", kernel)
 
 	// Expose bridge functions globally for JS proxy compatibility
@@ -59,6 +74,9 @@ func main() {
 	mesh.Set("registerChunk", js.FuncOf(jsMeshRegisterChunk))
 	mesh.Set("unregisterChunk", js.FuncOf(jsMeshUnregisterChunk))
 	mesh.Set("scheduleChunkPrefetch", js.FuncOf(jsMeshScheduleChunkPrefetch))
+	mesh.Set("pinChunk", js.FuncOf(jsMeshPinChunk))
+	mesh.Set("unpinChunk", js.FuncOf(jsMeshUnpinChunk))
+	mesh.Set("getPinStatus", js.FuncOf(jsMeshGetPinStatus))
 	mesh.Set("reportPeerPerformance", js.FuncOf(jsMeshReportPeerPerformance))
 	js.Global().Set("jsMeshConnectToPeer", js.FuncOf(jsMeshConnectToPeer))
 	mesh.Set("getPeerReputation", js.FuncOf(jsMeshGetPeerReputation))
@@ -67,16 +85,59 @@ func main() {
 	mesh.Set("disconnectFromPeer", js.FuncOf(jsMeshDisconnectFromPeer))
 	mesh.Set("subscribeToEvents", js.FuncOf(jsMeshSubscribeToEvents))
 	mesh.Set("unsubscribeFromEvents", js.FuncOf(jsMeshUnsubscribeFromEvents))
+	mesh.Set("subscribeMeshEvents", js.FuncOf(jsMeshSubscribeMeshEvents))
+	mesh.Set("unsubscribeMeshEvents", js.FuncOf(jsMeshUnsubscribeMeshEvents))
+	mesh.Set("readMeshEvents", js.FuncOf(jsMeshReadMeshEvents))
+	mesh.Set("getConnectivityReport", js.FuncOf(jsGetConnectivityReport))
+	mesh.Set("bootstrap", js.FuncOf(jsMeshBootstrap))
+	mesh.Set("blockPeer", js.FuncOf(jsMeshBlockPeer))
+	mesh.Set("unblockPeer", js.FuncOf(jsMeshUnblockPeer))
+	mesh.Set("allowPeer", js.FuncOf(jsMeshAllowPeer))
+	mesh.Set("disallowPeer", js.FuncOf(jsMeshDisallowPeer))
+	mesh.Set("setRegionPolicy", js.FuncOf(jsMeshSetRegionPolicy))
+	mesh.Set("getAdmissionPolicy", js.FuncOf(jsMeshGetAdmissionPolicy))
+	mesh.Set("setAdmissionPolicy", js.FuncOf(jsMeshSetAdmissionPolicy))
+	mesh.Set("unquarantinePeer", js.FuncOf(jsMeshUnquarantinePeer))
+	mesh.Set("getQuarantinedPeers", js.FuncOf(jsMeshGetQuarantinedPeers))
+	mesh.Set("exportTopology", js.FuncOf(jsMeshExportTopology))
+	mesh.Set("getDelegationAudit", js.FuncOf(jsMeshGetDelegationAudit))
+	mesh.Set("exportAudit", js.FuncOf(jsMeshExportAudit))
+	mesh.Set("getDelegationReceipts", js.FuncOf(jsMeshGetDelegationReceipts))
+	mesh.Set("verifyDelegationReceipt", js.FuncOf(jsMeshVerifyDelegationReceipt))
+	mesh.Set("fileDispute", js.FuncOf(jsMeshFileDispute))
+	mesh.Set("getDisputes", js.FuncOf(jsMeshGetDisputes))
+	mesh.Set("publish", js.FuncOf(jsMeshPublish))
+	mesh.Set("subscribe", js.FuncOf(jsMeshSubscribe))
+	mesh.Set("unsubscribe", js.FuncOf(jsMeshUnsubscribe))
+	mesh.Set("readTopic", js.FuncOf(jsMeshReadTopic))
+	mesh.Set("configureTopic", js.FuncOf(jsMeshConfigureTopic))
+	mesh.Set("getTimeSync", js.FuncOf(jsMeshGetTimeSync))
+	mesh.Set("warmManifest", js.FuncOf(jsMeshWarmManifest))
+	mesh.Set("getWarmStatus", js.FuncOf(jsMeshGetWarmStatus))
+	mesh.Set("registerModule", js.FuncOf(jsMeshRegisterModule))
+	mesh.Set("getModules", js.FuncOf(jsMeshGetModules))
+	mesh.Set("setGPUCapability", js.FuncOf(jsMeshSetGPUCapability))
+	mesh.Set("setBatteryStatus", js.FuncOf(jsMeshSetBatteryStatus))
+	mesh.Set("getPowerStatus", js.FuncOf(jsMeshGetPowerStatus))
+	mesh.Set("setRoleProfile", js.FuncOf(jsMeshSetRoleProfile))
+	mesh.Set("getRoleProfile", js.FuncOf(jsMeshGetRoleProfile))
+	registerChaosAPI(mesh)
 	js.Global().Set("mesh", mesh)
 	js.Global().Set("subscribeToEvents", js.FuncOf(jsMeshSubscribeToEvents))
 	js.Global().Set("unsubscribeFromEvents", js.FuncOf(jsMeshUnsubscribeFromEvents))
+	js.Global().Set("meshPublish", js.FuncOf(jsMeshPublish))
+	js.Global().Set("meshSubscribe", js.FuncOf(jsMeshSubscribe))
 	js.Global().Set("jsMeshGetTelemetry", js.FuncOf(jsMeshGetTelemetry))
+	js.Global().Set("jsGetConnectivityReport", js.FuncOf(jsGetConnectivityReport))
 
 	// Expose SAB metadata to Host (Dynamic Grounding)
 	js.Global().Set("getSystemSABAddress", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
 		// Return the actual SAB base pointer (set by InjectSAB at runtime)
 		return js.ValueOf(int(sabBasePtr))
 	}))
+	js.Global().Set("getSABLayout", js.FuncOf(jsGetSABLayout))
+	js.Global().Set("allocateSABRegion", js.FuncOf(jsAllocateSABRegion))
+	js.Global().Set("freeSABRegion", js.FuncOf(jsFreeSABRegion))
 	js.Global().Set("getSystemSABSize", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
 		if kernelInstance != nil {
 			return js.ValueOf(int(kernelInstance.GetSABSize()))
@@ -148,5 +209,11 @@ func detectOptimalConfig() *KernelConfig {
 		EnableThreading: true,
 		MaxWorkers:      workers,
 		LogLevel:        1, // INFO
+		RingWaitTimeout: 5 * time.Millisecond,
+
+		TraceExportInterval: 5 * time.Second,
+		TraceSampleRate:     1,
+
+		FrameBudget: 20 * time.Millisecond,
 	}
 }
@@ -5,10 +5,12 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"syscall/js"
 	"time"
 
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh"
 	"github.com/nmxmxh/inos_v1/kernel/core/mesh/transport"
 	"github.com/nmxmxh/inos_v1/kernel/threads/sab"
 )
@@ -146,6 +148,12 @@ func jsMeshDelegateCompute(this js.Value, args []js.Value) interface{} {
 
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
+	if module := job.Get("module"); module.Type() == js.TypeString && module.String() != "" {
+		ctx = mesh.WithModule(ctx, module.String())
+	}
+	if key := job.Get("idempotencyKey"); key.Type() == js.TypeString && key.String() != "" {
+		ctx = mesh.WithIdempotencyKey(ctx, key.String())
+	}
 	result, err := coord.DelegateCompute(ctx, operation, inputDigest, data)
 	if err != nil {
 		return js.ValueOf(map[string]interface{}{"error": err.Error()})
@@ -192,6 +200,68 @@ func jsMeshUnregisterChunk(this js.Value, args []js.Value) interface{} {
 	return js.ValueOf(map[string]interface{}{"success": true})
 }
 
+// jsMeshPinChunk pins a chunk against eviction: pinChunk(hash, replicas?).
+// Replicas defaults to the configured pin target.
+func jsMeshPinChunk(this js.Value, args []js.Value) interface{} {
+	if len(args) < 1 {
+		return js.ValueOf(map[string]interface{}{"error": "missing chunk hash"})
+	}
+	if kernelInstance == nil || kernelInstance.meshCoordinator == nil {
+		return js.ValueOf(map[string]interface{}{"error": "mesh not initialized"})
+	}
+	replicas := 0
+	if len(args) > 1 && args[1].Type() == js.TypeNumber {
+		replicas = args[1].Int()
+	}
+	if err := kernelInstance.meshCoordinator.PinChunk(args[0].String(), replicas); err != nil {
+		return js.ValueOf(map[string]interface{}{"error": err.Error()})
+	}
+	return js.ValueOf(map[string]interface{}{"success": true})
+}
+
+func jsMeshUnpinChunk(this js.Value, args []js.Value) interface{} {
+	if len(args) < 1 {
+		return js.ValueOf(map[string]interface{}{"error": "missing chunk hash"})
+	}
+	if kernelInstance == nil || kernelInstance.meshCoordinator == nil {
+		return js.ValueOf(map[string]interface{}{"error": "mesh not initialized"})
+	}
+	if err := kernelInstance.meshCoordinator.UnpinChunk(args[0].String()); err != nil {
+		return js.ValueOf(map[string]interface{}{"error": err.Error()})
+	}
+	return js.ValueOf(map[string]interface{}{"success": true})
+}
+
+// jsMeshGetPinStatus lists every pinned chunk with its replica count against
+// target, least replicated first.
+func jsMeshGetPinStatus(this js.Value, args []js.Value) interface{} {
+	if kernelInstance == nil || kernelInstance.meshCoordinator == nil {
+		return js.ValueOf(map[string]interface{}{"error": "mesh not initialized"})
+	}
+	statuses := kernelInstance.meshCoordinator.GetPinStatus()
+	pins := make([]interface{}, 0, len(statuses))
+	for _, st := range statuses {
+		pin := map[string]interface{}{
+			"hash":     st.Hash,
+			"target":   st.Target,
+			"replicas": st.Replicas,
+			"local":    st.Local,
+			"healthy":  st.Healthy,
+		}
+		if st.Namespace != "" {
+			pin["namespace"] = st.Namespace
+		}
+		if !st.LastRepair.IsZero() {
+			pin["lastRepair"] = st.LastRepair.UnixMilli()
+		}
+		if st.LastError != "" {
+			pin["lastError"] = st.LastError
+		}
+		pins = append(pins, pin)
+	}
+	return js.ValueOf(map[string]interface{}{"success": true, "pins": pins})
+}
+
 func jsMeshScheduleChunkPrefetch(this js.Value, args []js.Value) interface{} {
 	if len(args) < 1 {
 		return js.ValueOf(map[string]interface{}{"error": "missing chunk list"})
@@ -337,6 +407,610 @@ func jsMeshUnsubscribeFromEvents(this js.Value, args []js.Value) interface{} {
 	return js.ValueOf(map[string]interface{}{"success": success})
 }
 
+// jsMeshSubscribeMeshEvents opens a filtered subscription on the mesh event
+// ring: subscribeMeshEvents({topics?, peers?}). A bare topic array is also
+// accepted. The result tells the host where its cursor and the ring live.
+func jsMeshSubscribeMeshEvents(this js.Value, args []js.Value) interface{} {
+	if kernelInstance == nil || kernelInstance.meshCoordinator == nil {
+		return js.ValueOf(map[string]interface{}{"error": "mesh not initialized"})
+	}
+	var filter mesh.MeshEventFilter
+	if len(args) > 0 && args[0].Type() == js.TypeObject {
+		if args[0].InstanceOf(js.Global().Get("Array")) {
+			filter.Topics = jsValueToStringSlice(args[0])
+		} else {
+			filter.Topics = jsValueToStringSlice(args[0].Get("topics"))
+			filter.Peers = jsValueToStringSlice(args[0].Get("peers"))
+		}
+	}
+
+	sub, err := kernelInstance.meshCoordinator.SubscribeMeshEvents(filter)
+	if err != nil {
+		return js.ValueOf(map[string]interface{}{"error": err.Error()})
+	}
+	return js.ValueOf(map[string]interface{}{
+		"success":        true,
+		"subscriptionId": sub.ID,
+		"slot":           sub.Slot,
+		"regionOffset":   sub.RegionOffset,
+		"tailOffset":     sub.TailOffset,
+		"cursorOffset":   sub.CursorOffset,
+		"slotsOffset":    sub.SlotsOffset,
+		"slotSize":       sub.SlotSize,
+		"slotCount":      sub.SlotCount,
+		"epochIndex":     sub.EpochIndex,
+	})
+}
+
+// jsMeshUnsubscribeMeshEvents closes a subscription: unsubscribeMeshEvents(id).
+func jsMeshUnsubscribeMeshEvents(this js.Value, args []js.Value) interface{} {
+	if len(args) < 1 {
+		return js.ValueOf(map[string]interface{}{"error": "missing subscription ID"})
+	}
+	if kernelInstance == nil || kernelInstance.meshCoordinator == nil {
+		return js.ValueOf(map[string]interface{}{"error": "mesh not initialized"})
+	}
+	return js.ValueOf(map[string]interface{}{
+		"success": kernelInstance.meshCoordinator.UnsubscribeMeshEvents(args[0].String()),
+	})
+}
+
+// jsMeshReadMeshEvents drains pending events through the kernel instead of
+// the host parsing the ring: readMeshEvents(id, max?). Events are returned
+// as a JSON array string.
+func jsMeshReadMeshEvents(this js.Value, args []js.Value) interface{} {
+	if len(args) < 1 {
+		return js.ValueOf(map[string]interface{}{"error": "missing subscription ID"})
+	}
+	if kernelInstance == nil || kernelInstance.meshCoordinator == nil {
+		return js.ValueOf(map[string]interface{}{"error": "mesh not initialized"})
+	}
+	max := 0
+	if len(args) > 1 && args[1].Type() == js.TypeNumber {
+		max = args[1].Int()
+	}
+
+	events, lost, err := kernelInstance.meshCoordinator.ReadMeshEvents(args[0].String(), max)
+	if err != nil {
+		return js.ValueOf(map[string]interface{}{"error": err.Error()})
+	}
+	data, err := json.Marshal(events)
+	if err != nil {
+		return js.ValueOf(map[string]interface{}{"error": err.Error()})
+	}
+	return js.ValueOf(map[string]interface{}{
+		"success": true,
+		"count":   len(events),
+		"lost":    lost,
+		"events":  string(data),
+	})
+}
+
+// jsGetConnectivityReport returns the last NAT diagnostics report. Passing true
+// (or calling before any probe has run) starts a probe in the background; the
+// result is delivered as a "connectivity_report" kernel event.
+func jsGetConnectivityReport(this js.Value, args []js.Value) interface{} {
+	if kernelInstance == nil || kernelInstance.meshCoordinator == nil {
+		return js.ValueOf(map[string]interface{}{"error": "mesh not initialized"})
+	}
+	coord := kernelInstance.meshCoordinator
+
+	refresh := len(args) > 0 && args[0].Type() == js.TypeBoolean && args[0].Bool()
+	report := coord.LastConnectivityReport()
+	if report != nil && !refresh {
+		return js.ValueOf(report.ToMap())
+	}
+
+	// ICE gathering needs the JS event loop, so never block this callback on it.
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+		defer cancel()
+		fresh, err := coord.GetConnectivityReport(ctx, true)
+		if err != nil {
+			kernelInstance.notifyHost("connectivity_report", map[string]interface{}{"error": err.Error()})
+			return
+		}
+		kernelInstance.notifyHost("connectivity_report", fresh.ToMap())
+	}()
+
+	result := map[string]interface{}{"success": true, "pending": true}
+	if report != nil {
+		result["report"] = report.ToMap()
+	}
+	return js.ValueOf(result)
+}
+
+// jsMeshBootstrap joins the mesh via the given bootstrap entries (plus any
+// configured ones). DNS rendezvous may block, so the attempt runs in the
+// background and reports through the "bootstrap_result" host event.
+func jsMeshBootstrap(this js.Value, args []js.Value) interface{} {
+	if kernelInstance == nil || kernelInstance.meshCoordinator == nil {
+		return js.ValueOf(map[string]interface{}{"error": "mesh not initialized"})
+	}
+	coord := kernelInstance.meshCoordinator
+
+	var peers []string
+	if len(args) > 0 {
+		switch args[0].Type() {
+		case js.TypeString:
+			peers = []string{args[0].String()}
+		case js.TypeObject:
+			peers = jsValueToStringSlice(args[0])
+		}
+	}
+	coord.AddBootstrapPeers(peers...)
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		attempted, err := coord.Bootstrap(ctx, nil)
+		if err != nil {
+			kernelInstance.notifyHost("bootstrap_result", map[string]interface{}{"error": err.Error()})
+			return
+		}
+		kernelInstance.notifyHost("bootstrap_result", map[string]interface{}{"success": true, "attempted": attempted})
+	}()
+
+	return js.ValueOf(map[string]interface{}{"success": true, "pending": true})
+}
+
+// jsMeshUnquarantinePeer lifts a peer's quarantine and resets its breaker.
+func jsMeshUnquarantinePeer(this js.Value, args []js.Value) interface{} {
+	if len(args) < 1 {
+		return js.ValueOf(map[string]interface{}{"error": "missing peer ID"})
+	}
+	if kernelInstance == nil || kernelInstance.meshCoordinator == nil {
+		return js.ValueOf(map[string]interface{}{"error": "mesh not initialized"})
+	}
+	released := kernelInstance.meshCoordinator.UnquarantinePeer(args[0].String())
+	return js.ValueOf(map[string]interface{}{"success": true, "released": released})
+}
+
+func jsMeshGetQuarantinedPeers(this js.Value, args []js.Value) interface{} {
+	if kernelInstance == nil || kernelInstance.meshCoordinator == nil {
+		return js.ValueOf(map[string]interface{}{"error": "mesh not initialized"})
+	}
+	statuses := kernelInstance.meshCoordinator.GetQuarantinedPeers()
+	peers := make([]interface{}, 0, len(statuses))
+	for _, st := range statuses {
+		peers = append(peers, map[string]interface{}{
+			"peerId":        st.PeerID,
+			"level":         st.Level,
+			"failedCycles":  st.FailedCycles,
+			"until":         st.Until.UnixMilli(),
+			"remainingSecs": st.RemainingSecs,
+		})
+	}
+	return js.ValueOf(map[string]interface{}{"success": true, "peers": peers})
+}
+
+// jsMeshGetDelegationAudit returns the signed delegation audit log as JSON:
+// getDelegationAudit(requestId?). With a request ID only that job's records
+// are returned.
+func jsMeshGetDelegationAudit(this js.Value, args []js.Value) interface{} {
+	if kernelInstance == nil || kernelInstance.meshCoordinator == nil {
+		return js.ValueOf(map[string]interface{}{"error": "mesh not initialized"})
+	}
+	var records []mesh.DelegationAuditRecord
+	if len(args) > 0 && args[0].Type() == js.TypeString {
+		records = kernelInstance.meshCoordinator.GetDelegationAudit(args[0].String())
+	} else {
+		records = kernelInstance.meshCoordinator.GetDelegationAuditLog()
+	}
+
+	data, err := json.Marshal(records)
+	if err != nil {
+		return js.ValueOf(map[string]interface{}{"error": err.Error()})
+	}
+	return js.ValueOf(map[string]interface{}{
+		"success": true,
+		"count":   len(records),
+		"records": string(data),
+	})
+}
+
+// jsMeshExportAudit returns the audit log and ledger as one JSON document
+// for offline checking with `inos-node verify`.
+func jsMeshExportAudit(this js.Value, args []js.Value) interface{} {
+	if kernelInstance == nil || kernelInstance.meshCoordinator == nil {
+		return js.ValueOf(map[string]interface{}{"error": "mesh not initialized"})
+	}
+	data, err := json.Marshal(kernelInstance.meshCoordinator.ExportAudit())
+	if err != nil {
+		return js.ValueOf(map[string]interface{}{"error": err.Error()})
+	}
+	return js.ValueOf(map[string]interface{}{
+		"success": true,
+		"export":  string(data),
+	})
+}
+
+// jsMeshGetDelegationReceipts returns stored delegation receipts as JSON:
+// getDelegationReceipts(jobId?). With a job ID only its latest receipt is
+// returned.
+func jsMeshGetDelegationReceipts(this js.Value, args []js.Value) interface{} {
+	if kernelInstance == nil || kernelInstance.meshCoordinator == nil {
+		return js.ValueOf(map[string]interface{}{"error": "mesh not initialized"})
+	}
+	var receipts []mesh.DelegationReceipt
+	if len(args) > 0 && args[0].Type() == js.TypeString {
+		if receipt, ok := kernelInstance.meshCoordinator.GetDelegationReceipt(args[0].String()); ok {
+			receipts = append(receipts, receipt)
+		}
+	} else {
+		receipts = kernelInstance.meshCoordinator.GetDelegationReceipts()
+	}
+
+	data, err := json.Marshal(receipts)
+	if err != nil {
+		return js.ValueOf(map[string]interface{}{"error": err.Error()})
+	}
+	return js.ValueOf(map[string]interface{}{
+		"success":  true,
+		"count":    len(receipts),
+		"receipts": string(data),
+	})
+}
+
+// jsMeshVerifyDelegationReceipt checks a receipt's signature and executor:
+// verifyDelegationReceipt(receiptJson, input?, output?). Input and output
+// are Uint8Arrays checked against the receipt's digests when given.
+func jsMeshVerifyDelegationReceipt(this js.Value, args []js.Value) interface{} {
+	if len(args) < 1 || args[0].Type() != js.TypeString {
+		return js.ValueOf(map[string]interface{}{"error": "missing receipt"})
+	}
+	if kernelInstance == nil || kernelInstance.meshCoordinator == nil {
+		return js.ValueOf(map[string]interface{}{"error": "mesh not initialized"})
+	}
+	var receipt mesh.DelegationReceipt
+	if err := json.Unmarshal([]byte(args[0].String()), &receipt); err != nil {
+		return js.ValueOf(map[string]interface{}{"error": "invalid receipt: " + err.Error()})
+	}
+	optionalBytes := func(i int) []byte {
+		if len(args) <= i || args[i].IsUndefined() || args[i].IsNull() {
+			return nil
+		}
+		data := make([]byte, args[i].Get("length").Int())
+		js.CopyBytesToGo(data, args[i])
+		return data
+	}
+
+	if err := kernelInstance.meshCoordinator.VerifyDelegationReceipt(&receipt, optionalBytes(1), optionalBytes(2)); err != nil {
+		return js.ValueOf(map[string]interface{}{"success": true, "valid": false, "reason": err.Error()})
+	}
+	return js.ValueOf(map[string]interface{}{"success": true, "valid": true})
+}
+
+// jsMeshFileDispute disputes a delegated job's receipt:
+// fileDispute(jobId, input). Input is the job's Uint8Array input. Reviewers
+// re-execute the job, so the outcome is delivered later as a
+// "dispute_result" host event.
+func jsMeshFileDispute(this js.Value, args []js.Value) interface{} {
+	if len(args) < 2 || args[0].Type() != js.TypeString {
+		return js.ValueOf(map[string]interface{}{"error": "missing job ID or input"})
+	}
+	if kernelInstance == nil || kernelInstance.meshCoordinator == nil {
+		return js.ValueOf(map[string]interface{}{"error": "mesh not initialized"})
+	}
+	coord := kernelInstance.meshCoordinator
+	jobID := args[0].String()
+	input := make([]byte, args[1].Get("length").Int())
+	js.CopyBytesToGo(input, args[1])
+
+	go func() {
+		dispute, err := coord.FileDispute(context.Background(), jobID, input)
+		if err != nil {
+			kernelInstance.notifyHost("dispute_result", map[string]interface{}{"jobId": jobID, "error": err.Error()})
+			return
+		}
+		kernelInstance.notifyHost("dispute_result", map[string]interface{}{
+			"success": true,
+			"jobId":   jobID,
+			"id":      dispute.ID,
+			"status":  dispute.Status,
+			"slashed": dispute.Slashed,
+			"paid":    dispute.Paid,
+		})
+	}()
+
+	return js.ValueOf(map[string]interface{}{"success": true, "pending": true})
+}
+
+// jsMeshGetDisputes returns the disputes this node filed as JSON.
+func jsMeshGetDisputes(this js.Value, args []js.Value) interface{} {
+	if kernelInstance == nil || kernelInstance.meshCoordinator == nil {
+		return js.ValueOf(map[string]interface{}{"error": "mesh not initialized"})
+	}
+	disputes := kernelInstance.meshCoordinator.GetDisputes()
+	data, err := json.Marshal(disputes)
+	if err != nil {
+		return js.ValueOf(map[string]interface{}{"error": err.Error()})
+	}
+	return js.ValueOf(map[string]interface{}{
+		"success":  true,
+		"count":    len(disputes),
+		"disputes": string(data),
+	})
+}
+
+// jsMeshPublish sends a message on an application topic:
+// publish(topic, data). Data is a Uint8Array or a string.
+func jsMeshPublish(this js.Value, args []js.Value) interface{} {
+	if len(args) < 2 {
+		return js.ValueOf(map[string]interface{}{"error": "missing topic or data"})
+	}
+	if kernelInstance == nil || kernelInstance.meshCoordinator == nil {
+		return js.ValueOf(map[string]interface{}{"error": "mesh not initialized"})
+	}
+	var data []byte
+	if args[1].Type() == js.TypeString {
+		data = []byte(args[1].String())
+	} else {
+		data = make([]byte, args[1].Get("length").Int())
+		js.CopyBytesToGo(data, args[1])
+	}
+
+	msg, err := kernelInstance.meshCoordinator.PublishTopic(context.Background(), args[0].String(), data)
+	if err != nil {
+		return js.ValueOf(map[string]interface{}{"error": err.Error()})
+	}
+	return js.ValueOf(map[string]interface{}{
+		"success":     true,
+		"id":          msg.ID,
+		"publishedAt": msg.PublishedAt,
+	})
+}
+
+// jsMeshSubscribe starts collecting messages on a topic: subscribe(topic).
+// Arrivals are announced on the mesh event ring under eventTopic; the
+// messages themselves are drained with readTopic.
+func jsMeshSubscribe(this js.Value, args []js.Value) interface{} {
+	if len(args) < 1 {
+		return js.ValueOf(map[string]interface{}{"error": "missing topic"})
+	}
+	if kernelInstance == nil || kernelInstance.meshCoordinator == nil {
+		return js.ValueOf(map[string]interface{}{"error": "mesh not initialized"})
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	sub, err := kernelInstance.meshCoordinator.SubscribeTopic(ctx, args[0].String())
+	if err != nil {
+		return js.ValueOf(map[string]interface{}{"error": err.Error()})
+	}
+	return js.ValueOf(map[string]interface{}{
+		"success":        true,
+		"subscriptionId": sub.ID,
+		"topic":          sub.Topic,
+		"eventTopic":     mesh.MeshEventTopicPrefix + sub.Topic,
+		"backlog":        sub.Backlog,
+	})
+}
+
+// jsMeshUnsubscribe ends a topic subscription: unsubscribe(id).
+func jsMeshUnsubscribe(this js.Value, args []js.Value) interface{} {
+	if len(args) < 1 {
+		return js.ValueOf(map[string]interface{}{"error": "missing subscription ID"})
+	}
+	if kernelInstance == nil || kernelInstance.meshCoordinator == nil {
+		return js.ValueOf(map[string]interface{}{"error": "mesh not initialized"})
+	}
+	return js.ValueOf(map[string]interface{}{
+		"success": kernelInstance.meshCoordinator.UnsubscribeTopic(args[0].String()),
+	})
+}
+
+// jsMeshReadTopic drains a topic subscription: readTopic(id, max?).
+// Message data is returned as Uint8Arrays.
+func jsMeshReadTopic(this js.Value, args []js.Value) interface{} {
+	if len(args) < 1 {
+		return js.ValueOf(map[string]interface{}{"error": "missing subscription ID"})
+	}
+	if kernelInstance == nil || kernelInstance.meshCoordinator == nil {
+		return js.ValueOf(map[string]interface{}{"error": "mesh not initialized"})
+	}
+	max := 0
+	if len(args) > 1 && args[1].Type() == js.TypeNumber {
+		max = args[1].Int()
+	}
+
+	msgs, dropped, err := kernelInstance.meshCoordinator.ReadTopic(args[0].String(), max)
+	if err != nil {
+		return js.ValueOf(map[string]interface{}{"error": err.Error()})
+	}
+	out := make([]interface{}, 0, len(msgs))
+	for _, msg := range msgs {
+		data := js.Global().Get("Uint8Array").New(len(msg.Data))
+		js.CopyBytesToJS(data, msg.Data)
+		out = append(out, map[string]interface{}{
+			"id":          msg.ID,
+			"topic":       msg.Topic,
+			"publisher":   msg.Publisher,
+			"data":        data,
+			"publishedAt": msg.PublishedAt,
+		})
+	}
+	return js.ValueOf(map[string]interface{}{
+		"success":  true,
+		"messages": out,
+		"dropped":  dropped,
+	})
+}
+
+// jsMeshConfigureTopic sets a topic's limits and retention:
+// configureTopic(topic, {maxPayload?, ratePerSecond?, burst?, retain?, retainTtlMs?}).
+func jsMeshConfigureTopic(this js.Value, args []js.Value) interface{} {
+	if len(args) < 2 || args[1].Type() != js.TypeObject {
+		return js.ValueOf(map[string]interface{}{"error": "missing topic or options"})
+	}
+	if kernelInstance == nil || kernelInstance.meshCoordinator == nil {
+		return js.ValueOf(map[string]interface{}{"error": "mesh not initialized"})
+	}
+	intOpt := func(name string) int {
+		if v := args[1].Get(name); v.Type() == js.TypeNumber {
+			return v.Int()
+		}
+		return 0
+	}
+	opts := mesh.TopicOptions{
+		MaxPayload:    intOpt("maxPayload"),
+		RatePerSecond: intOpt("ratePerSecond"),
+		Burst:         intOpt("burst"),
+		Retain:        intOpt("retain"),
+		RetainTTL:     time.Duration(intOpt("retainTtlMs")) * time.Millisecond,
+	}
+	if err := kernelInstance.meshCoordinator.ConfigureTopic(args[0].String(), opts); err != nil {
+		return js.ValueOf(map[string]interface{}{"error": err.Error()})
+	}
+	return js.ValueOf(map[string]interface{}{"success": true})
+}
+
+// jsMeshExportTopology returns an anonymized membership snapshot for offline
+// analysis: exportTopology(format?, includeIdentities?). Format is "json"
+// (default) or "graphml".
+func jsMeshExportTopology(this js.Value, args []js.Value) interface{} {
+	if kernelInstance == nil || kernelInstance.meshCoordinator == nil {
+		return js.ValueOf(map[string]interface{}{"error": "mesh not initialized"})
+	}
+	opts := mesh.TopologyExportOptions{Format: mesh.TopologyFormatJSON}
+	if len(args) > 0 && args[0].Type() == js.TypeString {
+		opts.Format = mesh.TopologyFormat(args[0].String())
+	}
+	if len(args) > 1 && args[1].Type() == js.TypeBoolean {
+		opts.IncludeIdentities = args[1].Bool()
+	}
+
+	data, err := kernelInstance.meshCoordinator.ExportTopology(opts)
+	if err != nil {
+		return js.ValueOf(map[string]interface{}{"error": err.Error()})
+	}
+	return js.ValueOf(map[string]interface{}{
+		"success": true,
+		"format":  string(opts.Format),
+		"data":    string(data),
+	})
+}
+
+// jsMeshBlockPeer blocklists a peer ID or DID pattern: blockPeer(pattern,
+// reason?, ttlMs?). Connected peers matching the pattern are dropped.
+func jsMeshBlockPeer(this js.Value, args []js.Value) interface{} {
+	if len(args) < 1 {
+		return js.ValueOf(map[string]interface{}{"error": "missing peer pattern"})
+	}
+	if kernelInstance == nil || kernelInstance.meshCoordinator == nil {
+		return js.ValueOf(map[string]interface{}{"error": "mesh not initialized"})
+	}
+	reason := ""
+	if len(args) > 1 && args[1].Type() == js.TypeString {
+		reason = args[1].String()
+	}
+	var ttl time.Duration
+	if len(args) > 2 && args[2].Type() == js.TypeNumber {
+		ttl = time.Duration(args[2].Float()) * time.Millisecond
+	}
+	err := kernelInstance.meshCoordinator.Admission().Block(args[0].String(), reason, ttl)
+	return admissionResult(err)
+}
+
+func jsMeshUnblockPeer(this js.Value, args []js.Value) interface{} {
+	if len(args) < 1 {
+		return js.ValueOf(map[string]interface{}{"error": "missing peer pattern"})
+	}
+	if kernelInstance == nil || kernelInstance.meshCoordinator == nil {
+		return js.ValueOf(map[string]interface{}{"error": "mesh not initialized"})
+	}
+	return admissionResult(kernelInstance.meshCoordinator.Admission().Unblock(args[0].String()))
+}
+
+func jsMeshAllowPeer(this js.Value, args []js.Value) interface{} {
+	if len(args) < 1 {
+		return js.ValueOf(map[string]interface{}{"error": "missing peer pattern"})
+	}
+	if kernelInstance == nil || kernelInstance.meshCoordinator == nil {
+		return js.ValueOf(map[string]interface{}{"error": "mesh not initialized"})
+	}
+	return admissionResult(kernelInstance.meshCoordinator.Admission().Allow(args[0].String()))
+}
+
+func jsMeshDisallowPeer(this js.Value, args []js.Value) interface{} {
+	if len(args) < 1 {
+		return js.ValueOf(map[string]interface{}{"error": "missing peer pattern"})
+	}
+	if kernelInstance == nil || kernelInstance.meshCoordinator == nil {
+		return js.ValueOf(map[string]interface{}{"error": "mesh not initialized"})
+	}
+	return admissionResult(kernelInstance.meshCoordinator.Admission().Disallow(args[0].String()))
+}
+
+// jsMeshSetRegionPolicy sets a region to "open", "deny" or "allowlist_only".
+func jsMeshSetRegionPolicy(this js.Value, args []js.Value) interface{} {
+	if len(args) < 2 {
+		return js.ValueOf(map[string]interface{}{"error": "usage: setRegionPolicy(region, mode)"})
+	}
+	if kernelInstance == nil || kernelInstance.meshCoordinator == nil {
+		return js.ValueOf(map[string]interface{}{"error": "mesh not initialized"})
+	}
+	err := kernelInstance.meshCoordinator.Admission().SetRegionPolicy(args[0].String(), args[1].String())
+	return admissionResult(err)
+}
+
+func jsMeshGetAdmissionPolicy(this js.Value, args []js.Value) interface{} {
+	if kernelInstance == nil || kernelInstance.meshCoordinator == nil {
+		return js.ValueOf(map[string]interface{}{"error": "mesh not initialized"})
+	}
+	return admissionResult(nil)
+}
+
+// jsMeshSetAdmissionPolicy restores a policy the host persisted from an
+// "admission_policy_changed" event. It accepts the JSON string form.
+func jsMeshSetAdmissionPolicy(this js.Value, args []js.Value) interface{} {
+	if len(args) < 1 || args[0].Type() != js.TypeString {
+		return js.ValueOf(map[string]interface{}{"error": "missing policy JSON"})
+	}
+	if kernelInstance == nil || kernelInstance.meshCoordinator == nil {
+		return js.ValueOf(map[string]interface{}{"error": "mesh not initialized"})
+	}
+	var policy mesh.AdmissionPolicy
+	if err := json.Unmarshal([]byte(args[0].String()), &policy); err != nil {
+		return js.ValueOf(map[string]interface{}{"error": "invalid policy: " + err.Error()})
+	}
+	return admissionResult(kernelInstance.meshCoordinator.Admission().ImportPolicy(policy))
+}
+
+func admissionResult(err error) js.Value {
+	if err != nil {
+		return js.ValueOf(map[string]interface{}{"error": err.Error()})
+	}
+	policy, err := admissionPolicyToMap(kernelInstance.meshCoordinator.Admission().Policy())
+	if err != nil {
+		return js.ValueOf(map[string]interface{}{"error": err.Error()})
+	}
+	return js.ValueOf(map[string]interface{}{"success": true, "policy": policy})
+}
+
+// admissionPolicyToMap round-trips through JSON so js.ValueOf gets only
+// maps, slices and primitives.
+func admissionPolicyToMap(policy mesh.AdmissionPolicy) (map[string]interface{}, error) {
+	data, err := json.Marshal(policy)
+	if err != nil {
+		return nil, err
+	}
+	var out map[string]interface{}
+	err = json.Unmarshal(data, &out)
+	return out, err
+}
+
+// watchAdmissionPolicy forwards policy changes to the host so it can persist
+// them (e.g. in IndexedDB) and restore them with setAdmissionPolicy.
+func (k *Kernel) watchAdmissionPolicy() {
+	k.meshCoordinator.Admission().Subscribe(func(policy mesh.AdmissionPolicy) {
+		data, err := json.Marshal(policy)
+		if err != nil {
+			return
+		}
+		k.notifyHost("admission_policy_changed", map[string]interface{}{"policy": string(data)})
+	})
+}
+
 func jsValueToStringSlice(val js.Value) []string {
 	if val.IsUndefined() || val.IsNull() {
 		return nil
@@ -362,5 +1036,220 @@ func (k *Kernel) replaceTransport(cfg transport.TransportConfig) error {
 		return err
 	}
 	k.meshCoordinator.ReplaceTransport(tr)
+	k.transportConfig = cfg
 	return nil
 }
+
+// jsMeshGetTimeSync reports the mesh clock offset and, via meshTimeMs, the
+// corrected time so JS schedules against the same clock as the kernel.
+func jsMeshGetTimeSync(this js.Value, args []js.Value) interface{} {
+	if kernelInstance == nil || kernelInstance.meshCoordinator == nil {
+		return js.ValueOf(map[string]interface{}{"error": "mesh not initialized"})
+	}
+	coord := kernelInstance.meshCoordinator
+	status := coord.GetTimeSyncStatus()
+	lastSync := 0.0
+	if !status.LastSync.IsZero() {
+		lastSync = float64(status.LastSync.UnixMilli())
+	}
+	return js.ValueOf(map[string]interface{}{
+		"reference":  status.Reference,
+		"synced":     status.Synced,
+		"offsetMs":   float64(status.Offset) / float64(time.Millisecond),
+		"references": status.References,
+		"used":       status.Used,
+		"rejected":   float64(status.Rejected),
+		"lastSyncMs": lastSync,
+		"meshTimeMs": float64(coord.MeshTime().UnixMilli()),
+	})
+}
+
+// jsMeshWarmManifest starts prefetching an object: warmManifest(hash,
+// priority) where priority is "low", "normal" or "high". Readiness arrives
+// as a manifest.warm mesh event; getWarmStatus(hash) polls it.
+func jsMeshWarmManifest(this js.Value, args []js.Value) interface{} {
+	if len(args) < 1 {
+		return js.ValueOf(map[string]interface{}{"error": "missing manifest hash"})
+	}
+	if kernelInstance == nil || kernelInstance.meshCoordinator == nil {
+		return js.ValueOf(map[string]interface{}{"error": "mesh not initialized"})
+	}
+	priority := mesh.WarmPriorityNormal
+	if len(args) > 1 && args[1].Type() == js.TypeString {
+		switch args[1].String() {
+		case "low":
+			priority = mesh.WarmPriorityLow
+		case "high":
+			priority = mesh.WarmPriorityHigh
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	status, err := kernelInstance.meshCoordinator.WarmManifest(ctx, args[0].String(), priority)
+	if err != nil {
+		return js.ValueOf(map[string]interface{}{"error": err.Error()})
+	}
+	return js.ValueOf(warmStatusToJS(status))
+}
+
+// jsMeshGetWarmStatus reports a warm job: getWarmStatus(hash).
+func jsMeshGetWarmStatus(this js.Value, args []js.Value) interface{} {
+	if len(args) < 1 {
+		return js.ValueOf(map[string]interface{}{"error": "missing manifest hash"})
+	}
+	if kernelInstance == nil || kernelInstance.meshCoordinator == nil {
+		return js.ValueOf(map[string]interface{}{"error": "mesh not initialized"})
+	}
+	status, ok := kernelInstance.meshCoordinator.GetWarmStatus(args[0].String())
+	if !ok {
+		return js.ValueOf(map[string]interface{}{"error": "manifest is not being warmed"})
+	}
+	return js.ValueOf(warmStatusToJS(status))
+}
+
+func warmStatusToJS(status mesh.WarmStatus) map[string]interface{} {
+	out := map[string]interface{}{
+		"success":      true,
+		"manifestHash": status.ManifestHash,
+		"state":        status.State,
+		"chunks":       status.Chunks,
+		"ready":        status.Ready,
+		"fetched":      status.Fetched,
+		"bytes":        float64(status.Bytes),
+	}
+	if !status.ExpiresAt.IsZero() {
+		out["expiresAtMs"] = float64(status.ExpiresAt.UnixMilli())
+	}
+	if status.Error != "" {
+		out["failure"] = status.Error
+	}
+	return out
+}
+
+// jsMeshRegisterModule stores and announces a WASM module:
+// registerModule(name, bytes) returns its content hash, which delegateCompute
+// jobs pass as module.
+func jsMeshRegisterModule(this js.Value, args []js.Value) interface{} {
+	if len(args) < 2 {
+		return js.ValueOf(map[string]interface{}{"error": "missing name or module bytes"})
+	}
+	if kernelInstance == nil || kernelInstance.meshCoordinator == nil {
+		return js.ValueOf(map[string]interface{}{"error": "mesh not initialized"})
+	}
+	wasm := make([]byte, args[1].Get("length").Int())
+	js.CopyBytesToGo(wasm, args[1])
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	hash, err := kernelInstance.meshCoordinator.RegisterModule(ctx, args[0].String(), wasm)
+	if err != nil {
+		return js.ValueOf(map[string]interface{}{"error": err.Error()})
+	}
+	return js.ValueOf(map[string]interface{}{"success": true, "hash": hash})
+}
+
+// jsMeshGetModules lists announced modules and whether each is cached and
+// allowed to run here.
+func jsMeshGetModules(this js.Value, args []js.Value) interface{} {
+	if kernelInstance == nil || kernelInstance.meshCoordinator == nil {
+		return js.ValueOf(map[string]interface{}{"error": "mesh not initialized"})
+	}
+	coord := kernelInstance.meshCoordinator
+	known := coord.GetKnownModules()
+	modules := make([]interface{}, 0, len(known))
+	for _, mod := range known {
+		_, cached := coord.GetCompiledModule(mod.Hash)
+		modules = append(modules, map[string]interface{}{
+			"hash":      mod.Hash,
+			"name":      mod.Name,
+			"size":      mod.Size,
+			"publisher": mod.Publisher,
+			"cached":    cached,
+			"allowed":   coord.ModuleAllowed(mod.Hash) == nil,
+		})
+	}
+	return js.ValueOf(map[string]interface{}{"success": true, "modules": modules})
+}
+
+// jsMeshSetGPUCapability advertises this node's WebGPU adapter so gpu.* jobs
+// are routed here: setGPUCapability({vendor, architecture, device, limits:
+// {maxBufferSize, ...}, features: [...]}). null withdraws it.
+func jsMeshSetGPUCapability(this js.Value, args []js.Value) interface{} {
+	if kernelInstance == nil || kernelInstance.meshCoordinator == nil {
+		return js.ValueOf(map[string]interface{}{"error": "mesh not initialized"})
+	}
+	if len(args) < 1 || args[0].IsNull() || args[0].IsUndefined() {
+		kernelInstance.meshCoordinator.SetGPUCapability(nil)
+		return js.ValueOf(map[string]interface{}{"success": true})
+	}
+	info := args[0]
+	limits := info.Get("limits")
+	limit := func(name string) float64 {
+		if limits.IsUndefined() || limits.IsNull() {
+			return 0
+		}
+		if v := limits.Get(name); v.Type() == js.TypeNumber {
+			return v.Float()
+		}
+		return 0
+	}
+	gpu := &mesh.GPUCapability{
+		MaxBufferSize:                     uint64(limit("maxBufferSize")),
+		MaxStorageBufferBindingSize:       uint64(limit("maxStorageBufferBindingSize")),
+		MaxComputeWorkgroupStorageSize:    uint32(limit("maxComputeWorkgroupStorageSize")),
+		MaxComputeInvocationsPerWorkgroup: uint32(limit("maxComputeInvocationsPerWorkgroup")),
+		MaxComputeWorkgroupsPerDimension:  uint32(limit("maxComputeWorkgroupsPerDimension")),
+		Features:                          jsValueToStringSlice(info.Get("features")),
+	}
+	if v := info.Get("vendor"); v.Type() == js.TypeString {
+		gpu.Vendor = v.String()
+	}
+	if v := info.Get("architecture"); v.Type() == js.TypeString {
+		gpu.Architecture = v.String()
+	}
+	if v := info.Get("device"); v.Type() == js.TypeString {
+		gpu.Device = v.String()
+	}
+	kernelInstance.meshCoordinator.SetGPUCapability(gpu)
+	return js.ValueOf(map[string]interface{}{"success": true})
+}
+
+// jsMeshSetBatteryStatus reports the battery for hosts where the kernel
+// cannot read the Battery Status API itself: setBatteryStatus({charging,
+// level}) with level 0-1.
+func jsMeshSetBatteryStatus(this js.Value, args []js.Value) interface{} {
+	if kernelInstance == nil || kernelInstance.meshCoordinator == nil {
+		return js.ValueOf(map[string]interface{}{"error": "mesh not initialized"})
+	}
+	if len(args) < 1 || args[0].Type() != js.TypeObject {
+		return js.ValueOf(map[string]interface{}{"error": "battery status object required"})
+	}
+	level := args[0].Get("level")
+	if level.Type() != js.TypeNumber {
+		return js.ValueOf(map[string]interface{}{"error": "level must be a number"})
+	}
+	kernelInstance.meshCoordinator.SetBatteryStatus(args[0].Get("charging").Truthy(), level.Float())
+	return js.ValueOf(map[string]interface{}{"success": true})
+}
+
+// jsMeshGetPowerStatus reports the battery and thermal state and whether
+// the node is throttling its mesh participation.
+func jsMeshGetPowerStatus(this js.Value, args []js.Value) interface{} {
+	if kernelInstance == nil || kernelInstance.meshCoordinator == nil {
+		return js.ValueOf(map[string]interface{}{"error": "mesh not initialized"})
+	}
+	status := kernelInstance.meshCoordinator.GetPowerStatus()
+	return js.ValueOf(map[string]interface{}{
+		"success":        true,
+		"batteryKnown":   status.BatteryKnown,
+		"charging":       status.Charging,
+		"batteryLevel":   status.BatteryLevel,
+		"onBattery":      status.OnBattery,
+		"thermal":        status.Thermal,
+		"frameLatencyMs": float64(status.FrameLatency.Microseconds()) / 1000,
+		"baseLatencyMs":  float64(status.BaseLatency.Microseconds()) / 1000,
+		"constrained":    status.Constrained,
+		"gossipFanout":   status.GossipFanout,
+		"declinedJobs":   float64(status.DeclinedJobs),
+	})
+}
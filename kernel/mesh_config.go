@@ -8,20 +8,27 @@ import (
 	"time"
 
 	"github.com/nmxmxh/inos_v1/kernel/core/mesh/transport"
+	inosruntime "github.com/nmxmxh/inos_v1/kernel/runtime"
 	"github.com/nmxmxh/inos_v1/kernel/utils"
 )
 
 type MeshIdentity struct {
 	DID         string
 	DeviceID    string
-	NodeID      string
+	NodeID      string // derived from the node identity key at boot
 	DisplayName string
 }
 
 type MeshBootstrapConfig struct {
-	Identity  MeshIdentity
-	Region    string
-	Transport transport.TransportConfig
+	Identity   MeshIdentity
+	Region     string
+	Transport  transport.TransportConfig
+	Peers      []string // static bootstrap entries: peerID, peerID@wss://..., or wss://...
+	Rendezvous []string // DNS names with "inos-peer=" TXT records
+	Demo       bool     // populate the mesh with synthetic peers
+	DemoPeers  int      // synthetic peer count (0 = coordinator default)
+
+	RoleProfile inosruntime.RoleProfile // what the node contributes; empty is balanced
 }
 
 func loadMeshConfig() MeshBootstrapConfig {
@@ -31,7 +38,6 @@ func loadMeshConfig() MeshBootstrapConfig {
 		Identity: MeshIdentity{
 			DID:         "did:inos:system",
 			DeviceID:    "device:unknown",
-			NodeID:      "node:" + utils.GenerateID(),
 			DisplayName: "Guest",
 		},
 	}
@@ -42,6 +48,13 @@ func loadMeshConfig() MeshBootstrapConfig {
 		if region := rawConfig.Get("region"); region.Type() == js.TypeString {
 			config.Region = region.String()
 		}
+		if profile := rawConfig.Get("roleProfile"); profile.Type() == js.TypeString {
+			if parsed, err := inosruntime.ParseRoleProfile(profile.String()); err == nil {
+				config.RoleProfile = parsed
+			} else {
+				utils.DefaultLogger("kernel").Warn("Ignoring role profile", utils.Err(err))
+			}
+		}
 
 		// Diagnostic: check for WebRTC
 		pc := global.Get("RTCPeerConnection")
@@ -69,6 +82,24 @@ func loadMeshConfig() MeshBootstrapConfig {
 		if transportCfg := rawConfig.Get("transport"); transportCfg.Type() == js.TypeObject {
 			applyTransportConfigOverrides(&config.Transport, transportCfg)
 		}
+
+		if peers := rawConfig.Get("bootstrapPeers"); peers.Type() == js.TypeObject {
+			config.Peers = readStringSlice(peers)
+		}
+		if rendezvous := rawConfig.Get("rendezvous"); rendezvous.Type() == js.TypeObject {
+			config.Rendezvous = readStringSlice(rendezvous)
+		}
+
+		// demo: true | {enabled, peers}
+		switch demo := rawConfig.Get("demo"); demo.Type() {
+		case js.TypeBoolean:
+			config.Demo = demo.Bool()
+		case js.TypeObject:
+			config.Demo = demo.Get("enabled").Type() != js.TypeBoolean || demo.Get("enabled").Bool()
+			if peers := demo.Get("peers"); peers.Type() == js.TypeNumber {
+				config.DemoPeers = peers.Int()
+			}
+		}
 	}
 
 	rawIdentity := global.Get("__INOS_IDENTITY__")
@@ -87,10 +118,6 @@ func loadMeshConfig() MeshBootstrapConfig {
 		}
 	}
 
-	if config.Identity.NodeID == "" {
-		config.Identity.NodeID = "node:" + utils.GenerateID()
-	}
-
 	if len(config.Transport.SignalingServers) == 0 && config.Transport.WebSocketURL != "" {
 		config.Transport.SignalingServers = []string{config.Transport.WebSocketURL}
 	}
@@ -99,9 +126,9 @@ func loadMeshConfig() MeshBootstrapConfig {
 	}
 
 	utils.Info("Mesh config loaded",
-		utils.String("node_id", config.Identity.NodeID),
 		utils.String("signaling_url", config.Transport.WebSocketURL),
 		utils.Any("signaling_servers", config.Transport.SignalingServers),
+		utils.Any("bootstrap_peers", config.Peers),
 	)
 
 	return config
@@ -144,6 +171,9 @@ func applyTransportConfigOverrides(cfg *transport.TransportConfig, raw js.Value)
 	if v := raw.Get("keepAliveIntervalMs"); v.Type() == js.TypeNumber {
 		cfg.KeepAliveInterval = time.Duration(v.Int()) * time.Millisecond
 	}
+	if v := raw.Get("backgroundKeepAliveIntervalMs"); v.Type() == js.TypeNumber {
+		cfg.BackgroundKeepAliveInterval = time.Duration(v.Int()) * time.Millisecond
+	}
 	if v := raw.Get("rpcTimeoutMs"); v.Type() == js.TypeNumber {
 		cfg.RPCTimeout = time.Duration(v.Int()) * time.Millisecond
 	}
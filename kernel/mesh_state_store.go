@@ -0,0 +1,108 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"syscall/js"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh"
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh/routing"
+)
+
+const (
+	meshReputationKey = "inos:mesh:reputation"
+	meshLedgerKey     = "inos:mesh:ledger"
+	meshPinsKey       = "inos:mesh:pins"
+)
+
+// localMeshStateStore keeps reputation, ledger and pin snapshots in
+// localStorage.
+// Writes are synchronous, so they still land when called from beforeunload.
+type localMeshStateStore struct{}
+
+func (localMeshStateStore) storage() (js.Value, error) {
+	storage := js.Global().Get("localStorage")
+	if storage.IsUndefined() || storage.IsNull() {
+		return js.Value{}, errors.New("localStorage unavailable")
+	}
+	return storage, nil
+}
+
+func (s localMeshStateStore) save(key string, v interface{}) (err error) {
+	storage, err := s.storage()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	// setItem throws when the origin's quota is exhausted
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New("localStorage write failed")
+		}
+	}()
+	storage.Call("setItem", key, string(data))
+	return nil
+}
+
+// load returns false when nothing is stored under key.
+func (s localMeshStateStore) load(key string, v interface{}) (bool, error) {
+	storage, err := s.storage()
+	if err != nil {
+		return false, err
+	}
+	item := storage.Call("getItem", key)
+	if item.IsNull() || item.IsUndefined() {
+		return false, nil
+	}
+	return true, json.Unmarshal([]byte(item.String()), v)
+}
+
+func (s localMeshStateStore) SaveScores(scores map[string]routing.ReputationScore) error {
+	return s.save(meshReputationKey, scores)
+}
+
+func (s localMeshStateStore) LoadScores() (map[string]routing.ReputationScore, error) {
+	scores := make(map[string]routing.ReputationScore)
+	if _, err := s.load(meshReputationKey, &scores); err != nil {
+		return nil, err
+	}
+	return scores, nil
+}
+
+func (s localMeshStateStore) SaveLedger(snapshot mesh.LedgerSnapshot) error {
+	return s.save(meshLedgerKey, snapshot)
+}
+
+func (s localMeshStateStore) LoadLedger() (mesh.LedgerSnapshot, error) {
+	var snapshot mesh.LedgerSnapshot
+	found, err := s.load(meshLedgerKey, &snapshot)
+	if err != nil {
+		return mesh.LedgerSnapshot{}, err
+	}
+	if !found {
+		return mesh.LedgerSnapshot{}, mesh.ErrNoLedgerSnapshot
+	}
+	return snapshot, nil
+}
+
+func (s localMeshStateStore) SavePins(set mesh.PinSet) error {
+	return s.save(meshPinsKey, set)
+}
+
+func (s localMeshStateStore) LoadPins() (mesh.PinSet, error) {
+	var set mesh.PinSet
+	found, err := s.load(meshPinsKey, &set)
+	if err != nil {
+		return mesh.PinSet{}, err
+	}
+	if !found {
+		return mesh.PinSet{}, mesh.ErrNoPinSet
+	}
+	return set, nil
+}
@@ -0,0 +1,78 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import "syscall/js"
+
+// watchNetwork forwards the browser's online/offline events and Network
+// Information connection changes to the mesh, so connections restart ICE on
+// the new network instead of dying at the keepalive timeout. Hosts that see
+// network changes some other way report them with kernel.setNetworkStatus.
+func (k *Kernel) watchNetwork() {
+	global := js.Global()
+	navigator := global.Get("navigator")
+	if navigator.IsUndefined() || navigator.IsNull() {
+		return
+	}
+	connection := navigator.Get("connection")
+
+	report := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		online := true
+		if status := navigator.Get("onLine"); status.Type() == js.TypeBoolean {
+			online = status.Bool()
+		}
+		k.SetNetworkStatus(online, connectionType(connection))
+		return nil
+	})
+	if global.Get("addEventListener").Type() == js.TypeFunction {
+		global.Call("addEventListener", "online", report)
+		global.Call("addEventListener", "offline", report)
+	}
+	if !connection.IsUndefined() && !connection.IsNull() {
+		connection.Call("addEventListener", "change", report)
+	}
+}
+
+// connectionType reads navigator.connection.type, falling back to
+// effectiveType where only that is exposed.
+func connectionType(connection js.Value) string {
+	if connection.IsUndefined() || connection.IsNull() {
+		return ""
+	}
+	for _, field := range []string{"type", "effectiveType"} {
+		if v := connection.Get(field); v.Type() == js.TypeString {
+			return v.String()
+		}
+	}
+	return ""
+}
+
+// SetNetworkStatus tells the mesh the host's network changed.
+func (k *Kernel) SetNetworkStatus(online bool, connectionType string) {
+	if k.meshCoordinator == nil {
+		return
+	}
+	k.meshCoordinator.HandleNetworkChange(online, connectionType)
+	k.notifyHost("kernel:network", map[string]interface{}{
+		"online": online,
+		"type":   connectionType,
+	})
+}
+
+// jsSetNetworkStatus lets hosts report network changes:
+// kernel.setNetworkStatus(online, type?).
+func jsSetNetworkStatus(this js.Value, args []js.Value) interface{} {
+	if len(args) < 1 || args[0].Type() != js.TypeBoolean {
+		return js.ValueOf(map[string]interface{}{"error": "missing online flag"})
+	}
+	if kernelInstance == nil || kernelInstance.meshCoordinator == nil {
+		return js.ValueOf(map[string]interface{}{"error": "mesh not initialized"})
+	}
+	connectionType := ""
+	if len(args) > 1 && args[1].Type() == js.TypeString {
+		connectionType = args[1].String()
+	}
+	kernelInstance.SetNetworkStatus(args[0].Bool(), connectionType)
+	return js.ValueOf(map[string]interface{}{"success": true})
+}
@@ -0,0 +1,37 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import "syscall/js"
+
+// watchBattery feeds the Battery Status API into the mesh so a discharging
+// laptop or phone stops taking background compute. Browsers without the API
+// leave the battery unknown; hosts can still report it with
+// mesh.setBatteryStatus.
+func (k *Kernel) watchBattery() {
+	nav := js.Global().Get("navigator")
+	if nav.IsUndefined() || nav.IsNull() || nav.Get("getBattery").Type() != js.TypeFunction {
+		return
+	}
+
+	onBattery := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		if len(args) < 1 {
+			return nil
+		}
+		battery := args[0]
+		report := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+			k.meshCoordinator.SetBatteryStatus(battery.Get("charging").Bool(), battery.Get("level").Float())
+			return nil
+		})
+		report.Invoke()
+		battery.Call("addEventListener", "chargingchange", report)
+		battery.Call("addEventListener", "levelchange", report)
+		return nil
+	})
+	onError := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		k.logger.Debug("Battery status unavailable")
+		return nil
+	})
+	nav.Call("getBattery").Call("then", onBattery, onError)
+}
@@ -0,0 +1,101 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"syscall/js"
+
+	inosruntime "github.com/nmxmxh/inos_v1/kernel/runtime"
+	"github.com/nmxmxh/inos_v1/kernel/utils"
+)
+
+// selectRole adjusts the detected role config for the configured profile.
+// An unknown profile falls back to balanced rather than failing the boot.
+func (k *Kernel) selectRole(detected inosruntime.RoleConfig) inosruntime.RoleConfig {
+	config, err := inosruntime.ApplyRoleProfile(detected, k.config.RoleProfile)
+	if err != nil {
+		k.logger.Warn("Ignoring role profile", utils.Err(err))
+		config, _ = inosruntime.ApplyRoleProfile(detected, inosruntime.ProfileBalanced)
+	}
+	return config
+}
+
+// SetRoleProfile switches what the node contributes. Before boot it only
+// records the choice; afterwards the mesh, gossip and caches are
+// reconfigured from the detected role.
+func (k *Kernel) SetRoleProfile(profile inosruntime.RoleProfile) error {
+	k.roleMu.Lock()
+	defer k.roleMu.Unlock()
+
+	if _, err := inosruntime.ApplyRoleProfile(k.detectedRole, profile); err != nil {
+		return err
+	}
+	k.config.RoleProfile = profile
+	if k.detectedRole.GossipFanout == 0 {
+		return nil // Not profiled yet; Boot applies it
+	}
+
+	k.roleConfig = k.selectRole(k.detectedRole)
+	k.applyMemoryProfile(k.roleConfig.Memory)
+	if k.meshCoordinator != nil {
+		k.meshCoordinator.ApplyRoleConfig(k.roleConfig)
+	}
+	k.notifyHost("kernel:role_profile", roleProfileMap(k.roleConfig))
+	return nil
+}
+
+func roleProfileMap(config inosruntime.RoleConfig) map[string]interface{} {
+	c := config.Contribution
+	return map[string]interface{}{
+		"profile":           string(c.Profile),
+		"acceptCompute":     c.AcceptCompute,
+		"acceptStorage":     c.AcceptStorage,
+		"storageQuotaBytes": float64(c.StorageQuotaBytes),
+		"gossipFanout":      config.GossipFanout,
+		"maxPeers":          config.MaxPeers,
+		"canRelay":          config.CanRelay,
+		"chunkCacheEntries": config.Memory.ChunkCacheEntries,
+		"maxWorkers":        config.Memory.MaxWorkers,
+	}
+}
+
+// jsMeshSetRoleProfile selects a named role profile:
+// mesh.setRoleProfile("storage-heavy").
+func jsMeshSetRoleProfile(this js.Value, args []js.Value) interface{} {
+	if len(args) < 1 || args[0].Type() != js.TypeString {
+		return js.ValueOf(map[string]interface{}{"error": "missing profile name"})
+	}
+	if kernelInstance == nil {
+		return js.ValueOf(map[string]interface{}{"error": "kernel not initialized"})
+	}
+	profile, err := inosruntime.ParseRoleProfile(args[0].String())
+	if err != nil {
+		return js.ValueOf(map[string]interface{}{"error": err.Error()})
+	}
+	if err := kernelInstance.SetRoleProfile(profile); err != nil {
+		return js.ValueOf(map[string]interface{}{"error": err.Error()})
+	}
+	return js.ValueOf(map[string]interface{}{"success": true, "profile": string(profile)})
+}
+
+// jsMeshGetRoleProfile returns the active profile and what it adjusted.
+func jsMeshGetRoleProfile(this js.Value, args []js.Value) interface{} {
+	if kernelInstance == nil {
+		return js.ValueOf(map[string]interface{}{"error": "kernel not initialized"})
+	}
+	kernelInstance.roleMu.Lock()
+	config := kernelInstance.roleConfig
+	configured := kernelInstance.config.RoleProfile
+	kernelInstance.roleMu.Unlock()
+
+	profiles := make([]interface{}, 0)
+	for _, name := range inosruntime.RoleProfiles() {
+		profiles = append(profiles, string(name))
+	}
+	result := roleProfileMap(config)
+	result["success"] = true
+	result["configured"] = string(configured)
+	result["available"] = profiles
+	return js.ValueOf(result)
+}
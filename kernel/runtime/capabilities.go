@@ -8,4 +8,5 @@ type RuntimeCapabilities struct {
 	NetworkLatency  time.Duration // Loopback WebRTC RTT estimate
 	AtomicsOverhead time.Duration // Average overhead of Atomics.wait
 	IsHeadless      bool          // Heuristic detection
+	DeviceMemoryGB  float64       // navigator.deviceMemory; 0 when unknown
 }
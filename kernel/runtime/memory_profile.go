@@ -0,0 +1,83 @@
+package runtime
+
+import system "github.com/nmxmxh/inos_v1/kernel/gen/system/v1"
+
+// MemoryTier classifies how much memory the kernel may spend on caches.
+type MemoryTier string
+
+const (
+	MemoryLow    MemoryTier = "low"    // ~2GB devices: phones, low-end tablets
+	MemoryMedium MemoryTier = "medium" // ~4GB devices
+	MemoryHigh   MemoryTier = "high"   // Desktops and native hosts
+)
+
+// MemoryProfile bounds every cache, queue and worker pool together so a
+// low-end device shrinks all of them rather than just one.
+type MemoryProfile struct {
+	Tier                MemoryTier `json:"tier"`
+	ChunkCacheEntries   int        `json:"chunk_cache_entries"`   // Chunk-to-peer LRU entries
+	BloomFilterElements uint       `json:"bloom_filter_elements"` // Gossip dedup filter capacity
+	SeenCacheEntries    int        `json:"seen_cache_entries"`    // Gossip seen timestamps before reset
+	GossipQueueSize     int        `json:"gossip_queue_size"`     // Outbound gossip queue length
+	TransportQueueSize  int        `json:"transport_queue_size"`  // Transport send queue length
+	MaxWorkers          int        `json:"max_workers"`           // Upper bound on kernel workers
+	WarmStandby         bool       `json:"warm_standby"`          // Keep a second supervisor loaded for failover
+}
+
+// MemoryProfileFor returns the limits for a tier. Unknown tiers get the high
+// profile, which matches the historical defaults.
+func MemoryProfileFor(tier MemoryTier) MemoryProfile {
+	switch tier {
+	case MemoryLow:
+		return MemoryProfile{
+			Tier:                MemoryLow,
+			ChunkCacheEntries:   1000,
+			BloomFilterElements: 10000,
+			SeenCacheEntries:    1000,
+			GossipQueueSize:     100,
+			TransportQueueSize:  100,
+			MaxWorkers:          1,
+		}
+	case MemoryMedium:
+		return MemoryProfile{
+			Tier:                MemoryMedium,
+			ChunkCacheEntries:   4000,
+			BloomFilterElements: 40000,
+			SeenCacheEntries:    4000,
+			GossipQueueSize:     400,
+			TransportQueueSize:  400,
+			MaxWorkers:          2,
+		}
+	default:
+		return MemoryProfile{
+			Tier:                MemoryHigh,
+			ChunkCacheEntries:   10000,
+			BloomFilterElements: 100000,
+			SeenCacheEntries:    10000,
+			GossipQueueSize:     1000,
+			TransportQueueSize:  1000,
+			MaxWorkers:          4,
+			WarmStandby:         true,
+		}
+	}
+}
+
+// SelectMemoryProfile picks a tier from navigator.deviceMemory, capped by
+// role: a sentry never runs the high profile. Browsers that hide
+// deviceMemory report 0, in which case the role decides alone.
+func SelectMemoryProfile(caps RuntimeCapabilities, role system.Runtime_RuntimeRole) MemoryProfile {
+	tier := MemoryHigh
+	switch {
+	case caps.DeviceMemoryGB <= 0:
+		// Unknown; fall through to the role cap.
+	case caps.DeviceMemoryGB <= 2:
+		tier = MemoryLow
+	case caps.DeviceMemoryGB <= 4:
+		tier = MemoryMedium
+	}
+
+	if role == system.Runtime_RuntimeRole_sentry && tier == MemoryHigh {
+		tier = MemoryMedium
+	}
+	return MemoryProfileFor(tier)
+}
@@ -39,6 +39,7 @@ func (p *Profiler) Profile() RuntimeCapabilities {
 		utils.Float64("compute_score", caps.ComputeScore),
 		utils.Int64("atomics_ns", caps.AtomicsOverhead.Nanoseconds()),
 		utils.Bool("headless", caps.IsHeadless),
+		utils.Float64("device_memory_gb", caps.DeviceMemoryGB),
 	)
 
 	return caps
@@ -99,3 +100,17 @@ func (p *Profiler) detectHeadless() bool {
 
 	return webdriver.Truthy() || userAgent == ""
 }
+
+// detectDeviceMemory reads navigator.deviceMemory (GB, coarsened by the
+// browser). Returns 0 where the API is unavailable, e.g. Firefox and Safari.
+func (p *Profiler) detectDeviceMemory() float64 {
+	navigator := js.Global().Get("navigator")
+	if !navigator.Truthy() {
+		return 0
+	}
+	mem := navigator.Get("deviceMemory")
+	if mem.Type() != js.TypeNumber {
+		return 0
+	}
+	return mem.Float()
+}
@@ -16,6 +16,8 @@ type RoleConfig struct {
 	MaxPeers         int           // Maximum peer connections
 	RecommendedBoids int           // LOD: Target number of boids for this role
 	PhysicsPrecision int           // LOD: 0=High, 1=Medium, 2=Low
+	Memory           MemoryProfile // Cache, queue and worker bounds
+	Contribution     Contribution  // What the node offers peers; zero value is balanced
 }
 
 // AssignRole determines the role based on capabilities
@@ -70,10 +72,13 @@ func AssignRole(caps RuntimeCapabilities) RoleConfig {
 		}
 	}
 
+	config.Memory = SelectMemoryProfile(caps, role)
+
 	utils.Info("Runtime: Role Assigned",
 		utils.String("role", role.String()),
 		utils.Int("fanout", config.GossipFanout),
 		utils.Int64("batch_ms", config.BatchInterval.Milliseconds()),
+		utils.String("memory", string(config.Memory.Tier)),
 	)
 
 	return config
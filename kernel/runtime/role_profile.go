@@ -0,0 +1,140 @@
+package runtime
+
+import (
+	"fmt"
+	"sort"
+)
+
+// RoleProfile names what a node contributes to the mesh. The detected role
+// still sets the device limits; the profile decides how they are spent.
+type RoleProfile string
+
+const (
+	ProfileBalanced     RoleProfile = "balanced"      // Detected defaults: stores, computes and relays as able
+	ProfileStorageHeavy RoleProfile = "storage-heavy" // Large chunk quota and cache, declines remote compute
+	ProfileComputeOnly  RoleProfile = "compute-only"  // Runs remote jobs, stores no replicas for others
+	ProfileRelay        RoleProfile = "relay"         // Wide gossip and many connections, no storage or compute
+	ProfileObserver     RoleProfile = "observer"      // Follows the mesh with minimal footprint, contributes nothing
+)
+
+// Contribution is what a profile offers to other nodes.
+type Contribution struct {
+	Profile           RoleProfile `json:"profile"`
+	AcceptCompute     bool        `json:"accept_compute"`      // Execute delegated and pulled jobs
+	AcceptStorage     bool        `json:"accept_storage"`      // Store replicas pushed by peers
+	StorageQuotaBytes uint64      `json:"storage_quota_bytes"` // Chunk quota; 0 keeps the configured quota
+}
+
+// roleProfileSpec adjusts a detected RoleConfig. Multipliers of 0 leave the
+// field alone; fixed values override it.
+type roleProfileSpec struct {
+	contribution    Contribution
+	fanoutFactor    float64
+	minFanout       int
+	peersFactor     float64
+	cacheFactor     float64
+	maxWorkers      int
+	relay           *bool
+	dropWarmStandby bool
+}
+
+func boolPtr(v bool) *bool { return &v }
+
+var roleProfiles = map[RoleProfile]roleProfileSpec{
+	ProfileBalanced: {
+		contribution: Contribution{AcceptCompute: true, AcceptStorage: true},
+	},
+	ProfileStorageHeavy: {
+		contribution: Contribution{AcceptStorage: true, StorageQuotaBytes: 4 << 30},
+		cacheFactor:  2,
+	},
+	ProfileComputeOnly: {
+		contribution: Contribution{AcceptCompute: true},
+		cacheFactor:  0.5,
+	},
+	ProfileRelay: {
+		fanoutFactor:    2,
+		minFanout:       4,
+		peersFactor:     2,
+		maxWorkers:      1,
+		relay:           boolPtr(true),
+		dropWarmStandby: true,
+	},
+	ProfileObserver: {
+		fanoutFactor:    0.5,
+		minFanout:       1,
+		peersFactor:     0.5,
+		cacheFactor:     0.25,
+		maxWorkers:      1,
+		relay:           boolPtr(false),
+		dropWarmStandby: true,
+	},
+}
+
+// RoleProfiles lists the known profile names.
+func RoleProfiles() []RoleProfile {
+	names := make([]RoleProfile, 0, len(roleProfiles))
+	for name := range roleProfiles {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	return names
+}
+
+// ParseRoleProfile validates a profile name. The empty name is balanced.
+func ParseRoleProfile(name string) (RoleProfile, error) {
+	profile := RoleProfile(name)
+	if profile == "" {
+		return ProfileBalanced, nil
+	}
+	if _, ok := roleProfiles[profile]; !ok {
+		return "", fmt.Errorf("unknown role profile %q", name)
+	}
+	return profile, nil
+}
+
+// ApplyRoleProfile returns the detected config adjusted for a profile:
+// fanout, peer count, cache sizes and workers move together so the
+// subsystems agree on what the node is for.
+func ApplyRoleProfile(config RoleConfig, profile RoleProfile) (RoleConfig, error) {
+	if profile == "" {
+		profile = ProfileBalanced
+	}
+	spec, ok := roleProfiles[profile]
+	if !ok {
+		return config, fmt.Errorf("unknown role profile %q", profile)
+	}
+
+	config.Contribution = spec.contribution
+	config.Contribution.Profile = profile
+
+	if spec.fanoutFactor > 0 {
+		config.GossipFanout = max(int(float64(config.GossipFanout)*spec.fanoutFactor), spec.minFanout)
+	}
+	if spec.peersFactor > 0 && config.MaxPeers > 0 {
+		config.MaxPeers = max(int(float64(config.MaxPeers)*spec.peersFactor), 1)
+	}
+	if spec.relay != nil {
+		config.CanRelay = *spec.relay
+	}
+
+	if spec.cacheFactor == 0 && spec.maxWorkers == 0 && !spec.dropWarmStandby {
+		return config, nil
+	}
+	memory := config.Memory
+	if memory.Tier == "" {
+		memory = MemoryProfileFor(MemoryHigh)
+	}
+	if spec.cacheFactor > 0 {
+		memory.ChunkCacheEntries = max(int(float64(memory.ChunkCacheEntries)*spec.cacheFactor), 1)
+	}
+	if spec.maxWorkers > 0 && memory.MaxWorkers > spec.maxWorkers {
+		memory.MaxWorkers = spec.maxWorkers
+	}
+	if spec.dropWarmStandby {
+		memory.WarmStandby = false
+	}
+	config.Memory = memory
+
+	return config, nil
+}
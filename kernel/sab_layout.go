@@ -0,0 +1,156 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"fmt"
+	"syscall/js"
+
+	sab_layout "github.com/nmxmxh/inos_v1/kernel/threads/sab"
+	"github.com/nmxmxh/inos_v1/kernel/threads/supervisor"
+	"github.com/nmxmxh/inos_v1/kernel/utils"
+)
+
+// layoutHeaderView returns a Uint8Array over the layout header of the host's
+// SharedArrayBuffer, or false when no SAB has been published.
+func layoutHeaderView() (js.Value, bool) {
+	sab := js.Global().Get("__INOS_SAB__")
+	if sab.IsUndefined() || sab.IsNull() {
+		return js.Undefined(), false
+	}
+	base := 0
+	if offset := js.Global().Get("__INOS_SAB_OFFSET__"); offset.Type() == js.TypeNumber {
+		base = offset.Int()
+	}
+	view := js.Global().Get("Uint8Array").New(sab, base+int(sab_layout.OFFSET_LAYOUT_HEADER), int(sab_layout.SIZE_LAYOUT_HEADER))
+	return view, true
+}
+
+// validateSABLayout checks the layout header of an injected SAB against this
+// build. A SAB without a header gets one written; a header from an
+// incompatible build is rejected so offsets never silently disagree.
+func (k *Kernel) validateSABLayout(size uint32) error {
+	view, ok := layoutHeaderView()
+	if !ok {
+		k.logger.Warn("No host SAB published; skipping layout validation")
+		return nil
+	}
+
+	header := make([]byte, sab_layout.SIZE_LAYOUT_HEADER)
+	js.CopyBytesToGo(header, view)
+
+	err := sab_layout.ValidateLayout(header, size)
+	if err == nil {
+		return nil
+	}
+	if !sab_layout.IsLayoutMissing(err) {
+		return fmt.Errorf("SAB layout mismatch: %w", err)
+	}
+
+	encoded, err := sab_layout.EncodeLayoutHeader(size)
+	if err != nil {
+		return err
+	}
+	js.CopyBytesToJS(view, encoded)
+	k.logger.Info("SAB layout header written",
+		utils.Uint64("version", uint64(sab_layout.LAYOUT_VERSION)),
+		utils.Int("regions", len(sab_layout.LayoutRegions(size))))
+	return nil
+}
+
+// jsGetSABLayout returns the region map so hosts and Rust modules can
+// configure themselves from the kernel instead of duplicating constants.
+func jsGetSABLayout(this js.Value, args []js.Value) interface{} {
+	size := uint32(sab_layout.SAB_SIZE_DEFAULT)
+	if kernelInstance != nil && kernelInstance.GetSABSize() > 0 {
+		size = kernelInstance.GetSABSize()
+	}
+
+	regions := map[string]interface{}{}
+	for _, r := range sab_layout.LayoutRegions(size) {
+		regions[r.Name] = map[string]interface{}{
+			"offset": int(r.Offset),
+			"size":   int(r.Size),
+		}
+	}
+
+	dynamic := []interface{}{}
+	if bridge := kernelBridge(); bridge != nil {
+		for _, r := range bridge.DynamicRegions() {
+			dynamic = append(dynamic, dynamicRegionValue(r))
+		}
+	}
+
+	return js.ValueOf(map[string]interface{}{
+		"magic":        int(sab_layout.LAYOUT_MAGIC),
+		"version":      int(sab_layout.LAYOUT_VERSION),
+		"versionMajor": int(sab_layout.LayoutVersionMajor(sab_layout.LAYOUT_VERSION)),
+		"sabSize":      int(size),
+		"headerOffset": int(sab_layout.OFFSET_LAYOUT_HEADER),
+		"headerSize":   int(sab_layout.SIZE_LAYOUT_HEADER),
+		"regions":      regions,
+		"dynamicRegistry": map[string]interface{}{
+			"offset": int(sab_layout.OFFSET_DYNAMIC_REGISTRY),
+			"size":   int(sab_layout.SIZE_DYNAMIC_REGISTRY),
+		},
+		"dynamicRegions": dynamic,
+	})
+}
+
+// kernelBridge returns the live SAB bridge, or nil before compute starts.
+func kernelBridge() *supervisor.SABBridge {
+	if kernelInstance == nil || kernelInstance.supervisor == nil {
+		return nil
+	}
+	return kernelInstance.supervisor.GetBridge()
+}
+
+func dynamicRegionValue(r sab_layout.DynamicRegion) map[string]interface{} {
+	return map[string]interface{}{
+		"name":   r.Name,
+		"offset": int(r.Offset),
+		"size":   int(r.Size),
+	}
+}
+
+// jsAllocateSABRegion allocates a named dynamic region: (name, size, alignment?).
+func jsAllocateSABRegion(this js.Value, args []js.Value) interface{} {
+	if len(args) < 2 {
+		return js.ValueOf(map[string]interface{}{"error": "missing arguments: (name, size, alignment?)"})
+	}
+	bridge := kernelBridge()
+	if bridge == nil {
+		return js.ValueOf(map[string]interface{}{"error": "compute layer not initialized"})
+	}
+
+	alignment := uint32(0)
+	if len(args) > 2 && args[2].Type() == js.TypeNumber {
+		alignment = uint32(args[2].Int())
+	}
+	region, err := bridge.AllocateRegion(args[0].String(), uint32(args[1].Int()), alignment)
+	if err != nil {
+		return js.ValueOf(map[string]interface{}{"error": err.Error()})
+	}
+	return js.ValueOf(dynamicRegionValue(region))
+}
+
+// jsFreeSABRegion frees a dynamic region by name.
+func jsFreeSABRegion(this js.Value, args []js.Value) interface{} {
+	if len(args) < 1 {
+		return js.ValueOf(map[string]interface{}{"error": "missing argument: name"})
+	}
+	bridge := kernelBridge()
+	if bridge == nil {
+		return js.ValueOf(map[string]interface{}{"error": "compute layer not initialized"})
+	}
+
+	region, ok := bridge.LookupRegion(args[0].String())
+	if !ok {
+		return js.ValueOf(map[string]interface{}{"error": "region not found"})
+	}
+	if err := bridge.FreeRegion(region.Offset); err != nil {
+		return js.ValueOf(map[string]interface{}{"error": err.Error()})
+	}
+	return js.ValueOf(map[string]interface{}{"success": true})
+}
@@ -0,0 +1,119 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"time"
+	"unsafe"
+
+	"github.com/nmxmxh/inos_v1/kernel/threads"
+	sab_layout "github.com/nmxmxh/inos_v1/kernel/threads/sab"
+	"github.com/nmxmxh/inos_v1/kernel/threads/supervisor"
+	"github.com/nmxmxh/inos_v1/kernel/utils"
+)
+
+// supervisorConfig is shared by the active supervisor and its standby.
+func (k *Kernel) supervisorConfig(ptr unsafe.Pointer) threads.SupervisorConfig {
+	return threads.SupervisorConfig{
+		MeshCoordinator: k.meshCoordinator,
+		Logger:          k.logger,
+		SAB:             ptr,
+		MaxWorkers:      k.config.MaxWorkers,
+		Role:            k.roleConfig,
+		Restart:         k.restartPolicy(),
+	}
+}
+
+// startJobJournal mirrors in-flight jobs into the job history region.
+// Entries left by a previous kernel have nobody waiting on them and are
+// discarded.
+func (k *Kernel) startJobJournal() {
+	bridge := k.supervisor.GetBridge()
+	if bridge == nil {
+		return
+	}
+
+	journal, err := supervisor.NewJobJournal(bridge, sab_layout.OFFSET_JOB_HISTORY, sab_layout.SIZE_JOB_HISTORY)
+	if err != nil {
+		k.logger.Warn("Job journal unavailable; failover will drop in-flight jobs", utils.Err(err))
+		return
+	}
+	if stale, err := journal.Pending(); err == nil && len(stale) > 0 {
+		for _, p := range stale {
+			journal.Release(p.Ticket)
+		}
+		k.logger.Info("Discarded jobs journaled by a previous kernel", utils.Int("count", len(stale)))
+	}
+
+	k.journal = journal
+	k.supervisor.SetJournal(journal)
+}
+
+// prepareWarmStandby loads an idle second supervisor over the same SAB. It
+// costs a second replica, so only memory profiles that allow it get one.
+func (k *Kernel) prepareWarmStandby() {
+	if !k.roleConfig.Memory.WarmStandby || k.journal == nil {
+		return
+	}
+
+	standby := threads.NewRootSupervisor(k.ctx, k.supervisorConfig(k.supervisor.GetSABPointer()))
+	if err := standby.PrepareStandby(k.GetSABSize()); err != nil {
+		k.logger.Warn("Failed to prepare warm standby", utils.Err(err))
+		return
+	}
+
+	k.standbyMu.Lock()
+	k.standby = standby
+	k.standbyMu.Unlock()
+	k.logger.Info("Warm standby supervisor ready")
+}
+
+// failover replaces the active supervisor with the warm standby. The
+// standby's units are already loaded, so promotion only spawns children and
+// re-dispatches the journaled jobs; callers waiting on those jobs get the
+// standby's results.
+func (k *Kernel) failover(reason string) {
+	k.standbyMu.Lock()
+	standby := k.standby
+	k.standby = nil
+	k.standbyMu.Unlock()
+	if standby == nil {
+		k.logger.Warn("Supervisor escalated with no warm standby", utils.String("thread", reason))
+		return
+	}
+
+	start := time.Now()
+	failed := k.supervisor
+	go failed.Stop()
+
+	redispatched, err := standby.Promote(k.journal)
+	if err != nil {
+		k.logger.Error("Standby promotion failed", utils.Err(err))
+		return
+	}
+	k.supervisor = standby
+
+	if k.meshCoordinator != nil {
+		k.meshCoordinator.SetStorage(standby)
+		k.meshCoordinator.SetSABBridge(standby.GetBridge())
+	}
+	k.watchRingBackpressure()
+	k.attachFrameBudget()
+	if KernelState(k.state.Load()) == StateBackground {
+		standby.GetBridge().SetBackground(true)
+	}
+
+	elapsed := time.Since(start)
+	k.logger.Warn("Failed over to warm standby supervisor",
+		utils.String("thread", reason),
+		utils.Int("redispatched", redispatched),
+		utils.Duration("elapsed", elapsed))
+	k.notifyHost("kernel:failover", map[string]interface{}{
+		"thread":       reason,
+		"redispatched": redispatched,
+		"elapsedMs":    float64(elapsed.Microseconds()) / 1000,
+	})
+
+	go k.prepareWarmStandby()
+}
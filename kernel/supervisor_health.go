@@ -0,0 +1,46 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	sab_layout "github.com/nmxmxh/inos_v1/kernel/threads/sab"
+)
+
+// kernelSupervisorHealth reports the current root supervisor's threads for
+// the SAB health block.
+type kernelSupervisorHealth struct {
+	k *Kernel
+}
+
+func (h *kernelSupervisorHealth) SupervisorHealth() sab_layout.SupervisorHealth {
+	if h.k.supervisor == nil {
+		return sab_layout.SupervisorHealth{}
+	}
+	stats := h.k.supervisor.GetStats()
+	health := sab_layout.SupervisorHealth{
+		ActiveThreads:    uint32(stats.ActiveThreads),
+		FailedThreads:    uint32(stats.FailedThreads),
+		RestartedThreads: uint32(stats.RestartedThreads),
+		Messages:         stats.TotalMessages,
+	}
+
+	failed := 0
+	for _, thread := range stats.Threads {
+		if thread.Failed {
+			failed++
+		}
+	}
+	switch {
+	case stats.ActiveThreads == 0 || failed == stats.ActiveThreads:
+		health.Status = sab_layout.HealthStatusDown
+	case failed > 0:
+		health.Status = sab_layout.HealthStatusDegraded
+	default:
+		health.Status = sab_layout.HealthStatusOK
+	}
+	if stats.ActiveThreads > 0 {
+		health.Score = 1 - float32(failed)/float32(stats.ActiveThreads)
+	}
+	return health
+}
@@ -0,0 +1,249 @@
+package foundation
+
+import (
+	"runtime/metrics"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Subsystems that frame time is attributed to.
+const (
+	FrameSubsystemSAB        = "sab"
+	FrameSubsystemGossip     = "gossip"
+	FrameSubsystemDelegation = "delegation"
+	FrameSubsystemGC         = "gc"
+)
+
+var frameSubsystems = [...]string{FrameSubsystemSAB, FrameSubsystemGossip, FrameSubsystemDelegation, FrameSubsystemGC}
+
+// gcCPUMetric is the runtime's cumulative estimate of GC CPU time.
+const gcCPUMetric = "/cpu/classes/gc/total:cpu-seconds"
+
+// FrameBudgetConfig tunes a FrameBudget. Zero fields take defaults.
+type FrameBudgetConfig struct {
+	Target      time.Duration // Frame period above which a frame is blown
+	DeferAfter  int           // Consecutive blown frames before low-priority work is deferred
+	ResumeAfter int           // Consecutive good frames before deferred work resumes
+
+	// OnDeferChange is called, outside any lock, when deferral starts or stops.
+	OnDeferChange func(deferring bool)
+}
+
+// DefaultFrameBudgetConfig returns a 50 fps floor that defers after three
+// blown frames and resumes after half a second of good ones.
+func DefaultFrameBudgetConfig() FrameBudgetConfig {
+	return FrameBudgetConfig{
+		Target:      20 * time.Millisecond,
+		DeferAfter:  3,
+		ResumeAfter: 30,
+	}
+}
+
+// FrameBreakdown is where one frame's time went.
+type FrameBreakdown struct {
+	Frame      uint64
+	Total      time.Duration            // Time since the previous frame
+	Subsystems map[string]time.Duration // Time attributed to each subsystem
+	Other      time.Duration            // Physics, rendering and anything untracked
+	OverBudget bool
+}
+
+// FrameBudgetStats summarizes recent frames.
+type FrameBudgetStats struct {
+	Target           time.Duration
+	Frames           uint64
+	OverBudgetFrames uint64
+	Deferring        bool
+	DeferEvents      uint64                   // Times deferral started
+	AverageTotal     time.Duration            // Moving average frame time
+	Average          map[string]time.Duration // Moving average per subsystem
+	Last             FrameBreakdown
+}
+
+// FrameBudget attributes each frame's time to the subsystems sharing it and
+// tells background work to yield while frames run over budget. Subsystems
+// report time with Track or Record; the frame clock calls EndFrame once per
+// frame. All methods are safe on a nil FrameBudget.
+type FrameBudget struct {
+	cfg   FrameBudgetConfig
+	spent [len(frameSubsystems)]atomic.Int64 // Nanoseconds in the open frame
+
+	deferring atomic.Bool
+
+	mu          sync.Mutex
+	frames      uint64
+	overBudget  uint64
+	overStreak  int
+	goodStreak  int
+	deferEvents uint64
+	avgTotal    float64
+	avg         [len(frameSubsystems)]float64
+	last        FrameBreakdown
+	gcSample    []metrics.Sample
+	lastGC      float64
+}
+
+// frameAverageWeight is the EWMA weight of the newest frame.
+const frameAverageWeight = 0.1
+
+// NewFrameBudget creates a FrameBudget.
+func NewFrameBudget(cfg FrameBudgetConfig) *FrameBudget {
+	d := DefaultFrameBudgetConfig()
+	if cfg.Target <= 0 {
+		cfg.Target = d.Target
+	}
+	if cfg.DeferAfter <= 0 {
+		cfg.DeferAfter = d.DeferAfter
+	}
+	if cfg.ResumeAfter <= 0 {
+		cfg.ResumeAfter = d.ResumeAfter
+	}
+
+	b := &FrameBudget{cfg: cfg, gcSample: []metrics.Sample{{Name: gcCPUMetric}}}
+	b.lastGC = b.readGC()
+	return b
+}
+
+func frameSubsystemIndex(subsystem string) int {
+	for i, s := range frameSubsystems {
+		if s == subsystem {
+			return i
+		}
+	}
+	return -1
+}
+
+// Record attributes d to subsystem in the current frame.
+func (b *FrameBudget) Record(subsystem string, d time.Duration) {
+	if b == nil {
+		return
+	}
+	if i := frameSubsystemIndex(subsystem); i >= 0 {
+		b.spent[i].Add(int64(d))
+	}
+}
+
+// Track starts timing work for subsystem; call the returned func when the
+// work is done.
+func (b *FrameBudget) Track(subsystem string) func() {
+	if b == nil {
+		return func() {}
+	}
+	start := time.Now()
+	return func() { b.Record(subsystem, time.Since(start)) }
+}
+
+// DeferLowPriority reports whether background work should yield this frame.
+func (b *FrameBudget) DeferLowPriority() bool {
+	return b != nil && b.deferring.Load()
+}
+
+// EndFrame closes the current frame, whose period was frameTime, and starts
+// the next one.
+func (b *FrameBudget) EndFrame(frameTime time.Duration) FrameBreakdown {
+	if b == nil {
+		return FrameBreakdown{}
+	}
+
+	frame := FrameBreakdown{
+		Total:      frameTime,
+		Subsystems: make(map[string]time.Duration, len(frameSubsystems)),
+	}
+	var spent [len(frameSubsystems)]time.Duration
+	for i := range frameSubsystems {
+		spent[i] = time.Duration(b.spent[i].Swap(0))
+	}
+
+	b.mu.Lock()
+	frame.OverBudget = frameTime > b.cfg.Target
+	gc := b.readGC()
+	gcDelta := time.Duration((gc - b.lastGC) * float64(time.Second))
+	b.lastGC = gc
+	spent[frameSubsystemIndex(FrameSubsystemGC)] += max(gcDelta, 0)
+
+	var tracked time.Duration
+	for i, name := range frameSubsystems {
+		// Concurrent goroutines can overlap; no subsystem gets more than the frame
+		spent[i] = min(spent[i], frameTime)
+		frame.Subsystems[name] = spent[i]
+		tracked += spent[i]
+		b.avg[i] += frameAverageWeight * (float64(spent[i]) - b.avg[i])
+	}
+	frame.Other = max(frameTime-tracked, 0)
+
+	b.frames++
+	frame.Frame = b.frames
+	b.avgTotal += frameAverageWeight * (float64(frameTime) - b.avgTotal)
+	if frame.OverBudget {
+		b.overBudget++
+		b.overStreak++
+		b.goodStreak = 0
+	} else {
+		b.goodStreak++
+		b.overStreak = 0
+	}
+
+	changed := false
+	deferring := b.deferring.Load()
+	switch {
+	case !deferring && b.overStreak >= b.cfg.DeferAfter:
+		deferring, changed = true, true
+		b.deferEvents++
+	case deferring && b.goodStreak >= b.cfg.ResumeAfter:
+		deferring, changed = false, true
+	}
+	b.deferring.Store(deferring)
+	b.last = frame
+	b.mu.Unlock()
+
+	if changed && b.cfg.OnDeferChange != nil {
+		b.cfg.OnDeferChange(deferring)
+	}
+	return frame
+}
+
+// SetTarget changes the frame period above which a frame is blown. It
+// applies from the next EndFrame.
+func (b *FrameBudget) SetTarget(target time.Duration) {
+	if b == nil || target <= 0 {
+		return
+	}
+	b.mu.Lock()
+	b.cfg.Target = target
+	b.mu.Unlock()
+}
+
+// readGC returns cumulative GC CPU seconds. Callers hold b.mu, except
+// NewFrameBudget.
+func (b *FrameBudget) readGC() float64 {
+	metrics.Read(b.gcSample)
+	if b.gcSample[0].Value.Kind() != metrics.KindFloat64 {
+		return 0
+	}
+	return b.gcSample[0].Value.Float64()
+}
+
+// Stats returns the frame counters and moving averages.
+func (b *FrameBudget) Stats() FrameBudgetStats {
+	if b == nil {
+		return FrameBudgetStats{}
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	stats := FrameBudgetStats{
+		Target:           b.cfg.Target,
+		Frames:           b.frames,
+		OverBudgetFrames: b.overBudget,
+		Deferring:        b.deferring.Load(),
+		DeferEvents:      b.deferEvents,
+		AverageTotal:     time.Duration(b.avgTotal),
+		Average:          make(map[string]time.Duration, len(frameSubsystems)),
+		Last:             b.last,
+	}
+	for i, name := range frameSubsystems {
+		stats.Average[name] = time.Duration(b.avg[i])
+	}
+	return stats
+}
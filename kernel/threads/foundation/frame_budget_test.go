@@ -0,0 +1,77 @@
+package foundation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFrameBudget_AttributesFrameTime(t *testing.T) {
+	b := NewFrameBudget(FrameBudgetConfig{Target: 16 * time.Millisecond})
+
+	b.Record(FrameSubsystemSAB, 2*time.Millisecond)
+	b.Record(FrameSubsystemGossip, time.Millisecond)
+	b.Record(FrameSubsystemDelegation, 3*time.Millisecond)
+	b.Record("unknown", time.Second)
+
+	frame := b.EndFrame(12 * time.Millisecond)
+	assert.Equal(t, uint64(1), frame.Frame)
+	assert.False(t, frame.OverBudget)
+	assert.Equal(t, 2*time.Millisecond, frame.Subsystems[FrameSubsystemSAB])
+	assert.Equal(t, time.Millisecond, frame.Subsystems[FrameSubsystemGossip])
+	assert.Equal(t, 3*time.Millisecond, frame.Subsystems[FrameSubsystemDelegation])
+	gc := frame.Subsystems[FrameSubsystemGC]
+	assert.Equal(t, max(6*time.Millisecond-gc, 0), frame.Other)
+
+	// The next frame starts empty
+	next := b.EndFrame(10 * time.Millisecond)
+	assert.Zero(t, next.Subsystems[FrameSubsystemSAB])
+}
+
+func TestFrameBudget_DefersAfterBlownFramesAndResumes(t *testing.T) {
+	var changes []bool
+	b := NewFrameBudget(FrameBudgetConfig{
+		Target:        16 * time.Millisecond,
+		DeferAfter:    2,
+		ResumeAfter:   3,
+		OnDeferChange: func(deferring bool) { changes = append(changes, deferring) },
+	})
+
+	b.EndFrame(40 * time.Millisecond)
+	assert.False(t, b.DeferLowPriority(), "one blown frame is noise")
+	b.EndFrame(40 * time.Millisecond)
+	assert.True(t, b.DeferLowPriority())
+
+	b.EndFrame(10 * time.Millisecond)
+	b.EndFrame(10 * time.Millisecond)
+	assert.True(t, b.DeferLowPriority(), "resumes only after ResumeAfter good frames")
+	b.EndFrame(10 * time.Millisecond)
+	assert.False(t, b.DeferLowPriority())
+
+	assert.Equal(t, []bool{true, false}, changes)
+	stats := b.Stats()
+	assert.Equal(t, uint64(5), stats.Frames)
+	assert.Equal(t, uint64(2), stats.OverBudgetFrames)
+	assert.Equal(t, uint64(1), stats.DeferEvents)
+}
+
+func TestFrameBudget_NilIsInert(t *testing.T) {
+	var b *FrameBudget
+	b.Track(FrameSubsystemSAB)()
+	assert.False(t, b.DeferLowPriority())
+	assert.Zero(t, b.EndFrame(time.Second).Frame)
+	assert.Zero(t, b.Stats().Frames)
+}
+
+func TestFrameBudget_SetTarget(t *testing.T) {
+	b := NewFrameBudget(FrameBudgetConfig{Target: 20 * time.Millisecond})
+	assert.False(t, b.EndFrame(18*time.Millisecond).OverBudget)
+
+	b.SetTarget(0)
+	assert.Equal(t, 20*time.Millisecond, b.Stats().Target, "non-positive targets are ignored")
+
+	b.SetTarget(16 * time.Millisecond)
+	assert.True(t, b.EndFrame(18*time.Millisecond).OverBudget)
+	assert.Equal(t, 16*time.Millisecond, b.Stats().Target)
+}
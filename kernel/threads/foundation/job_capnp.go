@@ -0,0 +1,221 @@
+package foundation
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/gen/p2p/v1"
+	"github.com/nmxmxh/inos_v1/kernel/gen/system/v1"
+	capnp "zombiezen.com/go/capnproto2"
+)
+
+// ErrJobDataNotInline is returned when a Cap'n Proto job names its input by
+// digest or SAB offset instead of carrying it; the receiver must resolve the
+// resource itself.
+var ErrJobDataNotInline = errors.New("job resource is not inline")
+
+// ToCapnp converts Job to p2p.DelegateRequest, the form jobs take across
+// the browser and native boundary. Data travels as an uncompressed inline
+// resource tagged with its hex sha256 digest, and Parameters as JSON.
+// Priority is clamped to the schema's 0-255.
+func (j *Job) ToCapnp(seg *capnp.Segment) (p2p.DelegateRequest, error) {
+	req, err := p2p.NewDelegateRequest(seg)
+	if err != nil {
+		return p2p.DelegateRequest{}, err
+	}
+	req.SetId(j.ID)
+
+	op, err := req.NewOperation()
+	if err != nil {
+		return p2p.DelegateRequest{}, err
+	}
+	op.SetCustom(j.Operation)
+
+	if len(j.Parameters) > 0 {
+		params, err := json.Marshal(j.Parameters)
+		if err != nil {
+			return p2p.DelegateRequest{}, fmt.Errorf("failed to encode job parameters: %w", err)
+		}
+		req.SetParams(params)
+	}
+	if !j.Deadline.IsZero() {
+		req.SetDeadline(uint64(j.Deadline.UnixNano()))
+	}
+	req.SetPriority(uint8(min(max(j.Priority, 0), math.MaxUint8)))
+
+	if len(j.Data) > 0 {
+		res, err := req.NewResource()
+		if err != nil {
+			return p2p.DelegateRequest{}, err
+		}
+		digest := sha256.Sum256(j.Data)
+		res.SetId(j.ID)
+		res.SetDigest([]byte(hex.EncodeToString(digest[:])))
+		res.SetRawSize(uint32(len(j.Data)))
+		res.SetWireSize(uint32(len(j.Data)))
+		res.SetCompression(system.Resource_Compression_none)
+		res.SetEncryption(system.Resource_Encryption_none)
+		if err := res.SetInline(j.Data); err != nil {
+			return p2p.DelegateRequest{}, err
+		}
+	}
+
+	if j.TraceParent != "" {
+		md, err := req.NewMetadata()
+		if err != nil {
+			return p2p.DelegateRequest{}, err
+		}
+		md.SetTraceParent(j.TraceParent)
+	}
+	return req, nil
+}
+
+// FromCapnp updates Job from p2p.DelegateRequest. Only a custom operation
+// maps to Job.Operation; the built-in hash, compress and encrypt variants
+// become "hash", "compress" and "encrypt". Inline data is checked against
+// its digest.
+func (j *Job) FromCapnp(req p2p.DelegateRequest) error {
+	j.ID, _ = req.Id()
+
+	op, err := req.Operation()
+	if err != nil {
+		return fmt.Errorf("failed to read job operation: %w", err)
+	}
+	switch op.Which() {
+	case p2p.DelegateRequest_Operation_Which_custom:
+		j.Operation, _ = op.Custom()
+	case p2p.DelegateRequest_Operation_Which_hash:
+		j.Operation = "hash"
+	case p2p.DelegateRequest_Operation_Which_compress:
+		j.Operation = "compress"
+	case p2p.DelegateRequest_Operation_Which_encrypt:
+		j.Operation = "encrypt"
+	}
+
+	j.Parameters = nil
+	if params, _ := req.Params(); len(params) > 0 {
+		if err := json.Unmarshal(params, &j.Parameters); err != nil {
+			return fmt.Errorf("failed to decode job parameters: %w", err)
+		}
+	}
+	j.Deadline = time.Time{}
+	if deadline := req.Deadline(); deadline != 0 {
+		j.Deadline = time.Unix(0, int64(deadline))
+	}
+	j.Priority = int(req.Priority())
+
+	j.TraceParent = ""
+	if req.HasMetadata() {
+		md, _ := req.Metadata()
+		j.TraceParent, _ = md.TraceParent()
+	}
+
+	j.Data = nil
+	if !req.HasResource() {
+		return nil
+	}
+	res, err := req.Resource()
+	if err != nil {
+		return fmt.Errorf("failed to read job resource: %w", err)
+	}
+	if res.Which() != system.Resource_Which_inline || res.Compression() != system.Resource_Compression_none {
+		return ErrJobDataNotInline
+	}
+	data, err := res.Inline()
+	if err != nil {
+		return fmt.Errorf("failed to read job data: %w", err)
+	}
+	if digest, _ := res.Digest(); len(digest) > 0 {
+		sum := sha256.Sum256(data)
+		if string(digest) != hex.EncodeToString(sum[:]) {
+			return errors.New("job data does not match its digest")
+		}
+	}
+	j.Data = append([]byte(nil), data...)
+	return nil
+}
+
+// ToCapnp converts Result to p2p.DelegateResponse.
+func (r *Result) ToCapnp(seg *capnp.Segment) (p2p.DelegateResponse, error) {
+	resp, err := p2p.NewDelegateResponse(seg)
+	if err != nil {
+		return p2p.DelegateResponse{}, err
+	}
+	resp.SetRequestId(r.JobID)
+	if r.Success {
+		resp.SetStatus(p2p.DelegateResponse_Status_success)
+	} else {
+		resp.SetStatus(p2p.DelegateResponse_Status_failed)
+		resp.SetError(r.Error)
+	}
+
+	if len(r.Data) > 0 {
+		res, err := resp.NewResult()
+		if err != nil {
+			return p2p.DelegateResponse{}, err
+		}
+		digest := sha256.Sum256(r.Data)
+		res.SetId(r.JobID)
+		res.SetDigest([]byte(hex.EncodeToString(digest[:])))
+		res.SetRawSize(uint32(len(r.Data)))
+		res.SetWireSize(uint32(len(r.Data)))
+		res.SetCompression(system.Resource_Compression_none)
+		res.SetEncryption(system.Resource_Encryption_none)
+		if err := res.SetInline(r.Data); err != nil {
+			return p2p.DelegateResponse{}, err
+		}
+	}
+
+	metrics, err := resp.NewMetrics()
+	if err != nil {
+		return p2p.DelegateResponse{}, err
+	}
+	metrics.SetExecutionTimeNs(uint64(max(r.Latency, 0)))
+	if r.Metrics != nil {
+		metrics.SetPeakMemoryBytes(uint32(min(r.Metrics.MemoryUsed, math.MaxUint32)))
+	}
+	return resp, nil
+}
+
+// FromCapnp updates Result from p2p.DelegateResponse.
+func (r *Result) FromCapnp(resp p2p.DelegateResponse) error {
+	r.JobID, _ = resp.RequestId()
+	r.Success = resp.Status() == p2p.DelegateResponse_Status_success
+	r.Error, _ = resp.Error()
+	if !r.Success && r.Error == "" {
+		r.Error = resp.Status().String()
+	}
+
+	r.Latency = 0
+	r.Metrics = nil
+	if resp.HasMetrics() {
+		metrics, _ := resp.Metrics()
+		r.Latency = time.Duration(metrics.ExecutionTimeNs())
+		if peak := metrics.PeakMemoryBytes(); peak > 0 {
+			r.Metrics = &ExecutionMetrics{MemoryUsed: uint64(peak)}
+		}
+	}
+
+	r.Data = nil
+	if !resp.HasResult() {
+		return nil
+	}
+	res, err := resp.Result()
+	if err != nil {
+		return fmt.Errorf("failed to read result resource: %w", err)
+	}
+	if res.Which() != system.Resource_Which_inline || res.Compression() != system.Resource_Compression_none {
+		return ErrJobDataNotInline
+	}
+	data, err := res.Inline()
+	if err != nil {
+		return fmt.Errorf("failed to read result data: %w", err)
+	}
+	r.Data = append([]byte(nil), data...)
+	return nil
+}
@@ -0,0 +1,86 @@
+package foundation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/gen/p2p/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	capnp "zombiezen.com/go/capnproto2"
+)
+
+func TestJob_CapnpRoundTrip(t *testing.T) {
+	deadline := time.Unix(1700000000, 42)
+	job := &Job{
+		ID:          "job-1",
+		Operation:   "matmul",
+		Data:        []byte("input"),
+		Parameters:  map[string]interface{}{"rows": float64(4)},
+		Priority:    900,
+		Deadline:    deadline,
+		TraceParent: "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01",
+	}
+
+	msg, seg, err := capnp.NewMessage(capnp.SingleSegment(nil))
+	require.NoError(t, err)
+	req, err := job.ToCapnp(seg)
+	require.NoError(t, err)
+	require.NoError(t, msg.SetRootPtr(req.Struct.ToPtr()))
+
+	// Through the wire, as a browser peer would receive it
+	data, err := msg.Marshal()
+	require.NoError(t, err)
+	received, err := capnp.Unmarshal(data)
+	require.NoError(t, err)
+	root, err := p2p.ReadRootDelegateRequest(received)
+	require.NoError(t, err)
+
+	var got Job
+	require.NoError(t, got.FromCapnp(root))
+	assert.Equal(t, job.ID, got.ID)
+	assert.Equal(t, job.Operation, got.Operation)
+	assert.Equal(t, job.Data, got.Data)
+	assert.Equal(t, job.Parameters, got.Parameters)
+	assert.Equal(t, 255, got.Priority, "priority is clamped to the schema's range")
+	assert.True(t, deadline.Equal(got.Deadline))
+	assert.Equal(t, job.TraceParent, got.TraceParent)
+}
+
+func TestJob_FromCapnpRejectsUnresolvedData(t *testing.T) {
+	_, seg, err := capnp.NewMessage(capnp.SingleSegment(nil))
+	require.NoError(t, err)
+	req, err := (&Job{ID: "job-2", Operation: "hash", Data: []byte("input")}).ToCapnp(seg)
+	require.NoError(t, err)
+
+	res, err := req.Resource()
+	require.NoError(t, err)
+	res.SetDigest([]byte("not-the-digest"))
+	assert.Error(t, new(Job).FromCapnp(req))
+
+	ref, err := res.NewSabRef()
+	require.NoError(t, err)
+	ref.SetOffset(64)
+	assert.ErrorIs(t, new(Job).FromCapnp(req), ErrJobDataNotInline)
+}
+
+func TestResult_CapnpRoundTrip(t *testing.T) {
+	for _, result := range []*Result{
+		{JobID: "ok", Success: true, Data: []byte("output"), Latency: 3 * time.Millisecond, Metrics: &ExecutionMetrics{MemoryUsed: 1 << 20}},
+		{JobID: "bad", Error: "division by zero"},
+	} {
+		_, seg, err := capnp.NewMessage(capnp.SingleSegment(nil))
+		require.NoError(t, err)
+		resp, err := result.ToCapnp(seg)
+		require.NoError(t, err)
+
+		var got Result
+		require.NoError(t, got.FromCapnp(resp))
+		assert.Equal(t, result.JobID, got.JobID)
+		assert.Equal(t, result.Success, got.Success)
+		assert.Equal(t, result.Data, got.Data)
+		assert.Equal(t, result.Error, got.Error)
+		assert.Equal(t, result.Latency, got.Latency)
+		assert.Equal(t, result.Metrics, got.Metrics)
+	}
+}
@@ -0,0 +1,232 @@
+package foundation
+
+import (
+	"context"
+	"math"
+	"runtime/metrics"
+	"sync"
+	"time"
+)
+
+// Runtime metrics the load monitor reads. None of them stop the world.
+const (
+	goroutinesMetric = "/sched/goroutines:goroutines"
+	heapBytesMetric  = "/memory/classes/heap/objects:bytes"
+	memLimitMetric   = "/gc/gomemlimit:bytes"
+)
+
+// LoadSample is one reading of what keeps the node busy. The monitor reads
+// the runtime fields itself; a LoadSampler fills in the rest.
+type LoadSample struct {
+	Goroutines    int
+	HeapBytes     uint64        // Live and not yet swept heap objects
+	RingOccupancy float64       // Fullest SAB ring at its last write, 0-1
+	FrameLatency  time.Duration // Time between physics frames; 0 if unknown
+	QueueDepth    int           // Jobs waiting in unit queues
+}
+
+// LoadSampler fills in the parts of a sample only the kernel can see.
+type LoadSampler func(*LoadSample)
+
+// LoadMonitorConfig sets the reading at which each input counts as full
+// load. Zero fields take defaults.
+type LoadMonitorConfig struct {
+	MaxGoroutines int           // Goroutines that read as full load
+	MemoryLimit   uint64        // Heap that reads as full load; 0 uses the runtime memory limit, if one is set
+	FrameTarget   time.Duration // Frame period; frames twice this long read as full load
+	MaxQueueDepth int           // Queued jobs that read as full load
+	Smoothing     float64       // EWMA weight of the newest sample
+}
+
+// DefaultLoadMonitorConfig matches the frame budget's 50 fps floor.
+func DefaultLoadMonitorConfig() LoadMonitorConfig {
+	return LoadMonitorConfig{
+		MaxGoroutines: 4096,
+		FrameTarget:   DefaultFrameBudgetConfig().Target,
+		MaxQueueDepth: 64,
+		Smoothing:     0.3,
+	}
+}
+
+// LoadBreakdown is the pressure each input puts on the node, 0-1.
+type LoadBreakdown struct {
+	Goroutines float64 `json:"goroutines"`
+	Memory     float64 `json:"memory"`
+	Rings      float64 `json:"rings"`
+	Frames     float64 `json:"frames"`
+	Queue      float64 `json:"queue"`
+}
+
+// peak is the pressure of the busiest input.
+func (b LoadBreakdown) peak() float64 {
+	return max(b.Goroutines, b.Memory, b.Rings, b.Frames, b.Queue)
+}
+
+// LoadStats is the monitor's latest reading.
+type LoadStats struct {
+	Load      float64 // Smoothed, 0-1
+	Last      LoadSample
+	Breakdown LoadBreakdown // Of the last sample
+	Samples   uint64
+}
+
+// LoadMonitor samples the node's load. The busiest input sets it: a node
+// whose rings are full or whose frames overrun is loaded however idle its
+// workers look. It implements the mesh's SystemLoadProvider. All methods
+// are safe on a nil LoadMonitor, which reads as idle.
+type LoadMonitor struct {
+	cfg     LoadMonitorConfig
+	sampler LoadSampler
+	runtime []metrics.Sample
+
+	mu        sync.Mutex
+	load      float64
+	last      LoadSample
+	breakdown LoadBreakdown
+	samples   uint64
+}
+
+// NewLoadMonitor creates a LoadMonitor. sampler may be nil.
+func NewLoadMonitor(cfg LoadMonitorConfig, sampler LoadSampler) *LoadMonitor {
+	d := DefaultLoadMonitorConfig()
+	if cfg.MaxGoroutines <= 0 {
+		cfg.MaxGoroutines = d.MaxGoroutines
+	}
+	if cfg.FrameTarget <= 0 {
+		cfg.FrameTarget = d.FrameTarget
+	}
+	if cfg.MaxQueueDepth <= 0 {
+		cfg.MaxQueueDepth = d.MaxQueueDepth
+	}
+	if cfg.Smoothing <= 0 || cfg.Smoothing > 1 {
+		cfg.Smoothing = d.Smoothing
+	}
+
+	return &LoadMonitor{
+		cfg:     cfg,
+		sampler: sampler,
+		runtime: []metrics.Sample{{Name: goroutinesMetric}, {Name: heapBytesMetric}, {Name: memLimitMetric}},
+	}
+}
+
+// Sample takes a reading now and returns the smoothed load.
+func (lm *LoadMonitor) Sample() float64 {
+	if lm == nil {
+		return 0
+	}
+	var s LoadSample
+	limit := lm.readRuntime(&s)
+	if lm.sampler != nil {
+		lm.sampler(&s)
+	}
+	return lm.record(s, limit)
+}
+
+// readRuntime fills in the runtime fields of s and returns the runtime
+// memory limit, or 0 if none is set.
+func (lm *LoadMonitor) readRuntime(s *LoadSample) uint64 {
+	metrics.Read(lm.runtime)
+	var limit uint64
+	for _, m := range lm.runtime {
+		if m.Value.Kind() != metrics.KindUint64 {
+			continue
+		}
+		switch m.Name {
+		case goroutinesMetric:
+			s.Goroutines = int(m.Value.Uint64())
+		case heapBytesMetric:
+			s.HeapBytes = m.Value.Uint64()
+		case memLimitMetric:
+			if v := m.Value.Uint64(); v < math.MaxInt64 {
+				limit = v
+			}
+		}
+	}
+	return limit
+}
+
+// record folds s into the smoothed load. runtimeLimit stands in for an
+// unset MemoryLimit.
+func (lm *LoadMonitor) record(s LoadSample, runtimeLimit uint64) float64 {
+	limit := lm.cfg.MemoryLimit
+	if limit == 0 {
+		limit = runtimeLimit
+	}
+
+	b := LoadBreakdown{
+		Goroutines: ratio(float64(s.Goroutines), float64(lm.cfg.MaxGoroutines)),
+		Rings:      min(max(s.RingOccupancy, 0), 1),
+		Queue:      ratio(float64(s.QueueDepth), float64(lm.cfg.MaxQueueDepth)),
+	}
+	if limit > 0 {
+		b.Memory = ratio(float64(s.HeapBytes), float64(limit))
+	}
+	if s.FrameLatency > 0 {
+		// Frames within the target cost nothing; twice the target is full load
+		b.Frames = ratio(float64(s.FrameLatency-lm.cfg.FrameTarget), float64(lm.cfg.FrameTarget))
+	}
+
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+	if lm.samples == 0 {
+		lm.load = b.peak()
+	} else {
+		lm.load += lm.cfg.Smoothing * (b.peak() - lm.load)
+	}
+	lm.samples++
+	lm.last = s
+	lm.breakdown = b
+	return lm.load
+}
+
+// ratio returns v/limit clamped to 0-1.
+func ratio(v, limit float64) float64 {
+	if limit <= 0 {
+		return 0
+	}
+	return min(max(v/limit, 0), 1)
+}
+
+// GetSystemLoad returns the smoothed load, 0-1.
+func (lm *LoadMonitor) GetSystemLoad() float64 {
+	if lm == nil {
+		return 0
+	}
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+	return lm.load
+}
+
+// Stats returns the latest reading.
+func (lm *LoadMonitor) Stats() LoadStats {
+	if lm == nil {
+		return LoadStats{}
+	}
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+	return LoadStats{
+		Load:      lm.load,
+		Last:      lm.last,
+		Breakdown: lm.breakdown,
+		Samples:   lm.samples,
+	}
+}
+
+// Run samples every interval until ctx is done.
+func (lm *LoadMonitor) Run(ctx context.Context, interval time.Duration) {
+	if lm == nil || interval <= 0 {
+		return
+	}
+	lm.Sample()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			lm.Sample()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
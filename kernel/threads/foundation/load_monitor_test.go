@@ -0,0 +1,63 @@
+package foundation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadMonitor_BusiestInputSetsLoad(t *testing.T) {
+	var sample LoadSample
+	lm := NewLoadMonitor(LoadMonitorConfig{
+		MaxGoroutines: 1 << 20,
+		MemoryLimit:   1 << 50,
+		FrameTarget:   16 * time.Millisecond,
+		MaxQueueDepth: 10,
+		Smoothing:     1,
+	}, func(s *LoadSample) {
+		s.RingOccupancy = sample.RingOccupancy
+		s.FrameLatency = sample.FrameLatency
+		s.QueueDepth = sample.QueueDepth
+	})
+
+	assert.InDelta(t, 0, lm.Sample(), 0.01, "an idle node reads near zero")
+	assert.Positive(t, lm.Stats().Last.Goroutines, "runtime fields are read by the monitor")
+
+	sample.QueueDepth = 5
+	assert.InDelta(t, 0.5, lm.Sample(), 0.01)
+
+	sample.RingOccupancy = 0.8
+	assert.InDelta(t, 0.8, lm.Sample(), 0.01)
+	assert.InDelta(t, 0.5, lm.Stats().Breakdown.Queue, 0.01)
+
+	// Frames within the target are free; twice the target is full load
+	sample = LoadSample{FrameLatency: 12 * time.Millisecond}
+	lm.Sample()
+	assert.Zero(t, lm.Stats().Breakdown.Frames)
+	sample.FrameLatency = 40 * time.Millisecond
+	assert.Equal(t, 1.0, lm.Sample())
+	assert.Equal(t, 1.0, lm.GetSystemLoad())
+}
+
+func TestLoadMonitor_SmoothsSamples(t *testing.T) {
+	depth := 0
+	lm := NewLoadMonitor(LoadMonitorConfig{MaxGoroutines: 1 << 20, MaxQueueDepth: 10, Smoothing: 0.5}, func(s *LoadSample) {
+		s.QueueDepth = depth
+	})
+
+	lm.Sample()
+	depth = 10
+	assert.InDelta(t, 0.5, lm.Sample(), 0.01, "one busy sample moves the load half way")
+	assert.InDelta(t, 0.75, lm.Sample(), 0.01)
+	assert.Equal(t, uint64(3), lm.Stats().Samples)
+}
+
+func TestLoadMonitor_NilIsIdle(t *testing.T) {
+	var lm *LoadMonitor
+	assert.Zero(t, lm.Sample())
+	assert.Zero(t, lm.GetSystemLoad())
+	assert.Zero(t, lm.Stats().Samples)
+	lm.Run(context.Background(), time.Second)
+}
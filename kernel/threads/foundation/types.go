@@ -140,7 +140,9 @@ type MeshDelegator interface {
 	DelegateJob(ctx context.Context, job *Job) (*Result, error)
 }
 
-// Job represents a unit of work
+// Job represents a unit of work. It is the canonical job model: the mesh's
+// wire forms convert to and from it, and ToCapnp/FromCapnp carry it across
+// the browser and native boundary as a p2p.DelegateRequest.
 type Job struct {
 	ID         string
 	Type       string
@@ -151,6 +153,9 @@ type Job struct {
 	Deadline   time.Time
 	Source     string
 
+	// TraceParent is the W3C traceparent of the span that submitted the job
+	TraceParent string
+
 	// Prediction context
 	Features         map[string]float64
 	PredictedLatency time.Duration
@@ -161,6 +166,31 @@ type Job struct {
 	SubmittedAt time.Time
 }
 
+// Job parameters carrying sandbox limits to the runtime that executes the
+// job. Zero or absent leaves the runtime's own limit in place.
+const (
+	JobParamFuelLimit   = "wasm_fuel_limit"
+	JobParamMemoryLimit = "wasm_memory_limit"
+)
+
+// UintParam reads a numeric job parameter. It accepts the integer kinds a
+// job carries locally and the float64 it carries after a JSON round trip.
+func UintParam(v interface{}) uint64 {
+	switch n := v.(type) {
+	case int:
+		return uint64(max(n, 0))
+	case int64:
+		return uint64(max(n, 0))
+	case uint64:
+		return n
+	case float64:
+		if n > 0 {
+			return uint64(n)
+		}
+	}
+	return 0
+}
+
 // Result represents job execution result
 type Result struct {
 	JobID       string
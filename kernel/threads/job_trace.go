@@ -0,0 +1,39 @@
+package threads
+
+import (
+	"github.com/nmxmxh/inos_v1/kernel/threads/foundation"
+	"github.com/nmxmxh/inos_v1/kernel/utils/tracing"
+)
+
+// startJobSpan opens the supervisor span for a job, as a child of the span
+// that submitted it, and points the job's traceparent at the new span so
+// units and mesh delegation continue the trace.
+func startJobSpan(job *foundation.Job) *tracing.Span {
+	span := tracing.StartRemote(job.TraceParent, "supervisor.job", tracing.SpanKindInternal)
+	span.SetAttribute("job.id", job.ID)
+	span.SetAttribute("job.type", job.Type)
+	span.SetAttribute("job.operation", job.Operation)
+	job.TraceParent = span.Traceparent()
+	return span
+}
+
+// traceResult ends span when the job's result arrives and passes the result
+// on unchanged.
+func traceResult(span *tracing.Span, in <-chan *foundation.Result) <-chan *foundation.Result {
+	out := make(chan *foundation.Result, 1)
+	go func() {
+		defer close(out)
+		res, ok := <-in
+		switch {
+		case !ok || res == nil:
+			span.SetStatus(tracing.StatusError, "job finished without a result")
+		case !res.Success:
+			span.SetStatus(tracing.StatusError, res.Error)
+		}
+		span.End()
+		if ok {
+			out <- res
+		}
+	}()
+	return out
+}
@@ -0,0 +1,36 @@
+package threads
+
+import (
+	"testing"
+
+	"github.com/nmxmxh/inos_v1/kernel/threads/foundation"
+	"github.com/nmxmxh/inos_v1/kernel/utils/tracing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobSpan_ContinuesSubmitterTraceAndRecordsFailure(t *testing.T) {
+	previous := tracing.Default()
+	rec := tracing.NewRecorder("test", 8)
+	tracing.SetDefault(rec)
+	defer tracing.SetDefault(previous)
+
+	submitter := rec.StartRemote("", "host.command submit_job", tracing.SpanKindServer)
+	job := &foundation.Job{ID: "job-1", Type: "compute", TraceParent: submitter.Traceparent()}
+	span := startJobSpan(job)
+	assert.Equal(t, span.Traceparent(), job.TraceParent, "units continue from the supervisor span")
+
+	in := make(chan *foundation.Result, 1)
+	in <- &foundation.Result{JobID: "job-1", Error: "unit crashed"}
+	close(in)
+	res := <-traceResult(span, in)
+	require.NotNil(t, res)
+	assert.Equal(t, "job-1", res.JobID)
+
+	spans := rec.Snapshot()
+	require.Len(t, spans, 1)
+	assert.Equal(t, submitter.Context().TraceIDString(), spans[0].TraceID)
+	assert.Equal(t, submitter.Context().SpanIDString(), spans[0].ParentSpanID)
+	assert.Equal(t, tracing.StatusError, spans[0].Status)
+	assert.Equal(t, "unit crashed", spans[0].StatusMessage)
+}
@@ -0,0 +1,129 @@
+package threads
+
+import (
+	"time"
+)
+
+// RestartStrategy decides which children restart when one fails.
+type RestartStrategy string
+
+const (
+	// OneForOne restarts only the failed child.
+	OneForOne RestartStrategy = "one_for_one"
+	// RestForOne restarts the failed child and every child spawned after it,
+	// for children that depend on state set up by earlier siblings.
+	RestForOne RestartStrategy = "rest_for_one"
+)
+
+// RestartPolicy configures how the supervisor restarts failed children.
+// Each child has a restart budget (the maxRestarts given at spawn) that
+// refills as restarts age out of Window; backoff doubles with every restart
+// still inside the window.
+type RestartPolicy struct {
+	Strategy    RestartStrategy
+	BaseBackoff time.Duration // Delay before the first restart
+	MaxBackoff  time.Duration // Backoff cap
+	Window      time.Duration // Restarts older than this no longer count
+
+	// EscalateAfter is how many children may exhaust their budget before the
+	// supervisor escalates to OnEscalate. Zero disables escalation.
+	EscalateAfter int
+	OnEscalate    func(child string, err error)
+}
+
+// DefaultRestartPolicy returns the policy used when SupervisorConfig leaves
+// Restart unset.
+func DefaultRestartPolicy() RestartPolicy {
+	return RestartPolicy{
+		Strategy:      OneForOne,
+		BaseBackoff:   time.Second,
+		MaxBackoff:    30 * time.Second,
+		Window:        5 * time.Minute,
+		EscalateAfter: 3,
+	}
+}
+
+// withDefaults fills unset fields from DefaultRestartPolicy.
+func (p RestartPolicy) withDefaults() RestartPolicy {
+	d := DefaultRestartPolicy()
+	if p.Strategy == "" {
+		p.Strategy = d.Strategy
+	}
+	if p.BaseBackoff <= 0 {
+		p.BaseBackoff = d.BaseBackoff
+	}
+	if p.MaxBackoff < p.BaseBackoff {
+		p.MaxBackoff = max(d.MaxBackoff, p.BaseBackoff)
+	}
+	if p.Window <= 0 {
+		p.Window = d.Window
+	}
+	return p
+}
+
+// ThreadStats are the restart counters of one supervised child.
+type ThreadStats struct {
+	Restarts    int       `json:"restarts"`     // Restarts since spawn
+	Recent      int       `json:"recent"`       // Restarts inside the budget window
+	Failed      bool      `json:"failed"`       // Budget exhausted; no longer restarted
+	LastRestart time.Time `json:"last_restart"` // Zero if never restarted
+	LastError   string    `json:"last_error,omitempty"`
+}
+
+// restartBudget tracks the restarts of one child.
+type restartBudget struct {
+	history []time.Time // Restarts inside the window, oldest first
+	total   int
+}
+
+// next records a failure at now and returns the backoff before restarting,
+// or false once the child has used up its budget of limit restarts.
+func (b *restartBudget) next(p RestartPolicy, limit int, now time.Time) (time.Duration, bool) {
+	b.expire(p.Window, now)
+	if len(b.history) >= limit {
+		return 0, false
+	}
+
+	backoff := p.BaseBackoff << len(b.history)
+	if backoff > p.MaxBackoff || backoff <= 0 {
+		backoff = p.MaxBackoff
+	}
+	b.history = append(b.history, now)
+	b.total++
+	return backoff, true
+}
+
+// recent counts the restarts inside the window without expiring any.
+func (b *restartBudget) recent(window time.Duration, now time.Time) int {
+	n := 0
+	for _, t := range b.history {
+		if now.Sub(t) < window {
+			n++
+		}
+	}
+	return n
+}
+
+func (b *restartBudget) expire(window time.Duration, now time.Time) {
+	kept := b.history[:0]
+	for _, t := range b.history {
+		if now.Sub(t) < window {
+			kept = append(kept, t)
+		}
+	}
+	b.history = kept
+}
+
+// dependents returns the children that restart alongside failed under the
+// strategy, in spawn order.
+func (p RestartPolicy) dependents(order []string, failed string) []string {
+	if p.Strategy != RestForOne {
+		return nil
+	}
+	for i, name := range order {
+		if name == failed {
+			return append([]string(nil), order[i+1:]...)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package threads
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRestartBudget_ExponentialBackoffAndExhaustion(t *testing.T) {
+	p := RestartPolicy{BaseBackoff: time.Second, MaxBackoff: 3 * time.Second}.withDefaults()
+	var b restartBudget
+	now := time.Now()
+
+	var backoffs []time.Duration
+	for i := 0; i < 3; i++ {
+		backoff, ok := b.next(p, 3, now)
+		assert.True(t, ok)
+		backoffs = append(backoffs, backoff)
+	}
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second, 3 * time.Second}, backoffs)
+
+	_, ok := b.next(p, 3, now)
+	assert.False(t, ok, "fourth failure inside the window exhausts a budget of 3")
+	assert.Equal(t, 3, b.total)
+}
+
+func TestRestartBudget_RefillsAfterWindow(t *testing.T) {
+	p := RestartPolicy{Window: time.Minute}.withDefaults()
+	var b restartBudget
+	start := time.Now()
+
+	_, ok := b.next(p, 1, start)
+	assert.True(t, ok)
+	_, ok = b.next(p, 1, start.Add(30*time.Second))
+	assert.False(t, ok)
+
+	backoff, ok := b.next(p, 1, start.Add(2*time.Minute))
+	assert.True(t, ok, "restarts older than the window no longer count")
+	assert.Equal(t, p.BaseBackoff, backoff)
+	assert.Equal(t, 1, b.recent(p.Window, start.Add(2*time.Minute)))
+}
+
+func TestRestartPolicy_Dependents(t *testing.T) {
+	order := []string{"matchmaker", "watcher", "adjuster"}
+
+	oneForOne := DefaultRestartPolicy()
+	assert.Empty(t, oneForOne.dependents(order, "matchmaker"))
+
+	restForOne := RestartPolicy{Strategy: RestForOne}.withDefaults()
+	assert.Equal(t, []string{"watcher", "adjuster"}, restForOne.dependents(order, "matchmaker"))
+	assert.Empty(t, restForOne.dependents(order, "adjuster"))
+	assert.Empty(t, restForOne.dependents(order, "unknown"))
+}
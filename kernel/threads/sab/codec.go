@@ -0,0 +1,28 @@
+package sab
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// Codec reads and writes fixed-offset fields of a SAB record. Fields are
+// little-endian and floats are IEEE 754 bit patterns, which is what the host's
+// DataView(..., true) getters and Rust's from_le_bytes expect. Writers go
+// through it instead of reinterpreting float memory with unsafe.
+type Codec []byte
+
+func (c Codec) PutUint32(off uint32, v uint32) { binary.LittleEndian.PutUint32(c[off:], v) }
+func (c Codec) PutUint64(off uint32, v uint64) { binary.LittleEndian.PutUint64(c[off:], v) }
+func (c Codec) PutInt64(off uint32, v int64)   { c.PutUint64(off, uint64(v)) }
+func (c Codec) PutFloat32(off uint32, v float32) {
+	c.PutUint32(off, math.Float32bits(v))
+}
+func (c Codec) PutFloat64(off uint32, v float64) {
+	c.PutUint64(off, math.Float64bits(v))
+}
+
+func (c Codec) Uint32(off uint32) uint32   { return binary.LittleEndian.Uint32(c[off:]) }
+func (c Codec) Uint64(off uint32) uint64   { return binary.LittleEndian.Uint64(c[off:]) }
+func (c Codec) Int64(off uint32) int64     { return int64(c.Uint64(off)) }
+func (c Codec) Float32(off uint32) float32 { return math.Float32frombits(c.Uint32(off)) }
+func (c Codec) Float64(off uint32) float64 { return math.Float64frombits(c.Uint64(off)) }
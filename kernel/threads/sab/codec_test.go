@@ -0,0 +1,32 @@
+package sab
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCodec_FloatsKeepTheirBits(t *testing.T) {
+	c := make(Codec, 24)
+	for _, v := range []float32{0, float32(math.Copysign(0, -1)), 1.5, -3.25e-7, float32(math.Inf(1)), float32(math.NaN())} {
+		c.PutFloat32(4, v)
+		if got := c.Float32(4); math.Float32bits(got) != math.Float32bits(v) {
+			t.Fatalf("float32 %v came back as bits %08x", v, math.Float32bits(got))
+		}
+	}
+	for _, v := range []float64{math.Copysign(0, -1), math.MaxFloat64, math.SmallestNonzeroFloat64, math.Inf(-1)} {
+		c.PutFloat64(8, v)
+		if got := c.Float64(8); math.Float64bits(got) != math.Float64bits(v) {
+			t.Fatalf("float64 %v came back as bits %016x", v, math.Float64bits(got))
+		}
+	}
+
+	// Little-endian, as DataView reads with littleEndian=true
+	c.PutFloat32(0, 1)
+	if c[0] != 0x00 || c[1] != 0x00 || c[2] != 0x80 || c[3] != 0x3F {
+		t.Fatalf("expected 1.0 as 00 00 80 3f, got % x", c[:4])
+	}
+	c.PutInt64(16, -2)
+	if c.Int64(16) != -2 || c.Uint64(16) != math.MaxUint64-1 {
+		t.Fatalf("expected -2 as two's complement, got %d", c.Int64(16))
+	}
+}
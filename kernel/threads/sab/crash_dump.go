@@ -0,0 +1,73 @@
+package sab
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Crash dump encoding (little-endian, at OFFSET_CRASH_DUMP):
+//
+//	0x00 magic    u32  CRASH_DUMP_MAGIC
+//	0x04 sequence u32  crashes recorded into this SAB
+//	0x08 length   u32  report bytes that follow
+//	0x0C reserved u32
+//	0x10 report   JSON, at most MAX_CRASH_REPORT_SIZE bytes
+const (
+	crashDumpFixedSize = 16
+
+	// MAX_CRASH_REPORT_SIZE is the largest report the crash dump region holds.
+	MAX_CRASH_REPORT_SIZE = SIZE_CRASH_DUMP - crashDumpFixedSize
+)
+
+// CrashDump is a decoded crash dump region.
+type CrashDump struct {
+	Sequence uint32
+	Report   []byte
+}
+
+// EncodeCrashDump builds the SIZE_CRASH_DUMP bytes for a report. Callers must
+// shrink reports to MAX_CRASH_REPORT_SIZE first; a partial JSON document is
+// worse than none.
+func EncodeCrashDump(sequence uint32, report []byte) ([]byte, error) {
+	if len(report) > int(MAX_CRASH_REPORT_SIZE) {
+		return nil, &LayoutError{
+			Code:    "CRASH_DUMP_TOO_LARGE",
+			Message: fmt.Sprintf("report of %d bytes exceeds %d", len(report), MAX_CRASH_REPORT_SIZE),
+		}
+	}
+
+	buf := make([]byte, SIZE_CRASH_DUMP)
+	binary.LittleEndian.PutUint32(buf[0:], CRASH_DUMP_MAGIC)
+	binary.LittleEndian.PutUint32(buf[4:], sequence)
+	binary.LittleEndian.PutUint32(buf[8:], uint32(len(report)))
+	copy(buf[crashDumpFixedSize:], report)
+	return buf, nil
+}
+
+// DecodeCrashDump parses the crash dump region. It returns nil without an
+// error when no crash has been recorded.
+func DecodeCrashDump(buf []byte) (*CrashDump, error) {
+	if len(buf) < crashDumpFixedSize {
+		return nil, &LayoutError{Code: "CRASH_DUMP_TRUNCATED", Message: "crash dump shorter than its fixed fields"}
+	}
+
+	magic := binary.LittleEndian.Uint32(buf[0:])
+	if magic == 0 {
+		return nil, nil
+	}
+	if magic != CRASH_DUMP_MAGIC {
+		return nil, &LayoutError{Code: "CRASH_DUMP_BAD_MAGIC", Message: fmt.Sprintf("unexpected crash dump magic 0x%08x", magic)}
+	}
+
+	length := binary.LittleEndian.Uint32(buf[8:])
+	if length > MAX_CRASH_REPORT_SIZE || crashDumpFixedSize+int(length) > len(buf) {
+		return nil, &LayoutError{Code: "CRASH_DUMP_TRUNCATED", Message: fmt.Sprintf("report of %d bytes does not fit", length)}
+	}
+
+	report := make([]byte, length)
+	copy(report, buf[crashDumpFixedSize:])
+	return &CrashDump{
+		Sequence: binary.LittleEndian.Uint32(buf[4:]),
+		Report:   report,
+	}, nil
+}
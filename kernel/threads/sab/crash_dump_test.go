@@ -0,0 +1,42 @@
+package sab
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCrashDump_RoundTrip(t *testing.T) {
+	report := []byte(`{"reason":"boom"}`)
+	buf, err := EncodeCrashDump(3, report)
+	if err != nil {
+		t.Fatalf("EncodeCrashDump failed: %v", err)
+	}
+	if len(buf) != int(SIZE_CRASH_DUMP) {
+		t.Fatalf("expected %d bytes, got %d", SIZE_CRASH_DUMP, len(buf))
+	}
+
+	dump, err := DecodeCrashDump(buf)
+	if err != nil {
+		t.Fatalf("DecodeCrashDump failed: %v", err)
+	}
+	if dump.Sequence != 3 || !bytes.Equal(dump.Report, report) {
+		t.Fatalf("unexpected dump: seq=%d report=%q", dump.Sequence, dump.Report)
+	}
+}
+
+func TestCrashDump_EmptyAndOversized(t *testing.T) {
+	dump, err := DecodeCrashDump(make([]byte, SIZE_CRASH_DUMP))
+	if err != nil || dump != nil {
+		t.Fatalf("expected no dump in a blank region, got %v, %v", dump, err)
+	}
+
+	if _, err := EncodeCrashDump(1, make([]byte, MAX_CRASH_REPORT_SIZE+1)); err == nil {
+		t.Fatal("expected an oversized report to be rejected")
+	}
+
+	buf, _ := EncodeCrashDump(1, nil)
+	buf[0] = 0xFF
+	if _, err := DecodeCrashDump(buf); err == nil {
+		t.Fatal("expected a bad magic to be rejected")
+	}
+}
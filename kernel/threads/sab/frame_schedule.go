@@ -0,0 +1,74 @@
+package sab
+
+import (
+	"math"
+	"time"
+)
+
+// Frame schedule encoding (little-endian, at OFFSET_FRAME_SCHEDULE). The
+// host writes the times first and bumps the sequence last; times are f64
+// Unix milliseconds.
+//
+//	0x00 sequence    u32  frames reported; 0 until the host reports one
+//	0x04 reserved    u32
+//	0x08 frame start f64  when the current frame began
+//	0x10 idle start  f64  when its work finished and the idle window opened
+//	0x18 deadline    f64  when the next frame is due and the window closes
+const frameScheduleFixedSize = 32
+
+// FrameSchedule is the host's report of the current frame.
+type FrameSchedule struct {
+	Sequence   uint32
+	FrameStart time.Time
+	IdleStart  time.Time
+	Deadline   time.Time
+}
+
+// IdleAt reports how much of the frame's idle window is left at now, or
+// zero outside it.
+func (f FrameSchedule) IdleAt(now time.Time) time.Duration {
+	if f.Sequence == 0 || now.Before(f.IdleStart) || !now.Before(f.Deadline) {
+		return 0
+	}
+	return f.Deadline.Sub(now)
+}
+
+// EncodeFrameSchedule builds the frame schedule bytes, as the host writes
+// them.
+func EncodeFrameSchedule(f FrameSchedule) []byte {
+	c := make(Codec, frameScheduleFixedSize)
+	c.PutUint32(0x00, f.Sequence)
+	c.PutFloat64(0x08, unixMillis(f.FrameStart))
+	c.PutFloat64(0x10, unixMillis(f.IdleStart))
+	c.PutFloat64(0x18, unixMillis(f.Deadline))
+	return c
+}
+
+// DecodeFrameSchedule parses the frame schedule region. A zero Sequence
+// means the host has not reported a frame.
+func DecodeFrameSchedule(buf []byte) (FrameSchedule, error) {
+	if len(buf) < frameScheduleFixedSize {
+		return FrameSchedule{}, &LayoutError{Code: "FRAME_SCHEDULE_TRUNCATED", Message: "frame schedule shorter than its fixed fields"}
+	}
+	c := Codec(buf)
+	return FrameSchedule{
+		Sequence:   c.Uint32(0x00),
+		FrameStart: fromUnixMillis(c.Float64(0x08)),
+		IdleStart:  fromUnixMillis(c.Float64(0x10)),
+		Deadline:   fromUnixMillis(c.Float64(0x18)),
+	}, nil
+}
+
+func unixMillis(t time.Time) float64 {
+	if t.IsZero() {
+		return 0
+	}
+	return float64(t.UnixMicro()) / 1000
+}
+
+func fromUnixMillis(ms float64) time.Time {
+	if ms <= 0 || math.IsNaN(ms) || math.IsInf(ms, 0) {
+		return time.Time{}
+	}
+	return time.UnixMicro(int64(ms * 1000))
+}
@@ -0,0 +1,50 @@
+package sab
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFrameSchedule_RoundTrip(t *testing.T) {
+	start := time.UnixMicro(1_700_000_000_000_000)
+	want := FrameSchedule{
+		Sequence:   42,
+		FrameStart: start,
+		IdleStart:  start.Add(6 * time.Millisecond),
+		Deadline:   start.Add(16 * time.Millisecond),
+	}
+
+	buf := EncodeFrameSchedule(want)
+	if len(buf) > int(SIZE_FRAME_SCHEDULE) {
+		t.Fatalf("encoding of %d bytes exceeds the region", len(buf))
+	}
+	got, err := DecodeFrameSchedule(buf)
+	if err != nil {
+		t.Fatalf("DecodeFrameSchedule failed: %v", err)
+	}
+	if got.Sequence != 42 || !got.FrameStart.Equal(want.FrameStart) || !got.IdleStart.Equal(want.IdleStart) || !got.Deadline.Equal(want.Deadline) {
+		t.Fatalf("unexpected schedule %+v", got)
+	}
+
+	if idle := got.IdleAt(start.Add(10 * time.Millisecond)); idle != 6*time.Millisecond {
+		t.Errorf("expected 6ms of idle time left, got %v", idle)
+	}
+	for _, at := range []time.Duration{2 * time.Millisecond, 16 * time.Millisecond} {
+		if idle := got.IdleAt(start.Add(at)); idle != 0 {
+			t.Errorf("expected no idle time %v into the frame, got %v", at, idle)
+		}
+	}
+}
+
+func TestFrameSchedule_BlankAndTruncated(t *testing.T) {
+	f, err := DecodeFrameSchedule(make([]byte, SIZE_FRAME_SCHEDULE))
+	if err != nil || f.Sequence != 0 || !f.Deadline.IsZero() {
+		t.Fatalf("expected no frame in a blank region, got %+v, %v", f, err)
+	}
+	if f.IdleAt(time.Now()) != 0 {
+		t.Fatal("expected no idle window before the host reports a frame")
+	}
+	if _, err := DecodeFrameSchedule(make([]byte, 8)); err == nil {
+		t.Fatal("expected a truncated region to fail")
+	}
+}
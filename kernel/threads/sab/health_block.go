@@ -0,0 +1,425 @@
+package sab
+
+import (
+	"fmt"
+	"time"
+)
+
+// Health block encoding (little-endian, at OFFSET_HEALTH_BLOCK). The kernel
+// writes it as a seqlock: the sequence is made odd, the body written, then
+// the sequence made even again. A reader that sees an odd sequence, or a
+// different one after reading the body, read a torn block and retries.
+//
+// Field offsets are layout constants from sab_layout.capnp. The header is
+// at the HEALTH_HEADER_* offsets; HEALTH_SECTION_COUNT sections of
+// HEALTH_SECTION_SIZE bytes follow it in HEALTH_SECTION_* order. Each starts
+// with status u32 (HealthStatus*) and score f32 (0-1), then its counters at
+// the HEALTH_MESH_*, HEALTH_GOSSIP_* and so on offsets. New versions only
+// append sections or fields, so readers check the count and ignore what
+// they do not know.
+const (
+	healthBlockSize = HEALTH_HEADER_SIZE + HEALTH_SECTION_COUNT*HEALTH_SECTION_SIZE
+
+	// Reads retried before a block that keeps changing is reported busy
+	healthBlockReadAttempts = 8
+)
+
+// Subsystem health states.
+const (
+	HealthStatusUnknown  uint32 = iota // Subsystem not running or not reported
+	HealthStatusOK                     // Working normally
+	HealthStatusDegraded               // Working with reduced service
+	HealthStatusDown                   // Not working
+)
+
+// HealthSection is the status every section starts with.
+type HealthSection struct {
+	Status uint32  `json:"status"`
+	Score  float32 `json:"score"`
+}
+
+// MeshHealth is the mesh coordinator section.
+type MeshHealth struct {
+	HealthSection
+	TotalPeers       uint32  `json:"total_peers"`
+	ConnectedPeers   uint32  `json:"connected_peers"`
+	LocalChunks      uint32  `json:"local_chunks"`
+	ChunksAvailable  uint32  `json:"chunks_available"`
+	AvgReputation    float32 `json:"avg_reputation"`
+	SectorID         uint32  `json:"sector_id"`
+	SectorMembers    uint32  `json:"sector_members"`
+	RegionID         uint32  `json:"region_id"`
+	FetchSuccessRate float32 `json:"fetch_success_rate"`
+	Busy             bool    `json:"busy"`
+	InboundHeldBytes uint64  `json:"inbound_held_bytes"`
+}
+
+// GossipHealth is the gossip section.
+type GossipHealth struct {
+	HealthSection
+	MessagesSent        uint64  `json:"messages_sent"`
+	MessagesReceived    uint64  `json:"messages_received"`
+	MessagesDropped     uint64  `json:"messages_dropped"`
+	RateLimited         uint64  `json:"rate_limited"`
+	FailedSignatures    uint64  `json:"failed_signatures"`
+	QueueLength         uint32  `json:"queue_length"`
+	Fanout              uint32  `json:"fanout"`
+	MessageRate         float32 `json:"message_rate"`
+	DeliveryRatio       float32 `json:"delivery_ratio"`
+	DuplicateRatio      float32 `json:"duplicate_ratio"`
+	PropagationP95Ms    float32 `json:"propagation_p95_ms"`
+	NetworkSizeEstimate uint32  `json:"network_size_estimate"`
+}
+
+// DHTHealth is the DHT section.
+type DHTHealth struct {
+	HealthSection
+	Entries             uint32  `json:"entries"`
+	Peers               uint32  `json:"peers"`
+	NetworkSizeEstimate uint32  `json:"network_size_estimate"`
+	LookupSuccessRate   float32 `json:"lookup_success_rate"`
+	LookupP95Ms         uint32  `json:"lookup_p95_ms"`
+	Queries             uint64  `json:"queries"`
+	FailedQueries       uint64  `json:"failed_queries"`
+	RejectedNodeIDs     uint64  `json:"rejected_node_ids"`
+	InvalidNodeReplies  uint64  `json:"invalid_node_replies"`
+}
+
+// TransportHealth is the peer transport section.
+type TransportHealth struct {
+	HealthSection
+	ActiveConnections  uint32  `json:"active_connections"`
+	WebSocketFallbacks uint32  `json:"websocket_fallbacks"`
+	BytesSent          uint64  `json:"bytes_sent"`
+	BytesReceived      uint64  `json:"bytes_received"`
+	MessagesSent       uint64  `json:"messages_sent"`
+	MessagesReceived   uint64  `json:"messages_received"`
+	FailedMessages     uint64  `json:"failed_messages"`
+	LatencyP50Ms       float32 `json:"latency_p50_ms"`
+	LatencyP95Ms       float32 `json:"latency_p95_ms"`
+	LatencyP99Ms       float32 `json:"latency_p99_ms"`
+	ErrorRate          float32 `json:"error_rate"`
+	TotalConnections   uint64  `json:"total_connections"`
+}
+
+// EconomyHealth is the ledger and delegation section.
+type EconomyHealth struct {
+	HealthSection
+	Balance       int64  `json:"balance"`
+	Escrowed      uint64 `json:"escrowed"`
+	Settled       uint64 `json:"settled"`
+	Refunded      uint64 `json:"refunded"`
+	Slashed       uint64 `json:"slashed"`
+	ActiveEscrows uint32 `json:"active_escrows"`
+	Accounts      uint32 `json:"accounts"`
+	Settlements   uint64 `json:"settlements"`
+	JobsExecuted  uint64 `json:"jobs_executed"`
+	JobsFailed    uint64 `json:"jobs_failed"`
+}
+
+// SupervisorHealth is the thread supervisor section.
+type SupervisorHealth struct {
+	HealthSection
+	ActiveThreads    uint32 `json:"active_threads"`
+	FailedThreads    uint32 `json:"failed_threads"`
+	RestartedThreads uint32 `json:"restarted_threads"`
+	Messages         uint64 `json:"messages"`
+}
+
+// HealthBlock is the decoded health block.
+type HealthBlock struct {
+	Sequence   uint32           `json:"sequence"`
+	Version    uint32           `json:"version"`
+	WrittenAt  time.Time        `json:"written_at"`
+	Mesh       MeshHealth       `json:"mesh"`
+	Gossip     GossipHealth     `json:"gossip"`
+	DHT        DHTHealth        `json:"dht"`
+	Transport  TransportHealth  `json:"transport"`
+	Economy    EconomyHealth    `json:"economy"`
+	Supervisor SupervisorHealth `json:"supervisor"`
+}
+
+// RawWriter is the SAB access a health block writer needs.
+type RawWriter interface {
+	WriteRaw(offset uint32, data []byte) error
+}
+
+func putHealthSection(c Codec, s HealthSection) {
+	c.PutUint32(HEALTH_SECTION_STATUS, s.Status)
+	c.PutFloat32(HEALTH_SECTION_SCORE, s.Score)
+}
+
+func healthSection(c Codec) HealthSection {
+	return HealthSection{Status: c.Uint32(HEALTH_SECTION_STATUS), Score: c.Float32(HEALTH_SECTION_SCORE)}
+}
+
+func healthSectionAt(buf []byte, index uint32) Codec {
+	start := HEALTH_HEADER_SIZE + index*HEALTH_SECTION_SIZE
+	return Codec(buf[start : start+HEALTH_SECTION_SIZE])
+}
+
+// EncodeHealthBlock builds the health block bytes with the given sequence,
+// which must be even for a complete block.
+func EncodeHealthBlock(sequence uint32, b *HealthBlock) []byte {
+	buf := make(Codec, healthBlockSize)
+	buf.PutUint32(HEALTH_HEADER_SEQUENCE, sequence)
+	buf.PutUint32(HEALTH_HEADER_VERSION, HEALTH_BLOCK_VERSION)
+	buf.PutUint32(HEALTH_HEADER_SECTION_COUNT, HEALTH_SECTION_COUNT)
+	buf.PutUint32(HEALTH_HEADER_SECTION_SIZE, HEALTH_SECTION_SIZE)
+	buf.PutFloat64(HEALTH_HEADER_WRITTEN_AT, unixMillis(b.WrittenAt))
+
+	f := healthSectionAt(buf, HEALTH_SECTION_MESH)
+	putHealthSection(f, b.Mesh.HealthSection)
+	f.PutUint32(HEALTH_MESH_TOTAL_PEERS, b.Mesh.TotalPeers)
+	f.PutUint32(HEALTH_MESH_CONNECTED_PEERS, b.Mesh.ConnectedPeers)
+	f.PutUint32(HEALTH_MESH_LOCAL_CHUNKS, b.Mesh.LocalChunks)
+	f.PutUint32(HEALTH_MESH_CHUNKS_AVAILABLE, b.Mesh.ChunksAvailable)
+	f.PutFloat32(HEALTH_MESH_AVG_REPUTATION, b.Mesh.AvgReputation)
+	f.PutUint32(HEALTH_MESH_SECTOR_ID, b.Mesh.SectorID)
+	f.PutUint32(HEALTH_MESH_SECTOR_MEMBERS, b.Mesh.SectorMembers)
+	f.PutUint32(HEALTH_MESH_REGION_ID, b.Mesh.RegionID)
+	f.PutFloat32(HEALTH_MESH_FETCH_SUCCESS, b.Mesh.FetchSuccessRate)
+	if b.Mesh.Busy {
+		f.PutUint32(HEALTH_MESH_BUSY, 1)
+	}
+	f.PutUint64(HEALTH_MESH_INBOUND_HELD_BYTES, b.Mesh.InboundHeldBytes)
+
+	f = healthSectionAt(buf, HEALTH_SECTION_GOSSIP)
+	putHealthSection(f, b.Gossip.HealthSection)
+	f.PutUint64(HEALTH_GOSSIP_SENT, b.Gossip.MessagesSent)
+	f.PutUint64(HEALTH_GOSSIP_RECEIVED, b.Gossip.MessagesReceived)
+	f.PutUint64(HEALTH_GOSSIP_DROPPED, b.Gossip.MessagesDropped)
+	f.PutUint64(HEALTH_GOSSIP_RATE_LIMITED, b.Gossip.RateLimited)
+	f.PutUint64(HEALTH_GOSSIP_FAILED_SIGNATURES, b.Gossip.FailedSignatures)
+	f.PutUint32(HEALTH_GOSSIP_QUEUE_LENGTH, b.Gossip.QueueLength)
+	f.PutUint32(HEALTH_GOSSIP_FANOUT, b.Gossip.Fanout)
+	f.PutFloat32(HEALTH_GOSSIP_MESSAGE_RATE, b.Gossip.MessageRate)
+	f.PutFloat32(HEALTH_GOSSIP_DELIVERY_RATIO, b.Gossip.DeliveryRatio)
+	f.PutFloat32(HEALTH_GOSSIP_DUPLICATE_RATIO, b.Gossip.DuplicateRatio)
+	f.PutFloat32(HEALTH_GOSSIP_PROPAGATION_P95, b.Gossip.PropagationP95Ms)
+	f.PutUint32(HEALTH_GOSSIP_NETWORK_SIZE, b.Gossip.NetworkSizeEstimate)
+
+	f = healthSectionAt(buf, HEALTH_SECTION_DHT)
+	putHealthSection(f, b.DHT.HealthSection)
+	f.PutUint32(HEALTH_DHT_ENTRIES, b.DHT.Entries)
+	f.PutUint32(HEALTH_DHT_PEERS, b.DHT.Peers)
+	f.PutUint32(HEALTH_DHT_NETWORK_SIZE, b.DHT.NetworkSizeEstimate)
+	f.PutFloat32(HEALTH_DHT_LOOKUP_SUCCESS, b.DHT.LookupSuccessRate)
+	f.PutUint32(HEALTH_DHT_LOOKUP_P95, b.DHT.LookupP95Ms)
+	f.PutUint64(HEALTH_DHT_QUERIES, b.DHT.Queries)
+	f.PutUint64(HEALTH_DHT_FAILED_QUERIES, b.DHT.FailedQueries)
+	f.PutUint64(HEALTH_DHT_REJECTED_NODE_IDS, b.DHT.RejectedNodeIDs)
+	f.PutUint64(HEALTH_DHT_INVALID_NODE_REPLIES, b.DHT.InvalidNodeReplies)
+
+	f = healthSectionAt(buf, HEALTH_SECTION_TRANSPORT)
+	putHealthSection(f, b.Transport.HealthSection)
+	f.PutUint32(HEALTH_TRANSPORT_ACTIVE_CONNECTIONS, b.Transport.ActiveConnections)
+	f.PutUint32(HEALTH_TRANSPORT_WEBSOCKET_FALLBACKS, b.Transport.WebSocketFallbacks)
+	f.PutUint64(HEALTH_TRANSPORT_BYTES_SENT, b.Transport.BytesSent)
+	f.PutUint64(HEALTH_TRANSPORT_BYTES_RECEIVED, b.Transport.BytesReceived)
+	f.PutUint64(HEALTH_TRANSPORT_MESSAGES_SENT, b.Transport.MessagesSent)
+	f.PutUint64(HEALTH_TRANSPORT_MESSAGES_RECEIVED, b.Transport.MessagesReceived)
+	f.PutUint64(HEALTH_TRANSPORT_FAILED_MESSAGES, b.Transport.FailedMessages)
+	f.PutFloat32(HEALTH_TRANSPORT_LATENCY_P50, b.Transport.LatencyP50Ms)
+	f.PutFloat32(HEALTH_TRANSPORT_LATENCY_P95, b.Transport.LatencyP95Ms)
+	f.PutFloat32(HEALTH_TRANSPORT_LATENCY_P99, b.Transport.LatencyP99Ms)
+	f.PutFloat32(HEALTH_TRANSPORT_ERROR_RATE, b.Transport.ErrorRate)
+	f.PutUint64(HEALTH_TRANSPORT_TOTAL_CONNECTIONS, b.Transport.TotalConnections)
+
+	f = healthSectionAt(buf, HEALTH_SECTION_ECONOMY)
+	putHealthSection(f, b.Economy.HealthSection)
+	f.PutInt64(HEALTH_ECONOMY_BALANCE, b.Economy.Balance)
+	f.PutUint64(HEALTH_ECONOMY_ESCROWED, b.Economy.Escrowed)
+	f.PutUint64(HEALTH_ECONOMY_SETTLED, b.Economy.Settled)
+	f.PutUint64(HEALTH_ECONOMY_REFUNDED, b.Economy.Refunded)
+	f.PutUint64(HEALTH_ECONOMY_SLASHED, b.Economy.Slashed)
+	f.PutUint32(HEALTH_ECONOMY_ACTIVE_ESCROWS, b.Economy.ActiveEscrows)
+	f.PutUint32(HEALTH_ECONOMY_ACCOUNTS, b.Economy.Accounts)
+	f.PutUint64(HEALTH_ECONOMY_SETTLEMENTS, b.Economy.Settlements)
+	f.PutUint64(HEALTH_ECONOMY_JOBS_EXECUTED, b.Economy.JobsExecuted)
+	f.PutUint64(HEALTH_ECONOMY_JOBS_FAILED, b.Economy.JobsFailed)
+
+	f = healthSectionAt(buf, HEALTH_SECTION_SUPERVISOR)
+	putHealthSection(f, b.Supervisor.HealthSection)
+	f.PutUint32(HEALTH_SUPERVISOR_ACTIVE_THREADS, b.Supervisor.ActiveThreads)
+	f.PutUint32(HEALTH_SUPERVISOR_FAILED_THREADS, b.Supervisor.FailedThreads)
+	f.PutUint32(HEALTH_SUPERVISOR_RESTARTED_THREADS, b.Supervisor.RestartedThreads)
+	f.PutUint64(HEALTH_SUPERVISOR_MESSAGES, b.Supervisor.Messages)
+	return buf
+}
+
+// DecodeHealthBlock parses the health block region. A region the kernel has
+// not written yet decodes to a zero Sequence; one caught mid-write returns a
+// HEALTH_BLOCK_BUSY error, see IsHealthBlockBusy.
+func DecodeHealthBlock(buf []byte) (*HealthBlock, error) {
+	if len(buf) < int(HEALTH_HEADER_SIZE) {
+		return nil, &LayoutError{Code: "HEALTH_BLOCK_TRUNCATED", Message: "health block shorter than its fixed fields"}
+	}
+	c := Codec(buf)
+	b := &HealthBlock{
+		Sequence:  c.Uint32(HEALTH_HEADER_SEQUENCE),
+		Version:   c.Uint32(HEALTH_HEADER_VERSION),
+		WrittenAt: fromUnixMillis(c.Float64(HEALTH_HEADER_WRITTEN_AT)),
+	}
+	if b.Sequence%2 == 1 {
+		return nil, &LayoutError{Code: "HEALTH_BLOCK_BUSY", Message: "health block is being written"}
+	}
+	if b.Sequence == 0 {
+		return b, nil
+	}
+
+	count := c.Uint32(HEALTH_HEADER_SECTION_COUNT)
+	if size := c.Uint32(HEALTH_HEADER_SECTION_SIZE); size != HEALTH_SECTION_SIZE {
+		return nil, &LayoutError{Code: "HEALTH_BLOCK_BAD_SECTION", Message: fmt.Sprintf("section size %d, expected %d", size, HEALTH_SECTION_SIZE)}
+	}
+	if count > HEALTH_SECTION_COUNT {
+		count = HEALTH_SECTION_COUNT // Sections from a newer writer
+	}
+	if len(buf) < int(HEALTH_HEADER_SIZE+count*HEALTH_SECTION_SIZE) {
+		return nil, &LayoutError{Code: "HEALTH_BLOCK_TRUNCATED", Message: fmt.Sprintf("%d sections do not fit", count)}
+	}
+
+	for i := uint32(0); i < count; i++ {
+		f := healthSectionAt(buf, i)
+		switch i {
+		case HEALTH_SECTION_MESH:
+			b.Mesh = MeshHealth{
+				HealthSection:    healthSection(f),
+				TotalPeers:       f.Uint32(HEALTH_MESH_TOTAL_PEERS),
+				ConnectedPeers:   f.Uint32(HEALTH_MESH_CONNECTED_PEERS),
+				LocalChunks:      f.Uint32(HEALTH_MESH_LOCAL_CHUNKS),
+				ChunksAvailable:  f.Uint32(HEALTH_MESH_CHUNKS_AVAILABLE),
+				AvgReputation:    f.Float32(HEALTH_MESH_AVG_REPUTATION),
+				SectorID:         f.Uint32(HEALTH_MESH_SECTOR_ID),
+				SectorMembers:    f.Uint32(HEALTH_MESH_SECTOR_MEMBERS),
+				RegionID:         f.Uint32(HEALTH_MESH_REGION_ID),
+				FetchSuccessRate: f.Float32(HEALTH_MESH_FETCH_SUCCESS),
+				Busy:             f.Uint32(HEALTH_MESH_BUSY) != 0,
+				InboundHeldBytes: f.Uint64(HEALTH_MESH_INBOUND_HELD_BYTES),
+			}
+		case HEALTH_SECTION_GOSSIP:
+			b.Gossip = GossipHealth{
+				HealthSection:       healthSection(f),
+				MessagesSent:        f.Uint64(HEALTH_GOSSIP_SENT),
+				MessagesReceived:    f.Uint64(HEALTH_GOSSIP_RECEIVED),
+				MessagesDropped:     f.Uint64(HEALTH_GOSSIP_DROPPED),
+				RateLimited:         f.Uint64(HEALTH_GOSSIP_RATE_LIMITED),
+				FailedSignatures:    f.Uint64(HEALTH_GOSSIP_FAILED_SIGNATURES),
+				QueueLength:         f.Uint32(HEALTH_GOSSIP_QUEUE_LENGTH),
+				Fanout:              f.Uint32(HEALTH_GOSSIP_FANOUT),
+				MessageRate:         f.Float32(HEALTH_GOSSIP_MESSAGE_RATE),
+				DeliveryRatio:       f.Float32(HEALTH_GOSSIP_DELIVERY_RATIO),
+				DuplicateRatio:      f.Float32(HEALTH_GOSSIP_DUPLICATE_RATIO),
+				PropagationP95Ms:    f.Float32(HEALTH_GOSSIP_PROPAGATION_P95),
+				NetworkSizeEstimate: f.Uint32(HEALTH_GOSSIP_NETWORK_SIZE),
+			}
+		case HEALTH_SECTION_DHT:
+			b.DHT = DHTHealth{
+				HealthSection:       healthSection(f),
+				Entries:             f.Uint32(HEALTH_DHT_ENTRIES),
+				Peers:               f.Uint32(HEALTH_DHT_PEERS),
+				NetworkSizeEstimate: f.Uint32(HEALTH_DHT_NETWORK_SIZE),
+				LookupSuccessRate:   f.Float32(HEALTH_DHT_LOOKUP_SUCCESS),
+				LookupP95Ms:         f.Uint32(HEALTH_DHT_LOOKUP_P95),
+				Queries:             f.Uint64(HEALTH_DHT_QUERIES),
+				FailedQueries:       f.Uint64(HEALTH_DHT_FAILED_QUERIES),
+				RejectedNodeIDs:     f.Uint64(HEALTH_DHT_REJECTED_NODE_IDS),
+				InvalidNodeReplies:  f.Uint64(HEALTH_DHT_INVALID_NODE_REPLIES),
+			}
+		case HEALTH_SECTION_TRANSPORT:
+			b.Transport = TransportHealth{
+				HealthSection:      healthSection(f),
+				ActiveConnections:  f.Uint32(HEALTH_TRANSPORT_ACTIVE_CONNECTIONS),
+				WebSocketFallbacks: f.Uint32(HEALTH_TRANSPORT_WEBSOCKET_FALLBACKS),
+				BytesSent:          f.Uint64(HEALTH_TRANSPORT_BYTES_SENT),
+				BytesReceived:      f.Uint64(HEALTH_TRANSPORT_BYTES_RECEIVED),
+				MessagesSent:       f.Uint64(HEALTH_TRANSPORT_MESSAGES_SENT),
+				MessagesReceived:   f.Uint64(HEALTH_TRANSPORT_MESSAGES_RECEIVED),
+				FailedMessages:     f.Uint64(HEALTH_TRANSPORT_FAILED_MESSAGES),
+				LatencyP50Ms:       f.Float32(HEALTH_TRANSPORT_LATENCY_P50),
+				LatencyP95Ms:       f.Float32(HEALTH_TRANSPORT_LATENCY_P95),
+				LatencyP99Ms:       f.Float32(HEALTH_TRANSPORT_LATENCY_P99),
+				ErrorRate:          f.Float32(HEALTH_TRANSPORT_ERROR_RATE),
+				TotalConnections:   f.Uint64(HEALTH_TRANSPORT_TOTAL_CONNECTIONS),
+			}
+		case HEALTH_SECTION_ECONOMY:
+			b.Economy = EconomyHealth{
+				HealthSection: healthSection(f),
+				Balance:       f.Int64(HEALTH_ECONOMY_BALANCE),
+				Escrowed:      f.Uint64(HEALTH_ECONOMY_ESCROWED),
+				Settled:       f.Uint64(HEALTH_ECONOMY_SETTLED),
+				Refunded:      f.Uint64(HEALTH_ECONOMY_REFUNDED),
+				Slashed:       f.Uint64(HEALTH_ECONOMY_SLASHED),
+				ActiveEscrows: f.Uint32(HEALTH_ECONOMY_ACTIVE_ESCROWS),
+				Accounts:      f.Uint32(HEALTH_ECONOMY_ACCOUNTS),
+				Settlements:   f.Uint64(HEALTH_ECONOMY_SETTLEMENTS),
+				JobsExecuted:  f.Uint64(HEALTH_ECONOMY_JOBS_EXECUTED),
+				JobsFailed:    f.Uint64(HEALTH_ECONOMY_JOBS_FAILED),
+			}
+		case HEALTH_SECTION_SUPERVISOR:
+			b.Supervisor = SupervisorHealth{
+				HealthSection:    healthSection(f),
+				ActiveThreads:    f.Uint32(HEALTH_SUPERVISOR_ACTIVE_THREADS),
+				FailedThreads:    f.Uint32(HEALTH_SUPERVISOR_FAILED_THREADS),
+				RestartedThreads: f.Uint32(HEALTH_SUPERVISOR_RESTARTED_THREADS),
+				Messages:         f.Uint64(HEALTH_SUPERVISOR_MESSAGES),
+			}
+		}
+	}
+	return b, nil
+}
+
+// WriteHealthBlock writes b at OFFSET_HEALTH_BLOCK under sequence, which
+// must be even and above the last one written. Writers must not run
+// concurrently.
+func WriteHealthBlock(w RawWriter, sequence uint32, b *HealthBlock) error {
+	if sequence%2 == 1 {
+		return &LayoutError{Code: "HEALTH_BLOCK_BAD_SEQUENCE", Message: fmt.Sprintf("sequence %d is odd", sequence)}
+	}
+	buf := EncodeHealthBlock(sequence, b)
+
+	// Readers see an odd sequence until the body, everything after the
+	// sequence, is complete
+	busy := make(Codec, 4)
+	busy.PutUint32(0, sequence-1)
+	if err := w.WriteRaw(OFFSET_HEALTH_BLOCK+HEALTH_HEADER_SEQUENCE, busy); err != nil {
+		return err
+	}
+	if err := w.WriteRaw(OFFSET_HEALTH_BLOCK+HEALTH_HEADER_VERSION, buf[HEALTH_HEADER_VERSION:]); err != nil {
+		return err
+	}
+	return w.WriteRaw(OFFSET_HEALTH_BLOCK+HEALTH_HEADER_SEQUENCE, buf[HEALTH_HEADER_SEQUENCE:HEALTH_HEADER_VERSION])
+}
+
+// ReadHealthBlock reads a consistent health block from mem, retrying while
+// the kernel is writing it.
+func ReadHealthBlock(mem RegionMemory) (*HealthBlock, error) {
+	buf := make([]byte, healthBlockSize)
+	after := make(Codec, 4)
+	var err error
+	for attempt := 0; attempt < healthBlockReadAttempts; attempt++ {
+		if err = mem.ReadAt(OFFSET_HEALTH_BLOCK, buf); err != nil {
+			return nil, err
+		}
+		var b *HealthBlock
+		if b, err = DecodeHealthBlock(buf); err != nil {
+			if IsHealthBlockBusy(err) {
+				continue
+			}
+			return nil, err
+		}
+		if err = mem.ReadAt(OFFSET_HEALTH_BLOCK+HEALTH_HEADER_SEQUENCE, after); err != nil {
+			return nil, err
+		}
+		if after.Uint32(0) == b.Sequence {
+			return b, nil
+		}
+	}
+	return nil, &LayoutError{Code: "HEALTH_BLOCK_BUSY", Message: "health block kept changing while read"}
+}
+
+// IsHealthBlockBusy reports whether err means the block was caught mid-write.
+func IsHealthBlockBusy(err error) bool {
+	le, ok := err.(*LayoutError)
+	return ok && le.Code == "HEALTH_BLOCK_BUSY"
+}
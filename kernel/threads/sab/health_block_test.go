@@ -0,0 +1,189 @@
+package sab
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// goldenHealthBlock matches testdata/health_block.hex, which the frontend and
+// Rust SDK tests decode with their own readers.
+var goldenHealthBlock = HealthBlock{
+	Sequence:  42,
+	Version:   HEALTH_BLOCK_VERSION,
+	WrittenAt: time.UnixMilli(1_700_000_000_123),
+	Mesh: MeshHealth{
+		HealthSection:    HealthSection{Status: HealthStatusOK, Score: 0.75},
+		TotalPeers:       40,
+		ConnectedPeers:   12,
+		LocalChunks:      300,
+		ChunksAvailable:  4500,
+		AvgReputation:    0.625,
+		SectorID:         7,
+		SectorMembers:    5,
+		RegionID:         0xDEADBEEF,
+		FetchSuccessRate: 0.875,
+		Busy:             true,
+		InboundHeldBytes: 1 << 33,
+	},
+	Gossip: GossipHealth{
+		HealthSection:       HealthSection{Status: HealthStatusDegraded, Score: 0.5},
+		MessagesSent:        1001,
+		MessagesReceived:    2002,
+		MessagesDropped:     3,
+		RateLimited:         4,
+		FailedSignatures:    5,
+		QueueLength:         6,
+		Fanout:              8,
+		MessageRate:         12.5,
+		DeliveryRatio:       0.96875,
+		DuplicateRatio:      0.125,
+		PropagationP95Ms:    48.5,
+		NetworkSizeEstimate: 1024,
+	},
+	DHT: DHTHealth{
+		HealthSection:       HealthSection{Status: HealthStatusOK, Score: 1},
+		Entries:             256,
+		Peers:               20,
+		NetworkSizeEstimate: 1000,
+		LookupSuccessRate:   0.9375,
+		LookupP95Ms:         180,
+		Queries:             5000,
+		FailedQueries:       17,
+		RejectedNodeIDs:     2,
+		InvalidNodeReplies:  9,
+	},
+	Transport: TransportHealth{
+		HealthSection:      HealthSection{Status: HealthStatusDown, Score: 0.25},
+		ActiveConnections:  11,
+		WebSocketFallbacks: 2,
+		BytesSent:          0x0102030405060708,
+		BytesReceived:      1 << 40,
+		MessagesSent:       7000,
+		MessagesReceived:   6999,
+		FailedMessages:     13,
+		LatencyP50Ms:       3.25,
+		LatencyP95Ms:       20.5,
+		LatencyP99Ms:       120.5,
+		ErrorRate:          0.0625,
+		TotalConnections:   77,
+	},
+	Economy: EconomyHealth{
+		HealthSection: HealthSection{Status: HealthStatusOK, Score: 1},
+		Balance:       -1500,
+		Escrowed:      200,
+		Settled:       3000,
+		Refunded:      40,
+		Slashed:       5,
+		ActiveEscrows: 3,
+		Accounts:      9,
+		Settlements:   60,
+		JobsExecuted:  70,
+		JobsFailed:    8,
+	},
+	Supervisor: SupervisorHealth{
+		HealthSection:    HealthSection{Status: HealthStatusDegraded, Score: 0.5},
+		ActiveThreads:    6,
+		FailedThreads:    1,
+		RestartedThreads: 2,
+		Messages:         123456,
+	},
+}
+
+func TestHealthBlock_MatchesGolden(t *testing.T) {
+	raw, err := os.ReadFile("testdata/health_block.hex")
+	if err != nil {
+		t.Fatalf("failed to read golden block: %v", err)
+	}
+	golden, err := hex.DecodeString(strings.TrimSpace(string(raw)))
+	if err != nil {
+		t.Fatalf("golden block is not hex: %v", err)
+	}
+
+	if got := EncodeHealthBlock(goldenHealthBlock.Sequence, &goldenHealthBlock); !bytes.Equal(got, golden) {
+		t.Fatalf("encoding drifted from the golden block:\n got %x\nwant %x", got, golden)
+	}
+	decoded, err := DecodeHealthBlock(golden)
+	if err != nil {
+		t.Fatalf("DecodeHealthBlock failed: %v", err)
+	}
+	if !decoded.WrittenAt.Equal(goldenHealthBlock.WrittenAt) {
+		t.Fatalf("expected written at %v, got %v", goldenHealthBlock.WrittenAt, decoded.WrittenAt)
+	}
+	decoded.WrittenAt = goldenHealthBlock.WrittenAt
+	if *decoded != goldenHealthBlock {
+		t.Fatalf("decoded %+v, want %+v", decoded, goldenHealthBlock)
+	}
+}
+
+func TestHealthBlock_FitsRegion(t *testing.T) {
+	if healthBlockSize > SIZE_HEALTH_BLOCK {
+		t.Fatalf("block of %d bytes exceeds the %d byte region", healthBlockSize, SIZE_HEALTH_BLOCK)
+	}
+	// The last field of the widest sections must end inside the section
+	for _, end := range []uint32{HEALTH_GOSSIP_NETWORK_SIZE + 4, HEALTH_TRANSPORT_TOTAL_CONNECTIONS + 8, HEALTH_ECONOMY_JOBS_FAILED + 8} {
+		if end > HEALTH_SECTION_SIZE {
+			t.Fatalf("field ending at %d overruns the %d byte section", end, HEALTH_SECTION_SIZE)
+		}
+	}
+}
+
+func TestHealthBlock_RoundTrip(t *testing.T) {
+	want := &HealthBlock{
+		WrittenAt:  time.UnixMilli(1_700_000_000_000),
+		Mesh:       MeshHealth{HealthSection: HealthSection{Status: HealthStatusOK, Score: 1}, ConnectedPeers: 7, Busy: true, InboundHeldBytes: 1 << 40},
+		Gossip:     GossipHealth{HealthSection: HealthSection{Status: HealthStatusDegraded, Score: 0.25}, MessagesDropped: 3, DeliveryRatio: 0.9},
+		DHT:        DHTHealth{Entries: 12, Queries: 99, InvalidNodeReplies: 5},
+		Transport:  TransportHealth{LatencyP99Ms: 120.5, TotalConnections: 44},
+		Economy:    EconomyHealth{Balance: -15, Settled: 300, JobsFailed: 2},
+		Supervisor: SupervisorHealth{HealthSection: HealthSection{Status: HealthStatusDown}, FailedThreads: 1, Messages: 8},
+	}
+
+	buf := EncodeHealthBlock(6, want)
+	if len(buf) > int(SIZE_HEALTH_BLOCK) {
+		t.Fatalf("encoding of %d bytes exceeds the region", len(buf))
+	}
+	got, err := DecodeHealthBlock(buf)
+	if err != nil {
+		t.Fatalf("DecodeHealthBlock failed: %v", err)
+	}
+	want.Sequence, want.Version = 6, HEALTH_BLOCK_VERSION
+	if !got.WrittenAt.Equal(want.WrittenAt) {
+		t.Fatalf("expected written at %v, got %v", want.WrittenAt, got.WrittenAt)
+	}
+	got.WrittenAt = want.WrittenAt
+	if *got != *want {
+		t.Fatalf("round trip mismatch:\n got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestHealthBlock_Seqlock(t *testing.T) {
+	mem := make(ByteRegionMemory, OFFSET_HEALTH_BLOCK+SIZE_HEALTH_BLOCK)
+	if b, err := ReadHealthBlock(mem); err != nil || b.Sequence != 0 {
+		t.Fatalf("expected an unwritten block, got %+v, %v", b, err)
+	}
+
+	block := &HealthBlock{DHT: DHTHealth{Peers: 3}}
+	if err := WriteHealthBlock(mem, 3, block); err == nil {
+		t.Fatal("expected an odd sequence to be refused")
+	}
+	if err := WriteHealthBlock(mem, 2, block); err != nil {
+		t.Fatalf("WriteHealthBlock failed: %v", err)
+	}
+	if b, err := ReadHealthBlock(mem); err != nil || b.Sequence != 2 || b.DHT.Peers != 3 {
+		t.Fatalf("expected the written block, got %+v, %v", b, err)
+	}
+
+	// A writer that stopped midway leaves the sequence odd
+	binary.LittleEndian.PutUint32(mem[OFFSET_HEALTH_BLOCK:], 3)
+	if _, err := ReadHealthBlock(mem); !IsHealthBlockBusy(err) {
+		t.Fatalf("expected HEALTH_BLOCK_BUSY mid-write, got %v", err)
+	}
+	if _, err := DecodeHealthBlock(make([]byte, 8)); err == nil {
+		t.Fatal("expected a truncated block to fail")
+	}
+}
@@ -47,47 +47,52 @@ func (si *SABInitializer) Initialize() error {
 		return fmt.Errorf("failed to initialize metadata: %w", err)
 	}
 
-	// 3. Initialize module registry
+	// 3. Write layout header
+	if err := si.initLayoutHeader(); err != nil {
+		return fmt.Errorf("failed to write layout header: %w", err)
+	}
+
+	// 4. Initialize module registry
 	if err := si.initModuleRegistry(); err != nil {
 		return fmt.Errorf("failed to initialize module registry: %w", err)
 	}
 
-	// 4. Initialize supervisor headers
+	// 5. Initialize supervisor headers
 	if err := si.initSupervisorHeaders(); err != nil {
 		return fmt.Errorf("failed to initialize supervisor headers: %w", err)
 	}
 
-	// 5. Initialize pattern exchange
+	// 6. Initialize pattern exchange
 	if err := si.initPatternExchange(); err != nil {
 		return fmt.Errorf("failed to initialize pattern exchange: %w", err)
 	}
 
-	// 6. Initialize job history
+	// 7. Initialize job history
 	if err := si.initJobHistory(); err != nil {
 		return fmt.Errorf("failed to initialize job history: %w", err)
 	}
 
-	// 7. Initialize coordination state
+	// 8. Initialize coordination state
 	if err := si.initCoordination(); err != nil {
 		return fmt.Errorf("failed to initialize coordination: %w", err)
 	}
 
-	// 8. Initialize inbox/outbox
+	// 9. Initialize inbox/outbox
 	if err := si.initInboxOutbox(); err != nil {
 		return fmt.Errorf("failed to initialize inbox/outbox: %w", err)
 	}
 
-	// 9. Initialize arena
+	// 10. Initialize arena
 	if err := si.initArena(); err != nil {
 		return fmt.Errorf("failed to initialize arena: %w", err)
 	}
 
-	// 10. Validate layout
+	// 11. Validate layout
 	if err := si.validator.ValidateLayout(); err != nil {
 		return fmt.Errorf("layout validation failed: %w", err)
 	}
 
-	// 11. Set kernel ready flag
+	// 12. Set kernel ready flag
 	si.setKernelReady()
 
 	return nil
@@ -154,6 +159,17 @@ func (si *SABInitializer) initMetadata() error {
 	return nil
 }
 
+// initLayoutHeader writes the layout header so hosts and modules can check
+// their offsets against this build.
+func (si *SABInitializer) initLayoutHeader() error {
+	header, err := EncodeLayoutHeader(uint32(si.size))
+	if err != nil {
+		return err
+	}
+	copy(si.buffer[OFFSET_LAYOUT_HEADER:OFFSET_LAYOUT_HEADER+SIZE_LAYOUT_HEADER], header)
+	return nil
+}
+
 // initModuleRegistry initializes the module registry region
 func (si *SABInitializer) initModuleRegistry() error {
 	// Zero out entire registry region
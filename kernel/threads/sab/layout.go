@@ -51,6 +51,13 @@ const (
 	OFFSET_BLOOM_FILTER = system.OffsetBloomFilter
 	SIZE_BLOOM_FILTER   = system.SizeBloomFilter
 
+	// Layout header (1KB before supervisor headers)
+	OFFSET_LAYOUT_HEADER     = system.OffsetLayoutHeader
+	SIZE_LAYOUT_HEADER       = system.SizeLayoutHeader
+	LAYOUT_MAGIC             = system.LayoutMagic
+	LAYOUT_VERSION           = system.LayoutVersion
+	LAYOUT_REGION_ENTRY_SIZE = system.LayoutRegionEntrySize
+
 	// ========== SUPERVISOR HEADERS (0x002000 - 0x003000) ==========
 	OFFSET_SUPERVISOR_HEADERS = system.OffsetSupervisorHeaders
 	SIZE_SUPERVISOR_HEADERS   = system.SizeSupervisorHeaders
@@ -66,6 +73,25 @@ const (
 	OFFSET_MESH_METRICS = system.OffsetMeshMetrics
 	SIZE_MESH_METRICS   = system.SizeMeshMetrics
 
+	// Mesh metrics record fields
+	MESH_METRICS_TOTAL_PEERS        = system.MeshMetricsTotalPeers
+	MESH_METRICS_CONNECTED_PEERS    = system.MeshMetricsConnectedPeers
+	MESH_METRICS_DHT_ENTRIES        = system.MeshMetricsDhtEntries
+	MESH_METRICS_GOSSIP_RATE        = system.MeshMetricsGossipRate
+	MESH_METRICS_AVG_REPUTATION     = system.MeshMetricsAvgReputation
+	MESH_METRICS_REGION_ID          = system.MeshMetricsRegionId
+	MESH_METRICS_BYTES_SENT         = system.MeshMetricsBytesSent
+	MESH_METRICS_BYTES_RECEIVED     = system.MeshMetricsBytesReceived
+	MESH_METRICS_P50_LATENCY        = system.MeshMetricsP50Latency
+	MESH_METRICS_P95_LATENCY        = system.MeshMetricsP95Latency
+	MESH_METRICS_CONNECTION_SUCCESS = system.MeshMetricsConnectionSuccess
+	MESH_METRICS_FETCH_SUCCESS      = system.MeshMetricsFetchSuccess
+	MESH_METRICS_LOCAL_CHUNKS       = system.MeshMetricsLocalChunks
+	MESH_METRICS_TOTAL_CHUNKS       = system.MeshMetricsTotalChunks
+	MESH_METRICS_SECTOR_ID          = system.MeshMetricsSectorId
+	MESH_METRICS_SECTOR_MEMBERS     = system.MeshMetricsSectorMembers
+	MESH_METRICS_RECORD_SIZE        = system.MeshMetricsRecordSize
+
 	// ========== ECONOMICS (0x004100 - 0x008000) ==========
 	OFFSET_ECONOMICS = system.OffsetEconomics
 	SIZE_ECONOMICS   = system.SizeEconomics
@@ -109,6 +135,17 @@ const (
 	OFFSET_OUTBOX_KERNEL_BASE = system.OffsetOutboxKernelBase
 	SIZE_OUTBOX_KERNEL_TOTAL  = system.SizeOutboxKernelTotal
 
+	// Inbox priority lanes, each its own ring inside the inbox
+	OFFSET_INBOX_CONTROL = system.OffsetInboxControl
+	SIZE_INBOX_CONTROL   = system.SizeInboxControl
+	OFFSET_INBOX_COMPUTE = system.OffsetInboxCompute
+	SIZE_INBOX_COMPUTE   = system.SizeInboxCompute
+	OFFSET_INBOX_BULK    = system.OffsetInboxBulk
+	SIZE_INBOX_BULK      = system.SizeInboxBulk
+	INBOX_WEIGHT_CONTROL = system.InboxWeightControl
+	INBOX_WEIGHT_COMPUTE = system.InboxWeightCompute
+	INBOX_WEIGHT_BULK    = system.InboxWeightBulk
+
 	// ========== ARENA (0x150000 - end) ==========
 	OFFSET_ARENA          = system.OffsetArena
 	OFFSET_ARENA_METADATA = system.OffsetArenaMetadata
@@ -121,6 +158,105 @@ const (
 	OFFSET_BRIDGE_METRICS = system.OffsetDiagnostics + 0x800
 	SIZE_BRIDGE_METRICS   = 0x100
 
+	// Host frame schedule: when the current frame's idle window opens and closes
+	OFFSET_FRAME_SCHEDULE = system.OffsetFrameSchedule
+	SIZE_FRAME_SCHEDULE   = system.SizeFrameSchedule
+
+	// Per-subsystem health, written as a seqlock; see health_block.go
+	OFFSET_HEALTH_BLOCK = system.OffsetHealthBlock
+	SIZE_HEALTH_BLOCK   = system.SizeHealthBlock
+
+	// Health block header
+	HEALTH_BLOCK_VERSION        = system.HealthBlockVersion
+	HEALTH_HEADER_SEQUENCE      = system.HealthHeaderSequence
+	HEALTH_HEADER_VERSION       = system.HealthHeaderVersion
+	HEALTH_HEADER_SECTION_COUNT = system.HealthHeaderSectionCount
+	HEALTH_HEADER_SECTION_SIZE  = system.HealthHeaderSectionSize
+	HEALTH_HEADER_WRITTEN_AT    = system.HealthHeaderWrittenAt
+	HEALTH_HEADER_SIZE          = system.HealthHeaderSize
+
+	// Health sections, in this order after the header
+	HEALTH_SECTION_SIZE       = system.HealthSectionSize
+	HEALTH_SECTION_COUNT      = system.HealthSectionCount
+	HEALTH_SECTION_MESH       = system.HealthSectionMesh
+	HEALTH_SECTION_GOSSIP     = system.HealthSectionGossip
+	HEALTH_SECTION_DHT        = system.HealthSectionDht
+	HEALTH_SECTION_TRANSPORT  = system.HealthSectionTransport
+	HEALTH_SECTION_ECONOMY    = system.HealthSectionEconomy
+	HEALTH_SECTION_SUPERVISOR = system.HealthSectionSupervisor
+	HEALTH_SECTION_STATUS     = system.HealthSectionStatus
+	HEALTH_SECTION_SCORE      = system.HealthSectionScore
+
+	// Mesh section fields
+	HEALTH_MESH_TOTAL_PEERS        = system.HealthMeshTotalPeers
+	HEALTH_MESH_CONNECTED_PEERS    = system.HealthMeshConnectedPeers
+	HEALTH_MESH_LOCAL_CHUNKS       = system.HealthMeshLocalChunks
+	HEALTH_MESH_CHUNKS_AVAILABLE   = system.HealthMeshChunksAvailable
+	HEALTH_MESH_AVG_REPUTATION     = system.HealthMeshAvgReputation
+	HEALTH_MESH_SECTOR_ID          = system.HealthMeshSectorId
+	HEALTH_MESH_SECTOR_MEMBERS     = system.HealthMeshSectorMembers
+	HEALTH_MESH_REGION_ID          = system.HealthMeshRegionId
+	HEALTH_MESH_FETCH_SUCCESS      = system.HealthMeshFetchSuccess
+	HEALTH_MESH_BUSY               = system.HealthMeshBusy
+	HEALTH_MESH_INBOUND_HELD_BYTES = system.HealthMeshInboundHeldBytes
+
+	// Gossip section fields
+	HEALTH_GOSSIP_SENT              = system.HealthGossipSent
+	HEALTH_GOSSIP_RECEIVED          = system.HealthGossipReceived
+	HEALTH_GOSSIP_DROPPED           = system.HealthGossipDropped
+	HEALTH_GOSSIP_RATE_LIMITED      = system.HealthGossipRateLimited
+	HEALTH_GOSSIP_FAILED_SIGNATURES = system.HealthGossipFailedSignatures
+	HEALTH_GOSSIP_QUEUE_LENGTH      = system.HealthGossipQueueLength
+	HEALTH_GOSSIP_FANOUT            = system.HealthGossipFanout
+	HEALTH_GOSSIP_MESSAGE_RATE      = system.HealthGossipMessageRate
+	HEALTH_GOSSIP_DELIVERY_RATIO    = system.HealthGossipDeliveryRatio
+	HEALTH_GOSSIP_DUPLICATE_RATIO   = system.HealthGossipDuplicateRatio
+	HEALTH_GOSSIP_PROPAGATION_P95   = system.HealthGossipPropagationP95
+	HEALTH_GOSSIP_NETWORK_SIZE      = system.HealthGossipNetworkSize
+
+	// DHT section fields
+	HEALTH_DHT_ENTRIES              = system.HealthDhtEntries
+	HEALTH_DHT_PEERS                = system.HealthDhtPeers
+	HEALTH_DHT_NETWORK_SIZE         = system.HealthDhtNetworkSize
+	HEALTH_DHT_LOOKUP_SUCCESS       = system.HealthDhtLookupSuccess
+	HEALTH_DHT_LOOKUP_P95           = system.HealthDhtLookupP95
+	HEALTH_DHT_QUERIES              = system.HealthDhtQueries
+	HEALTH_DHT_FAILED_QUERIES       = system.HealthDhtFailedQueries
+	HEALTH_DHT_REJECTED_NODE_IDS    = system.HealthDhtRejectedNodeIds
+	HEALTH_DHT_INVALID_NODE_REPLIES = system.HealthDhtInvalidNodeReplies
+
+	// Transport section fields
+	HEALTH_TRANSPORT_ACTIVE_CONNECTIONS  = system.HealthTransportActiveConnections
+	HEALTH_TRANSPORT_WEBSOCKET_FALLBACKS = system.HealthTransportWebsocketFallbacks
+	HEALTH_TRANSPORT_BYTES_SENT          = system.HealthTransportBytesSent
+	HEALTH_TRANSPORT_BYTES_RECEIVED      = system.HealthTransportBytesReceived
+	HEALTH_TRANSPORT_MESSAGES_SENT       = system.HealthTransportMessagesSent
+	HEALTH_TRANSPORT_MESSAGES_RECEIVED   = system.HealthTransportMessagesReceived
+	HEALTH_TRANSPORT_FAILED_MESSAGES     = system.HealthTransportFailedMessages
+	HEALTH_TRANSPORT_LATENCY_P50         = system.HealthTransportLatencyP50
+	HEALTH_TRANSPORT_LATENCY_P95         = system.HealthTransportLatencyP95
+	HEALTH_TRANSPORT_LATENCY_P99         = system.HealthTransportLatencyP99
+	HEALTH_TRANSPORT_ERROR_RATE          = system.HealthTransportErrorRate
+	HEALTH_TRANSPORT_TOTAL_CONNECTIONS   = system.HealthTransportTotalConnections
+
+	// Economy section fields
+	HEALTH_ECONOMY_BALANCE        = system.HealthEconomyBalance
+	HEALTH_ECONOMY_ESCROWED       = system.HealthEconomyEscrowed
+	HEALTH_ECONOMY_SETTLED        = system.HealthEconomySettled
+	HEALTH_ECONOMY_REFUNDED       = system.HealthEconomyRefunded
+	HEALTH_ECONOMY_SLASHED        = system.HealthEconomySlashed
+	HEALTH_ECONOMY_ACTIVE_ESCROWS = system.HealthEconomyActiveEscrows
+	HEALTH_ECONOMY_ACCOUNTS       = system.HealthEconomyAccounts
+	HEALTH_ECONOMY_SETTLEMENTS    = system.HealthEconomySettlements
+	HEALTH_ECONOMY_JOBS_EXECUTED  = system.HealthEconomyJobsExecuted
+	HEALTH_ECONOMY_JOBS_FAILED    = system.HealthEconomyJobsFailed
+
+	// Supervisor section fields
+	HEALTH_SUPERVISOR_ACTIVE_THREADS    = system.HealthSupervisorActiveThreads
+	HEALTH_SUPERVISOR_FAILED_THREADS    = system.HealthSupervisorFailedThreads
+	HEALTH_SUPERVISOR_RESTARTED_THREADS = system.HealthSupervisorRestartedThreads
+	HEALTH_SUPERVISOR_MESSAGES          = system.HealthSupervisorMessages
+
 	// Async Request/Response Queues
 	OFFSET_ARENA_REQUEST_QUEUE  = system.OffsetArenaRequestQueue
 	OFFSET_ARENA_RESPONSE_QUEUE = system.OffsetArenaResponseQueue
@@ -141,6 +277,11 @@ const (
 	OFFSET_PINGPONG_CONTROL = system.OffsetPingpongControl
 	SIZE_PINGPONG_CONTROL   = system.SizePingpongControl
 
+	// Crash Dump
+	OFFSET_CRASH_DUMP = system.OffsetCrashDump
+	SIZE_CRASH_DUMP   = system.SizeCrashDump
+	CRASH_DUMP_MAGIC  = system.CrashDumpMagic
+
 	// Bird Population Data (Dual Buffers)
 	OFFSET_BIRD_BUFFER_A = system.OffsetBirdBufferA
 	OFFSET_BIRD_BUFFER_B = system.OffsetBirdBufferB
@@ -153,6 +294,12 @@ const (
 	SIZE_MATRIX_BUFFER     = system.SizeMatrixBuffer
 	MATRIX_STRIDE          = system.MatrixStride
 
+	// ========== DYNAMIC REGIONS (after the static layout) ==========
+	OFFSET_DYNAMIC_REGISTRY   = system.OffsetDynamicRegistry
+	SIZE_DYNAMIC_REGISTRY     = system.SizeDynamicRegistry
+	DYNAMIC_REGION_ENTRY_SIZE = system.DynamicRegionEntrySize
+	OFFSET_DYNAMIC_HEAP       = OFFSET_DYNAMIC_REGISTRY + SIZE_DYNAMIC_REGISTRY
+
 	// ========== EPOCH INDEX ALLOCATION ==========
 	// Fixed system epochs (0-31 Reserved)
 	IDX_KERNEL_READY      = system.IdxKernelReady
@@ -265,6 +412,15 @@ func GetAllRegions(sabSize uint32) []MemoryRegion {
 			MaxInline: MAX_MODULES_INLINE,
 			MaxTotal:  MAX_MODULES_TOTAL,
 		},
+		{
+			Name:      "LayoutHeader",
+			Offset:    OFFSET_LAYOUT_HEADER,
+			Size:      SIZE_LAYOUT_HEADER,
+			Purpose:   "Layout magic, version and region table",
+			CanExpand: false,
+			MaxInline: 1,
+			MaxTotal:  1,
+		},
 		{
 			Name:      "SupervisorHeaders",
 			Offset:    OFFSET_SUPERVISOR_HEADERS,
@@ -296,7 +452,7 @@ func GetAllRegions(sabSize uint32) []MemoryRegion {
 			Name:      "JobHistory",
 			Offset:    OFFSET_JOB_HISTORY,
 			Size:      SIZE_JOB_HISTORY,
-			Purpose:   "Job execution history (circular buffer)",
+			Purpose:   "In-flight job journal (supervisor failover)",
 			CanExpand: true,
 			MaxInline: 0, // Calculated based on entry size
 			MaxTotal:  0, // Unlimited with arena overflow
@@ -0,0 +1,190 @@
+package sab
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// Layout header encoding (little-endian, at OFFSET_LAYOUT_HEADER):
+//
+//	0x00 magic        u32  LAYOUT_MAGIC
+//	0x04 version      u32  major<<16 | minor
+//	0x08 sab size     u32
+//	0x0C region count u32
+//	0x10 regions      LAYOUT_REGION_ENTRY_SIZE each: name[20] offset u32 size u32
+const (
+	layoutHeaderFixedSize = 16
+	layoutRegionNameSize  = 20
+
+	// MAX_LAYOUT_REGIONS is how many region entries fit in the header.
+	MAX_LAYOUT_REGIONS = (SIZE_LAYOUT_HEADER - layoutHeaderFixedSize) / LAYOUT_REGION_ENTRY_SIZE
+)
+
+// LayoutRegion is one entry of the layout header's region table.
+type LayoutRegion struct {
+	Name   string `json:"name"`
+	Offset uint32 `json:"offset"`
+	Size   uint32 `json:"size"`
+}
+
+// LayoutHeader is the decoded layout header.
+type LayoutHeader struct {
+	Magic   uint32
+	Version uint32
+	SABSize uint32
+	Regions []LayoutRegion
+}
+
+// LayoutVersionMajor returns the major part of a layout version. Layouts with
+// different majors are incompatible; minors only add regions.
+func LayoutVersionMajor(version uint32) uint32 {
+	return version >> 16
+}
+
+// layoutSubRegions are regions that live inside the arena or are not tracked
+// by GetAllRegions but that hosts and modules address directly.
+var layoutSubRegions = []LayoutRegion{
+	{Name: "BloomFilter", Offset: OFFSET_BLOOM_FILTER, Size: SIZE_BLOOM_FILTER},
+	{Name: "GlobalAnalytics", Offset: OFFSET_GLOBAL_ANALYTICS, Size: SIZE_GLOBAL_ANALYTICS},
+	{Name: "IdentityRegistry", Offset: OFFSET_IDENTITY_REGISTRY, Size: SIZE_IDENTITY_REGISTRY},
+	{Name: "SocialGraph", Offset: OFFSET_SOCIAL_GRAPH, Size: SIZE_SOCIAL_GRAPH},
+	{Name: "InboxControl", Offset: OFFSET_INBOX_CONTROL, Size: SIZE_INBOX_CONTROL},
+	{Name: "InboxCompute", Offset: OFFSET_INBOX_COMPUTE, Size: SIZE_INBOX_COMPUTE},
+	{Name: "InboxBulk", Offset: OFFSET_INBOX_BULK, Size: SIZE_INBOX_BULK},
+	{Name: "OutboxHost", Offset: OFFSET_OUTBOX_HOST_BASE, Size: SIZE_OUTBOX_HOST_TOTAL},
+	{Name: "OutboxKernel", Offset: OFFSET_OUTBOX_KERNEL_BASE, Size: SIZE_OUTBOX_KERNEL_TOTAL},
+	{Name: "Diagnostics", Offset: OFFSET_DIAGNOSTICS, Size: SIZE_DIAGNOSTICS},
+	{Name: "BridgeMetrics", Offset: OFFSET_BRIDGE_METRICS, Size: SIZE_BRIDGE_METRICS},
+	{Name: "ArenaRequestQueue", Offset: OFFSET_ARENA_REQUEST_QUEUE, Size: MAX_ARENA_REQUESTS * ARENA_QUEUE_ENTRY_SIZE},
+	{Name: "ArenaResponseQueue", Offset: OFFSET_ARENA_RESPONSE_QUEUE, Size: MAX_ARENA_REQUESTS * ARENA_QUEUE_ENTRY_SIZE},
+	{Name: "MeshEventQueue", Offset: OFFSET_MESH_EVENT_QUEUE, Size: SIZE_MESH_EVENT_QUEUE},
+	{Name: "BirdState", Offset: OFFSET_BIRD_STATE, Size: SIZE_BIRD_STATE},
+	{Name: "PingPongControl", Offset: OFFSET_PINGPONG_CONTROL, Size: SIZE_PINGPONG_CONTROL},
+	{Name: "CrashDump", Offset: OFFSET_CRASH_DUMP, Size: SIZE_CRASH_DUMP},
+	{Name: "BirdBufferA", Offset: OFFSET_BIRD_BUFFER_A, Size: SIZE_BIRD_BUFFER},
+	{Name: "BirdBufferB", Offset: OFFSET_BIRD_BUFFER_B, Size: SIZE_BIRD_BUFFER},
+	{Name: "MatrixBufferA", Offset: OFFSET_MATRIX_BUFFER_A, Size: SIZE_MATRIX_BUFFER},
+	{Name: "MatrixBufferB", Offset: OFFSET_MATRIX_BUFFER_B, Size: SIZE_MATRIX_BUFFER},
+	{Name: "DynamicRegistry", Offset: OFFSET_DYNAMIC_REGISTRY, Size: SIZE_DYNAMIC_REGISTRY},
+}
+
+// LayoutRegions returns the full region map this build writes into the
+// layout header.
+func LayoutRegions(sabSize uint32) []LayoutRegion {
+	regions := GetAllRegions(sabSize)
+	out := make([]LayoutRegion, 0, len(regions)+len(layoutSubRegions))
+	for _, r := range regions {
+		out = append(out, LayoutRegion{Name: r.Name, Offset: r.Offset, Size: r.Size})
+	}
+	return append(out, layoutSubRegions...)
+}
+
+// EncodeLayoutHeader builds the SIZE_LAYOUT_HEADER bytes describing this
+// build's layout for a SAB of sabSize bytes.
+func EncodeLayoutHeader(sabSize uint32) ([]byte, error) {
+	regions := LayoutRegions(sabSize)
+	if len(regions) > int(MAX_LAYOUT_REGIONS) {
+		return nil, &LayoutError{
+			Code:    "LAYOUT_TABLE_FULL",
+			Message: fmt.Sprintf("%d regions exceed the header capacity of %d", len(regions), MAX_LAYOUT_REGIONS),
+		}
+	}
+
+	buf := make([]byte, SIZE_LAYOUT_HEADER)
+	binary.LittleEndian.PutUint32(buf[0:], LAYOUT_MAGIC)
+	binary.LittleEndian.PutUint32(buf[4:], LAYOUT_VERSION)
+	binary.LittleEndian.PutUint32(buf[8:], sabSize)
+	binary.LittleEndian.PutUint32(buf[12:], uint32(len(regions)))
+	for i, r := range regions {
+		entry := buf[layoutHeaderFixedSize+i*int(LAYOUT_REGION_ENTRY_SIZE):]
+		copy(entry[:layoutRegionNameSize], r.Name)
+		binary.LittleEndian.PutUint32(entry[layoutRegionNameSize:], r.Offset)
+		binary.LittleEndian.PutUint32(entry[layoutRegionNameSize+4:], r.Size)
+	}
+	return buf, nil
+}
+
+// DecodeLayoutHeader parses a layout header read from OFFSET_LAYOUT_HEADER.
+func DecodeLayoutHeader(buf []byte) (*LayoutHeader, error) {
+	if len(buf) < layoutHeaderFixedSize {
+		return nil, &LayoutError{Code: "LAYOUT_TRUNCATED", Message: "layout header shorter than its fixed fields"}
+	}
+
+	h := &LayoutHeader{
+		Magic:   binary.LittleEndian.Uint32(buf[0:]),
+		Version: binary.LittleEndian.Uint32(buf[4:]),
+		SABSize: binary.LittleEndian.Uint32(buf[8:]),
+	}
+	if h.Magic == 0 {
+		return nil, &LayoutError{Code: "LAYOUT_MISSING", Message: "no layout header written"}
+	}
+	if h.Magic != LAYOUT_MAGIC {
+		return nil, &LayoutError{Code: "LAYOUT_BAD_MAGIC", Message: fmt.Sprintf("unexpected layout magic 0x%08x", h.Magic)}
+	}
+
+	count := binary.LittleEndian.Uint32(buf[12:])
+	if count > MAX_LAYOUT_REGIONS || layoutHeaderFixedSize+int(count*LAYOUT_REGION_ENTRY_SIZE) > len(buf) {
+		return nil, &LayoutError{Code: "LAYOUT_TRUNCATED", Message: fmt.Sprintf("region table of %d entries does not fit", count)}
+	}
+
+	h.Regions = make([]LayoutRegion, count)
+	for i := range h.Regions {
+		entry := buf[layoutHeaderFixedSize+i*int(LAYOUT_REGION_ENTRY_SIZE):]
+		h.Regions[i] = LayoutRegion{
+			Name:   string(bytes.TrimRight(entry[:layoutRegionNameSize], "\x00")),
+			Offset: binary.LittleEndian.Uint32(entry[layoutRegionNameSize:]),
+			Size:   binary.LittleEndian.Uint32(entry[layoutRegionNameSize+4:]),
+		}
+	}
+	return h, nil
+}
+
+// ValidateLayout checks a layout header against this build's constants. The
+// major version and SAB size must match and every region this build knows
+// must appear at the same offset and size; regions added by a newer minor
+// version are ignored. An unwritten header returns a LAYOUT_MISSING error,
+// see IsLayoutMissing.
+func ValidateLayout(buf []byte, sabSize uint32) error {
+	h, err := DecodeLayoutHeader(buf)
+	if err != nil {
+		return err
+	}
+	if LayoutVersionMajor(h.Version) != LayoutVersionMajor(LAYOUT_VERSION) {
+		return &LayoutError{
+			Code:    "LAYOUT_VERSION_MISMATCH",
+			Message: fmt.Sprintf("layout version 0x%08x is incompatible with 0x%08x", h.Version, LAYOUT_VERSION),
+		}
+	}
+	if h.SABSize != sabSize {
+		return &LayoutError{
+			Code:    "LAYOUT_SIZE_MISMATCH",
+			Message: fmt.Sprintf("layout written for %d bytes, SAB is %d", h.SABSize, sabSize),
+		}
+	}
+
+	written := make(map[string]LayoutRegion, len(h.Regions))
+	for _, r := range h.Regions {
+		written[r.Name] = r
+	}
+	for _, want := range LayoutRegions(sabSize) {
+		got, ok := written[want.Name]
+		if !ok {
+			return &LayoutError{Code: "LAYOUT_REGION_MISMATCH", Message: "region " + want.Name + " missing from layout header"}
+		}
+		if got.Offset != want.Offset || got.Size != want.Size {
+			return &LayoutError{
+				Code: "LAYOUT_REGION_MISMATCH",
+				Message: fmt.Sprintf("region %s at 0x%x+%d, expected 0x%x+%d",
+					want.Name, got.Offset, got.Size, want.Offset, want.Size),
+			}
+		}
+	}
+	return nil
+}
+
+// IsLayoutMissing reports whether err means no layout header was written yet.
+func IsLayoutMissing(err error) bool {
+	le, ok := err.(*LayoutError)
+	return ok && le.Code == "LAYOUT_MISSING"
+}
@@ -0,0 +1,64 @@
+package sab
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+func TestLayoutHeader_RoundTrip(t *testing.T) {
+	header, err := EncodeLayoutHeader(SAB_SIZE_DEFAULT)
+	if err != nil {
+		t.Fatalf("EncodeLayoutHeader failed: %v", err)
+	}
+	if err := ValidateLayout(header, SAB_SIZE_DEFAULT); err != nil {
+		t.Fatalf("expected own header to validate, got %v", err)
+	}
+
+	decoded, err := DecodeLayoutHeader(header)
+	if err != nil {
+		t.Fatalf("DecodeLayoutHeader failed: %v", err)
+	}
+	want := LayoutRegions(SAB_SIZE_DEFAULT)
+	if len(decoded.Regions) != len(want) {
+		t.Fatalf("expected %d regions, got %d", len(want), len(decoded.Regions))
+	}
+	for i := range want {
+		if decoded.Regions[i] != want[i] {
+			t.Fatalf("region %d: expected %+v, got %+v", i, want[i], decoded.Regions[i])
+		}
+	}
+}
+
+func TestValidateLayout_Rejections(t *testing.T) {
+	blank := make([]byte, SIZE_LAYOUT_HEADER)
+	if err := ValidateLayout(blank, SAB_SIZE_DEFAULT); !IsLayoutMissing(err) {
+		t.Fatalf("expected LAYOUT_MISSING for a blank header, got %v", err)
+	}
+
+	header, _ := EncodeLayoutHeader(SAB_SIZE_DEFAULT)
+	if err := ValidateLayout(header, SAB_SIZE_MODERATE); err == nil {
+		t.Fatal("expected a SAB size mismatch to be rejected")
+	}
+
+	// A newer minor version only adds regions and stays compatible.
+	minor := append([]byte(nil), header...)
+	binary.LittleEndian.PutUint32(minor[4:], LAYOUT_VERSION+1)
+	if err := ValidateLayout(minor, SAB_SIZE_DEFAULT); err != nil {
+		t.Fatalf("expected minor version bump to be accepted, got %v", err)
+	}
+
+	major := append([]byte(nil), header...)
+	binary.LittleEndian.PutUint32(major[4:], LAYOUT_VERSION+1<<16)
+	if err := ValidateLayout(major, SAB_SIZE_DEFAULT); err == nil {
+		t.Fatal("expected a major version change to be rejected")
+	}
+
+	// Shift the first region's offset as a drifted build would.
+	drifted := append([]byte(nil), header...)
+	entry := drifted[layoutHeaderFixedSize:]
+	binary.LittleEndian.PutUint32(entry[layoutRegionNameSize:], 0x40)
+	err := ValidateLayout(drifted, SAB_SIZE_DEFAULT)
+	if le, ok := err.(*LayoutError); !ok || le.Code != "LAYOUT_REGION_MISMATCH" {
+		t.Fatalf("expected LAYOUT_REGION_MISMATCH, got %v", err)
+	}
+}
@@ -0,0 +1,70 @@
+package sab
+
+// MeshMetricsRecord is the mesh telemetry record at OFFSET_MESH_METRICS.
+// Field offsets are the MESH_METRICS_* layout constants.
+type MeshMetricsRecord struct {
+	TotalPeers            uint32
+	ConnectedPeers        uint32
+	DHTEntries            uint32
+	GossipRatePerSec      float32
+	AvgReputation         float32
+	RegionID              uint32
+	BytesSent             uint64
+	BytesReceived         uint64
+	P50LatencyMs          float32
+	P95LatencyMs          float32
+	ConnectionSuccessRate float32
+	ChunkFetchSuccessRate float32
+	LocalChunks           uint32
+	TotalChunksAvailable  uint32
+	SectorID              uint32
+	SectorMembers         uint32
+}
+
+// EncodeMeshMetrics builds the mesh metrics record bytes.
+func EncodeMeshMetrics(r MeshMetricsRecord) []byte {
+	c := make(Codec, MESH_METRICS_RECORD_SIZE)
+	c.PutUint32(MESH_METRICS_TOTAL_PEERS, r.TotalPeers)
+	c.PutUint32(MESH_METRICS_CONNECTED_PEERS, r.ConnectedPeers)
+	c.PutUint32(MESH_METRICS_DHT_ENTRIES, r.DHTEntries)
+	c.PutFloat32(MESH_METRICS_GOSSIP_RATE, r.GossipRatePerSec)
+	c.PutFloat32(MESH_METRICS_AVG_REPUTATION, r.AvgReputation)
+	c.PutUint32(MESH_METRICS_REGION_ID, r.RegionID)
+	c.PutUint64(MESH_METRICS_BYTES_SENT, r.BytesSent)
+	c.PutUint64(MESH_METRICS_BYTES_RECEIVED, r.BytesReceived)
+	c.PutFloat32(MESH_METRICS_P50_LATENCY, r.P50LatencyMs)
+	c.PutFloat32(MESH_METRICS_P95_LATENCY, r.P95LatencyMs)
+	c.PutFloat32(MESH_METRICS_CONNECTION_SUCCESS, r.ConnectionSuccessRate)
+	c.PutFloat32(MESH_METRICS_FETCH_SUCCESS, r.ChunkFetchSuccessRate)
+	c.PutUint32(MESH_METRICS_LOCAL_CHUNKS, r.LocalChunks)
+	c.PutUint32(MESH_METRICS_TOTAL_CHUNKS, r.TotalChunksAvailable)
+	c.PutUint32(MESH_METRICS_SECTOR_ID, r.SectorID)
+	c.PutUint32(MESH_METRICS_SECTOR_MEMBERS, r.SectorMembers)
+	return c
+}
+
+// DecodeMeshMetrics parses a mesh metrics record.
+func DecodeMeshMetrics(buf []byte) (MeshMetricsRecord, error) {
+	if len(buf) < int(MESH_METRICS_RECORD_SIZE) {
+		return MeshMetricsRecord{}, &LayoutError{Code: "MESH_METRICS_TRUNCATED", Message: "mesh metrics record shorter than its fixed fields"}
+	}
+	c := Codec(buf)
+	return MeshMetricsRecord{
+		TotalPeers:            c.Uint32(MESH_METRICS_TOTAL_PEERS),
+		ConnectedPeers:        c.Uint32(MESH_METRICS_CONNECTED_PEERS),
+		DHTEntries:            c.Uint32(MESH_METRICS_DHT_ENTRIES),
+		GossipRatePerSec:      c.Float32(MESH_METRICS_GOSSIP_RATE),
+		AvgReputation:         c.Float32(MESH_METRICS_AVG_REPUTATION),
+		RegionID:              c.Uint32(MESH_METRICS_REGION_ID),
+		BytesSent:             c.Uint64(MESH_METRICS_BYTES_SENT),
+		BytesReceived:         c.Uint64(MESH_METRICS_BYTES_RECEIVED),
+		P50LatencyMs:          c.Float32(MESH_METRICS_P50_LATENCY),
+		P95LatencyMs:          c.Float32(MESH_METRICS_P95_LATENCY),
+		ConnectionSuccessRate: c.Float32(MESH_METRICS_CONNECTION_SUCCESS),
+		ChunkFetchSuccessRate: c.Float32(MESH_METRICS_FETCH_SUCCESS),
+		LocalChunks:           c.Uint32(MESH_METRICS_LOCAL_CHUNKS),
+		TotalChunksAvailable:  c.Uint32(MESH_METRICS_TOTAL_CHUNKS),
+		SectorID:              c.Uint32(MESH_METRICS_SECTOR_ID),
+		SectorMembers:         c.Uint32(MESH_METRICS_SECTOR_MEMBERS),
+	}, nil
+}
@@ -0,0 +1,64 @@
+package sab
+
+import (
+	"bytes"
+	"encoding/hex"
+	"os"
+	"strings"
+	"testing"
+)
+
+// goldenMeshMetrics matches testdata/mesh_metrics.hex, which the frontend and
+// Rust SDK tests decode with their own readers.
+var goldenMeshMetrics = MeshMetricsRecord{
+	TotalPeers:            42,
+	ConnectedPeers:        17,
+	DHTEntries:            256,
+	GossipRatePerSec:      12.5,
+	AvgReputation:         0.75,
+	RegionID:              0xDEADBEEF,
+	BytesSent:             0x0102030405060708,
+	BytesReceived:         1 << 40,
+	P50LatencyMs:          3.25,
+	P95LatencyMs:          48.5,
+	ConnectionSuccessRate: 0.5,
+	ChunkFetchSuccessRate: 0.875,
+	LocalChunks:           9,
+	TotalChunksAvailable:  1234,
+	SectorID:              7,
+	SectorMembers:         5,
+}
+
+func TestMeshMetrics_MatchesGolden(t *testing.T) {
+	raw, err := os.ReadFile("testdata/mesh_metrics.hex")
+	if err != nil {
+		t.Fatalf("failed to read golden record: %v", err)
+	}
+	golden, err := hex.DecodeString(strings.TrimSpace(string(raw)))
+	if err != nil {
+		t.Fatalf("golden record is not hex: %v", err)
+	}
+
+	if got := EncodeMeshMetrics(goldenMeshMetrics); !bytes.Equal(got, golden) {
+		t.Fatalf("encoding drifted from the golden record:\n got %x\nwant %x", got, golden)
+	}
+	decoded, err := DecodeMeshMetrics(golden)
+	if err != nil {
+		t.Fatalf("DecodeMeshMetrics failed: %v", err)
+	}
+	if decoded != goldenMeshMetrics {
+		t.Fatalf("decoded %+v, want %+v", decoded, goldenMeshMetrics)
+	}
+}
+
+func TestMeshMetrics_FitsRegion(t *testing.T) {
+	if MESH_METRICS_RECORD_SIZE > SIZE_MESH_METRICS {
+		t.Fatalf("record of %d bytes exceeds the %d byte region", MESH_METRICS_RECORD_SIZE, SIZE_MESH_METRICS)
+	}
+	if MESH_METRICS_SECTOR_MEMBERS+4 != MESH_METRICS_RECORD_SIZE {
+		t.Fatalf("last field ends at %d, record size is %d", MESH_METRICS_SECTOR_MEMBERS+4, MESH_METRICS_RECORD_SIZE)
+	}
+	if _, err := DecodeMeshMetrics(make([]byte, MESH_METRICS_RECORD_SIZE-1)); err == nil {
+		t.Fatal("expected a truncated record to fail")
+	}
+}
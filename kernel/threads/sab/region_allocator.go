@@ -0,0 +1,271 @@
+package sab
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Dynamic region registry encoding (little-endian, at OFFSET_DYNAMIC_REGISTRY):
+//
+//	0x00 generation u32  bumped on every allocate/free
+//	0x04 count      u32  live entries
+//	0x08 heap start u32
+//	0x0C heap end   u32
+//	0x10 entries    DYNAMIC_REGION_ENTRY_SIZE each: name[20] offset u32 size u32 flags u32
+//
+// Entries are packed: free removes an entry by moving the last one into its
+// slot, so readers only scan the first count entries.
+const (
+	dynamicRegistryHeaderSize = 16
+	dynamicRegionNameSize     = 20
+
+	// MAX_DYNAMIC_REGIONS is how many named regions the registry can hold.
+	MAX_DYNAMIC_REGIONS = (SIZE_DYNAMIC_REGISTRY - dynamicRegistryHeaderSize) / DYNAMIC_REGION_ENTRY_SIZE
+)
+
+var (
+	ErrRegionExists   = errors.New("sab: dynamic region already exists")
+	ErrRegionNotFound = errors.New("sab: dynamic region not found")
+	ErrRegionNoSpace  = errors.New("sab: no space for dynamic region")
+)
+
+// RegionMemory is the SAB access the allocator needs. SABBridge implements it
+// for the live buffer; ByteRegionMemory wraps a plain slice.
+type RegionMemory interface {
+	ReadAt(offset uint32, dest []byte) error
+	WriteRaw(offset uint32, data []byte) error
+}
+
+// ByteRegionMemory adapts a byte slice to RegionMemory.
+type ByteRegionMemory []byte
+
+func (b ByteRegionMemory) ReadAt(offset uint32, dest []byte) error {
+	if uint64(offset)+uint64(len(dest)) > uint64(len(b)) {
+		return fmt.Errorf("out of bounds read: off=%d len=%d cap=%d", offset, len(dest), len(b))
+	}
+	copy(dest, b[offset:])
+	return nil
+}
+
+func (b ByteRegionMemory) WriteRaw(offset uint32, data []byte) error {
+	if uint64(offset)+uint64(len(data)) > uint64(len(b)) {
+		return fmt.Errorf("out of bounds write: off=%d len=%d cap=%d", offset, len(data), len(b))
+	}
+	copy(b[offset:], data)
+	return nil
+}
+
+// DynamicRegion is a named region allocated at runtime.
+type DynamicRegion struct {
+	Name   string `json:"name"`
+	Offset uint32 `json:"offset"`
+	Size   uint32 `json:"size"`
+	Flags  uint32 `json:"flags"`
+}
+
+// RegionAllocator hands out named scratch regions from the space above the
+// static layout. It is a first-fit allocator whose only state is the registry
+// in the SAB, so a restarted kernel picks up regions modules still hold.
+type RegionAllocator struct {
+	mem       RegionMemory
+	heapStart uint32
+	heapEnd   uint32
+
+	mu         sync.Mutex
+	regions    []DynamicRegion
+	generation uint32
+}
+
+// NewRegionAllocator opens the registry of a SAB of sabSize bytes, loading
+// any regions already recorded there.
+func NewRegionAllocator(mem RegionMemory, sabSize uint32) (*RegionAllocator, error) {
+	if sabSize < OFFSET_DYNAMIC_HEAP {
+		return nil, &LayoutError{
+			Code:    "NO_DYNAMIC_HEAP",
+			Message: fmt.Sprintf("SAB of %d bytes ends before the dynamic heap at 0x%x", sabSize, OFFSET_DYNAMIC_HEAP),
+		}
+	}
+
+	ra := &RegionAllocator{
+		mem:       mem,
+		heapStart: OFFSET_DYNAMIC_HEAP,
+		heapEnd:   sabSize,
+	}
+	if err := ra.load(); err != nil {
+		return nil, err
+	}
+	return ra, nil
+}
+
+// load reads the registry, or initializes it when it was never written or
+// describes a different heap.
+func (ra *RegionAllocator) load() error {
+	buf := make([]byte, SIZE_DYNAMIC_REGISTRY)
+	if err := ra.mem.ReadAt(OFFSET_DYNAMIC_REGISTRY, buf); err != nil {
+		return err
+	}
+
+	count := binary.LittleEndian.Uint32(buf[4:])
+	start := binary.LittleEndian.Uint32(buf[8:])
+	end := binary.LittleEndian.Uint32(buf[12:])
+	if start != ra.heapStart || end != ra.heapEnd || count > MAX_DYNAMIC_REGIONS {
+		return ra.flush()
+	}
+
+	ra.generation = binary.LittleEndian.Uint32(buf[0:])
+	ra.regions = make([]DynamicRegion, 0, count)
+	for i := uint32(0); i < count; i++ {
+		entry := buf[dynamicRegistryHeaderSize+i*DYNAMIC_REGION_ENTRY_SIZE:]
+		ra.regions = append(ra.regions, DynamicRegion{
+			Name:   string(bytes.TrimRight(entry[:dynamicRegionNameSize], "\x00")),
+			Offset: binary.LittleEndian.Uint32(entry[dynamicRegionNameSize:]),
+			Size:   binary.LittleEndian.Uint32(entry[dynamicRegionNameSize+4:]),
+			Flags:  binary.LittleEndian.Uint32(entry[dynamicRegionNameSize+8:]),
+		})
+	}
+	return nil
+}
+
+// flush writes the full registry back to the SAB.
+func (ra *RegionAllocator) flush() error {
+	buf := make([]byte, SIZE_DYNAMIC_REGISTRY)
+	binary.LittleEndian.PutUint32(buf[0:], ra.generation)
+	binary.LittleEndian.PutUint32(buf[4:], uint32(len(ra.regions)))
+	binary.LittleEndian.PutUint32(buf[8:], ra.heapStart)
+	binary.LittleEndian.PutUint32(buf[12:], ra.heapEnd)
+	for i, r := range ra.regions {
+		entry := buf[dynamicRegistryHeaderSize+uint32(i)*DYNAMIC_REGION_ENTRY_SIZE:]
+		copy(entry[:dynamicRegionNameSize], r.Name)
+		binary.LittleEndian.PutUint32(entry[dynamicRegionNameSize:], r.Offset)
+		binary.LittleEndian.PutUint32(entry[dynamicRegionNameSize+4:], r.Size)
+		binary.LittleEndian.PutUint32(entry[dynamicRegionNameSize+8:], r.Flags)
+	}
+	return ra.mem.WriteRaw(OFFSET_DYNAMIC_REGISTRY, buf)
+}
+
+// Allocate reserves size bytes aligned to alignment (a power of two; 0 means
+// a cache line) and records them under name.
+func (ra *RegionAllocator) Allocate(name string, size, alignment uint32) (DynamicRegion, error) {
+	if name == "" || len(name) > dynamicRegionNameSize {
+		return DynamicRegion{}, fmt.Errorf("region name must be 1-%d bytes", dynamicRegionNameSize)
+	}
+	if size == 0 {
+		return DynamicRegion{}, errors.New("region size must be positive")
+	}
+	if alignment == 0 {
+		alignment = ALIGNMENT_CACHE_LINE
+	}
+	if alignment&(alignment-1) != 0 {
+		return DynamicRegion{}, fmt.Errorf("alignment %d is not a power of two", alignment)
+	}
+
+	ra.mu.Lock()
+	defer ra.mu.Unlock()
+
+	for _, r := range ra.regions {
+		if r.Name == name {
+			return DynamicRegion{}, fmt.Errorf("%w: %s", ErrRegionExists, name)
+		}
+	}
+	if uint32(len(ra.regions)) >= MAX_DYNAMIC_REGIONS {
+		return DynamicRegion{}, fmt.Errorf("%w: registry full", ErrRegionNoSpace)
+	}
+
+	offset, ok := ra.findGap(size, alignment)
+	if !ok {
+		return DynamicRegion{}, fmt.Errorf("%w: %d bytes aligned to %d", ErrRegionNoSpace, size, alignment)
+	}
+
+	region := DynamicRegion{Name: name, Offset: offset, Size: size}
+	ra.regions = append(ra.regions, region)
+	ra.generation++
+	if err := ra.flush(); err != nil {
+		ra.regions = ra.regions[:len(ra.regions)-1]
+		return DynamicRegion{}, err
+	}
+	return region, nil
+}
+
+// findGap returns the lowest aligned offset with size free bytes.
+func (ra *RegionAllocator) findGap(size, alignment uint32) (uint32, bool) {
+	used := make([]DynamicRegion, len(ra.regions))
+	copy(used, ra.regions)
+	sort.Slice(used, func(i, j int) bool { return used[i].Offset < used[j].Offset })
+
+	cursor := uint64(ra.heapStart)
+	for _, r := range used {
+		candidate := uint64(AlignOffset(uint32(cursor), alignment))
+		if candidate+uint64(size) <= uint64(r.Offset) {
+			return uint32(candidate), true
+		}
+		if end := uint64(r.Offset) + uint64(r.Size); end > cursor {
+			cursor = end
+		}
+	}
+	candidate := uint64(AlignOffset(uint32(cursor), alignment))
+	if candidate+uint64(size) > uint64(ra.heapEnd) {
+		return 0, false
+	}
+	return uint32(candidate), true
+}
+
+// Free releases the region starting at offset.
+func (ra *RegionAllocator) Free(offset uint32) error {
+	ra.mu.Lock()
+	defer ra.mu.Unlock()
+
+	for i, r := range ra.regions {
+		if r.Offset != offset {
+			continue
+		}
+		last := len(ra.regions) - 1
+		ra.regions[i] = ra.regions[last]
+		ra.regions = ra.regions[:last]
+		ra.generation++
+		if err := ra.flush(); err != nil {
+			ra.regions = append(ra.regions, r)
+			return err
+		}
+		return nil
+	}
+	return fmt.Errorf("%w: offset 0x%x", ErrRegionNotFound, offset)
+}
+
+// Lookup returns the region registered under name.
+func (ra *RegionAllocator) Lookup(name string) (DynamicRegion, bool) {
+	ra.mu.Lock()
+	defer ra.mu.Unlock()
+	for _, r := range ra.regions {
+		if r.Name == name {
+			return r, true
+		}
+	}
+	return DynamicRegion{}, false
+}
+
+// Regions returns every live region ordered by offset.
+func (ra *RegionAllocator) Regions() []DynamicRegion {
+	ra.mu.Lock()
+	out := make([]DynamicRegion, len(ra.regions))
+	copy(out, ra.regions)
+	ra.mu.Unlock()
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Offset < out[j].Offset })
+	return out
+}
+
+// Available returns the free bytes left in the dynamic heap, ignoring
+// fragmentation.
+func (ra *RegionAllocator) Available() uint32 {
+	ra.mu.Lock()
+	defer ra.mu.Unlock()
+	free := ra.heapEnd - ra.heapStart
+	for _, r := range ra.regions {
+		free -= r.Size
+	}
+	return free
+}
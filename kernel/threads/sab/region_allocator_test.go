@@ -0,0 +1,80 @@
+package sab
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRegionAllocator_AllocateFreeReuse(t *testing.T) {
+	mem := make(ByteRegionMemory, SAB_SIZE_DEFAULT)
+	ra, err := NewRegionAllocator(mem, SAB_SIZE_DEFAULT)
+	if err != nil {
+		t.Fatalf("NewRegionAllocator failed: %v", err)
+	}
+
+	a, err := ra.Allocate("scratch-a", 1000, 0)
+	if err != nil {
+		t.Fatalf("Allocate a failed: %v", err)
+	}
+	if a.Offset != OFFSET_DYNAMIC_HEAP || a.Offset%ALIGNMENT_CACHE_LINE != 0 {
+		t.Fatalf("expected first region at the heap start, got 0x%x", a.Offset)
+	}
+	b, err := ra.Allocate("scratch-b", 4096, ALIGNMENT_PAGE)
+	if err != nil {
+		t.Fatalf("Allocate b failed: %v", err)
+	}
+	if b.Offset%ALIGNMENT_PAGE != 0 || b.Offset < a.Offset+a.Size {
+		t.Fatalf("expected page-aligned region after a, got 0x%x", b.Offset)
+	}
+
+	if _, err := ra.Allocate("scratch-a", 10, 0); !errors.Is(err, ErrRegionExists) {
+		t.Fatalf("expected duplicate name to be rejected, got %v", err)
+	}
+	if _, err := ra.Allocate("odd", 10, 3); err == nil {
+		t.Fatal("expected non power-of-two alignment to be rejected")
+	}
+	if _, err := ra.Allocate("huge", SAB_SIZE_DEFAULT, 0); !errors.Is(err, ErrRegionNoSpace) {
+		t.Fatalf("expected oversized region to be rejected, got %v", err)
+	}
+
+	if err := ra.Free(a.Offset); err != nil {
+		t.Fatalf("Free failed: %v", err)
+	}
+	if err := ra.Free(a.Offset); !errors.Is(err, ErrRegionNotFound) {
+		t.Fatalf("expected double free to fail, got %v", err)
+	}
+	c, err := ra.Allocate("scratch-c", 512, 0)
+	if err != nil || c.Offset != a.Offset {
+		t.Fatalf("expected freed gap to be reused, got 0x%x, %v", c.Offset, err)
+	}
+}
+
+func TestRegionAllocator_RegistryVisibleToOtherReaders(t *testing.T) {
+	mem := make(ByteRegionMemory, SAB_SIZE_DEFAULT)
+	ra, err := NewRegionAllocator(mem, SAB_SIZE_DEFAULT)
+	if err != nil {
+		t.Fatalf("NewRegionAllocator failed: %v", err)
+	}
+	want, err := ra.Allocate("wgpu-staging", 64*1024, ALIGNMENT_PAGE)
+	if err != nil {
+		t.Fatalf("Allocate failed: %v", err)
+	}
+
+	// A second allocator over the same memory stands in for a restarted
+	// kernel or a module reading the registry.
+	reopened, err := NewRegionAllocator(mem, SAB_SIZE_DEFAULT)
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	got, ok := reopened.Lookup("wgpu-staging")
+	if !ok || got != want {
+		t.Fatalf("expected %+v from the SAB registry, got %+v (found=%v)", want, got, ok)
+	}
+	if next, err := reopened.Allocate("next", 16, 0); err != nil || next.Offset < want.Offset+want.Size {
+		t.Fatalf("expected new region past the existing one, got 0x%x, %v", next.Offset, err)
+	}
+
+	if _, err := NewRegionAllocator(make(ByteRegionMemory, OFFSET_DYNAMIC_REGISTRY), OFFSET_DYNAMIC_REGISTRY); err == nil {
+		t.Fatal("expected a SAB without room for the dynamic heap to be rejected")
+	}
+}
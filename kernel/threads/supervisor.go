@@ -37,6 +37,8 @@ type Supervisor struct {
 
 	// Child supervisors (actor hierarchy)
 	children map[string]*ChildSupervisor
+	order    []string // Child names in spawn order, for rest-for-one
+	restart  RestartPolicy
 
 	// Message queues for each child
 	matchmakerQueue chan JobMatchRequest
@@ -60,6 +62,10 @@ type Supervisor struct {
 	credits  *supervisor.CreditSupervisor
 	identity *units.IdentitySupervisor
 	social   *supervisor.SocialGraphSupervisor
+
+	// Failover: in-flight jobs are journaled so a standby can take them over
+	journal *supervisor.JobJournal
+	standby bool // Units loaded but idle until Promote
 }
 
 type SupervisorConfig struct {
@@ -69,6 +75,7 @@ type SupervisorConfig struct {
 	SAB             unsafe.Pointer // SharedArrayBuffer pointer
 	MaxWorkers      int
 	Role            kruntime.RoleConfig
+	Restart         RestartPolicy // Zero value uses DefaultRestartPolicy
 }
 
 // SupervisorStats holds supervisor statistics
@@ -77,15 +84,21 @@ type SupervisorStats struct {
 	TotalMessages    uint64
 	FailedThreads    int
 	RestartedThreads int
+	Threads          map[string]ThreadStats // Per-child restart counters
 }
 
 // ChildSupervisor represents a supervised thread
 type ChildSupervisor struct {
 	name        string
 	startFunc   func(context.Context) error
-	restarts    int
-	maxRestarts int
+	maxRestarts int // Restart budget per RestartPolicy.Window
+	budget      restartBudget
 	lastRestart time.Time
+	lastErr     string
+	failed      bool
+
+	cancel         context.CancelFunc // Stops the current run
+	siblingRestart bool               // Current run was stopped by rest-for-one
 }
 
 // Message types for inter-thread communication
@@ -136,6 +149,7 @@ func NewRootSupervisor(ctx context.Context, config SupervisorConfig) *Supervisor
 		ctx:             supervisorCtx,
 		cancel:          cancel,
 		children:        make(map[string]*ChildSupervisor),
+		restart:         config.Restart.withDefaults(),
 		matchmakerQueue: make(chan JobMatchRequest, 100),
 		watcherQueue:    make(chan HealthCheckRequest, 100),
 		adjusterQueue:   make(chan ThrottleRequest, 100),
@@ -176,6 +190,19 @@ func (s *Supervisor) GetCredits() *supervisor.CreditSupervisor {
 	return s.credits
 }
 
+// ModuleLoadError returns nil if the module has registered itself in the SAB
+// registry, which the host does only after instantiating it.
+func (s *Supervisor) ModuleLoadError(moduleID string) error {
+	s.mu.RLock()
+	reg := s.registry
+	s.mu.RUnlock()
+	if reg == nil {
+		return fmt.Errorf("module registry not initialized")
+	}
+	_, err := reg.GetModule(moduleID)
+	return err
+}
+
 // GetSystemLoad implements mesh.SystemLoadProvider via RootSupervisor
 func (s *Supervisor) GetSystemLoad() float64 {
 	s.mu.RLock()
@@ -194,8 +221,49 @@ func (s *Supervisor) GetSystemLoad() float64 {
 	return load
 }
 
+// QueueDepth returns the jobs waiting in the units' queues.
+func (s *Supervisor) QueueDepth() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	depth := 0
+	for _, unit := range s.units {
+		if m, ok := unit.(interface {
+			Metrics() *foundation.SupervisorMetrics
+		}); ok {
+			if metrics := m.Metrics(); metrics != nil {
+				depth += metrics.QueueDepth
+			}
+		}
+	}
+	return depth
+}
+
 // InitializeCompute initializes the compute units with the provided SAB
 func (s *Supervisor) InitializeCompute(sab unsafe.Pointer, size uint32) error {
+	if loadedUnits := s.prepareCompute(size, true); loadedUnits != nil {
+		s.activate(loadedUnits)
+	}
+	return nil
+}
+
+// PrepareStandby loads regions and units into a private replica without
+// starting anything, so Promote only has to spawn children.
+func (s *Supervisor) PrepareStandby(size uint32) error {
+	s.mu.Lock()
+	s.standby = true
+	s.mu.Unlock()
+
+	if s.prepareCompute(size, false) == nil {
+		return fmt.Errorf("supervisor already initialized")
+	}
+	return nil
+}
+
+// prepareCompute establishes the supervisor regions and loads units. It
+// returns nil if the supervisor is already initialized. attachMesh hands the
+// credit vault to the mesh; a standby does that on promotion instead.
+func (s *Supervisor) prepareCompute(size uint32, attachMesh bool) map[string]interface{} {
 	s.mu.Lock()
 
 	// Guard: Don't initialize twice
@@ -231,11 +299,8 @@ func (s *Supervisor) InitializeCompute(sab unsafe.Pointer, size uint32) error {
 	// Initialize Core System Supervisors
 	s.credits = supervisor.NewCreditSupervisor(s.sab, s.sabSize, uint32(sab_layout.OFFSET_ECONOMICS))
 
-	// Inject CreditSupervisor into MeshCoordinator for unified economic authority
-	if m, ok := s.config.MeshCoordinator.(interface {
-		SetEconomicVault(foundation.EconomicVault)
-	}); ok {
-		m.SetEconomicVault(s.credits)
+	if attachMesh {
+		s.attachMesh()
 	}
 
 	s.social = supervisor.NewSocialGraphSupervisor(s.sab, s.sabSize, uint32(sab_layout.OFFSET_SOCIAL_GRAPH))
@@ -289,7 +354,21 @@ func (s *Supervisor) InitializeCompute(sab unsafe.Pointer, size uint32) error {
 	// CRITICAL: Release lock BEFORE spawning children to avoid recursive lock
 	// Child spawning acquires its own lock, so we must not hold this one
 	s.mu.Unlock()
+	return loadedUnits
+}
 
+// attachMesh injects the CreditSupervisor into the MeshCoordinator for
+// unified economic authority.
+func (s *Supervisor) attachMesh() {
+	if m, ok := s.config.MeshCoordinator.(interface {
+		SetEconomicVault(foundation.EconomicVault)
+	}); ok {
+		m.SetEconomicVault(s.credits)
+	}
+}
+
+// activate starts supervision of the loaded units and the background loops.
+func (s *Supervisor) activate(loadedUnits map[string]interface{}) {
 	// Start supervisors for initially discovered units (CONCURRENT - failures isolated)
 	for name, unit := range loadedUnits {
 		if starter, ok := unit.(interface{ Start(context.Context) error }); ok {
@@ -304,14 +383,128 @@ func (s *Supervisor) InitializeCompute(sab unsafe.Pointer, size uint32) error {
 	go s.spawnChild("signal_listener", s.runSignalListener, 100)
 	go s.spawnChild("economy_loop", s.runEconomyLoop, 10)
 	go s.spawnChild("metrics_loop", s.runMetricsLoop, 1)
+}
 
-	return nil
+// Promote activates a prepared standby in place of a failed supervisor and
+// re-dispatches the jobs left in the journal. It returns how many jobs were
+// taken over.
+func (s *Supervisor) Promote(journal *supervisor.JobJournal) (int, error) {
+	s.mu.Lock()
+	if !s.standby || s.units == nil {
+		s.mu.Unlock()
+		return 0, fmt.Errorf("supervisor is not a prepared standby")
+	}
+	s.standby = false
+	s.journal = journal
+	loadedUnits := s.units
+	s.mu.Unlock()
+
+	s.attachMesh()
+	s.activate(loadedUnits)
+	s.Start()
+	return s.Redispatch(), nil
+}
+
+// SetJournal mirrors every job submitted from now on into journal.
+func (s *Supervisor) SetJournal(journal *supervisor.JobJournal) {
+	s.mu.Lock()
+	s.journal = journal
+	s.mu.Unlock()
+}
+
+// Redispatch runs every job still pending in the journal on this supervisor.
+// Results reach the waiters of the original submissions. Jobs whose payload
+// did not fit the journal are failed rather than silently dropped.
+func (s *Supervisor) Redispatch() int {
+	s.mu.RLock()
+	journal := s.journal
+	s.mu.RUnlock()
+	if journal == nil {
+		return 0
+	}
+
+	pending, err := journal.Pending()
+	if err != nil {
+		s.logger.Error("Failed to read job journal", utils.Err(err))
+		return 0
+	}
+
+	for _, p := range pending {
+		if p.Truncated {
+			journal.Complete(p.Ticket, &foundation.Result{
+				JobID: p.Job.ID,
+				Error: "job payload was not journaled; resubmit after failover",
+			})
+			continue
+		}
+		span := startJobSpan(p.Job)
+		span.SetAttribute("job.redispatched", true)
+		resChan, err := s.dispatch(p.Job)
+		if err != nil {
+			span.SetError(err)
+			span.End()
+			journal.Complete(p.Ticket, &foundation.Result{JobID: p.Job.ID, Error: err.Error()})
+			continue
+		}
+		go s.completeJournaled(journal, p.Ticket, traceResult(span, resChan))
+	}
+	if len(pending) > 0 {
+		s.logger.Info("Re-dispatched journaled jobs", utils.Int("count", len(pending)))
+	}
+	return len(pending)
+}
+
+func (s *Supervisor) completeJournaled(journal *supervisor.JobJournal, ticket supervisor.JournalTicket, resChan <-chan *foundation.Result) {
+	select {
+	case res := <-resChan:
+		journal.Complete(ticket, res)
+	case <-s.ctx.Done():
+		// Left pending for whichever supervisor takes over next
+	}
 }
 
 // runDiscoveryLoop waits for module registration signals (zero-CPU blocking)
 // Replaces polling with Atomics.wait on IDX_REGISTRY_EPOCH
-// Submit routes a job to the appropriate unit supervisor and returns a result channel
+// Submit routes a job to the appropriate unit supervisor and returns a result channel.
+// With a journal set, the job stays mirrored in the SAB until it completes.
 func (s *Supervisor) Submit(job *foundation.Job) (<-chan *foundation.Result, error) {
+	span := startJobSpan(job)
+	resChan, err := s.submit(job)
+	if err != nil {
+		span.SetError(err)
+		span.End()
+		return nil, err
+	}
+	return traceResult(span, resChan), nil
+}
+
+func (s *Supervisor) submit(job *foundation.Job) (<-chan *foundation.Result, error) {
+	s.mu.RLock()
+	journal := s.journal
+	s.mu.RUnlock()
+	if journal == nil {
+		return s.dispatch(job)
+	}
+
+	out := make(chan *foundation.Result, 1)
+	ticket, err := journal.Record(job, out)
+	if err != nil {
+		// The job still runs; it just will not survive a failover
+		s.logger.Debug("Job not journaled", utils.String("job_id", job.ID), utils.Err(err))
+		return s.dispatch(job)
+	}
+
+	resChan, err := s.dispatch(job)
+	if err != nil {
+		journal.Release(ticket)
+		return nil, err
+	}
+	go s.completeJournaled(journal, ticket, resChan)
+	return out, nil
+}
+
+// dispatch hands a job to its unit.
+func (s *Supervisor) dispatch(job *foundation.Job) (<-chan *foundation.Result, error) {
 	s.mu.RLock()
 	unit, ok := s.units[job.Type]
 	s.mu.RUnlock()
@@ -492,12 +685,16 @@ func (s *Supervisor) spawnChild(name string, startFunc func(context.Context) err
 		maxRestarts: maxRestarts,
 	}
 
+	if _, exists := s.children[name]; !exists {
+		s.order = append(s.order, name)
+	}
 	s.children[name] = child
 	s.wg.Add(1)
 	go s.superviseChild(child)
 }
 
-// superviseChild supervises a child thread with automatic restart
+// superviseChild supervises a child thread, restarting it per the restart
+// policy until its budget runs out
 func (s *Supervisor) superviseChild(child *ChildSupervisor) {
 	defer s.wg.Done()
 
@@ -507,40 +704,88 @@ func (s *Supervisor) superviseChild(child *ChildSupervisor) {
 			s.logger.Info("Child supervisor stopping", utils.String("name", child.name))
 			return
 		default:
-			err := s.runChildWithRecovery(child)
+		}
 
-			if err != nil {
-				s.logger.Error("Child supervisor failed", utils.String("name", child.name), utils.Err(err))
+		err := s.runChildWithRecovery(child)
+		if s.ctx.Err() != nil {
+			// Stopping; a child unwinding is not a failure
+			continue
+		}
 
-				if child.restarts >= child.maxRestarts {
-					s.logger.Error("Child supervisor exceeded max restarts",
-						utils.String("name", child.name),
-						utils.Int("restarts", child.restarts))
-					s.mu.Lock()
-					s.stats.FailedThreads++
-					s.mu.Unlock()
-					return
-				}
+		s.mu.Lock()
+		sibling := child.siblingRestart
+		child.siblingRestart = false
+		s.mu.Unlock()
+		if sibling || err == nil {
+			continue
+		}
 
-				backoff := time.Duration(child.restarts+1) * time.Second
-				s.logger.Warn("Restarting child supervisor",
-					utils.String("name", child.name),
-					utils.Duration("backoff", backoff))
+		s.logger.Error("Child supervisor failed", utils.String("name", child.name), utils.Err(err))
 
-				time.Sleep(backoff)
-				child.restarts++
-				child.lastRestart = time.Now()
+		s.mu.Lock()
+		backoff, ok := child.budget.next(s.restart, child.maxRestarts, time.Now())
+		child.lastErr = err.Error()
+		if !ok {
+			child.failed = true
+			s.stats.FailedThreads++
+			escalate := s.restart.EscalateAfter > 0 && s.stats.FailedThreads >= s.restart.EscalateAfter
+			restarts := child.budget.total
+			s.mu.Unlock()
 
-				s.mu.Lock()
-				s.stats.RestartedThreads++
-				s.mu.Unlock()
+			s.logger.Error("Child supervisor exceeded restart budget",
+				utils.String("name", child.name),
+				utils.Int("restarts", restarts))
+			if escalate && s.restart.OnEscalate != nil {
+				s.restart.OnEscalate(child.name, err)
 			}
+			return
+		}
+		dependents := s.restart.dependents(s.order, child.name)
+		s.mu.Unlock()
+
+		s.logger.Warn("Restarting child supervisor",
+			utils.String("name", child.name),
+			utils.Duration("backoff", backoff),
+			utils.Int("dependents", len(dependents)))
+
+		select {
+		case <-time.After(backoff):
+		case <-s.ctx.Done():
+			return
+		}
+
+		s.mu.Lock()
+		child.lastRestart = time.Now()
+		s.stats.RestartedThreads++
+		s.mu.Unlock()
+		s.restartDependents(dependents)
+	}
+}
+
+// restartDependents stops the current run of each named child so its
+// supervise loop starts it again without charging its restart budget.
+func (s *Supervisor) restartDependents(names []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, name := range names {
+		child, ok := s.children[name]
+		if !ok || child.failed || child.cancel == nil {
+			continue
 		}
+		child.siblingRestart = true
+		child.cancel()
 	}
 }
 
 // runChildWithRecovery runs a child with panic recovery
 func (s *Supervisor) runChildWithRecovery(child *ChildSupervisor) (err error) {
+	ctx, cancel := context.WithCancel(s.ctx)
+	s.mu.Lock()
+	child.cancel = cancel
+	s.mu.Unlock()
+	defer cancel()
+
 	defer func() {
 		if r := recover(); r != nil {
 			s.logger.Error("Child supervisor panicked", utils.String("name", child.name))
@@ -552,7 +797,7 @@ func (s *Supervisor) runChildWithRecovery(child *ChildSupervisor) (err error) {
 		}
 	}()
 
-	return child.startFunc(s.ctx)
+	return child.startFunc(ctx)
 }
 
 // ========== StorageProvider Implementation ==========
@@ -661,6 +906,38 @@ func (s *Supervisor) HasChunk(ctx context.Context, hash string) (bool, error) {
 	return true, nil
 }
 
+// DeleteChunk removes a data chunk via the StorageSupervisor
+func (s *Supervisor) DeleteChunk(ctx context.Context, hash string) error {
+	s.mu.RLock()
+	unit, ok := s.units["storage"]
+	s.mu.RUnlock()
+
+	if !ok {
+		return fmt.Errorf("storage unit not found")
+	}
+
+	ss, ok := unit.(*units.StorageSupervisor)
+	if !ok {
+		return fmt.Errorf("invalid storage unit type")
+	}
+
+	job := &foundation.Job{
+		ID:        utils.GenerateID(),
+		Type:      "storage",
+		Operation: "delete",
+		Parameters: map[string]interface{}{
+			"hash": hash,
+		},
+	}
+
+	result := ss.ExecuteJob(job)
+	if result.Error != "" {
+		return fmt.Errorf("storage error: %s", result.Error)
+	}
+
+	return nil
+}
+
 // GetStats returns supervisor statistics
 func (s *Supervisor) GetStats() SupervisorStats {
 	s.mu.RLock()
@@ -668,6 +945,17 @@ func (s *Supervisor) GetStats() SupervisorStats {
 
 	stats := s.stats
 	stats.ActiveThreads = len(s.children)
+	stats.Threads = make(map[string]ThreadStats, len(s.children))
+	now := time.Now()
+	for name, child := range s.children {
+		stats.Threads[name] = ThreadStats{
+			Restarts:    child.budget.total,
+			Recent:      child.budget.recent(s.restart.Window, now),
+			Failed:      child.failed,
+			LastRestart: child.lastRestart,
+			LastError:   child.lastErr,
+		}
+	}
 	return stats
 }
 
@@ -0,0 +1,276 @@
+package supervisor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/utils/tracing"
+)
+
+// CommandStatus is the outcome of a host command.
+type CommandStatus string
+
+const (
+	CommandOK    CommandStatus = "ok"    // Handler succeeded
+	CommandError CommandStatus = "error" // Permanent failure; do not retry
+	CommandRetry CommandStatus = "retry" // Transient failure; retry after RetryAfterMs
+)
+
+// CommandErrorCode classifies a failed command.
+type CommandErrorCode string
+
+const (
+	CodeInvalidCommand CommandErrorCode = "invalid_command"
+	CodeUnknownCommand CommandErrorCode = "unknown_command"
+	CodeBusy           CommandErrorCode = "busy"
+	CodeInProgress     CommandErrorCode = "in_progress"
+	CodeTimeout        CommandErrorCode = "timeout"
+	CodeInternal       CommandErrorCode = "internal"
+)
+
+// CommandResultKind tags result envelopes in the host outbox so the host can
+// tell them apart from job results.
+const CommandResultKind = "command_result"
+
+const (
+	defaultCommandTimeout    = 30 * time.Second
+	defaultIdempotencyTTL    = 10 * time.Minute
+	defaultIdempotencyMax    = 1024
+	defaultCommandRetryAfter = 100 * time.Millisecond
+)
+
+// HostCommand is a command frame written by the host.
+type HostCommand struct {
+	CorrelationID  string          `json:"correlation_id"`
+	IdempotencyKey string          `json:"idempotency_key,omitempty"`
+	Type           string          `json:"type"`
+	Payload        json.RawMessage `json:"payload,omitempty"`
+	Traceparent    string          `json:"traceparent,omitempty"` // Host span the command runs under
+}
+
+// CommandResult is the envelope written to the host outbox for every
+// consumed command.
+type CommandResult struct {
+	Kind           string           `json:"kind"`
+	CorrelationID  string           `json:"correlation_id"`
+	IdempotencyKey string           `json:"idempotency_key,omitempty"`
+	Status         CommandStatus    `json:"status"`
+	ErrorCode      CommandErrorCode `json:"error_code,omitempty"`
+	Error          string           `json:"error,omitempty"`
+	RetryAfterMs   int64            `json:"retry_after_ms,omitempty"`
+	Replayed       bool             `json:"replayed,omitempty"` // Served from the idempotency cache
+	Payload        json.RawMessage  `json:"payload,omitempty"`
+}
+
+// CommandFailure lets handlers choose the error code and retry semantics of
+// a failure. Other errors are classified by classifyCommandError.
+type CommandFailure struct {
+	Code       CommandErrorCode
+	RetryAfter time.Duration // Non-zero marks the failure retryable
+	Err        error
+}
+
+func (f *CommandFailure) Error() string { return f.Err.Error() }
+func (f *CommandFailure) Unwrap() error { return f.Err }
+
+// CommandHandler executes one command type.
+type CommandHandler func(ctx context.Context, payload json.RawMessage) (interface{}, error)
+
+type idempotencyEntry struct {
+	result  *CommandResult // nil while the command is running
+	created time.Time
+}
+
+// CommandBus dispatches host commands and answers each with a
+// CommandResult. Commands that carry an idempotency key run at most once
+// while their result is cached; a retry after a timeout gets the cached
+// result instead of running the command again.
+type CommandBus struct {
+	mu       sync.Mutex
+	handlers map[string]CommandHandler
+	results  map[string]*idempotencyEntry
+	order    []string // Idempotency keys, oldest first
+
+	emit    func([]byte) error
+	timeout time.Duration
+	ttl     time.Duration
+	max     int
+}
+
+// NewCommandBus creates a bus that writes result envelopes with emit.
+func NewCommandBus(emit func([]byte) error) *CommandBus {
+	return &CommandBus{
+		handlers: make(map[string]CommandHandler),
+		results:  make(map[string]*idempotencyEntry),
+		emit:     emit,
+		timeout:  defaultCommandTimeout,
+		ttl:      defaultIdempotencyTTL,
+		max:      defaultIdempotencyMax,
+	}
+}
+
+// Register installs the handler for a command type.
+func (b *CommandBus) Register(commandType string, handler CommandHandler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[commandType] = handler
+}
+
+// Consume decodes and runs a command frame, emits its result envelope and
+// returns it.
+func (b *CommandBus) Consume(ctx context.Context, frame []byte) *CommandResult {
+	var cmd HostCommand
+	if err := json.Unmarshal(frame, &cmd); err != nil {
+		return b.finish(&CommandResult{
+			Status:    CommandError,
+			ErrorCode: CodeInvalidCommand,
+			Error:     fmt.Sprintf("malformed command: %v", err),
+		})
+	}
+	return b.Execute(ctx, cmd)
+}
+
+// Execute runs a decoded command, emits its result envelope and returns it.
+func (b *CommandBus) Execute(ctx context.Context, cmd HostCommand) *CommandResult {
+	if cmd.CorrelationID == "" || cmd.Type == "" {
+		return b.finish(&CommandResult{
+			CorrelationID:  cmd.CorrelationID,
+			IdempotencyKey: cmd.IdempotencyKey,
+			Status:         CommandError,
+			ErrorCode:      CodeInvalidCommand,
+			Error:          "command requires correlation_id and type",
+		})
+	}
+
+	b.mu.Lock()
+	handler, ok := b.handlers[cmd.Type]
+	if cmd.IdempotencyKey != "" {
+		b.pruneLocked(time.Now())
+		if entry, seen := b.results[cmd.IdempotencyKey]; seen {
+			b.mu.Unlock()
+			if entry.result == nil {
+				return b.finish(&CommandResult{
+					CorrelationID:  cmd.CorrelationID,
+					IdempotencyKey: cmd.IdempotencyKey,
+					Status:         CommandRetry,
+					ErrorCode:      CodeInProgress,
+					Error:          "command with this idempotency key is still running",
+					RetryAfterMs:   defaultCommandRetryAfter.Milliseconds(),
+				})
+			}
+			replay := *entry.result
+			replay.CorrelationID = cmd.CorrelationID
+			replay.Replayed = true
+			return b.finish(&replay)
+		}
+		if ok {
+			b.results[cmd.IdempotencyKey] = &idempotencyEntry{created: time.Now()}
+			b.order = append(b.order, cmd.IdempotencyKey)
+		}
+	}
+	b.mu.Unlock()
+
+	if !ok {
+		return b.finish(&CommandResult{
+			CorrelationID:  cmd.CorrelationID,
+			IdempotencyKey: cmd.IdempotencyKey,
+			Status:         CommandError,
+			ErrorCode:      CodeUnknownCommand,
+			Error:          fmt.Sprintf("unknown command type %q", cmd.Type),
+		})
+	}
+
+	runCtx, cancel := context.WithTimeout(tracing.ContextWithTraceparent(ctx, cmd.Traceparent), b.timeout)
+	runCtx, span := tracing.Start(runCtx, "host.command "+cmd.Type, tracing.SpanKindServer)
+	span.SetAttribute("command.correlation_id", cmd.CorrelationID)
+	value, err := handler(runCtx, cmd.Payload)
+	span.SetError(err)
+	span.End()
+	cancel()
+
+	result := &CommandResult{
+		Kind:           CommandResultKind,
+		CorrelationID:  cmd.CorrelationID,
+		IdempotencyKey: cmd.IdempotencyKey,
+		Status:         CommandOK,
+	}
+	if err == nil && value != nil {
+		if result.Payload, err = json.Marshal(value); err != nil {
+			err = fmt.Errorf("failed to encode command result: %w", err)
+		}
+	}
+	if err != nil {
+		result.Payload = nil
+		result.Status, result.ErrorCode, result.RetryAfterMs = classifyCommandError(err)
+		result.Error = err.Error()
+	}
+
+	if cmd.IdempotencyKey != "" {
+		b.mu.Lock()
+		if result.Status == CommandRetry {
+			// Transient failures must run again on retry
+			delete(b.results, cmd.IdempotencyKey)
+		} else if entry, ok := b.results[cmd.IdempotencyKey]; ok {
+			entry.result = result
+		}
+		b.mu.Unlock()
+	}
+	return b.finish(result)
+}
+
+// finish stamps and emits a result envelope.
+func (b *CommandBus) finish(result *CommandResult) *CommandResult {
+	if result.Kind == "" {
+		result.Kind = CommandResultKind
+	}
+	if b.emit != nil {
+		if data, err := json.Marshal(result); err == nil {
+			_ = b.emit(data) // The host outbox reports its own drops
+		}
+	}
+	return result
+}
+
+// pruneLocked drops expired idempotency entries and bounds the cache. Entries
+// for running commands are kept so a concurrent retry cannot run twice.
+func (b *CommandBus) pruneLocked(now time.Time) {
+	overflow := len(b.order) - b.max
+	kept := b.order[:0]
+	for _, key := range b.order {
+		entry, ok := b.results[key]
+		if !ok {
+			overflow--
+			continue
+		}
+		if entry.result != nil && (overflow > 0 || now.Sub(entry.created) > b.ttl) {
+			delete(b.results, key)
+			overflow--
+			continue
+		}
+		kept = append(kept, key)
+	}
+	b.order = kept
+}
+
+// classifyCommandError maps a handler error to result semantics.
+func classifyCommandError(err error) (CommandStatus, CommandErrorCode, int64) {
+	var failure *CommandFailure
+	if errors.As(err, &failure) {
+		if failure.RetryAfter > 0 {
+			return CommandRetry, failure.Code, failure.RetryAfter.Milliseconds()
+		}
+		return CommandError, failure.Code, 0
+	}
+	switch {
+	case errors.Is(err, ErrRingFull):
+		return CommandRetry, CodeBusy, defaultCommandRetryAfter.Milliseconds()
+	case errors.Is(err, context.DeadlineExceeded):
+		return CommandRetry, CodeTimeout, defaultCommandRetryAfter.Milliseconds()
+	default:
+		return CommandError, CodeInternal, 0
+	}
+}
@@ -0,0 +1,118 @@
+package supervisor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommandBus_EmitsEnvelopeForEveryCommand(t *testing.T) {
+	var emitted []CommandResult
+	bus := NewCommandBus(func(data []byte) error {
+		var res CommandResult
+		require.NoError(t, json.Unmarshal(data, &res))
+		emitted = append(emitted, res)
+		return nil
+	})
+	bus.Register("echo", func(ctx context.Context, payload json.RawMessage) (interface{}, error) {
+		return map[string]string{"echo": string(payload)}, nil
+	})
+
+	ok := bus.Consume(context.Background(), []byte(`{"correlation_id":"c1","type":"echo","payload":"hi"}`))
+	assert.Equal(t, CommandOK, ok.Status)
+	assert.JSONEq(t, `{"echo":"\"hi\""}`, string(ok.Payload))
+
+	unknown := bus.Consume(context.Background(), []byte(`{"correlation_id":"c2","type":"nope"}`))
+	assert.Equal(t, CodeUnknownCommand, unknown.ErrorCode)
+
+	malformed := bus.Consume(context.Background(), []byte(`{`))
+	assert.Equal(t, CodeInvalidCommand, malformed.ErrorCode)
+
+	require.Len(t, emitted, 3)
+	for _, res := range emitted {
+		assert.Equal(t, CommandResultKind, res.Kind)
+	}
+	assert.Equal(t, "c1", emitted[0].CorrelationID)
+}
+
+func TestCommandBus_IdempotentRetryReplaysResult(t *testing.T) {
+	var runs atomic.Int32
+	bus := NewCommandBus(nil)
+	bus.Register("spawn", func(ctx context.Context, payload json.RawMessage) (interface{}, error) {
+		return runs.Add(1), nil
+	})
+
+	first := bus.Execute(context.Background(), HostCommand{CorrelationID: "a", IdempotencyKey: "k1", Type: "spawn"})
+	retry := bus.Execute(context.Background(), HostCommand{CorrelationID: "b", IdempotencyKey: "k1", Type: "spawn"})
+
+	assert.Equal(t, int32(1), runs.Load(), "retry must not run the command again")
+	assert.True(t, retry.Replayed)
+	assert.Equal(t, "b", retry.CorrelationID)
+	assert.Equal(t, first.Payload, retry.Payload)
+}
+
+func TestCommandBus_RetryableFailuresRunAgain(t *testing.T) {
+	var runs atomic.Int32
+	bus := NewCommandBus(nil)
+	bus.Register("write", func(ctx context.Context, payload json.RawMessage) (interface{}, error) {
+		if runs.Add(1) == 1 {
+			return nil, fmt.Errorf("inbox: %w", ErrRingFull)
+		}
+		return nil, nil
+	})
+
+	busy := bus.Execute(context.Background(), HostCommand{CorrelationID: "a", IdempotencyKey: "k", Type: "write"})
+	assert.Equal(t, CommandRetry, busy.Status)
+	assert.Equal(t, CodeBusy, busy.ErrorCode)
+	assert.Positive(t, busy.RetryAfterMs)
+
+	again := bus.Execute(context.Background(), HostCommand{CorrelationID: "b", IdempotencyKey: "k", Type: "write"})
+	assert.Equal(t, CommandOK, again.Status)
+	assert.False(t, again.Replayed)
+	assert.Equal(t, int32(2), runs.Load())
+}
+
+func TestCommandBus_ClassifiesFailures(t *testing.T) {
+	bus := NewCommandBus(nil)
+	bus.Register("fail", func(ctx context.Context, payload json.RawMessage) (interface{}, error) {
+		return nil, errors.New("boom")
+	})
+	bus.Register("custom", func(ctx context.Context, payload json.RawMessage) (interface{}, error) {
+		return nil, &CommandFailure{Code: "quota", RetryAfter: 2 * time.Second, Err: errors.New("over quota")}
+	})
+	bus.Register("slow", func(ctx context.Context, payload json.RawMessage) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	bus.timeout = 10 * time.Millisecond
+
+	res := bus.Execute(context.Background(), HostCommand{CorrelationID: "1", Type: "fail"})
+	assert.Equal(t, CommandError, res.Status)
+	assert.Equal(t, CodeInternal, res.ErrorCode)
+
+	res = bus.Execute(context.Background(), HostCommand{CorrelationID: "2", Type: "custom"})
+	assert.Equal(t, CommandRetry, res.Status)
+	assert.Equal(t, CommandErrorCode("quota"), res.ErrorCode)
+	assert.Equal(t, int64(2000), res.RetryAfterMs)
+
+	res = bus.Execute(context.Background(), HostCommand{CorrelationID: "3", Type: "slow"})
+	assert.Equal(t, CodeTimeout, res.ErrorCode)
+}
+
+func TestCommandBus_BoundsIdempotencyCache(t *testing.T) {
+	bus := NewCommandBus(nil)
+	bus.max = 2
+	bus.Register("noop", func(ctx context.Context, payload json.RawMessage) (interface{}, error) { return nil, nil })
+
+	for i := 0; i < 5; i++ {
+		bus.Execute(context.Background(), HostCommand{CorrelationID: "c", IdempotencyKey: fmt.Sprint(i), Type: "noop"})
+	}
+	assert.LessOrEqual(t, len(bus.results), 3)
+}
@@ -0,0 +1,71 @@
+package supervisor
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// compactCrashLogs is how many log lines a compacted report keeps before
+// dropping them entirely.
+const compactCrashLogs = 8
+
+// CrashReport is the post-mortem of a kernel panic.
+type CrashReport struct {
+	Sequence   uint32                 `json:"sequence"`
+	Reason     string                 `json:"reason"`
+	Time       time.Time              `json:"time"`
+	State      string                 `json:"state"`
+	Uptime     string                 `json:"uptime,omitempty"`
+	Stack      string                 `json:"stack,omitempty"`
+	Supervisor map[string]interface{} `json:"supervisor,omitempty"`
+	Rings      []RingStats            `json:"rings,omitempty"`
+	Epochs     []int32                `json:"epochs,omitempty"` // Atomic flags region, by index
+	Mesh       map[string]interface{} `json:"mesh,omitempty"`
+	Logs       []string               `json:"logs,omitempty"` // Oldest first
+	Truncated  bool                   `json:"truncated,omitempty"`
+}
+
+// Compact encodes the report in at most limit bytes. Fields are dropped in
+// order of how little they say about the panic itself: mesh telemetry, then
+// logs, rings and counters, and finally the tail of the stack.
+func (r *CrashReport) Compact(limit int) ([]byte, error) {
+	c := *r
+	shrink := []func(){
+		func() { c.Mesh = nil },
+		func() {
+			if len(c.Logs) > compactCrashLogs {
+				c.Logs = c.Logs[len(c.Logs)-compactCrashLogs:]
+			}
+		},
+		func() { c.Logs = nil },
+		func() { c.Rings = nil },
+		func() { c.Supervisor, c.Epochs = nil, nil },
+	}
+
+	for step := 0; ; step++ {
+		data, err := json.Marshal(&c)
+		if err != nil {
+			return nil, err
+		}
+		over := len(data) - limit
+		if over <= 0 {
+			return data, nil
+		}
+
+		c.Truncated = true
+		switch {
+		case step < len(shrink):
+			shrink[step]()
+		case c.Stack != "":
+			// Each raw byte is at least one encoded byte, so this converges
+			if over >= len(c.Stack) {
+				c.Stack = ""
+			} else {
+				c.Stack = c.Stack[:len(c.Stack)-over]
+			}
+		default:
+			return nil, fmt.Errorf("crash report does not fit in %d bytes", limit)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package supervisor
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCrashReport_CompactFitsLimit(t *testing.T) {
+	logs := make([]string, 64)
+	for i := range logs {
+		logs[i] = strings.Repeat("l", 40)
+	}
+	report := &CrashReport{
+		Reason: "index out of range",
+		Time:   time.Now(),
+		State:  "running",
+		Stack:  strings.Repeat("goroutine 1 [running]:\n", 200),
+		Epochs: make([]int32, 32),
+		Mesh:   map[string]interface{}{"peers": 12},
+		Logs:   logs,
+	}
+
+	data, err := report.Compact(2032)
+	require.NoError(t, err)
+	assert.LessOrEqual(t, len(data), 2032)
+
+	var decoded CrashReport
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, "index out of range", decoded.Reason)
+	assert.True(t, decoded.Truncated)
+	assert.Nil(t, decoded.Mesh)
+	assert.NotEmpty(t, decoded.Stack, "the stack head is kept over everything else")
+	assert.Len(t, report.Logs, 64, "the original report is not modified")
+}
+
+func TestCrashReport_CompactKeepsSmallReport(t *testing.T) {
+	report := &CrashReport{Reason: "boom", State: "panic", Logs: []string{"a", "b"}}
+	data, err := report.Compact(2032)
+	require.NoError(t, err)
+
+	var decoded CrashReport
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.False(t, decoded.Truncated)
+	assert.Equal(t, []string{"a", "b"}, decoded.Logs)
+}
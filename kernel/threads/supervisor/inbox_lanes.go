@@ -0,0 +1,107 @@
+package supervisor
+
+import (
+	"sync"
+
+	"github.com/nmxmxh/inos_v1/kernel/threads/foundation"
+	sab_layout "github.com/nmxmxh/inos_v1/kernel/threads/sab"
+)
+
+// InboxLane is one priority lane of the SAB inbox. Each lane is a separate
+// ring so a backlog in one never blocks writes to another.
+type InboxLane int
+
+const (
+	LaneControl InboxLane = iota // Cancels, signals, lifecycle
+	LaneCompute                  // Ordinary jobs
+	LaneBulk                     // Particle and stream updates
+	laneCount
+)
+
+func (l InboxLane) String() string {
+	switch l {
+	case LaneControl:
+		return "control"
+	case LaneCompute:
+		return "compute"
+	case LaneBulk:
+		return "bulk"
+	default:
+		return "unknown"
+	}
+}
+
+// inboxLanes holds each lane's offset from the inbox base, ring size and
+// drain weight.
+var inboxLanes = [laneCount]struct {
+	offset, size, weight uint32
+}{
+	LaneControl: {sab_layout.OFFSET_INBOX_CONTROL - sab_layout.OFFSET_INBOX_BASE, sab_layout.SIZE_INBOX_CONTROL, sab_layout.INBOX_WEIGHT_CONTROL},
+	LaneCompute: {sab_layout.OFFSET_INBOX_COMPUTE - sab_layout.OFFSET_INBOX_BASE, sab_layout.SIZE_INBOX_COMPUTE, sab_layout.INBOX_WEIGHT_COMPUTE},
+	LaneBulk:    {sab_layout.OFFSET_INBOX_BULK - sab_layout.OFFSET_INBOX_BASE, sab_layout.SIZE_INBOX_BULK, sab_layout.INBOX_WEIGHT_BULK},
+}
+
+// InboxLaneForJob picks the lane for a job. Critical jobs take the control
+// lane; jobs opt into the bulk lane with the "lane" parameter set to "bulk".
+func InboxLaneForJob(job *foundation.Job) InboxLane {
+	if foundation.Priority(job.Priority) >= foundation.PriorityCritical {
+		return LaneControl
+	}
+	if lane, _ := job.Parameters["lane"].(string); lane == LaneBulk.String() {
+		return LaneBulk
+	}
+	return LaneCompute
+}
+
+// laneScheduler drains the inbox lanes by weighted round robin: each turn a
+// lane yields up to its weight in messages before the next lane is served.
+// The position survives between drains, so a small budget does not restart
+// at the control lane and starve the others.
+type laneScheduler struct {
+	mu      sync.Mutex
+	weights [laneCount]uint32
+	lane    InboxLane
+	served  uint32
+}
+
+func newLaneScheduler() *laneScheduler {
+	s := &laneScheduler{}
+	for lane := range inboxLanes {
+		s.weights[lane] = inboxLanes[lane].weight
+	}
+	return s
+}
+
+// drain reads up to budget messages, calling handle for each. It stops early
+// once every lane is empty. handle must not drain the same scheduler.
+func (s *laneScheduler) drain(budget int, read func(InboxLane) ([]byte, error), handle func(InboxLane, []byte)) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n, idle := 0, 0
+	for n < budget && idle < int(laneCount) {
+		if s.served >= s.weights[s.lane] {
+			s.advance()
+			continue
+		}
+		data, err := read(s.lane)
+		if err != nil {
+			return n, err
+		}
+		if data == nil {
+			s.advance()
+			idle++
+			continue
+		}
+		idle = 0
+		s.served++
+		handle(s.lane, data)
+		n++
+	}
+	return n, nil
+}
+
+func (s *laneScheduler) advance() {
+	s.lane = (s.lane + 1) % laneCount
+	s.served = 0
+}
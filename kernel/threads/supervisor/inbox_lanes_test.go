@@ -0,0 +1,85 @@
+package supervisor
+
+import (
+	"testing"
+
+	"github.com/nmxmxh/inos_v1/kernel/threads/foundation"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeLanes is an in-memory inbox with one queue per lane.
+type fakeLanes [laneCount][][]byte
+
+func (f *fakeLanes) fill(lane InboxLane, n int) {
+	for i := 0; i < n; i++ {
+		f[lane] = append(f[lane], []byte(lane.String()))
+	}
+}
+
+func (f *fakeLanes) read(lane InboxLane) ([]byte, error) {
+	if len(f[lane]) == 0 {
+		return nil, nil
+	}
+	msg := f[lane][0]
+	f[lane] = f[lane][1:]
+	return msg, nil
+}
+
+func TestLaneScheduler_ControlNotStarvedByBulk(t *testing.T) {
+	lanes := &fakeLanes{}
+	lanes.fill(LaneBulk, 1000)
+	lanes.fill(LaneControl, 3)
+
+	s := newLaneScheduler()
+	var order []InboxLane
+	n, err := s.drain(10, lanes.read, func(lane InboxLane, _ []byte) { order = append(order, lane) })
+	assert.NoError(t, err)
+	assert.Equal(t, 10, n)
+	assert.Equal(t, []InboxLane{LaneControl, LaneControl, LaneControl}, order[:3])
+	assert.Empty(t, lanes[LaneControl])
+}
+
+func TestLaneScheduler_WeightedShares(t *testing.T) {
+	lanes := &fakeLanes{}
+	for lane := LaneControl; lane < laneCount; lane++ {
+		lanes.fill(lane, 100)
+	}
+
+	s := newLaneScheduler()
+	counts := map[InboxLane]int{}
+	// Two full rounds at weights 8/4/1
+	_, err := s.drain(26, lanes.read, func(lane InboxLane, _ []byte) { counts[lane]++ })
+	assert.NoError(t, err)
+	assert.Equal(t, 16, counts[LaneControl])
+	assert.Equal(t, 8, counts[LaneCompute])
+	assert.Equal(t, 2, counts[LaneBulk])
+}
+
+func TestLaneScheduler_ResumesBetweenDrains(t *testing.T) {
+	lanes := &fakeLanes{}
+	lanes.fill(LaneControl, 100)
+	lanes.fill(LaneBulk, 100)
+
+	s := newLaneScheduler()
+	got := map[InboxLane]int{}
+	for i := 0; i < 9; i++ {
+		_, _ = s.drain(1, lanes.read, func(lane InboxLane, _ []byte) { got[lane]++ })
+	}
+	assert.Equal(t, 8, got[LaneControl])
+	assert.Equal(t, 1, got[LaneBulk], "single-message drains still rotate lanes")
+}
+
+func TestLaneScheduler_StopsWhenEmpty(t *testing.T) {
+	lanes := &fakeLanes{}
+	lanes.fill(LaneCompute, 2)
+
+	n, err := newLaneScheduler().drain(100, lanes.read, func(InboxLane, []byte) {})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+}
+
+func TestInboxLaneForJob(t *testing.T) {
+	assert.Equal(t, LaneCompute, InboxLaneForJob(&foundation.Job{}))
+	assert.Equal(t, LaneControl, InboxLaneForJob(&foundation.Job{Priority: int(foundation.PriorityCritical)}))
+	assert.Equal(t, LaneBulk, InboxLaneForJob(&foundation.Job{Parameters: map[string]interface{}{"lane": "bulk"}}))
+}
@@ -0,0 +1,297 @@
+package supervisor
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/nmxmxh/inos_v1/kernel/threads/foundation"
+)
+
+// Job journal encoding (little-endian, in the job history region):
+//
+//	0x00 magic      u32  jobJournalMagic
+//	0x04 slot count u32
+//	0x08 slot size  u32
+//	0x0C reserved   u32
+//	0x10 slots      jobJournalSlotSize each:
+//	     state u32, length u32, sequence u64, job JSON
+const (
+	jobJournalMagic      = 0x4C4E524A // "JRNL" little-endian
+	jobJournalHeaderSize = 16
+	jobJournalSlotSize   = 1024
+	jobJournalSlotHeader = 16
+
+	journalSlotFree      = 0
+	journalSlotPending   = 1
+	journalSlotTruncated = 2 // Pending, but the payload did not fit
+)
+
+// ErrJournalFull is returned when every journal slot holds an in-flight job.
+var ErrJournalFull = errors.New("job journal full")
+
+// JournalStore is the memory a JobJournal lives in; SABBridge implements it.
+type JournalStore interface {
+	ReadAt(offset uint32, dest []byte) error
+	WriteRaw(offset uint32, data []byte) error
+}
+
+// JournalTicket identifies one journaled job. The sequence number keeps a
+// late completion from clearing a slot that has since been reused.
+type JournalTicket struct {
+	Slot     uint32
+	Sequence uint64
+}
+
+// JournaledJob is an in-flight job read back from the journal.
+type JournaledJob struct {
+	Ticket    JournalTicket
+	Job       *foundation.Job
+	Truncated bool // Only the job's identity survived; it cannot be re-run
+}
+
+// journalEntry is the persisted form of a job. foundation.Job carries a
+// result channel and cannot be encoded directly.
+type journalEntry struct {
+	ID         string                 `json:"id"`
+	Type       string                 `json:"type"`
+	Operation  string                 `json:"op"`
+	Data       []byte                 `json:"data,omitempty"`
+	Parameters map[string]interface{} `json:"params,omitempty"`
+	Priority   int                    `json:"priority,omitempty"`
+	Deadline   time.Time              `json:"deadline,omitzero"`
+	Source     string                 `json:"source,omitempty"`
+	Trace      string                 `json:"trace,omitempty"`
+}
+
+type journalWaiter struct {
+	sequence uint64
+	result   chan<- *foundation.Result
+}
+
+// JobJournal mirrors in-flight jobs into shared memory so a standby
+// supervisor can re-dispatch them after failover. Waiters registered with
+// Record stay attached to their slot, so the caller receives the result of
+// whichever supervisor finishes the job first.
+type JobJournal struct {
+	mu       sync.Mutex
+	store    JournalStore
+	offset   uint32
+	slots    uint32
+	sequence uint64
+	free     []uint32
+	waiters  map[uint32]journalWaiter
+}
+
+// NewJobJournal opens the journal in [offset, offset+size). Slots left
+// pending by a previous owner are kept for Pending to return.
+func NewJobJournal(store JournalStore, offset, size uint32) (*JobJournal, error) {
+	if size < jobJournalHeaderSize+jobJournalSlotSize {
+		return nil, fmt.Errorf("job journal region of %d bytes is too small", size)
+	}
+
+	j := &JobJournal{
+		store:   store,
+		offset:  offset,
+		slots:   (size - jobJournalHeaderSize) / jobJournalSlotSize,
+		waiters: make(map[uint32]journalWaiter),
+	}
+
+	header := make([]byte, jobJournalHeaderSize)
+	if err := store.ReadAt(offset, header); err != nil {
+		return nil, err
+	}
+	if binary.LittleEndian.Uint32(header[0:]) != jobJournalMagic {
+		binary.LittleEndian.PutUint32(header[0:], jobJournalMagic)
+		binary.LittleEndian.PutUint32(header[4:], j.slots)
+		binary.LittleEndian.PutUint32(header[8:], jobJournalSlotSize)
+		if err := store.WriteRaw(offset, header); err != nil {
+			return nil, err
+		}
+		for slot := j.slots; slot > 0; slot-- {
+			j.free = append(j.free, slot-1)
+		}
+		return j, nil
+	}
+
+	slotHeader := make([]byte, jobJournalSlotHeader)
+	for slot := j.slots; slot > 0; slot-- {
+		if err := store.ReadAt(j.slotOffset(slot-1), slotHeader); err != nil {
+			return nil, err
+		}
+		if binary.LittleEndian.Uint32(slotHeader[0:]) == journalSlotFree {
+			j.free = append(j.free, slot-1)
+		}
+		if seq := binary.LittleEndian.Uint64(slotHeader[8:]); seq > j.sequence {
+			j.sequence = seq
+		}
+	}
+	return j, nil
+}
+
+func (j *JobJournal) slotOffset(slot uint32) uint32 {
+	return j.offset + jobJournalHeaderSize + slot*jobJournalSlotSize
+}
+
+// Record journals a job before it is dispatched. result, if not nil, must
+// be buffered; it receives the job's result from Complete and is closed.
+func (j *JobJournal) Record(job *foundation.Job, result chan<- *foundation.Result) (JournalTicket, error) {
+	entry := journalEntry{
+		ID:         job.ID,
+		Type:       job.Type,
+		Operation:  job.Operation,
+		Data:       job.Data,
+		Parameters: job.Parameters,
+		Priority:   job.Priority,
+		Deadline:   job.Deadline,
+		Source:     job.Source,
+		Trace:      job.TraceParent,
+	}
+	payload, err := json.Marshal(entry)
+	if err != nil {
+		return JournalTicket{}, err
+	}
+	state := uint32(journalSlotPending)
+	if len(payload) > jobJournalSlotSize-jobJournalSlotHeader {
+		// Keep the identity so the standby can fail the job instead of losing it
+		entry.Data, entry.Parameters = nil, nil
+		if payload, err = json.Marshal(entry); err != nil {
+			return JournalTicket{}, err
+		}
+		if len(payload) > jobJournalSlotSize-jobJournalSlotHeader {
+			return JournalTicket{}, fmt.Errorf("job %s does not fit a journal slot", job.ID)
+		}
+		state = journalSlotTruncated
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if len(j.free) == 0 {
+		return JournalTicket{}, ErrJournalFull
+	}
+	slot := j.free[len(j.free)-1]
+	j.sequence++
+	ticket := JournalTicket{Slot: slot, Sequence: j.sequence}
+
+	buf := make([]byte, jobJournalSlotHeader+len(payload))
+	binary.LittleEndian.PutUint32(buf[0:], state)
+	binary.LittleEndian.PutUint32(buf[4:], uint32(len(payload)))
+	binary.LittleEndian.PutUint64(buf[8:], ticket.Sequence)
+	copy(buf[jobJournalSlotHeader:], payload)
+	if err := j.store.WriteRaw(j.slotOffset(slot), buf); err != nil {
+		return JournalTicket{}, err
+	}
+
+	j.free = j.free[:len(j.free)-1]
+	if result != nil {
+		j.waiters[slot] = journalWaiter{sequence: ticket.Sequence, result: result}
+	}
+	return ticket, nil
+}
+
+// Complete clears a journaled job and hands res to its waiter. It returns
+// false if the ticket is stale, i.e. another supervisor already finished
+// the job.
+func (j *JobJournal) Complete(ticket JournalTicket, res *foundation.Result) bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	w, ok := j.clearLocked(ticket)
+	if !ok {
+		return false
+	}
+	if w.result != nil {
+		w.result <- res
+		close(w.result)
+	}
+	return true
+}
+
+// Release clears a journaled job without delivering a result, for jobs that
+// were never dispatched.
+func (j *JobJournal) Release(ticket JournalTicket) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.clearLocked(ticket)
+}
+
+func (j *JobJournal) clearLocked(ticket JournalTicket) (journalWaiter, bool) {
+	header := make([]byte, jobJournalSlotHeader)
+	if err := j.store.ReadAt(j.slotOffset(ticket.Slot), header); err != nil {
+		return journalWaiter{}, false
+	}
+	if binary.LittleEndian.Uint32(header[0:]) == journalSlotFree || binary.LittleEndian.Uint64(header[8:]) != ticket.Sequence {
+		return journalWaiter{}, false
+	}
+
+	binary.LittleEndian.PutUint32(header[0:], journalSlotFree)
+	if err := j.store.WriteRaw(j.slotOffset(ticket.Slot), header[:4]); err != nil {
+		return journalWaiter{}, false
+	}
+	j.free = append(j.free, ticket.Slot)
+
+	w, ok := j.waiters[ticket.Slot]
+	if !ok || w.sequence != ticket.Sequence {
+		return journalWaiter{}, true
+	}
+	delete(j.waiters, ticket.Slot)
+	return w, true
+}
+
+// Pending returns the jobs still in flight, oldest first.
+func (j *JobJournal) Pending() ([]JournaledJob, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	var pending []JournaledJob
+	buf := make([]byte, jobJournalSlotSize)
+	for slot := uint32(0); slot < j.slots; slot++ {
+		if err := j.store.ReadAt(j.slotOffset(slot), buf); err != nil {
+			return nil, err
+		}
+		state := binary.LittleEndian.Uint32(buf[0:])
+		if state == journalSlotFree {
+			continue
+		}
+		length := binary.LittleEndian.Uint32(buf[4:])
+		if length > jobJournalSlotSize-jobJournalSlotHeader {
+			continue
+		}
+		var entry journalEntry
+		if err := json.Unmarshal(buf[jobJournalSlotHeader:jobJournalSlotHeader+length], &entry); err != nil {
+			continue
+		}
+		pending = append(pending, JournaledJob{
+			Ticket: JournalTicket{Slot: slot, Sequence: binary.LittleEndian.Uint64(buf[8:])},
+			Job: &foundation.Job{
+				ID:          entry.ID,
+				Type:        entry.Type,
+				Operation:   entry.Operation,
+				Data:        entry.Data,
+				Parameters:  entry.Parameters,
+				Priority:    entry.Priority,
+				Deadline:    entry.Deadline,
+				Source:      entry.Source,
+				TraceParent: entry.Trace,
+			},
+			Truncated: state == journalSlotTruncated,
+		})
+	}
+
+	sort.Slice(pending, func(a, b int) bool {
+		return pending[a].Ticket.Sequence < pending[b].Ticket.Sequence
+	})
+	return pending, nil
+}
+
+// InFlight returns how many jobs are journaled.
+func (j *JobJournal) InFlight() int {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return int(j.slots) - len(j.free)
+}
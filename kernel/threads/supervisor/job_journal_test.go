@@ -0,0 +1,92 @@
+package supervisor
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/nmxmxh/inos_v1/kernel/threads/foundation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memStore is a JournalStore over a plain byte slice.
+type memStore []byte
+
+func (m memStore) ReadAt(offset uint32, dest []byte) error {
+	copy(dest, m[offset:])
+	return nil
+}
+
+func (m memStore) WriteRaw(offset uint32, data []byte) error {
+	copy(m[offset:], data)
+	return nil
+}
+
+func TestJobJournal_StandbyRedispatchesToOriginalWaiter(t *testing.T) {
+	store := make(memStore, 8*1024)
+	journal, err := NewJobJournal(store, 0, uint32(len(store)))
+	require.NoError(t, err)
+
+	waiter := make(chan *foundation.Result, 1)
+	traceparent := "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"
+	ticket, err := journal.Record(&foundation.Job{ID: "job-1", Type: "compute", Operation: "hash", Data: []byte("abc"), TraceParent: traceparent}, waiter)
+	require.NoError(t, err)
+	_, err = journal.Record(&foundation.Job{ID: "job-2", Type: "compute", Operation: "hash"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, journal.InFlight())
+
+	// The standby sees both jobs, in submission order
+	pending, err := journal.Pending()
+	require.NoError(t, err)
+	require.Len(t, pending, 2)
+	assert.Equal(t, "job-1", pending[0].Job.ID)
+	assert.Equal(t, []byte("abc"), pending[0].Job.Data)
+	assert.Equal(t, traceparent, pending[0].Job.TraceParent, "re-dispatched jobs stay in their trace")
+	assert.Equal(t, ticket, pending[0].Ticket)
+
+	assert.True(t, journal.Complete(pending[0].Ticket, &foundation.Result{JobID: "job-1", Success: true}))
+	res := <-waiter
+	assert.Equal(t, "job-1", res.JobID)
+
+	assert.False(t, journal.Complete(ticket, &foundation.Result{JobID: "job-1"}), "the failed supervisor's late result is stale")
+	assert.Equal(t, 1, journal.InFlight())
+}
+
+func TestJobJournal_ReopenKeepsPendingSlots(t *testing.T) {
+	store := make(memStore, 4*1024)
+	first, err := NewJobJournal(store, 0, uint32(len(store)))
+	require.NoError(t, err)
+	old, err := first.Record(&foundation.Job{ID: "job-1", Type: "compute", Operation: "hash"}, nil)
+	require.NoError(t, err)
+
+	reopened, err := NewJobJournal(store, 0, uint32(len(store)))
+	require.NoError(t, err)
+	assert.Equal(t, 1, reopened.InFlight())
+
+	next, err := reopened.Record(&foundation.Job{ID: "job-2", Type: "compute", Operation: "hash"}, nil)
+	require.NoError(t, err)
+	assert.NotEqual(t, old.Slot, next.Slot)
+	assert.Greater(t, next.Sequence, old.Sequence)
+}
+
+func TestJobJournal_OversizedPayloadIsTruncated(t *testing.T) {
+	store := make(memStore, 4*1024)
+	journal, err := NewJobJournal(store, 0, uint32(len(store)))
+	require.NoError(t, err)
+
+	_, err = journal.Record(&foundation.Job{ID: "big", Type: "compute", Operation: "hash", Data: bytes.Repeat([]byte{1}, 4096)}, nil)
+	require.NoError(t, err)
+
+	pending, err := journal.Pending()
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.True(t, pending[0].Truncated)
+	assert.Nil(t, pending[0].Job.Data)
+
+	for i := 0; i < 2; i++ {
+		_, err = journal.Record(&foundation.Job{ID: "fill", Type: "compute", Operation: "hash"}, nil)
+		require.NoError(t, err)
+	}
+	_, err = journal.Record(&foundation.Job{ID: "overflow", Type: "compute", Operation: "hash"}, nil)
+	assert.ErrorIs(t, err, ErrJournalFull)
+}
@@ -0,0 +1,151 @@
+package supervisor
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrRingFull is returned when a SAB ring has no room for a message, after
+// waiting for the consumer if a ring wait timeout is configured.
+var ErrRingFull = errors.New("ring buffer full")
+
+// RingID identifies one of the SAB message rings.
+type RingID int
+
+const (
+	RingInbox RingID = iota
+	RingOutboxHost
+	RingOutboxKernel
+	ringCount
+)
+
+func (r RingID) String() string {
+	switch r {
+	case RingInbox:
+		return "inbox"
+	case RingOutboxHost:
+		return "outbox_host"
+	case RingOutboxKernel:
+		return "outbox_kernel"
+	default:
+		return "unknown"
+	}
+}
+
+const (
+	// defaultStallEpochs is how many writes may land on a non-empty ring
+	// without the consumer advancing its head before the ring is stalled.
+	defaultStallEpochs = 64
+
+	// ringWaitInterval is the sleep between space checks while waiting.
+	ringWaitInterval = time.Millisecond
+)
+
+// RingStats reports backpressure on one ring.
+type RingStats struct {
+	Ring          string `json:"ring"`
+	Dropped       uint64 `json:"dropped"`        // Messages lost to a full ring
+	Waits         uint64 `json:"waits"`          // Writes that waited for space
+	PendingBytes  uint32 `json:"pending_bytes"`  // Unconsumed bytes at last write
+	CapacityBytes uint32 `json:"capacity_bytes"` // Data capacity of the ring, or inbox lane, last written
+	Stalled       bool   `json:"stalled"`        // Consumer is not draining
+	StalledEpochs uint32 `json:"stalled_epochs"` // Writes since the consumer last advanced
+}
+
+// ringMonitor tracks a ring from the producer side. Each write is one epoch;
+// the consumer is stalled when its head has not moved for stallEpochs epochs
+// while data is pending.
+type ringMonitor struct {
+	mu            sync.Mutex
+	dropped       uint64
+	waits         uint64
+	epoch         uint32
+	progressEpoch uint32
+	lastHead      uint32
+	pending       uint32
+	capacity      uint32
+	stalled       bool
+	stallEpochs   uint32
+}
+
+// observe records the ring state seen by a write and reports whether the
+// ring just became stalled.
+func (m *ringMonitor) observe(head, pending uint32) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.epoch++
+	m.pending = pending
+	if head != m.lastHead || pending == 0 {
+		m.lastHead = head
+		m.progressEpoch = m.epoch
+		m.stalled = false
+		return false
+	}
+
+	threshold := m.stallEpochs
+	if threshold == 0 {
+		threshold = defaultStallEpochs
+	}
+	if !m.stalled && m.epoch-m.progressEpoch >= threshold {
+		m.stalled = true
+		return true
+	}
+	return false
+}
+
+// setCapacity records the data capacity of the region about to be written.
+// Inbox lanes share a monitor but differ in size.
+func (m *ringMonitor) setCapacity(capacity uint32) {
+	m.mu.Lock()
+	m.capacity = capacity
+	m.mu.Unlock()
+}
+
+func (m *ringMonitor) recordWait() {
+	m.mu.Lock()
+	m.waits++
+	m.mu.Unlock()
+}
+
+func (m *ringMonitor) recordDrop() {
+	m.mu.Lock()
+	m.dropped++
+	m.mu.Unlock()
+}
+
+func (m *ringMonitor) stats(ring RingID) RingStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return RingStats{
+		Ring:          ring.String(),
+		Dropped:       m.dropped,
+		Waits:         m.waits,
+		PendingBytes:  m.pending,
+		CapacityBytes: m.capacity,
+		Stalled:       m.stalled,
+		StalledEpochs: m.epoch - m.progressEpoch,
+	}
+}
+
+// Occupancy returns the share of the ring that was unconsumed at the last
+// write, 0-1.
+func (rs RingStats) Occupancy() float64 {
+	if rs.CapacityBytes == 0 {
+		return 0
+	}
+	if rs.PendingBytes >= rs.CapacityBytes {
+		return 1
+	}
+	return float64(rs.PendingBytes) / float64(rs.CapacityBytes)
+}
+
+// ringPending returns the unconsumed bytes in a ring of the given data
+// capacity.
+func ringPending(head, tail, capacity uint32) uint32 {
+	if tail >= head {
+		return tail - head
+	}
+	return capacity - (head - tail)
+}
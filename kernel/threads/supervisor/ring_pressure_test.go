@@ -0,0 +1,61 @@
+package supervisor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRingMonitor_StallsWhenHeadStops(t *testing.T) {
+	m := &ringMonitor{stallEpochs: 3}
+
+	assert.False(t, m.observe(0, 10))
+	assert.False(t, m.observe(0, 20))
+	assert.True(t, m.observe(0, 30), "third write without head movement stalls")
+	assert.False(t, m.observe(0, 40), "stall is reported once")
+
+	stats := m.stats(RingOutboxHost)
+	assert.Equal(t, "outbox_host", stats.Ring)
+	assert.True(t, stats.Stalled)
+	assert.Equal(t, uint32(4), stats.StalledEpochs)
+	assert.Equal(t, uint32(40), stats.PendingBytes)
+
+	assert.False(t, m.observe(24, 30), "head movement clears the stall")
+	assert.False(t, m.stats(RingOutboxHost).Stalled)
+}
+
+func TestRingMonitor_EmptyRingNeverStalls(t *testing.T) {
+	m := &ringMonitor{stallEpochs: 2}
+	for i := 0; i < 10; i++ {
+		assert.False(t, m.observe(8, 0))
+	}
+	assert.False(t, m.stats(RingInbox).Stalled)
+}
+
+func TestRingMonitor_Counters(t *testing.T) {
+	m := &ringMonitor{}
+	m.recordWait()
+	m.recordDrop()
+	m.recordDrop()
+
+	stats := m.stats(RingOutboxKernel)
+	assert.Equal(t, uint64(1), stats.Waits)
+	assert.Equal(t, uint64(2), stats.Dropped)
+}
+
+func TestRingPending(t *testing.T) {
+	assert.Equal(t, uint32(0), ringPending(5, 5, 100))
+	assert.Equal(t, uint32(20), ringPending(10, 30, 100))
+	assert.Equal(t, uint32(30), ringPending(80, 10, 100))
+}
+
+func TestRingMonitor_Occupancy(t *testing.T) {
+	m := &ringMonitor{}
+	assert.Zero(t, m.stats(RingInbox).Occupancy(), "capacity unknown before the first write")
+
+	m.setCapacity(200)
+	m.observe(0, 50)
+	stats := m.stats(RingInbox)
+	assert.Equal(t, uint32(200), stats.CapacityBytes)
+	assert.InDelta(t, 0.25, stats.Occupancy(), 1e-9)
+}
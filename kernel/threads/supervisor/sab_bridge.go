@@ -71,9 +71,31 @@ type SABBridge struct {
 	epochWaitersMu      sync.Mutex
 	epochWaiters        map[uint32]chan int32
 
+	// Background mode: while the page is hidden, epoch changes are
+	// coalesced to the latest value per index (guarded by epochWaitersMu)
+	background     bool
+	bufferedEpochs map[uint32]int32
+
 	// Stability Monitor: Tracks frame-to-frame latency to detect throttling
 	lastFrameTime time.Time
 	frameLatency  time.Duration
+
+	// Per-frame time attribution; closed on every physics pulse
+	frameBudget atomic.Pointer[foundation.FrameBudget]
+
+	// Named regions above the static layout (opened on first use)
+	regionAlloc *sab_layout.RegionAllocator
+	regionMu    sync.Mutex
+
+	// Ring backpressure: per-ring drop/stall tracking, an optional bounded
+	// wait for space, and a callback fired when a consumer stalls.
+	// ringWaitTimeout and onRingStall are guarded by mu.
+	rings           [ringCount]ringMonitor
+	ringWaitTimeout time.Duration
+	onRingStall     func(RingStats)
+
+	// Weighted drain position across the inbox priority lanes
+	inboxSched *laneScheduler
 }
 
 const defaultViewCacheMax = 64
@@ -101,6 +123,7 @@ func NewSABBridge(replica []byte, inboxOffset, outboxHostOffset, outboxKernelOff
 		viewCacheMax:       defaultViewCacheMax,
 		cleanupThreshold:   100, // Cleanup every 100 epochs of activity
 		epochWaiters:       make(map[uint32]chan int32),
+		inboxSched:         newLaneScheduler(),
 	}
 
 	// Cache JS values once to prevent memory leak
@@ -283,7 +306,8 @@ func (sb *SABBridge) cleanupStaleJobs() {
 	}
 }
 
-// WriteJob writes a job to SAB inbox (non-blocking)
+// WriteJob writes a job to the SAB inbox lane chosen by InboxLaneForJob
+// (non-blocking)
 func (sb *SABBridge) WriteJob(job *foundation.Job) error {
 	sb.mu.Lock()
 	defer sb.mu.Unlock()
@@ -293,13 +317,31 @@ func (sb *SABBridge) WriteJob(job *foundation.Job) error {
 		return fmt.Errorf("failed to serialize job: %w", err)
 	}
 
-	if err := sb.writeToSAB(sb.inboxOffset, sab_layout.SIZE_INBOX_TOTAL, data); err != nil {
-		return fmt.Errorf("failed to write to inbox: %w", err)
+	lane := InboxLaneForJob(job)
+	if err := sb.writeToSAB(sb.laneOffset(lane), inboxLanes[lane].size, data); err != nil {
+		return fmt.Errorf("failed to write to %s inbox: %w", lane, err)
 	}
 
 	return nil
 }
 
+// laneOffset returns the byte offset of a lane's ring.
+func (sb *SABBridge) laneOffset(lane InboxLane) uint32 {
+	return sb.inboxOffset + inboxLanes[lane].offset
+}
+
+// DrainInbox reads up to budget messages from the inbox lanes with weighted
+// fairness and passes each to handle. It returns how many were read.
+func (sb *SABBridge) DrainInbox(budget int, handle func(InboxLane, []byte)) (int, error) {
+	return sb.inboxSched.drain(budget, sb.ReadInboxLane, handle)
+}
+
+// ReadInboxLane reads the next message from one inbox lane, or nil if the
+// lane is empty.
+func (sb *SABBridge) ReadInboxLane(lane InboxLane) ([]byte, error) {
+	return sb.readFromSAB(sb.laneOffset(lane), inboxLanes[lane].size)
+}
+
 // PollCompletion waits for job completion using signal-based blocking
 func (sb *SABBridge) PollCompletion(timeout time.Duration) (bool, error) {
 	startEpoch := sb.readEpoch()
@@ -358,10 +400,16 @@ func (sb *SABBridge) ReadAtomicI32(epochIndex uint32) int32 {
 	return val
 }
 
+// WriteInbox writes raw data to the compute lane.
 func (sb *SABBridge) WriteInbox(data []byte) error {
+	return sb.WriteInboxLane(LaneCompute, data)
+}
+
+// WriteInboxLane writes raw data to the given inbox lane.
+func (sb *SABBridge) WriteInboxLane(lane InboxLane, data []byte) error {
 	sb.mu.Lock()
 	defer sb.mu.Unlock()
-	return sb.writeToSAB(sb.inboxOffset, sab_layout.SIZE_INBOX_TOTAL, data)
+	return sb.writeToSAB(sb.laneOffset(lane), inboxLanes[lane].size, data)
 }
 
 func (sb *SABBridge) WriteOutbox(data []byte) error {
@@ -540,15 +588,30 @@ func (sb *SABBridge) getEpochWaiter(epochIndex uint32) chan int32 {
 func (sb *SABBridge) PushEpochChange(epochIndex uint32, value int32) {
 	atomic.StoreUint32(&sb.epochWatcherEnabled, 1)
 
+	sb.epochWaitersMu.Lock()
+	if sb.background {
+		sb.bufferedEpochs[epochIndex] = value
+		sb.epochWaitersMu.Unlock()
+		return
+	}
+	sb.epochWaitersMu.Unlock()
+
 	// Update Stability Monitor if this is a physics pulse (Index 12)
 	if epochIndex == sab_layout.IDX_BIRD_EPOCH {
 		now := time.Now()
 		if !sb.lastFrameTime.IsZero() {
 			sb.frameLatency = now.Sub(sb.lastFrameTime)
+			sb.frameBudget.Load().EndFrame(sb.frameLatency)
 		}
 		sb.lastFrameTime = now
 	}
 
+	sb.deliverEpoch(epochIndex, value)
+}
+
+// deliverEpoch hands the latest value to the index's waiter, replacing one
+// that was never picked up.
+func (sb *SABBridge) deliverEpoch(epochIndex uint32, value int32) {
 	ch := sb.getEpochWaiter(epochIndex)
 
 	select {
@@ -568,6 +631,35 @@ func (sb *SABBridge) PushEpochChange(epochIndex uint32, value int32) {
 	}
 }
 
+// SetBackground switches epoch delivery for a hidden page. While in
+// background, each index keeps only its latest value, so waiters are not
+// woken for every change a throttled page replays in a burst. Leaving
+// background delivers the buffered values and returns how many indexes
+// had changed.
+func (sb *SABBridge) SetBackground(background bool) int {
+	sb.epochWaitersMu.Lock()
+	if sb.background == background {
+		sb.epochWaitersMu.Unlock()
+		return 0
+	}
+	sb.background = background
+	if background {
+		sb.bufferedEpochs = make(map[uint32]int32)
+		sb.epochWaitersMu.Unlock()
+		return 0
+	}
+	buffered := sb.bufferedEpochs
+	sb.bufferedEpochs = nil
+	// The gap since the last pulse is the time spent hidden, not a frame.
+	sb.lastFrameTime = time.Time{}
+	sb.epochWaitersMu.Unlock()
+
+	for index, value := range buffered {
+		sb.deliverEpoch(index, value)
+	}
+	return len(buffered)
+}
+
 // NotifyEpochWaiters wakes up threads waiting on the given epoch index.
 func (sb *SABBridge) NotifyEpochWaiters(epochIndex uint32) int {
 	if !sb.jsInitialized || sb.jsInt32View.IsUndefined() {
@@ -590,14 +682,59 @@ func (sb *SABBridge) SignalOutboxKernel() {
 	sb.SignalEpoch(sab_layout.IDX_OUTBOX_KERNEL_DIRTY)
 }
 
-// writeToSAB writes raw data to SAB Inbox/Outbox using MPSC pattern
+// SetRingWaitTimeout enables bounded backpressure: a write to a full ring
+// waits up to d for the consumer to free space before the message is
+// dropped. Zero restores the fail-fast behavior.
+func (sb *SABBridge) SetRingWaitTimeout(d time.Duration) {
+	sb.mu.Lock()
+	sb.ringWaitTimeout = d
+	sb.mu.Unlock()
+}
+
+// SetRingStallHandler registers fn to be called when a ring's consumer stops
+// draining it. fn runs on its own goroutine.
+func (sb *SABBridge) SetRingStallHandler(fn func(RingStats)) {
+	sb.mu.Lock()
+	sb.onRingStall = fn
+	sb.mu.Unlock()
+}
+
+// RingStats returns backpressure counters for the inbox and both outboxes.
+func (sb *SABBridge) RingStats() []RingStats {
+	out := make([]RingStats, 0, ringCount)
+	for ring := RingInbox; ring < ringCount; ring++ {
+		out = append(out, sb.rings[ring].stats(ring))
+	}
+	return out
+}
+
+func (sb *SABBridge) ringFor(baseOffset uint32) RingID {
+	switch baseOffset {
+	case sb.outboxHostOffset:
+		return RingOutboxHost
+	case sb.outboxKernelOffset:
+		return RingOutboxKernel
+	default:
+		return RingInbox
+	}
+}
+
+// writeToSAB writes raw data to SAB Inbox/Outbox using MPSC pattern.
+// Callers hold sb.mu.
 func (sb *SABBridge) writeToSAB(baseOffset, regionSize uint32, data []byte) error {
+	defer sb.frameBudget.Load().Track(foundation.FrameSubsystemSAB)()
+
 	const HeaderSize = 8
 	DataCapacity := regionSize - HeaderSize
 
 	msgLen := uint32(len(data))
 	totalLen := 4 + msgLen
 
+	ring := sb.ringFor(baseOffset)
+	monitor := &sb.rings[ring]
+	observed := false
+	var deadline time.Time
+
 	// 1. Reserve space atomically (MPSC)
 	var reservedTail uint32
 	for {
@@ -615,8 +752,28 @@ func (sb *SABBridge) writeToSAB(baseOffset, regionSize uint32, data []byte) erro
 			available = (head - tail) - 1
 		}
 
+		if !observed {
+			observed = true
+			monitor.setCapacity(DataCapacity)
+			if monitor.observe(head, ringPending(head, tail, DataCapacity)) && sb.onRingStall != nil {
+				go sb.onRingStall(monitor.stats(ring))
+			}
+		}
+
 		if available < totalLen {
-			return fmt.Errorf("ring buffer full")
+			// Backpressure: give the consumer a bounded window to drain
+			if sb.ringWaitTimeout > 0 {
+				if deadline.IsZero() {
+					deadline = time.Now().Add(sb.ringWaitTimeout)
+					monitor.recordWait()
+				}
+				if time.Now().Before(deadline) {
+					time.Sleep(ringWaitInterval)
+					continue
+				}
+			}
+			monitor.recordDrop()
+			return fmt.Errorf("%w: %s", ErrRingFull, ring)
 		}
 
 		newTail := (tail + totalLen) % DataCapacity
@@ -725,6 +882,8 @@ func (sb *SABBridge) writeRawRing(baseOffset, headerSize, capacity, writeIdx uin
 }
 
 func (sb *SABBridge) readFromSAB(baseOffset, regionSize uint32) ([]byte, error) {
+	defer sb.frameBudget.Load().Track(foundation.FrameSubsystemSAB)()
+
 	const HeaderSize = 8
 	DataCapacity := regionSize - HeaderSize
 
@@ -1175,6 +1334,18 @@ func (sb *SABBridge) serializeJob(job *foundation.Job) ([]byte, error) {
 	}
 
 	req.SetInput(job.Data)
+
+	// Sandbox limits: the compute engine lowers its unit limits to these
+	req.SetFuelLimit(foundation.UintParam(job.Parameters[foundation.JobParamFuelLimit]))
+	req.SetMemoryLimit(foundation.UintParam(job.Parameters[foundation.JobParamMemoryLimit]))
+	if !job.Deadline.IsZero() {
+		// A past deadline still sends 1ms rather than 0, which means no limit
+		timeoutMs := time.Until(job.Deadline).Milliseconds()
+		if timeoutMs < 1 {
+			timeoutMs = 1
+		}
+		req.SetTimeout(uint64(timeoutMs))
+	}
 	return msg.Marshal()
 }
 
@@ -1248,3 +1419,14 @@ func (sb *SABBridge) DeserializeResult(data []byte) *foundation.Result {
 func (sb *SABBridge) GetFrameLatency() time.Duration {
 	return sb.frameLatency
 }
+
+// SetFrameBudget attaches the budget that ring traffic is charged to and
+// that physics pulses close frames on. Nil detaches it.
+func (sb *SABBridge) SetFrameBudget(fb *foundation.FrameBudget) {
+	sb.frameBudget.Store(fb)
+}
+
+// FrameBudget returns the attached frame budget, or nil.
+func (sb *SABBridge) FrameBudget() *foundation.FrameBudget {
+	return sb.frameBudget.Load()
+}
@@ -33,9 +33,11 @@ func createTestSABBridge() (*SABBridge, []byte) {
 	)
 
 	// Initialize ring buffer pointers
-	// Inbox Head/Tail
-	binary.LittleEndian.PutUint32(sab[sab_layout.OFFSET_INBOX_BASE:], 0)   // Head
-	binary.LittleEndian.PutUint32(sab[sab_layout.OFFSET_INBOX_BASE+4:], 0) // Tail
+	// Inbox lane Head/Tail
+	for _, base := range []uint32{sab_layout.OFFSET_INBOX_CONTROL, sab_layout.OFFSET_INBOX_COMPUTE, sab_layout.OFFSET_INBOX_BULK} {
+		binary.LittleEndian.PutUint32(sab[base:], 0)   // Head
+		binary.LittleEndian.PutUint32(sab[base+4:], 0) // Tail
+	}
 
 	// Outbox Host Head/Tail
 	binary.LittleEndian.PutUint32(sab[sab_layout.OFFSET_OUTBOX_HOST_BASE:], 0)   // Head
@@ -64,10 +66,10 @@ func TestSABBridge_WriteJob(t *testing.T) {
 	err := bridge.WriteJob(job)
 	require.NoError(t, err)
 
-	// Verify data written to inbox
+	// Verify data written to the compute lane
 	// Head should still be 0, Tail should be advanced
-	inboxHead := binary.LittleEndian.Uint32(sab[sab_layout.OFFSET_INBOX_BASE:])
-	inboxTail := binary.LittleEndian.Uint32(sab[sab_layout.OFFSET_INBOX_BASE+4:])
+	inboxHead := binary.LittleEndian.Uint32(sab[sab_layout.OFFSET_INBOX_COMPUTE:])
+	inboxTail := binary.LittleEndian.Uint32(sab[sab_layout.OFFSET_INBOX_COMPUTE+4:])
 
 	assert.Equal(t, uint32(0), inboxHead)
 	assert.Greater(t, inboxTail, uint32(0))
@@ -83,6 +85,30 @@ func TestSABBridge_WriteJob(t *testing.T) {
 	// Let's check unified.go or similar usage.
 }
 
+func TestSABBridge_SerializeJobCarriesSandboxLimits(t *testing.T) {
+	bridge, _ := createTestSABBridge()
+
+	data, err := bridge.serializeJob(&foundation.Job{
+		ID:        "job-limited",
+		Type:      "data",
+		Operation: "sort",
+		Deadline:  time.Now().Add(2 * time.Second),
+		Parameters: map[string]interface{}{
+			foundation.JobParamFuelLimit:   float64(5000),
+			foundation.JobParamMemoryLimit: uint64(1 << 20),
+		},
+	})
+	require.NoError(t, err)
+
+	msg, err := capnp.Unmarshal(data)
+	require.NoError(t, err)
+	req, err := compute.ReadRootCompute_JobRequest(msg)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(5000), req.FuelLimit())
+	assert.Equal(t, uint64(1<<20), req.MemoryLimit())
+	assert.InDelta(t, 2000, float64(req.Timeout()), 100)
+}
+
 func TestSABBridge_Signaling(t *testing.T) {
 	bridge, sab := createTestSABBridge()
 
@@ -213,6 +239,28 @@ func TestSABBridge_ConcurrentAccess(t *testing.T) {
 	wg.Wait()
 }
 
+func TestSABBridge_DrainInboxPrefersControl(t *testing.T) {
+	bridge, _ := createTestSABBridge()
+
+	for i := 0; i < 20; i++ {
+		require.NoError(t, bridge.WriteInboxLane(LaneBulk, []byte(fmt.Sprintf("bulk-%d", i))))
+	}
+	require.NoError(t, bridge.WriteInboxLane(LaneControl, []byte("cancel")))
+
+	var first []byte
+	n, err := bridge.DrainInbox(1, func(lane InboxLane, data []byte) {
+		assert.Equal(t, LaneControl, lane)
+		first = data
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, "cancel", string(first))
+
+	n, err = bridge.DrainInbox(100, func(lane InboxLane, _ []byte) { assert.Equal(t, LaneBulk, lane) })
+	require.NoError(t, err)
+	assert.Equal(t, 20, n)
+}
+
 func TestSABBridge_InboxAndRawOps(t *testing.T) {
 	bridge, sab := createTestSABBridge()
 
@@ -221,9 +269,9 @@ func TestSABBridge_InboxAndRawOps(t *testing.T) {
 	err := bridge.WriteInbox(data)
 	require.NoError(t, err)
 
-	// Verify manually
-	inboxHead := binary.LittleEndian.Uint32(sab[sab_layout.OFFSET_INBOX_BASE:])
-	inboxTail := binary.LittleEndian.Uint32(sab[sab_layout.OFFSET_INBOX_BASE+4:])
+	// Verify manually: raw writes go to the compute lane
+	inboxHead := binary.LittleEndian.Uint32(sab[sab_layout.OFFSET_INBOX_COMPUTE:])
+	inboxTail := binary.LittleEndian.Uint32(sab[sab_layout.OFFSET_INBOX_COMPUTE+4:])
 	assert.Equal(t, uint32(0), inboxHead)
 	assert.Greater(t, inboxTail, uint32(0))
 
@@ -0,0 +1,60 @@
+//go:build wasm
+
+package supervisor
+
+import (
+	sab_layout "github.com/nmxmxh/inos_v1/kernel/threads/sab"
+)
+
+// regionAllocator opens the dynamic region registry on first use so bridges
+// that never allocate do not touch it.
+func (sb *SABBridge) regionAllocator() (*sab_layout.RegionAllocator, error) {
+	sb.regionMu.Lock()
+	defer sb.regionMu.Unlock()
+
+	if sb.regionAlloc == nil {
+		alloc, err := sab_layout.NewRegionAllocator(sb, sb.sabSize)
+		if err != nil {
+			return nil, err
+		}
+		sb.regionAlloc = alloc
+	}
+	return sb.regionAlloc, nil
+}
+
+// AllocateRegion reserves a named scratch region above the static layout.
+// Alignment must be a power of two; 0 aligns to a cache line.
+func (sb *SABBridge) AllocateRegion(name string, size, alignment uint32) (sab_layout.DynamicRegion, error) {
+	alloc, err := sb.regionAllocator()
+	if err != nil {
+		return sab_layout.DynamicRegion{}, err
+	}
+	return alloc.Allocate(name, size, alignment)
+}
+
+// FreeRegion releases the dynamic region starting at offset.
+func (sb *SABBridge) FreeRegion(offset uint32) error {
+	alloc, err := sb.regionAllocator()
+	if err != nil {
+		return err
+	}
+	return alloc.Free(offset)
+}
+
+// LookupRegion finds a dynamic region by name.
+func (sb *SABBridge) LookupRegion(name string) (sab_layout.DynamicRegion, bool) {
+	alloc, err := sb.regionAllocator()
+	if err != nil {
+		return sab_layout.DynamicRegion{}, false
+	}
+	return alloc.Lookup(name)
+}
+
+// DynamicRegions lists every dynamic region ordered by offset.
+func (sb *SABBridge) DynamicRegions() []sab_layout.DynamicRegion {
+	alloc, err := sb.regionAllocator()
+	if err != nil {
+		return nil
+	}
+	return alloc.Regions()
+}
@@ -583,6 +583,22 @@ func (us *UnifiedSupervisor) ExecuteJob(job *foundation.Job) *foundation.Result
 	}
 }
 
+// ResultTimeout is how long a unit waits for its module to answer job: the
+// unit's own timeout, cut short by the job's sandbox deadline.
+func ResultTimeout(job *foundation.Job, fallback time.Duration) time.Duration {
+	if job.Deadline.IsZero() {
+		return fallback
+	}
+	until := time.Until(job.Deadline)
+	if until < 0 {
+		return 0
+	}
+	if until < fallback {
+		return until
+	}
+	return fallback
+}
+
 func (us *UnifiedSupervisor) recordLatency(latency time.Duration) {
 	us.mu.Lock()
 	defer us.mu.Unlock()
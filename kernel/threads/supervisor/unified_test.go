@@ -541,3 +541,14 @@ func TestUnifiedSupervisor_Throughput(t *testing.T) {
 	t.Logf("Throughput: %.2f jobs/sec", throughput)
 	assert.Greater(t, throughput, 100.0, "Should handle > 100 jobs/sec")
 }
+
+func TestResultTimeout_HonoursJobDeadline(t *testing.T) {
+	assert.Equal(t, 10*time.Second, supervisor.ResultTimeout(&foundation.Job{}, 10*time.Second))
+
+	soon := supervisor.ResultTimeout(&foundation.Job{Deadline: time.Now().Add(time.Second)}, 10*time.Second)
+	assert.LessOrEqual(t, soon, time.Second)
+	assert.Greater(t, soon, 500*time.Millisecond)
+
+	assert.Equal(t, 10*time.Second, supervisor.ResultTimeout(&foundation.Job{Deadline: time.Now().Add(time.Hour)}, 10*time.Second))
+	assert.Equal(t, time.Duration(0), supervisor.ResultTimeout(&foundation.Job{Deadline: time.Now().Add(-time.Second)}, 10*time.Second))
+}
@@ -2,8 +2,6 @@ package units
 
 import (
 	"context"
-	"encoding/binary"
-	"math"
 	"time"
 
 	"github.com/nmxmxh/inos_v1/kernel/core/mesh/common"
@@ -92,23 +90,22 @@ func (s *AnalyticsSupervisor) updateGlobalMetrics() {
 
 	// 2. Prepare SAB buffer
 	// Structure: [TotalStorage(8), TotalCompute(8), GlobalOps(8), NodeCount(4)]
-	var buf [28]byte
+	buf := make(sab_layout.Codec, 28)
 
 	// Total Storage (Bytes)
-	binary.LittleEndian.PutUint64(buf[0:8], metrics.TotalStorageBytes)
+	buf.PutUint64(0, metrics.TotalStorageBytes)
 
 	// Total Compute (GFLOPS) - Use Float64 for precision
-	binary.LittleEndian.PutUint64(buf[8:16], math.Float64bits(float64(metrics.TotalComputeGFLOPS)))
+	buf.PutFloat64(8, float64(metrics.TotalComputeGFLOPS))
 
 	// Global Ops/Sec - Use Float64 for precision
-	binary.LittleEndian.PutUint64(buf[16:24], math.Float64bits(float64(metrics.GlobalOpsPerSec)))
+	buf.PutFloat64(16, float64(metrics.GlobalOpsPerSec))
 
 	// Node Count - Report exactly what the provider gives us
-	nodeCount := metrics.ActiveNodeCount
-	binary.LittleEndian.PutUint32(buf[24:28], nodeCount)
+	buf.PutUint32(24, metrics.ActiveNodeCount)
 
 	// 3. Write to SAB
-	if err := s.bridge.WriteRaw(sab_layout.OFFSET_GLOBAL_ANALYTICS, buf[:]); err != nil {
+	if err := s.bridge.WriteRaw(sab_layout.OFFSET_GLOBAL_ANALYTICS, buf); err != nil {
 		utils.Error("Failed to write global analytics to SAB", utils.Err(err))
 		return
 	}
@@ -60,7 +60,7 @@ func (s *CryptoSupervisor) ExecuteJob(job *foundation.Job) *foundation.Result {
 
 	s.bridge.SignalInbox()
 
-	timer := time.NewTimer(5 * time.Second)
+	timer := time.NewTimer(supervisor.ResultTimeout(job, 5*time.Second))
 	defer timer.Stop()
 
 	select {
@@ -71,7 +71,7 @@ func (s *DataSupervisor) ExecuteJob(job *foundation.Job) *foundation.Result {
 
 	s.bridge.SignalInbox()
 
-	timer := time.NewTimer(10 * time.Second)
+	timer := time.NewTimer(supervisor.ResultTimeout(job, 10*time.Second))
 	defer timer.Stop()
 
 	select {
@@ -65,7 +65,7 @@ func (s *GPUSupervisor) ExecuteJob(job *foundation.Job) *foundation.Result {
 	s.bridge.SignalInbox()
 
 	// 5. Wait for result asynchronously (via channel)
-	timer := time.NewTimer(30 * time.Second)
+	timer := time.NewTimer(supervisor.ResultTimeout(job, 30*time.Second))
 	defer timer.Stop()
 
 	select {
@@ -60,7 +60,7 @@ func (s *ImageSupervisor) ExecuteJob(job *foundation.Job) *foundation.Result {
 
 	s.bridge.SignalInbox()
 
-	timer := time.NewTimer(15 * time.Second)
+	timer := time.NewTimer(supervisor.ResultTimeout(job, 15*time.Second))
 	defer timer.Stop()
 
 	select {
@@ -129,7 +129,7 @@ func (ss *StorageSupervisor) ExecuteJob(job *foundation.Job) *foundation.Result
 	}
 
 	// 5. Wait for result asynchronously (via channel)
-	timer := time.NewTimer(10 * time.Second)
+	timer := time.NewTimer(supervisor.ResultTimeout(job, 10*time.Second))
 	defer timer.Stop()
 
 	select {
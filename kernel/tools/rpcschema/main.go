@@ -0,0 +1,30 @@
+// Command rpcschema prints the machine-readable mesh RPC protocol description.
+//
+//	go run ./tools/rpcschema > ../protocols/rpc/mesh_rpc.json
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh"
+)
+
+func main() {
+	out, err := render()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "rpcschema: %v\n", err)
+		os.Exit(1)
+	}
+	os.Stdout.Write(out)
+}
+
+// render returns the description exactly as it is checked in.
+func render() ([]byte, error) {
+	out, err := json.MarshalIndent(mesh.RPCProtocolDescription(), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+const checkedIn = "../../../protocols/rpc/mesh_rpc.json"
+
+func TestCheckedInDescriptionIsCurrent(t *testing.T) {
+	want, err := render()
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	got, err := os.ReadFile(checkedIn)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", checkedIn, err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("%s is stale; regenerate it with: go run ./tools/rpcschema > ../protocols/rpc/mesh_rpc.json", checkedIn)
+	}
+}
@@ -0,0 +1,64 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"syscall/js"
+
+	"github.com/nmxmxh/inos_v1/kernel/utils"
+	"github.com/nmxmxh/inos_v1/kernel/utils/tracing"
+)
+
+// traceExportKind tags OTLP batches in the host outbox so the host can tell
+// them apart from job and command results.
+const traceExportKind = "otlp_traces"
+
+// traceExportBatch bounds one outbox frame to a few tens of KB.
+const traceExportBatch = 64
+
+// traceExport is the host outbox envelope around one OTLP/JSON batch.
+type traceExport struct {
+	Kind    string          `json:"kind"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// startTraceExport streams finished spans to the host outbox, where the
+// host can forward them to any OTLP collector.
+func (k *Kernel) startTraceExport() {
+	tracing.Default().SetSampleRate(k.config.TraceSampleRate)
+	if k.config.TraceExportInterval <= 0 {
+		return
+	}
+
+	exporter := tracing.ExporterFunc(func(ctx context.Context, payload []byte) error {
+		frame, err := json.Marshal(traceExport{Kind: traceExportKind, Payload: payload})
+		if err != nil {
+			return err
+		}
+		// Resolved per batch: failover swaps the supervisor
+		return k.supervisor.GetBridge().WriteOutbox(frame)
+	})
+	go tracing.RunExporter(k.ctx, tracing.Default(), exporter, k.config.TraceExportInterval, traceExportBatch, func(err error) {
+		k.logger.Debug("Trace export dropped a batch", utils.Err(err))
+	})
+}
+
+// jsGetTraces returns the buffered spans as OTLP/JSON without draining them:
+// getTraces().
+func jsGetTraces(this js.Value, args []js.Value) interface{} {
+	rec := tracing.Default()
+	spans := rec.Snapshot()
+	payload, err := tracing.EncodeOTLP(rec.Service(), spans)
+	if err != nil {
+		return js.ValueOf(map[string]interface{}{"error": err.Error()})
+	}
+	return js.ValueOf(map[string]interface{}{
+		"success": true,
+		"count":   len(spans),
+		"dropped": float64(rec.Dropped()),
+		"otlp":    string(payload),
+	})
+}
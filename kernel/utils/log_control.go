@@ -0,0 +1,73 @@
+package utils
+
+import "sync"
+
+// logLevels overrides logger levels at runtime, globally or per component,
+// so hosts can turn on debug output without rebuilding.
+type logLevels struct {
+	mu         sync.RWMutex
+	global     *LogLevel
+	components map[string]LogLevel
+}
+
+var levelOverrides = logLevels{components: make(map[string]LogLevel)}
+
+// lookup returns the override for component: its own, else the global one.
+func (o *logLevels) lookup(component string) (LogLevel, bool) {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	if level, ok := o.components[component]; ok {
+		return level, true
+	}
+	if o.global != nil {
+		return *o.global, true
+	}
+	return 0, false
+}
+
+// SetLogLevel overrides the level of every logger without a component
+// override.
+func SetLogLevel(level LogLevel) {
+	levelOverrides.mu.Lock()
+	levelOverrides.global = &level
+	levelOverrides.mu.Unlock()
+}
+
+// SetComponentLogLevel overrides the level of loggers for one component.
+func SetComponentLogLevel(component string, level LogLevel) {
+	levelOverrides.mu.Lock()
+	levelOverrides.components[component] = level
+	levelOverrides.mu.Unlock()
+}
+
+// ClearLogLevels removes every override; loggers return to their own level.
+func ClearLogLevels() {
+	levelOverrides.mu.Lock()
+	levelOverrides.global = nil
+	levelOverrides.components = make(map[string]LogLevel)
+	levelOverrides.mu.Unlock()
+}
+
+// LogLevels returns the global override, if any, and the component
+// overrides.
+func LogLevels() (global *LogLevel, components map[string]LogLevel) {
+	levelOverrides.mu.RLock()
+	defer levelOverrides.mu.RUnlock()
+	if levelOverrides.global != nil {
+		level := *levelOverrides.global
+		global = &level
+	}
+	components = make(map[string]LogLevel, len(levelOverrides.components))
+	for component, level := range levelOverrides.components {
+		components[component] = level
+	}
+	return global, components
+}
+
+// enabled reports whether the logger writes level, after overrides.
+func (l *Logger) enabled(level LogLevel) bool {
+	if min, ok := levelOverrides.lookup(l.component); ok {
+		return level >= min
+	}
+	return level >= l.level
+}
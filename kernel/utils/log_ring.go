@@ -0,0 +1,44 @@
+package utils
+
+import "sync"
+
+// recentLogCapacity is how many formatted log lines are kept in memory for
+// crash reports.
+const recentLogCapacity = 256
+
+// logRing keeps the most recent log lines from every logger.
+type logRing struct {
+	mu    sync.Mutex
+	lines [recentLogCapacity]string
+	next  int
+	count int
+}
+
+var recentLogs logRing
+
+func (r *logRing) add(line string) {
+	r.mu.Lock()
+	r.lines[r.next] = line
+	r.next = (r.next + 1) % recentLogCapacity
+	if r.count < recentLogCapacity {
+		r.count++
+	}
+	r.mu.Unlock()
+}
+
+// RecentLogs returns up to n of the most recent log lines, oldest first,
+// without color codes.
+func RecentLogs(n int) []string {
+	recentLogs.mu.Lock()
+	defer recentLogs.mu.Unlock()
+
+	if n > recentLogs.count {
+		n = recentLogs.count
+	}
+	out := make([]string, n)
+	start := recentLogs.next - n
+	for i := range out {
+		out[i] = recentLogs.lines[(start+i+recentLogCapacity)%recentLogCapacity]
+	}
+	return out
+}
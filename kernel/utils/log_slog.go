@@ -0,0 +1,85 @@
+package utils
+
+import (
+	"context"
+	"log/slog"
+)
+
+// SlogHandler sends log/slog records through a Logger, so subsystems that
+// log with slog share the kernel's sinks, level overrides and log stream.
+// A "component" attribute selects the Logger's component.
+type SlogHandler struct {
+	logger *Logger
+	fields []Field
+	group  string
+}
+
+// NewSlogHandler returns a handler writing through loggers configured like
+// base, one per component.
+func NewSlogHandler(base *Logger) *SlogHandler {
+	return &SlogHandler{logger: base}
+}
+
+// Enabled consults the component's level, including overrides.
+func (h *SlogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.logger.enabled(fromSlogLevel(level))
+}
+
+// Handle writes one record.
+func (h *SlogHandler) Handle(_ context.Context, r slog.Record) error {
+	fields := make([]Field, 0, len(h.fields)+r.NumAttrs())
+	fields = append(fields, h.fields...)
+	r.Attrs(func(a slog.Attr) bool {
+		fields = append(fields, h.field(a))
+		return true
+	})
+	h.logger.log(fromSlogLevel(r.Level), r.Message, fields...)
+	return nil
+}
+
+// WithAttrs returns a handler carrying attrs; a component attribute
+// switches the logger's component instead of becoming a field.
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	next := &SlogHandler{logger: h.logger, fields: append([]Field(nil), h.fields...), group: h.group}
+	for _, a := range attrs {
+		if a.Key == "component" && h.group == "" {
+			next.logger = h.logger.withComponent(a.Value.String())
+			continue
+		}
+		next.fields = append(next.fields, h.field(a))
+	}
+	return next
+}
+
+// WithGroup prefixes later attribute keys with name.
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	group := name
+	if h.group != "" {
+		group = h.group + "." + name
+	}
+	return &SlogHandler{logger: h.logger, fields: h.fields, group: group}
+}
+
+func (h *SlogHandler) field(a slog.Attr) Field {
+	key := a.Key
+	if h.group != "" {
+		key = h.group + "." + key
+	}
+	return Field{Key: key, Value: a.Value.Resolve().Any()}
+}
+
+func fromSlogLevel(level slog.Level) LogLevel {
+	switch {
+	case level < slog.LevelInfo:
+		return DEBUG
+	case level < slog.LevelWarn:
+		return INFO
+	case level < slog.LevelError:
+		return WARN
+	default:
+		return ERROR
+	}
+}
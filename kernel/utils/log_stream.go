@@ -0,0 +1,161 @@
+package utils
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultLogSubscriptionBuffer is how many records a subscription keeps
+// between reads; older ones are dropped and counted as lost.
+const DefaultLogSubscriptionBuffer = 512
+
+// ErrUnknownLogSubscription is returned for reads on a closed or unknown
+// subscription.
+var ErrUnknownLogSubscription = errors.New("unknown log subscription")
+
+// LogRecord is one structured log entry.
+type LogRecord struct {
+	Seq       uint64            `json:"seq"`
+	Time      time.Time         `json:"time"`
+	Level     string            `json:"level"`
+	Component string            `json:"component,omitempty"`
+	Message   string            `json:"message"`
+	Fields    map[string]string `json:"fields,omitempty"`
+}
+
+// LogFilter selects the records a subscription receives. Empty Components
+// matches every component.
+type LogFilter struct {
+	MinLevel   LogLevel
+	Components []string
+}
+
+func (f LogFilter) matches(level LogLevel, component string) bool {
+	if level < f.MinLevel {
+		return false
+	}
+	return len(f.Components) == 0 || slices.Contains(f.Components, component)
+}
+
+// logSubscription buffers matching records until they are read.
+type logSubscription struct {
+	filter  LogFilter
+	records []LogRecord // Ring of capacity len(records)
+	next    int
+	count   int
+	lost    uint64
+}
+
+// logStream fans written records out to subscriptions.
+type logStream struct {
+	active atomic.Int32 // Subscription count; zero skips record building
+	seq    atomic.Uint64
+	nextID atomic.Uint64
+
+	mu   sync.Mutex
+	subs map[string]*logSubscription
+}
+
+var logSubscriptions = logStream{subs: make(map[string]*logSubscription)}
+
+// SubscribeLogs starts buffering records that pass filter. Records are only
+// produced for lines a logger actually writes, so raise the level with
+// SetLogLevel or SetComponentLogLevel to capture debug output. buffer <= 0
+// uses DefaultLogSubscriptionBuffer.
+func SubscribeLogs(filter LogFilter, buffer int) string {
+	if buffer <= 0 {
+		buffer = DefaultLogSubscriptionBuffer
+	}
+	id := "logs-" + strconv.FormatUint(logSubscriptions.nextID.Add(1), 10)
+
+	logSubscriptions.mu.Lock()
+	logSubscriptions.subs[id] = &logSubscription{filter: filter, records: make([]LogRecord, buffer)}
+	logSubscriptions.active.Store(int32(len(logSubscriptions.subs)))
+	logSubscriptions.mu.Unlock()
+	return id
+}
+
+// UnsubscribeLogs closes a subscription.
+func UnsubscribeLogs(id string) bool {
+	logSubscriptions.mu.Lock()
+	defer logSubscriptions.mu.Unlock()
+	if _, ok := logSubscriptions.subs[id]; !ok {
+		return false
+	}
+	delete(logSubscriptions.subs, id)
+	logSubscriptions.active.Store(int32(len(logSubscriptions.subs)))
+	return true
+}
+
+// ReadLogs drains up to max buffered records, oldest first (max <= 0 drains
+// all), and returns how many were dropped since the last read.
+func ReadLogs(id string, max int) ([]LogRecord, uint64, error) {
+	logSubscriptions.mu.Lock()
+	defer logSubscriptions.mu.Unlock()
+
+	sub, ok := logSubscriptions.subs[id]
+	if !ok {
+		return nil, 0, ErrUnknownLogSubscription
+	}
+	n := sub.count
+	if max > 0 && max < n {
+		n = max
+	}
+	out := make([]LogRecord, n)
+	capacity := len(sub.records)
+	start := sub.next - sub.count
+	for i := range out {
+		slot := (start + i + capacity) % capacity
+		out[i] = sub.records[slot]
+		sub.records[slot] = LogRecord{}
+	}
+	sub.count -= n
+	lost := sub.lost
+	sub.lost = 0
+	return out, lost, nil
+}
+
+// publish hands a written line to the matching subscriptions.
+func (s *logStream) publish(level LogLevel, component, msg string, fields []Field) {
+	if s.active.Load() == 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var record *LogRecord
+	for _, sub := range s.subs {
+		if !sub.filter.matches(level, component) {
+			continue
+		}
+		if record == nil {
+			record = &LogRecord{
+				Seq:       s.seq.Add(1),
+				Time:      time.Now(),
+				Level:     level.String(),
+				Component: component,
+				Message:   msg,
+			}
+			if len(fields) > 0 {
+				record.Fields = make(map[string]string, len(fields))
+				for _, field := range fields {
+					record.Fields[field.Key] = field.plain()
+				}
+			}
+		}
+
+		capacity := len(sub.records)
+		sub.records[sub.next] = *record
+		sub.next = (sub.next + 1) % capacity
+		if sub.count < capacity {
+			sub.count++
+		} else {
+			sub.lost++
+		}
+	}
+}
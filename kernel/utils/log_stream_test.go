@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func newTestLogger(component string, out *bytes.Buffer) *Logger {
+	return NewLogger(LoggerConfig{Level: INFO, Component: component, Output: out})
+}
+
+func TestLogLevels_ComponentOverride(t *testing.T) {
+	t.Cleanup(ClearLogLevels)
+	var out bytes.Buffer
+	mesh, gossip := newTestLogger("mesh", &out), newTestLogger("gossip", &out)
+
+	mesh.Debug("hidden")
+	if out.Len() != 0 {
+		t.Fatalf("expected debug suppressed at INFO, got %q", out.String())
+	}
+
+	SetComponentLogLevel("mesh", DEBUG)
+	mesh.Debug("mesh detail")
+	gossip.Debug("gossip detail")
+	if !strings.Contains(out.String(), "mesh detail") || strings.Contains(out.String(), "gossip detail") {
+		t.Fatalf("expected only the mesh override to apply, got %q", out.String())
+	}
+
+	out.Reset()
+	SetLogLevel(ERROR)
+	gossip.Warn("quiet")
+	mesh.Debug("still loud")
+	if strings.Contains(out.String(), "quiet") || !strings.Contains(out.String(), "still loud") {
+		t.Fatalf("expected component override to win over global, got %q", out.String())
+	}
+}
+
+func TestLogStream_FiltersAndDrains(t *testing.T) {
+	var out bytes.Buffer
+	mesh, gossip := newTestLogger("mesh", &out), newTestLogger("gossip", &out)
+
+	id := SubscribeLogs(LogFilter{MinLevel: WARN, Components: []string{"mesh"}}, 2)
+	defer UnsubscribeLogs(id)
+
+	mesh.Info("too low")
+	gossip.Warn("other component")
+	mesh.Warn("first", String("peer", "abc"))
+	mesh.Error("second")
+	mesh.Error("third")
+
+	records, lost, err := ReadLogs(id, 0)
+	if err != nil {
+		t.Fatalf("ReadLogs failed: %v", err)
+	}
+	if len(records) != 2 || lost != 1 {
+		t.Fatalf("expected two records and one lost, got %d records, %d lost", len(records), lost)
+	}
+	if records[0].Message != "second" || records[1].Message != "third" || records[0].Level != "ERROR" {
+		t.Fatalf("unexpected records: %+v", records)
+	}
+
+	mesh.Warn("fourth", String("peer", "abc"))
+	records, _, _ = ReadLogs(id, 1)
+	if len(records) != 1 || records[0].Fields["peer"] != "abc" || records[0].Component != "mesh" {
+		t.Fatalf("expected structured fields, got %+v", records)
+	}
+
+	if !UnsubscribeLogs(id) {
+		t.Fatal("expected unsubscribe to succeed")
+	}
+	if _, _, err := ReadLogs(id, 0); err != ErrUnknownLogSubscription {
+		t.Fatalf("expected ErrUnknownLogSubscription, got %v", err)
+	}
+}
+
+func TestSlogHandler_UsesComponentAttribute(t *testing.T) {
+	t.Cleanup(ClearLogLevels)
+	var out bytes.Buffer
+	logger := slog.New(NewSlogHandler(newTestLogger("kernel", &out))).With("component", "transport")
+
+	logger.Debug("hidden")
+	SetComponentLogLevel("transport", DEBUG)
+	logger.Debug("visible", "peer", "abc")
+
+	line := out.String()
+	if strings.Contains(line, "hidden") || !strings.Contains(line, "[transport] visible") || !strings.Contains(line, `peer="abc"`) {
+		t.Fatalf("unexpected output %q", line)
+	}
+}
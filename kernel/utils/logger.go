@@ -79,6 +79,32 @@ func NewLogger(config LoggerConfig) *Logger {
 	}
 }
 
+// String returns the level name, e.g. "INFO".
+func (l LogLevel) String() string {
+	if name, ok := levelNames[l]; ok {
+		return name
+	}
+	return fmt.Sprintf("LEVEL(%d)", int(l))
+}
+
+// ParseLogLevel maps a level name such as "debug" or "WARN" to its LogLevel.
+func ParseLogLevel(name string) (LogLevel, error) {
+	upper := strings.ToUpper(strings.TrimSpace(name))
+	for level, levelName := range levelNames {
+		if levelName == upper {
+			return level, nil
+		}
+	}
+	return INFO, fmt.Errorf("unknown log level %q", name)
+}
+
+// SetLevel changes the minimum level the logger writes.
+func (l *Logger) SetLevel(level LogLevel) {
+	l.mu.Lock()
+	l.level = level
+	l.mu.Unlock()
+}
+
 // DefaultLogger creates a logger with sensible defaults
 func DefaultLogger(component string) *Logger {
 	return NewLogger(LoggerConfig{
@@ -103,6 +129,13 @@ func (l *Logger) With(fields ...Field) *Logger {
 	}
 }
 
+// withComponent returns a copy of the logger for another component.
+func (l *Logger) withComponent(component string) *Logger {
+	next := l.With()
+	next.component = component
+	return next
+}
+
 func (l *Logger) Debug(msg string, fields ...Field) { l.log(DEBUG, msg, fields...) }
 func (l *Logger) Info(msg string, fields ...Field)  { l.log(INFO, msg, fields...) }
 func (l *Logger) Warn(msg string, fields ...Field)  { l.log(WARN, msg, fields...) }
@@ -116,7 +149,7 @@ func (l *Logger) log(level LogLevel, msg string, fields ...Field) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
-	if level < l.level {
+	if !l.enabled(level) {
 		return
 	}
 
@@ -124,10 +157,6 @@ func (l *Logger) log(level LogLevel, msg string, fields ...Field) {
 	levelStr := levelNames[level]
 
 	var builder strings.Builder
-	if l.colorize {
-		builder.WriteString(levelColors[level])
-	}
-
 	builder.WriteString("[")
 	builder.WriteString(timestamp)
 	builder.WriteString("] ")
@@ -165,13 +194,15 @@ func (l *Logger) log(level LogLevel, msg string, fields ...Field) {
 		}
 	}
 
+	plain := builder.String()
+	recentLogs.add(plain)
+	logSubscriptions.publish(level, l.component, msg, fields)
+
+	logLine := plain + "\n"
 	if l.colorize {
-		builder.WriteString(colorReset)
+		logLine = levelColors[level] + plain + colorReset + "\n"
 	}
 
-	builder.WriteString("\n")
-	logLine := builder.String()
-
 	// Platform-specific redirection (WASM JS Console)
 	// If redirected successfully, we skip writing to l.output (which is usually os.Stdout)
 	// to avoid double logging in the browser console.
@@ -203,6 +234,18 @@ func (f Field) format() string {
 	}
 }
 
+// plain renders the value for structured records, without quoting.
+func (f Field) plain() string {
+	switch v := f.Value.(type) {
+	case string:
+		return v
+	case error:
+		return v.Error()
+	default:
+		return f.format()
+	}
+}
+
 func String(key, value string) Field                 { return Field{Key: key, Value: value} }
 func Int(key string, value int) Field                { return Field{Key: key, Value: value} }
 func Int64(key string, value int64) Field            { return Field{Key: key, Value: value} }
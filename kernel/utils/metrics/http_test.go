@@ -0,0 +1,35 @@
+//go:build !js || !wasm
+// +build !js !wasm
+
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_ServesScrapes(t *testing.T) {
+	reg := NewRegistry("inos")
+	reg.Register("execution", "", func() interface{} { return map[string]int{"executed": 5} })
+	srv := httptest.NewServer(reg)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, ContentType, resp.Header.Get("Content-Type"))
+	assert.Contains(t, string(body), "inos_execution_executed 5\n")
+
+	post, err := http.Post(srv.URL, "text/plain", nil)
+	require.NoError(t, err)
+	post.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, post.StatusCode)
+}
@@ -0,0 +1,243 @@
+// Package metrics exposes the kernel's existing stats structs in the
+// Prometheus text exposition format. Sources are plain funcs returning a
+// struct, pointer or map; their numeric fields become gauges named
+// <namespace>_<subsystem>_<json name>, so no stat has to be declared twice.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ContentType is the Prometheus text format version written by WriteText.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Source returns a snapshot of one subsystem's stats.
+type Source func() interface{}
+
+type source struct {
+	subsystem string
+	help      string
+	collect   Source
+}
+
+// Registry holds the sources scraped for one process.
+type Registry struct {
+	namespace string
+
+	mu      sync.RWMutex
+	sources []source
+}
+
+// NewRegistry creates a registry whose metric names start with namespace.
+func NewRegistry(namespace string) *Registry {
+	return &Registry{namespace: sanitizeName(namespace)}
+}
+
+// Register adds a source under subsystem, replacing any earlier source with
+// the same subsystem. help is attached to every metric the source yields.
+func (r *Registry) Register(subsystem, help string, collect Source) {
+	if collect == nil {
+		return
+	}
+	s := source{subsystem: sanitizeName(subsystem), help: help, collect: collect}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.sources {
+		if r.sources[i].subsystem == s.subsystem {
+			r.sources[i] = s
+			return
+		}
+	}
+	r.sources = append(r.sources, s)
+}
+
+// Sample is one flattened metric value.
+type Sample struct {
+	Name  string
+	Help  string
+	Value float64
+}
+
+// Gather collects every source and returns the samples sorted by name.
+// A source that panics is skipped so one broken subsystem cannot take the
+// whole scrape down.
+func (r *Registry) Gather() []Sample {
+	r.mu.RLock()
+	sources := append([]source(nil), r.sources...)
+	r.mu.RUnlock()
+
+	var samples []Sample
+	for _, s := range sources {
+		prefix := joinName(r.namespace, s.subsystem)
+		for name, value := range collectSource(s.collect) {
+			samples = append(samples, Sample{Name: joinName(prefix, name), Help: s.help, Value: value})
+		}
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i].Name < samples[j].Name })
+	return samples
+}
+
+func collectSource(collect Source) (values map[string]float64) {
+	values = make(map[string]float64)
+	defer func() {
+		if recover() != nil {
+			values = nil
+		}
+	}()
+	flatten(values, "", reflect.ValueOf(collect()))
+	return values
+}
+
+// WriteText writes every sample as a gauge in the text exposition format.
+func (r *Registry) WriteText(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	for _, s := range r.Gather() {
+		if s.Help != "" {
+			fmt.Fprintf(bw, "# HELP %s %s\n", s.Name, escapeHelp(s.Help))
+		}
+		fmt.Fprintf(bw, "# TYPE %s gauge\n", s.Name)
+		fmt.Fprintf(bw, "%s %s\n", s.Name, formatValue(s.Value))
+	}
+	return bw.Flush()
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// flatten walks v and records every numeric or boolean leaf. Durations are
+// reported in seconds; strings, times and slices are skipped.
+func flatten(out map[string]float64, name string, v reflect.Value) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		if v.Type() == reflect.TypeOf(time.Time{}) {
+			return
+		}
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			fieldName, ok := jsonName(field)
+			if !ok {
+				continue
+			}
+			flatten(out, joinName(name, fieldName), v.Field(i))
+		}
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			flatten(out, joinName(name, sanitizeName(iter.Key().String())), iter.Value())
+		}
+	case reflect.Bool:
+		if name != "" {
+			out[name] = 0
+			if v.Bool() {
+				out[name] = 1
+			}
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if name == "" {
+			return
+		}
+		if v.Type() == durationType {
+			out[joinName(name, "seconds")] = time.Duration(v.Int()).Seconds()
+			return
+		}
+		out[name] = float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if name != "" {
+			out[name] = float64(v.Uint())
+		}
+	case reflect.Float32, reflect.Float64:
+		if name != "" {
+			out[name] = v.Float()
+		}
+	}
+}
+
+// jsonName returns the field's json key, falling back to its snake-cased
+// Go name.
+func jsonName(f reflect.StructField) (string, bool) {
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	if name, _, _ := strings.Cut(tag, ","); name != "" {
+		return sanitizeName(name), true
+	}
+	return sanitizeName(snakeCase(f.Name)), true
+}
+
+func snakeCase(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		if r >= 'A' && r <= 'Z' {
+			if i > 0 && (s[i-1] < 'A' || s[i-1] > 'Z') {
+				b.WriteByte('_')
+			}
+			r += 'a' - 'A'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// sanitizeName maps s onto the metric name alphabet [a-z0-9_].
+func sanitizeName(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '_':
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
+
+func joinName(prefix, name string) string {
+	switch {
+	case prefix == "":
+		return name
+	case name == "":
+		return prefix
+	}
+	return prefix + "_" + name
+}
+
+func escapeHelp(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(s)
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,81 @@
+package metrics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type nestedStats struct {
+	Hits   uint64 `json:"hits"`
+	Misses int
+}
+
+type testStats struct {
+	Peers     uint32        `json:"total_peers"`
+	Rate      float32       `json:"gossip_rate_per_sec,omitempty"`
+	Enabled   bool          `json:"enabled"`
+	Interval  time.Duration `json:"interval"`
+	Name      string        `json:"name"`
+	Started   time.Time     `json:"started"`
+	Levels    []int         `json:"levels"`
+	Ignored   int           `json:"-"`
+	Cache     nestedStats   `json:"cache"`
+	unexposed int
+}
+
+func TestRegistry_FlattensStructsAndMaps(t *testing.T) {
+	reg := NewRegistry("inos")
+	reg.Register("mesh", "Mesh metrics", func() interface{} {
+		return &testStats{Peers: 3, Rate: 1.5, Enabled: true, Interval: 1500 * time.Millisecond, Cache: nestedStats{Hits: 7, Misses: 2}}
+	})
+	reg.Register("ledger", "", func() interface{} {
+		return map[string]interface{}{"total-supply": int64(100), "accounts": 4, "label": "x"}
+	})
+
+	values := map[string]float64{}
+	for _, s := range reg.Gather() {
+		values[s.Name] = s.Value
+	}
+	assert.Equal(t, map[string]float64{
+		"inos_mesh_total_peers":         3,
+		"inos_mesh_gossip_rate_per_sec": 1.5,
+		"inos_mesh_enabled":             1,
+		"inos_mesh_interval_seconds":    1.5,
+		"inos_mesh_cache_hits":          7,
+		"inos_mesh_cache_misses":        2,
+		"inos_ledger_total_supply":      100,
+		"inos_ledger_accounts":          4,
+	}, values)
+}
+
+func TestRegistry_WriteText(t *testing.T) {
+	reg := NewRegistry("inos")
+	reg.Register("gossip", "Gossip metrics", func() interface{} {
+		return map[string]uint64{"messages_sent": 42}
+	})
+	reg.Register("broken", "", func() interface{} { panic("boom") })
+
+	var buf bytes.Buffer
+	require.NoError(t, reg.WriteText(&buf))
+	assert.Equal(t, strings.Join([]string{
+		"# HELP inos_gossip_messages_sent Gossip metrics",
+		"# TYPE inos_gossip_messages_sent gauge",
+		"inos_gossip_messages_sent 42",
+		"",
+	}, "\n"), buf.String())
+}
+
+func TestRegistry_RegisterReplacesSubsystem(t *testing.T) {
+	reg := NewRegistry("inos")
+	reg.Register("dht", "", func() interface{} { return map[string]int{"a": 1} })
+	reg.Register("dht", "", func() interface{} { return map[string]int{"b": 2} })
+
+	samples := reg.Gather()
+	require.Len(t, samples, 1)
+	assert.Equal(t, "inos_dht_b", samples[0].Name)
+}
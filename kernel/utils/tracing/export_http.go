@@ -0,0 +1,58 @@
+//go:build !js || !wasm
+// +build !js !wasm
+
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// HTTPExporter posts OTLP/JSON batches to a collector's traces endpoint,
+// e.g. http://localhost:4318/v1/traces. Browser nodes export over the host
+// outbox instead.
+type HTTPExporter struct {
+	Endpoint string
+	Headers  map[string]string
+	Client   *http.Client
+}
+
+// NewHTTPExporter creates an exporter for endpoint with a 10s timeout.
+func NewHTTPExporter(endpoint string) *HTTPExporter {
+	return &HTTPExporter{
+		Endpoint: endpoint,
+		Client:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Export posts one batch.
+func (e *HTTPExporter) Export(ctx context.Context, payload []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.Endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range e.Headers {
+		req.Header.Set(k, v)
+	}
+
+	client := e.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("otlp export failed: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("otlp collector returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+//go:build !js || !wasm
+// +build !js !wasm
+
+package tracing
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPExporter_PostsOTLPJSON(t *testing.T) {
+	var body []byte
+	var contentType, auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		contentType = r.Header.Get("Content-Type")
+		auth = r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	rec := NewRecorder("test", 8)
+	rec.StartRemote("", "op", SpanKindInternal).End()
+
+	exp := NewHTTPExporter(srv.URL + "/v1/traces")
+	exp.Headers = map[string]string{"Authorization": "Bearer token"}
+	n, err := rec.Flush(context.Background(), exp, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, "application/json", contentType)
+	assert.Equal(t, "Bearer token", auth)
+	assert.Contains(t, string(body), `"resourceSpans"`)
+}
+
+func TestHTTPExporter_ReportsCollectorErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	err := NewHTTPExporter(srv.URL).Export(context.Background(), []byte(`{}`))
+	assert.ErrorContains(t, err, "503")
+}
@@ -0,0 +1,189 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// OTLP/JSON trace export (opentelemetry-proto ExportTraceServiceRequest).
+// IDs are hex and 64-bit integers are decimal strings, as the OTLP JSON
+// mapping requires.
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              SpanKind       `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Status            otlpStatus     `json:"status"`
+}
+
+type otlpStatus struct {
+	Code    StatusCode `json:"code"`
+	Message string     `json:"message,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+}
+
+// EncodeOTLP renders spans as an OTLP/JSON ExportTraceServiceRequest for
+// service.
+func EncodeOTLP(service string, spans []SpanData) ([]byte, error) {
+	out := make([]otlpSpan, len(spans))
+	for i, s := range spans {
+		out[i] = otlpSpan{
+			TraceID:           s.TraceID,
+			SpanID:            s.SpanID,
+			ParentSpanID:      s.ParentSpanID,
+			Name:              s.Name,
+			Kind:              s.Kind,
+			StartTimeUnixNano: strconv.FormatInt(s.Start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.End.UnixNano(), 10),
+			Attributes:        otlpAttributes(s.Attributes),
+			Status:            otlpStatus{Code: s.Status, Message: s.StatusMessage},
+		}
+	}
+
+	return json.Marshal(otlpRequest{ResourceSpans: []otlpResourceSpans{{
+		Resource:   otlpResource{Attributes: otlpAttributes(map[string]interface{}{"service.name": service})},
+		ScopeSpans: []otlpScopeSpans{{Scope: otlpScope{Name: "github.com/nmxmxh/inos_v1/kernel"}, Spans: out}},
+	}}})
+}
+
+func otlpAttributes(attrs map[string]interface{}) []otlpKeyValue {
+	if len(attrs) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	out := make([]otlpKeyValue, len(keys))
+	for i, k := range keys {
+		out[i] = otlpKeyValue{Key: k, Value: otlpValue(attrs[k])}
+	}
+	return out
+}
+
+func otlpValue(v interface{}) otlpAnyValue {
+	switch x := v.(type) {
+	case string:
+		return otlpAnyValue{StringValue: &x}
+	case bool:
+		return otlpAnyValue{BoolValue: &x}
+	case int:
+		s := strconv.FormatInt(int64(x), 10)
+		return otlpAnyValue{IntValue: &s}
+	case int32:
+		s := strconv.FormatInt(int64(x), 10)
+		return otlpAnyValue{IntValue: &s}
+	case int64:
+		s := strconv.FormatInt(x, 10)
+		return otlpAnyValue{IntValue: &s}
+	case uint32:
+		s := strconv.FormatUint(uint64(x), 10)
+		return otlpAnyValue{IntValue: &s}
+	case uint64:
+		s := strconv.FormatUint(x, 10)
+		return otlpAnyValue{IntValue: &s}
+	case float32:
+		f := float64(x)
+		return otlpAnyValue{DoubleValue: &f}
+	case float64:
+		return otlpAnyValue{DoubleValue: &x}
+	case time.Duration:
+		s := strconv.FormatInt(x.Nanoseconds(), 10)
+		return otlpAnyValue{IntValue: &s}
+	default:
+		s := fmt.Sprint(x)
+		return otlpAnyValue{StringValue: &s}
+	}
+}
+
+// Exporter delivers one encoded OTLP/JSON batch.
+type Exporter interface {
+	Export(ctx context.Context, payload []byte) error
+}
+
+// ExporterFunc adapts a function to Exporter.
+type ExporterFunc func(ctx context.Context, payload []byte) error
+
+func (f ExporterFunc) Export(ctx context.Context, payload []byte) error { return f(ctx, payload) }
+
+// Flush drains the recorder in batches of up to batch spans and exports
+// each. It stops at the first failed batch; those spans are lost, since a
+// collector that is down would otherwise pin the ring full. It returns the
+// number of spans exported.
+func (r *Recorder) Flush(ctx context.Context, exp Exporter, batch int) (int, error) {
+	exported := 0
+	for {
+		spans := r.Drain(batch)
+		if len(spans) == 0 {
+			return exported, nil
+		}
+		payload, err := EncodeOTLP(r.service, spans)
+		if err != nil {
+			return exported, err
+		}
+		if err := exp.Export(ctx, payload); err != nil {
+			return exported, err
+		}
+		exported += len(spans)
+	}
+}
+
+// RunExporter flushes the recorder every interval until ctx is done. onError,
+// if not nil, is told about failed exports.
+func RunExporter(ctx context.Context, r *Recorder, exp Exporter, interval time.Duration, batch int, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := r.Flush(ctx, exp, batch); err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
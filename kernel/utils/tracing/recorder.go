@@ -0,0 +1,211 @@
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultCapacity is how many finished spans the default recorder keeps.
+const DefaultCapacity = 2048
+
+// Recorder keeps finished spans in a ring buffer until they are exported.
+// When the ring is full the oldest span is dropped.
+type Recorder struct {
+	service string
+
+	mu      sync.Mutex
+	spans   []SpanData
+	next    int
+	count   int
+	dropped uint64
+
+	sampleRate atomic.Uint64 // Root sampling rate in parts per million
+}
+
+// NewRecorder creates a recorder for service that keeps up to capacity spans
+// and samples every root span.
+func NewRecorder(service string, capacity int) *Recorder {
+	if capacity <= 0 {
+		capacity = DefaultCapacity
+	}
+	r := &Recorder{service: service, spans: make([]SpanData, capacity)}
+	r.SetSampleRate(1)
+	return r
+}
+
+var defaultRecorder atomic.Pointer[Recorder]
+
+func init() {
+	defaultRecorder.Store(NewRecorder("inos-kernel", DefaultCapacity))
+}
+
+// Default returns the process-wide recorder used by the package functions.
+func Default() *Recorder {
+	return defaultRecorder.Load()
+}
+
+// SetDefault replaces the process-wide recorder.
+func SetDefault(r *Recorder) {
+	if r != nil {
+		defaultRecorder.Store(r)
+	}
+}
+
+// Service returns the service name spans are exported under.
+func (r *Recorder) Service() string {
+	return r.service
+}
+
+// SetSampleRate sets the fraction of new traces that are recorded. Spans
+// with a parent follow the parent's decision.
+func (r *Recorder) SetSampleRate(rate float64) {
+	rate = min(max(rate, 0), 1)
+	r.sampleRate.Store(uint64(rate * 1e6))
+}
+
+func (r *Recorder) sampleRoot() bool {
+	rate := r.sampleRate.Load()
+	return rate >= 1e6 || (rate > 0 && uint64(rand.Int63n(1e6)) < rate)
+}
+
+// Start begins a span named name, as a child of the span in ctx if there is
+// one, and returns a context carrying the new span.
+func (r *Recorder) Start(ctx context.Context, name string, kind SpanKind) (context.Context, *Span) {
+	var parent SpanContext
+	if p := SpanFromContext(ctx); p != nil {
+		parent = p.ctx
+	} else if remote, ok := ctx.Value(remoteParentKey{}).(SpanContext); ok {
+		parent = remote
+	}
+	span := r.newSpan(parent, name, kind)
+	return ContextWithSpan(ctx, span), span
+}
+
+// StartRemote begins a span whose parent arrived as a traceparent string
+// from another layer or peer. An empty or malformed traceparent starts a new
+// trace.
+func (r *Recorder) StartRemote(traceparent, name string, kind SpanKind) *Span {
+	parent, _ := ParseTraceparent(traceparent)
+	return r.newSpan(parent, name, kind)
+}
+
+func (r *Recorder) newSpan(parent SpanContext, name string, kind SpanKind) *Span {
+	span := &Span{recorder: r}
+	if parent.IsValid() {
+		span.ctx = SpanContext{TraceID: parent.TraceID, SpanID: newSpanID(), Sampled: parent.Sampled}
+		span.parent = parent.SpanID
+	} else {
+		span.ctx = SpanContext{TraceID: newTraceID(), SpanID: newSpanID(), Sampled: r.sampleRoot()}
+	}
+
+	span.data = SpanData{
+		TraceID: hex.EncodeToString(span.ctx.TraceID[:]),
+		SpanID:  hex.EncodeToString(span.ctx.SpanID[:]),
+		Name:    name,
+		Kind:    kind,
+		Start:   time.Now(),
+	}
+	if span.parent != [8]byte{} {
+		span.data.ParentSpanID = hex.EncodeToString(span.parent[:])
+	}
+	return span
+}
+
+func (r *Recorder) record(data SpanData) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.count == len(r.spans) {
+		r.dropped++
+	} else {
+		r.count++
+	}
+	r.spans[r.next] = data
+	r.next = (r.next + 1) % len(r.spans)
+}
+
+// Snapshot returns the buffered spans, oldest first, without removing them.
+func (r *Recorder) Snapshot() []SpanData {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.copyLocked(r.count)
+}
+
+// Drain removes and returns up to max buffered spans, oldest first. A max of
+// zero or less drains everything.
+func (r *Recorder) Drain(max int) []SpanData {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if max <= 0 || max > r.count {
+		max = r.count
+	}
+	out := r.copyLocked(max)
+	r.count -= max
+	return out
+}
+
+// copyLocked returns the n oldest spans.
+func (r *Recorder) copyLocked(n int) []SpanData {
+	out := make([]SpanData, n)
+	start := r.next - r.count + len(r.spans)
+	for i := range out {
+		out[i] = r.spans[(start+i)%len(r.spans)]
+	}
+	return out
+}
+
+// Len returns how many spans are buffered.
+func (r *Recorder) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.count
+}
+
+// Dropped returns how many spans were overwritten before being exported.
+func (r *Recorder) Dropped() uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.dropped
+}
+
+// Start begins a span on the default recorder.
+func Start(ctx context.Context, name string, kind SpanKind) (context.Context, *Span) {
+	return Default().Start(ctx, name, kind)
+}
+
+// StartRemote begins a span with a remote parent on the default recorder.
+func StartRemote(traceparent, name string, kind SpanKind) *Span {
+	return Default().StartRemote(traceparent, name, kind)
+}
+
+type spanKey struct{}
+type remoteParentKey struct{}
+
+// ContextWithSpan returns a context carrying span.
+func ContextWithSpan(ctx context.Context, span *Span) context.Context {
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+// SpanFromContext returns the span carried by ctx, or nil.
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// ContextWithTraceparent returns a context whose next span is a child of the
+// remote span named by traceparent. A malformed traceparent is ignored.
+func ContextWithTraceparent(ctx context.Context, traceparent string) context.Context {
+	parent, err := ParseTraceparent(traceparent)
+	if err != nil {
+		return ctx
+	}
+	return context.WithValue(ctx, remoteParentKey{}, parent)
+}
+
+// TraceparentFromContext returns the traceparent of the span in ctx, or "".
+func TraceparentFromContext(ctx context.Context) string {
+	return SpanFromContext(ctx).Traceparent()
+}
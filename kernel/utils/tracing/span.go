@@ -0,0 +1,205 @@
+// Package tracing records lightweight spans across the kernel layers (host
+// command, SAB, supervisor, mesh RPC, peer) and exports them as OTLP-JSON.
+// Span contexts travel between layers as W3C traceparent strings.
+package tracing
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SpanKind matches the OTLP span kind enumeration.
+type SpanKind int
+
+const (
+	SpanKindInternal SpanKind = 1
+	SpanKindServer   SpanKind = 2
+	SpanKindClient   SpanKind = 3
+	SpanKindProducer SpanKind = 4
+	SpanKindConsumer SpanKind = 5
+)
+
+// StatusCode matches the OTLP status code enumeration.
+type StatusCode int
+
+const (
+	StatusUnset StatusCode = 0
+	StatusOK    StatusCode = 1
+	StatusError StatusCode = 2
+)
+
+// SpanContext identifies a span and the trace it belongs to.
+type SpanContext struct {
+	TraceID [16]byte
+	SpanID  [8]byte
+	Sampled bool
+}
+
+// IsValid reports whether both IDs are set.
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID != [16]byte{} && sc.SpanID != [8]byte{}
+}
+
+// TraceIDString returns the trace ID as lowercase hex.
+func (sc SpanContext) TraceIDString() string {
+	return hex.EncodeToString(sc.TraceID[:])
+}
+
+// SpanIDString returns the span ID as lowercase hex.
+func (sc SpanContext) SpanIDString() string {
+	return hex.EncodeToString(sc.SpanID[:])
+}
+
+// Traceparent encodes the context as a W3C traceparent header value, or ""
+// if it is not valid.
+func (sc SpanContext) Traceparent() string {
+	if !sc.IsValid() {
+		return ""
+	}
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return fmt.Sprintf("00-%x-%x-%s", sc.TraceID, sc.SpanID, flags)
+}
+
+// ParseTraceparent decodes a W3C traceparent header value.
+func ParseTraceparent(s string) (SpanContext, error) {
+	parts := strings.Split(strings.TrimSpace(s), "-")
+	if len(parts) != 4 || len(parts[0]) != 2 || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return SpanContext{}, errors.New("malformed traceparent")
+	}
+	if parts[0] == "ff" {
+		return SpanContext{}, errors.New("invalid traceparent version")
+	}
+
+	var sc SpanContext
+	if _, err := hex.Decode(sc.TraceID[:], []byte(parts[1])); err != nil {
+		return SpanContext{}, fmt.Errorf("invalid trace id: %w", err)
+	}
+	if _, err := hex.Decode(sc.SpanID[:], []byte(parts[2])); err != nil {
+		return SpanContext{}, fmt.Errorf("invalid span id: %w", err)
+	}
+	flags, err := hex.DecodeString(parts[3])
+	if err != nil {
+		return SpanContext{}, fmt.Errorf("invalid trace flags: %w", err)
+	}
+	sc.Sampled = flags[0]&0x01 != 0
+	if !sc.IsValid() {
+		return SpanContext{}, errors.New("traceparent has zero ids")
+	}
+	return sc, nil
+}
+
+// SpanData is a finished span as stored in the recorder.
+type SpanData struct {
+	TraceID       string                 `json:"trace_id"`
+	SpanID        string                 `json:"span_id"`
+	ParentSpanID  string                 `json:"parent_span_id,omitempty"`
+	Name          string                 `json:"name"`
+	Kind          SpanKind               `json:"kind"`
+	Start         time.Time              `json:"start"`
+	End           time.Time              `json:"end"`
+	Attributes    map[string]interface{} `json:"attributes,omitempty"`
+	Status        StatusCode             `json:"status"`
+	StatusMessage string                 `json:"status_message,omitempty"`
+}
+
+// Span is an operation in progress. Unsampled spans still carry a context
+// for propagation but are not recorded. All methods are safe on a nil span.
+type Span struct {
+	mu       sync.Mutex
+	recorder *Recorder
+	ctx      SpanContext
+	parent   [8]byte
+	data     SpanData
+	ended    bool
+}
+
+// Context returns the span's identity.
+func (s *Span) Context() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.ctx
+}
+
+// Traceparent returns the span's context as a W3C traceparent value.
+func (s *Span) Traceparent() string {
+	return s.Context().Traceparent()
+}
+
+// SetAttribute records a key/value on the span. Values should be strings,
+// integers, floats or booleans; anything else is exported as its string form.
+func (s *Span) SetAttribute(key string, value interface{}) {
+	if s == nil || !s.ctx.Sampled {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ended {
+		return
+	}
+	if s.data.Attributes == nil {
+		s.data.Attributes = make(map[string]interface{})
+	}
+	s.data.Attributes[key] = value
+}
+
+// SetError marks the span failed. A nil error is ignored.
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.SetStatus(StatusError, err.Error())
+}
+
+// SetStatus sets the span's outcome.
+func (s *Span) SetStatus(code StatusCode, message string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.ended {
+		s.data.Status = code
+		s.data.StatusMessage = message
+	}
+}
+
+// End finishes the span and hands it to the recorder. Later calls are no-ops.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.End = time.Now()
+	data := s.data
+	s.mu.Unlock()
+
+	if s.ctx.Sampled && s.recorder != nil {
+		s.recorder.record(data)
+	}
+}
+
+func newTraceID() (id [16]byte) {
+	_, _ = rand.Read(id[:])
+	return id
+}
+
+func newSpanID() (id [8]byte) {
+	for id == [8]byte{} {
+		_, _ = rand.Read(id[:])
+	}
+	return id
+}
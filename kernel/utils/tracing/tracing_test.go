@@ -0,0 +1,140 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTraceparent_RoundTrip(t *testing.T) {
+	rec := NewRecorder("test", 8)
+	span := rec.StartRemote("", "root", SpanKindInternal)
+
+	parsed, err := ParseTraceparent(span.Traceparent())
+	require.NoError(t, err)
+	assert.Equal(t, span.Context(), parsed)
+
+	for _, bad := range []string{"", "00-abc-def-01", "ff-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01",
+		"00-00000000000000000000000000000000-b7ad6b7169203331-01"} {
+		_, err := ParseTraceparent(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestRecorder_ChildSpansShareTrace(t *testing.T) {
+	rec := NewRecorder("test", 8)
+
+	ctx, parent := rec.Start(context.Background(), "host.command", SpanKindServer)
+	_, child := rec.Start(ctx, "supervisor.job", SpanKindInternal)
+	remote := rec.StartRemote(child.Traceparent(), "mesh.delegate", SpanKindServer)
+	remote.SetError(errors.New("boom"))
+
+	remote.End()
+	child.End()
+	parent.End()
+	parent.End() // Second End is a no-op
+
+	spans := rec.Snapshot()
+	require.Len(t, spans, 3)
+	assert.Equal(t, "mesh.delegate", spans[0].Name)
+	assert.Equal(t, spans[2].TraceID, spans[0].TraceID)
+	assert.Equal(t, spans[1].SpanID, spans[0].ParentSpanID)
+	assert.Equal(t, spans[2].SpanID, spans[1].ParentSpanID)
+	assert.Empty(t, spans[2].ParentSpanID)
+	assert.Equal(t, StatusError, spans[0].Status)
+}
+
+func TestRecorder_RingDropsOldestAndDrains(t *testing.T) {
+	rec := NewRecorder("test", 3)
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		rec.StartRemote("", name, SpanKindInternal).End()
+	}
+	assert.Equal(t, uint64(2), rec.Dropped())
+
+	first := rec.Drain(2)
+	require.Len(t, first, 2)
+	assert.Equal(t, "c", first[0].Name)
+	assert.Equal(t, "d", first[1].Name)
+
+	rest := rec.Drain(0)
+	require.Len(t, rest, 1)
+	assert.Equal(t, "e", rest[0].Name)
+	assert.Zero(t, rec.Len())
+}
+
+func TestRecorder_UnsampledTracesAreNotRecorded(t *testing.T) {
+	rec := NewRecorder("test", 8)
+	rec.SetSampleRate(0)
+
+	ctx, root := rec.Start(context.Background(), "root", SpanKindInternal)
+	_, child := rec.Start(ctx, "child", SpanKindInternal)
+	assert.True(t, child.Context().IsValid(), "unsampled spans still propagate")
+	child.End()
+	root.End()
+	assert.Zero(t, rec.Len())
+
+	// A sampled remote parent overrides the local rate
+	rec.StartRemote("00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01", "server", SpanKindServer).End()
+	assert.Equal(t, 1, rec.Len())
+}
+
+func TestEncodeOTLP(t *testing.T) {
+	rec := NewRecorder("inos-test", 8)
+	_, span := rec.Start(context.Background(), "mesh.rpc", SpanKindClient)
+	span.SetAttribute("peer", "peer-1")
+	span.SetAttribute("bytes", 42)
+	span.SetAttribute("cached", true)
+	span.End()
+
+	payload, err := EncodeOTLP(rec.Service(), rec.Snapshot())
+	require.NoError(t, err)
+
+	var decoded struct {
+		ResourceSpans []struct {
+			Resource struct {
+				Attributes []struct {
+					Key   string
+					Value map[string]interface{}
+				}
+			}
+			ScopeSpans []struct {
+				Spans []map[string]interface{}
+			}
+		}
+	}
+	require.NoError(t, json.Unmarshal(payload, &decoded))
+	require.Len(t, decoded.ResourceSpans, 1)
+	assert.Equal(t, "service.name", decoded.ResourceSpans[0].Resource.Attributes[0].Key)
+	assert.Equal(t, "inos-test", decoded.ResourceSpans[0].Resource.Attributes[0].Value["stringValue"])
+
+	spans := decoded.ResourceSpans[0].ScopeSpans[0].Spans
+	require.Len(t, spans, 1)
+	assert.Equal(t, span.Context().TraceIDString(), spans[0]["traceId"])
+	assert.Equal(t, float64(SpanKindClient), spans[0]["kind"])
+	assert.IsType(t, "", spans[0]["startTimeUnixNano"])
+
+	attrs := spans[0]["attributes"].([]interface{})
+	require.Len(t, attrs, 3)
+	assert.Equal(t, map[string]interface{}{"key": "bytes", "value": map[string]interface{}{"intValue": "42"}}, attrs[0])
+}
+
+func TestRecorder_FlushBatches(t *testing.T) {
+	rec := NewRecorder("test", 8)
+	for i := 0; i < 5; i++ {
+		rec.StartRemote("", "op", SpanKindInternal).End()
+	}
+
+	var batches int
+	n, err := rec.Flush(context.Background(), ExporterFunc(func(ctx context.Context, payload []byte) error {
+		batches++
+		return nil
+	}), 2)
+	require.NoError(t, err)
+	assert.Equal(t, 5, n)
+	assert.Equal(t, 3, batches)
+	assert.Zero(t, rec.Len())
+}
@@ -0,0 +1,63 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"errors"
+	"sync"
+	"syscall/js"
+
+	"github.com/nmxmxh/inos_v1/kernel/core/mesh"
+)
+
+// wasmFeatureTests are minimal modules that only validate when the engine
+// implements the feature (the same probes wasm-feature-detect uses).
+var wasmFeatureTests = map[string][]byte{
+	mesh.WASMFeatureSIMD:       {0, 97, 115, 109, 1, 0, 0, 0, 1, 5, 1, 96, 0, 1, 123, 3, 2, 1, 0, 10, 10, 1, 8, 0, 65, 0, 253, 15, 253, 98, 11},
+	mesh.WASMFeatureThreads:    {0, 97, 115, 109, 1, 0, 0, 0, 1, 4, 1, 96, 0, 0, 3, 2, 1, 0, 5, 4, 1, 3, 1, 1, 10, 11, 1, 9, 0, 65, 0, 254, 16, 2, 0, 26, 11},
+	mesh.WASMFeatureBulkMemory: {0, 97, 115, 109, 1, 0, 0, 0, 1, 4, 1, 96, 0, 0, 3, 2, 1, 0, 5, 3, 1, 0, 1, 10, 14, 1, 12, 0, 65, 0, 65, 0, 65, 0, 252, 10, 0, 0, 11},
+	mesh.WASMFeatureExceptions: {0, 97, 115, 109, 1, 0, 0, 0, 1, 4, 1, 96, 0, 0, 3, 2, 1, 0, 10, 8, 1, 6, 0, 6, 64, 25, 11, 11},
+	mesh.WASMFeatureTailCall:   {0, 97, 115, 109, 1, 0, 0, 0, 1, 4, 1, 96, 0, 0, 3, 2, 1, 0, 10, 6, 1, 4, 0, 18, 0, 11},
+}
+
+// kernelFeatureProber answers mesh feature probes from the host's
+// WebAssembly engine and the module registry of the current supervisor.
+type kernelFeatureProber struct {
+	k *Kernel
+
+	once     sync.Once
+	features []string
+}
+
+// WASMFeatures validates each test module once; engine support cannot change
+// while the page is open.
+func (p *kernelFeatureProber) WASMFeatures() []string {
+	p.once.Do(func() {
+		wasm := js.Global().Get("WebAssembly")
+		if wasm.IsUndefined() {
+			return
+		}
+		for feature, module := range wasmFeatureTests {
+			bytes := js.Global().Get("Uint8Array").New(len(module))
+			js.CopyBytesToJS(bytes, module)
+			if !wasm.Call("validate", bytes).Bool() {
+				continue
+			}
+			// Shared memory validates everywhere, but is usable only when the
+			// page is cross-origin isolated
+			if feature == mesh.WASMFeatureThreads && js.Global().Get("SharedArrayBuffer").IsUndefined() {
+				continue
+			}
+			p.features = append(p.features, feature)
+		}
+	})
+	return p.features
+}
+
+func (p *kernelFeatureProber) ModuleLoadError(moduleID string) error {
+	if p.k.supervisor == nil {
+		return errors.New("supervisor not running")
+	}
+	return p.k.supervisor.ModuleLoadError(moduleID)
+}
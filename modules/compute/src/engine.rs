@@ -1,8 +1,21 @@
 use async_trait::async_trait;
+use std::cell::Cell;
 use std::collections::HashMap;
 use std::sync::Arc;
 use thiserror::Error;
 
+const WASM_PAGE_SIZE: u64 = 64 * 1024;
+
+// Resource names shared with the mesh sandbox (kernel/core/mesh/sandbox.go)
+pub const RESOURCE_FUEL: &str = "fuel";
+pub const RESOURCE_MEMORY: &str = "memory";
+pub const RESOURCE_WALL_CLOCK: &str = "wall_clock";
+
+thread_local! {
+    // (limit, used) for the job running on this thread; limit 0 = unmetered
+    static FUEL: Cell<(u64, u64)> = const { Cell::new((0, 0)) };
+}
+
 /// Core compute engine implementing the Unit Proxy pattern
 /// Thread-safe: Can be used in static context with multi-threading
 pub struct ComputeEngine {
@@ -46,6 +59,15 @@ pub struct ResourceLimits {
     pub max_fuel: u64,
 }
 
+/// Sandbox limits a delegated job carries in its JobRequest (fuelLimit,
+/// memoryLimit, timeout). Zero leaves the unit's own limit in place.
+#[derive(Clone, Copy, Debug, Default, PartialEq, Eq)]
+pub struct JobLimits {
+    pub fuel: u64,
+    pub memory_bytes: u64,
+    pub timeout_ms: u64,
+}
+
 impl Default for ResourceLimits {
     fn default() -> Self {
         Self::for_image()
@@ -117,6 +139,15 @@ pub enum ComputeError {
     #[error("Fuel exhausted (max: {max_fuel})")]
     FuelExhausted { max_fuel: u64 },
 
+    /// A job's sandbox limit was hit. The message is the mesh's wire form,
+    /// so the requester sees a structured ResourceExceededError.
+    #[error("resource_exceeded:{resource}:{limit}:{used}")]
+    ResourceExceeded {
+        resource: &'static str,
+        limit: u64,
+        used: u64,
+    },
+
     #[error("Invalid params: {0}")]
     InvalidParams(String),
 
@@ -162,6 +193,27 @@ impl ComputeEngine {
         action: &str,
         input: &[u8],
         params: &[u8],
+    ) -> Result<Vec<u8>, ComputeError> {
+        self.execute_with_limits(service, action, input, params, JobLimits::default())
+            .await
+    }
+
+    /// Execute a job under the unit's limits lowered to the job's own. A job
+    /// can tighten the sandbox but never loosen it.
+    ///
+    /// Units are compiled in rather than instantiated, so nothing counts
+    /// instructions: fuel is charged one unit per byte read or written, a
+    /// floor on the job's work, plus whatever the unit reports through
+    /// charge_fuel. Memory is the input plus what the linear memory grew by.
+    /// Execution is synchronous, so the wall clock is checked on return; the
+    /// kernel has already stopped waiting by then.
+    pub async fn execute_with_limits(
+        &self,
+        service: &str,
+        action: &str,
+        input: &[u8],
+        params: &[u8],
+        job: JobLimits,
     ) -> Result<Vec<u8>, ComputeError> {
         // 1. Get unit
         let unit = self
@@ -181,12 +233,46 @@ impl ComputeEngine {
         // 3. Validate params
         validate_params(params)?;
 
-        // 4. Execute
+        // 4. Apply the job's sandbox
+        let fuel_limit = lower_limit(limits.max_fuel, job.fuel);
+        let memory_limit = lower_limit(
+            limits.max_memory_pages as u64 * WASM_PAGE_SIZE,
+            job.memory_bytes,
+        );
+        let timeout_ms = lower_limit(limits.timeout_ms, job.timeout_ms);
+        check_memory(memory_limit, input.len() as u64)?;
+
+        FUEL.with(|f| f.set((fuel_limit, 0)));
+        charge_fuel(input.len() as u64)?;
+        let memory_before = linear_memory_bytes();
+        let started = sdk::js_interop::get_performance_now();
+
+        // 5. Execute
         // Note: tokio::time::timeout is removed because it causes hangs in WASM/block_on environments
         // without a running tokio reactor.
-        let output: Vec<u8> = unit.execute(action, input, params).await?;
+        let result = unit.execute(action, input, params).await;
+        let elapsed_ms = (sdk::js_interop::get_performance_now() - started).max(0.0) as u64;
+        let output = result.and_then(|output| {
+            charge_fuel(output.len() as u64)?;
+            Ok(output)
+        });
+        FUEL.with(|f| f.set((0, 0)));
+        let output: Vec<u8> = output?;
+
+        if timeout_ms > 0 && elapsed_ms > timeout_ms {
+            return Err(ComputeError::ResourceExceeded {
+                resource: RESOURCE_WALL_CLOCK,
+                limit: timeout_ms * 1_000_000,
+                used: elapsed_ms * 1_000_000,
+            });
+        }
+        let grown = linear_memory_bytes().saturating_sub(memory_before);
+        check_memory(
+            memory_limit,
+            input.len() as u64 + grown.max(output.len() as u64),
+        )?;
 
-        // 5. Validate output size
+        // 6. Validate output size
         if output.len() > limits.max_output_size {
             return Err(ComputeError::OutputTooLarge {
                 size: output.len(),
@@ -204,6 +290,54 @@ impl Default for ComputeEngine {
     }
 }
 
+/// Charges fuel to the job running on this thread. Long-running units call
+/// it from their loops so a job's fuel limit can stop them early.
+pub fn charge_fuel(amount: u64) -> Result<(), ComputeError> {
+    FUEL.with(|f| {
+        let (limit, used) = f.get();
+        let used = used.saturating_add(amount);
+        f.set((limit, used));
+        if limit > 0 && used > limit {
+            return Err(ComputeError::ResourceExceeded {
+                resource: RESOURCE_FUEL,
+                limit,
+                used,
+            });
+        }
+        Ok(())
+    })
+}
+
+fn lower_limit(limit: u64, requested: u64) -> u64 {
+    if requested > 0 && (limit == 0 || requested < limit) {
+        requested
+    } else {
+        limit
+    }
+}
+
+fn check_memory(limit: u64, used: u64) -> Result<(), ComputeError> {
+    if limit > 0 && used > limit {
+        return Err(ComputeError::ResourceExceeded {
+            resource: RESOURCE_MEMORY,
+            limit,
+            used,
+        });
+    }
+    Ok(())
+}
+
+fn linear_memory_bytes() -> u64 {
+    #[cfg(target_arch = "wasm32")]
+    {
+        core::arch::wasm32::memory_size(0) as u64 * WASM_PAGE_SIZE
+    }
+    #[cfg(not(target_arch = "wasm32"))]
+    {
+        0
+    }
+}
+
 /// Validate JSON params (if interpreted as JSON)
 fn validate_params(params: &[u8]) -> Result<(), ComputeError> {
     // 1. Size check
@@ -315,6 +449,50 @@ mod tests {
         assert!(matches!(result, Err(ComputeError::InputTooLarge { .. })));
     }
 
+    #[tokio::test]
+    async fn test_job_limits_tighten_the_sandbox() {
+        let mut engine = ComputeEngine::new();
+        engine.register(Arc::new(MockUnit));
+
+        let memory = JobLimits {
+            memory_bytes: 8,
+            ..JobLimits::default()
+        };
+        let result = engine
+            .execute_with_limits("mock", "echo", b"far too large", b"{}", memory)
+            .await;
+        assert!(matches!(
+            result,
+            Err(ComputeError::ResourceExceeded {
+                resource: RESOURCE_MEMORY,
+                limit: 8,
+                ..
+            })
+        ));
+
+        // double reads 4 bytes and writes 8: 12 units of fuel
+        let fuel = JobLimits {
+            fuel: 10,
+            ..JobLimits::default()
+        };
+        let err = engine
+            .execute_with_limits("mock", "double", b"test", b"{}", fuel)
+            .await
+            .unwrap_err();
+        assert_eq!(err.to_string(), "resource_exceeded:fuel:10:12");
+
+        let roomy = JobLimits {
+            fuel: 12,
+            memory_bytes: 1 << 20,
+            timeout_ms: 1000,
+        };
+        let output = engine
+            .execute_with_limits("mock", "double", b"test", b"{}", roomy)
+            .await
+            .unwrap();
+        assert_eq!(output, b"testtest");
+    }
+
     #[tokio::test]
     async fn test_invalid_params() {
         // params validation is now lenient for non-JSON, so "not json" might pass validation
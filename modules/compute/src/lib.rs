@@ -298,7 +298,8 @@ pub extern "C" fn compute_dispatch(request_ptr: *const u8, request_len: usize) -
     };
 
     let engine = get_engine();
-    let result = match poll_sync(engine.execute(service, action, input, params)) {
+    let run = engine.execute_with_limits(service, action, input, params, job_limits(&job));
+    let result = match poll_sync(run) {
         Ok(res) => res,
         Err(_) => return std::ptr::null_mut(),
     };
@@ -319,6 +320,16 @@ pub extern "C" fn compute_dispatch(request_ptr: *const u8, request_len: usize) -
     }
 }
 
+/// Sandbox limits the kernel copied into the request from the job's
+/// wasm_fuel_limit and wasm_memory_limit parameters and its deadline
+fn job_limits(job: &sdk::protocols::compute::compute::job_request::Reader) -> engine::JobLimits {
+    engine::JobLimits {
+        fuel: job.get_fuel_limit(),
+        memory_bytes: job.get_memory_limit(),
+        timeout_ms: job.get_timeout(),
+    }
+}
+
 /// Helper to poll a future once synchronously
 /// Panics or errors if the future yields (is not ready immediately)
 fn poll_sync<T>(future: impl std::future::Future<Output = T>) -> Result<T, String> {
@@ -537,7 +548,9 @@ impl ComputeKernel {
             input.len()
         );
 
-        self.engine.execute(library, method, input, params).await
+        self.engine
+            .execute_with_limits(library, method, input, params, job_limits(&job))
+            .await
     }
 
     /// Helper to serialize JobResult
@@ -110,6 +110,166 @@ mod layout_tests {
         assert!(SAB_SIZE_DEFAULT > 0);
         assert!(SAB_SIZE_DEFAULT <= 64 * 1024 * 1024); // Max 64MB (Wait, max is 64 in layout.rs, test said 256)
     }
+
+    // Same record the kernel's encoder is checked against in
+    // kernel/threads/sab/mesh_metrics_test.go
+    const MESH_METRICS_GOLDEN: &str =
+        include_str!("../../../kernel/threads/sab/testdata/mesh_metrics.hex");
+
+    #[test]
+    fn test_mesh_metrics_golden_record() {
+        let hex = MESH_METRICS_GOLDEN.trim();
+        let buf: Vec<u8> = (0..hex.len())
+            .step_by(2)
+            .map(|i| u8::from_str_radix(&hex[i..i + 2], 16).unwrap())
+            .collect();
+        assert_eq!(buf.len(), MESH_METRICS_RECORD_SIZE);
+        assert!(MESH_METRICS_RECORD_SIZE <= SIZE_MESH_METRICS);
+
+        let u32_at = |off: usize| u32::from_le_bytes(buf[off..off + 4].try_into().unwrap());
+        let u64_at = |off: usize| u64::from_le_bytes(buf[off..off + 8].try_into().unwrap());
+        let f32_at = |off: usize| f32::from_le_bytes(buf[off..off + 4].try_into().unwrap());
+
+        assert_eq!(u32_at(MESH_METRICS_TOTAL_PEERS), 42);
+        assert_eq!(u32_at(MESH_METRICS_CONNECTED_PEERS), 17);
+        assert_eq!(u32_at(MESH_METRICS_DHT_ENTRIES), 256);
+        assert_eq!(f32_at(MESH_METRICS_GOSSIP_RATE), 12.5);
+        assert_eq!(f32_at(MESH_METRICS_AVG_REPUTATION), 0.75);
+        assert_eq!(u32_at(MESH_METRICS_REGION_ID), 0xDEAD_BEEF);
+        assert_eq!(u64_at(MESH_METRICS_BYTES_SENT), 0x0102_0304_0506_0708);
+        assert_eq!(u64_at(MESH_METRICS_BYTES_RECEIVED), 1 << 40);
+        assert_eq!(f32_at(MESH_METRICS_P50_LATENCY), 3.25);
+        assert_eq!(f32_at(MESH_METRICS_P95_LATENCY), 48.5);
+        assert_eq!(f32_at(MESH_METRICS_CONNECTION_SUCCESS), 0.5);
+        assert_eq!(f32_at(MESH_METRICS_FETCH_SUCCESS), 0.875);
+        assert_eq!(u32_at(MESH_METRICS_LOCAL_CHUNKS), 9);
+        assert_eq!(u32_at(MESH_METRICS_TOTAL_CHUNKS), 1234);
+        assert_eq!(u32_at(MESH_METRICS_SECTOR_ID), 7);
+        assert_eq!(u32_at(MESH_METRICS_SECTOR_MEMBERS), 5);
+    }
+
+    // Same block the kernel's encoder is checked against in
+    // kernel/threads/sab/health_block_test.go
+    const HEALTH_BLOCK_GOLDEN: &str =
+        include_str!("../../../kernel/threads/sab/testdata/health_block.hex");
+
+    #[test]
+    fn test_health_block_golden_record() {
+        let hex = HEALTH_BLOCK_GOLDEN.trim();
+        let buf: Vec<u8> = (0..hex.len())
+            .step_by(2)
+            .map(|i| u8::from_str_radix(&hex[i..i + 2], 16).unwrap())
+            .collect();
+        assert_eq!(
+            buf.len(),
+            HEALTH_HEADER_SIZE + HEALTH_SECTION_COUNT * HEALTH_SECTION_SIZE
+        );
+        assert!(buf.len() <= SIZE_HEALTH_BLOCK);
+
+        let u32_at = |off: usize| u32::from_le_bytes(buf[off..off + 4].try_into().unwrap());
+        let u64_at = |off: usize| u64::from_le_bytes(buf[off..off + 8].try_into().unwrap());
+        let i64_at = |off: usize| i64::from_le_bytes(buf[off..off + 8].try_into().unwrap());
+        let f32_at = |off: usize| f32::from_le_bytes(buf[off..off + 4].try_into().unwrap());
+        let f64_at = |off: usize| f64::from_le_bytes(buf[off..off + 8].try_into().unwrap());
+        let section = |index: usize| HEALTH_HEADER_SIZE + index * HEALTH_SECTION_SIZE;
+
+        assert_eq!(u32_at(HEALTH_HEADER_SEQUENCE), 42);
+        assert_eq!(u32_at(HEALTH_HEADER_VERSION) as usize, HEALTH_BLOCK_VERSION);
+        assert_eq!(
+            u32_at(HEALTH_HEADER_SECTION_COUNT) as usize,
+            HEALTH_SECTION_COUNT
+        );
+        assert_eq!(
+            u32_at(HEALTH_HEADER_SECTION_SIZE) as usize,
+            HEALTH_SECTION_SIZE
+        );
+        assert_eq!(f64_at(HEALTH_HEADER_WRITTEN_AT), 1_700_000_000_123.0);
+
+        let mesh = section(HEALTH_SECTION_MESH);
+        assert_eq!(u32_at(mesh + HEALTH_SECTION_STATUS), 1);
+        assert_eq!(f32_at(mesh + HEALTH_SECTION_SCORE), 0.75);
+        assert_eq!(u32_at(mesh + HEALTH_MESH_TOTAL_PEERS), 40);
+        assert_eq!(u32_at(mesh + HEALTH_MESH_CONNECTED_PEERS), 12);
+        assert_eq!(u32_at(mesh + HEALTH_MESH_LOCAL_CHUNKS), 300);
+        assert_eq!(u32_at(mesh + HEALTH_MESH_CHUNKS_AVAILABLE), 4500);
+        assert_eq!(f32_at(mesh + HEALTH_MESH_AVG_REPUTATION), 0.625);
+        assert_eq!(u32_at(mesh + HEALTH_MESH_SECTOR_ID), 7);
+        assert_eq!(u32_at(mesh + HEALTH_MESH_SECTOR_MEMBERS), 5);
+        assert_eq!(u32_at(mesh + HEALTH_MESH_REGION_ID), 0xDEAD_BEEF);
+        assert_eq!(f32_at(mesh + HEALTH_MESH_FETCH_SUCCESS), 0.875);
+        assert_eq!(u32_at(mesh + HEALTH_MESH_BUSY), 1);
+        assert_eq!(u64_at(mesh + HEALTH_MESH_INBOUND_HELD_BYTES), 1 << 33);
+
+        let gossip = section(HEALTH_SECTION_GOSSIP);
+        assert_eq!(u32_at(gossip + HEALTH_SECTION_STATUS), 2);
+        assert_eq!(f32_at(gossip + HEALTH_SECTION_SCORE), 0.5);
+        assert_eq!(u64_at(gossip + HEALTH_GOSSIP_SENT), 1001);
+        assert_eq!(u64_at(gossip + HEALTH_GOSSIP_RECEIVED), 2002);
+        assert_eq!(u64_at(gossip + HEALTH_GOSSIP_DROPPED), 3);
+        assert_eq!(u64_at(gossip + HEALTH_GOSSIP_RATE_LIMITED), 4);
+        assert_eq!(u64_at(gossip + HEALTH_GOSSIP_FAILED_SIGNATURES), 5);
+        assert_eq!(u32_at(gossip + HEALTH_GOSSIP_QUEUE_LENGTH), 6);
+        assert_eq!(u32_at(gossip + HEALTH_GOSSIP_FANOUT), 8);
+        assert_eq!(f32_at(gossip + HEALTH_GOSSIP_MESSAGE_RATE), 12.5);
+        assert_eq!(f32_at(gossip + HEALTH_GOSSIP_DELIVERY_RATIO), 0.96875);
+        assert_eq!(f32_at(gossip + HEALTH_GOSSIP_DUPLICATE_RATIO), 0.125);
+        assert_eq!(f32_at(gossip + HEALTH_GOSSIP_PROPAGATION_P95), 48.5);
+        assert_eq!(u32_at(gossip + HEALTH_GOSSIP_NETWORK_SIZE), 1024);
+
+        let dht = section(HEALTH_SECTION_DHT);
+        assert_eq!(u32_at(dht + HEALTH_SECTION_STATUS), 1);
+        assert_eq!(f32_at(dht + HEALTH_SECTION_SCORE), 1.0);
+        assert_eq!(u32_at(dht + HEALTH_DHT_ENTRIES), 256);
+        assert_eq!(u32_at(dht + HEALTH_DHT_PEERS), 20);
+        assert_eq!(u32_at(dht + HEALTH_DHT_NETWORK_SIZE), 1000);
+        assert_eq!(f32_at(dht + HEALTH_DHT_LOOKUP_SUCCESS), 0.9375);
+        assert_eq!(u32_at(dht + HEALTH_DHT_LOOKUP_P95), 180);
+        assert_eq!(u64_at(dht + HEALTH_DHT_QUERIES), 5000);
+        assert_eq!(u64_at(dht + HEALTH_DHT_FAILED_QUERIES), 17);
+        assert_eq!(u64_at(dht + HEALTH_DHT_REJECTED_NODE_IDS), 2);
+        assert_eq!(u64_at(dht + HEALTH_DHT_INVALID_NODE_REPLIES), 9);
+
+        let transport = section(HEALTH_SECTION_TRANSPORT);
+        assert_eq!(u32_at(transport + HEALTH_SECTION_STATUS), 3);
+        assert_eq!(f32_at(transport + HEALTH_SECTION_SCORE), 0.25);
+        assert_eq!(u32_at(transport + HEALTH_TRANSPORT_ACTIVE_CONNECTIONS), 11);
+        assert_eq!(u32_at(transport + HEALTH_TRANSPORT_WEBSOCKET_FALLBACKS), 2);
+        assert_eq!(
+            u64_at(transport + HEALTH_TRANSPORT_BYTES_SENT),
+            0x0102_0304_0506_0708
+        );
+        assert_eq!(u64_at(transport + HEALTH_TRANSPORT_BYTES_RECEIVED), 1 << 40);
+        assert_eq!(u64_at(transport + HEALTH_TRANSPORT_MESSAGES_SENT), 7000);
+        assert_eq!(u64_at(transport + HEALTH_TRANSPORT_MESSAGES_RECEIVED), 6999);
+        assert_eq!(u64_at(transport + HEALTH_TRANSPORT_FAILED_MESSAGES), 13);
+        assert_eq!(f32_at(transport + HEALTH_TRANSPORT_LATENCY_P50), 3.25);
+        assert_eq!(f32_at(transport + HEALTH_TRANSPORT_LATENCY_P95), 20.5);
+        assert_eq!(f32_at(transport + HEALTH_TRANSPORT_LATENCY_P99), 120.5);
+        assert_eq!(f32_at(transport + HEALTH_TRANSPORT_ERROR_RATE), 0.0625);
+        assert_eq!(u64_at(transport + HEALTH_TRANSPORT_TOTAL_CONNECTIONS), 77);
+
+        let economy = section(HEALTH_SECTION_ECONOMY);
+        assert_eq!(u32_at(economy + HEALTH_SECTION_STATUS), 1);
+        assert_eq!(f32_at(economy + HEALTH_SECTION_SCORE), 1.0);
+        assert_eq!(i64_at(economy + HEALTH_ECONOMY_BALANCE), -1500);
+        assert_eq!(u64_at(economy + HEALTH_ECONOMY_ESCROWED), 200);
+        assert_eq!(u64_at(economy + HEALTH_ECONOMY_SETTLED), 3000);
+        assert_eq!(u64_at(economy + HEALTH_ECONOMY_REFUNDED), 40);
+        assert_eq!(u64_at(economy + HEALTH_ECONOMY_SLASHED), 5);
+        assert_eq!(u32_at(economy + HEALTH_ECONOMY_ACTIVE_ESCROWS), 3);
+        assert_eq!(u32_at(economy + HEALTH_ECONOMY_ACCOUNTS), 9);
+        assert_eq!(u64_at(economy + HEALTH_ECONOMY_SETTLEMENTS), 60);
+        assert_eq!(u64_at(economy + HEALTH_ECONOMY_JOBS_EXECUTED), 70);
+        assert_eq!(u64_at(economy + HEALTH_ECONOMY_JOBS_FAILED), 8);
+
+        let supervisor = section(HEALTH_SECTION_SUPERVISOR);
+        assert_eq!(u32_at(supervisor + HEALTH_SECTION_STATUS), 2);
+        assert_eq!(f32_at(supervisor + HEALTH_SECTION_SCORE), 0.5);
+        assert_eq!(u32_at(supervisor + HEALTH_SUPERVISOR_ACTIVE_THREADS), 6);
+        assert_eq!(u32_at(supervisor + HEALTH_SUPERVISOR_FAILED_THREADS), 1);
+        assert_eq!(u32_at(supervisor + HEALTH_SUPERVISOR_RESTARTED_THREADS), 2);
+        assert_eq!(u64_at(supervisor + HEALTH_SUPERVISOR_MESSAGES), 123456);
+    }
 }
 
 #[cfg(test)]
@@ -38,6 +38,13 @@ pub const MAX_MODULES_TOTAL: usize = sab::MAX_MODULES_TOTAL as usize;
 pub const OFFSET_BLOOM_FILTER: usize = sab::OFFSET_BLOOM_FILTER as usize;
 pub const SIZE_BLOOM_FILTER: usize = sab::SIZE_BLOOM_FILTER as usize;
 
+/// Layout Header (1KB) - written by the kernel; check before trusting offsets
+pub const OFFSET_LAYOUT_HEADER: usize = sab::OFFSET_LAYOUT_HEADER as usize;
+pub const SIZE_LAYOUT_HEADER: usize = sab::SIZE_LAYOUT_HEADER as usize;
+pub const LAYOUT_MAGIC: u32 = sab::LAYOUT_MAGIC;
+pub const LAYOUT_VERSION: u32 = sab::LAYOUT_VERSION;
+pub const LAYOUT_REGION_ENTRY_SIZE: usize = sab::LAYOUT_REGION_ENTRY_SIZE as usize;
+
 /// Supervisor Headers (4KB)
 pub const OFFSET_SUPERVISOR_HEADERS: usize = sab::OFFSET_SUPERVISOR_HEADERS as usize;
 pub const SIZE_SUPERVISOR_HEADERS: usize = sab::SIZE_SUPERVISOR_HEADERS as usize;
@@ -47,6 +54,27 @@ pub const MAX_SUPERVISORS_INLINE: usize = sab::MAX_SUPERVISORS_INLINE as usize;
 pub const OFFSET_SYSCALL_TABLE: usize = sab::OFFSET_SYSCALL_TABLE as usize;
 pub const SIZE_SYSCALL_TABLE: usize = sab::SIZE_SYSCALL_TABLE as usize;
 
+/// Mesh Metrics (256 bytes) - kernel telemetry, little-endian fields below
+pub const OFFSET_MESH_METRICS: usize = sab::OFFSET_MESH_METRICS as usize;
+pub const SIZE_MESH_METRICS: usize = sab::SIZE_MESH_METRICS as usize;
+pub const MESH_METRICS_TOTAL_PEERS: usize = sab::MESH_METRICS_TOTAL_PEERS as usize;
+pub const MESH_METRICS_CONNECTED_PEERS: usize = sab::MESH_METRICS_CONNECTED_PEERS as usize;
+pub const MESH_METRICS_DHT_ENTRIES: usize = sab::MESH_METRICS_DHT_ENTRIES as usize;
+pub const MESH_METRICS_GOSSIP_RATE: usize = sab::MESH_METRICS_GOSSIP_RATE as usize;
+pub const MESH_METRICS_AVG_REPUTATION: usize = sab::MESH_METRICS_AVG_REPUTATION as usize;
+pub const MESH_METRICS_REGION_ID: usize = sab::MESH_METRICS_REGION_ID as usize;
+pub const MESH_METRICS_BYTES_SENT: usize = sab::MESH_METRICS_BYTES_SENT as usize;
+pub const MESH_METRICS_BYTES_RECEIVED: usize = sab::MESH_METRICS_BYTES_RECEIVED as usize;
+pub const MESH_METRICS_P50_LATENCY: usize = sab::MESH_METRICS_P50_LATENCY as usize;
+pub const MESH_METRICS_P95_LATENCY: usize = sab::MESH_METRICS_P95_LATENCY as usize;
+pub const MESH_METRICS_CONNECTION_SUCCESS: usize = sab::MESH_METRICS_CONNECTION_SUCCESS as usize;
+pub const MESH_METRICS_FETCH_SUCCESS: usize = sab::MESH_METRICS_FETCH_SUCCESS as usize;
+pub const MESH_METRICS_LOCAL_CHUNKS: usize = sab::MESH_METRICS_LOCAL_CHUNKS as usize;
+pub const MESH_METRICS_TOTAL_CHUNKS: usize = sab::MESH_METRICS_TOTAL_CHUNKS as usize;
+pub const MESH_METRICS_SECTOR_ID: usize = sab::MESH_METRICS_SECTOR_ID as usize;
+pub const MESH_METRICS_SECTOR_MEMBERS: usize = sab::MESH_METRICS_SECTOR_MEMBERS as usize;
+pub const MESH_METRICS_RECORD_SIZE: usize = sab::MESH_METRICS_RECORD_SIZE as usize;
+
 /// Economics Region (16KB)
 pub const OFFSET_ECONOMICS: usize = sab::OFFSET_ECONOMICS as usize;
 pub const SIZE_ECONOMICS: usize = sab::SIZE_ECONOMICS as usize;
@@ -80,6 +108,17 @@ pub const SIZE_OUTBOX: usize = sab::SIZE_OUTBOX_KERNEL_TOTAL as usize;
 pub const OFFSET_SAB_INBOX: usize = sab::OFFSET_INBOX_BASE as usize;
 pub const OFFSET_SAB_OUTBOX: usize = sab::OFFSET_OUTBOX_KERNEL_BASE as usize;
 
+/// Inbox priority lanes (control, compute, bulk), each a separate ring
+pub const OFFSET_INBOX_CONTROL: usize = sab::OFFSET_INBOX_CONTROL as usize;
+pub const SIZE_INBOX_CONTROL: usize = sab::SIZE_INBOX_CONTROL as usize;
+pub const OFFSET_INBOX_COMPUTE: usize = sab::OFFSET_INBOX_COMPUTE as usize;
+pub const SIZE_INBOX_COMPUTE: usize = sab::SIZE_INBOX_COMPUTE as usize;
+pub const OFFSET_INBOX_BULK: usize = sab::OFFSET_INBOX_BULK as usize;
+pub const SIZE_INBOX_BULK: usize = sab::SIZE_INBOX_BULK as usize;
+pub const INBOX_WEIGHT_CONTROL: u32 = sab::INBOX_WEIGHT_CONTROL;
+pub const INBOX_WEIGHT_COMPUTE: u32 = sab::INBOX_WEIGHT_COMPUTE;
+pub const INBOX_WEIGHT_BULK: u32 = sab::INBOX_WEIGHT_BULK;
+
 // ========== ARENA REGIONS ==========
 
 pub const OFFSET_ARENA: usize = sab::OFFSET_ARENA as usize;
@@ -91,6 +130,94 @@ pub const SIZE_DIAGNOSTICS: usize = sab::SIZE_DIAGNOSTICS as usize;
 pub const OFFSET_BRIDGE_METRICS: usize = OFFSET_DIAGNOSTICS + 0x800;
 pub const SIZE_BRIDGE_METRICS: usize = 0x100;
 
+/// Host frame schedule (idle window between animation frames)
+pub const OFFSET_FRAME_SCHEDULE: usize = sab::OFFSET_FRAME_SCHEDULE as usize;
+pub const SIZE_FRAME_SCHEDULE: usize = sab::SIZE_FRAME_SCHEDULE as usize;
+
+/// Per-subsystem health block (seqlock; odd sequence while being written)
+pub const OFFSET_HEALTH_BLOCK: usize = sab::OFFSET_HEALTH_BLOCK as usize;
+pub const SIZE_HEALTH_BLOCK: usize = sab::SIZE_HEALTH_BLOCK as usize;
+pub const HEALTH_BLOCK_VERSION: usize = sab::HEALTH_BLOCK_VERSION as usize;
+pub const HEALTH_HEADER_SEQUENCE: usize = sab::HEALTH_HEADER_SEQUENCE as usize;
+pub const HEALTH_HEADER_VERSION: usize = sab::HEALTH_HEADER_VERSION as usize;
+pub const HEALTH_HEADER_SECTION_COUNT: usize = sab::HEALTH_HEADER_SECTION_COUNT as usize;
+pub const HEALTH_HEADER_SECTION_SIZE: usize = sab::HEALTH_HEADER_SECTION_SIZE as usize;
+pub const HEALTH_HEADER_WRITTEN_AT: usize = sab::HEALTH_HEADER_WRITTEN_AT as usize;
+pub const HEALTH_HEADER_SIZE: usize = sab::HEALTH_HEADER_SIZE as usize;
+pub const HEALTH_SECTION_SIZE: usize = sab::HEALTH_SECTION_SIZE as usize;
+pub const HEALTH_SECTION_COUNT: usize = sab::HEALTH_SECTION_COUNT as usize;
+pub const HEALTH_SECTION_MESH: usize = sab::HEALTH_SECTION_MESH as usize;
+pub const HEALTH_SECTION_GOSSIP: usize = sab::HEALTH_SECTION_GOSSIP as usize;
+pub const HEALTH_SECTION_DHT: usize = sab::HEALTH_SECTION_DHT as usize;
+pub const HEALTH_SECTION_TRANSPORT: usize = sab::HEALTH_SECTION_TRANSPORT as usize;
+pub const HEALTH_SECTION_ECONOMY: usize = sab::HEALTH_SECTION_ECONOMY as usize;
+pub const HEALTH_SECTION_SUPERVISOR: usize = sab::HEALTH_SECTION_SUPERVISOR as usize;
+pub const HEALTH_SECTION_STATUS: usize = sab::HEALTH_SECTION_STATUS as usize;
+pub const HEALTH_SECTION_SCORE: usize = sab::HEALTH_SECTION_SCORE as usize;
+pub const HEALTH_MESH_TOTAL_PEERS: usize = sab::HEALTH_MESH_TOTAL_PEERS as usize;
+pub const HEALTH_MESH_CONNECTED_PEERS: usize = sab::HEALTH_MESH_CONNECTED_PEERS as usize;
+pub const HEALTH_MESH_LOCAL_CHUNKS: usize = sab::HEALTH_MESH_LOCAL_CHUNKS as usize;
+pub const HEALTH_MESH_CHUNKS_AVAILABLE: usize = sab::HEALTH_MESH_CHUNKS_AVAILABLE as usize;
+pub const HEALTH_MESH_AVG_REPUTATION: usize = sab::HEALTH_MESH_AVG_REPUTATION as usize;
+pub const HEALTH_MESH_SECTOR_ID: usize = sab::HEALTH_MESH_SECTOR_ID as usize;
+pub const HEALTH_MESH_SECTOR_MEMBERS: usize = sab::HEALTH_MESH_SECTOR_MEMBERS as usize;
+pub const HEALTH_MESH_REGION_ID: usize = sab::HEALTH_MESH_REGION_ID as usize;
+pub const HEALTH_MESH_FETCH_SUCCESS: usize = sab::HEALTH_MESH_FETCH_SUCCESS as usize;
+pub const HEALTH_MESH_BUSY: usize = sab::HEALTH_MESH_BUSY as usize;
+pub const HEALTH_MESH_INBOUND_HELD_BYTES: usize = sab::HEALTH_MESH_INBOUND_HELD_BYTES as usize;
+pub const HEALTH_GOSSIP_SENT: usize = sab::HEALTH_GOSSIP_SENT as usize;
+pub const HEALTH_GOSSIP_RECEIVED: usize = sab::HEALTH_GOSSIP_RECEIVED as usize;
+pub const HEALTH_GOSSIP_DROPPED: usize = sab::HEALTH_GOSSIP_DROPPED as usize;
+pub const HEALTH_GOSSIP_RATE_LIMITED: usize = sab::HEALTH_GOSSIP_RATE_LIMITED as usize;
+pub const HEALTH_GOSSIP_FAILED_SIGNATURES: usize = sab::HEALTH_GOSSIP_FAILED_SIGNATURES as usize;
+pub const HEALTH_GOSSIP_QUEUE_LENGTH: usize = sab::HEALTH_GOSSIP_QUEUE_LENGTH as usize;
+pub const HEALTH_GOSSIP_FANOUT: usize = sab::HEALTH_GOSSIP_FANOUT as usize;
+pub const HEALTH_GOSSIP_MESSAGE_RATE: usize = sab::HEALTH_GOSSIP_MESSAGE_RATE as usize;
+pub const HEALTH_GOSSIP_DELIVERY_RATIO: usize = sab::HEALTH_GOSSIP_DELIVERY_RATIO as usize;
+pub const HEALTH_GOSSIP_DUPLICATE_RATIO: usize = sab::HEALTH_GOSSIP_DUPLICATE_RATIO as usize;
+pub const HEALTH_GOSSIP_PROPAGATION_P95: usize = sab::HEALTH_GOSSIP_PROPAGATION_P95 as usize;
+pub const HEALTH_GOSSIP_NETWORK_SIZE: usize = sab::HEALTH_GOSSIP_NETWORK_SIZE as usize;
+pub const HEALTH_DHT_ENTRIES: usize = sab::HEALTH_DHT_ENTRIES as usize;
+pub const HEALTH_DHT_PEERS: usize = sab::HEALTH_DHT_PEERS as usize;
+pub const HEALTH_DHT_NETWORK_SIZE: usize = sab::HEALTH_DHT_NETWORK_SIZE as usize;
+pub const HEALTH_DHT_LOOKUP_SUCCESS: usize = sab::HEALTH_DHT_LOOKUP_SUCCESS as usize;
+pub const HEALTH_DHT_LOOKUP_P95: usize = sab::HEALTH_DHT_LOOKUP_P95 as usize;
+pub const HEALTH_DHT_QUERIES: usize = sab::HEALTH_DHT_QUERIES as usize;
+pub const HEALTH_DHT_FAILED_QUERIES: usize = sab::HEALTH_DHT_FAILED_QUERIES as usize;
+pub const HEALTH_DHT_REJECTED_NODE_IDS: usize = sab::HEALTH_DHT_REJECTED_NODE_IDS as usize;
+pub const HEALTH_DHT_INVALID_NODE_REPLIES: usize = sab::HEALTH_DHT_INVALID_NODE_REPLIES as usize;
+pub const HEALTH_TRANSPORT_ACTIVE_CONNECTIONS: usize =
+    sab::HEALTH_TRANSPORT_ACTIVE_CONNECTIONS as usize;
+pub const HEALTH_TRANSPORT_WEBSOCKET_FALLBACKS: usize =
+    sab::HEALTH_TRANSPORT_WEBSOCKET_FALLBACKS as usize;
+pub const HEALTH_TRANSPORT_BYTES_SENT: usize = sab::HEALTH_TRANSPORT_BYTES_SENT as usize;
+pub const HEALTH_TRANSPORT_BYTES_RECEIVED: usize = sab::HEALTH_TRANSPORT_BYTES_RECEIVED as usize;
+pub const HEALTH_TRANSPORT_MESSAGES_SENT: usize = sab::HEALTH_TRANSPORT_MESSAGES_SENT as usize;
+pub const HEALTH_TRANSPORT_MESSAGES_RECEIVED: usize =
+    sab::HEALTH_TRANSPORT_MESSAGES_RECEIVED as usize;
+pub const HEALTH_TRANSPORT_FAILED_MESSAGES: usize = sab::HEALTH_TRANSPORT_FAILED_MESSAGES as usize;
+pub const HEALTH_TRANSPORT_LATENCY_P50: usize = sab::HEALTH_TRANSPORT_LATENCY_P50 as usize;
+pub const HEALTH_TRANSPORT_LATENCY_P95: usize = sab::HEALTH_TRANSPORT_LATENCY_P95 as usize;
+pub const HEALTH_TRANSPORT_LATENCY_P99: usize = sab::HEALTH_TRANSPORT_LATENCY_P99 as usize;
+pub const HEALTH_TRANSPORT_ERROR_RATE: usize = sab::HEALTH_TRANSPORT_ERROR_RATE as usize;
+pub const HEALTH_TRANSPORT_TOTAL_CONNECTIONS: usize =
+    sab::HEALTH_TRANSPORT_TOTAL_CONNECTIONS as usize;
+pub const HEALTH_ECONOMY_BALANCE: usize = sab::HEALTH_ECONOMY_BALANCE as usize;
+pub const HEALTH_ECONOMY_ESCROWED: usize = sab::HEALTH_ECONOMY_ESCROWED as usize;
+pub const HEALTH_ECONOMY_SETTLED: usize = sab::HEALTH_ECONOMY_SETTLED as usize;
+pub const HEALTH_ECONOMY_REFUNDED: usize = sab::HEALTH_ECONOMY_REFUNDED as usize;
+pub const HEALTH_ECONOMY_SLASHED: usize = sab::HEALTH_ECONOMY_SLASHED as usize;
+pub const HEALTH_ECONOMY_ACTIVE_ESCROWS: usize = sab::HEALTH_ECONOMY_ACTIVE_ESCROWS as usize;
+pub const HEALTH_ECONOMY_ACCOUNTS: usize = sab::HEALTH_ECONOMY_ACCOUNTS as usize;
+pub const HEALTH_ECONOMY_SETTLEMENTS: usize = sab::HEALTH_ECONOMY_SETTLEMENTS as usize;
+pub const HEALTH_ECONOMY_JOBS_EXECUTED: usize = sab::HEALTH_ECONOMY_JOBS_EXECUTED as usize;
+pub const HEALTH_ECONOMY_JOBS_FAILED: usize = sab::HEALTH_ECONOMY_JOBS_FAILED as usize;
+pub const HEALTH_SUPERVISOR_ACTIVE_THREADS: usize = sab::HEALTH_SUPERVISOR_ACTIVE_THREADS as usize;
+pub const HEALTH_SUPERVISOR_FAILED_THREADS: usize = sab::HEALTH_SUPERVISOR_FAILED_THREADS as usize;
+pub const HEALTH_SUPERVISOR_RESTARTED_THREADS: usize =
+    sab::HEALTH_SUPERVISOR_RESTARTED_THREADS as usize;
+pub const HEALTH_SUPERVISOR_MESSAGES: usize = sab::HEALTH_SUPERVISOR_MESSAGES as usize;
+
 /// Async Request/Response Queues
 pub const OFFSET_ARENA_REQUEST_QUEUE: usize = sab::OFFSET_ARENA_REQUEST_QUEUE as usize;
 pub const OFFSET_ARENA_RESPONSE_QUEUE: usize = sab::OFFSET_ARENA_RESPONSE_QUEUE as usize;
@@ -113,6 +240,11 @@ pub const SIZE_BIRD_STATE: usize = sab::SIZE_BIRD_STATE as usize;
 pub const OFFSET_PINGPONG_CONTROL: usize = sab::OFFSET_PINGPONG_CONTROL as usize;
 pub const SIZE_PINGPONG_CONTROL: usize = sab::SIZE_PINGPONG_CONTROL as usize;
 
+/// Kernel crash dump (post-mortem report, survives kernel reload)
+pub const OFFSET_CRASH_DUMP: usize = sab::OFFSET_CRASH_DUMP as usize;
+pub const SIZE_CRASH_DUMP: usize = sab::SIZE_CRASH_DUMP as usize;
+pub const CRASH_DUMP_MAGIC: u32 = sab::CRASH_DUMP_MAGIC;
+
 /// Bird Population Data (Dual Buffers)
 pub const OFFSET_BIRD_BUFFER_A: usize = sab::OFFSET_BIRD_BUFFER_A as usize;
 pub const OFFSET_BIRD_BUFFER_B: usize = sab::OFFSET_BIRD_BUFFER_B as usize;
@@ -125,6 +257,11 @@ pub const OFFSET_MATRIX_BUFFER_B: usize = sab::OFFSET_MATRIX_BUFFER_B as usize;
 pub const SIZE_MATRIX_BUFFER: usize = sab::SIZE_MATRIX_BUFFER as usize;
 pub const MATRIX_STRIDE: usize = sab::MATRIX_STRIDE as usize;
 
+/// Dynamic Region Registry (4KB) - names, offsets and sizes of runtime regions
+pub const OFFSET_DYNAMIC_REGISTRY: usize = sab::OFFSET_DYNAMIC_REGISTRY as usize;
+pub const SIZE_DYNAMIC_REGISTRY: usize = sab::SIZE_DYNAMIC_REGISTRY as usize;
+pub const DYNAMIC_REGION_ENTRY_SIZE: usize = sab::DYNAMIC_REGION_ENTRY_SIZE as usize;
+
 // ========== EPOCH INDEX ALLOCATION ==========
 
 pub const IDX_KERNEL_READY: u32 = sab::IDX_KERNEL_READY;
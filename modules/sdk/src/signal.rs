@@ -5,13 +5,34 @@ pub use crate::layout::{
     IDX_SYSTEM_EPOCH, OFFSET_SAB_INBOX, OFFSET_SAB_OUTBOX, SIZE_INBOX, SIZE_OUTBOX,
 };
 
+use crate::layout::{
+    INBOX_WEIGHT_BULK, INBOX_WEIGHT_COMPUTE, INBOX_WEIGHT_CONTROL, OFFSET_INBOX_BULK,
+    OFFSET_INBOX_COMPUTE, OFFSET_INBOX_CONTROL, SIZE_INBOX_BULK, SIZE_INBOX_COMPUTE,
+    SIZE_INBOX_CONTROL,
+};
 use crate::ringbuffer::RingBuffer;
 use crate::sab::SafeSAB;
+use std::cell::Cell;
+
+/// Inbox lanes in drain order: control, compute, bulk
+pub const LANE_CONTROL: usize = 0;
+pub const LANE_COMPUTE: usize = 1;
+pub const LANE_BULK: usize = 2;
+
+const LANE_WEIGHTS: [u32; 3] = [
+    INBOX_WEIGHT_CONTROL,
+    INBOX_WEIGHT_COMPUTE,
+    INBOX_WEIGHT_BULK,
+];
 
 pub struct Reactor {
     flags: SafeSAB,
-    pub inbox: RingBuffer,
+    /// Inbox priority lanes, indexed by LANE_*
+    pub inbox: [RingBuffer; 3],
     pub outbox: RingBuffer,
+    // Weighted round robin position: current lane and messages taken from it
+    lane: Cell<usize>,
+    served: Cell<u32>,
 }
 
 impl Reactor {
@@ -20,7 +41,23 @@ impl Reactor {
         // We use a shared view of the first 1024 bytes of the provided SafeSAB (which is already offset-scoped)
         let flags = SafeSAB::new_shared_view(sab.inner(), sab.base_offset() as u32, 1024);
 
-        let inbox = RingBuffer::new(sab.clone(), OFFSET_SAB_INBOX as u32, SIZE_INBOX as u32);
+        let inbox = [
+            RingBuffer::new(
+                sab.clone(),
+                OFFSET_INBOX_CONTROL as u32,
+                SIZE_INBOX_CONTROL as u32,
+            ),
+            RingBuffer::new(
+                sab.clone(),
+                OFFSET_INBOX_COMPUTE as u32,
+                SIZE_INBOX_COMPUTE as u32,
+            ),
+            RingBuffer::new(
+                sab.clone(),
+                OFFSET_INBOX_BULK as u32,
+                SIZE_INBOX_BULK as u32,
+            ),
+        ];
 
         let outbox = RingBuffer::new(sab.clone(), OFFSET_SAB_OUTBOX as u32, SIZE_OUTBOX as u32);
 
@@ -28,6 +65,8 @@ impl Reactor {
             flags,
             inbox,
             outbox,
+            lane: Cell::new(LANE_CONTROL),
+            served: Cell::new(0),
         }
     }
 
@@ -43,9 +82,34 @@ impl Reactor {
         crate::js_interop::atomic_add(self.flags.barrier_view(), IDX_OUTBOX_KERNEL_DIRTY, 1);
     }
 
-    /// Read next message from Inbox (Ring Buffer)
+    /// Read next message from the inbox lanes. Lanes are served by weighted
+    /// round robin, so a flood on the bulk lane cannot starve control
+    /// messages; the position carries over between calls.
     pub fn read_request(&self) -> Option<Vec<u8>> {
-        self.inbox.read_message().unwrap_or(None)
+        let mut idle = 0;
+        while idle < self.inbox.len() {
+            let lane = self.lane.get();
+            if self.served.get() >= LANE_WEIGHTS[lane] {
+                self.advance_lane();
+                continue;
+            }
+            match self.inbox[lane].read_message().unwrap_or(None) {
+                Some(msg) => {
+                    self.served.set(self.served.get() + 1);
+                    return Some(msg);
+                }
+                None => {
+                    self.advance_lane();
+                    idle += 1;
+                }
+            }
+        }
+        None
+    }
+
+    fn advance_lane(&self) {
+        self.lane.set((self.lane.get() + 1) % self.inbox.len());
+        self.served.set(0);
     }
 
     /// Write message to Outbox (Ring Buffer)
@@ -133,4 +197,24 @@ mod tests {
             start_epoch + 1
         );
     }
+
+    #[test]
+    fn test_reactor_control_lane_not_starved() {
+        let sab = SafeSAB::with_size(16 * 1024 * 1024);
+        let reactor = Reactor::new(sab);
+
+        for _ in 0..20 {
+            assert!(reactor.inbox[LANE_BULK].write_message(b"bulk").unwrap());
+        }
+        assert!(reactor.inbox[LANE_CONTROL]
+            .write_message(b"cancel")
+            .unwrap());
+
+        assert_eq!(reactor.read_request().as_deref(), Some(&b"cancel"[..]));
+        let mut bulk = 0;
+        while reactor.read_request().is_some() {
+            bulk += 1;
+        }
+        assert_eq!(bulk, 20);
+    }
 }